@@ -2,18 +2,53 @@ package api
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
+	"monitor-tanques/internal/adapters/atg"
+	"monitor-tanques/internal/adapters/auth"
+	"monitor-tanques/internal/adapters/broadcast"
+	"monitor-tanques/internal/adapters/cache"
+	"monitor-tanques/internal/adapters/datalake"
+	"monitor-tanques/internal/adapters/deviceprofiles"
+	"monitor-tanques/internal/adapters/edgesync"
+	"monitor-tanques/internal/adapters/erp"
+	"monitor-tanques/internal/adapters/faults"
 	"monitor-tanques/internal/adapters/handlers"
+	"monitor-tanques/internal/adapters/ingest"
+	"monitor-tanques/internal/adapters/locks"
+	"monitor-tanques/internal/adapters/mqtt"
+	"monitor-tanques/internal/adapters/notifiers"
+	"monitor-tanques/internal/adapters/projections"
 	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/adapters/scheduler"
+	"monitor-tanques/internal/adapters/secrets"
+	"monitor-tanques/internal/adapters/sigfox"
+	"monitor-tanques/internal/adapters/snmp"
+	"monitor-tanques/internal/adapters/sse"
+	"monitor-tanques/internal/adapters/storage"
+	"monitor-tanques/internal/adapters/webhooks"
+	"monitor-tanques/internal/adapters/websocket"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
 	"monitor-tanques/internal/core/services"
 	"monitor-tanques/pkg/logger"
+	"monitor-tanques/pkg/metrics"
+)
+
+// Modos de autenticación reconocidos en AUTH_MODE
+const (
+	authModeNone  = "none"
+	authModeOIDC  = "oidc"
+	authModeToken = "token"
 )
 
 // Config contiene la configuración de la API
@@ -22,6 +57,272 @@ type Config struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+	RequestTimeout  time.Duration // Tiempo máximo de procesamiento de cada solicitud
+	MonitorInterval time.Duration // Intervalo del monitoreo en segundo plano (0 lo desactiva)
+	LockBackend     string        // memory o redis
+	RedisAddr       string
+	RedisPassword   string
+	InstanceID      string // Identificador de esta réplica para los bloqueos distribuidos
+
+	// Adaptadores elegidos al arrancar
+	RepositoryBackend   string // memory, sqlite, postgres o mongo
+	AlertNotifier       string // Notificador predeterminado: log, webhook o slack
+	AlertNotifierTarget string // URL del webhook para webhook y slack
+
+	// Configuración recargable con SIGHUP o POST /api/admin/config/reload. Los valores de
+	// RuntimeConfigFile sustituyen a los de las variables de entorno.
+	LogLevel              string  // debug, info, warn o error
+	DefaultAlertThreshold float64 // Umbral de alerta de los tanques nuevos que no indican uno
+	RuntimeConfigFile     string
+
+	// Instantáneas de los repositorios en memoria: con MemorySnapshotPath se guardan
+	// periódicamente en ese archivo y se restauran al arrancar
+	MemorySnapshotPath     string
+	MemorySnapshotInterval time.Duration
+
+	// Archivo YAML con el mapeo de los webhooks de las plataformas IoT de terceros
+	InboundWebhooksFile string
+
+	// Archivo YAML con los perfiles de equipo: las versiones de carga que admite cada uno y sus
+	// esquemas JSON
+	DeviceProfilesFile string
+
+	// Archivo YAML con los equipos Sigfox y el token de sus callbacks; las tramas repetidas se
+	// descartan durante SigfoxDedupWindow
+	SigfoxDevicesFile string
+	SigfoxDedupWindow time.Duration
+
+	// Archivo YAML con los agentes SNMP de los medidores antiguos y los OIDs de cada tanque. Los
+	// traps se reciben en SNMPTrapAddr (vacío los desactiva).
+	SNMPFile     string
+	SNMPTrapAddr string
+	SNMPTimeout  time.Duration // Espera de cada consulta a un agente
+
+	// Archivo YAML con las consolas ATG Veeder-Root TLS y sus tanques
+	ATGFile    string
+	ATGTimeout time.Duration // Plazo de cada comando enviado a una consola
+
+	// Archivo YAML con las conexiones a los ERP a los que se envía periódicamente el inventario
+	ERPFile string
+
+	// Modo de ejecución: server (instancia central) o edge (pasarela de un sitio que guarda sus
+	// mediciones y las reenvía a la instancia central cuando hay conexión)
+	RunMode           string
+	EdgeUpstreamURL   string        // Instancia central, p. ej. https://tanques.example.com
+	EdgeUpstreamToken string        // Token de la API de la instancia central (rol admin)
+	EdgeGatewayID     string        // Identificador de la pasarela; por defecto, InstanceID
+	EdgeSyncInterval  time.Duration // Frecuencia de las sincronizaciones
+	EdgeSyncBatchSize int           // Mediciones por lote (máximo 1000)
+	EdgeSyncTimeout   time.Duration // Plazo de cada envío a la instancia central
+	EdgeQueuePath     string        // Diario de la cola de mediciones pendientes; vacío la guarda en el backend
+
+	// Informe periódico de calidad de datos: huecos, lecturas congeladas y fuera de rango de la
+	// última ventana, enviado por correo a los destinatarios (0 desactiva el informe programado)
+	DataQualityReportInterval   time.Duration
+	DataQualityReportWindow     time.Duration
+	DataQualityFlatlineCount    int // Lecturas idénticas consecutivas que cuentan como racha congelada
+	DataQualityReportRecipients []string
+
+	// Servidor SMTP para los correos de los informes; vacío desactiva el envío
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// Archivo YAML con los sitios, tanques, sensores y reglas de alerta que se reconcilian al arrancar
+	ProvisioningFile string
+	ProvisioningPlan bool // Solo calcula los cambios del archivo (--plan), sin aplicarlos
+
+	// Vigencia de la proyección del estado de cada tanque; 0 la conserva hasta la siguiente
+	// medición. Con varias réplicas permite ver las mediciones guardadas por las demás.
+	TankStateMaxAge time.Duration
+
+	// Escritura diferida de mediciones: con MeasurementBatchSize > 0 las mediciones se guardan por lotes
+	MeasurementBatchSize     int
+	MeasurementFlushInterval time.Duration
+
+	// Margen para las mediciones fechadas en el futuro por la deriva del reloj de los equipos;
+	// las posteriores a ahora más este margen se rechazan
+	MeasurementFutureTolerance time.Duration
+
+	// Bandeja de salida: las alertas de nivel se guardan junto con las mediciones y una tarea las
+	// entrega después, reintentando hasta OutboxMaxAttempts veces
+	OutboxEnabled       bool
+	OutboxRelayInterval time.Duration
+	OutboxMaxAttempts   int
+	OutboxRetryBackoff  time.Duration // Espera tras el primer fallo; se duplica en cada intento
+
+	// Adjuntos de los tanques: local (disco) o s3
+	AttachmentStorage string
+	AttachmentDir     string
+	AttachmentMaxSize int64 // Bytes por archivo
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+
+	// Exportación de las mediciones al data lake en Parquet: s3 (también GCS con claves HMAC), local
+	// o vacío para desactivarla. Las credenciales y la región de S3 son las de los adjuntos.
+	DataLakeStorage        string
+	DataLakeBucket         string
+	DataLakeEndpoint       string // Por defecto, S3Endpoint
+	DataLakeDir            string
+	DataLakePrefix         string
+	DataLakeExportInterval time.Duration
+	DataLakeExportDelay    time.Duration // Margen para las mediciones que llegan con retraso
+
+	// Notificaciones push con geovalla para los canales de tipo push
+	PushRadiusKm       float64
+	FCMCredentialsFile string
+	APNsKeyFile        string
+	APNsKeyID          string
+	APNsTeamID         string
+	APNsTopic          string
+	APNsSandbox        bool
+
+	// Detección de anomalías en las series de nivel y temperatura
+	AnomalyWindow        int     // Lecturas previas de referencia (0 desactiva la puntuación z)
+	AnomalyZThreshold    float64 // Puntuación z a partir de la cual una lectura es anómala
+	AnomalyFlatlineCount int     // Lecturas idénticas que indican un sensor congelado (0 lo desactiva)
+
+	// Evaluación de la salud de los sensores
+	SensorHealthSamples       int     // Lecturas recientes analizadas por tanque
+	SensorBatteryFullVoltage  float64 // Voltaje de la batería nueva
+	SensorBatteryEmptyVoltage float64 // Voltaje con el que el sensor deja de funcionar
+	SensorLowBatteryVoltage   float64 // Voltaje que dispara el aviso de batería baja (0 lo desactiva)
+	SensorWeakSignalRSSI      float64 // RSSI en dBm que dispara el aviso de señal débil (0 lo desactiva)
+
+	// Reloj de los sensores: desfase medio que lo marca como desajustado y desfase a partir del
+	// cual las marcas de tiempo se sustituyen por la hora de recepción (0 no corrige)
+	ClockSkewDriftThreshold      time.Duration
+	ClockSkewCorrectionThreshold time.Duration
+
+	// Comandos de bajada a los equipos por MQTT (sin broker, los comandos se rechazan)
+	MQTTBrokerAddr   string
+	MQTTUsername     string
+	MQTTPassword     string
+	MQTTCommandTopic string        // {id} se reemplaza por el ID del equipo
+	TankPollTimeout  time.Duration // Espera máxima de la lectura solicitada con POST /api/tanks/{id}/poll
+
+	// Ingesta de las mediciones publicadas por los equipos en el broker de MQTTBrokerAddr (vacío la
+	// desactiva); los mensajes confirmados se guardan antes en el búfer durable de MQTTIngestBufferPath
+	MQTTIngestTopic      string // {id} es el segmento del tema con el ID del tanque
+	MQTTIngestBufferPath string
+
+	// Descubrimiento MQTT de Home Assistant: cada tanque aparece como un dispositivo con sus
+	// sensores en el broker de MQTTBrokerAddr
+	HomeAssistantEnabled         bool
+	HomeAssistantDiscoveryPrefix string
+	HomeAssistantStateTopic      string // {id} se reemplaza por el ID del tanque
+
+	// Compresión de las respuestas según Accept-Encoding
+	CompressionEnabled bool
+	CompressionMinSize int // Bytes a partir de los cuales se comprime la respuesta
+
+	// Límites por solicitud. Las rutas de ingesta de mediciones y de exportación de historiales
+	// tienen presupuestos propios; las subidas de archivos aplican sus propios límites de tamaño.
+	MaxRequestBodySize   int64         // Bytes máximos del cuerpo de las solicitudes (0 sin límite)
+	IngestMaxBodySize    int64         // Bytes máximos de una medición enviada por un sensor
+	IngestRequestTimeout time.Duration // Tiempo máximo de la ingesta de una medición
+	ExportRequestTimeout time.Duration // Tiempo máximo de la exportación de un historial
+
+	// Caché de las consultas costosas (KPI, sugerencias de reabastecimiento, salud de sensores)
+	ResponseCacheTTL time.Duration // 0 la desactiva
+
+	// Perfiles de pprof bajo /api/admin/debug/pprof (desactivados por defecto)
+	ProfilingEnabled bool
+
+	// Métricas de la entrega de alertas en /metrics, en el formato de texto de Prometheus
+	MetricsEnabled bool
+
+	// Páginas de estado públicas en /public/{token}, sin autenticación, con el nivel de los
+	// tanques elegidos (desactivadas por defecto)
+	PublicStatusEnabled bool
+
+	// Medición del uso por organización para la facturación en instalaciones compartidas: las
+	// solicitudes se atribuyen a la organización del usuario y las mediciones a la de la etiqueta
+	// UsageOrganizationLabel del tanque
+	UsageMeteringEnabled   bool
+	UsageOrganizationLabel string
+
+	// Enlaces de descarga firmados para las exportaciones, que se pueden enviar por correo sin
+	// credenciales. Sin clave se genera una aleatoria al arrancar: los enlaces dejan de valer al
+	// reiniciar y solo los acepta la réplica que los generó.
+	SignedURLSecret string
+	SignedURLTTL    time.Duration // Vigencia por defecto
+	SignedURLMaxTTL time.Duration // Vigencia máxima que se puede solicitar
+	PublicBaseURL   string        // Origen de los enlaces generados, p. ej. https://tanques.example.com
+
+	// Secreto con el que se firman los webhooks salientes (canales webhook sin secreto propio y
+	// webhooks de estado de los tanques); vacío, se envían sin firmar
+	WebhookSigningSecret string
+
+	// Eventos en tiempo real por WebSocket en /api/live
+	LivePingInterval time.Duration // Cada cuánto se comprueba que cada cliente siga conectado
+	LiveSendBuffer   int           // Eventos pendientes por cliente antes de desconectarlo por lento
+
+	// Entorno de ejecución: production, staging o test
+	Environment string
+
+	// Inyección de fallos para verificar la resiliencia; solo se permite en staging y test
+	FaultLatency   time.Duration // Retardo añadido a cada operación
+	FaultErrorRate float64       // Probabilidad de que una operación falle (entre 0 y 1)
+	FaultTargets   string        // repositories y/o notifiers, separados por comas
+
+	// Autenticación: none (sin autenticación), oidc (proveedor de identidad externo, además de los
+	// tokens de la API) o token (solo los tokens de la API emitidos con create-admin)
+	AuthMode         string
+	OIDCIssuerURL    string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCGroupsClaim  string
+	OIDCRoleMapping  string // grupo:rol separados por comas, p. ej. "tank-admins:admin,ops:operator"
+	OIDCOrgClaim     string // Claim con la organización del usuario
+
+	// Protección frente a fuerza bruta en los modos oidc y token: LoginMaxFailures fallos de una
+	// misma IP dentro de LoginFailureWindow la bloquean durante LoginLockoutDuration (0 desactiva
+	// los bloqueos). Con CaptchaVerifyURL, el inicio de sesión OIDC exige CAPTCHA a partir de
+	// CaptchaAfterFailures fallos.
+	LoginMaxFailures       int
+	LoginFailureWindow     time.Duration
+	LoginLockoutDuration   time.Duration
+	LoginTrustForwardedFor bool   // Toma la IP del cliente de X-Forwarded-For (solo detrás de un proxy)
+	CaptchaVerifyURL       string // Endpoint siteverify de reCAPTCHA, hCaptcha o Turnstile
+	CaptchaSecret          string
+	CaptchaAfterFailures   int
+
+	// Sesiones abiertas al iniciar sesión con OIDC: el token de acceso vence a los
+	// SessionAccessTTL y se renueva con el token de refresco, que rota en cada uso y vence si la
+	// sesión no se refresca en SessionRefreshTTL
+	SessionAccessTTL  time.Duration
+	SessionRefreshTTL time.Duration
+
+	// Sitios regulados: los cambios del umbral o de la capacidad de sus tanques que hace un
+	// operador quedan pendientes hasta que los aprueba un administrador (vacío lo desactiva)
+	ChangeApprovalSites []string
+
+	// Escucha mTLS para la ingesta de los equipos de campo fijos (vacía la desactiva). Cada equipo
+	// presenta un certificado emitido por DeviceTLSClientCAFile cuyo CN es su ID.
+	DeviceTLSAddr         string
+	DeviceTLSCertFile     string
+	DeviceTLSKeyFile      string
+	DeviceTLSClientCAFile string
+
+	// Proveedor de secretos para las credenciales indicadas como secret:<nombre>: env, file, vault
+	// o aws. Las credenciales del propio proveedor solo pueden venir del entorno.
+	SecretsProvider        string
+	SecretsDir             string        // Directorio de los archivos de secretos (file)
+	SecretsCacheTTL        time.Duration // Vigencia de los secretos leídos (0 los lee en cada uso)
+	VaultAddr              string
+	VaultToken             string
+	VaultMount             string
+	AWSRegion              string
+	AWSAccessKeyID         string
+	AWSSecretAccessKey     string
+	AWSSessionToken        string
+	SecretsManagerEndpoint string // Vacío usa el de la región
 }
 
 // DefaultConfig retorna una configuración predeterminada para la API
@@ -31,15 +332,148 @@ func DefaultConfig() Config {
 		ReadTimeout:     5 * time.Second,
 		WriteTimeout:    10 * time.Second,
 		ShutdownTimeout: 5 * time.Second,
+		RequestTimeout:  8 * time.Second,
+		MonitorInterval: time.Minute,
+		LockBackend:     "memory",
+		RedisAddr:       "localhost:6379",
+		InstanceID:      defaultInstanceID(),
+
+		RepositoryBackend: "memory",
+		AlertNotifier:     "log",
+
+		RunMode:           domain.RunModeServer,
+		EdgeSyncInterval:  30 * time.Second,
+		EdgeSyncBatchSize: 500,
+		EdgeSyncTimeout:   30 * time.Second,
+
+		LogLevel:              "debug",
+		DefaultAlertThreshold: domain.DefaultAlertThreshold,
+
+		MemorySnapshotInterval: 5 * time.Minute,
+
+		MeasurementFlushInterval:   time.Second,
+		MeasurementFutureTolerance: 5 * time.Minute,
+
+		ClockSkewDriftThreshold: 2 * time.Minute,
+
+		OutboxRelayInterval: 5 * time.Second,
+		OutboxMaxAttempts:   10,
+		OutboxRetryBackoff:  30 * time.Second,
+
+		AttachmentStorage: "local",
+		AttachmentDir:     "data/attachments",
+		AttachmentMaxSize: 20 << 20,
+
+		DataLakeDir:            "data/datalake",
+		DataLakeExportInterval: time.Hour,
+		DataLakeExportDelay:    15 * time.Minute,
+
+		PushRadiusKm: 25,
+
+		AnomalyWindow:        20,
+		AnomalyZThreshold:    4,
+		AnomalyFlatlineCount: 48,
+
+		SensorHealthSamples:       100,
+		SensorBatteryFullVoltage:  3.6,
+		SensorBatteryEmptyVoltage: 3.0,
+		SensorLowBatteryVoltage:   3.2,
+		SensorWeakSignalRSSI:      -110,
+
+		MQTTCommandTopic: "devices/{id}/commands",
+		TankPollTimeout:  6 * time.Second,
+
+		HomeAssistantDiscoveryPrefix: "homeassistant",
+		HomeAssistantStateTopic:      "monitor-tanques/tanks/{id}/state",
+
+		SigfoxDedupWindow: 10 * time.Minute,
+		SNMPTimeout:       5 * time.Second,
+		ATGTimeout:        10 * time.Second,
+
+		DataQualityReportInterval: 24 * time.Hour,
+		DataQualityReportWindow:   24 * time.Hour,
+		DataQualityFlatlineCount:  12,
+
+		CompressionEnabled: true,
+		CompressionMinSize: 1024,
+
+		MaxRequestBodySize:   1 << 20,
+		IngestMaxBodySize:    64 << 10,
+		IngestRequestTimeout: 3 * time.Second,
+		ExportRequestTimeout: 2 * time.Minute,
+
+		ResponseCacheTTL: 30 * time.Second,
+
+		MetricsEnabled: true,
+
+		UsageOrganizationLabel: "organization",
+
+		SignedURLTTL:    time.Hour,
+		SignedURLMaxTTL: 24 * time.Hour,
+
+		LivePingInterval: 30 * time.Second,
+		LiveSendBuffer:   64,
+
+		Environment:  "production",
+		FaultTargets: "repositories,notifiers",
+
+		AuthMode:        authModeNone,
+		OIDCGroupsClaim: "groups",
+		OIDCOrgClaim:    "org_id",
+
+		LoginMaxFailures:     5,
+		LoginFailureWindow:   15 * time.Minute,
+		LoginLockoutDuration: 15 * time.Minute,
+		CaptchaAfterFailures: 3,
+
+		SessionAccessTTL:  15 * time.Minute,
+		SessionRefreshTTL: 7 * 24 * time.Hour,
+
+		SecretsProvider: secretsProviderEnv,
+		SecretsDir:      "/run/secrets",
+		SecretsCacheTTL: 5 * time.Minute,
+		VaultMount:      "secret",
+		AWSRegion:       "us-east-1",
+	}
+}
+
+// defaultInstanceID usa el nombre del host como identificador de la réplica
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return uuid.New().String()
 	}
+	return hostname
 }
 
 // API es el componente principal de la aplicación que maneja el servidor HTTP
 type API struct {
-	server *http.Server
-	router *mux.Router
-	logger logger.Logger
-	config Config
+	server        *http.Server
+	router        *mux.Router
+	logger        logger.Logger
+	config        Config
+	alertNotifier ports.AlertNotifier
+	scheduler     *scheduler.Scheduler
+	batchWriter   *ingest.BatchWriter
+	snmpCollector *snmp.Collector                       // Solo con SNMPFile y SNMPTrapAddr, para recibir los traps
+	versions      map[string]*mux.Router                // Rutas propias de cada versión publicada de la API
+	memoryStore   *repositories.MemoryStore             // Solo con instantáneas de los repositorios en memoria
+	edgeQueue     *repositories.FileSyncQueueRepository // Solo en una pasarela edge con EdgeQueuePath
+	shutdownHooks []shutdownHook
+	responseCache *cache.ResponseCache // Solo con ResponseCacheTTL > 0
+	metrics       *metrics.Registry    // Solo con MetricsEnabled
+	runtimeConfig ports.RuntimeConfigService
+	secrets       ports.SecretProvider // Resuelve las credenciales indicadas como secret:<nombre>
+	deviceServer  *http.Server         // Solo con DeviceTLSAddr, para la ingesta con certificado de cliente
+
+	// Solo con MQTTIngestTopic, para recibir las mediciones publicadas por los equipos
+	mqttSubscriber *mqtt.Subscriber
+
+	// Solo con HomeAssistantEnabled, para publicar el estado de los tanques en segundo plano
+	homeAssistant        *mqtt.HomeAssistantPublisher
+	tankStatePublication *services.TankStatePublishingTankService
+
+	provisioningService ports.ProvisioningService
 }
 
 // NewAPI crea una nueva instancia de la API
@@ -48,45 +482,997 @@ func NewAPI(config Config, logger logger.Logger) *API {
 
 	server := &http.Server{
 		Addr:         ":" + config.Port,
-		Handler:      router,
 		ReadTimeout:  config.ReadTimeout,
 		WriteTimeout: config.WriteTimeout,
 	}
 
-	return &API{
-		server: server,
-		router: router,
-		logger: logger,
-		config: config,
+	a := &API{
+		server:   server,
+		router:   router,
+		logger:   logger,
+		config:   config,
+		versions: make(map[string]*mux.Router),
 	}
+
+	// Las versiones se resuelven antes del enrutado; cada una empieza con las rutas compartidas
+	for _, version := range publishedAPIVersions {
+		a.versionRoutes(version)
+	}
+	server.Handler = a.languageMiddleware(a.versionMiddleware(router))
+
+	return a
+}
+
+// SetAlertNotifier reemplaza el notificador de alertas. Debe llamarse antes de SetupRoutes.
+func (a *API) SetAlertNotifier(notifier ports.AlertNotifier) {
+	a.alertNotifier = notifier
+}
+
+// Handler devuelve el manejador HTTP de la API, útil para pruebas con httptest
+func (a *API) Handler() http.Handler {
+	return a.server.Handler
 }
 
 // SetupRoutes configura todas las rutas de la API
 func (a *API) SetupRoutes() {
-	// Creamos los repositorios (adaptadores de salida)
-	tankRepo := repositories.NewMemoryTankRepository()
-	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	// Las credenciales se resuelven antes de crear cualquier adaptador que las use
+	a.resolveSecrets()
+
+	// Creamos los repositorios (adaptadores de salida) del backend configurado
+	repos, err := a.newRepositories()
+	if err != nil {
+		a.logger.Fatal("Invalid repository backend", "error", err)
+	}
+
+	// En staging y test pueden inyectarse fallos en la persistencia y en las notificaciones
+	injector, faultTargets := a.newFaultInjector()
+	if faultTargets[faultTargetRepositories] {
+		repos.tanks = faults.NewTankRepository(repos.tanks, injector)
+		repos.measurements = faults.NewMeasurementRepository(repos.measurements, injector)
+		repos.unitOfWork = faults.NewUnitOfWork(repos.unitOfWork, injector)
+	}
+
+	if a.alertNotifier == nil {
+		notifier, err := a.newAlertNotifier(a.config.AlertNotifier, a.config.AlertNotifierTarget)
+		if err != nil {
+			a.logger.Fatal("Invalid alert notifier", "error", err)
+		}
+		a.alertNotifier = notifier
+	}
+
+	// Las alertas de los canales push llegan solo a los técnicos cercanos al tanque
+	pushNotifier := services.NewGeofencedPushNotifier(repos.tanks, repos.mobileDevices, a.newPushSender(), a.config.PushRadiusKm)
+
+	// El correo sirve a los canales de tipo email, a los informes y a los avisos de las políticas
+	emailSender := a.newEmailSender()
+
+	// Cada entrega de alertas se mide por canal para detectar proveedores que fallan o se ralentizan
+	var channelSender ports.ChannelSender = notifiers.NewChannelSender(pushNotifier, emailSender, a.config.WebhookSigningSecret, a.logger)
+	// Los destinos de los canales pueden ser referencias a secretos, como las URL de Slack
+	channelSender = secrets.NewChannelSender(channelSender, a.secrets)
+	// El notificador predeterminado se reemplaza al recargar la configuración sin tocar sus decoradores
+	swappableNotifier := services.NewSwappableAlertNotifier(a.alertNotifier)
+	alertQueue, defaultNotifier := repos.alertQueue, ports.AlertNotifier(swappableNotifier)
+	if faultTargets[faultTargetNotifiers] {
+		channelSender = faults.NewChannelSender(channelSender, injector)
+		defaultNotifier = faults.NewAlertNotifier(defaultNotifier, injector)
+	}
+	if a.config.MetricsEnabled {
+		a.metrics = metrics.NewRegistry()
+		alertMetrics := notifiers.NewAlertMetrics(a.metrics)
+		channelSender = notifiers.NewInstrumentedChannelSender(channelSender, alertMetrics)
+		alertQueue = notifiers.NewInstrumentedAlertQueue(alertQueue, alertMetrics)
+		defaultNotifier = notifiers.NewInstrumentedAlertNotifier(defaultNotifier, a.config.AlertNotifier, alertMetrics)
+	}
+
+	// Las consultas de los usuarios se limitan a los tanques de sus concesiones de acceso
+	accessService := services.NewAccessService(repos.accessGrants)
 
-	// Creamos un notificador de alertas mock (podría ser reemplazado por uno real)
-	alertNotifier := &mockAlertNotifier{logger: a.logger}
+	// Las alertas se distribuyen por los canales configurados; sin canales se usa el notificador predeterminado
+	notificationService := services.NewNotificationService(
+		repos.channels,
+		alertQueue,
+		channelSender,
+		defaultNotifier,
+		accessService,
+	)
+
+	// Las mediciones y las alertas se difunden por WebSocket o SSE a los clientes suscritos que
+	// tengan acceso al tanque según sus concesiones
+	var liveMetrics *broadcast.Metrics
+	if a.metrics != nil {
+		liveMetrics = broadcast.NewMetrics(a.metrics)
+	}
+	liveHub := broadcast.NewHub(accessService, broadcast.Config{SendBuffer: a.config.LiveSendBuffer}, liveMetrics, a.logger)
+	a.server.RegisterOnShutdown(liveHub.Close)
+	liveNotifier := services.NewLiveAlertNotifier(notificationService, liveHub)
+
+	// Las alertas críticas de los líquidos peligrosos se avisan además a los contactos de su política
+	var policyNotifier ports.AlertNotifier = liveNotifier
+	if emailSender != nil {
+		policyNotifier = services.NewHazmatAlertNotifier(liveNotifier, repos.liquidPolicies, emailSender)
+	}
+
+	// Cada alerta crítica guarda una instantánea del tanque para investigar el incidente
+	evidenceNotifier := services.NewEvidenceAlertNotifier(policyNotifier, repos.tanks, repos.measurements, repos.notes, repos.alertEvidence)
+
+	// Los operadores pueden silenciar temporalmente las alertas de un tanque con un problema conocido
+	mutingNotifier := services.NewMutingAlertNotifier(evidenceNotifier, repos.alertMutes)
 
 	// Creamos el servicio principal (puerto)
-	tankService := services.NewTankService(tankRepo, measurementRepo, alertNotifier)
+	// El estado actual de cada tanque se proyecta en memoria y se mantiene al guardar mediciones
+	tankStates := projections.NewMemoryTankStateStore(a.config.TankStateMaxAge)
+	tankService := services.NewTankService(repos.tanks, repos.measurements, mutingNotifier, repos.unitOfWork, tankStates)
+	if a.config.OutboxEnabled {
+		tankService = services.NewTankServiceWithOutbox(repos.tanks, repos.measurements, mutingNotifier, repos.unitOfWork, tankStates)
+	}
+	outboxService := services.NewOutboxService(repos.outbox, mutingNotifier, domain.OutboxConfig{
+		BatchSize:    outboxBatchSize,
+		MaxAttempts:  a.config.OutboxMaxAttempts,
+		RetryBackoff: a.config.OutboxRetryBackoff,
+	})
+
+	// Cada medición guardada se analiza en busca de lecturas inusuales o sensores congelados
+	detectingTankService := services.NewAnomalyDetectingTankService(
+		tankService,
+		repos.measurements,
+		repos.anomalies,
+		mutingNotifier,
+		domain.AnomalyConfig{
+			Window:        a.config.AnomalyWindow,
+			ZThreshold:    a.config.AnomalyZThreshold,
+			FlatlineCount: a.config.AnomalyFlatlineCount,
+		},
+	)
+
+	// La batería y la señal reportadas por los sensores generan avisos al cruzar sus umbrales
+	telemetryTankService := services.NewTelemetryAlertingTankService(
+		detectingTankService,
+		repos.measurements,
+		mutingNotifier,
+		domain.TelemetryConfig{
+			LowBatteryVoltage: a.config.SensorLowBatteryVoltage,
+			WeakSignalRSSI:    a.config.SensorWeakSignalRSSI,
+		},
+	)
+
+	// Con la medición de uso, las mediciones guardadas se cuentan en la organización de su tanque
+	meteredTankService := telemetryTankService
+	if a.config.UsageMeteringEnabled {
+		meteredTankService = services.NewMeteringTankService(telemetryTankService, repos.usage, a.config.UsageOrganizationLabel)
+	}
+
+	liveTankService := services.NewLiveEventTankService(meteredTankService, liveHub)
+
+	// Los tanques con webhook propio lo reciben en cada cambio de estado
+	webhookTankService := services.NewStatusWebhookTankService(liveTankService, repos.measurements, notifiers.NewStatusWebhookSender(a.config.WebhookSigningSecret, a.logger))
+
+	// Home Assistant descubre los tanques y recibe su estado por MQTT
+	publishedTankService := webhookTankService
+	if publisher := a.newHomeAssistantPublisher(); publisher != nil {
+		a.homeAssistant = publisher
+		a.tankStatePublication = services.NewTankStatePublishingTankService(webhookTankService, publisher)
+		publishedTankService = a.tankStatePublication
+	}
+
+	// Los tanques nuevos toman los umbrales de la política de su líquido, que limita además sus
+	// cambios; sin política, el umbral por defecto de la configuración recargable
+	tankDefaults := services.NewTankDefaults(a.config.DefaultAlertThreshold)
+	defaultsTankService := services.NewDefaultsTankService(publishedTankService, tankDefaults)
+	policyTankService := services.NewLiquidPolicyTankService(defaultsTankService, repos.liquidPolicies)
+
+	// El nivel de logging, el notificador predeterminado y el umbral por defecto se recargan sin
+	// reiniciar el servidor
+	a.runtimeConfig = a.setupRuntimeConfig(swappableNotifier, tankDefaults)
+
+	// Las respuestas en caché de un tanque se descartan en cuanto se guardan sus mediciones
+	storedTankService := policyTankService
+	if a.config.ResponseCacheTTL > 0 {
+		a.responseCache = cache.NewResponseCache(a.config.ResponseCacheTTL)
+		storedTankService = services.NewCacheInvalidatingTankService(policyTankService, a.responseCache)
+	}
+
+	// Con ingesta intensiva, las mediciones se acumulan y se guardan por lotes
+	ingestTankService := storedTankService
+	if a.config.MeasurementBatchSize > 0 {
+		a.batchWriter = ingest.NewBatchWriter(storedTankService, ingest.BatchConfig{
+			Size:          a.config.MeasurementBatchSize,
+			FlushInterval: a.config.MeasurementFlushInterval,
+		}, a.logger)
+		ingestTankService = a.batchWriter
+	}
+
+	// Las mediciones fechadas en el futuro se rechazan antes de llegar al búfer, para que el
+	// equipo reciba el error
+	ingestTankService = services.NewFutureGuardTankService(ingestTankService, a.config.MeasurementFutureTolerance)
+
+	// El reloj de cada sensor se mide antes del rechazo de las mediciones futuras, para que la
+	// corrección, si está activada, se aplique primero y los sensores rechazados queden marcados
+	clockSkewTracker := services.NewClockSkewTracker(domain.ClockSkewConfig{
+		DriftThreshold:      a.config.ClockSkewDriftThreshold,
+		CorrectionThreshold: a.config.ClockSkewCorrectionThreshold,
+	})
+	ingestTankService = services.NewClockSkewTankService(ingestTankService, clockSkewTracker)
+
+	// En una pasarela edge, cada medición aceptada se encola para reenviarla a la instancia central
+	syncOrigin := domain.SyncOriginCentral
+	switch a.config.RunMode {
+	case domain.RunModeServer, "":
+	case domain.RunModeEdge:
+		syncOrigin = a.edgeGatewayID()
+		repos.syncQueue = a.newEdgeSyncQueue(repos.syncQueue)
+		ingestTankService = services.NewEdgeQueueingTankService(ingestTankService, repos.syncQueue)
+	default:
+		a.logger.Fatal("Invalid run mode", "mode", a.config.RunMode)
+	}
+
+	// Cada cambio de configuración de un tanque, también el que aplica una aprobación, se avisa
+	// por los canales de notificación. Los silencios de un tanque no ocultan estos avisos.
+	configTankService := services.NewConfigNotifyingTankService(ingestTankService, liveNotifier)
+
+	// Los cambios de configuración de los usuarios quedan en el registro de sincronización, del que
+	// los obtiene la otra instancia; los que llegan de ella se aplican por debajo, sin registrarse
+	// de nuevo como propios
+	trackedTankService := services.NewSyncTrackingTankService(configTankService, repos.tankSync, syncOrigin)
+	// Los cambios de umbral y capacidad que llegan de una pasarela no tienen quien solicite su
+	// aprobación: en los sitios regulados se rechazan
+	syncTankService := configTankService
+	if len(a.config.ChangeApprovalSites) > 0 {
+		syncTankService = services.NewSyncApprovalTankService(configTankService, a.config.ChangeApprovalSites)
+	}
+	tankSyncService := services.NewTankSyncService(syncTankService, repos.tankSync)
+	var edgeSyncService ports.EdgeSyncService
+	if a.config.RunMode == domain.RunModeEdge {
+		edgeSyncService = a.newEdgeSyncService(repos.syncQueue, repos.tankSync, configTankService)
+	}
+
+	// En los sitios regulados, los cambios de umbral y capacidad de los operadores esperan la
+	// aprobación de un administrador; la ingesta de los equipos no pasa por aquí
+	approvalTankService := trackedTankService
+	if len(a.config.ChangeApprovalSites) > 0 {
+		approvalTankService = services.NewApprovalTankService(trackedTankService, repos.tankChanges, a.config.ChangeApprovalSites)
+	}
+
+	// Las consultas y modificaciones de los usuarios pasan por las concesiones de acceso por sitio o grupo
+	authorizedTankService := services.NewAuthorizedTankService(approvalTankService, accessService)
+	// Solo las respuestas de la API llevan la tendencia; los servicios internos no la necesitan
+	trendTankService := services.NewTrendTankService(authorizedTankService, repos.measurementStreamer)
+
+	measurementService := services.NewMeasurementService(authorizedTankService, repos.measurements, repos.measurementStreamer)
+	reorderService := services.NewReorderService(authorizedTankService, repos.measurements)
+	deliveryService := services.NewDeliveryService(repos.suppliers, repos.deliveryOrders, authorizedTankService, repos.measurements)
+	statusHistoryService := services.NewStatusHistoryService(authorizedTankService, repos.statusChanges, repos.notes)
+	noteService := services.NewNoteService(authorizedTankService, repos.notes, repos.measurements, repos.statusChanges)
+	alertEvidenceService := services.NewAlertEvidenceService(repos.alertEvidence, accessService)
+	kpiService := services.NewKPIService(authorizedTankService, repos.measurements, repos.statusChanges)
+	anomalyService := services.NewAnomalyService(authorizedTankService, repos.anomalies)
+	sensorHealthService := services.NewSensorHealthService(authorizedTankService, repos.measurements, domain.SensorHealthConfig{
+		Samples:             a.config.SensorHealthSamples,
+		BatteryFullVoltage:  a.config.SensorBatteryFullVoltage,
+		BatteryEmptyVoltage: a.config.SensorBatteryEmptyVoltage,
+	})
+	clockSkewService := services.NewClockSkewService(authorizedTankService, clockSkewTracker)
+	fileStorage := a.newFileStorage()
+	attachmentService := services.NewAttachmentService(authorizedTankService, repos.attachments, fileStorage)
+	deviceService := services.NewMobileDeviceService(repos.mobileDevices)
+	savedViewService := services.NewSavedViewService(repos.savedViews)
+	fieldDeviceService := services.NewFieldDeviceService(authorizedTankService, repos.fieldDevices)
+	commandService := services.NewDeviceCommandService(fieldDeviceService, repos.commands, a.newCommandPublisher())
+	pollService := services.NewTankPollService(authorizedTankService, fieldDeviceService, commandService, repos.measurements, a.config.TankPollTimeout)
+	searchService := services.NewSearchService(authorizedTankService, repos.notes)
+	voiceService := services.NewVoiceService(authorizedTankService)
+	compartmentService := services.NewCompartmentService(trendTankService)
+	mobileOverviewService := services.NewMobileOverviewService(authorizedTankService, repos.measurementStreamer)
+	digestService := services.NewDigestService(authorizedTankService, repos.measurements, repos.channels, alertQueue, channelSender)
+	alertRuleService := services.NewAlertRuleService(authorizedTankService, repos.measurements, repos.channels, repos.alertMutes)
+	tankImportService := services.NewTankImportService(authorizedTankService, accessService)
+	alertMuteService := services.NewAlertMuteService(authorizedTankService, repos.alertMutes)
+	tankChangeService := services.NewTankChangeService(authorizedTankService, repos.tankChanges)
+	liquidPolicyService := services.NewLiquidPolicyService(repos.liquidPolicies)
+	provisioningService := services.NewProvisioningService(authorizedTankService, fieldDeviceService, notificationService)
+	dataQualityService := services.NewDataQualityService(
+		authorizedTankService,
+		repos.measurements,
+		emailSender,
+		domain.DataQualityConfig{FlatlineCount: a.config.DataQualityFlatlineCount},
+		a.config.DataQualityReportWindow,
+		a.config.DataQualityReportRecipients,
+	)
+	shiftReportService := services.NewShiftReportService(authorizedTankService, repos.measurements, repos.statusChanges, repos.alertMutes)
+	usageService := services.NewUsageService(repos.usage)
+	statusShareService := services.NewStatusShareService(authorizedTankService, repos.statusShares)
+	urlSigner := auth.NewHMACURLSigner(a.signedURLKey())
+	signedURLService, err := services.NewSignedURLService(urlSigner, signableExportPaths, a.config.PublicBaseURL, a.config.SignedURLTTL, a.config.SignedURLMaxTTL)
+	if err != nil {
+		a.logger.Fatal("Invalid public base URL", "url", a.config.PublicBaseURL, "error", err)
+	}
+	var responseCache ports.CacheInvalidator
+	if a.responseCache != nil {
+		responseCache = a.responseCache
+	}
+	recomputeService := services.NewRecomputeService(
+		authorizedTankService,
+		repos.tanks,
+		repos.measurements,
+		repos.statusChanges,
+		repos.statusRewriter,
+		tankStates,
+		responseCache,
+	)
+	purgeService := services.NewPurgeService(
+		repos.tanks,
+		repos.measurementPurger,
+		repos.tankPurgers,
+		repos.attachments,
+		fileStorage,
+		tankStates,
+		responseCache,
+		a.config.UsageOrganizationLabel,
+	)
+	erpConfig := a.loadERPConfig()
+	inventorySyncService := services.NewInventorySyncService(authorizedTankService, erpExporters(erpConfig)...)
+	dataLakeExportService := a.newDataLakeExportService(repos.tanks, repos.measurementStreamer)
+
+	a.provisioningService = provisioningService
+	if a.config.ProvisioningFile != "" && !a.config.ProvisioningPlan {
+		a.provision(provisioningService)
+	}
+
+	// Al apagar, las mediciones del búfer se guardan antes de entregar las alertas en cola y de
+	// tomar la última instantánea, para que ambas las incluyan. Las purgas en curso terminan
+	// antes que todo ello, para que la instantánea no conserve datos a medio eliminar.
+	a.onShutdown("purges", purgeService.Wait)
+	if a.batchWriter != nil {
+		a.onShutdown("flush-measurements", a.batchWriter.Close)
+	}
+	if a.config.OutboxEnabled {
+		a.onShutdown("flush-outbox", outboxService.RelayEvents)
+	}
+	a.onShutdown("flush-alert-queue", notificationService.FlushQueuedAlerts)
+	if a.memoryStore != nil {
+		a.onShutdown("memory-snapshot", a.saveMemorySnapshot)
+	}
+	if a.edgeQueue != nil {
+		a.onShutdown("close-edge-queue", func(context.Context) error { return a.edgeQueue.Close() })
+	}
+
+	// Configuramos las tareas en segundo plano
+	a.scheduler = scheduler.NewScheduler(a.newLocker(), a.logger)
+	a.scheduler.AddJob(scheduler.Job{
+		Name:     "monitor-tanks",
+		Interval: a.config.MonitorInterval,
+		Run:      tankService.MonitorAllTanks,
+	})
+	if a.config.OutboxEnabled {
+		a.scheduler.AddJob(scheduler.Job{
+			Name:     "outbox-relay",
+			Interval: a.config.OutboxRelayInterval,
+			Run:      outboxService.RelayEvents,
+		})
+	}
+	a.scheduler.AddJob(scheduler.Job{
+		Name:     "flush-alert-queue",
+		Interval: time.Minute,
+		Run:      notificationService.FlushQueuedAlerts,
+	})
+	// Cada canal con resumen diario lo recibe a su hora, en la zona horaria de su horario
+	a.scheduler.AddJob(scheduler.Job{
+		Name:     "notification-digests",
+		Interval: time.Minute,
+		Run:      digestService.SendDueDigests,
+	})
+	if a.memoryStore != nil && a.config.MemorySnapshotInterval > 0 {
+		// Cada réplica guarda su propia memoria, así que el bloqueo es por instancia
+		a.scheduler.AddJob(scheduler.Job{
+			Name:     "memory-snapshot-" + a.config.InstanceID,
+			Interval: a.config.MemorySnapshotInterval,
+			Run:      a.saveMemorySnapshot,
+		})
+	}
+	if a.config.DataQualityReportInterval > 0 {
+		a.scheduler.AddJob(scheduler.Job{
+			Name:     "data-quality-report",
+			Interval: a.config.DataQualityReportInterval,
+			Run:      dataQualityService.GenerateScheduledReport,
+		})
+	}
+	if dataLakeExportService != nil {
+		a.scheduler.AddJob(scheduler.Job{
+			Name:     "data-lake-export",
+			Interval: a.config.DataLakeExportInterval,
+			Run: func(ctx context.Context) error {
+				_, err := dataLakeExportService.Export(ctx)
+				return err
+			},
+		})
+	}
+	if edgeSyncService != nil {
+		// Cada pasarela sincroniza su propia cola, así que el bloqueo es por instancia
+		a.scheduler.AddJob(scheduler.Job{
+			Name:     "edge-sync-" + a.config.InstanceID,
+			Interval: a.config.EdgeSyncInterval,
+			Run:      edgeSyncService.Forward,
+		})
+	}
+	for _, connector := range erpConfig.Connectors {
+		a.scheduler.AddJob(scheduler.Job{
+			Name:     "erp-sync-" + connector.Name,
+			Interval: connector.Interval,
+			Run: func(ctx context.Context) error {
+				_, err := inventorySyncService.Sync(ctx, connector.Name)
+				return err
+			},
+		})
+	}
+	if a.config.SNMPFile != "" {
+		a.setupSNMP(ingestTankService)
+	}
+	if a.config.ATGFile != "" {
+		a.setupATG(ingestTankService, mutingNotifier)
+	}
+	if a.config.MQTTIngestTopic != "" {
+		a.setupMQTTIngest(ingestTankService)
+	}
 
 	// Creamos los handlers (adaptadores de entrada)
-	tankHandler := handlers.NewTankHandler(tankService, a.logger)
+	tankHandler := handlers.NewTankHandler(trendTankService, a.logger)
+	tankImportHandler := handlers.NewTankImportHandler(tankImportService, a.logger)
+	measurementHandler := handlers.NewMeasurementHandler(measurementService, a.logger)
+	reorderHandler := handlers.NewReorderHandler(reorderService, a.logger)
+	deliveryHandler := handlers.NewDeliveryHandler(deliveryService, a.logger)
+	statusHistoryHandler := handlers.NewStatusHistoryHandler(statusHistoryService, a.logger)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, a.logger)
+	digestHandler := handlers.NewDigestHandler(digestService, a.logger)
+	alertRuleHandler := handlers.NewAlertRuleHandler(alertRuleService, a.logger)
+	accessHandler := handlers.NewAccessHandler(accessService, a.logger)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, a.config.AttachmentMaxSize, a.logger)
+	deviceHandler := handlers.NewDeviceHandler(deviceService, a.logger)
+	noteHandler := handlers.NewNoteHandler(noteService, a.logger)
+	kpiHandler := handlers.NewKPIHandler(kpiService, a.logger)
+	anomalyHandler := handlers.NewAnomalyHandler(anomalyService, a.logger)
+	sensorHandler := handlers.NewSensorHandler(sensorHealthService, clockSkewService, a.logger)
+	fieldDeviceHandler := handlers.NewFieldDeviceHandler(fieldDeviceService, a.logger)
+	commandHandler := handlers.NewDeviceCommandHandler(commandService, a.logger)
+	pollHandler := handlers.NewTankPollHandler(pollService, a.logger)
+	provisioningHandler := handlers.NewProvisioningHandler(provisioningService, a.logger)
+	searchHandler := handlers.NewSearchHandler(searchService, a.logger)
+	voiceHandler := handlers.NewVoiceHandler(voiceService, a.logger)
+	compartmentHandler := handlers.NewCompartmentHandler(compartmentService, a.logger)
+	mobileOverviewHandler := handlers.NewMobileOverviewHandler(mobileOverviewService, a.logger)
+	alertMuteHandler := handlers.NewAlertMuteHandler(alertMuteService, a.logger)
+	tankChangeHandler := handlers.NewTankChangeHandler(tankChangeService, a.logger)
+	liquidPolicyHandler := handlers.NewLiquidPolicyHandler(liquidPolicyService, a.logger)
+	reportHandler := handlers.NewReportHandler(dataQualityService, shiftReportService, a.logger)
+	alertEvidenceHandler := handlers.NewAlertEvidenceHandler(alertEvidenceService, a.logger)
+	inventorySyncHandler := handlers.NewInventorySyncHandler(inventorySyncService, a.logger)
 
 	// Registramos las rutas
 	tankHandler.RegisterRoutes(a.router)
+	tankImportHandler.RegisterRoutes(a.router)
+	measurementHandler.RegisterRoutes(a.router)
+	reorderHandler.RegisterRoutes(a.router)
+	deliveryHandler.RegisterRoutes(a.router)
+	statusHistoryHandler.RegisterRoutes(a.router)
+	notificationHandler.RegisterRoutes(a.router)
+	digestHandler.RegisterRoutes(a.router)
+	alertRuleHandler.RegisterRoutes(a.router)
+	accessHandler.RegisterRoutes(a.router)
+	attachmentHandler.RegisterRoutes(a.router)
+	deviceHandler.RegisterRoutes(a.router)
+	noteHandler.RegisterRoutes(a.router)
+	kpiHandler.RegisterRoutes(a.router)
+	anomalyHandler.RegisterRoutes(a.router)
+	sensorHandler.RegisterRoutes(a.router)
+	fieldDeviceHandler.RegisterRoutes(a.router)
+	commandHandler.RegisterRoutes(a.router)
+	pollHandler.RegisterRoutes(a.router)
+	provisioningHandler.RegisterRoutes(a.router)
+	searchHandler.RegisterRoutes(a.router)
+	voiceHandler.RegisterRoutes(a.router)
+	compartmentHandler.RegisterRoutes(a.router)
+	mobileOverviewHandler.RegisterRoutes(a.router)
+	alertMuteHandler.RegisterRoutes(a.router)
+	tankChangeHandler.RegisterRoutes(a.router)
+	liquidPolicyHandler.RegisterRoutes(a.router)
+	reportHandler.RegisterRoutes(a.router)
+	alertEvidenceHandler.RegisterRoutes(a.router)
+	inventorySyncHandler.RegisterRoutes(a.router)
+	handlers.NewSyncHandler(tankSyncService, a.logger).RegisterRoutes(a.router)
+	if dataLakeExportService != nil {
+		handlers.NewDataLakeHandler(dataLakeExportService, a.logger).RegisterRoutes(a.router)
+	}
+	if edgeSyncService != nil {
+		handlers.NewEdgeSyncHandler(edgeSyncService, a.logger).RegisterRoutes(a.router)
+	}
+	handlers.NewSavedViewHandler(savedViewService, a.logger).RegisterRoutes(a.router)
+	handlers.NewSignedURLHandler(signedURLService, a.logger).RegisterRoutes(a.router)
+	handlers.NewRecomputeHandler(recomputeService, a.logger).RegisterRoutes(a.router)
+	handlers.NewPurgeHandler(purgeService, a.logger).RegisterRoutes(a.router)
+	handlers.NewRuntimeConfigHandler(a.runtimeConfig, a.logger).RegisterRoutes(a.router)
+	handlers.NewRepositoryStatsHandler(
+		services.NewRepositoryStatsService(repos.tanks, repos.measurementStreamer, repos.inspector, a.repositoryBackendInfo()),
+		a.logger,
+	).RegisterRoutes(a.router)
+	if a.config.UsageMeteringEnabled {
+		handlers.NewUsageHandler(usageService, a.logger).RegisterRoutes(a.router)
+	}
+	if a.config.PublicStatusEnabled {
+		handlers.NewStatusShareHandler(statusShareService, a.logger).RegisterRoutes(a.router)
+	}
+
+	// Las plataformas IoT de terceros envían sus lecturas por webhook según el mapeo configurado
+	if a.config.InboundWebhooksFile != "" {
+		mappings, err := webhooks.LoadFile(a.config.InboundWebhooksFile)
+		if err != nil {
+			a.logger.Fatal("Invalid inbound webhooks file", "path", a.config.InboundWebhooksFile, "error", err)
+		}
+		handlers.NewInboundWebhookHandler(authorizedTankService, mappings, a.logger).RegisterRoutes(a.router)
+		a.logger.Info("Inbound webhooks enabled", "path", a.config.InboundWebhooksFile, "sources", len(mappings))
+	}
+
+	// Los equipos propios envían sus lecturas en las versiones de carga que admite su perfil
+	if a.config.DeviceProfilesFile != "" {
+		profiles, err := deviceprofiles.LoadFile(a.config.DeviceProfilesFile)
+		if err != nil {
+			a.logger.Fatal("Invalid device profiles file", "path", a.config.DeviceProfilesFile, "error", err)
+		}
+		handlers.NewDeviceIngestHandler(authorizedTankService, profiles, a.logger).RegisterRoutes(a.router)
+		a.logger.Info("Device ingestion enabled", "path", a.config.DeviceProfilesFile, "profiles", len(profiles))
+	}
+
+	// Los medidores Sigfox llegan por los callbacks del backend de Sigfox
+	if a.config.SigfoxDevicesFile != "" {
+		sigfoxConfig, err := sigfox.LoadFile(a.config.SigfoxDevicesFile)
+		if err != nil {
+			a.logger.Fatal("Invalid Sigfox devices file", "path", a.config.SigfoxDevicesFile, "error", err)
+		}
+		deduplicator := sigfox.NewDeduplicator(a.config.SigfoxDedupWindow)
+		handlers.NewSigfoxHandler(authorizedTankService, sigfoxConfig, deduplicator, a.logger).RegisterRoutes(a.router)
+		a.logger.Info("Sigfox callbacks enabled", "path", a.config.SigfoxDevicesFile, "devices", len(sigfoxConfig.Devices))
+	}
+
+	// Las series sintéticas solo se inyectan en staging y test: en producción falsearían los datos
+	if testingEnvironments[a.config.Environment] {
+		handlers.NewSimulationHandler(services.NewSimulationService(authorizedTankService), a.logger).RegisterRoutes(a.router)
+	}
+
+	if a.config.ProfilingEnabled {
+		if a.config.AuthMode != authModeOIDC && a.config.AuthMode != authModeToken {
+			a.logger.Warn("Profiling endpoints enabled without authentication")
+		}
+		handlers.NewProfilingHandler().RegisterRoutes(a.router)
+	}
 
-	// Añadimos middleware para logging
+	// Añadimos middleware para logging, para limitar la duración y el tamaño de las solicitudes
+	// y para comprimir las respuestas
 	a.router.Use(a.loggingMiddleware)
+	a.router.Use(a.limitsMiddleware)
+	a.router.Use(a.compressionMiddleware)
+
+	// Los enlaces firmados identifican al principal antes de la autenticación, que entonces no
+	// exige token
+	a.router.Use(auth.SignedURLMiddleware(urlSigner, a.logger))
+
+	// Los equipos de campo que se conectan a la escucha mTLS se identifican por su certificado
+	if a.config.DeviceTLSAddr != "" {
+		a.router.Use(auth.ClientCertMiddleware(repos.fieldDevices, isIngestRoute, a.logger))
+		a.deviceServer = a.newDeviceServer()
+	}
+
+	// Configuramos la autenticación; las conexiones en tiempo real se autentican al abrirse
+	authenticator := a.setupAuth(repos)
+	liveWebSocketServer := websocket.NewServer(liveHub, websocket.ServerConfig{
+		MaxMessageSize: liveMaxMessageSize,
+		PingInterval:   a.config.LivePingInterval,
+		WriteTimeout:   a.config.WriteTimeout,
+	}, a.logger)
+	liveSSEServer := sse.NewServer(liveHub, sse.ServerConfig{
+		KeepAliveInterval: a.config.LivePingInterval,
+		WriteTimeout:      a.config.WriteTimeout,
+	}, a.logger)
+	handlers.NewLiveHandler(liveWebSocketServer, liveSSEServer, authenticator, a.logger).RegisterRoutes(a.router)
+
+	// Las solicitudes se cuentan después de autenticarlas, para atribuirlas a la organización del
+	// usuario, y antes de la caché, porque las respuestas cacheadas también se facturan
+	if a.config.UsageMeteringEnabled {
+		a.router.Use(a.usageMiddleware(usageService))
+	}
+
+	// La caché va después de la autenticación porque sus claves dependen del usuario
+	a.router.Use(a.cacheMiddleware)
 
 	// Ruta de comprobación de estado
 	a.router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	}).Methods(http.MethodGet)
+
+	if a.metrics != nil {
+		a.router.Handle("/metrics", a.metrics.Handler()).Methods(http.MethodGet)
+	}
+}
+
+// setupAuth configura la autenticación según el modo elegido y devuelve el autenticador, o nil
+// sin autenticación. Los tokens de la API se admiten en los modos oidc y token.
+func (a *API) setupAuth(repos *repositorySet) ports.Authenticator {
+	var authenticator ports.Authenticator
+	var protection *auth.LoginProtection
+	var sessionService ports.SessionService
+	if a.config.AuthMode == authModeOIDC || a.config.AuthMode == authModeToken {
+		protection = a.newLoginProtection(repos.securityEvents)
+
+		var err error
+		sessionService, err = services.NewSessionService(domain.SessionConfig{
+			AccessTTL:  a.config.SessionAccessTTL,
+			RefreshTTL: a.config.SessionRefreshTTL,
+		}, repos.sessions)
+		if err != nil {
+			a.logger.Fatal("Invalid session config", "error", err)
+		}
+		handlers.NewSessionHandler(sessionService, protection, a.logger).RegisterRoutes(a.router)
+		handlers.NewAPITokenHandler(services.NewAPITokenService(repos.apiTokens), a.logger).RegisterRoutes(a.router)
+	}
+
+	switch a.config.AuthMode {
+	case authModeOIDC:
+		roleMapping, err := auth.ParseRoleMapping(a.config.OIDCRoleMapping)
+		if err != nil {
+			a.logger.Fatal("Invalid OIDC role mapping", "error", err)
+		}
+
+		provider := auth.NewOIDCProvider(auth.OIDCConfig{
+			IssuerURL:    a.config.OIDCIssuerURL,
+			ClientID:     a.config.OIDCClientID,
+			ClientSecret: a.config.OIDCClientSecret,
+			RedirectURL:  a.config.OIDCRedirectURL,
+			GroupsClaim:  a.config.OIDCGroupsClaim,
+			RoleMapping:  roleMapping,
+
+			OrganizationClaim: a.config.OIDCOrgClaim,
+		})
+
+		handlers.NewOIDCHandler(provider, sessionService, protection, a.logger).RegisterRoutes(a.router)
+		authenticator = auth.NewAPITokenAuthenticator(repos.apiTokens, auth.NewSessionAuthenticator(sessionService, provider))
+		a.logger.Info("OIDC authentication enabled", "issuer", a.config.OIDCIssuerURL)
+	case authModeToken:
+		authenticator = auth.NewAPITokenAuthenticator(repos.apiTokens, auth.NewSessionAuthenticator(sessionService, nil))
+		a.logger.Info("API token authentication enabled")
+	default:
+		a.logger.Warn("Authentication disabled", "auth_mode", a.config.AuthMode)
+		return nil
+	}
+
+	publicPaths := []string{"/health", "/metrics", "/api/auth/oidc/", handlers.RefreshPath, "/api/ingest/webhooks/", "/api/ingest/devices/", "/api/ingest/sigfox", handlers.PublicStatusPrefix, handlers.LivePath}
+	a.router.Use(auth.Middleware(authenticator, publicPaths, protection, a.logger))
+	return authenticator
+}
+
+// newLoginProtection crea la protección frente a fuerza bruta y registra la consulta del registro
+// de auditoría de los accesos
+func (a *API) newLoginProtection(securityEvents ports.SecurityEventRepository) *auth.LoginProtection {
+	var captcha ports.CaptchaVerifier
+	if a.config.CaptchaVerifyURL != "" {
+		if a.config.CaptchaSecret == "" {
+			a.logger.Fatal("CAPTCHA_VERIFY_URL requires CAPTCHA_SECRET")
+		}
+		captcha = auth.NewSiteVerifyCaptcha(a.config.CaptchaVerifyURL, a.config.CaptchaSecret)
+	}
+
+	guard, err := services.NewLoginGuard(domain.LoginGuardConfig{
+		MaxFailures:  a.config.LoginMaxFailures,
+		Window:       a.config.LoginFailureWindow,
+		Lockout:      a.config.LoginLockoutDuration,
+		CaptchaAfter: a.config.CaptchaAfterFailures,
+	}, securityEvents, captcha)
+	if err != nil {
+		a.logger.Fatal("Invalid login protection config", "error", err)
+	}
+
+	handlers.NewSecurityEventHandler(guard, a.logger).RegisterRoutes(a.router)
+	return &auth.LoginProtection{Guard: guard, TrustForwardedFor: a.config.LoginTrustForwardedFor}
+}
+
+// liveMaxMessageSize limita los mensajes de suscripción de los clientes en tiempo real
+const liveMaxMessageSize = 16 << 10
+
+// outboxBatchSize es el número máximo de eventos de la bandeja de salida entregados en cada pasada
+const outboxBatchSize = 100
+
+// signableExportPaths son las descargas que se pueden compartir con un enlace firmado
+var signableExportPaths = []string{
+	"/api/tanks/{id}/measurements",
+	"/api/tanks/{id}/status-history",
+	"/api/tanks/{id}/attachments/{attachmentId}",
+	"/api/reports/data-quality",
+	"/api/admin/usage/export",
+}
+
+// signedURLKey devuelve la clave de los enlaces firmados, o una aleatoria si no está configurada
+func (a *API) signedURLKey() []byte {
+	if a.config.SignedURLSecret != "" {
+		return []byte(a.config.SignedURLSecret)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		a.logger.Fatal("Failed to generate signed URL key", "error", err)
+	}
+	a.logger.Warn("SIGNED_URL_SECRET not set, signed URLs only work on this instance until it restarts")
+	return key
+}
+
+// setupSNMP programa el sondeo de los agentes SNMP y prepara la recepción de sus traps
+func (a *API) setupSNMP(tankService ports.TankService) {
+	config, err := snmp.LoadFile(a.config.SNMPFile)
+	if err != nil {
+		a.logger.Fatal("Invalid SNMP file", "path", a.config.SNMPFile, "error", err)
+	}
+
+	collector := snmp.NewCollector(tankService, config, snmp.NewClient(a.config.SNMPTimeout), a.logger)
+	for _, target := range config.Targets {
+		a.scheduler.AddJob(scheduler.Job{
+			Name:     "snmp-poll-" + target.Name,
+			Interval: target.Interval,
+			Run: func(ctx context.Context) error {
+				return collector.Poll(ctx, target)
+			},
+		})
+	}
+
+	if a.config.SNMPTrapAddr != "" {
+		a.snmpCollector = collector
+	}
+	a.logger.Info("SNMP enabled", "path", a.config.SNMPFile, "targets", len(config.Targets), "traps", a.config.SNMPTrapAddr)
+}
+
+// setupATG programa la consulta periódica de las consolas ATG
+func (a *API) setupATG(tankService ports.TankService, notifier ports.AlertNotifier) {
+	config, err := atg.LoadFile(a.config.ATGFile)
+	if err != nil {
+		a.logger.Fatal("Invalid ATG file", "path", a.config.ATGFile, "error", err)
+	}
+
+	collector := atg.NewCollector(tankService, notifier, atg.NewClient(a.config.ATGTimeout), a.logger)
+	for _, console := range config.Consoles {
+		a.scheduler.AddJob(scheduler.Job{
+			Name:     "atg-poll-" + console.Name,
+			Interval: console.Interval,
+			Run: func(ctx context.Context) error {
+				return collector.Poll(ctx, console)
+			},
+		})
+	}
+	a.logger.Info("ATG consoles enabled", "path", a.config.ATGFile, "consoles", len(config.Consoles))
+}
+
+// loadERPConfig lee las conexiones a los ERP; sin archivo no hay ninguna
+func (a *API) loadERPConfig() *erp.Config {
+	if a.config.ERPFile == "" {
+		return &erp.Config{}
+	}
+
+	config, err := erp.LoadFile(a.config.ERPFile)
+	if err != nil {
+		a.logger.Fatal("Invalid ERP file", "path", a.config.ERPFile, "error", err)
+	}
+	a.logger.Info("ERP inventory sync enabled", "path", a.config.ERPFile, "connectors", len(config.Connectors))
+	return config
+}
+
+// erpExporters crea los adaptadores de entrega de las conexiones a los ERP
+func erpExporters(config *erp.Config) []ports.InventoryExporter {
+	exporters := make([]ports.InventoryExporter, len(config.Connectors))
+	for i, connector := range config.Connectors {
+		exporters[i] = erp.NewExporter(connector)
+	}
+	return exporters
+}
+
+// newLocker crea el bloqueo distribuido configurado para coordinar las réplicas
+func (a *API) newLocker() ports.Locker {
+	switch a.config.LockBackend {
+	case "redis":
+		a.logger.Info("Using Redis distributed locks", "addr", a.config.RedisAddr, "instance", a.config.InstanceID)
+		return locks.NewRedisLocker(a.config.RedisAddr, a.config.RedisPassword, a.config.InstanceID)
+	default:
+		return locks.NewMemoryLocker()
+	}
+}
+
+// newFileStorage crea el almacenamiento configurado para el contenido de los adjuntos
+func (a *API) newFileStorage() ports.FileStorage {
+	switch a.config.AttachmentStorage {
+	case "s3":
+		a.logger.Info("Using S3 attachment storage", "endpoint", a.config.S3Endpoint, "bucket", a.config.S3Bucket)
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:        a.config.S3Endpoint,
+			Region:          a.config.S3Region,
+			Bucket:          a.config.S3Bucket,
+			AccessKeyID:     a.config.S3AccessKeyID,
+			SecretAccessKey: a.config.S3SecretAccessKey,
+		})
+	default:
+		return storage.NewLocalStorage(a.config.AttachmentDir)
+	}
+}
+
+// newPushSender crea el emisor de notificaciones push con las credenciales configuradas
+func (a *API) newPushSender() ports.PushSender {
+	sender, err := notifiers.NewPushSender(notifiers.PushConfig{
+		FCMCredentialsFile: a.config.FCMCredentialsFile,
+		APNsKeyFile:        a.config.APNsKeyFile,
+		APNsKeyID:          a.config.APNsKeyID,
+		APNsTeamID:         a.config.APNsTeamID,
+		APNsTopic:          a.config.APNsTopic,
+		APNsSandbox:        a.config.APNsSandbox,
+	})
+	if err != nil {
+		a.logger.Fatal("Invalid push notification credentials", "error", err)
+	}
+	return sender
+}
+
+// newEmailSender crea el emisor de correo de los informes, o nil si no hay servidor SMTP configurado
+func (a *API) newEmailSender() ports.EmailSender {
+	if a.config.SMTPAddr == "" {
+		return nil
+	}
+
+	sender, err := notifiers.NewEmailSender(notifiers.SMTPConfig{
+		Addr:     a.config.SMTPAddr,
+		Username: a.config.SMTPUsername,
+		Password: a.config.SMTPPassword,
+		From:     a.config.SMTPFrom,
+	})
+	if err != nil {
+		a.logger.Fatal("Invalid SMTP configuration", "error", err)
+	}
+	return sender
+}
+
+// edgeGatewayID devuelve el identificador de la pasarela: EDGE_GATEWAY_ID o, si falta, INSTANCE_ID
+func (a *API) edgeGatewayID() string {
+	if a.config.EdgeGatewayID != "" {
+		return a.config.EdgeGatewayID
+	}
+	return a.config.InstanceID
+}
+
+// newDataLakeExportService crea la exportación de mediciones al data lake, o nil si no hay
+// almacenamiento configurado
+func (a *API) newDataLakeExportService(tankRepo ports.TankRepository, streamer ports.MeasurementStreamer) ports.DataLakeExportService {
+	var fileStorage ports.FileStorage
+	switch a.config.DataLakeStorage {
+	case "":
+		return nil
+	case "s3":
+		if a.config.DataLakeBucket == "" {
+			a.logger.Fatal("DATA_LAKE_STORAGE=s3 requires DATA_LAKE_BUCKET")
+		}
+		endpoint := a.config.DataLakeEndpoint
+		if endpoint == "" {
+			endpoint = a.config.S3Endpoint
+		}
+		a.logger.Info("Exporting measurements to S3 data lake", "endpoint", endpoint, "bucket", a.config.DataLakeBucket, "prefix", a.config.DataLakePrefix)
+		fileStorage = storage.NewS3Storage(storage.S3Config{
+			Endpoint:        endpoint,
+			Region:          a.config.S3Region,
+			Bucket:          a.config.DataLakeBucket,
+			AccessKeyID:     a.config.S3AccessKeyID,
+			SecretAccessKey: a.config.S3SecretAccessKey,
+		})
+	case "local":
+		a.logger.Info("Exporting measurements to local data lake", "dir", a.config.DataLakeDir, "prefix", a.config.DataLakePrefix)
+		fileStorage = storage.NewLocalStorage(a.config.DataLakeDir)
+	default:
+		a.logger.Fatal("Invalid data lake storage", "storage", a.config.DataLakeStorage)
+	}
+
+	writer := datalake.NewWriter(fileStorage, a.config.DataLakePrefix)
+	return services.NewDataLakeExportService(tankRepo, streamer, writer, a.config.DataLakeExportDelay)
+}
+
+// newEdgeSyncQueue devuelve la cola de mediciones de la pasarela: el diario de EdgeQueuePath, que
+// confirma cada medición en el disco, o si falta la del backend de repositorios
+func (a *API) newEdgeSyncQueue(backend ports.SyncQueueRepository) ports.SyncQueueRepository {
+	if a.config.EdgeQueuePath == "" {
+		if a.memoryStore == nil {
+			a.logger.Warn("Edge sync queue is not persisted, set EDGE_QUEUE_PATH to keep it across restarts")
+		}
+		return backend
+	}
+
+	queue, err := repositories.OpenFileSyncQueueRepository(a.config.EdgeQueuePath)
+	if err != nil {
+		a.logger.Fatal("Invalid edge sync queue", "path", a.config.EdgeQueuePath, "error", err)
+	}
+	queued, err := queue.CountQueuedMeasurements(context.Background())
+	if err != nil {
+		a.logger.Fatal("Invalid edge sync queue", "path", a.config.EdgeQueuePath, "error", err)
+	}
+	a.logger.Info("Using edge sync queue journal", "path", a.config.EdgeQueuePath, "queued", queued)
+	a.edgeQueue = queue
+	return queue
+}
+
+// newEdgeSyncService crea la sincronización de una pasarela edge con la instancia central
+func (a *API) newEdgeSyncService(queue ports.SyncQueueRepository, states ports.TankSyncRepository, tanks ports.TankService) ports.EdgeSyncService {
+	if a.config.EdgeUpstreamURL == "" {
+		a.logger.Fatal("RUN_MODE=edge requires EDGE_UPSTREAM_URL")
+	}
+	gatewayID := a.edgeGatewayID()
+	a.logger.Info("Running as edge gateway", "gateway_id", gatewayID, "upstream", a.config.EdgeUpstreamURL)
+	client := edgesync.NewClient(a.config.EdgeUpstreamURL, a.config.EdgeUpstreamToken, a.config.EdgeSyncTimeout)
+	return services.NewEdgeSyncService(queue, states, tanks, client, gatewayID, a.config.EdgeUpstreamURL, a.config.EdgeSyncBatchSize)
+}
+
+// newCommandPublisher crea el publicador MQTT de comandos, o nil si no hay broker configurado
+func (a *API) newCommandPublisher() ports.CommandPublisher {
+	if a.config.MQTTBrokerAddr == "" {
+		a.logger.Warn("Device commands disabled, no MQTT broker configured")
+		return nil
+	}
+
+	a.logger.Info("Using MQTT downlink", "broker", a.config.MQTTBrokerAddr, "topic", a.config.MQTTCommandTopic)
+	return mqtt.NewCommandPublisher(mqtt.Config{
+		BrokerAddr:   a.config.MQTTBrokerAddr,
+		Username:     a.config.MQTTUsername,
+		Password:     a.config.MQTTPassword,
+		ClientID:     "monitor-tanques-" + a.config.InstanceID,
+		CommandTopic: a.config.MQTTCommandTopic,
+	})
+}
+
+// setupMQTTIngest prepara la recepción de las mediciones publicadas por MQTT
+func (a *API) setupMQTTIngest(tankService ports.TankService) {
+	if a.config.MQTTBrokerAddr == "" {
+		a.logger.Fatal("MQTT_INGEST_TOPIC requires MQTT_BROKER_ADDR")
+	}
+	if a.config.MQTTIngestBufferPath == "" {
+		a.logger.Fatal("MQTT_INGEST_TOPIC requires MQTT_INGEST_BUFFER_PATH")
+	}
+
+	buffer, err := repositories.OpenFileSyncQueueRepository(a.config.MQTTIngestBufferPath)
+	if err != nil {
+		a.logger.Fatal("Invalid MQTT ingest buffer", "path", a.config.MQTTIngestBufferPath, "error", err)
+	}
+	subscriber, err := mqtt.NewSubscriber(mqtt.Config{
+		BrokerAddr: a.config.MQTTBrokerAddr,
+		Username:   a.config.MQTTUsername,
+		Password:   a.config.MQTTPassword,
+		// La sesión persistente se identifica por este ID, distinto al de los otros clientes
+		ClientID: "monitor-tanques-" + a.config.InstanceID + "-ingest",
+	}, a.config.MQTTIngestTopic, tankService, buffer, a.logger)
+	if err != nil {
+		a.logger.Fatal("Invalid MQTT ingest topic", "topic", a.config.MQTTIngestTopic, "error", err)
+	}
+
+	a.mqttSubscriber = subscriber
+	a.onShutdown("close-mqtt-buffer", func(context.Context) error { return buffer.Close() })
+	a.logger.Info("MQTT ingest enabled", "broker", a.config.MQTTBrokerAddr, "topic", a.config.MQTTIngestTopic, "buffer", a.config.MQTTIngestBufferPath)
+}
+
+// newHomeAssistantPublisher crea el publicador del descubrimiento de Home Assistant, o nil si está
+// desactivado
+func (a *API) newHomeAssistantPublisher() *mqtt.HomeAssistantPublisher {
+	if !a.config.HomeAssistantEnabled {
+		return nil
+	}
+	if a.config.MQTTBrokerAddr == "" {
+		a.logger.Fatal("HOMEASSISTANT_ENABLED requires MQTT_BROKER_ADDR")
+	}
+
+	a.logger.Info("Using Home Assistant MQTT discovery", "broker", a.config.MQTTBrokerAddr, "prefix", a.config.HomeAssistantDiscoveryPrefix)
+	return mqtt.NewHomeAssistantPublisher(mqtt.Config{
+		BrokerAddr: a.config.MQTTBrokerAddr,
+		Username:   a.config.MQTTUsername,
+		Password:   a.config.MQTTPassword,
+		// Un ID distinto al de los comandos: el broker cierra la sesión anterior de un mismo ID
+		ClientID: "monitor-tanques-" + a.config.InstanceID + "-ha",
+	}, mqtt.HomeAssistantConfig{
+		DiscoveryPrefix: a.config.HomeAssistantDiscoveryPrefix,
+		StateTopic:      a.config.HomeAssistantStateTopic,
+	}, a.logger)
 }
 
 // loggingMiddleware registra información sobre cada solicitud HTTP
@@ -109,46 +1495,126 @@ func (a *API) loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// Start inicia el servidor HTTP
+// Start inicia el servidor HTTP y las tareas en segundo plano hasta recibir SIGINT o SIGTERM
 func (a *API) Start() error {
-	// Configurar manejo de interrupciones para cierre graceful
-	idleConnsClosed := make(chan struct{})
-	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
-		<-sigint
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return a.Run(ctx)
+}
+
+// Run sirve la API hasta que se cancele ctx o falle algún componente. Al terminar detiene el
+// servidor HTTP (esperando las solicitudes en curso) y las tareas en segundo plano, y después
+// ejecuta los pasos de cierre: guardar las mediciones del búfer, entregar las alertas en cola
+// que ya correspondan y guardar la instantánea de los repositorios en memoria.
+func (a *API) Run(ctx context.Context) error {
+	group, groupCtx := newWorkerGroup(ctx)
 
+	group.Go(func() error {
+		a.logger.Info("Servidor iniciado", "port", a.config.Port)
+		if err := a.server.ListenAndServe(); err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	})
+
+	group.Go(func() error {
+		<-groupCtx.Done()
 		a.logger.Info("Recibida señal para apagar el servidor, cerrando conexiones...")
 
-		// Creamos un contexto con timeout para el cierre
-		ctx, cancel := context.WithTimeout(context.Background(), a.config.ShutdownTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), a.config.ShutdownTimeout)
 		defer cancel()
-
-		if err := a.server.Shutdown(ctx); err != nil {
-			a.logger.Error("Error al cerrar el servidor:", "error", err)
+		err := a.server.Shutdown(shutdownCtx)
+		if a.deviceServer != nil {
+			err = errors.Join(err, a.deviceServer.Shutdown(shutdownCtx))
 		}
+		return err
+	})
 
-		close(idleConnsClosed)
-	}()
+	if a.deviceServer != nil {
+		group.Go(func() error {
+			a.logger.Info("Device mTLS listener started", "addr", a.config.DeviceTLSAddr)
+			if err := a.deviceServer.ListenAndServeTLS(a.config.DeviceTLSCertFile, a.config.DeviceTLSKeyFile); err != http.ErrServerClosed {
+				return err
+			}
+			return nil
+		})
+	}
 
-	a.logger.Info("Servidor iniciado", "port", a.config.Port)
+	if a.scheduler != nil {
+		group.Go(func() error {
+			a.scheduler.Start(groupCtx)
+			<-groupCtx.Done()
+			a.scheduler.Wait()
+			return nil
+		})
+	}
 
-	if err := a.server.ListenAndServe(); err != http.ErrServerClosed {
-		return err
+	if a.batchWriter != nil {
+		a.batchWriter.Start(groupCtx)
 	}
 
-	<-idleConnsClosed
-	a.logger.Info("Servidor apagado correctamente")
-	return nil
-}
+	if a.homeAssistant != nil {
+		group.Go(func() error {
+			// Al arrancar se anuncian todos los tanques; después, solo los que cambian
+			if err := a.tankStatePublication.PublishAllTanks(groupCtx); err != nil {
+				a.logger.Warn("Failed to publish tanks to Home Assistant", "error", err)
+			}
+			a.homeAssistant.Run(groupCtx)
+			return nil
+		})
+	}
 
-// mockAlertNotifier es una implementación simple del puerto AlertNotifier para desarrollo
-type mockAlertNotifier struct {
-	logger logger.Logger
-}
+	if a.mqttSubscriber != nil {
+		group.Go(func() error {
+			a.mqttSubscriber.Run(groupCtx)
+			return nil
+		})
+	}
 
-// SendAlert envía una alerta (en este caso, solo la registra)
-func (n *mockAlertNotifier) SendAlert(ctx context.Context, tankID string, message string) error {
-	n.logger.Warn("ALERTA", "tank_id", tankID, "message", message)
+	if a.snmpCollector != nil {
+		group.Go(func() error {
+			return a.snmpCollector.ListenTraps(groupCtx, a.config.SNMPTrapAddr)
+		})
+	}
+
+	// SIGHUP recarga la configuración sin cerrar las conexiones abiertas
+	if a.runtimeConfig != nil {
+		hangups := make(chan os.Signal, 1)
+		signal.Notify(hangups, syscall.SIGHUP)
+		group.Go(func() error {
+			defer signal.Stop(hangups)
+			for {
+				select {
+				case <-groupCtx.Done():
+					return nil
+				case <-hangups:
+					a.reloadRuntimeConfig(groupCtx)
+				}
+			}
+		})
+	}
+
+	err := group.Wait()
+	if err != nil {
+		a.logger.Error("Error al ejecutar el servidor", "error", err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.config.ShutdownTimeout)
+	defer cancel()
+
+	for _, hook := range a.shutdownHooks {
+		if hookErr := hook.run(shutdownCtx); hookErr != nil {
+			a.logger.Error("Shutdown step failed", "step", hook.name, "error", hookErr)
+			continue
+		}
+		a.logger.Debug("Shutdown step completed", "step", hook.name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	a.logger.Info("Servidor apagado correctamente")
 	return nil
 }
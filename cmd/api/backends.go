@@ -0,0 +1,261 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"monitor-tanques/internal/adapters/notifiers"
+	"monitor-tanques/internal/adapters/provisioning"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/adapters/secrets"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// Backends de persistencia reconocidos en REPOSITORY_BACKEND
+const (
+	repositoryBackendMemory   = "memory"
+	repositoryBackendSQLite   = "sqlite"
+	repositoryBackendPostgres = "postgres"
+	repositoryBackendMongo    = "mongo"
+)
+
+// repositorySet agrupa los adaptadores de persistencia de todos los puertos
+type repositorySet struct {
+	tanks               ports.TankRepository
+	measurements        ports.MeasurementRepository
+	measurementStreamer ports.MeasurementStreamer
+	statusChanges       ports.StatusHistoryRepository
+	statusRewriter      ports.StatusHistoryRewriter
+	unitOfWork          ports.UnitOfWork
+	suppliers           ports.SupplierRepository
+	deliveryOrders      ports.DeliveryOrderRepository
+	channels            ports.NotificationChannelRepository
+	alertQueue          ports.AlertQueueRepository
+	accessGrants        ports.AccessGrantRepository
+	attachments         ports.AttachmentRepository
+	mobileDevices       ports.MobileDeviceRepository
+	notes               ports.TankNoteRepository
+	anomalies           ports.AnomalyRepository
+	fieldDevices        ports.FieldDeviceRepository
+	commands            ports.DeviceCommandRepository
+	alertMutes          ports.AlertMuteRepository
+	usage               ports.UsageRepository
+	statusShares        ports.StatusShareRepository
+	outbox              ports.OutboxRepository
+	liquidPolicies      ports.LiquidPolicyRepository
+	alertEvidence       ports.AlertEvidenceRepository
+	apiTokens           ports.APITokenRepository
+	securityEvents      ports.SecurityEventRepository
+	sessions            ports.SessionRepository
+	tankChanges         ports.TankChangeRepository
+	syncQueue           ports.SyncQueueRepository
+	tankSync            ports.TankSyncRepository
+	savedViews          ports.SavedViewRepository
+	measurementPurger   ports.MeasurementPurger
+	inspector           ports.RepositoryInspector
+	tankPurgers         map[string]ports.TankDataPurger // datos que se vacían al purgar una organización
+}
+
+// newRepositories crea los repositorios del backend configurado en RepositoryBackend
+func (a *API) newRepositories() (*repositorySet, error) {
+	switch a.config.RepositoryBackend {
+	case repositoryBackendMemory, "":
+		store := repositories.NewMemoryStore()
+		if a.config.MemorySnapshotPath == "" {
+			a.logger.Warn("Using in-memory repositories, data is lost on restart", "backend", repositoryBackendMemory)
+			return newMemoryRepositories(store), nil
+		}
+
+		loaded, err := store.LoadSnapshot(context.Background(), a.config.MemorySnapshotPath)
+		if err != nil {
+			return nil, fmt.Errorf("load memory snapshot: %w", err)
+		}
+		a.logger.Info("Using in-memory repositories with snapshots",
+			"backend", repositoryBackendMemory,
+			"path", a.config.MemorySnapshotPath,
+			"interval", a.config.MemorySnapshotInterval,
+			"restored", loaded,
+		)
+		a.memoryStore = store
+		return newMemoryRepositories(store), nil
+	case repositoryBackendSQLite, repositoryBackendPostgres, repositoryBackendMongo:
+		// Aún no hay adaptadores de base de datos; se añadirán en internal/adapters/repositories
+		return nil, fmt.Errorf("repository backend %q is not available in this build", a.config.RepositoryBackend)
+	default:
+		return nil, fmt.Errorf("unknown repository backend %q", a.config.RepositoryBackend)
+	}
+}
+
+// newMemoryRepositories expone los repositorios en memoria del almacén a través de sus puertos
+func newMemoryRepositories(store *repositories.MemoryStore) *repositorySet {
+	return &repositorySet{
+		tanks:               store.Tanks,
+		measurements:        store.Measurements,
+		measurementStreamer: store.Measurements,
+		statusChanges:       store.StatusChanges,
+		statusRewriter:      store.StatusChanges,
+		unitOfWork:          store.UnitOfWork,
+		suppliers:           store.Suppliers,
+		deliveryOrders:      store.DeliveryOrders,
+		channels:            store.Channels,
+		alertQueue:          store.AlertQueue,
+		accessGrants:        store.AccessGrants,
+		attachments:         store.Attachments,
+		mobileDevices:       store.MobileDevices,
+		notes:               store.Notes,
+		anomalies:           store.Anomalies,
+		fieldDevices:        store.FieldDevices,
+		commands:            store.Commands,
+		alertMutes:          store.AlertMutes,
+		usage:               store.Usage,
+		statusShares:        store.StatusShares,
+		outbox:              store.Outbox,
+		liquidPolicies:      store.LiquidPolicies,
+		alertEvidence:       store.AlertEvidence,
+		apiTokens:           store.APITokens,
+		securityEvents:      store.SecurityEvents,
+		sessions:            store.Sessions,
+		tankChanges:         store.TankChanges,
+		syncQueue:           store.SyncQueue,
+		tankSync:            store.TankSync,
+		savedViews:          store.SavedViews,
+		measurementPurger:   store.Measurements,
+		inspector:           store,
+		tankPurgers: map[string]ports.TankDataPurger{
+			"measurements":    store.Measurements,
+			"status_changes":  store.StatusChanges,
+			"notes":           store.Notes,
+			"anomalies":       store.Anomalies,
+			"alert_mutes":     store.AlertMutes,
+			"alert_evidence":  store.AlertEvidence,
+			"field_devices":   store.FieldDevices,
+			"delivery_orders": store.DeliveryOrders,
+			"tank_changes":    store.TankChanges,
+		},
+	}
+}
+
+// repositoryBackendInfo describe el backend de persistencia para el diagnóstico de /api/admin/stats
+func (a *API) repositoryBackendInfo() domain.RepositoryBackendInfo {
+	info := domain.RepositoryBackendInfo{Name: a.config.RepositoryBackend}
+	if info.Name == "" {
+		info.Name = repositoryBackendMemory
+	}
+	if a.memoryStore != nil {
+		info.Persistent = true
+		info.SnapshotPath = a.config.MemorySnapshotPath
+		info.SnapshotInterval = a.config.MemorySnapshotInterval.Seconds()
+	}
+	return info
+}
+
+// saveMemorySnapshot guarda la instantánea de los repositorios en memoria
+func (a *API) saveMemorySnapshot(ctx context.Context) error {
+	if err := a.memoryStore.SaveSnapshot(ctx, a.config.MemorySnapshotPath); err != nil {
+		return err
+	}
+	a.logger.Debug("Memory snapshot saved", "path", a.config.MemorySnapshotPath)
+	return nil
+}
+
+// provision aplica el archivo de aprovisionamiento configurado. Un archivo inválido detiene el
+// arranque: es preferible no servir a hacerlo con una instalación a medio configurar.
+func (a *API) provision(service ports.ProvisioningService) {
+	spec, err := provisioning.LoadFile(a.config.ProvisioningFile)
+	if err != nil {
+		a.logger.Fatal("Invalid provisioning file", "path", a.config.ProvisioningFile, "error", err)
+	}
+
+	result, err := service.Provision(context.Background(), spec)
+	if err != nil {
+		a.logger.Fatal("Provisioning failed", "path", a.config.ProvisioningFile, "error", err)
+	}
+
+	for _, change := range result.Changes {
+		if change.Action != domain.ProvisioningActionUnchanged {
+			a.logger.Info("Provisioned resource", "kind", change.Kind, "id", change.ID, "action", change.Action, "fields", change.Fields)
+		}
+	}
+	a.logger.Info("Provisioning applied",
+		"path", a.config.ProvisioningFile,
+		"created", result.Created,
+		"updated", result.Updated,
+		"unchanged", result.Unchanged,
+	)
+}
+
+// PlanProvisioning escribe en w los cambios que el archivo de aprovisionamiento aplicaría sobre
+// el estado actual, sin aplicarlos. Requiere haber llamado a SetupRoutes.
+func (a *API) PlanProvisioning(w io.Writer) error {
+	if a.config.ProvisioningFile == "" {
+		return errors.New("PROVISIONING_FILE is not set")
+	}
+
+	spec, err := provisioning.LoadFile(a.config.ProvisioningFile)
+	if err != nil {
+		return err
+	}
+
+	result, err := a.provisioningService.Plan(context.Background(), spec)
+	if err != nil {
+		return err
+	}
+
+	return provisioning.WritePlan(w, result)
+}
+
+// newAlertNotifier crea el notificador predeterminado del tipo indicado (AlertNotifier). Se usa
+// cuando no hay canales de notificación dados de alta.
+func (a *API) newAlertNotifier(kind, target string) (ports.AlertNotifier, error) {
+	switch kind {
+	case domain.ChannelTypeLog, "":
+		a.logger.Info("Using default alert notifier", "type", domain.ChannelTypeLog)
+		return &mockAlertNotifier{logger: a.logger}, nil
+	case domain.ChannelTypeWebhook, domain.ChannelTypeSlack:
+		if target == "" {
+			return nil, fmt.Errorf("alert notifier %q requires ALERT_NOTIFIER_TARGET", kind)
+		}
+		// La referencia se comprueba ahora, pero se resuelve en cada entrega para seguir las rotaciones
+		if _, err := secrets.Resolve(context.Background(), a.secrets, target); err != nil {
+			return nil, fmt.Errorf("alert notifier target: %w", err)
+		}
+		a.logger.Info("Using default alert notifier", "type", kind)
+		return &channelAlertNotifier{
+			sender: secrets.NewChannelSender(notifiers.NewChannelSender(nil, nil, a.config.WebhookSigningSecret, a.logger), a.secrets),
+			channel: &domain.NotificationChannel{
+				Name:    "default",
+				Type:    kind,
+				Target:  target,
+				Enabled: true,
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown alert notifier %q", kind)
+	}
+}
+
+// mockAlertNotifier es una implementación simple del puerto AlertNotifier para desarrollo
+type mockAlertNotifier struct {
+	logger logger.Logger
+}
+
+// Notify envía una alerta (en este caso, solo la registra)
+func (n *mockAlertNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	n.logger.Warn("ALERTA", "tank_id", alert.TankID, "type", alert.Type, "severity", alert.Severity, "message", alert.Message)
+	return nil
+}
+
+// channelAlertNotifier entrega todas las alertas por un canal fijo (webhook o Slack)
+type channelAlertNotifier struct {
+	sender  ports.ChannelSender
+	channel *domain.NotificationChannel
+}
+
+// Notify envía la alerta por el canal configurado
+func (n *channelAlertNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	return n.sender.Send(ctx, n.channel, alert)
+}
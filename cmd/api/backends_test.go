@@ -0,0 +1,126 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/pkg/logger"
+)
+
+// newBackendTestAPI crea una API con la configuración por defecto modificada por configure
+func newBackendTestAPI(configure func(*Config)) *API {
+	config := DefaultConfig()
+	if configure != nil {
+		configure(&config)
+	}
+	return NewAPI(config, logger.NewSimpleLogger())
+}
+
+func TestNewRepositories(t *testing.T) {
+	corrupt := filepath.Join(t.TempDir(), "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{no es json"), 0o600); err != nil {
+		t.Fatalf("Error al preparar la instantánea: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		backend      string
+		snapshotPath string
+		wantErr      string // Fragmento del error esperado; vacío si no se espera error
+		wantStore    bool   // Si la API debe conservar el almacén para guardar instantáneas
+	}{
+		{name: "memoria por defecto", backend: ""},
+		{name: "memoria", backend: repositoryBackendMemory},
+		{name: "memoria con instantánea nueva", backend: repositoryBackendMemory, snapshotPath: filepath.Join(t.TempDir(), "snapshot.json"), wantStore: true},
+		{name: "instantánea ilegible", backend: repositoryBackendMemory, snapshotPath: corrupt, wantErr: "load memory snapshot"},
+		{name: "sqlite", backend: repositoryBackendSQLite, wantErr: "not available in this build"},
+		{name: "postgres", backend: repositoryBackendPostgres, wantErr: "not available in this build"},
+		{name: "mongo", backend: repositoryBackendMongo, wantErr: "not available in this build"},
+		{name: "desconocido", backend: "oracle", wantErr: `unknown repository backend "oracle"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			a := newBackendTestAPI(func(config *Config) {
+				config.RepositoryBackend = tt.backend
+				config.MemorySnapshotPath = tt.snapshotPath
+			})
+
+			// Act
+			repos, err := a.newRepositories()
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Se esperaba un error con %q, se obtuvo: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("No se esperaba error: %v", err)
+			}
+			if repos.tanks == nil || repos.measurements == nil || repos.unitOfWork == nil || repos.savedViews == nil {
+				t.Errorf("Faltan repositorios en el conjunto: %+v", repos)
+			}
+			if (a.memoryStore != nil) != tt.wantStore {
+				t.Errorf("Almacén para instantáneas inesperado: %v", a.memoryStore)
+			}
+		})
+	}
+}
+
+func TestNewAlertNotifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		target  string
+		wantErr string // Fragmento del error esperado; vacío si no se espera error
+		channel bool   // Si las alertas se entregan por un canal en lugar de registrarse
+	}{
+		{name: "log por defecto", kind: ""},
+		{name: "log", kind: domain.ChannelTypeLog},
+		{name: "log ignora el destino", kind: domain.ChannelTypeLog, target: "https://hooks.example.com/a"},
+		{name: "webhook", kind: domain.ChannelTypeWebhook, target: "https://hooks.example.com/a", channel: true},
+		{name: "webhook sin destino", kind: domain.ChannelTypeWebhook, wantErr: "requires ALERT_NOTIFIER_TARGET"},
+		{name: "slack", kind: domain.ChannelTypeSlack, target: "https://hooks.slack.com/services/T/B/X", channel: true},
+		{name: "slack sin destino", kind: domain.ChannelTypeSlack, wantErr: "requires ALERT_NOTIFIER_TARGET"},
+		{name: "desconocido", kind: "sms", target: "+34600000000", wantErr: `unknown alert notifier "sms"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			a := newBackendTestAPI(nil)
+
+			// Act
+			notifier, err := a.newAlertNotifier(tt.kind, tt.target)
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Se esperaba un error con %q, se obtuvo: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("No se esperaba error: %v", err)
+			}
+			switch n := notifier.(type) {
+			case *mockAlertNotifier:
+				if tt.channel {
+					t.Errorf("Se esperaba un notificador por canal para %q", tt.kind)
+				}
+			case *channelAlertNotifier:
+				if !tt.channel || n.channel.Type != tt.kind || n.channel.Target != tt.target || !n.channel.Enabled {
+					t.Errorf("Canal inesperado: %+v", n.channel)
+				}
+			default:
+				t.Errorf("Notificador inesperado: %T", notifier)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/adapters/cache"
+	"monitor-tanques/internal/adapters/handlers"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/pkg/i18n"
+)
+
+// cachedRoutes son las consultas costosas cuyas respuestas se guardan en la caché, con la
+// variable de la ruta que identifica el tanque del que dependen. Las que no tienen variable
+// agregan toda la flota y se invalidan con cualquier cambio.
+var cachedRoutes = map[string]string{
+	"/api/tanks/{id}/kpis":                     "id",
+	"/api/tanks/{id}/stats":                    "id",
+	"/api/tanks/{id}/threshold-recommendation": "id",
+	"/api/reorder/suggestions":                 "",
+	"/api/groups/{id}/capacity-plan":           "",
+	"/api/sensors":                             "",
+}
+
+// cacheMiddleware responde las consultas de cachedRoutes desde la caché mientras no venzan ni
+// cambien los datos del tanque. Las respuestas dependen del usuario (concesiones de acceso), de
+// la versión de la API, del idioma y del formato pedido, así que forman parte de la clave. La
+// cabecera X-Cache indica si la respuesta salió de la caché (HIT) o se calculó (MISS).
+func (a *API) cacheMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.responseCache == nil || r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tankVar, cached := cachedRoutes[template]
+		if !cached {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tankID := cache.AllTanks
+		if tankVar != "" {
+			tankID = mux.Vars(r)[tankVar]
+		}
+
+		key := responseCacheKey(r)
+		if response, ok := a.responseCache.Get(key); ok {
+			header := w.Header()
+			for name, values := range response.Header {
+				header[name] = values
+			}
+			header.Set("X-Cache", "HIT")
+			w.WriteHeader(response.Status)
+			w.Write(response.Body)
+			return
+		}
+
+		w.Header().Set("X-Cache", "MISS")
+		recorder := &cacheRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		// Solo se guardan las respuestas correctas; los errores se recalculan en cada consulta
+		if recorder.status == http.StatusOK {
+			a.responseCache.Set(key, tankID, &cache.Response{
+				Status: recorder.status,
+				Header: recorder.header,
+				Body:   recorder.body,
+			})
+		}
+	})
+}
+
+// responseCacheKey identifica la respuesta por usuario, versión, idioma, formato, ruta y parámetros
+func responseCacheKey(r *http.Request) string {
+	subject := ""
+	if principal := domain.PrincipalFromContext(r.Context()); principal != nil {
+		subject = principal.Subject
+	}
+
+	return strings.Join([]string{
+		subject,
+		handlers.APIVersion(r.Context()),
+		i18n.FromContext(r.Context()),
+		r.Header.Get("Accept"),
+		r.URL.Path,
+		r.URL.RawQuery,
+	}, "\x00")
+}
+
+// cacheRecorder copia la respuesta mientras se envía al cliente
+type cacheRecorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   []byte
+}
+
+// WriteHeader guarda el código de estado y las cabeceras de la respuesta
+func (w *cacheRecorder) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	w.header = w.Header().Clone()
+	w.header.Del("X-Cache")
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write copia el cuerpo de la respuesta
+func (w *cacheRecorder) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body = append(w.body, p...)
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"monitor-tanques/internal/adapters/provisioning"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/cli"
+	"monitor-tanques/pkg/logger"
+)
+
+// errVolatileStore se devuelve cuando un comando de administración escribiría en repositorios
+// en memoria que se pierden al terminar el proceso
+var errVolatileStore = errors.New("the memory backend needs MEMORY_SNAPSHOT_PATH to keep the changes")
+
+// demoSeedSpec es la instalación de ejemplo que seed aplica si no se indica otro archivo
+const demoSeedSpec = `
+sites:
+  - id: estacion-norte
+    tanks:
+      - id: demo-diesel-1
+        name: Diésel principal
+        group_id: region-norte
+        labels: { region: norte, demo: "true" }
+        capacity: 20000
+        liquid_type: diesel
+        alert_threshold: 15
+        location: { latitude: 4.65, longitude: -74.05 }
+        reorder: { reorder_level: 5000, lead_time_days: 2, delivery_size: 12000 }
+        sensors:
+          - id: demo-radar-1
+            reporting_interval: 300
+            low_level_threshold: 15
+            report_on_change: 2
+      - id: demo-agua-1
+        name: Agua de servicio
+        group_id: region-norte
+        labels: { region: norte, demo: "true" }
+        capacity: 5000
+        liquid_type: agua
+  - id: estacion-sur
+    tanks:
+      - id: demo-gasolina-1
+        name: Gasolina corriente
+        group_id: region-sur
+        labels: { region: sur, demo: "true" }
+        capacity: 15000
+        liquid_type: gasolina
+        alert_threshold: 20
+        location: { latitude: 3.42, longitude: -76.52 }
+`
+
+// ServeCommand es el comando predeterminado: arranca el servidor o, con -plan, muestra los
+// cambios del archivo de aprovisionamiento
+func ServeCommand(config Config, log logger.Logger) *cli.Command {
+	var plan bool
+	return &cli.Command{
+		Name:  "serve",
+		Short: "Arranca el servidor de la API",
+		Flags: func(flags *flag.FlagSet) {
+			flags.BoolVar(&plan, "plan", false, "muestra los cambios de PROVISIONING_FILE sin aplicarlos y termina")
+		},
+		Run: func(ctx context.Context, args []string) error {
+			config.ProvisioningPlan = plan
+			app := NewAPI(config, log)
+			app.SetupRoutes()
+
+			// En modo plan solo se informa de los cambios del aprovisionamiento
+			if plan {
+				return app.PlanProvisioning(os.Stdout)
+			}
+			return app.Start()
+		},
+	}
+}
+
+// AdminCommands devuelve los comandos de administración del binario
+func AdminCommands(config Config, log logger.Logger) []*cli.Command {
+	var seedFile, adminName string
+	return []*cli.Command{
+		{
+			Name:  "migrate",
+			Short: "Actualiza el almacenamiento configurado al formato actual",
+			Run: func(ctx context.Context, args []string) error {
+				return NewAPI(config, log).Migrate(ctx, os.Stdout)
+			},
+		},
+		{
+			Name:  "seed",
+			Short: "Carga una instalación de ejemplo o el archivo de aprovisionamiento indicado",
+			Flags: func(flags *flag.FlagSet) {
+				flags.StringVar(&seedFile, "file", config.ProvisioningFile, "archivo de aprovisionamiento; sin él, la instalación de ejemplo")
+			},
+			Run: func(ctx context.Context, args []string) error {
+				// La semilla se aplica aquí y no al preparar las rutas
+				seedConfig := config
+				seedConfig.ProvisioningFile = ""
+				return NewAPI(seedConfig, log).Seed(ctx, seedFile, os.Stdout)
+			},
+		},
+		{
+			Name:  "create-admin",
+			Short: "Emite un token de la API con el rol admin",
+			Flags: func(flags *flag.FlagSet) {
+				flags.StringVar(&adminName, "name", "admin", "nombre descriptivo del token")
+			},
+			Run: func(ctx context.Context, args []string) error {
+				return NewAPI(config, log).CreateAdmin(ctx, adminName, os.Stdout)
+			},
+		},
+	}
+}
+
+// requirePersistentStore comprueba que los cambios de un comando de administración se conserven
+func (a *API) requirePersistentStore() error {
+	backend := a.config.RepositoryBackend
+	if (backend == repositoryBackendMemory || backend == "") && a.config.MemorySnapshotPath == "" {
+		return errVolatileStore
+	}
+	return nil
+}
+
+// Migrate lleva el almacenamiento configurado al formato actual. Con los repositorios en memoria
+// reescribe la instantánea, o la crea vacía si todavía no existe; sin instantánea no hay nada que
+// migrar. Debe ejecutarse con el servidor detenido, que de lo contrario sobrescribiría el archivo.
+func (a *API) Migrate(ctx context.Context, w io.Writer) error {
+	if _, err := a.newRepositories(); err != nil {
+		return err
+	}
+
+	if a.memoryStore == nil {
+		fmt.Fprintln(w, "Repositorios en memoria sin instantánea: no hay nada que migrar.")
+		return nil
+	}
+	if err := a.saveMemorySnapshot(ctx); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Instantánea %s actualizada al formato actual.\n", a.config.MemorySnapshotPath)
+	return nil
+}
+
+// Seed aplica el archivo de aprovisionamiento indicado, o la instalación de ejemplo, y guarda
+// el resultado. Como el aprovisionamiento, volver a aplicarlo no produce cambios.
+func (a *API) Seed(ctx context.Context, path string, w io.Writer) error {
+	if err := a.requirePersistentStore(); err != nil {
+		return err
+	}
+
+	var spec *domain.ProvisioningSpec
+	var err error
+	if path != "" {
+		spec, err = provisioning.LoadFile(path)
+	} else {
+		spec, err = provisioning.Parse(strings.NewReader(demoSeedSpec))
+	}
+	if err != nil {
+		return err
+	}
+
+	a.SetupRoutes()
+	result, err := a.provisioningService.Provision(ctx, spec)
+	if err != nil {
+		return err
+	}
+	if a.memoryStore != nil {
+		if err := a.saveMemorySnapshot(ctx); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "Semilla aplicada: %d creados, %d actualizados, %d sin cambios.\n", result.Created, result.Updated, result.Unchanged)
+	return nil
+}
+
+// CreateAdmin emite un token de la API con el rol admin y lo escribe en w. Es la única vez que
+// el token puede verse: solo se guarda su resumen.
+func (a *API) CreateAdmin(ctx context.Context, name string, w io.Writer) error {
+	if err := a.requirePersistentStore(); err != nil {
+		return err
+	}
+
+	repos, err := a.newRepositories()
+	if err != nil {
+		return err
+	}
+
+	token, plain, err := services.NewAPITokenService(repos.apiTokens).CreateToken(ctx, name, domain.RoleAdmin, nil)
+	if err != nil {
+		return err
+	}
+	if a.memoryStore != nil {
+		if err := a.saveMemorySnapshot(ctx); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "Token de administración %q (%s) creado. Guárdelo ahora, no volverá a mostrarse:\n\n%s\n\n", token.Name, token.ID, plain)
+	fmt.Fprintln(w, "Úselo con AUTH_MODE=oidc o AUTH_MODE=token en la cabecera Authorization: Bearer <token>.")
+	return nil
+}
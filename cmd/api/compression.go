@@ -0,0 +1,277 @@
+package api
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// contentEncoder describe una codificación de contenido que la API puede negociar con el cliente
+type contentEncoder struct {
+	name      string
+	newWriter func(w io.Writer) io.WriteCloser
+}
+
+// gzipWriters reutiliza los compresores gzip entre respuestas
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		writer, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return writer
+	},
+}
+
+// pooledGzipWriter devuelve el compresor al pool al cerrarse
+type pooledGzipWriter struct {
+	*gzip.Writer
+}
+
+func (w pooledGzipWriter) Close() error {
+	err := w.Writer.Close()
+	gzipWriters.Put(w.Writer)
+	return err
+}
+
+// contentEncoders son las codificaciones soportadas en orden de preferencia del servidor. Brotli
+// no está en la biblioteca estándar; para ofrecerlo basta con añadir aquí un codificador "br".
+var contentEncoders = []contentEncoder{
+	{
+		name: "gzip",
+		newWriter: func(w io.Writer) io.WriteCloser {
+			writer := gzipWriters.Get().(*gzip.Writer)
+			writer.Reset(w)
+			return pooledGzipWriter{writer}
+		},
+	},
+}
+
+// incompressibleTypes son los tipos de contenido que ya vienen comprimidos o que se transmiten
+// evento a evento (SSE), donde acumular bytes para comprimir retrasaría la entrega
+var incompressibleTypes = []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/pdf", "application/octet-stream", "text/event-stream"}
+
+// compressionMiddleware comprime las respuestas con la codificación aceptada por el cliente
+// (Accept-Encoding). Las respuestas menores que CompressionMinSize y los contenidos ya
+// comprimidos (imágenes, PDF, ...) se envían sin comprimir.
+func (a *API) compressionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.config.CompressionEnabled || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoder := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoder == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressResponseWriter{ResponseWriter: w, encoder: encoder, minSize: a.config.CompressionMinSize}
+		defer cw.Close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding elige la codificación soportada con mayor calidad en Accept-Encoding, o nil
+// si el cliente no acepta ninguna
+func negotiateEncoding(acceptEncoding string) *contentEncoder {
+	if acceptEncoding == "" {
+		return nil
+	}
+
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		qualities[strings.ToLower(strings.TrimSpace(name))] = quality
+	}
+
+	var best *contentEncoder
+	bestQuality := 0.0
+	for i := range contentEncoders {
+		quality, ok := qualities[contentEncoders[i].name]
+		if !ok {
+			quality = qualities["*"]
+		}
+		if quality > bestQuality {
+			best = &contentEncoders[i]
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// compressResponseWriter retiene los primeros bytes de la respuesta hasta saber si vale la pena
+// comprimirla
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoder *contentEncoder
+	minSize int
+
+	status      int
+	buffer      []byte
+	decided     bool
+	compressing bool
+	writer      io.WriteCloser
+}
+
+// WriteHeader retiene el código de estado hasta decidir si se comprime
+func (w *compressResponseWriter) WriteHeader(status int) {
+	if w.status != 0 || w.decided {
+		return
+	}
+
+	// Las respuestas informativas (1xx) no llevan cuerpo y pueden preceder a la definitiva
+	if status < http.StatusOK {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+
+	// Las respuestas sin cuerpo se envían de inmediato
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		w.decide(false)
+	}
+}
+
+// Write acumula el cuerpo hasta alcanzar el tamaño mínimo de compresión
+func (w *compressResponseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if !w.decided {
+		w.buffer = append(w.buffer, p...)
+		if len(w.buffer) < w.minSize {
+			return len(p), nil
+		}
+		if err := w.decide(w.compressible()); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	if w.compressing {
+		return w.writer.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush envía lo acumulado; permite transmitir respuestas por partes (p. ej. NDJSON)
+func (w *compressResponseWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if err := w.decide(w.compressible()); err != nil {
+			return
+		}
+	}
+
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok && w.compressing {
+		flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap devuelve el ResponseWriter original, p. ej. para ampliar su plazo de escritura con
+// http.ResponseController
+func (w *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack delega en el ResponseWriter original (p. ej. para WebSockets)
+func (w *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return hijacker.Hijack()
+}
+
+// Close termina la respuesta: envía lo pendiente y cierra el compresor
+func (w *compressResponseWriter) Close() error {
+	if !w.decided {
+		if w.status == 0 {
+			// El handler no escribió nada; net/http responderá 200 sin cuerpo
+			return nil
+		}
+		// La respuesta completa es menor que el tamaño mínimo
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+
+	if w.compressing {
+		return w.writer.Close()
+	}
+	return nil
+}
+
+// compressible indica si el tipo y las cabeceras de la respuesta permiten comprimirla
+func (w *compressResponseWriter) compressible() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buffer)
+	}
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decide escribe las cabeceras, con o sin codificación, y el cuerpo acumulado
+func (w *compressResponseWriter) decide(compress bool) error {
+	w.decided = true
+	w.compressing = compress
+
+	if compress {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoder.name)
+		header.Del("Content-Length")
+		// La representación comprimida no es idéntica byte a byte a la original
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		w.writer = w.encoder.newWriter(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if len(w.buffer) == 0 {
+		return nil
+	}
+
+	buffered := w.buffer
+	w.buffer = nil
+	if compress {
+		_, err := w.writer.Write(buffered)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buffered)
+	return err
+}
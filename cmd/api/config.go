@@ -0,0 +1,507 @@
+package api
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigFromEnv parte de la configuración predeterminada y aplica las variables de entorno definidas
+func ConfigFromEnv() Config {
+	config := DefaultConfig()
+
+	if value := os.Getenv("PORT"); value != "" {
+		config.Port = value
+	}
+	if value, ok := durationFromEnv("SHUTDOWN_TIMEOUT"); ok {
+		config.ShutdownTimeout = value
+	}
+	if value, ok := durationFromEnv("REQUEST_TIMEOUT"); ok {
+		config.RequestTimeout = value
+	}
+	if value, ok := durationFromEnv("INGEST_REQUEST_TIMEOUT"); ok {
+		config.IngestRequestTimeout = value
+	}
+	if value, ok := durationFromEnv("EXPORT_REQUEST_TIMEOUT"); ok {
+		config.ExportRequestTimeout = value
+	}
+	if value, ok := intFromEnv("MAX_REQUEST_BODY_SIZE"); ok {
+		config.MaxRequestBodySize = int64(value)
+	}
+	if value, ok := intFromEnv("INGEST_MAX_BODY_SIZE"); ok {
+		config.IngestMaxBodySize = int64(value)
+	}
+	if value, ok := durationFromEnv("MONITOR_INTERVAL"); ok {
+		config.MonitorInterval = value
+	}
+	if value := os.Getenv("LOCK_BACKEND"); value != "" {
+		config.LockBackend = value
+	}
+	if value := os.Getenv("REDIS_ADDR"); value != "" {
+		config.RedisAddr = value
+	}
+	if value := os.Getenv("REDIS_PASSWORD"); value != "" {
+		config.RedisPassword = value
+	}
+	if value := os.Getenv("INSTANCE_ID"); value != "" {
+		config.InstanceID = value
+	}
+	if value := os.Getenv("REPOSITORY_BACKEND"); value != "" {
+		config.RepositoryBackend = value
+	}
+	if value := os.Getenv("ALERT_NOTIFIER"); value != "" {
+		config.AlertNotifier = value
+	}
+	if value := os.Getenv("ALERT_NOTIFIER_TARGET"); value != "" {
+		config.AlertNotifierTarget = value
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("DEFAULT_ALERT_THRESHOLD"), 64); err == nil {
+		config.DefaultAlertThreshold = value
+	}
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		config.LogLevel = value
+	}
+	if value := os.Getenv("RUNTIME_CONFIG_FILE"); value != "" {
+		config.RuntimeConfigFile = value
+	}
+	if value := os.Getenv("MEMORY_SNAPSHOT_PATH"); value != "" {
+		config.MemorySnapshotPath = value
+	}
+	if value, ok := durationFromEnv("MEMORY_SNAPSHOT_INTERVAL"); ok {
+		config.MemorySnapshotInterval = value
+	}
+	if value := os.Getenv("INBOUND_WEBHOOKS_FILE"); value != "" {
+		config.InboundWebhooksFile = value
+	}
+	if value := os.Getenv("DEVICE_PROFILES_FILE"); value != "" {
+		config.DeviceProfilesFile = value
+	}
+	if value := os.Getenv("SIGFOX_DEVICES_FILE"); value != "" {
+		config.SigfoxDevicesFile = value
+	}
+	if value, ok := durationFromEnv("SIGFOX_DEDUP_WINDOW"); ok {
+		config.SigfoxDedupWindow = value
+	}
+	if value := os.Getenv("SNMP_FILE"); value != "" {
+		config.SNMPFile = value
+	}
+	if value := os.Getenv("SNMP_TRAP_ADDR"); value != "" {
+		config.SNMPTrapAddr = value
+	}
+	if value, ok := durationFromEnv("SNMP_TIMEOUT"); ok {
+		config.SNMPTimeout = value
+	}
+	if value := os.Getenv("ATG_FILE"); value != "" {
+		config.ATGFile = value
+	}
+	if value, ok := durationFromEnv("ATG_TIMEOUT"); ok {
+		config.ATGTimeout = value
+	}
+	if value := os.Getenv("ERP_FILE"); value != "" {
+		config.ERPFile = value
+	}
+	if value := os.Getenv("RUN_MODE"); value != "" {
+		config.RunMode = value
+	}
+	if value := os.Getenv("EDGE_UPSTREAM_URL"); value != "" {
+		config.EdgeUpstreamURL = value
+	}
+	if value := os.Getenv("EDGE_UPSTREAM_TOKEN"); value != "" {
+		config.EdgeUpstreamToken = value
+	}
+	if value := os.Getenv("EDGE_GATEWAY_ID"); value != "" {
+		config.EdgeGatewayID = value
+	}
+	if value, ok := durationFromEnv("EDGE_SYNC_INTERVAL"); ok {
+		config.EdgeSyncInterval = value
+	}
+	if value, ok := intFromEnv("EDGE_SYNC_BATCH_SIZE"); ok {
+		config.EdgeSyncBatchSize = value
+	}
+	if value, ok := durationFromEnv("EDGE_SYNC_TIMEOUT"); ok {
+		config.EdgeSyncTimeout = value
+	}
+	if value := os.Getenv("EDGE_QUEUE_PATH"); value != "" {
+		config.EdgeQueuePath = value
+	}
+	if value, ok := durationFromEnv("DATA_QUALITY_REPORT_INTERVAL"); ok {
+		config.DataQualityReportInterval = value
+	}
+	if value, ok := durationFromEnv("DATA_QUALITY_REPORT_WINDOW"); ok {
+		config.DataQualityReportWindow = value
+	}
+	if value, ok := intFromEnv("DATA_QUALITY_FLATLINE_COUNT"); ok {
+		config.DataQualityFlatlineCount = value
+	}
+	if value := os.Getenv("DATA_QUALITY_REPORT_RECIPIENTS"); value != "" {
+		for _, recipient := range strings.Split(value, ",") {
+			if recipient = strings.TrimSpace(recipient); recipient != "" {
+				config.DataQualityReportRecipients = append(config.DataQualityReportRecipients, recipient)
+			}
+		}
+	}
+	if value := os.Getenv("SMTP_ADDR"); value != "" {
+		config.SMTPAddr = value
+	}
+	if value := os.Getenv("SMTP_USERNAME"); value != "" {
+		config.SMTPUsername = value
+	}
+	if value := os.Getenv("SMTP_PASSWORD"); value != "" {
+		config.SMTPPassword = value
+	}
+	if value := os.Getenv("SMTP_FROM"); value != "" {
+		config.SMTPFrom = value
+	}
+	if value := os.Getenv("PROVISIONING_FILE"); value != "" {
+		config.ProvisioningFile = value
+	}
+
+	if value, ok := durationFromEnv("TANK_STATE_MAX_AGE"); ok {
+		config.TankStateMaxAge = value
+	}
+
+	if value, ok := intFromEnv("MEASUREMENT_BATCH_SIZE"); ok {
+		config.MeasurementBatchSize = value
+	}
+	if value, ok := durationFromEnv("MEASUREMENT_FLUSH_INTERVAL"); ok {
+		config.MeasurementFlushInterval = value
+	}
+	if value, ok := durationFromEnv("MEASUREMENT_FUTURE_TOLERANCE"); ok && value >= 0 {
+		config.MeasurementFutureTolerance = value
+	}
+
+	if value, err := strconv.ParseBool(os.Getenv("OUTBOX_ENABLED")); err == nil {
+		config.OutboxEnabled = value
+	}
+	if value, ok := durationFromEnv("OUTBOX_RELAY_INTERVAL"); ok {
+		config.OutboxRelayInterval = value
+	}
+	if value, ok := intFromEnv("OUTBOX_MAX_ATTEMPTS"); ok {
+		config.OutboxMaxAttempts = value
+	}
+	if value, ok := durationFromEnv("OUTBOX_RETRY_BACKOFF"); ok {
+		config.OutboxRetryBackoff = value
+	}
+	if value := os.Getenv("ATTACHMENT_STORAGE"); value != "" {
+		config.AttachmentStorage = value
+	}
+	if value := os.Getenv("ATTACHMENT_DIR"); value != "" {
+		config.AttachmentDir = value
+	}
+	if value, ok := intFromEnv("ATTACHMENT_MAX_SIZE"); ok {
+		config.AttachmentMaxSize = int64(value)
+	}
+	if value := os.Getenv("S3_ENDPOINT"); value != "" {
+		config.S3Endpoint = value
+	}
+	if value := os.Getenv("S3_REGION"); value != "" {
+		config.S3Region = value
+	}
+	if value := os.Getenv("S3_BUCKET"); value != "" {
+		config.S3Bucket = value
+	}
+	if value := os.Getenv("S3_ACCESS_KEY_ID"); value != "" {
+		config.S3AccessKeyID = value
+	}
+	if value := os.Getenv("S3_SECRET_ACCESS_KEY"); value != "" {
+		config.S3SecretAccessKey = value
+	}
+	if value := os.Getenv("DATA_LAKE_STORAGE"); value != "" {
+		config.DataLakeStorage = value
+	}
+	if value := os.Getenv("DATA_LAKE_BUCKET"); value != "" {
+		config.DataLakeBucket = value
+	}
+	if value := os.Getenv("DATA_LAKE_ENDPOINT"); value != "" {
+		config.DataLakeEndpoint = value
+	}
+	if value := os.Getenv("DATA_LAKE_DIR"); value != "" {
+		config.DataLakeDir = value
+	}
+	if value := os.Getenv("DATA_LAKE_PREFIX"); value != "" {
+		config.DataLakePrefix = value
+	}
+	if value, ok := durationFromEnv("DATA_LAKE_EXPORT_INTERVAL"); ok {
+		config.DataLakeExportInterval = value
+	}
+	if value, ok := durationFromEnv("DATA_LAKE_EXPORT_DELAY"); ok {
+		config.DataLakeExportDelay = value
+	}
+
+	if value, err := strconv.ParseFloat(os.Getenv("PUSH_RADIUS_KM"), 64); err == nil {
+		config.PushRadiusKm = value
+	}
+	if value := os.Getenv("FCM_CREDENTIALS_FILE"); value != "" {
+		config.FCMCredentialsFile = value
+	}
+	if value := os.Getenv("APNS_KEY_FILE"); value != "" {
+		config.APNsKeyFile = value
+	}
+	if value := os.Getenv("APNS_KEY_ID"); value != "" {
+		config.APNsKeyID = value
+	}
+	if value := os.Getenv("APNS_TEAM_ID"); value != "" {
+		config.APNsTeamID = value
+	}
+	if value := os.Getenv("APNS_TOPIC"); value != "" {
+		config.APNsTopic = value
+	}
+	if value, err := strconv.ParseBool(os.Getenv("APNS_SANDBOX")); err == nil {
+		config.APNsSandbox = value
+	}
+
+	if value, ok := intFromEnv("ANOMALY_WINDOW"); ok {
+		config.AnomalyWindow = value
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("ANOMALY_Z_THRESHOLD"), 64); err == nil {
+		config.AnomalyZThreshold = value
+	}
+	if value, ok := intFromEnv("ANOMALY_FLATLINE_COUNT"); ok {
+		config.AnomalyFlatlineCount = value
+	}
+
+	if value, ok := intFromEnv("SENSOR_HEALTH_SAMPLES"); ok {
+		config.SensorHealthSamples = value
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("SENSOR_BATTERY_FULL_VOLTAGE"), 64); err == nil {
+		config.SensorBatteryFullVoltage = value
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("SENSOR_BATTERY_EMPTY_VOLTAGE"), 64); err == nil {
+		config.SensorBatteryEmptyVoltage = value
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("SENSOR_LOW_BATTERY_VOLTAGE"), 64); err == nil {
+		config.SensorLowBatteryVoltage = value
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("SENSOR_WEAK_SIGNAL_RSSI"), 64); err == nil {
+		config.SensorWeakSignalRSSI = value
+	}
+	if value, ok := durationFromEnv("CLOCK_SKEW_DRIFT_THRESHOLD"); ok {
+		config.ClockSkewDriftThreshold = value
+	}
+	if value, ok := durationFromEnv("CLOCK_SKEW_CORRECTION_THRESHOLD"); ok {
+		config.ClockSkewCorrectionThreshold = value
+	}
+
+	if value := os.Getenv("MQTT_BROKER_ADDR"); value != "" {
+		config.MQTTBrokerAddr = value
+	}
+	if value := os.Getenv("MQTT_USERNAME"); value != "" {
+		config.MQTTUsername = value
+	}
+	if value := os.Getenv("MQTT_PASSWORD"); value != "" {
+		config.MQTTPassword = value
+	}
+	if value := os.Getenv("MQTT_COMMAND_TOPIC"); value != "" {
+		config.MQTTCommandTopic = value
+	}
+	if value := os.Getenv("MQTT_INGEST_TOPIC"); value != "" {
+		config.MQTTIngestTopic = value
+	}
+	if value := os.Getenv("MQTT_INGEST_BUFFER_PATH"); value != "" {
+		config.MQTTIngestBufferPath = value
+	}
+	if value, ok := durationFromEnv("TANK_POLL_TIMEOUT"); ok {
+		config.TankPollTimeout = value
+	}
+
+	if value, err := strconv.ParseBool(os.Getenv("HOMEASSISTANT_ENABLED")); err == nil {
+		config.HomeAssistantEnabled = value
+	}
+	if value := os.Getenv("HOMEASSISTANT_DISCOVERY_PREFIX"); value != "" {
+		config.HomeAssistantDiscoveryPrefix = value
+	}
+	if value := os.Getenv("HOMEASSISTANT_STATE_TOPIC"); value != "" {
+		config.HomeAssistantStateTopic = value
+	}
+
+	if value, err := strconv.ParseBool(os.Getenv("COMPRESSION_ENABLED")); err == nil {
+		config.CompressionEnabled = value
+	}
+	if value, ok := intFromEnv("COMPRESSION_MIN_SIZE"); ok {
+		config.CompressionMinSize = value
+	}
+
+	if value, ok := durationFromEnv("RESPONSE_CACHE_TTL"); ok {
+		config.ResponseCacheTTL = value
+	}
+
+	if value, err := strconv.ParseBool(os.Getenv("PROFILING_ENABLED")); err == nil {
+		config.ProfilingEnabled = value
+	}
+
+	if value, err := strconv.ParseBool(os.Getenv("METRICS_ENABLED")); err == nil {
+		config.MetricsEnabled = value
+	}
+
+	if value, err := strconv.ParseBool(os.Getenv("PUBLIC_STATUS_ENABLED")); err == nil {
+		config.PublicStatusEnabled = value
+	}
+
+	if value, err := strconv.ParseBool(os.Getenv("USAGE_METERING_ENABLED")); err == nil {
+		config.UsageMeteringEnabled = value
+	}
+	if value := os.Getenv("USAGE_ORGANIZATION_LABEL"); value != "" {
+		config.UsageOrganizationLabel = value
+	}
+
+	if value := os.Getenv("WEBHOOK_SIGNING_SECRET"); value != "" {
+		config.WebhookSigningSecret = value
+	}
+	if value := os.Getenv("SIGNED_URL_SECRET"); value != "" {
+		config.SignedURLSecret = value
+	}
+	if value, ok := durationFromEnv("SIGNED_URL_TTL"); ok {
+		config.SignedURLTTL = value
+	}
+	if value, ok := durationFromEnv("SIGNED_URL_MAX_TTL"); ok {
+		config.SignedURLMaxTTL = value
+	}
+	if value := os.Getenv("PUBLIC_BASE_URL"); value != "" {
+		config.PublicBaseURL = value
+	}
+
+	if value, ok := durationFromEnv("LIVE_PING_INTERVAL"); ok {
+		config.LivePingInterval = value
+	}
+	if value, ok := intFromEnv("LIVE_SEND_BUFFER"); ok {
+		config.LiveSendBuffer = value
+	}
+
+	if value := os.Getenv("APP_ENV"); value != "" {
+		config.Environment = value
+	}
+	if value, ok := durationFromEnv("FAULT_LATENCY"); ok {
+		config.FaultLatency = value
+	}
+	if value, err := strconv.ParseFloat(os.Getenv("FAULT_ERROR_RATE"), 64); err == nil {
+		config.FaultErrorRate = value
+	}
+	if value := os.Getenv("FAULT_TARGETS"); value != "" {
+		config.FaultTargets = value
+	}
+
+	if value := os.Getenv("AUTH_MODE"); value != "" {
+		config.AuthMode = value
+	}
+	if value := os.Getenv("OIDC_ISSUER_URL"); value != "" {
+		config.OIDCIssuerURL = value
+	}
+	if value := os.Getenv("OIDC_CLIENT_ID"); value != "" {
+		config.OIDCClientID = value
+	}
+	if value := os.Getenv("OIDC_CLIENT_SECRET"); value != "" {
+		config.OIDCClientSecret = value
+	}
+	if value := os.Getenv("OIDC_REDIRECT_URL"); value != "" {
+		config.OIDCRedirectURL = value
+	}
+	if value := os.Getenv("OIDC_GROUPS_CLAIM"); value != "" {
+		config.OIDCGroupsClaim = value
+	}
+	if value := os.Getenv("OIDC_ORG_CLAIM"); value != "" {
+		config.OIDCOrgClaim = value
+	}
+	if value := os.Getenv("OIDC_ROLE_MAPPING"); value != "" {
+		config.OIDCRoleMapping = value
+	}
+	if value, ok := intFromEnv("LOGIN_MAX_FAILURES"); ok {
+		config.LoginMaxFailures = value
+	}
+	if value, ok := durationFromEnv("LOGIN_FAILURE_WINDOW"); ok {
+		config.LoginFailureWindow = value
+	}
+	if value, ok := durationFromEnv("LOGIN_LOCKOUT_DURATION"); ok {
+		config.LoginLockoutDuration = value
+	}
+	if value, err := strconv.ParseBool(os.Getenv("LOGIN_TRUST_FORWARDED_FOR")); err == nil {
+		config.LoginTrustForwardedFor = value
+	}
+	if value := os.Getenv("CAPTCHA_VERIFY_URL"); value != "" {
+		config.CaptchaVerifyURL = value
+	}
+	if value := os.Getenv("CAPTCHA_SECRET"); value != "" {
+		config.CaptchaSecret = value
+	}
+	if value, ok := intFromEnv("CAPTCHA_AFTER_FAILURES"); ok {
+		config.CaptchaAfterFailures = value
+	}
+	if value, ok := durationFromEnv("SESSION_ACCESS_TTL"); ok {
+		config.SessionAccessTTL = value
+	}
+	if value, ok := durationFromEnv("SESSION_REFRESH_TTL"); ok {
+		config.SessionRefreshTTL = value
+	}
+	if value := os.Getenv("CHANGE_APPROVAL_SITES"); value != "" {
+		for _, site := range strings.Split(value, ",") {
+			if site = strings.TrimSpace(site); site != "" {
+				config.ChangeApprovalSites = append(config.ChangeApprovalSites, site)
+			}
+		}
+	}
+	if value := os.Getenv("DEVICE_TLS_ADDR"); value != "" {
+		config.DeviceTLSAddr = value
+	}
+	if value := os.Getenv("DEVICE_TLS_CERT_FILE"); value != "" {
+		config.DeviceTLSCertFile = value
+	}
+	if value := os.Getenv("DEVICE_TLS_KEY_FILE"); value != "" {
+		config.DeviceTLSKeyFile = value
+	}
+	if value := os.Getenv("DEVICE_TLS_CLIENT_CA_FILE"); value != "" {
+		config.DeviceTLSClientCAFile = value
+	}
+	if value := os.Getenv("SECRETS_PROVIDER"); value != "" {
+		config.SecretsProvider = value
+	}
+	if value := os.Getenv("SECRETS_DIR"); value != "" {
+		config.SecretsDir = value
+	}
+	if value, ok := durationFromEnv("SECRETS_CACHE_TTL"); ok {
+		config.SecretsCacheTTL = value
+	}
+	if value := os.Getenv("VAULT_ADDR"); value != "" {
+		config.VaultAddr = value
+	}
+	if value := os.Getenv("VAULT_TOKEN"); value != "" {
+		config.VaultToken = value
+	}
+	if value := os.Getenv("VAULT_MOUNT"); value != "" {
+		config.VaultMount = value
+	}
+	if value := os.Getenv("AWS_REGION"); value != "" {
+		config.AWSRegion = value
+	}
+	if value := os.Getenv("AWS_ACCESS_KEY_ID"); value != "" {
+		config.AWSAccessKeyID = value
+	}
+	if value := os.Getenv("AWS_SECRET_ACCESS_KEY"); value != "" {
+		config.AWSSecretAccessKey = value
+	}
+	if value := os.Getenv("AWS_SESSION_TOKEN"); value != "" {
+		config.AWSSessionToken = value
+	}
+	if value := os.Getenv("SECRETS_MANAGER_ENDPOINT"); value != "" {
+		config.SecretsManagerEndpoint = value
+	}
+
+	return config
+}
+
+// durationFromEnv lee una duración (p. ej. "30s") de una variable de entorno
+func durationFromEnv(name string) (time.Duration, bool) {
+	value, err := time.ParseDuration(os.Getenv(name))
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// intFromEnv lee un número entero de una variable de entorno
+func intFromEnv(name string) (int, bool) {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
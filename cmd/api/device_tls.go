@@ -0,0 +1,52 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+)
+
+// newDeviceServer crea la escucha de ingesta con TLS mutuo: solo se aceptan conexiones con un
+// certificado de cliente emitido por la CA de los equipos. Sirve el mismo manejador que la API,
+// pero ClientCertMiddleware limita las solicitudes con certificado a las rutas de ingesta.
+func (a *API) newDeviceServer() *http.Server {
+	if a.config.DeviceTLSClientCAFile == "" {
+		a.logger.Fatal("DEVICE_TLS_ADDR requires DEVICE_TLS_CLIENT_CA_FILE")
+	}
+	pem, err := os.ReadFile(a.config.DeviceTLSClientCAFile)
+	if err != nil {
+		a.logger.Fatal("Invalid device client CA file", "path", a.config.DeviceTLSClientCAFile, "error", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(pem) {
+		a.logger.Fatal("Invalid device client CA file", "path", a.config.DeviceTLSClientCAFile, "error", "no PEM certificates found")
+	}
+
+	return &http.Server{
+		Addr:         a.config.DeviceTLSAddr,
+		Handler:      a.server.Handler,
+		ReadTimeout:  a.config.ReadTimeout,
+		WriteTimeout: a.config.WriteTimeout,
+		TLSConfig: &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+}
+
+// DeviceTLSConfig devuelve la configuración TLS de la escucha de los equipos, o nil si está
+// desactivada; útil para pruebas con httptest. Requiere haber llamado a SetupRoutes.
+func (a *API) DeviceTLSConfig() *tls.Config {
+	if a.deviceServer == nil {
+		return nil
+	}
+	return a.deviceServer.TLSConfig.Clone()
+}
+
+// isIngestRoute indica si la solicitud va a una ruta de ingesta de mediciones, las únicas que
+// pueden usar los equipos autenticados con certificado
+func isIngestRoute(r *http.Request) bool {
+	return routeClass(r) == routeClassIngest
+}
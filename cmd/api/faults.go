@@ -0,0 +1,57 @@
+package api
+
+import (
+	"strings"
+
+	"monitor-tanques/internal/adapters/faults"
+)
+
+// Adaptadores en los que pueden inyectarse fallos (FAULT_TARGETS)
+const (
+	faultTargetRepositories = "repositories"
+	faultTargetNotifiers    = "notifiers"
+)
+
+// testingEnvironments son los entornos de pruebas, los únicos en los que se permite inyectar
+// fallos y mediciones sintéticas
+var testingEnvironments = map[string]bool{
+	"staging": true,
+	"test":    true,
+}
+
+// newFaultInjector crea el inyector de fallos configurado y devuelve los adaptadores a los que
+// se aplica. Sin latencia ni tasa de error no se inyecta nada. En producción la configuración de
+// fallos detiene el arranque: una variable olvidada no debe degradar el servicio real.
+func (a *API) newFaultInjector() (*faults.Injector, map[string]bool) {
+	config := faults.Config{Latency: a.config.FaultLatency, ErrorRate: a.config.FaultErrorRate}
+	if !config.Enabled() {
+		return nil, nil
+	}
+
+	if !testingEnvironments[a.config.Environment] {
+		a.logger.Fatal("Fault injection is only allowed in staging or test", "environment", a.config.Environment)
+	}
+	if config.ErrorRate < 0 || config.ErrorRate > 1 {
+		a.logger.Fatal("Invalid fault error rate", "error_rate", config.ErrorRate)
+	}
+
+	targets := make(map[string]bool)
+	for _, target := range strings.Split(a.config.FaultTargets, ",") {
+		target = strings.TrimSpace(target)
+		switch target {
+		case faultTargetRepositories, faultTargetNotifiers:
+			targets[target] = true
+		case "":
+		default:
+			a.logger.Fatal("Unknown fault target", "target", target)
+		}
+	}
+
+	a.logger.Warn("Fault injection enabled",
+		"environment", a.config.Environment,
+		"latency", config.Latency,
+		"error_rate", config.ErrorRate,
+		"targets", a.config.FaultTargets,
+	)
+	return faults.NewInjector(config), targets
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"net/http"
+
+	"monitor-tanques/pkg/i18n"
+)
+
+// languageMiddleware negocia el idioma de la respuesta con Accept-Language y lo guarda en el
+// contexto para que los handlers y el middleware de autenticación traduzcan sus mensajes
+func (a *API) languageMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		language := i18n.Negotiate(r.Header.Get("Accept-Language"))
+
+		header := w.Header()
+		header.Add("Vary", "Accept-Language")
+		header.Set("Content-Language", language)
+
+		next.ServeHTTP(w, r.WithContext(i18n.WithLanguage(r.Context(), language)))
+	})
+}
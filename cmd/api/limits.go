@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Clases de ruta con presupuestos propios de tamaño y de tiempo
+const (
+	routeClassDefault = iota
+	routeClassIngest  // Mediciones enviadas por los sensores: cuerpos pequeños y respuesta rápida
+	routeClassExport  // Historiales largos, que pueden transmitirse durante minutos
+	routeClassUpload  // Subidas de archivos, que limitan su tamaño en el propio manejador
+	routeClassLive    // Conexiones WebSocket y SSE, que duran lo que el cliente permanezca conectado
+)
+
+// routeClasses asigna a cada ruta (método y plantilla) su clase; el resto usa la predeterminada
+var routeClasses = map[string]int{
+	"POST /api/tanks/{id}/measurements":  routeClassIngest,
+	"POST /api/ingest/devices/{profile}": routeClassIngest,
+	"POST /api/ingest/sigfox":            routeClassIngest,
+	"GET /api/tanks/{id}/measurements":   routeClassExport,
+	"POST /api/tanks/{id}/attachments":   routeClassUpload,
+	"POST /api/tanks/import":             routeClassUpload,
+	"POST /api/admin/provision":          routeClassUpload,
+	"GET /api/live":                      routeClassLive,
+	"GET /api/live/events":               routeClassLive,
+}
+
+// routeClass devuelve la clase de la ruta que atiende la solicitud
+func routeClass(r *http.Request) int {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return routeClassDefault
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return routeClassDefault
+	}
+	return routeClasses[r.Method+" "+template]
+}
+
+// requestBudget devuelve el tamaño máximo del cuerpo y el plazo de la solicitud según su ruta.
+// Un tamaño 0 deja el cuerpo sin límite y un plazo 0 no limita la duración.
+func (a *API) requestBudget(r *http.Request) (int64, time.Duration) {
+	switch routeClass(r) {
+	case routeClassIngest:
+		return a.config.IngestMaxBodySize, a.config.IngestRequestTimeout
+	case routeClassExport:
+		return a.config.MaxRequestBodySize, a.config.ExportRequestTimeout
+	case routeClassUpload:
+		return 0, a.config.RequestTimeout
+	case routeClassLive:
+		return a.config.MaxRequestBodySize, 0
+	default:
+		return a.config.MaxRequestBodySize, a.config.RequestTimeout
+	}
+}
+
+// limitsMiddleware limita el tamaño del cuerpo de cada solicitud, para que un cliente defectuoso
+// o malicioso no agote la memoria con un JSON gigante, y asocia un plazo máximo a su contexto,
+// de modo que los repositorios abandonen el trabajo si un almacenamiento lento no responde a
+// tiempo. Las rutas cuyo plazo supera WriteTimeout amplían el plazo de escritura de la conexión.
+func (a *API) limitsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		maxBodySize, timeout := a.requestBudget(r)
+
+		if maxBodySize > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+		}
+
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if a.config.WriteTimeout > 0 && timeout > a.config.WriteTimeout {
+			// No todos los ResponseWriter lo admiten (p. ej. en pruebas); se ignora si falla
+			http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout))
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+
+	"monitor-tanques/internal/adapters/runtimeconfig"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// runtimeSettingsSourceEnv identifica la configuración tomada de las variables de entorno
+const runtimeSettingsSourceEnv = "env"
+
+// runtimeSettingsApplier aplica la configuración recargable a los componentes en marcha
+type runtimeSettingsApplier struct {
+	api      *API
+	notifier *services.SwappableAlertNotifier
+	defaults *services.TankDefaults
+	applied  domain.RuntimeSettings // Notificador en vigor, para no reconstruirlo si no cambia
+}
+
+// ApplyRuntimeSettings cambia el nivel de logging, el notificador predeterminado y el umbral por
+// defecto. El notificador se crea antes de cambiar nada, de modo que un error no deje la
+// configuración aplicada a medias.
+func (r *runtimeSettingsApplier) ApplyRuntimeSettings(ctx context.Context, settings *domain.RuntimeSettings) error {
+	level, err := logger.ParseLevel(settings.LogLevel)
+	if err != nil {
+		return err
+	}
+
+	if settings.AlertNotifier != r.applied.AlertNotifier || settings.AlertNotifierTarget != r.applied.AlertNotifierTarget {
+		notifier, err := r.api.newAlertNotifier(settings.AlertNotifier, settings.AlertNotifierTarget)
+		if err != nil {
+			return err
+		}
+		r.notifier.Swap(notifier)
+	}
+
+	if setter, ok := r.api.logger.(logger.LevelSetter); ok {
+		setter.SetLevel(level)
+	}
+	r.defaults.SetAlertThreshold(settings.DefaultAlertThreshold)
+	r.applied = *settings
+	return nil
+}
+
+// setupRuntimeConfig aplica la configuración recargable de las variables de entorno y, con
+// RuntimeConfigFile, la del archivo, que la sustituye. El notificador ya está creado con las
+// variables de entorno, o inyectado con SetAlertNotifier, y solo se reemplaza si el archivo
+// indica otro.
+func (a *API) setupRuntimeConfig(notifier *services.SwappableAlertNotifier, defaults *services.TankDefaults) ports.RuntimeConfigService {
+	base := domain.RuntimeSettings{
+		LogLevel:              a.config.LogLevel,
+		AlertNotifier:         a.config.AlertNotifier,
+		AlertNotifierTarget:   a.config.AlertNotifierTarget,
+		DefaultAlertThreshold: a.config.DefaultAlertThreshold,
+		Source:                runtimeSettingsSourceEnv,
+	}
+	if err := base.Validate(); err != nil {
+		a.logger.Fatal("Invalid runtime config", "error", err)
+	}
+
+	applier := &runtimeSettingsApplier{api: a, notifier: notifier, defaults: defaults, applied: base}
+	if err := applier.ApplyRuntimeSettings(context.Background(), &base); err != nil {
+		a.logger.Fatal("Invalid runtime config", "error", err)
+	}
+
+	var source ports.RuntimeSettingsSource
+	if a.config.RuntimeConfigFile != "" {
+		source = runtimeconfig.NewFileSource(a.config.RuntimeConfigFile)
+	}
+	configService := services.NewRuntimeConfigService(source, applier, base)
+	if source != nil {
+		if _, err := configService.Reload(context.Background()); err != nil {
+			a.logger.Fatal("Invalid runtime config file", "path", a.config.RuntimeConfigFile, "error", err)
+		}
+		a.logger.Info("Runtime config loaded", "path", a.config.RuntimeConfigFile)
+	}
+	return configService
+}
+
+// reloadRuntimeConfig recarga la configuración al recibir SIGHUP. Si no es válida se registra el
+// error y siguen en vigor los valores anteriores.
+func (a *API) reloadRuntimeConfig(ctx context.Context) {
+	settings, err := a.runtimeConfig.Reload(ctx)
+	if err != nil {
+		a.logger.Error("Failed to reload runtime config", "error", err)
+		return
+	}
+
+	a.logger.Info("Runtime config reloaded", "source", settings.Source, "log_level", settings.LogLevel,
+		"alert_notifier", settings.AlertNotifier, "default_alert_threshold", settings.DefaultAlertThreshold)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"monitor-tanques/internal/adapters/secrets"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Proveedores de secretos reconocidos en SECRETS_PROVIDER
+const (
+	secretsProviderEnv   = "env"
+	secretsProviderFile  = "file"
+	secretsProviderVault = "vault"
+	secretsProviderAWS   = "aws"
+)
+
+// newSecretProvider crea el proveedor de secretos configurado en SecretsProvider
+func (a *API) newSecretProvider() (ports.SecretProvider, error) {
+	var provider ports.SecretProvider
+	switch a.config.SecretsProvider {
+	case secretsProviderEnv, "":
+		provider = secrets.NewEnvProvider()
+	case secretsProviderFile:
+		provider = secrets.NewFileProvider(a.config.SecretsDir)
+	case secretsProviderVault:
+		if a.config.VaultAddr == "" || a.config.VaultToken == "" {
+			return nil, fmt.Errorf("secrets provider %q requires VAULT_ADDR and VAULT_TOKEN", a.config.SecretsProvider)
+		}
+		provider = secrets.NewVaultProvider(secrets.VaultConfig{
+			Addr:  a.config.VaultAddr,
+			Token: a.config.VaultToken,
+			Mount: a.config.VaultMount,
+		})
+	case secretsProviderAWS:
+		if a.config.AWSAccessKeyID == "" || a.config.AWSSecretAccessKey == "" {
+			return nil, fmt.Errorf("secrets provider %q requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", a.config.SecretsProvider)
+		}
+		provider = secrets.NewAWSProvider(secrets.AWSConfig{
+			Endpoint:        a.config.SecretsManagerEndpoint,
+			Region:          a.config.AWSRegion,
+			AccessKeyID:     a.config.AWSAccessKeyID,
+			SecretAccessKey: a.config.AWSSecretAccessKey,
+			SessionToken:    a.config.AWSSessionToken,
+		})
+	default:
+		return nil, fmt.Errorf("unknown secrets provider %q", a.config.SecretsProvider)
+	}
+
+	return secrets.NewCachedProvider(provider, a.config.SecretsCacheTTL), nil
+}
+
+// secretSettings son las credenciales de la configuración que pueden indicarse como referencias
+// secret:<nombre>. El destino del notificador predeterminado se resuelve al crearlo, porque puede
+// cambiar al recargar la configuración.
+func (a *API) secretSettings() map[string]*string {
+	return map[string]*string{
+		"REDIS_PASSWORD":         &a.config.RedisPassword,
+		"SMTP_USERNAME":          &a.config.SMTPUsername,
+		"SMTP_PASSWORD":          &a.config.SMTPPassword,
+		"S3_ACCESS_KEY_ID":       &a.config.S3AccessKeyID,
+		"S3_SECRET_ACCESS_KEY":   &a.config.S3SecretAccessKey,
+		"MQTT_USERNAME":          &a.config.MQTTUsername,
+		"MQTT_PASSWORD":          &a.config.MQTTPassword,
+		"SIGNED_URL_SECRET":      &a.config.SignedURLSecret,
+		"WEBHOOK_SIGNING_SECRET": &a.config.WebhookSigningSecret,
+		"OIDC_CLIENT_SECRET":     &a.config.OIDCClientSecret,
+		"CAPTCHA_SECRET":         &a.config.CaptchaSecret,
+		"EDGE_UPSTREAM_TOKEN":    &a.config.EdgeUpstreamToken,
+	}
+}
+
+// resolveSecrets crea el proveedor de secretos y sustituye las referencias de la configuración por
+// las credenciales. Una referencia que no se puede resolver detiene el arranque: es preferible no
+// servir a hacerlo sin correo, sin bloqueos o con una clave de firma distinta en cada réplica.
+func (a *API) resolveSecrets() {
+	provider, err := a.newSecretProvider()
+	if err != nil {
+		a.logger.Fatal("Invalid secrets provider", "error", err)
+	}
+	a.secrets = provider
+
+	for setting, value := range a.secretSettings() {
+		if !secrets.IsReference(*value) {
+			continue
+		}
+		resolved, err := secrets.Resolve(context.Background(), provider, *value)
+		if err != nil {
+			a.logger.Fatal("Failed to resolve secret", "setting", setting, "provider", a.config.SecretsProvider, "error", err)
+		}
+		*value = resolved
+		a.logger.Debug("Secret resolved", "setting", setting, "provider", a.config.SecretsProvider)
+	}
+}
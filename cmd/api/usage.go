@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"monitor-tanques/internal/core/ports"
+)
+
+// usageMiddleware cuenta cada solicitud a /api en la organización del usuario autenticado. Un
+// fallo al contar se registra pero no impide atender la solicitud.
+func (a *API) usageMiddleware(usageService ports.UsageService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				if err := usageService.RecordAPICall(r.Context()); err != nil {
+					a.logger.Warn("Failed to record API usage", "error", err, "path", r.URL.Path)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
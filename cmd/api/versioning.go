@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/adapters/handlers"
+	"monitor-tanques/pkg/i18n"
+)
+
+// stableAPIVersion es la versión servida por las rutas sin versión (/api/...), que se mantienen
+// como alias para los clientes existentes
+const stableAPIVersion = "v1"
+
+// publishedAPIVersions son las versiones de la API disponibles. La v2 envuelve las respuestas
+// en un sobre común (data, meta, links); ver handlers.Envelope.
+var publishedAPIVersions = []string{"v1", "v2"}
+
+// apiVersionPattern reconoce el segmento de versión de /api/{version}/...
+var apiVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// versionRoutes devuelve el router de las rutas propias de una versión y la publica. Las rutas
+// se registran sin prefijo de versión (p. ej. "/api/tanks/{id}/measurements") y tienen prioridad
+// sobre las compartidas solo para esa versión; lo que una versión no redefine se atiende con las
+// rutas compartidas. Así un cambio incompatible del esquema puede publicarse en /api/v2 mientras
+// /api/v1 sigue funcionando. Debe llamarse antes de registrar las rutas compartidas.
+func (a *API) versionRoutes(version string) *mux.Router {
+	if router, ok := a.versions[version]; ok {
+		return router
+	}
+
+	router := a.router.MatcherFunc(func(r *http.Request, _ *mux.RouteMatch) bool {
+		return handlers.APIVersion(r.Context()) == version
+	}).Subrouter()
+	a.versions[version] = router
+
+	return router
+}
+
+// versionMiddleware traduce /api/{version}/... a la ruta sin versión antes del enrutado y anota
+// la versión en el contexto y en la cabecera API-Version. Las versiones no publicadas responden 404.
+func (a *API) versionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, "/api/")
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		version := stableAPIVersion
+		segment, remainder, _ := strings.Cut(rest, "/")
+		if apiVersionPattern.MatchString(segment) {
+			if _, published := a.versions[segment]; !published {
+				http.Error(w, i18n.Translate(i18n.FromContext(r.Context()), "Versión de API no soportada"), http.StatusNotFound)
+				return
+			}
+			version = segment
+
+			r = r.Clone(handlers.WithAPIVersion(r.Context(), version))
+			r.URL.Path = "/api/" + remainder
+			r.URL.RawPath = ""
+		} else {
+			r = r.WithContext(handlers.WithAPIVersion(r.Context(), version))
+		}
+
+		w.Header().Set("API-Version", version)
+		next.ServeHTTP(w, r)
+	})
+}
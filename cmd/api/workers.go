@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"sync"
+)
+
+// workerGroup ejecuta los componentes de larga duración de la API y cancela su contexto común en
+// cuanto uno termina con error, al estilo de errgroup (golang.org/x/sync no es una dependencia
+// del proyecto)
+type workerGroup struct {
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+	once   sync.Once
+	err    error
+}
+
+// newWorkerGroup crea un grupo cuyo contexto se cancela al cancelarse ctx o al fallar un componente
+func newWorkerGroup(ctx context.Context) (*workerGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &workerGroup{cancel: cancel}, ctx
+}
+
+// Go ejecuta fn en su propia goroutine
+func (g *workerGroup) Go(fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := fn(); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+// Wait espera a que terminen todos los componentes y devuelve el primer error
+func (g *workerGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+// shutdownHook es un paso del cierre ordenado, ejecutado cuando el servidor HTTP y las tareas
+// en segundo plano ya se detuvieron
+type shutdownHook struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// onShutdown registra un paso del cierre. Los pasos se ejecutan en orden de registro.
+func (a *API) onShutdown(name string, run func(ctx context.Context) error) {
+	a.shutdownHooks = append(a.shutdownHooks, shutdownHook{name: name, run: run})
+}
@@ -0,0 +1,60 @@
+package atg
+
+import "monitor-tanques/internal/core/domain"
+
+// Códigos de las alarmas de tanque de las consolas TLS (respuesta a i205)
+const (
+	AlarmSetupDataWarning   = 1
+	AlarmLeak               = 2
+	AlarmHighWater          = 3
+	AlarmOverfill           = 4
+	AlarmLowProduct         = 5
+	AlarmSuddenLoss         = 6
+	AlarmHighProduct        = 7
+	AlarmInvalidFuelLevel   = 8
+	AlarmProbeOut           = 9
+	AlarmHighWaterWarning   = 10
+	AlarmDeliveryNeeded     = 11
+	AlarmMaxProduct         = 12
+	AlarmGrossLeakTestFail  = 13
+	AlarmPeriodicLeakFail   = 14
+	AlarmAnnualLeakTestFail = 15
+)
+
+// alarmInfo describe cómo notificar una alarma de la consola
+type alarmInfo struct {
+	severity string
+	format   string // Argumentos: consola y nombre del tanque
+}
+
+// alarms son las alarmas conocidas. Las demás se notifican con su código como advertencia.
+var alarms = map[int]alarmInfo{
+	AlarmSetupDataWarning:   {domain.AlertSeverityWarning, "La consola %s reporta datos de configuración incorrectos en el tanque %s."},
+	AlarmLeak:               {domain.AlertSeverityCritical, "La consola %s reporta una fuga en el tanque %s."},
+	AlarmHighWater:          {domain.AlertSeverityCritical, "La consola %s reporta agua alta en el tanque %s."},
+	AlarmOverfill:           {domain.AlertSeverityCritical, "La consola %s reporta sobrellenado en el tanque %s."},
+	AlarmLowProduct:         {domain.AlertSeverityWarning, "La consola %s reporta producto bajo en el tanque %s."},
+	AlarmSuddenLoss:         {domain.AlertSeverityCritical, "La consola %s reporta una pérdida repentina en el tanque %s."},
+	AlarmHighProduct:        {domain.AlertSeverityWarning, "La consola %s reporta producto alto en el tanque %s."},
+	AlarmInvalidFuelLevel:   {domain.AlertSeverityWarning, "La consola %s reporta un nivel de combustible inválido en el tanque %s."},
+	AlarmProbeOut:           {domain.AlertSeverityWarning, "La consola %s no recibe datos de la sonda del tanque %s."},
+	AlarmHighWaterWarning:   {domain.AlertSeverityWarning, "La consola %s advierte de agua alta en el tanque %s."},
+	AlarmDeliveryNeeded:     {domain.AlertSeverityInfo, "La consola %s indica que el tanque %s necesita una entrega."},
+	AlarmMaxProduct:         {domain.AlertSeverityCritical, "La consola %s reporta el producto máximo en el tanque %s."},
+	AlarmGrossLeakTestFail:  {domain.AlertSeverityCritical, "El tanque %[2]s no superó la prueba de fuga gruesa de la consola %[1]s."},
+	AlarmPeriodicLeakFail:   {domain.AlertSeverityCritical, "El tanque %[2]s no superó la prueba de fugas periódica de la consola %[1]s."},
+	AlarmAnnualLeakTestFail: {domain.AlertSeverityCritical, "El tanque %[2]s no superó la prueba de fugas anual de la consola %[1]s."},
+}
+
+// unknownAlarmFormat es el mensaje de las alarmas sin descripción. Argumentos: consola, código
+// y nombre del tanque.
+const unknownAlarmFormat = "La consola %s reporta la alarma %d en el tanque %s."
+
+// alarmAlert crea la alerta de una alarma que acaba de activarse
+func alarmAlert(console string, code int, tank *domain.Tank) *domain.Alert {
+	info, ok := alarms[code]
+	if !ok {
+		return domain.NewAlert(domain.AlertEventATGAlarm, domain.AlertSeverityWarning, tank, unknownAlarmFormat, console, code, tank.Name)
+	}
+	return domain.NewAlert(domain.AlertEventATGAlarm, info.severity, tank, info.format, console, tank.Name)
+}
@@ -0,0 +1,69 @@
+package atg
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// maxResponseSize limita la respuesta de la consola; un inventario de 99 tanques ocupa unos 7 KB
+const maxResponseSize = 64 << 10
+
+// Client envía comandos a las consolas por TCP. Habla el protocolo directamente para no añadir
+// dependencias externas.
+type Client struct {
+	Timeout time.Duration // Plazo de cada comando, incluida la conexión
+}
+
+// NewClient crea un cliente con el plazo indicado por comando
+func NewClient(timeout time.Duration) *Client {
+	return &Client{Timeout: timeout}
+}
+
+// Query envía el comando a la consola y devuelve los datos de la respuesta validada
+func (c *Client) Query(ctx context.Context, console *Console, command string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", console.Address)
+	if err != nil {
+		return nil, fmt.Errorf("atg: %w", err)
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write(Frame(console.SecurityCode, command)); err != nil {
+		return nil, fmt.Errorf("atg: %w", err)
+	}
+
+	frame, err := readFrame(bufio.NewReader(conn))
+	if err != nil {
+		return nil, fmt.Errorf("atg: %w", err)
+	}
+	return ParseResponse(frame, command)
+}
+
+// readFrame lee una respuesta de SOH a ETX, descartando lo que llegue antes de SOH
+func readFrame(reader *bufio.Reader) ([]byte, error) {
+	if _, err := reader.ReadBytes(soh); err != nil {
+		return nil, err
+	}
+
+	frame := []byte{soh}
+	for len(frame) < maxResponseSize {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		frame = append(frame, b)
+		if b == etx {
+			return frame, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: response exceeds %d bytes", ErrMalformed, maxResponseSize)
+}
@@ -0,0 +1,135 @@
+package atg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// Collector consulta las consolas, guarda el inventario de sus tanques como mediciones y
+// notifica las alarmas que se activan
+type Collector struct {
+	tankService ports.TankService
+	notifier    ports.AlertNotifier
+	client      *Client
+	logger      logger.Logger
+
+	mutex  sync.Mutex
+	active map[string]map[int]bool // Alarmas activas en la última consulta, por consola/tanque
+}
+
+// NewCollector crea un colector de consolas ATG
+func NewCollector(tankService ports.TankService, notifier ports.AlertNotifier, client *Client, logger logger.Logger) *Collector {
+	return &Collector{
+		tankService: tankService,
+		notifier:    notifier,
+		client:      client,
+		logger:      logger,
+		active:      make(map[string]map[int]bool),
+	}
+}
+
+// Poll lee el inventario y las alarmas de la consola. Un tanque que falla no impide procesar
+// los demás.
+func (c *Collector) Poll(ctx context.Context, console *Console) error {
+	data, err := c.client.Query(ctx, console, CommandInventory)
+	if err != nil {
+		return fmt.Errorf("console %s inventory: %w", console.Name, err)
+	}
+	_, inventories, err := ParseInventory(data, time.Local)
+	if err != nil {
+		return fmt.Errorf("console %s inventory: %w", console.Name, err)
+	}
+
+	var errs []error
+	now := time.Now()
+	for _, inventory := range inventories {
+		tankID, ok := console.Tanks[inventory.Tank]
+		if !ok {
+			continue
+		}
+
+		measurement := &domain.Measurement{
+			ID:          uuid.New().String(),
+			TankID:      tankID,
+			Level:       inventory.Volume * console.VolumeScale,
+			Temperature: inventory.Temperature,
+			Timestamp:   now,
+		}
+		if console.Fahrenheit {
+			measurement.Temperature = (inventory.Temperature - 32) * 5 / 9
+		}
+		if err := c.tankService.AddMeasurement(ctx, measurement); err != nil && !services.IsStaleMeasurement(err) {
+			c.logger.Error("Failed to save ATG measurement", "console", console.Name, "tank_id", tankID, "error", err)
+			errs = append(errs, fmt.Errorf("tank %s: %w", tankID, err))
+		}
+	}
+
+	data, err = c.client.Query(ctx, console, CommandStatus)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("console %s status: %w", console.Name, err))
+		return errors.Join(errs...)
+	}
+	_, statuses, err := ParseStatus(data, time.Local)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("console %s status: %w", console.Name, err))
+		return errors.Join(errs...)
+	}
+	errs = append(errs, c.notifyAlarms(ctx, console, statuses))
+
+	return errors.Join(errs...)
+}
+
+// raisedAlarm es una alarma que se activó desde la consulta anterior
+type raisedAlarm struct {
+	tankID string
+	code   int
+}
+
+// notifyAlarms notifica las alarmas activas que no lo estaban en la consulta anterior. Tras un
+// reinicio de la API se notifican de nuevo las que sigan activas.
+func (c *Collector) notifyAlarms(ctx context.Context, console *Console, statuses []*Status) error {
+	current := make(map[int][]int, len(statuses))
+	for _, status := range statuses {
+		current[status.Tank] = status.Alarms
+	}
+
+	c.mutex.Lock()
+	var raised []raisedAlarm
+	for number, tankID := range console.Tanks {
+		key := fmt.Sprintf("%s/%d", console.Name, number)
+		previous := c.active[key]
+		active := make(map[int]bool, len(current[number]))
+		for _, code := range current[number] {
+			active[code] = true
+			if !previous[code] {
+				raised = append(raised, raisedAlarm{tankID: tankID, code: code})
+			}
+		}
+		c.active[key] = active
+	}
+	c.mutex.Unlock()
+
+	var errs []error
+	for _, alarm := range raised {
+		tank, err := c.tankService.GetTank(ctx, alarm.tankID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tank %s: %w", alarm.tankID, err))
+			continue
+		}
+		c.logger.Warn("ATG alarm raised", "console", console.Name, "tank_id", alarm.tankID, "alarm", alarm.code)
+		if err := c.notifier.Notify(ctx, alarmAlert(console.Name, alarm.code, tank)); err != nil {
+			errs = append(errs, fmt.Errorf("tank %s alarm %d: %w", alarm.tankID, alarm.code, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,157 @@
+package atg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig se devuelve cuando el archivo de consolas no es válido
+var ErrInvalidConfig = errors.New("invalid atg file")
+
+// consoleNamePattern limita los nombres de las consolas a los que pueden usarse en los logs y en
+// los nombres de las tareas
+var consoleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// gallonsToLiters convierte los volúmenes de las consolas configuradas en galones
+const gallonsToLiters = 3.785411784
+
+// Config es la lista de consolas ATG
+type Config struct {
+	Consoles []*Console
+}
+
+// Console es una consola TLS y la asociación de sus tanques con los de la API
+type Console struct {
+	Name         string
+	Address      string // host:puerto de la interfaz serie (TCP o servidor de terminal)
+	SecurityCode string // Código de seguridad de la interfaz serie, si la consola lo exige
+	Interval     time.Duration
+	VolumeScale  float64 // Litros por unidad de volumen de la consola
+	Fahrenheit   bool    // La consola reporta la temperatura en °F
+	Tanks        map[int]string
+}
+
+// fileConfig es el formato del archivo de consolas
+type fileConfig struct {
+	Consoles []consoleConfig `yaml:"consoles"`
+}
+
+type consoleConfig struct {
+	Name            string        `yaml:"name"`
+	Address         string        `yaml:"address"`
+	SecurityCode    string        `yaml:"security_code"`
+	Interval        time.Duration `yaml:"interval"`
+	VolumeUnit      string        `yaml:"volume_unit"`
+	TemperatureUnit string        `yaml:"temperature_unit"`
+	Tanks           []tankConfig  `yaml:"tanks"`
+}
+
+type tankConfig struct {
+	Number int    `yaml:"number"`
+	TankID string `yaml:"tank_id"`
+}
+
+// LoadFile lee el archivo de consolas indicado
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse interpreta un documento YAML con las consolas y sus tanques. Los campos desconocidos se
+// rechazan para que una errata no pase inadvertida.
+func Parse(r io.Reader) (*Config, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file fileConfig
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	config := &Config{}
+	names := make(map[string]bool, len(file.Consoles))
+	for _, entry := range file.Consoles {
+		console, err := entry.console()
+		if err != nil {
+			return nil, fmt.Errorf("%w: console %q: %v", ErrInvalidConfig, entry.Name, err)
+		}
+		if names[console.Name] {
+			return nil, fmt.Errorf("%w: duplicate console %q", ErrInvalidConfig, console.Name)
+		}
+		names[console.Name] = true
+		config.Consoles = append(config.Consoles, console)
+	}
+
+	return config, nil
+}
+
+// console valida la consola y completa los valores predeterminados
+func (c consoleConfig) console() (*Console, error) {
+	if !consoleNamePattern.MatchString(c.Name) {
+		return nil, errors.New("invalid name")
+	}
+	if c.Address == "" {
+		return nil, errors.New("address is required")
+	}
+	if c.Interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	if len(c.SecurityCode) != 0 && len(c.SecurityCode) != 6 {
+		return nil, errors.New("security_code must have 6 characters")
+	}
+
+	console := &Console{
+		Name:         c.Name,
+		Address:      c.Address,
+		SecurityCode: c.SecurityCode,
+		Interval:     c.Interval,
+		Tanks:        make(map[int]string, len(c.Tanks)),
+	}
+
+	switch strings.ToLower(c.VolumeUnit) {
+	case "", "liters":
+		console.VolumeScale = 1
+	case "gallons":
+		console.VolumeScale = gallonsToLiters
+	default:
+		return nil, fmt.Errorf("unknown volume_unit %q", c.VolumeUnit)
+	}
+
+	switch strings.ToUpper(c.TemperatureUnit) {
+	case "", "C":
+	case "F":
+		console.Fahrenheit = true
+	default:
+		return nil, fmt.Errorf("unknown temperature_unit %q", c.TemperatureUnit)
+	}
+
+	if len(c.Tanks) == 0 {
+		return nil, errors.New("at least one tank is required")
+	}
+	for _, tank := range c.Tanks {
+		if tank.Number < 1 || tank.Number > 99 {
+			return nil, fmt.Errorf("invalid tank number %d", tank.Number)
+		}
+		if tank.TankID == "" {
+			return nil, fmt.Errorf("tank %d without tank_id", tank.Number)
+		}
+		if _, repeated := console.Tanks[tank.Number]; repeated {
+			return nil, fmt.Errorf("duplicate tank number %d", tank.Number)
+		}
+		console.Tanks[tank.Number] = tank.TankID
+	}
+
+	return console, nil
+}
@@ -0,0 +1,206 @@
+// Package atg integra las consolas de medición automática de tanques (ATG) Veeder-Root TLS-350 y
+// TLS-450 mediante su protocolo serie en formato de computadora, por TCP o a través de un
+// servidor de terminal. Del inventario de cada tanque se obtienen mediciones y de su estado, las
+// alarmas activas.
+package atg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Caracteres de control del protocolo
+const (
+	soh = 0x01 // Inicio de la solicitud y de la respuesta
+	etx = 0x03 // Fin de la respuesta
+)
+
+// Comandos en formato de computadora; 00 pide todos los tanques
+const (
+	CommandInventory = "i20100" // Inventario de los tanques
+	CommandStatus    = "i20500" // Alarmas activas de los tanques
+)
+
+// Errores del protocolo
+var (
+	ErrMalformed          = errors.New("malformed atg response")
+	ErrChecksum           = errors.New("atg response checksum mismatch")
+	ErrUnsupportedCommand = errors.New("atg console rejected the command")
+)
+
+// timestampLayout es el formato de la fecha de las respuestas (AAMMDDhhmm, hora local de la consola)
+const timestampLayout = "0601021504"
+
+// Inventory es el inventario de un tanque. Los volúmenes están en las unidades configuradas en
+// la consola (galones o litros) y la temperatura en °F o °C.
+type Inventory struct {
+	Tank        int
+	Product     byte
+	Status      uint16 // Bits de estado: entrega en curso, prueba de fugas en curso...
+	Volume      float64
+	TCVolume    float64 // Volumen compensado por temperatura
+	Ullage      float64 // Espacio libre
+	Height      float64
+	Water       float64 // Altura del agua en el fondo
+	Temperature float64
+	WaterVolume float64
+}
+
+// Status son las alarmas activas de un tanque, con los códigos de la consola
+type Status struct {
+	Tank   int
+	Alarms []int
+}
+
+// Frame compone la solicitud de un comando, precedido por el código de seguridad si la consola
+// lo exige
+func Frame(securityCode, command string) []byte {
+	return append([]byte{soh}, securityCode+command...)
+}
+
+// Checksum calcula la suma de control de la respuesta: el complemento a dos de la suma de los
+// bytes desde SOH hasta && inclusive, de modo que la suma total sea 0 módulo 65536
+func Checksum(data []byte) uint16 {
+	var sum uint16
+	for _, b := range data {
+		sum += uint16(b)
+	}
+	return -sum
+}
+
+// ParseResponse valida una respuesta completa (de SOH a ETX) al comando indicado y devuelve sus
+// datos, sin el eco del comando, la suma de control ni los caracteres de control
+func ParseResponse(frame []byte, command string) ([]byte, error) {
+	if len(frame) < 2 || frame[0] != soh || frame[len(frame)-1] != etx {
+		return nil, fmt.Errorf("%w: missing SOH or ETX", ErrMalformed)
+	}
+	body := frame[1 : len(frame)-1]
+	if len(body) >= 4 && string(body[:4]) == "9999" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCommand, command)
+	}
+
+	// ...datos&&CCCC
+	if len(body) < len(command)+6 || string(body[len(body)-6:len(body)-4]) != "&&" {
+		return nil, fmt.Errorf("%w: missing checksum", ErrMalformed)
+	}
+	expected, err := strconv.ParseUint(string(body[len(body)-4:]), 16, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid checksum", ErrMalformed)
+	}
+	if Checksum(frame[:len(frame)-5]) != uint16(expected) {
+		return nil, ErrChecksum
+	}
+
+	if string(body[:len(command)]) != command {
+		return nil, fmt.Errorf("%w: response to %q instead of %q", ErrMalformed, body[:len(command)], command)
+	}
+	return body[len(command) : len(body)-6], nil
+}
+
+// ParseInventory interpreta los datos de la respuesta a i201: la fecha y, por cada tanque, su
+// número, producto, estado y los campos de inventario como flotantes IEEE 754 en hexadecimal
+func ParseInventory(data []byte, location *time.Location) (time.Time, []*Inventory, error) {
+	timestamp, rest, err := parseTimestamp(data, location)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	var inventories []*Inventory
+	for len(rest) > 0 {
+		if len(rest) < 9 {
+			return time.Time{}, nil, fmt.Errorf("%w: truncated tank block", ErrMalformed)
+		}
+		tank, err := strconv.Atoi(string(rest[0:2]))
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("%w: invalid tank number", ErrMalformed)
+		}
+		status, err := strconv.ParseUint(string(rest[3:7]), 16, 16)
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("%w: invalid tank status", ErrMalformed)
+		}
+		count, err := strconv.ParseUint(string(rest[7:9]), 16, 8)
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("%w: invalid field count", ErrMalformed)
+		}
+		inventory := &Inventory{Tank: tank, Product: rest[2], Status: uint16(status)}
+		rest = rest[9:]
+
+		if len(rest) < int(count)*8 {
+			return time.Time{}, nil, fmt.Errorf("%w: truncated inventory fields", ErrMalformed)
+		}
+		fields := []*float64{
+			&inventory.Volume, &inventory.TCVolume, &inventory.Ullage, &inventory.Height,
+			&inventory.Water, &inventory.Temperature, &inventory.WaterVolume,
+		}
+		for i := 0; i < int(count); i++ {
+			bits, err := strconv.ParseUint(string(rest[:8]), 16, 32)
+			if err != nil {
+				return time.Time{}, nil, fmt.Errorf("%w: invalid inventory field", ErrMalformed)
+			}
+			// Las consolas más nuevas pueden añadir campos al final; se ignoran
+			if i < len(fields) {
+				*fields[i] = float64(math.Float32frombits(uint32(bits)))
+			}
+			rest = rest[8:]
+		}
+		inventories = append(inventories, inventory)
+	}
+
+	return timestamp, inventories, nil
+}
+
+// ParseStatus interpreta los datos de la respuesta a i205: la fecha y, por cada tanque, su
+// número, el número de alarmas activas y el código de cada una
+func ParseStatus(data []byte, location *time.Location) (time.Time, []*Status, error) {
+	timestamp, rest, err := parseTimestamp(data, location)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	var statuses []*Status
+	for len(rest) > 0 {
+		if len(rest) < 4 {
+			return time.Time{}, nil, fmt.Errorf("%w: truncated tank block", ErrMalformed)
+		}
+		tank, err := strconv.Atoi(string(rest[0:2]))
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("%w: invalid tank number", ErrMalformed)
+		}
+		count, err := strconv.ParseUint(string(rest[2:4]), 16, 8)
+		if err != nil {
+			return time.Time{}, nil, fmt.Errorf("%w: invalid alarm count", ErrMalformed)
+		}
+		rest = rest[4:]
+
+		if len(rest) < int(count)*2 {
+			return time.Time{}, nil, fmt.Errorf("%w: truncated alarms", ErrMalformed)
+		}
+		status := &Status{Tank: tank}
+		for i := 0; i < int(count); i++ {
+			alarm, err := strconv.Atoi(string(rest[:2]))
+			if err != nil {
+				return time.Time{}, nil, fmt.Errorf("%w: invalid alarm code", ErrMalformed)
+			}
+			status.Alarms = append(status.Alarms, alarm)
+			rest = rest[2:]
+		}
+		statuses = append(statuses, status)
+	}
+
+	return timestamp, statuses, nil
+}
+
+// parseTimestamp lee la fecha con la que empiezan los datos de las respuestas
+func parseTimestamp(data []byte, location *time.Location) (time.Time, []byte, error) {
+	if len(data) < len(timestampLayout) {
+		return time.Time{}, nil, fmt.Errorf("%w: missing timestamp", ErrMalformed)
+	}
+	timestamp, err := time.ParseInLocation(timestampLayout, string(data[:len(timestampLayout)]), location)
+	if err != nil {
+		return time.Time{}, nil, fmt.Errorf("%w: invalid timestamp", ErrMalformed)
+	}
+	return timestamp, data[len(timestampLayout):], nil
+}
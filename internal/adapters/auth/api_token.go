@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// APITokenAuthenticator autentica los tokens emitidos por la propia aplicación y delega los
+// demás en next, normalmente el proveedor OIDC. Sin next solo se admiten tokens de la API.
+type APITokenAuthenticator struct {
+	tokenRepo ports.APITokenRepository
+	next      ports.Authenticator
+}
+
+// NewAPITokenAuthenticator crea un autenticador de tokens de la API
+func NewAPITokenAuthenticator(tokenRepo ports.APITokenRepository, next ports.Authenticator) *APITokenAuthenticator {
+	return &APITokenAuthenticator{
+		tokenRepo: tokenRepo,
+		next:      next,
+	}
+}
+
+// Authenticate busca el token por su resumen y devuelve la identidad con el rol del token
+func (a *APITokenAuthenticator) Authenticate(ctx context.Context, token string) (*domain.Principal, error) {
+	if !domain.IsAPIToken(token) {
+		if a.next == nil {
+			return nil, ErrInvalidToken
+		}
+		return a.next.Authenticate(ctx, token)
+	}
+
+	stored, err := a.tokenRepo.GetTokenByHash(ctx, domain.HashAPIToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, ErrInvalidToken
+	}
+	return stored.Principal(), nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SiteVerifyCaptcha valida las respuestas CAPTCHA con el endpoint siteverify del proveedor. El
+// protocolo es común a reCAPTCHA, hCaptcha y Cloudflare Turnstile. Implementa ports.CaptchaVerifier.
+type SiteVerifyCaptcha struct {
+	verifyURL string
+	secret    string
+	client    *http.Client
+}
+
+// NewSiteVerifyCaptcha crea un nuevo verificador CAPTCHA con la URL de verificación del proveedor
+// (p. ej. https://www.google.com/recaptcha/api/siteverify) y la clave secreta del sitio
+func NewSiteVerifyCaptcha(verifyURL, secret string) *SiteVerifyCaptcha {
+	return &SiteVerifyCaptcha{
+		verifyURL: verifyURL,
+		secret:    secret,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// VerifyCaptcha envía la respuesta del cliente al proveedor e indica si la aceptó
+func (c *SiteVerifyCaptcha) VerifyCaptcha(ctx context.Context, response, remoteIP string) (bool, error) {
+	form := url.Values{"secret": {c.secret}, "response": {response}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha verification failed with status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("invalid captcha verification response: %w", err)
+	}
+	return result.Success, nil
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"net/http"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/i18n"
+	"monitor-tanques/pkg/logger"
+)
+
+// ClientCertSource identifica a los principales autenticados con un certificado de cliente (mTLS)
+const ClientCertSource = "mtls"
+
+// ClientCertMiddleware autentica las solicitudes que llegan con un certificado de cliente
+// verificado como el equipo de campo cuyo ID coincide con el CN del certificado. El equipo envía
+// mediciones con el rol operator, pero solo de su tanque y solo por las rutas que acepta allowed.
+// Un certificado válido de un equipo no registrado responde 403. El equipo se consulta en cada
+// solicitud para aplicar al instante los cambios de tanque. Las solicitudes sin certificado
+// continúan sin cambios.
+func ClientCertMiddleware(devices ports.FieldDeviceRepository, allowed func(*http.Request) bool, logger logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.PeerCertificates) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			deviceID := r.TLS.PeerCertificates[0].Subject.CommonName
+			if !allowed(r) {
+				logger.Warn("Client certificate used outside ingestion", "device_id", deviceID, "path", r.URL.Path, "method", r.Method)
+				http.Error(w, i18n.Translate(i18n.FromContext(r.Context()), "Ruta no permitida con certificado de cliente"), http.StatusForbidden)
+				return
+			}
+
+			var device *domain.FieldDevice
+			var err error
+			if deviceID != "" {
+				device, err = devices.GetFieldDevice(r.Context(), deviceID)
+			}
+			if err != nil {
+				logger.Error("Failed to get field device", "error", err, "device_id", deviceID)
+				http.Error(w, i18n.Translate(i18n.FromContext(r.Context()), "Error al obtener el equipo de campo"), http.StatusInternalServerError)
+				return
+			}
+			if device == nil {
+				logger.Warn("Client certificate of unknown device", "device_id", deviceID, "path", r.URL.Path)
+				http.Error(w, i18n.Translate(i18n.FromContext(r.Context()), "Equipo no registrado"), http.StatusForbidden)
+				return
+			}
+
+			principal := &domain.Principal{
+				Subject: "device:" + device.ID,
+				Name:    device.ID,
+				Roles:   []string{domain.RoleOperator},
+				Source:  ClientCertSource,
+				TankID:  device.TankID,
+			}
+			next.ServeHTTP(w, r.WithContext(domain.ContextWithPrincipal(r.Context(), principal)))
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// Errores de validación de tokens
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// jwtHeader contiene los campos de la cabecera JWT que necesitamos
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// parsedJWT es un token dividido en sus partes, todavía sin verificar
+type parsedJWT struct {
+	header       jwtHeader
+	claims       map[string]interface{}
+	signingInput string
+	signature    []byte
+}
+
+// parseJWT decodifica un token JWT compacto sin verificar la firma
+func parseJWT(token string) (*parsedJWT, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	parsed := &parsedJWT{
+		signingInput: parts[0] + "." + parts[1],
+		signature:    signature,
+	}
+
+	if err := json.Unmarshal(headerBytes, &parsed.header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(claimsBytes)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&parsed.claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return parsed, nil
+}
+
+// verifyRS256 comprueba la firma RS256 del token con la clave pública indicada
+func (t *parsedJWT) verifyRS256(key *rsa.PublicKey) error {
+	if t.header.Algorithm != "RS256" {
+		return ErrInvalidToken
+	}
+
+	digest := sha256.Sum256([]byte(t.signingInput))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], t.signature); err != nil {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+// stringClaim devuelve un claim de texto, o vacío si no existe
+func (t *parsedJWT) stringClaim(name string) string {
+	value, _ := t.claims[name].(string)
+	return value
+}
+
+// numericClaim devuelve un claim numérico (p. ej. exp), o 0 si no existe
+func (t *parsedJWT) numericClaim(name string) int64 {
+	number, ok := t.claims[name].(json.Number)
+	if !ok {
+		return 0
+	}
+
+	value, err := number.Int64()
+	if err != nil {
+		floatValue, err := number.Float64()
+		if err != nil {
+			return 0
+		}
+		return int64(floatValue)
+	}
+	return value
+}
+
+// stringListClaim devuelve un claim que puede ser un texto o una lista de textos (p. ej. aud, groups)
+func (t *parsedJWT) stringListClaim(name string) []string {
+	switch value := t.claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if text, ok := item.(string); ok {
+				result = append(result, text)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// jsonWebKey es una clave pública publicada en el JWKS del proveedor de identidad
+type jsonWebKey struct {
+	KeyType  string `json:"kty"`
+	KeyID    string `json:"kid"`
+	Use      string `json:"use"`
+	Modulus  string `json:"n"`
+	Exponent string `json:"e"`
+}
+
+// rsaPublicKey convierte la clave JWK en una clave pública RSA
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	if k.KeyType != "RSA" {
+		return nil, errors.New("unsupported key type")
+	}
+
+	modulus, err := base64.RawURLEncoding.DecodeString(k.Modulus)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent, err := base64.RawURLEncoding.DecodeString(k.Exponent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
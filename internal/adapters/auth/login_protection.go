@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/ports"
+)
+
+// LoginProtection frena los ataques de fuerza bruta contra la autenticación, identificando a
+// cada cliente por su dirección IP
+type LoginProtection struct {
+	Guard ports.LoginGuard
+
+	// TrustForwardedFor toma la dirección del cliente de la última entrada de X-Forwarded-For,
+	// la que añade el proxy inverso. Solo debe activarse detrás de un proxy que la fije; si no,
+	// un atacante elegiría la dirección a la que se le cuentan los fallos.
+	TrustForwardedFor bool
+}
+
+// ClientIP devuelve la dirección IP del cliente de la solicitud
+func (p *LoginProtection) ClientIP(r *http.Request) string {
+	if p.TrustForwardedFor {
+		if header := r.Header.Values("X-Forwarded-For"); len(header) > 0 {
+			entries := strings.Split(header[len(header)-1], ",")
+			if ip := strings.TrimSpace(entries[len(entries)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// SetRetryAfter indica al cliente bloqueado cuántos segundos debe esperar antes de reintentarlo
+func SetRetryAfter(w http.ResponseWriter, wait time.Duration) {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/i18n"
+	"monitor-tanques/pkg/logger"
+)
+
+// Middleware autentica cada solicitud con un token Bearer y comprueba que el principal tenga
+// el rol necesario. Las rutas cuyo prefijo esté en publicPaths no requieren autenticación, y las
+// solicitudes que ya traen un principal (p. ej. de un enlace firmado) no necesitan token. Con
+// protection, los tokens inválidos cuentan como intentos fallidos y un cliente bloqueado recibe
+// 429 sin que se compruebe su token.
+func Middleware(authenticator ports.Authenticator, publicPaths []string, protection *LoginProtection, logger logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range publicPaths {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			// Un enlace firmado ya identificó al principal; solo falta comprobar su rol
+			principal := domain.PrincipalFromContext(r.Context())
+			if principal == nil {
+				token, ok := bearerToken(r)
+				if !ok {
+					w.Header().Set("WWW-Authenticate", "Bearer")
+					http.Error(w, i18n.Translate(i18n.FromContext(r.Context()), "Autenticación requerida"), http.StatusUnauthorized)
+					return
+				}
+
+				attempt := domain.LoginAttempt{Method: domain.LoginMethodToken, Path: r.URL.Path}
+				if protection != nil {
+					attempt.ClientIP = protection.ClientIP(r)
+					if wait := protection.Guard.Check(r.Context(), attempt.ClientIP); wait > 0 {
+						attempt.Reason = "locked out"
+						if err := protection.Guard.RecordFailure(r.Context(), attempt); err != nil {
+							logger.Error("Failed to record login failure", "error", err, "client_ip", attempt.ClientIP)
+						}
+						logger.Warn("Authentication blocked", "client_ip", attempt.ClientIP, "path", r.URL.Path, "retry_after", wait)
+						SetRetryAfter(w, wait)
+						http.Error(w, i18n.Translate(i18n.FromContext(r.Context()), "Demasiados intentos fallidos; inténtelo más tarde"), http.StatusTooManyRequests)
+						return
+					}
+				}
+
+				authenticated, err := authenticator.Authenticate(r.Context(), token)
+				if err != nil {
+					logger.Warn("Authentication failed", "error", err, "path", r.URL.Path, "client_ip", attempt.ClientIP)
+					if protection != nil {
+						attempt.Reason = err.Error()
+						if err := protection.Guard.RecordFailure(r.Context(), attempt); err != nil {
+							logger.Error("Failed to record login failure", "error", err, "client_ip", attempt.ClientIP)
+						}
+					}
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+					http.Error(w, i18n.Translate(i18n.FromContext(r.Context()), "Token inválido"), http.StatusUnauthorized)
+					return
+				}
+				if protection != nil {
+					attempt.Subject = authenticated.Subject
+					if err := protection.Guard.RecordSuccess(r.Context(), attempt); err != nil {
+						logger.Error("Failed to record login success", "error", err, "client_ip", attempt.ClientIP)
+					}
+				}
+				principal = authenticated
+			}
+
+			if !principal.HasRole(RequiredRole(r)) {
+				logger.Warn("Access denied", "subject", principal.Subject, "path", r.URL.Path, "method", r.Method)
+				http.Error(w, i18n.Translate(i18n.FromContext(r.Context()), "Permisos insuficientes"), http.StatusForbidden)
+				return
+			}
+			if scope := RequiredScope(r); !principal.HasScope(scope) {
+				logger.Warn("Access denied by token scope", "subject", principal.Subject, "path", r.URL.Path, "method", r.Method, "scope", scope)
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+				http.Error(w, i18n.Translate(i18n.FromContext(r.Context()), "El token no tiene el alcance necesario"), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(domain.ContextWithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequiredRole devuelve el rol mínimo necesario para la solicitud: las rutas de administración y
+// la API de sincronización, que replica la configuración de todos los tanques, requieren admin,
+// las modificaciones operator y las consultas viewer. Firmar un enlace solo requiere viewer
+// porque el enlace conserva los roles de quien lo firma, cualquier usuario puede cerrar sus
+// propias sesiones y gestionar sus vistas guardadas, y las preguntas de los asistentes de voz
+// solo consultan.
+func RequiredRole(r *http.Request) string {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/admin"), strings.HasPrefix(r.URL.Path, "/api/sync/"):
+		return domain.RoleAdmin
+	case r.URL.Path == "/api/signed-urls", r.URL.Path == "/api/auth/logout-all", r.URL.Path == "/api/voice/query",
+		isSavedViewPath(r.URL.Path):
+		return domain.RoleViewer
+	case r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions:
+		return domain.RoleViewer
+	default:
+		return domain.RoleOperator
+	}
+}
+
+// measurementRoutes son las rutas (método y plantilla) que envían mediciones
+var measurementRoutes = map[string]bool{
+	"POST /api/tanks/{id}/measurements":          true,
+	"POST /api/tanks/{id}/measurements/backfill": true,
+}
+
+// RequiredScope devuelve el alcance que necesita un token con alcances para la solicitud: las
+// rutas de administración y la API de sincronización requieren admin:config, el envío de
+// mediciones write:measurements, el resto de modificaciones write:tanks y las consultas y las
+// vistas guardadas read:tanks
+func RequiredScope(r *http.Request) string {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/admin"), strings.HasPrefix(r.URL.Path, "/api/sync/"):
+		return domain.ScopeAdminConfig
+	case r.URL.Path == "/api/signed-urls", r.URL.Path == "/api/auth/logout-all", r.URL.Path == "/api/voice/query",
+		isSavedViewPath(r.URL.Path):
+		return domain.ScopeReadTanks
+	case r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions:
+		return domain.ScopeReadTanks
+	}
+
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil && measurementRoutes[r.Method+" "+template] {
+			return domain.ScopeWriteMeasurements
+		}
+	}
+	return domain.ScopeWriteTanks
+}
+
+// isSavedViewPath indica si la ruta es la de las vistas guardadas, que solo afectan a su usuario
+func isSavedViewPath(path string) bool {
+	return path == "/api/views" || strings.HasPrefix(path, "/api/views/")
+}
+
+// bearerToken extrae el token de la cabecera Authorization
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
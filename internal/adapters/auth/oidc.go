@@ -0,0 +1,349 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// OIDCConfig contiene la configuración del proveedor de identidad externo (Keycloak, Azure AD, ...)
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	GroupsClaim  string            // Claim que contiene los grupos del usuario, por defecto "groups"
+	RoleMapping  map[string]string // Grupo del IdP → rol de la aplicación
+
+	// Claim con la organización del usuario en las instalaciones compartidas, por defecto "org_id"
+	OrganizationClaim string
+}
+
+// oidcDiscovery es el documento .well-known/openid-configuration del proveedor
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// TokenResponse es la respuesta del endpoint de tokens del proveedor
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// OIDCProvider valida los tokens emitidos por el proveedor de identidad y gestiona el flujo
+// authorization code. Implementa ports.Authenticator.
+type OIDCProvider struct {
+	config    OIDCConfig
+	client    *http.Client
+	mutex     sync.RWMutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+	keysAt    time.Time
+}
+
+// NewOIDCProvider crea un nuevo proveedor OIDC. El descubrimiento se realiza en el primer uso
+// para que la API pueda arrancar aunque el proveedor no esté disponible todavía.
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.OrganizationClaim == "" {
+		config.OrganizationClaim = "org_id"
+	}
+
+	return &OIDCProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   make(map[string]*rsa.PublicKey),
+	}
+}
+
+// Authenticate valida un token de acceso o de identidad y devuelve el principal con los roles mapeados
+func (p *OIDCProvider) Authenticate(ctx context.Context, token string) (*domain.Principal, error) {
+	parsed, err := parseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := p.publicKey(ctx, parsed.header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := parsed.verifyRS256(key); err != nil {
+		return nil, err
+	}
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.stringClaim("iss") != discovery.Issuer {
+		return nil, ErrInvalidToken
+	}
+
+	if !p.audienceMatches(parsed) {
+		return nil, ErrInvalidToken
+	}
+
+	if exp := parsed.numericClaim("exp"); exp == 0 || time.Now().Unix() >= exp {
+		return nil, ErrTokenExpired
+	}
+
+	return &domain.Principal{
+		Subject:      parsed.stringClaim("sub"),
+		Name:         firstNonEmpty(parsed.stringClaim("name"), parsed.stringClaim("preferred_username")),
+		Email:        parsed.stringClaim("email"),
+		Roles:        p.mapRoles(parsed.stringListClaim(p.config.GroupsClaim)),
+		Organization: parsed.stringClaim(p.config.OrganizationClaim),
+		Source:       "oidc",
+	}, nil
+}
+
+// AuthCodeURL construye la URL de inicio de sesión en el proveedor
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.config.ClientID)
+	params.Set("redirect_uri", p.config.RedirectURL)
+	params.Set("scope", "openid profile email")
+	params.Set("state", state)
+
+	return discovery.AuthorizationEndpoint + "?" + params.Encode(), nil
+}
+
+// Exchange intercambia el código de autorización por los tokens del proveedor
+func (p *OIDCProvider) Exchange(ctx context.Context, code string) (*TokenResponse, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("client_secret", p.config.ClientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var tokens TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, err
+	}
+
+	// Validamos el id_token antes de entregarlo al cliente
+	if _, err := p.Authenticate(ctx, tokens.IDToken); err != nil {
+		return nil, err
+	}
+
+	return &tokens, nil
+}
+
+// discover obtiene (y cachea) el documento de descubrimiento del proveedor
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mutex.RLock()
+	discovery := p.discovery
+	p.mutex.RUnlock()
+	if discovery != nil {
+		return discovery, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+
+	var document oidcDiscovery
+	if err := p.getJSON(ctx, wellKnown, &document); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+
+	p.mutex.Lock()
+	p.discovery = &document
+	p.mutex.Unlock()
+
+	return &document, nil
+}
+
+// publicKey devuelve la clave del JWKS con el kid indicado, recargando el JWKS si no se conoce
+// (los proveedores rotan sus claves periódicamente)
+func (p *OIDCProvider) publicKey(ctx context.Context, keyID string) (*rsa.PublicKey, error) {
+	p.mutex.RLock()
+	key, ok := p.keys[keyID]
+	recentlyLoaded := time.Since(p.keysAt) < time.Minute
+	p.mutex.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	// Evitamos que tokens con kid desconocido provoquen una recarga en cada solicitud
+	if recentlyLoaded {
+		return nil, ErrInvalidToken
+	}
+
+	if err := p.loadKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	key, ok = p.keys[keyID]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	return key, nil
+}
+
+// loadKeys descarga el JWKS del proveedor
+func (p *OIDCProvider) loadKeys(ctx context.Context) error {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return fmt.Errorf("oidc jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	p.mutex.Lock()
+	p.keys = keys
+	p.keysAt = time.Now()
+	p.mutex.Unlock()
+
+	return nil
+}
+
+// getJSON descarga y decodifica un documento JSON
+func (p *OIDCProvider) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// audienceMatches comprueba que el token esté emitido para este cliente
+func (p *OIDCProvider) audienceMatches(token *parsedJWT) bool {
+	if p.config.ClientID == "" {
+		return true
+	}
+
+	for _, audience := range token.stringListClaim("aud") {
+		if audience == p.config.ClientID {
+			return true
+		}
+	}
+
+	// Azure AD y Keycloak incluyen el cliente en azp en los tokens de acceso
+	return token.stringClaim("azp") == p.config.ClientID
+}
+
+// mapRoles traduce los grupos del proveedor a roles de la aplicación
+func (p *OIDCProvider) mapRoles(groups []string) []string {
+	roles := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, group := range groups {
+		// Keycloak antepone "/" a la ruta del grupo
+		role, ok := p.config.RoleMapping[strings.TrimPrefix(group, "/")]
+		if !ok {
+			role, ok = p.config.RoleMapping[group]
+		}
+		if ok && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+// ParseRoleMapping interpreta una lista "grupo:rol,grupo2:rol2" validando los roles
+func ParseRoleMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if strings.TrimSpace(value) == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		group, role, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || group == "" || !domain.IsValidRole(role) {
+			return nil, errors.New("invalid role mapping: " + pair)
+		}
+		mapping[group] = role
+	}
+
+	return mapping, nil
+}
+
+// firstNonEmpty devuelve el primer valor no vacío
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// SessionAuthenticator autentica los tokens de acceso de las sesiones iniciadas en la aplicación
+// y delega los demás en next, normalmente el proveedor OIDC. Sin next solo se admiten sesiones.
+type SessionAuthenticator struct {
+	sessions ports.SessionService
+	next     ports.Authenticator
+}
+
+// NewSessionAuthenticator crea un autenticador de tokens de sesión
+func NewSessionAuthenticator(sessions ports.SessionService, next ports.Authenticator) *SessionAuthenticator {
+	return &SessionAuthenticator{
+		sessions: sessions,
+		next:     next,
+	}
+}
+
+// Authenticate valida el token con la sesión a la que pertenece, que puede haberse revocado
+func (a *SessionAuthenticator) Authenticate(ctx context.Context, token string) (*domain.Principal, error) {
+	if !domain.IsSessionToken(token) {
+		if a.next == nil {
+			return nil, ErrInvalidToken
+		}
+		return a.next.Authenticate(ctx, token)
+	}
+	return a.sessions.Authenticate(ctx, token)
+}
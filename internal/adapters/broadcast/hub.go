@@ -0,0 +1,294 @@
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+	"monitor-tanques/pkg/metrics"
+)
+
+// Motivos por los que el hub cierra una suscripción
+var (
+	ErrSlowSubscriber = errors.New("subscriber too slow")
+	ErrHubClosed      = errors.New("hub closed")
+	ErrTooManyTopics  = fmt.Errorf("at most %d topics per subscriber", domain.MaxLiveTopics)
+)
+
+// Config contiene los límites de las suscripciones
+type Config struct {
+	SendBuffer int // Eventos pendientes por suscriptor antes de desconectarlo por lento
+}
+
+// Metrics agrupa las métricas del reparto de eventos en tiempo real, etiquetadas por transporte
+// (websocket o sse)
+type Metrics struct {
+	Subscribers *metrics.GaugeVec   // Suscriptores conectados
+	Queued      *metrics.CounterVec // Eventos encolados para algún suscriptor
+	Evicted     *metrics.CounterVec // Suscriptores desconectados por no consumir sus eventos a tiempo
+}
+
+// NewMetrics registra las métricas del reparto de eventos en tiempo real
+func NewMetrics(registry *metrics.Registry) *Metrics {
+	return &Metrics{
+		Subscribers: registry.NewGaugeVec(
+			"tank_live_subscribers",
+			"Clientes conectados a los eventos en tiempo real.",
+			"transport",
+		),
+		Queued: registry.NewCounterVec(
+			"tank_live_events_queued_total",
+			"Eventos en tiempo real encolados para los clientes suscritos.",
+			"transport",
+		),
+		Evicted: registry.NewCounterVec(
+			"tank_live_subscribers_evicted_total",
+			"Clientes desconectados por no consumir sus eventos a tiempo.",
+			"transport",
+		),
+	}
+}
+
+// Hub implementa ports.LiveEventPublisher: reparte cada evento entre los suscriptores de alguno
+// de sus temas, con un búfer acotado por suscriptor. Los adaptadores de transporte (WebSocket,
+// SSE) solo leen los eventos de su suscripción y los escriben en su conexión.
+type Hub struct {
+	accessService ports.AccessService
+	config        Config
+	metrics       *Metrics // nil sin métricas
+	logger        logger.Logger
+
+	mutex       sync.RWMutex
+	subscribers map[*Subscriber]bool
+	closed      bool
+}
+
+// NewHub crea un repartidor de eventos sin suscriptores
+func NewHub(accessService ports.AccessService, config Config, metrics *Metrics, logger logger.Logger) *Hub {
+	return &Hub{
+		accessService: accessService,
+		config:        config,
+		metrics:       metrics,
+		logger:        logger,
+		subscribers:   make(map[*Subscriber]bool),
+	}
+}
+
+// Subscribe registra un suscriptor sin temas en nombre del principal, que puede ser nil sin
+// autenticación. Quien lo crea debe llamar a Remove al terminar.
+func (h *Hub) Subscribe(transport string, principal *domain.Principal) *Subscriber {
+	ctx := context.Background()
+	if principal != nil {
+		ctx = domain.ContextWithPrincipal(ctx, principal)
+	}
+	s := &Subscriber{
+		transport: transport,
+		principal: principal,
+		ctx:       ctx,
+		topics:    make(map[string]bool),
+		events:    make(chan *domain.LiveEvent, h.config.SendBuffer),
+		done:      make(chan struct{}),
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed {
+		s.close(ErrHubClosed)
+		return s
+	}
+	h.subscribers[s] = true
+	if h.metrics != nil {
+		h.metrics.Subscribers.Add(1, transport)
+	}
+	return s
+}
+
+// Remove da de baja al suscriptor y cierra su suscripción
+func (h *Hub) Remove(s *Subscriber) {
+	h.mutex.Lock()
+	if h.subscribers[s] {
+		delete(h.subscribers, s)
+		if h.metrics != nil {
+			h.metrics.Subscribers.Add(-1, s.transport)
+		}
+	}
+	h.mutex.Unlock()
+
+	s.close(nil)
+}
+
+// HasSubscribers indica si hay algún suscriptor conectado
+func (h *Hub) HasSubscribers() bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.subscribers) > 0
+}
+
+// Publish encola el evento para los suscriptores de alguno de sus temas. Nunca bloquea: un
+// suscriptor con el búfer lleno se desconecta, ya que perdería eventos sin saberlo.
+func (h *Hub) Publish(event *domain.LiveEvent) {
+	topics := event.Topics()
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for s := range h.subscribers {
+		if !s.subscribed(topics) {
+			continue
+		}
+		select {
+		case <-s.done:
+		case s.events <- event:
+			if h.metrics != nil {
+				h.metrics.Queued.Inc(s.transport)
+			}
+		default:
+			h.logger.Warn("Live subscriber too slow, disconnecting", "transport", s.transport, "subject", domain.SubjectOf(s.principal))
+			if h.metrics != nil {
+				h.metrics.Evicted.Inc(s.transport)
+			}
+			s.close(ErrSlowSubscriber)
+		}
+	}
+}
+
+// Close cierra todas las suscripciones, p. ej. al apagar el servidor. Las suscripciones
+// posteriores nacen cerradas.
+func (h *Hub) Close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.closed = true
+	for s := range h.subscribers {
+		s.close(ErrHubClosed)
+	}
+}
+
+// Visible aplica las concesiones de acceso del suscriptor al tanque del evento. Los eventos sin
+// tanque solo llegan a los administradores y a las conexiones sin autenticación.
+func (h *Hub) Visible(s *Subscriber, event *domain.LiveEvent) bool {
+	if s.principal == nil || s.principal.HasRole(domain.RoleAdmin) {
+		return true
+	}
+	if event.Tank == nil {
+		return false
+	}
+
+	allowed, err := h.accessService.CanAccessTank(s.ctx, event.Tank)
+	if err != nil {
+		h.logger.Warn("Failed to check live event access", "error", err, "tank_id", event.TankID)
+		return false
+	}
+	return allowed
+}
+
+// Subscriber es la suscripción de un cliente en tiempo real: sus temas y su búfer de eventos
+type Subscriber struct {
+	transport string
+	principal *domain.Principal
+	ctx       context.Context // Contexto con el principal para comprobar su acceso
+
+	mutex  sync.RWMutex
+	topics map[string]bool
+
+	events    chan *domain.LiveEvent
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// Principal devuelve el usuario de la suscripción, o nil sin autenticación
+func (s *Subscriber) Principal() *domain.Principal {
+	return s.principal
+}
+
+// Events devuelve los eventos encolados para el suscriptor, aún sin filtrar con Hub.Visible
+func (s *Subscriber) Events() <-chan *domain.LiveEvent {
+	return s.events
+}
+
+// Done se cierra cuando termina la suscripción
+func (s *Subscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err devuelve el motivo del cierre: ErrSlowSubscriber, ErrHubClosed o nil si la cerró el cliente
+func (s *Subscriber) Err() error {
+	<-s.done
+	return s.err
+}
+
+// Subscribe añade los temas a la suscripción y devuelve todos sus temas. Si algún tema no es
+// válido o se supera domain.MaxLiveTopics, la suscripción no cambia.
+func (s *Subscriber) Subscribe(topics []string) ([]string, error) {
+	for _, topic := range topics {
+		if err := domain.ValidateLiveTopic(topic); err != nil {
+			return nil, fmt.Errorf("%w: %q", err, topic)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	added := 0
+	for _, topic := range topics {
+		if !s.topics[topic] {
+			added++
+		}
+	}
+	if len(s.topics)+added > domain.MaxLiveTopics {
+		return nil, ErrTooManyTopics
+	}
+	for _, topic := range topics {
+		s.topics[topic] = true
+	}
+	return s.sortedTopicsLocked(), nil
+}
+
+// Unsubscribe quita los temas de la suscripción y devuelve los que quedan
+func (s *Subscriber) Unsubscribe(topics []string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, topic := range topics {
+		delete(s.topics, topic)
+	}
+	return s.sortedTopicsLocked()
+}
+
+// sortedTopicsLocked devuelve los temas ordenados. Requiere tener el mutex.
+func (s *Subscriber) sortedTopicsLocked() []string {
+	topics := make([]string, 0, len(s.topics))
+	for topic := range s.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// subscribed indica si el suscriptor está suscrito a alguno de los temas
+func (s *Subscriber) subscribed(topics []string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, topic := range topics {
+		if s.topics[topic] {
+			return true
+		}
+	}
+	return false
+}
+
+// close termina la suscripción una única vez con el motivo indicado
+func (s *Subscriber) close(err error) {
+	s.closeOnce.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// AllTanks etiqueta las respuestas que agregan datos de toda la flota; cualquier cambio en un
+// tanque las invalida
+const AllTanks = "*"
+
+// maxEntries limita el número de respuestas guardadas; al alcanzarlo se descartan las vencidas
+// y, si aún no hay sitio, la respuesta nueva no se guarda
+const maxEntries = 1000
+
+// Response es una respuesta HTTP guardada
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// entry es una respuesta guardada junto con su vencimiento y el tanque del que depende
+type entry struct {
+	response  *Response
+	tankID    string
+	expiresAt time.Time
+}
+
+// ResponseCache guarda en memoria respuestas de consultas costosas durante un tiempo corto.
+// Implementa ports.CacheInvalidator: las respuestas de un tanque se descartan cuando cambian
+// sus datos, sin esperar al vencimiento.
+type ResponseCache struct {
+	ttl     time.Duration
+	entries map[string]*entry
+	mutex   sync.Mutex
+}
+
+// NewResponseCache crea una caché cuyas respuestas vencen tras ttl
+func NewResponseCache(ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		ttl:     ttl,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Get devuelve la respuesta guardada con la clave, si existe y no ha vencido
+func (c *ResponseCache) Get(key string) (*Response, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	cached, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(cached.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return cached.response, true
+}
+
+// Set guarda la respuesta con la clave. tankID es el tanque del que depende, o AllTanks si
+// agrega datos de toda la flota.
+func (c *ResponseCache) Set(key, tankID string, response *Response) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= maxEntries {
+		for k, cached := range c.entries {
+			if now.After(cached.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxEntries {
+			return
+		}
+	}
+
+	c.entries[key] = &entry{
+		response:  response,
+		tankID:    tankID,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// InvalidateTank descarta las respuestas del tanque y las que agregan toda la flota
+func (c *ResponseCache) InvalidateTank(tankID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key, cached := range c.entries {
+		if cached.tankID == tankID || cached.tankID == AllTanks {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Package datalake escribe las mediciones exportadas como archivos Parquet particionados por día
+// (estilo Hive: <prefijo>/measurements/dt=AAAA-MM-DD/...) en un almacenamiento de archivos, para
+// consultarlas con Athena, BigQuery, Spark o DuckDB
+package datalake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/adapters/storage"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/parquet"
+)
+
+// Tipos de contenido de los archivos escritos
+const (
+	parquetContentType = "application/vnd.apache.parquet"
+	jsonContentType    = "application/json"
+)
+
+// checkpointName es el archivo del punto de control, fuera de la tabla de mediciones; los motores
+// de consulta ignoran además los archivos que empiezan por guion bajo
+const checkpointName = "_checkpoint.json"
+
+// Writer implementa ports.DataLakeWriter sobre un almacenamiento de archivos (S3, GCS con claves
+// HMAC o un directorio local). El punto de control se guarda junto a los datos, de modo que el
+// data lake y su progreso no pueden separarse.
+type Writer struct {
+	storage ports.FileStorage
+	prefix  string
+}
+
+// NewWriter crea un escritor que guarda los archivos bajo prefix (vacío para la raíz del bucket)
+func NewWriter(storage ports.FileStorage, prefix string) *Writer {
+	return &Writer{
+		storage: storage,
+		prefix:  strings.Trim(prefix, "/"),
+	}
+}
+
+// GetCheckpoint lee el punto de control; si aún no existe devuelve uno vacío
+func (w *Writer) GetCheckpoint(ctx context.Context) (*domain.DataLakeCheckpoint, error) {
+	content, err := w.storage.Get(ctx, w.key(checkpointName))
+	if errors.Is(err, storage.ErrFileNotFound) {
+		return &domain.DataLakeCheckpoint{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	var checkpoint domain.DataLakeCheckpoint
+	if err := json.NewDecoder(content).Decode(&checkpoint); err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// SaveCheckpoint reemplaza el punto de control
+func (w *Writer) SaveCheckpoint(ctx context.Context, checkpoint *domain.DataLakeCheckpoint) error {
+	payload, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return w.storage.Put(ctx, w.key(checkpointName), bytes.NewReader(payload), int64(len(payload)), jsonContentType)
+}
+
+// WriteMeasurements escribe las mediciones en <prefijo>/measurements/dt=AAAA-MM-DD/<name>.parquet.
+// La fecha de la partición va en la ruta y no se repite como columna.
+func (w *Writer) WriteMeasurements(ctx context.Context, day time.Time, name string, measurements []*domain.Measurement) (string, error) {
+	rows := len(measurements)
+	ids := make([]string, rows)
+	tankIDs := make([]string, rows)
+	sensorIDs := make([]string, rows)
+	timestamps := make([]time.Time, rows)
+	levels := make([]float64, rows)
+	temperatures := make([]float64, rows)
+	batteries := make([]*float64, rows)
+	signals := make([]*float64, rows)
+	for i, measurement := range measurements {
+		ids[i] = measurement.ID
+		tankIDs[i] = measurement.TankID
+		sensorIDs[i] = measurement.SensorID
+		timestamps[i] = measurement.Timestamp
+		levels[i] = measurement.Level
+		temperatures[i] = measurement.Temperature
+		batteries[i] = measurement.BatteryVoltage
+		signals[i] = measurement.SignalStrength
+	}
+
+	var file bytes.Buffer
+	err := parquet.Write(&file,
+		parquet.String("id", ids),
+		parquet.String("tank_id", tankIDs),
+		parquet.String("sensor_id", sensorIDs),
+		parquet.Timestamp("timestamp", timestamps),
+		parquet.Double("level", levels),
+		parquet.Double("temperature", temperatures),
+		parquet.OptionalDouble("battery_voltage", batteries),
+		parquet.OptionalDouble("rssi", signals),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	key := w.key("measurements", "dt="+day.UTC().Format("2006-01-02"), name+".parquet")
+	if err := w.storage.Put(ctx, key, &file, int64(file.Len()), parquetContentType); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// key une el prefijo con los elementos de la ruta
+func (w *Writer) key(elements ...string) string {
+	return path.Join(append([]string{w.prefix}, elements...)...)
+}
@@ -0,0 +1,122 @@
+package deviceprofiles
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig se devuelve cuando el archivo de perfiles no es válido
+var ErrInvalidConfig = errors.New("invalid device profiles file")
+
+// profileNamePattern limita los nombres de los perfiles a los que pueden ir en la URL
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// fileConfig es el formato del archivo de perfiles de equipo
+type fileConfig struct {
+	Profiles []profileConfig `yaml:"profiles"`
+}
+
+type profileConfig struct {
+	Name           string          `yaml:"name"`
+	Token          string          `yaml:"token"`
+	DefaultVersion int             `yaml:"default_version"`
+	Versions       []versionConfig `yaml:"versions"`
+}
+
+type versionConfig struct {
+	Version int         `yaml:"version"`
+	Schema  interface{} `yaml:"schema"` // JSON Schema escrito en YAML (o en JSON, que también es YAML)
+}
+
+// LoadFile lee el archivo de perfiles indicado
+func LoadFile(path string) (map[string]*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse interpreta un documento YAML con los perfiles de equipo, indexados por nombre. Los
+// campos desconocidos, incluidas las palabras de los esquemas, se rechazan para que una errata
+// no pase inadvertida.
+func Parse(r io.Reader) (map[string]*Profile, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file fileConfig
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	profiles := make(map[string]*Profile, len(file.Profiles))
+	for _, entry := range file.Profiles {
+		if !profileNamePattern.MatchString(entry.Name) {
+			return nil, fmt.Errorf("%w: invalid profile name %q", ErrInvalidConfig, entry.Name)
+		}
+		if _, repeated := profiles[entry.Name]; repeated {
+			return nil, fmt.Errorf("%w: duplicate profile %q", ErrInvalidConfig, entry.Name)
+		}
+		if entry.Token == "" {
+			return nil, fmt.Errorf("%w: profile %q without token", ErrInvalidConfig, entry.Name)
+		}
+
+		profile, err := entry.profile()
+		if err != nil {
+			return nil, fmt.Errorf("%w: profile %q: %v", ErrInvalidConfig, entry.Name, err)
+		}
+		profiles[entry.Name] = profile
+	}
+
+	return profiles, nil
+}
+
+// profile compila los esquemas de las versiones admitidas. Sin default_version, las cargas sin
+// campo version se interpretan con la versión más antigua admitida.
+func (c profileConfig) profile() (*Profile, error) {
+	if len(c.Versions) == 0 {
+		return nil, errors.New("at least one version is required")
+	}
+
+	profile := &Profile{
+		Name:           c.Name,
+		Token:          c.Token,
+		DefaultVersion: c.DefaultVersion,
+		Schemas:        make(map[int]*Schema, len(c.Versions)),
+	}
+	for _, entry := range c.Versions {
+		if _, known := baseSchemas[entry.Version]; !known {
+			return nil, fmt.Errorf("unknown version %d (available: %v)", entry.Version, Versions())
+		}
+		if _, repeated := profile.Schemas[entry.Version]; repeated {
+			return nil, fmt.Errorf("duplicate version %d", entry.Version)
+		}
+
+		var schema *Schema
+		if entry.Schema != nil {
+			compiled, err := CompileSchema(entry.Schema)
+			if err != nil {
+				return nil, fmt.Errorf("version %d: %v", entry.Version, err)
+			}
+			schema = compiled
+		}
+		profile.Schemas[entry.Version] = schema
+
+		if c.DefaultVersion == 0 && (profile.DefaultVersion == 0 || entry.Version < profile.DefaultVersion) {
+			profile.DefaultVersion = entry.Version
+		}
+	}
+
+	if _, supported := profile.Schemas[profile.DefaultVersion]; !supported {
+		return nil, fmt.Errorf("default_version %d is not among the supported versions", profile.DefaultVersion)
+	}
+
+	return profile, nil
+}
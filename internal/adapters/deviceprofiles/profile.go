@@ -0,0 +1,241 @@
+// Package deviceprofiles recibe las lecturas que los equipos envían directamente a la API en
+// formatos versionados. Cada perfil de equipo indica qué versiones de carga admite y las valida
+// con JSON Schema, de modo que una actualización de firmware pueda desplegarse gradualmente en
+// la flota: los equipos antiguos siguen enviando la v1 mientras los actualizados envían la v2.
+package deviceprofiles
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// Versiones de las cargas
+const (
+	Version1 = 1 // JSON plano con una lectura: {"tank_id": "...", "level": 400, ...}
+	Version2 = 2 // Lecturas de varios sensores del equipo: {"version": 2, "device_id": "...", "sensors": [...]}
+)
+
+// Errores de las cargas recibidas
+var (
+	ErrInvalidPayload     = errors.New("invalid device payload")
+	ErrUnsupportedVersion = errors.New("payload version not supported by profile")
+)
+
+// ValidationError describe por qué la carga no cumple el esquema de su versión
+type ValidationError struct {
+	Version    int
+	Violations []string // Infracciones con la ruta JSON Pointer del valor afectado
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("payload does not match schema v%d: %s", e.Version, strings.Join(e.Violations, "; "))
+}
+
+// baseSchemas son los esquemas de cada versión de carga. Garantizan que la carga pueda
+// traducirse a mediciones; el esquema del perfil añade sus propias reglas.
+var baseSchemas = map[int]*Schema{
+	Version1: mustCompileJSON(`{
+		"type": "object",
+		"required": ["tank_id", "level"],
+		"properties": {
+			"version": {"const": 1},
+			"tank_id": {"type": "string", "minLength": 1},
+			"sensor_id": {"type": "string"},
+			"level": {"type": "number"},
+			"temperature": {"type": "number"},
+			"timestamp": {"type": ["string", "number"]},
+			"battery_voltage": {"type": "number"},
+			"rssi": {"type": "number"}
+		}
+	}`),
+	Version2: mustCompileJSON(`{
+		"type": "object",
+		"required": ["version", "device_id", "sensors"],
+		"properties": {
+			"version": {"const": 2},
+			"device_id": {"type": "string", "minLength": 1},
+			"timestamp": {"type": ["string", "number"]},
+			"battery_voltage": {"type": "number"},
+			"rssi": {"type": "number"},
+			"sensors": {
+				"type": "array",
+				"minItems": 1,
+				"maxItems": 100,
+				"items": {
+					"type": "object",
+					"required": ["tank_id", "level"],
+					"properties": {
+						"id": {"type": "string"},
+						"tank_id": {"type": "string", "minLength": 1},
+						"level": {"type": "number"},
+						"temperature": {"type": "number"},
+						"timestamp": {"type": ["string", "number"]}
+					}
+				}
+			}
+		}
+	}`),
+}
+
+// mustCompileJSON compila un esquema escrito en JSON; solo se usa con los esquemas base
+func mustCompileJSON(definition string) *Schema {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(definition), &decoded); err != nil {
+		panic(err)
+	}
+	schema, err := CompileSchema(decoded)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// Versions devuelve las versiones de carga conocidas, de menor a mayor
+func Versions() []int {
+	return []int{Version1, Version2}
+}
+
+// Profile es un perfil de equipo: el token con el que se autentican sus equipos y las versiones
+// de carga que admite, cada una con su esquema
+type Profile struct {
+	Name           string
+	Token          string          // Secreto compartido que los equipos envían en cada solicitud
+	DefaultVersion int             // Versión de las cargas sin campo version (firmware antiguo)
+	Schemas        map[int]*Schema // Esquema propio de cada versión admitida; nil solo aplica el base
+}
+
+// Record es el resultado de traducir una lectura: la medición o el motivo por el que se descartó
+type Record struct {
+	Index       int // Posición de la lectura en la carga (el sensor en la v2)
+	Measurement *domain.Measurement
+	Error       string // Mensaje fijo, para poder traducirlo
+}
+
+// Extract valida la carga con el esquema de su versión y la traduce a mediciones. La versión se
+// toma del campo version o, si falta, de DefaultVersion. Una carga que no cumple el esquema
+// devuelve un *ValidationError sin lecturas; las lecturas con valores inválidos se devuelven
+// con su error sin impedir el resto.
+func (p *Profile) Extract(payload []byte) (int, []*Record, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	object, ok := document.(map[string]interface{})
+	if !ok {
+		return 0, nil, fmt.Errorf("%w: expected a JSON object", ErrInvalidPayload)
+	}
+
+	version := p.DefaultVersion
+	if value, present := object["version"]; present {
+		number, ok := toFloat(value)
+		if !ok || number != math.Trunc(number) {
+			return 0, nil, fmt.Errorf("%w: version must be an integer", ErrInvalidPayload)
+		}
+		version = int(number)
+	}
+
+	schema, supported := p.Schemas[version]
+	if !supported {
+		return version, nil, fmt.Errorf("%w: v%d", ErrUnsupportedVersion, version)
+	}
+	violations := baseSchemas[version].Validate(document)
+	if schema != nil {
+		violations = append(violations, schema.Validate(document)...)
+	}
+	if len(violations) > 0 {
+		return version, nil, &ValidationError{Version: version, Violations: violations}
+	}
+
+	switch version {
+	case Version2:
+		return version, sensorRecords(object), nil
+	default:
+		measurement, problem := flatMeasurement(object)
+		return version, []*Record{{Index: 0, Measurement: measurement, Error: problem}}, nil
+	}
+}
+
+// flatMeasurement traduce una carga v1, ya validada con su esquema base
+func flatMeasurement(object map[string]interface{}) (*domain.Measurement, string) {
+	measurement := &domain.Measurement{
+		TankID:         object["tank_id"].(string),
+		Level:          number(object["level"]),
+		Temperature:    number(object["temperature"]),
+		BatteryVoltage: optionalNumber(object["battery_voltage"]),
+		SignalStrength: optionalNumber(object["rssi"]),
+	}
+	measurement.SensorID, _ = object["sensor_id"].(string)
+
+	timestamp, err := parseTimestamp(object["timestamp"])
+	if err != nil {
+		return nil, "La marca de tiempo no es válida"
+	}
+	measurement.Timestamp = timestamp
+	return measurement, ""
+}
+
+// sensorRecords traduce cada sensor de una carga v2, ya validada con su esquema base. El
+// sensor sin id se identifica con el equipo; la batería y la señal son las del equipo.
+func sensorRecords(object map[string]interface{}) []*Record {
+	deviceID := object["device_id"].(string)
+	sensors := object["sensors"].([]interface{})
+
+	records := make([]*Record, 0, len(sensors))
+	for i, entry := range sensors {
+		sensor := entry.(map[string]interface{})
+		measurement := &domain.Measurement{
+			TankID:         sensor["tank_id"].(string),
+			SensorID:       deviceID,
+			Level:          number(sensor["level"]),
+			Temperature:    number(sensor["temperature"]),
+			BatteryVoltage: optionalNumber(object["battery_voltage"]),
+			SignalStrength: optionalNumber(object["rssi"]),
+		}
+		if id, _ := sensor["id"].(string); id != "" {
+			measurement.SensorID = id
+		}
+
+		value, present := sensor["timestamp"]
+		if !present {
+			value = object["timestamp"]
+		}
+		timestamp, err := parseTimestamp(value)
+		if err != nil {
+			records = append(records, &Record{Index: i, Error: "La marca de tiempo no es válida"})
+			continue
+		}
+		measurement.Timestamp = timestamp
+		records = append(records, &Record{Index: i, Measurement: measurement})
+	}
+	return records
+}
+
+// number devuelve el número validado por el esquema, o 0 si falta
+func number(value interface{}) float64 {
+	converted, _ := toFloat(value)
+	return converted
+}
+
+// optionalNumber devuelve el número validado por el esquema, o nil si falta
+func optionalNumber(value interface{}) *float64 {
+	converted, ok := toFloat(value)
+	if !ok {
+		return nil
+	}
+	return &converted
+}
+
+// parseTimestamp interpreta una marca de tiempo ISO 8601 o en segundos o milisegundos desde
+// 1970; sin ella devuelve el instante cero
+func parseTimestamp(value interface{}) (time.Time, error) {
+	return domain.ParseTimestampValue(value)
+}
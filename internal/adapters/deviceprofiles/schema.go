@@ -0,0 +1,407 @@
+package deviceprofiles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidSchema se devuelve cuando un esquema usa palabras o valores no admitidos
+var ErrInvalidSchema = errors.New("invalid json schema")
+
+// schemaTypes son los tipos de JSON Schema
+var schemaTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true, "number": true, "integer": true, "string": true,
+}
+
+// annotationKeywords son las palabras que documentan el esquema sin restringir los valores
+var annotationKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true, "examples": true, "default": true,
+}
+
+// Schema es un esquema JSON compilado. Admite el subconjunto de JSON Schema necesario para
+// describir las lecturas de los equipos: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, minLength, maxLength y pattern. Las palabras desconocidas se rechazan al
+// compilar, para que una errata no desactive una regla sin que nadie lo note.
+type Schema struct {
+	reject bool // Esquema false: ningún valor es válido
+
+	types        []string
+	enum         []interface{}
+	constValue   interface{}
+	hasConst     bool
+	properties   map[string]*Schema
+	required     []string
+	additional   *Schema // nil admite cualquier propiedad adicional
+	items        *Schema
+	minItems     *int
+	maxItems     *int
+	minimum      *float64
+	maximum      *float64
+	exclusiveMin *float64
+	exclusiveMax *float64
+	minLength    *int
+	maxLength    *int
+	pattern      *regexp.Regexp
+}
+
+// CompileSchema compila un esquema decodificado de JSON o de YAML (objeto o booleano)
+func CompileSchema(definition interface{}) (*Schema, error) {
+	return compileSchema(definition, "#")
+}
+
+// compileSchema compila el esquema situado en location, que se usa en los mensajes de error
+func compileSchema(definition interface{}, location string) (*Schema, error) {
+	switch d := definition.(type) {
+	case bool:
+		return &Schema{reject: !d}, nil
+	case map[string]interface{}:
+		return compileObjectSchema(d, location)
+	default:
+		return nil, fmt.Errorf("%w: %s must be an object or a boolean", ErrInvalidSchema, location)
+	}
+}
+
+// compileObjectSchema compila un esquema con palabras clave
+func compileObjectSchema(definition map[string]interface{}, location string) (*Schema, error) {
+	schema := &Schema{}
+
+	keywords := make([]string, 0, len(definition))
+	for keyword := range definition {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		value := definition[keyword]
+		at := location + "/" + keyword
+		var err error
+
+		switch keyword {
+		case "type":
+			schema.types, err = compileTypes(value, at)
+		case "enum":
+			values, ok := value.([]interface{})
+			if !ok || len(values) == 0 {
+				return nil, fmt.Errorf("%w: %s must be a non-empty array", ErrInvalidSchema, at)
+			}
+			for _, v := range values {
+				schema.enum = append(schema.enum, normalizeValue(v))
+			}
+		case "const":
+			schema.constValue = normalizeValue(value)
+			schema.hasConst = true
+		case "properties":
+			properties, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be an object", ErrInvalidSchema, at)
+			}
+			schema.properties = make(map[string]*Schema, len(properties))
+			for name, property := range properties {
+				if schema.properties[name], err = compileSchema(property, at+"/"+name); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			names, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be an array of strings", ErrInvalidSchema, at)
+			}
+			for _, name := range names {
+				text, ok := name.(string)
+				if !ok {
+					return nil, fmt.Errorf("%w: %s must be an array of strings", ErrInvalidSchema, at)
+				}
+				schema.required = append(schema.required, text)
+			}
+		case "additionalProperties":
+			schema.additional, err = compileSchema(value, at)
+		case "items":
+			schema.items, err = compileSchema(value, at)
+		case "minItems":
+			schema.minItems, err = compileCount(value, at)
+		case "maxItems":
+			schema.maxItems, err = compileCount(value, at)
+		case "minLength":
+			schema.minLength, err = compileCount(value, at)
+		case "maxLength":
+			schema.maxLength, err = compileCount(value, at)
+		case "minimum":
+			schema.minimum, err = compileLimit(value, at)
+		case "maximum":
+			schema.maximum, err = compileLimit(value, at)
+		case "exclusiveMinimum":
+			schema.exclusiveMin, err = compileLimit(value, at)
+		case "exclusiveMaximum":
+			schema.exclusiveMax, err = compileLimit(value, at)
+		case "pattern":
+			expression, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be a string", ErrInvalidSchema, at)
+			}
+			if schema.pattern, err = regexp.Compile(expression); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSchema, at, err)
+			}
+		default:
+			if !annotationKeywords[keyword] {
+				return nil, fmt.Errorf("%w: unsupported keyword %s", ErrInvalidSchema, at)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return schema, nil
+}
+
+// compileTypes interpreta type, que puede ser un tipo o una lista de tipos
+func compileTypes(value interface{}, location string) ([]string, error) {
+	var names []interface{}
+	switch v := value.(type) {
+	case string:
+		names = []interface{}{v}
+	case []interface{}:
+		names = v
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: %s must be a type or a list of types", ErrInvalidSchema, location)
+	}
+
+	types := make([]string, 0, len(names))
+	for _, name := range names {
+		text, ok := name.(string)
+		if !ok || !schemaTypes[text] {
+			return nil, fmt.Errorf("%w: %s has unknown type %v", ErrInvalidSchema, location, name)
+		}
+		types = append(types, text)
+	}
+	return types, nil
+}
+
+// compileCount interpreta los límites de elementos y de longitud, enteros no negativos
+func compileCount(value interface{}, location string) (*int, error) {
+	number, ok := toFloat(value)
+	if !ok || number < 0 || number != math.Trunc(number) {
+		return nil, fmt.Errorf("%w: %s must be a non-negative integer", ErrInvalidSchema, location)
+	}
+	count := int(number)
+	return &count, nil
+}
+
+// compileLimit interpreta los límites numéricos
+func compileLimit(value interface{}, location string) (*float64, error) {
+	number, ok := toFloat(value)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be a number", ErrInvalidSchema, location)
+	}
+	return &number, nil
+}
+
+// Validate comprueba el valor, decodificado de JSON con UseNumber, y devuelve las infracciones
+// encontradas con la ruta JSON Pointer del valor afectado ("" es el documento)
+func (s *Schema) Validate(value interface{}) []string {
+	var violations []string
+	s.validate(value, "", &violations)
+	return violations
+}
+
+func (s *Schema) validate(value interface{}, path string, violations *[]string) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, pointer(path)+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s.reject {
+		report("value is not allowed")
+		return
+	}
+	if len(s.types) > 0 && !matchesAnyType(value, s.types) {
+		report("expected %s, got %s", strings.Join(s.types, " or "), typeOf(value))
+		return
+	}
+	if s.hasConst && !reflect.DeepEqual(normalizeValue(value), s.constValue) {
+		report("must be %v", s.constValue)
+	}
+	if len(s.enum) > 0 && !containsValue(s.enum, normalizeValue(value)) {
+		report("must be one of %v", s.enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.validateObject(v, path, violations)
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			report("must have at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			report("must have at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.validate(item, path+"/"+strconv.Itoa(i), violations)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.minLength != nil && length < *s.minLength {
+			report("must have at least %d characters", *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			report("must have at most %d characters", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			report("must match %s", s.pattern)
+		}
+	default:
+		if number, ok := toFloat(value); ok {
+			s.validateNumber(number, report)
+		}
+	}
+}
+
+// validateObject comprueba las propiedades requeridas, conocidas y adicionales del objeto
+func (s *Schema) validateObject(object map[string]interface{}, path string, violations *[]string) {
+	for _, name := range s.required {
+		if _, ok := object[name]; !ok {
+			*violations = append(*violations, pointer(path)+": missing required property "+strconv.Quote(name))
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, known := s.properties[name]
+		switch {
+		case known:
+			property.validate(object[name], path+"/"+escapePointer(name), violations)
+		case s.additional != nil:
+			s.additional.validate(object[name], path+"/"+escapePointer(name), violations)
+		}
+	}
+}
+
+// validateNumber comprueba los límites numéricos
+func (s *Schema) validateNumber(number float64, report func(string, ...interface{})) {
+	if s.minimum != nil && number < *s.minimum {
+		report("must be >= %v", *s.minimum)
+	}
+	if s.maximum != nil && number > *s.maximum {
+		report("must be <= %v", *s.maximum)
+	}
+	if s.exclusiveMin != nil && number <= *s.exclusiveMin {
+		report("must be > %v", *s.exclusiveMin)
+	}
+	if s.exclusiveMax != nil && number >= *s.exclusiveMax {
+		report("must be < %v", *s.exclusiveMax)
+	}
+}
+
+// matchesAnyType indica si el valor es de alguno de los tipos
+func matchesAnyType(value interface{}, types []string) bool {
+	actual := typeOf(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf devuelve el tipo de JSON Schema del valor; los números enteros son integer
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	}
+	if number, ok := toFloat(value); ok {
+		if number == math.Trunc(number) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// toFloat convierte los números decodificados de JSON (json.Number, float64) o de YAML (int)
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// normalizeValue convierte todos los números a float64 para comparar valores de JSON y de YAML
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			normalized[key] = normalizeValue(item)
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for i, item := range v {
+			normalized[i] = normalizeValue(item)
+		}
+		return normalized
+	}
+	if number, ok := toFloat(value); ok {
+		return number
+	}
+	return value
+}
+
+// containsValue indica si el valor normalizado está en la lista
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// pointer muestra la ruta JSON Pointer; el documento completo se indica con "/"
+func pointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// escapePointer escapa un nombre de propiedad para una ruta JSON Pointer (RFC 6901)
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
@@ -0,0 +1,101 @@
+// Package edgesync conecta una pasarela edge con la instancia central a través de la API de
+// sincronización (/api/sync/push y /api/sync/pull)
+package edgesync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// Rutas de la API de sincronización en la instancia central
+const (
+	PushPath = "/api/sync/push"
+	PullPath = "/api/sync/pull"
+)
+
+// maxErrorBody limita el fragmento de la respuesta que se incluye en los errores
+const maxErrorBody = 512
+
+// Client implementa ports.SyncClient sobre la API de sincronización de la instancia central, con
+// un token de la API de rol admin
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewClient crea un cliente de la instancia central en baseURL (p. ej. https://tanques.example.com)
+func NewClient(baseURL, token string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Push envía el lote. Cualquier respuesta distinta de 200 es un error, de modo que las mediciones
+// y los cambios siguen pendientes hasta el siguiente intento.
+func (c *Client) Push(ctx context.Context, batch *domain.SyncBatch) (*domain.SyncResult, error) {
+	payload, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	var result domain.SyncResult
+	if err := c.do(ctx, http.MethodPost, PushPath, bytes.NewReader(payload), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Pull obtiene los cambios de configuración posteriores al cursor
+func (c *Client) Pull(ctx context.Context, cursor int64, limit int) (*domain.SyncPullResult, error) {
+	query := url.Values{}
+	query.Set("cursor", strconv.FormatInt(cursor, 10))
+	query.Set("limit", strconv.Itoa(limit))
+
+	var result domain.SyncPullResult
+	if err := c.do(ctx, http.MethodGet, PullPath+"?"+query.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// do ejecuta la petición y decodifica en out la respuesta 200
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("upstream responded %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode upstream response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package erp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrInvalidConfig se devuelve cuando el archivo de conexiones no es válido
+var ErrInvalidConfig = errors.New("invalid erp file")
+
+// connectorNamePattern limita los nombres de las conexiones a los que pueden usarse en las rutas,
+// en los logs y en los nombres de las tareas
+var connectorNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Tipos de conexión
+const (
+	TypeHTTP = "http" // POST del lote a un endpoint del ERP
+	TypeFile = "file" // Archivo depositado en un directorio (local o montado por SFTP/SMB)
+	TypeSFTP = "sftp"
+)
+
+// Formatos del lote
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// Valores predeterminados de las conexiones
+const (
+	defaultTimeout      = 30 * time.Second
+	defaultRetryBackoff = 30 * time.Second
+)
+
+// Config es la lista de conexiones con los ERP
+type Config struct {
+	Connectors []*Connector
+}
+
+// Connector es una conexión con un ERP y la forma de entregarle el inventario
+type Connector struct {
+	domain.InventoryConnector
+	Type      string
+	Interval  time.Duration
+	Format    string
+	Delimiter rune // Separador de las columnas del CSV
+
+	// Conexiones http
+	URL                string
+	Headers            map[string]string
+	Timeout            time.Duration
+	ConfirmationHeader string // Cabecera de la respuesta con la referencia del ERP
+
+	// Conexiones file
+	Dir        string
+	DoneMarker bool // Crea <archivo>.done al terminar de escribir el lote
+}
+
+// fileConfig es el formato del archivo de conexiones
+type fileConfig struct {
+	Connectors []connectorConfig `yaml:"connectors"`
+}
+
+type connectorConfig struct {
+	Name               string            `yaml:"name"`
+	Type               string            `yaml:"type"`
+	Interval           time.Duration     `yaml:"interval"`
+	Format             string            `yaml:"format"`
+	Delimiter          string            `yaml:"delimiter"`
+	TankSelector       string            `yaml:"tank_selector"`
+	Retries            *int              `yaml:"retries"`
+	RetryBackoff       time.Duration     `yaml:"retry_backoff"`
+	URL                string            `yaml:"url"`
+	Headers            map[string]string `yaml:"headers"`
+	Timeout            time.Duration     `yaml:"timeout"`
+	ConfirmationHeader string            `yaml:"confirmation_header"`
+	Dir                string            `yaml:"dir"`
+	DoneMarker         bool              `yaml:"done_marker"`
+}
+
+// LoadFile lee el archivo de conexiones indicado
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse interpreta un documento YAML con las conexiones. Los campos desconocidos se rechazan para
+// que una errata no pase inadvertida.
+func Parse(r io.Reader) (*Config, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file fileConfig
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	config := &Config{}
+	names := make(map[string]bool, len(file.Connectors))
+	for _, entry := range file.Connectors {
+		connector, err := entry.connector()
+		if err != nil {
+			return nil, fmt.Errorf("%w: connector %q: %v", ErrInvalidConfig, entry.Name, err)
+		}
+		if names[connector.Name] {
+			return nil, fmt.Errorf("%w: duplicate connector %q", ErrInvalidConfig, connector.Name)
+		}
+		names[connector.Name] = true
+		config.Connectors = append(config.Connectors, connector)
+	}
+
+	return config, nil
+}
+
+// connector valida la conexión y completa los valores predeterminados
+func (c connectorConfig) connector() (*Connector, error) {
+	if !connectorNamePattern.MatchString(c.Name) {
+		return nil, errors.New("invalid name")
+	}
+	if c.Interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+
+	selector, err := domain.ParseLabelSelector(c.TankSelector)
+	if err != nil {
+		return nil, fmt.Errorf("tank_selector: %v", err)
+	}
+
+	connector := &Connector{
+		InventoryConnector: domain.InventoryConnector{
+			Name:         c.Name,
+			Retries:      3,
+			RetryBackoff: c.RetryBackoff,
+			TankSelector: selector,
+		},
+		Type:      c.Type,
+		Interval:  c.Interval,
+		Format:    strings.ToLower(c.Format),
+		Delimiter: ',',
+	}
+	if c.Retries != nil {
+		if *c.Retries < 0 {
+			return nil, errors.New("retries cannot be negative")
+		}
+		connector.Retries = *c.Retries
+	}
+	if connector.RetryBackoff <= 0 {
+		connector.RetryBackoff = defaultRetryBackoff
+	}
+
+	switch connector.Format {
+	case "":
+		connector.Format = FormatCSV
+	case FormatCSV, FormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown format %q", c.Format)
+	}
+	if c.Delimiter != "" {
+		delimiter, size := utf8.DecodeRuneInString(c.Delimiter)
+		if size != len(c.Delimiter) || delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+			return nil, fmt.Errorf("invalid delimiter %q", c.Delimiter)
+		}
+		connector.Delimiter = delimiter
+	}
+
+	switch c.Type {
+	case TypeHTTP:
+		target, err := url.Parse(c.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return nil, errors.New("url must be an absolute http(s) URL")
+		}
+		connector.URL = c.URL
+		connector.Headers = make(map[string]string, len(c.Headers))
+		for name, value := range c.Headers {
+			connector.Headers[http.CanonicalHeaderKey(name)] = value
+		}
+		connector.Timeout = c.Timeout
+		if connector.Timeout <= 0 {
+			connector.Timeout = defaultTimeout
+		}
+		connector.ConfirmationHeader = c.ConfirmationHeader
+	case TypeFile:
+		if c.Dir == "" {
+			return nil, errors.New("dir is required")
+		}
+		connector.Dir = c.Dir
+		connector.DoneMarker = c.DoneMarker
+	case TypeSFTP:
+		// La imagen no incluye un cliente SSH: el directorio remoto se monta (sshfs, rclone) y se
+		// usa una conexión file
+		return nil, errors.New("type sftp is not available in this build; mount the SFTP directory and use type file")
+	default:
+		return nil, fmt.Errorf("unknown type %q", c.Type)
+	}
+
+	return connector, nil
+}
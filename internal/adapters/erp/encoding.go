@@ -0,0 +1,70 @@
+package erp
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// csvHeader son las columnas del lote en CSV, una fila por tanque
+var csvHeader = []string{
+	"batch_id", "generated_at", "tank_id", "tank_name", "site_id", "group_id", "liquid_type",
+	"capacity_liters", "level_liters", "level_percent", "temperature_c", "last_updated",
+}
+
+// encodeBatch serializa el lote en el formato de la conexión y devuelve también su tipo de contenido
+func encodeBatch(batch *domain.InventoryBatch, format string, delimiter rune) ([]byte, string, error) {
+	if format == FormatJSON {
+		body, err := json.Marshal(batch)
+		return body, "application/json", err
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	writer.Comma = delimiter
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, "", err
+	}
+
+	generatedAt := formatTime(batch.GeneratedAt)
+	for _, record := range batch.Records {
+		row := []string{
+			batch.ID,
+			generatedAt,
+			record.TankID,
+			record.TankName,
+			record.SiteID,
+			record.GroupID,
+			record.LiquidType,
+			formatNumber(record.Capacity),
+			formatNumber(record.Level),
+			formatNumber(record.Percentage),
+			formatNumber(record.Temperature),
+			formatTime(record.LastUpdated),
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, "", err
+		}
+	}
+
+	writer.Flush()
+	return buffer.Bytes(), "text/csv; charset=utf-8", writer.Error()
+}
+
+// formatNumber escribe las cantidades con punto decimal y dos decimales, independientemente del
+// separador de columnas
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// formatTime escribe las fechas en UTC; los tanques sin lecturas quedan vacíos
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,148 @@
+package erp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// maxErrorBody limita el fragmento de la respuesta del ERP que se incluye en los errores
+const maxErrorBody = 512
+
+// NewExporter crea el adaptador de entrega de la conexión según su tipo
+func NewExporter(connector *Connector) ports.InventoryExporter {
+	if connector.Type == TypeFile {
+		return &fileExporter{connector: connector}
+	}
+	return &httpExporter{connector: connector, client: &http.Client{Timeout: connector.Timeout}}
+}
+
+// httpExporter envía el lote en el cuerpo de un POST al endpoint del ERP
+type httpExporter struct {
+	connector *Connector
+	client    *http.Client
+}
+
+// Connector devuelve la configuración de la conexión
+func (e *httpExporter) Connector() domain.InventoryConnector {
+	return e.connector.InventoryConnector
+}
+
+// Export envía el lote con el ID en Idempotency-Key, para que el ERP descarte los reintentos de un
+// lote ya recibido. La entrega se confirma con una respuesta 2xx (y, si está configurada, con la
+// cabecera de confirmación); los 4xx distintos de 408 y 429 son rechazos definitivos.
+func (e *httpExporter) Export(ctx context.Context, batch *domain.InventoryBatch) (string, error) {
+	body, contentType, err := encodeBatch(batch, e.connector.Format, e.connector.Delimiter)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.connector.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	for name, value := range e.connector.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Idempotency-Key", batch.ID)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return "", fmt.Errorf("erp responded %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	default:
+		return "", fmt.Errorf("%w: %s: %s", domain.ErrInventoryRejected, resp.Status, strings.TrimSpace(string(snippet)))
+	}
+
+	if e.connector.ConfirmationHeader == "" {
+		return resp.Status, nil
+	}
+	confirmation := resp.Header.Get(e.connector.ConfirmationHeader)
+	if confirmation == "" {
+		return "", fmt.Errorf("erp responded %s without %s", resp.Status, e.connector.ConfirmationHeader)
+	}
+	return confirmation, nil
+}
+
+// fileExporter deposita el lote como archivo en el directorio de intercambio con el ERP
+type fileExporter struct {
+	connector *Connector
+}
+
+// Connector devuelve la configuración de la conexión
+func (e *fileExporter) Connector() domain.InventoryConnector {
+	return e.connector.InventoryConnector
+}
+
+// Export escribe el lote en un archivo temporal oculto y lo renombra al terminar, para que el ERP
+// nunca recoja un archivo a medias. Los reintentos de un lote reemplazan el mismo archivo. El acuse
+// es el nombre del archivo con su SHA-256.
+func (e *fileExporter) Export(ctx context.Context, batch *domain.InventoryBatch) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	body, _, err := encodeBatch(batch, e.connector.Format, e.connector.Delimiter)
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("inventory_%s_%s_%s.%s",
+		batch.Connector, batch.GeneratedAt.UTC().Format("20060102T150405Z"), batch.ID, e.connector.Format)
+	if err := writeFileAtomic(filepath.Join(e.connector.Dir, name), body); err != nil {
+		return "", err
+	}
+	if e.connector.DoneMarker {
+		if err := writeFileAtomic(filepath.Join(e.connector.Dir, name+".done"), nil); err != nil {
+			return "", err
+		}
+	}
+
+	sum := sha256.Sum256(body)
+	return name + " sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// writeFileAtomic escribe el contenido en un temporal del mismo directorio, lo sincroniza con el
+// disco y lo renombra a su nombre definitivo
+func writeFileAtomic(path string, content []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(content); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(temp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
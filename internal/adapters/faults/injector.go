@@ -0,0 +1,75 @@
+// Package faults inyecta latencia y errores en los adaptadores de salida para comprobar de
+// principio a fin cómo se comporta la API ante dependencias lentas o caídas (reintentos,
+// canales alternativos, colas). Solo debe habilitarse en pruebas o en staging.
+package faults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault es el error devuelto por las operaciones que fallan a propósito
+var ErrInjectedFault = errors.New("injected fault")
+
+// Config describe los fallos que se inyectan en cada operación
+type Config struct {
+	Latency   time.Duration // Retardo añadido antes de cada operación
+	ErrorRate float64       // Probabilidad (entre 0 y 1) de que la operación falle
+}
+
+// Enabled indica si la configuración inyecta algún fallo
+func (c Config) Enabled() bool {
+	return c.Latency > 0 || c.ErrorRate > 0
+}
+
+// Injector decide, operación a operación, si se retrasa y si falla
+type Injector struct {
+	config Config
+	random *rand.Rand
+	mutex  sync.Mutex
+}
+
+// NewInjector crea un inyector de fallos con la configuración indicada
+func NewInjector(config Config) *Injector {
+	return NewInjectorWithSeed(config, time.Now().UnixNano())
+}
+
+// NewInjectorWithSeed crea un inyector cuyos fallos se repiten con la misma semilla, útil para
+// reproducir un escenario en las pruebas
+func NewInjectorWithSeed(config Config, seed int64) *Injector {
+	return &Injector{
+		config: config,
+		random: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Inject aplica la latencia configurada y devuelve ErrInjectedFault si la operación debe
+// fallar. La espera se interrumpe si el contexto se cancela.
+func (i *Injector) Inject(ctx context.Context, operation string) error {
+	if i.config.Latency > 0 {
+		timer := time.NewTimer(i.config.Latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if i.config.ErrorRate <= 0 {
+		return nil
+	}
+
+	i.mutex.Lock()
+	fail := i.random.Float64() < i.config.ErrorRate
+	i.mutex.Unlock()
+
+	if fail {
+		return fmt.Errorf("%w: %s", ErrInjectedFault, operation)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package faults
+
+import (
+	"context"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// AlertNotifier decora un ports.AlertNotifier con los fallos del inyector
+type AlertNotifier struct {
+	next     ports.AlertNotifier
+	injector *Injector
+}
+
+// NewAlertNotifier crea un notificador con fallos inyectados
+func NewAlertNotifier(next ports.AlertNotifier, injector *Injector) *AlertNotifier {
+	return &AlertNotifier{next: next, injector: injector}
+}
+
+// Notify entrega la alerta salvo que se inyecte un fallo
+func (n *AlertNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	if err := n.injector.Inject(ctx, "Notify"); err != nil {
+		return err
+	}
+	return n.next.Notify(ctx, alert)
+}
+
+// ChannelSender decora un ports.ChannelSender con los fallos del inyector, como un proveedor de
+// notificaciones lento o caído
+type ChannelSender struct {
+	next     ports.ChannelSender
+	injector *Injector
+}
+
+// NewChannelSender crea un emisor por canal con fallos inyectados
+func NewChannelSender(next ports.ChannelSender, injector *Injector) *ChannelSender {
+	return &ChannelSender{next: next, injector: injector}
+}
+
+// Send entrega la alerta por el canal salvo que se inyecte un fallo
+func (s *ChannelSender) Send(ctx context.Context, channel *domain.NotificationChannel, alert *domain.Alert) error {
+	if err := s.injector.Inject(ctx, "Send "+channel.Type); err != nil {
+		return err
+	}
+	return s.next.Send(ctx, channel, alert)
+}
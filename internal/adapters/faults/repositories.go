@@ -0,0 +1,115 @@
+package faults
+
+import (
+	"context"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// TankRepository decora un ports.TankRepository con los fallos del inyector
+type TankRepository struct {
+	next     ports.TankRepository
+	injector *Injector
+}
+
+// NewTankRepository crea un repositorio de tanques con fallos inyectados
+func NewTankRepository(next ports.TankRepository, injector *Injector) *TankRepository {
+	return &TankRepository{next: next, injector: injector}
+}
+
+// GetTank obtiene el tanque salvo que se inyecte un fallo
+func (r *TankRepository) GetTank(ctx context.Context, id string) (*domain.Tank, error) {
+	if err := r.injector.Inject(ctx, "GetTank"); err != nil {
+		return nil, err
+	}
+	return r.next.GetTank(ctx, id)
+}
+
+// GetAllTanks obtiene los tanques salvo que se inyecte un fallo
+func (r *TankRepository) GetAllTanks(ctx context.Context) ([]*domain.Tank, error) {
+	if err := r.injector.Inject(ctx, "GetAllTanks"); err != nil {
+		return nil, err
+	}
+	return r.next.GetAllTanks(ctx)
+}
+
+// SaveTank guarda el tanque salvo que se inyecte un fallo
+func (r *TankRepository) SaveTank(ctx context.Context, tank *domain.Tank) error {
+	if err := r.injector.Inject(ctx, "SaveTank"); err != nil {
+		return err
+	}
+	return r.next.SaveTank(ctx, tank)
+}
+
+// UpdateTank actualiza el tanque salvo que se inyecte un fallo
+func (r *TankRepository) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	if err := r.injector.Inject(ctx, "UpdateTank"); err != nil {
+		return err
+	}
+	return r.next.UpdateTank(ctx, tank)
+}
+
+// DeleteTank elimina el tanque salvo que se inyecte un fallo
+func (r *TankRepository) DeleteTank(ctx context.Context, id string) error {
+	if err := r.injector.Inject(ctx, "DeleteTank"); err != nil {
+		return err
+	}
+	return r.next.DeleteTank(ctx, id)
+}
+
+// MeasurementRepository decora un ports.MeasurementRepository con los fallos del inyector
+type MeasurementRepository struct {
+	next     ports.MeasurementRepository
+	injector *Injector
+}
+
+// NewMeasurementRepository crea un repositorio de mediciones con fallos inyectados
+func NewMeasurementRepository(next ports.MeasurementRepository, injector *Injector) *MeasurementRepository {
+	return &MeasurementRepository{next: next, injector: injector}
+}
+
+// SaveMeasurement guarda la medición salvo que se inyecte un fallo
+func (r *MeasurementRepository) SaveMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if err := r.injector.Inject(ctx, "SaveMeasurement"); err != nil {
+		return err
+	}
+	return r.next.SaveMeasurement(ctx, measurement)
+}
+
+// GetMeasurementsByTankID obtiene las mediciones salvo que se inyecte un fallo
+func (r *MeasurementRepository) GetMeasurementsByTankID(ctx context.Context, tankID string, limit int) ([]*domain.Measurement, error) {
+	if err := r.injector.Inject(ctx, "GetMeasurementsByTankID"); err != nil {
+		return nil, err
+	}
+	return r.next.GetMeasurementsByTankID(ctx, tankID, limit)
+}
+
+// GetLastMeasurement obtiene la última medición salvo que se inyecte un fallo
+func (r *MeasurementRepository) GetLastMeasurement(ctx context.Context, tankID string) (*domain.Measurement, error) {
+	if err := r.injector.Inject(ctx, "GetLastMeasurement"); err != nil {
+		return nil, err
+	}
+	return r.next.GetLastMeasurement(ctx, tankID)
+}
+
+// UnitOfWork decora un ports.UnitOfWork: los repositorios de la transacción también fallan, de
+// modo que puede comprobarse que un error a mitad de la transacción no deja escrituras parciales
+type UnitOfWork struct {
+	next     ports.UnitOfWork
+	injector *Injector
+}
+
+// NewUnitOfWork crea una unidad de trabajo con fallos inyectados
+func NewUnitOfWork(next ports.UnitOfWork, injector *Injector) *UnitOfWork {
+	return &UnitOfWork{next: next, injector: injector}
+}
+
+// Do ejecuta fn con los repositorios de la transacción decorados
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos ports.TxRepositories) error) error {
+	return u.next.Do(ctx, func(ctx context.Context, repos ports.TxRepositories) error {
+		repos.Tanks = NewTankRepository(repos.Tanks, u.injector)
+		repos.Measurements = NewMeasurementRepository(repos.Measurements, u.injector)
+		return fn(ctx, repos)
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// AccessHandler maneja las peticiones HTTP de administración de concesiones de acceso
+type AccessHandler struct {
+	accessService ports.AccessService
+	logger        logger.Logger
+}
+
+// NewAccessHandler crea una nueva instancia del manejador de concesiones
+func NewAccessHandler(accessService ports.AccessService, logger logger.Logger) *AccessHandler {
+	return &AccessHandler{
+		accessService: accessService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router. Al estar bajo /api/admin, el
+// middleware de autenticación exige el rol de administrador.
+func (h *AccessHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/access-grants", h.GetGrants).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/access-grants", h.CreateGrant).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/access-grants/{id}", h.DeleteGrant).Methods(http.MethodDelete)
+}
+
+// GetGrants devuelve las concesiones, opcionalmente filtradas por usuario (?subject=)
+func (h *AccessHandler) GetGrants(w http.ResponseWriter, r *http.Request) {
+	subject := r.URL.Query().Get("subject")
+
+	grants, err := h.accessService.GetGrants(r.Context(), subject)
+	if err != nil {
+		h.logger.Error("Failed to get access grants", "error", err, "subject", subject)
+		writeError(w, r, "Error al obtener las concesiones de acceso", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, grants, h.logger)
+}
+
+// CreateGrant concede a un usuario acceso a un sitio o grupo de tanques
+func (h *AccessHandler) CreateGrant(w http.ResponseWriter, r *http.Request) {
+	var grant domain.AccessGrant
+	if err := json.NewDecoder(r.Body).Decode(&grant); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Generamos un ID único si no se proporcionó
+	if grant.ID == "" {
+		grant.ID = uuid.New().String()
+	}
+
+	if err := h.accessService.CreateGrant(r.Context(), &grant); err != nil {
+		h.logger.Error("Failed to create access grant", "error", err)
+		writeError(w, r, "Error al crear la concesión de acceso", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, grant, h.logger)
+}
+
+// DeleteGrant revoca una concesión de acceso
+func (h *AccessHandler) DeleteGrant(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.accessService.DeleteGrant(r.Context(), id); err != nil {
+		h.logger.Error("Failed to delete access grant", "error", err, "id", id)
+		writeError(w, r, "Error al eliminar la concesión de acceso", statusForError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
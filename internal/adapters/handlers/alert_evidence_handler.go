@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// AlertEvidenceHandler maneja las peticiones HTTP de la evidencia de las alertas
+type AlertEvidenceHandler struct {
+	evidenceService ports.AlertEvidenceService
+	logger          logger.Logger
+}
+
+// NewAlertEvidenceHandler crea una nueva instancia del manejador de evidencias de alertas
+func NewAlertEvidenceHandler(evidenceService ports.AlertEvidenceService, logger logger.Logger) *AlertEvidenceHandler {
+	return &AlertEvidenceHandler{
+		evidenceService: evidenceService,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *AlertEvidenceHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/alerts/{id}/evidence", h.GetEvidence).Methods(http.MethodGet)
+}
+
+// GetEvidence descarga la evidencia capturada al dispararse la alerta crítica
+func (h *AlertEvidenceHandler) GetEvidence(w http.ResponseWriter, r *http.Request) {
+	alertID := mux.Vars(r)["id"]
+
+	evidence, err := h.evidenceService.GetEvidence(r.Context(), alertID)
+	if err != nil {
+		h.logger.Error("Failed to get alert evidence", "error", err, "alert_id", alertID)
+		writeError(w, r, "Error al obtener la evidencia de la alerta", statusForError(err))
+		return
+	}
+
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "alert-" + alertID + "-evidence.json"}))
+	writeJSON(w, r, http.StatusOK, evidence, h.logger)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// AlertMuteHandler maneja las peticiones HTTP para silenciar las alertas de los tanques
+type AlertMuteHandler struct {
+	muteService ports.AlertMuteService
+	logger      logger.Logger
+}
+
+// NewAlertMuteHandler crea una nueva instancia del manejador de silencios de alertas
+func NewAlertMuteHandler(muteService ports.AlertMuteService, logger logger.Logger) *AlertMuteHandler {
+	return &AlertMuteHandler{
+		muteService: muteService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *AlertMuteHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/tanks/{id}/alerts/mute", h.MuteAlerts).Methods(http.MethodPost)
+	router.HandleFunc("/api/tanks/{id}/alerts/mute", h.UnmuteAlerts).Methods(http.MethodDelete)
+	router.HandleFunc("/api/tanks/{id}/alerts/mutes", h.GetMutes).Methods(http.MethodGet)
+}
+
+// muteAlertsRequest es el cuerpo opcional de la petición de silencio
+type muteAlertsRequest struct {
+	Reason string `json:"reason"`
+}
+
+// MuteAlerts silencia las alertas del tanque durante el parámetro duration (p. ej. 2h)
+func (h *AlertMuteHandler) MuteAlerts(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	duration, err := time.ParseDuration(r.URL.Query().Get("duration"))
+	if err != nil {
+		writeError(w, r, "Parámetro duration inválido", http.StatusBadRequest)
+		return
+	}
+
+	var request muteAlertsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	mute, err := h.muteService.MuteAlerts(r.Context(), id, duration, request.Reason)
+	if err != nil {
+		h.logger.Error("Failed to mute alerts", "error", err, "id", id)
+		writeError(w, r, "Error al silenciar las alertas del tanque", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Tank alerts muted", "id", id, "until", mute.ExpiresAt, "by", mute.MutedBy)
+	writeJSON(w, r, http.StatusCreated, mute, h.logger)
+}
+
+// UnmuteAlerts levanta el silencio vigente del tanque
+func (h *AlertMuteHandler) UnmuteAlerts(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	mute, err := h.muteService.UnmuteAlerts(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to unmute alerts", "error", err, "id", id)
+		writeError(w, r, "Error al reactivar las alertas del tanque", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Tank alerts unmuted", "id", id, "by", mute.UnmutedBy)
+	writeJSON(w, r, http.StatusOK, mute, h.logger)
+}
+
+// GetMutes devuelve el historial de silencios del tanque
+func (h *AlertMuteHandler) GetMutes(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	mutes, err := h.muteService.GetMutes(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get alert mutes", "error", err, "id", id)
+		writeError(w, r, "Error al obtener los silencios de alertas", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, mutes, h.logger)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// defaultAlertRuleTestSpan es el periodo que se prueba si la solicitud no indica from
+const defaultAlertRuleTestSpan = 7 * 24 * time.Hour
+
+// AlertRuleHandler maneja las peticiones HTTP de las reglas de alerta. Las reglas son los canales
+// de notificación, como en el aprovisionamiento; aquí solo se prueban.
+type AlertRuleHandler struct {
+	alertRuleService ports.AlertRuleService
+	logger           logger.Logger
+}
+
+// NewAlertRuleHandler crea una nueva instancia del manejador de reglas de alerta
+func NewAlertRuleHandler(alertRuleService ports.AlertRuleService, logger logger.Logger) *AlertRuleHandler {
+	return &AlertRuleHandler{
+		alertRuleService: alertRuleService,
+		logger:           logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *AlertRuleHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/alert-rules/{id}/test", h.TestAlertRule).Methods(http.MethodPost)
+}
+
+// TestAlertRule evalúa la regla contra las mediciones del periodo indicado con from/to (por
+// defecto, los últimos 7 días) e informa cuándo habría alertado, sin enviar ninguna alerta
+func (h *AlertRuleHandler) TestAlertRule(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	from, to, err := parsePeriod(r, defaultAlertRuleTestSpan)
+	if err != nil {
+		writeError(w, r, "Parámetros from/to inválidos", http.StatusBadRequest)
+		return
+	}
+
+	simulation, err := h.alertRuleService.TestAlertRule(r.Context(), id, from, to)
+	if err != nil {
+		h.logger.Error("Failed to test alert rule", "error", err, "rule_id", id)
+		writeError(w, r, "Error al probar la regla de alerta", statusForError(err))
+		return
+	}
+
+	h.logger.Debug("Alert rule tested", "rule_id", id, "tanks", simulation.Tanks, "firings", len(simulation.Firings))
+	writeJSON(w, r, http.StatusOK, simulation, h.logger)
+}
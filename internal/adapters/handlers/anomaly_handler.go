@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// AnomalyHandler maneja las peticiones HTTP de las anomalías detectadas en los tanques
+type AnomalyHandler struct {
+	anomalyService ports.AnomalyService
+	logger         logger.Logger
+}
+
+// NewAnomalyHandler crea una nueva instancia del manejador de anomalías
+func NewAnomalyHandler(anomalyService ports.AnomalyService, logger logger.Logger) *AnomalyHandler {
+	return &AnomalyHandler{
+		anomalyService: anomalyService,
+		logger:         logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *AnomalyHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/tanks/{id}/anomalies", h.GetAnomalies).Methods(http.MethodGet)
+}
+
+// GetAnomalies devuelve las anomalías de un tanque en el periodo solicitado
+func (h *AnomalyHandler) GetAnomalies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	// Por defecto devolvemos los últimos 30 días
+	from, to, err := parsePeriod(r, 30*24*time.Hour)
+	if err != nil {
+		writeError(w, r, "Parámetros from/to inválidos", http.StatusBadRequest)
+		return
+	}
+
+	anomalies, err := h.anomalyService.GetAnomalies(ctx, id, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get anomalies", "error", err, "id", id)
+		writeError(w, r, "Error al obtener las anomalías", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, anomalies, h.logger)
+}
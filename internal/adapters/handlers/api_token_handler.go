@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// APITokenHandler maneja la emisión y la revocación de los tokens de la API
+type APITokenHandler struct {
+	tokenService ports.APITokenService
+	logger       logger.Logger
+}
+
+// NewAPITokenHandler crea una nueva instancia del manejador de tokens de la API
+func NewAPITokenHandler(tokenService ports.APITokenService, logger logger.Logger) *APITokenHandler {
+	return &APITokenHandler{
+		tokenService: tokenService,
+		logger:       logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *APITokenHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/api-tokens", h.GetTokens).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/api-tokens", h.CreateToken).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/api-tokens/{id}", h.RevokeToken).Methods(http.MethodDelete)
+}
+
+// createAPITokenRequest es el cuerpo de la petición de emisión de un token
+type createAPITokenRequest struct {
+	Name   string   `json:"name"`
+	Role   string   `json:"role"`
+	Scopes []string `json:"scopes"`
+}
+
+// issuedAPIToken es la respuesta de la emisión: el único momento en que se ve el token en claro
+type issuedAPIToken struct {
+	*domain.APIToken
+	Token string `json:"token"`
+}
+
+// CreateToken emite un token con el rol y los alcances indicados
+func (h *APITokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	var request createAPITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	token, plain, err := h.tokenService.CreateToken(r.Context(), request.Name, request.Role, request.Scopes)
+	if err != nil {
+		h.logger.Error("Failed to create api token", "error", err, "name", request.Name)
+		writeError(w, r, "Error al emitir el token", statusForError(err))
+		return
+	}
+
+	h.logger.Info("API token issued", "id", token.ID, "name", token.Name, "role", token.Role, "scopes", token.Scopes,
+		"by", domain.SubjectOf(domain.PrincipalFromContext(r.Context())))
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, http.StatusCreated, issuedAPIToken{APIToken: token, Token: plain}, h.logger)
+}
+
+// GetTokens devuelve los tokens emitidos, sin los tokens en claro
+func (h *APITokenHandler) GetTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.tokenService.GetTokens(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get api tokens", "error", err)
+		writeError(w, r, "Error al obtener los tokens", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, tokens, h.logger)
+}
+
+// RevokeToken revoca un token; deja de aceptarse de inmediato
+func (h *APITokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.tokenService.RevokeToken(r.Context(), id); err != nil {
+		h.logger.Error("Failed to revoke api token", "error", err, "id", id)
+		writeError(w, r, "Error al revocar el token", statusForError(err))
+		return
+	}
+
+	h.logger.Warn("API token revoked", "id", id, "by", domain.SubjectOf(domain.PrincipalFromContext(r.Context())))
+	w.WriteHeader(http.StatusNoContent)
+}
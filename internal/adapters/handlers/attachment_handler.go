@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// multipartMemory es la parte del formulario que se mantiene en memoria; el resto va a disco temporal
+const multipartMemory = 1 << 20
+
+// AttachmentHandler maneja las peticiones HTTP relacionadas con las fotos y documentos de los tanques
+type AttachmentHandler struct {
+	attachmentService ports.AttachmentService
+	maxSize           int64
+	logger            logger.Logger
+}
+
+// NewAttachmentHandler crea una nueva instancia del manejador de adjuntos. maxSize limita el
+// tamaño de cada archivo subido en bytes.
+func NewAttachmentHandler(attachmentService ports.AttachmentService, maxSize int64, logger logger.Logger) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		maxSize:           maxSize,
+		logger:            logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *AttachmentHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/tanks/{id}/attachments", h.GetAttachments).Methods(http.MethodGet)
+	router.HandleFunc("/api/tanks/{id}/attachments", h.UploadAttachment).Methods(http.MethodPost)
+	router.HandleFunc("/api/tanks/{id}/attachments/{attachmentId}", h.DownloadAttachment).Methods(http.MethodGet)
+	router.HandleFunc("/api/tanks/{id}/attachments/{attachmentId}", h.DeleteAttachment).Methods(http.MethodDelete)
+}
+
+// UploadAttachment sube un archivo (campo "file" de un formulario multipart) y lo vincula al tanque
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	tankID := mux.Vars(r)["id"]
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxSize+multipartMemory)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, r, "El archivo supera el tamaño máximo permitido", http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.logger.Error("Failed to parse multipart form", "error", err)
+		writeError(w, r, "Error al decodificar la solicitud", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, r, "Falta el archivo en el campo file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > h.maxSize {
+		writeError(w, r, "El archivo supera el tamaño máximo permitido", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	attachment := domain.Attachment{
+		ID:          uuid.New().String(),
+		TankID:      tankID,
+		Kind:        r.FormValue("kind"),
+		FileName:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+		Description: r.FormValue("description"),
+	}
+
+	if err := h.attachmentService.UploadAttachment(r.Context(), &attachment, file); err != nil {
+		h.logger.Error("Failed to upload attachment", "error", err, "tank_id", tankID)
+		writeError(w, r, "Error al subir el adjunto", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, attachment, h.logger)
+}
+
+// GetAttachments devuelve los adjuntos de un tanque
+func (h *AttachmentHandler) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	tankID := mux.Vars(r)["id"]
+
+	attachments, err := h.attachmentService.GetAttachments(r.Context(), tankID)
+	if err != nil {
+		h.logger.Error("Failed to get attachments", "error", err, "tank_id", tankID)
+		writeError(w, r, "Error al obtener los adjuntos", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, attachments, h.logger)
+}
+
+// DownloadAttachment devuelve el contenido de un adjunto
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tankID, id := vars["id"], vars["attachmentId"]
+
+	attachment, content, err := h.attachmentService.OpenAttachment(r.Context(), tankID, id)
+	if err != nil {
+		h.logger.Error("Failed to open attachment", "error", err, "tank_id", tankID, "id", id)
+		writeError(w, r, "Error al obtener el adjunto", statusForError(err))
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, content); err != nil {
+		h.logger.Error("Failed to stream attachment", "error", err, "id", id)
+	}
+}
+
+// DeleteAttachment elimina un adjunto
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tankID, id := vars["id"], vars["attachmentId"]
+
+	if err := h.attachmentService.DeleteAttachment(r.Context(), tankID, id); err != nil {
+		h.logger.Error("Failed to delete attachment", "error", err, "tank_id", tankID, "id", id)
+		writeError(w, r, "Error al eliminar el adjunto", statusForError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
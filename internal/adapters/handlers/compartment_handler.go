@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// CompartmentHandler maneja las peticiones HTTP de los compartimentos de los tanques
+type CompartmentHandler struct {
+	compartmentService ports.CompartmentService
+	logger             logger.Logger
+}
+
+// NewCompartmentHandler crea una nueva instancia del manejador de compartimentos
+func NewCompartmentHandler(compartmentService ports.CompartmentService, logger logger.Logger) *CompartmentHandler {
+	return &CompartmentHandler{
+		compartmentService: compartmentService,
+		logger:             logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *CompartmentHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/tanks/{id}/compartments", h.GetCompartments).Methods(http.MethodGet)
+}
+
+// GetCompartments devuelve los compartimentos del tanque y su resumen
+func (h *CompartmentHandler) GetCompartments(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	rollup, err := h.compartmentService.GetCompartments(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get tank compartments", "error", err, "id", id)
+		writeError(w, r, "Error al obtener los compartimentos del tanque", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, rollup, h.logger)
+}
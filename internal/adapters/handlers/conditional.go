@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"monitor-tanques/pkg/logger"
+)
+
+// writeConditionalJSON codifica value (envuelto a partir de la v2 y en el formato negociado, como
+// writeJSON) con ETag y, si se conoce, Last-Modified. Cada formato tiene su propio ETag. Si la
+// solicitud ya tiene la representación actual (If-None-Match, o If-Modified-Since cuando no se
+// envía If-None-Match) responde 304 sin cuerpo.
+func writeConditionalJSON(w http.ResponseWriter, r *http.Request, value interface{}, lastModified time.Time, log logger.Logger) {
+	value, err := responseBody(r, value)
+	if err != nil {
+		writeError(w, r, "Parámetros limit u offset inválidos", http.StatusBadRequest)
+		return
+	}
+
+	encoder := negotiateEncoder(r)
+	body, err := encoder.Marshal(value)
+	if err != nil {
+		log.Error("Failed to encode response", "error", err, "content_type", encoder.ContentType())
+		writeError(w, r, "Error al codificar la respuesta", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	header := w.Header()
+	header.Set("ETag", etag)
+	// Los clientes pueden guardar la respuesta, pero deben revalidarla en cada consulta
+	header.Set("Cache-Control", "no-cache")
+	header.Add("Vary", "Accept")
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	header.Set("Content-Type", encoder.ContentType())
+	w.Write(body)
+}
+
+// notModified evalúa las cabeceras condicionales de la solicitud. If-None-Match tiene prioridad
+// sobre If-Modified-Since, como establece RFC 9110.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	// Last-Modified tiene precisión de segundos
+	return !lastModified.Truncate(time.Second).After(since)
+}
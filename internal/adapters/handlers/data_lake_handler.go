@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// DataLakeHandler maneja las peticiones HTTP de la exportación de mediciones al data lake
+type DataLakeHandler struct {
+	exportService ports.DataLakeExportService
+	logger        logger.Logger
+}
+
+// NewDataLakeHandler crea una nueva instancia del manejador de la exportación al data lake
+func NewDataLakeHandler(exportService ports.DataLakeExportService, logger logger.Logger) *DataLakeHandler {
+	return &DataLakeHandler{
+		exportService: exportService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *DataLakeHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/datalake", h.GetCheckpoint).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/datalake/export", h.Export).Methods(http.MethodPost)
+}
+
+// Export exporta en el momento las mediciones nuevas, sin esperar a la tarea programada
+func (h *DataLakeHandler) Export(w http.ResponseWriter, r *http.Request) {
+	export, err := h.exportService.Export(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to export measurements to the data lake", "error", err)
+		writeError(w, r, "Error al exportar las mediciones al data lake", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Measurements exported to the data lake", "to", export.To, "measurements", export.Measurements, "files", len(export.Files))
+	writeJSON(w, r, http.StatusOK, export, h.logger)
+}
+
+// GetCheckpoint devuelve hasta dónde se exportó y el resultado de la última exportación
+func (h *DataLakeHandler) GetCheckpoint(w http.ResponseWriter, r *http.Request) {
+	checkpoint, err := h.exportService.GetCheckpoint(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get data lake checkpoint", "error", err)
+		writeError(w, r, "Error al obtener el estado de la exportación al data lake", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, checkpoint, h.logger)
+}
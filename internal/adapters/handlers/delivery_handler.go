@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// DeliveryHandler maneja las peticiones HTTP relacionadas con proveedores y pedidos de entrega
+type DeliveryHandler struct {
+	deliveryService ports.DeliveryService
+	logger          logger.Logger
+}
+
+// NewDeliveryHandler crea una nueva instancia del manejador de entregas
+func NewDeliveryHandler(deliveryService ports.DeliveryService, logger logger.Logger) *DeliveryHandler {
+	return &DeliveryHandler{
+		deliveryService: deliveryService,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *DeliveryHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/suppliers", h.GetAllSuppliers).Methods(http.MethodGet)
+	router.HandleFunc("/api/suppliers/{id}", h.GetSupplier).Methods(http.MethodGet)
+	router.HandleFunc("/api/suppliers", h.CreateSupplier).Methods(http.MethodPost)
+	router.HandleFunc("/api/suppliers/{id}", h.UpdateSupplier).Methods(http.MethodPut)
+	router.HandleFunc("/api/suppliers/{id}", h.DeleteSupplier).Methods(http.MethodDelete)
+
+	router.HandleFunc("/api/deliveries", h.GetDeliveryOrders).Methods(http.MethodGet)
+	router.HandleFunc("/api/deliveries/{id}", h.GetDeliveryOrder).Methods(http.MethodGet)
+	router.HandleFunc("/api/deliveries", h.RequestDelivery).Methods(http.MethodPost)
+	router.HandleFunc("/api/deliveries/{id}/schedule", h.ScheduleDelivery).Methods(http.MethodPost)
+	router.HandleFunc("/api/deliveries/{id}/receive", h.ReceiveDelivery).Methods(http.MethodPost)
+	router.HandleFunc("/api/deliveries/{id}/reconciliation", h.ReconcileDelivery).Methods(http.MethodGet)
+}
+
+// GetAllSuppliers devuelve todos los proveedores
+func (h *DeliveryHandler) GetAllSuppliers(w http.ResponseWriter, r *http.Request) {
+	suppliers, err := h.deliveryService.GetAllSuppliers(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get suppliers", "error", err)
+		writeError(w, r, "Error al obtener los proveedores", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, suppliers, h.logger)
+}
+
+// GetSupplier devuelve un proveedor específico
+func (h *DeliveryHandler) GetSupplier(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	supplier, err := h.deliveryService.GetSupplier(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get supplier", "error", err, "id", id)
+		writeError(w, r, "Error al obtener el proveedor", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, supplier, h.logger)
+}
+
+// CreateSupplier crea un nuevo proveedor
+func (h *DeliveryHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	var supplier domain.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Generamos un ID único si no se proporcionó
+	if supplier.ID == "" {
+		supplier.ID = uuid.New().String()
+	}
+
+	if err := h.deliveryService.CreateSupplier(r.Context(), &supplier); err != nil {
+		h.logger.Error("Failed to create supplier", "error", err)
+		writeError(w, r, "Error al crear el proveedor", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, supplier, h.logger)
+}
+
+// UpdateSupplier actualiza un proveedor existente
+func (h *DeliveryHandler) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var supplier domain.Supplier
+	if err := json.NewDecoder(r.Body).Decode(&supplier); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Aseguramos que el ID en el cuerpo coincida con el de la URL
+	supplier.ID = id
+
+	if err := h.deliveryService.UpdateSupplier(r.Context(), &supplier); err != nil {
+		h.logger.Error("Failed to update supplier", "error", err, "id", id)
+		writeError(w, r, "Error al actualizar el proveedor", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, supplier, h.logger)
+}
+
+// DeleteSupplier elimina un proveedor
+func (h *DeliveryHandler) DeleteSupplier(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.deliveryService.DeleteSupplier(r.Context(), id); err != nil {
+		h.logger.Error("Failed to delete supplier", "error", err, "id", id)
+		writeError(w, r, "Error al eliminar el proveedor", statusForError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveryOrders devuelve los pedidos de entrega, filtrados opcionalmente por tank_id
+func (h *DeliveryHandler) GetDeliveryOrders(w http.ResponseWriter, r *http.Request) {
+	orders, err := h.deliveryService.GetDeliveryOrders(r.Context(), r.URL.Query().Get("tank_id"))
+	if err != nil {
+		h.logger.Error("Failed to get delivery orders", "error", err)
+		writeError(w, r, "Error al obtener los pedidos", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, orders, h.logger)
+}
+
+// GetDeliveryOrder devuelve un pedido de entrega específico
+func (h *DeliveryHandler) GetDeliveryOrder(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	order, err := h.deliveryService.GetDeliveryOrder(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get delivery order", "error", err, "id", id)
+		writeError(w, r, "Error al obtener el pedido", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, order, h.logger)
+}
+
+// RequestDelivery registra un nuevo pedido de entrega
+func (h *DeliveryHandler) RequestDelivery(w http.ResponseWriter, r *http.Request) {
+	var order domain.DeliveryOrder
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Generamos un ID único si no se proporcionó
+	if order.ID == "" {
+		order.ID = uuid.New().String()
+	}
+
+	if err := h.deliveryService.RequestDelivery(r.Context(), &order); err != nil {
+		h.logger.Error("Failed to request delivery", "error", err, "tankID", order.TankID)
+		writeError(w, r, "Error al registrar el pedido", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, order, h.logger)
+}
+
+// ScheduleDelivery programa la fecha de entrega de un pedido
+func (h *DeliveryHandler) ScheduleDelivery(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var request struct {
+		ScheduledFor time.Time `json:"scheduled_for"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	order, err := h.deliveryService.ScheduleDelivery(r.Context(), id, request.ScheduledFor)
+	if err != nil {
+		h.logger.Error("Failed to schedule delivery", "error", err, "id", id)
+		writeError(w, r, "Error al programar el pedido", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, order, h.logger)
+}
+
+// ReceiveDelivery registra la recepción de un pedido
+func (h *DeliveryHandler) ReceiveDelivery(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var request struct {
+		ReceivedVolume float64   `json:"received_volume"`
+		ReceivedAt     time.Time `json:"received_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	order, err := h.deliveryService.ReceiveDelivery(r.Context(), id, request.ReceivedVolume, request.ReceivedAt)
+	if err != nil {
+		h.logger.Error("Failed to receive delivery", "error", err, "id", id)
+		writeError(w, r, "Error al registrar la recepción del pedido", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, order, h.logger)
+}
+
+// ReconcileDelivery compara el volumen recibido con la subida de nivel medida
+func (h *DeliveryHandler) ReconcileDelivery(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	reconciliation, err := h.deliveryService.ReconcileDelivery(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to reconcile delivery", "error", err, "id", id)
+		writeError(w, r, "Error al conciliar el pedido", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, reconciliation, h.logger)
+}
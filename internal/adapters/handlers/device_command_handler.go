@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// DeviceCommandHandler maneja las peticiones HTTP de comandos de bajada a los equipos de campo
+type DeviceCommandHandler struct {
+	commandService ports.DeviceCommandService
+	logger         logger.Logger
+}
+
+// NewDeviceCommandHandler crea una nueva instancia del manejador de comandos
+func NewDeviceCommandHandler(commandService ports.DeviceCommandService, logger logger.Logger) *DeviceCommandHandler {
+	return &DeviceCommandHandler{
+		commandService: commandService,
+		logger:         logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *DeviceCommandHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/devices/{id}/commands", h.GetCommands).Methods(http.MethodGet)
+	router.HandleFunc("/api/devices/{id}/commands", h.SendCommand).Methods(http.MethodPost)
+}
+
+// GetCommands devuelve el historial de comandos enviados al equipo
+func (h *DeviceCommandHandler) GetCommands(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	commands, err := h.commandService.GetCommands(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get device commands", "error", err, "id", id)
+		writeError(w, r, "Error al obtener los comandos del equipo", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, commands, h.logger)
+}
+
+// SendCommand publica un comando hacia el equipo. La respuesta es 202 porque el broker confirma
+// la publicación, no su ejecución en el equipo.
+func (h *DeviceCommandHandler) SendCommand(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var command domain.DeviceCommand
+	if err := json.NewDecoder(r.Body).Decode(&command); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	command.ID = uuid.New().String()
+	command.DeviceID = id
+
+	if err := h.commandService.SendCommand(r.Context(), &command); err != nil {
+		h.logger.Error("Failed to send device command", "error", err, "id", id, "type", command.Type)
+		writeError(w, r, "Error al enviar el comando al equipo", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusAccepted, command, h.logger)
+}
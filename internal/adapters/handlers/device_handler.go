@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/i18n"
+	"monitor-tanques/pkg/logger"
+)
+
+// DeviceHandler maneja las peticiones HTTP de registro de dispositivos móviles para alertas push
+type DeviceHandler struct {
+	deviceService ports.MobileDeviceService
+	logger        logger.Logger
+}
+
+// NewDeviceHandler crea una nueva instancia del manejador de dispositivos
+func NewDeviceHandler(deviceService ports.MobileDeviceService, logger logger.Logger) *DeviceHandler {
+	return &DeviceHandler{
+		deviceService: deviceService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *DeviceHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/devices", h.GetDevices).Methods(http.MethodGet)
+	router.HandleFunc("/api/devices", h.RegisterDevice).Methods(http.MethodPost)
+	router.HandleFunc("/api/devices/{id}/location", h.UpdateLocation).Methods(http.MethodPut)
+	router.HandleFunc("/api/devices/{id}", h.DeleteDevice).Methods(http.MethodDelete)
+}
+
+// GetDevices devuelve los dispositivos registrados, opcionalmente filtrados por usuario (?subject=)
+func (h *DeviceHandler) GetDevices(w http.ResponseWriter, r *http.Request) {
+	devices, err := h.deviceService.GetDevices(r.Context(), r.URL.Query().Get("subject"))
+	if err != nil {
+		h.logger.Error("Failed to get devices", "error", err)
+		writeError(w, r, "Error al obtener los dispositivos", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, devices, h.logger)
+}
+
+// RegisterDevice registra un dispositivo con su token push y su ubicación
+func (h *DeviceHandler) RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	var device domain.MobileDevice
+	if err := json.NewDecoder(r.Body).Decode(&device); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Generamos un ID único si no se proporcionó
+	if device.ID == "" {
+		device.ID = uuid.New().String()
+	}
+
+	// Sin idioma explícito, las notificaciones usan el idioma negociado con la aplicación
+	if device.Language == "" {
+		device.Language = i18n.FromContext(r.Context())
+	}
+
+	if err := h.deviceService.RegisterDevice(r.Context(), &device); err != nil {
+		h.logger.Error("Failed to register device", "error", err)
+		writeError(w, r, "Error al registrar el dispositivo", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, device, h.logger)
+}
+
+// UpdateLocation actualiza la ubicación actual del dispositivo
+func (h *DeviceHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var location domain.GeoLocation
+	if err := json.NewDecoder(r.Body).Decode(&location); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	device, err := h.deviceService.UpdateLocation(r.Context(), id, location)
+	if err != nil {
+		h.logger.Error("Failed to update device location", "error", err, "id", id)
+		writeError(w, r, "Error al actualizar la ubicación del dispositivo", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, device, h.logger)
+}
+
+// DeleteDevice da de baja un dispositivo
+func (h *DeviceHandler) DeleteDevice(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.deviceService.DeleteDevice(r.Context(), id); err != nil {
+		h.logger.Error("Failed to delete device", "error", err, "id", id)
+		writeError(w, r, "Error al eliminar el dispositivo", statusForError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/adapters/deviceprofiles"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/i18n"
+	"monitor-tanques/pkg/logger"
+)
+
+// DeviceIngestResult es el informe de las lecturas recibidas de un equipo
+type DeviceIngestResult struct {
+	Profile    string                  `json:"profile"`
+	Version    int                     `json:"version"`
+	Accepted   int                     `json:"accepted"`
+	Rejected   int                     `json:"rejected"`
+	Records    []*InboundWebhookRecord `json:"records"`
+	Violations []string                `json:"violations,omitempty"` // Infracciones del esquema de la versión
+}
+
+// DeviceIngestHandler recibe las lecturas que los equipos envían directamente en las versiones
+// de carga admitidas por su perfil y las guarda como mediciones
+type DeviceIngestHandler struct {
+	tankService ports.TankService
+	profiles    map[string]*deviceprofiles.Profile
+	logger      logger.Logger
+}
+
+// NewDeviceIngestHandler crea una nueva instancia del manejador de lecturas de equipos.
+// profiles contiene cada perfil de equipo, indexado por el nombre usado en la URL.
+func NewDeviceIngestHandler(tankService ports.TankService, profiles map[string]*deviceprofiles.Profile, logger logger.Logger) *DeviceIngestHandler {
+	return &DeviceIngestHandler{
+		tankService: tankService,
+		profiles:    profiles,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *DeviceIngestHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/ingest/devices/{profile}", h.Receive).Methods(http.MethodPost)
+}
+
+// Receive valida la carga con el esquema de su versión y guarda sus lecturas. El equipo se
+// identifica con el token de su perfil, en la cabecera X-Webhook-Token o como Authorization:
+// Bearer. Una carga que no cumple el esquema se rechaza entera con sus infracciones; las
+// lecturas que no se pueden guardar se descartan sin impedir el resto.
+func (h *DeviceIngestHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["profile"]
+	profile, ok := h.profiles[name]
+	if !ok {
+		writeError(w, r, "Perfil de equipo desconocido", http.StatusNotFound)
+		return
+	}
+
+	if !validWebhookToken(r, profile.Token) {
+		writeError(w, r, "Token de webhook inválido", http.StatusUnauthorized)
+		return
+	}
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	version, records, err := profile.Extract(payload)
+	var invalid *deviceprofiles.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		h.logger.Warn("Device payload does not match schema", "profile", name, "version", version, "violations", invalid.Violations)
+		result := &DeviceIngestResult{Profile: name, Version: version, Records: []*InboundWebhookRecord{}, Violations: invalid.Violations}
+		writeJSON(w, r, http.StatusBadRequest, result, h.logger)
+		return
+	case errors.Is(err, deviceprofiles.ErrUnsupportedVersion):
+		h.logger.Warn("Unsupported device payload version", "profile", name, "version", version)
+		writeError(w, r, "Versión de carga no admitida por el perfil", http.StatusBadRequest)
+		return
+	case err != nil:
+		h.logger.Warn("Invalid device payload", "profile", name, "error", err)
+		writeError(w, r, "Contenido de la carga inválido", http.StatusBadRequest)
+		return
+	}
+
+	language := i18n.FromContext(r.Context())
+	result := &DeviceIngestResult{Profile: name, Version: version, Records: make([]*InboundWebhookRecord, 0, len(records))}
+	for _, record := range records {
+		outcome := &InboundWebhookRecord{Index: record.Index, Status: webhookRecordRejected}
+		result.Records = append(result.Records, outcome)
+
+		problem := record.Error
+		if measurement := record.Measurement; measurement != nil {
+			outcome.TankID = measurement.TankID
+			measurement.ID = uuid.New().String()
+			if measurement.Timestamp.IsZero() {
+				measurement.Timestamp = time.Now()
+			}
+
+			if err := h.tankService.AddMeasurement(r.Context(), measurement); err != nil && !services.IsStaleMeasurement(err) {
+				if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+					h.logger.Error("Device ingestion interrupted", "profile", name, "error", err)
+					writeError(w, r, "Error al guardar las lecturas del equipo", statusForError(err))
+					return
+				}
+				h.logger.Warn("Failed to save device measurement", "profile", name, "tank_id", measurement.TankID, "error", err)
+				problem = webhookRecordProblem(err)
+			} else {
+				outcome.MeasurementID = measurement.ID
+			}
+		}
+
+		if problem != "" {
+			outcome.Error = i18n.Translate(language, problem)
+			result.Rejected++
+			continue
+		}
+		outcome.Status = webhookRecordAccepted
+		result.Accepted++
+	}
+
+	h.logger.Info("Device payload received", "profile", name, "version", version, "accepted", result.Accepted, "rejected", result.Rejected)
+	writeJSON(w, r, http.StatusOK, result, h.logger)
+}
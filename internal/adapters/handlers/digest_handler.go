@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// DigestHandler maneja las peticiones HTTP de los resúmenes diarios de los canales
+type DigestHandler struct {
+	digestService ports.DigestService
+	logger        logger.Logger
+}
+
+// NewDigestHandler crea una nueva instancia del manejador de resúmenes diarios
+func NewDigestHandler(digestService ports.DigestService, logger logger.Logger) *DigestHandler {
+	return &DigestHandler{
+		digestService: digestService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *DigestHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/notification-channels/{id}/digest", h.GetDigest).Methods(http.MethodGet)
+	router.HandleFunc("/api/notification-channels/{id}/digest", h.SendDigest).Methods(http.MethodPost)
+}
+
+// GetDigest devuelve el resumen que recibiría ahora el canal, sin enviarlo
+func (h *DigestHandler) GetDigest(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	digest, err := h.digestService.GetDigest(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to build digest", "error", err, "channel_id", id)
+		writeError(w, r, "Error al componer el resumen diario", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, digest, h.logger)
+}
+
+// SendDigest envía ahora el resumen del canal, con las alertas acumuladas hasta el momento
+func (h *DigestHandler) SendDigest(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	digest, err := h.digestService.SendDigest(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to send digest", "error", err, "channel_id", id)
+		writeError(w, r, "Error al enviar el resumen diario", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Digest sent", "channel_id", id, "tanks", len(digest.Tanks), "alerts", len(digest.Alerts))
+	writeJSON(w, r, http.StatusOK, digest, h.logger)
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"monitor-tanques/pkg/msgpack"
+)
+
+// Encoder codifica las respuestas de la API en un formato concreto
+type Encoder interface {
+	// ContentType es el tipo de medio con el que se anuncia la respuesta
+	ContentType() string
+	// Marshal codifica el valor, que puede ser cualquier valor codificable como JSON
+	Marshal(value interface{}) ([]byte, error)
+}
+
+// encoders son los formatos disponibles, indexados por los tipos de medio que los identifican
+// en la cabecera Accept. JSON es el formato predeterminado.
+var encoders = map[string]Encoder{
+	"application/json":        jsonEncoder{},
+	"application/xml":         xmlEncoder{},
+	"text/xml":                xmlEncoder{},
+	"application/msgpack":     msgpackEncoder{},
+	"application/x-msgpack":   msgpackEncoder{},
+	"application/vnd.msgpack": msgpackEncoder{},
+}
+
+// RegisterEncoder publica un formato de respuesta para los tipos de medio indicados. Debe
+// llamarse al arrancar, antes de atender solicitudes.
+func RegisterEncoder(encoder Encoder, mediaTypes ...string) {
+	for _, mediaType := range mediaTypes {
+		encoders[strings.ToLower(mediaType)] = encoder
+	}
+}
+
+// negotiateEncoder elige el formato preferido según la cabecera Accept. Sin cabecera, con
+// comodines o si no se acepta ningún formato disponible se responde en JSON, para no romper a
+// los clientes que envían Accept genéricos como los navegadores.
+func negotiateEncoder(r *http.Request) Encoder {
+	var best Encoder = jsonEncoder{}
+	bestQuality := 0.0
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				parsed, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					quality = 0
+				} else {
+					quality = parsed
+				}
+			}
+		}
+
+		encoder, ok := encoders[strings.ToLower(strings.TrimSpace(mediaType))]
+		if ok && quality > bestQuality {
+			best = encoder
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// jsonEncoder codifica las respuestas como JSON
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string { return "application/json" }
+
+func (jsonEncoder) Marshal(value interface{}) ([]byte, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return append(body, '\n'), nil
+}
+
+// genericValue convierte el valor a su forma JSON genérica (objetos, listas, números...). Los
+// demás formatos parten de ella, de modo que usan los mismos nombres de campo que JSON.
+func genericValue(value interface{}) (interface{}, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// msgpackEncoder codifica las respuestas como MessagePack, más compacto que JSON para los
+// gateways con poco ancho de banda
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) ContentType() string { return "application/msgpack" }
+
+func (msgpackEncoder) Marshal(value interface{}) ([]byte, error) {
+	generic, err := genericValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return msgpack.Marshal(generic)
+}
+
+// xmlEncoder codifica las respuestas como XML para las integraciones SCADA heredadas. Cada
+// campo es un elemento con el nombre del campo JSON, los elementos de las listas se llaman
+// item y las claves que no son nombres XML válidos se escriben como <entry key="...">.
+type xmlEncoder struct{}
+
+func (xmlEncoder) ContentType() string { return "application/xml" }
+
+func (xmlEncoder) Marshal(value interface{}) ([]byte, error) {
+	generic, err := genericValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	writeXMLElement(&buf, "response", generic)
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// writeXMLElement escribe el valor genérico como un elemento con el nombre indicado
+func writeXMLElement(buf *bytes.Buffer, name string, value interface{}) {
+	open, closing := "<"+name, "</"+name+">"
+	if !isXMLName(name) {
+		var key bytes.Buffer
+		xml.EscapeText(&key, []byte(name))
+		open, closing = `<entry key="`+key.String()+`"`, "</entry>"
+	}
+
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString(open + "/>")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buf.WriteString(open + ">")
+		for _, key := range keys {
+			writeXMLElement(buf, key, v[key])
+		}
+		buf.WriteString(closing)
+	case []interface{}:
+		buf.WriteString(open + ">")
+		for _, item := range v {
+			writeXMLElement(buf, "item", item)
+		}
+		buf.WriteString(closing)
+	default:
+		buf.WriteString(open + ">")
+		xml.EscapeText(buf, []byte(xmlText(v)))
+		buf.WriteString(closing)
+	}
+}
+
+// xmlText devuelve el texto de un valor escalar
+func xmlText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// isXMLName indica si el nombre puede usarse como nombre de elemento
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r), r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
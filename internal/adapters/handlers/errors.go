@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+// statusForError traduce los errores del dominio a códigos de estado HTTP
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrTankNotFound),
+		errors.Is(err, services.ErrSupplierNotFound),
+		errors.Is(err, services.ErrDeliveryOrderNotFound),
+		errors.Is(err, services.ErrChannelNotFound),
+		errors.Is(err, services.ErrAccessGrantNotFound),
+		errors.Is(err, services.ErrAttachmentNotFound),
+		errors.Is(err, services.ErrDeviceNotFound),
+		errors.Is(err, services.ErrFieldDeviceNotFound),
+		errors.Is(err, services.ErrGroupNotFound),
+		errors.Is(err, services.ErrConnectorNotFound),
+		errors.Is(err, services.ErrStatusShareNotFound),
+		errors.Is(err, services.ErrLiquidPolicyNotFound),
+		errors.Is(err, services.ErrAlertEvidenceNotFound),
+		errors.Is(err, services.ErrPurgeJobNotFound),
+		errors.Is(err, services.ErrSessionNotFound),
+		errors.Is(err, services.ErrAPITokenNotFound),
+		errors.Is(err, services.ErrTankChangeNotFound),
+		errors.Is(err, services.ErrSavedViewNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidTank),
+		errors.Is(err, services.ErrInvalidMeasurement),
+		errors.Is(err, services.ErrInvalidSupplier),
+		errors.Is(err, services.ErrInvalidDeliveryOrder),
+		errors.Is(err, services.ErrInvalidPeriod),
+		errors.Is(err, services.ErrInvalidChannel),
+		errors.Is(err, services.ErrInvalidAccessGrant),
+		errors.Is(err, services.ErrInvalidAttachment),
+		errors.Is(err, services.ErrInvalidDevice),
+		errors.Is(err, services.ErrInvalidNote),
+		errors.Is(err, services.ErrInvalidHealthFilter),
+		errors.Is(err, services.ErrInvalidDeviceConfig),
+		errors.Is(err, services.ErrInvalidDeviceCommand),
+		errors.Is(err, services.ErrInvalidProvisioningSpec),
+		errors.Is(err, services.ErrInvalidSearchQuery),
+		errors.Is(err, services.ErrInvalidFilter),
+		errors.Is(err, services.ErrInvalidSavedView),
+		errors.Is(err, services.ErrInvalidVoiceQuery),
+		errors.Is(err, services.ErrInvalidSimulation),
+		errors.Is(err, services.ErrInvalidAlertMute),
+		errors.Is(err, services.ErrInvalidCapacityPlan),
+		errors.Is(err, services.ErrInvalidStatusShare),
+		errors.Is(err, services.ErrInvalidSignedURL),
+		errors.Is(err, services.ErrInvalidBackfill),
+		errors.Is(err, services.ErrInvalidLiquidPolicy),
+		errors.Is(err, services.ErrInvalidRuntimeConfig),
+		errors.Is(err, services.ErrInvalidSeries),
+		errors.Is(err, services.ErrFutureMeasurement),
+		errors.Is(err, services.ErrInvalidPurge),
+		errors.Is(err, services.ErrInvalidSession),
+		errors.Is(err, services.ErrInvalidAPIToken),
+		errors.Is(err, services.ErrInvalidSyncBatch),
+		errors.Is(err, services.ErrInvalidTankChange),
+		errors.Is(err, domain.ErrSafetyPolicyViolation),
+		errors.Is(err, domain.ErrInvalidSchedule),
+		errors.Is(err, domain.ErrInvalidUsageMonth):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInvalidSessionToken):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrForbidden),
+		errors.Is(err, services.ErrCaptchaRequired),
+		errors.Is(err, services.ErrInvalidCaptcha):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidDeliveryTransition),
+		errors.Is(err, services.ErrDeliveryNotReceived),
+		errors.Is(err, services.ErrUnknownConfigVersion),
+		errors.Is(err, services.ErrNoPollableDevice),
+		errors.Is(err, services.ErrAlertsNotMuted),
+		errors.Is(err, services.ErrRuntimeConfigUnavailable),
+		errors.Is(err, services.ErrInsufficientHistory),
+		errors.Is(err, services.ErrTankChangeNotPending),
+		errors.Is(err, services.ErrTankHasCompartments),
+		errors.Is(err, services.ErrTankChangeConflict),
+		errors.Is(err, services.ErrSavedViewConflict):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrCommandNotDelivered),
+		errors.Is(err, services.ErrInventoryNotDelivered),
+		errors.Is(err, services.ErrDigestNotDelivered):
+		return http.StatusBadGateway
+	case errors.Is(err, services.ErrDownlinkUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, services.ErrPollTimeout),
+		errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
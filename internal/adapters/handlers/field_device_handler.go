@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// FieldDeviceHandler maneja las peticiones HTTP de configuración de los equipos de campo
+type FieldDeviceHandler struct {
+	fieldDeviceService ports.FieldDeviceService
+	logger             logger.Logger
+}
+
+// NewFieldDeviceHandler crea una nueva instancia del manejador de equipos de campo
+func NewFieldDeviceHandler(fieldDeviceService ports.FieldDeviceService, logger logger.Logger) *FieldDeviceHandler {
+	return &FieldDeviceHandler{
+		fieldDeviceService: fieldDeviceService,
+		logger:             logger,
+	}
+}
+
+// desiredConfigRequest es el cuerpo de la solicitud para cambiar la configuración deseada
+type desiredConfigRequest struct {
+	TankID string `json:"tank_id"`
+	domain.DeviceConfig
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *FieldDeviceHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/field-devices", h.GetFieldDevices).Methods(http.MethodGet)
+	router.HandleFunc("/api/devices/{id}/config", h.PollConfig).Methods(http.MethodGet)
+	router.HandleFunc("/api/devices/{id}/config", h.SetDesiredConfig).Methods(http.MethodPut)
+	router.HandleFunc("/api/devices/{id}/config/status", h.GetConfigStatus).Methods(http.MethodGet)
+	router.HandleFunc("/api/devices/{id}/config/applied", h.ReportAppliedConfig).Methods(http.MethodPut)
+}
+
+// GetFieldDevices devuelve los equipos de campo, opcionalmente filtrados con ?in_sync=true|false
+func (h *FieldDeviceHandler) GetFieldDevices(w http.ResponseWriter, r *http.Request) {
+	var inSync *bool
+	if value := r.URL.Query().Get("in_sync"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, r, "Parámetro in_sync inválido", http.StatusBadRequest)
+			return
+		}
+		inSync = &parsed
+	}
+
+	devices, err := h.fieldDeviceService.GetFieldDevices(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get field devices", "error", err)
+		writeError(w, r, "Error al obtener los equipos de campo", statusForError(err))
+		return
+	}
+
+	if inSync != nil {
+		filtered := make([]*domain.FieldDevice, 0, len(devices))
+		for _, device := range devices {
+			if device.InSync == *inSync {
+				filtered = append(filtered, device)
+			}
+		}
+		devices = filtered
+	}
+
+	writeJSON(w, r, http.StatusOK, devices, h.logger)
+}
+
+// PollConfig devuelve al equipo su configuración deseada
+func (h *FieldDeviceHandler) PollConfig(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	config, err := h.fieldDeviceService.PollConfig(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to poll device config", "error", err, "id", id)
+		writeError(w, r, "Error al obtener la configuración del equipo", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, config, h.logger)
+}
+
+// SetDesiredConfig cambia la configuración deseada del equipo
+func (h *FieldDeviceHandler) SetDesiredConfig(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var request desiredConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	device, err := h.fieldDeviceService.SetDesiredConfig(r.Context(), id, request.TankID, request.DeviceConfig)
+	if err != nil {
+		h.logger.Error("Failed to set device config", "error", err, "id", id)
+		writeError(w, r, "Error al actualizar la configuración del equipo", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, device, h.logger)
+}
+
+// GetConfigStatus devuelve la configuración deseada y la aplicada del equipo
+func (h *FieldDeviceHandler) GetConfigStatus(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	device, err := h.fieldDeviceService.GetFieldDevice(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get field device", "error", err, "id", id)
+		writeError(w, r, "Error al obtener el equipo de campo", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, device, h.logger)
+}
+
+// ReportAppliedConfig registra la configuración que el equipo confirma haber aplicado
+func (h *FieldDeviceHandler) ReportAppliedConfig(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var config domain.DeviceConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	device, err := h.fieldDeviceService.ReportAppliedConfig(r.Context(), id, config)
+	if err != nil {
+		h.logger.Error("Failed to report applied device config", "error", err, "id", id)
+		writeError(w, r, "Error al registrar la configuración aplicada", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, device, h.logger)
+}
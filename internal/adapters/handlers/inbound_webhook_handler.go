@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/adapters/webhooks"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/i18n"
+	"monitor-tanques/pkg/logger"
+)
+
+// Resultado de cada lectura recibida por webhook
+const (
+	webhookRecordAccepted = "accepted"
+	webhookRecordRejected = "rejected"
+)
+
+// InboundWebhookResult es el informe de las lecturas recibidas por webhook
+type InboundWebhookResult struct {
+	Source   string                  `json:"source"`
+	Accepted int                     `json:"accepted"`
+	Rejected int                     `json:"rejected"`
+	Records  []*InboundWebhookRecord `json:"records"`
+}
+
+// InboundWebhookRecord es el resultado de una lectura
+type InboundWebhookRecord struct {
+	Index         int    `json:"index"`
+	TankID        string `json:"tank_id,omitempty"`
+	MeasurementID string `json:"measurement_id,omitempty"`
+	Status        string `json:"status"` // accepted o rejected
+	Error         string `json:"error,omitempty"`
+}
+
+// InboundWebhookHandler recibe las lecturas que las plataformas IoT de terceros envían por
+// webhook y las guarda como mediciones según el mapeo configurado para cada plataforma
+type InboundWebhookHandler struct {
+	tankService ports.TankService
+	mappings    map[string]*webhooks.Mapping
+	logger      logger.Logger
+}
+
+// NewInboundWebhookHandler crea una nueva instancia del manejador de webhooks entrantes.
+// mappings contiene el mapeo de cada plataforma, indexado por el nombre usado en la URL.
+func NewInboundWebhookHandler(tankService ports.TankService, mappings map[string]*webhooks.Mapping, logger logger.Logger) *InboundWebhookHandler {
+	return &InboundWebhookHandler{
+		tankService: tankService,
+		mappings:    mappings,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *InboundWebhookHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/ingest/webhooks/{source}", h.Receive).Methods(http.MethodPost)
+}
+
+// Receive guarda las lecturas del webhook de una plataforma. La plataforma se identifica con el
+// token de su mapeo, en la cabecera X-Webhook-Token o como Authorization: Bearer. Las lecturas
+// inválidas se descartan sin impedir el resto; el informe indica el resultado de cada una.
+func (h *InboundWebhookHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	source := mux.Vars(r)["source"]
+	mapping, ok := h.mappings[source]
+	if !ok {
+		writeError(w, r, "Plataforma de webhook desconocida", http.StatusNotFound)
+		return
+	}
+
+	if !validWebhookToken(r, mapping.Token) {
+		writeError(w, r, "Token de webhook inválido", http.StatusUnauthorized)
+		return
+	}
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	records, err := mapping.Extract(payload)
+	if err != nil {
+		h.logger.Warn("Invalid webhook payload", "source", source, "error", err)
+		writeError(w, r, "Contenido del webhook inválido", http.StatusBadRequest)
+		return
+	}
+
+	language := i18n.FromContext(r.Context())
+	result := &InboundWebhookResult{Source: source, Records: make([]*InboundWebhookRecord, 0, len(records))}
+	for _, record := range records {
+		outcome := &InboundWebhookRecord{Index: record.Index, Status: webhookRecordRejected}
+		result.Records = append(result.Records, outcome)
+
+		problem := record.Error
+		if measurement := record.Measurement; measurement != nil {
+			outcome.TankID = measurement.TankID
+			measurement.ID = uuid.New().String()
+			if measurement.Timestamp.IsZero() {
+				measurement.Timestamp = time.Now()
+			}
+
+			if err := h.tankService.AddMeasurement(r.Context(), measurement); err != nil && !services.IsStaleMeasurement(err) {
+				if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+					h.logger.Error("Webhook ingestion interrupted", "source", source, "error", err)
+					writeError(w, r, "Error al guardar las lecturas del webhook", statusForError(err))
+					return
+				}
+				h.logger.Warn("Failed to save webhook measurement", "source", source, "tank_id", measurement.TankID, "error", err)
+				problem = webhookRecordProblem(err)
+			} else {
+				outcome.MeasurementID = measurement.ID
+			}
+		}
+
+		if problem != "" {
+			outcome.Error = i18n.Translate(language, problem)
+			result.Rejected++
+			continue
+		}
+		outcome.Status = webhookRecordAccepted
+		result.Accepted++
+	}
+
+	h.logger.Info("Webhook received", "source", source, "accepted", result.Accepted, "rejected", result.Rejected)
+	writeJSON(w, r, http.StatusOK, result, h.logger)
+}
+
+// validWebhookToken compara en tiempo constante el token recibido con el de la plataforma
+func validWebhookToken(r *http.Request, expected string) bool {
+	token := r.Header.Get("X-Webhook-Token")
+	if token == "" {
+		token, _ = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// webhookRecordProblem describe por qué no se guardó una lectura
+func webhookRecordProblem(err error) string {
+	switch {
+	case errors.Is(err, services.ErrTankNotFound):
+		return "El tanque no existe"
+	case errors.Is(err, services.ErrForbidden):
+		return "Sin acceso al tanque"
+	default:
+		return "No se pudo guardar la medición"
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// InventorySyncHandler maneja las peticiones HTTP de la sincronización de inventario con los ERP
+type InventorySyncHandler struct {
+	syncService ports.InventorySyncService
+	logger      logger.Logger
+}
+
+// NewInventorySyncHandler crea una nueva instancia del manejador de sincronización con los ERP
+func NewInventorySyncHandler(syncService ports.InventorySyncService, logger logger.Logger) *InventorySyncHandler {
+	return &InventorySyncHandler{
+		syncService: syncService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *InventorySyncHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/erp/{connector}/sync", h.Sync).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/erp/{connector}/syncs", h.GetSyncs).Methods(http.MethodGet)
+}
+
+// Sync envía en el momento el inventario por la conexión, sin esperar a la tarea programada
+func (h *InventorySyncHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	connector := mux.Vars(r)["connector"]
+
+	result, err := h.syncService.Sync(r.Context(), connector)
+	if err != nil {
+		h.logger.Error("Failed to sync inventory", "error", err, "connector", connector)
+		writeError(w, r, "Error al sincronizar el inventario con el ERP", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Inventory synced", "connector", connector, "batch", result.BatchID, "tanks", result.Tanks, "attempts", result.Attempts)
+	writeJSON(w, r, http.StatusOK, result, h.logger)
+}
+
+// GetSyncs devuelve las últimas sincronizaciones de la conexión con su confirmación o su error
+func (h *InventorySyncHandler) GetSyncs(w http.ResponseWriter, r *http.Request) {
+	connector := mux.Vars(r)["connector"]
+
+	syncs, err := h.syncService.GetSyncs(r.Context(), connector)
+	if err != nil {
+		h.logger.Error("Failed to get inventory syncs", "error", err, "connector", connector)
+		writeError(w, r, "Error al obtener las sincronizaciones con el ERP", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, syncs, h.logger)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// KPIHandler maneja las peticiones HTTP de los indicadores de gestión de los tanques
+type KPIHandler struct {
+	kpiService ports.KPIService
+	logger     logger.Logger
+}
+
+// NewKPIHandler crea una nueva instancia del manejador de indicadores
+func NewKPIHandler(kpiService ports.KPIService, logger logger.Logger) *KPIHandler {
+	return &KPIHandler{
+		kpiService: kpiService,
+		logger:     logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *KPIHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/tanks/{id}/kpis", h.GetTankKPIs).Methods(http.MethodGet)
+	router.HandleFunc("/api/tanks/{id}/stats", h.GetTankStats).Methods(http.MethodGet)
+}
+
+// GetTankKPIs devuelve los indicadores de un tanque en el periodo solicitado
+func (h *KPIHandler) GetTankKPIs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	// Por defecto devolvemos los últimos 30 días
+	from, to, err := parsePeriod(r, 30*24*time.Hour)
+	if err != nil {
+		writeError(w, r, "Parámetros from/to inválidos", http.StatusBadRequest)
+		return
+	}
+
+	kpis, err := h.kpiService.GetTankKPIs(ctx, id, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get tank KPIs", "error", err, "id", id)
+		writeError(w, r, "Error al obtener los indicadores del tanque", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, kpis, h.logger)
+}
+
+// GetTankStats devuelve las estadísticas del llenado de un tanque. period (p. ej. 30d o 12h)
+// fija la duración del periodo, que por defecto son 30 días y termina en to o ahora.
+func (h *KPIHandler) GetTankStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	span := 30 * 24 * time.Hour
+	if value := r.URL.Query().Get("period"); value != "" {
+		parsed, err := parseSpan(value)
+		if err != nil {
+			writeError(w, r, "El parámetro period debe ser una duración, p. ej. 30d", http.StatusBadRequest)
+			return
+		}
+		span = parsed
+	}
+
+	from, to, err := parsePeriod(r, span)
+	if err != nil {
+		writeError(w, r, "Parámetros from/to inválidos", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.kpiService.GetTankStats(ctx, id, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get tank stats", "error", err, "id", id)
+		writeError(w, r, "Error al obtener las estadísticas del tanque", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, stats, h.logger)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// LiquidPolicyHandler maneja las peticiones HTTP de las políticas por tipo de líquido
+type LiquidPolicyHandler struct {
+	policyService ports.LiquidPolicyService
+	logger        logger.Logger
+}
+
+// NewLiquidPolicyHandler crea una nueva instancia del manejador de políticas por tipo de líquido
+func NewLiquidPolicyHandler(policyService ports.LiquidPolicyService, logger logger.Logger) *LiquidPolicyHandler {
+	return &LiquidPolicyHandler{
+		policyService: policyService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *LiquidPolicyHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/liquid-policies", h.GetPolicies).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/liquid-policies/{liquidType}", h.GetPolicy).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/liquid-policies/{liquidType}", h.SavePolicy).Methods(http.MethodPut)
+	router.HandleFunc("/api/admin/liquid-policies/{liquidType}", h.DeletePolicy).Methods(http.MethodDelete)
+}
+
+// GetPolicies devuelve todas las políticas
+func (h *LiquidPolicyHandler) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.policyService.GetPolicies(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get liquid policies", "error", err)
+		writeError(w, r, "Error al obtener las políticas de líquidos", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, policies, h.logger)
+}
+
+// GetPolicy devuelve la política del tipo de líquido
+func (h *LiquidPolicyHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	liquidType := mux.Vars(r)["liquidType"]
+
+	policy, err := h.policyService.GetPolicy(r.Context(), liquidType)
+	if err != nil {
+		h.logger.Error("Failed to get liquid policy", "error", err, "liquid_type", liquidType)
+		writeError(w, r, "Error al obtener la política del líquido", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, policy, h.logger)
+}
+
+// SavePolicy crea o reemplaza la política del tipo de líquido de la ruta
+func (h *LiquidPolicyHandler) SavePolicy(w http.ResponseWriter, r *http.Request) {
+	liquidType := mux.Vars(r)["liquidType"]
+
+	var policy domain.LiquidPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+	policy.LiquidType = liquidType
+
+	saved, err := h.policyService.SavePolicy(r.Context(), &policy)
+	if err != nil {
+		h.logger.Error("Failed to save liquid policy", "error", err, "liquid_type", liquidType)
+		writeError(w, r, "Error al guardar la política del líquido", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Liquid policy saved", "liquid_type", saved.LiquidType,
+		"min_safety_stock", saved.MinSafetyStock, "max_fill", saved.MaxFill)
+	writeJSON(w, r, http.StatusOK, saved, h.logger)
+}
+
+// DeletePolicy elimina la política del tipo de líquido
+func (h *LiquidPolicyHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	liquidType := mux.Vars(r)["liquidType"]
+
+	if err := h.policyService.DeletePolicy(r.Context(), liquidType); err != nil {
+		h.logger.Error("Failed to delete liquid policy", "error", err, "liquid_type", liquidType)
+		writeError(w, r, "Error al eliminar la política del líquido", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Liquid policy deleted", "liquid_type", liquidType)
+	w.WriteHeader(http.StatusNoContent)
+}
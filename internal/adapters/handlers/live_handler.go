@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/adapters/sse"
+	"monitor-tanques/internal/adapters/websocket"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// LivePath es el prefijo de las conexiones en tiempo real: WebSocket en la propia ruta y SSE en
+// /events. Se autentican en el propio manejador porque los navegadores no pueden enviar la
+// cabecera Authorization al abrir un WebSocket ni un EventSource.
+const LivePath = "/api/live"
+
+// LiveHandler maneja las conexiones de eventos en tiempo real
+type LiveHandler struct {
+	wsServer      *websocket.Server
+	sseServer     *sse.Server
+	authenticator ports.Authenticator // nil sin autenticación
+	logger        logger.Logger
+}
+
+// NewLiveHandler crea una nueva instancia del manejador de eventos en tiempo real
+func NewLiveHandler(wsServer *websocket.Server, sseServer *sse.Server, authenticator ports.Authenticator, logger logger.Logger) *LiveHandler {
+	return &LiveHandler{
+		wsServer:      wsServer,
+		sseServer:     sseServer,
+		authenticator: authenticator,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *LiveHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc(LivePath, h.Connect).Methods(http.MethodGet)
+	router.HandleFunc(LivePath+"/events", h.Stream).Methods(http.MethodGet)
+}
+
+// authenticate identifica al cliente con su token (cabecera Authorization o parámetro
+// access_token). Devuelve false si ya respondió con el error; el principal es nil sin autenticación.
+func (h *LiveHandler) authenticate(w http.ResponseWriter, r *http.Request) (*domain.Principal, bool) {
+	if h.authenticator == nil {
+		return nil, true
+	}
+
+	token := liveToken(r)
+	if token == "" {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, r, "Autenticación requerida", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	principal, err := h.authenticator.Authenticate(r.Context(), token)
+	if err != nil {
+		h.logger.Warn("Live authentication failed", "error", err)
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		writeError(w, r, "Token inválido", http.StatusUnauthorized)
+		return nil, false
+	}
+	if !principal.HasRole(domain.RoleViewer) {
+		writeError(w, r, "Permisos insuficientes", http.StatusForbidden)
+		return nil, false
+	}
+	if !principal.HasScope(domain.ScopeReadTanks) {
+		writeError(w, r, "El token no tiene el alcance necesario", http.StatusForbidden)
+		return nil, false
+	}
+	return principal, true
+}
+
+// Connect abre la conexión WebSocket del cliente autenticado, que recibe los eventos de los temas
+// a los que se suscriba
+func (h *LiveHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := h.wsServer.Upgrade(w, r)
+	if err != nil {
+		if errors.Is(err, websocket.ErrNotWebSocket) {
+			writeError(w, r, "Se esperaba una conexión WebSocket", http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to upgrade live connection", "error", err)
+		return
+	}
+
+	h.logger.Info("Live client connected", "subject", domain.SubjectOf(principal), "remote_addr", r.RemoteAddr)
+	h.wsServer.Serve(conn, principal)
+	h.logger.Info("Live client disconnected", "subject", domain.SubjectOf(principal))
+}
+
+// Stream transmite por Server-Sent Events los eventos de los temas del parámetro topics,
+// separados por comas, al cliente autenticado
+func (h *LiveHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	principal, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	var topics []string
+	for _, topic := range strings.Split(r.URL.Query().Get("topics"), ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+
+	h.logger.Info("Live client connected", "subject", domain.SubjectOf(principal), "remote_addr", r.RemoteAddr, "transport", sse.Transport)
+	if err := h.sseServer.Serve(w, r, principal, topics); err != nil {
+		writeError(w, r, "Parámetro topics inválido", http.StatusBadRequest)
+		return
+	}
+	h.logger.Info("Live client disconnected", "subject", domain.SubjectOf(principal), "transport", sse.Transport)
+}
+
+// liveToken extrae el token de la cabecera Authorization o, para los navegadores, del parámetro
+// access_token
+func liveToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(token) != "" {
+		return strings.TrimSpace(token)
+	}
+	return r.URL.Query().Get("access_token")
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// ndjsonContentType es el tipo de contenido de las exportaciones en streaming (una medición por línea)
+const ndjsonContentType = "application/x-ndjson"
+
+// ndjsonFlushRows es el número de líneas escritas entre cada envío al cliente
+const ndjsonFlushRows = 100
+
+// MeasurementHandler maneja las peticiones HTTP de consulta del historial de mediciones
+type MeasurementHandler struct {
+	measurementService ports.MeasurementService
+	logger             logger.Logger
+}
+
+// NewMeasurementHandler crea una nueva instancia del manejador de mediciones
+func NewMeasurementHandler(measurementService ports.MeasurementService, logger logger.Logger) *MeasurementHandler {
+	return &MeasurementHandler{
+		measurementService: measurementService,
+		logger:             logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *MeasurementHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/tanks/{id}/measurements", h.GetMeasurements).Methods(http.MethodGet)
+}
+
+// GetMeasurements devuelve las mediciones de un tanque, de la más reciente a la más antigua,
+// limitadas opcionalmente con limit y filtradas con una expresión en filter (p. ej. level<100 AND
+// temperature>30, ver domain.ParseFilter). Con format=ndjson (o Accept: application/x-ndjson)
+// las filas se escriben a medida que se leen del repositorio, sin acumular el historial en
+// memoria. Con step devuelve en su lugar la serie uniforme del periodo (ver getSeries).
+func (h *MeasurementHandler) GetMeasurements(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if r.URL.Query().Get("step") != "" {
+		h.getSeries(w, r, id)
+		return
+	}
+
+	// A partir de la v2, limit y offset paginan el historial completo dentro del sobre
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" && (!usesEnvelope(r) || wantsNDJSON(r)) {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, r, "El parámetro limit debe ser un entero positivo", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	filter := r.URL.Query().Get("filter")
+
+	if wantsNDJSON(r) {
+		h.streamMeasurements(w, r, id, limit, filter)
+		return
+	}
+
+	measurements, err := h.measurementService.GetMeasurements(r.Context(), id, limit, filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidFilter) {
+			writeFilterError(w, r, err)
+			return
+		}
+		h.logger.Error("Failed to get measurements", "error", err, "id", id)
+		writeError(w, r, "Error al obtener las mediciones", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, measurements, h.logger)
+}
+
+// getSeries devuelve el historial remuestreado cada step (p. ej. 15m o 1d) entre from y to, por
+// defecto las últimas 24 horas. fill indica cómo completar los pasos sin mediciones: none (por
+// defecto), linear o locf; los valores estimados se marcan con filled.
+func (h *MeasurementHandler) getSeries(w http.ResponseWriter, r *http.Request, id string) {
+	step, err := parseSpan(r.URL.Query().Get("step"))
+	if err != nil {
+		writeError(w, r, "El parámetro step debe ser una duración, p. ej. 15m", http.StatusBadRequest)
+		return
+	}
+	from, to, err := parsePeriod(r, 24*time.Hour)
+	if err != nil {
+		writeError(w, r, "Parámetros from/to inválidos", http.StatusBadRequest)
+		return
+	}
+	fill := r.URL.Query().Get("fill")
+	if fill == "" {
+		fill = domain.SeriesFillNone
+	}
+
+	series, err := h.measurementService.GetSeries(r.Context(), id, from, to, step, fill)
+	if err != nil {
+		h.logger.Error("Failed to get measurement series", "error", err, "id", id)
+		writeError(w, r, "Error al obtener la serie de mediciones", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, series, h.logger)
+}
+
+// streamMeasurements escribe las mediciones como NDJSON. Las cabeceras se envían con la primera
+// fila, de modo que los errores previos (tanque inexistente o sin acceso) conservan su código.
+func (h *MeasurementHandler) streamMeasurements(w http.ResponseWriter, r *http.Request, id string, limit int, filter string) {
+	controller := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+	rows := 0
+
+	err := h.measurementService.StreamMeasurements(r.Context(), id, limit, filter, func(measurement *domain.Measurement) error {
+		if rows == 0 {
+			w.Header().Set("Content-Type", ndjsonContentType)
+		}
+		if err := encoder.Encode(measurement); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%ndjsonFlushRows == 0 {
+			// No todos los ResponseWriter admiten Flush; en ese caso la respuesta sale al terminar
+			controller.Flush()
+		}
+		return nil
+	})
+
+	if err != nil {
+		if rows > 0 {
+			// El estado ya se envió: solo queda cortar la respuesta para que el cliente note el fallo
+			h.logger.Error("Measurement stream interrupted", "error", err, "id", id, "rows", rows)
+			panic(http.ErrAbortHandler)
+		}
+		if errors.Is(err, services.ErrInvalidFilter) {
+			writeFilterError(w, r, err)
+			return
+		}
+		h.logger.Error("Failed to stream measurements", "error", err, "id", id)
+		writeError(w, r, "Error al obtener las mediciones", statusForError(err))
+		return
+	}
+
+	if rows == 0 {
+		w.Header().Set("Content-Type", ndjsonContentType)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// wantsNDJSON indica si el cliente pidió la exportación en streaming
+func wantsNDJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "ndjson" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), ndjsonContentType)
+}
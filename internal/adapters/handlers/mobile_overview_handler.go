@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// MobileOverviewHandler maneja la pantalla de inicio de la aplicación móvil
+type MobileOverviewHandler struct {
+	overviewService ports.MobileOverviewService
+	logger          logger.Logger
+}
+
+// NewMobileOverviewHandler crea una nueva instancia del manejador de la pantalla de inicio móvil
+func NewMobileOverviewHandler(overviewService ports.MobileOverviewService, logger logger.Logger) *MobileOverviewHandler {
+	return &MobileOverviewHandler{
+		overviewService: overviewService,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *MobileOverviewHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/mobile/overview", h.GetOverview).Methods(http.MethodGet)
+}
+
+// GetOverview devuelve el resumen compacto de los tanques
+func (h *MobileOverviewHandler) GetOverview(w http.ResponseWriter, r *http.Request) {
+	overview, err := h.overviewService.GetOverview(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get mobile overview", "error", err)
+		writeError(w, r, "Error al obtener el resumen de los tanques", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, overview, h.logger)
+}
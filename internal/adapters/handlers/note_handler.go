@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// NoteHandler maneja las peticiones HTTP de notas y línea de tiempo de los tanques
+type NoteHandler struct {
+	noteService ports.NoteService
+	logger      logger.Logger
+}
+
+// NewNoteHandler crea una nueva instancia del manejador de notas
+func NewNoteHandler(noteService ports.NoteService, logger logger.Logger) *NoteHandler {
+	return &NoteHandler{
+		noteService: noteService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *NoteHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/tanks/{id}/notes", h.GetNotes).Methods(http.MethodGet)
+	router.HandleFunc("/api/tanks/{id}/notes", h.AddNote).Methods(http.MethodPost)
+	router.HandleFunc("/api/tanks/{id}/timeline", h.GetTimeline).Methods(http.MethodGet)
+}
+
+// AddNote registra una observación sobre el tanque
+func (h *NoteHandler) AddNote(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var note domain.TankNote
+	if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Generamos un ID único si no se proporcionó
+	if note.ID == "" {
+		note.ID = uuid.New().String()
+	}
+	note.TankID = id
+
+	if err := h.noteService.AddNote(r.Context(), &note); err != nil {
+		h.logger.Error("Failed to add note", "error", err, "id", id)
+		writeError(w, r, "Error al registrar la nota", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, note, h.logger)
+}
+
+// GetNotes devuelve las notas del tanque en el periodo solicitado
+func (h *NoteHandler) GetNotes(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	// Por defecto devolvemos los últimos 30 días
+	from, to, err := parsePeriod(r, 30*24*time.Hour)
+	if err != nil {
+		writeError(w, r, "Parámetros from/to inválidos", http.StatusBadRequest)
+		return
+	}
+
+	notes, err := h.noteService.GetNotes(r.Context(), id, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get notes", "error", err, "id", id)
+		writeError(w, r, "Error al obtener las notas", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, notes, h.logger)
+}
+
+// GetTimeline devuelve las mediciones, notas y cambios de estado del tanque en orden cronológico
+func (h *NoteHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	// Por defecto devolvemos los últimos 7 días
+	from, to, err := parsePeriod(r, 7*24*time.Hour)
+	if err != nil {
+		writeError(w, r, "Parámetros from/to inválidos", http.StatusBadRequest)
+		return
+	}
+
+	timeline, err := h.noteService.GetTimeline(r.Context(), id, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get timeline", "error", err, "id", id)
+		writeError(w, r, "Error al obtener la línea de tiempo", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, timeline, h.logger)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// NotificationHandler maneja las peticiones HTTP relacionadas con los canales de notificación
+type NotificationHandler struct {
+	notificationService ports.NotificationService
+	logger              logger.Logger
+}
+
+// NewNotificationHandler crea una nueva instancia del manejador de notificaciones
+func NewNotificationHandler(notificationService ports.NotificationService, logger logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *NotificationHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/notification-channels", h.GetAllChannels).Methods(http.MethodGet)
+	router.HandleFunc("/api/notification-channels/{id}", h.GetChannel).Methods(http.MethodGet)
+	router.HandleFunc("/api/notification-channels", h.CreateChannel).Methods(http.MethodPost)
+	router.HandleFunc("/api/notification-channels/{id}", h.UpdateChannel).Methods(http.MethodPut)
+	router.HandleFunc("/api/notification-channels/{id}", h.DeleteChannel).Methods(http.MethodDelete)
+	router.HandleFunc("/api/notification-channels/{id}/queue", h.GetQueuedAlerts).Methods(http.MethodGet)
+}
+
+// GetAllChannels devuelve todos los canales de notificación
+func (h *NotificationHandler) GetAllChannels(w http.ResponseWriter, r *http.Request) {
+	channels, err := h.notificationService.GetAllChannels(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get notification channels", "error", err)
+		writeError(w, r, "Error al obtener los canales de notificación", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, channels, h.logger)
+}
+
+// GetChannel devuelve un canal de notificación específico
+func (h *NotificationHandler) GetChannel(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	channel, err := h.notificationService.GetChannel(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get notification channel", "error", err, "id", id)
+		writeError(w, r, "Error al obtener el canal de notificación", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, channel, h.logger)
+}
+
+// CreateChannel crea un nuevo canal de notificación
+func (h *NotificationHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	var channel domain.NotificationChannel
+	if err := json.NewDecoder(r.Body).Decode(&channel); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Generamos un ID único si no se proporcionó
+	if channel.ID == "" {
+		channel.ID = uuid.New().String()
+	}
+
+	if err := h.notificationService.CreateChannel(r.Context(), &channel); err != nil {
+		h.logger.Error("Failed to create notification channel", "error", err)
+		writeError(w, r, "Error al crear el canal de notificación", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, channel, h.logger)
+}
+
+// UpdateChannel actualiza un canal de notificación existente
+func (h *NotificationHandler) UpdateChannel(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var channel domain.NotificationChannel
+	if err := json.NewDecoder(r.Body).Decode(&channel); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Aseguramos que el ID en el cuerpo coincida con el de la URL
+	channel.ID = id
+
+	if err := h.notificationService.UpdateChannel(r.Context(), &channel); err != nil {
+		h.logger.Error("Failed to update notification channel", "error", err, "id", id)
+		writeError(w, r, "Error al actualizar el canal de notificación", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, channel, h.logger)
+}
+
+// DeleteChannel elimina un canal de notificación
+func (h *NotificationHandler) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.notificationService.DeleteChannel(r.Context(), id); err != nil {
+		h.logger.Error("Failed to delete notification channel", "error", err, "id", id)
+		writeError(w, r, "Error al eliminar el canal de notificación", statusForError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetQueuedAlerts devuelve las alertas retenidas fuera de horario para un canal, filtradas
+// opcionalmente con una expresión en filter (p. ej. severity=critical AND level<100)
+func (h *NotificationHandler) GetQueuedAlerts(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	alerts, err := h.notificationService.GetQueuedAlerts(r.Context(), id, r.URL.Query().Get("filter"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidFilter) {
+			writeFilterError(w, r, err)
+			return
+		}
+		h.logger.Error("Failed to get queued alerts", "error", err, "id", id)
+		writeError(w, r, "Error al obtener las alertas en cola", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, alerts, h.logger)
+}
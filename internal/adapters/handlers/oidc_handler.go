@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/adapters/auth"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// oidcStateCookie guarda el parámetro state entre el inicio de sesión y el callback
+const oidcStateCookie = "oidc_state"
+
+// OIDCHandler maneja el inicio de sesión delegado en un proveedor de identidad externo
+type OIDCHandler struct {
+	provider       *auth.OIDCProvider
+	sessionService ports.SessionService
+	protection     *auth.LoginProtection // nil sin protección frente a fuerza bruta
+	logger         logger.Logger
+}
+
+// NewOIDCHandler crea una nueva instancia del manejador OIDC. Cada inicio de sesión abre una
+// sesión en sessionService, cuyos tokens son los que recibe el cliente. Con protection, los callbacks
+// fallidos cuentan como intentos fallidos, los clientes bloqueados reciben 429 y, tras varios
+// fallos, el inicio de sesión exige una respuesta CAPTCHA en el parámetro captcha.
+func NewOIDCHandler(provider *auth.OIDCProvider, sessionService ports.SessionService, protection *auth.LoginProtection, logger logger.Logger) *OIDCHandler {
+	return &OIDCHandler{
+		provider:       provider,
+		sessionService: sessionService,
+		protection:     protection,
+		logger:         logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *OIDCHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/auth/oidc/login", h.Login).Methods(http.MethodGet)
+	router.HandleFunc("/api/auth/oidc/callback", h.Callback).Methods(http.MethodGet)
+}
+
+// Login redirige al usuario a la página de inicio de sesión del proveedor
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if h.protection != nil {
+		attempt := h.loginAttempt(r)
+		if h.rejectLocked(w, r, attempt) {
+			return
+		}
+		if h.protection.Guard.RequiresCaptcha(r.Context(), attempt.ClientIP) {
+			if err := h.protection.Guard.VerifyCaptcha(r.Context(), attempt.ClientIP, r.URL.Query().Get("captcha")); err != nil {
+				h.writeCaptchaError(w, r, attempt, err)
+				return
+			}
+		}
+	}
+
+	stateBytes := make([]byte, 24)
+	if _, err := rand.Read(stateBytes); err != nil {
+		h.logger.Error("Failed to generate OIDC state", "error", err)
+		writeError(w, r, "Error al iniciar sesión", http.StatusInternalServerError)
+		return
+	}
+	state := base64.RawURLEncoding.EncodeToString(stateBytes)
+
+	authURL, err := h.provider.AuthCodeURL(r.Context(), state)
+	if err != nil {
+		h.logger.Error("Failed to build OIDC login URL", "error", err)
+		writeError(w, r, "Proveedor de identidad no disponible", http.StatusBadGateway)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/auth/oidc",
+		MaxAge:   int((10 * time.Minute).Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback recibe el código de autorización del proveedor, abre una sesión para el usuario y
+// devuelve sus tokens de acceso y de refresco junto con el id_token del proveedor
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	attempt := h.loginAttempt(r)
+	if h.rejectLocked(w, r, attempt) {
+		return
+	}
+
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(query.Get("state"))) != 1 {
+		h.recordFailure(r, attempt, "invalid state")
+		writeError(w, r, "Parámetro state inválido", http.StatusBadRequest)
+		return
+	}
+
+	// Eliminamos la cookie de estado, que es de un solo uso
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/api/auth/oidc", MaxAge: -1})
+
+	code := query.Get("code")
+	if code == "" {
+		h.recordFailure(r, attempt, "missing authorization code")
+		writeError(w, r, "Código de autorización ausente", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.provider.Exchange(r.Context(), code)
+	if err != nil {
+		h.logger.Error("Failed to exchange OIDC code", "error", err)
+		h.recordFailure(r, attempt, err.Error())
+		writeError(w, r, "Error al validar el inicio de sesión", http.StatusUnauthorized)
+		return
+	}
+
+	principal, err := h.provider.Authenticate(r.Context(), tokens.IDToken)
+	if err != nil {
+		h.logger.Error("Failed to validate OIDC id token", "error", err)
+		h.recordFailure(r, attempt, err.Error())
+		writeError(w, r, "Error al validar el inicio de sesión", http.StatusUnauthorized)
+		return
+	}
+
+	session, err := h.sessionService.CreateSession(r.Context(), principal, attempt.ClientIP, r.UserAgent())
+	if err != nil {
+		h.logger.Error("Failed to create session", "error", err, "subject", principal.Subject)
+		writeError(w, r, "Error al iniciar sesión", statusForError(err))
+		return
+	}
+	session.IDToken = tokens.IDToken
+
+	if h.protection != nil {
+		attempt.Subject = principal.Subject
+		if err := h.protection.Guard.RecordSuccess(r.Context(), attempt); err != nil {
+			h.logger.Error("Failed to record login success", "error", err, "client_ip", attempt.ClientIP)
+		}
+	}
+
+	h.logger.Info("Session started", "subject", principal.Subject, "session_id", session.SessionID)
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, http.StatusOK, session, h.logger)
+}
+
+// loginAttempt describe el intento de inicio de sesión de la solicitud
+func (h *OIDCHandler) loginAttempt(r *http.Request) domain.LoginAttempt {
+	attempt := domain.LoginAttempt{Method: domain.LoginMethodOIDC, Path: r.URL.Path}
+	if h.protection != nil {
+		attempt.ClientIP = h.protection.ClientIP(r)
+	}
+	return attempt
+}
+
+// rejectLocked responde 429 si el cliente está bloqueado e indica si lo estaba
+func (h *OIDCHandler) rejectLocked(w http.ResponseWriter, r *http.Request, attempt domain.LoginAttempt) bool {
+	if h.protection == nil {
+		return false
+	}
+	wait := h.protection.Guard.Check(r.Context(), attempt.ClientIP)
+	if wait <= 0 {
+		return false
+	}
+
+	h.recordFailure(r, attempt, "locked out")
+	h.logger.Warn("Login blocked", "client_ip", attempt.ClientIP, "path", r.URL.Path, "retry_after", wait)
+	auth.SetRetryAfter(w, wait)
+	writeError(w, r, "Demasiados intentos fallidos; inténtelo más tarde", http.StatusTooManyRequests)
+	return true
+}
+
+// recordFailure cuenta un intento fallido del cliente
+func (h *OIDCHandler) recordFailure(r *http.Request, attempt domain.LoginAttempt, reason string) {
+	if h.protection == nil {
+		return
+	}
+	attempt.Reason = reason
+	if err := h.protection.Guard.RecordFailure(r.Context(), attempt); err != nil {
+		h.logger.Error("Failed to record login failure", "error", err, "client_ip", attempt.ClientIP)
+	}
+}
+
+// writeCaptchaError responde al cliente que no superó la verificación CAPTCHA
+func (h *OIDCHandler) writeCaptchaError(w http.ResponseWriter, r *http.Request, attempt domain.LoginAttempt, err error) {
+	switch {
+	case errors.Is(err, services.ErrCaptchaRequired):
+		writeError(w, r, "Verificación CAPTCHA requerida", statusForError(err))
+	case errors.Is(err, services.ErrInvalidCaptcha):
+		h.logger.Warn("Invalid captcha response", "client_ip", attempt.ClientIP)
+		writeError(w, r, "Verificación CAPTCHA inválida", statusForError(err))
+	default:
+		h.logger.Error("Failed to verify captcha", "error", err, "client_ip", attempt.ClientIP)
+		writeError(w, r, "Error al verificar el CAPTCHA", http.StatusBadGateway)
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// errInvalidPeriodParams se devuelve cuando from/to no tienen formato RFC3339
+var errInvalidPeriodParams = errors.New("invalid from/to parameters")
+
+// errInvalidSpanParam se devuelve cuando una duración no es positiva ni tiene un formato válido
+var errInvalidSpanParam = errors.New("invalid duration parameter")
+
+// errInvalidBBoxParam se devuelve cuando bbox no tiene el formato minLon,minLat,maxLon,maxLat
+var errInvalidBBoxParam = errors.New("invalid bbox parameter")
+
+// parsePeriod lee los parámetros from y to (RFC3339) de la solicitud. Si faltan, el periodo
+// termina ahora y abarca la duración indicada por defaultSpan.
+func parsePeriod(r *http.Request, defaultSpan time.Duration) (time.Time, time.Time, error) {
+	query := r.URL.Query()
+
+	to := time.Now()
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errInvalidPeriodParams
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultSpan)
+	if value := query.Get("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errInvalidPeriodParams
+		}
+		from = parsed
+	}
+
+	return from, to, nil
+}
+
+// parseSpan lee una duración positiva en días (30d) o en el formato de Go (12h, 90m)
+func parseSpan(value string) (time.Duration, error) {
+	var span time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		parsed, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, errInvalidSpanParam
+		}
+		span = time.Duration(parsed) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, errInvalidSpanParam
+		}
+		span = parsed
+	}
+
+	if span <= 0 {
+		return 0, errInvalidSpanParam
+	}
+	return span, nil
+}
+
+// parseLabelSelector lee el selector de etiquetas del parámetro labels; vacío selecciona todo
+func parseLabelSelector(r *http.Request) (domain.LabelSelector, error) {
+	return domain.ParseLabelSelector(r.URL.Query().Get("labels"))
+}
+
+// parseBoundingBox lee el parámetro bbox (minLon,minLat,maxLon,maxLat), o nil si no se indicó
+func parseBoundingBox(r *http.Request) (*domain.BoundingBox, error) {
+	value := r.URL.Query().Get("bbox")
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, errInvalidBBoxParam
+	}
+
+	coords := make([]float64, 4)
+	for i, part := range parts {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, errInvalidBBoxParam
+		}
+		coords[i] = parsed
+	}
+
+	bbox := &domain.BoundingBox{
+		MinLongitude: coords[0],
+		MinLatitude:  coords[1],
+		MaxLongitude: coords[2],
+		MaxLatitude:  coords[3],
+	}
+	if bbox.MinLongitude > bbox.MaxLongitude || bbox.MinLatitude > bbox.MaxLatitude {
+		return nil, errInvalidBBoxParam
+	}
+
+	return bbox, nil
+}
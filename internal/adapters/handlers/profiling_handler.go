@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/gorilla/mux"
+)
+
+// profilingPrefix sitúa los perfiles bajo /api/admin para que, con autenticación, solo los
+// administradores puedan capturarlos
+const profilingPrefix = "/api/admin/debug/pprof"
+
+// ProfilingHandler expone los perfiles de net/http/pprof para diagnosticar la API en producción
+type ProfilingHandler struct{}
+
+// NewProfilingHandler crea una nueva instancia del manejador de perfiles
+func NewProfilingHandler() *ProfilingHandler {
+	return &ProfilingHandler{}
+}
+
+// RegisterRoutes registra las rutas de perfiles en el router
+func (h *ProfilingHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc(profilingPrefix+"/", h.Index).Methods(http.MethodGet)
+	router.HandleFunc(profilingPrefix+"/cmdline", pprof.Cmdline).Methods(http.MethodGet)
+	router.HandleFunc(profilingPrefix+"/profile", pprof.Profile).Methods(http.MethodGet)
+	router.HandleFunc(profilingPrefix+"/symbol", pprof.Symbol).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc(profilingPrefix+"/trace", pprof.Trace).Methods(http.MethodGet)
+	router.HandleFunc(profilingPrefix+"/{profile}", h.Profile).Methods(http.MethodGet)
+}
+
+// Index muestra la lista de perfiles disponibles
+func (h *ProfilingHandler) Index(w http.ResponseWriter, r *http.Request) {
+	pprof.Index(w, r)
+}
+
+// Profile sirve un perfil por nombre (heap, goroutine, allocs, block, mutex, threadcreate)
+func (h *ProfilingHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	pprof.Handler(mux.Vars(r)["profile"]).ServeHTTP(w, r)
+}
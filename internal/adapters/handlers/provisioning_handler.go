@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/adapters/provisioning"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// maxProvisioningFileSize limita el tamaño del archivo de aprovisionamiento recibido
+const maxProvisioningFileSize = 1 << 20
+
+// ProvisioningHandler maneja las peticiones HTTP de aprovisionamiento declarativo
+type ProvisioningHandler struct {
+	provisioningService ports.ProvisioningService
+	logger              logger.Logger
+}
+
+// NewProvisioningHandler crea una nueva instancia del manejador de aprovisionamiento
+func NewProvisioningHandler(provisioningService ports.ProvisioningService, logger logger.Logger) *ProvisioningHandler {
+	return &ProvisioningHandler{
+		provisioningService: provisioningService,
+		logger:              logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router. Al estar bajo /api/admin, el
+// middleware de autenticación exige el rol de administrador.
+func (h *ProvisioningHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/provision", h.Provision).Methods(http.MethodPost)
+}
+
+// Provision reconcilia la instalación con el archivo YAML recibido en el cuerpo. Con
+// ?dry_run=true solo devuelve los cambios que se aplicarían.
+func (h *ProvisioningHandler) Provision(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, r, "Parámetro dry_run inválido", http.StatusBadRequest)
+			return
+		}
+		dryRun = parsed
+	}
+
+	spec, err := provisioning.Parse(http.MaxBytesReader(w, r.Body, maxProvisioningFileSize))
+	if err != nil {
+		h.logger.Error("Failed to parse provisioning file", "error", err)
+		writeError(w, r, "Archivo de aprovisionamiento inválido", http.StatusBadRequest)
+		return
+	}
+
+	var result *domain.ProvisioningResult
+	if dryRun {
+		result, err = h.provisioningService.Plan(r.Context(), spec)
+	} else {
+		result, err = h.provisioningService.Provision(r.Context(), spec)
+	}
+	if err != nil {
+		h.logger.Error("Failed to provision", "error", err, "dry_run", dryRun)
+		writeError(w, r, "Error al aplicar el aprovisionamiento", statusForError(err))
+		return
+	}
+
+	if !dryRun {
+		h.logger.Info("Provisioning applied", "created", result.Created, "updated", result.Updated, "unchanged", result.Unchanged)
+	}
+
+	writeJSON(w, r, http.StatusOK, result, h.logger)
+}
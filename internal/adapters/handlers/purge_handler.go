@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// PurgeHandler maneja las peticiones HTTP de las purgas administrativas de datos
+type PurgeHandler struct {
+	purgeService ports.PurgeService
+	logger       logger.Logger
+}
+
+// NewPurgeHandler crea una nueva instancia del manejador de purgas
+func NewPurgeHandler(purgeService ports.PurgeService, logger logger.Logger) *PurgeHandler {
+	return &PurgeHandler{
+		purgeService: purgeService,
+		logger:       logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *PurgeHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/purges", h.GetPurges).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/purges", h.StartPurge).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/purges/{id}", h.GetPurge).Methods(http.MethodGet)
+}
+
+// StartPurge lanza una purga en segundo plano y responde 202 con el trabajo para seguir su progreso
+func (h *PurgeHandler) StartPurge(w http.ResponseWriter, r *http.Request) {
+	var request domain.PurgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	job, err := h.purgeService.StartPurge(r.Context(), request)
+	if err != nil {
+		h.logger.Error("Failed to start purge", "error", err, "scope", request.Scope)
+		writeError(w, r, "Error al iniciar la purga", statusForError(err))
+		return
+	}
+
+	h.logger.Warn("Purge started", "id", job.ID, "scope", request.Scope, "tank_id", request.TankID,
+		"organization", request.Organization, "by", job.RequestedBy)
+	w.Header().Set("Location", "/api/admin/purges/"+job.ID)
+	writeJSON(w, r, http.StatusAccepted, job, h.logger)
+}
+
+// GetPurges devuelve las últimas purgas, de la más reciente a la más antigua
+func (h *PurgeHandler) GetPurges(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.purgeService.GetPurgeJobs(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get purge jobs", "error", err)
+		writeError(w, r, "Error al obtener las purgas", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, jobs, h.logger)
+}
+
+// GetPurge devuelve el estado y el progreso de una purga
+func (h *PurgeHandler) GetPurge(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	job, err := h.purgeService.GetPurgeJob(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get purge job", "error", err, "id", id)
+		writeError(w, r, "Error al obtener la purga", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, job, h.logger)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// RecomputeHandler maneja las peticiones HTTP de reconstrucción de los datos derivados
+type RecomputeHandler struct {
+	recomputeService ports.RecomputeService
+	logger           logger.Logger
+}
+
+// NewRecomputeHandler crea una nueva instancia del manejador de reconstrucción
+func NewRecomputeHandler(recomputeService ports.RecomputeService, logger logger.Logger) *RecomputeHandler {
+	return &RecomputeHandler{
+		recomputeService: recomputeService,
+		logger:           logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *RecomputeHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/recompute", h.Recompute).Methods(http.MethodPost)
+}
+
+// Recompute reconstruye los datos derivados del tanque indicado en tank, o de todos, desde from
+// (RFC3339); sin from, desde la primera medición
+func (h *RecomputeHandler) Recompute(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	tankID := query.Get("tank")
+
+	var from time.Time
+	if value := query.Get("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeError(w, r, "Parámetro from inválido", http.StatusBadRequest)
+			return
+		}
+		from = parsed
+	}
+
+	results, err := h.recomputeService.Recompute(r.Context(), tankID, from)
+	if err != nil {
+		h.logger.Error("Failed to recompute derived data", "error", err, "tank_id", tankID, "from", from)
+		writeError(w, r, "Error al recalcular los datos derivados", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Derived data recomputed", "tank_id", tankID, "from", from, "tanks", len(results))
+	writeJSON(w, r, http.StatusOK, results, h.logger)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// ReorderHandler maneja las peticiones HTTP relacionadas con el reabastecimiento
+type ReorderHandler struct {
+	reorderService ports.ReorderService
+	logger         logger.Logger
+}
+
+// NewReorderHandler crea una nueva instancia del manejador de reabastecimiento
+func NewReorderHandler(reorderService ports.ReorderService, logger logger.Logger) *ReorderHandler {
+	return &ReorderHandler{
+		reorderService: reorderService,
+		logger:         logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *ReorderHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/reorder/suggestions", h.GetSuggestions).Methods(http.MethodGet)
+	router.HandleFunc("/api/groups/{id}/capacity-plan", h.GetCapacityPlan).Methods(http.MethodGet)
+	router.HandleFunc("/api/tanks/{id}/threshold-recommendation", h.GetThresholdRecommendation).Methods(http.MethodGet)
+}
+
+// GetSuggestions devuelve los tanques que necesitan un pedido de reabastecimiento
+func (h *ReorderHandler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	days := 7
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, r, "Parámetro days inválido", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	suggestions, err := h.reorderService.GetReorderSuggestions(ctx, days)
+	if err != nil {
+		h.logger.Error("Failed to get reorder suggestions", "error", err)
+		writeError(w, r, "Error al obtener las sugerencias de pedido", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, suggestions, h.logger)
+}
+
+// GetCapacityPlan devuelve los litros que hay que entregar a los tanques del grupo en los
+// próximos days días (7 por defecto)
+func (h *ReorderHandler) GetCapacityPlan(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	days := 7
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, r, "Parámetro days inválido", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	plan, err := h.reorderService.GetGroupCapacityPlan(r.Context(), id, days)
+	if err != nil {
+		h.logger.Error("Failed to get capacity plan", "error", err, "group", id)
+		writeError(w, r, "Error al obtener el plan de capacidad del grupo", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, plan, h.logger)
+}
+
+// GetThresholdRecommendation devuelve el umbral de alerta y el punto de pedido recomendados
+// para un tanque. lead_time (p. ej. 48h o 2d) es el margen que debe dejar la alerta crítica.
+func (h *ReorderHandler) GetThresholdRecommendation(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	leadTime := domain.DefaultAlertLeadTime
+	if value := r.URL.Query().Get("lead_time"); value != "" {
+		parsed, err := parseSpan(value)
+		if err != nil {
+			writeError(w, r, "El parámetro lead_time debe ser una duración, p. ej. 48h", http.StatusBadRequest)
+			return
+		}
+		leadTime = parsed
+	}
+
+	recommendation, err := h.reorderService.GetThresholdRecommendation(r.Context(), id, leadTime)
+	if err != nil {
+		h.logger.Error("Failed to get threshold recommendation", "error", err, "id", id)
+		writeError(w, r, "Error al recomendar los umbrales del tanque", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, recommendation, h.logger)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// ReportHandler maneja las peticiones HTTP de los informes periódicos
+type ReportHandler struct {
+	dataQualityService ports.DataQualityService
+	shiftReportService ports.ShiftReportService
+	logger             logger.Logger
+}
+
+// NewReportHandler crea una nueva instancia del manejador de informes
+func NewReportHandler(dataQualityService ports.DataQualityService, shiftReportService ports.ShiftReportService, logger logger.Logger) *ReportHandler {
+	return &ReportHandler{
+		dataQualityService: dataQualityService,
+		shiftReportService: shiftReportService,
+		logger:             logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *ReportHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/reports/data-quality", h.GetDataQualityReport).Methods(http.MethodGet)
+	router.HandleFunc("/api/reports/shift", h.GetShiftReport).Methods(http.MethodGet)
+}
+
+// GetDataQualityReport devuelve el último informe de calidad de datos, o lo calcula para el
+// periodo indicado con from/to. El selector labels limita los tanques del informe.
+func (h *ReportHandler) GetDataQualityReport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	selector, err := parseLabelSelector(r)
+	if err != nil {
+		writeError(w, r, "Parámetro labels inválido", http.StatusBadRequest)
+		return
+	}
+
+	var report *domain.DataQualityReport
+	if query.Get("from") != "" || query.Get("to") != "" {
+		from, to, perr := parsePeriod(r, 24*time.Hour)
+		if perr != nil {
+			writeError(w, r, "Parámetros from/to inválidos", http.StatusBadRequest)
+			return
+		}
+		report, err = h.dataQualityService.GetReport(ctx, from, to, selector)
+	} else {
+		report, err = h.dataQualityService.GetLatestReport(ctx, selector)
+	}
+	if err != nil {
+		h.logger.Error("Failed to get data quality report", "error", err)
+		writeError(w, r, "Error al obtener el informe de calidad de datos", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, report, h.logger)
+}
+
+// GetShiftReport devuelve el informe de relevo del turno indicado con from/to; sin from, las
+// últimas 8 horas. Con format=text responde el texto plano listo para el correo de relevo.
+func (h *ReportHandler) GetShiftReport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "text" {
+		writeError(w, r, "Parámetro format inválido, use json o text", http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parsePeriod(r, 8*time.Hour)
+	if err != nil {
+		writeError(w, r, "Parámetros from/to inválidos", http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.shiftReportService.GetShiftReport(r.Context(), from, to)
+	if err != nil {
+		h.logger.Error("Failed to get shift report", "error", err, "from", from, "to", to)
+		writeError(w, r, "Error al obtener el informe de relevo de turno", statusForError(err))
+		return
+	}
+
+	if format == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, report.HandoverText())
+		return
+	}
+	writeJSON(w, r, http.StatusOK, report, h.logger)
+}
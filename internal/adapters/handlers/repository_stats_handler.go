@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// RepositoryStatsHandler maneja las peticiones HTTP del diagnóstico de la persistencia
+type RepositoryStatsHandler struct {
+	statsService ports.RepositoryStatsService
+	logger       logger.Logger
+}
+
+// NewRepositoryStatsHandler crea una nueva instancia del manejador de diagnóstico
+func NewRepositoryStatsHandler(statsService ports.RepositoryStatsService, logger logger.Logger) *RepositoryStatsHandler {
+	return &RepositoryStatsHandler{
+		statsService: statsService,
+		logger:       logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *RepositoryStatsHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/stats", h.GetStats).Methods(http.MethodGet)
+}
+
+// GetStats devuelve el backend de persistencia, las entidades guardadas, la ingesta reciente y
+// el rango de las mediciones de cada tanque
+func (h *RepositoryStatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.statsService.GetStats(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get repository stats", "error", err)
+		writeError(w, r, "Error al obtener las estadísticas de la persistencia", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, stats, h.logger)
+}
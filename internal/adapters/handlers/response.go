@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/pkg/i18n"
+	"monitor-tanques/pkg/logger"
+)
+
+// firstEnvelopedVersion es la primera versión de la API que envuelve las respuestas. La v1
+// conserva el formato original para no romper a los clientes existentes.
+const firstEnvelopedVersion = 2
+
+// errInvalidPaginationParams se devuelve cuando limit u offset no son enteros válidos
+var errInvalidPaginationParams = errors.New("invalid limit/offset parameters")
+
+// Envelope es el contrato común de las respuestas a partir de la v2
+type Envelope struct {
+	Data  interface{}       `json:"data"`
+	Meta  *Meta             `json:"meta,omitempty"`
+	Links map[string]string `json:"links"`
+}
+
+// Meta contiene la información adicional de la respuesta, como la paginación de las listas
+type Meta struct {
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describe la página devuelta de una lista
+type Pagination struct {
+	Total  int `json:"total"`  // Elementos de la lista completa
+	Count  int `json:"count"`  // Elementos de esta página
+	Limit  int `json:"limit"`  // Tamaño de página pedido (0 = sin límite)
+	Offset int `json:"offset"` // Posición del primer elemento de la página
+}
+
+// ErrorEnvelope es el cuerpo de las respuestas de error a partir de la v2
+type ErrorEnvelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describe un error de la API
+type ErrorBody struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// apiVersionKey es la clave del contexto con la versión de la API pedida por el cliente
+type apiVersionKey struct{}
+
+// WithAPIVersion anota en el contexto la versión de la API pedida (p. ej. "v2")
+func WithAPIVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, apiVersionKey{}, version)
+}
+
+// APIVersion devuelve la versión de la API anotada en el contexto, o "" si no hay ninguna
+func APIVersion(ctx context.Context) string {
+	version, _ := ctx.Value(apiVersionKey{}).(string)
+	return version
+}
+
+// usesEnvelope indica si la respuesta a la solicitud debe ir envuelta
+func usesEnvelope(r *http.Request) bool {
+	number, err := strconv.Atoi(strings.TrimPrefix(APIVersion(r.Context()), "v"))
+	return err == nil && number >= firstEnvelopedVersion
+}
+
+// writeJSON codifica value con el código de estado indicado en el formato negociado con la
+// cabecera Accept (JSON por defecto; ver negotiateEncoder). A partir de la v2 lo envuelve en un
+// Envelope y, si es una lista, la pagina según limit y offset.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, value interface{}, log logger.Logger) {
+	body, err := responseBody(r, value)
+	if err != nil {
+		writeError(w, r, "Parámetros limit u offset inválidos", http.StatusBadRequest)
+		return
+	}
+
+	encoder := negotiateEncoder(r)
+	payload, err := encoder.Marshal(body)
+	if err != nil {
+		log.Error("Failed to encode response", "error", err, "content_type", encoder.ContentType())
+		writeError(w, r, "Error al codificar la respuesta", http.StatusInternalServerError)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", encoder.ContentType())
+	header.Add("Vary", "Accept")
+	w.WriteHeader(status)
+	w.Write(payload)
+}
+
+// writeError responde con un mensaje de error en el idioma negociado con el cliente. A partir de
+// la v2 el error se devuelve como ErrorEnvelope en el formato negociado; la v1 conserva el
+// texto plano.
+func writeError(w http.ResponseWriter, r *http.Request, message string, status int) {
+	message = i18n.Translate(i18n.FromContext(r.Context()), message)
+	if !usesEnvelope(r) {
+		http.Error(w, message, status)
+		return
+	}
+
+	encoder := negotiateEncoder(r)
+	payload, err := encoder.Marshal(ErrorEnvelope{Error: ErrorBody{Status: status, Message: message}})
+	if err != nil {
+		http.Error(w, message, status)
+		return
+	}
+
+	header := w.Header()
+	header.Del("Content-Length")
+	header.Set("Content-Type", encoder.ContentType())
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Add("Vary", "Accept")
+	w.WriteHeader(status)
+	w.Write(payload)
+}
+
+// writeDecodeError responde el error al leer el cuerpo JSON de la solicitud: 413 si supera el
+// tamaño máximo permitido para la ruta y 400 si no es un JSON válido
+func writeDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, r, "El cuerpo de la solicitud supera el tamaño máximo permitido", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidTimestamp) {
+		writeError(w, r, "La marca de tiempo no es válida", http.StatusBadRequest)
+		return
+	}
+	writeError(w, r, "Error al decodificar la solicitud", http.StatusBadRequest)
+}
+
+// writeFilterError responde 400 a una expresión de filtro inválida, con el motivo y la posición
+// que indica el analizador para que quien la escribió pueda corregirla
+func writeFilterError(w http.ResponseWriter, r *http.Request, err error) {
+	message := i18n.Translate(i18n.FromContext(r.Context()), "La expresión del parámetro filter no es válida")
+	var invalid *domain.FilterError
+	if errors.As(err, &invalid) {
+		message += ": " + invalid.Error()
+	}
+	writeError(w, r, message, http.StatusBadRequest)
+}
+
+// responseBody devuelve lo que debe codificarse para la solicitud: el valor tal cual en la v1 o
+// el Envelope correspondiente a partir de la v2
+func responseBody(r *http.Request, value interface{}) (interface{}, error) {
+	if !usesEnvelope(r) {
+		return value, nil
+	}
+
+	self := requestURL(r)
+	envelope := Envelope{
+		Data:  value,
+		Links: map[string]string{"self": self.RequestURI()},
+	}
+
+	list := reflect.ValueOf(value)
+	if list.Kind() != reflect.Slice {
+		return envelope, nil
+	}
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		return nil, err
+	}
+
+	total := list.Len()
+	start := min(offset, total)
+	end := total
+	if limit > 0 {
+		end = min(start+limit, total)
+	}
+
+	page := list.Slice(start, end)
+	if list.IsNil() {
+		// Las listas vacías se devuelven como [] y no como null
+		page = reflect.MakeSlice(list.Type(), 0, 0)
+	}
+	envelope.Data = page.Interface()
+	envelope.Meta = &Meta{Pagination: &Pagination{Total: total, Count: end - start, Limit: limit, Offset: start}}
+
+	if limit > 0 {
+		if end < total {
+			envelope.Links["next"] = pageURI(self, limit, end)
+		}
+		if start > 0 {
+			envelope.Links["prev"] = pageURI(self, limit, max(0, start-limit))
+		}
+	}
+
+	return envelope, nil
+}
+
+// parsePagination lee los parámetros limit y offset; 0 significa sin límite o desde el inicio
+func parsePagination(r *http.Request) (int, int, error) {
+	values := [2]int{}
+	for i, name := range []string{"limit", "offset"} {
+		raw := r.URL.Query().Get(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return 0, 0, errInvalidPaginationParams
+		}
+		values[i] = parsed
+	}
+
+	return values[0], values[1], nil
+}
+
+// requestURL devuelve la URL pedida por el cliente, con el prefijo de versión que el enrutado
+// elimina de r.URL
+func requestURL(r *http.Request) *url.URL {
+	if parsed, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		return parsed
+	}
+	return r.URL
+}
+
+// pageURI construye el enlace a otra página conservando el resto de parámetros
+func pageURI(self *url.URL, limit, offset int) string {
+	page := *self
+	query := page.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+	page.RawQuery = query.Encode()
+	return page.RequestURI()
+}
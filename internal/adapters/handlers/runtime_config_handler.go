@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// RuntimeConfigHandler maneja las peticiones HTTP de la configuración recargable
+type RuntimeConfigHandler struct {
+	configService ports.RuntimeConfigService
+	logger        logger.Logger
+}
+
+// NewRuntimeConfigHandler crea una nueva instancia del manejador de la configuración recargable
+func NewRuntimeConfigHandler(configService ports.RuntimeConfigService, logger logger.Logger) *RuntimeConfigHandler {
+	return &RuntimeConfigHandler{
+		configService: configService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *RuntimeConfigHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/config", h.GetSettings).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/config/reload", h.Reload).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/loglevel", h.GetLogLevel).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/loglevel", h.SetLogLevel).Methods(http.MethodPut)
+}
+
+// logLevelPayload es el cuerpo de las peticiones y respuestas del nivel de logging
+type logLevelPayload struct {
+	Level string `json:"level"`
+}
+
+// GetSettings devuelve la configuración recargable en vigor
+func (h *RuntimeConfigHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.configService.GetSettings(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get runtime config", "error", err)
+		writeError(w, r, "Error al obtener la configuración", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, settings, h.logger)
+}
+
+// Reload vuelve a leer el archivo de configuración y aplica sus valores sin reiniciar el servidor
+func (h *RuntimeConfigHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.configService.Reload(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to reload runtime config", "error", err)
+		writeError(w, r, "Error al recargar la configuración", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Runtime config reloaded", "source", settings.Source, "log_level", settings.LogLevel,
+		"alert_notifier", settings.AlertNotifier, "default_alert_threshold", settings.DefaultAlertThreshold)
+	writeJSON(w, r, http.StatusOK, settings, h.logger)
+}
+
+// GetLogLevel devuelve el nivel de logging en vigor
+func (h *RuntimeConfigHandler) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.configService.GetSettings(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get log level", "error", err)
+		writeError(w, r, "Error al obtener la configuración", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, logLevelPayload{Level: settings.LogLevel}, h.logger)
+}
+
+// SetLogLevel cambia el nivel de logging sin reiniciar el servidor
+func (h *RuntimeConfigHandler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var payload logLevelPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	settings, err := h.configService.SetLogLevel(r.Context(), payload.Level)
+	if err != nil {
+		h.logger.Error("Failed to set log level", "error", err, "level", payload.Level)
+		writeError(w, r, "Error al cambiar el nivel de logging", statusForError(err))
+		return
+	}
+
+	h.logger.Warn("Log level changed", "level", settings.LogLevel)
+	writeJSON(w, r, http.StatusOK, logLevelPayload{Level: settings.LogLevel}, h.logger)
+}
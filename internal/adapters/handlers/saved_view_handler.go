@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// SavedViewHandler maneja las peticiones HTTP de las vistas guardadas del usuario
+type SavedViewHandler struct {
+	viewService ports.SavedViewService
+	logger      logger.Logger
+}
+
+// NewSavedViewHandler crea una nueva instancia del manejador de vistas guardadas
+func NewSavedViewHandler(viewService ports.SavedViewService, logger logger.Logger) *SavedViewHandler {
+	return &SavedViewHandler{
+		viewService: viewService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *SavedViewHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/views", h.GetViews).Methods(http.MethodGet)
+	router.HandleFunc("/api/views", h.CreateView).Methods(http.MethodPost)
+	router.HandleFunc("/api/views/{id}", h.GetView).Methods(http.MethodGet)
+	router.HandleFunc("/api/views/{id}", h.UpdateView).Methods(http.MethodPut)
+	router.HandleFunc("/api/views/{id}", h.DeleteView).Methods(http.MethodDelete)
+}
+
+// GetViews devuelve las vistas del usuario ordenadas por nombre
+func (h *SavedViewHandler) GetViews(w http.ResponseWriter, r *http.Request) {
+	views, err := h.viewService.GetViews(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get saved views", "error", err)
+		writeError(w, r, "Error al obtener las vistas guardadas", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, views, h.logger)
+}
+
+// GetView devuelve una vista del usuario
+func (h *SavedViewHandler) GetView(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	view, err := h.viewService.GetView(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get saved view", "error", err, "id", id)
+		writeError(w, r, "Error al obtener la vista guardada", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, view, h.logger)
+}
+
+// CreateView guarda una vista nueva del usuario
+func (h *SavedViewHandler) CreateView(w http.ResponseWriter, r *http.Request) {
+	var view domain.SavedView
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	if err := h.viewService.CreateView(r.Context(), &view); err != nil {
+		h.logger.Error("Failed to create saved view", "error", err, "name", view.Name)
+		writeError(w, r, "Error al guardar la vista", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, view, h.logger)
+}
+
+// UpdateView reemplaza una vista del usuario
+func (h *SavedViewHandler) UpdateView(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var view domain.SavedView
+	if err := json.NewDecoder(r.Body).Decode(&view); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	// Aseguramos que el ID en el cuerpo coincida con el de la URL
+	view.ID = id
+
+	if err := h.viewService.UpdateView(r.Context(), &view); err != nil {
+		h.logger.Error("Failed to update saved view", "error", err, "id", id)
+		writeError(w, r, "Error al actualizar la vista guardada", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, view, h.logger)
+}
+
+// DeleteView elimina una vista del usuario
+func (h *SavedViewHandler) DeleteView(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.viewService.DeleteView(r.Context(), id); err != nil {
+		h.logger.Error("Failed to delete saved view", "error", err, "id", id)
+		writeError(w, r, "Error al eliminar la vista guardada", statusForError(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
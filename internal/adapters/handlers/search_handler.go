@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// Número de resultados de la búsqueda
+const (
+	defaultSearchLimit = 20
+	maxSearchResults   = 100
+)
+
+// SearchHandler maneja las peticiones HTTP de búsqueda
+type SearchHandler struct {
+	searchService ports.SearchService
+	logger        logger.Logger
+}
+
+// NewSearchHandler crea una nueva instancia del manejador de búsqueda
+func NewSearchHandler(searchService ports.SearchService, logger logger.Logger) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *SearchHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/search", h.Search).Methods(http.MethodGet)
+}
+
+// Search busca ?q= en tanques, sitios y notas. Devuelve los 20 resultados más relevantes, o
+// limit (hasta 100); a partir de la v2, limit y offset paginan los 100 primeros dentro del sobre.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+
+	limit := maxSearchResults
+	if !usesEnvelope(r) {
+		limit = defaultSearchLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				writeError(w, r, "El parámetro limit debe ser un entero positivo", http.StatusBadRequest)
+				return
+			}
+			limit = min(parsed, maxSearchResults)
+		}
+	}
+
+	results, err := h.searchService.Search(r.Context(), query, limit)
+	if err != nil {
+		h.logger.Error("Failed to search", "error", err, "query", query)
+		writeError(w, r, "Error al realizar la búsqueda", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, results, h.logger)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// defaultSecurityEventLimit es el número de eventos de seguridad devueltos si no se pide limit
+const defaultSecurityEventLimit = 100
+
+// SecurityEventHandler maneja la consulta del registro de auditoría de los accesos
+type SecurityEventHandler struct {
+	loginGuard ports.LoginGuard
+	logger     logger.Logger
+}
+
+// NewSecurityEventHandler crea una nueva instancia del manejador de eventos de seguridad
+func NewSecurityEventHandler(loginGuard ports.LoginGuard, logger logger.Logger) *SecurityEventHandler {
+	return &SecurityEventHandler{
+		loginGuard: loginGuard,
+		logger:     logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *SecurityEventHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/security-events", h.GetSecurityEvents).Methods(http.MethodGet)
+}
+
+// GetSecurityEvents devuelve los últimos intentos fallidos, bloqueos e inicios de sesión, del más
+// reciente al más antiguo
+func (h *SecurityEventHandler) GetSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	limit := defaultSecurityEventLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, r, "El parámetro limit debe ser un entero positivo", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := h.loginGuard.GetSecurityEvents(r.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to get security events", "error", err)
+		writeError(w, r, "Error al obtener los eventos de seguridad", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, events, h.logger)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// SensorHandler maneja las peticiones HTTP de la salud de los sensores
+type SensorHandler struct {
+	sensorHealthService ports.SensorHealthService
+	clockSkewService    ports.ClockSkewService
+	logger              logger.Logger
+}
+
+// NewSensorHandler crea una nueva instancia del manejador de sensores
+func NewSensorHandler(sensorHealthService ports.SensorHealthService, clockSkewService ports.ClockSkewService, logger logger.Logger) *SensorHandler {
+	return &SensorHandler{
+		sensorHealthService: sensorHealthService,
+		clockSkewService:    clockSkewService,
+		logger:              logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *SensorHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/sensors", h.GetSensors).Methods(http.MethodGet)
+	router.HandleFunc("/api/sensors/clocks", h.GetSensorClocks).Methods(http.MethodGet)
+}
+
+// GetSensors devuelve la salud de los sensores, opcionalmente filtrada con ?health=good|fair|poor|unknown
+func (h *SensorHandler) GetSensors(w http.ResponseWriter, r *http.Request) {
+	health := r.URL.Query().Get("health")
+
+	sensors, err := h.sensorHealthService.GetSensorHealth(r.Context(), health)
+	if err != nil {
+		h.logger.Error("Failed to get sensor health", "error", err, "health", health)
+		writeError(w, r, "Error al obtener la salud de los sensores", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, sensors, h.logger)
+}
+
+// GetSensorClocks devuelve el desfase del reloj de los sensores; con ?drifting=true solo los desajustados
+func (h *SensorHandler) GetSensorClocks(w http.ResponseWriter, r *http.Request) {
+	drifting := false
+	if value := r.URL.Query().Get("drifting"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, r, "Parámetro drifting inválido", http.StatusBadRequest)
+			return
+		}
+		drifting = parsed
+	}
+
+	clocks, err := h.clockSkewService.GetSensorClocks(r.Context(), drifting)
+	if err != nil {
+		h.logger.Error("Failed to get sensor clocks", "error", err)
+		writeError(w, r, "Error al obtener el reloj de los sensores", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, clocks, h.logger)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/adapters/auth"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// RefreshPath es la ruta pública para refrescar una sesión
+const RefreshPath = "/api/auth/refresh"
+
+// SessionHandler maneja el refresco y la revocación de las sesiones
+type SessionHandler struct {
+	sessionService ports.SessionService
+	protection     *auth.LoginProtection // nil sin protección frente a fuerza bruta
+	logger         logger.Logger
+}
+
+// NewSessionHandler crea una nueva instancia del manejador de sesiones. Con protection, los
+// tokens de refresco inválidos cuentan como intentos de autenticación fallidos.
+func NewSessionHandler(sessionService ports.SessionService, protection *auth.LoginProtection, logger logger.Logger) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+		protection:     protection,
+		logger:         logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *SessionHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc(RefreshPath, h.Refresh).Methods(http.MethodPost)
+	router.HandleFunc("/api/auth/logout-all", h.LogoutAll).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/sessions", h.GetSessions).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/sessions", h.RevokeSubjectSessions).Methods(http.MethodDelete)
+	router.HandleFunc("/api/admin/sessions/{id}", h.RevokeSession).Methods(http.MethodDelete)
+}
+
+// refreshRequest es el cuerpo de la petición de refresco
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// revokedSessions es la respuesta de las revocaciones de varias sesiones
+type revokedSessions struct {
+	Revoked int `json:"revoked"`
+}
+
+// Refresh canjea un token de refresco por un nuevo par de tokens; el token presentado deja de
+// ser válido
+func (h *SessionHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	attempt := domain.LoginAttempt{Method: domain.LoginMethodRefresh, Path: r.URL.Path}
+	if h.protection != nil {
+		attempt.ClientIP = h.protection.ClientIP(r)
+		if wait := h.protection.Guard.Check(r.Context(), attempt.ClientIP); wait > 0 {
+			h.recordFailure(r, attempt, "locked out")
+			auth.SetRetryAfter(w, wait)
+			writeError(w, r, "Demasiados intentos fallidos; inténtelo más tarde", http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	var request refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	tokens, err := h.sessionService.Refresh(r.Context(), request.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSessionToken) {
+			h.logger.Warn("Session refresh rejected", "error", err, "client_ip", attempt.ClientIP)
+			h.recordFailure(r, attempt, err.Error())
+		} else {
+			h.logger.Error("Failed to refresh session", "error", err)
+		}
+		writeError(w, r, "Token de refresco inválido o vencido", statusForError(err))
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, r, http.StatusOK, tokens, h.logger)
+}
+
+// LogoutAll revoca todas las sesiones activas del usuario autenticado
+func (h *SessionHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	principal := domain.PrincipalFromContext(r.Context())
+	if principal == nil {
+		writeError(w, r, "Autenticación requerida", http.StatusUnauthorized)
+		return
+	}
+
+	revoked, err := h.sessionService.RevokeSubjectSessions(r.Context(), principal.Subject, domain.SessionRevokedLogout)
+	if err != nil {
+		h.logger.Error("Failed to revoke sessions", "error", err, "subject", principal.Subject)
+		writeError(w, r, "Error al cerrar las sesiones", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Sessions revoked by user", "subject", principal.Subject, "revoked", revoked)
+	writeJSON(w, r, http.StatusOK, revokedSessions{Revoked: revoked}, h.logger)
+}
+
+// GetSessions devuelve las sesiones, de la más reciente a la más antigua, filtradas por usuario
+// (subject) y por estado (active)
+func (h *SessionHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	filter := domain.SessionFilter{Subject: r.URL.Query().Get("subject")}
+	if value := r.URL.Query().Get("active"); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, r, "El parámetro active debe ser true o false", http.StatusBadRequest)
+			return
+		}
+		filter.ActiveOnly = active
+	}
+
+	sessions, err := h.sessionService.GetSessions(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get sessions", "error", err)
+		writeError(w, r, "Error al obtener las sesiones", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, sessions, h.logger)
+}
+
+// RevokeSession expulsa una sesión: sus tokens de acceso y de refresco dejan de aceptarse
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	if err := h.sessionService.RevokeSession(r.Context(), id); err != nil {
+		h.logger.Error("Failed to revoke session", "error", err, "id", id)
+		writeError(w, r, "Error al revocar la sesión", statusForError(err))
+		return
+	}
+
+	h.logger.Warn("Session revoked", "id", id, "by", domain.SubjectOf(domain.PrincipalFromContext(r.Context())))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeSubjectSessions expulsa todas las sesiones activas del usuario indicado en subject
+func (h *SessionHandler) RevokeSubjectSessions(w http.ResponseWriter, r *http.Request) {
+	subject := r.URL.Query().Get("subject")
+
+	revoked, err := h.sessionService.RevokeSubjectSessions(r.Context(), subject, domain.SessionRevokedByAdmin)
+	if err != nil {
+		h.logger.Error("Failed to revoke sessions", "error", err, "subject", subject)
+		writeError(w, r, "Error al revocar las sesiones", statusForError(err))
+		return
+	}
+
+	h.logger.Warn("Sessions revoked", "subject", subject, "revoked", revoked, "by", domain.SubjectOf(domain.PrincipalFromContext(r.Context())))
+	writeJSON(w, r, http.StatusOK, revokedSessions{Revoked: revoked}, h.logger)
+}
+
+// recordFailure cuenta un intento fallido del cliente
+func (h *SessionHandler) recordFailure(r *http.Request, attempt domain.LoginAttempt, reason string) {
+	if h.protection == nil {
+		return
+	}
+	attempt.Reason = reason
+	if err := h.protection.Guard.RecordFailure(r.Context(), attempt); err != nil {
+		h.logger.Error("Failed to record login failure", "error", err, "client_ip", attempt.ClientIP)
+	}
+}
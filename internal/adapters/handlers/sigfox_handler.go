@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/adapters/sigfox"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// Resultado de cada callback de Sigfox
+const (
+	sigfoxFrameAccepted  = "accepted"
+	sigfoxFrameDuplicate = "duplicate"
+)
+
+// SigfoxCallbackResult es la respuesta a un callback de Sigfox
+type SigfoxCallbackResult struct {
+	Device        string `json:"device"`
+	SeqNumber     int    `json:"seq_number"`
+	Status        string `json:"status"` // accepted o duplicate
+	TankID        string `json:"tank_id"`
+	MeasurementID string `json:"measurement_id,omitempty"`
+}
+
+// SigfoxHandler recibe los callbacks de datos del backend de Sigfox y guarda cada trama como una
+// medición del tanque asociado al equipo
+type SigfoxHandler struct {
+	tankService  ports.TankService
+	config       *sigfox.Config
+	deduplicator *sigfox.Deduplicator
+	logger       logger.Logger
+}
+
+// NewSigfoxHandler crea una nueva instancia del manejador de callbacks de Sigfox
+func NewSigfoxHandler(tankService ports.TankService, config *sigfox.Config, deduplicator *sigfox.Deduplicator, logger logger.Logger) *SigfoxHandler {
+	return &SigfoxHandler{
+		tankService:  tankService,
+		config:       config,
+		deduplicator: deduplicator,
+		logger:       logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *SigfoxHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/ingest/sigfox", h.Receive).Methods(http.MethodPost)
+}
+
+// Receive guarda la trama de un callback de Sigfox. El callback se autentica con el token de la
+// configuración, en la cabecera X-Webhook-Token o como Authorization: Bearer. Las tramas
+// repetidas (marcadas como duplicate o ya recibidas por otra estación base) se confirman sin
+// guardarse de nuevo.
+func (h *SigfoxHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	if !validWebhookToken(r, h.config.Token) {
+		writeError(w, r, "Token de webhook inválido", http.StatusUnauthorized)
+		return
+	}
+
+	var callback sigfox.Callback
+	if err := json.NewDecoder(r.Body).Decode(&callback); err != nil {
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	deviceID := callback.DeviceID()
+	device, ok := h.config.Devices[deviceID]
+	if !ok {
+		h.logger.Warn("Sigfox callback from unknown device", "device", deviceID)
+		writeError(w, r, "Equipo Sigfox desconocido", http.StatusNotFound)
+		return
+	}
+
+	result := &SigfoxCallbackResult{Device: deviceID, SeqNumber: callback.SeqNumber, Status: sigfoxFrameDuplicate, TankID: device.TankID}
+	if callback.Duplicate || !h.deduplicator.Claim(deviceID, callback.SeqNumber, time.Now()) {
+		h.logger.Debug("Duplicate Sigfox frame", "device", deviceID, "seq_number", callback.SeqNumber)
+		writeJSON(w, r, http.StatusOK, result, h.logger)
+		return
+	}
+
+	measurement, err := device.Measurement(&callback)
+	if err != nil {
+		h.deduplicator.Release(deviceID, callback.SeqNumber)
+		h.logger.Warn("Invalid Sigfox frame", "device", deviceID, "data", callback.Data, "error", err)
+		writeError(w, r, "Trama de Sigfox inválida", http.StatusBadRequest)
+		return
+	}
+	measurement.ID = uuid.New().String()
+	if measurement.Timestamp.IsZero() {
+		measurement.Timestamp = time.Now()
+	}
+
+	if err := h.tankService.AddMeasurement(r.Context(), measurement); err != nil && !services.IsStaleMeasurement(err) {
+		h.deduplicator.Release(deviceID, callback.SeqNumber)
+		h.logger.Error("Failed to save Sigfox measurement", "device", deviceID, "tank_id", device.TankID, "error", err)
+		writeError(w, r, "Error al guardar la lectura de Sigfox", statusForError(err))
+		return
+	}
+
+	result.Status = sigfoxFrameAccepted
+	result.MeasurementID = measurement.ID
+	writeJSON(w, r, http.StatusOK, result, h.logger)
+}
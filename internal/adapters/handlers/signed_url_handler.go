@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// SignedURLHandler maneja las peticiones HTTP de los enlaces de descarga firmados
+type SignedURLHandler struct {
+	signedURLService ports.SignedURLService
+	logger           logger.Logger
+}
+
+// NewSignedURLHandler crea una nueva instancia del manejador de enlaces firmados
+func NewSignedURLHandler(signedURLService ports.SignedURLService, logger logger.Logger) *SignedURLHandler {
+	return &SignedURLHandler{
+		signedURLService: signedURLService,
+		logger:           logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *SignedURLHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/signed-urls", h.SignURL).Methods(http.MethodPost)
+}
+
+// signURLRequest es el cuerpo de la petición de un enlace firmado
+type signURLRequest struct {
+	Path string `json:"path"` // Ruta de la exportación con sus parámetros
+	TTL  string `json:"ttl"`  // Vigencia opcional, p. ej. 2h
+}
+
+// SignURL genera un enlace temporal a una exportación que se descarga sin credenciales
+func (h *SignedURLHandler) SignURL(w http.ResponseWriter, r *http.Request) {
+	var request signURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	var ttl time.Duration
+	if request.TTL != "" {
+		parsed, err := time.ParseDuration(request.TTL)
+		if err != nil {
+			writeError(w, r, "Parámetro ttl inválido", http.StatusBadRequest)
+			return
+		}
+		ttl = parsed
+	}
+
+	signed, err := h.signedURLService.SignURL(r.Context(), request.Path, ttl)
+	if err != nil {
+		h.logger.Error("Failed to sign URL", "error", err, "path", request.Path)
+		writeError(w, r, "Error al firmar el enlace", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Signed URL created", "path", request.Path, "expires_at", signed.ExpiresAt)
+	writeJSON(w, r, http.StatusCreated, signed, h.logger)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// Valores por defecto de las series sintéticas
+const (
+	defaultSimulationDuration = time.Hour
+	defaultSimulationInterval = time.Minute
+)
+
+// SimulationHandler maneja las peticiones HTTP de las series sintéticas. Solo se registra en los
+// entornos de pruebas: en producción falsearía los datos de los tanques.
+type SimulationHandler struct {
+	simulationService ports.SimulationService
+	logger            logger.Logger
+}
+
+// NewSimulationHandler crea una nueva instancia del manejador de series sintéticas
+func NewSimulationHandler(simulationService ports.SimulationService, logger logger.Logger) *SimulationHandler {
+	return &SimulationHandler{
+		simulationService: simulationService,
+		logger:            logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *SimulationHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/tanks/{id}/simulate", h.SimulateTank).Methods(http.MethodPost)
+}
+
+// SimulateTank inyecta en el tanque una serie sintética del perfil indicado (consumption, refill
+// o leak; por defecto consumption) que abarca duration (por defecto 1h) hasta ahora, con una
+// lectura cada interval (por defecto 1m)
+func (h *SimulationHandler) SimulateTank(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	query := r.URL.Query()
+
+	profile := query.Get("profile")
+	if profile == "" {
+		profile = domain.SyntheticProfileConsumption
+	}
+
+	duration := defaultSimulationDuration
+	if value := query.Get("duration"); value != "" {
+		parsed, err := parseSpan(value)
+		if err != nil {
+			writeError(w, r, "Parámetro duration inválido", http.StatusBadRequest)
+			return
+		}
+		duration = parsed
+	}
+
+	interval := defaultSimulationInterval
+	if value := query.Get("interval"); value != "" {
+		parsed, err := parseSpan(value)
+		if err != nil {
+			writeError(w, r, "Parámetro interval inválido", http.StatusBadRequest)
+			return
+		}
+		interval = parsed
+	}
+
+	series, err := h.simulationService.SimulateTank(r.Context(), id, profile, duration, interval)
+	if err != nil {
+		h.logger.Error("Failed to simulate tank", "error", err, "tank_id", id, "profile", profile)
+		writeError(w, r, "Error al generar la serie sintética", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Synthetic series injected", "tank_id", id, "profile", profile, "measurements", series.Measurements)
+	writeJSON(w, r, http.StatusCreated, series, h.logger)
+}
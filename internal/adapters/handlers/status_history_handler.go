@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// StatusHistoryHandler maneja las peticiones HTTP del historial de estados de los tanques
+type StatusHistoryHandler struct {
+	statusHistoryService ports.StatusHistoryService
+	logger               logger.Logger
+}
+
+// NewStatusHistoryHandler crea una nueva instancia del manejador de historial de estados
+func NewStatusHistoryHandler(statusHistoryService ports.StatusHistoryService, logger logger.Logger) *StatusHistoryHandler {
+	return &StatusHistoryHandler{
+		statusHistoryService: statusHistoryService,
+		logger:               logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *StatusHistoryHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/tanks/{id}/status-history", h.GetStatusHistory).Methods(http.MethodGet)
+}
+
+// GetStatusHistory devuelve las transiciones de estado de un tanque en el periodo solicitado
+func (h *StatusHistoryHandler) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	// Por defecto devolvemos los últimos 30 días
+	from, to, err := parsePeriod(r, 30*24*time.Hour)
+	if err != nil {
+		writeError(w, r, "Parámetros from/to inválidos", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.statusHistoryService.GetStatusHistory(ctx, id, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get status history", "error", err, "id", id)
+		writeError(w, r, "Error al obtener el historial de estados", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, history, h.logger)
+}
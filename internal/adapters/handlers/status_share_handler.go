@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/i18n"
+	"monitor-tanques/pkg/logger"
+)
+
+// PublicStatusPrefix es el prefijo de las páginas de estado públicas, que no requieren autenticación
+const PublicStatusPrefix = "/public/"
+
+// publicStatusRefresh es cada cuánto se recarga la versión HTML de la página pública
+const publicStatusRefresh = 60
+
+// StatusShareHandler maneja las peticiones HTTP de las páginas de estado públicas
+type StatusShareHandler struct {
+	shareService ports.StatusShareService
+	logger       logger.Logger
+}
+
+// NewStatusShareHandler crea una nueva instancia del manejador de páginas de estado públicas
+func NewStatusShareHandler(shareService ports.StatusShareService, logger logger.Logger) *StatusShareHandler {
+	return &StatusShareHandler{
+		shareService: shareService,
+		logger:       logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *StatusShareHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/status-shares", h.GetShares).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/status-shares", h.CreateShare).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/status-shares/{id}", h.RevokeShare).Methods(http.MethodDelete)
+	router.HandleFunc(PublicStatusPrefix+"{token}", h.GetPublicStatus).Methods(http.MethodGet)
+}
+
+// createStatusShareRequest es el cuerpo de la petición de creación de una página pública
+type createStatusShareRequest struct {
+	Name      string     `json:"name"`
+	TankIDs   []string   `json:"tank_ids"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreateShare crea una página pública; la respuesta incluye el token, que no vuelve a mostrarse
+func (h *StatusShareHandler) CreateShare(w http.ResponseWriter, r *http.Request) {
+	var request createStatusShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	share, err := h.shareService.CreateShare(r.Context(), &domain.StatusShare{
+		Name:      request.Name,
+		TankIDs:   request.TankIDs,
+		ExpiresAt: request.ExpiresAt,
+	})
+	if err != nil {
+		h.logger.Error("Failed to create status share", "error", err)
+		writeError(w, r, "Error al crear la página de estado", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Status share created", "id", share.ID, "tanks", len(share.TankIDs), "by", share.CreatedBy)
+	writeJSON(w, r, http.StatusCreated, share, h.logger)
+}
+
+// GetShares devuelve todas las páginas públicas, sin sus tokens
+func (h *StatusShareHandler) GetShares(w http.ResponseWriter, r *http.Request) {
+	shares, err := h.shareService.GetShares(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get status shares", "error", err)
+		writeError(w, r, "Error al obtener las páginas de estado", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, shares, h.logger)
+}
+
+// RevokeShare desactiva una página pública
+func (h *StatusShareHandler) RevokeShare(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	share, err := h.shareService.RevokeShare(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to revoke status share", "error", err, "id", id)
+		writeError(w, r, "Error al revocar la página de estado", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Status share revoked", "id", id)
+	writeJSON(w, r, http.StatusOK, share, h.logger)
+}
+
+// GetPublicStatus muestra el nivel de los tanques de la página del token, en HTML para los
+// navegadores (p. ej. dentro de un iframe de la intranet del cliente) o en JSON. Cualquier origen
+// puede consultarla, y el token no se envía como Referer a los enlaces de la página.
+func (h *StatusShareHandler) GetPublicStatus(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Set("Access-Control-Allow-Origin", "*")
+	header.Set("Referrer-Policy", "no-referrer")
+	header.Set("Cache-Control", "no-store")
+
+	page, err := h.shareService.GetPublicStatus(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		status := statusForError(err)
+		if status != http.StatusNotFound {
+			h.logger.Error("Failed to get public status", "error", err)
+		}
+		writeError(w, r, "Página de estado no encontrada", status)
+		return
+	}
+
+	if !wantsHTML(r) {
+		writeJSON(w, r, http.StatusOK, page, h.logger)
+		return
+	}
+
+	language := i18n.FromContext(r.Context())
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	header.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	w.WriteHeader(http.StatusOK)
+	err = publicStatusTemplate.Execute(w, map[string]interface{}{
+		"Language":    language,
+		"Refresh":     publicStatusRefresh,
+		"Page":        page,
+		"UpdatedText": i18n.Translate(language, "Actualizado"),
+		"NoDataText":  i18n.Translate(language, "Sin lecturas"),
+	})
+	if err != nil {
+		h.logger.Error("Failed to render public status", "error", err)
+	}
+}
+
+// wantsHTML indica si el cliente es un navegador que prefiere HTML
+func wantsHTML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// publicStatusTemplate es la página HTML autónoma, sin scripts ni recursos externos, con un
+// indicador de llenado por tanque
+var publicStatusTemplate = template.Must(template.New("public-status").Funcs(template.FuncMap{
+	"width": func(value float64) float64 {
+		return min(max(value, 0), 100)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="{{.Refresh}}">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Page.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 1rem; color: #222; }
+.tank { margin-bottom: 1rem; }
+.gauge { background: #eee; border-radius: 4px; height: 1.25rem; overflow: hidden; }
+.fill { height: 100%; background: #2e7d32; }
+.warning .fill { background: #f9a825; }
+.critical .fill { background: #c62828; }
+.meta { font-size: 0.85rem; color: #666; }
+</style>
+</head>
+<body>
+<h1>{{.Page.Name}}</h1>
+{{range .Page.Tanks}}<div class="tank {{.Status}}">
+<strong>{{.Name}}</strong> · {{.LiquidType}} · {{printf "%.1f" .Percentage}}%
+<div class="gauge"><div class="fill" style="width: {{width .Percentage}}%"></div></div>
+<div class="meta">{{printf "%.0f" .Level}} / {{printf "%.0f" .Capacity}} L · {{if .LastUpdated.IsZero}}{{$.NoDataText}}{{else}}{{$.UpdatedText}} {{.LastUpdated.UTC.Format "2006-01-02 15:04"}} UTC{{end}}</div>
+</div>
+{{end}}</body>
+</html>
+`))
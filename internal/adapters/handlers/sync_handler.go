@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// SyncHandler expone en la instancia central la API de sincronización con las pasarelas edge
+type SyncHandler struct {
+	syncService ports.TankSyncService
+	logger      logger.Logger
+}
+
+// NewSyncHandler crea una nueva instancia del manejador de la API de sincronización
+func NewSyncHandler(syncService ports.TankSyncService, logger logger.Logger) *SyncHandler {
+	return &SyncHandler{
+		syncService: syncService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *SyncHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/sync/push", h.Push).Methods(http.MethodPost)
+	router.HandleFunc("/api/sync/pull", h.Pull).Methods(http.MethodGet)
+}
+
+// Push aplica un lote de una pasarela. Las mediciones y los cambios inválidos se rechazan sin
+// impedir el resto; la pasarela da por enviado todo lo de un lote respondido con 200.
+func (h *SyncHandler) Push(w http.ResponseWriter, r *http.Request) {
+	var batch domain.SyncBatch
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		h.logger.Error("Failed to decode sync batch", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	result, err := h.syncService.Push(r.Context(), &batch)
+	if err != nil {
+		h.logger.Error("Failed to apply sync batch", "gateway_id", batch.GatewayID, "batch_id", batch.BatchID, "error", err)
+		writeError(w, r, "Error al guardar el lote de sincronización", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Sync batch received", "gateway_id", result.GatewayID, "batch_id", result.BatchID,
+		"accepted", result.Accepted, "rejected", result.Rejected, "tanks", len(result.Tanks))
+	writeJSON(w, r, http.StatusOK, result, h.logger)
+}
+
+// Pull devuelve los cambios de configuración de los tanques posteriores a cursor
+func (h *SyncHandler) Pull(w http.ResponseWriter, r *http.Request) {
+	var cursor int64
+	if value := r.URL.Query().Get("cursor"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			writeError(w, r, "Cursor de sincronización inválido", http.StatusBadRequest)
+			return
+		}
+		cursor = parsed
+	}
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, r, "El parámetro limit debe ser un entero positivo", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	result, err := h.syncService.Pull(r.Context(), cursor, limit)
+	if err != nil {
+		h.logger.Error("Failed to get sync changes", "cursor", cursor, "error", err)
+		writeError(w, r, "Error al obtener los cambios de sincronización", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, result, h.logger)
+}
+
+// EdgeSyncHandler expone en una pasarela edge el estado de la sincronización con la instancia central
+type EdgeSyncHandler struct {
+	syncService ports.EdgeSyncService
+	logger      logger.Logger
+}
+
+// NewEdgeSyncHandler crea una nueva instancia del manejador del estado del reenvío
+func NewEdgeSyncHandler(syncService ports.EdgeSyncService, logger logger.Logger) *EdgeSyncHandler {
+	return &EdgeSyncHandler{
+		syncService: syncService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *EdgeSyncHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/edge/status", h.GetStatus).Methods(http.MethodGet)
+	router.HandleFunc("/api/edge/sync", h.Sync).Methods(http.MethodPost)
+}
+
+// GetStatus devuelve las mediciones pendientes y el resultado de la última sincronización
+func (h *EdgeSyncHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.syncService.GetStatus(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get edge sync status", "error", err)
+		writeError(w, r, "Error al obtener el estado de la sincronización", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, status, h.logger)
+}
+
+// Sync sincroniza ahora con la instancia central, sin esperar a la siguiente sincronización
+// programada, y devuelve el estado resultante
+func (h *EdgeSyncHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	if err := h.syncService.Forward(r.Context()); err != nil {
+		// Los rechazos y los cortes quedan en el estado; no impiden responder con él
+		h.logger.Warn("Edge sync incomplete", "error", err)
+	}
+
+	h.GetStatus(w, r)
+}
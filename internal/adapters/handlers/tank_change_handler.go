@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// TankChangeHandler maneja las peticiones HTTP de los cambios de tanques pendientes de aprobación
+type TankChangeHandler struct {
+	changeService ports.TankChangeService
+	logger        logger.Logger
+}
+
+// NewTankChangeHandler crea una nueva instancia del manejador de cambios pendientes
+func NewTankChangeHandler(changeService ports.TankChangeService, logger logger.Logger) *TankChangeHandler {
+	return &TankChangeHandler{
+		changeService: changeService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router. La aprobación y el rechazo van
+// bajo /api/admin para exigir el rol admin.
+func (h *TankChangeHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/pending-changes", h.GetTankChanges).Methods(http.MethodGet)
+	router.HandleFunc("/api/pending-changes/{id}", h.GetTankChange).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/pending-changes/{id}/approve", h.ApproveTankChange).Methods(http.MethodPost)
+	router.HandleFunc("/api/admin/pending-changes/{id}/reject", h.RejectTankChange).Methods(http.MethodPost)
+}
+
+// reviewTankChangeRequest es el cuerpo opcional de la aprobación o el rechazo
+type reviewTankChangeRequest struct {
+	Comment string `json:"comment"`
+}
+
+// GetTankChanges lista las solicitudes filtradas por status (pending por defecto; all las
+// devuelve todas) y tank_id
+func (h *TankChangeHandler) GetTankChanges(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.TankChangeFilter{Status: query.Get("status"), TankID: query.Get("tank_id")}
+	switch filter.Status {
+	case "":
+		filter.Status = domain.TankChangePending
+	case "all":
+		filter.Status = ""
+	}
+
+	changes, err := h.changeService.GetTankChanges(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to get tank changes", "error", err)
+		writeError(w, r, "Error al obtener los cambios pendientes", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, changes, h.logger)
+}
+
+// GetTankChange devuelve una solicitud de cambio
+func (h *TankChangeHandler) GetTankChange(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	change, err := h.changeService.GetTankChange(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get tank change", "error", err, "id", id)
+		writeError(w, r, "Error al obtener la solicitud de cambio", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, change, h.logger)
+}
+
+// ApproveTankChange aplica una solicitud pendiente al tanque
+func (h *TankChangeHandler) ApproveTankChange(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	request, ok := h.decodeReview(w, r)
+	if !ok {
+		return
+	}
+
+	change, err := h.changeService.ApproveTankChange(r.Context(), id, request.Comment)
+	if err != nil {
+		h.logger.Error("Failed to approve tank change", "error", err, "id", id)
+		writeError(w, r, "Error al aprobar la solicitud de cambio", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Tank change approved", "id", id, "tank", change.TankID, "by", change.ReviewedBy)
+	writeJSON(w, r, http.StatusOK, change, h.logger)
+}
+
+// RejectTankChange descarta una solicitud pendiente; el comentario es el motivo del rechazo
+func (h *TankChangeHandler) RejectTankChange(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	request, ok := h.decodeReview(w, r)
+	if !ok {
+		return
+	}
+
+	change, err := h.changeService.RejectTankChange(r.Context(), id, request.Comment)
+	if err != nil {
+		h.logger.Error("Failed to reject tank change", "error", err, "id", id)
+		writeError(w, r, "Error al rechazar la solicitud de cambio", statusForError(err))
+		return
+	}
+
+	h.logger.Info("Tank change rejected", "id", id, "tank", change.TankID, "by", change.ReviewedBy)
+	writeJSON(w, r, http.StatusOK, change, h.logger)
+}
+
+// decodeReview lee el cuerpo opcional de la revisión y responde con el error si no es válido
+func (h *TankChangeHandler) decodeReview(w http.ResponseWriter, r *http.Request) (reviewTankChangeRequest, bool) {
+	var request reviewTankChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return request, false
+	}
+	return request, true
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"time"
 
@@ -10,6 +12,8 @@ import (
 
 	"monitor-tanques/internal/core/domain"
 	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/i18n"
 	"monitor-tanques/pkg/logger"
 )
 
@@ -30,27 +34,70 @@ func NewTankHandler(tankService ports.TankService, logger logger.Logger) *TankHa
 // RegisterRoutes registra las rutas del manejador en el router
 func (h *TankHandler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/api/tanks", h.GetAllTanks).Methods(http.MethodGet)
+	// Debe registrarse antes de /api/tanks/{id} para que "geojson" no se tome como un ID
+	router.HandleFunc("/api/tanks/geojson", h.GetTanksGeoJSON).Methods(http.MethodGet)
 	router.HandleFunc("/api/tanks/{id}", h.GetTank).Methods(http.MethodGet)
 	router.HandleFunc("/api/tanks", h.CreateTank).Methods(http.MethodPost)
 	router.HandleFunc("/api/tanks/{id}", h.UpdateTank).Methods(http.MethodPut)
 	router.HandleFunc("/api/tanks/{id}", h.DeleteTank).Methods(http.MethodDelete)
 	router.HandleFunc("/api/tanks/{id}/measurements", h.AddMeasurement).Methods(http.MethodPost)
+	router.HandleFunc("/api/tanks/{id}/measurements/backfill", h.BackfillMeasurements).Methods(http.MethodPost)
+	router.HandleFunc("/api/tanks/{id}/clone", h.CloneTank).Methods(http.MethodPost)
 }
 
-// GetAllTanks devuelve todos los tanques
+// GetAllTanks devuelve todos los tanques, opcionalmente filtrados por el selector de etiquetas labels
 func (h *TankHandler) GetAllTanks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	selector, err := parseLabelSelector(r)
+	if err != nil {
+		writeError(w, r, "Parámetro labels inválido", http.StatusBadRequest)
+		return
+	}
+
 	tanks, err := h.tankService.GetAllTanks(ctx)
 	if err != nil {
 		h.logger.Error("Failed to get tanks", "error", err)
-		http.Error(w, "Error al obtener los tanques", http.StatusInternalServerError)
+		writeError(w, r, "Error al obtener los tanques", statusForError(err))
+		return
+	}
+	tanks = domain.FilterTanksByLabels(tanks, selector)
+
+	// Los tableros consultan la lista con frecuencia; si nada cambió responden 304 sin cuerpo
+	lastModified := time.Time{}
+	for _, tank := range tanks {
+		if tank.LastUpdated.After(lastModified) {
+			lastModified = tank.LastUpdated
+		}
+	}
+
+	writeConditionalJSON(w, r, tanks, lastModified, h.logger)
+}
+
+// GetTanksGeoJSON devuelve los tanques con ubicación como FeatureCollection GeoJSON para mapas,
+// opcionalmente limitados al rectángulo bbox=minLon,minLat,maxLon,maxLat y al selector labels
+func (h *TankHandler) GetTanksGeoJSON(w http.ResponseWriter, r *http.Request) {
+	bbox, err := parseBoundingBox(r)
+	if err != nil {
+		writeError(w, r, "Parámetro bbox inválido, use minLon,minLat,maxLon,maxLat", http.StatusBadRequest)
+		return
+	}
+	selector, err := parseLabelSelector(r)
+	if err != nil {
+		writeError(w, r, "Parámetro labels inválido", http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(tanks); err != nil {
-		h.logger.Error("Failed to encode tanks", "error", err)
-		http.Error(w, "Error al codificar la respuesta", http.StatusInternalServerError)
+	tanks, err := h.tankService.GetAllTanks(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to get tanks", "error", err)
+		writeError(w, r, "Error al obtener los tanques", statusForError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	if err := json.NewEncoder(w).Encode(domain.BuildTankFeatureCollection(domain.FilterTanksByLabels(tanks, selector), bbox)); err != nil {
+		h.logger.Error("Failed to encode GeoJSON", "error", err)
+		writeError(w, r, "Error al codificar la respuesta", http.StatusInternalServerError)
 		return
 	}
 }
@@ -64,21 +111,16 @@ func (h *TankHandler) GetTank(w http.ResponseWriter, r *http.Request) {
 	tank, err := h.tankService.GetTank(ctx, id)
 	if err != nil {
 		h.logger.Error("Failed to get tank", "error", err, "id", id)
-		http.Error(w, "Error al obtener el tanque", http.StatusInternalServerError)
+		writeError(w, r, "Error al obtener el tanque", statusForError(err))
 		return
 	}
 
 	if tank == nil {
-		http.Error(w, "Tanque no encontrado", http.StatusNotFound)
+		writeError(w, r, "Tanque no encontrado", http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(tank); err != nil {
-		h.logger.Error("Failed to encode tank", "error", err)
-		http.Error(w, "Error al codificar la respuesta", http.StatusInternalServerError)
-		return
-	}
+	writeConditionalJSON(w, r, tank, tank.LastUpdated, h.logger)
 }
 
 // CreateTank crea un nuevo tanque
@@ -88,7 +130,7 @@ func (h *TankHandler) CreateTank(w http.ResponseWriter, r *http.Request) {
 
 	if err := json.NewDecoder(r.Body).Decode(&tank); err != nil {
 		h.logger.Error("Failed to decode request body", "error", err)
-		http.Error(w, "Error al decodificar la solicitud", http.StatusBadRequest)
+		writeDecodeError(w, r, err)
 		return
 	}
 
@@ -99,17 +141,11 @@ func (h *TankHandler) CreateTank(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.tankService.CreateTank(ctx, &tank); err != nil {
 		h.logger.Error("Failed to create tank", "error", err)
-		http.Error(w, "Error al crear el tanque", http.StatusInternalServerError)
+		writeError(w, r, "Error al crear el tanque", statusForError(err))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(tank); err != nil {
-		h.logger.Error("Failed to encode response", "error", err)
-		http.Error(w, "Error al codificar la respuesta", http.StatusInternalServerError)
-		return
-	}
+	writeJSON(w, r, http.StatusCreated, tank, h.logger)
 }
 
 // UpdateTank actualiza un tanque existente
@@ -121,25 +157,29 @@ func (h *TankHandler) UpdateTank(w http.ResponseWriter, r *http.Request) {
 	var tank domain.Tank
 	if err := json.NewDecoder(r.Body).Decode(&tank); err != nil {
 		h.logger.Error("Failed to decode request body", "error", err)
-		http.Error(w, "Error al decodificar la solicitud", http.StatusBadRequest)
+		writeDecodeError(w, r, err)
 		return
 	}
 
 	// Aseguramos que el ID en el cuerpo coincida con el de la URL
 	tank.ID = id
 
-	if err := h.tankService.UpdateTank(ctx, &tank); err != nil {
-		h.logger.Error("Failed to update tank", "error", err, "id", id)
-		http.Error(w, "Error al actualizar el tanque", http.StatusInternalServerError)
+	err := h.tankService.UpdateTank(ctx, &tank)
+	var pending *services.PendingApprovalError
+	if errors.As(err, &pending) {
+		// El resto de la actualización se aplicó; el umbral y la capacidad esperan aprobación
+		h.logger.Info("Tank change pending approval", "id", id, "change", pending.Change.ID)
+		w.Header().Set("Location", "/api/pending-changes/"+pending.Change.ID)
+		writeJSON(w, r, http.StatusAccepted, pending.Change, h.logger)
 		return
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(tank); err != nil {
-		h.logger.Error("Failed to encode response", "error", err)
-		http.Error(w, "Error al codificar la respuesta", http.StatusInternalServerError)
+	if err != nil {
+		h.logger.Error("Failed to update tank", "error", err, "id", id)
+		writeError(w, r, "Error al actualizar el tanque", statusForError(err))
 		return
 	}
+
+	writeJSON(w, r, http.StatusOK, tank, h.logger)
 }
 
 // DeleteTank elimina un tanque
@@ -150,7 +190,7 @@ func (h *TankHandler) DeleteTank(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.tankService.DeleteTank(ctx, id); err != nil {
 		h.logger.Error("Failed to delete tank", "error", err, "id", id)
-		http.Error(w, "Error al eliminar el tanque", http.StatusInternalServerError)
+		writeError(w, r, "Error al eliminar el tanque", statusForError(err))
 		return
 	}
 
@@ -166,7 +206,7 @@ func (h *TankHandler) AddMeasurement(w http.ResponseWriter, r *http.Request) {
 	var measurement domain.Measurement
 	if err := json.NewDecoder(r.Body).Decode(&measurement); err != nil {
 		h.logger.Error("Failed to decode request body", "error", err)
-		http.Error(w, "Error al decodificar la solicitud", http.StatusBadRequest)
+		writeDecodeError(w, r, err)
 		return
 	}
 
@@ -183,17 +223,92 @@ func (h *TankHandler) AddMeasurement(w http.ResponseWriter, r *http.Request) {
 		measurement.Timestamp = time.Now()
 	}
 
-	if err := h.tankService.AddMeasurement(ctx, &measurement); err != nil {
+	// Una lectura repetida o atrasada se acepta: los reintentos del equipo no son errores
+	if err := h.tankService.AddMeasurement(ctx, &measurement); err != nil && !services.IsStaleMeasurement(err) {
 		h.logger.Error("Failed to add measurement", "error", err, "tankID", tankID)
-		http.Error(w, "Error al añadir la medición", http.StatusInternalServerError)
+		writeError(w, r, "Error al añadir la medición", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, measurement, h.logger)
+}
+
+// backfillRequest es el cuerpo de la importación de mediciones históricas
+type backfillRequest struct {
+	Measurements []*domain.Measurement `json:"measurements"`
+}
+
+// BackfillMeasurements importa mediciones históricas del tanque sin generar alertas
+func (h *TankHandler) BackfillMeasurements(w http.ResponseWriter, r *http.Request) {
+	tankID := mux.Vars(r)["id"]
+
+	var request backfillRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(measurement); err != nil {
-		h.logger.Error("Failed to encode response", "error", err)
-		http.Error(w, "Error al codificar la respuesta", http.StatusInternalServerError)
+	for _, measurement := range request.Measurements {
+		if measurement != nil && measurement.ID == "" {
+			measurement.ID = uuid.New().String()
+		}
+	}
+
+	result, err := h.tankService.BackfillMeasurements(r.Context(), tankID, request.Measurements)
+	if err != nil {
+		h.logger.Error("Failed to backfill measurements", "error", err, "tankID", tankID)
+		writeError(w, r, "Error al importar el historial de mediciones", statusForError(err))
 		return
 	}
+
+	h.logger.Info("Measurements backfilled", "tankID", tankID, "imported", result.Imported, "duplicates", result.Duplicates)
+	writeJSON(w, r, http.StatusOK, result, h.logger)
+}
+
+// cloneTankRequest es el cuerpo opcional de POST /api/tanks/{id}/clone
+type cloneTankRequest struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// CloneTank crea un tanque con la configuración de otro, sin sus mediciones. El cuerpo puede
+// indicar el ID y el nombre del tanque nuevo; por defecto se genera el ID y el nombre es el del
+// original seguido de "(copia)".
+func (h *TankHandler) CloneTank(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+
+	var request cloneTankRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	source, err := h.tankService.GetTank(ctx, id)
+	if err != nil {
+		h.logger.Error("Failed to get tank to clone", "error", err, "id", id)
+		writeError(w, r, "Error al obtener el tanque", statusForError(err))
+		return
+	}
+
+	if request.ID == "" {
+		request.ID = uuid.New().String()
+	} else if _, err := h.tankService.GetTank(ctx, request.ID); err == nil {
+		writeError(w, r, "Ya existe un tanque con ese ID", http.StatusConflict)
+		return
+	}
+	if request.Name == "" {
+		request.Name = source.Name + " (" + i18n.Translate(i18n.FromContext(ctx), "copia") + ")"
+	}
+
+	clone := source.Clone(request.ID, request.Name)
+	if err := h.tankService.CreateTank(ctx, clone); err != nil {
+		h.logger.Error("Failed to create cloned tank", "error", err, "source", id)
+		writeError(w, r, "Error al crear el tanque", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, clone, h.logger)
 }
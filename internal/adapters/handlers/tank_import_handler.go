@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/adapters/tankimport"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/i18n"
+	"monitor-tanques/pkg/logger"
+)
+
+// maxTankImportFileSize limita el tamaño de la hoja de cálculo recibida
+const maxTankImportFileSize = 5 << 20
+
+// errMissingImportFile indica que el formulario no incluye el campo file
+var errMissingImportFile = errors.New("missing file field")
+
+// TankImportHandler maneja la importación de tanques desde hojas de cálculo
+type TankImportHandler struct {
+	importService ports.TankImportService
+	logger        logger.Logger
+}
+
+// NewTankImportHandler crea una nueva instancia del manejador de importación de tanques
+func NewTankImportHandler(importService ports.TankImportService, logger logger.Logger) *TankImportHandler {
+	return &TankImportHandler{
+		importService: importService,
+		logger:        logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *TankImportHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/tanks/import", h.ImportTanks).Methods(http.MethodPost)
+}
+
+// ImportTanks crea los tanques de una hoja CSV o XLSX, enviada como cuerpo de la solicitud o en
+// el campo "file" de un formulario multipart. El formato se toma de ?format, de la extensión del
+// archivo o de su Content-Type. Con ?dry_run=true solo devuelve el informe de validación.
+func (h *TankImportHandler) ImportTanks(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, r, "Parámetro dry_run inválido", http.StatusBadRequest)
+			return
+		}
+		dryRun = parsed
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxTankImportFileSize)
+
+	body, format, err := importFile(r)
+	if err != nil {
+		writeImportFileError(w, r, err)
+		return
+	}
+	if closer, ok := body.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+
+	rows, err := tankimport.Parse(body, format)
+	if err != nil {
+		h.logger.Error("Failed to parse tank import file", "error", err, "format", format)
+		writeImportFileError(w, r, err)
+		return
+	}
+
+	result, err := h.importService.ImportTanks(r.Context(), rows, dryRun)
+	if err != nil {
+		h.logger.Error("Failed to import tanks", "error", err, "dry_run", dryRun)
+		writeError(w, r, "Error al importar los tanques", statusForError(err))
+		return
+	}
+
+	if !dryRun {
+		h.logger.Info("Tanks imported", "created", result.Created, "invalid", result.Invalid)
+	}
+
+	// Los mensajes de las filas se devuelven en el idioma de la solicitud
+	language := i18n.FromContext(r.Context())
+	for _, row := range result.Rows {
+		for i := range row.Errors {
+			row.Errors[i].Message = i18n.Translate(language, row.Errors[i].Message)
+		}
+	}
+
+	writeJSON(w, r, http.StatusOK, result, h.logger)
+}
+
+// importFile devuelve el contenido de la hoja de cálculo y su formato
+func importFile(r *http.Request) (io.Reader, string, error) {
+	format := r.URL.Query().Get("format")
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		if format == "" {
+			format, _ = tankimport.FormatForContentType(r.Header.Get("Content-Type"))
+		}
+		return r.Body, format, nil
+	}
+
+	if err := r.ParseMultipartForm(maxTankImportFileSize); err != nil {
+		return nil, "", err
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, "", errMissingImportFile
+	}
+
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+	}
+	if format != tankimport.FormatCSV && format != tankimport.FormatXLSX {
+		if detected, ok := tankimport.FormatForContentType(header.Header.Get("Content-Type")); ok {
+			format = detected
+		}
+	}
+
+	return file, format, nil
+}
+
+// writeImportFileError responde el error de lectura de la hoja de cálculo
+func writeImportFileError(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeError(w, r, "El archivo supera el tamaño máximo permitido", http.StatusRequestEntityTooLarge)
+	case errors.Is(err, errMissingImportFile):
+		writeError(w, r, "Falta el archivo en el campo file", http.StatusBadRequest)
+	case errors.Is(err, tankimport.ErrUnsupportedFormat):
+		writeError(w, r, "Formato de archivo no soportado, use CSV o XLSX", http.StatusUnsupportedMediaType)
+	case errors.Is(err, tankimport.ErrInvalidFile):
+		writeError(w, r, "Archivo de tanques inválido", http.StatusBadRequest)
+	default:
+		writeError(w, r, "Error al decodificar la solicitud", http.StatusBadRequest)
+	}
+}
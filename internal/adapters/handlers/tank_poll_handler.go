@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// TankPollHandler maneja las peticiones HTTP de lectura bajo demanda de los tanques
+type TankPollHandler struct {
+	pollService ports.TankPollService
+	logger      logger.Logger
+}
+
+// NewTankPollHandler crea una nueva instancia del manejador de lecturas bajo demanda
+func NewTankPollHandler(pollService ports.TankPollService, logger logger.Logger) *TankPollHandler {
+	return &TankPollHandler{
+		pollService: pollService,
+		logger:      logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *TankPollHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/tanks/{id}/poll", h.PollTank).Methods(http.MethodPost)
+}
+
+// PollTank solicita una lectura inmediata y devuelve la medición recibida
+func (h *TankPollHandler) PollTank(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	measurement, err := h.pollService.PollTank(r.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to poll tank", "error", err, "id", id)
+		writeError(w, r, "Error al obtener una lectura inmediata del tanque", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, measurement, h.logger)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// UsageHandler maneja las peticiones HTTP del uso por organización
+type UsageHandler struct {
+	usageService ports.UsageService
+	logger       logger.Logger
+}
+
+// NewUsageHandler crea una nueva instancia del manejador de uso
+func NewUsageHandler(usageService ports.UsageService, logger logger.Logger) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+		logger:       logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *UsageHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/admin/usage", h.GetUsage).Methods(http.MethodGet)
+	router.HandleFunc("/api/admin/usage/export", h.ExportUsage).Methods(http.MethodGet)
+}
+
+// GetUsage devuelve el uso de cada organización en el mes del parámetro month (AAAA-MM, por
+// defecto el mes en curso)
+func (h *UsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	usage, err := h.usageService.GetUsage(r.Context(), r.URL.Query().Get("month"))
+	if err != nil {
+		h.logger.Error("Failed to get usage", "error", err)
+		writeError(w, r, "Error al obtener el uso por organización", statusForError(err))
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, usage, h.logger)
+}
+
+// ExportUsage descarga el uso del mes como CSV, una fila por organización, para la facturación
+func (h *UsageHandler) ExportUsage(w http.ResponseWriter, r *http.Request) {
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		month = domain.UsageMonth(time.Now())
+	}
+
+	usage, err := h.usageService.GetUsage(r.Context(), month)
+	if err != nil {
+		h.logger.Error("Failed to export usage", "error", err, "month", month)
+		writeError(w, r, "Error al obtener el uso por organización", statusForError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "usage-" + month + ".csv"}))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"month", "organization", "api_calls", "active_tanks", "measurements"})
+	for _, entry := range usage {
+		writer.Write([]string{
+			entry.Month,
+			entry.Organization,
+			strconv.FormatInt(entry.APICalls, 10),
+			strconv.Itoa(entry.ActiveTanks),
+			strconv.FormatInt(entry.Measurements, 10),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		h.logger.Error("Failed to write usage export", "error", err, "month", month)
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/i18n"
+	"monitor-tanques/pkg/logger"
+)
+
+// VoiceHandler maneja las preguntas de los asistentes de voz
+type VoiceHandler struct {
+	voiceService ports.VoiceService
+	logger       logger.Logger
+}
+
+// NewVoiceHandler crea una nueva instancia del manejador de asistentes de voz
+func NewVoiceHandler(voiceService ports.VoiceService, logger logger.Logger) *VoiceHandler {
+	return &VoiceHandler{
+		voiceService: voiceService,
+		logger:       logger,
+	}
+}
+
+// RegisterRoutes registra las rutas del manejador en el router
+func (h *VoiceHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/api/voice/query", h.Query).Methods(http.MethodPost)
+}
+
+// Query responde la pregunta en el idioma de su locale o, sin él, en el de Accept-Language
+func (h *VoiceHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var query domain.VoiceQuery
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		h.logger.Error("Failed to decode request body", "error", err)
+		writeDecodeError(w, r, err)
+		return
+	}
+
+	answer, err := h.voiceService.Answer(r.Context(), &query)
+	if err != nil {
+		h.logger.Error("Failed to answer voice query", "error", err, "intent", query.Intent)
+		writeError(w, r, "Error al responder la pregunta", statusForError(err))
+		return
+	}
+
+	language := i18n.FromContext(r.Context())
+	if query.Locale != "" {
+		language = i18n.Negotiate(query.Locale)
+	}
+	answer.Speech = i18n.Sprintf(language, answer.SpeechFormat, answer.SpeechArgs...)
+
+	h.logger.Debug("Voice query answered", "intent", answer.Intent, "tanks", len(answer.TankIDs))
+	writeJSON(w, r, http.StatusOK, answer, h.logger)
+}
@@ -0,0 +1,201 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// BatchConfig configura la escritura diferida de mediciones
+type BatchConfig struct {
+	Size          int           // Mediciones acumuladas que provocan una escritura
+	FlushInterval time.Duration // Tiempo máximo que una medición espera en el búfer
+}
+
+// maxPendingFactor limita el búfer a varias veces el tamaño del lote: si las escrituras no dan
+// abasto, quien añade la medición escribe el lote directamente en lugar de seguir acumulando
+const maxPendingFactor = 4
+
+// BatchWriter decora un TankService acumulando las mediciones recibidas y guardándolas por lotes
+// en una sola unidad de trabajo. La medición se valida y se comprueba que su tanque exista antes
+// de aceptarla, pero el nivel del tanque no se actualiza hasta la siguiente escritura del lote.
+type BatchWriter struct {
+	ports.TankService
+	config BatchConfig
+	logger logger.Logger
+
+	mutex   sync.Mutex
+	pending []*domain.Measurement
+	flushCh chan struct{}
+
+	flushMutex sync.Mutex // serializa las escrituras para conservar el orden de llegada
+	wg         sync.WaitGroup
+}
+
+// NewBatchWriter crea un escritor por lotes sobre el servicio de tanques indicado
+func NewBatchWriter(inner ports.TankService, config BatchConfig, logger logger.Logger) *BatchWriter {
+	if config.Size <= 0 {
+		config.Size = 1
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+
+	return &BatchWriter{
+		TankService: inner,
+		config:      config,
+		logger:      logger,
+		pending:     make([]*domain.Measurement, 0, config.Size),
+		flushCh:     make(chan struct{}, 1),
+	}
+}
+
+// AddMeasurement valida la medición y la deja en el búfer para la siguiente escritura
+func (w *BatchWriter) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if measurement == nil || measurement.TankID == "" || measurement.Level < 0 {
+		return errors.New("invalid measurement data")
+	}
+
+	if _, err := w.TankService.GetTank(ctx, measurement.TankID); err != nil {
+		return err
+	}
+
+	if measurement.Timestamp.IsZero() {
+		measurement.Timestamp = time.Now()
+	}
+
+	measurementCopy := *measurement
+
+	w.mutex.Lock()
+	w.pending = append(w.pending, &measurementCopy)
+	pending := len(w.pending)
+	w.mutex.Unlock()
+
+	if pending >= w.config.Size*maxPendingFactor {
+		// El lote incluye mediciones de otros clientes que ya recibieron respuesta: no debe abortarse
+		// si este cliente se desconecta, y a este solo le concierne el error de su medición
+		return w.flush(context.WithoutCancel(ctx), &measurementCopy)
+	}
+
+	if pending >= w.config.Size {
+		select {
+		case w.flushCh <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Start lanza la escritura periódica del búfer hasta que se cancele el contexto
+func (w *BatchWriter) Start(ctx context.Context) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.loop(ctx)
+	}()
+}
+
+// Close espera a que termine la escritura periódica y guarda las mediciones pendientes.
+// Debe llamarse al apagar el servidor, después de cancelar el contexto de Start.
+func (w *BatchWriter) Close(ctx context.Context) error {
+	w.wg.Wait()
+	return w.Flush(ctx)
+}
+
+// Flush guarda inmediatamente las mediciones acumuladas
+func (w *BatchWriter) Flush(ctx context.Context) error {
+	return w.flush(ctx, nil)
+}
+
+// flush guarda las mediciones acumuladas. Con own, devuelve solo el error de esa medición; sin
+// ella, los de todas las que no se pudieron guardar.
+func (w *BatchWriter) flush(ctx context.Context, own *domain.Measurement) error {
+	w.flushMutex.Lock()
+	defer w.flushMutex.Unlock()
+
+	w.mutex.Lock()
+	batch := w.pending
+	w.pending = make([]*domain.Measurement, 0, w.config.Size)
+	w.mutex.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	if err := w.TankService.AddMeasurements(ctx, batch); err != nil {
+		// Un lote falla entero si una medición no se puede guardar (p. ej. su tanque se eliminó);
+		// reintentamos una a una para no perder el resto
+		w.logger.Warn("Measurement batch failed, retrying individually", "size", len(batch), "error", err)
+		return w.writeIndividually(ctx, batch, own)
+	}
+
+	w.logger.Debug("Measurement batch written", "size", len(batch), "duration", time.Since(start))
+	return nil
+}
+
+// loop escribe el búfer al llenarse o al vencer el intervalo
+func (w *BatchWriter) loop(ctx context.Context) {
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	// Las escrituras en curso no deben abortarse al cancelar el contexto de las tareas
+	flushCtx := context.WithoutCancel(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.flushCh:
+		}
+
+		if err := w.Flush(flushCtx); err != nil {
+			w.logger.Error("Failed to flush measurements", "error", err)
+		}
+	}
+}
+
+// writeIndividually guarda cada medición por separado. Las que nunca podrán guardarse se descartan;
+// ante cualquier otro error, la medición y las siguientes vuelven al principio del búfer para la
+// siguiente escritura. Devuelve el error de own si se descartó o, sin ella, los errores de todas.
+func (w *BatchWriter) writeIndividually(ctx context.Context, batch []*domain.Measurement, own *domain.Measurement) error {
+	var errs []error
+	for i, measurement := range batch {
+		err := w.TankService.AddMeasurement(ctx, measurement)
+		if err == nil || services.IsStaleMeasurement(err) {
+			continue
+		}
+
+		if !services.IsPermanentRejection(err) {
+			w.logger.Warn("Failed to write measurements, keeping them for the next flush", "pending", len(batch)-i, "error", err)
+			w.requeue(batch[i:])
+			if own == nil {
+				errs = append(errs, err)
+			}
+			break
+		}
+
+		w.logger.Error("Dropping measurement", "tank_id", measurement.TankID, "error", err)
+		if own == nil || measurement == own {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// requeue devuelve las mediciones al principio del búfer, delante de las recibidas mientras tanto
+func (w *BatchWriter) requeue(measurements []*domain.Measurement) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.pending = append(append(make([]*domain.Measurement, 0, len(measurements)+len(w.pending)), measurements...), w.pending...)
+}
@@ -0,0 +1,55 @@
+package locks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryLocker implementa ports.Locker dentro de un único proceso. Es suficiente cuando
+// se ejecuta una sola réplica de la API.
+type MemoryLocker struct {
+	leases map[string]time.Time // clave: nombre del bloqueo, valor: vencimiento
+	mutex  sync.Mutex
+}
+
+// NewMemoryLocker crea una nueva instancia del bloqueo en memoria
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		leases: make(map[string]time.Time),
+	}
+}
+
+// TryAcquire obtiene el bloqueo si no existe o si ya venció, y descarta los que hayan vencido
+func (l *MemoryLocker) TryAcquire(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	if expiresAt, exists := l.leases[name]; exists && now.Before(expiresAt) {
+		return false, nil
+	}
+
+	// Los bloqueos vencidos no se vuelven a pedir (p. ej. los de periodos pasados del planificador)
+	for other, expiresAt := range l.leases {
+		if !now.Before(expiresAt) {
+			delete(l.leases, other)
+		}
+	}
+
+	l.leases[name] = now.Add(ttl)
+	return true, nil
+}
+
+// Release libera el bloqueo
+func (l *MemoryLocker) Release(ctx context.Context, name string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.leases, name)
+	return nil
+}
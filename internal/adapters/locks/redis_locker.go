@@ -0,0 +1,132 @@
+package locks
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// releaseScript elimina la clave solo si el valor coincide con el propietario, de modo que una
+// instancia nunca libere un bloqueo que ya venció y fue tomado por otra
+const releaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+
+// RedisLocker implementa ports.Locker sobre Redis con SET NX PX, compartido por todas las réplicas.
+// Habla el protocolo RESP directamente para no añadir dependencias externas.
+type RedisLocker struct {
+	addr        string
+	password    string
+	owner       string
+	prefix      string
+	dialTimeout time.Duration
+}
+
+// NewRedisLocker crea un bloqueo distribuido sobre Redis. owner identifica a esta instancia.
+func NewRedisLocker(addr, password, owner string) *RedisLocker {
+	return &RedisLocker{
+		addr:        addr,
+		password:    password,
+		owner:       owner,
+		prefix:      "monitor-tanques:lock:",
+		dialTimeout: 2 * time.Second,
+	}
+}
+
+// TryAcquire obtiene el bloqueo si ninguna otra instancia lo posee
+func (l *RedisLocker) TryAcquire(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	reply, err := l.do(ctx, "SET", l.prefix+name, l.owner, "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+
+	// Redis devuelve OK si se estableció la clave y nil si ya existía
+	return reply == "OK", nil
+}
+
+// Release libera el bloqueo si pertenece a esta instancia
+func (l *RedisLocker) Release(ctx context.Context, name string) error {
+	_, err := l.do(ctx, "EVAL", releaseScript, "1", l.prefix+name, l.owner)
+	return err
+}
+
+// do abre una conexión, se autentica si es necesario y ejecuta un único comando
+func (l *RedisLocker) do(ctx context.Context, args ...string) (string, error) {
+	dialer := net.Dialer{Timeout: l.dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", l.addr)
+	if err != nil {
+		return "", fmt.Errorf("redis: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(l.dialTimeout))
+	}
+
+	reader := bufio.NewReader(conn)
+
+	if l.password != "" {
+		if _, err := execute(conn, reader, "AUTH", l.password); err != nil {
+			return "", err
+		}
+	}
+
+	return execute(conn, reader, args...)
+}
+
+// execute escribe un comando en formato RESP y lee su respuesta
+func execute(w io.Writer, r *bufio.Reader, args ...string) (string, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return "", fmt.Errorf("redis: %w", err)
+	}
+
+	return readReply(r)
+}
+
+// readReply lee una respuesta RESP simple, de error, entera o bulk
+func readReply(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("redis: %w", err)
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", fmt.Errorf("redis: %s", line[1:])
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("redis: invalid bulk length: %w", err)
+		}
+		if size < 0 {
+			return "", nil
+		}
+
+		payload := make([]byte, size+2)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return "", fmt.Errorf("redis: %w", err)
+		}
+		return string(payload[:size]), nil
+	default:
+		return "", fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
@@ -0,0 +1,349 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/pkg/logger"
+)
+
+// homeAssistantTimeout limita cada envío al broker, que puede incluir varios tanques
+const homeAssistantTimeout = 10 * time.Second
+
+// Espera entre los reintentos tras un fallo del broker: se duplica en cada fallo consecutivo
+const (
+	homeAssistantMinBackoff = time.Second
+	homeAssistantMaxBackoff = time.Minute
+)
+
+// HomeAssistantConfig contiene los temas de la integración con Home Assistant
+type HomeAssistantConfig struct {
+	DiscoveryPrefix string // Prefijo de descubrimiento de Home Assistant
+	StateTopic      string // Tema del estado de cada tanque; {id} se reemplaza por el ID del tanque
+}
+
+// homeAssistantSensor es una de las entidades con las que aparece cada tanque en Home Assistant
+type homeAssistantSensor struct {
+	key           string
+	name          string
+	valueTemplate string
+	unit          string
+	deviceClass   string
+	stateClass    string
+	icon          string
+	options       []string
+}
+
+// homeAssistantSensors son las entidades de cada tanque; leen su valor del JSON de estado
+var homeAssistantSensors = []homeAssistantSensor{
+	{key: "level", name: "Nivel", valueTemplate: "{{ value_json.level }}", unit: "L", deviceClass: "volume_storage", stateClass: "measurement"},
+	{key: "percentage", name: "Llenado", valueTemplate: "{{ value_json.percentage }}", unit: "%", stateClass: "measurement", icon: "mdi:storage-tank"},
+	{key: "temperature", name: "Temperatura", valueTemplate: "{{ value_json.temperature }}", unit: "°C", deviceClass: "temperature", stateClass: "measurement"},
+	{key: "status", name: "Estado", valueTemplate: "{{ value_json.status }}", deviceClass: "enum", icon: "mdi:alert-circle-outline", options: []string{"normal", "warning", "critical"}},
+}
+
+// homeAssistantDevice agrupa las entidades de un tanque bajo un mismo dispositivo
+type homeAssistantDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model,omitempty"`
+}
+
+// homeAssistantDiscovery es el mensaje de configuración de una entidad
+type homeAssistantDiscovery struct {
+	Name              string              `json:"name"`
+	UniqueID          string              `json:"unique_id"`
+	StateTopic        string              `json:"state_topic"`
+	ValueTemplate     string              `json:"value_template"`
+	UnitOfMeasurement string              `json:"unit_of_measurement,omitempty"`
+	DeviceClass       string              `json:"device_class,omitempty"`
+	StateClass        string              `json:"state_class,omitempty"`
+	Icon              string              `json:"icon,omitempty"`
+	Options           []string            `json:"options,omitempty"`
+	Device            homeAssistantDevice `json:"device"`
+}
+
+// homeAssistantState es el estado de un tanque que leen sus entidades
+type homeAssistantState struct {
+	Level       float64   `json:"level"`
+	Percentage  float64   `json:"percentage"`
+	Temperature float64   `json:"temperature"`
+	Status      string    `json:"status"`
+	Capacity    float64   `json:"capacity"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// message es un mensaje retenido pendiente de publicar
+type message struct {
+	topic   string
+	payload []byte
+}
+
+// HomeAssistantPublisher implementa ports.TankStatePublisher con el descubrimiento MQTT de Home
+// Assistant: cada tanque aparece como un dispositivo con sensores de nivel, llenado, temperatura
+// y estado, sin configurarlo a mano. Los mensajes se publican retenidos para que Home Assistant
+// los reciba aunque se conecte después. Los envíos no bloquean la ingesta: se acumulan y Run los
+// publica en segundo plano, solo el último estado de cada tanque. Si el broker no está
+// disponible, los tanques vuelven a quedar pendientes y Run reintenta con espera exponencial, de
+// modo que una caída del broker no deja en Home Assistant un estado antiguo.
+type HomeAssistantPublisher struct {
+	config      Config
+	ha          HomeAssistantConfig
+	dialTimeout time.Duration
+	logger      logger.Logger
+
+	mutex   sync.Mutex
+	pending map[string]*domain.Tank // nil indica que el tanque se eliminó
+	order   []string
+	wake    chan struct{}
+
+	announced map[string]string // Descubrimiento publicado de cada tanque; solo lo usa Run
+}
+
+// NewHomeAssistantPublisher crea un publicador del estado de los tanques para Home Assistant
+func NewHomeAssistantPublisher(config Config, ha HomeAssistantConfig, logger logger.Logger) *HomeAssistantPublisher {
+	if ha.DiscoveryPrefix == "" {
+		ha.DiscoveryPrefix = "homeassistant"
+	}
+	if ha.StateTopic == "" {
+		ha.StateTopic = "monitor-tanques/tanks/{id}/state"
+	}
+	return &HomeAssistantPublisher{
+		config:      config,
+		ha:          ha,
+		dialTimeout: 5 * time.Second,
+		logger:      logger,
+		pending:     make(map[string]*domain.Tank),
+		wake:        make(chan struct{}, 1),
+		announced:   make(map[string]string),
+	}
+}
+
+// PublishTank encola el estado actual del tanque; sustituye al que estuviera pendiente
+func (p *HomeAssistantPublisher) PublishTank(ctx context.Context, tank *domain.Tank) {
+	if tank == nil || tank.ID == "" {
+		return
+	}
+	copied := *tank
+	p.enqueue(tank.ID, &copied)
+}
+
+// RemoveTank encola la retirada del tanque de Home Assistant
+func (p *HomeAssistantPublisher) RemoveTank(ctx context.Context, tankID string) {
+	if tankID == "" {
+		return
+	}
+	p.enqueue(tankID, nil)
+}
+
+// enqueue deja el tanque pendiente y despierta a Run
+func (p *HomeAssistantPublisher) enqueue(tankID string, tank *domain.Tank) {
+	p.mutex.Lock()
+	if _, ok := p.pending[tankID]; !ok {
+		p.order = append(p.order, tankID)
+	}
+	p.pending[tankID] = tank
+	p.mutex.Unlock()
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run publica los tanques pendientes hasta que se cancele ctx. Tras un fallo, los cambios que
+// llegan durante la espera se acumulan y se publican con el reintento.
+func (p *HomeAssistantPublisher) Run(ctx context.Context) {
+	var backoff time.Duration
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.wake:
+			if retry != nil {
+				continue
+			}
+		case <-retry:
+		}
+
+		retry = nil
+		if p.flush(ctx) {
+			backoff = 0
+			continue
+		}
+		backoff = min(max(2*backoff, homeAssistantMinBackoff), homeAssistantMaxBackoff)
+		retry = time.After(backoff)
+	}
+}
+
+// flush publica en una sola conexión todos los tanques pendientes. Si falla, los devuelve a
+// pendientes, salvo los que ya tienen un estado más reciente, e indica que hay que reintentar.
+func (p *HomeAssistantPublisher) flush(ctx context.Context) bool {
+	p.mutex.Lock()
+	pending, order := p.pending, p.order
+	p.pending, p.order = make(map[string]*domain.Tank), nil
+	p.mutex.Unlock()
+
+	if len(order) == 0 {
+		return true
+	}
+
+	var messages []message
+	announced := make(map[string]string)
+	for _, tankID := range order {
+		tank := pending[tankID]
+		if tank == nil {
+			messages = append(messages, p.removalMessages(tankID)...)
+			announced[tankID] = ""
+			continue
+		}
+
+		discovery := p.discoveryMessages(tank)
+		signature := signatureOf(discovery)
+		if p.announced[tankID] != signature {
+			messages = append(messages, discovery...)
+			announced[tankID] = signature
+		}
+		messages = append(messages, p.stateMessage(tank))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, homeAssistantTimeout)
+	defer cancel()
+	if err := p.publish(ctx, messages); err != nil {
+		// El descubrimiento no publicado se vuelve a enviar con el reintento
+		p.logger.Warn("Home Assistant publish failed, will retry", "tanks", len(order), "error", err)
+		p.requeue(order, pending)
+		return false
+	}
+
+	for tankID, signature := range announced {
+		if signature == "" {
+			delete(p.announced, tankID)
+			continue
+		}
+		p.announced[tankID] = signature
+	}
+	p.logger.Debug("Home Assistant state published", "tanks", len(order), "messages", len(messages))
+	return true
+}
+
+// requeue devuelve a pendientes los tanques de un envío fallido, por delante de los encolados
+// después; los que ya se volvieron a encolar conservan su estado más reciente
+func (p *HomeAssistantPublisher) requeue(order []string, pending map[string]*domain.Tank) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	requeued := make([]string, 0, len(order))
+	for _, tankID := range order {
+		if _, newer := p.pending[tankID]; newer {
+			continue
+		}
+		p.pending[tankID] = pending[tankID]
+		requeued = append(requeued, tankID)
+	}
+	p.order = append(requeued, p.order...)
+}
+
+// publish envía los mensajes retenidos en una sola sesión
+func (p *HomeAssistantPublisher) publish(ctx context.Context, messages []message) error {
+	s, err := dial(ctx, p.config, p.dialTimeout)
+	if err != nil {
+		return err
+	}
+	for _, m := range messages {
+		if err := s.publish(m.topic, m.payload, true); err != nil {
+			s.conn.Close()
+			return err
+		}
+	}
+	return s.close()
+}
+
+// discoveryMessages devuelve la configuración de las entidades del tanque
+func (p *HomeAssistantPublisher) discoveryMessages(tank *domain.Tank) []message {
+	device := homeAssistantDevice{
+		Identifiers:  []string{p.nodeID(tank.ID)},
+		Name:         tank.Name,
+		Manufacturer: "monitor-tanques",
+		Model:        tank.LiquidType,
+	}
+
+	messages := make([]message, 0, len(homeAssistantSensors))
+	for _, sensor := range homeAssistantSensors {
+		payload, _ := json.Marshal(homeAssistantDiscovery{
+			Name:              sensor.name,
+			UniqueID:          p.nodeID(tank.ID) + "_" + sensor.key,
+			StateTopic:        p.stateTopic(tank.ID),
+			ValueTemplate:     sensor.valueTemplate,
+			UnitOfMeasurement: sensor.unit,
+			DeviceClass:       sensor.deviceClass,
+			StateClass:        sensor.stateClass,
+			Icon:              sensor.icon,
+			Options:           sensor.options,
+			Device:            device,
+		})
+		messages = append(messages, message{topic: p.discoveryTopic(tank.ID, sensor.key), payload: payload})
+	}
+	return messages
+}
+
+// removalMessages borra la configuración retenida de las entidades, con lo que Home Assistant
+// las retira, y el último estado del tanque
+func (p *HomeAssistantPublisher) removalMessages(tankID string) []message {
+	messages := make([]message, 0, len(homeAssistantSensors)+1)
+	for _, sensor := range homeAssistantSensors {
+		messages = append(messages, message{topic: p.discoveryTopic(tankID, sensor.key)})
+	}
+	return append(messages, message{topic: p.stateTopic(tankID)})
+}
+
+// stateMessage devuelve el estado actual del tanque
+func (p *HomeAssistantPublisher) stateMessage(tank *domain.Tank) message {
+	payload, _ := json.Marshal(homeAssistantState{
+		Level:       tank.CurrentLevel,
+		Percentage:  math.Round(tank.GetLevelPercentage()*10) / 10,
+		Temperature: tank.Temperature,
+		Status:      tank.Status,
+		Capacity:    tank.Capacity,
+		LastUpdated: tank.LastUpdated,
+	})
+	return message{topic: p.stateTopic(tank.ID), payload: payload}
+}
+
+// discoveryTopic devuelve el tema de configuración de una entidad del tanque
+func (p *HomeAssistantPublisher) discoveryTopic(tankID, key string) string {
+	return p.ha.DiscoveryPrefix + "/sensor/" + p.nodeID(tankID) + "/" + key + "/config"
+}
+
+// stateTopic devuelve el tema de estado del tanque
+func (p *HomeAssistantPublisher) stateTopic(tankID string) string {
+	return strings.ReplaceAll(p.ha.StateTopic, "{id}", tankID)
+}
+
+// nodeID identifica al tanque en los temas de descubrimiento, que solo admiten letras, dígitos,
+// guiones y guiones bajos
+func (p *HomeAssistantPublisher) nodeID(tankID string) string {
+	return "monitor_tanques_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, tankID)
+}
+
+// signatureOf resume los mensajes de descubrimiento para no repetirlos si no cambian
+func signatureOf(messages []message) string {
+	var builder strings.Builder
+	for _, m := range messages {
+		builder.WriteString(m.topic)
+		builder.Write(m.payload)
+	}
+	return builder.String()
+}
@@ -0,0 +1,62 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// Config contiene los parámetros de conexión al broker MQTT
+type Config struct {
+	BrokerAddr   string // host:puerto del broker
+	Username     string
+	Password     string
+	ClientID     string
+	CommandTopic string // Tema de los comandos; {id} se reemplaza por el ID del equipo
+
+	// PersistentSession pide al broker conservar la sesión entre conexiones: las suscripciones y
+	// los mensajes QoS 1 que lleguen mientras el cliente está desconectado
+	PersistentSession bool
+}
+
+// CommandPublisher implementa ports.CommandPublisher publicando cada comando con QoS 1 en el tema
+// del equipo. Habla el protocolo MQTT 3.1.1 directamente para no añadir dependencias externas.
+type CommandPublisher struct {
+	config      Config
+	dialTimeout time.Duration
+}
+
+// NewCommandPublisher crea un publicador de comandos hacia el broker configurado
+func NewCommandPublisher(config Config) *CommandPublisher {
+	if config.CommandTopic == "" {
+		config.CommandTopic = "devices/{id}/commands"
+	}
+	return &CommandPublisher{
+		config:      config,
+		dialTimeout: 5 * time.Second,
+	}
+}
+
+// PublishCommand publica el comando como JSON y espera la confirmación del broker
+func (p *CommandPublisher) PublishCommand(ctx context.Context, command *domain.DeviceCommand) error {
+	payload, err := json.Marshal(command)
+	if err != nil {
+		return err
+	}
+
+	s, err := dial(ctx, p.config, p.dialTimeout)
+	if err != nil {
+		return err
+	}
+
+	topic := strings.ReplaceAll(p.config.CommandTopic, "{id}", command.DeviceID)
+	if err := s.publish(topic, payload, false); err != nil {
+		s.conn.Close()
+		return err
+	}
+
+	return s.close()
+}
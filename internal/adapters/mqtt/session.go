@@ -0,0 +1,227 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Tipos de paquete de MQTT 3.1.1 usados por los publicadores y el suscriptor
+const (
+	packetConnect    = 0x10
+	packetConnAck    = 0x20
+	packetPublish    = 0x30
+	packetPublishQoS = 0x32 // PUBLISH con QoS 1
+	packetPubAck     = 0x40
+	packetSubscribe  = 0x82 // SUBSCRIBE lleva siempre los flags 0010
+	packetSubAck     = 0x90
+	packetPingReq    = 0xC0
+	packetPingResp   = 0xD0
+	packetDisconnect = 0xE0
+
+	flagRetain = 0x01 // El broker conserva el mensaje y lo entrega a los nuevos suscriptores
+)
+
+// session es una conexión MQTT abierta con el broker por la que se publican uno o varios mensajes
+// o se reciben los de una suscripción
+type session struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	packetID uint16
+	resumed  bool // El broker conservaba la sesión persistente de una conexión anterior
+}
+
+// dial abre una sesión con el broker de config. Sin plazo en ctx, la sesión entera dispone de
+// timeout.
+func dial(ctx context.Context, config Config, timeout time.Duration) (*session, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", config.BrokerAddr)
+	if err != nil {
+		return nil, fmt.Errorf("mqtt: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	s := &session{conn: conn, reader: bufio.NewReader(conn)}
+	if err := s.connect(config); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// connect abre la sesión MQTT y comprueba que el broker la acepte
+func (s *session) connect(config Config) error {
+	flags := byte(0x02) // Sesión limpia
+	if config.PersistentSession {
+		flags = 0
+	}
+	payload := encodeString(config.ClientID)
+	if config.Username != "" {
+		flags |= 0x80
+		payload = append(payload, encodeString(config.Username)...)
+	}
+	if config.Password != "" {
+		flags |= 0x40
+		payload = append(payload, encodeString(config.Password)...)
+	}
+
+	body := encodeString("MQTT")
+	body = append(body, 4, flags, 0, 30) // Nivel de protocolo 4 (3.1.1) y keep alive de 30 s
+	body = append(body, payload...)
+
+	if err := writePacket(s.conn, packetConnect, body); err != nil {
+		return err
+	}
+
+	packetType, response, err := readPacket(s.reader)
+	if err != nil {
+		return err
+	}
+	if packetType != packetConnAck || len(response) != 2 {
+		return errors.New("mqtt: unexpected reply to CONNECT")
+	}
+	if response[1] != 0 {
+		return fmt.Errorf("mqtt: connection refused with code %d", response[1])
+	}
+
+	s.resumed = response[0]&0x01 != 0
+	return nil
+}
+
+// publish envía el mensaje con QoS 1 y espera el PUBACK correspondiente. Un mensaje retenido
+// vacío borra el que el broker conservaba en el tema.
+func (s *session) publish(topic string, payload []byte, retain bool) error {
+	body := encodeString(topic)
+	body = binary.BigEndian.AppendUint16(body, s.nextPacketID())
+	body = append(body, payload...)
+
+	header := byte(packetPublishQoS)
+	if retain {
+		header |= flagRetain
+	}
+	if err := writePacket(s.conn, header, body); err != nil {
+		return err
+	}
+
+	packetType, response, err := readPacket(s.reader)
+	if err != nil {
+		return err
+	}
+	if packetType != packetPubAck || len(response) != 2 || binary.BigEndian.Uint16(response) != s.packetID {
+		return errors.New("mqtt: unexpected reply to PUBLISH")
+	}
+
+	return nil
+}
+
+// subscribe se suscribe al filtro con QoS 1 y espera el SUBACK correspondiente
+func (s *session) subscribe(filter string) error {
+	body := binary.BigEndian.AppendUint16(nil, s.nextPacketID())
+	body = append(body, encodeString(filter)...)
+	body = append(body, 1)
+	if err := writePacket(s.conn, packetSubscribe, body); err != nil {
+		return err
+	}
+
+	packetType, response, err := readPacket(s.reader)
+	if err != nil {
+		return err
+	}
+	if packetType != packetSubAck || len(response) != 3 || binary.BigEndian.Uint16(response) != s.packetID {
+		return errors.New("mqtt: unexpected reply to SUBSCRIBE")
+	}
+	if response[2] == 0x80 {
+		return fmt.Errorf("mqtt: subscription to %q refused", filter)
+	}
+	return nil
+}
+
+// nextPacketID devuelve el identificador del siguiente paquete, que nunca puede ser 0
+func (s *session) nextPacketID() uint16 {
+	s.packetID++
+	if s.packetID == 0 {
+		s.packetID = 1
+	}
+	return s.packetID
+}
+
+// close cierra la sesión con DISCONNECT para que el broker no la dé por perdida
+func (s *session) close() error {
+	_, err := s.conn.Write([]byte{packetDisconnect, 0})
+	return errors.Join(err, s.conn.Close())
+}
+
+// encodeString codifica una cadena con su longitud de 2 bytes
+func encodeString(value string) []byte {
+	encoded := binary.BigEndian.AppendUint16(nil, uint16(len(value)))
+	return append(encoded, value...)
+}
+
+// writePacket escribe la cabecera fija (tipo y longitud restante) seguida del cuerpo
+func writePacket(w io.Writer, packetType byte, body []byte) error {
+	packet := []byte{packetType}
+
+	length := len(body)
+	for {
+		encoded := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			encoded |= 0x80
+		}
+		packet = append(packet, encoded)
+		if length == 0 {
+			break
+		}
+	}
+
+	packet = append(packet, body...)
+	_, err := w.Write(packet)
+	return err
+}
+
+// readPacket lee un paquete y devuelve su tipo (sin los bits de flags) y su cuerpo
+func readPacket(reader *bufio.Reader) (byte, []byte, error) {
+	header, body, err := readRawPacket(reader)
+	return header & 0xF0, body, err
+}
+
+// readRawPacket lee un paquete y devuelve su cabecera completa, con los flags, y su cuerpo
+func readRawPacket(reader *bufio.Reader) (byte, []byte, error) {
+	header, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, fmt.Errorf("mqtt: %w", err)
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("mqtt: malformed remaining length")
+		}
+		encoded, err := reader.ReadByte()
+		if err != nil {
+			return 0, nil, fmt.Errorf("mqtt: %w", err)
+		}
+		length += int(encoded&0x7F) * multiplier
+		if encoded&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return 0, nil, fmt.Errorf("mqtt: %w", err)
+	}
+
+	return header, body, nil
+}
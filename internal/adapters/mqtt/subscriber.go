@@ -0,0 +1,317 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// Espera entre las reconexiones con el broker y entre los reintentos de guardar las mediciones del
+// búfer: se duplica en cada fallo consecutivo
+const (
+	subscriberMinBackoff = time.Second
+	subscriberMaxBackoff = time.Minute
+)
+
+// subscriberPingInterval es el silencio tras el que se comprueba la conexión con un PINGREQ; sin
+// respuesta en otro intervalo, la conexión se da por perdida. Es la mitad del keep alive de 30 s.
+const subscriberPingInterval = 15 * time.Second
+
+// subscriberBatchSize limita las mediciones del búfer que se leen de una vez para guardarlas
+const subscriberBatchSize = 100
+
+// MeasurementBuffer guarda de forma durable las mediciones recibidas hasta que se guardan en el
+// tanque. Lo implementa el diario de repositories.FileSyncQueueRepository.
+type MeasurementBuffer interface {
+	EnqueueMeasurements(ctx context.Context, measurements []*domain.Measurement) error
+	GetQueuedMeasurements(ctx context.Context, limit int) ([]*domain.Measurement, error)
+	DeleteQueuedMeasurements(ctx context.Context, ids []string) error
+}
+
+// Subscriber recibe las mediciones que los equipos publican por MQTT. Se conecta con una sesión
+// persistente, así que durante una caída el broker conserva la suscripción y los mensajes QoS 1, y
+// al reconectar los entrega. Cada mensaje se confirma con PUBACK solo después de escribirlo en el
+// búfer durable, de modo que un mensaje sin confirmar vuelve a entregarse y uno confirmado
+// sobrevive a un reinicio; las mediciones se guardan después en segundo plano, reintentando con
+// espera exponencial mientras el almacenamiento falle.
+type Subscriber struct {
+	config      Config
+	topic       string // Tema de las mediciones; {id} es el segmento con el ID del tanque
+	tanks       ports.TankService
+	buffer      MeasurementBuffer
+	dialTimeout time.Duration
+	logger      logger.Logger
+	wake        chan struct{}
+}
+
+// NewSubscriber crea un suscriptor de las mediciones publicadas en topic, que debe tener {id} como
+// uno de sus segmentos. config.ClientID identifica la sesión persistente en el broker y no debe
+// compartirse con otro cliente.
+func NewSubscriber(config Config, topic string, tanks ports.TankService, buffer MeasurementBuffer, logger logger.Logger) (*Subscriber, error) {
+	if !containsSegment(topic, "{id}") {
+		return nil, errors.New("mqtt: measurement topic must have an {id} segment")
+	}
+	config.PersistentSession = true
+	return &Subscriber{
+		config:      config,
+		topic:       topic,
+		tanks:       tanks,
+		buffer:      buffer,
+		dialTimeout: 5 * time.Second,
+		logger:      logger,
+		wake:        make(chan struct{}, 1),
+	}, nil
+}
+
+// Run recibe mensajes hasta que se cancele ctx, reconectando con espera exponencial tras cada
+// caída del broker. Las mediciones que quedaron en el búfer de una ejecución anterior se guardan
+// al empezar.
+func (s *Subscriber) Run(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.store(ctx)
+	}()
+	s.notify()
+
+	var backoff time.Duration
+	for {
+		connected, err := s.receive(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		if connected {
+			backoff = 0
+		}
+		backoff = min(max(2*backoff, subscriberMinBackoff), subscriberMaxBackoff)
+		s.logger.Warn("MQTT subscriber disconnected, will reconnect", "broker", s.config.BrokerAddr, "retry_in", backoff, "error", err)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	<-done
+}
+
+// receive abre una sesión y escribe en el búfer los mensajes recibidos hasta que se pierda la
+// conexión. Indica si llegó a conectarse, para reiniciar la espera entre reconexiones.
+func (s *Subscriber) receive(ctx context.Context) (bool, error) {
+	conn, err := dial(ctx, s.config, s.dialTimeout)
+	if err != nil {
+		return false, err
+	}
+	defer conn.conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.conn.Close() })
+	defer stop()
+
+	// Una sesión reanudada conserva la suscripción; el broker entrega ahora lo que retuvo
+	if !conn.resumed {
+		if err := conn.subscribe(subscriptionFilter(s.topic)); err != nil {
+			return true, err
+		}
+	}
+	s.logger.Info("MQTT subscriber connected", "broker", s.config.BrokerAddr, "topic", s.topic, "resumed", conn.resumed)
+
+	pinging := false
+	for {
+		// Se espera al siguiente paquete sin consumirlo, para que el plazo no corte uno a medias
+		conn.conn.SetDeadline(time.Now().Add(subscriberPingInterval))
+		if _, err := conn.reader.Peek(1); err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && !pinging {
+				pinging = true
+				if err := writePacket(conn.conn, packetPingReq, nil); err != nil {
+					return true, err
+				}
+				continue
+			}
+			return true, err
+		}
+
+		conn.conn.SetDeadline(time.Now().Add(s.dialTimeout))
+		header, body, err := readRawPacket(conn.reader)
+		if err != nil {
+			return true, err
+		}
+		pinging = false
+
+		switch header & 0xF0 {
+		case packetPingResp:
+		case packetPublish:
+			if err := s.accept(ctx, conn, header, body); err != nil {
+				return true, err
+			}
+		}
+	}
+}
+
+// accept escribe en el búfer la medición de un PUBLISH y la confirma. Si el búfer falla, el mensaje
+// queda sin confirmar y se devuelve el error para reconectar: el broker lo entregará de nuevo.
+func (s *Subscriber) accept(ctx context.Context, conn *session, header byte, body []byte) error {
+	qos := (header >> 1) & 0x03
+	if len(body) < 2 {
+		return errors.New("mqtt: malformed PUBLISH")
+	}
+	topicLength := int(binary.BigEndian.Uint16(body))
+	offset := 2 + topicLength
+	if qos > 0 {
+		offset += 2
+	}
+	if len(body) < offset {
+		return errors.New("mqtt: malformed PUBLISH")
+	}
+	topic := string(body[2 : 2+topicLength])
+
+	// Un mensaje inválido se confirma igualmente: entregarlo de nuevo no lo arreglaría
+	if measurement, err := s.decode(topic, body[offset:]); err != nil {
+		s.logger.Warn("Discarding invalid MQTT measurement", "topic", topic, "error", err)
+	} else {
+		if err := s.buffer.EnqueueMeasurements(ctx, []*domain.Measurement{measurement}); err != nil {
+			return err
+		}
+		s.notify()
+	}
+
+	if qos == 0 {
+		return nil
+	}
+	return writePacket(conn.conn, packetPubAck, body[offset-2:offset])
+}
+
+// decode convierte el mensaje en una medición del tanque de su tema. Sin marca de tiempo, la
+// medición lleva la de su recepción, no la de su guardado, que puede retrasarse.
+func (s *Subscriber) decode(topic string, payload []byte) (*domain.Measurement, error) {
+	tankID := tankIDFromTopic(s.topic, topic)
+	if tankID == "" {
+		return nil, errors.New("topic does not match the measurement topic")
+	}
+
+	var measurement domain.Measurement
+	if err := json.Unmarshal(payload, &measurement); err != nil {
+		return nil, err
+	}
+	measurement.TankID = tankID
+	if measurement.ID == "" {
+		measurement.ID = uuid.New().String()
+	}
+	if measurement.Timestamp.IsZero() {
+		measurement.Timestamp = time.Now()
+	}
+	return &measurement, nil
+}
+
+// store guarda las mediciones del búfer cada vez que llegan nuevas, hasta que se cancele ctx. Si el
+// almacenamiento falla, reintenta con espera exponencial.
+func (s *Subscriber) store(ctx context.Context) {
+	var backoff time.Duration
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+			if retry != nil {
+				continue
+			}
+		case <-retry:
+		}
+
+		retry = nil
+		if err := s.flush(ctx); err != nil {
+			backoff = min(max(2*backoff, subscriberMinBackoff), subscriberMaxBackoff)
+			s.logger.Warn("Failed to store MQTT measurements, will retry", "retry_in", backoff, "error", err)
+			retry = time.After(backoff)
+			continue
+		}
+		backoff = 0
+	}
+}
+
+// flush guarda las mediciones del búfer en orden y las quita de él. Las que nunca podrán guardarse
+// se descartan; ante cualquier otro error, la medición y las siguientes se conservan.
+func (s *Subscriber) flush(ctx context.Context) error {
+	for {
+		measurements, err := s.buffer.GetQueuedMeasurements(ctx, subscriberBatchSize)
+		if err != nil || len(measurements) == 0 {
+			return err
+		}
+
+		ids := make([]string, 0, len(measurements))
+		var storeErr error
+		for _, measurement := range measurements {
+			if err := s.tanks.AddMeasurement(ctx, measurement); err != nil && !services.IsStaleMeasurement(err) {
+				if !services.IsPermanentRejection(err) {
+					storeErr = err
+					break
+				}
+				s.logger.Warn("Discarding rejected MQTT measurement", "tank_id", measurement.TankID, "error", err)
+			}
+			ids = append(ids, measurement.ID)
+		}
+		if err := s.buffer.DeleteQueuedMeasurements(ctx, ids); err != nil {
+			return err
+		}
+		if storeErr != nil {
+			return storeErr
+		}
+	}
+}
+
+// notify despierta a store sin bloquear
+func (s *Subscriber) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// subscriptionFilter convierte el tema de las mediciones en el filtro de la suscripción
+func subscriptionFilter(topic string) string {
+	return strings.ReplaceAll(topic, "{id}", "+")
+}
+
+// tankIDFromTopic devuelve el segmento de topic que corresponde a {id} en pattern, o "" si topic
+// no sigue el patrón
+func tankIDFromTopic(pattern, topic string) string {
+	patternSegments := strings.Split(pattern, "/")
+	topicSegments := strings.Split(topic, "/")
+	if len(patternSegments) != len(topicSegments) {
+		return ""
+	}
+
+	tankID := ""
+	for i, segment := range patternSegments {
+		switch {
+		case segment == "{id}":
+			tankID = topicSegments[i]
+		case segment != topicSegments[i]:
+			return ""
+		}
+	}
+	return tankID
+}
+
+// containsSegment indica si segment es uno de los segmentos del tema
+func containsSegment(topic, segment string) bool {
+	for _, s := range strings.Split(topic, "/") {
+		if s == segment {
+			return true
+		}
+	}
+	return false
+}
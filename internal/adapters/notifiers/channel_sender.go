@@ -0,0 +1,159 @@
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/i18n"
+	"monitor-tanques/pkg/logger"
+	"monitor-tanques/pkg/webhooksig"
+)
+
+// ChannelSender implementa ports.ChannelSender entregando las alertas según el tipo de canal
+type ChannelSender struct {
+	client        *http.Client
+	push          ports.AlertNotifier
+	email         ports.EmailSender
+	signingSecret string
+	logger        logger.Logger
+}
+
+// NewChannelSender crea un nuevo emisor de alertas por canal. push entrega las alertas de los
+// canales de tipo push y email las de los canales de correo; cualquiera de los dos puede ser nil
+// si no está configurado. signingSecret firma las entregas de los webhooks que no tienen secreto
+// propio; vacío, se envían sin firmar.
+func NewChannelSender(push ports.AlertNotifier, email ports.EmailSender, signingSecret string, logger logger.Logger) *ChannelSender {
+	return &ChannelSender{
+		client:        &http.Client{Timeout: 10 * time.Second},
+		push:          push,
+		email:         email,
+		signingSecret: signingSecret,
+		logger:        logger,
+	}
+}
+
+// slackSeverityPrefixes destacan la severidad al inicio del mensaje de Slack
+var slackSeverityPrefixes = map[string]string{
+	domain.AlertSeverityInfo:     ":information_source:",
+	domain.AlertSeverityWarning:  ":warning:",
+	domain.AlertSeverityCritical: ":rotating_light:",
+}
+
+// Send entrega la alerta a través del canal indicado, con el formato propio del canal y en su idioma
+func (s *ChannelSender) Send(ctx context.Context, channel *domain.NotificationChannel, alert *domain.Alert) error {
+	alert = localizedAlert(alert, channel.Language)
+
+	switch channel.Type {
+	case domain.ChannelTypeLog:
+		if alert.Type == domain.AlertEventDailyDigest {
+			s.logger.Info("RESUMEN", "channel", channel.Name, "tanks", len(alert.Digest.Tanks), "message", alert.Message)
+			return nil
+		}
+		keysAndValues := []interface{}{"channel", channel.Name, "tank_id", alert.TankID, "type", alert.Type, "severity", alert.Severity, "message", alert.Message}
+		if alert.Severity == domain.AlertSeverityCritical {
+			s.logger.Error("ALERTA", keysAndValues...)
+		} else {
+			s.logger.Warn("ALERTA", keysAndValues...)
+		}
+		return nil
+	case domain.ChannelTypeWebhook:
+		// Los webhooks reciben la alerta completa, incluido el estado del tanque, para que el
+		// receptor decida cómo procesarla
+		secret := channel.Secret
+		if secret == "" {
+			secret = s.signingSecret
+		}
+		return s.postJSON(ctx, channel.Target, alert, secret)
+	case domain.ChannelTypeSlack:
+		// Los webhooks entrantes de Slack esperan el texto en el campo "text"
+		text := alert.Message
+		if alert.Severity == domain.AlertSeverityCritical {
+			text = "*" + i18n.Translate(channel.Language, "CRÍTICO") + "* " + text
+		}
+		if prefix, ok := slackSeverityPrefixes[alert.Severity]; ok {
+			text = prefix + " " + text
+		}
+		return s.postJSON(ctx, channel.Target, map[string]string{
+			"text": text,
+		}, "")
+	case domain.ChannelTypePush:
+		if s.push == nil {
+			return errors.New("push notifications are not configured")
+		}
+		return s.push.Notify(ctx, alert)
+	case domain.ChannelTypeEmail:
+		if s.email == nil {
+			return errors.New("email is not configured")
+		}
+		// La primera línea del mensaje sirve de asunto; las alertas solo tienen una
+		subject, _, _ := strings.Cut(alert.Message, "\n")
+		if alert.Severity == domain.AlertSeverityCritical && alert.Type != domain.AlertEventDailyDigest {
+			subject = i18n.Translate(channel.Language, "CRÍTICO") + ": " + subject
+		}
+		return s.email.SendEmail(ctx, emailRecipients(channel.Target), subject, alert.Message)
+	default:
+		return fmt.Errorf("unsupported channel type %q", channel.Type)
+	}
+}
+
+// emailRecipients separa las direcciones del destino de un canal de correo
+func emailRecipients(target string) []string {
+	recipients := make([]string, 0)
+	for _, address := range strings.Split(target, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			recipients = append(recipients, address)
+		}
+	}
+	return recipients
+}
+
+// localizedAlert devuelve una copia de la alerta con el mensaje compuesto en el idioma indicado.
+// Las alertas sin formato (p. ej. encoladas por una versión anterior) conservan su mensaje.
+func localizedAlert(alert *domain.Alert, language string) *domain.Alert {
+	if alert.MessageFormat == "" || language == "" || language == i18n.DefaultLanguage {
+		return alert
+	}
+
+	localized := *alert
+	localized.Message = i18n.Sprintf(language, alert.MessageFormat, alert.MessageArgs...)
+	return &localized
+}
+
+// postJSON envía el cuerpo como JSON a la URL indicada, firmado con secret si no está vacío
+func (s *ChannelSender) postJSON(ctx context.Context, url string, body interface{}, secret string) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		webhooksig.SignRequest(req, []byte(secret), uuid.New().String(), time.Now(), payload)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification endpoint returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
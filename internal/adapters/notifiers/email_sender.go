@@ -0,0 +1,80 @@
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig contiene los datos del servidor de correo saliente
+type SMTPConfig struct {
+	Addr     string // host:puerto del servidor SMTP
+	Username string // Vacío si el servidor no exige autenticación
+	Password string
+	From     string // Remitente de los correos
+}
+
+// EmailSender implementa ports.EmailSender sobre SMTP, con STARTTLS si el servidor lo ofrece
+type EmailSender struct {
+	config SMTPConfig
+}
+
+// NewEmailSender crea un emisor de correo para el servidor configurado
+func NewEmailSender(config SMTPConfig) (*EmailSender, error) {
+	if _, _, err := net.SplitHostPort(config.Addr); err != nil {
+		return nil, fmt.Errorf("smtp address: %w", err)
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("smtp from: %w", err)
+	}
+	return &EmailSender{config: config}, nil
+}
+
+// SendEmail envía un correo de texto plano en UTF-8 a los destinatarios
+func (s *EmailSender) SendEmail(ctx context.Context, to []string, subject, body string) error {
+	if len(to) == 0 {
+		return errors.New("no recipients")
+	}
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		host, _, _ := net.SplitHostPort(s.config.Addr)
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, host)
+	}
+
+	message := s.message(to, subject, body)
+
+	// net/smtp no acepta contexto: respetamos la cancelación sin esperar al envío
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.config.Addr, auth, s.config.From, to, message)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// message compone las cabeceras y el cuerpo del correo con saltos de línea CRLF
+func (s *EmailSender) message(to []string, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + s.config.From + "\r\n")
+	b.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
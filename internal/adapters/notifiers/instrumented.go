@@ -0,0 +1,128 @@
+package notifiers
+
+import (
+	"context"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/metrics"
+)
+
+// DefaultChannel es la etiqueta de canal de las alertas entregadas por el notificador
+// predeterminado, que se usa cuando no hay canales configurados
+const DefaultChannel = "default"
+
+// AlertMetrics agrupa las métricas de la entrega de alertas. Se etiquetan por canal para
+// detectar cuándo un proveedor empieza a fallar o a responder con lentitud.
+type AlertMetrics struct {
+	Queued    *metrics.CounterVec   // Alertas aplazadas por estar fuera del horario del canal
+	Delivered *metrics.CounterVec   // Alertas entregadas
+	Failed    *metrics.CounterVec   // Entregas fallidas
+	Latency   *metrics.HistogramVec // Duración de cada intento de entrega
+}
+
+// NewAlertMetrics registra las métricas de la entrega de alertas
+func NewAlertMetrics(registry *metrics.Registry) *AlertMetrics {
+	return &AlertMetrics{
+		Queued: registry.NewCounterVec(
+			"tank_alerts_queued_total",
+			"Alertas encoladas hasta la siguiente ventana de entrega del canal.",
+			"channel",
+		),
+		Delivered: registry.NewCounterVec(
+			"tank_alerts_delivered_total",
+			"Alertas entregadas por canal.",
+			"channel", "type",
+		),
+		Failed: registry.NewCounterVec(
+			"tank_alerts_failed_total",
+			"Entregas de alertas fallidas por canal.",
+			"channel", "type",
+		),
+		Latency: registry.NewHistogramVec(
+			"tank_alert_delivery_seconds",
+			"Duración de los intentos de entrega de alertas por canal.",
+			metrics.DefaultLatencyBuckets,
+			"channel", "type",
+		),
+	}
+}
+
+// observe registra el resultado y la duración de un intento de entrega
+func (m *AlertMetrics) observe(channel, channelType string, started time.Time, err error) {
+	m.Latency.Observe(time.Since(started).Seconds(), channel, channelType)
+	if err != nil {
+		m.Failed.Inc(channel, channelType)
+		return
+	}
+	m.Delivered.Inc(channel, channelType)
+}
+
+// InstrumentedChannelSender decora un ports.ChannelSender con las métricas de entrega
+type InstrumentedChannelSender struct {
+	next    ports.ChannelSender
+	metrics *AlertMetrics
+}
+
+// NewInstrumentedChannelSender crea un emisor que mide cada entrega de next
+func NewInstrumentedChannelSender(next ports.ChannelSender, alertMetrics *AlertMetrics) *InstrumentedChannelSender {
+	return &InstrumentedChannelSender{next: next, metrics: alertMetrics}
+}
+
+// Send entrega la alerta y registra su resultado y su latencia
+func (s *InstrumentedChannelSender) Send(ctx context.Context, channel *domain.NotificationChannel, alert *domain.Alert) error {
+	started := time.Now()
+	err := s.next.Send(ctx, channel, alert)
+	s.metrics.observe(channelLabel(channel), channel.Type, started, err)
+	return err
+}
+
+// InstrumentedAlertNotifier decora el notificador predeterminado con las métricas de entrega
+type InstrumentedAlertNotifier struct {
+	next        ports.AlertNotifier
+	channelType string
+	metrics     *AlertMetrics
+}
+
+// NewInstrumentedAlertNotifier crea un notificador que mide cada entrega de next. channelType
+// identifica el notificador configurado (log, webhook...).
+func NewInstrumentedAlertNotifier(next ports.AlertNotifier, channelType string, alertMetrics *AlertMetrics) *InstrumentedAlertNotifier {
+	return &InstrumentedAlertNotifier{next: next, channelType: channelType, metrics: alertMetrics}
+}
+
+// Notify entrega la alerta y registra su resultado y su latencia
+func (n *InstrumentedAlertNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	started := time.Now()
+	err := n.next.Notify(ctx, alert)
+	n.metrics.observe(DefaultChannel, n.channelType, started, err)
+	return err
+}
+
+// InstrumentedAlertQueue decora la cola de alertas aplazadas contando las alertas encoladas
+type InstrumentedAlertQueue struct {
+	ports.AlertQueueRepository
+	metrics *AlertMetrics
+}
+
+// NewInstrumentedAlertQueue crea una cola que cuenta las alertas que se encolan en next
+func NewInstrumentedAlertQueue(next ports.AlertQueueRepository, alertMetrics *AlertMetrics) *InstrumentedAlertQueue {
+	return &InstrumentedAlertQueue{AlertQueueRepository: next, metrics: alertMetrics}
+}
+
+// EnqueueAlert encola la alerta y la cuenta si se guardó
+func (q *InstrumentedAlertQueue) EnqueueAlert(ctx context.Context, alert *domain.QueuedAlert) error {
+	if err := q.AlertQueueRepository.EnqueueAlert(ctx, alert); err != nil {
+		return err
+	}
+	q.metrics.Queued.Inc(alert.ChannelID)
+	return nil
+}
+
+// channelLabel identifica el canal por su ID, que no cambia al renombrarlo
+func channelLabel(channel *domain.NotificationChannel) string {
+	if channel.ID == "" {
+		return DefaultChannel
+	}
+	return channel.ID
+}
@@ -0,0 +1,415 @@
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/pkg/i18n"
+)
+
+// pushTitle es el título de las notificaciones push de alerta
+const pushTitle = "Alerta de tanque"
+
+// PushConfig contiene las credenciales de los proveedores push. Un proveedor sin credenciales
+// queda deshabilitado y sus dispositivos no reciben notificaciones.
+type PushConfig struct {
+	FCMCredentialsFile string // JSON de la cuenta de servicio de Firebase
+
+	APNsKeyFile string // Clave .p8 de autenticación por token
+	APNsKeyID   string
+	APNsTeamID  string
+	APNsTopic   string // Bundle ID de la aplicación
+	APNsSandbox bool
+}
+
+// PushSender implementa ports.PushSender sobre FCM HTTP v1 y APNs (autenticación por token)
+type PushSender struct {
+	client *http.Client
+	fcm    *fcmClient
+	apns   *apnsClient
+}
+
+// NewPushSender crea un emisor push cargando las credenciales configuradas
+func NewPushSender(config PushConfig) (*PushSender, error) {
+	sender := &PushSender{client: &http.Client{Timeout: 10 * time.Second}}
+
+	if config.FCMCredentialsFile != "" {
+		fcm, err := newFCMClient(config.FCMCredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("fcm credentials: %w", err)
+		}
+		sender.fcm = fcm
+	}
+
+	if config.APNsKeyFile != "" {
+		apns, err := newAPNsClient(config)
+		if err != nil {
+			return nil, fmt.Errorf("apns key: %w", err)
+		}
+		sender.apns = apns
+	}
+
+	return sender, nil
+}
+
+// Push envía la alerta al dispositivo a través de su proveedor, en el idioma del dispositivo
+func (s *PushSender) Push(ctx context.Context, device *domain.MobileDevice, alert *domain.Alert) error {
+	alert = localizedAlert(alert, device.Language)
+	title := i18n.Translate(device.Language, pushTitle)
+
+	switch device.Platform {
+	case domain.PushPlatformFCM:
+		if s.fcm == nil {
+			return errors.New("fcm is not configured")
+		}
+		return s.fcm.send(ctx, s.client, device.Token, alert.TankID, title, alert.Message)
+	case domain.PushPlatformAPNs:
+		if s.apns == nil {
+			return errors.New("apns is not configured")
+		}
+		return s.apns.send(ctx, s.client, device.Token, alert.TankID, title, alert.Message)
+	default:
+		return fmt.Errorf("unsupported push platform %q", device.Platform)
+	}
+}
+
+// fcmClient envía mensajes con la API HTTP v1 de Firebase Cloud Messaging
+type fcmClient struct {
+	projectID   string
+	clientEmail string
+	tokenURI    string
+	key         *rsa.PrivateKey
+
+	mutex       sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// newFCMClient carga la cuenta de servicio de Firebase
+func newFCMClient(path string) (*fcmClient, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var account struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, err
+	}
+
+	parsed, err := parsePrivateKey([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("service account key is not RSA")
+	}
+
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	return &fcmClient{
+		projectID:   account.ProjectID,
+		clientEmail: account.ClientEmail,
+		tokenURI:    account.TokenURI,
+		key:         key,
+	}, nil
+}
+
+// send envía la notificación al token de registro indicado
+func (c *fcmClient) send(ctx context.Context, client *http.Client, token, tankID, title, message string) error {
+	accessToken, err := c.token(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        token,
+			"notification": map[string]string{"title": title, "body": message},
+			"data":         map[string]string{"tank_id": tankID},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := "https://fcm.googleapis.com/v1/projects/" + c.projectID + "/messages:send"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		// FCM responde 404 UNREGISTERED cuando el token ya no es válido
+		return domain.ErrDeviceUnregistered
+	case resp.StatusCode >= 300:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("fcm returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	return nil
+}
+
+// token obtiene (y cachea) un token de acceso OAuth2 firmando una aserción JWT con la cuenta de servicio
+func (c *fcmClient) token(ctx context.Context, client *http.Client) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.accessToken != "" && time.Now().Before(c.expiresAt) {
+		return c.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := signJWT(map[string]interface{}{"alg": "RS256", "typ": "JWT"}, map[string]interface{}{
+		"iss":   c.clientEmail,
+		"scope": "https://www.googleapis.com/auth/firebase.messaging",
+		"aud":   c.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}, c.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fcm token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", err
+	}
+
+	// Renovamos el token un minuto antes de que caduque
+	c.accessToken = tokenResponse.AccessToken
+	c.expiresAt = now.Add(time.Duration(tokenResponse.ExpiresIn)*time.Second - time.Minute)
+
+	return c.accessToken, nil
+}
+
+// apnsClient envía notificaciones a Apple Push Notification service con autenticación por token
+type apnsClient struct {
+	host   string
+	keyID  string
+	teamID string
+	topic  string
+	key    *ecdsa.PrivateKey
+
+	mutex    sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+// newAPNsClient carga la clave .p8 de APNs
+func newAPNsClient(config PushConfig) (*apnsClient, error) {
+	data, err := os.ReadFile(config.APNsKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("apns key is not ECDSA")
+	}
+
+	host := "https://api.push.apple.com"
+	if config.APNsSandbox {
+		host = "https://api.sandbox.push.apple.com"
+	}
+
+	return &apnsClient{
+		host:   host,
+		keyID:  config.APNsKeyID,
+		teamID: config.APNsTeamID,
+		topic:  config.APNsTopic,
+		key:    key,
+	}, nil
+}
+
+// send envía la notificación al token de dispositivo indicado. APNs requiere HTTP/2, que el
+// cliente HTTP de Go negocia automáticamente sobre TLS.
+func (c *apnsClient) send(ctx context.Context, client *http.Client, token, tankID, title, message string) error {
+	bearer, err := c.token()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": title, "body": message},
+			"sound": "default",
+		},
+		"tank_id": tankID,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.host+"/3/device/"+token, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+bearer)
+	req.Header.Set("apns-topic", c.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusGone:
+		// APNs responde 410 cuando el token ya no está activo para el topic
+		return domain.ErrDeviceUnregistered
+	case resp.StatusCode >= 300:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("apns returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	return nil
+}
+
+// token devuelve el JWT de proveedor. Apple exige renovarlo como mucho cada hora y rechaza
+// renovaciones más frecuentes que cada 20 minutos, por lo que se reutiliza durante 50 minutos.
+func (c *apnsClient) token() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.jwt != "" && time.Since(c.issuedAt) < 50*time.Minute {
+		return c.jwt, nil
+	}
+
+	now := time.Now()
+	jwt, err := signJWT(
+		map[string]interface{}{"alg": "ES256", "kid": c.keyID},
+		map[string]interface{}{"iss": c.teamID, "iat": now.Unix()},
+		c.key,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	c.jwt = jwt
+	c.issuedAt = now
+	return jwt, nil
+}
+
+// signJWT firma un JWT compacto con RS256 o ES256 según el tipo de clave
+func signJWT(header, claims map[string]interface{}, key crypto.Signer) (string, error) {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." +
+		base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		// JWS exige la firma ECDSA como r||s de longitud fija, no en DER
+		r, s, signErr := ecdsa.Sign(rand.Reader, k, digest[:])
+		err = signErr
+		if err == nil {
+			size := (k.Curve.Params().BitSize + 7) / 8
+			signature = make([]byte, 2*size)
+			r.FillBytes(signature[:size])
+			s.FillBytes(signature[size:])
+		}
+	default:
+		return "", errors.New("unsupported signing key")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey decodifica una clave privada PEM en formato PKCS#8
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return signer, nil
+}
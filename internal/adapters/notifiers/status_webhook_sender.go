@@ -0,0 +1,78 @@
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/pkg/logger"
+	"monitor-tanques/pkg/webhooksig"
+)
+
+// statusWebhookTimeout limita cada entrega para que un receptor lento no acumule envíos
+const statusWebhookTimeout = 10 * time.Second
+
+// StatusWebhookSender implementa ports.StatusWebhookSender con un POST JSON por evento. Los envíos
+// no bloquean la ingesta de mediciones: se hacen en segundo plano y sus fallos solo se registran.
+type StatusWebhookSender struct {
+	client        *http.Client
+	signingSecret string
+	logger        logger.Logger
+}
+
+// NewStatusWebhookSender crea un nuevo emisor de los webhooks de estado de los tanques. Con
+// signingSecret, cada envío se firma (ver pkg/webhooksig).
+func NewStatusWebhookSender(signingSecret string, logger logger.Logger) *StatusWebhookSender {
+	return &StatusWebhookSender{
+		client:        &http.Client{Timeout: statusWebhookTimeout},
+		signingSecret: signingSecret,
+		logger:        logger,
+	}
+}
+
+// SendStatusWebhook envía el evento a la URL sin esperar la respuesta
+func (s *StatusWebhookSender) SendStatusWebhook(ctx context.Context, url string, event *domain.StatusWebhookEvent) {
+	// La entrega sigue aunque termine la solicitud que provocó el cambio de estado
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := s.post(ctx, url, event); err != nil {
+			s.logger.Warn("Status webhook failed", "tank_id", event.TankID, "status", event.NewStatus, "error", err)
+			return
+		}
+		s.logger.Debug("Status webhook delivered", "tank_id", event.TankID, "status", event.NewStatus)
+	}()
+}
+
+// post envía el evento como JSON
+func (s *StatusWebhookSender) post(ctx context.Context, url string, event *domain.StatusWebhookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.signingSecret != "" {
+		webhooksig.SignRequest(req, []byte(s.signingSecret), uuid.New().String(), time.Now(), payload)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
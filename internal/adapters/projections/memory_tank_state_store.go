@@ -0,0 +1,71 @@
+package projections
+
+import (
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// stateEntry es una proyección junto con el momento en que se cargó
+type stateEntry struct {
+	state    *domain.TankState
+	loadedAt time.Time
+}
+
+// MemoryTankStateStore implementa ports.TankStateStore en memoria. Con maxAge > 0 las
+// proyecciones vencen y se vuelven a cargar, lo que permite ver las mediciones que guardan
+// otras réplicas de la API.
+type MemoryTankStateStore struct {
+	maxAge  time.Duration
+	entries map[string]stateEntry
+	mutex   sync.RWMutex
+}
+
+// NewMemoryTankStateStore crea el modelo de lectura; maxAge 0 conserva las proyecciones hasta
+// la siguiente medición
+func NewMemoryTankStateStore(maxAge time.Duration) *MemoryTankStateStore {
+	return &MemoryTankStateStore{
+		maxAge:  maxAge,
+		entries: make(map[string]stateEntry),
+	}
+}
+
+// Get devuelve una copia de la proyección del tanque
+func (s *MemoryTankStateStore) Get(tankID string) (*domain.TankState, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entry, ok := s.entries[tankID]
+	if !ok || (s.maxAge > 0 && time.Since(entry.loadedAt) > s.maxAge) {
+		return nil, false
+	}
+
+	state := *entry.state
+	return &state, true
+}
+
+// Put guarda la proyección salvo que la existente, aún vigente, refleje una medición más
+// reciente: una carga concurrente con datos anteriores no deshace una medición ya aplicada
+func (s *MemoryTankStateStore) Put(state *domain.TankState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if entry, ok := s.entries[state.TankID]; ok && entry.state.NewerThan(state) {
+		if s.maxAge <= 0 || now.Sub(entry.loadedAt) <= s.maxAge {
+			return
+		}
+	}
+
+	stored := *state
+	s.entries[state.TankID] = stateEntry{state: &stored, loadedAt: now}
+}
+
+// Remove descarta la proyección del tanque
+func (s *MemoryTankStateStore) Remove(tankID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.entries, tankID)
+}
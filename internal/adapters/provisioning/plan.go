@@ -0,0 +1,41 @@
+package provisioning
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// planSymbols marcan cada acción en el plan legible, al estilo de terraform plan
+var planSymbols = map[string]string{
+	domain.ProvisioningActionCreate: "+",
+	domain.ProvisioningActionUpdate: "~",
+}
+
+// WritePlan escribe los cambios del resultado en formato legible, uno por línea, seguidos del
+// resumen. Los recursos sin cambios solo se cuentan.
+func WritePlan(w io.Writer, result *domain.ProvisioningResult) error {
+	for _, change := range result.Changes {
+		symbol, ok := planSymbols[change.Action]
+		if !ok {
+			continue
+		}
+
+		line := fmt.Sprintf("%s %s %s", symbol, change.Kind, change.ID)
+		if len(change.Fields) > 0 {
+			line += " (" + strings.Join(change.Fields, ", ") + ")"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	if result.DryRun {
+		_, err := fmt.Fprintf(w, "Plan: %d to create, %d to update, %d unchanged.\n", result.Created, result.Updated, result.Unchanged)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Applied: %d created, %d updated, %d unchanged.\n", result.Created, result.Updated, result.Unchanged)
+	return err
+}
@@ -0,0 +1,189 @@
+package provisioning
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrInvalidSpec se devuelve cuando el archivo de aprovisionamiento no es válido
+var ErrInvalidSpec = errors.New("invalid provisioning file")
+
+// fileSpec es el formato del archivo de aprovisionamiento: los tanques se declaran dentro de su
+// sitio y los sensores dentro de su tanque
+type fileSpec struct {
+	Sites      []siteSpec      `yaml:"sites"`
+	AlertRules []alertRuleSpec `yaml:"alert_rules"`
+}
+
+type siteSpec struct {
+	ID       string     `yaml:"id"`
+	Timezone string     `yaml:"timezone"` // Zona horaria de los tanques del sitio que no indican otra
+	Tanks    []tankSpec `yaml:"tanks"`
+}
+
+type tankSpec struct {
+	ID             string            `yaml:"id"`
+	Name           string            `yaml:"name"`
+	GroupID        string            `yaml:"group_id"`
+	Labels         map[string]string `yaml:"labels"`
+	Capacity       float64           `yaml:"capacity"`
+	LiquidType     string            `yaml:"liquid_type"`
+	AlertThreshold float64           `yaml:"alert_threshold"`
+	Location       *locationSpec     `yaml:"location"`
+	Reorder        reorderSpec       `yaml:"reorder"`
+	Timezone       string            `yaml:"timezone"`
+	Sensors        []sensorSpec      `yaml:"sensors"`
+}
+
+type locationSpec struct {
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+}
+
+type reorderSpec struct {
+	ReorderLevel float64 `yaml:"reorder_level"`
+	LeadTimeDays float64 `yaml:"lead_time_days"`
+	DeliverySize float64 `yaml:"delivery_size"`
+}
+
+type sensorSpec struct {
+	ID                 string  `yaml:"id"`
+	ReportingInterval  int     `yaml:"reporting_interval"`
+	LowLevelThreshold  float64 `yaml:"low_level_threshold"`
+	HighLevelThreshold float64 `yaml:"high_level_threshold"`
+	ReportOnChange     float64 `yaml:"report_on_change"`
+}
+
+type alertRuleSpec struct {
+	ID                string       `yaml:"id"`
+	Name              string       `yaml:"name"`
+	Type              string       `yaml:"type"`
+	Target            string       `yaml:"target"`
+	Enabled           *bool        `yaml:"enabled"` // Por defecto, true
+	Schedule          scheduleSpec `yaml:"schedule"`
+	OutOfSchedule     string       `yaml:"out_of_schedule"`
+	FallbackChannelID string       `yaml:"fallback_channel_id"`
+	Language          string       `yaml:"language"`
+	TankSelector      string       `yaml:"tank_selector"`
+}
+
+type scheduleSpec struct {
+	Timezone string       `yaml:"timezone"`
+	Windows  []windowSpec `yaml:"windows"`
+}
+
+type windowSpec struct {
+	Days  []string `yaml:"days"`
+	Start string   `yaml:"start"`
+	End   string   `yaml:"end"`
+}
+
+// LoadFile lee el archivo de aprovisionamiento indicado
+func LoadFile(path string) (*domain.ProvisioningSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse interpreta un documento YAML de aprovisionamiento. Los campos desconocidos se rechazan
+// para que una errata no pase inadvertida.
+func Parse(r io.Reader) (*domain.ProvisioningSpec, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file fileSpec
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSpec, err)
+	}
+
+	spec := &domain.ProvisioningSpec{}
+	for _, site := range file.Sites {
+		if site.ID == "" {
+			return nil, fmt.Errorf("%w: site without id", ErrInvalidSpec)
+		}
+
+		for _, t := range site.Tanks {
+			if t.ID == "" {
+				return nil, fmt.Errorf("%w: tank without id in site %q", ErrInvalidSpec, site.ID)
+			}
+
+			tank := &domain.Tank{
+				ID:             t.ID,
+				Name:           t.Name,
+				SiteID:         site.ID,
+				GroupID:        t.GroupID,
+				Labels:         t.Labels,
+				Capacity:       t.Capacity,
+				LiquidType:     t.LiquidType,
+				AlertThreshold: t.AlertThreshold,
+				Reorder: domain.ReorderConfig{
+					ReorderLevel: t.Reorder.ReorderLevel,
+					LeadTimeDays: t.Reorder.LeadTimeDays,
+					DeliverySize: t.Reorder.DeliverySize,
+				},
+			}
+			if tank.AlertThreshold == 0 {
+				tank.AlertThreshold = domain.DefaultAlertThreshold
+			}
+			tank.Timezone = t.Timezone
+			if tank.Timezone == "" {
+				tank.Timezone = site.Timezone
+			}
+			if t.Location != nil {
+				tank.Location = &domain.GeoLocation{Latitude: t.Location.Latitude, Longitude: t.Location.Longitude}
+			}
+			spec.Tanks = append(spec.Tanks, tank)
+
+			for _, s := range t.Sensors {
+				spec.Sensors = append(spec.Sensors, &domain.FieldDevice{
+					ID:     s.ID,
+					TankID: t.ID,
+					Desired: domain.DeviceConfig{
+						ReportingInterval:  s.ReportingInterval,
+						LowLevelThreshold:  s.LowLevelThreshold,
+						HighLevelThreshold: s.HighLevelThreshold,
+						ReportOnChange:     s.ReportOnChange,
+					},
+				})
+			}
+		}
+	}
+
+	for _, rule := range file.AlertRules {
+		channel := &domain.NotificationChannel{
+			ID:                rule.ID,
+			Name:              rule.Name,
+			Type:              rule.Type,
+			Target:            rule.Target,
+			Enabled:           rule.Enabled == nil || *rule.Enabled,
+			Schedule:          domain.NotificationSchedule{Timezone: rule.Schedule.Timezone},
+			OutOfSchedule:     rule.OutOfSchedule,
+			FallbackChannelID: rule.FallbackChannelID,
+			Language:          rule.Language,
+			TankSelector:      rule.TankSelector,
+		}
+		if channel.OutOfSchedule == "" {
+			channel.OutOfSchedule = domain.OutOfScheduleQueue
+		}
+		for _, window := range rule.Schedule.Windows {
+			channel.Schedule.Windows = append(channel.Schedule.Windows, domain.ScheduleWindow{
+				Days:  window.Days,
+				Start: window.Start,
+				End:   window.End,
+			})
+		}
+		spec.AlertRules = append(spec.AlertRules, channel)
+	}
+
+	return spec, nil
+}
@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// syncQueueJournalEntry es un registro del diario de la cola: un alta o una baja de mediciones
+type syncQueueJournalEntry struct {
+	Enqueue []*domain.Measurement `json:"enqueue,omitempty"`
+	Delete  []string              `json:"delete,omitempty"`
+}
+
+// FileSyncQueueRepository implementa en un diario de solo anexado, una línea JSON por alta o baja,
+// una cola durable de mediciones: la de una pasarela edge o el búfer de las recibidas por MQTT.
+// Cada operación se escribe y se sincroniza con el disco antes de confirmarse, así que la cola
+// sobrevive a un reinicio o a un corte de luz sin depender de las instantáneas. El diario se
+// reescribe con la cola actual cuando queda vacía o cuando las bajas registradas superan a las
+// mediciones pendientes.
+type FileSyncQueueRepository struct {
+	queue   *MemorySyncQueueRepository // Contenido de la cola, reconstruido del diario al abrirlo
+	path    string
+	file    *os.File
+	size    int64 // Bytes válidos del diario
+	deleted int   // Bajas registradas desde la última reescritura
+	mutex   sync.Mutex
+}
+
+// OpenFileSyncQueueRepository abre el diario de path, creándolo si no existe, y recupera la cola.
+// Una última línea incompleta, de una escritura interrumpida, se descarta.
+func OpenFileSyncQueueRepository(path string) (*FileSyncQueueRepository, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &FileSyncQueueRepository{
+		queue: NewMemorySyncQueueRepository(),
+		path:  path,
+		file:  file,
+	}
+	if err := r.replay(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("read sync queue journal %s: %w", path, err)
+	}
+	if err := file.Truncate(r.size); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(r.size, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// replay aplica a la cola los registros del diario en el orden en que se escribieron
+func (r *FileSyncQueueRepository) replay() error {
+	ctx := context.Background()
+	reader := bufio.NewReader(r.file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Lo que queda sin salto de línea es una escritura interrumpida
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var entry syncQueueJournalEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("entry at offset %d: %w", r.size, err)
+		}
+		if err := r.queue.EnqueueMeasurements(ctx, entry.Enqueue); err != nil {
+			return err
+		}
+		if err := r.queue.DeleteQueuedMeasurements(ctx, entry.Delete); err != nil {
+			return err
+		}
+		r.size += int64(len(line))
+		r.deleted += len(entry.Delete)
+	}
+}
+
+// EnqueueMeasurements registra las mediciones en el diario y las añade al final de la cola
+func (r *FileSyncQueueRepository) EnqueueMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(measurements) == 0 {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.append(syncQueueJournalEntry{Enqueue: measurements}); err != nil {
+		return err
+	}
+	return r.queue.EnqueueMeasurements(ctx, measurements)
+}
+
+// GetQueuedMeasurements devuelve copias de las primeras limit mediciones de la cola
+func (r *FileSyncQueueRepository) GetQueuedMeasurements(ctx context.Context, limit int) ([]*domain.Measurement, error) {
+	return r.queue.GetQueuedMeasurements(ctx, limit)
+}
+
+// DeleteQueuedMeasurements registra la baja en el diario y quita de la cola las mediciones con los
+// IDs indicados
+func (r *FileSyncQueueRepository) DeleteQueuedMeasurements(ctx context.Context, ids []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.append(syncQueueJournalEntry{Delete: ids}); err != nil {
+		return err
+	}
+	if err := r.queue.DeleteQueuedMeasurements(ctx, ids); err != nil {
+		return err
+	}
+	r.deleted += len(ids)
+
+	remaining, err := r.queue.CountQueuedMeasurements(ctx)
+	if err != nil {
+		return err
+	}
+	if remaining == 0 || r.deleted > remaining {
+		// La baja ya es durable: si la reescritura falla, el diario anterior sigue siendo válido
+		return r.compact(ctx)
+	}
+	return nil
+}
+
+// CountQueuedMeasurements devuelve el número de mediciones en la cola
+func (r *FileSyncQueueRepository) CountQueuedMeasurements(ctx context.Context) (int, error) {
+	return r.queue.CountQueuedMeasurements(ctx)
+}
+
+// Close cierra el diario. Todo lo confirmado ya está en el disco.
+func (r *FileSyncQueueRepository) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.file.Close()
+}
+
+// append escribe un registro al final del diario y lo sincroniza con el disco. Si la escritura
+// falla, el diario vuelve a su tamaño anterior para que no quede un registro a medias en medio.
+func (r *FileSyncQueueRepository) append(entry syncQueueJournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if _, err := r.file.Write(line); err != nil {
+		return errors.Join(err, r.rewind())
+	}
+	if err := r.file.Sync(); err != nil {
+		return errors.Join(err, r.rewind())
+	}
+	r.size += int64(len(line))
+	return nil
+}
+
+// rewind descarta lo escrito en el diario después del último registro confirmado
+func (r *FileSyncQueueRepository) rewind() error {
+	if err := r.file.Truncate(r.size); err != nil {
+		return err
+	}
+	_, err := r.file.Seek(r.size, io.SeekStart)
+	return err
+}
+
+// compact reemplaza el diario por un único alta con las mediciones pendientes. El nuevo diario se
+// escribe aparte y se renombra, así que un corte a mitad deja el anterior intacto.
+func (r *FileSyncQueueRepository) compact(ctx context.Context) error {
+	pending, err := r.queue.GetQueuedMeasurements(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	if len(pending) > 0 {
+		line, err := json.Marshal(syncQueueJournalEntry{Enqueue: pending})
+		if err != nil {
+			return err
+		}
+		buffer.Write(line)
+		buffer.WriteByte('\n')
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(buffer.Bytes()); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := os.Rename(temp.Name(), r.path); err != nil {
+		temp.Close()
+		return err
+	}
+
+	// El archivo renombrado es ahora el diario; se sigue escribiendo al final
+	r.file.Close()
+	r.file = temp
+	r.size = int64(buffer.Len())
+	r.deleted = 0
+	return nil
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrAccessGrantNotFound se devuelve cuando la concesión no existe en el repositorio
+var ErrAccessGrantNotFound = errors.New("access grant not found")
+
+// MemoryAccessGrantRepository implementa un repositorio de concesiones de acceso en memoria
+type MemoryAccessGrantRepository struct {
+	grants map[string]*domain.AccessGrant
+	mutex  sync.RWMutex
+}
+
+// NewMemoryAccessGrantRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryAccessGrantRepository() *MemoryAccessGrantRepository {
+	return &MemoryAccessGrantRepository{
+		grants: make(map[string]*domain.AccessGrant),
+	}
+}
+
+// SaveGrant guarda una nueva concesión
+func (r *MemoryAccessGrantRepository) SaveGrant(ctx context.Context, grant *domain.AccessGrant) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if grant == nil {
+		return errors.New("access grant cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	grantCopy := *grant
+	r.grants[grant.ID] = &grantCopy
+
+	return nil
+}
+
+// DeleteGrant elimina una concesión por su ID
+func (r *MemoryAccessGrantRepository) DeleteGrant(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.grants[id]; !exists {
+		return ErrAccessGrantNotFound
+	}
+
+	delete(r.grants, id)
+	return nil
+}
+
+// GetGrant obtiene una concesión por su ID, o nil si no existe
+func (r *MemoryAccessGrantRepository) GetGrant(ctx context.Context, id string) (*domain.AccessGrant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	grant, exists := r.grants[id]
+	if !exists {
+		return nil, nil
+	}
+
+	grantCopy := *grant
+	return &grantCopy, nil
+}
+
+// GetGrants obtiene las concesiones de un usuario, o todas si subject está vacío
+func (r *MemoryAccessGrantRepository) GetGrants(ctx context.Context, subject string) ([]*domain.AccessGrant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	grants := make([]*domain.AccessGrant, 0)
+	for _, grant := range r.grants {
+		if subject != "" && grant.Subject != subject {
+			continue
+		}
+		grantCopy := *grant
+		grants = append(grants, &grantCopy)
+	}
+
+	sort.Slice(grants, func(i, j int) bool {
+		return grants[i].CreatedAt.Before(grants[j].CreatedAt)
+	})
+
+	return grants, nil
+}
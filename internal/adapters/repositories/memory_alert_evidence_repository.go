@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryAlertEvidenceRepository implementa un repositorio de evidencias de alertas en memoria
+type MemoryAlertEvidenceRepository struct {
+	evidence map[string]*domain.AlertEvidence // clave: alertID
+	mutex    sync.RWMutex
+}
+
+// NewMemoryAlertEvidenceRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryAlertEvidenceRepository() *MemoryAlertEvidenceRepository {
+	return &MemoryAlertEvidenceRepository{
+		evidence: make(map[string]*domain.AlertEvidence),
+	}
+}
+
+// SaveEvidence guarda la evidencia de una alerta. La evidencia no se modifica después de
+// capturarla, así que se guarda tal cual.
+func (r *MemoryAlertEvidenceRepository) SaveEvidence(ctx context.Context, evidence *domain.AlertEvidence) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if evidence == nil || evidence.AlertID == "" {
+		return errors.New("alert evidence must have an alert ID")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.evidence[evidence.AlertID] = evidence
+	return nil
+}
+
+// GetEvidence obtiene la evidencia de la alerta, o nil si no tiene
+func (r *MemoryAlertEvidenceRepository) GetEvidence(ctx context.Context, alertID string) (*domain.AlertEvidence, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	evidence, ok := r.evidence[alertID]
+	if !ok {
+		return nil, nil
+	}
+	return evidence, nil
+}
+
+// PurgeTankData elimina la evidencia de las alertas del tanque y devuelve cuántos registros eliminó
+func (r *MemoryAlertEvidenceRepository) PurgeTankData(ctx context.Context, tankID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	removed := 0
+	for id, item := range r.evidence {
+		if item.TankID == tankID {
+			delete(r.evidence, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryAlertMuteRepository implementa un repositorio de silencios de alertas en memoria
+type MemoryAlertMuteRepository struct {
+	mutes map[string][]*domain.AlertMute // clave: tankID, valor: silencios en orden cronológico
+	mutex sync.RWMutex
+}
+
+// NewMemoryAlertMuteRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryAlertMuteRepository() *MemoryAlertMuteRepository {
+	return &MemoryAlertMuteRepository{
+		mutes: make(map[string][]*domain.AlertMute),
+	}
+}
+
+// SaveMute guarda un silencio nuevo o reemplaza el existente con el mismo ID
+func (r *MemoryAlertMuteRepository) SaveMute(ctx context.Context, mute *domain.AlertMute) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if mute == nil {
+		return errors.New("alert mute cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	muteCopy := *mute
+	mutes := r.mutes[mute.TankID]
+	for i, existing := range mutes {
+		if existing.ID == mute.ID {
+			mutes[i] = &muteCopy
+			return nil
+		}
+	}
+
+	mutes = append(mutes, &muteCopy)
+	sort.SliceStable(mutes, func(i, j int) bool {
+		return mutes[i].MutedAt.Before(mutes[j].MutedAt)
+	})
+	r.mutes[mute.TankID] = mutes
+
+	return nil
+}
+
+// GetMutes obtiene los silencios de un tanque en orden cronológico
+func (r *MemoryAlertMuteRepository) GetMutes(ctx context.Context, tankID string) ([]*domain.AlertMute, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	mutes := r.mutes[tankID]
+	copies := make([]*domain.AlertMute, len(mutes))
+	for i, mute := range mutes {
+		muteCopy := *mute
+		copies[i] = &muteCopy
+	}
+
+	return copies, nil
+}
+
+// PurgeTankData elimina los silencios de alertas del tanque y devuelve cuántos registros eliminó
+func (r *MemoryAlertMuteRepository) PurgeTankData(ctx context.Context, tankID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	removed := len(r.mutes[tankID])
+	delete(r.mutes, tankID)
+	return removed, nil
+}
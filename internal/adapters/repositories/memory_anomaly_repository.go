@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryAnomalyRepository implementa un repositorio de anomalías en memoria
+type MemoryAnomalyRepository struct {
+	anomalies map[string][]*domain.Anomaly // clave: tankID, valor: anomalías en orden cronológico
+	mutex     sync.RWMutex
+}
+
+// NewMemoryAnomalyRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryAnomalyRepository() *MemoryAnomalyRepository {
+	return &MemoryAnomalyRepository{
+		anomalies: make(map[string][]*domain.Anomaly),
+	}
+}
+
+// SaveAnomaly guarda una nueva anomalía
+func (r *MemoryAnomalyRepository) SaveAnomaly(ctx context.Context, anomaly *domain.Anomaly) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if anomaly == nil {
+		return errors.New("anomaly cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	anomalyCopy := *anomaly
+	anomalies := append(r.anomalies[anomaly.TankID], &anomalyCopy)
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return anomalies[i].DetectedAt.Before(anomalies[j].DetectedAt)
+	})
+
+	r.anomalies[anomaly.TankID] = anomalies
+
+	return nil
+}
+
+// GetAnomalies obtiene todas las anomalías de un tanque en orden cronológico
+func (r *MemoryAnomalyRepository) GetAnomalies(ctx context.Context, tankID string) ([]*domain.Anomaly, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	anomalies := r.anomalies[tankID]
+	copies := make([]*domain.Anomaly, len(anomalies))
+	for i, anomaly := range anomalies {
+		anomalyCopy := *anomaly
+		copies[i] = &anomalyCopy
+	}
+
+	return copies, nil
+}
+
+// PurgeTankData elimina las anomalías del tanque y devuelve cuántos registros eliminó
+func (r *MemoryAnomalyRepository) PurgeTankData(ctx context.Context, tankID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	removed := len(r.anomalies[tankID])
+	delete(r.anomalies, tankID)
+	return removed, nil
+}
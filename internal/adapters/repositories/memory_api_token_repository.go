@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryAPITokenRepository implementa un repositorio de tokens de la API en memoria
+type MemoryAPITokenRepository struct {
+	tokens map[string]*domain.APIToken // clave: resumen del token
+	mutex  sync.RWMutex
+}
+
+// NewMemoryAPITokenRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryAPITokenRepository() *MemoryAPITokenRepository {
+	return &MemoryAPITokenRepository{
+		tokens: make(map[string]*domain.APIToken),
+	}
+}
+
+// SaveToken guarda un token nuevo o reemplaza el que tenga el mismo resumen
+func (r *MemoryAPITokenRepository) SaveToken(ctx context.Context, token *domain.APIToken) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if token == nil || token.Hash == "" {
+		return errors.New("api token hash cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tokenCopy := *token
+	r.tokens[token.Hash] = &tokenCopy
+	return nil
+}
+
+// GetTokenByHash obtiene el token con el resumen indicado, o nil si no existe
+func (r *MemoryAPITokenRepository) GetTokenByHash(ctx context.Context, hash string) (*domain.APIToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	token, ok := r.tokens[hash]
+	if !ok {
+		return nil, nil
+	}
+	tokenCopy := *token
+	return &tokenCopy, nil
+}
+
+// GetTokens obtiene todos los tokens ordenados por fecha de creación
+func (r *MemoryAPITokenRepository) GetTokens(ctx context.Context) ([]*domain.APIToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	tokens := make([]*domain.APIToken, 0, len(r.tokens))
+	for _, token := range r.tokens {
+		tokenCopy := *token
+		tokens = append(tokens, &tokenCopy)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+// DeleteToken elimina el token con el ID indicado; devuelve false si no existe
+func (r *MemoryAPITokenRepository) DeleteToken(ctx context.Context, id string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for hash, token := range r.tokens {
+		if token.ID == id {
+			delete(r.tokens, hash)
+			return true, nil
+		}
+	}
+	return false, nil
+}
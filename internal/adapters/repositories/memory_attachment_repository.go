@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrAttachmentNotFound se devuelve cuando el adjunto no existe en el repositorio
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// MemoryAttachmentRepository implementa un repositorio de metadatos de adjuntos en memoria
+type MemoryAttachmentRepository struct {
+	attachments map[string]*domain.Attachment
+	mutex       sync.RWMutex
+}
+
+// NewMemoryAttachmentRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryAttachmentRepository() *MemoryAttachmentRepository {
+	return &MemoryAttachmentRepository{
+		attachments: make(map[string]*domain.Attachment),
+	}
+}
+
+// SaveAttachment guarda los metadatos de un adjunto
+func (r *MemoryAttachmentRepository) SaveAttachment(ctx context.Context, attachment *domain.Attachment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if attachment == nil {
+		return errors.New("attachment cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	attachmentCopy := *attachment
+	r.attachments[attachment.ID] = &attachmentCopy
+
+	return nil
+}
+
+// GetAttachment obtiene un adjunto por su ID, o nil si no existe
+func (r *MemoryAttachmentRepository) GetAttachment(ctx context.Context, id string) (*domain.Attachment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	attachment, exists := r.attachments[id]
+	if !exists {
+		return nil, nil
+	}
+
+	attachmentCopy := *attachment
+	return &attachmentCopy, nil
+}
+
+// GetAttachmentsByTankID obtiene los adjuntos de un tanque, del más reciente al más antiguo
+func (r *MemoryAttachmentRepository) GetAttachmentsByTankID(ctx context.Context, tankID string) ([]*domain.Attachment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	attachments := make([]*domain.Attachment, 0)
+	for _, attachment := range r.attachments {
+		if attachment.TankID == tankID {
+			attachmentCopy := *attachment
+			attachments = append(attachments, &attachmentCopy)
+		}
+	}
+
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].UploadedAt.After(attachments[j].UploadedAt)
+	})
+
+	return attachments, nil
+}
+
+// DeleteAttachment elimina los metadatos de un adjunto
+func (r *MemoryAttachmentRepository) DeleteAttachment(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.attachments[id]; !exists {
+		return ErrAttachmentNotFound
+	}
+
+	delete(r.attachments, id)
+	return nil
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrDeliveryOrderNotFound se devuelve cuando el pedido no existe en el repositorio
+var ErrDeliveryOrderNotFound = errors.New("delivery order not found")
+
+// MemoryDeliveryOrderRepository implementa un repositorio de pedidos de entrega en memoria
+type MemoryDeliveryOrderRepository struct {
+	orders map[string]*domain.DeliveryOrder
+	mutex  sync.RWMutex
+}
+
+// NewMemoryDeliveryOrderRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryDeliveryOrderRepository() *MemoryDeliveryOrderRepository {
+	return &MemoryDeliveryOrderRepository{
+		orders: make(map[string]*domain.DeliveryOrder),
+	}
+}
+
+// GetDeliveryOrder obtiene un pedido por su ID, o nil si no existe
+func (r *MemoryDeliveryOrderRepository) GetDeliveryOrder(ctx context.Context, id string) (*domain.DeliveryOrder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	order, exists := r.orders[id]
+	if !exists {
+		return nil, nil
+	}
+
+	orderCopy := *order
+	return &orderCopy, nil
+}
+
+// GetDeliveryOrders obtiene los pedidos de un tanque (o todos si tankID está vacío),
+// ordenados del más reciente al más antiguo
+func (r *MemoryDeliveryOrderRepository) GetDeliveryOrders(ctx context.Context, tankID string) ([]*domain.DeliveryOrder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	orders := make([]*domain.DeliveryOrder, 0)
+	for _, order := range r.orders {
+		if tankID != "" && order.TankID != tankID {
+			continue
+		}
+		orderCopy := *order
+		orders = append(orders, &orderCopy)
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].RequestedAt.After(orders[j].RequestedAt)
+	})
+
+	return orders, nil
+}
+
+// SaveDeliveryOrder guarda un nuevo pedido
+func (r *MemoryDeliveryOrderRepository) SaveDeliveryOrder(ctx context.Context, order *domain.DeliveryOrder) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if order == nil {
+		return errors.New("delivery order cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	orderCopy := *order
+	r.orders[order.ID] = &orderCopy
+
+	return nil
+}
+
+// UpdateDeliveryOrder actualiza un pedido existente
+func (r *MemoryDeliveryOrderRepository) UpdateDeliveryOrder(ctx context.Context, order *domain.DeliveryOrder) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if order == nil {
+		return errors.New("delivery order cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.orders[order.ID]; !exists {
+		return ErrDeliveryOrderNotFound
+	}
+
+	orderCopy := *order
+	r.orders[order.ID] = &orderCopy
+
+	return nil
+}
+
+// PurgeTankData elimina los pedidos de entrega del tanque y devuelve cuántos registros eliminó
+func (r *MemoryDeliveryOrderRepository) PurgeTankData(ctx context.Context, tankID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	removed := 0
+	for id, order := range r.orders {
+		if order.TankID == tankID {
+			delete(r.orders, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryDeviceCommandRepository implementa un repositorio de comandos de equipos en memoria
+type MemoryDeviceCommandRepository struct {
+	commands map[string][]*domain.DeviceCommand // clave: deviceID, valor: comandos en orden cronológico
+	mutex    sync.RWMutex
+}
+
+// NewMemoryDeviceCommandRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryDeviceCommandRepository() *MemoryDeviceCommandRepository {
+	return &MemoryDeviceCommandRepository{
+		commands: make(map[string][]*domain.DeviceCommand),
+	}
+}
+
+// SaveCommand guarda un nuevo comando
+func (r *MemoryDeviceCommandRepository) SaveCommand(ctx context.Context, command *domain.DeviceCommand) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if command == nil {
+		return errors.New("device command cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	commandCopy := *command
+	commands := append(r.commands[command.DeviceID], &commandCopy)
+
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].CreatedAt.Before(commands[j].CreatedAt)
+	})
+
+	r.commands[command.DeviceID] = commands
+
+	return nil
+}
+
+// GetCommands obtiene los comandos de un equipo en orden cronológico
+func (r *MemoryDeviceCommandRepository) GetCommands(ctx context.Context, deviceID string) ([]*domain.DeviceCommand, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	commands := r.commands[deviceID]
+	copies := make([]*domain.DeviceCommand, len(commands))
+	for i, command := range commands {
+		commandCopy := *command
+		copies[i] = &commandCopy
+	}
+
+	return copies, nil
+}
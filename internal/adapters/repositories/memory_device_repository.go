@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrMobileDeviceNotFound se devuelve cuando el dispositivo no existe en el repositorio
+var ErrMobileDeviceNotFound = errors.New("mobile device not found")
+
+// MemoryMobileDeviceRepository implementa un repositorio de dispositivos móviles en memoria
+type MemoryMobileDeviceRepository struct {
+	devices map[string]*domain.MobileDevice
+	mutex   sync.RWMutex
+}
+
+// NewMemoryMobileDeviceRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryMobileDeviceRepository() *MemoryMobileDeviceRepository {
+	return &MemoryMobileDeviceRepository{
+		devices: make(map[string]*domain.MobileDevice),
+	}
+}
+
+// SaveDevice guarda un dispositivo, reemplazándolo si ya existe
+func (r *MemoryMobileDeviceRepository) SaveDevice(ctx context.Context, device *domain.MobileDevice) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if device == nil {
+		return errors.New("mobile device cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	deviceCopy := *device
+	r.devices[device.ID] = &deviceCopy
+
+	return nil
+}
+
+// DeleteDevice elimina un dispositivo por su ID
+func (r *MemoryMobileDeviceRepository) DeleteDevice(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.devices[id]; !exists {
+		return ErrMobileDeviceNotFound
+	}
+
+	delete(r.devices, id)
+	return nil
+}
+
+// GetDevice obtiene un dispositivo por su ID, o nil si no existe
+func (r *MemoryMobileDeviceRepository) GetDevice(ctx context.Context, id string) (*domain.MobileDevice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	device, exists := r.devices[id]
+	if !exists {
+		return nil, nil
+	}
+
+	deviceCopy := *device
+	return &deviceCopy, nil
+}
+
+// GetDevices obtiene los dispositivos de un usuario, o todos si subject está vacío
+func (r *MemoryMobileDeviceRepository) GetDevices(ctx context.Context, subject string) ([]*domain.MobileDevice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	devices := make([]*domain.MobileDevice, 0)
+	for _, device := range r.devices {
+		if subject != "" && device.Subject != subject {
+			continue
+		}
+		deviceCopy := *device
+		devices = append(devices, &deviceCopy)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].Registered.Before(devices[j].Registered)
+	})
+
+	return devices, nil
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryFieldDeviceRepository implementa un repositorio de equipos de campo en memoria
+type MemoryFieldDeviceRepository struct {
+	devices map[string]*domain.FieldDevice
+	mutex   sync.RWMutex
+}
+
+// NewMemoryFieldDeviceRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryFieldDeviceRepository() *MemoryFieldDeviceRepository {
+	return &MemoryFieldDeviceRepository{
+		devices: make(map[string]*domain.FieldDevice),
+	}
+}
+
+// SaveFieldDevice guarda un equipo, reemplazándolo si ya existe
+func (r *MemoryFieldDeviceRepository) SaveFieldDevice(ctx context.Context, device *domain.FieldDevice) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if device == nil {
+		return errors.New("field device cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.devices[device.ID] = copyFieldDevice(device)
+
+	return nil
+}
+
+// GetFieldDevice obtiene un equipo por su ID, o nil si no existe
+func (r *MemoryFieldDeviceRepository) GetFieldDevice(ctx context.Context, id string) (*domain.FieldDevice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	device, exists := r.devices[id]
+	if !exists {
+		return nil, nil
+	}
+
+	return copyFieldDevice(device), nil
+}
+
+// GetAllFieldDevices obtiene todos los equipos ordenados por ID
+func (r *MemoryFieldDeviceRepository) GetAllFieldDevices(ctx context.Context) ([]*domain.FieldDevice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	devices := make([]*domain.FieldDevice, 0, len(r.devices))
+	for _, device := range r.devices {
+		devices = append(devices, copyFieldDevice(device))
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].ID < devices[j].ID
+	})
+
+	return devices, nil
+}
+
+// copyFieldDevice copia el equipo incluyendo los campos referenciados por puntero
+func copyFieldDevice(device *domain.FieldDevice) *domain.FieldDevice {
+	deviceCopy := *device
+	if device.Applied != nil {
+		applied := *device.Applied
+		deviceCopy.Applied = &applied
+	}
+	if device.LastPolledAt != nil {
+		polledAt := *device.LastPolledAt
+		deviceCopy.LastPolledAt = &polledAt
+	}
+	if device.AppliedAt != nil {
+		appliedAt := *device.AppliedAt
+		deviceCopy.AppliedAt = &appliedAt
+	}
+	return &deviceCopy
+}
+
+// PurgeTankData elimina los equipos de campo del tanque y devuelve cuántos registros eliminó
+func (r *MemoryFieldDeviceRepository) PurgeTankData(ctx context.Context, tankID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	removed := 0
+	for id, device := range r.devices {
+		if device.TankID == tankID {
+			delete(r.devices, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
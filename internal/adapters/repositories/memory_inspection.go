@@ -0,0 +1,65 @@
+package repositories
+
+import "context"
+
+// CountEntities devuelve cuántos elementos guarda cada repositorio en memoria. Cuenta con los
+// mismos bloqueos que las instantáneas, así que el resultado es coherente entre colecciones.
+func (s *MemoryStore) CountEntities(ctx context.Context) (map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	lockers := s.lockers()
+	for _, mutex := range lockers {
+		mutex.RLock()
+	}
+	defer func() {
+		for _, mutex := range lockers {
+			mutex.RUnlock()
+		}
+	}()
+
+	usage := 0
+	for _, counters := range s.Usage.counters {
+		usage += len(counters)
+	}
+
+	return map[string]int{
+		"tanks":           len(s.Tanks.tanks),
+		"measurements":    countNested(s.Measurements.measurements),
+		"status_changes":  countNested(s.StatusChanges.changes),
+		"suppliers":       len(s.Suppliers.suppliers),
+		"delivery_orders": len(s.DeliveryOrders.orders),
+		"channels":        len(s.Channels.channels),
+		"alert_queue":     len(s.AlertQueue.alerts),
+		"access_grants":   len(s.AccessGrants.grants),
+		"attachments":     len(s.Attachments.attachments),
+		"mobile_devices":  len(s.MobileDevices.devices),
+		"notes":           countNested(s.Notes.notes),
+		"anomalies":       countNested(s.Anomalies.anomalies),
+		"field_devices":   len(s.FieldDevices.devices),
+		"device_commands": countNested(s.Commands.commands),
+		"alert_mutes":     countNested(s.AlertMutes.mutes),
+		"usage_counters":  usage,
+		"status_shares":   len(s.StatusShares.shares),
+		"outbox_events":   len(s.Outbox.events),
+		"liquid_policies": len(s.LiquidPolicies.policies),
+		"api_tokens":      len(s.APITokens.tokens),
+		"alert_evidence":  len(s.AlertEvidence.evidence),
+		"security_events": len(s.SecurityEvents.events),
+		"sessions":        len(s.Sessions.sessions),
+		"tank_changes":    len(s.TankChanges.changes),
+		"sync_queue":      len(s.SyncQueue.queue),
+		"tank_sync":       len(s.TankSync.states),
+		"saved_views":     len(s.SavedViews.views),
+	}, nil
+}
+
+// countNested cuenta los elementos de todas las listas del mapa
+func countNested[V any](values map[string][]V) int {
+	count := 0
+	for _, items := range values {
+		count += len(items)
+	}
+	return count
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryLiquidPolicyRepository implementa un repositorio de políticas por tipo de líquido en memoria
+type MemoryLiquidPolicyRepository struct {
+	policies map[string]*domain.LiquidPolicy // clave: domain.LiquidPolicyKey del tipo de líquido
+	mutex    sync.RWMutex
+}
+
+// NewMemoryLiquidPolicyRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryLiquidPolicyRepository() *MemoryLiquidPolicyRepository {
+	return &MemoryLiquidPolicyRepository{
+		policies: make(map[string]*domain.LiquidPolicy),
+	}
+}
+
+// copyLiquidPolicy copia la política para que los cambios del llamador no alteren la guardada
+func copyLiquidPolicy(policy *domain.LiquidPolicy) *domain.LiquidPolicy {
+	policyCopy := *policy
+	policyCopy.HazmatContacts = append([]string(nil), policy.HazmatContacts...)
+	return &policyCopy
+}
+
+// SavePolicy guarda una política nueva o reemplaza la del mismo tipo de líquido
+func (r *MemoryLiquidPolicyRepository) SavePolicy(ctx context.Context, policy *domain.LiquidPolicy) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if policy == nil {
+		return errors.New("liquid policy cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.policies[domain.LiquidPolicyKey(policy.LiquidType)] = copyLiquidPolicy(policy)
+	return nil
+}
+
+// GetPolicy obtiene la política del tipo de líquido, o nil si no tiene
+func (r *MemoryLiquidPolicyRepository) GetPolicy(ctx context.Context, liquidType string) (*domain.LiquidPolicy, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	policy, ok := r.policies[domain.LiquidPolicyKey(liquidType)]
+	if !ok {
+		return nil, nil
+	}
+	return copyLiquidPolicy(policy), nil
+}
+
+// GetPolicies obtiene todas las políticas ordenadas por tipo de líquido
+func (r *MemoryLiquidPolicyRepository) GetPolicies(ctx context.Context) ([]*domain.LiquidPolicy, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	policies := make([]*domain.LiquidPolicy, 0, len(r.policies))
+	for _, policy := range r.policies {
+		policies = append(policies, copyLiquidPolicy(policy))
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return domain.LiquidPolicyKey(policies[i].LiquidType) < domain.LiquidPolicyKey(policies[j].LiquidType)
+	})
+	return policies, nil
+}
+
+// DeletePolicy elimina la política del tipo de líquido; eliminar una inexistente no es un error
+func (r *MemoryLiquidPolicyRepository) DeletePolicy(ctx context.Context, liquidType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.policies, domain.LiquidPolicyKey(liquidType))
+	return nil
+}
@@ -24,8 +24,13 @@ func NewMemoryMeasurementRepository() *MemoryMeasurementRepository {
 	}
 }
 
-// SaveMeasurement guarda una nueva medición
+// SaveMeasurement guarda una nueva medición en su posición cronológica; las lecturas repetidas
+// devuelven domain.ErrDuplicateMeasurement
 func (r *MemoryMeasurementRepository) SaveMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if measurement == nil {
 		return errors.New("measurement cannot be nil")
 	}
@@ -38,28 +43,61 @@ func (r *MemoryMeasurementRepository) SaveMeasurement(ctx context.Context, measu
 		measurement.Timestamp = time.Now()
 	}
 
-	// Guardamos una copia para evitar problemas de concurrencia
-	measurementCopy := *measurement
+	if !r.insertLocked(measurement) {
+		return domain.ErrDuplicateMeasurement
+	}
 
-	// Añadimos la medición al slice correspondiente al tankID
-	if _, exists := r.measurements[measurement.TankID]; !exists {
-		r.measurements[measurement.TankID] = make([]*domain.Measurement, 0)
+	return nil
+}
+
+// insertLocked añade una copia de la medición en su posición cronológica (más recientes
+// primero) y devuelve false, sin añadirla, si la lectura ya está guardada. A igual marca de
+// tiempo, la medición nueva va delante. Requiere tener el mutex de escritura.
+func (r *MemoryMeasurementRepository) insertLocked(measurement *domain.Measurement) bool {
+	measurements := r.measurements[measurement.TankID]
+	position := r.positionLocked(measurement)
+	if position < 0 {
+		return false
 	}
 
-	r.measurements[measurement.TankID] = append(r.measurements[measurement.TankID], &measurementCopy)
+	// Guardamos una copia para evitar problemas de concurrencia
+	measurementCopy := *measurement
+	measurements = append(measurements, nil)
+	copy(measurements[position+1:], measurements[position:])
+	measurements[position] = &measurementCopy
+	r.measurements[measurement.TankID] = measurements
+
+	return true
+}
 
-	// Ordenamos las mediciones por timestamp (más recientes primero)
-	sort.Slice(r.measurements[measurement.TankID], func(i, j int) bool {
-		return r.measurements[measurement.TankID][i].Timestamp.After(
-			r.measurements[measurement.TankID][j].Timestamp,
-		)
+// positionLocked devuelve la posición que corresponde a la medición, o -1 si la lectura ya está
+// guardada. Requiere tener el mutex de lectura.
+func (r *MemoryMeasurementRepository) positionLocked(measurement *domain.Measurement) int {
+	measurements := r.measurements[measurement.TankID]
+	position := sort.Search(len(measurements), func(i int) bool {
+		return !measurements[i].Timestamp.After(measurement.Timestamp)
 	})
+	for i := position; i < len(measurements) && measurements[i].Timestamp.Equal(measurement.Timestamp); i++ {
+		if measurements[i].IsSameReading(measurement) {
+			return -1
+		}
+	}
+	return position
+}
 
-	return nil
+// containsReading indica si la lectura ya está guardada
+func (r *MemoryMeasurementRepository) containsReading(measurement *domain.Measurement) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.positionLocked(measurement) < 0
 }
 
 // GetMeasurementsByTankID obtiene las mediciones para un tanque específico
 func (r *MemoryMeasurementRepository) GetMeasurementsByTankID(ctx context.Context, tankID string, limit int) ([]*domain.Measurement, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -90,6 +128,10 @@ func (r *MemoryMeasurementRepository) GetMeasurementsByTankID(ctx context.Contex
 
 // GetLastMeasurement obtiene la última medición para un tanque específico
 func (r *MemoryMeasurementRepository) GetLastMeasurement(ctx context.Context, tankID string) (*domain.Measurement, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -106,3 +148,119 @@ func (r *MemoryMeasurementRepository) GetLastMeasurement(ctx context.Context, ta
 	lastMeasurement := *measurements[0]
 	return &lastMeasurement, nil
 }
+
+// StreamMeasurementsByTankID entrega las mediciones de un tanque de una en una. Solo se retienen
+// los punteros durante el recorrido: las mediciones guardadas no se modifican tras insertarse y
+// cada una se copia justo antes de entregarla.
+func (r *MemoryMeasurementRepository) StreamMeasurementsByTankID(ctx context.Context, tankID string, limit int, fn func(*domain.Measurement) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if tankID == "" {
+		return errors.New("tank ID cannot be empty")
+	}
+
+	r.mutex.RLock()
+	measurements := r.measurements[tankID]
+	if limit > 0 && limit < len(measurements) {
+		measurements = measurements[:limit]
+	}
+	// Las inserciones desplazan los elementos del slice, así que recorremos una instantánea de los punteros
+	snapshot := make([]*domain.Measurement, len(measurements))
+	copy(snapshot, measurements)
+	r.mutex.RUnlock()
+
+	for _, m := range snapshot {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		measurementCopy := *m
+		if err := fn(&measurementCopy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StreamFilteredMeasurementsByTankID recorre las mediciones del tanque que cumplen el filtro, de
+// la más reciente a la más antigua; limit cuenta solo las que lo cumplen
+func (r *MemoryMeasurementRepository) StreamFilteredMeasurementsByTankID(ctx context.Context, tankID string, filter *domain.Filter, limit int, fn func(*domain.Measurement) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if tankID == "" {
+		return errors.New("tank ID cannot be empty")
+	}
+
+	r.mutex.RLock()
+	var snapshot []*domain.Measurement
+	for _, m := range r.measurements[tankID] {
+		if limit > 0 && len(snapshot) >= limit {
+			break
+		}
+		if filter.Match(m) {
+			snapshot = append(snapshot, m)
+		}
+	}
+	r.mutex.RUnlock()
+
+	for _, m := range snapshot {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		measurementCopy := *m
+		if err := fn(&measurementCopy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgeTankData elimina todas las mediciones del tanque y devuelve cuántas eliminó
+func (r *MemoryMeasurementRepository) PurgeTankData(ctx context.Context, tankID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	removed := len(r.measurements[tankID])
+	delete(r.measurements, tankID)
+	return removed, nil
+}
+
+// DeleteMeasurementsBefore elimina las mediciones del tanque anteriores a before y devuelve
+// cuántas eliminó
+func (r *MemoryMeasurementRepository) DeleteMeasurementsBefore(ctx context.Context, tankID string, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	if tankID == "" {
+		return 0, errors.New("tank ID cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Las mediciones están ordenadas de la más reciente a la más antigua
+	measurements := r.measurements[tankID]
+	kept := sort.Search(len(measurements), func(i int) bool {
+		return measurements[i].Timestamp.Before(before)
+	})
+	removed := len(measurements) - kept
+	if removed == 0 {
+		return 0, nil
+	}
+
+	// Copiamos las que se conservan para liberar la memoria de las eliminadas
+	r.measurements[tankID] = append([]*domain.Measurement(nil), measurements[:kept]...)
+	return removed, nil
+}
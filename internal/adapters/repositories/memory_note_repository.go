@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryTankNoteRepository implementa un repositorio de notas de tanques en memoria
+type MemoryTankNoteRepository struct {
+	notes map[string][]*domain.TankNote // clave: tankID, valor: notas en orden cronológico
+	mutex sync.RWMutex
+}
+
+// NewMemoryTankNoteRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryTankNoteRepository() *MemoryTankNoteRepository {
+	return &MemoryTankNoteRepository{
+		notes: make(map[string][]*domain.TankNote),
+	}
+}
+
+// SaveNote guarda una nueva nota
+func (r *MemoryTankNoteRepository) SaveNote(ctx context.Context, note *domain.TankNote) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if note == nil {
+		return errors.New("note cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	noteCopy := *note
+	notes := append(r.notes[note.TankID], &noteCopy)
+
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].CreatedAt.Before(notes[j].CreatedAt)
+	})
+
+	r.notes[note.TankID] = notes
+
+	return nil
+}
+
+// GetNotes obtiene todas las notas de un tanque en orden cronológico
+func (r *MemoryTankNoteRepository) GetNotes(ctx context.Context, tankID string) ([]*domain.TankNote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	notes := r.notes[tankID]
+	copies := make([]*domain.TankNote, len(notes))
+	for i, note := range notes {
+		noteCopy := *note
+		copies[i] = &noteCopy
+	}
+
+	return copies, nil
+}
+
+// PurgeTankData elimina las notas del tanque y devuelve cuántos registros eliminó
+func (r *MemoryTankNoteRepository) PurgeTankData(ctx context.Context, tankID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	removed := len(r.notes[tankID])
+	delete(r.notes, tankID)
+	return removed, nil
+}
@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrChannelNotFound se devuelve cuando el canal de notificación no existe en el repositorio
+var ErrChannelNotFound = errors.New("notification channel not found")
+
+// MemoryNotificationChannelRepository implementa un repositorio de canales de notificación en memoria
+type MemoryNotificationChannelRepository struct {
+	channels map[string]*domain.NotificationChannel
+	mutex    sync.RWMutex
+}
+
+// NewMemoryNotificationChannelRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryNotificationChannelRepository() *MemoryNotificationChannelRepository {
+	return &MemoryNotificationChannelRepository{
+		channels: make(map[string]*domain.NotificationChannel),
+	}
+}
+
+// copyChannel copia el canal incluyendo sus ventanas horarias
+func copyChannel(channel *domain.NotificationChannel) *domain.NotificationChannel {
+	channelCopy := *channel
+	channelCopy.Schedule.Windows = make([]domain.ScheduleWindow, len(channel.Schedule.Windows))
+	for i, window := range channel.Schedule.Windows {
+		window.Days = append([]string(nil), window.Days...)
+		channelCopy.Schedule.Windows[i] = window
+	}
+	return &channelCopy
+}
+
+// GetChannel obtiene un canal por su ID, o nil si no existe
+func (r *MemoryNotificationChannelRepository) GetChannel(ctx context.Context, id string) (*domain.NotificationChannel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	channel, exists := r.channels[id]
+	if !exists {
+		return nil, nil
+	}
+
+	return copyChannel(channel), nil
+}
+
+// GetAllChannels obtiene todos los canales
+func (r *MemoryNotificationChannelRepository) GetAllChannels(ctx context.Context) ([]*domain.NotificationChannel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	channels := make([]*domain.NotificationChannel, 0, len(r.channels))
+	for _, channel := range r.channels {
+		channels = append(channels, copyChannel(channel))
+	}
+
+	return channels, nil
+}
+
+// SaveChannel guarda un nuevo canal
+func (r *MemoryNotificationChannelRepository) SaveChannel(ctx context.Context, channel *domain.NotificationChannel) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if channel == nil {
+		return errors.New("notification channel cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.channels[channel.ID] = copyChannel(channel)
+
+	return nil
+}
+
+// UpdateChannel actualiza un canal existente
+func (r *MemoryNotificationChannelRepository) UpdateChannel(ctx context.Context, channel *domain.NotificationChannel) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if channel == nil {
+		return errors.New("notification channel cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.channels[channel.ID]; !exists {
+		return ErrChannelNotFound
+	}
+
+	r.channels[channel.ID] = copyChannel(channel)
+
+	return nil
+}
+
+// DeleteChannel elimina un canal por su ID
+func (r *MemoryNotificationChannelRepository) DeleteChannel(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.channels[id]; !exists {
+		return ErrChannelNotFound
+	}
+
+	delete(r.channels, id)
+	return nil
+}
+
+// MemoryAlertQueueRepository implementa una cola de alertas retenidas en memoria
+type MemoryAlertQueueRepository struct {
+	alerts map[string]*domain.QueuedAlert
+	mutex  sync.RWMutex
+}
+
+// NewMemoryAlertQueueRepository crea una nueva instancia de la cola en memoria
+func NewMemoryAlertQueueRepository() *MemoryAlertQueueRepository {
+	return &MemoryAlertQueueRepository{
+		alerts: make(map[string]*domain.QueuedAlert),
+	}
+}
+
+// EnqueueAlert añade una alerta a la cola
+func (r *MemoryAlertQueueRepository) EnqueueAlert(ctx context.Context, alert *domain.QueuedAlert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if alert == nil {
+		return errors.New("queued alert cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	alertCopy := *alert
+	r.alerts[alert.ID] = &alertCopy
+
+	return nil
+}
+
+// GetQueuedAlerts obtiene las alertas encoladas de un canal (o de todos si channelID está vacío),
+// de la más antigua a la más reciente
+func (r *MemoryAlertQueueRepository) GetQueuedAlerts(ctx context.Context, channelID string) ([]*domain.QueuedAlert, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	alerts := make([]*domain.QueuedAlert, 0)
+	for _, alert := range r.alerts {
+		if channelID != "" && alert.ChannelID != channelID {
+			continue
+		}
+		alertCopy := *alert
+		alerts = append(alerts, &alertCopy)
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].QueuedAt.Before(alerts[j].QueuedAt)
+	})
+
+	return alerts, nil
+}
+
+// DeleteQueuedAlert elimina una alerta de la cola
+func (r *MemoryAlertQueueRepository) DeleteQueuedAlert(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.alerts, id)
+	return nil
+}
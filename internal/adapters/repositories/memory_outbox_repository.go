@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryOutboxRepository implementa una bandeja de salida de eventos en memoria
+type MemoryOutboxRepository struct {
+	events map[string]*domain.OutboxEvent // clave: ID del evento
+	mutex  sync.RWMutex
+}
+
+// NewMemoryOutboxRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryOutboxRepository() *MemoryOutboxRepository {
+	return &MemoryOutboxRepository{
+		events: make(map[string]*domain.OutboxEvent),
+	}
+}
+
+// AddEvent guarda un evento nuevo
+func (r *MemoryOutboxRepository) AddEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if event == nil {
+		return errors.New("outbox event cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.putLocked(event)
+	return nil
+}
+
+// putLocked guarda una copia del evento. Requiere tener el mutex de escritura.
+func (r *MemoryOutboxRepository) putLocked(event *domain.OutboxEvent) {
+	eventCopy := *event
+	r.events[event.ID] = &eventCopy
+}
+
+// GetDueEvents obtiene los eventos pendientes cuyo próximo intento ya venció, del más antiguo al
+// más reciente
+func (r *MemoryOutboxRepository) GetDueEvents(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	events := make([]*domain.OutboxEvent, 0)
+	for _, event := range r.events {
+		if event.Due(now) {
+			eventCopy := *event
+			events = append(events, &eventCopy)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	if limit > 0 && limit < len(events) {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
+
+// UpdateEvent reemplaza un evento existente
+func (r *MemoryOutboxRepository) UpdateEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if event == nil {
+		return errors.New("outbox event cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.events[event.ID]; !exists {
+		return errors.New("outbox event not found")
+	}
+	r.putLocked(event)
+	return nil
+}
+
+// DeleteDeliveredBefore elimina los eventos entregados antes de before
+func (r *MemoryOutboxRepository) DeleteDeliveredBefore(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	deleted := 0
+	for id, event := range r.events {
+		if event.DeliveredAt != nil && event.DeliveredAt.Before(before) {
+			delete(r.events, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrSavedViewNotFound se devuelve cuando la vista no existe en el repositorio
+var ErrSavedViewNotFound = errors.New("saved view not found")
+
+// MemorySavedViewRepository implementa un repositorio de vistas guardadas en memoria
+type MemorySavedViewRepository struct {
+	views map[string]*domain.SavedView
+	mutex sync.RWMutex
+}
+
+// NewMemorySavedViewRepository crea una nueva instancia del repositorio en memoria
+func NewMemorySavedViewRepository() *MemorySavedViewRepository {
+	return &MemorySavedViewRepository{
+		views: make(map[string]*domain.SavedView),
+	}
+}
+
+// SaveView guarda una vista, reemplazándola si ya existe
+func (r *MemorySavedViewRepository) SaveView(ctx context.Context, view *domain.SavedView) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if view == nil {
+		return errors.New("saved view cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.views[view.ID] = view.Clone()
+	return nil
+}
+
+// GetView obtiene una vista por su ID, o nil si no existe
+func (r *MemorySavedViewRepository) GetView(ctx context.Context, id string) (*domain.SavedView, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	view, exists := r.views[id]
+	if !exists {
+		return nil, nil
+	}
+	return view.Clone(), nil
+}
+
+// GetViews obtiene las vistas de un usuario ordenadas por nombre
+func (r *MemorySavedViewRepository) GetViews(ctx context.Context, subject string) ([]*domain.SavedView, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	views := make([]*domain.SavedView, 0)
+	for _, view := range r.views {
+		if view.Subject == subject {
+			views = append(views, view.Clone())
+		}
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Name != views[j].Name {
+			return views[i].Name < views[j].Name
+		}
+		return views[i].ID < views[j].ID
+	})
+	return views, nil
+}
+
+// DeleteView elimina una vista por su ID
+func (r *MemorySavedViewRepository) DeleteView(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.views[id]; !exists {
+		return ErrSavedViewNotFound
+	}
+
+	delete(r.views, id)
+	return nil
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// maxSecurityEvents limita los eventos de seguridad que se conservan: un ataque sostenido no debe
+// agotar la memoria con su propio rastro
+const maxSecurityEvents = 10000
+
+// MemorySecurityEventRepository implementa un registro de auditoría de accesos en memoria
+type MemorySecurityEventRepository struct {
+	events []*domain.SecurityEvent // del más antiguo al más reciente
+	mutex  sync.RWMutex
+}
+
+// NewMemorySecurityEventRepository crea una nueva instancia del repositorio en memoria
+func NewMemorySecurityEventRepository() *MemorySecurityEventRepository {
+	return &MemorySecurityEventRepository{}
+}
+
+// SaveSecurityEvent añade un evento al registro, descartando los más antiguos si se llena
+func (r *MemorySecurityEventRepository) SaveSecurityEvent(ctx context.Context, event *domain.SecurityEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if event == nil || event.ID == "" {
+		return errors.New("security event must have an ID")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *event
+	r.events = append(r.events, &stored)
+	if len(r.events) > maxSecurityEvents {
+		r.events = append([]*domain.SecurityEvent(nil), r.events[len(r.events)-maxSecurityEvents:]...)
+	}
+	return nil
+}
+
+// GetSecurityEvents devuelve los últimos eventos, del más reciente al más antiguo (0 sin límite)
+func (r *MemorySecurityEventRepository) GetSecurityEvents(ctx context.Context, limit int) ([]*domain.SecurityEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	count := len(r.events)
+	if limit > 0 && limit < count {
+		count = limit
+	}
+	events := make([]*domain.SecurityEvent, 0, count)
+	for i := len(r.events) - 1; i >= 0 && len(events) < count; i-- {
+		event := *r.events[i]
+		events = append(events, &event)
+	}
+	return events, nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemorySessionRepository implementa un repositorio de sesiones en memoria
+type MemorySessionRepository struct {
+	sessions map[string]*domain.Session
+	mutex    sync.RWMutex
+}
+
+// NewMemorySessionRepository crea una nueva instancia del repositorio en memoria
+func NewMemorySessionRepository() *MemorySessionRepository {
+	return &MemorySessionRepository{
+		sessions: make(map[string]*domain.Session),
+	}
+}
+
+// SaveSession guarda una sesión nueva o reemplaza la que tenga el mismo ID
+func (r *MemorySessionRepository) SaveSession(ctx context.Context, session *domain.Session) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if session == nil || session.ID == "" {
+		return errors.New("session ID cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sessions[session.ID] = copySession(session)
+	return nil
+}
+
+// GetSession obtiene la sesión con el ID indicado, o nil si no existe
+func (r *MemorySessionRepository) GetSession(ctx context.Context, id string) (*domain.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	return copySession(session), nil
+}
+
+// GetSessions obtiene todas las sesiones ordenadas por fecha de creación
+func (r *MemorySessionRepository) GetSessions(ctx context.Context) ([]*domain.Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	sessions := make([]*domain.Session, 0, len(r.sessions))
+	for _, session := range r.sessions {
+		sessions = append(sessions, copySession(session))
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+// DeleteSessionsExpiredBefore elimina las sesiones que vencieron antes de before
+func (r *MemorySessionRepository) DeleteSessionsExpiredBefore(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	deleted := 0
+	for id, session := range r.sessions {
+		if session.ExpiresAt.Before(before) {
+			delete(r.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// copySession copia la sesión para que quien la recibe no modifique la guardada
+func copySession(session *domain.Session) *domain.Session {
+	sessionCopy := *session
+	sessionCopy.Principal.Roles = append([]string(nil), session.Principal.Roles...)
+	sessionCopy.PreviousRefreshHashes = append([]string(nil), session.PreviousRefreshHashes...)
+	if session.RevokedAt != nil {
+		revokedAt := *session.RevokedAt
+		sessionCopy.RevokedAt = &revokedAt
+	}
+	return &sessionCopy
+}
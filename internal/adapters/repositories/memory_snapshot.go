@@ -0,0 +1,301 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// memorySnapshotVersion identifica el formato del archivo de instantánea
+const memorySnapshotVersion = 1
+
+// MemoryStore agrupa los repositorios en memoria para guardarlos y restaurarlos juntos, de modo
+// que una instalación pequeña sobreviva a los reinicios sin una base de datos
+type MemoryStore struct {
+	Tanks          *MemoryTankRepository
+	Measurements   *MemoryMeasurementRepository
+	StatusChanges  *MemoryStatusHistoryRepository
+	UnitOfWork     *MemoryUnitOfWork
+	Suppliers      *MemorySupplierRepository
+	DeliveryOrders *MemoryDeliveryOrderRepository
+	Channels       *MemoryNotificationChannelRepository
+	AlertQueue     *MemoryAlertQueueRepository
+	AccessGrants   *MemoryAccessGrantRepository
+	Attachments    *MemoryAttachmentRepository
+	MobileDevices  *MemoryMobileDeviceRepository
+	Notes          *MemoryTankNoteRepository
+	Anomalies      *MemoryAnomalyRepository
+	FieldDevices   *MemoryFieldDeviceRepository
+	Commands       *MemoryDeviceCommandRepository
+	AlertMutes     *MemoryAlertMuteRepository
+	Usage          *MemoryUsageRepository
+	StatusShares   *MemoryStatusShareRepository
+	Outbox         *MemoryOutboxRepository
+	LiquidPolicies *MemoryLiquidPolicyRepository
+	APITokens      *MemoryAPITokenRepository
+	AlertEvidence  *MemoryAlertEvidenceRepository
+	SecurityEvents *MemorySecurityEventRepository
+	Sessions       *MemorySessionRepository
+	TankChanges    *MemoryTankChangeRepository
+	SyncQueue      *MemorySyncQueueRepository
+	TankSync       *MemoryTankSyncRepository
+	SavedViews     *MemorySavedViewRepository
+}
+
+// NewMemoryStore crea todos los repositorios en memoria vacíos
+func NewMemoryStore() *MemoryStore {
+	tankRepo := NewMemoryTankRepository()
+	measurementRepo := NewMemoryMeasurementRepository()
+	statusRepo := NewMemoryStatusHistoryRepository()
+	outboxRepo := NewMemoryOutboxRepository()
+
+	return &MemoryStore{
+		Tanks:          tankRepo,
+		Measurements:   measurementRepo,
+		StatusChanges:  statusRepo,
+		UnitOfWork:     NewMemoryUnitOfWork(tankRepo, measurementRepo, statusRepo, outboxRepo),
+		Suppliers:      NewMemorySupplierRepository(),
+		DeliveryOrders: NewMemoryDeliveryOrderRepository(),
+		Channels:       NewMemoryNotificationChannelRepository(),
+		AlertQueue:     NewMemoryAlertQueueRepository(),
+		AccessGrants:   NewMemoryAccessGrantRepository(),
+		Attachments:    NewMemoryAttachmentRepository(),
+		MobileDevices:  NewMemoryMobileDeviceRepository(),
+		Notes:          NewMemoryTankNoteRepository(),
+		Anomalies:      NewMemoryAnomalyRepository(),
+		FieldDevices:   NewMemoryFieldDeviceRepository(),
+		Commands:       NewMemoryDeviceCommandRepository(),
+		AlertMutes:     NewMemoryAlertMuteRepository(),
+		Usage:          NewMemoryUsageRepository(),
+		StatusShares:   NewMemoryStatusShareRepository(),
+		Outbox:         outboxRepo,
+		LiquidPolicies: NewMemoryLiquidPolicyRepository(),
+		APITokens:      NewMemoryAPITokenRepository(),
+		AlertEvidence:  NewMemoryAlertEvidenceRepository(),
+		SecurityEvents: NewMemorySecurityEventRepository(),
+		Sessions:       NewMemorySessionRepository(),
+		TankChanges:    NewMemoryTankChangeRepository(),
+		SyncQueue:      NewMemorySyncQueueRepository(),
+		TankSync:       NewMemoryTankSyncRepository(),
+		SavedViews:     NewMemorySavedViewRepository(),
+	}
+}
+
+// memorySnapshot es el contenido del archivo de instantánea. Se codifica con gob, que a
+// diferencia de JSON conserva los campos ocultos en la API (p. ej. Attachment.StorageKey).
+type memorySnapshot struct {
+	Version        int
+	TakenAt        time.Time
+	Tanks          map[string]*domain.Tank
+	Measurements   map[string][]*domain.Measurement
+	StatusChanges  map[string][]*domain.StatusChange
+	Suppliers      map[string]*domain.Supplier
+	DeliveryOrders map[string]*domain.DeliveryOrder
+	Channels       map[string]*domain.NotificationChannel
+	AlertQueue     map[string]*domain.QueuedAlert
+	AccessGrants   map[string]*domain.AccessGrant
+	Attachments    map[string]*domain.Attachment
+	MobileDevices  map[string]*domain.MobileDevice
+	Notes          map[string][]*domain.TankNote
+	Anomalies      map[string][]*domain.Anomaly
+	FieldDevices   map[string]*domain.FieldDevice
+	Commands       map[string][]*domain.DeviceCommand
+	AlertMutes     map[string][]*domain.AlertMute
+	Usage          map[string]map[string]*domain.UsageCounter
+	StatusShares   map[string]*domain.StatusShare
+	Outbox         map[string]*domain.OutboxEvent
+	LiquidPolicies map[string]*domain.LiquidPolicy
+	APITokens      map[string]*domain.APIToken
+	AlertEvidence  map[string]*domain.AlertEvidence
+	SecurityEvents []*domain.SecurityEvent
+	Sessions       map[string]*domain.Session
+	TankChanges    map[string]*domain.TankChangeRequest
+	SyncQueue      []*domain.Measurement
+	TankSync       map[string]*domain.TankSyncState
+	TankSyncSeq    int64
+	SyncCursors    map[string]int64
+	SyncResults    map[string]*domain.SyncResult
+	SavedViews     map[string]*domain.SavedView
+}
+
+// lockers devuelve los mutex de todos los repositorios
+func (s *MemoryStore) lockers() []*sync.RWMutex {
+	return []*sync.RWMutex{
+		&s.Tanks.mutex, &s.Measurements.mutex, &s.StatusChanges.mutex, &s.Suppliers.mutex,
+		&s.DeliveryOrders.mutex, &s.Channels.mutex, &s.AlertQueue.mutex, &s.AccessGrants.mutex,
+		&s.Attachments.mutex, &s.MobileDevices.mutex, &s.Notes.mutex, &s.Anomalies.mutex,
+		&s.FieldDevices.mutex, &s.Commands.mutex, &s.AlertMutes.mutex, &s.Usage.mutex,
+		&s.StatusShares.mutex, &s.Outbox.mutex, &s.LiquidPolicies.mutex, &s.APITokens.mutex,
+		&s.AlertEvidence.mutex, &s.SecurityEvents.mutex, &s.Sessions.mutex, &s.TankChanges.mutex,
+		&s.SyncQueue.mutex, &s.TankSync.mutex, &s.SavedViews.mutex,
+	}
+}
+
+// SaveSnapshot guarda el contenido de todos los repositorios en path. El archivo se escribe
+// aparte y se renombra al terminar, para que un corte a mitad no deje una instantánea corrupta.
+func (s *MemoryStore) SaveSnapshot(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Sin unidades de trabajo en curso, tanques, mediciones y transiciones quedan coherentes
+	s.UnitOfWork.mutex.Lock()
+	defer s.UnitOfWork.mutex.Unlock()
+
+	lockers := s.lockers()
+	for _, mutex := range lockers {
+		mutex.RLock()
+	}
+	snapshot := memorySnapshot{
+		Version:        memorySnapshotVersion,
+		TakenAt:        time.Now(),
+		Tanks:          s.Tanks.tanks,
+		Measurements:   s.Measurements.measurements,
+		StatusChanges:  s.StatusChanges.changes,
+		Suppliers:      s.Suppliers.suppliers,
+		DeliveryOrders: s.DeliveryOrders.orders,
+		Channels:       s.Channels.channels,
+		AlertQueue:     s.AlertQueue.alerts,
+		AccessGrants:   s.AccessGrants.grants,
+		Attachments:    s.Attachments.attachments,
+		MobileDevices:  s.MobileDevices.devices,
+		Notes:          s.Notes.notes,
+		Anomalies:      s.Anomalies.anomalies,
+		FieldDevices:   s.FieldDevices.devices,
+		Commands:       s.Commands.commands,
+		AlertMutes:     s.AlertMutes.mutes,
+		Usage:          s.Usage.counters,
+		StatusShares:   s.StatusShares.shares,
+		Outbox:         s.Outbox.events,
+		LiquidPolicies: s.LiquidPolicies.policies,
+		APITokens:      s.APITokens.tokens,
+		AlertEvidence:  s.AlertEvidence.evidence,
+		SecurityEvents: s.SecurityEvents.events,
+		Sessions:       s.Sessions.sessions,
+		TankChanges:    s.TankChanges.changes,
+		SyncQueue:      s.SyncQueue.queue,
+		TankSync:       s.TankSync.states,
+		TankSyncSeq:    s.TankSync.sequence,
+		SyncCursors:    s.TankSync.cursors,
+		SyncResults:    s.TankSync.results,
+		SavedViews:     s.SavedViews.views,
+	}
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(&snapshot)
+	for _, mutex := range lockers {
+		mutex.RUnlock()
+	}
+	if err != nil {
+		return fmt.Errorf("encode memory snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(buffer.Bytes()); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
+
+// LoadSnapshot reemplaza el contenido de los repositorios por el guardado en path. Devuelve
+// false, sin error, si todavía no existe ninguna instantánea.
+func (s *MemoryStore) LoadSnapshot(ctx context.Context, path string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var snapshot memorySnapshot
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snapshot); err != nil {
+		return false, fmt.Errorf("decode memory snapshot: %w", err)
+	}
+	if snapshot.Version != memorySnapshotVersion {
+		return false, fmt.Errorf("unsupported memory snapshot version %d", snapshot.Version)
+	}
+
+	s.UnitOfWork.mutex.Lock()
+	defer s.UnitOfWork.mutex.Unlock()
+
+	lockers := s.lockers()
+	for _, mutex := range lockers {
+		mutex.Lock()
+	}
+	defer func() {
+		for _, mutex := range lockers {
+			mutex.Unlock()
+		}
+	}()
+
+	s.Tanks.tanks = orEmpty(snapshot.Tanks)
+	s.Measurements.measurements = orEmpty(snapshot.Measurements)
+	s.StatusChanges.changes = orEmpty(snapshot.StatusChanges)
+	s.Suppliers.suppliers = orEmpty(snapshot.Suppliers)
+	s.DeliveryOrders.orders = orEmpty(snapshot.DeliveryOrders)
+	s.Channels.channels = orEmpty(snapshot.Channels)
+	s.AlertQueue.alerts = orEmpty(snapshot.AlertQueue)
+	s.AccessGrants.grants = orEmpty(snapshot.AccessGrants)
+	s.Attachments.attachments = orEmpty(snapshot.Attachments)
+	s.MobileDevices.devices = orEmpty(snapshot.MobileDevices)
+	s.Notes.notes = orEmpty(snapshot.Notes)
+	s.Anomalies.anomalies = orEmpty(snapshot.Anomalies)
+	s.FieldDevices.devices = orEmpty(snapshot.FieldDevices)
+	s.Commands.commands = orEmpty(snapshot.Commands)
+	s.AlertMutes.mutes = orEmpty(snapshot.AlertMutes)
+	s.Usage.counters = orEmpty(snapshot.Usage)
+	s.StatusShares.shares = orEmpty(snapshot.StatusShares)
+	s.Outbox.events = orEmpty(snapshot.Outbox)
+	s.LiquidPolicies.policies = orEmpty(snapshot.LiquidPolicies)
+	s.APITokens.tokens = orEmpty(snapshot.APITokens)
+	s.AlertEvidence.evidence = orEmpty(snapshot.AlertEvidence)
+	s.SecurityEvents.events = snapshot.SecurityEvents
+	s.Sessions.sessions = orEmpty(snapshot.Sessions)
+	s.TankChanges.changes = orEmpty(snapshot.TankChanges)
+	s.SyncQueue.queue = snapshot.SyncQueue
+	s.TankSync.states = orEmpty(snapshot.TankSync)
+	s.TankSync.sequence = snapshot.TankSyncSeq
+	s.TankSync.cursors = orEmpty(snapshot.SyncCursors)
+	s.TankSync.results = orEmpty(snapshot.SyncResults)
+	s.SavedViews.views = orEmpty(snapshot.SavedViews)
+
+	return true, nil
+}
+
+// orEmpty devuelve un mapa vacío en lugar de nil; gob omite los mapas sin elementos
+func orEmpty[V any](values map[string]V) map[string]V {
+	if values == nil {
+		return make(map[string]V)
+	}
+	return values
+}
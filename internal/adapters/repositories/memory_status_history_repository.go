@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryStatusHistoryRepository implementa un repositorio de transiciones de estado en memoria
+type MemoryStatusHistoryRepository struct {
+	changes map[string][]*domain.StatusChange // clave: tankID, valor: transiciones en orden cronológico
+	mutex   sync.RWMutex
+}
+
+// NewMemoryStatusHistoryRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryStatusHistoryRepository() *MemoryStatusHistoryRepository {
+	return &MemoryStatusHistoryRepository{
+		changes: make(map[string][]*domain.StatusChange),
+	}
+}
+
+// SaveStatusChange guarda una nueva transición de estado
+func (r *MemoryStatusHistoryRepository) SaveStatusChange(ctx context.Context, change *domain.StatusChange) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if change == nil {
+		return errors.New("status change cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.insertLocked(change)
+
+	return nil
+}
+
+// insertLocked añade una copia de la transición manteniendo el orden. Requiere tener el mutex de escritura.
+func (r *MemoryStatusHistoryRepository) insertLocked(change *domain.StatusChange) {
+	changeCopy := *change
+	changes := append(r.changes[change.TankID], &changeCopy)
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].ChangedAt.Before(changes[j].ChangedAt)
+	})
+
+	r.changes[change.TankID] = changes
+}
+
+// GetStatusChanges obtiene todas las transiciones de un tanque en orden cronológico
+func (r *MemoryStatusHistoryRepository) GetStatusChanges(ctx context.Context, tankID string) ([]*domain.StatusChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	changes := r.changes[tankID]
+	copies := make([]*domain.StatusChange, len(changes))
+	for i, change := range changes {
+		changeCopy := *change
+		copies[i] = &changeCopy
+	}
+
+	return copies, nil
+}
+
+// ReplaceStatusChanges sustituye las transiciones del tanque desde from (incluido) por changes
+func (r *MemoryStatusHistoryRepository) ReplaceStatusChanges(ctx context.Context, tankID string, from time.Time, changes []*domain.StatusChange) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if change == nil || change.TankID != tankID {
+			return errors.New("status change does not belong to the tank")
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	kept := make([]*domain.StatusChange, 0, len(r.changes[tankID])+len(changes))
+	for _, change := range r.changes[tankID] {
+		if change.ChangedAt.Before(from) {
+			kept = append(kept, change)
+		}
+	}
+	r.changes[tankID] = kept
+
+	for _, change := range changes {
+		r.insertLocked(change)
+	}
+
+	return nil
+}
+
+// PurgeTankData elimina las transiciones de estado del tanque y devuelve cuántos registros eliminó
+func (r *MemoryStatusHistoryRepository) PurgeTankData(ctx context.Context, tankID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	removed := len(r.changes[tankID])
+	delete(r.changes, tankID)
+	return removed, nil
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryStatusShareRepository implementa un repositorio de páginas de estado públicas en memoria
+type MemoryStatusShareRepository struct {
+	shares map[string]*domain.StatusShare // clave: ID de la página
+	mutex  sync.RWMutex
+}
+
+// NewMemoryStatusShareRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryStatusShareRepository() *MemoryStatusShareRepository {
+	return &MemoryStatusShareRepository{
+		shares: make(map[string]*domain.StatusShare),
+	}
+}
+
+// copyStatusShare copia la página sin el token en claro, que nunca se guarda
+func copyStatusShare(share *domain.StatusShare) *domain.StatusShare {
+	shareCopy := *share
+	shareCopy.Token = ""
+	shareCopy.TankIDs = append([]string(nil), share.TankIDs...)
+	return &shareCopy
+}
+
+// SaveShare guarda una página nueva o reemplaza la existente con el mismo ID
+func (r *MemoryStatusShareRepository) SaveShare(ctx context.Context, share *domain.StatusShare) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if share == nil {
+		return errors.New("status share cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.shares[share.ID] = copyStatusShare(share)
+	return nil
+}
+
+// GetShare obtiene una página por su ID, o nil si no existe
+func (r *MemoryStatusShareRepository) GetShare(ctx context.Context, id string) (*domain.StatusShare, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	share, ok := r.shares[id]
+	if !ok {
+		return nil, nil
+	}
+	return copyStatusShare(share), nil
+}
+
+// GetShareByTokenHash obtiene la página cuyo token tiene el hash indicado, o nil si no existe
+func (r *MemoryStatusShareRepository) GetShareByTokenHash(ctx context.Context, tokenHash string) (*domain.StatusShare, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, share := range r.shares {
+		if share.TokenHash == tokenHash {
+			return copyStatusShare(share), nil
+		}
+	}
+	return nil, nil
+}
+
+// GetShares obtiene todas las páginas, de la más antigua a la más reciente
+func (r *MemoryStatusShareRepository) GetShares(ctx context.Context) ([]*domain.StatusShare, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	shares := make([]*domain.StatusShare, 0, len(r.shares))
+	for _, share := range r.shares {
+		shares = append(shares, copyStatusShare(share))
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		return shares[i].CreatedAt.Before(shares[j].CreatedAt)
+	})
+	return shares, nil
+}
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrSupplierNotFound se devuelve cuando el proveedor no existe en el repositorio
+var ErrSupplierNotFound = errors.New("supplier not found")
+
+// MemorySupplierRepository implementa un repositorio de proveedores en memoria
+type MemorySupplierRepository struct {
+	suppliers map[string]*domain.Supplier
+	mutex     sync.RWMutex
+}
+
+// NewMemorySupplierRepository crea una nueva instancia del repositorio en memoria
+func NewMemorySupplierRepository() *MemorySupplierRepository {
+	return &MemorySupplierRepository{
+		suppliers: make(map[string]*domain.Supplier),
+	}
+}
+
+// GetSupplier obtiene un proveedor por su ID, o nil si no existe
+func (r *MemorySupplierRepository) GetSupplier(ctx context.Context, id string) (*domain.Supplier, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	supplier, exists := r.suppliers[id]
+	if !exists {
+		return nil, nil
+	}
+
+	supplierCopy := *supplier
+	return &supplierCopy, nil
+}
+
+// GetAllSuppliers obtiene todos los proveedores
+func (r *MemorySupplierRepository) GetAllSuppliers(ctx context.Context) ([]*domain.Supplier, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	suppliers := make([]*domain.Supplier, 0, len(r.suppliers))
+	for _, supplier := range r.suppliers {
+		supplierCopy := *supplier
+		suppliers = append(suppliers, &supplierCopy)
+	}
+
+	return suppliers, nil
+}
+
+// SaveSupplier guarda un nuevo proveedor
+func (r *MemorySupplierRepository) SaveSupplier(ctx context.Context, supplier *domain.Supplier) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if supplier == nil {
+		return errors.New("supplier cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	supplierCopy := *supplier
+	r.suppliers[supplier.ID] = &supplierCopy
+
+	return nil
+}
+
+// UpdateSupplier actualiza un proveedor existente
+func (r *MemorySupplierRepository) UpdateSupplier(ctx context.Context, supplier *domain.Supplier) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if supplier == nil {
+		return errors.New("supplier cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.suppliers[supplier.ID]; !exists {
+		return ErrSupplierNotFound
+	}
+
+	supplierCopy := *supplier
+	r.suppliers[supplier.ID] = &supplierCopy
+
+	return nil
+}
+
+// DeleteSupplier elimina un proveedor por su ID
+func (r *MemorySupplierRepository) DeleteSupplier(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.suppliers[id]; !exists {
+		return ErrSupplierNotFound
+	}
+
+	delete(r.suppliers, id)
+	return nil
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemorySyncQueueRepository implementa en memoria la cola de mediciones de una pasarela edge
+// pendientes de reenviar. Con MEMORY_SNAPSHOT_PATH la cola sobrevive a los reinicios.
+type MemorySyncQueueRepository struct {
+	queue []*domain.Measurement // De la más antigua a la más reciente
+	mutex sync.RWMutex
+}
+
+// NewMemorySyncQueueRepository crea una nueva instancia del repositorio en memoria
+func NewMemorySyncQueueRepository() *MemorySyncQueueRepository {
+	return &MemorySyncQueueRepository{
+		queue: make([]*domain.Measurement, 0),
+	}
+}
+
+// EnqueueMeasurements añade copias de las mediciones al final de la cola
+func (r *MemorySyncQueueRepository) EnqueueMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, measurement := range measurements {
+		measurementCopy := *measurement
+		r.queue = append(r.queue, &measurementCopy)
+	}
+	return nil
+}
+
+// GetQueuedMeasurements devuelve copias de las primeras limit mediciones de la cola
+func (r *MemorySyncQueueRepository) GetQueuedMeasurements(ctx context.Context, limit int) ([]*domain.Measurement, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	count := len(r.queue)
+	if limit > 0 && limit < count {
+		count = limit
+	}
+	measurements := make([]*domain.Measurement, 0, count)
+	for _, measurement := range r.queue[:count] {
+		measurementCopy := *measurement
+		measurements = append(measurements, &measurementCopy)
+	}
+	return measurements, nil
+}
+
+// DeleteQueuedMeasurements quita de la cola las mediciones con los IDs indicados
+func (r *MemorySyncQueueRepository) DeleteQueuedMeasurements(ctx context.Context, ids []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	kept := make([]*domain.Measurement, 0, len(r.queue))
+	for _, measurement := range r.queue {
+		if !remove[measurement.ID] {
+			kept = append(kept, measurement)
+		}
+	}
+	r.queue = kept
+	return nil
+}
+
+// CountQueuedMeasurements devuelve el número de mediciones en la cola
+func (r *MemorySyncQueueRepository) CountQueuedMeasurements(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.queue), nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryTankChangeRepository implementa un repositorio de solicitudes de cambio de tanques en memoria
+type MemoryTankChangeRepository struct {
+	changes map[string]*domain.TankChangeRequest
+	mutex   sync.RWMutex
+}
+
+// NewMemoryTankChangeRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryTankChangeRepository() *MemoryTankChangeRepository {
+	return &MemoryTankChangeRepository{
+		changes: make(map[string]*domain.TankChangeRequest),
+	}
+}
+
+// SaveTankChange guarda una solicitud nueva o reemplaza la existente con el mismo ID
+func (r *MemoryTankChangeRepository) SaveTankChange(ctx context.Context, change *domain.TankChangeRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if change == nil || change.ID == "" {
+		return errors.New("tank change request must have an ID")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.changes[change.ID] = copyTankChange(change)
+	return nil
+}
+
+// GetTankChange obtiene una solicitud por su ID, o nil si no existe
+func (r *MemoryTankChangeRepository) GetTankChange(ctx context.Context, id string) (*domain.TankChangeRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	change, exists := r.changes[id]
+	if !exists {
+		return nil, nil
+	}
+	return copyTankChange(change), nil
+}
+
+// GetTankChanges obtiene las solicitudes que cumplen el filtro, de la más reciente a la más antigua
+func (r *MemoryTankChangeRepository) GetTankChanges(ctx context.Context, filter domain.TankChangeFilter) ([]*domain.TankChangeRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	changes := make([]*domain.TankChangeRequest, 0)
+	for _, change := range r.changes {
+		if filter.Matches(change) {
+			changes = append(changes, copyTankChange(change))
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].RequestedAt.After(changes[j].RequestedAt)
+	})
+	return changes, nil
+}
+
+// PurgeTankData elimina las solicitudes de cambio del tanque y devuelve cuántas eliminó
+func (r *MemoryTankChangeRepository) PurgeTankData(ctx context.Context, tankID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	removed := 0
+	for id, change := range r.changes {
+		if change.TankID == tankID {
+			delete(r.changes, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// copyTankChange copia la solicitud con sus cambios, para que el llamador no modifique lo guardado
+func copyTankChange(change *domain.TankChangeRequest) *domain.TankChangeRequest {
+	changeCopy := *change
+	changeCopy.Changes = make([]*domain.FieldChange, len(change.Changes))
+	for i, field := range change.Changes {
+		fieldCopy := *field
+		changeCopy.Changes[i] = &fieldCopy
+	}
+	return &changeCopy
+}
@@ -11,7 +11,8 @@ import (
 
 // Errores comunes para el repositorio
 var (
-	ErrTankNotFound = errors.New("tank not found")
+	// ErrTankNotFound es el del dominio, para que los servicios reconozcan el tanque inexistente
+	ErrTankNotFound = domain.ErrTankNotFound
 )
 
 // MemoryTankRepository implementa un repositorio de tanques en memoria
@@ -30,6 +31,10 @@ func NewMemoryTankRepository() *MemoryTankRepository {
 
 // GetTank obtiene un tanque por su ID
 func (r *MemoryTankRepository) GetTank(ctx context.Context, id string) (*domain.Tank, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
@@ -40,17 +45,23 @@ func (r *MemoryTankRepository) GetTank(ctx context.Context, id string) (*domain.
 
 	// Devolvemos una copia para evitar problemas de concurrencia
 	tankCopy := *tank
+	tankCopy.Labels = tank.Labels.Clone()
 	return &tankCopy, nil
 }
 
 // GetAllTanks obtiene todos los tanques
 func (r *MemoryTankRepository) GetAllTanks(ctx context.Context) ([]*domain.Tank, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	tanks := make([]*domain.Tank, 0, len(r.tanks))
 	for _, tank := range r.tanks {
 		tankCopy := *tank
+		tankCopy.Labels = tank.Labels.Clone()
 		tanks = append(tanks, &tankCopy)
 	}
 
@@ -59,6 +70,10 @@ func (r *MemoryTankRepository) GetAllTanks(ctx context.Context) ([]*domain.Tank,
 
 // SaveTank guarda un nuevo tanque
 func (r *MemoryTankRepository) SaveTank(ctx context.Context, tank *domain.Tank) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if tank == nil {
 		return errors.New("tank cannot be nil")
 	}
@@ -73,6 +88,7 @@ func (r *MemoryTankRepository) SaveTank(ctx context.Context, tank *domain.Tank)
 
 	// Guardamos una copia para evitar problemas de concurrencia
 	tankCopy := *tank
+	tankCopy.Labels = tank.Labels.Clone()
 	r.tanks[tank.ID] = &tankCopy
 
 	return nil
@@ -80,6 +96,10 @@ func (r *MemoryTankRepository) SaveTank(ctx context.Context, tank *domain.Tank)
 
 // UpdateTank actualiza un tanque existente
 func (r *MemoryTankRepository) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if tank == nil {
 		return errors.New("tank cannot be nil")
 	}
@@ -98,6 +118,7 @@ func (r *MemoryTankRepository) UpdateTank(ctx context.Context, tank *domain.Tank
 
 	// Guardamos una copia para evitar problemas de concurrencia
 	tankCopy := *tank
+	tankCopy.Labels = tank.Labels.Clone()
 	r.tanks[tank.ID] = &tankCopy
 
 	return nil
@@ -105,6 +126,10 @@ func (r *MemoryTankRepository) UpdateTank(ctx context.Context, tank *domain.Tank
 
 // DeleteTank elimina un tanque por su ID
 func (r *MemoryTankRepository) DeleteTank(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
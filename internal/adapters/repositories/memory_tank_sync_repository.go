@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// maxSyncResults es el número de respuestas a lotes de sincronización que se conservan para
+// reconocer los reenvíos
+const maxSyncResults = 1000
+
+// MemoryTankSyncRepository implementa en memoria el registro de sincronización de la
+// configuración de los tanques
+type MemoryTankSyncRepository struct {
+	states   map[string]*domain.TankSyncState // Por ID de tanque
+	sequence int64
+	cursors  map[string]int64
+	results  map[string]*domain.SyncResult // Por pasarela y lote
+	mutex    sync.RWMutex
+}
+
+// NewMemoryTankSyncRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryTankSyncRepository() *MemoryTankSyncRepository {
+	return &MemoryTankSyncRepository{
+		states:  make(map[string]*domain.TankSyncState),
+		cursors: make(map[string]int64),
+		results: make(map[string]*domain.SyncResult),
+	}
+}
+
+// SaveTankSyncState guarda una copia del estado con la siguiente secuencia
+func (r *MemoryTankSyncRepository) SaveTankSyncState(ctx context.Context, state *domain.TankSyncState) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sequence++
+	state.Sequence = r.sequence
+	r.states[state.TankID] = state.Clone()
+	return nil
+}
+
+// GetTankSyncState devuelve una copia del estado del tanque
+func (r *MemoryTankSyncRepository) GetTankSyncState(ctx context.Context, tankID string) (*domain.TankSyncState, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	state, ok := r.states[tankID]
+	if !ok {
+		return nil, nil
+	}
+	return state.Clone(), nil
+}
+
+// GetTankSyncStates devuelve copias de los estados posteriores al cursor
+func (r *MemoryTankSyncRepository) GetTankSyncStates(ctx context.Context, cursor int64, limit int) ([]*domain.TankSyncState, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	states := make([]*domain.TankSyncState, 0)
+	for _, state := range r.states {
+		if state.Sequence > cursor {
+			states = append(states, state.Clone())
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Sequence < states[j].Sequence })
+	if limit > 0 && len(states) > limit {
+		states = states[:limit]
+	}
+	return states, nil
+}
+
+// GetSyncCursor devuelve el cursor guardado con el nombre indicado
+func (r *MemoryTankSyncRepository) GetSyncCursor(ctx context.Context, name string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.cursors[name], nil
+}
+
+// SaveSyncCursor guarda el cursor con el nombre indicado
+func (r *MemoryTankSyncRepository) SaveSyncCursor(ctx context.Context, name string, cursor int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cursors[name] = cursor
+	return nil
+}
+
+// GetSyncResult devuelve la respuesta ya dada al lote de la pasarela
+func (r *MemoryTankSyncRepository) GetSyncResult(ctx context.Context, gatewayID, batchID string) (*domain.SyncResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.results[syncResultKey(gatewayID, batchID)], nil
+}
+
+// SaveSyncResult guarda la respuesta a un lote y descarta la más antigua al superar maxSyncResults
+func (r *MemoryTankSyncRepository) SaveSyncResult(ctx context.Context, result *domain.SyncResult) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.results[syncResultKey(result.GatewayID, result.BatchID)] = result
+	if len(r.results) <= maxSyncResults {
+		return nil
+	}
+
+	var oldestKey string
+	var oldest *domain.SyncResult
+	for key, saved := range r.results {
+		if oldest == nil || saved.ReceivedAt.Before(oldest.ReceivedAt) {
+			oldestKey, oldest = key, saved
+		}
+	}
+	delete(r.results, oldestKey)
+	return nil
+}
+
+// syncResultKey identifica un lote por su pasarela y su ID
+func syncResultKey(gatewayID, batchID string) string {
+	return gatewayID + "/" + batchID
+}
@@ -0,0 +1,367 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// MemoryUnitOfWork implementa ports.UnitOfWork sobre los repositorios en memoria.
+// Las escrituras se acumulan durante la unidad de trabajo y se aplican de una sola vez
+// al confirmar, bloqueando los repositorios para que ningún lector vea un estado parcial.
+type MemoryUnitOfWork struct {
+	tankRepo        *MemoryTankRepository
+	measurementRepo *MemoryMeasurementRepository
+	statusRepo      *MemoryStatusHistoryRepository
+	outboxRepo      *MemoryOutboxRepository
+	mutex           sync.Mutex // serializa las unidades de trabajo
+}
+
+// NewMemoryUnitOfWork crea una nueva unidad de trabajo sobre los repositorios en memoria
+func NewMemoryUnitOfWork(
+	tankRepo *MemoryTankRepository,
+	measurementRepo *MemoryMeasurementRepository,
+	statusRepo *MemoryStatusHistoryRepository,
+	outboxRepo *MemoryOutboxRepository,
+) *MemoryUnitOfWork {
+	return &MemoryUnitOfWork{
+		tankRepo:        tankRepo,
+		measurementRepo: measurementRepo,
+		statusRepo:      statusRepo,
+		outboxRepo:      outboxRepo,
+	}
+}
+
+// Do ejecuta fn y confirma sus escrituras solo si no devuelve error
+func (u *MemoryUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos ports.TxRepositories) error) error {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	tanks := &txTankRepository{base: u.tankRepo, staged: make(map[string]*domain.Tank)}
+	measurements := &txMeasurementRepository{base: u.measurementRepo}
+	statusChanges := &txStatusHistoryRepository{base: u.statusRepo}
+	outbox := &txOutboxRepository{base: u.outboxRepo}
+
+	repos := ports.TxRepositories{
+		Tanks:         tanks,
+		Measurements:  measurements,
+		StatusChanges: statusChanges,
+		Outbox:        outbox,
+	}
+	if err := fn(ctx, repos); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return u.commit(tanks, measurements, statusChanges, outbox)
+}
+
+// commit aplica las escrituras acumuladas de forma atómica
+func (u *MemoryUnitOfWork) commit(
+	tanks *txTankRepository,
+	measurements *txMeasurementRepository,
+	statusChanges *txStatusHistoryRepository,
+	outbox *txOutboxRepository,
+) error {
+	u.tankRepo.mutex.Lock()
+	defer u.tankRepo.mutex.Unlock()
+	u.measurementRepo.mutex.Lock()
+	defer u.measurementRepo.mutex.Unlock()
+	u.statusRepo.mutex.Lock()
+	defer u.statusRepo.mutex.Unlock()
+	u.outboxRepo.mutex.Lock()
+	defer u.outboxRepo.mutex.Unlock()
+
+	// Validamos antes de escribir nada para no dejar cambios a medias
+	for _, id := range tanks.updated {
+		if _, exists := u.tankRepo.tanks[id]; !exists {
+			return ErrTankNotFound
+		}
+	}
+
+	for id, tank := range tanks.staged {
+		if tank == nil {
+			delete(u.tankRepo.tanks, id)
+			continue
+		}
+		tankCopy := *tank
+		tankCopy.Labels = tank.Labels.Clone()
+		u.tankRepo.tanks[id] = &tankCopy
+	}
+
+	// Una lectura que otra unidad de trabajo guardó mientras tanto no se duplica
+	for _, measurement := range measurements.staged {
+		u.measurementRepo.insertLocked(measurement)
+	}
+
+	for _, change := range statusChanges.staged {
+		u.statusRepo.insertLocked(change)
+	}
+
+	for _, event := range outbox.staged {
+		u.outboxRepo.putLocked(event)
+	}
+
+	return nil
+}
+
+// txTankRepository acumula las escrituras de tanques dentro de una unidad de trabajo
+type txTankRepository struct {
+	base    *MemoryTankRepository
+	staged  map[string]*domain.Tank // nil indica que el tanque se elimina
+	updated []string                // IDs que deben existir al confirmar
+}
+
+// GetTank obtiene un tanque teniendo en cuenta las escrituras pendientes
+func (r *txTankRepository) GetTank(ctx context.Context, id string) (*domain.Tank, error) {
+	if tank, exists := r.staged[id]; exists {
+		if tank == nil {
+			return nil, ErrTankNotFound
+		}
+		tankCopy := *tank
+		tankCopy.Labels = tank.Labels.Clone()
+		return &tankCopy, nil
+	}
+
+	return r.base.GetTank(ctx, id)
+}
+
+// GetAllTanks obtiene todos los tanques teniendo en cuenta las escrituras pendientes
+func (r *txTankRepository) GetAllTanks(ctx context.Context) ([]*domain.Tank, error) {
+	baseTanks, err := r.base.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tanks := make([]*domain.Tank, 0, len(baseTanks)+len(r.staged))
+	for _, tank := range baseTanks {
+		if _, exists := r.staged[tank.ID]; !exists {
+			tanks = append(tanks, tank)
+		}
+	}
+	for _, tank := range r.staged {
+		if tank != nil {
+			tankCopy := *tank
+			tankCopy.Labels = tank.Labels.Clone()
+			tanks = append(tanks, &tankCopy)
+		}
+	}
+
+	return tanks, nil
+}
+
+// SaveTank acumula la creación de un tanque
+func (r *txTankRepository) SaveTank(ctx context.Context, tank *domain.Tank) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if tank == nil {
+		return errors.New("tank cannot be nil")
+	}
+
+	if tank.LastUpdated.IsZero() {
+		tank.LastUpdated = time.Now()
+	}
+
+	tankCopy := *tank
+	tankCopy.Labels = tank.Labels.Clone()
+	r.staged[tank.ID] = &tankCopy
+	return nil
+}
+
+// UpdateTank acumula la actualización de un tanque existente
+func (r *txTankRepository) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	if tank == nil {
+		return errors.New("tank cannot be nil")
+	}
+
+	if _, err := r.GetTank(ctx, tank.ID); err != nil {
+		return err
+	}
+
+	if tank.LastUpdated.IsZero() {
+		tank.LastUpdated = time.Now()
+	}
+
+	// Los tanques creados en esta misma unidad de trabajo no existen todavía en el repositorio base
+	if _, staged := r.staged[tank.ID]; !staged {
+		r.updated = append(r.updated, tank.ID)
+	}
+
+	tankCopy := *tank
+	tankCopy.Labels = tank.Labels.Clone()
+	r.staged[tank.ID] = &tankCopy
+	return nil
+}
+
+// DeleteTank acumula la eliminación de un tanque
+func (r *txTankRepository) DeleteTank(ctx context.Context, id string) error {
+	if _, err := r.GetTank(ctx, id); err != nil {
+		return err
+	}
+
+	r.staged[id] = nil
+	return nil
+}
+
+// txMeasurementRepository acumula las mediciones guardadas dentro de una unidad de trabajo
+type txMeasurementRepository struct {
+	base   *MemoryMeasurementRepository
+	staged []*domain.Measurement
+}
+
+// SaveMeasurement acumula una nueva medición; las lecturas ya guardadas o acumuladas devuelven
+// domain.ErrDuplicateMeasurement
+func (r *txMeasurementRepository) SaveMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if measurement == nil {
+		return errors.New("measurement cannot be nil")
+	}
+
+	if measurement.Timestamp.IsZero() {
+		measurement.Timestamp = time.Now()
+	}
+
+	if r.base.containsReading(measurement) {
+		return domain.ErrDuplicateMeasurement
+	}
+	for _, staged := range r.staged {
+		if staged.IsSameReading(measurement) {
+			return domain.ErrDuplicateMeasurement
+		}
+	}
+
+	measurementCopy := *measurement
+	r.staged = append(r.staged, &measurementCopy)
+	return nil
+}
+
+// GetMeasurementsByTankID obtiene las mediciones incluyendo las pendientes de confirmar
+func (r *txMeasurementRepository) GetMeasurementsByTankID(ctx context.Context, tankID string, limit int) ([]*domain.Measurement, error) {
+	saved, err := r.base.GetMeasurementsByTankID(ctx, tankID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Las acumuladas son las últimas guardadas: a igual marca de tiempo van delante, de la más
+	// nueva a la más antigua
+	measurements := make([]*domain.Measurement, 0, len(saved)+len(r.staged))
+	for i := len(r.staged) - 1; i >= 0; i-- {
+		if m := r.staged[i]; m.TankID == tankID {
+			measurementCopy := *m
+			measurements = append(measurements, &measurementCopy)
+		}
+	}
+	measurements = append(measurements, saved...)
+
+	sort.SliceStable(measurements, func(i, j int) bool {
+		return measurements[i].Timestamp.After(measurements[j].Timestamp)
+	})
+
+	if limit > 0 && limit < len(measurements) {
+		measurements = measurements[:limit]
+	}
+
+	return measurements, nil
+}
+
+// GetLastMeasurement obtiene la última medición incluyendo las pendientes de confirmar
+func (r *txMeasurementRepository) GetLastMeasurement(ctx context.Context, tankID string) (*domain.Measurement, error) {
+	measurements, err := r.GetMeasurementsByTankID(ctx, tankID, 1)
+	if err != nil || len(measurements) == 0 {
+		return nil, err
+	}
+
+	return measurements[0], nil
+}
+
+// txStatusHistoryRepository acumula las transiciones de estado dentro de una unidad de trabajo
+type txStatusHistoryRepository struct {
+	base   *MemoryStatusHistoryRepository
+	staged []*domain.StatusChange
+}
+
+// SaveStatusChange acumula una nueva transición de estado
+func (r *txStatusHistoryRepository) SaveStatusChange(ctx context.Context, change *domain.StatusChange) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if change == nil {
+		return errors.New("status change cannot be nil")
+	}
+
+	changeCopy := *change
+	r.staged = append(r.staged, &changeCopy)
+	return nil
+}
+
+// GetStatusChanges obtiene las transiciones incluyendo las pendientes de confirmar
+func (r *txStatusHistoryRepository) GetStatusChanges(ctx context.Context, tankID string) ([]*domain.StatusChange, error) {
+	changes, err := r.base.GetStatusChanges(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range r.staged {
+		if change.TankID == tankID {
+			changeCopy := *change
+			changes = append(changes, &changeCopy)
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].ChangedAt.Before(changes[j].ChangedAt)
+	})
+
+	return changes, nil
+}
+
+// txOutboxRepository acumula los eventos de la bandeja de salida dentro de una unidad de trabajo.
+// Las lecturas solo ven los eventos ya confirmados, que son los únicos que pueden entregarse.
+type txOutboxRepository struct {
+	base   *MemoryOutboxRepository
+	staged []*domain.OutboxEvent
+}
+
+// AddEvent acumula un evento nuevo
+func (r *txOutboxRepository) AddEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if event == nil {
+		return errors.New("outbox event cannot be nil")
+	}
+
+	eventCopy := *event
+	r.staged = append(r.staged, &eventCopy)
+	return nil
+}
+
+// GetDueEvents obtiene los eventos confirmados que deben entregarse
+func (r *txOutboxRepository) GetDueEvents(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxEvent, error) {
+	return r.base.GetDueEvents(ctx, now, limit)
+}
+
+// UpdateEvent acumula la actualización de un evento
+func (r *txOutboxRepository) UpdateEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	return r.AddEvent(ctx, event)
+}
+
+// DeleteDeliveredBefore elimina los eventos entregados; no forma parte de la unidad de trabajo
+func (r *txOutboxRepository) DeleteDeliveredBefore(ctx context.Context, before time.Time) (int, error) {
+	return r.base.DeleteDeliveredBefore(ctx, before)
+}
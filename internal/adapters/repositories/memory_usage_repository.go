@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// MemoryUsageRepository implementa un repositorio de contadores de uso en memoria
+type MemoryUsageRepository struct {
+	counters map[string]map[string]*domain.UsageCounter // clave: mes, organización
+	mutex    sync.RWMutex
+}
+
+// NewMemoryUsageRepository crea una nueva instancia del repositorio en memoria
+func NewMemoryUsageRepository() *MemoryUsageRepository {
+	return &MemoryUsageRepository{
+		counters: make(map[string]map[string]*domain.UsageCounter),
+	}
+}
+
+// counter devuelve el contador de la organización en el mes, creándolo si no existe. Debe
+// llamarse con el bloqueo de escritura tomado.
+func (r *MemoryUsageRepository) counter(month, organization string) *domain.UsageCounter {
+	organizations, ok := r.counters[month]
+	if !ok {
+		organizations = make(map[string]*domain.UsageCounter)
+		r.counters[month] = organizations
+	}
+
+	counter, ok := organizations[organization]
+	if !ok {
+		counter = &domain.UsageCounter{}
+		organizations[organization] = counter
+	}
+	// gob omite los mapas vacíos al restaurar una instantánea
+	if counter.Tanks == nil {
+		counter.Tanks = make(map[string]bool)
+	}
+	return counter
+}
+
+// AddAPICalls suma solicitudes a la organización en el mes
+func (r *MemoryUsageRepository) AddAPICalls(ctx context.Context, month, organization string, calls int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.counter(month, organization).APICalls += calls
+	return nil
+}
+
+// AddMeasurements suma mediciones del tanque a la organización en el mes y lo marca como activo
+func (r *MemoryUsageRepository) AddMeasurements(ctx context.Context, month, organization, tankID string, measurements int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	counter := r.counter(month, organization)
+	counter.Measurements += measurements
+	counter.Tanks[tankID] = true
+	return nil
+}
+
+// GetUsage devuelve el uso de cada organización en el mes
+func (r *MemoryUsageRepository) GetUsage(ctx context.Context, month string) ([]*domain.OrganizationUsage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return domain.BuildUsage(month, r.counters[month]), nil
+}
@@ -0,0 +1,78 @@
+package runtimeconfig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrInvalidFile se devuelve cuando el archivo de configuración recargable no es válido
+var ErrInvalidFile = errors.New("invalid runtime config file")
+
+// fileSettings es el formato del archivo. Los campos son punteros para distinguir los ausentes,
+// que conservan el valor de las variables de entorno.
+type fileSettings struct {
+	LogLevel              *string  `yaml:"log_level"`
+	AlertNotifier         *string  `yaml:"alert_notifier"`
+	AlertNotifierTarget   *string  `yaml:"alert_notifier_target"`
+	DefaultAlertThreshold *float64 `yaml:"default_alert_threshold"`
+}
+
+// FileSource lee la configuración recargable de un archivo YAML cada vez que se recarga
+type FileSource struct {
+	path string
+}
+
+// NewFileSource crea una fuente de configuración recargable sobre el archivo indicado
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+// Load lee el archivo y aplica sus valores sobre base
+func (s *FileSource) Load(ctx context.Context, base domain.RuntimeSettings) (*domain.RuntimeSettings, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := Parse(bytes.NewReader(data), base)
+	if err != nil {
+		return nil, err
+	}
+	settings.Source = s.path
+	return settings, nil
+}
+
+// Parse interpreta un documento YAML de configuración recargable sobre base. Los campos
+// desconocidos se rechazan para que una errata no pase inadvertida.
+func Parse(r io.Reader, base domain.RuntimeSettings) (*domain.RuntimeSettings, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file fileSettings
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	settings := base
+	if file.LogLevel != nil {
+		settings.LogLevel = *file.LogLevel
+	}
+	if file.AlertNotifier != nil {
+		settings.AlertNotifier = *file.AlertNotifier
+	}
+	if file.AlertNotifierTarget != nil {
+		settings.AlertNotifierTarget = *file.AlertNotifierTarget
+	}
+	if file.DefaultAlertThreshold != nil {
+		settings.DefaultAlertThreshold = *file.DefaultAlertThreshold
+	}
+	return &settings, nil
+}
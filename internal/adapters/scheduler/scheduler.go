@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+// Job representa una tarea periódica en segundo plano
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler ejecuta tareas periódicas garantizando, mediante un bloqueo distribuido,
+// que cada ejecución ocurra en una sola réplica de la aplicación
+type Scheduler struct {
+	locker ports.Locker
+	logger logger.Logger
+	jobs   []Job
+	wg     sync.WaitGroup
+}
+
+// NewScheduler crea un nuevo planificador de tareas
+func NewScheduler(locker ports.Locker, logger logger.Logger) *Scheduler {
+	return &Scheduler{
+		locker: locker,
+		logger: logger,
+	}
+}
+
+// AddJob registra una tarea. Debe llamarse antes de Start.
+func (s *Scheduler) AddJob(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start lanza todas las tareas registradas hasta que se cancele el contexto
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			continue
+		}
+
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait espera a que terminen todas las tareas tras cancelar el contexto de Start
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// loop ejecuta la tarea al comienzo de cada periodo mientras el contexto siga activo. Los periodos
+// se alinean con múltiplos del intervalo, así que todas las réplicas compiten por el mismo periodo
+// y cada disparo cae en uno distinto.
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	timer := time.NewTimer(untilNextPeriod(time.Now(), job.Interval))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			s.RunOnce(ctx, job)
+			timer.Reset(untilNextPeriod(time.Now(), job.Interval))
+		}
+	}
+}
+
+// RunOnce ejecuta la tarea si esta instancia consigue el bloqueo del periodo actual
+func (s *Scheduler) RunOnce(ctx context.Context, job Job) bool {
+	return s.RunAt(ctx, job, time.Now())
+}
+
+// RunAt ejecuta la tarea si esta instancia consigue el bloqueo del periodo que contiene at. Cada
+// periodo tiene su propio bloqueo, que no se libera al terminar: vence con el intervalo, de modo que
+// el resto de réplicas no repitan la ejecución en ese periodo, y el siguiente no lo espera.
+func (s *Scheduler) RunAt(ctx context.Context, job Job, at time.Time) bool {
+	acquired, err := s.locker.TryAcquire(ctx, periodLockName(job, at), job.Interval)
+	if err != nil {
+		s.logger.Error("Failed to acquire job lock", "job", job.Name, "error", err)
+		return false
+	}
+
+	if !acquired {
+		s.logger.Debug("Job already running on another instance", "job", job.Name)
+		return false
+	}
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		s.logger.Error("Job failed", "job", job.Name, "error", err)
+	} else {
+		s.logger.Debug("Job completed", "job", job.Name, "duration", time.Since(start))
+	}
+
+	return true
+}
+
+// periodLockName devuelve el nombre del bloqueo de la tarea para el periodo que contiene at
+func periodLockName(job Job, at time.Time) string {
+	return "job:" + job.Name + ":" + strconv.FormatInt(at.Truncate(job.Interval).Unix(), 10)
+}
+
+// untilNextPeriod devuelve la espera desde now hasta el comienzo del siguiente periodo
+func untilNextPeriod(now time.Time, interval time.Duration) time.Duration {
+	return now.Truncate(interval).Add(interval).Sub(now)
+}
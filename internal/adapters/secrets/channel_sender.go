@@ -0,0 +1,41 @@
+package secrets
+
+import (
+	"context"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ChannelSender decora un ports.ChannelSender resolviendo los destinos y los secretos de firma de
+// los canales que son referencias a secretos, como la URL de un webhook entrante de Slack, que
+// lleva su token. El canal guardado y el que devuelve la API conservan la referencia.
+type ChannelSender struct {
+	next     ports.ChannelSender
+	provider ports.SecretProvider
+}
+
+// NewChannelSender crea un emisor por canal que resuelve los destinos con provider
+func NewChannelSender(next ports.ChannelSender, provider ports.SecretProvider) *ChannelSender {
+	return &ChannelSender{next: next, provider: provider}
+}
+
+// Send entrega la alerta por el canal con su destino y su secreto de firma resueltos
+func (s *ChannelSender) Send(ctx context.Context, channel *domain.NotificationChannel, alert *domain.Alert) error {
+	if !IsReference(channel.Target) && !IsReference(channel.Secret) {
+		return s.next.Send(ctx, channel, alert)
+	}
+
+	resolved := *channel
+	for _, value := range []*string{&resolved.Target, &resolved.Secret} {
+		if !IsReference(*value) {
+			continue
+		}
+		secret, err := Resolve(ctx, s.provider, *value)
+		if err != nil {
+			return err
+		}
+		*value = secret
+	}
+	return s.next.Send(ctx, &resolved, alert)
+}
@@ -0,0 +1,28 @@
+package secrets
+
+import (
+	"context"
+	"os"
+)
+
+// EnvProvider implementa ports.SecretProvider sobre las variables de entorno del proceso, p. ej.
+// las que inyecta el orquestador a partir de sus propios secretos
+type EnvProvider struct{}
+
+// NewEnvProvider crea un proveedor de secretos de variables de entorno
+func NewEnvProvider() *EnvProvider {
+	return &EnvProvider{}
+}
+
+// GetSecret devuelve el valor de la variable de entorno name
+func (p *EnvProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
@@ -0,0 +1,43 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileProvider implementa ports.SecretProvider sobre un directorio con un archivo por secreto, como
+// los secretos de Docker y Kubernetes montados en /run/secrets
+type FileProvider struct {
+	dir string
+}
+
+// NewFileProvider crea un proveedor de secretos que lee los archivos de dir
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+// GetSecret devuelve el contenido del archivo name sin el salto de línea final que suelen añadir
+// los editores y el propio kubectl
+func (p *FileProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// El nombre no puede salir del directorio de secretos
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", ErrInvalidName
+	}
+
+	content, err := os.ReadFile(filepath.Join(p.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
@@ -0,0 +1,106 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/ports"
+)
+
+// ReferencePrefix marca los valores de configuración que no son la credencial sino su nombre en
+// el proveedor de secretos, p. ej. SMTP_PASSWORD=secret:smtp-password
+const ReferencePrefix = "secret:"
+
+// Errores de los proveedores de secretos
+var (
+	ErrSecretNotFound = errors.New("secret not found")
+	ErrInvalidName    = errors.New("invalid secret name")
+)
+
+// IsReference indica si el valor es una referencia a un secreto
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, ReferencePrefix)
+}
+
+// Resolve devuelve el secreto al que apunta value si es una referencia, o value sin cambios si no
+// lo es. Así las credenciales pueden seguir indicándose directamente en desarrollo.
+func Resolve(ctx context.Context, provider ports.SecretProvider, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	name := strings.TrimSpace(strings.TrimPrefix(value, ReferencePrefix))
+	if name == "" {
+		return "", ErrInvalidName
+	}
+	secret, err := provider.GetSecret(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("secret %q: %w", name, err)
+	}
+	return secret, nil
+}
+
+// splitKey separa el nombre del secreto del campo pedido dentro de él (nombre#campo), para los
+// almacenes que guardan varios valores por secreto
+func splitKey(name string) (string, string) {
+	if index := strings.LastIndex(name, "#"); index >= 0 {
+		return name[:index], name[index+1:]
+	}
+	return name, ""
+}
+
+// cachedSecret es un secreto leído junto con el momento en que deja de valer
+type cachedSecret struct {
+	value   string
+	expires time.Time
+}
+
+// CachedProvider decora un proveedor guardando los secretos leídos durante ttl, para no consultar
+// el almacén remoto en cada alerta entregada
+type CachedProvider struct {
+	next ports.SecretProvider
+	ttl  time.Duration
+	now  func() time.Time
+
+	mutex   sync.Mutex
+	secrets map[string]cachedSecret
+}
+
+// NewCachedProvider crea un proveedor con caché. Con ttl 0 cada lectura llega al proveedor.
+func NewCachedProvider(next ports.SecretProvider, ttl time.Duration) *CachedProvider {
+	return &CachedProvider{
+		next:    next,
+		ttl:     ttl,
+		now:     time.Now,
+		secrets: make(map[string]cachedSecret),
+	}
+}
+
+// GetSecret devuelve el secreto guardado si sigue vigente o lo lee del proveedor. Los errores no
+// se guardan: un almacén caído vuelve a consultarse en la siguiente lectura.
+func (p *CachedProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	if p.ttl <= 0 {
+		return p.next.GetSecret(ctx, name)
+	}
+
+	p.mutex.Lock()
+	cached, ok := p.secrets[name]
+	p.mutex.Unlock()
+	if ok && p.now().Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	value, err := p.next.GetSecret(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	p.mutex.Lock()
+	p.secrets[name] = cachedSecret{value: value, expires: p.now().Add(p.ttl)}
+	p.mutex.Unlock()
+	return value, nil
+}
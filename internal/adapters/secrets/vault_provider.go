@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultConfig contiene la conexión con HashiCorp Vault
+type VaultConfig struct {
+	Addr  string // p. ej. https://vault.example.com:8200
+	Token string
+	Mount string // Motor de secretos KV versión 2; por defecto "secret"
+}
+
+// VaultProvider implementa ports.SecretProvider sobre el motor KV v2 de Vault. Los nombres tienen
+// la forma ruta#campo, p. ej. monitor/smtp#password; sin campo se lee "value".
+type VaultProvider struct {
+	config VaultConfig
+	client *http.Client
+}
+
+// NewVaultProvider crea un proveedor de secretos de Vault
+func NewVaultProvider(config VaultConfig) *VaultProvider {
+	if config.Mount == "" {
+		config.Mount = "secret"
+	}
+	config.Addr = strings.TrimSuffix(config.Addr, "/")
+	config.Mount = strings.Trim(config.Mount, "/")
+
+	return &VaultProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// vaultKVResponse es la respuesta de lectura del motor KV v2
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+// GetSecret lee la última versión del secreto y devuelve el campo pedido
+func (p *VaultProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	path, field := splitKey(name)
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "", ErrInvalidName
+	}
+	if field == "" {
+		field = "value"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.Addr+"/v1/"+p.config.Mount+"/data/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.config.Token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrSecretNotFound
+	}
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("vault returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	var body vaultKVResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode vault response: %w", err)
+	}
+	value, ok := body.Data.Data[field].(string)
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
@@ -0,0 +1,108 @@
+package sigfox
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// maxFrameSize es el tamaño máximo de una trama de subida de Sigfox
+const maxFrameSize = 12
+
+// Callback es el cuerpo del callback de datos de Sigfox, configurado en el backend como
+//
+//	{"device": "{device}", "time": {time}, "data": "{data}", "seqNumber": {seqNumber},
+//	 "duplicate": {duplicate}, "rssi": {rssi}}
+type Callback struct {
+	Device    string `json:"device"`
+	Time      int64  `json:"time"` // Segundos desde 1970
+	Data      string `json:"data"` // Trama en hexadecimal
+	SeqNumber int    `json:"seqNumber"`
+	Duplicate bool   `json:"duplicate"` // La trama ya se recibió por otra estación base
+	RSSI      Number `json:"rssi,omitempty"`
+}
+
+// Number es un número que el backend de Sigfox puede enviar como número o como texto, según
+// cómo se escriba la plantilla del callback
+type Number struct {
+	Value float64
+	Valid bool
+}
+
+// UnmarshalJSON acepta números, cadenas numéricas y null
+func (n *Number) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "" || text == "null" {
+		*n = Number{}
+		return nil
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*n = Number{Value: value, Valid: true}
+	return nil
+}
+
+// MarshalJSON escribe el número o null
+func (n Number) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Value)
+}
+
+// DeviceID devuelve el ID del equipo normalizado a mayúsculas
+func (c *Callback) DeviceID() string {
+	return strings.ToUpper(strings.TrimSpace(c.Device))
+}
+
+// Measurement decodifica la trama del callback con el tipo del equipo y la convierte en una
+// medición de su tanque. El sensor de la medición es el propio equipo Sigfox.
+func (d *Device) Measurement(callback *Callback) (*domain.Measurement, error) {
+	frame, err := hex.DecodeString(strings.TrimSpace(callback.Data))
+	if err != nil || len(frame) == 0 || len(frame) > maxFrameSize {
+		return nil, fmt.Errorf("%w: data %q is not a hex frame of 1 to %d bytes", ErrInvalidFrame, callback.Data, maxFrameSize)
+	}
+
+	reading, err := decoders[d.Type](frame)
+	if err != nil {
+		return nil, err
+	}
+
+	measurement := &domain.Measurement{
+		TankID:         d.TankID,
+		SensorID:       d.ID,
+		BatteryVoltage: reading.BatteryVoltage,
+	}
+
+	switch {
+	case reading.Level != nil:
+		measurement.Level = *reading.Level
+	case reading.Distance != nil && d.LitersPerMm > 0:
+		// El sensor mide desde arriba: cuanto menor la distancia, más líquido
+		measurement.Level = math.Max(0, d.EmptyDistance-*reading.Distance) * d.LitersPerMm
+	default:
+		return nil, fmt.Errorf("%w: device %s reported no level", ErrInvalidFrame, d.ID)
+	}
+
+	if reading.Temperature != nil {
+		measurement.Temperature = *reading.Temperature
+	}
+	if callback.RSSI.Valid {
+		rssi := callback.RSSI.Value
+		measurement.SignalStrength = &rssi
+	}
+	if callback.Time > 0 {
+		measurement.Timestamp = time.Unix(callback.Time, 0).UTC()
+	}
+
+	return measurement, nil
+}
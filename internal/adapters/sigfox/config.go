@@ -0,0 +1,107 @@
+package sigfox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig se devuelve cuando el archivo de equipos Sigfox no es válido
+var ErrInvalidConfig = errors.New("invalid sigfox devices file")
+
+// deviceIDPattern valida los IDs de equipo de Sigfox (hexadecimales)
+var deviceIDPattern = regexp.MustCompile(`^[0-9A-F]{1,8}$`)
+
+// Config es la configuración de los callbacks de Sigfox
+type Config struct {
+	Token   string             // Secreto que el callback envía en cada solicitud
+	Devices map[string]*Device // Equipos indexados por su ID en mayúsculas
+}
+
+// Device asocia un equipo Sigfox a su tanque y al tipo que decodifica sus tramas
+type Device struct {
+	ID     string
+	TankID string
+	Type   string
+
+	// Conversión de la distancia medida por los equipos ultrasónicos a litros
+	EmptyDistance float64 // Distancia en mm con el tanque vacío
+	LitersPerMm   float64 // Litros por cada mm de altura del líquido
+}
+
+// fileConfig es el formato del archivo de equipos
+type fileConfig struct {
+	Token   string         `yaml:"token"`
+	Devices []deviceConfig `yaml:"devices"`
+}
+
+type deviceConfig struct {
+	ID            string  `yaml:"id"`
+	TankID        string  `yaml:"tank_id"`
+	Type          string  `yaml:"type"`
+	EmptyDistance float64 `yaml:"empty_distance"`
+	LitersPerMm   float64 `yaml:"liters_per_mm"`
+}
+
+// LoadFile lee el archivo de equipos indicado
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse interpreta un documento YAML con el token de los callbacks y los equipos. Los campos
+// desconocidos se rechazan para que una errata no pase inadvertida.
+func Parse(r io.Reader) (*Config, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file fileConfig
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if file.Token == "" {
+		return nil, fmt.Errorf("%w: token is required", ErrInvalidConfig)
+	}
+
+	config := &Config{Token: file.Token, Devices: make(map[string]*Device, len(file.Devices))}
+	for _, entry := range file.Devices {
+		device := &Device{
+			ID:            strings.ToUpper(entry.ID),
+			TankID:        entry.TankID,
+			Type:          entry.Type,
+			EmptyDistance: entry.EmptyDistance,
+			LitersPerMm:   entry.LitersPerMm,
+		}
+
+		if !deviceIDPattern.MatchString(device.ID) {
+			return nil, fmt.Errorf("%w: invalid device id %q", ErrInvalidConfig, entry.ID)
+		}
+		if _, repeated := config.Devices[device.ID]; repeated {
+			return nil, fmt.Errorf("%w: duplicate device %q", ErrInvalidConfig, device.ID)
+		}
+		if device.TankID == "" {
+			return nil, fmt.Errorf("%w: device %q without tank_id", ErrInvalidConfig, device.ID)
+		}
+		if _, ok := decoders[device.Type]; !ok {
+			return nil, fmt.Errorf("%w: device %q has unknown type %q (available: %s)",
+				ErrInvalidConfig, device.ID, device.Type, strings.Join(DeviceTypes(), ", "))
+		}
+		if device.Type == TypeUltrasonic && (device.EmptyDistance <= 0 || device.LitersPerMm <= 0) {
+			return nil, fmt.Errorf("%w: ultrasonic device %q requires empty_distance and liters_per_mm", ErrInvalidConfig, device.ID)
+		}
+
+		config.Devices[device.ID] = device
+	}
+
+	return config, nil
+}
@@ -0,0 +1,89 @@
+// Package sigfox traduce las tramas que el backend de Sigfox reenvía por callback a mediciones
+// de la API. Cada equipo se asocia a un tanque y a un tipo de equipo que define cómo decodificar
+// los bytes de la trama (hasta 12 en Sigfox).
+package sigfox
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrInvalidFrame se devuelve cuando la trama no corresponde al tipo del equipo
+var ErrInvalidFrame = errors.New("invalid sigfox frame")
+
+// Tipos de equipo incluidos
+const (
+	TypeUltrasonic = "ultrasonic" // Distancia al líquido en mm, temperatura y batería
+	TypeLevel      = "level"      // Nivel en litros calculado por el propio equipo
+)
+
+// Reading es la lectura decodificada de una trama, antes de convertirla en una medición
+type Reading struct {
+	Distance       *float64 // Distancia del sensor a la superficie del líquido en mm
+	Level          *float64 // Nivel en litros, si el equipo lo calcula
+	Temperature    *float64 // Temperatura en grados Celsius
+	BatteryVoltage *float64 // Voltaje de la batería
+}
+
+// Decoder decodifica las tramas de un tipo de equipo
+type Decoder func(frame []byte) (*Reading, error)
+
+// decoders son los tipos de equipo disponibles, indexados por el nombre usado en la configuración
+var decoders = map[string]Decoder{
+	TypeUltrasonic: decodeUltrasonic,
+	TypeLevel:      decodeLevel,
+}
+
+// RegisterDecoder publica un tipo de equipo. Debe llamarse al arrancar, antes de leer la
+// configuración de los equipos.
+func RegisterDecoder(deviceType string, decoder Decoder) {
+	decoders[deviceType] = decoder
+}
+
+// DeviceTypes devuelve los tipos de equipo disponibles, ordenados
+func DeviceTypes() []string {
+	types := make([]string, 0, len(decoders))
+	for deviceType := range decoders {
+		types = append(types, deviceType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// decodeUltrasonic decodifica las tramas de los medidores ultrasónicos:
+//
+//	bytes 0-1  distancia en mm (uint16, big endian)
+//	byte  2    temperatura en °C (int8)
+//	byte  3    batería en pasos de 20 mV (uint8)
+func decodeUltrasonic(frame []byte) (*Reading, error) {
+	if len(frame) < 4 {
+		return nil, fmt.Errorf("%w: ultrasonic frame has %d bytes, expected 4", ErrInvalidFrame, len(frame))
+	}
+
+	distance := float64(binary.BigEndian.Uint16(frame[0:2]))
+	temperature := float64(int8(frame[2]))
+	battery := float64(frame[3]) * 0.02
+	return &Reading{Distance: &distance, Temperature: &temperature, BatteryVoltage: &battery}, nil
+}
+
+// decodeLevel decodifica las tramas de los equipos que calculan el nivel:
+//
+//	bytes 0-3  nivel en décimas de litro (uint32, big endian)
+//	bytes 4-5  temperatura en décimas de °C (int16, big endian)
+//	byte  6    batería en pasos de 20 mV (uint8, opcional)
+func decodeLevel(frame []byte) (*Reading, error) {
+	if len(frame) < 6 {
+		return nil, fmt.Errorf("%w: level frame has %d bytes, expected at least 6", ErrInvalidFrame, len(frame))
+	}
+
+	level := float64(binary.BigEndian.Uint32(frame[0:4])) / 10
+	temperature := float64(int16(binary.BigEndian.Uint16(frame[4:6]))) / 10
+	reading := &Reading{Level: &level, Temperature: &temperature}
+	if len(frame) >= 7 {
+		battery := float64(frame[6]) * 0.02
+		reading.BatteryVoltage = &battery
+	}
+	return reading, nil
+}
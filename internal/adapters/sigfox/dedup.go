@@ -0,0 +1,63 @@
+package sigfox
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Deduplicator descarta las tramas repetidas. Sigfox envía cada trama tres veces por radio y
+// varias estaciones base pueden recibirla, así que el mismo número de secuencia de un equipo
+// puede llegar en varios callbacks. Solo se recuerdan las tramas de la última ventana, ya que los
+// números de secuencia se reutilizan tras 4096 tramas.
+type Deduplicator struct {
+	window time.Duration
+
+	mutex     sync.Mutex
+	frames    map[string]time.Time // Momento en que se reclamó cada trama (equipo/secuencia)
+	lastPurge time.Time
+}
+
+// NewDeduplicator crea un deduplicador que recuerda las tramas durante la ventana indicada
+func NewDeduplicator(window time.Duration) *Deduplicator {
+	return &Deduplicator{
+		window: window,
+		frames: make(map[string]time.Time),
+	}
+}
+
+// Claim reserva la trama para procesarla. Devuelve false si ya se reclamó dentro de la ventana,
+// en cuyo caso la trama es un duplicado.
+func (d *Deduplicator) Claim(deviceID string, seqNumber int, now time.Time) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if now.Sub(d.lastPurge) >= d.window {
+		for key, claimed := range d.frames {
+			if now.Sub(claimed) >= d.window {
+				delete(d.frames, key)
+			}
+		}
+		d.lastPurge = now
+	}
+
+	key := frameKey(deviceID, seqNumber)
+	if claimed, ok := d.frames[key]; ok && now.Sub(claimed) < d.window {
+		return false
+	}
+	d.frames[key] = now
+	return true
+}
+
+// Release libera una trama que no pudo guardarse, para que un callback posterior con la misma
+// trama pueda reintentarlo
+func (d *Deduplicator) Release(deviceID string, seqNumber int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.frames, frameKey(deviceID, seqNumber))
+}
+
+func frameKey(deviceID string, seqNumber int) string {
+	return deviceID + "/" + strconv.Itoa(seqNumber)
+}
@@ -0,0 +1,199 @@
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrMalformed se devuelve cuando un paquete no es BER válido
+var ErrMalformed = errors.New("malformed snmp packet")
+
+// Etiquetas BER de los tipos usados por SNMP
+const (
+	TagInteger        byte = 0x02
+	TagOctetString    byte = 0x04
+	TagNull           byte = 0x05
+	TagOID            byte = 0x06
+	TagSequence       byte = 0x30
+	TagIPAddress      byte = 0x40
+	TagCounter32      byte = 0x41
+	TagGauge32        byte = 0x42
+	TagTimeTicks      byte = 0x43
+	TagOpaque         byte = 0x44
+	TagCounter64      byte = 0x46
+	TagNoSuchObject   byte = 0x80
+	TagNoSuchInstance byte = 0x81
+	TagEndOfMibView   byte = 0x82
+)
+
+// readTLV lee un elemento (etiqueta, longitud y contenido) y devuelve lo que queda detrás
+func readTLV(data []byte) (tag byte, content, rest []byte, err error) {
+	if len(data) < 2 {
+		return 0, nil, nil, fmt.Errorf("%w: truncated element", ErrMalformed)
+	}
+	tag = data[0]
+	length := int(data[1])
+	offset := 2
+	if length&0x80 != 0 {
+		size := length & 0x7f
+		if size == 0 || size > 4 || len(data) < 2+size {
+			return 0, nil, nil, fmt.Errorf("%w: invalid length", ErrMalformed)
+		}
+		length = 0
+		for _, b := range data[2 : 2+size] {
+			length = length<<8 | int(b)
+		}
+		offset += size
+	}
+	if length < 0 || len(data)-offset < length {
+		return 0, nil, nil, fmt.Errorf("%w: element longer than packet", ErrMalformed)
+	}
+	return tag, data[offset : offset+length], data[offset+length:], nil
+}
+
+// readExpected lee un elemento y comprueba su etiqueta
+func readExpected(data []byte, expected byte) (content, rest []byte, err error) {
+	tag, content, rest, err := readTLV(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tag != expected {
+		return nil, nil, fmt.Errorf("%w: expected tag 0x%02x, got 0x%02x", ErrMalformed, expected, tag)
+	}
+	return content, rest, nil
+}
+
+// appendTLV añade un elemento con su longitud en la forma corta o larga
+func appendTLV(dst []byte, tag byte, content []byte) []byte {
+	dst = append(dst, tag)
+	switch length := len(content); {
+	case length < 0x80:
+		dst = append(dst, byte(length))
+	case length <= 0xff:
+		dst = append(dst, 0x81, byte(length))
+	default:
+		dst = append(dst, 0x82, byte(length>>8), byte(length))
+	}
+	return append(dst, content...)
+}
+
+// encodeInteger codifica un entero en complemento a dos con los mínimos bytes
+func encodeInteger(value int64) []byte {
+	var content []byte
+	for {
+		content = append([]byte{byte(value)}, content...)
+		value >>= 8
+		if (value == 0 && content[0]&0x80 == 0) || (value == -1 && content[0]&0x80 != 0) {
+			return content
+		}
+	}
+}
+
+// encodeUnsigned codifica un entero sin signo (Counter32, Gauge32...) sin que parezca negativo
+func encodeUnsigned(value uint64) []byte {
+	var content []byte
+	for {
+		content = append([]byte{byte(value)}, content...)
+		value >>= 8
+		if value == 0 {
+			break
+		}
+	}
+	if content[0]&0x80 != 0 {
+		content = append([]byte{0}, content...)
+	}
+	return content
+}
+
+// decodeInteger decodifica un entero con signo
+func decodeInteger(content []byte) (int64, error) {
+	if len(content) == 0 || len(content) > 8 {
+		return 0, fmt.Errorf("%w: invalid integer", ErrMalformed)
+	}
+	value := int64(int8(content[0]))
+	for _, b := range content[1:] {
+		value = value<<8 | int64(b)
+	}
+	return value, nil
+}
+
+// decodeUnsigned decodifica un entero sin signo
+func decodeUnsigned(content []byte) (uint64, error) {
+	if len(content) == 0 || len(content) > 9 || (len(content) == 9 && content[0] != 0) {
+		return 0, fmt.Errorf("%w: invalid unsigned integer", ErrMalformed)
+	}
+	var value uint64
+	for _, b := range content {
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
+
+// encodeOID codifica un OID en notación de puntos (1.3.6.1...)
+func encodeOID(oid string) ([]byte, error) {
+	parts := strings.Split(strings.TrimPrefix(oid, "."), ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid oid %q", oid)
+	}
+	arcs := make([]uint64, len(parts))
+	for i, part := range parts {
+		arc, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid oid %q", oid)
+		}
+		arcs[i] = arc
+	}
+	if arcs[0] > 2 || (arcs[0] < 2 && arcs[1] >= 40) {
+		return nil, fmt.Errorf("invalid oid %q", oid)
+	}
+
+	content := appendBase128(nil, arcs[0]*40+arcs[1])
+	for _, arc := range arcs[2:] {
+		content = appendBase128(content, arc)
+	}
+	return content, nil
+}
+
+// appendBase128 añade un arco en base 128, con el bit alto en todos los bytes menos el último
+func appendBase128(dst []byte, value uint64) []byte {
+	var chunk [10]byte
+	i := len(chunk) - 1
+	chunk[i] = byte(value & 0x7f)
+	for value >>= 7; value > 0; value >>= 7 {
+		i--
+		chunk[i] = byte(value&0x7f) | 0x80
+	}
+	return append(dst, chunk[i:]...)
+}
+
+// decodeOID decodifica un OID a notación de puntos
+func decodeOID(content []byte) (string, error) {
+	if len(content) == 0 {
+		return "", fmt.Errorf("%w: empty oid", ErrMalformed)
+	}
+
+	var arcs []string
+	var value uint64
+	for i, b := range content {
+		value = value<<7 | uint64(b&0x7f)
+		if b&0x80 != 0 {
+			if i == len(content)-1 || value > 1<<32 {
+				return "", fmt.Errorf("%w: invalid oid", ErrMalformed)
+			}
+			continue
+		}
+		if arcs == nil {
+			first := value / 40
+			if first > 2 {
+				first = 2
+			}
+			arcs = append(arcs, strconv.FormatUint(first, 10), strconv.FormatUint(value-first*40, 10))
+		} else {
+			arcs = append(arcs, strconv.FormatUint(value, 10))
+		}
+		value = 0
+	}
+	return strings.Join(arcs, "."), nil
+}
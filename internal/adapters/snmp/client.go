@@ -0,0 +1,92 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// ErrAgent se devuelve cuando el agente responde con un estado de error
+var ErrAgent = errors.New("snmp agent error")
+
+// Client consulta agentes SNMP v1 o v2c por UDP. Habla el protocolo directamente para no añadir
+// dependencias externas.
+type Client struct {
+	Timeout time.Duration // Espera de cada intento
+	Retries int           // Reintentos tras el primer intento sin respuesta
+}
+
+// NewClient crea un cliente con el plazo indicado por intento y un reintento
+func NewClient(timeout time.Duration) *Client {
+	return &Client{Timeout: timeout, Retries: 1}
+}
+
+// Get lee los OIDs indicados del agente en address (host:puerto)
+func (c *Client) Get(ctx context.Context, address, community string, version int, oids []string) ([]Varbind, error) {
+	request := &Packet{
+		Version:   version,
+		Community: community,
+		PDUType:   PDUGetRequest,
+		RequestID: rand.Int31(),
+	}
+	for _, oid := range oids {
+		request.Varbinds = append(request.Varbinds, Varbind{OID: oid, Type: TagNull})
+	}
+	payload, err := request.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("snmp: %w", err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 65535)
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		deadline := time.Now().Add(c.Timeout)
+		if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+		conn.SetDeadline(deadline)
+
+		if _, err := conn.Write(payload); err != nil {
+			return nil, fmt.Errorf("snmp: %w", err)
+		}
+
+		response, err := c.readResponse(conn, buf, request.RequestID)
+		if err == nil {
+			if response.ErrorStatus != 0 {
+				return nil, fmt.Errorf("%w: status %d at index %d", ErrAgent, response.ErrorStatus, response.ErrorIndex)
+			}
+			return response.Varbinds, nil
+		}
+
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() || ctx.Err() != nil {
+			return nil, fmt.Errorf("snmp: %w", err)
+		}
+	}
+
+	return nil, fmt.Errorf("snmp: no response from %s", address)
+}
+
+// readResponse espera la respuesta a la solicitud, descartando las que correspondan a otras
+func (c *Client) readResponse(conn net.Conn, buf []byte, requestID int32) (*Packet, error) {
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		packet, err := ParsePacket(buf[:n])
+		if err != nil || packet.PDUType != PDUGetResponse || packet.RequestID != requestID {
+			continue
+		}
+		return packet, nil
+	}
+}
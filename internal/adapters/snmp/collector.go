@@ -0,0 +1,155 @@
+// Package snmp integra los sistemas de medición de tanques antiguos (p. ej. consolas Veeder-Root
+// tras un conversor) que solo publican sus lecturas por SNMP. Los agentes se consultan
+// periódicamente o envían traps, y los OIDs configurados se guardan como mediciones.
+package snmp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// Collector guarda como mediciones las lecturas de los agentes SNMP configurados
+type Collector struct {
+	tankService ports.TankService
+	config      *Config
+	client      *Client
+	logger      logger.Logger
+}
+
+// NewCollector crea un colector para los agentes de la configuración
+func NewCollector(tankService ports.TankService, config *Config, client *Client, logger logger.Logger) *Collector {
+	return &Collector{
+		tankService: tankService,
+		config:      config,
+		client:      client,
+		logger:      logger,
+	}
+}
+
+// Poll consulta los OIDs del agente y guarda las mediciones de sus tanques
+func (c *Collector) Poll(ctx context.Context, target *Target) error {
+	varbinds, err := c.client.Get(ctx, target.Address, target.Community, target.Version, target.OIDs())
+	if err != nil {
+		return fmt.Errorf("polling %s: %w", target.Name, err)
+	}
+
+	measurements := target.Measurements(varbinds, time.Now())
+	if len(measurements) == 0 {
+		c.logger.Warn("SNMP poll returned no levels", "target", target.Name)
+	}
+	return c.save(ctx, target, measurements)
+}
+
+// save guarda las mediciones; un tanque que falla no impide guardar los demás
+func (c *Collector) save(ctx context.Context, target *Target, measurements []*domain.Measurement) error {
+	var errs []error
+	for _, measurement := range measurements {
+		measurement.ID = uuid.New().String()
+		if err := c.tankService.AddMeasurement(ctx, measurement); err != nil && !services.IsStaleMeasurement(err) {
+			c.logger.Error("Failed to save SNMP measurement", "target", target.Name, "tank_id", measurement.TankID, "error", err)
+			errs = append(errs, fmt.Errorf("tank %s: %w", measurement.TankID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ListenTraps recibe traps en la dirección UDP indicada hasta que se cancele ctx
+func (c *Collector) ListenTraps(ctx context.Context, address string) error {
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return fmt.Errorf("snmp traps: %w", err)
+	}
+	c.logger.Info("Listening for SNMP traps", "address", conn.LocalAddr().String())
+	return c.ServeTraps(ctx, conn)
+}
+
+// ServeTraps atiende los traps que lleguen por conn hasta que se cancele ctx, y después la
+// cierra. Los informs de SNMPv2c se confirman al agente.
+func (c *Collector) ServeTraps(ctx context.Context, conn net.PacketConn) error {
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("snmp traps: %w", err)
+		}
+
+		packet, err := ParsePacket(buf[:n])
+		if err != nil {
+			c.logger.Warn("Invalid SNMP packet", "source", addr.String(), "error", err)
+			continue
+		}
+
+		if err := c.handleTrap(ctx, addr, packet); err != nil {
+			c.logger.Warn("SNMP trap discarded", "source", addr.String(), "error", err)
+			continue
+		}
+
+		if packet.PDUType == PDUInform {
+			response := *packet
+			response.PDUType = PDUGetResponse
+			if encoded, err := response.Marshal(); err == nil {
+				conn.WriteTo(encoded, addr)
+			}
+		}
+	}
+}
+
+// handleTrap identifica el agente por la IP de origen y la comunidad y guarda las lecturas
+func (c *Collector) handleTrap(ctx context.Context, addr net.Addr, packet *Packet) error {
+	switch packet.PDUType {
+	case PDUTrapV1, PDUTrapV2, PDUInform:
+	default:
+		return fmt.Errorf("unexpected pdu 0x%02x", packet.PDUType)
+	}
+
+	target := c.trapTarget(addr, packet)
+	if target == nil {
+		return errors.New("unknown agent or community")
+	}
+
+	measurements := target.Measurements(packet.Varbinds, time.Now())
+	c.logger.Debug("SNMP trap received", "target", target.Name, "measurements", len(measurements))
+	return c.save(ctx, target, measurements)
+}
+
+// trapTarget busca el agente que envió el trap. En SNMPv1 se usa la dirección del agente del
+// propio trap, que no cambia al atravesar un NAT o un reenviador de traps.
+func (c *Collector) trapTarget(addr net.Addr, packet *Packet) *Target {
+	var source net.IP
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		source = udpAddr.IP
+	}
+	if packet.PDUType == PDUTrapV1 && packet.AgentAddr != nil && !packet.AgentAddr.IsUnspecified() {
+		source = packet.AgentAddr
+	}
+
+	for _, target := range c.config.Targets {
+		ip := net.ParseIP(target.Host())
+		if ip == nil || !ip.Equal(source) {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(target.Community), []byte(packet.Community)) == 1 {
+			return target
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package snmp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrInvalidConfig se devuelve cuando el archivo de SNMP no es válido
+var ErrInvalidConfig = errors.New("invalid snmp file")
+
+// targetNamePattern limita los nombres de los agentes a los que pueden usarse en los logs y en
+// los nombres de las tareas
+var targetNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// defaultPort es el puerto SNMP de los agentes cuya dirección no lo indica
+const defaultPort = "161"
+
+// Config es la lista de agentes SNMP (medidores o conversores) y sus tanques
+type Config struct {
+	Targets []*Target
+}
+
+// Target es un agente SNMP. Con Interval > 0 se consulta periódicamente; sus traps se
+// reconocen por la IP de origen y la comunidad.
+type Target struct {
+	Name      string
+	Address   string // host:puerto
+	Community string
+	Version   int // Version1 o Version2c
+	Interval  time.Duration
+	Tanks     []*TankMapping
+}
+
+// TankMapping asocia los OIDs de un agente con las lecturas de un tanque
+type TankMapping struct {
+	TankID           string
+	LevelOID         string
+	LevelScale       float64 // Factor aplicado al valor leído (1 por defecto)
+	TemperatureOID   string
+	TemperatureScale float64
+}
+
+// fileConfig es el formato del archivo de SNMP
+type fileConfig struct {
+	Targets []targetConfig `yaml:"targets"`
+}
+
+type targetConfig struct {
+	Name      string          `yaml:"name"`
+	Address   string          `yaml:"address"`
+	Community string          `yaml:"community"`
+	Version   string          `yaml:"version"`
+	Interval  time.Duration   `yaml:"interval"`
+	Tanks     []tankOIDConfig `yaml:"tanks"`
+}
+
+type tankOIDConfig struct {
+	TankID           string  `yaml:"tank_id"`
+	LevelOID         string  `yaml:"level_oid"`
+	LevelScale       float64 `yaml:"level_scale"`
+	TemperatureOID   string  `yaml:"temperature_oid"`
+	TemperatureScale float64 `yaml:"temperature_scale"`
+}
+
+// LoadFile lee el archivo de SNMP indicado
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse interpreta un documento YAML con los agentes SNMP y los OIDs de cada tanque. Los campos
+// desconocidos se rechazan para que una errata no pase inadvertida.
+func Parse(r io.Reader) (*Config, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file fileConfig
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	config := &Config{}
+	names := make(map[string]bool, len(file.Targets))
+	for _, entry := range file.Targets {
+		target, err := entry.target()
+		if err != nil {
+			return nil, fmt.Errorf("%w: target %q: %v", ErrInvalidConfig, entry.Name, err)
+		}
+		if names[target.Name] {
+			return nil, fmt.Errorf("%w: duplicate target %q", ErrInvalidConfig, target.Name)
+		}
+		names[target.Name] = true
+		config.Targets = append(config.Targets, target)
+	}
+
+	return config, nil
+}
+
+// target valida el agente y completa los valores predeterminados
+func (c targetConfig) target() (*Target, error) {
+	if !targetNamePattern.MatchString(c.Name) {
+		return nil, errors.New("invalid name")
+	}
+
+	target := &Target{Name: c.Name, Community: c.Community, Interval: c.Interval}
+	if target.Community == "" {
+		target.Community = "public"
+	}
+
+	switch c.Version {
+	case "", "2c":
+		target.Version = Version2c
+	case "1":
+		target.Version = Version1
+	default:
+		return nil, fmt.Errorf("unsupported version %q", c.Version)
+	}
+
+	if c.Address == "" {
+		return nil, errors.New("address is required")
+	}
+	target.Address = c.Address
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		target.Address = net.JoinHostPort(c.Address, defaultPort)
+	}
+	if c.Interval < 0 {
+		return nil, errors.New("interval must not be negative")
+	}
+	if len(c.Tanks) == 0 {
+		return nil, errors.New("at least one tank is required")
+	}
+
+	for _, tank := range c.Tanks {
+		if tank.TankID == "" {
+			return nil, errors.New("tank without tank_id")
+		}
+		for _, oid := range []string{tank.LevelOID, tank.TemperatureOID} {
+			if oid == "" {
+				continue
+			}
+			if _, err := encodeOID(oid); err != nil {
+				return nil, err
+			}
+		}
+		if tank.LevelOID == "" {
+			return nil, fmt.Errorf("tank %q without level_oid", tank.TankID)
+		}
+
+		mapping := &TankMapping{
+			TankID:           tank.TankID,
+			LevelOID:         normalizeOID(tank.LevelOID),
+			LevelScale:       tank.LevelScale,
+			TemperatureOID:   normalizeOID(tank.TemperatureOID),
+			TemperatureScale: tank.TemperatureScale,
+		}
+		if mapping.LevelScale == 0 {
+			mapping.LevelScale = 1
+		}
+		if mapping.TemperatureScale == 0 {
+			mapping.TemperatureScale = 1
+		}
+		target.Tanks = append(target.Tanks, mapping)
+	}
+
+	return target, nil
+}
+
+// normalizeOID quita el punto inicial de la notación de algunas herramientas (.1.3.6...)
+func normalizeOID(oid string) string {
+	if len(oid) > 0 && oid[0] == '.' {
+		return oid[1:]
+	}
+	return oid
+}
+
+// OIDs devuelve los OIDs que se consultan en cada sondeo del agente
+func (t *Target) OIDs() []string {
+	var oids []string
+	for _, tank := range t.Tanks {
+		oids = append(oids, tank.LevelOID)
+		if tank.TemperatureOID != "" {
+			oids = append(oids, tank.TemperatureOID)
+		}
+	}
+	return oids
+}
+
+// Host devuelve la IP o el nombre del agente, sin el puerto
+func (t *Target) Host() string {
+	host, _, _ := net.SplitHostPort(t.Address)
+	return host
+}
+
+// Measurements convierte los valores recibidos (de un sondeo o de un trap) en mediciones. Solo
+// se generan las de los tanques cuyo nivel está entre los valores y es numérico; los traps
+// suelen incluir únicamente algunos OIDs.
+func (t *Target) Measurements(varbinds []Varbind, timestamp time.Time) []*domain.Measurement {
+	values := make(map[string]float64, len(varbinds))
+	for _, varbind := range varbinds {
+		if value, ok := varbind.Float(); ok {
+			values[normalizeOID(varbind.OID)] = value
+		}
+	}
+
+	var measurements []*domain.Measurement
+	for _, tank := range t.Tanks {
+		level, ok := values[tank.LevelOID]
+		if !ok {
+			continue
+		}
+
+		measurement := &domain.Measurement{
+			TankID:    tank.TankID,
+			Level:     level * tank.LevelScale,
+			Timestamp: timestamp,
+		}
+		if temperature, ok := values[tank.TemperatureOID]; ok && tank.TemperatureOID != "" {
+			measurement.Temperature = temperature * tank.TemperatureScale
+		}
+		measurements = append(measurements, measurement)
+	}
+	return measurements
+}
@@ -0,0 +1,309 @@
+package snmp
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Versiones de SNMP admitidas, con el valor que viaja en el paquete
+const (
+	Version1  = 0
+	Version2c = 1
+)
+
+// Tipos de PDU
+const (
+	PDUGetRequest  byte = 0xA0
+	PDUGetResponse byte = 0xA2
+	PDUTrapV1      byte = 0xA4
+	PDUInform      byte = 0xA6
+	PDUTrapV2      byte = 0xA7
+)
+
+// Varbind es un par OID-valor. Value es int64 (Integer), uint64 (Counter32, Gauge32,
+// TimeTicks, Counter64), []byte (OctetString, Opaque), string (OID), net.IP (IpAddress) o nil
+// (Null y las excepciones noSuchObject, noSuchInstance y endOfMibView).
+type Varbind struct {
+	OID   string
+	Type  byte
+	Value interface{}
+}
+
+// Float devuelve el valor como número. Los conversores de los medidores antiguos a menudo
+// publican las lecturas como texto, así que también se aceptan cadenas numéricas.
+func (v Varbind) Float() (float64, bool) {
+	var number float64
+	switch value := v.Value.(type) {
+	case int64:
+		number = float64(value)
+	case uint64:
+		number = float64(value)
+	case []byte:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(string(value)), 64)
+		if err != nil {
+			return 0, false
+		}
+		number = parsed
+	default:
+		return 0, false
+	}
+	if math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, false
+	}
+	return number, true
+}
+
+// Packet es un mensaje SNMP v1 o v2c
+type Packet struct {
+	Version   int
+	Community string
+	PDUType   byte
+
+	RequestID   int32
+	ErrorStatus int
+	ErrorIndex  int
+
+	// Campos de los traps de SNMPv1
+	Enterprise   string
+	AgentAddr    net.IP
+	GenericTrap  int
+	SpecificTrap int
+	Timestamp    uint32
+
+	Varbinds []Varbind
+}
+
+// Marshal codifica el paquete en BER
+func (p *Packet) Marshal() ([]byte, error) {
+	var varbinds []byte
+	for _, varbind := range p.Varbinds {
+		encoded, err := marshalVarbind(varbind)
+		if err != nil {
+			return nil, err
+		}
+		varbinds = append(varbinds, encoded...)
+	}
+
+	var pdu []byte
+	if p.PDUType == PDUTrapV1 {
+		enterprise, err := encodeOID(p.Enterprise)
+		if err != nil {
+			return nil, err
+		}
+		agent := p.AgentAddr.To4()
+		if agent == nil {
+			agent = net.IPv4zero.To4()
+		}
+		pdu = appendTLV(pdu, TagOID, enterprise)
+		pdu = appendTLV(pdu, TagIPAddress, agent)
+		pdu = appendTLV(pdu, TagInteger, encodeInteger(int64(p.GenericTrap)))
+		pdu = appendTLV(pdu, TagInteger, encodeInteger(int64(p.SpecificTrap)))
+		pdu = appendTLV(pdu, TagTimeTicks, encodeUnsigned(uint64(p.Timestamp)))
+	} else {
+		pdu = appendTLV(pdu, TagInteger, encodeInteger(int64(p.RequestID)))
+		pdu = appendTLV(pdu, TagInteger, encodeInteger(int64(p.ErrorStatus)))
+		pdu = appendTLV(pdu, TagInteger, encodeInteger(int64(p.ErrorIndex)))
+	}
+	pdu = appendTLV(pdu, TagSequence, varbinds)
+
+	var message []byte
+	message = appendTLV(message, TagInteger, encodeInteger(int64(p.Version)))
+	message = appendTLV(message, TagOctetString, []byte(p.Community))
+	message = appendTLV(message, p.PDUType, pdu)
+	return appendTLV(nil, TagSequence, message), nil
+}
+
+// marshalVarbind codifica un par OID-valor
+func marshalVarbind(varbind Varbind) ([]byte, error) {
+	oid, err := encodeOID(varbind.OID)
+	if err != nil {
+		return nil, err
+	}
+
+	var value []byte
+	switch v := varbind.Value.(type) {
+	case nil:
+		value = nil
+	case int64:
+		value = encodeInteger(v)
+	case int:
+		value = encodeInteger(int64(v))
+	case uint64:
+		value = encodeUnsigned(v)
+	case []byte:
+		value = v
+	case string:
+		if varbind.Type == TagOID {
+			if value, err = encodeOID(v); err != nil {
+				return nil, err
+			}
+		} else {
+			value = []byte(v)
+		}
+	case net.IP:
+		value = v.To4()
+	default:
+		return nil, fmt.Errorf("unsupported varbind value %T", varbind.Value)
+	}
+
+	tag := varbind.Type
+	if tag == 0 {
+		tag = TagNull
+	}
+	content := appendTLV(nil, TagOID, oid)
+	content = appendTLV(content, tag, value)
+	return appendTLV(nil, TagSequence, content), nil
+}
+
+// ParsePacket decodifica un mensaje SNMP v1 o v2c
+func ParsePacket(data []byte) (*Packet, error) {
+	message, _, err := readExpected(data, TagSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	content, message, err := readExpected(message, TagInteger)
+	if err != nil {
+		return nil, err
+	}
+	version, err := decodeInteger(content)
+	if err != nil {
+		return nil, err
+	}
+	if version != Version1 && version != Version2c {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrMalformed, version)
+	}
+
+	community, message, err := readExpected(message, TagOctetString)
+	if err != nil {
+		return nil, err
+	}
+
+	pduType, pdu, _, err := readTLV(message)
+	if err != nil {
+		return nil, err
+	}
+
+	packet := &Packet{Version: int(version), Community: string(community), PDUType: pduType}
+	if pduType == PDUTrapV1 {
+		pdu, err = packet.parseTrapV1Header(pdu)
+	} else {
+		pdu, err = packet.parseHeader(pdu)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	varbinds, _, err := readExpected(pdu, TagSequence)
+	if err != nil {
+		return nil, err
+	}
+	for len(varbinds) > 0 {
+		var encoded []byte
+		if encoded, varbinds, err = readExpected(varbinds, TagSequence); err != nil {
+			return nil, err
+		}
+		varbind, err := parseVarbind(encoded)
+		if err != nil {
+			return nil, err
+		}
+		packet.Varbinds = append(packet.Varbinds, varbind)
+	}
+
+	return packet, nil
+}
+
+// parseHeader lee el ID de la solicitud y el estado de error de las PDU que no son traps v1
+func (p *Packet) parseHeader(pdu []byte) ([]byte, error) {
+	fields := make([]int64, 3)
+	for i := range fields {
+		content, rest, err := readExpected(pdu, TagInteger)
+		if err != nil {
+			return nil, err
+		}
+		if fields[i], err = decodeInteger(content); err != nil {
+			return nil, err
+		}
+		pdu = rest
+	}
+	p.RequestID = int32(fields[0])
+	p.ErrorStatus = int(fields[1])
+	p.ErrorIndex = int(fields[2])
+	return pdu, nil
+}
+
+// parseTrapV1Header lee la cabecera de los traps de SNMPv1
+func (p *Packet) parseTrapV1Header(pdu []byte) ([]byte, error) {
+	content, pdu, err := readExpected(pdu, TagOID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Enterprise, err = decodeOID(content); err != nil {
+		return nil, err
+	}
+
+	content, pdu, err = readExpected(pdu, TagIPAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) != 4 {
+		return nil, fmt.Errorf("%w: invalid agent address", ErrMalformed)
+	}
+	p.AgentAddr = net.IP(append([]byte(nil), content...))
+
+	for _, field := range []*int{&p.GenericTrap, &p.SpecificTrap} {
+		if content, pdu, err = readExpected(pdu, TagInteger); err != nil {
+			return nil, err
+		}
+		value, err := decodeInteger(content)
+		if err != nil {
+			return nil, err
+		}
+		*field = int(value)
+	}
+
+	if content, pdu, err = readExpected(pdu, TagTimeTicks); err != nil {
+		return nil, err
+	}
+	timestamp, err := decodeUnsigned(content)
+	if err != nil {
+		return nil, err
+	}
+	p.Timestamp = uint32(timestamp)
+	return pdu, nil
+}
+
+// parseVarbind decodifica un par OID-valor
+func parseVarbind(data []byte) (Varbind, error) {
+	content, data, err := readExpected(data, TagOID)
+	if err != nil {
+		return Varbind{}, err
+	}
+	oid, err := decodeOID(content)
+	if err != nil {
+		return Varbind{}, err
+	}
+
+	tag, content, _, err := readTLV(data)
+	if err != nil {
+		return Varbind{}, err
+	}
+
+	varbind := Varbind{OID: oid, Type: tag}
+	switch tag {
+	case TagInteger:
+		varbind.Value, err = decodeInteger(content)
+	case TagCounter32, TagGauge32, TagTimeTicks, TagCounter64:
+		varbind.Value, err = decodeUnsigned(content)
+	case TagOctetString, TagOpaque:
+		varbind.Value = append([]byte(nil), content...)
+	case TagOID:
+		varbind.Value, err = decodeOID(content)
+	case TagIPAddress:
+		varbind.Value = net.IP(append([]byte(nil), content...))
+	}
+	return varbind, err
+}
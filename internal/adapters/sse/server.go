@@ -0,0 +1,110 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"monitor-tanques/internal/adapters/broadcast"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/pkg/logger"
+)
+
+// Transport es la etiqueta de las suscripciones SSE en las métricas del hub
+const Transport = "sse"
+
+// ErrNoTopics indica que el cliente no indicó ningún tema. Con SSE los temas se fijan al
+// conectar, porque el cliente no puede enviar mensajes por la misma conexión.
+var ErrNoTopics = errors.New("at least one topic is required")
+
+// ServerConfig contiene los límites de las conexiones SSE
+type ServerConfig struct {
+	KeepAliveInterval time.Duration // Cada cuánto se envía un comentario para mantener viva la conexión
+	WriteTimeout      time.Duration
+}
+
+// Server atiende las conexiones Server-Sent Events en tiempo real, para los clientes que no
+// pueden usar WebSocket (p. ej. detrás de proxies que no lo admiten)
+type Server struct {
+	hub    *broadcast.Hub
+	config ServerConfig
+	logger logger.Logger
+}
+
+// NewServer crea el adaptador SSE del hub
+func NewServer(hub *broadcast.Hub, config ServerConfig, logger logger.Logger) *Server {
+	return &Server{
+		hub:    hub,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Serve suscribe al cliente a los temas y le transmite los eventos que puede ver hasta que se
+// desconecta o el hub cierra la suscripción. Si los temas no son válidos devuelve el error sin
+// escribir la respuesta.
+func (s *Server) Serve(w http.ResponseWriter, r *http.Request, principal *domain.Principal, topics []string) error {
+	if len(topics) == 0 {
+		return ErrNoTopics
+	}
+
+	subscriber := s.hub.Subscribe(Transport, principal)
+	defer s.hub.Remove(subscriber)
+
+	subscribed, err := subscriber.Subscribe(topics)
+	if err != nil {
+		return err
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no") // Evita que nginx acumule los eventos
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	write := func(chunk string) error {
+		// El plazo de escritura del servidor se renueva en cada evento: la conexión dura lo que
+		// el cliente permanezca conectado. No todos los ResponseWriter lo admiten.
+		controller.SetWriteDeadline(time.Now().Add(s.config.WriteTimeout))
+		if _, err := fmt.Fprint(w, chunk); err != nil {
+			return err
+		}
+		return controller.Flush()
+	}
+
+	ready, _ := json.Marshal(map[string]interface{}{"type": "subscribed", "topics": subscribed})
+	if err := write(fmt.Sprintf("event: subscribed\ndata: %s\n\n", ready)); err != nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(s.config.KeepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-subscriber.Done():
+			return nil
+		case <-ticker.C:
+			if err := write(": ping\n\n"); err != nil {
+				return nil
+			}
+		case event := <-subscriber.Events():
+			if !s.hub.Visible(subscriber, event) {
+				continue
+			}
+			encoded, err := json.Marshal(event)
+			if err != nil {
+				s.logger.Error("Failed to encode live event", "error", err, "type", event.Type)
+				continue
+			}
+			if err := write(fmt.Sprintf("event: %s\ndata: %s\n\n", event.Type, encoded)); err != nil {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package tankimport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// Formatos de archivo admitidos
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Errores de lectura del archivo completo; los problemas de cada fila se anotan en la fila
+var (
+	ErrInvalidFile       = errors.New("invalid tank import file")
+	ErrUnsupportedFormat = errors.New("unsupported tank import format")
+)
+
+// columns son las columnas reconocidas en la cabecera; name y capacity son obligatorias
+var columns = map[string]bool{
+	"id":              true,
+	"name":            true,
+	"site_id":         true,
+	"group_id":        true,
+	"capacity":        true,
+	"current_level":   true,
+	"liquid_type":     true,
+	"alert_threshold": true,
+	"latitude":        true,
+	"longitude":       true,
+	"reorder_level":   true,
+	"lead_time_days":  true,
+	"delivery_size":   true,
+	"timezone":        true,
+}
+
+// FormatForContentType devuelve el formato correspondiente a un tipo de contenido
+func FormatForContentType(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return FormatCSV, true
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return FormatXLSX, true
+	default:
+		return "", false
+	}
+}
+
+// Parse lee los tanques de una hoja de cálculo CSV o XLSX. La primera fila es la cabecera con
+// los nombres de las columnas; las filas vacías se ignoran. Los valores que no pueden leerse se
+// anotan como errores de su fila para incluirlos en el informe de validación.
+func Parse(r io.Reader, format string) ([]*domain.TankImportRow, error) {
+	var records [][]string
+	var err error
+	switch format {
+	case FormatCSV:
+		records, err = readCSV(r)
+	case FormatXLSX:
+		records, err = readXLSX(r)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidFile)
+	}
+
+	header := make([]string, len(records[0]))
+	present := make(map[string]bool)
+	for i, name := range records[0] {
+		name = normalizeColumn(name)
+		if name == "" {
+			continue
+		}
+		if !columns[name] {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidFile, name)
+		}
+		if present[name] {
+			return nil, fmt.Errorf("%w: duplicated column %q", ErrInvalidFile, name)
+		}
+		header[i] = name
+		present[name] = true
+	}
+	for _, required := range []string{"name", "capacity"} {
+		if !present[required] {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidFile, required)
+		}
+	}
+
+	rows := make([]*domain.TankImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if isBlank(record) {
+			continue
+		}
+		rows = append(rows, parseRow(i+2, header, record))
+	}
+
+	return rows, nil
+}
+
+// parseRow construye el tanque de una fila a partir de sus celdas
+func parseRow(number int, header []string, record []string) *domain.TankImportRow {
+	row := &domain.TankImportRow{Row: number, Tank: &domain.Tank{}}
+	tank := row.Tank
+
+	var latitude, longitude *float64
+	for i, name := range header {
+		if name == "" || i >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+
+		switch name {
+		case "id":
+			tank.ID = value
+		case "name":
+			tank.Name = value
+		case "site_id":
+			tank.SiteID = value
+		case "group_id":
+			tank.GroupID = value
+		case "liquid_type":
+			tank.LiquidType = value
+		case "timezone":
+			tank.Timezone = value
+		default:
+			number, ok := parseNumber(value)
+			if !ok {
+				row.AddError(name, "El valor no es un número")
+				continue
+			}
+			switch name {
+			case "capacity":
+				tank.Capacity = number
+			case "current_level":
+				tank.CurrentLevel = number
+			case "alert_threshold":
+				tank.AlertThreshold = number
+			case "latitude":
+				latitude = &number
+			case "longitude":
+				longitude = &number
+			case "reorder_level":
+				tank.Reorder.ReorderLevel = number
+			case "lead_time_days":
+				tank.Reorder.LeadTimeDays = number
+			case "delivery_size":
+				tank.Reorder.DeliverySize = number
+			}
+		}
+	}
+
+	switch {
+	case latitude != nil && longitude != nil:
+		tank.Location = &domain.GeoLocation{Latitude: *latitude, Longitude: *longitude}
+	case latitude != nil || longitude != nil:
+		row.AddError("", "Indique la latitud y la longitud")
+	}
+
+	return row
+}
+
+// parseNumber interpreta un número con punto o, como exportan las hojas en español, con coma
+// decimal
+func parseNumber(value string) (float64, bool) {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number, true
+	}
+	if strings.Count(value, ",") == 1 && !strings.Contains(value, ".") {
+		if number, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64); err == nil {
+			return number, true
+		}
+	}
+	return 0, false
+}
+
+// normalizeColumn admite cabeceras con mayúsculas o espacios ("Alert Threshold")
+func normalizeColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	return strings.Join(strings.Fields(name), "_")
+}
+
+// isBlank indica si todas las celdas de la fila están vacías
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
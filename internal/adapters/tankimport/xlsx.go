@@ -0,0 +1,198 @@
+package tankimport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Las hojas XLSX se leen sin dependencias externas: el archivo es un ZIP con XML (Office Open
+// XML). Solo se lee la primera hoja del libro, y de cada celda su valor guardado; las fórmulas
+// se toman con el último resultado calculado por la hoja de cálculo.
+
+// maxXMLSize limita lo que se descomprime de cada archivo del libro
+const maxXMLSize = 32 << 20
+
+// workbookXML es la lista de hojas del libro (xl/workbook.xml)
+type workbookXML struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// relationshipsXML resuelve los identificadores de las hojas a sus archivos (xl/_rels/workbook.xml.rels)
+type relationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// sharedStringsXML contiene los textos compartidos entre celdas (xl/sharedStrings.xml)
+type sharedStringsXML struct {
+	Items []inlineStringXML `xml:"si"`
+}
+
+// inlineStringXML es un texto simple (t) o con formato por tramos (r/t)
+type inlineStringXML struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s inlineStringXML) String() string {
+	if len(s.Runs) == 0 {
+		return s.Text
+	}
+	var builder strings.Builder
+	for _, run := range s.Runs {
+		builder.WriteString(run.Text)
+	}
+	return builder.String()
+}
+
+// worksheetXML son las filas de una hoja (xl/worksheets/sheetN.xml)
+type worksheetXML struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string          `xml:"r,attr"`
+			Type   string          `xml:"t,attr"`
+			Value  string          `xml:"v"`
+			Inline inlineStringXML `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX lee todas las filas de la primera hoja de un libro XLSX
+func readXLSX(r io.Reader) ([][]string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared sharedStringsXML
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(file, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	file, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing worksheet %s", ErrInvalidFile, sheetPath)
+	}
+	var sheet worksheetXML
+	if err := decodeXML(file, &sheet); err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		record := make([]string, 0, len(row.Cells))
+		for i, cell := range row.Cells {
+			// Las celdas vacías pueden omitirse; la referencia (p. ej. "C2") indica la columna
+			column := i
+			if cell.Ref != "" {
+				column = columnIndex(cell.Ref)
+			}
+			for len(record) < column {
+				record = append(record, "")
+			}
+
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(shared.Items) {
+					return nil, fmt.Errorf("%w: invalid shared string in %s", ErrInvalidFile, cell.Ref)
+				}
+				value = shared.Items[index].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			}
+			record = append(record, value)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// firstSheetPath devuelve el archivo de la primera hoja del libro
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook workbookXML
+	var relationships relationshipsXML
+	workbookFile, hasWorkbook := files["xl/workbook.xml"]
+	relsFile, hasRels := files["xl/_rels/workbook.xml.rels"]
+	if !hasWorkbook || !hasRels {
+		return "xl/worksheets/sheet1.xml", nil
+	}
+	if err := decodeXML(workbookFile, &workbook); err != nil {
+		return "", err
+	}
+	if err := decodeXML(relsFile, &relationships); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("%w: workbook without sheets", ErrInvalidFile)
+	}
+
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		// Los destinos son relativos a xl/, salvo que empiecen por "/"
+		if strings.HasPrefix(relationship.Target, "/") {
+			return strings.TrimPrefix(relationship.Target, "/"), nil
+		}
+		return path.Join("xl", relationship.Target), nil
+	}
+
+	return "", fmt.Errorf("%w: first sheet not found", ErrInvalidFile)
+}
+
+// decodeXML decodifica un archivo XML del libro
+func decodeXML(file *zip.File, out interface{}) error {
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer reader.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(reader, maxXMLSize)).Decode(out); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidFile, file.Name, err)
+	}
+	return nil
+}
+
+// columnIndex convierte la columna de una referencia de celda ("AB12") en un índice desde 0
+func columnIndex(ref string) int {
+	index := 0
+	for _, char := range ref {
+		if char < 'A' || char > 'Z' {
+			break
+		}
+		index = index*26 + int(char-'A'+1)
+	}
+	return index - 1
+}
@@ -0,0 +1,133 @@
+package webhooks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig se devuelve cuando el archivo de webhooks no es válido
+var ErrInvalidConfig = errors.New("invalid webhooks file")
+
+// sourceNamePattern limita los nombres de las plataformas a los que pueden ir en la URL
+var sourceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// fileConfig es el formato del archivo de webhooks entrantes
+type fileConfig struct {
+	Sources []sourceConfig `yaml:"sources"`
+}
+
+type sourceConfig struct {
+	Name            string       `yaml:"name"`
+	Token           string       `yaml:"token"`
+	Records         string       `yaml:"records"`
+	Fields          fieldsConfig `yaml:"fields"`
+	TimestampFormat string       `yaml:"timestamp_format"`
+	LevelScale      float64      `yaml:"level_scale"`
+}
+
+type fieldsConfig struct {
+	TankID         string `yaml:"tank_id"`
+	SensorID       string `yaml:"sensor_id"`
+	Level          string `yaml:"level"`
+	Temperature    string `yaml:"temperature"`
+	Timestamp      string `yaml:"timestamp"`
+	BatteryVoltage string `yaml:"battery_voltage"`
+	SignalStrength string `yaml:"rssi"`
+}
+
+// LoadFile lee el archivo de webhooks indicado
+func LoadFile(path string) (map[string]*Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse interpreta un documento YAML con los mapeos de cada plataforma, indexados por nombre.
+// Los campos desconocidos se rechazan para que una errata no pase inadvertida.
+func Parse(r io.Reader) (map[string]*Mapping, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var file fileConfig
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	mappings := make(map[string]*Mapping, len(file.Sources))
+	for _, source := range file.Sources {
+		if !sourceNamePattern.MatchString(source.Name) {
+			return nil, fmt.Errorf("%w: invalid source name %q", ErrInvalidConfig, source.Name)
+		}
+		if _, repeated := mappings[source.Name]; repeated {
+			return nil, fmt.Errorf("%w: duplicate source %q", ErrInvalidConfig, source.Name)
+		}
+		if source.Token == "" {
+			return nil, fmt.Errorf("%w: source %q without token", ErrInvalidConfig, source.Name)
+		}
+
+		mapping, err := source.mapping()
+		if err != nil {
+			return nil, fmt.Errorf("%w: source %q: %v", ErrInvalidConfig, source.Name, err)
+		}
+		mappings[source.Name] = mapping
+	}
+
+	return mappings, nil
+}
+
+// mapping compila las rutas de la plataforma
+func (s sourceConfig) mapping() (*Mapping, error) {
+	mapping := &Mapping{
+		Name:            s.Name,
+		Token:           s.Token,
+		TimestampFormat: s.TimestampFormat,
+		LevelScale:      s.LevelScale,
+	}
+
+	switch mapping.TimestampFormat {
+	case "":
+		mapping.TimestampFormat = TimestampAuto
+	case TimestampAuto, TimestampRFC3339, TimestampUnix, TimestampUnixMs:
+	default:
+		return nil, fmt.Errorf("unknown timestamp_format %q", s.TimestampFormat)
+	}
+
+	if s.Fields.TankID == "" || s.Fields.Level == "" {
+		return nil, errors.New("fields tank_id and level are required")
+	}
+
+	paths := []struct {
+		expression string
+		target     **Path
+	}{
+		{s.Records, &mapping.Records},
+		{s.Fields.TankID, &mapping.TankID},
+		{s.Fields.SensorID, &mapping.SensorID},
+		{s.Fields.Level, &mapping.Level},
+		{s.Fields.Temperature, &mapping.Temperature},
+		{s.Fields.Timestamp, &mapping.Timestamp},
+		{s.Fields.BatteryVoltage, &mapping.BatteryVoltage},
+		{s.Fields.SignalStrength, &mapping.SignalStrength},
+	}
+	for _, p := range paths {
+		if p.expression == "" {
+			continue
+		}
+		path, err := ParsePath(p.expression)
+		if err != nil {
+			return nil, err
+		}
+		*p.target = path
+	}
+
+	return mapping, nil
+}
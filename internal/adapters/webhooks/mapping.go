@@ -0,0 +1,213 @@
+// Package webhooks traduce los datos que las plataformas IoT de terceros envían por webhook a
+// mediciones de la API. Cada plataforma se describe con un mapeo declarativo (rutas al estilo
+// JSONPath hasta el ID del tanque, el nivel, la temperatura...), sin código propio por proveedor.
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrInvalidPayload se devuelve cuando el cuerpo recibido no es JSON o no tiene registros
+var ErrInvalidPayload = errors.New("invalid webhook payload")
+
+// Formatos de las marcas de tiempo recibidas
+const (
+	TimestampAuto    = "auto"    // ISO 8601 con zona horaria o segundos/milisegundos según su magnitud
+	TimestampRFC3339 = "rfc3339" // 2024-05-01T10:00:00Z
+	TimestampUnix    = "unix"    // Segundos desde 1970
+	TimestampUnixMs  = "unix_ms" // Milisegundos desde 1970
+)
+
+// Mapping describe cómo extraer mediciones del webhook de una plataforma
+type Mapping struct {
+	Name  string
+	Token string // Secreto compartido que la plataforma envía en cada solicitud
+
+	// Records localiza la lista de lecturas en el documento; sin ella el documento es una lectura
+	Records *Path
+
+	// Rutas de cada campo, relativas a la lectura. TankID y Level son obligatorias.
+	TankID         *Path
+	SensorID       *Path
+	Level          *Path
+	Temperature    *Path
+	Timestamp      *Path
+	BatteryVoltage *Path
+	SignalStrength *Path
+
+	TimestampFormat string  // auto (por defecto), rfc3339, unix o unix_ms
+	LevelScale      float64 // Factor aplicado al nivel recibido, p. ej. para convertir unidades
+}
+
+// Record es el resultado de traducir una lectura: la medición o el motivo por el que se descartó
+type Record struct {
+	Index       int // Posición de la lectura en el documento
+	Measurement *domain.Measurement
+	Error       string // Mensaje fijo, para poder traducirlo
+}
+
+// Extract traduce el documento recibido a mediciones. Las lecturas incompletas o con valores
+// inválidos se devuelven con su error sin impedir el resto.
+func (m *Mapping) Extract(payload []byte) ([]*Record, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	readings := []interface{}{document}
+	if m.Records != nil {
+		readings = m.Records.Select(document)
+		if len(readings) == 1 {
+			// $.data sin [*] también se acepta cuando data es la lista
+			if list, ok := readings[0].([]interface{}); ok {
+				readings = list
+			}
+		}
+		if len(readings) == 0 {
+			return nil, fmt.Errorf("%w: no records at %s", ErrInvalidPayload, m.Records)
+		}
+	}
+
+	records := make([]*Record, 0, len(readings))
+	for i, reading := range readings {
+		measurement, problem := m.measurement(reading)
+		records = append(records, &Record{Index: i, Measurement: measurement, Error: problem})
+	}
+	return records, nil
+}
+
+// measurement traduce una lectura; devuelve el mensaje del problema si no es válida
+func (m *Mapping) measurement(reading interface{}) (*domain.Measurement, string) {
+	tankID, ok := selectString(m.TankID, reading)
+	if !ok || tankID == "" {
+		return nil, "Falta el ID del tanque"
+	}
+
+	level, ok := selectNumber(m.Level, reading)
+	if !ok {
+		return nil, "Falta el nivel o no es un número"
+	}
+	if m.LevelScale != 0 {
+		level *= m.LevelScale
+	}
+
+	measurement := &domain.Measurement{TankID: tankID, Level: level}
+
+	if m.SensorID != nil {
+		measurement.SensorID, _ = selectString(m.SensorID, reading)
+	}
+	if m.Temperature != nil {
+		if temperature, ok := selectNumber(m.Temperature, reading); ok {
+			measurement.Temperature = temperature
+		}
+	}
+	if m.BatteryVoltage != nil {
+		if voltage, ok := selectNumber(m.BatteryVoltage, reading); ok {
+			measurement.BatteryVoltage = &voltage
+		}
+	}
+	if m.SignalStrength != nil {
+		if rssi, ok := selectNumber(m.SignalStrength, reading); ok {
+			measurement.SignalStrength = &rssi
+		}
+	}
+	if m.Timestamp != nil {
+		if values := m.Timestamp.Select(reading); len(values) > 0 {
+			timestamp, err := parseTimestamp(values[0], m.TimestampFormat)
+			if err != nil {
+				return nil, "La marca de tiempo no es válida"
+			}
+			measurement.Timestamp = timestamp
+		}
+	}
+
+	return measurement, ""
+}
+
+// selectString devuelve el valor de la ruta como texto; los números se aceptan como IDs
+func selectString(path *Path, reading interface{}) (string, bool) {
+	values := path.Select(reading)
+	if len(values) == 0 {
+		return "", false
+	}
+	switch v := values[0].(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
+
+// selectNumber devuelve el valor de la ruta como número; muchas plataformas envían los números
+// como texto, así que también se aceptan cadenas numéricas
+func selectNumber(path *Path, reading interface{}) (float64, bool) {
+	values := path.Select(reading)
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	var number float64
+	var err error
+	switch v := values[0].(type) {
+	case json.Number:
+		number, err = v.Float64()
+	case string:
+		number, err = strconv.ParseFloat(strings.TrimSpace(v), 64)
+	default:
+		return 0, false
+	}
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, false
+	}
+	return number, true
+}
+
+// parseTimestamp interpreta la marca de tiempo en el formato configurado
+func parseTimestamp(value interface{}, format string) (time.Time, error) {
+	switch format {
+	case TimestampUnix, TimestampUnixMs:
+		var seconds float64
+		switch v := value.(type) {
+		case json.Number:
+			parsed, err := v.Float64()
+			if err != nil {
+				return time.Time{}, err
+			}
+			seconds = parsed
+		case string:
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			seconds = parsed
+		default:
+			return time.Time{}, fmt.Errorf("unexpected timestamp %v", value)
+		}
+		if format == TimestampUnixMs {
+			return time.UnixMilli(int64(seconds)).UTC(), nil
+		}
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(fraction*1e9)).UTC(), nil
+	case TimestampRFC3339:
+		text, ok := value.(string)
+		if !ok {
+			return time.Time{}, fmt.Errorf("unexpected timestamp %v", value)
+		}
+		return time.Parse(time.RFC3339, text)
+	default:
+		return domain.ParseTimestampValue(value)
+	}
+}
@@ -0,0 +1,132 @@
+package webhooks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment es un paso de una ruta: un campo de un objeto, una posición de una lista o todos sus
+// elementos
+type segment struct {
+	field    string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Path es una expresión al estilo JSONPath que localiza valores dentro de un documento JSON.
+// Admite $ (la raíz), .campo, ['campo con espacios'], [n] (n negativo cuenta desde el final) y
+// [*] (todos los elementos de una lista), por ejemplo $.data[*].values['nivel (cm)'].
+type Path struct {
+	expression string
+	segments   []segment
+}
+
+// ParsePath compila una expresión de ruta
+func ParsePath(expression string) (*Path, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(expression), "$")
+	if !ok {
+		return nil, fmt.Errorf("path %q must start with $", expression)
+	}
+
+	path := &Path{expression: expression}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("path %q has an empty field name", expression)
+			}
+			if name == "*" {
+				path.segments = append(path.segments, segment{wildcard: true})
+			} else {
+				path.segments = append(path.segments, segment{field: name})
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q has an unclosed bracket", expression)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			switch {
+			case inner == "*":
+				path.segments = append(path.segments, segment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				path.segments = append(path.segments, segment{field: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("path %q has an invalid index %q", expression, inner)
+				}
+				path.segments = append(path.segments, segment{index: index, isIndex: true})
+			}
+		default:
+			return nil, fmt.Errorf("path %q has an unexpected character %q", expression, rest[0])
+		}
+	}
+
+	return path, nil
+}
+
+// String devuelve la expresión original
+func (p *Path) String() string {
+	return p.expression
+}
+
+// Select devuelve los valores que la ruta localiza en el documento. Las rutas sin [*] devuelven
+// como mucho un valor; los campos o posiciones inexistentes no producen resultados.
+func (p *Path) Select(document interface{}) []interface{} {
+	current := []interface{}{document}
+	for _, seg := range p.segments {
+		var next []interface{}
+		for _, value := range current {
+			next = append(next, seg.apply(value)...)
+		}
+		current = next
+	}
+	return current
+}
+
+// apply aplica el paso a un valor
+func (s segment) apply(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if s.wildcard {
+			values := make([]interface{}, 0, len(v))
+			for _, item := range v {
+				values = append(values, item)
+			}
+			return values
+		}
+		if s.isIndex {
+			return nil
+		}
+		if item, ok := v[s.field]; ok {
+			return []interface{}{item}
+		}
+	case []interface{}:
+		if s.wildcard {
+			return v
+		}
+		if !s.isIndex {
+			return nil
+		}
+		index := s.index
+		if index < 0 {
+			index += len(v)
+		}
+		if index >= 0 && index < len(v) {
+			return []interface{}{v[index]}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// acceptGUID es el valor fijo con el que se calcula Sec-WebSocket-Accept (RFC 6455, sección 1.3)
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Códigos de operación de las tramas
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Códigos de cierre usados por el servidor
+const (
+	closeNormal        = 1000
+	closeGoingAway     = 1001
+	closeProtocolError = 1002
+	closeTooLarge      = 1009
+	closePolicy        = 1008
+)
+
+// maxControlPayload es el tamaño máximo de las tramas de control (ping, pong y cierre)
+const maxControlPayload = 125
+
+// Errores de la conexión WebSocket
+var (
+	ErrNotWebSocket    = errors.New("not a websocket handshake")
+	ErrProtocol        = errors.New("websocket protocol error")
+	ErrMessageTooLarge = errors.New("websocket message too large")
+)
+
+// Conn es una conexión WebSocket del lado del servidor. Implementa lo necesario de RFC 6455
+// (mensajes de texto fragmentados, ping, pong y cierre) sin extensiones, para no añadir
+// dependencias externas. Admite un lector y varios escritores concurrentes.
+type Conn struct {
+	conn           net.Conn
+	reader         *bufio.Reader
+	writeMutex     sync.Mutex
+	maxMessageSize int
+	readTimeout    time.Duration // Plazo para recibir cualquier trama, incluidos los pong
+	writeTimeout   time.Duration
+}
+
+// Upgrade completa el handshake de WebSocket y toma el control de la conexión. Si la solicitud no
+// es un handshake válido devuelve ErrNotWebSocket sin escribir la respuesta.
+func Upgrade(w http.ResponseWriter, r *http.Request, maxMessageSize int, readTimeout, writeTimeout time.Duration) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	decodedKey, err := base64.StdEncoding.DecodeString(key)
+	if r.Method != http.MethodGet ||
+		!headerHasToken(r.Header, "Connection", "upgrade") ||
+		!headerHasToken(r.Header, "Upgrade", "websocket") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" ||
+		err != nil || len(decodedKey) != 16 {
+		return nil, ErrNotWebSocket
+	}
+
+	netConn, buffered, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// El servidor HTTP fijó los plazos de la solicitud; la conexión los gestiona a partir de aquí
+	netConn.SetDeadline(time.Time{})
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	netConn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := buffered.WriteString(response); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	if err := buffered.Flush(); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	return &Conn{
+		conn:           netConn,
+		reader:         buffered.Reader,
+		maxMessageSize: maxMessageSize,
+		readTimeout:    readTimeout,
+		writeTimeout:   writeTimeout,
+	}, nil
+}
+
+// acceptKey calcula la respuesta al Sec-WebSocket-Key del cliente
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerHasToken indica si alguno de los valores de la cabecera, separados por comas, es token
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadMessage devuelve el siguiente mensaje de datos completo. Responde a los ping y descarta los
+// pong; al recibir un cierre lo confirma y devuelve io.EOF.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var message []byte
+	fragmented := false
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			c.closeWithError(err)
+			return nil, err
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			code := payload
+			if len(code) > 2 {
+				code = code[:2]
+			}
+			c.writeFrame(opClose, code)
+			c.conn.Close()
+			return nil, io.EOF
+		case opText, opBinary:
+			if fragmented {
+				c.closeWithError(ErrProtocol)
+				return nil, ErrProtocol
+			}
+			message = payload
+		case opContinuation:
+			if !fragmented {
+				c.closeWithError(ErrProtocol)
+				return nil, ErrProtocol
+			}
+			message = append(message, payload...)
+		default:
+			c.closeWithError(ErrProtocol)
+			return nil, ErrProtocol
+		}
+
+		if len(message) > c.maxMessageSize {
+			c.closeWithError(ErrMessageTooLarge)
+			return nil, ErrMessageTooLarge
+		}
+		if fin {
+			return message, nil
+		}
+		fragmented = true
+	}
+}
+
+// readFrame lee una trama y le quita la máscara. Los clientes deben enmascarar todas sus tramas.
+func (c *Conn) readFrame() (bool, byte, []byte, error) {
+	if c.readTimeout > 0 {
+		c.conn.SetReadDeadline(time.Now().Add(c.readTimeout))
+	}
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	if header[0]&0x70 != 0 || !masked {
+		return false, 0, nil, ErrProtocol
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if opcode >= opClose && (!fin || length > maxControlPayload) {
+		return false, 0, nil, ErrProtocol
+	}
+	if length > uint64(c.maxMessageSize) {
+		return false, 0, nil, ErrMessageTooLarge
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// WriteText envía un mensaje de texto en una sola trama
+func (c *Conn) WriteText(message []byte) error {
+	return c.writeFrame(opText, message)
+}
+
+// Ping envía un ping; el cliente debe responder antes de que venza el plazo de lectura
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// Close envía la trama de cierre indicada y cierra la conexión
+func (c *Conn) Close(code int) error {
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	c.writeFrame(opClose, payload)
+	return c.conn.Close()
+}
+
+// closeWithError cierra la conexión con el código que corresponde al error de lectura
+func (c *Conn) closeWithError(err error) {
+	switch {
+	case errors.Is(err, ErrProtocol):
+		c.Close(closeProtocolError)
+	case errors.Is(err, ErrMessageTooLarge):
+		c.Close(closeTooLarge)
+	default:
+		c.conn.Close()
+	}
+}
+
+// writeFrame envía una trama sin fragmentar y sin máscara, como corresponde al servidor
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	frame := make([]byte, 0, len(payload)+10)
+	frame = append(frame, 0x80|opcode)
+	switch length := len(payload); {
+	case length <= 125:
+		frame = append(frame, byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	frame = append(frame, payload...)
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	if c.writeTimeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
@@ -0,0 +1,171 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"monitor-tanques/internal/adapters/broadcast"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/pkg/logger"
+)
+
+// Transport es la etiqueta de las suscripciones WebSocket en las métricas del hub
+const Transport = "websocket"
+
+// Acciones del protocolo de suscripción que envía el cliente
+const (
+	actionSubscribe   = "subscribe"
+	actionUnsubscribe = "unsubscribe"
+)
+
+// Tipos de mensaje del servidor, además de los eventos
+const (
+	messageSubscribed = "subscribed"
+	messageError      = "error"
+)
+
+// ServerConfig contiene los límites de las conexiones WebSocket
+type ServerConfig struct {
+	MaxMessageSize int           // Tamaño máximo de los mensajes del cliente
+	PingInterval   time.Duration // Cada cuánto se comprueba que el cliente siga conectado
+	WriteTimeout   time.Duration
+}
+
+// clientMessage es un mensaje del protocolo de suscripción
+type clientMessage struct {
+	Action string   `json:"action"`
+	Topics []string `json:"topics"`
+}
+
+// serverMessage es la respuesta del servidor a un mensaje del cliente
+type serverMessage struct {
+	Type   string   `json:"type"`
+	Topics []string `json:"topics,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Server atiende las conexiones WebSocket en tiempo real: traduce el protocolo de suscripción a
+// una suscripción del hub y escribe en la conexión los eventos que el cliente puede ver
+type Server struct {
+	hub    *broadcast.Hub
+	config ServerConfig
+	logger logger.Logger
+}
+
+// NewServer crea el adaptador WebSocket del hub
+func NewServer(hub *broadcast.Hub, config ServerConfig, logger logger.Logger) *Server {
+	return &Server{
+		hub:    hub,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Upgrade completa el handshake con los límites del servidor. El plazo de lectura cubre dos
+// comprobaciones de la conexión, así que un cliente que no responde a los ping se desconecta.
+func (s *Server) Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	return Upgrade(w, r, s.config.MaxMessageSize, 2*s.config.PingInterval, s.config.WriteTimeout)
+}
+
+// Serve atiende la conexión hasta que el cliente se desconecta o el hub cierra la suscripción.
+// Los eventos se envían en nombre del principal, que puede ser nil sin autenticación.
+func (s *Server) Serve(conn *Conn, principal *domain.Principal) {
+	subscriber := s.hub.Subscribe(Transport, principal)
+	defer s.hub.Remove(subscriber)
+
+	go s.writeLoop(conn, subscriber)
+	s.readLoop(conn, subscriber)
+}
+
+// readLoop procesa los mensajes de suscripción del cliente hasta que se desconecta
+func (s *Server) readLoop(conn *Conn, subscriber *broadcast.Subscriber) {
+	for {
+		payload, err := conn.ReadMessage()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				select {
+				case <-subscriber.Done():
+				default:
+					s.logger.Debug("Live client disconnected", "subject", domain.SubjectOf(subscriber.Principal()), "error", err)
+				}
+			}
+			return
+		}
+
+		reply := handleMessage(subscriber, payload)
+		encoded, _ := json.Marshal(reply)
+		if err := conn.WriteText(encoded); err != nil {
+			return
+		}
+	}
+}
+
+// handleMessage aplica una suscripción o una baja y devuelve la respuesta para el cliente
+func handleMessage(subscriber *broadcast.Subscriber, payload []byte) serverMessage {
+	var message clientMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return serverMessage{Type: messageError, Error: "invalid message: " + err.Error()}
+	}
+
+	switch message.Action {
+	case actionSubscribe:
+		topics, err := subscriber.Subscribe(message.Topics)
+		if err != nil {
+			return serverMessage{Type: messageError, Error: err.Error()}
+		}
+		return serverMessage{Type: messageSubscribed, Topics: topics}
+	case actionUnsubscribe:
+		return serverMessage{Type: messageSubscribed, Topics: subscriber.Unsubscribe(message.Topics)}
+	default:
+		return serverMessage{Type: messageError, Error: fmt.Sprintf("unknown action %q", message.Action)}
+	}
+}
+
+// writeLoop envía los eventos que el cliente puede ver, comprueba periódicamente la conexión y la
+// cierra al terminar la suscripción, lo que también detiene readLoop
+func (s *Server) writeLoop(conn *Conn, subscriber *broadcast.Subscriber) {
+	ticker := time.NewTicker(s.config.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-subscriber.Done():
+			conn.Close(closeCode(subscriber.Err()))
+			return
+		case <-ticker.C:
+			if err := conn.Ping(); err != nil {
+				conn.Close(closeGoingAway)
+				return
+			}
+		case event := <-subscriber.Events():
+			if !s.hub.Visible(subscriber, event) {
+				continue
+			}
+			encoded, err := json.Marshal(event)
+			if err != nil {
+				s.logger.Error("Failed to encode live event", "error", err, "type", event.Type)
+				continue
+			}
+			if err := conn.WriteText(encoded); err != nil {
+				conn.Close(closeGoingAway)
+				return
+			}
+		}
+	}
+}
+
+// closeCode devuelve el código de cierre que corresponde al motivo del fin de la suscripción
+func closeCode(err error) int {
+	switch {
+	case errors.Is(err, broadcast.ErrSlowSubscriber):
+		return closePolicy
+	case errors.Is(err, broadcast.ErrHubClosed):
+		return closeGoingAway
+	default:
+		return closeNormal
+	}
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+)
+
+// Ámbitos a los que se puede conceder acceso
+const (
+	AccessScopeSite  = "site"
+	AccessScopeGroup = "group"
+)
+
+// AccessGrant concede a un usuario acceso a los tanques de un sitio o de un grupo. Los usuarios
+// con al menos una concesión solo ven los tanques cubiertos por sus concesiones.
+type AccessGrant struct {
+	ID        string    `json:"id"`
+	Subject   string    `json:"subject"`    // Identificador del usuario (sub del token)
+	ScopeType string    `json:"scope_type"` // site o group
+	ScopeID   string    `json:"scope_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Covers indica si la concesión da acceso al tanque indicado
+func (g *AccessGrant) Covers(tank *Tank) bool {
+	switch g.ScopeType {
+	case AccessScopeSite:
+		return tank.SiteID != "" && tank.SiteID == g.ScopeID
+	case AccessScopeGroup:
+		return tank.GroupID != "" && tank.GroupID == g.ScopeID
+	default:
+		return false
+	}
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tipos de evento que generan alertas
+const (
+	AlertEventLevelCritical = "level_critical" // El nivel cruzó el umbral de alerta del tanque
+	AlertEventAnomaly       = "anomaly"        // El detector de anomalías marcó una lectura
+	AlertEventLowBattery    = TelemetryAlertLowBattery
+	AlertEventWeakSignal    = TelemetryAlertWeakSignal
+	AlertEventATGAlarm      = "atg_alarm"      // Una consola de medición automática activó una alarma
+	AlertEventDailyDigest   = "daily_digest"   // Resumen diario de los canales que no reciben cada alerta
+	AlertEventConfigChanged = "config_changed" // Alguien cambió la configuración de un tanque
+)
+
+// Severidades de las alertas, de menor a mayor
+const (
+	AlertSeverityInfo     = "info"
+	AlertSeverityWarning  = "warning"
+	AlertSeverityCritical = "critical"
+)
+
+// Alert es un evento que se notifica por los canales. Los adaptadores de cada canal deciden cómo
+// presentarlo a partir de la severidad y el tipo; Message es el resumen legible para los canales
+// que solo muestran texto.
+type Alert struct {
+	ID        string    `json:"id,omitempty"` // Solo las alertas críticas, que guardan su evidencia
+	Type      string    `json:"type"`
+	Severity  string    `json:"severity"`
+	TankID    string    `json:"tank_id"`
+	SensorID  string    `json:"sensor_id,omitempty"`
+	Tank      *Tank     `json:"tank,omitempty"` // Estado del tanque al generarse la alerta
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	Digest    *Digest   `json:"digest,omitempty"` // Solo en los resúmenes diarios
+
+	// Solo en los avisos de cambios de configuración
+	ConfigChange *TankConfigChange `json:"config_change,omitempty"`
+
+	// Formato y argumentos del mensaje, para que cada canal lo traduzca al idioma de sus destinatarios
+	MessageFormat string        `json:"-"`
+	MessageArgs   []interface{} `json:"-"`
+}
+
+// NewAlert crea una alerta con una copia del estado actual del tanque, de modo que los cambios
+// posteriores del tanque no alteren la alerta encolada o en envío. El mensaje se compone con
+// format y args, en español; los canales pueden volver a componerlo en otro idioma.
+func NewAlert(eventType, severity string, tank *Tank, format string, args ...interface{}) *Alert {
+	alert := &Alert{
+		Type:          eventType,
+		Severity:      severity,
+		Message:       fmt.Sprintf(format, args...),
+		Timestamp:     time.Now(),
+		MessageFormat: format,
+		MessageArgs:   args,
+	}
+	if tank != nil {
+		snapshot := *tank
+		alert.Tank = &snapshot
+		alert.TankID = tank.ID
+	}
+	return alert
+}
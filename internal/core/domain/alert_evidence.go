@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+// AlertEvidenceWindow es el periodo de mediciones anterior a la alerta que se guarda en su evidencia
+const AlertEvidenceWindow = 24 * time.Hour
+
+// AlertEvidenceMaxNotes limita las notas recientes del tanque que se guardan en la evidencia
+const AlertEvidenceMaxNotes = 20
+
+// AlertEvidence es la instantánea que se captura al dispararse una alerta crítica, para investigar
+// el incidente aunque después cambien el tanque, sus mediciones o sus notas
+type AlertEvidence struct {
+	AlertID      string         `json:"alert_id"`
+	TankID       string         `json:"tank_id"`
+	CapturedAt   time.Time      `json:"captured_at"`
+	From         time.Time      `json:"from"` // Inicio del periodo de las mediciones
+	Alert        *Alert         `json:"alert"`
+	Tank         *Tank          `json:"tank"`         // Configuración y estado del tanque en la alerta
+	Measurements []*Measurement `json:"measurements"` // De la más antigua a la más reciente
+	Notes        []*TankNote    `json:"notes"`        // Las más recientes, en orden cronológico
+}
+
+// NewAlertEvidence compone la evidencia de la alerta con las mediciones de AlertEvidenceWindow y
+// las últimas AlertEvidenceMaxNotes notas del tanque
+func NewAlertEvidence(alert *Alert, tank *Tank, measurements []*Measurement, notes []*TankNote, now time.Time) *AlertEvidence {
+	from := alert.Timestamp.Add(-AlertEvidenceWindow)
+
+	recent := make([]*Measurement, 0)
+	for _, m := range SortMeasurementsAscending(measurements) {
+		if !m.Timestamp.Before(from) && !m.Timestamp.After(alert.Timestamp) {
+			recent = append(recent, m)
+		}
+	}
+
+	if len(notes) > AlertEvidenceMaxNotes {
+		notes = notes[len(notes)-AlertEvidenceMaxNotes:]
+	}
+	if notes == nil {
+		notes = make([]*TankNote, 0)
+	}
+
+	// Los argumentos del mensaje solo sirven para traducirlo a los canales
+	snapshot := *alert
+	snapshot.MessageFormat, snapshot.MessageArgs = "", nil
+
+	return &AlertEvidence{
+		AlertID:      alert.ID,
+		TankID:       alert.TankID,
+		CapturedAt:   now,
+		From:         from,
+		Alert:        &snapshot,
+		Tank:         tank,
+		Measurements: recent,
+		Notes:        notes,
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// MaxAlertMuteDuration limita el silencio de las alertas de un tanque: un silencio olvidado no
+// debe ocultar indefinidamente un problema real
+const MaxAlertMuteDuration = 7 * 24 * time.Hour
+
+// AlertMute es el registro de un silencio de las alertas de un tanque. Los registros se
+// conservan después de expirar o de levantarse, como auditoría de quién silenció qué y cuándo.
+type AlertMute struct {
+	ID        string     `json:"id"`
+	TankID    string     `json:"tank_id"`
+	MutedBy   string     `json:"muted_by,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	MutedAt   time.Time  `json:"muted_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UnmutedAt *time.Time `json:"unmuted_at,omitempty"` // Solo si se levantó antes de expirar
+	UnmutedBy string     `json:"unmuted_by,omitempty"`
+}
+
+// IsActive indica si el silencio sigue vigente en el instante indicado
+func (m *AlertMute) IsActive(now time.Time) bool {
+	return m.UnmutedAt == nil && now.Before(m.ExpiresAt)
+}
+
+// CoveredAt indica si el silencio estaba vigente en el instante t, aunque después se levantara
+func (m *AlertMute) CoveredAt(t time.Time) bool {
+	if t.Before(m.MutedAt) || !t.Before(m.ExpiresAt) {
+		return false
+	}
+	return m.UnmutedAt == nil || t.Before(*m.UnmutedAt)
+}
+
+// ActiveAlertMute devuelve el silencio vigente entre los registros de un tanque, o nil
+func ActiveAlertMute(mutes []*AlertMute, now time.Time) *AlertMute {
+	for i := len(mutes) - 1; i >= 0; i-- {
+		if mutes[i].IsActive(now) {
+			return mutes[i]
+		}
+	}
+	return nil
+}
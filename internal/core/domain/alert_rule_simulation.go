@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Lo que el canal habría hecho con cada alerta de la simulación
+const (
+	RuleDispositionSend   = "send"   // En horario: se envía al momento
+	RuleDispositionRoute  = "route"  // Fuera de horario: se desvía al canal alternativo
+	RuleDispositionQueue  = "queue"  // Fuera de horario: se retiene hasta que el canal vuelva a estar activo
+	RuleDispositionDigest = "digest" // Se acumula para el resumen diario
+	RuleDispositionMuted  = "muted"  // Las alertas del tanque estaban silenciadas
+)
+
+// AlertRuleFiring es una alerta que la regla habría generado en el periodo simulado
+type AlertRuleFiring struct {
+	TankID      string     `json:"tank_id"`
+	TankName    string     `json:"tank_name"`
+	FiredAt     time.Time  `json:"fired_at"`
+	Level       float64    `json:"level"`
+	Percentage  float64    `json:"percentage"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"` // nil si el nivel siguió crítico hasta el final del periodo
+	Disposition string     `json:"disposition"`
+}
+
+// AlertRuleSimulation es el resultado de probar una regla de alerta (un canal de notificación)
+// contra las mediciones históricas de sus tanques
+type AlertRuleSimulation struct {
+	RuleID       string             `json:"rule_id"`
+	From         time.Time          `json:"from"`
+	To           time.Time          `json:"to"`
+	Tanks        int                `json:"tanks"`        // Tanques que cumplen el selector de la regla
+	Measurements int                `json:"measurements"` // Lecturas evaluadas en el periodo
+	Firings      []*AlertRuleFiring `json:"firings"`      // En orden cronológico
+}
+
+// Add incorpora las alertas simuladas de un tanque y mantiene el orden cronológico
+func (s *AlertRuleSimulation) Add(firings []*AlertRuleFiring) {
+	s.Firings = append(s.Firings, firings...)
+	sort.SliceStable(s.Firings, func(i, j int) bool {
+		return s.Firings[i].FiredAt.Before(s.Firings[j].FiredAt)
+	})
+}
+
+// SimulateLevelAlerts repite las lecturas del tanque en el periodo [from, to], en orden
+// cronológico, con su capacidad y su umbral actuales. La alerta de nivel crítico se dispara con
+// la primera lectura que entra en nivel crítico y se resuelve con la primera que sale de él; si
+// el periodo empieza en nivel crítico, la primera lectura dispara la alerta.
+func SimulateLevelAlerts(tank *Tank, measurements []*Measurement, from, to time.Time) ([]*AlertRuleFiring, int) {
+	inPeriod := make([]*Measurement, 0, len(measurements))
+	for _, m := range measurements {
+		if !m.Timestamp.Before(from) && !m.Timestamp.After(to) {
+			inPeriod = append(inPeriod, m)
+		}
+	}
+	sort.SliceStable(inPeriod, func(i, j int) bool {
+		return inPeriod[i].Timestamp.Before(inPeriod[j].Timestamp)
+	})
+
+	var firings []*AlertRuleFiring
+	var active *AlertRuleFiring
+	replay := *tank
+	for _, m := range inPeriod {
+		replay.CurrentLevel = m.Level
+		critical := replay.IsLevelCritical()
+
+		switch {
+		case critical && active == nil:
+			active = &AlertRuleFiring{
+				TankID:     tank.ID,
+				TankName:   tank.Name,
+				FiredAt:    m.Timestamp,
+				Level:      m.Level,
+				Percentage: replay.GetLevelPercentage(),
+			}
+			firings = append(firings, active)
+		case !critical && active != nil:
+			resolvedAt := m.Timestamp
+			active.ResolvedAt = &resolvedAt
+			active = nil
+		}
+	}
+	return firings, len(inPeriod)
+}
+
+// Disposition indica qué habría hecho el canal con una alerta del tanque en el instante at.
+// fallback es el canal alternativo del canal, o nil si no tiene o no existe.
+func (c *NotificationChannel) Disposition(at time.Time, tank *Tank, fallback *NotificationChannel) string {
+	switch {
+	case c.IsDigest():
+		return RuleDispositionDigest
+	case c.Schedule.IsActiveFor(at, tank):
+		return RuleDispositionSend
+	case c.OutOfSchedule == OutOfScheduleRoute && fallback != nil && fallback.Enabled && fallback.Schedule.IsActiveFor(at, tank):
+		return RuleDispositionRoute
+	default:
+		return RuleDispositionQueue
+	}
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Tipos de anomalía detectados en las series de mediciones
+const (
+	AnomalyKindOutlier  = "outlier"  // Lectura muy alejada del comportamiento reciente
+	AnomalyKindFlatline = "flatline" // El sensor repite exactamente la misma lectura
+)
+
+// Magnitudes analizadas por el detector de anomalías
+const (
+	AnomalyMetricLevel       = "level"
+	AnomalyMetricTemperature = "temperature"
+)
+
+// minAnomalySamples es el número mínimo de lecturas previas para estimar la dispersión
+const minAnomalySamples = 5
+
+// AnomalyConfig contiene los parámetros del detector de anomalías
+type AnomalyConfig struct {
+	Window        int     // Lecturas previas usadas como referencia (0 desactiva la detección)
+	ZThreshold    float64 // Puntuación z a partir de la cual una lectura es anómala
+	FlatlineCount int     // Lecturas idénticas consecutivas que indican un sensor congelado (0 lo desactiva)
+}
+
+// Enabled indica si la detección de anomalías está activa
+func (c AnomalyConfig) Enabled() bool {
+	return c.Window > 0 || c.FlatlineCount > 0
+}
+
+// HistorySize devuelve cuántas lecturas (incluida la nueva) necesita el detector
+func (c AnomalyConfig) HistorySize() int {
+	return max(c.Window, c.FlatlineCount) + 1
+}
+
+// Anomaly es un evento de lectura inusual, distinto de las alertas por umbral
+type Anomaly struct {
+	ID            string    `json:"id"`
+	TankID        string    `json:"tank_id"`
+	MeasurementID string    `json:"measurement_id"`
+	Metric        string    `json:"metric"` // level o temperature
+	Kind          string    `json:"kind"`   // outlier o flatline
+	Value         float64   `json:"value"`
+	Expected      float64   `json:"expected"` // Media de la ventana de referencia
+	Score         float64   `json:"score"`    // Puntuación z, o lecturas repetidas para flatline
+	DetectedAt    time.Time `json:"detected_at"`
+}
+
+// DetectAnomalies analiza la lectura más reciente de la serie (ordenada de la más antigua a la
+// más reciente) frente a las anteriores con una puntuación z móvil:
+//   - nivel: se evalúa la variación respecto a la lectura anterior y solo se marcan las caídas,
+//     ya que las recargas son subidas esperadas;
+//   - temperatura: se evalúa el valor en ambos sentidos;
+//   - sensor congelado: se marca una sola vez, cuando la racha de lecturas idénticas alcanza
+//     FlatlineCount.
+func DetectAnomalies(measurements []*Measurement, config AnomalyConfig) []*Anomaly {
+	anomalies := make([]*Anomaly, 0)
+	if len(measurements) < 2 {
+		return anomalies
+	}
+
+	latest := measurements[len(measurements)-1]
+	newAnomaly := func(metric, kind string, value, expected, score float64) *Anomaly {
+		return &Anomaly{
+			TankID:        latest.TankID,
+			MeasurementID: latest.ID,
+			Metric:        metric,
+			Kind:          kind,
+			Value:         value,
+			Expected:      expected,
+			Score:         score,
+			DetectedAt:    latest.Timestamp,
+		}
+	}
+
+	if config.Window > 0 {
+		reference := measurements[max(0, len(measurements)-1-config.Window) : len(measurements)-1]
+
+		// Variaciones de nivel entre lecturas consecutivas de la ventana
+		deltas := make([]float64, 0, len(reference))
+		for i := 1; i < len(reference); i++ {
+			deltas = append(deltas, reference[i].Level-reference[i-1].Level)
+		}
+		delta := latest.Level - reference[len(reference)-1].Level
+		if mean, score, ok := zScore(deltas, delta); ok && score <= -config.ZThreshold {
+			anomalies = append(anomalies, newAnomaly(AnomalyMetricLevel, AnomalyKindOutlier, delta, mean, score))
+		}
+
+		temperatures := make([]float64, len(reference))
+		for i, m := range reference {
+			temperatures[i] = m.Temperature
+		}
+		if mean, score, ok := zScore(temperatures, latest.Temperature); ok && math.Abs(score) >= config.ZThreshold {
+			anomalies = append(anomalies, newAnomaly(AnomalyMetricTemperature, AnomalyKindOutlier, latest.Temperature, mean, score))
+		}
+	}
+
+	if config.FlatlineCount > 1 {
+		streak := 1
+		for i := len(measurements) - 2; i >= 0; i-- {
+			if measurements[i].Level != latest.Level || measurements[i].Temperature != latest.Temperature {
+				break
+			}
+			streak++
+		}
+
+		if streak == config.FlatlineCount {
+			anomalies = append(anomalies, newAnomaly(AnomalyMetricLevel, AnomalyKindFlatline, latest.Level, latest.Level, float64(streak)))
+		}
+	}
+
+	return anomalies
+}
+
+// zScore calcula la puntuación z del valor frente a la muestra. Devuelve false si la muestra es
+// demasiado pequeña o no tiene dispersión.
+func zScore(sample []float64, value float64) (float64, float64, bool) {
+	if len(sample) < minAnomalySamples {
+		return 0, 0, false
+	}
+
+	mean := 0.0
+	for _, v := range sample {
+		mean += v
+	}
+	mean /= float64(len(sample))
+
+	variance := 0.0
+	for _, v := range sample {
+		variance += (v - mean) * (v - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(sample)))
+	if stdDev == 0 {
+		return mean, 0, false
+	}
+
+	return mean, (value - mean) / stdDev, true
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// APITokenPrefix distingue los tokens de la API de los tokens del proveedor de identidad
+const APITokenPrefix = "mtk_"
+
+// APIToken es un token de acceso emitido por la propia aplicación, p. ej. para el primer
+// administrador de una instalación o para integraciones sin proveedor de identidad. Solo se
+// guarda el resumen del token; el token en claro se muestra una única vez al crearlo.
+type APIToken struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Scopes    []string  `json:"scopes,omitempty"` // Vacío permite todo lo que permite el rol
+	Hash      string    `json:"-"`                // SHA-256 del token en hexadecimal
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsAPIToken indica si el token Bearer es un token de la API
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// HashAPIToken devuelve el resumen con el que se guarda y se busca el token
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Principal devuelve la identidad de quien se autentica con el token
+func (t *APIToken) Principal() *Principal {
+	return &Principal{
+		Subject: "token:" + t.ID,
+		Name:    t.Name,
+		Roles:   []string{t.Role},
+		Scopes:  append([]string(nil), t.Scopes...),
+		Source:  "api_token",
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+)
+
+// Tipos de adjunto de un tanque
+const (
+	AttachmentKindPhoto       = "photo"
+	AttachmentKindCalibration = "calibration_certificate"
+	AttachmentKindInspection  = "inspection_report"
+	AttachmentKindOther       = "other"
+)
+
+// Attachment representa un archivo (foto o documento) vinculado a un tanque. El contenido se
+// guarda en el almacenamiento de archivos bajo StorageKey; aquí solo se guardan los metadatos.
+type Attachment struct {
+	ID          string    `json:"id"`
+	TankID      string    `json:"tank_id"`
+	Kind        string    `json:"kind"` // photo, calibration_certificate, inspection_report, other
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"` // Bytes
+	Description string    `json:"description,omitempty"`
+	StorageKey  string    `json:"-"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// IsValidAttachmentKind indica si el tipo de adjunto es uno de los admitidos
+func IsValidAttachmentKind(kind string) bool {
+	switch kind {
+	case AttachmentKindPhoto, AttachmentKindCalibration, AttachmentKindInspection, AttachmentKindOther:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"context"
+)
+
+// Roles de la aplicación, de mayor a menor privilegio
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator"
+	RoleViewer   = "viewer"
+)
+
+// roleRank ordena los roles para que un rol superior incluya los permisos de los inferiores
+var roleRank = map[string]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// Alcances de los tokens de la API; restringen lo que permite su rol
+const (
+	ScopeReadTanks         = "read:tanks"         // Consultas
+	ScopeWriteTanks        = "write:tanks"        // Modificaciones, salvo el envío de mediciones
+	ScopeWriteMeasurements = "write:measurements" // Envío de mediciones
+	ScopeAdminConfig       = "admin:config"       // Rutas de administración
+)
+
+// scopeRoles es el rol mínimo con el que cada alcance tiene sentido
+var scopeRoles = map[string]string{
+	ScopeReadTanks:         RoleViewer,
+	ScopeWriteTanks:        RoleOperator,
+	ScopeWriteMeasurements: RoleOperator,
+	ScopeAdminConfig:       RoleAdmin,
+}
+
+// IsValidScope indica si el alcance es uno de los alcances de la aplicación
+func IsValidScope(scope string) bool {
+	_, ok := scopeRoles[scope]
+	return ok
+}
+
+// RoleForScopes devuelve el rol mínimo que necesitan los alcances, o "" si no hay alcances
+func RoleForScopes(scopes []string) string {
+	role := ""
+	for _, scope := range scopes {
+		if required := scopeRoles[scope]; roleRank[required] > roleRank[role] {
+			role = required
+		}
+	}
+	return role
+}
+
+// IsValidRole indica si el rol es uno de los roles de la aplicación
+func IsValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// Principal representa la identidad autenticada que realiza una solicitud
+type Principal struct {
+	Subject string   `json:"subject"`
+	Name    string   `json:"name"`
+	Email   string   `json:"email"`
+	Roles   []string `json:"roles"`
+	Source  string   `json:"source"` // Origen de la identidad, p. ej. oidc
+
+	// Organización del usuario en las instalaciones compartidas por varios clientes
+	Organization string `json:"organization,omitempty"`
+
+	// Tanque al que queda limitado el principal, p. ej. un equipo de campo identificado por su
+	// certificado de cliente; vacío no limita el acceso
+	TankID string `json:"tank_id,omitempty"`
+
+	// Alcances a los que queda limitado el principal, p. ej. un token de la API de solo lectura;
+	// vacío permite todo lo que permiten sus roles
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// HasRole indica si el principal tiene el rol indicado o uno superior
+func (p *Principal) HasRole(role string) bool {
+	required := roleRank[role]
+	for _, r := range p.Roles {
+		if rank, ok := roleRank[r]; ok && rank >= required {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope indica si el principal puede usar el alcance indicado
+func (p *Principal) HasScope(scope string) bool {
+	if len(p.Scopes) == 0 {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// SubjectOf identifica al principal en los registros; vacío si la solicitud es anónima
+func SubjectOf(principal *Principal) string {
+	if principal == nil {
+		return ""
+	}
+	return principal.Subject
+}
+
+// principalKey es la clave del principal dentro del contexto
+type principalKey struct{}
+
+// ContextWithPrincipal devuelve un contexto que transporta el principal autenticado
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext devuelve el principal autenticado, o nil si la solicitud es anónima
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
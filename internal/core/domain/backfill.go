@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// MaxBackfillMeasurements limita las mediciones históricas de una solicitud de importación; los
+// historiales más largos se envían en varias solicitudes
+const MaxBackfillMeasurements = 5000
+
+// BackfillResult resume la importación de mediciones históricas de un tanque
+type BackfillResult struct {
+	TankID     string    `json:"tank_id"`
+	Received   int       `json:"received"`
+	Imported   int       `json:"imported"`
+	Duplicates int       `json:"duplicates"` // Mediciones con la misma marca de tiempo que una ya guardada
+	From       time.Time `json:"from"`       // Medición importada más antigua
+	To         time.Time `json:"to"`         // Medición importada más reciente
+
+	// Indica si la medición importada más reciente pasó a ser la última del tanque y, con ella,
+	// su nivel actual
+	CurrentLevelUpdated bool `json:"current_level_updated"`
+}
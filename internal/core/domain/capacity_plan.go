@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// TankCapacityForecast es la previsión de un tanque dentro del plan de capacidad de su grupo
+type TankCapacityForecast struct {
+	TankID               string     `json:"tank_id"`
+	TankName             string     `json:"tank_name"`
+	LiquidType           string     `json:"liquid_type"`
+	Capacity             float64    `json:"capacity"`
+	CurrentLevel         float64    `json:"current_level"`
+	DailyConsumption     float64    `json:"daily_consumption"`     // Consumo estimado en litros por día
+	ForecastAvailable    bool       `json:"forecast_available"`    // Falso si no hay historial suficiente para estimar el consumo
+	ProjectedConsumption float64    `json:"projected_consumption"` // Litros que se consumirán en el horizonte
+	ProjectedLevel       float64    `json:"projected_level"`       // Nivel al final del horizonte sin entregas
+	TargetLevel          float64    `json:"target_level"`          // Punto de pedido o, si no hay, nivel de alerta
+	RequiredDelivery     float64    `json:"required_delivery"`     // Litros a entregar para no bajar del nivel objetivo
+	RunsOutAt            *time.Time `json:"runs_out_at,omitempty"` // Se vacía dentro del horizonte sin entregas
+}
+
+// CapacityPlanProduct agrega el plan de un tipo de líquido, ya que cada producto se entrega por separado
+type CapacityPlanProduct struct {
+	LiquidType           string  `json:"liquid_type"`
+	Tanks                int     `json:"tanks"`
+	ProjectedConsumption float64 `json:"projected_consumption"`
+	RequiredDelivery     float64 `json:"required_delivery"`
+}
+
+// CapacityPlan responde cuántos litros hay que entregar a un grupo de tanques en los próximos días
+type CapacityPlan struct {
+	GroupID              string                  `json:"group_id"`
+	HorizonDays          int                     `json:"horizon_days"`
+	GeneratedAt          time.Time               `json:"generated_at"`
+	Tanks                int                     `json:"tanks"`
+	TanksNeedingDelivery int                     `json:"tanks_needing_delivery"`
+	Capacity             float64                 `json:"capacity"`
+	CurrentLevel         float64                 `json:"current_level"`
+	ProjectedConsumption float64                 `json:"projected_consumption"`
+	RequiredDelivery     float64                 `json:"required_delivery"`
+	Products             []*CapacityPlanProduct  `json:"products"`
+	Forecasts            []*TankCapacityForecast `json:"forecasts"` // Primero los que más litros necesitan
+}
+
+// BuildTankCapacityForecast proyecta el nivel del tanque durante horizonDays días con su consumo
+// diario estimado y calcula los litros que hay que entregar para que no baje del nivel objetivo.
+// La entrega nunca supera el espacio libre del tanque al final del horizonte.
+func BuildTankCapacityForecast(tank *Tank, measurements []*Measurement, horizonDays int, now time.Time) *TankCapacityForecast {
+	daily := EstimateDailyConsumption(measurements)
+	consumption := daily * float64(horizonDays)
+
+	target := tank.Capacity * tank.AlertThreshold / 100
+	if tank.Reorder.IsEnabled() {
+		target = tank.Reorder.ReorderLevel
+	}
+
+	forecast := &TankCapacityForecast{
+		TankID:               tank.ID,
+		TankName:             tank.Name,
+		LiquidType:           tank.LiquidType,
+		Capacity:             tank.Capacity,
+		CurrentLevel:         tank.CurrentLevel,
+		DailyConsumption:     daily,
+		ForecastAvailable:    len(measurements) >= 2,
+		ProjectedConsumption: consumption,
+		ProjectedLevel:       math.Max(tank.CurrentLevel-consumption, 0),
+		TargetLevel:          target,
+	}
+
+	required := target + consumption - tank.CurrentLevel
+	forecast.RequiredDelivery = math.Max(math.Min(required, tank.Capacity-forecast.ProjectedLevel), 0)
+
+	if daily > 0 {
+		if days := tank.CurrentLevel / daily; days < float64(horizonDays) {
+			runsOutAt := now.Add(time.Duration(days * 24 * float64(time.Hour)))
+			forecast.RunsOutAt = &runsOutAt
+		}
+	}
+
+	return forecast
+}
+
+// BuildCapacityPlan agrega las previsiones de los tanques del grupo, en total y por tipo de líquido
+func BuildCapacityPlan(groupID string, horizonDays int, forecasts []*TankCapacityForecast, now time.Time) *CapacityPlan {
+	plan := &CapacityPlan{
+		GroupID:     groupID,
+		HorizonDays: horizonDays,
+		GeneratedAt: now,
+		Tanks:       len(forecasts),
+		Products:    make([]*CapacityPlanProduct, 0),
+		Forecasts:   forecasts,
+	}
+
+	products := make(map[string]*CapacityPlanProduct)
+	for _, forecast := range forecasts {
+		plan.Capacity += forecast.Capacity
+		plan.CurrentLevel += forecast.CurrentLevel
+		plan.ProjectedConsumption += forecast.ProjectedConsumption
+		plan.RequiredDelivery += forecast.RequiredDelivery
+		if forecast.RequiredDelivery > 0 {
+			plan.TanksNeedingDelivery++
+		}
+
+		product, ok := products[forecast.LiquidType]
+		if !ok {
+			product = &CapacityPlanProduct{LiquidType: forecast.LiquidType}
+			products[forecast.LiquidType] = product
+			plan.Products = append(plan.Products, product)
+		}
+		product.Tanks++
+		product.ProjectedConsumption += forecast.ProjectedConsumption
+		product.RequiredDelivery += forecast.RequiredDelivery
+	}
+
+	sort.SliceStable(plan.Forecasts, func(i, j int) bool {
+		return plan.Forecasts[i].RequiredDelivery > plan.Forecasts[j].RequiredDelivery
+	})
+	sort.SliceStable(plan.Products, func(i, j int) bool {
+		return plan.Products[i].LiquidType < plan.Products[j].LiquidType
+	})
+
+	return plan
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// clockSkewSmoothing es el peso de cada lectura en el desfase medio de un sensor: una lectura
+// aislada, p. ej. reenviada tras un corte de red, no basta para marcar el reloj como desajustado
+const clockSkewSmoothing = 0.2
+
+// ClockSkewConfig contiene los parámetros del seguimiento del reloj de los sensores
+type ClockSkewConfig struct {
+	DriftThreshold      time.Duration // Desfase medio a partir del cual el reloj se marca como desajustado
+	CorrectionThreshold time.Duration // Desfase a partir del cual se corrigen las marcas de tiempo (0 lo desactiva)
+}
+
+// SensorClock es el desfase entre el reloj de un sensor y la hora de recepción del servidor.
+// Un desfase positivo indica que el reloj del sensor adelanta; negativo, que atrasa.
+type SensorClock struct {
+	SensorID       string    `json:"sensor_id"`
+	TankID         string    `json:"tank_id"`
+	Offset         float64   `json:"offset"`         // Segundos de desfase de la última lectura
+	AverageOffset  float64   `json:"average_offset"` // Media móvil exponencial del desfase en segundos
+	MaxOffset      float64   `json:"max_offset"`     // Mayor desfase observado en valor absoluto, en segundos
+	Samples        int       `json:"samples"`        // Lecturas observadas
+	Corrected      int       `json:"corrected"`      // Lecturas cuya marca de tiempo se corrigió
+	LastReceivedAt time.Time `json:"last_received_at"`
+	Drifting       bool      `json:"drifting"` // El desfase medio supera el umbral configurado
+}
+
+// Observe registra el desfase de una lectura recibida en receivedAt y recalcula si el reloj
+// está desajustado
+func (c *SensorClock) Observe(offset time.Duration, receivedAt time.Time, config ClockSkewConfig) {
+	seconds := offset.Seconds()
+	if c.Samples == 0 {
+		c.AverageOffset = seconds
+	} else {
+		c.AverageOffset += clockSkewSmoothing * (seconds - c.AverageOffset)
+	}
+	c.Offset = seconds
+	c.MaxOffset = math.Max(c.MaxOffset, math.Abs(seconds))
+	c.Samples++
+	c.LastReceivedAt = receivedAt
+	c.Drifting = config.DriftThreshold > 0 && math.Abs(c.AverageOffset) > config.DriftThreshold.Seconds()
+}
+
+// ShouldCorrect indica si el desfase de una lectura es tan grande que su marca de tiempo debe
+// sustituirse por la del servidor
+func (c ClockSkewConfig) ShouldCorrect(offset time.Duration) bool {
+	if c.CorrectionThreshold <= 0 {
+		return false
+	}
+	if offset < 0 {
+		offset = -offset
+	}
+	return offset > c.CorrectionThreshold
+}
@@ -0,0 +1,67 @@
+package domain
+
+import "sort"
+
+// CompartmentRollup agrega los compartimentos de un tanque compartimentado (p. ej. una cisterna).
+// Cada compartimento es un tanque con su propio nivel, líquido y mediciones; el tanque padre
+// resume la capacidad y el contenido de todos ellos.
+type CompartmentRollup struct {
+	TankID       string   `json:"tank_id"`
+	TankName     string   `json:"tank_name"`
+	Capacity     float64  `json:"capacity"`      // Suma de las capacidades de los compartimentos
+	CurrentLevel float64  `json:"current_level"` // Suma de los niveles de los compartimentos
+	Percentage   float64  `json:"percentage"`
+	Status       string   `json:"status"`       // El más grave de los compartimentos
+	LiquidTypes  []string `json:"liquid_types"` // Líquidos distintos, en orden alfabético
+	Critical     int      `json:"critical"`     // Compartimentos en nivel crítico
+	Compartments []*Tank  `json:"compartments"` // Por nombre
+}
+
+// RollupCompartments resume los compartimentos del tanque padre. Un tanque sin compartimentos
+// devuelve un resumen vacío en estado normal.
+func RollupCompartments(parent *Tank, compartments []*Tank) *CompartmentRollup {
+	rollup := &CompartmentRollup{
+		TankID:       parent.ID,
+		TankName:     parent.Name,
+		Status:       "normal",
+		LiquidTypes:  make([]string, 0),
+		Compartments: make([]*Tank, 0, len(compartments)),
+	}
+
+	liquids := make(map[string]bool)
+	for _, compartment := range compartments {
+		rollup.Compartments = append(rollup.Compartments, compartment)
+		rollup.Capacity += compartment.Capacity
+		rollup.CurrentLevel += compartment.CurrentLevel
+		if statusRank(compartment.Status) > statusRank(rollup.Status) {
+			rollup.Status = compartment.Status
+		}
+		if compartment.Status == "critical" {
+			rollup.Critical++
+		}
+		if compartment.LiquidType != "" && !liquids[compartment.LiquidType] {
+			liquids[compartment.LiquidType] = true
+			rollup.LiquidTypes = append(rollup.LiquidTypes, compartment.LiquidType)
+		}
+	}
+
+	if rollup.Capacity > 0 {
+		rollup.Percentage = rollup.CurrentLevel / rollup.Capacity * 100
+	}
+	sort.Strings(rollup.LiquidTypes)
+	sort.SliceStable(rollup.Compartments, func(i, j int) bool {
+		return rollup.Compartments[i].Name < rollup.Compartments[j].Name
+	})
+	return rollup
+}
+
+// CompartmentsOf devuelve los tanques que son compartimentos del tanque indicado
+func CompartmentsOf(tanks []*Tank, parentID string) []*Tank {
+	compartments := make([]*Tank, 0)
+	for _, tank := range tanks {
+		if tank.ParentID == parentID {
+			compartments = append(compartments, tank)
+		}
+	}
+	return compartments
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// DataLakeExport es el resultado de una exportación de mediciones al data lake. Incluye las
+// mediciones con marca de tiempo posterior a From y hasta To, ambos en UTC.
+type DataLakeExport struct {
+	From         time.Time `json:"from"` // Punto de control anterior (cero en la primera exportación)
+	To           time.Time `json:"to"`   // Nuevo punto de control
+	Measurements int       `json:"measurements"`
+	Files        []string  `json:"files"` // Claves de los archivos escritos en el almacenamiento
+	FinishedAt   time.Time `json:"finished_at"`
+}
+
+// DataLakeCheckpoint es el punto de control de las exportaciones al data lake. PendingUntil
+// queda fijado mientras una exportación no termina, para que el siguiente intento repita el mismo
+// corte y reemplace los archivos ya escritos en lugar de duplicar las mediciones.
+type DataLakeCheckpoint struct {
+	ExportedUntil time.Time       `json:"exported_until"` // Mediciones exportadas hasta esta marca de tiempo
+	PendingUntil  *time.Time      `json:"pending_until,omitempty"`
+	LastExport    *DataLakeExport `json:"last_export,omitempty"`
+}
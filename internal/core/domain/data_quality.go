@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Estados de la calidad de los datos de un tanque
+const (
+	DataQualityOK       = "ok"       // Sin huecos, lecturas congeladas ni valores fuera de rango
+	DataQualityDegraded = "degraded" // Con algún problema: conviene revisar el sensor
+	DataQualityNoData   = "no_data"  // Sin lecturas en el periodo
+)
+
+// Límites físicos de la temperatura; las lecturas fuera de ellos indican un sensor averiado
+const (
+	minPlausibleTemperature = -50.0
+	maxPlausibleTemperature = 100.0
+)
+
+// DataQualityConfig contiene los parámetros del informe de calidad de datos
+type DataQualityConfig struct {
+	FlatlineCount int // Lecturas idénticas consecutivas a partir de las cuales se cuenta una racha congelada
+}
+
+// TankDataQuality resume la calidad de las lecturas de un tanque en el periodo del informe
+type TankDataQuality struct {
+	TankID           string  `json:"tank_id"`
+	TankName         string  `json:"tank_name"`
+	Measurements     int     `json:"measurements"`
+	ExpectedInterval float64 `json:"expected_interval"` // Mediana de segundos entre lecturas
+	MissingIntervals int     `json:"missing_intervals"` // Lecturas que faltan según el intervalo habitual
+	LongestGap       float64 `json:"longest_gap"`       // Mayor hueco en segundos, incluidos los extremos del periodo
+	Flatlines        int     `json:"flatlines"`         // Rachas de lecturas idénticas de FlatlineCount o más
+	OutOfRange       int     `json:"out_of_range"`      // Lecturas con nivel negativo o mayor que la capacidad, o temperatura imposible
+	Status           string  `json:"status"`            // ok, degraded o no_data
+}
+
+// DataQualitySummary agrega los problemas de todos los tanques del informe
+type DataQualitySummary struct {
+	Tanks            int `json:"tanks"`
+	TanksWithIssues  int `json:"tanks_with_issues"` // Tanques degradados o sin datos
+	MissingIntervals int `json:"missing_intervals"`
+	Flatlines        int `json:"flatlines"`
+	OutOfRange       int `json:"out_of_range"`
+}
+
+// DataQualityReport es el informe de calidad de datos de los tanques en un periodo
+type DataQualityReport struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Summary     DataQualitySummary `json:"summary"`
+	Tanks       []*TankDataQuality `json:"tanks"` // Primero los tanques con problemas
+}
+
+// BuildTankDataQuality evalúa las lecturas del tanque en el periodo [from, to]. Las lecturas
+// fuera del periodo se ignoran. El intervalo habitual es la mediana de los intervalos entre
+// lecturas, así que hacen falta al menos tres para contar los huecos.
+func BuildTankDataQuality(tank *Tank, measurements []*Measurement, from, to time.Time, config DataQualityConfig) *TankDataQuality {
+	quality := &TankDataQuality{TankID: tank.ID, TankName: tank.Name, Status: DataQualityNoData}
+
+	var inPeriod []*Measurement
+	for _, measurement := range SortMeasurementsAscending(measurements) {
+		if !measurement.Timestamp.Before(from) && !measurement.Timestamp.After(to) {
+			inPeriod = append(inPeriod, measurement)
+		}
+	}
+	quality.Measurements = len(inPeriod)
+	if len(inPeriod) == 0 {
+		quality.LongestGap = to.Sub(from).Seconds()
+		return quality
+	}
+
+	run := 1
+	for i, measurement := range inPeriod {
+		if measurement.Level < 0 || (tank.Capacity > 0 && measurement.Level > tank.Capacity) ||
+			measurement.Temperature < minPlausibleTemperature || measurement.Temperature > maxPlausibleTemperature {
+			quality.OutOfRange++
+		}
+
+		if i == 0 {
+			continue
+		}
+		previous := inPeriod[i-1]
+		if measurement.Level == previous.Level && measurement.Temperature == previous.Temperature {
+			run++
+			// La racha se cuenta una sola vez, al alcanzar el umbral
+			if config.FlatlineCount > 1 && run == config.FlatlineCount {
+				quality.Flatlines++
+			}
+		} else {
+			run = 1
+		}
+	}
+
+	// Los huecos incluyen el silencio al principio y al final del periodo
+	gaps := []float64{inPeriod[0].Timestamp.Sub(from).Seconds()}
+	intervals := make([]float64, 0, len(inPeriod)-1)
+	for i := 1; i < len(inPeriod); i++ {
+		intervals = append(intervals, inPeriod[i].Timestamp.Sub(inPeriod[i-1].Timestamp).Seconds())
+	}
+	gaps = append(gaps, intervals...)
+	gaps = append(gaps, to.Sub(inPeriod[len(inPeriod)-1].Timestamp).Seconds())
+
+	for _, gap := range gaps {
+		quality.LongestGap = math.Max(quality.LongestGap, gap)
+	}
+
+	if len(intervals) >= 2 {
+		quality.ExpectedInterval = median(intervals)
+		if quality.ExpectedInterval > 0 {
+			for _, gap := range gaps {
+				// Un hueco de hasta 1,5 intervalos es la variación normal del reporte
+				if missing := int(math.Round(gap/quality.ExpectedInterval)) - 1; gap > 1.5*quality.ExpectedInterval && missing > 0 {
+					quality.MissingIntervals += missing
+				}
+			}
+		}
+	}
+
+	quality.Status = DataQualityOK
+	if quality.MissingIntervals > 0 || quality.Flatlines > 0 || quality.OutOfRange > 0 {
+		quality.Status = DataQualityDegraded
+	}
+	return quality
+}
+
+// HasIssues indica si el tanque tiene problemas de calidad o no tiene datos
+func (q *TankDataQuality) HasIssues() bool {
+	return q.Status != DataQualityOK
+}
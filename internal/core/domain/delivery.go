@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"time"
+)
+
+// Estados posibles de un pedido de entrega
+const (
+	DeliveryStatusRequested = "requested"
+	DeliveryStatusScheduled = "scheduled"
+	DeliveryStatusReceived  = "received"
+)
+
+// Supplier representa un proveedor que abastece líquido a los tanques
+type Supplier struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Contact string `json:"contact"`
+	Phone   string `json:"phone"`
+	Email   string `json:"email"`
+}
+
+// DeliveryOrder representa un pedido de entrega de líquido para un tanque
+type DeliveryOrder struct {
+	ID              string    `json:"id"`
+	TankID          string    `json:"tank_id"`
+	SupplierID      string    `json:"supplier_id"`
+	RequestedVolume float64   `json:"requested_volume"` // Litros solicitados
+	ReceivedVolume  float64   `json:"received_volume"`  // Litros declarados en la recepción
+	Status          string    `json:"status"`           // requested, scheduled, received
+	RequestedAt     time.Time `json:"requested_at"`
+	ScheduledFor    time.Time `json:"scheduled_for,omitempty"`
+	ReceivedAt      time.Time `json:"received_at,omitempty"`
+}
+
+// CanTransitionTo indica si el pedido puede pasar al estado indicado
+func (o *DeliveryOrder) CanTransitionTo(status string) bool {
+	switch status {
+	case DeliveryStatusScheduled:
+		return o.Status == DeliveryStatusRequested || o.Status == DeliveryStatusScheduled
+	case DeliveryStatusReceived:
+		return o.Status == DeliveryStatusRequested || o.Status == DeliveryStatusScheduled
+	default:
+		return false
+	}
+}
+
+// DeliveryReconciliation compara el volumen recibido con la subida de nivel medida
+type DeliveryReconciliation struct {
+	OrderID           string  `json:"order_id"`
+	TankID            string  `json:"tank_id"`
+	RequestedVolume   float64 `json:"requested_volume"`
+	ReceivedVolume    float64 `json:"received_volume"`
+	DetectedVolume    float64 `json:"detected_volume"` // Subida de nivel detectada por las mediciones
+	Difference        float64 `json:"difference"`      // Recibido - detectado
+	DifferencePercent float64 `json:"difference_percent"`
+	WithinTolerance   bool    `json:"within_tolerance"`
+}
+
+// DetectLevelRise calcula la subida de nivel acumulada en el intervalo [from, to]. Se toma como
+// referencia la última medición anterior al intervalo, si existe.
+func DetectLevelRise(measurements []*Measurement, from, to time.Time) float64 {
+	var previous *Measurement
+	rise := 0.0
+
+	for _, m := range SortMeasurementsAscending(measurements) {
+		if m.Timestamp.After(to) {
+			break
+		}
+
+		if m.Timestamp.Before(from) {
+			previous = m
+			continue
+		}
+
+		if previous != nil && m.Level > previous.Level {
+			rise += m.Level - previous.Level
+		}
+		previous = m
+	}
+
+	return rise
+}
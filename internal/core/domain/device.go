@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Plataformas de notificaciones push soportadas
+const (
+	PushPlatformFCM  = "fcm"  // Firebase Cloud Messaging (Android, web)
+	PushPlatformAPNs = "apns" // Apple Push Notification service (iOS)
+)
+
+// ErrDeviceUnregistered indica que el proveedor push ya no reconoce el token del dispositivo
+var ErrDeviceUnregistered = errors.New("push token is no longer registered")
+
+// MobileDevice representa el dispositivo móvil de un técnico registrado para recibir alertas
+// push. Solo recibe las alertas de tanques cercanos a su última ubicación conocida.
+type MobileDevice struct {
+	ID         string      `json:"id"`
+	Subject    string      `json:"subject"`  // Usuario propietario del dispositivo
+	Platform   string      `json:"platform"` // fcm o apns
+	Token      string      `json:"token"`    // Token de registro del proveedor push
+	Location   GeoLocation `json:"location"`
+	LocatedAt  time.Time   `json:"located_at"`
+	Registered time.Time   `json:"registered_at"`
+	Language   string      `json:"language,omitempty"` // Idioma de las notificaciones (por defecto, el de la solicitud de registro)
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+)
+
+// Tipos de comando que se pueden enviar a un equipo de campo
+const (
+	DeviceCommandSetReportingInterval = "set_reporting_interval" // Cambia el intervalo de reporte
+	DeviceCommandReadNow              = "read_now"               // Solicita una lectura inmediata
+)
+
+// Estados de entrega de un comando
+const (
+	DeviceCommandSent   = "sent"   // El broker confirmó la publicación
+	DeviceCommandFailed = "failed" // No se pudo publicar
+)
+
+// DeviceCommand es un mensaje de bajada (downlink) publicado en el tema del equipo
+type DeviceCommand struct {
+	ID                string    `json:"id"`
+	DeviceID          string    `json:"device_id"`
+	Type              string    `json:"type"`                         // set_reporting_interval o read_now
+	ReportingInterval int       `json:"reporting_interval,omitempty"` // Segundos, para set_reporting_interval
+	Status            string    `json:"status"`                       // sent o failed
+	Error             string    `json:"error,omitempty"`
+	IssuedBy          string    `json:"issued_by,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// IsValid indica si el comando está completo según su tipo
+func (c *DeviceCommand) IsValid() bool {
+	switch c.Type {
+	case DeviceCommandSetReportingInterval:
+		return c.ReportingInterval > 0
+	case DeviceCommandReadNow:
+		return c.ReportingInterval == 0
+	default:
+		return false
+	}
+}
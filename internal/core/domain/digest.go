@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Digest es el resumen diario de los tanques de un canal
+type Digest struct {
+	ChannelID    string        `json:"channel_id"`
+	Date         string        `json:"date"` // Fecha del resumen en la zona horaria del canal
+	GeneratedAt  time.Time     `json:"generated_at"`
+	Tanks        []*TankDigest `json:"tanks"`
+	ActiveAlerts int           `json:"active_alerts"` // Tanques en aviso o en estado crítico
+	Alerts       []*Alert      `json:"alerts"`        // Alertas acumuladas desde el resumen anterior
+}
+
+// TankDigest es la línea de un tanque en el resumen diario
+type TankDigest struct {
+	TankID           string   `json:"tank_id"`
+	TankName         string   `json:"tank_name"`
+	Status           string   `json:"status"`
+	Level            float64  `json:"level"`
+	Capacity         float64  `json:"capacity"`
+	Percentage       float64  `json:"percentage"`
+	Change24h        *float64 `json:"change_24h,omitempty"`       // Litros ganados o perdidos en 24 h; nil sin lecturas de entonces
+	DailyConsumption float64  `json:"daily_consumption"`          // Consumo estimado en litros por día
+	DaysUntilEmpty   *float64 `json:"days_until_empty,omitempty"` // nil si el tanque no se está consumiendo
+}
+
+// NewDigest crea el resumen con las líneas de los tanques, los más urgentes primero
+func NewDigest(channel *NotificationChannel, tanks []*TankDigest, alerts []*Alert, now time.Time) *Digest {
+	sort.SliceStable(tanks, func(i, j int) bool {
+		a, b := digestStatusRank(tanks[i].Status), digestStatusRank(tanks[j].Status)
+		if a != b {
+			return a > b
+		}
+		return tanks[i].Percentage < tanks[j].Percentage
+	})
+
+	digest := &Digest{
+		ChannelID:   channel.ID,
+		Date:        channel.LocalDate(now),
+		GeneratedAt: now,
+		Tanks:       tanks,
+		Alerts:      alerts,
+	}
+	for _, tank := range tanks {
+		if digestStatusRank(tank.Status) > 0 {
+			digest.ActiveAlerts++
+		}
+	}
+	if digest.Tanks == nil {
+		digest.Tanks = make([]*TankDigest, 0)
+	}
+	if digest.Alerts == nil {
+		digest.Alerts = make([]*Alert, 0)
+	}
+	return digest
+}
+
+// NewTankDigest resume el tanque a partir de sus mediciones recientes, en cualquier orden: la
+// variación frente a la última lectura de hace 24 h o más y, con el consumo estimado, los días
+// que faltan para vaciarlo
+func NewTankDigest(tank *Tank, measurements []*Measurement, now time.Time) *TankDigest {
+	digest := &TankDigest{
+		TankID:           tank.ID,
+		TankName:         tank.Name,
+		Status:           tank.Status,
+		Level:            tank.CurrentLevel,
+		Capacity:         tank.Capacity,
+		Percentage:       tank.GetLevelPercentage(),
+		DailyConsumption: EstimateDailyConsumption(measurements),
+	}
+
+	dayAgo := now.Add(-24 * time.Hour)
+	var reference *Measurement
+	for _, m := range measurements {
+		if !m.Timestamp.After(dayAgo) && (reference == nil || m.Timestamp.After(reference.Timestamp)) {
+			reference = m
+		}
+	}
+	if reference != nil {
+		change := tank.CurrentLevel - reference.Level
+		digest.Change24h = &change
+	}
+
+	if digest.DailyConsumption > 0 {
+		days := tank.CurrentLevel / digest.DailyConsumption
+		digest.DaysUntilEmpty = &days
+	}
+	return digest
+}
+
+// digestStatusRank ordena los estados de menor a mayor urgencia
+func digestStatusRank(status string) int {
+	switch status {
+	case "critical":
+		return 2
+	case "warning":
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,275 @@
+package domain
+
+import "time"
+
+// Modos de ejecución del binario
+const (
+	RunModeServer = "server" // Instancia central (por defecto)
+	RunModeEdge   = "edge"   // Pasarela de un sitio que reenvía sus mediciones a la instancia central
+)
+
+// MaxSyncBatchSize limita las mediciones de cada lote de sincronización
+const MaxSyncBatchSize = 1000
+
+// Resultado de cada medición de un lote de sincronización
+const (
+	SyncRecordAccepted = "accepted" // Guardada, o ya estaba guardada (reenvío tras un corte)
+	SyncRecordRejected = "rejected" // Inválida o de un tanque desconocido; reenviarla no cambiaría nada
+)
+
+// MaxSyncPullLimit limita los cambios de configuración de cada respuesta de /api/sync/pull
+const MaxSyncPullLimit = 500
+
+// SyncOriginCentral identifica como origen de un cambio a la instancia central
+const SyncOriginCentral = "central"
+
+// Resultado de cada cambio de configuración de un tanque recibido de la otra instancia
+const (
+	TankSyncApplied  = "applied"  // Se aplicó entero
+	TankSyncMerged   = "merged"   // Se aplicaron los campos más recientes; el resto ya tenía un cambio posterior
+	TankSyncIgnored  = "ignored"  // La configuración local ya era igual o más reciente
+	TankSyncRejected = "rejected" // No se pudo aplicar (p. ej. configuración inválida)
+)
+
+// SyncBatch es un lote que una pasarela edge envía a la instancia central con POST
+// /api/sync/push: sus mediciones, de la más antigua a la más reciente, y los cambios de la
+// configuración de sus tanques. BatchID identifica el lote: si se repite, la instancia central
+// devuelve la respuesta que ya dio sin aplicarlo de nuevo.
+type SyncBatch struct {
+	GatewayID    string           `json:"gateway_id"`
+	BatchID      string           `json:"batch_id"`
+	Measurements []*Measurement   `json:"measurements"`
+	Tanks        []*TankSyncState `json:"tanks,omitempty"`
+}
+
+// SyncResult es la respuesta de la instancia central a un lote de sincronización
+type SyncResult struct {
+	GatewayID  string            `json:"gateway_id"`
+	BatchID    string            `json:"batch_id"`
+	ReceivedAt time.Time         `json:"received_at"`
+	Accepted   int               `json:"accepted"`
+	Rejected   int               `json:"rejected"`
+	Records    []*SyncRecord     `json:"records"`         // En el orden del lote
+	Tanks      []*TankSyncRecord `json:"tanks,omitempty"` // En el orden del lote
+}
+
+// SyncRecord es el resultado de una medición del lote
+type SyncRecord struct {
+	MeasurementID string `json:"measurement_id"`
+	TankID        string `json:"tank_id"`
+	Status        string `json:"status"` // accepted o rejected
+	Error         string `json:"error,omitempty"`
+}
+
+// Add incorpora el resultado de una medición y actualiza los totales
+func (r *SyncResult) Add(record *SyncRecord) {
+	r.Records = append(r.Records, record)
+	if record.Status == SyncRecordAccepted {
+		r.Accepted++
+	} else {
+		r.Rejected++
+	}
+}
+
+// EdgeSyncStatus es el estado de la sincronización de una pasarela edge con la instancia central
+type EdgeSyncStatus struct {
+	GatewayID  string     `json:"gateway_id"`
+	Upstream   string     `json:"upstream"`
+	Pending    int        `json:"pending"`                // Mediciones guardadas en la pasarela pendientes de reenviar
+	PullCursor int64      `json:"pull_cursor"`            // Último cambio de configuración de la instancia central aplicado
+	LastSyncAt *time.Time `json:"last_sync_at,omitempty"` // Última sincronización completa
+	LastError  string     `json:"last_error,omitempty"`   // Error del último intento, vacío si tuvo éxito
+}
+
+// TankSyncRecord es el resultado de un cambio de configuración de un tanque recibido de la otra
+// instancia
+type TankSyncRecord struct {
+	TankID string   `json:"tank_id"`
+	Status string   `json:"status"`           // applied, merged, ignored o rejected
+	Fields []string `json:"fields,omitempty"` // Campos aplicados
+	Error  string   `json:"error,omitempty"`
+}
+
+// SyncPullResult es la respuesta de GET /api/sync/pull: los cambios de configuración posteriores
+// al cursor, en el orden en que se registraron
+type SyncPullResult struct {
+	Tanks   []*TankSyncState `json:"tanks"`
+	Cursor  int64            `json:"cursor"`   // Cursor de la siguiente consulta
+	HasMore bool             `json:"has_more"` // Quedan cambios después del cursor
+}
+
+// TankSyncFields son los campos de configuración de un tanque que se sincronizan. El nivel, la
+// temperatura y el estado provienen de las mediciones, que se sincronizan aparte.
+var TankSyncFields = []string{
+	"name", "site_id", "group_id", "parent_id", "labels", "location", TankFieldCapacity,
+	"liquid_type", TankFieldAlertThreshold, "reorder", "timezone", "status_webhook_url",
+}
+
+// TankSyncState es la última configuración conocida de un tanque en el registro de
+// sincronización de una instancia, con la fecha del último cambio de cada campo
+type TankSyncState struct {
+	TankID     string               `json:"tank_id"`
+	Sequence   int64                `json:"sequence"`             // Posición en el registro de la instancia que lo envía
+	Origin     string               `json:"origin"`               // central o el ID de la pasarela del último cambio
+	Tank       *Tank                `json:"tank,omitempty"`       // Solo los campos de configuración; nil si se borró
+	FieldTimes map[string]time.Time `json:"field_times"`          // Fecha del último cambio de cada campo
+	DeletedAt  *time.Time           `json:"deleted_at,omitempty"` // Fecha del borrado
+}
+
+// NewTankSyncState crea el estado de un tanque con la configuración de tank y los campos
+// indicados cambiados en at. Los demás campos conservan la fecha de previous, si existe.
+func NewTankSyncState(previous *TankSyncState, tank *Tank, fields []string, origin string, at time.Time) *TankSyncState {
+	state := &TankSyncState{
+		TankID:     tank.ID,
+		Origin:     origin,
+		Tank:       TankSyncConfig(tank),
+		FieldTimes: make(map[string]time.Time, len(TankSyncFields)),
+	}
+	if previous != nil && previous.DeletedAt == nil {
+		for field, changedAt := range previous.FieldTimes {
+			state.FieldTimes[field] = changedAt
+		}
+	}
+	for _, field := range fields {
+		state.FieldTimes[field] = at
+	}
+	return state
+}
+
+// NewTankSyncTombstone crea el estado de un tanque borrado en at
+func NewTankSyncTombstone(tankID, origin string, at time.Time) *TankSyncState {
+	return &TankSyncState{
+		TankID:     tankID,
+		Origin:     origin,
+		FieldTimes: make(map[string]time.Time),
+		DeletedAt:  &at,
+	}
+}
+
+// TankSyncConfig devuelve una copia de tank con solo su ID y sus campos de configuración
+func TankSyncConfig(tank *Tank) *Tank {
+	config := &Tank{ID: tank.ID}
+	for _, field := range TankSyncFields {
+		CopyTankConfigField(config, tank, field)
+	}
+	return config
+}
+
+// CopyTankConfigField copia en dst el valor del campo de configuración field de src
+func CopyTankConfigField(dst, src *Tank, field string) {
+	switch field {
+	case "name":
+		dst.Name = src.Name
+	case "site_id":
+		dst.SiteID = src.SiteID
+	case "group_id":
+		dst.GroupID = src.GroupID
+	case "parent_id":
+		dst.ParentID = src.ParentID
+	case "labels":
+		dst.Labels = src.Labels.Clone()
+	case "location":
+		dst.Location = nil
+		if src.Location != nil {
+			location := *src.Location
+			dst.Location = &location
+		}
+	case TankFieldCapacity:
+		dst.Capacity = src.Capacity
+	case "liquid_type":
+		dst.LiquidType = src.LiquidType
+	case TankFieldAlertThreshold:
+		dst.AlertThreshold = src.AlertThreshold
+	case "reorder":
+		dst.Reorder = src.Reorder
+	case "timezone":
+		dst.Timezone = src.Timezone
+	case "status_webhook_url":
+		dst.StatusWebhookURL = src.StatusWebhookURL
+	}
+}
+
+// lastChange devuelve la fecha del último cambio del estado: el borrado o el campo más reciente
+func (s *TankSyncState) lastChange() time.Time {
+	if s.DeletedAt != nil {
+		return *s.DeletedAt
+	}
+	var last time.Time
+	for _, changedAt := range s.FieldTimes {
+		if changedAt.After(last) {
+			last = changedAt
+		}
+	}
+	return last
+}
+
+// MergeTankSyncState combina el estado local de un tanque con el recibido de la otra instancia.
+// Cada campo conserva el valor de su último cambio (gana el último en escribir) y, en caso de
+// empate, el local. Un borrado gana a los cambios anteriores a él, y un cambio posterior a un
+// borrado recupera el tanque con la configuración recibida. Devuelve el estado combinado y los
+// campos tomados del recibido, o nil si el local ya era igual o más reciente.
+func MergeTankSyncState(local, remote *TankSyncState) (*TankSyncState, []string) {
+	switch {
+	case local == nil:
+		return remote.Clone(), remote.setFields()
+	case remote.DeletedAt != nil:
+		if local.DeletedAt != nil || !remote.DeletedAt.After(local.lastChange()) {
+			return nil, nil
+		}
+		return remote.Clone(), nil
+	case local.DeletedAt != nil:
+		if !remote.lastChange().After(*local.DeletedAt) {
+			return nil, nil
+		}
+		return remote.Clone(), remote.setFields()
+	}
+
+	merged := local.Clone()
+	merged.Origin = remote.Origin
+	var fields []string
+	for _, field := range TankSyncFields {
+		changedAt, ok := remote.FieldTimes[field]
+		if !ok || !changedAt.After(local.FieldTimes[field]) {
+			continue
+		}
+		CopyTankConfigField(merged.Tank, remote.Tank, field)
+		merged.FieldTimes[field] = changedAt
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return merged, fields
+}
+
+// setFields devuelve los campos con fecha de cambio, en el orden de TankSyncFields
+func (s *TankSyncState) setFields() []string {
+	var fields []string
+	for _, field := range TankSyncFields {
+		if _, ok := s.FieldTimes[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Clone devuelve una copia del estado
+func (s *TankSyncState) Clone() *TankSyncState {
+	state := &TankSyncState{
+		TankID:     s.TankID,
+		Sequence:   s.Sequence,
+		Origin:     s.Origin,
+		FieldTimes: make(map[string]time.Time, len(s.FieldTimes)),
+	}
+	if s.Tank != nil {
+		state.Tank = TankSyncConfig(s.Tank)
+	}
+	for field, changedAt := range s.FieldTimes {
+		state.FieldTimes[field] = changedAt
+	}
+	if s.DeletedAt != nil {
+		deletedAt := *s.DeletedAt
+		state.DeletedAt = &deletedAt
+	}
+	return state
+}
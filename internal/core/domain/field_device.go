@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+)
+
+// DeviceConfig es la configuración de reporte de un equipo de campo (gateway o sensor)
+type DeviceConfig struct {
+	Version            int     `json:"version"`              // Se incrementa con cada cambio de la configuración deseada
+	ReportingInterval  int     `json:"reporting_interval"`   // Segundos entre lecturas
+	LowLevelThreshold  float64 `json:"low_level_threshold"`  // Porcentaje de llenado que dispara un reporte inmediato
+	HighLevelThreshold float64 `json:"high_level_threshold"` // Porcentaje de llenado que dispara un reporte inmediato (0 lo desactiva)
+	ReportOnChange     float64 `json:"report_on_change"`     // Litros de variación que disparan un reporte fuera de intervalo (0 lo desactiva)
+}
+
+// IsValid indica si la configuración puede enviarse a un equipo
+func (c DeviceConfig) IsValid() bool {
+	if c.ReportingInterval <= 0 || c.ReportOnChange < 0 {
+		return false
+	}
+	if c.LowLevelThreshold < 0 || c.LowLevelThreshold > 100 || c.HighLevelThreshold < 0 || c.HighLevelThreshold > 100 {
+		return false
+	}
+	return c.HighLevelThreshold == 0 || c.LowLevelThreshold < c.HighLevelThreshold
+}
+
+// FieldDevice es un equipo de campo que consulta periódicamente su configuración deseada y
+// confirma la que aplicó, de modo que la API sepa qué equipos están pendientes de actualizar
+type FieldDevice struct {
+	ID           string        `json:"id"`
+	TankID       string        `json:"tank_id"`
+	Desired      DeviceConfig  `json:"desired"`
+	Applied      *DeviceConfig `json:"applied,omitempty"` // Última configuración confirmada por el equipo
+	InSync       bool          `json:"in_sync"`           // El equipo aplicó la versión deseada
+	UpdatedAt    time.Time     `json:"updated_at"`        // Último cambio de la configuración deseada
+	LastPolledAt *time.Time    `json:"last_polled_at,omitempty"`
+	AppliedAt    *time.Time    `json:"applied_at,omitempty"`
+}
+
+// UpdateSync recalcula si el equipo tiene aplicada la configuración deseada
+func (d *FieldDevice) UpdateSync() {
+	d.InSync = d.Applied != nil && d.Applied.Version == d.Desired.Version
+}
@@ -0,0 +1,456 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxFilterLength limita la longitud de una expresión de filtro
+const MaxFilterLength = 1024
+
+// Nodos de una expresión de filtro
+const (
+	FilterAnd     = "and"
+	FilterOr      = "or"
+	FilterNot     = "not"
+	FilterCompare = "compare"
+)
+
+// Operadores de comparación de los filtros
+const (
+	FilterEqual        = "="
+	FilterNotEqual     = "!="
+	FilterLess         = "<"
+	FilterLessEqual    = "<="
+	FilterGreater      = ">"
+	FilterGreaterEqual = ">="
+)
+
+// FilterFieldType es el tipo de los valores de un campo filtrable
+type FilterFieldType int
+
+// Tipos de los campos filtrables
+const (
+	FilterNumber FilterFieldType = iota
+	FilterText
+	FilterTime
+)
+
+// MeasurementFilterFields son los campos por los que se filtran las mediciones
+var MeasurementFilterFields = map[string]FilterFieldType{
+	"level":           FilterNumber,
+	"temperature":     FilterNumber,
+	"battery_voltage": FilterNumber,
+	"rssi":            FilterNumber,
+	"sensor_id":       FilterText,
+	"timestamp":       FilterTime,
+}
+
+// QueuedAlertFilterFields son los campos por los que se filtran las alertas en cola. level es el
+// nivel del tanque al generarse la alerta.
+var QueuedAlertFilterFields = map[string]FilterFieldType{
+	"type":      FilterText,
+	"severity":  FilterText,
+	"tank_id":   FilterText,
+	"sensor_id": FilterText,
+	"level":     FilterNumber,
+	"timestamp": FilterTime,
+	"queued_at": FilterTime,
+}
+
+// Filter es una expresión de filtro ya validada, p. ej. level<100 AND temperature>30. Es un árbol:
+// los nodos and y or tienen dos operandos, not uno y compare ninguno. Los repositorios que la
+// admiten pueden traducirla a su lenguaje de consulta (p. ej. a un WHERE de SQL) recorriendo el
+// árbol; el resto la evalúan con Match.
+type Filter struct {
+	Op         string
+	Operands   []*Filter
+	Field      string
+	Comparator string
+	Type       FilterFieldType
+	Number     float64
+	Text       string
+	Time       time.Time
+}
+
+// FilterRecord es un registro al que se aplica un filtro. FilterValue devuelve el valor del campo
+// (float64, string o time.Time según su tipo), o false si el registro no lo tiene.
+type FilterRecord interface {
+	FilterValue(field string) (interface{}, bool)
+}
+
+// filterTruth es el resultado de evaluar un filtro con la lógica de tres valores de SQL
+type filterTruth int
+
+const (
+	filterFalse filterTruth = iota
+	filterUnknown
+	filterTrue
+)
+
+// Match indica si el registro cumple el filtro. Como en SQL, una comparación con un campo sin
+// valor (p. ej. rssi en un sensor que no la reporta) es desconocida: no se cumple, tampoco con !=
+// ni negada con NOT, y AND y OR la combinan con la lógica de tres valores (desconocido OR cierto
+// es cierto; desconocido AND falso, falso). Así el resultado coincide con el de un repositorio
+// que traduzca el filtro a un WHERE de SQL.
+func (f *Filter) Match(record FilterRecord) bool {
+	return f.eval(record) == filterTrue
+}
+
+// eval evalúa el filtro sobre el registro con la lógica de tres valores
+func (f *Filter) eval(record FilterRecord) filterTruth {
+	switch f.Op {
+	case FilterAnd:
+		return min(f.Operands[0].eval(record), f.Operands[1].eval(record))
+	case FilterOr:
+		return max(f.Operands[0].eval(record), f.Operands[1].eval(record))
+	case FilterNot:
+		return filterTrue - f.Operands[0].eval(record)
+	}
+
+	value, ok := record.FilterValue(f.Field)
+	if !ok {
+		return filterUnknown
+	}
+	var cmp int
+	switch f.Type {
+	case FilterNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return filterUnknown
+		}
+		cmp = compareFloat(number, f.Number)
+	case FilterText:
+		text, ok := value.(string)
+		if !ok {
+			return filterUnknown
+		}
+		cmp = strings.Compare(strings.ToLower(text), strings.ToLower(f.Text))
+	case FilterTime:
+		ts, ok := value.(time.Time)
+		if !ok {
+			return filterUnknown
+		}
+		cmp = ts.Compare(f.Time)
+	}
+
+	var matched bool
+	switch f.Comparator {
+	case FilterEqual:
+		matched = cmp == 0
+	case FilterNotEqual:
+		matched = cmp != 0
+	case FilterLess:
+		matched = cmp < 0
+	case FilterLessEqual:
+		matched = cmp <= 0
+	case FilterGreater:
+		matched = cmp > 0
+	default:
+		matched = cmp >= 0
+	}
+	if matched {
+		return filterTrue
+	}
+	return filterFalse
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// FilterError describe por qué no es válida una expresión de filtro y dónde
+type FilterError struct {
+	Message  string
+	Position int // Carácter en el que se detectó, desde 1; 0 si afecta a toda la expresión
+}
+
+func (e *FilterError) Error() string {
+	if e.Position == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+// ParseFilter analiza una expresión de filtro sobre los campos indicados. La gramática admite
+// comparaciones campo operador valor (=, !=, <, <=, >, >=) combinadas con AND, OR, NOT y
+// paréntesis; AND se evalúa antes que OR y las palabras clave no distinguen mayúsculas. Los
+// textos con espacios van entre comillas simples o dobles y las fechas admiten los formatos de
+// ParseTimestamp. Los textos solo se comparan con = y !=, sin distinguir mayúsculas. Los errores
+// son FilterError, con la posición en la que se detectaron.
+func ParseFilter(expression string, fields map[string]FilterFieldType) (*Filter, error) {
+	if len(expression) > MaxFilterLength {
+		return nil, &FilterError{Message: fmt.Sprintf("longer than %d characters", MaxFilterLength)}
+	}
+	tokens, err := lexFilter(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, &FilterError{Message: "empty expression"}
+	}
+
+	parser := &filterParser{tokens: tokens, fields: fields, end: len([]rune(expression))}
+	filter, err := parser.or()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(tokens) {
+		return nil, parser.errorf("unexpected %q", tokens[parser.pos].text)
+	}
+	return filter, nil
+}
+
+// Tipos de los elementos léxicos de un filtro
+const (
+	filterTokenWord = iota // Campo, palabra clave o valor sin comillas
+	filterTokenQuoted
+	filterTokenOperator
+	filterTokenOpen
+	filterTokenClose
+)
+
+type filterToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+// lexFilter divide la expresión en campos, valores, operadores y paréntesis
+func lexFilter(expression string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: filterTokenOpen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: filterTokenClose, text: ")", pos: i})
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, &FilterError{Message: "unterminated quote", Position: i + 1}
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenQuoted, text: string(runes[i+1 : end]), pos: i})
+			i = end + 1
+		case strings.ContainsRune("=!<>", r):
+			operator := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				operator += "="
+			}
+			if operator == "!" {
+				return nil, &FilterError{Message: `unexpected "!"`, Position: i + 1}
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenOperator, text: operator, pos: i})
+			i += len(operator)
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("()'\"=!<>", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenWord, text: string(runes[i:end]), pos: i})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// filterParser analiza los elementos léxicos por descenso recursivo
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+	fields map[string]FilterFieldType
+	end    int // Longitud de la expresión, posición de los errores al final
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	position := p.end
+	if p.pos < len(p.tokens) {
+		position = p.tokens[p.pos].pos
+	}
+	return &FilterError{Message: fmt.Sprintf(format, args...), Position: position + 1}
+}
+
+// keyword indica si el siguiente elemento es la palabra clave indicada y, si lo es, la consume
+func (p *filterParser) keyword(word string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == filterTokenWord && strings.EqualFold(p.tokens[p.pos].text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) or() (*Filter, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &Filter{Op: FilterOr, Operands: []*Filter{left, right}}
+	}
+	return left, nil
+}
+
+func (p *filterParser) and() (*Filter, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Filter{Op: FilterAnd, Operands: []*Filter{left, right}}
+	}
+	return left, nil
+}
+
+func (p *filterParser) unary() (*Filter, error) {
+	if p.keyword("NOT") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Filter{Op: FilterNot, Operands: []*Filter{operand}}, nil
+	}
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == filterTokenOpen {
+		p.pos++
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != filterTokenClose {
+			return nil, p.errorf("missing \")\"")
+		}
+		p.pos++
+		return inner, nil
+	}
+	return p.comparison()
+}
+
+// comparison analiza campo operador valor y convierte el valor al tipo del campo
+func (p *filterParser) comparison() (*Filter, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	field := p.tokens[p.pos]
+	if field.kind != filterTokenWord {
+		return nil, p.errorf("expected a field, got %q", field.text)
+	}
+	name := strings.ToLower(field.text)
+	fieldType, ok := p.fields[name]
+	if !ok {
+		return nil, p.errorf("unknown field %q", field.text)
+	}
+	p.pos++
+
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != filterTokenOperator {
+		return nil, p.errorf("expected a comparison operator after %q", field.text)
+	}
+	comparator := p.tokens[p.pos].text
+	p.pos++
+
+	if p.pos >= len(p.tokens) || (p.tokens[p.pos].kind != filterTokenWord && p.tokens[p.pos].kind != filterTokenQuoted) {
+		return nil, p.errorf("expected a value after %q", comparator)
+	}
+	value := p.tokens[p.pos]
+
+	filter := &Filter{Op: FilterCompare, Field: name, Comparator: comparator, Type: fieldType}
+	switch fieldType {
+	case FilterNumber:
+		number, err := strconv.ParseFloat(value.text, 64)
+		if err != nil {
+			return nil, p.errorf("%s expects a number, got %q", name, value.text)
+		}
+		filter.Number = number
+	case FilterText:
+		if comparator != FilterEqual && comparator != FilterNotEqual {
+			return nil, p.errorf("%s only supports = and !=", name)
+		}
+		filter.Text = value.text
+	case FilterTime:
+		ts, err := ParseTimestamp(value.text)
+		if err != nil {
+			return nil, p.errorf("%s expects a timestamp, got %q", name, value.text)
+		}
+		filter.Time = ts
+	}
+	p.pos++
+	return filter, nil
+}
+
+// FilterValue devuelve el valor de un campo de MeasurementFilterFields
+func (m *Measurement) FilterValue(field string) (interface{}, bool) {
+	switch field {
+	case "level":
+		return m.Level, true
+	case "temperature":
+		return m.Temperature, true
+	case "battery_voltage":
+		if m.BatteryVoltage == nil {
+			return nil, false
+		}
+		return *m.BatteryVoltage, true
+	case "rssi":
+		if m.SignalStrength == nil {
+			return nil, false
+		}
+		return *m.SignalStrength, true
+	case "sensor_id":
+		return m.SensorID, m.SensorID != ""
+	case "timestamp":
+		return m.Timestamp, true
+	}
+	return nil, false
+}
+
+// FilterValue devuelve el valor de un campo de QueuedAlertFilterFields
+func (q *QueuedAlert) FilterValue(field string) (interface{}, bool) {
+	switch field {
+	case "tank_id":
+		return q.TankID, true
+	case "queued_at":
+		return q.QueuedAt, true
+	}
+	if q.Alert == nil {
+		return nil, false
+	}
+	switch field {
+	case "type":
+		return q.Alert.Type, true
+	case "severity":
+		return q.Alert.Severity, true
+	case "sensor_id":
+		return q.Alert.SensorID, q.Alert.SensorID != ""
+	case "timestamp":
+		return q.Alert.Timestamp, true
+	case "level":
+		if q.Alert.Tank == nil {
+			return nil, false
+		}
+		return q.Alert.Tank.CurrentLevel, true
+	}
+	return nil, false
+}
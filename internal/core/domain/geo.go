@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// GeoLocation es la posición geográfica de un tanque en grados decimales (WGS 84)
+type GeoLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// IsValid indica si las coordenadas están dentro de los rangos válidos
+func (l GeoLocation) IsValid() bool {
+	return l.Latitude >= -90 && l.Latitude <= 90 && l.Longitude >= -180 && l.Longitude <= 180
+}
+
+// BoundingBox es un rectángulo geográfico usado para filtrar los tanques visibles en un mapa
+type BoundingBox struct {
+	MinLongitude float64
+	MinLatitude  float64
+	MaxLongitude float64
+	MaxLatitude  float64
+}
+
+// Contains indica si la posición está dentro del rectángulo
+func (b BoundingBox) Contains(l GeoLocation) bool {
+	return l.Latitude >= b.MinLatitude && l.Latitude <= b.MaxLatitude &&
+		l.Longitude >= b.MinLongitude && l.Longitude <= b.MaxLongitude
+}
+
+// FeatureCollection es una colección GeoJSON (RFC 7946)
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature es un elemento GeoJSON con su geometría y propiedades
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Geometry   PointGeometry          `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// PointGeometry es una geometría GeoJSON de tipo punto. Las coordenadas van en orden [longitud, latitud].
+type PointGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// BuildTankFeatureCollection convierte los tanques con ubicación en una colección GeoJSON con su
+// nivel y estado como propiedades. Si bbox no es nil, solo se incluyen los tanques dentro de él.
+func BuildTankFeatureCollection(tanks []*Tank, bbox *BoundingBox) FeatureCollection {
+	collection := FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]Feature, 0, len(tanks)),
+	}
+
+	for _, tank := range tanks {
+		if tank.Location == nil {
+			continue
+		}
+		if bbox != nil && !bbox.Contains(*tank.Location) {
+			continue
+		}
+
+		properties := map[string]interface{}{
+			"name":             tank.Name,
+			"site_id":          tank.SiteID,
+			"group_id":         tank.GroupID,
+			"liquid_type":      tank.LiquidType,
+			"capacity":         tank.Capacity,
+			"current_level":    tank.CurrentLevel,
+			"level_percentage": tank.GetLevelPercentage(),
+			"status":           tank.Status,
+			"last_updated":     tank.LastUpdated.Format(time.RFC3339),
+		}
+		if len(tank.Labels) > 0 {
+			properties["labels"] = tank.Labels
+		}
+
+		collection.Features = append(collection.Features, Feature{
+			Type: "Feature",
+			ID:   tank.ID,
+			Geometry: PointGeometry{
+				Type:        "Point",
+				Coordinates: [2]float64{tank.Location.Longitude, tank.Location.Latitude},
+			},
+			Properties: properties,
+		})
+	}
+
+	return collection
+}
+
+// earthRadiusKm es el radio medio de la Tierra usado para calcular distancias
+const earthRadiusKm = 6371.0
+
+// DistanceKm calcula la distancia en kilómetros entre dos posiciones (fórmula del haversine)
+func (l GeoLocation) DistanceKm(other GeoLocation) float64 {
+	lat1 := l.Latitude * math.Pi / 180
+	lat2 := other.Latitude * math.Pi / 180
+	deltaLat := (other.Latitude - l.Latitude) * math.Pi / 180
+	deltaLon := (other.Longitude - l.Longitude) * math.Pi / 180
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrInventoryRejected indica que el ERP rechazó el envío de forma definitiva (por ejemplo, por
+// credenciales o formato inválidos), así que repetirlo no serviría de nada
+var ErrInventoryRejected = errors.New("inventory rejected by ERP")
+
+// Estados de un envío de inventario al ERP
+const (
+	InventorySyncDelivered = "delivered"
+	InventorySyncFailed    = "failed"
+)
+
+// InventoryRecord es la existencia de un tanque en el momento del envío
+type InventoryRecord struct {
+	TankID      string    `json:"tank_id"`
+	TankName    string    `json:"tank_name"`
+	SiteID      string    `json:"site_id"`
+	GroupID     string    `json:"group_id"`
+	LiquidType  string    `json:"liquid_type"`
+	Capacity    float64   `json:"capacity"`
+	Level       float64   `json:"level"`
+	Percentage  float64   `json:"percentage"`
+	Temperature float64   `json:"temperature"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// InventoryBatch es el inventario enviado en una sincronización. El ID se conserva en los
+// reintentos para que el ERP pueda descartar los duplicados.
+type InventoryBatch struct {
+	ID          string            `json:"batch_id"`
+	Connector   string            `json:"connector"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Records     []InventoryRecord `json:"records"`
+}
+
+// InventorySync es el resultado de una sincronización con el ERP
+type InventorySync struct {
+	BatchID      string     `json:"batch_id"`
+	Connector    string     `json:"connector"`
+	Status       string     `json:"status"` // delivered o failed
+	Tanks        int        `json:"tanks"`
+	Attempts     int        `json:"attempts"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   time.Time  `json:"finished_at"`
+	Confirmation string     `json:"confirmation,omitempty"` // Acuse del ERP (referencia, archivo, ...)
+	Error        string     `json:"error,omitempty"`
+	DeliveredAt  *time.Time `json:"delivered_at,omitempty"`
+}
+
+// InventoryConnector es la configuración común de una conexión con un ERP
+type InventoryConnector struct {
+	Name         string
+	Retries      int           // Reintentos tras un fallo transitorio
+	RetryBackoff time.Duration // Espera antes del primer reintento; se duplica en cada uno
+	TankSelector LabelSelector // Tanques incluidos (vacío incluye todos)
+}
+
+// BuildInventoryBatch arma el inventario de los tanques que cumplen el selector, ordenado por
+// sitio y tanque para que los envíos sucesivos sean comparables
+func BuildInventoryBatch(id, connector string, tanks []*Tank, selector LabelSelector, now time.Time) *InventoryBatch {
+	batch := &InventoryBatch{ID: id, Connector: connector, GeneratedAt: now, Records: []InventoryRecord{}}
+	for _, tank := range FilterTanksByLabels(tanks, selector) {
+		batch.Records = append(batch.Records, InventoryRecord{
+			TankID:      tank.ID,
+			TankName:    tank.Name,
+			SiteID:      tank.SiteID,
+			GroupID:     tank.GroupID,
+			LiquidType:  tank.LiquidType,
+			Capacity:    tank.Capacity,
+			Level:       tank.CurrentLevel,
+			Percentage:  tank.GetLevelPercentage(),
+			Temperature: tank.Temperature,
+			LastUpdated: tank.LastUpdated,
+		})
+	}
+
+	sort.Slice(batch.Records, func(i, j int) bool {
+		if batch.Records[i].SiteID != batch.Records[j].SiteID {
+			return batch.Records[i].SiteID < batch.Records[j].SiteID
+		}
+		return batch.Records[i].TankID < batch.Records[j].TankID
+	})
+
+	return batch
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"time"
+)
+
+// TankKPIs contiene los indicadores de gestión de un tanque en un periodo
+type TankKPIs struct {
+	TankID                string    `json:"tank_id"`
+	From                  time.Time `json:"from"`
+	To                    time.Time `json:"to"`
+	AverageFillPercentage float64   `json:"average_fill_percentage"` // Llenado medio ponderado por tiempo
+	AverageLevel          float64   `json:"average_level"`           // Inventario medio en litros
+	Consumption           float64   `json:"consumption"`             // Litros consumidos (suma de descensos de nivel)
+	Replenishment         float64   `json:"replenishment"`           // Litros recargados (suma de subidas de nivel)
+	Turnover              float64   `json:"turnover"`                // Rotación: consumo / inventario medio
+	DaysAtCritical        float64   `json:"days_at_critical"`        // Días en estado crítico
+	Stockouts             int       `json:"stockouts"`               // Veces que el tanque se vació
+}
+
+// BuildTankKPIs calcula los indicadores del tanque en el periodo [from, to] a partir de su
+// historial completo de mediciones y transiciones de estado. El nivel se considera constante
+// entre mediciones; el tiempo anterior a la primera medición conocida no se contabiliza.
+func BuildTankKPIs(tank *Tank, measurements []*Measurement, changes []*StatusChange, from, to time.Time) *TankKPIs {
+	kpis := &TankKPIs{
+		TankID: tank.ID,
+		From:   from,
+		To:     to,
+	}
+
+	var previous *Measurement
+	cursor := from
+	weightedLevel := 0.0
+	knownSeconds := 0.0
+
+	for _, m := range SortMeasurementsAscending(measurements) {
+		if m.Timestamp.After(to) {
+			break
+		}
+
+		if m.Timestamp.Before(from) {
+			// Nivel vigente al inicio del periodo
+			previous = m
+			continue
+		}
+
+		if previous != nil {
+			seconds := m.Timestamp.Sub(cursor).Seconds()
+			weightedLevel += previous.Level * seconds
+			knownSeconds += seconds
+
+			if change := m.Level - previous.Level; change < 0 {
+				kpis.Consumption -= change
+			} else {
+				kpis.Replenishment += change
+			}
+
+			if previous.Level > 0 && m.Level <= 0 {
+				kpis.Stockouts++
+			}
+		}
+
+		previous = m
+		cursor = m.Timestamp
+	}
+
+	if previous != nil && to.After(cursor) {
+		seconds := to.Sub(cursor).Seconds()
+		weightedLevel += previous.Level * seconds
+		knownSeconds += seconds
+	}
+
+	if knownSeconds > 0 {
+		kpis.AverageLevel = weightedLevel / knownSeconds
+		if tank.Capacity > 0 {
+			kpis.AverageFillPercentage = kpis.AverageLevel / tank.Capacity * 100
+		}
+	}
+
+	if kpis.AverageLevel > 0 {
+		kpis.Turnover = kpis.Consumption / kpis.AverageLevel
+	}
+
+	history := BuildStatusHistory(tank.ID, changes, from, to)
+	kpis.DaysAtCritical = history.TimeInStatus["critical"] / (24 * 60 * 60)
+
+	return kpis
+}
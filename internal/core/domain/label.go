@@ -0,0 +1,270 @@
+package domain
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Límites de las etiquetas, como en Kubernetes
+const (
+	maxLabels           = 64
+	maxLabelNameLength  = 63
+	maxLabelValueLength = 63
+	maxLabelPrefixLen   = 253
+)
+
+// Operadores de los requisitos de un selector de etiquetas
+const (
+	LabelOpEquals       = "="
+	LabelOpNotEquals    = "!="
+	LabelOpIn           = "in"
+	LabelOpNotIn        = "notin"
+	LabelOpExists       = "exists"
+	LabelOpDoesNotExist = "!"
+)
+
+// Errores de las etiquetas y sus selectores
+var (
+	ErrInvalidLabels        = errors.New("invalid labels")
+	ErrInvalidLabelSelector = errors.New("invalid label selector")
+)
+
+// Labels son pares clave/valor libres para clasificar los tanques (region=caribe, customer=acme).
+// Las claves admiten un prefijo opcional separado por "/" (p. ej. acme.com/cuenta).
+type Labels map[string]string
+
+// Validate comprueba el número de etiquetas y el formato de sus claves y valores
+func (l Labels) Validate() error {
+	if len(l) > maxLabels {
+		return ErrInvalidLabels
+	}
+	for key, value := range l {
+		if !validLabelKey(key) || !validLabelValue(value) {
+			return ErrInvalidLabels
+		}
+	}
+	return nil
+}
+
+// Clone devuelve una copia independiente de las etiquetas
+func (l Labels) Clone() Labels {
+	return maps.Clone(l)
+}
+
+// Equal indica si dos conjuntos de etiquetas son iguales; nil equivale a vacío
+func (l Labels) Equal(other Labels) bool {
+	return maps.Equal(l, other)
+}
+
+// LabelRequirement es una condición de un selector: region=caribe, region in (caribe,andina),
+// customer (existe) o !customer (no existe)
+type LabelRequirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+// Matches indica si las etiquetas cumplen el requisito. Como en Kubernetes, != y notin se
+// cumplen también cuando la etiqueta no existe.
+func (r LabelRequirement) Matches(labels Labels) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case LabelOpExists:
+		return ok
+	case LabelOpDoesNotExist:
+		return !ok
+	case LabelOpEquals, LabelOpIn:
+		return ok && slices.Contains(r.Values, value)
+	case LabelOpNotEquals, LabelOpNotIn:
+		return !ok || !slices.Contains(r.Values, value)
+	default:
+		return false
+	}
+}
+
+// LabelSelector selecciona tanques por sus etiquetas; se deben cumplir todos los requisitos.
+// Un selector vacío selecciona todos los tanques.
+type LabelSelector []LabelRequirement
+
+// ParseLabelSelector interpreta un selector al estilo de Kubernetes, con los requisitos
+// separados por comas: "region=caribe,customer!=acme,tier in (1,2),!retired"
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	parts, err := splitSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements := make(LabelSelector, 0, len(parts))
+	for _, part := range parts {
+		requirement, err := parseLabelRequirement(part)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
+
+// Matches indica si las etiquetas cumplen todos los requisitos del selector
+func (s LabelSelector) Matches(labels Labels) bool {
+	for _, requirement := range s {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty indica si el selector no tiene requisitos
+func (s LabelSelector) Empty() bool {
+	return len(s) == 0
+}
+
+// FilterTanksByLabels devuelve los tanques cuyas etiquetas cumplen el selector
+func FilterTanksByLabels(tanks []*Tank, selector LabelSelector) []*Tank {
+	if selector.Empty() {
+		return tanks
+	}
+
+	filtered := make([]*Tank, 0, len(tanks))
+	for _, tank := range tanks {
+		if selector.Matches(tank.Labels) {
+			filtered = append(filtered, tank)
+		}
+	}
+	return filtered
+}
+
+// splitSelector separa los requisitos por las comas que no están dentro de paréntesis
+func splitSelector(selector string) ([]string, error) {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range selector {
+		switch r {
+		case '(':
+			depth++
+			if depth > 1 {
+				return nil, ErrInvalidLabelSelector
+			}
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, ErrInvalidLabelSelector
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, ErrInvalidLabelSelector
+	}
+	parts = append(parts, selector[start:])
+
+	// Un selector vacío es válido; una coma sobrante no
+	if len(parts) == 1 && strings.TrimSpace(parts[0]) == "" {
+		return nil, nil
+	}
+	return parts, nil
+}
+
+// parseLabelRequirement interpreta un requisito del selector
+func parseLabelRequirement(part string) (LabelRequirement, error) {
+	part = strings.TrimSpace(part)
+
+	if open := strings.Index(part, "("); open >= 0 {
+		if !strings.HasSuffix(part, ")") {
+			return LabelRequirement{}, ErrInvalidLabelSelector
+		}
+		fields := strings.Fields(part[:open])
+		if len(fields) != 2 || (fields[1] != LabelOpIn && fields[1] != LabelOpNotIn) {
+			return LabelRequirement{}, ErrInvalidLabelSelector
+		}
+
+		var values []string
+		for _, value := range strings.Split(part[open+1:len(part)-1], ",") {
+			value = strings.TrimSpace(value)
+			if !validLabelValue(value) {
+				return LabelRequirement{}, ErrInvalidLabelSelector
+			}
+			values = append(values, value)
+		}
+		return newLabelRequirement(fields[0], fields[1], values...)
+	}
+
+	for _, operator := range []string{"!=", "==", "="} {
+		if key, value, ok := strings.Cut(part, operator); ok {
+			value = strings.TrimSpace(value)
+			if !validLabelValue(value) {
+				return LabelRequirement{}, ErrInvalidLabelSelector
+			}
+			if operator == "!=" {
+				return newLabelRequirement(key, LabelOpNotEquals, value)
+			}
+			return newLabelRequirement(key, LabelOpEquals, value)
+		}
+	}
+
+	if key, ok := strings.CutPrefix(part, "!"); ok {
+		return newLabelRequirement(key, LabelOpDoesNotExist)
+	}
+	return newLabelRequirement(part, LabelOpExists)
+}
+
+// newLabelRequirement crea un requisito validando su clave
+func newLabelRequirement(key, operator string, values ...string) (LabelRequirement, error) {
+	key = strings.TrimSpace(key)
+	if !validLabelKey(key) {
+		return LabelRequirement{}, ErrInvalidLabelSelector
+	}
+	return LabelRequirement{Key: key, Operator: operator, Values: values}, nil
+}
+
+// validLabelKey comprueba una clave con prefijo opcional (dominio/nombre)
+func validLabelKey(key string) bool {
+	prefix, name, hasPrefix := strings.Cut(key, "/")
+	if !hasPrefix {
+		name, prefix = prefix, ""
+	} else if prefix == "" || len(prefix) > maxLabelPrefixLen || strings.Contains(name, "/") || !validLabelPrefix(prefix) {
+		return false
+	}
+	return name != "" && len(name) <= maxLabelNameLength && validLabelName(name)
+}
+
+// validLabelValue comprueba un valor: vacío, o con el mismo formato que los nombres
+func validLabelValue(value string) bool {
+	return value == "" || (len(value) <= maxLabelValueLength && validLabelName(value))
+}
+
+// validLabelName admite letras, dígitos, '-', '_' y '.', empezando y terminando en alfanumérico
+func validLabelName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case isAlphanumeric(c):
+		case (c == '-' || c == '_' || c == '.') && i > 0 && i < len(name)-1:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validLabelPrefix admite un nombre de dominio en minúsculas
+func validLabelPrefix(prefix string) bool {
+	for _, part := range strings.Split(prefix, ".") {
+		if part == "" || part != strings.ToLower(part) || !validLabelName(part) || strings.Contains(part, "_") {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlphanumeric indica si el byte es una letra ASCII o un dígito
+func isAlphanumeric(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// TankLevelStats resume la distribución de las lecturas de un tanque en un periodo, para
+// ajustar los umbrales al comportamiento real. Los llenados son porcentajes de la capacidad.
+type TankLevelStats struct {
+	TankID                string    `json:"tank_id"`
+	From                  time.Time `json:"from"`
+	To                    time.Time `json:"to"`
+	Samples               int       `json:"samples"` // Lecturas del periodo; con 0 el resto de valores es 0
+	MeanFillPercentage    float64   `json:"mean_fill_percentage"`
+	MedianFillPercentage  float64   `json:"median_fill_percentage"`
+	P5FillPercentage      float64   `json:"p5_fill_percentage"`
+	P95FillPercentage     float64   `json:"p95_fill_percentage"`
+	MinFillPercentage     float64   `json:"min_fill_percentage"`
+	MaxFillPercentage     float64   `json:"max_fill_percentage"`
+	FillVariance          float64   `json:"fill_variance"` // Varianza poblacional del llenado
+	FillStandardDeviation float64   `json:"fill_standard_deviation"`
+	MinTemperature        *float64  `json:"min_temperature,omitempty"`
+	MaxTemperature        *float64  `json:"max_temperature,omitempty"`
+	CurrentAlertThreshold float64   `json:"current_alert_threshold"` // Umbral vigente, para compararlo con los percentiles
+	SamplesBelowThreshold int       `json:"samples_below_threshold"` // Lecturas con el llenado en el umbral o por debajo
+}
+
+// BuildTankLevelStats calcula las estadísticas de las lecturas del tanque en [from, to]. Cada
+// lectura pesa lo mismo, sin ponderar por el tiempo que estuvo vigente, y los percentiles se
+// interpolan linealmente entre las lecturas ordenadas.
+func BuildTankLevelStats(tank *Tank, measurements []*Measurement, from, to time.Time) *TankLevelStats {
+	stats := &TankLevelStats{
+		TankID:                tank.ID,
+		From:                  from,
+		To:                    to,
+		CurrentAlertThreshold: tank.AlertThreshold,
+	}
+
+	var fills []float64
+	for _, m := range measurements {
+		if m.Timestamp.Before(from) || m.Timestamp.After(to) {
+			continue
+		}
+
+		fill := 0.0
+		if tank.Capacity > 0 {
+			fill = m.Level / tank.Capacity * 100
+		}
+		fills = append(fills, fill)
+		if fill <= tank.AlertThreshold {
+			stats.SamplesBelowThreshold++
+		}
+
+		temperature := m.Temperature
+		if stats.MinTemperature == nil || temperature < *stats.MinTemperature {
+			stats.MinTemperature = &temperature
+		}
+		if stats.MaxTemperature == nil || temperature > *stats.MaxTemperature {
+			stats.MaxTemperature = &temperature
+		}
+	}
+
+	stats.Samples = len(fills)
+	if stats.Samples == 0 {
+		return stats
+	}
+
+	sort.Float64s(fills)
+	stats.MinFillPercentage = fills[0]
+	stats.MaxFillPercentage = fills[len(fills)-1]
+	stats.MedianFillPercentage = percentile(fills, 50)
+	stats.P5FillPercentage = percentile(fills, 5)
+	stats.P95FillPercentage = percentile(fills, 95)
+
+	sum := 0.0
+	for _, fill := range fills {
+		sum += fill
+	}
+	stats.MeanFillPercentage = sum / float64(len(fills))
+
+	squares := 0.0
+	for _, fill := range fills {
+		squares += (fill - stats.MeanFillPercentage) * (fill - stats.MeanFillPercentage)
+	}
+	stats.FillVariance = squares / float64(len(fills))
+	stats.FillStandardDeviation = math.Sqrt(stats.FillVariance)
+
+	return stats
+}
+
+// percentile devuelve el percentil p (0-100) de los valores ya ordenados, interpolando entre
+// los dos más cercanos
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
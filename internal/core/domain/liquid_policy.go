@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// ErrSafetyPolicyViolation indica que la configuración de un tanque incumple la política de su líquido
+var ErrSafetyPolicyViolation = errors.New("liquid safety policy violation")
+
+// maxHazmatContacts limita los destinatarios de los avisos de materiales peligrosos de cada política
+const maxHazmatContacts = 20
+
+// LiquidPolicy es la política de seguridad de un tipo de líquido. Se aplica a todos los tanques
+// que lo almacenan: fija su umbral de alerta al crearlos y limita después los cambios de umbrales.
+type LiquidPolicy struct {
+	LiquidType     string    `json:"liquid_type"`               // Clave de la política, sin distinguir mayúsculas
+	MinSafetyStock float64   `json:"min_safety_stock"`          // Porcentaje mínimo del umbral de alerta y del punto de pedido
+	MaxFill        float64   `json:"max_fill"`                  // Porcentaje máximo de llenado tras una entrega (espacio de expansión)
+	HazmatContacts []string  `json:"hazmat_contacts,omitempty"` // Correos avisados de las alertas críticas de estos tanques
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// LiquidPolicyKey normaliza el tipo de líquido para buscar su política
+func LiquidPolicyKey(liquidType string) string {
+	return strings.ToLower(strings.TrimSpace(liquidType))
+}
+
+// Validate comprueba que los porcentajes sean coherentes y los contactos, correos válidos
+func (p *LiquidPolicy) Validate() error {
+	if LiquidPolicyKey(p.LiquidType) == "" {
+		return errors.New("liquid_type is required")
+	}
+	if p.MinSafetyStock < 0 || p.MinSafetyStock > 100 {
+		return errors.New("min_safety_stock must be between 0 and 100")
+	}
+	if p.MaxFill <= 0 || p.MaxFill > 100 {
+		return errors.New("max_fill must be greater than 0 and at most 100")
+	}
+	if p.MinSafetyStock >= p.MaxFill {
+		return errors.New("min_safety_stock must be lower than max_fill")
+	}
+	if len(p.HazmatContacts) > maxHazmatContacts {
+		return fmt.Errorf("at most %d hazmat contacts are allowed", maxHazmatContacts)
+	}
+	for _, contact := range p.HazmatContacts {
+		if _, err := mail.ParseAddress(contact); err != nil {
+			return fmt.Errorf("invalid hazmat contact %q", contact)
+		}
+	}
+	return nil
+}
+
+// ApplyDefaults fija el umbral de alerta del tanque nuevo que no indica uno
+func (p *LiquidPolicy) ApplyDefaults(tank *Tank) {
+	if tank.AlertThreshold <= 0 {
+		tank.AlertThreshold = p.MinSafetyStock
+	}
+}
+
+// Check comprueba que los umbrales del tanque respeten la política: el umbral de alerta y el
+// punto de pedido no bajan del stock de seguridad, y una entrega estándar en el punto de pedido
+// no supera el llenado máximo
+func (p *LiquidPolicy) Check(tank *Tank) error {
+	if tank.AlertThreshold < p.MinSafetyStock {
+		return fmt.Errorf("%w: alert_threshold must be at least %.2f%% for %s", ErrSafetyPolicyViolation, p.MinSafetyStock, tank.LiquidType)
+	}
+	if !tank.Reorder.IsEnabled() || tank.Capacity <= 0 {
+		return nil
+	}
+
+	if minimum := tank.Capacity * p.MinSafetyStock / 100; tank.Reorder.ReorderLevel < minimum {
+		return fmt.Errorf("%w: reorder_level must be at least %.2f liters (%.2f%%) for %s",
+			ErrSafetyPolicyViolation, minimum, p.MinSafetyStock, tank.LiquidType)
+	}
+	if maximum := tank.Capacity * p.MaxFill / 100; tank.Reorder.ReorderLevel+tank.Reorder.DeliverySize > maximum {
+		return fmt.Errorf("%w: reorder_level plus delivery_size must not exceed %.2f liters (%.2f%%) for %s",
+			ErrSafetyPolicyViolation, maximum, p.MaxFill, tank.LiquidType)
+	}
+	return nil
+}
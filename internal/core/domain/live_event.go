@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Tipos de evento en tiempo real
+const (
+	LiveEventMeasurement = "measurement" // Se guardó una medición de un tanque
+	LiveEventAlert       = "alert"       // Se notificó una alerta
+)
+
+// Prefijos de los temas a los que se suscriben los clientes en tiempo real
+const (
+	LiveTopicTank     = "tank:"     // tank:{id}, los eventos de un tanque
+	LiveTopicSite     = "site:"     // site:{id}, los eventos de los tanques de un sitio
+	LiveTopicSeverity = "severity:" // severity:{severidad}, las alertas de esa severidad de cualquier tanque
+)
+
+// MaxLiveTopics limita los temas a los que puede suscribirse una conexión
+const MaxLiveTopics = 100
+
+// ErrInvalidLiveTopic se devuelve cuando un tema no tiene un prefijo conocido o su valor no es válido
+var ErrInvalidLiveTopic = errors.New("invalid live topic")
+
+// LiveEvent es un evento que se difunde a los clientes conectados en tiempo real
+type LiveEvent struct {
+	Type      string      `json:"type"`
+	TankID    string      `json:"tank_id,omitempty"`
+	SiteID    string      `json:"site_id,omitempty"`
+	Severity  string      `json:"severity,omitempty"` // Solo en las alertas
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"` // La medición o la alerta
+	Tank      *Tank       `json:"-"`    // Estado del tanque, para comprobar el acceso de cada cliente
+}
+
+// NewMeasurementEvent crea el evento de una medición guardada en el tanque
+func NewMeasurementEvent(tank *Tank, measurement *Measurement) *LiveEvent {
+	return &LiveEvent{
+		Type:      LiveEventMeasurement,
+		TankID:    tank.ID,
+		SiteID:    tank.SiteID,
+		Timestamp: measurement.Timestamp,
+		Data:      measurement,
+		Tank:      tank,
+	}
+}
+
+// NewAlertEvent crea el evento de una alerta notificada
+func NewAlertEvent(alert *Alert) *LiveEvent {
+	event := &LiveEvent{
+		Type:      LiveEventAlert,
+		TankID:    alert.TankID,
+		Severity:  alert.Severity,
+		Timestamp: alert.Timestamp,
+		Data:      alert,
+		Tank:      alert.Tank,
+	}
+	if alert.Tank != nil {
+		event.SiteID = alert.Tank.SiteID
+	}
+	return event
+}
+
+// Topics devuelve los temas que reciben el evento
+func (e *LiveEvent) Topics() []string {
+	topics := make([]string, 0, 3)
+	if e.TankID != "" {
+		topics = append(topics, LiveTopicTank+e.TankID)
+	}
+	if e.SiteID != "" {
+		topics = append(topics, LiveTopicSite+e.SiteID)
+	}
+	if e.Severity != "" {
+		topics = append(topics, LiveTopicSeverity+e.Severity)
+	}
+	return topics
+}
+
+// ValidateLiveTopic comprueba que el tema tenga un prefijo conocido y un valor válido
+func ValidateLiveTopic(topic string) error {
+	switch {
+	case strings.HasPrefix(topic, LiveTopicTank):
+		if topic == LiveTopicTank {
+			return ErrInvalidLiveTopic
+		}
+	case strings.HasPrefix(topic, LiveTopicSite):
+		if topic == LiveTopicSite {
+			return ErrInvalidLiveTopic
+		}
+	case strings.HasPrefix(topic, LiveTopicSeverity):
+		switch strings.TrimPrefix(topic, LiveTopicSeverity) {
+		case AlertSeverityInfo, AlertSeverityWarning, AlertSeverityCritical:
+		default:
+			return ErrInvalidLiveTopic
+		}
+	default:
+		return ErrInvalidLiveTopic
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package domain
+
+import "time"
+
+// Tipos de eventos de seguridad del registro de auditoría
+const (
+	SecurityEventLoginFailed    = "login_failed"    // Token, código de autorización o state inválidos
+	SecurityEventLoginSucceeded = "login_succeeded" // Inicio de sesión interactivo completado
+	SecurityEventLockout        = "lockout"         // El cliente superó los intentos fallidos permitidos
+	SecurityEventLoginBlocked   = "login_blocked"   // Intento rechazado durante un bloqueo
+	SecurityEventCaptchaFailed  = "captcha_failed"  // Falta la verificación CAPTCHA o no es válida
+)
+
+// Métodos de inicio de sesión
+const (
+	LoginMethodToken   = "token"   // Token Bearer en cada solicitud
+	LoginMethodOIDC    = "oidc"    // Inicio de sesión interactivo con el proveedor de identidad
+	LoginMethodRefresh = "refresh" // Refresco de una sesión con su token de refresco
+)
+
+// SecurityEvent es una entrada del registro de auditoría de los accesos
+type SecurityEvent struct {
+	ID          string     `json:"id"`
+	Type        string     `json:"type"`
+	Method      string     `json:"method"`
+	ClientIP    string     `json:"client_ip"`
+	Subject     string     `json:"subject,omitempty"` // Solo si se conoce la identidad
+	Path        string     `json:"path,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// LoginAttempt describe un intento de autenticación de un cliente
+type LoginAttempt struct {
+	ClientIP string
+	Method   string
+	Subject  string
+	Path     string
+	Reason   string // Motivo del fallo
+}
+
+// LoginGuardConfig limita los intentos fallidos de cada cliente
+type LoginGuardConfig struct {
+	MaxFailures  int           // Fallos dentro de Window que bloquean al cliente (0 desactiva los bloqueos)
+	Window       time.Duration // Periodo en el que se acumulan los fallos
+	Lockout      time.Duration // Duración del bloqueo
+	CaptchaAfter int           // Fallos a partir de los cuales se exige CAPTCHA (0 no lo exige)
+}
+
+// LoginFailures son los intentos fallidos recientes de un cliente
+type LoginFailures struct {
+	Count       int
+	WindowStart time.Time
+	LockedUntil time.Time
+}
+
+// IsLocked indica si el cliente está bloqueado en el instante now
+func (f *LoginFailures) IsLocked(now time.Time) bool {
+	return now.Before(f.LockedUntil)
+}
+
+// IsStale indica si los fallos ya no cuentan: el bloqueo terminó y la ventana venció
+func (f *LoginFailures) IsStale(now time.Time, config LoginGuardConfig) bool {
+	return !f.IsLocked(now) && !now.Before(f.WindowStart.Add(config.Window))
+}
+
+// RecordFailure suma un fallo y bloquea al cliente si alcanza el máximo. Devuelve true si el
+// fallo inicia un bloqueo; al terminar el bloqueo el cliente dispone de nuevo de todos los intentos.
+func (f *LoginFailures) RecordFailure(now time.Time, config LoginGuardConfig) bool {
+	if f.IsStale(now, config) {
+		f.Count = 0
+		f.WindowStart = now
+	}
+	f.Count++
+
+	if config.MaxFailures > 0 && f.Count >= config.MaxFailures {
+		f.LockedUntil = now.Add(config.Lockout)
+		f.Count = 0
+		f.WindowStart = f.LockedUntil
+		return true
+	}
+	return false
+}
+
+// RequiresCaptcha indica si el cliente debe superar una verificación CAPTCHA antes de intentarlo
+func (f *LoginFailures) RequiresCaptcha(now time.Time, config LoginGuardConfig) bool {
+	return config.CaptchaAfter > 0 && !f.IsStale(now, config) && f.Count >= config.CaptchaAfter
+}
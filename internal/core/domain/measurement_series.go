@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Métodos para completar los pasos de una serie sin mediciones
+const (
+	SeriesFillNone   = "none"   // El paso queda sin valor
+	SeriesFillLinear = "linear" // Interpolación lineal entre las mediciones vecinas
+	SeriesFillLOCF   = "locf"   // Última medición anterior (last observation carried forward)
+)
+
+// MaxSeriesPoints limita los pasos de una serie para que un paso muy corto no agote la memoria
+const MaxSeriesPoints = 10000
+
+// SeriesPoint es un paso de una serie uniforme. Level y Temperature son nil si el paso no tiene
+// valor: sin mediciones y sin relleno, o fuera del intervalo que se puede interpolar.
+type SeriesPoint struct {
+	Timestamp   time.Time `json:"timestamp"` // Inicio del paso
+	Level       *float64  `json:"level"`
+	Temperature *float64  `json:"temperature"`
+	Filled      bool      `json:"filled"`  // El valor es estimado: el paso no tiene mediciones
+	Samples     int       `json:"samples"` // Mediciones promediadas en el paso
+}
+
+// MeasurementSeries es el historial de un tanque remuestreado en pasos iguales, para las
+// bibliotecas de gráficos que esperan una escala de tiempo uniforme
+type MeasurementSeries struct {
+	TankID string         `json:"tank_id"`
+	From   time.Time      `json:"from"`
+	To     time.Time      `json:"to"`
+	Step   string         `json:"step"`
+	Fill   string         `json:"fill"`
+	Points []*SeriesPoint `json:"points"`
+}
+
+// BuildMeasurementSeries divide [from, to] en pasos de step. Cada paso con mediciones toma su
+// promedio; los demás se completan según fill con las mediciones vecinas, aunque queden fuera
+// del periodo, y se marcan como estimados. No se extrapola más allá de la primera o la última
+// medición. Con pasos de días enteros, los pasos empiezan a la medianoche local del tanque
+// anterior a from y siguen los días del calendario, aunque cambie el horario de verano.
+func BuildMeasurementSeries(tank *Tank, measurements []*Measurement, from, to time.Time, step time.Duration, fill string) (*MeasurementSeries, error) {
+	switch fill {
+	case SeriesFillNone, SeriesFillLinear, SeriesFillLOCF:
+	default:
+		return nil, fmt.Errorf("unknown fill method %q", fill)
+	}
+	if step <= 0 {
+		return nil, errors.New("step must be positive")
+	}
+	if !from.Before(to) {
+		return nil, errors.New("from must be before to")
+	}
+	if to.Sub(from)/step >= MaxSeriesPoints {
+		return nil, fmt.Errorf("at most %d points are allowed", MaxSeriesPoints)
+	}
+
+	location := tank.TimeLocation()
+	advance := func(start time.Time) time.Time { return start.Add(step) }
+	if step%(24*time.Hour) == 0 {
+		days := int(step / (24 * time.Hour))
+		from = StartOfLocalDay(from, location)
+		advance = func(start time.Time) time.Time { return start.AddDate(0, 0, days) }
+	}
+
+	sorted := SortMeasurementsAscending(measurements)
+	series := &MeasurementSeries{
+		TankID: tank.ID,
+		From:   from,
+		To:     to,
+		Step:   step.String(),
+		Fill:   fill,
+		Points: make([]*SeriesPoint, 0, int(to.Sub(from)/step)+1),
+	}
+
+	// next es el índice de la primera medición que no es anterior al paso en curso
+	next := 0
+	for start := from.In(location); !start.After(to); start = advance(start) {
+		end := advance(start)
+		for next < len(sorted) && sorted[next].Timestamp.Before(start) {
+			next++
+		}
+
+		point := &SeriesPoint{Timestamp: start}
+		var level, temperature float64
+		for i := next; i < len(sorted) && sorted[i].Timestamp.Before(end); i++ {
+			level += sorted[i].Level
+			temperature += sorted[i].Temperature
+			point.Samples++
+		}
+
+		switch {
+		case point.Samples > 0:
+			level, temperature = level/float64(point.Samples), temperature/float64(point.Samples)
+			point.Level, point.Temperature = &level, &temperature
+		case fill == SeriesFillLOCF && next > 0:
+			previous := sorted[next-1]
+			level, temperature = previous.Level, previous.Temperature
+			point.Level, point.Temperature, point.Filled = &level, &temperature, true
+		case fill == SeriesFillLinear && next > 0 && next < len(sorted):
+			previous, following := sorted[next-1], sorted[next]
+			ratio := float64(start.Sub(previous.Timestamp)) / float64(following.Timestamp.Sub(previous.Timestamp))
+			level = previous.Level + (following.Level-previous.Level)*ratio
+			temperature = previous.Temperature + (following.Temperature-previous.Temperature)*ratio
+			point.Level, point.Temperature, point.Filled = &level, &temperature, true
+		}
+		series.Points = append(series.Points, point)
+	}
+	return series, nil
+}
+
+// StartOfLocalDay devuelve la medianoche, en la zona horaria indicada, del día en que cae t
+func StartOfLocalDay(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
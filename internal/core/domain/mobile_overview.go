@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Tendencia del nivel de un tanque, que la aplicación móvil muestra como una flecha
+const (
+	TrendUp   = "up"
+	TrendDown = "down"
+	TrendFlat = "flat"
+)
+
+// MobileTrendWindow es el periodo con el que se compara el nivel actual para calcular la tendencia
+const MobileTrendWindow = 6 * time.Hour
+
+// mobileTrendThreshold es la variación mínima, en puntos porcentuales de la capacidad, que
+// cuenta como subida o bajada; por debajo, el ruido del sensor se lee como nivel estable
+const mobileTrendThreshold = 1.0
+
+// MobileOverview es la pantalla de inicio de la aplicación móvil: lo mínimo de cada tanque, en
+// una sola petición y con claves cortas para las conexiones móviles
+type MobileOverview struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Critical    int           `json:"critical"`
+	Warning     int           `json:"warning"`
+	Tanks       []*MobileTank `json:"tanks"` // Primero los críticos, luego los de menor porcentaje
+}
+
+// MobileTank es la fila de un tanque en la pantalla de inicio
+type MobileTank struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Percent float64 `json:"pct"` // Redondeado a un decimal
+	Status  string  `json:"status"`
+	Trend   string  `json:"trend"` // up, down o flat
+}
+
+// NewMobileTank resume el tanque; reference es su última lectura de hace MobileTrendWindow o
+// más, o nil si no la hay, en cuyo caso la tendencia es estable
+func NewMobileTank(tank *Tank, reference *Measurement) *MobileTank {
+	percentage := tank.GetLevelPercentage()
+	row := &MobileTank{
+		ID:      tank.ID,
+		Name:    tank.Name,
+		Percent: math.Round(percentage*10) / 10,
+		Status:  tank.Status,
+		Trend:   TrendFlat,
+	}
+
+	if reference != nil && tank.Capacity > 0 {
+		change := percentage - reference.Level/tank.Capacity*100
+		switch {
+		case change >= mobileTrendThreshold:
+			row.Trend = TrendUp
+		case change <= -mobileTrendThreshold:
+			row.Trend = TrendDown
+		}
+	}
+	return row
+}
+
+// NewMobileOverview ordena las filas, los tanques más urgentes primero, y cuenta los que están en
+// aviso o en estado crítico
+func NewMobileOverview(tanks []*MobileTank, now time.Time) *MobileOverview {
+	sort.SliceStable(tanks, func(i, j int) bool {
+		a, b := statusRank(tanks[i].Status), statusRank(tanks[j].Status)
+		if a != b {
+			return a > b
+		}
+		return tanks[i].Percent < tanks[j].Percent
+	})
+
+	overview := &MobileOverview{GeneratedAt: now, Tanks: tanks}
+	for _, tank := range tanks {
+		switch tank.Status {
+		case "critical":
+			overview.Critical++
+		case "warning":
+			overview.Warning++
+		}
+	}
+	if overview.Tanks == nil {
+		overview.Tanks = make([]*MobileTank, 0)
+	}
+	return overview
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Tipos de entrada de la línea de tiempo de un tanque
+const (
+	TimelineEntryMeasurement  = "measurement"
+	TimelineEntryNote         = "note"
+	TimelineEntryStatusChange = "status_change"
+)
+
+// TankNote es una observación registrada por un operador sobre un tanque
+// (p. ej. "se reemplazó el medidor", "se drenó el agua")
+type TankNote struct {
+	ID        string    `json:"id"`
+	TankID    string    `json:"tank_id"`
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TimelineEntry es un evento de la línea de tiempo de un tanque. Solo uno de los campos
+// Measurement, Note o StatusChange está informado, según Type.
+type TimelineEntry struct {
+	Type         string        `json:"type"`
+	Timestamp    time.Time     `json:"timestamp"`
+	Measurement  *Measurement  `json:"measurement,omitempty"`
+	Note         *TankNote     `json:"note,omitempty"`
+	StatusChange *StatusChange `json:"status_change,omitempty"`
+}
+
+// BuildTimeline combina mediciones, notas y transiciones de estado del periodo [from, to] en una
+// única línea de tiempo en orden cronológico
+func BuildTimeline(measurements []*Measurement, notes []*TankNote, changes []*StatusChange, from, to time.Time) []*TimelineEntry {
+	entries := make([]*TimelineEntry, 0, len(measurements)+len(notes)+len(changes))
+	inPeriod := func(t time.Time) bool {
+		return !t.Before(from) && !t.After(to)
+	}
+
+	for _, measurement := range measurements {
+		if inPeriod(measurement.Timestamp) {
+			entries = append(entries, &TimelineEntry{Type: TimelineEntryMeasurement, Timestamp: measurement.Timestamp, Measurement: measurement})
+		}
+	}
+	for _, note := range notes {
+		if inPeriod(note.CreatedAt) {
+			entries = append(entries, &TimelineEntry{Type: TimelineEntryNote, Timestamp: note.CreatedAt, Note: note})
+		}
+	}
+	for _, change := range changes {
+		if inPeriod(change.ChangedAt) {
+			entries = append(entries, &TimelineEntry{Type: TimelineEntryStatusChange, Timestamp: change.ChangedAt, StatusChange: change})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	return entries
+}
+
+// NotesInPeriod devuelve las notas creadas en el periodo [from, to]
+func NotesInPeriod(notes []*TankNote, from, to time.Time) []*TankNote {
+	result := make([]*TankNote, 0)
+	for _, note := range notes {
+		if !note.CreatedAt.Before(from) && !note.CreatedAt.After(to) {
+			result = append(result, note)
+		}
+	}
+	return result
+}
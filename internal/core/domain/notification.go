@@ -0,0 +1,281 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Tipos de canal de notificación soportados
+const (
+	ChannelTypeLog     = "log"
+	ChannelTypeWebhook = "webhook"
+	ChannelTypeSlack   = "slack"
+	ChannelTypePush    = "push"  // Notificaciones push a los técnicos cercanos al tanque
+	ChannelTypeEmail   = "email" // Correo a las direcciones del destino, separadas por comas
+)
+
+// Formas de entrega de las alertas de un canal
+const (
+	DeliveryImmediate = "immediate" // Cada alerta se envía en cuanto se produce
+	DeliveryDigest    = "digest"    // Las alertas se acumulan para el resumen diario
+)
+
+// DefaultDigestTime es la hora del resumen diario de los canales que no fijan otra
+const DefaultDigestTime = "07:00"
+
+// Acciones posibles para las alertas que llegan fuera del horario de un canal
+const (
+	OutOfScheduleQueue = "queue" // Se encolan hasta que el canal vuelva a estar activo
+	OutOfScheduleRoute = "route" // Se envían al canal alternativo
+)
+
+// ErrInvalidSchedule se devuelve cuando una ventana horaria no tiene un formato válido
+var ErrInvalidSchedule = errors.New("invalid notification schedule")
+
+// NotificationChannel representa un destino de alertas con su horario de notificación
+type NotificationChannel struct {
+	ID                string               `json:"id"`
+	Name              string               `json:"name"`
+	Type              string               `json:"type"`   // log, webhook, slack, push, email
+	Target            string               `json:"target"` // URL del webhook o destino del canal
+	Enabled           bool                 `json:"enabled"`
+	Schedule          NotificationSchedule `json:"schedule"`
+	OutOfSchedule     string               `json:"out_of_schedule"`               // queue o route
+	FallbackChannelID string               `json:"fallback_channel_id,omitempty"` // Canal alternativo para route
+	Language          string               `json:"language,omitempty"`            // Idioma de los mensajes (por defecto, es)
+	TankSelector      string               `json:"tank_selector,omitempty"`       // Selector de etiquetas de los tanques cuyas alertas recibe
+	// Secret firma las entregas de los canales webhook (ver pkg/webhooksig); admite una referencia
+	// secret:<nombre>. Vacío, se usa el secreto de firma global, si lo hay. Solo se escribe: las
+	// respuestas no lo incluyen, salvo si es una referencia (ver MarshalJSON).
+	Secret string `json:"secret,omitempty"`
+
+	// Con Delivery digest, el canal no recibe cada alerta sino un resumen diario de sus tanques a
+	// la hora DigestTime de la zona horaria del horario, con las alertas acumuladas desde el anterior
+	Delivery     string     `json:"delivery,omitempty"`    // immediate (por defecto) o digest
+	DigestTime   string     `json:"digest_time,omitempty"` // HH:MM; por defecto 07:00
+	LastDigestAt *time.Time `json:"last_digest_at,omitempty"`
+}
+
+// MarshalJSON codifica el canal sin su secreto de firma, que permitiría falsificar las entregas:
+// has_secret indica si lo tiene, y solo una referencia secret:<nombre> se muestra tal cual
+func (c NotificationChannel) MarshalJSON() ([]byte, error) {
+	type channelJSON NotificationChannel
+	encoded := struct {
+		channelJSON
+		HasSecret bool `json:"has_secret"`
+	}{channelJSON: channelJSON(c), HasSecret: c.Secret != ""}
+	if !strings.HasPrefix(c.Secret, "secret:") {
+		encoded.Secret = ""
+	}
+	return json.Marshal(encoded)
+}
+
+// IsDigest indica si el canal recibe un resumen diario en lugar de cada alerta
+func (c *NotificationChannel) IsDigest() bool {
+	return c.Delivery == DeliveryDigest
+}
+
+// DigestDue indica si corresponde enviar el resumen diario: ya pasó la hora del resumen de hoy y
+// no se ha enviado desde entonces
+func (c *NotificationChannel) DigestDue(now time.Time) bool {
+	if !c.IsDigest() {
+		return false
+	}
+	minutes, err := parseClock(c.DigestTime)
+	if err != nil {
+		return false
+	}
+	location, err := c.Schedule.location()
+	if err != nil {
+		location = time.UTC
+	}
+
+	local := now.In(location)
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), 0, minutes, 0, 0, location)
+	if local.Before(scheduled) {
+		scheduled = scheduled.AddDate(0, 0, -1)
+	}
+	return c.LastDigestAt == nil || c.LastDigestAt.Before(scheduled)
+}
+
+// LocalDate devuelve la fecha del instante en la zona horaria del horario del canal
+func (c *NotificationChannel) LocalDate(t time.Time) string {
+	location, err := c.Schedule.location()
+	if err != nil {
+		location = time.UTC
+	}
+	return t.In(location).Format("2006-01-02")
+}
+
+// MatchesAlert indica si el canal recibe la alerta según el selector de etiquetas de los tanques.
+// Sin selector recibe todas; con selector, solo las de los tanques que lo cumplen.
+func (c *NotificationChannel) MatchesAlert(alert *Alert) bool {
+	return c.MatchesTank(alert.Tank)
+}
+
+// MatchesTank indica si el tanque cumple el selector de etiquetas del canal
+func (c *NotificationChannel) MatchesTank(tank *Tank) bool {
+	if c.TankSelector == "" {
+		return true
+	}
+
+	selector, err := ParseLabelSelector(c.TankSelector)
+	if err != nil || tank == nil {
+		return false
+	}
+	return selector.Matches(tank.Labels)
+}
+
+// ValidateDigestTime comprueba la hora del resumen diario: HH:MM entre 00:00 y 23:59
+func ValidateDigestTime(value string) error {
+	if value == "24:00" {
+		return ErrInvalidSchedule
+	}
+	if _, err := parseClock(value); err != nil {
+		return ErrInvalidSchedule
+	}
+	return nil
+}
+
+// NotificationSchedule define cuándo un canal puede recibir alertas. Sin ventanas, el canal está activo 24/7.
+type NotificationSchedule struct {
+	Timezone string           `json:"timezone"` // Zona horaria IANA; por defecto, la del tanque de la alerta o UTC
+	Windows  []ScheduleWindow `json:"windows"`
+}
+
+// ScheduleWindow es una franja horaria activa, p. ej. de lunes a viernes de 06:00 a 22:00.
+// Si Start es posterior a End, la franja cruza la medianoche.
+type ScheduleWindow struct {
+	Days  []string `json:"days"`  // mon, tue, wed, thu, fri, sat, sun; vacío significa todos los días
+	Start string   `json:"start"` // HH:MM
+	End   string   `json:"end"`   // HH:MM
+}
+
+// QueuedAlert representa una alerta retenida hasta que su canal vuelva a estar en horario
+type QueuedAlert struct {
+	ID        string    `json:"id"`
+	ChannelID string    `json:"channel_id"`
+	TankID    string    `json:"tank_id"`
+	Message   string    `json:"message"`
+	QueuedAt  time.Time `json:"queued_at"`
+	Alert     *Alert    `json:"alert,omitempty"` // Alerta completa que se entregará al canal
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Validate comprueba que la zona horaria y las ventanas del horario sean válidas
+func (s NotificationSchedule) Validate() error {
+	if _, err := s.location(); err != nil {
+		return ErrInvalidSchedule
+	}
+
+	for _, window := range s.Windows {
+		if _, err := parseClock(window.Start); err != nil {
+			return ErrInvalidSchedule
+		}
+		if _, err := parseClock(window.End); err != nil {
+			return ErrInvalidSchedule
+		}
+		for _, day := range window.Days {
+			if _, ok := weekdayNames[strings.ToLower(day)]; !ok {
+				return ErrInvalidSchedule
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsActive indica si el horario permite notificar en el instante indicado
+func (s NotificationSchedule) IsActive(t time.Time) bool {
+	if len(s.Windows) == 0 {
+		return true
+	}
+
+	location, err := s.location()
+	if err != nil {
+		return true
+	}
+
+	local := t.In(location)
+	minute := local.Hour()*60 + local.Minute()
+
+	for _, window := range s.Windows {
+		start, errStart := parseClock(window.Start)
+		end, errEnd := parseClock(window.End)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+
+		if start <= end {
+			if minute >= start && minute < end && window.includes(local.Weekday()) {
+				return true
+			}
+			continue
+		}
+
+		// La franja cruza la medianoche: la parte posterior pertenece al día en que empezó
+		if minute >= start && window.includes(local.Weekday()) {
+			return true
+		}
+		if minute < end && window.includes(local.Add(-24*time.Hour).Weekday()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsActiveFor es IsActive para una alerta del tanque indicado: si el horario no fija zona
+// horaria se usa la del tanque, de modo que las horas de silencio siguen la hora local del sitio
+func (s NotificationSchedule) IsActiveFor(t time.Time, tank *Tank) bool {
+	if s.Timezone == "" && tank != nil && IsValidTimezone(tank.Timezone) {
+		s.Timezone = tank.Timezone
+	}
+	return s.IsActive(t)
+}
+
+// location devuelve la zona horaria del horario
+func (s NotificationSchedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+// includes indica si la ventana aplica al día de la semana indicado
+func (w ScheduleWindow) includes(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+
+	for _, name := range w.Days {
+		if weekdayNames[strings.ToLower(name)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock convierte una hora HH:MM en minutos desde la medianoche. Admite 24:00 como fin del día.
+func parseClock(value string) (int, error) {
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
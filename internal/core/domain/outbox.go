@@ -0,0 +1,81 @@
+package domain
+
+import "time"
+
+// Estados de un evento de la bandeja de salida
+const (
+	OutboxEventPending   = "pending"
+	OutboxEventDelivered = "delivered"
+	OutboxEventFailed    = "failed" // Agotó los intentos de entrega
+)
+
+// OutboxRetention es cuánto se conservan los eventos entregados antes de eliminarlos
+const OutboxRetention = 24 * time.Hour
+
+// maxOutboxRetryDelay limita la espera entre dos intentos de entrega
+const maxOutboxRetryDelay = time.Hour
+
+// OutboxConfig contiene los parámetros de entrega de la bandeja de salida
+type OutboxConfig struct {
+	BatchSize    int           // Eventos entregados en cada pasada
+	MaxAttempts  int           // Intentos antes de dar el evento por fallido
+	RetryBackoff time.Duration // Espera tras el primer fallo; se duplica en cada intento
+}
+
+// OutboxEvent es un evento de dominio guardado en la misma unidad de trabajo que el cambio de
+// estado que lo origina y entregado después por un proceso aparte, que lo reintenta hasta que se
+// confirma. Un corte entre el cambio y el envío no pierde la alerta, a cambio de que pueda
+// entregarse más de una vez.
+type OutboxEvent struct {
+	ID            string     `json:"id"`
+	TankID        string     `json:"tank_id"`
+	Alert         *Alert     `json:"alert"`
+	Status        string     `json:"status"` // pending, delivered o failed
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+// NewOutboxEvent crea un evento pendiente para entregar la alerta en cuanto sea posible
+func NewOutboxEvent(id string, alert *Alert, now time.Time) *OutboxEvent {
+	return &OutboxEvent{
+		ID:            id,
+		TankID:        alert.TankID,
+		Alert:         alert,
+		Status:        OutboxEventPending,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}
+}
+
+// Due indica si el evento está pendiente y su próximo intento ya venció
+func (e *OutboxEvent) Due(now time.Time) bool {
+	return e.Status == OutboxEventPending && !e.NextAttemptAt.After(now)
+}
+
+// MarkDelivered registra la entrega confirmada del evento
+func (e *OutboxEvent) MarkDelivered(now time.Time) {
+	e.Attempts++
+	e.Status = OutboxEventDelivered
+	e.LastError = ""
+	e.DeliveredAt = &now
+}
+
+// MarkFailed registra un intento fallido y programa el siguiente con una espera creciente, o da
+// el evento por fallido si agotó los intentos
+func (e *OutboxEvent) MarkFailed(err error, now time.Time, config OutboxConfig) {
+	e.Attempts++
+	e.LastError = err.Error()
+	if e.Attempts >= config.MaxAttempts {
+		e.Status = OutboxEventFailed
+		return
+	}
+
+	delay := config.RetryBackoff
+	for i := 1; i < e.Attempts && delay < maxOutboxRetryDelay; i++ {
+		delay *= 2
+	}
+	e.NextAttemptAt = now.Add(min(delay, maxOutboxRetryDelay))
+}
@@ -0,0 +1,196 @@
+package domain
+
+// Tipos de recurso gestionados por el aprovisionamiento declarativo
+const (
+	ProvisioningKindTank      = "tank"
+	ProvisioningKindSensor    = "sensor"     // Equipo de campo con su configuración deseada
+	ProvisioningKindAlertRule = "alert_rule" // Canal de notificación
+)
+
+// Acciones de la reconciliación de un recurso
+const (
+	ProvisioningActionCreate    = "create"
+	ProvisioningActionUpdate    = "update"
+	ProvisioningActionUnchanged = "unchanged"
+)
+
+// DefaultAlertThreshold es el umbral de alerta (porcentaje) de los tanques que no indican otro
+const DefaultAlertThreshold = 10.0
+
+// ProvisioningSpec es el estado deseado de una instalación, declarado en un archivo versionado.
+// La reconciliación crea y actualiza los recursos declarados; nunca elimina los que no aparecen.
+type ProvisioningSpec struct {
+	Tanks      []*Tank                `json:"tanks"`
+	Sensors    []*FieldDevice         `json:"sensors"`     // Se reconcilia ID, TankID y Desired (sin la versión)
+	AlertRules []*NotificationChannel `json:"alert_rules"` // Canales de notificación
+}
+
+// ProvisioningChange describe lo que la reconciliación hizo con un recurso declarado
+type ProvisioningChange struct {
+	Kind   string   `json:"kind"`
+	ID     string   `json:"id"`
+	Action string   `json:"action"`
+	Fields []string `json:"fields,omitempty"` // Campos modificados en una actualización
+}
+
+// ProvisioningResult resume una reconciliación. En un plan (DryRun) describe los cambios que
+// se aplicarían.
+type ProvisioningResult struct {
+	DryRun    bool                  `json:"dry_run"`
+	Created   int                   `json:"created"`
+	Updated   int                   `json:"updated"`
+	Unchanged int                   `json:"unchanged"`
+	Changes   []*ProvisioningChange `json:"changes"`
+}
+
+// Add registra el cambio de un recurso y actualiza los totales
+func (r *ProvisioningResult) Add(change *ProvisioningChange) {
+	switch change.Action {
+	case ProvisioningActionCreate:
+		r.Created++
+	case ProvisioningActionUpdate:
+		r.Updated++
+	default:
+		r.Unchanged++
+	}
+	r.Changes = append(r.Changes, change)
+}
+
+// TankSpecDiff devuelve los campos gestionados por el aprovisionamiento en los que current
+// difiere de desired. El nivel, la temperatura y el estado provienen de las mediciones y no se comparan.
+func TankSpecDiff(current, desired *Tank) []string {
+	var fields []string
+	if current.Name != desired.Name {
+		fields = append(fields, "name")
+	}
+	if current.SiteID != desired.SiteID {
+		fields = append(fields, "site_id")
+	}
+	if current.GroupID != desired.GroupID {
+		fields = append(fields, "group_id")
+	}
+	if current.ParentID != desired.ParentID {
+		fields = append(fields, "parent_id")
+	}
+	if !current.Labels.Equal(desired.Labels) {
+		fields = append(fields, "labels")
+	}
+	if !sameLocation(current.Location, desired.Location) {
+		fields = append(fields, "location")
+	}
+	if current.Capacity != desired.Capacity {
+		fields = append(fields, "capacity")
+	}
+	if current.LiquidType != desired.LiquidType {
+		fields = append(fields, "liquid_type")
+	}
+	if current.AlertThreshold != desired.AlertThreshold {
+		fields = append(fields, "alert_threshold")
+	}
+	if current.Reorder != desired.Reorder {
+		fields = append(fields, "reorder")
+	}
+	if current.Timezone != desired.Timezone {
+		fields = append(fields, "timezone")
+	}
+	return fields
+}
+
+// ApplyTankSpec copia en tank los campos gestionados por el aprovisionamiento
+func ApplyTankSpec(tank, desired *Tank) {
+	tank.Name = desired.Name
+	tank.SiteID = desired.SiteID
+	tank.GroupID = desired.GroupID
+	tank.ParentID = desired.ParentID
+	tank.Labels = desired.Labels.Clone()
+	tank.Location = desired.Location
+	tank.Capacity = desired.Capacity
+	tank.LiquidType = desired.LiquidType
+	tank.AlertThreshold = desired.AlertThreshold
+	tank.Reorder = desired.Reorder
+	tank.Timezone = desired.Timezone
+}
+
+// DeviceConfigDiff devuelve los campos en los que difieren dos configuraciones, sin contar la versión
+func DeviceConfigDiff(current, desired DeviceConfig) []string {
+	var fields []string
+	if current.ReportingInterval != desired.ReportingInterval {
+		fields = append(fields, "reporting_interval")
+	}
+	if current.LowLevelThreshold != desired.LowLevelThreshold {
+		fields = append(fields, "low_level_threshold")
+	}
+	if current.HighLevelThreshold != desired.HighLevelThreshold {
+		fields = append(fields, "high_level_threshold")
+	}
+	if current.ReportOnChange != desired.ReportOnChange {
+		fields = append(fields, "report_on_change")
+	}
+	return fields
+}
+
+// ChannelSpecDiff devuelve los campos en los que difieren dos canales de notificación
+func ChannelSpecDiff(current, desired *NotificationChannel) []string {
+	var fields []string
+	if current.Name != desired.Name {
+		fields = append(fields, "name")
+	}
+	if current.Type != desired.Type {
+		fields = append(fields, "type")
+	}
+	if current.Target != desired.Target {
+		fields = append(fields, "target")
+	}
+	if current.Enabled != desired.Enabled {
+		fields = append(fields, "enabled")
+	}
+	if !sameSchedule(current.Schedule, desired.Schedule) {
+		fields = append(fields, "schedule")
+	}
+	if current.OutOfSchedule != desired.OutOfSchedule {
+		fields = append(fields, "out_of_schedule")
+	}
+	if current.FallbackChannelID != desired.FallbackChannelID {
+		fields = append(fields, "fallback_channel_id")
+	}
+	if current.Language != desired.Language {
+		fields = append(fields, "language")
+	}
+	if current.TankSelector != desired.TankSelector {
+		fields = append(fields, "tank_selector")
+	}
+	if current.IsDigest() != desired.IsDigest() {
+		fields = append(fields, "delivery")
+	}
+	if desired.IsDigest() && current.DigestTime != desired.DigestTime {
+		fields = append(fields, "digest_time")
+	}
+	return fields
+}
+
+// sameLocation compara dos ubicaciones opcionales
+func sameLocation(a, b *GeoLocation) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// sameSchedule compara dos horarios de notificación
+func sameSchedule(a, b NotificationSchedule) bool {
+	if a.Timezone != b.Timezone || len(a.Windows) != len(b.Windows) {
+		return false
+	}
+	for i := range a.Windows {
+		wa, wb := a.Windows[i], b.Windows[i]
+		if wa.Start != wb.Start || wa.End != wb.End || len(wa.Days) != len(wb.Days) {
+			return false
+		}
+		for j := range wa.Days {
+			if wa.Days[j] != wb.Days[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// Alcances de una purga de datos
+const (
+	PurgeScopeMeasurements = "measurements" // Mediciones de un tanque anteriores a una fecha
+	PurgeScopeOrganization = "organization" // Tanques de una organización con todos sus datos
+)
+
+// Estados de un trabajo de purga
+const (
+	PurgeJobPending   = "pending"
+	PurgeJobRunning   = "running"
+	PurgeJobCompleted = "completed"
+	PurgeJobFailed    = "failed"
+)
+
+// PurgeRequest describe los datos que se deben eliminar
+type PurgeRequest struct {
+	Scope        string     `json:"scope"`                  // measurements u organization
+	TankID       string     `json:"tank_id,omitempty"`      // Tanque de una purga de mediciones
+	Before       *time.Time `json:"before,omitempty"`       // Se eliminan las mediciones anteriores a esta fecha
+	Organization string     `json:"organization,omitempty"` // Organización de una purga completa
+}
+
+// PurgeJob es el seguimiento de una purga en segundo plano. Deleted cuenta los registros
+// eliminados por tipo de dato (measurements, status_changes, notes, ...).
+type PurgeJob struct {
+	ID          string         `json:"id"`
+	Request     PurgeRequest   `json:"request"`
+	Status      string         `json:"status"`
+	RequestedBy string         `json:"requested_by,omitempty"`
+	TanksTotal  int            `json:"tanks_total"`
+	TanksDone   int            `json:"tanks_done"`
+	Progress    float64        `json:"progress"` // Porcentaje de tanques procesados
+	Deleted     map[string]int `json:"deleted"`
+	Error       string         `json:"error,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	StartedAt   *time.Time     `json:"started_at,omitempty"`
+	FinishedAt  *time.Time     `json:"finished_at,omitempty"`
+}
+
+// Clone devuelve una copia del trabajo que puede leerse mientras la purga avanza
+func (j *PurgeJob) Clone() *PurgeJob {
+	clone := *j
+	clone.Deleted = make(map[string]int, len(j.Deleted))
+	for kind, count := range j.Deleted {
+		clone.Deleted[kind] = count
+	}
+	if j.Request.Before != nil {
+		before := *j.Request.Before
+		clone.Request.Before = &before
+	}
+	return &clone
+}
+
+// MarkTankDone anota un tanque procesado y recalcula el progreso
+func (j *PurgeJob) MarkTankDone() {
+	j.TanksDone++
+	if j.TanksTotal > 0 {
+		j.Progress = float64(j.TanksDone) / float64(j.TanksTotal) * 100
+	}
+}
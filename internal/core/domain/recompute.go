@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// RecomputeResult resume la reconstrucción de los datos derivados de un tanque a partir de sus
+// mediciones, p. ej. tras importar un historial
+type RecomputeResult struct {
+	TankID        string    `json:"tank_id"`
+	From          time.Time `json:"from"`           // Inicio del periodo reconstruido
+	Measurements  int       `json:"measurements"`   // Mediciones recorridas desde From
+	StatusChanges int       `json:"status_changes"` // Transiciones reconstruidas desde From
+	Level         float64   `json:"level"`          // Nivel actual según la medición más reciente
+	Status        string    `json:"status"`
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"time"
+)
+
+// ReorderConfig contiene la configuración de reabastecimiento de un tanque
+type ReorderConfig struct {
+	ReorderLevel float64 `json:"reorder_level"`  // Nivel en litros a partir del cual se debe pedir
+	LeadTimeDays float64 `json:"lead_time_days"` // Días que tarda el proveedor en entregar
+	DeliverySize float64 `json:"delivery_size"`  // Volumen estándar de un pedido en litros
+}
+
+// IsEnabled indica si el tanque tiene configurado un punto de pedido
+func (c ReorderConfig) IsEnabled() bool {
+	return c.ReorderLevel > 0
+}
+
+// ReorderSuggestion representa una sugerencia de pedido de reabastecimiento para un tanque
+type ReorderSuggestion struct {
+	TankID            string    `json:"tank_id"`
+	TankName          string    `json:"tank_name"`
+	CurrentLevel      float64   `json:"current_level"`
+	ReorderLevel      float64   `json:"reorder_level"`
+	DailyConsumption  float64   `json:"daily_consumption"`  // Consumo estimado en litros por día
+	DaysUntilReorder  float64   `json:"days_until_reorder"` // Días hasta alcanzar el punto de pedido
+	OrderBy           time.Time `json:"order_by"`           // Fecha límite para realizar el pedido
+	SuggestedQuantity float64   `json:"suggested_quantity"` // Litros a pedir
+	Urgent            bool      `json:"urgent"`             // El pedido debería haberse hecho ya
+}
+
+// EstimateDailyConsumption estima el consumo diario (litros/día) a partir de un historial
+// de mediciones. Solo se tienen en cuenta los descensos de nivel, de modo que las recargas
+// no reducen el consumo estimado. El orden de las mediciones no importa.
+func EstimateDailyConsumption(measurements []*Measurement) float64 {
+	if len(measurements) < 2 {
+		return 0
+	}
+
+	oldest, newest := measurements[0], measurements[0]
+	for _, m := range measurements {
+		if m.Timestamp.Before(oldest.Timestamp) {
+			oldest = m
+		}
+		if m.Timestamp.After(newest.Timestamp) {
+			newest = m
+		}
+	}
+
+	days := newest.Timestamp.Sub(oldest.Timestamp).Hours() / 24
+	if days <= 0 {
+		return 0
+	}
+
+	chronological := SortMeasurementsAscending(measurements)
+
+	consumed := 0.0
+	for i := 1; i < len(chronological); i++ {
+		if drop := chronological[i-1].Level - chronological[i].Level; drop > 0 {
+			consumed += drop
+		}
+	}
+
+	return consumed / days
+}
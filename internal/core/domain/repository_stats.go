@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// RepositoryStats es el diagnóstico de la persistencia para los administradores: qué backend se
+// usa, cuánto guarda y a qué ritmo llegan las mediciones
+type RepositoryStats struct {
+	GeneratedAt time.Time               `json:"generated_at"`
+	Backend     RepositoryBackendInfo   `json:"backend"`
+	Entities    map[string]int          `json:"entities"` // Elementos guardados en cada colección
+	Ingestion   IngestionRate           `json:"ingestion"`
+	Tanks       []*TankMeasurementRange `json:"tanks"`
+}
+
+// RepositoryBackendInfo describe el backend de persistencia configurado
+type RepositoryBackendInfo struct {
+	Name             string  `json:"name"`
+	Persistent       bool    `json:"persistent"` // Los datos sobreviven a un reinicio
+	SnapshotPath     string  `json:"snapshot_path,omitempty"`
+	SnapshotInterval float64 `json:"snapshot_interval,omitempty"` // Segundos entre instantáneas
+}
+
+// IngestionRate cuenta las mediciones recientes según la marca de tiempo de las lecturas; las
+// importaciones de historiales recientes también cuentan
+type IngestionRate struct {
+	LastMinute int     `json:"last_minute"`
+	LastHour   int     `json:"last_hour"`
+	LastDay    int     `json:"last_day"`
+	PerMinute  float64 `json:"per_minute"` // Media de la última hora
+}
+
+// TankMeasurementRange resume las mediciones guardadas de un tanque
+type TankMeasurementRange struct {
+	TankID       string     `json:"tank_id"`
+	TankName     string     `json:"tank_name"`
+	Measurements int        `json:"measurements"`
+	Oldest       *time.Time `json:"oldest,omitempty"` // nil si el tanque no tiene mediciones
+	Newest       *time.Time `json:"newest,omitempty"`
+}
+
+// Add incorpora una medición del tanque al rango
+func (r *TankMeasurementRange) Add(timestamp time.Time) {
+	r.Measurements++
+	if r.Oldest == nil || timestamp.Before(*r.Oldest) {
+		oldest := timestamp
+		r.Oldest = &oldest
+	}
+	if r.Newest == nil || timestamp.After(*r.Newest) {
+		newest := timestamp
+		r.Newest = &newest
+	}
+}
+
+// Add cuenta la medición si es reciente respecto de now
+func (r *IngestionRate) Add(timestamp, now time.Time) {
+	age := now.Sub(timestamp)
+	if age < 0 || age >= 24*time.Hour {
+		return
+	}
+	r.LastDay++
+	if age < time.Hour {
+		r.LastHour++
+		r.PerMinute = float64(r.LastHour) / 60
+	}
+	if age < time.Minute {
+		r.LastMinute++
+	}
+}
+
+// SortTankMeasurementRanges ordena los tanques por nombre
+func SortTankMeasurementRanges(ranges []*TankMeasurementRange) {
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].TankName != ranges[j].TankName {
+			return ranges[i].TankName < ranges[j].TankName
+		}
+		return ranges[i].TankID < ranges[j].TankID
+	})
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Niveles de logging admitidos en la configuración recargable
+var runtimeLogLevels = map[string]bool{"debug": true, "info": true, "warn": true, "warning": true, "error": true}
+
+// RuntimeSettings es la parte de la configuración que puede recargarse sin reiniciar el
+// servidor: se aplica sin cerrar las conexiones WebSocket, SSE ni MQTT abiertas
+type RuntimeSettings struct {
+	LogLevel              string     `json:"log_level"`
+	AlertNotifier         string     `json:"alert_notifier"`          // Notificador predeterminado: log, webhook o slack
+	AlertNotifierTarget   string     `json:"-"`                       // URL del webhook, que puede incluir credenciales
+	DefaultAlertThreshold float64    `json:"default_alert_threshold"` // Umbral de los tanques nuevos que no indican uno
+	Source                string     `json:"source"`                  // env o la ruta del archivo de configuración
+	ReloadedAt            *time.Time `json:"reloaded_at,omitempty"`   // Última recarga; nil si no se ha recargado
+}
+
+// Validate comprueba que el nivel de logging, el notificador y el umbral sean válidos
+func (s *RuntimeSettings) Validate() error {
+	if !runtimeLogLevels[strings.ToLower(strings.TrimSpace(s.LogLevel))] {
+		return fmt.Errorf("unknown log level %q", s.LogLevel)
+	}
+	switch s.AlertNotifier {
+	case ChannelTypeLog, "":
+	case ChannelTypeWebhook, ChannelTypeSlack:
+		if s.AlertNotifierTarget == "" {
+			return fmt.Errorf("alert notifier %q requires a target", s.AlertNotifier)
+		}
+	default:
+		return fmt.Errorf("unknown alert notifier %q", s.AlertNotifier)
+	}
+	if s.DefaultAlertThreshold <= 0 || s.DefaultAlertThreshold > 100 {
+		return errors.New("default_alert_threshold must be greater than 0 and at most 100")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// Límites de las vistas guardadas
+const (
+	MaxSavedViewsPerSubject = 100
+	MaxSavedViewNameLength  = 100
+)
+
+// SavedView es una consulta con nombre que un usuario guarda para repetirla desde el panel web o
+// la línea de comandos, p. ej. "Diésel crítico – Norte". Path es la ruta del listado o informe
+// (p. ej. /api/tanks o /api/tanks/{id}/measurements) y Query sus parámetros de filtro y agregación
+// (labels, filter, step, fill, from, to, ...), que los clientes añaden tal cual a la petición.
+type SavedView struct {
+	ID          string            `json:"id"`
+	Subject     string            `json:"subject,omitempty"` // Usuario al que pertenece la vista
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Path        string            `json:"path"`
+	Query       map[string]string `json:"query,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// Clone devuelve una copia de la vista que no comparte los parámetros
+func (v *SavedView) Clone() *SavedView {
+	clone := *v
+	if v.Query != nil {
+		clone.Query = make(map[string]string, len(v.Query))
+		for key, value := range v.Query {
+			clone.Query[key] = value
+		}
+	}
+	return &clone
+}
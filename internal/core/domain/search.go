@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Tipos de resultado de la búsqueda
+const (
+	SearchResultTank = "tank"
+	SearchResultSite = "site"
+	SearchResultNote = "note"
+)
+
+// Calidad de la coincidencia de un término con una palabra del texto
+const (
+	searchMatchWord      = 1.0  // Palabra completa
+	searchMatchPrefix    = 0.75 // Comienzo de una palabra
+	searchMatchSubstring = 0.5  // En medio de una palabra
+)
+
+// SearchResult es un resultado de la búsqueda. Según Type se informa Tank (tanque), Site
+// (número de tanques del sitio en TankCount) o Note (con su tanque en TankID).
+type SearchResult struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Score     float64   `json:"score"`
+	TankID    string    `json:"tank_id,omitempty"`
+	TankCount int       `json:"tank_count,omitempty"`
+	Tank      *Tank     `json:"tank,omitempty"`
+	Note      *TankNote `json:"note,omitempty"`
+}
+
+// SearchField es un texto en el que se busca con el peso de sus coincidencias
+type SearchField struct {
+	Text   string
+	Weight float64
+}
+
+// searchFolding quita las tildes y la diéresis para que "diesel" encuentre "Diésel"
+var searchFolding = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	"à", "a", "è", "e", "ì", "i", "ò", "o", "ù", "u", "ç", "c",
+)
+
+// NormalizeSearchText pasa el texto a minúsculas sin tildes
+func NormalizeSearchText(text string) string {
+	return searchFolding.Replace(strings.ToLower(text))
+}
+
+// SearchTerms divide la consulta en términos normalizados
+func SearchTerms(query string) []string {
+	return strings.Fields(NormalizeSearchText(query))
+}
+
+// ScoreSearch puntúa un documento formado por varios campos. Todos los términos deben aparecer en
+// algún campo; cada término suma el peso del campo donde mejor coincide por la calidad de la
+// coincidencia (palabra completa, prefijo o subcadena). Devuelve 0 si falta algún término.
+func ScoreSearch(terms []string, fields ...SearchField) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+
+	normalized := make([][]string, len(fields))
+	for i, field := range fields {
+		normalized[i] = searchWords(NormalizeSearchText(field.Text))
+	}
+
+	score := 0.0
+	for _, term := range terms {
+		best := 0.0
+		for i, field := range fields {
+			if quality := matchQuality(term, normalized[i]); quality*field.Weight > best {
+				best = quality * field.Weight
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		score += best
+	}
+
+	return score
+}
+
+// searchWords separa el texto en palabras; guiones, puntos y guiones bajos también separan, para
+// que "norte" coincida con el sitio "estacion-norte"
+func searchWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		switch r {
+		case ' ', '\t', '\n', '-', '_', '.', ',', ';', ':', '/', '(', ')':
+			return true
+		}
+		return false
+	})
+}
+
+// matchQuality devuelve la mejor calidad de coincidencia del término con las palabras
+func matchQuality(term string, words []string) float64 {
+	best := 0.0
+	for _, word := range words {
+		switch {
+		case word == term:
+			return searchMatchWord
+		case strings.HasPrefix(word, term):
+			best = math.Max(best, searchMatchPrefix)
+		case strings.Contains(word, term):
+			best = math.Max(best, searchMatchSubstring)
+		}
+	}
+	return best
+}
+
+// RankSearchResults ordena los resultados de mayor a menor puntuación (a igual puntuación, por
+// título) y conserva como máximo limit
+func RankSearchResults(results []*SearchResult, limit int) []*SearchResult {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Title < results[j].Title
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
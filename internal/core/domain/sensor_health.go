@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Categorías de salud de un sensor según su puntuación
+const (
+	SensorHealthGood    = "good"    // Puntuación de 80 o más
+	SensorHealthFair    = "fair"    // Puntuación entre 50 y 80
+	SensorHealthPoor    = "poor"    // Puntuación inferior a 50: conviene planificar su mantenimiento
+	SensorHealthUnknown = "unknown" // Sin lecturas suficientes para evaluarlo
+)
+
+// minSensorHealthSamples es el número mínimo de lecturas para evaluar un sensor
+const minSensorHealthSamples = 3
+
+// SensorHealthConfig contiene los parámetros de la evaluación de salud de los sensores
+type SensorHealthConfig struct {
+	Samples             int     // Lecturas más recientes analizadas por tanque
+	BatteryFullVoltage  float64 // Voltaje de la batería nueva
+	BatteryEmptyVoltage float64 // Voltaje a partir del cual el sensor deja de funcionar
+}
+
+// BatteryPercentage estima la carga restante a partir del voltaje reportado
+func (c SensorHealthConfig) BatteryPercentage(voltage float64) float64 {
+	if c.BatteryFullVoltage <= c.BatteryEmptyVoltage {
+		return 0
+	}
+	percentage := (voltage - c.BatteryEmptyVoltage) / (c.BatteryFullVoltage - c.BatteryEmptyVoltage) * 100
+	return math.Max(0, math.Min(100, percentage))
+}
+
+// SensorHealth resume el comportamiento reciente de un sensor y su puntuación de salud
+type SensorHealth struct {
+	SensorID          string    `json:"sensor_id"`
+	TankID            string    `json:"tank_id"`
+	Samples           int       `json:"samples"`            // Lecturas analizadas
+	LastSeen          time.Time `json:"last_seen"`          // Lectura más reciente
+	ReportingInterval float64   `json:"reporting_interval"` // Mediana de segundos entre lecturas
+	IntervalJitter    float64   `json:"interval_jitter"`    // Coeficiente de variación de los intervalos
+	SilentFor         float64   `json:"silent_for"`         // Segundos desde la última lectura
+	FlatlineRatio     float64   `json:"flatline_ratio"`     // Fracción de lecturas idénticas a la anterior
+	NoisePercentage   float64   `json:"noise_percentage"`   // Ruido del nivel en % de la capacidad
+	BatteryVoltage    *float64  `json:"battery_voltage,omitempty"`
+	BatteryPercentage *float64  `json:"battery_percentage,omitempty"`
+	SignalStrength    *float64  `json:"rssi,omitempty"` // Última intensidad de señal reportada en dBm
+	Score             float64   `json:"score"`          // 0 (sin servicio) a 100 (sano)
+	Health            string    `json:"health"`         // good, fair, poor o unknown
+}
+
+// HealthForScore devuelve la categoría de salud correspondiente a una puntuación
+func HealthForScore(score float64) string {
+	switch {
+	case score >= 80:
+		return SensorHealthGood
+	case score >= 50:
+		return SensorHealthFair
+	default:
+		return SensorHealthPoor
+	}
+}
+
+// IsValidSensorHealth indica si la categoría de salud existe
+func IsValidSensorHealth(health string) bool {
+	switch health {
+	case SensorHealthGood, SensorHealthFair, SensorHealthPoor, SensorHealthUnknown:
+		return true
+	default:
+		return false
+	}
+}
+
+// SensorIDForMeasurement identifica el sensor que tomó la medición. Las mediciones sin
+// sensor_id se atribuyen al sensor principal del tanque, identificado con el ID del tanque.
+func SensorIDForMeasurement(measurement *Measurement) string {
+	if measurement.SensorID != "" {
+		return measurement.SensorID
+	}
+	return measurement.TankID
+}
+
+// BuildSensorHealth evalúa un sensor a partir de sus lecturas recientes. La puntuación parte de
+// 100 y descuenta:
+//   - hasta 30 puntos por irregularidad en el intervalo de reporte;
+//   - hasta 40 puntos si el sensor lleva más de dos intervalos habituales sin reportar;
+//   - hasta 60 puntos por lecturas repetidas (sensor congelado);
+//   - hasta 20 puntos por ruido en el nivel;
+//   - hasta 30 puntos si la batería reportada baja del 50 %.
+func BuildSensorHealth(sensorID string, tank *Tank, measurements []*Measurement, config SensorHealthConfig, now time.Time) *SensorHealth {
+	health := &SensorHealth{
+		SensorID: sensorID,
+		TankID:   tank.ID,
+		Samples:  len(measurements),
+		Health:   SensorHealthUnknown,
+	}
+
+	ordered := SortMeasurementsAscending(measurements)
+	if len(ordered) > 0 {
+		latest := ordered[len(ordered)-1]
+		health.LastSeen = latest.Timestamp
+		health.SilentFor = math.Max(0, now.Sub(latest.Timestamp).Seconds())
+
+		if latest.BatteryVoltage != nil {
+			voltage := *latest.BatteryVoltage
+			percentage := config.BatteryPercentage(voltage)
+			health.BatteryVoltage = &voltage
+			health.BatteryPercentage = &percentage
+		}
+
+		if latest.SignalStrength != nil {
+			rssi := *latest.SignalStrength
+			health.SignalStrength = &rssi
+		}
+	}
+
+	if len(ordered) < minSensorHealthSamples {
+		return health
+	}
+
+	intervals := make([]float64, 0, len(ordered)-1)
+	repeated := 0
+	for i := 1; i < len(ordered); i++ {
+		intervals = append(intervals, ordered[i].Timestamp.Sub(ordered[i-1].Timestamp).Seconds())
+		if ordered[i].Level == ordered[i-1].Level && ordered[i].Temperature == ordered[i-1].Temperature {
+			repeated++
+		}
+	}
+
+	health.ReportingInterval = median(intervals)
+	health.IntervalJitter = coefficientOfVariation(intervals)
+	health.FlatlineRatio = float64(repeated) / float64(len(intervals))
+
+	// Segunda diferencia del nivel: el consumo regular se anula y queda la oscilación de la lectura.
+	// La mediana descarta los saltos puntuales de las recargas.
+	if tank.Capacity > 0 {
+		curvature := make([]float64, 0, len(ordered)-2)
+		for i := 1; i < len(ordered)-1; i++ {
+			curvature = append(curvature, math.Abs(ordered[i-1].Level-2*ordered[i].Level+ordered[i+1].Level))
+		}
+		health.NoisePercentage = median(curvature) / tank.Capacity * 100
+	}
+
+	score := 100.0
+	score -= math.Min(30, health.IntervalJitter*30)
+	if health.ReportingInterval > 0 {
+		score -= math.Max(0, math.Min(40, (health.SilentFor/health.ReportingInterval-2)*10))
+	}
+	score -= health.FlatlineRatio * 60
+	score -= math.Min(20, health.NoisePercentage*10)
+	if health.BatteryPercentage != nil && *health.BatteryPercentage < 50 {
+		score -= (50 - *health.BatteryPercentage) * 0.6
+	}
+
+	health.Score = math.Round(math.Max(0, score)*10) / 10
+	health.Health = HealthForScore(health.Score)
+
+	return health
+}
+
+// median devuelve la mediana de los valores, o 0 si no hay ninguno
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// coefficientOfVariation devuelve la desviación típica relativa a la media
+func coefficientOfVariation(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if mean <= 0 {
+		return 0
+	}
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+
+	return math.Sqrt(variance/float64(len(values))) / mean
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Prefijos de los tokens de sesión; distinguen los tokens emitidos al iniciar sesión de los
+// tokens de la API y de los del proveedor de identidad
+const (
+	SessionAccessTokenPrefix  = "mts_"
+	SessionRefreshTokenPrefix = "mtr_"
+)
+
+// MaxPreviousRefreshHashes limita los tokens de refresco ya rotados que se recuerdan por sesión;
+// uno más antiguo se rechaza sin revocar la sesión
+const MaxPreviousRefreshHashes = 32
+
+// SessionTokenType es el tipo de los tokens de acceso de las sesiones
+const SessionTokenType = "Bearer"
+
+// Motivos por los que se revoca una sesión
+const (
+	SessionRevokedLogout       = "logout"        // El usuario cerró todas sus sesiones
+	SessionRevokedByAdmin      = "admin"         // Un administrador expulsó la sesión
+	SessionRevokedRefreshReuse = "refresh_reuse" // Se presentó un token de refresco ya rotado
+)
+
+// Session es una sesión iniciada en la aplicación. El token de acceso dura poco y se renueva con
+// el token de refresco, que rota en cada uso: presentar uno ya usado indica que pudo ser robado y
+// revoca la sesión. Solo se guardan los resúmenes de los tokens.
+type Session struct {
+	ID        string    `json:"id"`
+	Principal Principal `json:"principal"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+
+	AccessHash      string    `json:"-"` // SHA-256 del token de acceso vigente
+	AccessExpiresAt time.Time `json:"-"`
+	RefreshHash     string    `json:"-"` // SHA-256 del token de refresco vigente
+	// SHA-256 de los últimos tokens de refresco rotados, del más antiguo al más reciente
+	PreviousRefreshHashes []string `json:"-"`
+
+	CreatedAt     time.Time  `json:"created_at"`
+	RefreshedAt   time.Time  `json:"refreshed_at"` // Último inicio de sesión o refresco
+	ExpiresAt     time.Time  `json:"expires_at"`   // Vencimiento del token de refresco
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason string     `json:"revoked_reason,omitempty"`
+	Active        bool       `json:"active"` // Calculado al consultar
+}
+
+// SessionTokens son los tokens que se entregan al cliente al iniciar sesión o refrescarla
+type SessionTokens struct {
+	SessionID    string `json:"session_id"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`         // Segundos de validez del token de acceso
+	IDToken      string `json:"id_token,omitempty"` // id_token del proveedor, solo al iniciar sesión
+}
+
+// SessionConfig fija la vigencia de los tokens de las sesiones
+type SessionConfig struct {
+	AccessTTL  time.Duration // Vigencia del token de acceso
+	RefreshTTL time.Duration // Vigencia del token de refresco; cada refresco la renueva
+}
+
+// SessionFilter filtra el listado de sesiones
+type SessionFilter struct {
+	Subject    string // Vacío no filtra
+	ActiveOnly bool
+}
+
+// IsActive indica si la sesión puede usarse en el instante now
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// Revoke cierra la sesión; revocar una sesión ya revocada no cambia el motivo
+func (s *Session) Revoke(reason string, now time.Time) {
+	if s.RevokedAt != nil {
+		return
+	}
+	s.RevokedAt = &now
+	s.RevokedReason = reason
+}
+
+// RotateRefreshHash reemplaza el resumen del token de refresco vigente y recuerda el anterior,
+// para reconocer su reutilización
+func (s *Session) RotateRefreshHash(hash string) {
+	if s.RefreshHash != "" {
+		previous := append([]string(nil), s.PreviousRefreshHashes...)
+		previous = append(previous, s.RefreshHash)
+		if len(previous) > MaxPreviousRefreshHashes {
+			previous = previous[len(previous)-MaxPreviousRefreshHashes:]
+		}
+		s.PreviousRefreshHashes = previous
+	}
+	s.RefreshHash = hash
+}
+
+// IsSessionToken indica si el token Bearer es un token de acceso de una sesión
+func IsSessionToken(token string) bool {
+	return strings.HasPrefix(token, SessionAccessTokenPrefix)
+}
+
+// SessionTokenID separa un token de sesión de la forma <prefijo><ID de la sesión>.<secreto> en
+// el ID de la sesión, con el que se busca, y el resto del token
+func SessionTokenID(token, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(token, prefix)
+	if !ok {
+		return "", false
+	}
+	id, secret, ok := strings.Cut(rest, ".")
+	if !ok || id == "" || secret == "" {
+		return "", false
+	}
+	return id, true
+}
+
+// HashSessionToken devuelve el resumen con el que se guarda y se compara el token de sesión
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
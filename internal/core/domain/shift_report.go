@@ -0,0 +1,273 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MinDetectedDeliveryPercent es la subida mínima de nivel, en porcentaje de la capacidad, que se
+// considera una entrega; las subidas menores suelen ser ruido del sensor o dilatación térmica
+const MinDetectedDeliveryPercent = 5.0
+
+// ShiftAlert es una alerta levantada durante el turno: la entrada de un tanque en un estado de
+// aviso o crítico. Se considera atendida si un operador silenció las alertas del tanque después.
+type ShiftAlert struct {
+	TankID         string     `json:"tank_id"`
+	TankName       string     `json:"tank_name"`
+	Severity       string     `json:"severity"` // warning o critical
+	RaisedAt       time.Time  `json:"raised_at"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	Reason         string     `json:"reason,omitempty"` // Motivo indicado al silenciar
+}
+
+// DetectedDelivery es una entrega deducida de una subida continuada del nivel del tanque
+type DetectedDelivery struct {
+	TankID    string    `json:"tank_id"`
+	TankName  string    `json:"tank_name"`
+	StartedAt time.Time `json:"started_at"` // Última medición antes de la subida
+	EndedAt   time.Time `json:"ended_at"`   // Medición con el nivel más alto de la subida
+	Volume    float64   `json:"volume"`     // Litros de subida
+}
+
+// ShiftTankStatus es un tanque que sigue en aviso o crítico al cerrar el turno
+type ShiftTankStatus struct {
+	TankID          string     `json:"tank_id"`
+	TankName        string     `json:"tank_name"`
+	SiteID          string     `json:"site_id,omitempty"`
+	Status          string     `json:"status"`
+	LevelPercentage float64    `json:"level_percentage"`
+	Since           *time.Time `json:"since,omitempty"` // Entrada en el estado, si se conoce
+}
+
+// ShiftSummary agrega los hechos del turno
+type ShiftSummary struct {
+	AlertsRaised       int     `json:"alerts_raised"`
+	AlertsAcknowledged int     `json:"alerts_acknowledged"`
+	DeliveriesDetected int     `json:"deliveries_detected"`
+	DeliveredVolume    float64 `json:"delivered_volume"`
+	TanksWarning       int     `json:"tanks_warning"`
+	TanksCritical      int     `json:"tanks_critical"`
+}
+
+// ShiftReport es el informe de relevo de turno de los operadores
+type ShiftReport struct {
+	GeneratedAt time.Time           `json:"generated_at"`
+	From        time.Time           `json:"from"`
+	To          time.Time           `json:"to"`
+	Summary     ShiftSummary        `json:"summary"`
+	Alerts      []*ShiftAlert       `json:"alerts"`     // En orden cronológico
+	Deliveries  []*DetectedDelivery `json:"deliveries"` // En orden cronológico
+	Attention   []*ShiftTankStatus  `json:"attention"`  // Primero los críticos
+}
+
+// statusRank ordena los estados de menor a mayor gravedad
+func statusRank(status string) int {
+	switch status {
+	case "critical":
+		return 2
+	case "warning":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// BuildShiftAlerts devuelve las alertas levantadas en [from, to] a partir de las transiciones
+// del tanque: cada empeoramiento hacia aviso o crítico es una alerta. La primera alerta
+// posterior a cada silencio se marca como atendida por él. Las horas se expresan en la zona
+// horaria del tanque, como en el resto del informe.
+func BuildShiftAlerts(tank *Tank, changes []*StatusChange, mutes []*AlertMute, from, to time.Time) []*ShiftAlert {
+	location := tank.TimeLocation()
+	alerts := make([]*ShiftAlert, 0)
+	for _, change := range changes {
+		if change.ChangedAt.Before(from) || change.ChangedAt.After(to) {
+			continue
+		}
+		if statusRank(change.ToStatus) == 0 || statusRank(change.ToStatus) <= statusRank(change.FromStatus) {
+			continue
+		}
+		alerts = append(alerts, &ShiftAlert{
+			TankID:   tank.ID,
+			TankName: tank.Name,
+			Severity: change.ToStatus,
+			RaisedAt: change.ChangedAt.In(location),
+		})
+	}
+
+	for _, mute := range mutes {
+		if mute.MutedAt.Before(from) || mute.MutedAt.After(to) {
+			continue
+		}
+		// El silencio atiende la alerta más reciente anterior a él que siga sin atender
+		for i := len(alerts) - 1; i >= 0; i-- {
+			alert := alerts[i]
+			if alert.RaisedAt.After(mute.MutedAt) {
+				continue
+			}
+			if alert.AcknowledgedAt == nil {
+				mutedAt := mute.MutedAt.In(location)
+				alert.AcknowledgedAt = &mutedAt
+				alert.AcknowledgedBy = mute.MutedBy
+				alert.Reason = mute.Reason
+			}
+			break
+		}
+	}
+	return alerts
+}
+
+// DetectDeliveries busca en las mediciones del tanque las subidas continuadas de nivel que
+// terminan en [from, to] y superan MinDetectedDeliveryPercent de la capacidad
+func DetectDeliveries(tank *Tank, measurements []*Measurement, from, to time.Time) []*DetectedDelivery {
+	deliveries := make([]*DetectedDelivery, 0)
+	minimum := tank.Capacity * MinDetectedDeliveryPercent / 100
+	location := tank.TimeLocation()
+
+	var previous, start, peak *Measurement
+	flush := func() {
+		if start != nil && peak.Level-start.Level >= minimum && !peak.Timestamp.Before(from) {
+			deliveries = append(deliveries, &DetectedDelivery{
+				TankID:    tank.ID,
+				TankName:  tank.Name,
+				StartedAt: start.Timestamp.In(location),
+				EndedAt:   peak.Timestamp.In(location),
+				Volume:    peak.Level - start.Level,
+			})
+		}
+		start, peak = nil, nil
+	}
+
+	for _, m := range SortMeasurementsAscending(measurements) {
+		if m.Timestamp.After(to) {
+			break
+		}
+		if previous != nil {
+			if m.Level > previous.Level {
+				if start == nil {
+					start = previous
+				}
+				peak = m
+			} else {
+				flush()
+			}
+		}
+		previous = m
+	}
+	flush()
+	return deliveries
+}
+
+// NewShiftTankStatus devuelve el estado del tanque si sigue en aviso o crítico, o nil. since es
+// la última entrada conocida en ese estado según sus transiciones.
+func NewShiftTankStatus(tank *Tank, changes []*StatusChange) *ShiftTankStatus {
+	if statusRank(tank.Status) == 0 {
+		return nil
+	}
+
+	status := &ShiftTankStatus{
+		TankID:          tank.ID,
+		TankName:        tank.Name,
+		SiteID:          tank.SiteID,
+		Status:          tank.Status,
+		LevelPercentage: tank.GetLevelPercentage(),
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		if changes[i].ToStatus != tank.Status {
+			break
+		}
+		changedAt := changes[i].ChangedAt.In(tank.TimeLocation())
+		status.Since = &changedAt
+	}
+	return status
+}
+
+// Finalize ordena las secciones del informe y calcula el resumen
+func (r *ShiftReport) Finalize() {
+	sort.SliceStable(r.Alerts, func(i, j int) bool { return r.Alerts[i].RaisedAt.Before(r.Alerts[j].RaisedAt) })
+	sort.SliceStable(r.Deliveries, func(i, j int) bool { return r.Deliveries[i].EndedAt.Before(r.Deliveries[j].EndedAt) })
+	sort.SliceStable(r.Attention, func(i, j int) bool {
+		a, b := r.Attention[i], r.Attention[j]
+		if statusRank(a.Status) != statusRank(b.Status) {
+			return statusRank(a.Status) > statusRank(b.Status)
+		}
+		return a.LevelPercentage < b.LevelPercentage
+	})
+
+	r.Summary = ShiftSummary{AlertsRaised: len(r.Alerts), DeliveriesDetected: len(r.Deliveries)}
+	for _, alert := range r.Alerts {
+		if alert.AcknowledgedAt != nil {
+			r.Summary.AlertsAcknowledged++
+		}
+	}
+	for _, delivery := range r.Deliveries {
+		r.Summary.DeliveredVolume += delivery.Volume
+	}
+	for _, tank := range r.Attention {
+		if tank.Status == "critical" {
+			r.Summary.TanksCritical++
+		} else {
+			r.Summary.TanksWarning++
+		}
+	}
+}
+
+// HandoverText compone el informe como texto plano para el correo de relevo del turno
+func (r *ShiftReport) HandoverText() string {
+	const layout = "2006-01-02 15:04 MST"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Relevo de turno del %s al %s\n\n", r.From.Format(layout), r.To.Format(layout))
+	fmt.Fprintf(&b, "Alertas levantadas: %d (%d atendidas)\n", r.Summary.AlertsRaised, r.Summary.AlertsAcknowledged)
+	fmt.Fprintf(&b, "Entregas detectadas: %d (%.2f L)\n", r.Summary.DeliveriesDetected, r.Summary.DeliveredVolume)
+	fmt.Fprintf(&b, "Tanques en crítico: %d\n", r.Summary.TanksCritical)
+	fmt.Fprintf(&b, "Tanques en aviso: %d\n", r.Summary.TanksWarning)
+
+	b.WriteString("\nTanques que requieren atención:\n")
+	if len(r.Attention) == 0 {
+		b.WriteString("- Ninguno\n")
+	}
+	for _, tank := range r.Attention {
+		fmt.Fprintf(&b, "- [%s] %s (%s): %.2f%%", tank.Status, tank.TankName, tank.TankID, tank.LevelPercentage)
+		if tank.Since != nil {
+			fmt.Fprintf(&b, " desde %s", tank.Since.Format(layout))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nAlertas del turno:\n")
+	if len(r.Alerts) == 0 {
+		b.WriteString("- Ninguna\n")
+	}
+	for _, alert := range r.Alerts {
+		fmt.Fprintf(&b, "- %s [%s] %s (%s)", alert.RaisedAt.Format(layout), alert.Severity, alert.TankName, alert.TankID)
+		switch {
+		case alert.AcknowledgedAt == nil:
+			b.WriteString(": sin atender")
+		case alert.Reason != "":
+			fmt.Fprintf(&b, ": atendida por %s (%s)", acknowledgedBy(alert), alert.Reason)
+		default:
+			fmt.Fprintf(&b, ": atendida por %s", acknowledgedBy(alert))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nEntregas detectadas:\n")
+	if len(r.Deliveries) == 0 {
+		b.WriteString("- Ninguna\n")
+	}
+	for _, delivery := range r.Deliveries {
+		fmt.Fprintf(&b, "- %s %s (%s): %.2f L\n", delivery.EndedAt.Format(layout), delivery.TankName, delivery.TankID, delivery.Volume)
+	}
+	return b.String()
+}
+
+// acknowledgedBy devuelve quién atendió la alerta, o un texto genérico si no consta
+func acknowledgedBy(alert *ShiftAlert) string {
+	if alert.AcknowledgedBy == "" {
+		return "un operador"
+	}
+	return alert.AcknowledgedBy
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// SignedURL es un enlace de descarga temporal que no necesita credenciales: la firma autoriza
+// la solicitud con la identidad de quien lo generó hasta ExpiresAt
+type SignedURL struct {
+	URL       string    `json:"url"`
+	Method    string    `json:"method"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
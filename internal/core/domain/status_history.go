@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"time"
+)
+
+// StatusChange representa una transición de estado de un tanque (p. ej. normal → warning)
+type StatusChange struct {
+	TankID     string    `json:"tank_id"`
+	FromStatus string    `json:"from_status"` // Vacío para el estado inicial del tanque
+	ToStatus   string    `json:"to_status"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// StatusHistory resume las transiciones de un tanque y el tiempo pasado en cada estado
+type StatusHistory struct {
+	TankID       string             `json:"tank_id"`
+	From         time.Time          `json:"from"`
+	To           time.Time          `json:"to"`
+	Changes      []*StatusChange    `json:"changes"`
+	TimeInStatus map[string]float64 `json:"time_in_status"` // Segundos en cada estado dentro del periodo
+	Availability float64            `json:"availability"`   // Porcentaje del tiempo conocido fuera de estado crítico
+	Notes        []*TankNote        `json:"notes"`          // Observaciones de los operadores en el periodo
+}
+
+// BuildStatusHistory calcula el historial de estados en el periodo [from, to] a partir de todas
+// las transiciones del tanque ordenadas cronológicamente. El tiempo anterior a la primera
+// transición conocida no se contabiliza.
+func BuildStatusHistory(tankID string, changes []*StatusChange, from, to time.Time) *StatusHistory {
+	history := &StatusHistory{
+		TankID:       tankID,
+		From:         from,
+		To:           to,
+		Changes:      make([]*StatusChange, 0),
+		TimeInStatus: make(map[string]float64),
+		Notes:        make([]*TankNote, 0),
+	}
+
+	current := ""
+	cursor := from
+
+	for _, change := range changes {
+		if change.ChangedAt.After(to) {
+			break
+		}
+
+		if change.ChangedAt.Before(from) {
+			// Estado vigente al inicio del periodo
+			current = change.ToStatus
+			continue
+		}
+
+		if current != "" {
+			history.TimeInStatus[current] += change.ChangedAt.Sub(cursor).Seconds()
+		}
+
+		history.Changes = append(history.Changes, change)
+		current = change.ToStatus
+		cursor = change.ChangedAt
+	}
+
+	if current != "" && to.After(cursor) {
+		history.TimeInStatus[current] += to.Sub(cursor).Seconds()
+	}
+
+	total := 0.0
+	for _, seconds := range history.TimeInStatus {
+		total += seconds
+	}
+	if total > 0 {
+		history.Availability = (total - history.TimeInStatus["critical"]) / total * 100
+	}
+
+	return history
+}
+
+// ReplayStatusChanges recalcula las transiciones de estado del tanque a partir de sus mediciones,
+// ordenadas de la más antigua a la más reciente, partiendo del estado vigente antes de la primera
+// (vacío si no se conoce). Se usan la capacidad y el umbral actuales del tanque.
+func ReplayStatusChanges(tank *Tank, measurements []*Measurement, initialStatus string) []*StatusChange {
+	changes := make([]*StatusChange, 0)
+	replayed := *tank
+	current := initialStatus
+
+	for _, measurement := range measurements {
+		replayed.CurrentLevel = measurement.Level
+		replayed.UpdateStatus()
+		if replayed.Status == current {
+			continue
+		}
+
+		changes = append(changes, &StatusChange{
+			TankID:     tank.ID,
+			FromStatus: current,
+			ToStatus:   replayed.Status,
+			ChangedAt:  measurement.Timestamp,
+		})
+		current = replayed.Status
+	}
+
+	return changes
+}
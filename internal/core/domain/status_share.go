@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// MaxStatusShareTanks limita los tanques de una página de estado pública
+const MaxStatusShareTanks = 100
+
+// StatusShare publica el nivel de algunos tanques en una página de estado de solo lectura, sin
+// autenticación, a la que se accede con un token secreto. Solo se guarda el hash del token: el
+// token se muestra una única vez, al crear la página.
+type StatusShare struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"` // Título de la página, p. ej. el nombre del cliente
+	TankIDs   []string   `json:"tank_ids"`
+	TokenHash string     `json:"-"`
+	Token     string     `json:"token,omitempty"` // Solo en la respuesta de creación
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsActive indica si la página puede consultarse en el instante indicado
+func (s *StatusShare) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && (s.ExpiresAt == nil || now.Before(*s.ExpiresAt))
+}
+
+// HashShareToken devuelve el hash con el que se guarda y se busca el token de una página
+func HashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// PublicTankStatus es lo que la página pública muestra de cada tanque: solo su nivel, sin
+// identificadores, ubicación ni configuración
+type PublicTankStatus struct {
+	Name        string    `json:"name"`
+	LiquidType  string    `json:"liquid_type"`
+	Capacity    float64   `json:"capacity"`
+	Level       float64   `json:"level"`
+	Percentage  float64   `json:"percentage"`
+	Status      string    `json:"status"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// PublicStatusPage es el contenido de una página de estado pública
+type PublicStatusPage struct {
+	Name        string              `json:"name"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	Tanks       []*PublicTankStatus `json:"tanks"`
+}
+
+// NewPublicTankStatus extrae del tanque los datos que se pueden publicar
+func NewPublicTankStatus(tank *Tank) *PublicTankStatus {
+	return &PublicTankStatus{
+		Name:        tank.Name,
+		LiquidType:  tank.LiquidType,
+		Capacity:    tank.Capacity,
+		Level:       tank.CurrentLevel,
+		Percentage:  tank.GetLevelPercentage(),
+		Status:      tank.Status,
+		LastUpdated: tank.LastUpdated,
+	}
+}
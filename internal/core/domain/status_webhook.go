@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"net/url"
+	"time"
+)
+
+// StatusWebhookEventType es el tipo de evento que reciben los webhooks de los tanques
+const StatusWebhookEventType = "tank.status_changed"
+
+// StatusWebhookEvent es el cuerpo que se envía al webhook propio de un tanque cuando cambia su
+// estado. Es una integración sencilla, sin reintentos ni cola, para los casos en los que un canal
+// de notificación sería excesivo.
+type StatusWebhookEvent struct {
+	Event           string       `json:"event"`
+	TankID          string       `json:"tank_id"`
+	TankName        string       `json:"tank_name"`
+	OldStatus       string       `json:"old_status"`
+	NewStatus       string       `json:"new_status"`
+	LevelPercentage float64      `json:"level_percentage"`
+	ChangedAt       time.Time    `json:"changed_at"`
+	Measurement     *Measurement `json:"measurement,omitempty"` // Última medición del tanque, si tiene
+}
+
+// NewStatusWebhookEvent crea el evento del cambio de estado del tanque
+func NewStatusWebhookEvent(tank *Tank, oldStatus string, measurement *Measurement) *StatusWebhookEvent {
+	return &StatusWebhookEvent{
+		Event:           StatusWebhookEventType,
+		TankID:          tank.ID,
+		TankName:        tank.Name,
+		OldStatus:       oldStatus,
+		NewStatus:       tank.Status,
+		LevelPercentage: tank.GetLevelPercentage(),
+		ChangedAt:       tank.LastUpdated,
+		Measurement:     measurement,
+	}
+}
+
+// IsValidWebhookURL indica si la URL puede usarse como webhook: absoluta y con esquema http o
+// https. La URL vacía es válida y desactiva el webhook.
+func IsValidWebhookURL(raw string) bool {
+	if raw == "" {
+		return true
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Perfiles de las series sintéticas
+const (
+	SyntheticProfileConsumption = "consumption" // Consumo diurno, casi nulo de noche
+	SyntheticProfileRefill      = "refill"      // Consumo hasta el nivel crítico y reabastecimiento
+	SyntheticProfileLeak        = "leak"        // Pérdida continua, también de noche, hasta el nivel crítico
+)
+
+// SyntheticSensorID es el sensor de las mediciones sintéticas; las distingue de las lecturas reales
+const SyntheticSensorID = "simulator"
+
+// MaxSyntheticMeasurements limita las mediciones de una serie sintética
+const MaxSyntheticMeasurements = MaxBackfillMeasurements
+
+// Parámetros de las series sintéticas, en fracciones de la capacidad del tanque
+const (
+	syntheticDailyConsumption = 0.15  // Consumo de un día completo
+	syntheticNoise            = 0.003 // Desviación típica del ruido del sensor
+	syntheticRefillLevel      = 0.95  // Nivel al que se reabastece
+	syntheticStartLevel       = 0.9   // Nivel inicial si el tanque está en nivel crítico
+)
+
+// SyntheticSeries resume una serie sintética inyectada en un tanque
+type SyntheticSeries struct {
+	TankID       string    `json:"tank_id"`
+	Profile      string    `json:"profile"`
+	From         time.Time `json:"from"`     // Primera medición
+	To           time.Time `json:"to"`       // Última medición
+	Interval     float64   `json:"interval"` // Segundos entre mediciones
+	Measurements int       `json:"measurements"`
+	StartLevel   float64   `json:"start_level"`
+	EndLevel     float64   `json:"end_level"`
+	MinLevel     float64   `json:"min_level"`
+}
+
+// IsValidSyntheticProfile indica si el perfil de serie sintética es conocido
+func IsValidSyntheticProfile(profile string) bool {
+	switch profile {
+	case SyntheticProfileConsumption, SyntheticProfileRefill, SyntheticProfileLeak:
+		return true
+	}
+	return false
+}
+
+// GenerateSyntheticSeries genera una lectura cada interval durante duration, terminando en to,
+// con el perfil indicado. La serie parte del nivel actual del tanque (del 90 % si está en nivel
+// crítico), sigue el horario local del tanque y añade ruido de sensor al nivel y a la temperatura.
+func GenerateSyntheticSeries(tank *Tank, profile string, to time.Time, duration, interval time.Duration, random *rand.Rand) []*Measurement {
+	count := int(duration / interval)
+	if count <= 0 || tank.Capacity <= 0 {
+		return nil
+	}
+
+	capacity := tank.Capacity
+	critical := capacity * tank.AlertThreshold / 100
+	start := tank.CurrentLevel
+	if start <= critical || start > capacity {
+		start = capacity * syntheticStartLevel
+	}
+
+	baseTemperature := tank.Temperature
+	if baseTemperature == 0 {
+		baseTemperature = 20
+	}
+
+	location := tank.TimeLocation()
+	from := to.Add(-time.Duration(count) * interval)
+	hours := duration.Hours()
+
+	// El reabastecimiento llega al 60 % del periodo, tras bajar hasta el 80 % del nivel crítico,
+	// y dura el 5 % del periodo
+	refillAt := 0.6 * hours
+	refillHours := math.Max(0.05*hours, interval.Hours())
+	refillFrom := 0.8 * critical
+
+	level := start
+	measurements := make([]*Measurement, 0, count)
+	for i := 1; i <= count; i++ {
+		at := from.Add(time.Duration(i) * interval)
+		elapsed := at.Sub(from).Hours()
+		step := interval.Hours()
+
+		switch profile {
+		case SyntheticProfileLeak:
+			// Pérdida constante que termina en la mitad del nivel crítico
+			level -= (start - critical/2) / hours * step
+		case SyntheticProfileRefill:
+			switch {
+			case elapsed <= refillAt:
+				level -= (start - refillFrom) / refillAt * step
+			case elapsed <= refillAt+refillHours:
+				level += (capacity*syntheticRefillLevel - refillFrom) / refillHours * step
+			default:
+				level -= syntheticConsumption(capacity, at.In(location), step)
+			}
+		default:
+			level -= syntheticConsumption(capacity, at.In(location), step)
+		}
+		level = math.Max(0, math.Min(capacity, level))
+
+		measurements = append(measurements, &Measurement{
+			TankID:      tank.ID,
+			SensorID:    SyntheticSensorID,
+			Level:       roundTenth(math.Max(0, math.Min(capacity, level+random.NormFloat64()*syntheticNoise*capacity))),
+			Temperature: roundTenth(syntheticTemperature(baseTemperature, at.In(location)) + random.NormFloat64()*0.2),
+			Timestamp:   at,
+		})
+	}
+	return measurements
+}
+
+// syntheticConsumption devuelve los litros consumidos en step horas a partir de la hora local at:
+// de 06:00 a 22:00 se consume ocho veces más que de noche
+func syntheticConsumption(capacity float64, at time.Time, step float64) float64 {
+	const dayWeight, nightWeight = 1.6, 0.2
+	weight := nightWeight
+	if hour := at.Hour(); hour >= 6 && hour < 22 {
+		weight = dayWeight
+	}
+	perHour := capacity * syntheticDailyConsumption / (16*dayWeight + 8*nightWeight)
+	return perHour * weight * step
+}
+
+// syntheticTemperature sigue el ciclo diario: la mínima a las 03:00 y la máxima a las 15:00
+func syntheticTemperature(base float64, at time.Time) float64 {
+	hour := float64(at.Hour()) + float64(at.Minute())/60
+	return base + 4*math.Sin(2*math.Pi*(hour-9)/24)
+}
+
+// roundTenth redondea a una décima, la resolución habitual de los sensores
+func roundTenth(value float64) float64 {
+	return math.Round(value*10) / 10
+}
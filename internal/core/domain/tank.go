@@ -1,20 +1,69 @@
 package domain
 
 import (
+	"errors"
+	"sort"
 	"time"
 )
 
+// ErrDuplicateMeasurement se devuelve al guardar una lectura que ya está guardada: la misma
+// marca de tiempo del mismo sensor del tanque, p. ej. porque el equipo reintentó el envío
+var ErrDuplicateMeasurement = errors.New("duplicate measurement")
+
+// ErrLateMeasurement se devuelve al guardar una lectura anterior a la última del tanque: queda en
+// el historial, pero no cambia el nivel actual ni genera alertas
+var ErrLateMeasurement = errors.New("late measurement")
+
+// ErrTankNotFound se devuelve cuando el tanque no existe. Lo comparten los repositorios y los
+// servicios, para que un tanque inexistente se reconozca venga de donde venga.
+var ErrTankNotFound = errors.New("tank not found")
+
 // Tank representa la entidad principal de nuestro dominio - un tanque que almacena líquidos
 type Tank struct {
-	ID             string    `json:"id"`
-	Name           string    `json:"name"`
-	Capacity       float64   `json:"capacity"`      // Capacidad total en litros
-	CurrentLevel   float64   `json:"current_level"` // Nivel actual en litros
-	LiquidType     string    `json:"liquid_type"`   // Tipo de líquido almacenado
-	Temperature    float64   `json:"temperature"`   // Temperatura en grados Celsius
-	LastUpdated    time.Time `json:"last_updated"`
-	Status         string    `json:"status"`          // normal, warning, critical
-	AlertThreshold float64   `json:"alert_threshold"` // Umbral para alertas (porcentaje)
+	ID             string        `json:"id"`
+	Name           string        `json:"name"`
+	SiteID         string        `json:"site_id"`             // Estación o instalación donde se encuentra el tanque
+	GroupID        string        `json:"group_id"`            // Grupo lógico de tanques (región, cliente, ...)
+	ParentID       string        `json:"parent_id,omitempty"` // Tanque del que es compartimento; vacío en los independientes
+	Labels         Labels        `json:"labels,omitempty"`    // Etiquetas libres para filtrar y seleccionar tanques
+	Location       *GeoLocation  `json:"location,omitempty"`
+	Capacity       float64       `json:"capacity"`      // Capacidad total en litros
+	CurrentLevel   float64       `json:"current_level"` // Nivel actual en litros
+	LiquidType     string        `json:"liquid_type"`   // Tipo de líquido almacenado
+	Temperature    float64       `json:"temperature"`   // Temperatura en grados Celsius
+	LastUpdated    time.Time     `json:"last_updated"`
+	Status         string        `json:"status"`          // normal, warning, critical
+	AlertThreshold float64       `json:"alert_threshold"` // Umbral para alertas (porcentaje)
+	Reorder        ReorderConfig `json:"reorder"`         // Configuración de reabastecimiento
+	// StatusWebhookURL recibe un POST cada vez que cambia el estado del tanque (opcional)
+	StatusWebhookURL string `json:"status_webhook_url,omitempty"`
+	// Timezone es la zona horaria IANA del tanque (p. ej. America/Bogota); vacía equivale a UTC.
+	// Fija dónde empiezan los días de los agregados y los horarios de los informes y avisos.
+	Timezone string `json:"timezone,omitempty"`
+	// Trend es la tendencia a corto plazo según las últimas mediciones; se calcula al consultar
+	// el tanque y no se guarda
+	Trend *TankTrend `json:"trend,omitempty"`
+}
+
+// IsValidTimezone indica si el nombre es una zona horaria IANA conocida; vacío es válido (UTC)
+func IsValidTimezone(name string) bool {
+	if name == "" {
+		return true
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// TimeLocation devuelve la zona horaria del tanque, o UTC si no tiene una válida
+func (t *Tank) TimeLocation() *time.Location {
+	if t.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
 }
 
 // GetLevelPercentage calcula el porcentaje de llenado del tanque
@@ -45,11 +94,56 @@ func (t *Tank) UpdateStatus() {
 	}
 }
 
+// Clone devuelve un tanque nuevo con la misma configuración (sitio, grupo, tanque padre, etiquetas,
+// ubicación, zona horaria, capacidad, líquido, umbral y reabastecimiento) pero sin nivel, temperatura
+// ni estado, que provienen de las mediciones del tanque original. El clon de un compartimento es
+// otro compartimento del mismo tanque.
+func (t *Tank) Clone(id, name string) *Tank {
+	clone := &Tank{
+		ID:             id,
+		Name:           name,
+		SiteID:         t.SiteID,
+		GroupID:        t.GroupID,
+		ParentID:       t.ParentID,
+		Labels:         t.Labels.Clone(),
+		Capacity:       t.Capacity,
+		LiquidType:     t.LiquidType,
+		AlertThreshold: t.AlertThreshold,
+		Reorder:        t.Reorder,
+		Timezone:       t.Timezone,
+	}
+	if t.Location != nil {
+		location := *t.Location
+		clone.Location = &location
+	}
+	return clone
+}
+
 // Measurement representa una medición del nivel del tanque en un momento específico
 type Measurement struct {
-	ID          string    `json:"id"`
-	TankID      string    `json:"tank_id"`
-	Level       float64   `json:"level"`
-	Timestamp   time.Time `json:"timestamp"`
-	Temperature float64   `json:"temperature"`
+	ID             string    `json:"id"`
+	TankID         string    `json:"tank_id"`
+	SensorID       string    `json:"sensor_id,omitempty"` // Sensor que tomó la lectura (por defecto, el del tanque)
+	Level          float64   `json:"level"`
+	Timestamp      time.Time `json:"timestamp"`
+	Temperature    float64   `json:"temperature"`
+	BatteryVoltage *float64  `json:"battery_voltage,omitempty"` // Voltaje de la batería, si el sensor lo reporta
+	SignalStrength *float64  `json:"rssi,omitempty"`            // Intensidad de la señal de radio en dBm
+}
+
+// IsSameReading indica si las dos mediciones son la misma lectura: mismo tanque, mismo sensor y
+// misma marca de tiempo
+func (m *Measurement) IsSameReading(other *Measurement) bool {
+	return m.TankID == other.TankID && SensorIDForMeasurement(m) == SensorIDForMeasurement(other) &&
+		m.Timestamp.Equal(other.Timestamp)
+}
+
+// SortMeasurementsAscending devuelve una copia del slice ordenada de la más antigua a la más reciente
+func SortMeasurementsAscending(measurements []*Measurement) []*Measurement {
+	sorted := make([]*Measurement, len(measurements))
+	copy(sorted, measurements)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+	return sorted
 }
@@ -0,0 +1,115 @@
+package domain
+
+import "time"
+
+// Estados de una solicitud de cambio de un tanque
+const (
+	TankChangePending    = "pending"    // Esperando la revisión de un administrador
+	TankChangeApproved   = "approved"   // Aprobada y aplicada al tanque
+	TankChangeRejected   = "rejected"   // Rechazada; el tanque no cambió
+	TankChangeSuperseded = "superseded" // Reemplazada por una solicitud posterior del mismo tanque
+)
+
+// Campos del tanque cuyos cambios requieren aprobación en los sitios regulados
+const (
+	TankFieldAlertThreshold = "alert_threshold"
+	TankFieldCapacity       = "capacity"
+)
+
+// FieldChange es el cambio de un campo numérico del tanque
+type FieldChange struct {
+	Field string  `json:"field"`
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+}
+
+// TankChangeRequest es un cambio del umbral o de la capacidad de un tanque de un sitio regulado
+// que un operador propuso y que solo se aplica cuando lo aprueba un administrador. Las solicitudes
+// se conservan tras revisarse, como auditoría de quién pidió y quién aprobó cada cambio.
+type TankChangeRequest struct {
+	ID          string         `json:"id"`
+	TankID      string         `json:"tank_id"`
+	SiteID      string         `json:"site_id"`
+	Changes     []*FieldChange `json:"changes"`
+	Status      string         `json:"status"`
+	RequestedBy string         `json:"requested_by,omitempty"`
+	RequestedAt time.Time      `json:"requested_at"`
+	ReviewedBy  string         `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time     `json:"reviewed_at,omitempty"`
+	Comment     string         `json:"comment,omitempty"` // Motivo del rechazo o comentario de la aprobación
+}
+
+// TankChangeFilter filtra el listado de solicitudes de cambio
+type TankChangeFilter struct {
+	Status string // Vacío no filtra
+	TankID string // Vacío no filtra
+}
+
+// Matches indica si la solicitud cumple el filtro
+func (f TankChangeFilter) Matches(change *TankChangeRequest) bool {
+	return (f.Status == "" || change.Status == f.Status) && (f.TankID == "" || change.TankID == f.TankID)
+}
+
+// IsValidTankChangeStatus indica si el estado de solicitud de cambio es conocido
+func IsValidTankChangeStatus(status string) bool {
+	switch status {
+	case TankChangePending, TankChangeApproved, TankChangeRejected, TankChangeSuperseded:
+		return true
+	}
+	return false
+}
+
+// TankChangesRequiringApproval devuelve los cambios del umbral y de la capacidad entre el tanque
+// guardado y el deseado
+func TankChangesRequiringApproval(current, desired *Tank) []*FieldChange {
+	var changes []*FieldChange
+	if desired.AlertThreshold != current.AlertThreshold {
+		changes = append(changes, &FieldChange{Field: TankFieldAlertThreshold, From: current.AlertThreshold, To: desired.AlertThreshold})
+	}
+	if desired.Capacity != current.Capacity {
+		changes = append(changes, &FieldChange{Field: TankFieldCapacity, From: current.Capacity, To: desired.Capacity})
+	}
+	return changes
+}
+
+// IsStale indica si el tanque cambió desde la solicitud: algún campo ya no tiene el valor del
+// que partía, así que aprobarla pisaría un cambio posterior
+func (c *TankChangeRequest) IsStale(tank *Tank) bool {
+	for _, change := range c.Changes {
+		if tankField(tank, change.Field) != change.From {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply aplica al tanque los valores solicitados
+func (c *TankChangeRequest) Apply(tank *Tank) {
+	for _, change := range c.Changes {
+		switch change.Field {
+		case TankFieldAlertThreshold:
+			tank.AlertThreshold = change.To
+		case TankFieldCapacity:
+			tank.Capacity = change.To
+		}
+	}
+}
+
+// Review cierra la solicitud con el estado indicado
+func (c *TankChangeRequest) Review(status, reviewer, comment string, now time.Time) {
+	c.Status = status
+	c.ReviewedBy = reviewer
+	c.ReviewedAt = &now
+	c.Comment = comment
+}
+
+// tankField devuelve el valor actual de un campo que requiere aprobación
+func tankField(tank *Tank, field string) float64 {
+	switch field {
+	case TankFieldAlertThreshold:
+		return tank.AlertThreshold
+	case TankFieldCapacity:
+		return tank.Capacity
+	}
+	return 0
+}
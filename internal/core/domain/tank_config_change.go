@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Campos de configuración cuyo cambio se notifica con severidad de aviso: determinan cuándo
+// salta la alerta de nivel crítico
+var safetyConfigFields = map[string]bool{
+	TankFieldAlertThreshold: true,
+	TankFieldCapacity:       true,
+}
+
+// ConfigFieldChange es el cambio de un campo de la configuración de un tanque
+type ConfigFieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// TankConfigChange describe un cambio de la configuración de un tanque: quién lo hizo y el valor
+// anterior y el nuevo de cada campo
+type TankConfigChange struct {
+	ChangedBy string               `json:"changed_by,omitempty"` // Vacío sin usuario autenticado
+	Changes   []*ConfigFieldChange `json:"changes"`
+}
+
+// TankConfigChanges devuelve los campos de configuración en los que after difiere de before: los
+// del aprovisionamiento y el webhook de estado. El nivel, la temperatura y el estado provienen de
+// las mediciones y no se comparan.
+func TankConfigChanges(before, after *Tank) []*ConfigFieldChange {
+	fields := TankSpecDiff(before, after)
+	if before.StatusWebhookURL != after.StatusWebhookURL {
+		fields = append(fields, "status_webhook_url")
+	}
+
+	changes := make([]*ConfigFieldChange, 0, len(fields))
+	for _, field := range fields {
+		changes = append(changes, &ConfigFieldChange{
+			Field:  field,
+			Before: tankConfigValue(before, field),
+			After:  tankConfigValue(after, field),
+		})
+	}
+	return changes
+}
+
+// Severity devuelve la severidad de la notificación: aviso si cambió el umbral o la capacidad,
+// informativa en el resto de los casos
+func (c *TankConfigChange) Severity() string {
+	for _, change := range c.Changes {
+		if safetyConfigFields[change.Field] {
+			return AlertSeverityWarning
+		}
+	}
+	return AlertSeverityInfo
+}
+
+// Summary resume los cambios en una línea, p. ej. "alert_threshold: 10 → 20; capacity: 1000 → 1200"
+func (c *TankConfigChange) Summary() string {
+	parts := make([]string, 0, len(c.Changes))
+	for _, change := range c.Changes {
+		parts = append(parts, fmt.Sprintf("%s: %s → %s", change.Field, formatConfigValue(change.Before), formatConfigValue(change.After)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// tankConfigValue devuelve el valor de un campo de configuración del tanque
+func tankConfigValue(tank *Tank, field string) interface{} {
+	switch field {
+	case "name":
+		return tank.Name
+	case "site_id":
+		return tank.SiteID
+	case "group_id":
+		return tank.GroupID
+	case "parent_id":
+		return tank.ParentID
+	case "labels":
+		return tank.Labels.Clone()
+	case "location":
+		if tank.Location == nil {
+			return nil
+		}
+		location := *tank.Location
+		return &location
+	case TankFieldCapacity:
+		return tank.Capacity
+	case "liquid_type":
+		return tank.LiquidType
+	case TankFieldAlertThreshold:
+		return tank.AlertThreshold
+	case "reorder":
+		return tank.Reorder
+	case "timezone":
+		return tank.Timezone
+	case "status_webhook_url":
+		return tank.StatusWebhookURL
+	}
+	return nil
+}
+
+// formatConfigValue presenta el valor de un campo para el texto de la notificación
+func formatConfigValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		if v == "" {
+			return "-"
+		}
+		return v
+	case *GeoLocation:
+		return fmt.Sprintf("%.5f,%.5f", v.Latitude, v.Longitude)
+	case Labels:
+		if len(v) == 0 {
+			return "-"
+		}
+		pairs := make([]string, 0, len(v))
+		for key, label := range v {
+			pairs = append(pairs, key+"="+label)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	case ReorderConfig:
+		return fmt.Sprintf("reorder_level=%g lead_time_days=%g delivery_size=%g", v.ReorderLevel, v.LeadTimeDays, v.DeliverySize)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,87 @@
+package domain
+
+// Resultado de cada fila de una importación de tanques
+const (
+	TankImportCreated = "created" // El tanque se creó
+	TankImportValid   = "valid"   // La fila es válida; en una simulación no se crea nada
+	TankImportInvalid = "invalid" // La fila tiene errores y se omitió
+)
+
+// TankImportError describe un problema de una fila; Column está vacío si no se debe a una
+// columna concreta. Los mensajes son fijos para poder traducirlos.
+type TankImportError struct {
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// TankImportRow es una fila leída de la hoja de cálculo con los errores de lectura de sus celdas
+type TankImportRow struct {
+	Row    int // Número de fila en el archivo, contando la cabecera como la 1
+	Tank   *Tank
+	Errors []TankImportError
+}
+
+// AddError anota un problema de la fila
+func (r *TankImportRow) AddError(column, message string) {
+	r.Errors = append(r.Errors, TankImportError{Column: column, Message: message})
+}
+
+// TankImportRowResult es el resultado de importar una fila
+type TankImportRowResult struct {
+	Row    int               `json:"row"`
+	TankID string            `json:"tank_id,omitempty"`
+	Name   string            `json:"name"`
+	Status string            `json:"status"` // created, valid o invalid
+	Errors []TankImportError `json:"errors,omitempty"`
+}
+
+// TankImportResult es el informe de validación de una importación de tanques
+type TankImportResult struct {
+	DryRun  bool                   `json:"dry_run"`
+	Total   int                    `json:"total"`
+	Created int                    `json:"created"`
+	Valid   int                    `json:"valid"` // Filas válidas (creadas o, en una simulación, por crear)
+	Invalid int                    `json:"invalid"`
+	Rows    []*TankImportRowResult `json:"rows"`
+}
+
+// Add incorpora el resultado de una fila a los totales
+func (r *TankImportResult) Add(row *TankImportRowResult) {
+	r.Total++
+	switch row.Status {
+	case TankImportCreated:
+		r.Created++
+		r.Valid++
+	case TankImportValid:
+		r.Valid++
+	default:
+		r.Invalid++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// ValidateImportedTank revisa los datos de un tanque importado y devuelve sus problemas
+func ValidateImportedTank(tank *Tank) []TankImportError {
+	var problems []TankImportError
+
+	if tank.Name == "" {
+		problems = append(problems, TankImportError{Column: "name", Message: "El nombre es obligatorio"})
+	}
+	if tank.Capacity <= 0 {
+		problems = append(problems, TankImportError{Column: "capacity", Message: "La capacidad debe ser mayor que cero"})
+	}
+	if tank.CurrentLevel < 0 || (tank.Capacity > 0 && tank.CurrentLevel > tank.Capacity) {
+		problems = append(problems, TankImportError{Column: "current_level", Message: "El nivel actual debe estar entre cero y la capacidad"})
+	}
+	if tank.AlertThreshold < 0 || tank.AlertThreshold > 100 {
+		problems = append(problems, TankImportError{Column: "alert_threshold", Message: "El umbral de alerta debe estar entre 0 y 100"})
+	}
+	if tank.Location != nil && !tank.Location.IsValid() {
+		problems = append(problems, TankImportError{Message: "La ubicación no es válida"})
+	}
+	if !IsValidTimezone(tank.Timezone) {
+		problems = append(problems, TankImportError{Column: "timezone", Message: "La zona horaria no existe"})
+	}
+
+	return problems
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// TankState es la proyección del estado actual de un tanque: los valores de su medición más
+// reciente. Se mantiene al guardar mediciones para no consultarlos en cada lectura del tanque.
+type TankState struct {
+	TankID      string
+	Measured    bool // false si el tanque aún no tiene mediciones
+	Level       float64
+	Temperature float64
+	MeasuredAt  time.Time
+}
+
+// NewTankState crea la proyección a partir de la última medición del tanque, que puede ser nil
+func NewTankState(tankID string, lastMeasurement *Measurement) *TankState {
+	state := &TankState{TankID: tankID}
+	if lastMeasurement != nil {
+		state.Measured = true
+		state.Level = lastMeasurement.Level
+		state.Temperature = lastMeasurement.Temperature
+		state.MeasuredAt = lastMeasurement.Timestamp
+	}
+	return state
+}
+
+// NewerThan indica si la proyección refleja una medición posterior a la de other
+func (s *TankState) NewerThan(other *TankState) bool {
+	if other == nil || !other.Measured {
+		return s.Measured
+	}
+	return s.Measured && s.MeasuredAt.After(other.MeasuredAt)
+}
+
+// Project devuelve una copia del tanque con el nivel, la temperatura y el estado de su medición
+// más reciente. El tanque recibido no se modifica.
+func (s *TankState) Project(tank *Tank) *Tank {
+	projected := *tank
+	if !s.Measured {
+		return &projected
+	}
+
+	projected.CurrentLevel = s.Level
+	projected.Temperature = s.Temperature
+	// Una edición del tanque posterior a la última medición también cuenta como actualización
+	if s.MeasuredAt.After(projected.LastUpdated) {
+		projected.LastUpdated = s.MeasuredAt
+	}
+	projected.UpdateStatus()
+
+	return &projected
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Dirección de la tendencia a corto plazo del nivel de un tanque
+const (
+	TrendFilling  = "filling"
+	TrendDraining = "draining"
+	TrendStable   = "stable"
+)
+
+// TankTrendWindow es el periodo, hasta la última lectura del tanque, cuyas mediciones se usan
+// para calcular la tendencia
+const TankTrendWindow = 3 * time.Hour
+
+// tankTrendStableRate es el ritmo mínimo, en porcentaje de la capacidad por hora, que cuenta
+// como llenado o vaciado; por debajo, el ruido del sensor se lee como nivel estable
+const tankTrendStableRate = 0.5
+
+// TankTrend es la tendencia a corto plazo del nivel de un tanque, para que las interfaces
+// muestren una flecha sin consultar el historial
+type TankTrend struct {
+	Direction   string  `json:"direction"`     // filling, draining o stable
+	RatePerHour float64 `json:"rate_per_hour"` // Litros por hora, negativo al vaciarse; redondeado a un decimal
+	Samples     int     `json:"samples"`       // Lecturas usadas en el cálculo
+}
+
+// ComputeTankTrend calcula la tendencia del tanque con las mediciones de los TankTrendWindow
+// anteriores a la más reciente, en cualquier orden. El ritmo es la pendiente de la recta de
+// mínimos cuadrados, que atenúa el ruido de las lecturas sueltas. Devuelve nil si no hay al
+// menos dos lecturas en instantes distintos dentro del periodo.
+func ComputeTankTrend(tank *Tank, measurements []*Measurement) *TankTrend {
+	if len(measurements) < 2 {
+		return nil
+	}
+
+	newest := measurements[0].Timestamp
+	for _, m := range measurements {
+		if m.Timestamp.After(newest) {
+			newest = m.Timestamp
+		}
+	}
+	since := newest.Add(-TankTrendWindow)
+
+	var n, sumX, sumY, sumXY, sumXX float64
+	for _, m := range measurements {
+		if m.Timestamp.Before(since) {
+			continue
+		}
+		x := m.Timestamp.Sub(since).Hours()
+		n++
+		sumX += x
+		sumY += m.Level
+		sumXY += x * m.Level
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if n < 2 || denominator <= 0 {
+		return nil
+	}
+	rate := (n*sumXY - sumX*sumY) / denominator
+
+	trend := &TankTrend{
+		Direction:   TrendStable,
+		RatePerHour: math.Round(rate*10) / 10,
+		Samples:     int(n),
+	}
+	if tank.Capacity > 0 {
+		switch percentPerHour := rate / tank.Capacity * 100; {
+		case percentPerHour >= tankTrendStableRate:
+			trend.Direction = TrendFilling
+		case percentPerHour <= -tankTrendStableRate:
+			trend.Direction = TrendDraining
+		}
+	}
+	return trend
+}
@@ -0,0 +1,45 @@
+package domain
+
+// Tipos de alerta generados por la telemetría de los sensores
+const (
+	TelemetryAlertLowBattery = "low_battery"
+	TelemetryAlertWeakSignal = "weak_signal"
+)
+
+// TelemetryConfig contiene los umbrales de alerta de la telemetría de los sensores
+type TelemetryConfig struct {
+	LowBatteryVoltage float64 // Voltaje por debajo del cual se avisa de batería baja (0 lo desactiva)
+	WeakSignalRSSI    float64 // RSSI en dBm por debajo del cual se avisa de señal débil (0 lo desactiva)
+}
+
+// TelemetryAlert describe un umbral de telemetría que el sensor acaba de cruzar
+type TelemetryAlert struct {
+	Kind     string  // low_battery o weak_signal
+	SensorID string  // Sensor que reportó la telemetría
+	Value    float64 // Voltaje o RSSI reportado
+}
+
+// DetectTelemetryAlerts compara la telemetría de la lectura más reciente de un sensor con la de
+// su lectura anterior (nil si no la hay). Solo se genera una alerta al cruzar el umbral, no en
+// cada lectura mientras el sensor siga por debajo.
+func DetectTelemetryAlerts(previous, latest *Measurement, config TelemetryConfig) []*TelemetryAlert {
+	alerts := make([]*TelemetryAlert, 0)
+	sensorID := SensorIDForMeasurement(latest)
+
+	if config.LowBatteryVoltage > 0 && isBelow(latest.BatteryVoltage, config.LowBatteryVoltage) &&
+		(previous == nil || !isBelow(previous.BatteryVoltage, config.LowBatteryVoltage)) {
+		alerts = append(alerts, &TelemetryAlert{Kind: TelemetryAlertLowBattery, SensorID: sensorID, Value: *latest.BatteryVoltage})
+	}
+
+	if config.WeakSignalRSSI != 0 && isBelow(latest.SignalStrength, config.WeakSignalRSSI) &&
+		(previous == nil || !isBelow(previous.SignalStrength, config.WeakSignalRSSI)) {
+		alerts = append(alerts, &TelemetryAlert{Kind: TelemetryAlertWeakSignal, SensorID: sensorID, Value: *latest.SignalStrength})
+	}
+
+	return alerts
+}
+
+// isBelow indica si el valor reportado está por debajo del umbral; sin valor no hay alerta
+func isBelow(value *float64, threshold float64) bool {
+	return value != nil && *value < threshold
+}
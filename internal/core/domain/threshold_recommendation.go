@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultAlertLeadTime es la antelación predeterminada de la alerta crítica: el tiempo que
+// debe quedar de producto cuando salta, para reaccionar antes de que el tanque se vacíe
+const DefaultAlertLeadTime = 48 * time.Hour
+
+// ThresholdRecommendation propone el umbral de alerta y el punto de pedido de un tanque a
+// partir de su consumo real, con los datos usados para calcularlos
+type ThresholdRecommendation struct {
+	TankID                    string  `json:"tank_id"`
+	TankName                  string  `json:"tank_name"`
+	HistoryDays               float64 `json:"history_days"`                // Días de historial analizados
+	DailyConsumption          float64 `json:"daily_consumption"`           // Consumo medio en litros por día
+	PeakDailyConsumption      float64 `json:"peak_daily_consumption"`      // Percentil 95 del consumo de cada día
+	AlertLeadTimeHours        float64 `json:"alert_lead_time_hours"`       // Horas de producto que cubre el umbral recomendado
+	CurrentAlertThreshold     float64 `json:"current_alert_threshold"`     // Porcentaje de llenado
+	RecommendedAlertThreshold float64 `json:"recommended_alert_threshold"` // Porcentaje de llenado
+	SupplierLeadTimeDays      float64 `json:"supplier_lead_time_days"`     // Plazo de entrega considerado
+	CurrentReorderLevel       float64 `json:"current_reorder_level"`       // Litros; 0 si no tiene punto de pedido
+	RecommendedReorderLevel   float64 `json:"recommended_reorder_level"`   // Litros
+}
+
+// BuildThresholdRecommendation calcula la recomendación con las mediciones indicadas. El
+// umbral de alerta cubre alertLeadTime al consumo de los días de más consumo (percentil 95),
+// redondeado al porcentaje entero superior. El punto de pedido añade a ese nivel el consumo
+// medio durante el plazo de entrega del proveedor, de modo que el pedido llegue antes de la
+// alerta; si el tanque no tiene plazo configurado se usa alertLeadTime. Devuelve false si el
+// historial no muestra consumo o el tanque no tiene capacidad.
+func BuildThresholdRecommendation(tank *Tank, measurements []*Measurement, alertLeadTime time.Duration) (*ThresholdRecommendation, bool) {
+	daily := EstimateDailyConsumption(measurements)
+	if daily <= 0 || tank.Capacity <= 0 {
+		return nil, false
+	}
+
+	chronological := SortMeasurementsAscending(measurements)
+	oldest, newest := chronological[0], chronological[len(chronological)-1]
+
+	peak := math.Max(daily, percentile(dailyConsumptions(chronological, tank.TimeLocation()), 95))
+	leadDays := alertLeadTime.Hours() / 24
+
+	threshold := math.Ceil(peak * leadDays / tank.Capacity * 100)
+	threshold = math.Min(math.Max(threshold, 1), 100)
+
+	supplierLeadDays := tank.Reorder.LeadTimeDays
+	if supplierLeadDays <= 0 {
+		supplierLeadDays = leadDays
+	}
+	reorderLevel := math.Ceil(threshold/100*tank.Capacity + daily*supplierLeadDays)
+
+	return &ThresholdRecommendation{
+		TankID:                    tank.ID,
+		TankName:                  tank.Name,
+		HistoryDays:               newest.Timestamp.Sub(oldest.Timestamp).Hours() / 24,
+		DailyConsumption:          daily,
+		PeakDailyConsumption:      peak,
+		AlertLeadTimeHours:        alertLeadTime.Hours(),
+		CurrentAlertThreshold:     tank.AlertThreshold,
+		RecommendedAlertThreshold: threshold,
+		SupplierLeadTimeDays:      supplierLeadDays,
+		CurrentReorderLevel:       tank.Reorder.ReorderLevel,
+		RecommendedReorderLevel:   math.Min(reorderLevel, tank.Capacity),
+	}, true
+}
+
+// dailyConsumptions devuelve los litros consumidos cada día, de medianoche a medianoche en la
+// zona horaria del tanque, entre la primera y la última medición, ordenados de menor a mayor.
+// Cada descenso cuenta en el día de la medición que lo registra y los días sin descensos
+// cuentan como cero.
+func dailyConsumptions(chronological []*Measurement, location *time.Location) []float64 {
+	first := chronological[0].Timestamp
+	days := make([]float64, localDaysBetween(first, chronological[len(chronological)-1].Timestamp, location)+1)
+	for i := 1; i < len(chronological); i++ {
+		if drop := chronological[i-1].Level - chronological[i].Level; drop > 0 {
+			days[localDaysBetween(first, chronological[i].Timestamp, location)] += drop
+		}
+	}
+
+	sort.Float64s(days)
+	return days
+}
+
+// localDaysBetween devuelve los días del calendario local que separan from de to
+func localDaysBetween(from, to time.Time, location *time.Location) int {
+	fromYear, fromMonth, fromDay := from.In(location).Date()
+	toYear, toMonth, toDay := to.In(location).Date()
+	start := time.Date(fromYear, fromMonth, fromDay, 0, 0, 0, 0, time.UTC)
+	end := time.Date(toYear, toMonth, toDay, 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start) / (24 * time.Hour))
+}
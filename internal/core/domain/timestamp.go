@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTimestamp se devuelve cuando una marca de tiempo no tiene un formato reconocido
+var ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+// epochMillisThreshold separa los segundos de los milisegundos en las marcas numéricas: 1e11
+// segundos es el año 5138, mientras que 1e11 milisegundos es marzo de 1973
+const epochMillisThreshold = 1e11
+
+// timestampLayouts son las variantes de ISO 8601 que envían los equipos. Todas llevan la zona
+// horaria: sin ella no se sabe a qué instante corresponde la hora local del equipo.
+var timestampLayouts = []string{
+	time.RFC3339Nano,                      // 2024-05-01T10:00:00.5-05:00
+	"2006-01-02T15:04:05.999999999Z0700",  // 2024-05-01T10:00:00-0500
+	"2006-01-02 15:04:05.999999999Z07:00", // 2024-05-01 10:00:00-05:00
+	"2006-01-02 15:04:05.999999999Z0700",  // 2024-05-01 10:00:00-0500
+	"20060102T150405Z0700",                // 20240501T100000Z (formato básico)
+}
+
+// ParseTimestamp interpreta una marca de tiempo en RFC 3339 u otra variante de ISO 8601 con
+// zona horaria, o en segundos o milisegundos desde 1970 (se distinguen por su magnitud). El
+// resultado se normaliza a UTC.
+func ParseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("%w: empty value", ErrInvalidTimestamp)
+	}
+
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return EpochTimestamp(number)
+	}
+
+	for _, layout := range timestampLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTimestamp, value)
+}
+
+// EpochTimestamp convierte segundos (admite decimales) o milisegundos desde 1970 en UTC
+func EpochTimestamp(number float64) (time.Time, error) {
+	if math.IsNaN(number) || math.IsInf(number, 0) || number < 0 {
+		return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidTimestamp, number)
+	}
+	if number >= epochMillisThreshold {
+		return time.UnixMilli(int64(number)).UTC(), nil
+	}
+	whole, fraction := math.Modf(number)
+	return time.Unix(int64(whole), int64(fraction*1e9)).UTC(), nil
+}
+
+// ParseTimestampValue interpreta una marca de tiempo decodificada de JSON o YAML: texto o
+// número. nil devuelve el instante cero, que los servicios sustituyen por la hora de llegada.
+func ParseTimestampValue(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, nil
+	case string:
+		return ParseTimestamp(v)
+	case json.Number:
+		return ParseTimestamp(v.String())
+	case float64:
+		return EpochTimestamp(v)
+	case int:
+		return EpochTimestamp(float64(v))
+	case int64:
+		return EpochTimestamp(float64(v))
+	case uint64:
+		return EpochTimestamp(float64(v))
+	default:
+		return time.Time{}, fmt.Errorf("%w: unexpected %T", ErrInvalidTimestamp, value)
+	}
+}
+
+// UnmarshalJSON admite en timestamp los formatos de ParseTimestamp, como texto o como número
+func (m *Measurement) UnmarshalJSON(data []byte) error {
+	type plain Measurement
+	aux := struct {
+		*plain
+		Timestamp json.RawMessage `json:"timestamp"`
+	}{plain: (*plain)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	m.Timestamp = time.Time{}
+	if len(aux.Timestamp) == 0 || string(aux.Timestamp) == "null" {
+		return nil
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(aux.Timestamp)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	timestamp, err := ParseTimestampValue(value)
+	if err != nil {
+		return err
+	}
+	m.Timestamp = timestamp
+	return nil
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// UnassignedOrganization agrupa el uso que no se puede atribuir a ninguna organización: las
+// solicitudes anónimas o de usuarios sin organización y los tanques sin la etiqueta de organización
+const UnassignedOrganization = "unassigned"
+
+// usageMonthLayout es el formato de los meses de facturación
+const usageMonthLayout = "2006-01"
+
+// ErrInvalidUsageMonth se devuelve cuando el mes no tiene el formato AAAA-MM
+var ErrInvalidUsageMonth = errors.New("invalid usage month")
+
+// UsageCounter acumula el uso de una organización durante un mes
+type UsageCounter struct {
+	APICalls     int64
+	Measurements int64
+	Tanks        map[string]bool // Tanques que recibieron mediciones en el mes
+}
+
+// OrganizationUsage es el uso facturable de una organización en un mes
+type OrganizationUsage struct {
+	Organization string `json:"organization"`
+	Month        string `json:"month"`        // AAAA-MM
+	APICalls     int64  `json:"api_calls"`    // Solicitudes a /api
+	ActiveTanks  int    `json:"active_tanks"` // Tanques con al menos una medición en el mes
+	Measurements int64  `json:"measurements"` // Mediciones guardadas
+}
+
+// UsageMonth devuelve el mes de facturación (en UTC) del instante indicado
+func UsageMonth(t time.Time) string {
+	return t.UTC().Format(usageMonthLayout)
+}
+
+// ParseUsageMonth valida un mes con el formato AAAA-MM
+func ParseUsageMonth(value string) (string, error) {
+	month, err := time.Parse(usageMonthLayout, value)
+	if err != nil {
+		return "", ErrInvalidUsageMonth
+	}
+	return UsageMonth(month), nil
+}
+
+// OrganizationOf devuelve la organización del principal, o UnassignedOrganization si no la tiene
+func OrganizationOf(principal *Principal) string {
+	if principal == nil || principal.Organization == "" {
+		return UnassignedOrganization
+	}
+	return principal.Organization
+}
+
+// TankOrganization devuelve la organización indicada en la etiqueta organizationLabel del tanque,
+// o UnassignedOrganization si no la tiene
+func TankOrganization(tank *Tank, organizationLabel string) string {
+	if organization := tank.Labels[organizationLabel]; organization != "" {
+		return organization
+	}
+	return UnassignedOrganization
+}
+
+// BuildUsage convierte los contadores del mes en el uso de cada organización, ordenado por nombre
+func BuildUsage(month string, counters map[string]*UsageCounter) []*OrganizationUsage {
+	usage := make([]*OrganizationUsage, 0, len(counters))
+	for organization, counter := range counters {
+		usage = append(usage, &OrganizationUsage{
+			Organization: organization,
+			Month:        month,
+			APICalls:     counter.APICalls,
+			ActiveTanks:  len(counter.Tanks),
+			Measurements: counter.Measurements,
+		})
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Organization < usage[j].Organization
+	})
+	return usage
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Intenciones que entienden los asistentes de voz
+const (
+	VoiceIntentTankLevel  = "tank_level"  // Nivel de un tanque
+	VoiceIntentTankStatus = "tank_status" // Estado de un tanque
+	VoiceIntentLowTanks   = "low_tanks"   // Tanques con el nivel bajo
+	VoiceIntentSummary    = "summary"     // Resumen de todos los tanques
+)
+
+// IsValidVoiceIntent indica si el asistente de voz entiende la intención
+func IsValidVoiceIntent(intent string) bool {
+	switch intent {
+	case VoiceIntentTankLevel, VoiceIntentTankStatus, VoiceIntentLowTanks, VoiceIntentSummary:
+		return true
+	}
+	return false
+}
+
+// VoiceQuery es una pregunta de un asistente de voz (Alexa, Google Assistant, ...). La plataforma
+// puede resolver la intención y el tanque por su cuenta o enviar solo la frase del usuario.
+type VoiceQuery struct {
+	Intent string `json:"intent,omitempty"` // Vacía se deduce de Text
+	Tank   string `json:"tank,omitempty"`   // Nombre del tanque o de su líquido, tal como se dijo
+	Text   string `json:"text,omitempty"`   // Frase completa del usuario
+	Locale string `json:"locale,omitempty"` // Idioma de la respuesta (es-ES, en-US, ...)
+}
+
+// VoiceAnswer es la respuesta hablada a una pregunta
+type VoiceAnswer struct {
+	Intent     string   `json:"intent"`
+	Speech     string   `json:"speech"`
+	TankIDs    []string `json:"tank_ids,omitempty"` // Tanques de los que habla la respuesta
+	EndSession bool     `json:"end_session"`        // false si el asistente espera una aclaración
+
+	// Formato y argumentos de la respuesta, para traducirla al idioma del usuario
+	SpeechFormat string        `json:"-"`
+	SpeechArgs   []interface{} `json:"-"`
+}
+
+// NewVoiceAnswer crea una respuesta que cierra la conversación
+func NewVoiceAnswer(intent, format string, args ...interface{}) *VoiceAnswer {
+	return &VoiceAnswer{
+		Intent:       intent,
+		Speech:       fmt.Sprintf(format, args...),
+		EndSession:   true,
+		SpeechFormat: format,
+		SpeechArgs:   args,
+	}
+}
+
+// Palabras con las que se deduce la intención de una frase, en español y en inglés
+var (
+	voiceStatusWords = map[string]bool{"estado": true, "status": true, "bien": true, "ok": true, "okay": true}
+	voiceLowWords    = map[string]bool{
+		"bajo": true, "bajos": true, "baja": true, "bajas": true, "critico": true, "criticos": true,
+		"alerta": true, "alertas": true, "low": true, "critical": true, "alert": true, "alerts": true,
+		"empty": true, "vacio": true, "vacios": true,
+	}
+
+	// voiceIgnoredWords no distinguen un tanque de otro aunque formen parte de su nombre
+	voiceIgnoredWords = map[string]bool{
+		"tanque": true, "tanques": true, "tank": true, "tanks": true, "del": true, "los": true,
+		"las": true, "the": true, "nivel": true, "level": true, "estado": true, "status": true,
+	}
+)
+
+// DetectVoiceIntent deduce la intención de la frase del usuario. Si la frase nombra un tanque se
+// pregunta por su estado (si menciona estado, alertas o nivel bajo) o por su nivel; si no, por los
+// tanques con el nivel bajo o por el resumen de todos.
+func DetectVoiceIntent(text string, namesTank bool) string {
+	status, low := false, false
+	for _, word := range voiceWords(text) {
+		status = status || voiceStatusWords[word]
+		low = low || voiceLowWords[word]
+	}
+
+	switch {
+	case namesTank && (status || low):
+		return VoiceIntentTankStatus
+	case namesTank:
+		return VoiceIntentTankLevel
+	case low:
+		return VoiceIntentLowTanks
+	default:
+		return VoiceIntentSummary
+	}
+}
+
+// VoiceTankScore puntúa cuánto se parece lo dicho al tanque: suma las palabras de la frase que
+// coinciden con una palabra del nombre (peso 3), del líquido (2) o del sitio (1), sin contar las
+// palabras de menos de tres letras ni las que cualquier tanque podría tener ("tanque", "nivel").
+// Devuelve 0 si la frase no nombra el tanque.
+func VoiceTankScore(phrase string, tank *Tank) float64 {
+	fields := []SearchField{
+		{Text: tank.Name, Weight: 3},
+		{Text: tank.LiquidType, Weight: 2},
+		{Text: tank.SiteID, Weight: 1},
+	}
+
+	score := 0.0
+	for _, word := range voiceWords(phrase) {
+		if len(word) < 3 || voiceIgnoredWords[word] {
+			continue
+		}
+		best := 0.0
+		for _, field := range fields {
+			for _, candidate := range voiceWords(field.Text) {
+				if candidate == word && field.Weight > best {
+					best = field.Weight
+				}
+			}
+		}
+		score += best
+	}
+	return score
+}
+
+// voiceWords divide el texto en palabras normalizadas, sin puntuación
+func voiceWords(text string) []string {
+	return strings.FieldsFunc(NormalizeSearchText(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+}
@@ -2,6 +2,9 @@ package ports
 
 import (
 	"context"
+	"io"
+	"net/url"
+	"time"
 
 	"monitor-tanques/internal/core/domain"
 )
@@ -17,11 +20,49 @@ type TankRepository interface {
 
 // MeasurementRepository define el puerto para operaciones de persistencia de mediciones
 type MeasurementRepository interface {
+	// SaveMeasurement guarda la medición en su posición cronológica, aunque llegue después de
+	// otras más recientes. Si la lectura ya está guardada devuelve domain.ErrDuplicateMeasurement.
 	SaveMeasurement(ctx context.Context, measurement *domain.Measurement) error
+	// GetMeasurementsByTankID devuelve las mediciones por marca de tiempo, de la más reciente a la
+	// más antigua; a igual marca, la última guardada va primero
 	GetMeasurementsByTankID(ctx context.Context, tankID string, limit int) ([]*domain.Measurement, error)
+	// GetLastMeasurement devuelve la medición con la marca de tiempo más reciente, o nil si no hay
 	GetLastMeasurement(ctx context.Context, tankID string) (*domain.Measurement, error)
 }
 
+// MeasurementStreamer define el puerto para recorrer historiales largos de mediciones sin
+// cargarlos completos en memoria
+type MeasurementStreamer interface {
+	// StreamMeasurementsByTankID entrega las mediciones del tanque, de la más reciente a la más
+	// antigua, a medida que se leen. Se detiene en cuanto fn devuelve un error.
+	StreamMeasurementsByTankID(ctx context.Context, tankID string, limit int, fn func(*domain.Measurement) error) error
+}
+
+// FilteredMeasurementStreamer lo implementan los repositorios que evalúan los filtros de las
+// mediciones en la propia consulta (p. ej. traduciendo domain.Filter a un WHERE de SQL): limit
+// cuenta solo las mediciones que cumplen el filtro y el resto no llega al servicio
+type FilteredMeasurementStreamer interface {
+	StreamFilteredMeasurementsByTankID(ctx context.Context, tankID string, filter *domain.Filter, limit int, fn func(*domain.Measurement) error) error
+}
+
+// RepositoryInspector define el puerto para inspeccionar el contenido de la persistencia
+type RepositoryInspector interface {
+	// CountEntities devuelve cuántos elementos guarda cada colección
+	CountEntities(ctx context.Context) (map[string]int, error)
+}
+
+// MeasurementPurger define el puerto para eliminar las mediciones antiguas de un tanque
+type MeasurementPurger interface {
+	// DeleteMeasurementsBefore elimina las mediciones anteriores a before y devuelve cuántas eliminó
+	DeleteMeasurementsBefore(ctx context.Context, tankID string, before time.Time) (int, error)
+}
+
+// TankDataPurger define el puerto para eliminar de un repositorio todos los datos de un tanque
+type TankDataPurger interface {
+	// PurgeTankData elimina los datos del tanque y devuelve cuántos registros eliminó
+	PurgeTankData(ctx context.Context, tankID string) (int, error)
+}
+
 // TankService define el puerto para el servicio de tanques
 type TankService interface {
 	GetTank(ctx context.Context, id string) (*domain.Tank, error)
@@ -30,11 +71,824 @@ type TankService interface {
 	UpdateTank(ctx context.Context, tank *domain.Tank) error
 	DeleteTank(ctx context.Context, id string) error
 	MonitorTank(ctx context.Context, tankID string) error
+	MonitorAllTanks(ctx context.Context) error
+	// AddMeasurement guarda la medición y la aplica como la actual del tanque. Si ya estaba guardada
+	// devuelve domain.ErrDuplicateMeasurement, y si es anterior a la última la guarda solo en el
+	// historial y devuelve domain.ErrLateMeasurement: quien la envía debe tratarlos como aceptados.
 	AddMeasurement(ctx context.Context, measurement *domain.Measurement) error
+	// AddMeasurements guarda un lote de mediciones en una sola unidad de trabajo
+	AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error
+	// BackfillMeasurements importa mediciones históricas sin evaluar alertas ni reemplazar un
+	// nivel actual más reciente
+	BackfillMeasurements(ctx context.Context, tankID string, measurements []*domain.Measurement) (*domain.BackfillResult, error)
 	GetTankStatus(ctx context.Context, tankID string) (string, error)
 }
 
 // AlertNotifier define el puerto para enviar notificaciones/alertas
 type AlertNotifier interface {
-	SendAlert(ctx context.Context, tankID string, message string) error
+	// Notify entrega la alerta; el adaptador la formatea según su severidad y tipo de evento
+	Notify(ctx context.Context, alert *domain.Alert) error
+}
+
+// ReorderService define el puerto para las sugerencias de reabastecimiento
+type ReorderService interface {
+	GetReorderSuggestions(ctx context.Context, horizonDays int) ([]*domain.ReorderSuggestion, error)
+	// GetGroupCapacityPlan prevé los litros que hay que entregar a los tanques accesibles del
+	// grupo en los próximos horizonDays días
+	GetGroupCapacityPlan(ctx context.Context, groupID string, horizonDays int) (*domain.CapacityPlan, error)
+	// GetThresholdRecommendation propone el umbral de alerta y el punto de pedido de un tanque
+	// para que la alerta crítica deje alertLeadTime de margen
+	GetThresholdRecommendation(ctx context.Context, tankID string, alertLeadTime time.Duration) (*domain.ThresholdRecommendation, error)
+}
+
+// SupplierRepository define el puerto para operaciones de persistencia de proveedores
+type SupplierRepository interface {
+	GetSupplier(ctx context.Context, id string) (*domain.Supplier, error)
+	GetAllSuppliers(ctx context.Context) ([]*domain.Supplier, error)
+	SaveSupplier(ctx context.Context, supplier *domain.Supplier) error
+	UpdateSupplier(ctx context.Context, supplier *domain.Supplier) error
+	DeleteSupplier(ctx context.Context, id string) error
+}
+
+// DeliveryOrderRepository define el puerto para operaciones de persistencia de pedidos de entrega
+type DeliveryOrderRepository interface {
+	GetDeliveryOrder(ctx context.Context, id string) (*domain.DeliveryOrder, error)
+	GetDeliveryOrders(ctx context.Context, tankID string) ([]*domain.DeliveryOrder, error)
+	SaveDeliveryOrder(ctx context.Context, order *domain.DeliveryOrder) error
+	UpdateDeliveryOrder(ctx context.Context, order *domain.DeliveryOrder) error
+}
+
+// DeliveryService define el puerto para la gestión de proveedores y pedidos de entrega
+type DeliveryService interface {
+	GetSupplier(ctx context.Context, id string) (*domain.Supplier, error)
+	GetAllSuppliers(ctx context.Context) ([]*domain.Supplier, error)
+	CreateSupplier(ctx context.Context, supplier *domain.Supplier) error
+	UpdateSupplier(ctx context.Context, supplier *domain.Supplier) error
+	DeleteSupplier(ctx context.Context, id string) error
+	GetDeliveryOrder(ctx context.Context, id string) (*domain.DeliveryOrder, error)
+	GetDeliveryOrders(ctx context.Context, tankID string) ([]*domain.DeliveryOrder, error)
+	RequestDelivery(ctx context.Context, order *domain.DeliveryOrder) error
+	ScheduleDelivery(ctx context.Context, id string, scheduledFor time.Time) (*domain.DeliveryOrder, error)
+	ReceiveDelivery(ctx context.Context, id string, receivedVolume float64, receivedAt time.Time) (*domain.DeliveryOrder, error)
+	ReconcileDelivery(ctx context.Context, id string) (*domain.DeliveryReconciliation, error)
+}
+
+// StatusHistoryRepository define el puerto para persistir las transiciones de estado de los tanques
+type StatusHistoryRepository interface {
+	SaveStatusChange(ctx context.Context, change *domain.StatusChange) error
+	// GetStatusChanges devuelve todas las transiciones de un tanque ordenadas cronológicamente
+	GetStatusChanges(ctx context.Context, tankID string) ([]*domain.StatusChange, error)
+}
+
+// StatusHistoryRewriter define el puerto para reemplazar las transiciones de un tanque cuando se
+// reconstruyen a partir de sus mediciones
+type StatusHistoryRewriter interface {
+	// ReplaceStatusChanges sustituye las transiciones del tanque desde from (incluido) por changes
+	ReplaceStatusChanges(ctx context.Context, tankID string, from time.Time, changes []*domain.StatusChange) error
+}
+
+// StatusHistoryService define el puerto para consultar el historial de estados
+type StatusHistoryService interface {
+	GetStatusHistory(ctx context.Context, tankID string, from, to time.Time) (*domain.StatusHistory, error)
+}
+
+// TxRepositories agrupa los repositorios disponibles dentro de una unidad de trabajo
+type TxRepositories struct {
+	Tanks         TankRepository
+	Measurements  MeasurementRepository
+	StatusChanges StatusHistoryRepository
+	Outbox        OutboxRepository
+}
+
+// UnitOfWork define el puerto para ejecutar varias operaciones de persistencia de forma atómica.
+// Si fn devuelve un error, ninguna de las escrituras realizadas a través de repos se aplica.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context, repos TxRepositories) error) error
+}
+
+// TankStateStore define el puerto del modelo de lectura con el estado actual de cada tanque.
+// Las implementaciones deben ser seguras para uso concurrente.
+type TankStateStore interface {
+	// Get devuelve la proyección del tanque, o false si no está cargada o ha vencido
+	Get(tankID string) (*domain.TankState, bool)
+	// Put guarda la proyección salvo que la existente refleje una medición más reciente
+	Put(state *domain.TankState)
+	Remove(tankID string)
+}
+
+// CacheInvalidator define el puerto para descartar las respuestas en caché que dependen de un tanque
+type CacheInvalidator interface {
+	InvalidateTank(tankID string)
+}
+
+// Locker define el puerto para bloqueos distribuidos entre réplicas de la aplicación
+type Locker interface {
+	// TryAcquire intenta obtener el bloqueo durante ttl. Devuelve false si otra instancia lo posee.
+	TryAcquire(ctx context.Context, name string, ttl time.Duration) (bool, error)
+	// Release libera el bloqueo si pertenece a esta instancia
+	Release(ctx context.Context, name string) error
+}
+
+// NotificationChannelRepository define el puerto para persistir los canales de notificación
+type NotificationChannelRepository interface {
+	GetChannel(ctx context.Context, id string) (*domain.NotificationChannel, error)
+	GetAllChannels(ctx context.Context) ([]*domain.NotificationChannel, error)
+	SaveChannel(ctx context.Context, channel *domain.NotificationChannel) error
+	UpdateChannel(ctx context.Context, channel *domain.NotificationChannel) error
+	DeleteChannel(ctx context.Context, id string) error
+}
+
+// DigestService define el puerto para los resúmenes diarios de los canales de notificación
+type DigestService interface {
+	// GetDigest compone el resumen que recibiría ahora el canal, sin enviarlo
+	GetDigest(ctx context.Context, channelID string) (*domain.Digest, error)
+	// SendDigest envía ahora el resumen del canal
+	SendDigest(ctx context.Context, channelID string) (*domain.Digest, error)
+	// SendDueDigests envía el resumen de los canales a los que ya les corresponde
+	SendDueDigests(ctx context.Context) error
+}
+
+// AlertRuleService define el puerto para probar las reglas de alerta (los canales de
+// notificación) contra los datos históricos antes de activarlas
+type AlertRuleService interface {
+	// TestAlertRule indica cuándo habría alertado la regla en el periodo [from, to] y qué habría
+	// hecho el canal con cada alerta
+	TestAlertRule(ctx context.Context, ruleID string, from, to time.Time) (*domain.AlertRuleSimulation, error)
+}
+
+// SimulationService define el puerto para inyectar series de mediciones sintéticas en los
+// entornos de pruebas
+type SimulationService interface {
+	// SimulateTank genera y guarda una serie del perfil indicado que termina ahora
+	SimulateTank(ctx context.Context, tankID, profile string, duration, interval time.Duration) (*domain.SyntheticSeries, error)
+}
+
+// RepositoryStatsService define el puerto para el diagnóstico de la persistencia
+type RepositoryStatsService interface {
+	// GetStats cuenta las entidades, mide la ingesta reciente y resume las mediciones de cada tanque
+	GetStats(ctx context.Context) (*domain.RepositoryStats, error)
+}
+
+// AlertQueueRepository define el puerto para las alertas retenidas fuera de horario
+type AlertQueueRepository interface {
+	EnqueueAlert(ctx context.Context, alert *domain.QueuedAlert) error
+	GetQueuedAlerts(ctx context.Context, channelID string) ([]*domain.QueuedAlert, error)
+	DeleteQueuedAlert(ctx context.Context, id string) error
+}
+
+// ChannelSender define el puerto para entregar una alerta a través de un canal concreto
+type ChannelSender interface {
+	Send(ctx context.Context, channel *domain.NotificationChannel, alert *domain.Alert) error
+}
+
+// NotificationService define el puerto para gestionar los canales y sus horarios
+type NotificationService interface {
+	AlertNotifier
+	GetChannel(ctx context.Context, id string) (*domain.NotificationChannel, error)
+	GetAllChannels(ctx context.Context) ([]*domain.NotificationChannel, error)
+	CreateChannel(ctx context.Context, channel *domain.NotificationChannel) error
+	UpdateChannel(ctx context.Context, channel *domain.NotificationChannel) error
+	DeleteChannel(ctx context.Context, id string) error
+	// GetQueuedAlerts devuelve las alertas retenidas del canal que cumplen la expresión filter (ver
+	// domain.ParseFilter), o todas si está vacía
+	GetQueuedAlerts(ctx context.Context, channelID, filter string) ([]*domain.QueuedAlert, error)
+	FlushQueuedAlerts(ctx context.Context) error
+}
+
+// Authenticator define el puerto para validar credenciales y obtener la identidad del solicitante
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*domain.Principal, error)
+}
+
+// LoginGuard define el puerto para frenar los ataques de fuerza bruta contra la autenticación:
+// cuenta los intentos fallidos de cada cliente, lo bloquea temporalmente y audita los accesos
+type LoginGuard interface {
+	// Check devuelve cuánto falta para que termine el bloqueo del cliente, o 0 si puede intentarlo
+	Check(ctx context.Context, clientIP string) time.Duration
+	// RequiresCaptcha indica si el cliente debe superar una verificación CAPTCHA para iniciar sesión
+	RequiresCaptcha(ctx context.Context, clientIP string) bool
+	// VerifyCaptcha comprueba la respuesta CAPTCHA del cliente
+	VerifyCaptcha(ctx context.Context, clientIP, response string) error
+	// RecordFailure cuenta un intento fallido y bloquea al cliente si alcanza el máximo
+	RecordFailure(ctx context.Context, attempt domain.LoginAttempt) error
+	// RecordSuccess reinicia los fallos del cliente
+	RecordSuccess(ctx context.Context, attempt domain.LoginAttempt) error
+	// GetSecurityEvents devuelve los últimos eventos de seguridad, del más reciente al más antiguo
+	GetSecurityEvents(ctx context.Context, limit int) ([]*domain.SecurityEvent, error)
+}
+
+// SecurityEventRepository define el puerto para persistir el registro de auditoría de los accesos
+type SecurityEventRepository interface {
+	SaveSecurityEvent(ctx context.Context, event *domain.SecurityEvent) error
+	// GetSecurityEvents devuelve los últimos eventos, del más reciente al más antiguo (0 sin límite)
+	GetSecurityEvents(ctx context.Context, limit int) ([]*domain.SecurityEvent, error)
+}
+
+// CaptchaVerifier define el puerto para validar las respuestas CAPTCHA con el proveedor
+// (reCAPTCHA, hCaptcha, Turnstile, ...)
+type CaptchaVerifier interface {
+	VerifyCaptcha(ctx context.Context, response, remoteIP string) (bool, error)
+}
+
+// APITokenRepository define el puerto para persistir los tokens de la API
+type APITokenRepository interface {
+	SaveToken(ctx context.Context, token *domain.APIToken) error
+	// GetTokenByHash devuelve el token con el resumen indicado, o nil si no existe
+	GetTokenByHash(ctx context.Context, hash string) (*domain.APIToken, error)
+	// GetTokens devuelve todos los tokens ordenados por fecha de creación
+	GetTokens(ctx context.Context) ([]*domain.APIToken, error)
+	// DeleteToken elimina el token con el ID indicado y devuelve false si no existía
+	DeleteToken(ctx context.Context, id string) (bool, error)
+}
+
+// APITokenService define el puerto para emitir tokens de la API
+type APITokenService interface {
+	// CreateToken emite un token con el rol y los alcances indicados y devuelve su registro y el
+	// token en claro, que no vuelve a poder consultarse. Sin rol se usa el mínimo de los alcances.
+	CreateToken(ctx context.Context, name, role string, scopes []string) (*domain.APIToken, string, error)
+	GetTokens(ctx context.Context) ([]*domain.APIToken, error)
+	// RevokeToken elimina el token; deja de aceptarse de inmediato
+	RevokeToken(ctx context.Context, id string) error
+}
+
+// SessionRepository define el puerto para persistir las sesiones iniciadas en la aplicación
+type SessionRepository interface {
+	SaveSession(ctx context.Context, session *domain.Session) error
+	// GetSession devuelve la sesión con el ID indicado, o nil si no existe
+	GetSession(ctx context.Context, id string) (*domain.Session, error)
+	// GetSessions devuelve todas las sesiones ordenadas por fecha de creación
+	GetSessions(ctx context.Context) ([]*domain.Session, error)
+	// DeleteSessionsExpiredBefore elimina las sesiones que vencieron antes de before y devuelve
+	// cuántas eliminó
+	DeleteSessionsExpiredBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+// SessionService define el puerto para gestionar las sesiones y sus tokens de refresco
+type SessionService interface {
+	// CreateSession inicia una sesión para el principal autenticado y devuelve sus tokens
+	CreateSession(ctx context.Context, principal *domain.Principal, clientIP, userAgent string) (*domain.SessionTokens, error)
+	// Refresh rota el token de refresco y emite un nuevo token de acceso
+	Refresh(ctx context.Context, refreshToken string) (*domain.SessionTokens, error)
+	// Authenticate valida un token de acceso de una sesión y devuelve su principal
+	Authenticate(ctx context.Context, accessToken string) (*domain.Principal, error)
+	// GetSessions devuelve las sesiones que cumplen el filtro, de la más reciente a la más antigua
+	GetSessions(ctx context.Context, filter domain.SessionFilter) ([]*domain.Session, error)
+	RevokeSession(ctx context.Context, id string) error
+	// RevokeSubjectSessions revoca las sesiones activas del usuario y devuelve cuántas revocó
+	RevokeSubjectSessions(ctx context.Context, subject, reason string) (int, error)
+}
+
+// AccessGrantRepository define el puerto para persistir las concesiones de acceso por sitio o grupo
+type AccessGrantRepository interface {
+	SaveGrant(ctx context.Context, grant *domain.AccessGrant) error
+	DeleteGrant(ctx context.Context, id string) error
+	GetGrant(ctx context.Context, id string) (*domain.AccessGrant, error)
+	GetGrants(ctx context.Context, subject string) ([]*domain.AccessGrant, error)
+}
+
+// AccessService define el puerto para gestionar y evaluar el acceso a tanques concretos
+type AccessService interface {
+	GetGrants(ctx context.Context, subject string) ([]*domain.AccessGrant, error)
+	CreateGrant(ctx context.Context, grant *domain.AccessGrant) error
+	DeleteGrant(ctx context.Context, id string) error
+	// CanAccessTank indica si el principal del contexto puede ver y modificar el tanque
+	CanAccessTank(ctx context.Context, tank *domain.Tank) (bool, error)
+}
+
+// AttachmentRepository define el puerto para persistir los metadatos de los adjuntos
+type AttachmentRepository interface {
+	SaveAttachment(ctx context.Context, attachment *domain.Attachment) error
+	GetAttachment(ctx context.Context, id string) (*domain.Attachment, error)
+	GetAttachmentsByTankID(ctx context.Context, tankID string) ([]*domain.Attachment, error)
+	DeleteAttachment(ctx context.Context, id string) error
+}
+
+// FileStorage define el puerto para guardar el contenido de los archivos (disco local, S3, ...)
+type FileStorage interface {
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// AttachmentService define el puerto para gestionar las fotos y documentos de los tanques
+type AttachmentService interface {
+	UploadAttachment(ctx context.Context, attachment *domain.Attachment, content io.Reader) error
+	GetAttachments(ctx context.Context, tankID string) ([]*domain.Attachment, error)
+	GetAttachment(ctx context.Context, tankID, id string) (*domain.Attachment, error)
+	// OpenAttachment devuelve los metadatos y el contenido; quien llama debe cerrar el contenido
+	OpenAttachment(ctx context.Context, tankID, id string) (*domain.Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, tankID, id string) error
+}
+
+// MobileDeviceRepository define el puerto para persistir los dispositivos móviles registrados
+type MobileDeviceRepository interface {
+	SaveDevice(ctx context.Context, device *domain.MobileDevice) error
+	GetDevice(ctx context.Context, id string) (*domain.MobileDevice, error)
+	GetDevices(ctx context.Context, subject string) ([]*domain.MobileDevice, error)
+	DeleteDevice(ctx context.Context, id string) error
+}
+
+// PushSender define el puerto para enviar una notificación push a un dispositivo (FCM, APNs).
+// Devuelve domain.ErrDeviceUnregistered si el proveedor ya no reconoce el token.
+type PushSender interface {
+	Push(ctx context.Context, device *domain.MobileDevice, alert *domain.Alert) error
+}
+
+// MobileDeviceService define el puerto para gestionar los dispositivos de los técnicos
+type MobileDeviceService interface {
+	GetDevices(ctx context.Context, subject string) ([]*domain.MobileDevice, error)
+	RegisterDevice(ctx context.Context, device *domain.MobileDevice) error
+	UpdateLocation(ctx context.Context, id string, location domain.GeoLocation) (*domain.MobileDevice, error)
+	DeleteDevice(ctx context.Context, id string) error
+}
+
+// TankNoteRepository define el puerto para persistir las notas de los tanques
+type TankNoteRepository interface {
+	SaveNote(ctx context.Context, note *domain.TankNote) error
+	// GetNotes devuelve las notas del tanque en orden cronológico
+	GetNotes(ctx context.Context, tankID string) ([]*domain.TankNote, error)
+}
+
+// NoteService define el puerto para registrar observaciones y consultar la línea de tiempo de un tanque
+type NoteService interface {
+	AddNote(ctx context.Context, note *domain.TankNote) error
+	GetNotes(ctx context.Context, tankID string, from, to time.Time) ([]*domain.TankNote, error)
+	GetTimeline(ctx context.Context, tankID string, from, to time.Time) ([]*domain.TimelineEntry, error)
+}
+
+// SearchService define el puerto para la búsqueda de texto sobre tanques, sitios y notas
+type SearchService interface {
+	// Search devuelve como máximo limit resultados ordenados por relevancia
+	Search(ctx context.Context, query string, limit int) ([]*domain.SearchResult, error)
+}
+
+// VoiceService define el puerto para responder las preguntas de los asistentes de voz
+type VoiceService interface {
+	// Answer devuelve una respuesta breve, pensada para leerse en voz alta
+	Answer(ctx context.Context, query *domain.VoiceQuery) (*domain.VoiceAnswer, error)
+}
+
+// KPIService define el puerto para calcular los indicadores de gestión de los tanques
+type KPIService interface {
+	GetTankKPIs(ctx context.Context, tankID string, from, to time.Time) (*domain.TankKPIs, error)
+	// GetTankStats devuelve la distribución del llenado y la temperatura en el periodo
+	GetTankStats(ctx context.Context, tankID string, from, to time.Time) (*domain.TankLevelStats, error)
+}
+
+// AnomalyRepository define el puerto para persistir los eventos de anomalía
+type AnomalyRepository interface {
+	SaveAnomaly(ctx context.Context, anomaly *domain.Anomaly) error
+	// GetAnomalies devuelve las anomalías del tanque en orden cronológico
+	GetAnomalies(ctx context.Context, tankID string) ([]*domain.Anomaly, error)
+}
+
+// AnomalyService define el puerto para consultar las anomalías detectadas en un tanque
+type AnomalyService interface {
+	GetAnomalies(ctx context.Context, tankID string, from, to time.Time) ([]*domain.Anomaly, error)
+}
+
+// SensorHealthService define el puerto para evaluar la salud de los sensores de los tanques
+type SensorHealthService interface {
+	// GetSensorHealth devuelve los sensores evaluados, opcionalmente filtrados por categoría de salud
+	GetSensorHealth(ctx context.Context, health string) ([]*domain.SensorHealth, error)
+}
+
+// ClockSkewService define el puerto para consultar el desfase del reloj de los sensores
+type ClockSkewService interface {
+	// GetSensorClocks devuelve el desfase de los sensores accesibles; con drifting solo los desajustados
+	GetSensorClocks(ctx context.Context, drifting bool) ([]*domain.SensorClock, error)
+}
+
+// FieldDeviceRepository define el puerto para persistir los equipos de campo y su configuración
+type FieldDeviceRepository interface {
+	SaveFieldDevice(ctx context.Context, device *domain.FieldDevice) error
+	GetFieldDevice(ctx context.Context, id string) (*domain.FieldDevice, error)
+	GetAllFieldDevices(ctx context.Context) ([]*domain.FieldDevice, error)
+}
+
+// FieldDeviceService define el puerto para gestionar la configuración deseada y aplicada de los equipos de campo
+type FieldDeviceService interface {
+	GetFieldDevices(ctx context.Context) ([]*domain.FieldDevice, error)
+	GetFieldDevice(ctx context.Context, id string) (*domain.FieldDevice, error)
+	// SetDesiredConfig asigna una nueva configuración deseada al equipo, registrándolo si no existe
+	SetDesiredConfig(ctx context.Context, id, tankID string, config domain.DeviceConfig) (*domain.FieldDevice, error)
+	// PollConfig devuelve la configuración deseada y registra la consulta del equipo
+	PollConfig(ctx context.Context, id string) (*domain.DeviceConfig, error)
+	// ReportAppliedConfig registra la configuración que el equipo confirma haber aplicado
+	ReportAppliedConfig(ctx context.Context, id string, config domain.DeviceConfig) (*domain.FieldDevice, error)
+}
+
+// CommandPublisher define el puerto para publicar comandos de bajada hacia los equipos (MQTT, ...)
+type CommandPublisher interface {
+	PublishCommand(ctx context.Context, command *domain.DeviceCommand) error
+}
+
+// DeviceCommandRepository define el puerto para persistir el historial de comandos enviados
+type DeviceCommandRepository interface {
+	SaveCommand(ctx context.Context, command *domain.DeviceCommand) error
+	// GetCommands devuelve los comandos del equipo en orden cronológico
+	GetCommands(ctx context.Context, deviceID string) ([]*domain.DeviceCommand, error)
+}
+
+// DeviceCommandService define el puerto para enviar comandos a los equipos de campo
+type DeviceCommandService interface {
+	SendCommand(ctx context.Context, command *domain.DeviceCommand) error
+	GetCommands(ctx context.Context, deviceID string) ([]*domain.DeviceCommand, error)
+}
+
+// MeasurementService define el puerto para consultar y exportar el historial de mediciones
+type MeasurementService interface {
+	// GetMeasurements y StreamMeasurements devuelven las mediciones más recientes que cumplen la
+	// expresión filter (ver domain.ParseFilter), o todas si está vacía
+	GetMeasurements(ctx context.Context, tankID string, limit int, filter string) ([]*domain.Measurement, error)
+	StreamMeasurements(ctx context.Context, tankID string, limit int, filter string, fn func(*domain.Measurement) error) error
+	// GetSeries devuelve el historial del periodo remuestreado en pasos iguales, con los pasos sin
+	// mediciones completados según fill (ver domain.BuildMeasurementSeries)
+	GetSeries(ctx context.Context, tankID string, from, to time.Time, step time.Duration, fill string) (*domain.MeasurementSeries, error)
+}
+
+// TankPollService define el puerto para solicitar una lectura inmediata de un tanque
+type TankPollService interface {
+	// PollTank solicita la lectura a los equipos del tanque y devuelve la medición recibida
+	PollTank(ctx context.Context, tankID string) (*domain.Measurement, error)
+}
+
+// TankImportService define el puerto para dar de alta tanques en bloque desde una hoja de cálculo
+type TankImportService interface {
+	// ImportTanks valida las filas y crea los tanques de las válidas; con dryRun solo las valida
+	ImportTanks(ctx context.Context, rows []*domain.TankImportRow, dryRun bool) (*domain.TankImportResult, error)
+}
+
+// ProvisioningService define el puerto para reconciliar la instalación con un estado declarado
+type ProvisioningService interface {
+	// Provision crea o actualiza los tanques, sensores y reglas de alerta declarados
+	Provision(ctx context.Context, spec *domain.ProvisioningSpec) (*domain.ProvisioningResult, error)
+	// Plan devuelve los cambios que aplicaría Provision sin aplicarlos
+	Plan(ctx context.Context, spec *domain.ProvisioningSpec) (*domain.ProvisioningResult, error)
+}
+
+// EmailSender define el puerto para enviar correos de texto plano (informes periódicos)
+type EmailSender interface {
+	SendEmail(ctx context.Context, to []string, subject, body string) error
+}
+
+// SecretProvider define el puerto para obtener credenciales de un almacén de secretos (variables de
+// entorno, archivos montados, Vault, AWS Secrets Manager) en lugar de guardarlas en la configuración
+type SecretProvider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// DataQualityService define el puerto para el informe de calidad de los datos de los sensores
+type DataQualityService interface {
+	// GetReport evalúa los tanques accesibles que cumplen el selector en el periodo [from, to]
+	GetReport(ctx context.Context, from, to time.Time, selector domain.LabelSelector) (*domain.DataQualityReport, error)
+	// GetLatestReport devuelve el último informe programado, limitado a los tanques accesibles
+	// que cumplen el selector. Si todavía no se generó ninguno, lo genera.
+	GetLatestReport(ctx context.Context, selector domain.LabelSelector) (*domain.DataQualityReport, error)
+	// GenerateScheduledReport genera el informe del último periodo, lo conserva y lo envía a
+	// los destinatarios configurados
+	GenerateScheduledReport(ctx context.Context) error
+}
+
+// ShiftReportService define el puerto del informe de relevo de turno de los operadores
+type ShiftReportService interface {
+	// GetShiftReport resume las alertas, las entregas detectadas en [from, to] y los tanques
+	// accesibles que siguen en aviso o crítico
+	GetShiftReport(ctx context.Context, from, to time.Time) (*domain.ShiftReport, error)
+}
+
+// AlertMuteRepository define el puerto para persistir los silencios de alertas de los tanques
+type AlertMuteRepository interface {
+	// SaveMute guarda un silencio nuevo o reemplaza el existente con el mismo ID
+	SaveMute(ctx context.Context, mute *domain.AlertMute) error
+	// GetMutes devuelve los silencios del tanque en orden cronológico
+	GetMutes(ctx context.Context, tankID string) ([]*domain.AlertMute, error)
+}
+
+// AlertMuteService define el puerto para silenciar temporalmente las alertas de un tanque
+type AlertMuteService interface {
+	// MuteAlerts silencia las alertas del tanque durante duration, reemplazando el silencio vigente
+	MuteAlerts(ctx context.Context, tankID string, duration time.Duration, reason string) (*domain.AlertMute, error)
+	// UnmuteAlerts levanta el silencio vigente antes de que expire
+	UnmuteAlerts(ctx context.Context, tankID string) (*domain.AlertMute, error)
+	// GetMutes devuelve el historial de silencios del tanque
+	GetMutes(ctx context.Context, tankID string) ([]*domain.AlertMute, error)
+}
+
+// TankChangeRepository define el puerto para persistir las solicitudes de cambio de los tanques
+type TankChangeRepository interface {
+	// SaveTankChange guarda una solicitud nueva o reemplaza la existente con el mismo ID
+	SaveTankChange(ctx context.Context, change *domain.TankChangeRequest) error
+	// GetTankChange devuelve la solicitud con el ID indicado, o nil si no existe
+	GetTankChange(ctx context.Context, id string) (*domain.TankChangeRequest, error)
+	// GetTankChanges devuelve las solicitudes que cumplen el filtro, de la más reciente a la más antigua
+	GetTankChanges(ctx context.Context, filter domain.TankChangeFilter) ([]*domain.TankChangeRequest, error)
+}
+
+// TankChangeService define el puerto para revisar los cambios pendientes de aprobación de los
+// tanques de los sitios regulados
+type TankChangeService interface {
+	// GetTankChanges devuelve las solicitudes de los tanques accesibles que cumplen el filtro
+	GetTankChanges(ctx context.Context, filter domain.TankChangeFilter) ([]*domain.TankChangeRequest, error)
+	// GetTankChange devuelve una solicitud de un tanque accesible
+	GetTankChange(ctx context.Context, id string) (*domain.TankChangeRequest, error)
+	// ApproveTankChange aplica al tanque una solicitud pendiente
+	ApproveTankChange(ctx context.Context, id, comment string) (*domain.TankChangeRequest, error)
+	// RejectTankChange descarta una solicitud pendiente
+	RejectTankChange(ctx context.Context, id, reason string) (*domain.TankChangeRequest, error)
+}
+
+// CompartmentService define el puerto para consultar los compartimentos de un tanque
+type CompartmentService interface {
+	// GetCompartments devuelve el resumen de los compartimentos accesibles del tanque
+	GetCompartments(ctx context.Context, tankID string) (*domain.CompartmentRollup, error)
+}
+
+// MobileOverviewService define el puerto para la pantalla de inicio de la aplicación móvil
+type MobileOverviewService interface {
+	// GetOverview devuelve el resumen compacto de los tanques accesibles
+	GetOverview(ctx context.Context) (*domain.MobileOverview, error)
+}
+
+// SyncQueueRepository define el puerto para la cola de mediciones de una pasarela edge pendientes
+// de reenviar a la instancia central
+type SyncQueueRepository interface {
+	// EnqueueMeasurements añade las mediciones al final de la cola
+	EnqueueMeasurements(ctx context.Context, measurements []*domain.Measurement) error
+	// GetQueuedMeasurements devuelve hasta limit mediciones, de la más antigua a la más reciente
+	GetQueuedMeasurements(ctx context.Context, limit int) ([]*domain.Measurement, error)
+	// DeleteQueuedMeasurements quita de la cola las mediciones con los IDs indicados
+	DeleteQueuedMeasurements(ctx context.Context, ids []string) error
+	// CountQueuedMeasurements devuelve el número de mediciones en la cola
+	CountQueuedMeasurements(ctx context.Context) (int, error)
+}
+
+// TankSyncRepository define el puerto para el registro de sincronización de la configuración de
+// los tanques y los cursores e idempotencia del protocolo
+type TankSyncRepository interface {
+	// SaveTankSyncState guarda el estado del tanque con la siguiente secuencia del registro, que
+	// asigna a state.Sequence
+	SaveTankSyncState(ctx context.Context, state *domain.TankSyncState) error
+	// GetTankSyncState devuelve el estado del tanque, o nil si no está en el registro
+	GetTankSyncState(ctx context.Context, tankID string) (*domain.TankSyncState, error)
+	// GetTankSyncStates devuelve hasta limit estados con secuencia mayor que cursor, en orden de
+	// secuencia; cada tanque aparece solo con su último cambio
+	GetTankSyncStates(ctx context.Context, cursor int64, limit int) ([]*domain.TankSyncState, error)
+	// GetSyncCursor devuelve el cursor guardado con el nombre indicado, o 0
+	GetSyncCursor(ctx context.Context, name string) (int64, error)
+	// SaveSyncCursor guarda el cursor con el nombre indicado
+	SaveSyncCursor(ctx context.Context, name string, cursor int64) error
+	// GetSyncResult devuelve la respuesta ya dada al lote de la pasarela, o nil
+	GetSyncResult(ctx context.Context, gatewayID, batchID string) (*domain.SyncResult, error)
+	// SaveSyncResult guarda la respuesta a un lote; solo se conservan las más recientes
+	SaveSyncResult(ctx context.Context, result *domain.SyncResult) error
+}
+
+// TankSyncService define el puerto de la API de sincronización de la instancia central
+type TankSyncService interface {
+	// Push aplica un lote de una pasarela: los cambios de configuración de sus tanques, combinados
+	// con los locales, y sus mediciones. Un lote repetido devuelve la respuesta ya dada.
+	Push(ctx context.Context, batch *domain.SyncBatch) (*domain.SyncResult, error)
+	// Pull devuelve hasta limit cambios de configuración posteriores al cursor
+	Pull(ctx context.Context, cursor int64, limit int) (*domain.SyncPullResult, error)
+}
+
+// SyncClient define el puerto para que una pasarela edge se sincronice con la instancia central
+type SyncClient interface {
+	// Push envía el lote; un error indica que la instancia central no lo confirmó
+	Push(ctx context.Context, batch *domain.SyncBatch) (*domain.SyncResult, error)
+	// Pull obtiene hasta limit cambios de configuración posteriores al cursor
+	Pull(ctx context.Context, cursor int64, limit int) (*domain.SyncPullResult, error)
+}
+
+// EdgeSyncService define el puerto para la sincronización de una pasarela edge
+type EdgeSyncService interface {
+	// Forward envía en lotes las mediciones pendientes y los cambios locales de configuración, y
+	// aplica después los cambios de la instancia central
+	Forward(ctx context.Context) error
+	// GetStatus devuelve el estado del reenvío
+	GetStatus(ctx context.Context) (*domain.EdgeSyncStatus, error)
+}
+
+// InventoryExporter define el puerto para entregar el inventario de los tanques a un ERP
+type InventoryExporter interface {
+	// Connector devuelve la configuración de la conexión (nombre, reintentos y tanques incluidos)
+	Connector() domain.InventoryConnector
+	// Export entrega el lote y devuelve el acuse del ERP. Los rechazos definitivos envuelven
+	// domain.ErrInventoryRejected.
+	Export(ctx context.Context, batch *domain.InventoryBatch) (string, error)
+}
+
+// InventorySyncService define el puerto para sincronizar las existencias de los tanques con los ERP
+type InventorySyncService interface {
+	// Sync envía el inventario actual por la conexión indicada, con reintentos, y devuelve el resultado
+	Sync(ctx context.Context, connector string) (*domain.InventorySync, error)
+	// GetSyncs devuelve las últimas sincronizaciones de la conexión, de la más reciente a la más antigua
+	GetSyncs(ctx context.Context, connector string) ([]*domain.InventorySync, error)
+}
+
+// SavedViewRepository define el puerto para persistir las vistas guardadas de los usuarios
+type SavedViewRepository interface {
+	SaveView(ctx context.Context, view *domain.SavedView) error
+	// GetView devuelve la vista, o nil si no existe
+	GetView(ctx context.Context, id string) (*domain.SavedView, error)
+	// GetViews devuelve las vistas del usuario ordenadas por nombre
+	GetViews(ctx context.Context, subject string) ([]*domain.SavedView, error)
+	DeleteView(ctx context.Context, id string) error
+}
+
+// SavedViewService define el puerto para gestionar las vistas guardadas del usuario autenticado
+type SavedViewService interface {
+	GetViews(ctx context.Context) ([]*domain.SavedView, error)
+	GetView(ctx context.Context, id string) (*domain.SavedView, error)
+	CreateView(ctx context.Context, view *domain.SavedView) error
+	UpdateView(ctx context.Context, view *domain.SavedView) error
+	DeleteView(ctx context.Context, id string) error
+}
+
+// DataLakeWriter define el puerto para escribir las mediciones exportadas en el data lake
+type DataLakeWriter interface {
+	// GetCheckpoint devuelve el punto de control de las exportaciones, vacío si nunca se exportó
+	GetCheckpoint(ctx context.Context) (*domain.DataLakeCheckpoint, error)
+	SaveCheckpoint(ctx context.Context, checkpoint *domain.DataLakeCheckpoint) error
+	// WriteMeasurements escribe un archivo con las mediciones del día (partición) y devuelve su
+	// clave. Un archivo con el mismo día y nombre se reemplaza.
+	WriteMeasurements(ctx context.Context, day time.Time, name string, measurements []*domain.Measurement) (string, error)
+}
+
+// DataLakeExportService define el puerto para exportar las mediciones al data lake
+type DataLakeExportService interface {
+	// Export escribe las mediciones nuevas desde el último punto de control y lo avanza
+	Export(ctx context.Context) (*domain.DataLakeExport, error)
+	GetCheckpoint(ctx context.Context) (*domain.DataLakeCheckpoint, error)
+}
+
+// UsageRepository define el puerto para persistir los contadores de uso por organización y mes
+type UsageRepository interface {
+	// AddAPICalls suma solicitudes a la organización en el mes (AAAA-MM)
+	AddAPICalls(ctx context.Context, month, organization string, calls int64) error
+	// AddMeasurements suma mediciones del tanque a la organización en el mes y lo marca como activo
+	AddMeasurements(ctx context.Context, month, organization, tankID string, measurements int64) error
+	// GetUsage devuelve el uso de cada organización en el mes
+	GetUsage(ctx context.Context, month string) ([]*domain.OrganizationUsage, error)
+}
+
+// UsageService define el puerto para medir el uso de cada organización con fines de facturación
+type UsageService interface {
+	// RecordAPICall atribuye una solicitud a la organización del usuario autenticado
+	RecordAPICall(ctx context.Context) error
+	// GetUsage devuelve el uso de cada organización en el mes (AAAA-MM); vacío es el mes en curso
+	GetUsage(ctx context.Context, month string) ([]*domain.OrganizationUsage, error)
+}
+
+// StatusShareRepository define el puerto para persistir las páginas de estado públicas
+type StatusShareRepository interface {
+	// SaveShare guarda una página nueva o reemplaza la existente con el mismo ID
+	SaveShare(ctx context.Context, share *domain.StatusShare) error
+	// GetShare devuelve la página, o nil si no existe
+	GetShare(ctx context.Context, id string) (*domain.StatusShare, error)
+	// GetShareByTokenHash devuelve la página con el hash de token indicado, o nil si no existe
+	GetShareByTokenHash(ctx context.Context, tokenHash string) (*domain.StatusShare, error)
+	// GetShares devuelve todas las páginas, de la más antigua a la más reciente
+	GetShares(ctx context.Context) ([]*domain.StatusShare, error)
+}
+
+// StatusShareService define el puerto para publicar el nivel de algunos tanques sin autenticación
+type StatusShareService interface {
+	// CreateShare crea la página y devuelve su token, que no vuelve a mostrarse
+	CreateShare(ctx context.Context, share *domain.StatusShare) (*domain.StatusShare, error)
+	// GetShares devuelve todas las páginas, incluidas las revocadas y las vencidas
+	GetShares(ctx context.Context) ([]*domain.StatusShare, error)
+	// RevokeShare desactiva la página; su token deja de funcionar
+	RevokeShare(ctx context.Context, id string) (*domain.StatusShare, error)
+	// GetPublicStatus devuelve el nivel de los tanques de la página del token
+	GetPublicStatus(ctx context.Context, token string) (*domain.PublicStatusPage, error)
+}
+
+// LiveEventPublisher define el puerto para difundir eventos a los clientes conectados en tiempo real
+type LiveEventPublisher interface {
+	// Publish entrega el evento a los clientes suscritos a alguno de sus temas, sin bloquear
+	Publish(event *domain.LiveEvent)
+	// HasSubscribers indica si hay clientes conectados, para no preparar eventos que nadie recibe
+	HasSubscribers() bool
+}
+
+// URLSigner define el puerto para firmar enlaces temporales y verificar su firma
+type URLSigner interface {
+	// Sign añade a la URL la caducidad, la identidad del principal y la firma
+	Sign(target *url.URL, principal *domain.Principal, expiresAt time.Time) *url.URL
+	// Verify comprueba la firma y la caducidad de la URL y devuelve el principal que la firmó
+	Verify(target *url.URL, now time.Time) (*domain.Principal, error)
+}
+
+// SignedURLService define el puerto para generar enlaces de descarga temporales
+type SignedURLService interface {
+	// SignURL firma una ruta de exportación para el principal de la solicitud; ttl 0 usa la
+	// vigencia por defecto
+	SignURL(ctx context.Context, target string, ttl time.Duration) (*domain.SignedURL, error)
+}
+
+// PurgeService define el puerto para las purgas administrativas de datos, que se ejecutan en
+// segundo plano
+type PurgeService interface {
+	// StartPurge valida la solicitud y lanza la purga; devuelve el trabajo para seguir su progreso
+	StartPurge(ctx context.Context, request domain.PurgeRequest) (*domain.PurgeJob, error)
+	GetPurgeJobs(ctx context.Context) ([]*domain.PurgeJob, error)
+	GetPurgeJob(ctx context.Context, id string) (*domain.PurgeJob, error)
+}
+
+// RecomputeService define el puerto para reconstruir los datos derivados de las mediciones
+type RecomputeService interface {
+	// Recompute reconstruye los datos del tanque, o de todos si tankID está vacío, desde from.
+	// Sin from, se reconstruyen desde la primera medición de cada tanque.
+	Recompute(ctx context.Context, tankID string, from time.Time) ([]*domain.RecomputeResult, error)
+}
+
+// OutboxRepository define el puerto de la bandeja de salida de eventos. Dentro de una unidad de
+// trabajo, los eventos añadidos se confirman junto con el resto de escrituras.
+type OutboxRepository interface {
+	AddEvent(ctx context.Context, event *domain.OutboxEvent) error
+	// GetDueEvents devuelve hasta limit eventos pendientes cuyo próximo intento ya venció, del
+	// más antiguo al más reciente
+	GetDueEvents(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxEvent, error)
+	UpdateEvent(ctx context.Context, event *domain.OutboxEvent) error
+	// DeleteDeliveredBefore elimina los eventos entregados antes de before y devuelve cuántos eran
+	DeleteDeliveredBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+// OutboxService define el puerto de la entrega de los eventos de la bandeja de salida
+type OutboxService interface {
+	// RelayEvents entrega los eventos pendientes; los que fallan se reintentan en otra pasada
+	RelayEvents(ctx context.Context) error
+}
+
+// LiquidPolicyRepository define el puerto para persistir las políticas de seguridad por tipo de
+// líquido, indexadas por domain.LiquidPolicyKey
+type LiquidPolicyRepository interface {
+	// SavePolicy guarda una política nueva o reemplaza la del mismo tipo de líquido
+	SavePolicy(ctx context.Context, policy *domain.LiquidPolicy) error
+	// GetPolicy devuelve la política del tipo de líquido, o nil si no tiene
+	GetPolicy(ctx context.Context, liquidType string) (*domain.LiquidPolicy, error)
+	GetPolicies(ctx context.Context) ([]*domain.LiquidPolicy, error)
+	DeletePolicy(ctx context.Context, liquidType string) error
+}
+
+// LiquidPolicyService define el puerto para gestionar las políticas de seguridad por tipo de líquido
+type LiquidPolicyService interface {
+	GetPolicies(ctx context.Context) ([]*domain.LiquidPolicy, error)
+	GetPolicy(ctx context.Context, liquidType string) (*domain.LiquidPolicy, error)
+	// SavePolicy crea o reemplaza la política del tipo de líquido. Los tanques existentes la
+	// cumplen a partir del siguiente cambio de sus umbrales.
+	SavePolicy(ctx context.Context, policy *domain.LiquidPolicy) (*domain.LiquidPolicy, error)
+	DeletePolicy(ctx context.Context, liquidType string) error
+}
+
+// RuntimeSettingsSource define el puerto de lectura de la configuración recargable
+type RuntimeSettingsSource interface {
+	// Load lee la configuración; los valores que la fuente no indica se toman de base
+	Load(ctx context.Context, base domain.RuntimeSettings) (*domain.RuntimeSettings, error)
+}
+
+// RuntimeSettingsApplier define el puerto que aplica la configuración recargable a los
+// componentes en marcha (logger, notificador predeterminado y valores por defecto)
+type RuntimeSettingsApplier interface {
+	ApplyRuntimeSettings(ctx context.Context, settings *domain.RuntimeSettings) error
+}
+
+// RuntimeConfigService define el puerto de la recarga de la configuración sin reiniciar el servidor
+type RuntimeConfigService interface {
+	// GetSettings devuelve la configuración recargable en vigor
+	GetSettings(ctx context.Context) (*domain.RuntimeSettings, error)
+	// Reload vuelve a leer la configuración y la aplica; si no es válida se conserva la anterior
+	Reload(ctx context.Context) (*domain.RuntimeSettings, error)
+	// SetLogLevel cambia solo el nivel de logging hasta la siguiente recarga
+	SetLogLevel(ctx context.Context, level string) (*domain.RuntimeSettings, error)
+}
+
+// StatusWebhookSender define el puerto para avisar al webhook propio de un tanque de sus cambios
+// de estado
+type StatusWebhookSender interface {
+	// SendStatusWebhook entrega el evento en segundo plano; los fallos no se reintentan
+	SendStatusWebhook(ctx context.Context, url string, event *domain.StatusWebhookEvent)
+}
+
+// TankStatePublisher define el puerto para publicar el estado de los tanques en sistemas externos
+// de automatización (Home Assistant, ...)
+type TankStatePublisher interface {
+	// PublishTank publica el estado actual del tanque en segundo plano; los fallos no se reintentan
+	PublishTank(ctx context.Context, tank *domain.Tank)
+	// RemoveTank retira el tanque eliminado
+	RemoveTank(ctx context.Context, tankID string)
+}
+
+// AlertEvidenceRepository define el puerto para persistir la evidencia de las alertas críticas
+type AlertEvidenceRepository interface {
+	SaveEvidence(ctx context.Context, evidence *domain.AlertEvidence) error
+	// GetEvidence devuelve la evidencia de la alerta, o nil si no tiene
+	GetEvidence(ctx context.Context, alertID string) (*domain.AlertEvidence, error)
+}
+
+// AlertEvidenceService define el puerto para consultar la evidencia de las alertas críticas
+type AlertEvidenceService interface {
+	GetEvidence(ctx context.Context, alertID string) (*domain.AlertEvidence, error)
 }
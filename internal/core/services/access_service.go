@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver el servicio de acceso
+var (
+	ErrForbidden           = errors.New("access to the resource is forbidden")
+	ErrAccessGrantNotFound = errors.New("access grant not found")
+	ErrInvalidAccessGrant  = errors.New("invalid access grant data")
+)
+
+// AccessServiceImpl implementa la interfaz AccessService
+type AccessServiceImpl struct {
+	grantRepo ports.AccessGrantRepository
+}
+
+// NewAccessService crea una nueva instancia del servicio de acceso
+func NewAccessService(grantRepo ports.AccessGrantRepository) ports.AccessService {
+	return &AccessServiceImpl{
+		grantRepo: grantRepo,
+	}
+}
+
+// GetGrants obtiene las concesiones de un usuario, o todas si subject está vacío
+func (s *AccessServiceImpl) GetGrants(ctx context.Context, subject string) ([]*domain.AccessGrant, error) {
+	return s.grantRepo.GetGrants(ctx, subject)
+}
+
+// CreateGrant concede a un usuario acceso a un sitio o grupo de tanques
+func (s *AccessServiceImpl) CreateGrant(ctx context.Context, grant *domain.AccessGrant) error {
+	if grant == nil || grant.ID == "" || grant.Subject == "" || grant.ScopeID == "" {
+		return ErrInvalidAccessGrant
+	}
+
+	if grant.ScopeType != domain.AccessScopeSite && grant.ScopeType != domain.AccessScopeGroup {
+		return ErrInvalidAccessGrant
+	}
+
+	grant.CreatedAt = time.Now()
+
+	return s.grantRepo.SaveGrant(ctx, grant)
+}
+
+// DeleteGrant revoca una concesión
+func (s *AccessServiceImpl) DeleteGrant(ctx context.Context, id string) error {
+	grant, err := s.grantRepo.GetGrant(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if grant == nil {
+		return ErrAccessGrantNotFound
+	}
+
+	return s.grantRepo.DeleteGrant(ctx, id)
+}
+
+// CanAccessTank indica si el principal del contexto tiene acceso al tanque. Las solicitudes sin
+// principal (tareas internas o autenticación deshabilitada), los administradores y los usuarios
+// sin concesiones conservan el acceso global de su rol. Un principal limitado a un tanque solo
+// accede a ese tanque.
+func (s *AccessServiceImpl) CanAccessTank(ctx context.Context, tank *domain.Tank) (bool, error) {
+	principal := domain.PrincipalFromContext(ctx)
+	if principal != nil && principal.TankID != "" {
+		return tank.ID == principal.TankID, nil
+	}
+	if principal == nil || principal.HasRole(domain.RoleAdmin) {
+		return true, nil
+	}
+
+	grants, err := s.grantRepo.GetGrants(ctx, principal.Subject)
+	if err != nil {
+		return false, err
+	}
+
+	if len(grants) == 0 {
+		return true, nil
+	}
+
+	for _, grant := range grants {
+		if grant.Covers(tank) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrAlertEvidenceNotFound se devuelve cuando la alerta no tiene evidencia o el usuario no tiene
+// acceso a su tanque
+var ErrAlertEvidenceNotFound = errors.New("alert evidence not found")
+
+// AlertEvidenceServiceImpl implementa la interfaz AlertEvidenceService
+type AlertEvidenceServiceImpl struct {
+	evidenceRepo  ports.AlertEvidenceRepository
+	accessService ports.AccessService
+}
+
+// NewAlertEvidenceService crea una nueva instancia del servicio de evidencias de alertas
+func NewAlertEvidenceService(evidenceRepo ports.AlertEvidenceRepository, accessService ports.AccessService) ports.AlertEvidenceService {
+	return &AlertEvidenceServiceImpl{
+		evidenceRepo:  evidenceRepo,
+		accessService: accessService,
+	}
+}
+
+// GetEvidence devuelve la evidencia de la alerta. El acceso se comprueba con el tanque guardado
+// en la evidencia, de modo que sigue disponible aunque el tanque se haya eliminado después.
+func (s *AlertEvidenceServiceImpl) GetEvidence(ctx context.Context, alertID string) (*domain.AlertEvidence, error) {
+	evidence, err := s.evidenceRepo.GetEvidence(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if evidence == nil {
+		return nil, ErrAlertEvidenceNotFound
+	}
+
+	allowed, err := s.accessService.CanAccessTank(ctx, evidence.Tank)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrAlertEvidenceNotFound
+	}
+	return evidence, nil
+}
+
+// EvidenceAlertNotifier decora un AlertNotifier capturando la evidencia de cada alerta crítica
+// antes de entregarla: las mediciones de las últimas 24 horas, la configuración del tanque y sus
+// notas recientes. La alerta recibe un ID con el que los canales pueden pedir la evidencia.
+type EvidenceAlertNotifier struct {
+	next            ports.AlertNotifier
+	tankRepo        ports.TankRepository
+	measurementRepo ports.MeasurementRepository
+	noteRepo        ports.TankNoteRepository
+	evidenceRepo    ports.AlertEvidenceRepository
+}
+
+// NewEvidenceAlertNotifier crea un notificador que guarda la evidencia de las alertas críticas
+func NewEvidenceAlertNotifier(next ports.AlertNotifier, tankRepo ports.TankRepository, measurementRepo ports.MeasurementRepository, noteRepo ports.TankNoteRepository, evidenceRepo ports.AlertEvidenceRepository) *EvidenceAlertNotifier {
+	return &EvidenceAlertNotifier{
+		next:            next,
+		tankRepo:        tankRepo,
+		measurementRepo: measurementRepo,
+		noteRepo:        noteRepo,
+		evidenceRepo:    evidenceRepo,
+	}
+}
+
+// Notify captura la evidencia de las alertas críticas y entrega la alerta. Si la evidencia no se
+// puede capturar la alerta se entrega igualmente, sin ID: avisar es más importante que documentar.
+func (n *EvidenceAlertNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	if alert.Severity == domain.AlertSeverityCritical && alert.ID == "" && alert.TankID != "" {
+		alert.ID = uuid.New().String()
+		if err := n.capture(ctx, alert); err != nil {
+			alert.ID = ""
+		}
+	}
+
+	return n.next.Notify(ctx, alert)
+}
+
+// capture compone y guarda la evidencia de la alerta
+func (n *EvidenceAlertNotifier) capture(ctx context.Context, alert *domain.Alert) error {
+	tank := alert.Tank
+	if tank == nil {
+		current, err := n.tankRepo.GetTank(ctx, alert.TankID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return ErrTankNotFound
+		}
+		tank = current
+	}
+
+	measurements, err := n.measurementRepo.GetMeasurementsByTankID(ctx, alert.TankID, 0)
+	if err != nil {
+		return err
+	}
+	notes, err := n.noteRepo.GetNotes(ctx, alert.TankID)
+	if err != nil {
+		return err
+	}
+
+	evidence := domain.NewAlertEvidence(alert, tank, measurements, notes, time.Now())
+	return n.evidenceRepo.SaveEvidence(ctx, evidence)
+}
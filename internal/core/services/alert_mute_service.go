@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver el servicio de silencios de alertas
+var (
+	ErrInvalidAlertMute = errors.New("invalid alert mute data")
+	ErrAlertsNotMuted   = errors.New("tank alerts are not muted")
+)
+
+// maxMuteReasonLength limita la longitud del motivo del silencio
+const maxMuteReasonLength = 500
+
+// AlertMuteServiceImpl implementa la interfaz AlertMuteService
+type AlertMuteServiceImpl struct {
+	tankService ports.TankService
+	muteRepo    ports.AlertMuteRepository
+}
+
+// NewAlertMuteService crea una nueva instancia del servicio de silencios de alertas
+func NewAlertMuteService(tankService ports.TankService, muteRepo ports.AlertMuteRepository) ports.AlertMuteService {
+	return &AlertMuteServiceImpl{
+		tankService: tankService,
+		muteRepo:    muteRepo,
+	}
+}
+
+// MuteAlerts silencia las alertas del tanque durante duration. Si ya había un silencio vigente,
+// se levanta y se registra el nuevo, de modo que el historial refleje cada decisión.
+func (s *AlertMuteServiceImpl) MuteAlerts(ctx context.Context, tankID string, duration time.Duration, reason string) (*domain.AlertMute, error) {
+	if duration <= 0 || duration > domain.MaxAlertMuteDuration {
+		return nil, ErrInvalidAlertMute
+	}
+
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxMuteReasonLength {
+		return nil, ErrInvalidAlertMute
+	}
+
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	author := principalName(ctx)
+	if _, err := s.endActiveMute(ctx, tankID, author, now); err != nil && !errors.Is(err, ErrAlertsNotMuted) {
+		return nil, err
+	}
+
+	mute := &domain.AlertMute{
+		ID:        uuid.New().String(),
+		TankID:    tankID,
+		MutedBy:   author,
+		Reason:    reason,
+		MutedAt:   now,
+		ExpiresAt: now.Add(duration),
+	}
+	if err := s.muteRepo.SaveMute(ctx, mute); err != nil {
+		return nil, err
+	}
+
+	return mute, nil
+}
+
+// UnmuteAlerts levanta el silencio vigente del tanque antes de que expire
+func (s *AlertMuteServiceImpl) UnmuteAlerts(ctx context.Context, tankID string) (*domain.AlertMute, error) {
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	return s.endActiveMute(ctx, tankID, principalName(ctx), time.Now())
+}
+
+// GetMutes devuelve el historial de silencios del tanque
+func (s *AlertMuteServiceImpl) GetMutes(ctx context.Context, tankID string) ([]*domain.AlertMute, error) {
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	return s.muteRepo.GetMutes(ctx, tankID)
+}
+
+// endActiveMute marca como levantado el silencio vigente del tanque
+func (s *AlertMuteServiceImpl) endActiveMute(ctx context.Context, tankID, author string, now time.Time) (*domain.AlertMute, error) {
+	mutes, err := s.muteRepo.GetMutes(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := domain.ActiveAlertMute(mutes, now)
+	if active == nil {
+		return nil, ErrAlertsNotMuted
+	}
+
+	active.UnmutedAt = &now
+	active.UnmutedBy = author
+	if err := s.muteRepo.SaveMute(ctx, active); err != nil {
+		return nil, err
+	}
+
+	return active, nil
+}
+
+// MutingAlertNotifier implementa ports.AlertNotifier descartando las alertas de los tanques con
+// un silencio vigente. Al expirar el silencio, las alertas vuelven a notificarse sin intervención.
+type MutingAlertNotifier struct {
+	next     ports.AlertNotifier
+	muteRepo ports.AlertMuteRepository
+}
+
+// NewMutingAlertNotifier crea un notificador que respeta los silencios antes de delegar en next
+func NewMutingAlertNotifier(next ports.AlertNotifier, muteRepo ports.AlertMuteRepository) *MutingAlertNotifier {
+	return &MutingAlertNotifier{
+		next:     next,
+		muteRepo: muteRepo,
+	}
+}
+
+// Notify entrega la alerta salvo que su tanque esté silenciado. Las alertas que no pertenecen a
+// un tanque no se silencian.
+func (n *MutingAlertNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	if alert.TankID != "" {
+		mutes, err := n.muteRepo.GetMutes(ctx, alert.TankID)
+		if err != nil {
+			return err
+		}
+		if domain.ActiveAlertMute(mutes, time.Now()) != nil {
+			return nil
+		}
+	}
+
+	return n.next.Notify(ctx, alert)
+}
+
+// principalName devuelve el nombre del usuario autenticado, o su identificador si no tiene nombre
+func principalName(ctx context.Context) string {
+	principal := domain.PrincipalFromContext(ctx)
+	if principal == nil {
+		return ""
+	}
+	if principal.Name != "" {
+		return principal.Name
+	}
+	return principal.Subject
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// AlertRuleServiceImpl implementa la interfaz AlertRuleService: repite las mediciones históricas
+// de los tanques de la regla para mostrar cuándo habría alertado, sin enviar nada
+type AlertRuleServiceImpl struct {
+	tankService     ports.TankService
+	measurementRepo ports.MeasurementRepository
+	channelRepo     ports.NotificationChannelRepository
+	muteRepo        ports.AlertMuteRepository
+}
+
+// NewAlertRuleService crea una nueva instancia del servicio de prueba de reglas de alerta
+func NewAlertRuleService(
+	tankService ports.TankService,
+	measurementRepo ports.MeasurementRepository,
+	channelRepo ports.NotificationChannelRepository,
+	muteRepo ports.AlertMuteRepository,
+) ports.AlertRuleService {
+	return &AlertRuleServiceImpl{
+		tankService:     tankService,
+		measurementRepo: measurementRepo,
+		channelRepo:     channelRepo,
+		muteRepo:        muteRepo,
+	}
+}
+
+// TestAlertRule simula la alerta de nivel crítico sobre las mediciones del periodo de los
+// tanques accesibles que cumplen el selector de la regla. La regla puede estar deshabilitada:
+// la prueba sirve precisamente para validarla antes de activarla.
+func (s *AlertRuleServiceImpl) TestAlertRule(ctx context.Context, ruleID string, from, to time.Time) (*domain.AlertRuleSimulation, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+	if ruleID == "" {
+		return nil, ErrInvalidChannel
+	}
+
+	rule, err := s.channelRepo.GetChannel(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, ErrChannelNotFound
+	}
+
+	var fallback *domain.NotificationChannel
+	if rule.OutOfSchedule == domain.OutOfScheduleRoute && rule.FallbackChannelID != "" {
+		if fallback, err = s.channelRepo.GetChannel(ctx, rule.FallbackChannelID); err != nil {
+			return nil, err
+		}
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &domain.AlertRuleSimulation{RuleID: rule.ID, From: from, To: to, Firings: make([]*domain.AlertRuleFiring, 0)}
+	for _, tank := range tanks {
+		if !rule.MatchesTank(tank) {
+			continue
+		}
+		simulation.Tanks++
+
+		measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tank.ID, 0)
+		if err != nil {
+			return nil, err
+		}
+		firings, evaluated := domain.SimulateLevelAlerts(tank, measurements, from, to)
+		simulation.Measurements += evaluated
+		if len(firings) == 0 {
+			continue
+		}
+
+		mutes, err := s.muteRepo.GetMutes(ctx, tank.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, firing := range firings {
+			firing.Disposition = rule.Disposition(firing.FiredAt, tank, fallback)
+			if mutedAt(mutes, firing.FiredAt) {
+				firing.Disposition = domain.RuleDispositionMuted
+			}
+		}
+		simulation.Add(firings)
+	}
+
+	return simulation, nil
+}
+
+// mutedAt indica si alguno de los silencios del tanque cubría el instante t
+func mutedAt(mutes []*domain.AlertMute, t time.Time) bool {
+	for _, mute := range mutes {
+		if mute.CoveredAt(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// AnomalyServiceImpl implementa la interfaz AnomalyService
+type AnomalyServiceImpl struct {
+	tankService ports.TankService
+	anomalyRepo ports.AnomalyRepository
+}
+
+// NewAnomalyService crea una nueva instancia del servicio de consulta de anomalías
+func NewAnomalyService(tankService ports.TankService, anomalyRepo ports.AnomalyRepository) ports.AnomalyService {
+	return &AnomalyServiceImpl{
+		tankService: tankService,
+		anomalyRepo: anomalyRepo,
+	}
+}
+
+// GetAnomalies obtiene las anomalías de un tanque detectadas en el periodo indicado
+func (s *AnomalyServiceImpl) GetAnomalies(ctx context.Context, tankID string, from, to time.Time) ([]*domain.Anomaly, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	anomalies, err := s.anomalyRepo.GetAnomalies(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.Anomaly, 0)
+	for _, anomaly := range anomalies {
+		if !anomaly.DetectedAt.Before(from) && !anomaly.DetectedAt.After(to) {
+			result = append(result, anomaly)
+		}
+	}
+
+	return result, nil
+}
+
+// AnomalyDetectingTankService decora un TankService analizando cada medición guardada con el
+// detector de anomalías. Las anomalías se registran y se notifican como eventos propios,
+// independientes de las alertas por umbral.
+type AnomalyDetectingTankService struct {
+	ports.TankService
+	measurementRepo ports.MeasurementRepository
+	anomalyRepo     ports.AnomalyRepository
+	notifier        ports.AlertNotifier
+	config          domain.AnomalyConfig
+}
+
+// NewAnomalyDetectingTankService crea un TankService que detecta anomalías en las mediciones
+func NewAnomalyDetectingTankService(
+	inner ports.TankService,
+	measurementRepo ports.MeasurementRepository,
+	anomalyRepo ports.AnomalyRepository,
+	notifier ports.AlertNotifier,
+	config domain.AnomalyConfig,
+) ports.TankService {
+	return &AnomalyDetectingTankService{
+		TankService:     inner,
+		measurementRepo: measurementRepo,
+		anomalyRepo:     anomalyRepo,
+		notifier:        notifier,
+		config:          config,
+	}
+}
+
+// AddMeasurement guarda la medición y analiza si es anómala
+func (s *AnomalyDetectingTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if err := s.TankService.AddMeasurement(ctx, measurement); err != nil {
+		return err
+	}
+
+	return s.detect(ctx, measurement.TankID)
+}
+
+// AddMeasurements guarda el lote y analiza la medición más reciente de cada tanque
+func (s *AnomalyDetectingTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	if err := s.TankService.AddMeasurements(ctx, measurements); err != nil {
+		return err
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	for _, measurement := range measurements {
+		if seen[measurement.TankID] {
+			continue
+		}
+		seen[measurement.TankID] = true
+
+		if detectErr := s.detect(ctx, measurement.TankID); detectErr != nil {
+			errs = append(errs, fmt.Errorf("tank %s: %w", measurement.TankID, detectErr))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// detect analiza la lectura más reciente del tanque frente a su historial
+func (s *AnomalyDetectingTankService) detect(ctx context.Context, tankID string) error {
+	if !s.config.Enabled() {
+		return nil
+	}
+
+	// El repositorio devuelve las mediciones de la más reciente a la más antigua
+	recent, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tankID, s.config.HistorySize())
+	if err != nil {
+		return err
+	}
+
+	anomalies := domain.DetectAnomalies(domain.SortMeasurementsAscending(recent), s.config)
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	tank, err := s.TankService.GetTank(ctx, tankID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, anomaly := range anomalies {
+		anomaly.ID = uuid.New().String()
+		if err := s.anomalyRepo.SaveAnomaly(ctx, anomaly); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		format, args := anomalyMessage(anomaly)
+		alert := domain.NewAlert(domain.AlertEventAnomaly, anomalySeverity(anomaly), tank, format, args...)
+		if err := s.notifier.Notify(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// anomalySeverity clasifica la anomalía: una caída de nivel inusual puede ser una fuga y es
+// crítica; el resto requiere revisión pero no atención inmediata
+func anomalySeverity(anomaly *domain.Anomaly) string {
+	if anomaly.Kind != domain.AnomalyKindFlatline && anomaly.Metric == domain.AnomalyMetricLevel {
+		return domain.AlertSeverityCritical
+	}
+	return domain.AlertSeverityWarning
+}
+
+// anomalyMessage devuelve el formato y los argumentos del mensaje que describe la anomalía
+func anomalyMessage(anomaly *domain.Anomaly) (string, []interface{}) {
+	switch {
+	case anomaly.Kind == domain.AnomalyKindFlatline:
+		return "Anomalía: el sensor del tanque %s repite la misma lectura desde hace %.0f mediciones. " +
+			"Es posible que esté congelado.", []interface{}{anomaly.TankID, anomaly.Score}
+	case anomaly.Metric == domain.AnomalyMetricLevel:
+		return "Anomalía: caída de nivel inusual en el tanque %s (%.2f L, z=%.1f). " +
+			"Revise posibles fugas o extracciones no registradas.", []interface{}{anomaly.TankID, anomaly.Value, anomaly.Score}
+	default:
+		return "Anomalía: temperatura inusual en el tanque %s (%.2f °C, media reciente %.2f °C).",
+			[]interface{}{anomaly.TankID, anomaly.Value, anomaly.Expected}
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver el servicio de tokens de la API
+var (
+	ErrInvalidAPIToken  = errors.New("invalid api token")
+	ErrAPITokenNotFound = errors.New("api token not found")
+)
+
+// apiTokenBytes es la entropía de los tokens de la API
+const apiTokenBytes = 32
+
+// APITokenServiceImpl implementa la interfaz APITokenService
+type APITokenServiceImpl struct {
+	tokenRepo ports.APITokenRepository
+}
+
+// NewAPITokenService crea una nueva instancia del servicio de tokens de la API
+func NewAPITokenService(tokenRepo ports.APITokenRepository) ports.APITokenService {
+	return &APITokenServiceImpl{
+		tokenRepo: tokenRepo,
+	}
+}
+
+// CreateToken emite un token aleatorio con el rol y los alcances indicados y guarda solo su
+// resumen. Un alcance que el rol no permite se rechaza, porque el token nunca podría usarlo.
+func (s *APITokenServiceImpl) CreateToken(ctx context.Context, name, role string, scopes []string) (*domain.APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: name is required", ErrInvalidAPIToken)
+	}
+	for _, scope := range scopes {
+		if !domain.IsValidScope(scope) {
+			return nil, "", fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIToken, scope)
+		}
+	}
+	if role == "" {
+		role = domain.RoleForScopes(scopes)
+	}
+	if !domain.IsValidRole(role) {
+		return nil, "", fmt.Errorf("%w: unknown role %q", ErrInvalidAPIToken, role)
+	}
+	if required := domain.RoleForScopes(scopes); required != "" && !(&domain.Principal{Roles: []string{role}}).HasRole(required) {
+		return nil, "", fmt.Errorf("%w: scopes require the %s role", ErrInvalidAPIToken, required)
+	}
+
+	secret := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	plain := domain.APITokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	token := &domain.APIToken{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Role:      role,
+		Scopes:    scopes,
+		Hash:      domain.HashAPIToken(plain),
+		CreatedAt: time.Now(),
+	}
+	if err := s.tokenRepo.SaveToken(ctx, token); err != nil {
+		return nil, "", err
+	}
+	return token, plain, nil
+}
+
+// GetTokens devuelve los tokens emitidos ordenados por fecha de creación
+func (s *APITokenServiceImpl) GetTokens(ctx context.Context) ([]*domain.APIToken, error) {
+	return s.tokenRepo.GetTokens(ctx)
+}
+
+// RevokeToken elimina el token; las solicitudes que lo presenten dejan de autenticarse
+func (s *APITokenServiceImpl) RevokeToken(ctx context.Context, id string) error {
+	deleted, err := s.tokenRepo.DeleteToken(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrChangeRequiresApproval se devuelve cuando un cambio de configuración recibido de otra instancia
+// cambia el umbral o la capacidad de un tanque de un sitio regulado
+var ErrChangeRequiresApproval = errors.New("tank change requires approval")
+
+// PendingApprovalError se devuelve cuando la actualización de un tanque incluye cambios que
+// quedan pendientes de aprobación. El resto de la actualización ya se aplicó.
+type PendingApprovalError struct {
+	Change *domain.TankChangeRequest
+}
+
+func (e *PendingApprovalError) Error() string {
+	return "tank change is pending approval"
+}
+
+// ApprovalTankService decora un TankService reteniendo los cambios del umbral de alerta y de la
+// capacidad de los tanques de los sitios regulados hasta que los apruebe un administrador. Los
+// administradores y las tareas internas, sin usuario autenticado, aplican los cambios directamente.
+type ApprovalTankService struct {
+	ports.TankService
+	changeRepo ports.TankChangeRepository
+	sites      map[string]bool
+}
+
+// NewApprovalTankService crea un TankService que exige aprobación en los sitios indicados
+func NewApprovalTankService(inner ports.TankService, changeRepo ports.TankChangeRepository, sites []string) ports.TankService {
+	regulated := make(map[string]bool, len(sites))
+	for _, site := range sites {
+		regulated[site] = true
+	}
+	return &ApprovalTankService{
+		TankService: inner,
+		changeRepo:  changeRepo,
+		sites:       regulated,
+	}
+}
+
+// UpdateTank aplica la actualización conservando el umbral y la capacidad actuales cuando su
+// cambio requiere aprobación; en ese caso registra la solicitud, que reemplaza a las pendientes
+// del mismo tanque, y devuelve un *PendingApprovalError con ella
+func (s *ApprovalTankService) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	principal := domain.PrincipalFromContext(ctx)
+	if tank == nil || principal == nil || principal.HasRole(domain.RoleAdmin) {
+		return s.TankService.UpdateTank(ctx, tank)
+	}
+
+	current, err := s.TankService.GetTank(ctx, tank.ID)
+	if err != nil {
+		return err
+	}
+
+	// Sacar un tanque de un sitio regulado también requiere aprobación para sus cambios
+	if !s.sites[current.SiteID] && !s.sites[tank.SiteID] {
+		return s.TankService.UpdateTank(ctx, tank)
+	}
+
+	changes := domain.TankChangesRequiringApproval(current, tank)
+	if len(changes) == 0 {
+		return s.TankService.UpdateTank(ctx, tank)
+	}
+
+	tank.AlertThreshold = current.AlertThreshold
+	tank.Capacity = current.Capacity
+	if err := s.TankService.UpdateTank(ctx, tank); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.supersedePending(ctx, tank.ID, principalName(ctx), now); err != nil {
+		return err
+	}
+
+	site := tank.SiteID
+	if !s.sites[site] {
+		site = current.SiteID
+	}
+	change := &domain.TankChangeRequest{
+		ID:          uuid.New().String(),
+		TankID:      tank.ID,
+		SiteID:      site,
+		Changes:     changes,
+		Status:      domain.TankChangePending,
+		RequestedBy: principalName(ctx),
+		RequestedAt: now,
+	}
+	if err := s.changeRepo.SaveTankChange(ctx, change); err != nil {
+		return err
+	}
+
+	return &PendingApprovalError{Change: change}
+}
+
+// supersedePending cierra las solicitudes pendientes del tanque: solo la última refleja lo que
+// el operador quiere
+func (s *ApprovalTankService) supersedePending(ctx context.Context, tankID, author string, now time.Time) error {
+	pending, err := s.changeRepo.GetTankChanges(ctx, domain.TankChangeFilter{Status: domain.TankChangePending, TankID: tankID})
+	if err != nil {
+		return err
+	}
+
+	for _, change := range pending {
+		change.Review(domain.TankChangeSuperseded, author, "", now)
+		if err := s.changeRepo.SaveTankChange(ctx, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncApprovalTankService decora el TankService con el que se aplican los cambios de configuración
+// recibidos de las pasarelas. No hay un operador que pueda solicitar su aprobación, así que en los
+// sitios regulados rechaza los cambios de umbral y capacidad sin aplicar nada del cambio.
+type SyncApprovalTankService struct {
+	ports.TankService
+	sites map[string]bool
+}
+
+// NewSyncApprovalTankService crea un TankService que rechaza con ErrChangeRequiresApproval los
+// cambios que requieren aprobación en los sitios indicados
+func NewSyncApprovalTankService(inner ports.TankService, sites []string) ports.TankService {
+	regulated := make(map[string]bool, len(sites))
+	for _, site := range sites {
+		regulated[site] = true
+	}
+	return &SyncApprovalTankService{
+		TankService: inner,
+		sites:       regulated,
+	}
+}
+
+// UpdateTank aplica la actualización salvo que cambie el umbral o la capacidad de un tanque que
+// está o estaba en un sitio regulado
+func (s *SyncApprovalTankService) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	if tank == nil {
+		return s.TankService.UpdateTank(ctx, tank)
+	}
+
+	current, err := s.TankService.GetTank(ctx, tank.ID)
+	if err != nil {
+		return err
+	}
+	if (s.sites[current.SiteID] || s.sites[tank.SiteID]) && len(domain.TankChangesRequiringApproval(current, tank)) > 0 {
+		return ErrChangeRequiresApproval
+	}
+	return s.TankService.UpdateTank(ctx, tank)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver el servicio de adjuntos
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrInvalidAttachment  = errors.New("invalid attachment data")
+)
+
+// AttachmentServiceImpl implementa la interfaz AttachmentService
+type AttachmentServiceImpl struct {
+	tankService    ports.TankService
+	attachmentRepo ports.AttachmentRepository
+	storage        ports.FileStorage
+}
+
+// NewAttachmentService crea una nueva instancia del servicio de adjuntos
+func NewAttachmentService(
+	tankService ports.TankService,
+	attachmentRepo ports.AttachmentRepository,
+	storage ports.FileStorage,
+) ports.AttachmentService {
+	return &AttachmentServiceImpl{
+		tankService:    tankService,
+		attachmentRepo: attachmentRepo,
+		storage:        storage,
+	}
+}
+
+// UploadAttachment guarda el contenido en el almacenamiento y registra sus metadatos
+func (s *AttachmentServiceImpl) UploadAttachment(ctx context.Context, attachment *domain.Attachment, content io.Reader) error {
+	if attachment == nil || attachment.ID == "" || content == nil {
+		return ErrInvalidAttachment
+	}
+
+	// Conservamos solo el nombre del archivo, sin rutas del cliente
+	attachment.FileName = path.Base(strings.ReplaceAll(attachment.FileName, "\\", "/"))
+	if attachment.FileName == "" || attachment.FileName == "." || attachment.FileName == "/" {
+		return ErrInvalidAttachment
+	}
+
+	if attachment.Kind == "" {
+		attachment.Kind = domain.AttachmentKindOther
+	}
+	if !domain.IsValidAttachmentKind(attachment.Kind) {
+		return ErrInvalidAttachment
+	}
+
+	if attachment.ContentType == "" {
+		attachment.ContentType = "application/octet-stream"
+	}
+
+	if _, err := s.tankService.GetTank(ctx, attachment.TankID); err != nil {
+		return err
+	}
+
+	attachment.StorageKey = "tanks/" + attachment.TankID + "/" + attachment.ID
+	attachment.UploadedAt = time.Now()
+
+	counter := &countingReader{reader: content}
+	if err := s.storage.Put(ctx, attachment.StorageKey, counter, attachment.Size, attachment.ContentType); err != nil {
+		return err
+	}
+	attachment.Size = counter.count
+
+	if err := s.attachmentRepo.SaveAttachment(ctx, attachment); err != nil {
+		// Sin metadatos el archivo quedaría huérfano en el almacenamiento
+		_ = s.storage.Delete(context.WithoutCancel(ctx), attachment.StorageKey)
+		return err
+	}
+
+	return nil
+}
+
+// GetAttachments obtiene los adjuntos de un tanque
+func (s *AttachmentServiceImpl) GetAttachments(ctx context.Context, tankID string) ([]*domain.Attachment, error) {
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	return s.attachmentRepo.GetAttachmentsByTankID(ctx, tankID)
+}
+
+// GetAttachment obtiene los metadatos de un adjunto del tanque
+func (s *AttachmentServiceImpl) GetAttachment(ctx context.Context, tankID, id string) (*domain.Attachment, error) {
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attachmentRepo.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachment == nil || attachment.TankID != tankID {
+		return nil, ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+// OpenAttachment obtiene los metadatos y el contenido de un adjunto del tanque
+func (s *AttachmentServiceImpl) OpenAttachment(ctx context.Context, tankID, id string) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.GetAttachment(ctx, tankID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.storage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// DeleteAttachment elimina un adjunto del tanque y su contenido
+func (s *AttachmentServiceImpl) DeleteAttachment(ctx context.Context, tankID, id string) error {
+	attachment, err := s.GetAttachment(ctx, tankID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.attachmentRepo.DeleteAttachment(ctx, id); err != nil {
+		return err
+	}
+
+	return s.storage.Delete(ctx, attachment.StorageKey)
+}
+
+// countingReader cuenta los bytes leídos para registrar el tamaño real del archivo
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
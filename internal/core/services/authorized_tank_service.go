@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// AuthorizedTankService decora un TankService aplicando las concesiones de acceso por sitio o
+// grupo a todas las consultas y modificaciones. Los tanques sin acceso se tratan como inexistentes
+// para no revelar su existencia.
+type AuthorizedTankService struct {
+	ports.TankService
+	access ports.AccessService
+}
+
+// NewAuthorizedTankService crea un TankService que respeta las concesiones de acceso
+func NewAuthorizedTankService(inner ports.TankService, access ports.AccessService) ports.TankService {
+	return &AuthorizedTankService{
+		TankService: inner,
+		access:      access,
+	}
+}
+
+// GetTank obtiene un tanque si el usuario tiene acceso a él
+func (s *AuthorizedTankService) GetTank(ctx context.Context, id string) (*domain.Tank, error) {
+	tank, err := s.TankService.GetTank(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.access.CanAccessTank(ctx, tank)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, ErrTankNotFound
+	}
+
+	return tank, nil
+}
+
+// GetAllTanks obtiene los tanques a los que el usuario tiene acceso
+func (s *AuthorizedTankService) GetAllTanks(ctx context.Context) ([]*domain.Tank, error) {
+	tanks, err := s.TankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*domain.Tank, 0, len(tanks))
+	for _, tank := range tanks {
+		allowed, err := s.access.CanAccessTank(ctx, tank)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			visible = append(visible, tank)
+		}
+	}
+
+	return visible, nil
+}
+
+// CreateTank crea un tanque solo en un sitio o grupo al que el usuario tenga acceso
+func (s *AuthorizedTankService) CreateTank(ctx context.Context, tank *domain.Tank) error {
+	if tank != nil {
+		if err := s.requireAccess(ctx, tank); err != nil {
+			return err
+		}
+	}
+
+	return s.TankService.CreateTank(ctx, tank)
+}
+
+// UpdateTank actualiza un tanque accesible sin permitir moverlo fuera de los ámbitos concedidos
+func (s *AuthorizedTankService) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	if tank != nil {
+		if _, err := s.GetTank(ctx, tank.ID); err != nil {
+			return err
+		}
+		if err := s.requireAccess(ctx, tank); err != nil {
+			return err
+		}
+	}
+
+	return s.TankService.UpdateTank(ctx, tank)
+}
+
+// DeleteTank elimina un tanque accesible
+func (s *AuthorizedTankService) DeleteTank(ctx context.Context, id string) error {
+	if _, err := s.GetTank(ctx, id); err != nil {
+		return err
+	}
+
+	return s.TankService.DeleteTank(ctx, id)
+}
+
+// MonitorTank monitorea un tanque accesible
+func (s *AuthorizedTankService) MonitorTank(ctx context.Context, tankID string) error {
+	if _, err := s.GetTank(ctx, tankID); err != nil {
+		return err
+	}
+
+	return s.TankService.MonitorTank(ctx, tankID)
+}
+
+// AddMeasurement añade una medición a un tanque accesible
+func (s *AuthorizedTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if measurement != nil {
+		if _, err := s.GetTank(ctx, measurement.TankID); err != nil {
+			return err
+		}
+	}
+
+	return s.TankService.AddMeasurement(ctx, measurement)
+}
+
+// AddMeasurements añade un lote de mediciones si todos sus tanques son accesibles
+func (s *AuthorizedTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	checked := make(map[string]bool)
+	for _, measurement := range measurements {
+		if measurement == nil || checked[measurement.TankID] {
+			continue
+		}
+		if _, err := s.GetTank(ctx, measurement.TankID); err != nil {
+			return err
+		}
+		checked[measurement.TankID] = true
+	}
+
+	return s.TankService.AddMeasurements(ctx, measurements)
+}
+
+// BackfillMeasurements importa mediciones históricas de un tanque accesible
+func (s *AuthorizedTankService) BackfillMeasurements(ctx context.Context, tankID string, measurements []*domain.Measurement) (*domain.BackfillResult, error) {
+	if _, err := s.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	return s.TankService.BackfillMeasurements(ctx, tankID, measurements)
+}
+
+// GetTankStatus obtiene el estado de un tanque accesible
+func (s *AuthorizedTankService) GetTankStatus(ctx context.Context, tankID string) (string, error) {
+	if _, err := s.GetTank(ctx, tankID); err != nil {
+		return "", err
+	}
+
+	return s.TankService.GetTankStatus(ctx, tankID)
+}
+
+// requireAccess devuelve ErrForbidden si el tanque queda fuera de los ámbitos del usuario
+func (s *AuthorizedTankService) requireAccess(ctx context.Context, tank *domain.Tank) error {
+	allowed, err := s.access.CanAccessTank(ctx, tank)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return ErrForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// CacheInvalidatingTankService decora un TankService descartando las respuestas en caché de
+// un tanque cuando se guardan sus mediciones o se modifica su configuración
+type CacheInvalidatingTankService struct {
+	ports.TankService
+	cache ports.CacheInvalidator
+}
+
+// NewCacheInvalidatingTankService crea un TankService que invalida la caché en cada cambio
+func NewCacheInvalidatingTankService(inner ports.TankService, cache ports.CacheInvalidator) ports.TankService {
+	return &CacheInvalidatingTankService{
+		TankService: inner,
+		cache:       cache,
+	}
+}
+
+// CreateTank crea el tanque; las respuestas de toda la flota dejan de estar al día
+func (s *CacheInvalidatingTankService) CreateTank(ctx context.Context, tank *domain.Tank) error {
+	if err := s.TankService.CreateTank(ctx, tank); err != nil {
+		return err
+	}
+
+	s.cache.InvalidateTank(tank.ID)
+	return nil
+}
+
+// UpdateTank actualiza el tanque e invalida sus respuestas en caché
+func (s *CacheInvalidatingTankService) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	if err := s.TankService.UpdateTank(ctx, tank); err != nil {
+		return err
+	}
+
+	s.cache.InvalidateTank(tank.ID)
+	return nil
+}
+
+// DeleteTank elimina el tanque e invalida sus respuestas en caché
+func (s *CacheInvalidatingTankService) DeleteTank(ctx context.Context, id string) error {
+	if err := s.TankService.DeleteTank(ctx, id); err != nil {
+		return err
+	}
+
+	s.cache.InvalidateTank(id)
+	return nil
+}
+
+// AddMeasurement guarda la medición e invalida las respuestas en caché de su tanque. Una medición
+// atrasada también las invalida, porque cambia el historial.
+func (s *CacheInvalidatingTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	err := s.TankService.AddMeasurement(ctx, measurement)
+	if err != nil && !errors.Is(err, domain.ErrLateMeasurement) {
+		return err
+	}
+
+	s.cache.InvalidateTank(measurement.TankID)
+	return err
+}
+
+// AddMeasurements guarda el lote e invalida una vez las respuestas de cada tanque afectado
+func (s *CacheInvalidatingTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	if err := s.TankService.AddMeasurements(ctx, measurements); err != nil {
+		return err
+	}
+
+	invalidated := make(map[string]bool)
+	for _, measurement := range measurements {
+		if invalidated[measurement.TankID] {
+			continue
+		}
+		invalidated[measurement.TankID] = true
+		s.cache.InvalidateTank(measurement.TankID)
+	}
+	return nil
+}
+
+// BackfillMeasurements importa mediciones históricas e invalida las respuestas en caché del
+// tanque, cuyos indicadores incluyen ahora el historial importado
+func (s *CacheInvalidatingTankService) BackfillMeasurements(ctx context.Context, tankID string, measurements []*domain.Measurement) (*domain.BackfillResult, error) {
+	result, err := s.TankService.BackfillMeasurements(ctx, tankID, measurements)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.InvalidateTank(tankID)
+	return result, nil
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ClockSkewTracker lleva el desfase del reloj de cada sensor respecto a la hora de recepción.
+// El seguimiento es en memoria: tras reiniciar, el desfase se recalcula con las lecturas nuevas.
+type ClockSkewTracker struct {
+	mutex  sync.RWMutex
+	config domain.ClockSkewConfig
+	clocks map[clockKey]*domain.SensorClock
+}
+
+// clockKey identifica un sensor; el mismo ID de sensor puede repetirse en varios tanques
+type clockKey struct {
+	tankID   string
+	sensorID string
+}
+
+// NewClockSkewTracker crea un seguimiento del reloj de los sensores con la configuración indicada
+func NewClockSkewTracker(config domain.ClockSkewConfig) *ClockSkewTracker {
+	return &ClockSkewTracker{
+		config: config,
+		clocks: make(map[clockKey]*domain.SensorClock),
+	}
+}
+
+// observe registra las lecturas recibidas en receivedAt y, si la configuración lo indica,
+// corrige sus marcas de tiempo. Cada sensor se mide con su lectura más reciente del lote, de
+// modo que las lecturas almacenadas que el equipo reenvía tarde no cuentan como desfase; al
+// corregir, todas las lecturas del sensor se desplazan lo mismo para conservar su intervalo.
+func (t *ClockSkewTracker) observe(measurements []*domain.Measurement, receivedAt time.Time) {
+	newest := make(map[clockKey]time.Time)
+	for _, measurement := range measurements {
+		if measurement == nil || measurement.Timestamp.IsZero() {
+			continue
+		}
+		key := clockKey{tankID: measurement.TankID, sensorID: domain.SensorIDForMeasurement(measurement)}
+		if latest, ok := newest[key]; !ok || measurement.Timestamp.After(latest) {
+			newest[key] = measurement.Timestamp
+		}
+	}
+	if len(newest) == 0 {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	offsets := make(map[clockKey]time.Duration, len(newest))
+	for key, latest := range newest {
+		clock, ok := t.clocks[key]
+		if !ok {
+			clock = &domain.SensorClock{SensorID: key.sensorID, TankID: key.tankID}
+			t.clocks[key] = clock
+		}
+		offset := latest.Sub(receivedAt)
+		clock.Observe(offset, receivedAt, t.config)
+		if t.config.ShouldCorrect(offset) {
+			offsets[key] = offset
+		}
+	}
+
+	for _, measurement := range measurements {
+		if measurement == nil || measurement.Timestamp.IsZero() {
+			continue
+		}
+		key := clockKey{tankID: measurement.TankID, sensorID: domain.SensorIDForMeasurement(measurement)}
+		if offset, ok := offsets[key]; ok {
+			measurement.Timestamp = measurement.Timestamp.Add(-offset)
+			t.clocks[key].Corrected++
+		}
+	}
+}
+
+// ClockSkewServiceImpl implementa la interfaz ClockSkewService
+type ClockSkewServiceImpl struct {
+	tankService ports.TankService
+	tracker     *ClockSkewTracker
+}
+
+// NewClockSkewService crea el servicio de consulta del desfase de los relojes. tankService
+// limita el resultado a los sensores de los tanques accesibles.
+func NewClockSkewService(tankService ports.TankService, tracker *ClockSkewTracker) ports.ClockSkewService {
+	return &ClockSkewServiceImpl{
+		tankService: tankService,
+		tracker:     tracker,
+	}
+}
+
+// GetSensorClocks devuelve el desfase de los sensores, del mayor al menor desfase medio
+func (s *ClockSkewServiceImpl) GetSensorClocks(ctx context.Context, drifting bool) ([]*domain.SensorClock, error) {
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accessible := make(map[string]bool, len(tanks))
+	for _, tank := range tanks {
+		accessible[tank.ID] = true
+	}
+
+	s.tracker.mutex.RLock()
+	result := make([]*domain.SensorClock, 0)
+	for key, clock := range s.tracker.clocks {
+		if accessible[key.tankID] && (!drifting || clock.Drifting) {
+			copied := *clock
+			result = append(result, &copied)
+		}
+	}
+	s.tracker.mutex.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if offsetI, offsetJ := math.Abs(result[i].AverageOffset), math.Abs(result[j].AverageOffset); offsetI != offsetJ {
+			return offsetI > offsetJ
+		}
+		if result[i].TankID != result[j].TankID {
+			return result[i].TankID < result[j].TankID
+		}
+		return result[i].SensorID < result[j].SensorID
+	})
+
+	return result, nil
+}
+
+// ClockSkewTankService decora un TankService registrando el desfase del reloj de los sensores
+// en cada medición recibida y corrigiendo las marcas de tiempo muy desfasadas si está activado
+type ClockSkewTankService struct {
+	ports.TankService
+	tracker *ClockSkewTracker
+}
+
+// NewClockSkewTankService crea un TankService que registra el reloj de los sensores en tracker
+func NewClockSkewTankService(inner ports.TankService, tracker *ClockSkewTracker) ports.TankService {
+	return &ClockSkewTankService{
+		TankService: inner,
+		tracker:     tracker,
+	}
+}
+
+// AddMeasurement registra el desfase de la medición antes de guardarla
+func (s *ClockSkewTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	s.tracker.observe([]*domain.Measurement{measurement}, time.Now())
+	return s.TankService.AddMeasurement(ctx, measurement)
+}
+
+// AddMeasurements registra el desfase de cada sensor del lote antes de guardarlo
+func (s *ClockSkewTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	s.tracker.observe(measurements, time.Now())
+	return s.TankService.AddMeasurements(ctx, measurements)
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// CompartmentServiceImpl implementa la interfaz CompartmentService
+type CompartmentServiceImpl struct {
+	tankService ports.TankService
+}
+
+// NewCompartmentService crea una nueva instancia del servicio de compartimentos
+func NewCompartmentService(tankService ports.TankService) ports.CompartmentService {
+	return &CompartmentServiceImpl{tankService: tankService}
+}
+
+// GetCompartments resume los compartimentos del tanque con su nivel actual. Un compartimento
+// no puede tener compartimentos, así que pedirlos devuelve ErrInvalidTank.
+func (s *CompartmentServiceImpl) GetCompartments(ctx context.Context, tankID string) (*domain.CompartmentRollup, error) {
+	parent, err := s.tankService.GetTank(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentID != "" {
+		return nil, ErrInvalidTank
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.RollupCompartments(parent, domain.CompartmentsOf(tanks, parent.ID)), nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Formatos del aviso de cambio de configuración, con y sin usuario autenticado
+const (
+	configChangedByFormat = "Configuración del tanque %s cambiada por %s: %s."
+	configChangedFormat   = "Configuración del tanque %s cambiada: %s."
+)
+
+// ConfigNotifyingTankService decora un TankService avisando por el notificador de cada cambio de
+// la configuración de un tanque, con quién lo hizo y los valores anterior y nuevo de cada campo,
+// para que los supervisores detecten, por ejemplo, un umbral de alerta modificado sin avisar
+type ConfigNotifyingTankService struct {
+	ports.TankService
+	notifier ports.AlertNotifier
+}
+
+// NewConfigNotifyingTankService crea un TankService que avisa de los cambios de configuración
+func NewConfigNotifyingTankService(inner ports.TankService, notifier ports.AlertNotifier) ports.TankService {
+	return &ConfigNotifyingTankService{
+		TankService: inner,
+		notifier:    notifier,
+	}
+}
+
+// UpdateTank actualiza el tanque y avisa si cambió su configuración. Un fallo del aviso se
+// devuelve como el de las demás alertas, aunque la actualización ya se guardó.
+func (s *ConfigNotifyingTankService) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	if tank == nil {
+		return s.TankService.UpdateTank(ctx, tank)
+	}
+
+	before, err := s.TankService.GetTank(ctx, tank.ID)
+	if err != nil {
+		return s.TankService.UpdateTank(ctx, tank)
+	}
+	if err := s.TankService.UpdateTank(ctx, tank); err != nil {
+		return err
+	}
+
+	change := &domain.TankConfigChange{
+		ChangedBy: principalName(ctx),
+		Changes:   domain.TankConfigChanges(before, tank),
+	}
+	if len(change.Changes) == 0 {
+		return nil
+	}
+
+	var alert *domain.Alert
+	if change.ChangedBy != "" {
+		alert = domain.NewAlert(domain.AlertEventConfigChanged, change.Severity(), tank, configChangedByFormat, tank.Name, change.ChangedBy, change.Summary())
+	} else {
+		alert = domain.NewAlert(domain.AlertEventConfigChanged, change.Severity(), tank, configChangedFormat, tank.Name, change.Summary())
+	}
+	alert.ConfigChange = change
+	return s.notifier.Notify(ctx, alert)
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// dataLakeMaxFileRows limita las mediciones de cada archivo; un día con más se reparte en varios
+const dataLakeMaxFileRows = 100000
+
+// errDataLakeExported detiene el recorrido de las mediciones al llegar a las ya exportadas
+var errDataLakeExported = errors.New("measurements already exported")
+
+// DataLakeExportServiceImpl implementa la interfaz DataLakeExportService. Cada exportación toma
+// las mediciones posteriores al punto de control y anteriores a ahora menos delay, que deja
+// margen a las mediciones que llegan con retraso; las que llegan después de exportado su periodo
+// no se exportan.
+type DataLakeExportServiceImpl struct {
+	tankRepo ports.TankRepository
+	streamer ports.MeasurementStreamer
+	writer   ports.DataLakeWriter
+	delay    time.Duration
+
+	mutex sync.Mutex // Una exportación manual no se solapa con la programada
+}
+
+// NewDataLakeExportService crea el servicio de exportación al data lake
+func NewDataLakeExportService(tankRepo ports.TankRepository, streamer ports.MeasurementStreamer, writer ports.DataLakeWriter, delay time.Duration) *DataLakeExportServiceImpl {
+	return &DataLakeExportServiceImpl{
+		tankRepo: tankRepo,
+		streamer: streamer,
+		writer:   writer,
+		delay:    delay,
+	}
+}
+
+// Export exporta las mediciones nuevas día a día (UTC), para no cargar en memoria más que las
+// mediciones de un día aunque falte exportar todo el historial. El corte de cada día se guarda
+// como pendiente antes de escribir: si la exportación falla a medias, el siguiente intento repite
+// ese corte con los mismos nombres de archivo y reemplaza lo ya escrito.
+func (s *DataLakeExportServiceImpl) Export(ctx context.Context) (*domain.DataLakeExport, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	checkpoint, err := s.writer.GetCheckpoint(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get data lake checkpoint: %w", err)
+	}
+
+	cutoff := time.Now().Add(-s.delay).UTC().Truncate(time.Second)
+	if checkpoint.PendingUntil != nil && checkpoint.PendingUntil.After(cutoff) {
+		cutoff = *checkpoint.PendingUntil
+	}
+	export := &domain.DataLakeExport{From: checkpoint.ExportedUntil, To: checkpoint.ExportedUntil, Files: []string{}}
+
+	// Sin corte pendiente, el primer día a exportar es el de la medición nueva más antigua
+	var next *time.Time
+	if checkpoint.PendingUntil == nil {
+		if _, next, err = s.collect(ctx, checkpoint.ExportedUntil, checkpoint.ExportedUntil, cutoff); err != nil {
+			return nil, err
+		}
+	}
+
+	for checkpoint.PendingUntil != nil || next != nil {
+		if checkpoint.PendingUntil == nil {
+			until := dataLakeDayEnd(*next)
+			if until.After(cutoff) {
+				until = cutoff
+			}
+			checkpoint.PendingUntil = &until
+			if err := s.writer.SaveCheckpoint(ctx, checkpoint); err != nil {
+				return nil, fmt.Errorf("save data lake checkpoint: %w", err)
+			}
+		}
+		until := *checkpoint.PendingUntil
+
+		var days []*dataLakeDay
+		days, next, err = s.collect(ctx, checkpoint.ExportedUntil, until, cutoff)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.writeDays(ctx, export, until, days); err != nil {
+			return nil, err
+		}
+
+		export.To = until
+		checkpoint.ExportedUntil = until
+		checkpoint.PendingUntil = nil
+		checkpoint.LastExport = export
+		if err := s.writer.SaveCheckpoint(ctx, checkpoint); err != nil {
+			return nil, fmt.Errorf("save data lake checkpoint: %w", err)
+		}
+	}
+
+	if cutoff.After(export.To) {
+		// Sin mediciones hasta el corte: se avanza igualmente para no volver a recorrerlas
+		export.To = cutoff
+		checkpoint.ExportedUntil = cutoff
+	}
+	export.FinishedAt = time.Now()
+	checkpoint.LastExport = export
+	if err := s.writer.SaveCheckpoint(ctx, checkpoint); err != nil {
+		return nil, fmt.Errorf("save data lake checkpoint: %w", err)
+	}
+	return export, nil
+}
+
+// writeDays escribe las mediciones de cada día en archivos de hasta dataLakeMaxFileRows, con
+// nombres que dependen solo del corte para que un reintento los reemplace
+func (s *DataLakeExportServiceImpl) writeDays(ctx context.Context, export *domain.DataLakeExport, until time.Time, days []*dataLakeDay) error {
+	part := until.Format("20060102T150405Z")
+	for _, day := range days {
+		for start, n := 0, 1; start < len(day.measurements); start, n = start+dataLakeMaxFileRows, n+1 {
+			end := start + dataLakeMaxFileRows
+			if end > len(day.measurements) {
+				end = len(day.measurements)
+			}
+			name := fmt.Sprintf("part-%s-%04d", part, n)
+			key, err := s.writer.WriteMeasurements(ctx, day.date, name, day.measurements[start:end])
+			if err != nil {
+				return fmt.Errorf("write data lake file: %w", err)
+			}
+			export.Files = append(export.Files, key)
+			export.Measurements += end - start
+		}
+	}
+	return nil
+}
+
+// GetCheckpoint devuelve el punto de control con el resultado de la última exportación
+func (s *DataLakeExportServiceImpl) GetCheckpoint(ctx context.Context) (*domain.DataLakeCheckpoint, error) {
+	return s.writer.GetCheckpoint(ctx)
+}
+
+// dataLakeDay son las mediciones de un día (UTC), una partición del data lake
+type dataLakeDay struct {
+	date         time.Time
+	measurements []*domain.Measurement
+}
+
+// collect reúne las mediciones posteriores a from y hasta until de todos los tanques, agrupadas
+// por día y ordenadas por tanque y marca de tiempo. Devuelve además la marca de tiempo de la
+// medición más antigua posterior a until y hasta cutoff, donde empieza el siguiente día a
+// exportar, o nil si no hay ninguna; las mediciones que recorre hasta llegar a from no se guardan.
+func (s *DataLakeExportServiceImpl) collect(ctx context.Context, from, until, cutoff time.Time) ([]*dataLakeDay, *time.Time, error) {
+	tanks, err := s.tankRepo.GetAllTanks(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(tanks, func(i, j int) bool { return tanks[i].ID < tanks[j].ID })
+
+	var next *time.Time
+	byDate := make(map[time.Time]*dataLakeDay)
+	for _, tank := range tanks {
+		var measurements []*domain.Measurement
+		err := s.streamer.StreamMeasurementsByTankID(ctx, tank.ID, 0, func(m *domain.Measurement) error {
+			if !m.Timestamp.After(from) {
+				return errDataLakeExported
+			}
+			if m.Timestamp.After(until) {
+				if !m.Timestamp.After(cutoff) && (next == nil || m.Timestamp.Before(*next)) {
+					ts := m.Timestamp
+					next = &ts
+				}
+				return nil
+			}
+			measurements = append(measurements, m)
+			return nil
+		})
+		if err != nil && !errors.Is(err, errDataLakeExported) {
+			return nil, nil, err
+		}
+
+		// El recorrido va de la más reciente a la más antigua
+		for i := len(measurements) - 1; i >= 0; i-- {
+			m := measurements[i]
+			date := dataLakeDate(m.Timestamp)
+			day, ok := byDate[date]
+			if !ok {
+				day = &dataLakeDay{date: date}
+				byDate[date] = day
+			}
+			day.measurements = append(day.measurements, m)
+		}
+	}
+
+	days := make([]*dataLakeDay, 0, len(byDate))
+	for _, day := range byDate {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].date.Before(days[j].date) })
+	return days, next, nil
+}
+
+// dataLakeDate devuelve el día (UTC) de la partición de la marca de tiempo
+func dataLakeDate(ts time.Time) time.Time {
+	ts = ts.UTC()
+	return time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// dataLakeDayEnd devuelve el corte que cierra el día de la marca de tiempo: el último instante
+// antes de la medianoche siguiente, para que cada corte quede en un solo día
+func dataLakeDayEnd(ts time.Time) time.Time {
+	return dataLakeDate(ts).AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// DataQualityServiceImpl implementa la interfaz DataQualityService
+type DataQualityServiceImpl struct {
+	tankService     ports.TankService
+	measurementRepo ports.MeasurementRepository
+	emailSender     ports.EmailSender
+	config          domain.DataQualityConfig
+	window          time.Duration
+	recipients      []string
+
+	mutex  sync.RWMutex
+	latest *domain.DataQualityReport
+}
+
+// NewDataQualityService crea una nueva instancia del servicio de calidad de datos. El informe
+// programado abarca la ventana indicada hasta el momento de generarlo y se envía por correo a
+// recipients; emailSender puede ser nil si el correo no está configurado.
+func NewDataQualityService(
+	tankService ports.TankService,
+	measurementRepo ports.MeasurementRepository,
+	emailSender ports.EmailSender,
+	config domain.DataQualityConfig,
+	window time.Duration,
+	recipients []string,
+) ports.DataQualityService {
+	return &DataQualityServiceImpl{
+		tankService:     tankService,
+		measurementRepo: measurementRepo,
+		emailSender:     emailSender,
+		config:          config,
+		window:          window,
+		recipients:      recipients,
+	}
+}
+
+// GetReport evalúa los tanques accesibles que cumplen el selector en el periodo [from, to]
+func (s *DataQualityServiceImpl) GetReport(ctx context.Context, from, to time.Time, selector domain.LabelSelector) (*domain.DataQualityReport, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.DataQualityReport{GeneratedAt: time.Now(), From: from, To: to}
+	for _, tank := range domain.FilterTanksByLabels(tanks, selector) {
+		measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tank.ID, 0)
+		if err != nil {
+			return nil, err
+		}
+		report.Tanks = append(report.Tanks, domain.BuildTankDataQuality(tank, measurements, from, to, s.config))
+	}
+
+	return withSummary(report), nil
+}
+
+// GetLatestReport devuelve el último informe programado con los tanques accesibles que cumplen
+// el selector
+func (s *DataQualityServiceImpl) GetLatestReport(ctx context.Context, selector domain.LabelSelector) (*domain.DataQualityReport, error) {
+	s.mutex.RLock()
+	latest := s.latest
+	s.mutex.RUnlock()
+
+	if latest == nil {
+		to := time.Now()
+		return s.GetReport(ctx, to.Add(-s.window), to, selector)
+	}
+
+	// El informe programado incluye todos los tanques; cada usuario ve solo los suyos
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accessible := make(map[string]bool, len(tanks))
+	for _, tank := range domain.FilterTanksByLabels(tanks, selector) {
+		accessible[tank.ID] = true
+	}
+
+	report := &domain.DataQualityReport{GeneratedAt: latest.GeneratedAt, From: latest.From, To: latest.To}
+	for _, quality := range latest.Tanks {
+		if accessible[quality.TankID] {
+			report.Tanks = append(report.Tanks, quality)
+		}
+	}
+	return withSummary(report), nil
+}
+
+// GenerateScheduledReport genera el informe de la última ventana, lo conserva como el más
+// reciente y lo envía por correo si hay destinatarios
+func (s *DataQualityServiceImpl) GenerateScheduledReport(ctx context.Context) error {
+	to := time.Now()
+	report, err := s.GetReport(ctx, to.Add(-s.window), to, nil)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.latest = report
+	s.mutex.Unlock()
+
+	if s.emailSender == nil || len(s.recipients) == 0 {
+		return nil
+	}
+	subject := fmt.Sprintf("Informe de calidad de datos: %d de %d tanques con problemas",
+		report.Summary.TanksWithIssues, report.Summary.Tanks)
+	return s.emailSender.SendEmail(ctx, s.recipients, subject, dataQualityReportText(report))
+}
+
+// withSummary ordena los tanques (primero los que tienen problemas) y calcula el resumen
+func withSummary(report *domain.DataQualityReport) *domain.DataQualityReport {
+	sort.SliceStable(report.Tanks, func(i, j int) bool {
+		a, b := report.Tanks[i], report.Tanks[j]
+		if a.HasIssues() != b.HasIssues() {
+			return a.HasIssues()
+		}
+		return a.TankName < b.TankName
+	})
+
+	report.Summary = domain.DataQualitySummary{Tanks: len(report.Tanks)}
+	for _, quality := range report.Tanks {
+		if quality.HasIssues() {
+			report.Summary.TanksWithIssues++
+		}
+		report.Summary.MissingIntervals += quality.MissingIntervals
+		report.Summary.Flatlines += quality.Flatlines
+		report.Summary.OutOfRange += quality.OutOfRange
+	}
+	if report.Tanks == nil {
+		report.Tanks = make([]*domain.TankDataQuality, 0)
+	}
+	return report
+}
+
+// dataQualityReportText compone el cuerpo del correo: el resumen y una línea por cada tanque
+// con problemas
+func dataQualityReportText(report *domain.DataQualityReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Informe de calidad de datos del %s al %s\n\n",
+		report.From.UTC().Format(time.RFC3339), report.To.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Tanques evaluados: %d\n", report.Summary.Tanks)
+	fmt.Fprintf(&b, "Tanques con problemas: %d\n", report.Summary.TanksWithIssues)
+	fmt.Fprintf(&b, "Lecturas faltantes: %d\n", report.Summary.MissingIntervals)
+	fmt.Fprintf(&b, "Rachas congeladas: %d\n", report.Summary.Flatlines)
+	fmt.Fprintf(&b, "Lecturas fuera de rango: %d\n", report.Summary.OutOfRange)
+
+	if report.Summary.TanksWithIssues == 0 {
+		b.WriteString("\nTodos los sensores reportaron con normalidad.\n")
+		return b.String()
+	}
+
+	b.WriteString("\nTanques con problemas:\n")
+	for _, quality := range report.Tanks {
+		if !quality.HasIssues() {
+			continue
+		}
+		if quality.Status == domain.DataQualityNoData {
+			fmt.Fprintf(&b, "- %s (%s): sin lecturas en el periodo\n", quality.TankName, quality.TankID)
+			continue
+		}
+		fmt.Fprintf(&b, "- %s (%s): %d lecturas faltantes, %d rachas congeladas, %d fuera de rango, mayor hueco de %s\n",
+			quality.TankName, quality.TankID, quality.MissingIntervals, quality.Flatlines, quality.OutOfRange,
+			time.Duration(quality.LongestGap*float64(time.Second)).Round(time.Minute))
+	}
+	return b.String()
+}
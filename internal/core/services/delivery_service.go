@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver el servicio de entregas
+var (
+	ErrSupplierNotFound          = errors.New("supplier not found")
+	ErrInvalidSupplier           = errors.New("invalid supplier data")
+	ErrDeliveryOrderNotFound     = errors.New("delivery order not found")
+	ErrInvalidDeliveryOrder      = errors.New("invalid delivery order data")
+	ErrInvalidDeliveryTransition = errors.New("invalid delivery order status transition")
+	ErrDeliveryNotReceived       = errors.New("delivery order has not been received")
+)
+
+const (
+	// reconciliationWindow es el margen alrededor de la recepción en el que se busca la subida de nivel
+	reconciliationWindow = 6 * time.Hour
+	// reconciliationTolerancePercent es la diferencia admitida entre volumen recibido y detectado
+	reconciliationTolerancePercent = 2.0
+)
+
+// DeliveryServiceImpl implementa la interfaz DeliveryService
+type DeliveryServiceImpl struct {
+	supplierRepo    ports.SupplierRepository
+	orderRepo       ports.DeliveryOrderRepository
+	tankService     ports.TankService
+	measurementRepo ports.MeasurementRepository
+}
+
+// NewDeliveryService crea una nueva instancia del servicio de entregas
+func NewDeliveryService(
+	supplierRepo ports.SupplierRepository,
+	orderRepo ports.DeliveryOrderRepository,
+	tankService ports.TankService,
+	measurementRepo ports.MeasurementRepository,
+) ports.DeliveryService {
+	return &DeliveryServiceImpl{
+		supplierRepo:    supplierRepo,
+		orderRepo:       orderRepo,
+		tankService:     tankService,
+		measurementRepo: measurementRepo,
+	}
+}
+
+// GetSupplier obtiene un proveedor por su ID
+func (s *DeliveryServiceImpl) GetSupplier(ctx context.Context, id string) (*domain.Supplier, error) {
+	if id == "" {
+		return nil, ErrInvalidSupplier
+	}
+
+	supplier, err := s.supplierRepo.GetSupplier(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if supplier == nil {
+		return nil, ErrSupplierNotFound
+	}
+
+	return supplier, nil
+}
+
+// GetAllSuppliers obtiene todos los proveedores
+func (s *DeliveryServiceImpl) GetAllSuppliers(ctx context.Context) ([]*domain.Supplier, error) {
+	return s.supplierRepo.GetAllSuppliers(ctx)
+}
+
+// CreateSupplier crea un nuevo proveedor
+func (s *DeliveryServiceImpl) CreateSupplier(ctx context.Context, supplier *domain.Supplier) error {
+	if supplier == nil || supplier.ID == "" || supplier.Name == "" {
+		return ErrInvalidSupplier
+	}
+
+	return s.supplierRepo.SaveSupplier(ctx, supplier)
+}
+
+// UpdateSupplier actualiza un proveedor existente
+func (s *DeliveryServiceImpl) UpdateSupplier(ctx context.Context, supplier *domain.Supplier) error {
+	if supplier == nil || supplier.ID == "" || supplier.Name == "" {
+		return ErrInvalidSupplier
+	}
+
+	if _, err := s.GetSupplier(ctx, supplier.ID); err != nil {
+		return err
+	}
+
+	return s.supplierRepo.UpdateSupplier(ctx, supplier)
+}
+
+// DeleteSupplier elimina un proveedor por su ID
+func (s *DeliveryServiceImpl) DeleteSupplier(ctx context.Context, id string) error {
+	if _, err := s.GetSupplier(ctx, id); err != nil {
+		return err
+	}
+
+	return s.supplierRepo.DeleteSupplier(ctx, id)
+}
+
+// GetDeliveryOrder obtiene un pedido por su ID
+func (s *DeliveryServiceImpl) GetDeliveryOrder(ctx context.Context, id string) (*domain.DeliveryOrder, error) {
+	if id == "" {
+		return nil, ErrInvalidDeliveryOrder
+	}
+
+	order, err := s.orderRepo.GetDeliveryOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order == nil {
+		return nil, ErrDeliveryOrderNotFound
+	}
+
+	// Los pedidos de tanques sin acceso se tratan como inexistentes
+	visible, err := s.visibleTankIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !visible[order.TankID] {
+		return nil, ErrDeliveryOrderNotFound
+	}
+
+	return order, nil
+}
+
+// GetDeliveryOrders obtiene los pedidos de un tanque, o todos si tankID está vacío. Solo se
+// incluyen los pedidos de tanques visibles para el usuario.
+func (s *DeliveryServiceImpl) GetDeliveryOrders(ctx context.Context, tankID string) ([]*domain.DeliveryOrder, error) {
+	orders, err := s.orderRepo.GetDeliveryOrders(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible, err := s.visibleTankIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*domain.DeliveryOrder, 0, len(orders))
+	for _, order := range orders {
+		if visible[order.TankID] {
+			filtered = append(filtered, order)
+		}
+	}
+
+	return filtered, nil
+}
+
+// RequestDelivery registra un nuevo pedido de entrega para un tanque
+func (s *DeliveryServiceImpl) RequestDelivery(ctx context.Context, order *domain.DeliveryOrder) error {
+	if order == nil || order.ID == "" || order.TankID == "" || order.SupplierID == "" || order.RequestedVolume <= 0 {
+		return ErrInvalidDeliveryOrder
+	}
+
+	// Verificamos que el tanque (accesible para el usuario) y el proveedor existan
+	if _, err := s.tankService.GetTank(ctx, order.TankID); err != nil {
+		return err
+	}
+
+	if _, err := s.GetSupplier(ctx, order.SupplierID); err != nil {
+		return err
+	}
+
+	order.Status = domain.DeliveryStatusRequested
+	order.RequestedAt = time.Now()
+	order.ScheduledFor = time.Time{}
+	order.ReceivedAt = time.Time{}
+	order.ReceivedVolume = 0
+
+	return s.orderRepo.SaveDeliveryOrder(ctx, order)
+}
+
+// ScheduleDelivery programa la fecha de entrega de un pedido
+func (s *DeliveryServiceImpl) ScheduleDelivery(ctx context.Context, id string, scheduledFor time.Time) (*domain.DeliveryOrder, error) {
+	if scheduledFor.IsZero() {
+		return nil, ErrInvalidDeliveryOrder
+	}
+
+	order, err := s.GetDeliveryOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !order.CanTransitionTo(domain.DeliveryStatusScheduled) {
+		return nil, ErrInvalidDeliveryTransition
+	}
+
+	order.Status = domain.DeliveryStatusScheduled
+	order.ScheduledFor = scheduledFor
+
+	if err := s.orderRepo.UpdateDeliveryOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ReceiveDelivery marca un pedido como recibido con el volumen declarado por el proveedor
+func (s *DeliveryServiceImpl) ReceiveDelivery(ctx context.Context, id string, receivedVolume float64, receivedAt time.Time) (*domain.DeliveryOrder, error) {
+	if receivedVolume <= 0 {
+		return nil, ErrInvalidDeliveryOrder
+	}
+
+	order, err := s.GetDeliveryOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !order.CanTransitionTo(domain.DeliveryStatusReceived) {
+		return nil, ErrInvalidDeliveryTransition
+	}
+
+	if receivedAt.IsZero() {
+		receivedAt = time.Now()
+	}
+
+	order.Status = domain.DeliveryStatusReceived
+	order.ReceivedVolume = receivedVolume
+	order.ReceivedAt = receivedAt
+
+	if err := s.orderRepo.UpdateDeliveryOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ReconcileDelivery compara el volumen recibido con la subida de nivel detectada por las
+// mediciones alrededor del momento de la recepción
+func (s *DeliveryServiceImpl) ReconcileDelivery(ctx context.Context, id string) (*domain.DeliveryReconciliation, error) {
+	order, err := s.GetDeliveryOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != domain.DeliveryStatusReceived {
+		return nil, ErrDeliveryNotReceived
+	}
+
+	measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, order.TankID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	detected := domain.DetectLevelRise(
+		measurements,
+		order.ReceivedAt.Add(-reconciliationWindow),
+		order.ReceivedAt.Add(reconciliationWindow),
+	)
+
+	difference := order.ReceivedVolume - detected
+	differencePercent := difference / order.ReceivedVolume * 100
+
+	return &domain.DeliveryReconciliation{
+		OrderID:           order.ID,
+		TankID:            order.TankID,
+		RequestedVolume:   order.RequestedVolume,
+		ReceivedVolume:    order.ReceivedVolume,
+		DetectedVolume:    detected,
+		Difference:        difference,
+		DifferencePercent: differencePercent,
+		WithinTolerance:   math.Abs(differencePercent) <= reconciliationTolerancePercent,
+	}, nil
+}
+
+// visibleTankIDs devuelve los IDs de los tanques que el usuario puede ver
+func (s *DeliveryServiceImpl) visibleTankIDs(ctx context.Context) (map[string]bool, error) {
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make(map[string]bool, len(tanks))
+	for _, tank := range tanks {
+		visible[tank.ID] = true
+	}
+
+	return visible, nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver el servicio de comandos de equipos
+var (
+	ErrInvalidDeviceCommand = errors.New("invalid device command")
+	ErrDownlinkUnavailable  = errors.New("no downlink channel is configured")
+	ErrCommandNotDelivered  = errors.New("device command could not be delivered")
+)
+
+// DeviceCommandServiceImpl implementa la interfaz DeviceCommandService
+type DeviceCommandServiceImpl struct {
+	fieldDeviceService ports.FieldDeviceService
+	commandRepo        ports.DeviceCommandRepository
+	publisher          ports.CommandPublisher
+}
+
+// NewDeviceCommandService crea una nueva instancia del servicio de comandos. Con publisher nil
+// los comandos se rechazan con ErrDownlinkUnavailable.
+func NewDeviceCommandService(
+	fieldDeviceService ports.FieldDeviceService,
+	commandRepo ports.DeviceCommandRepository,
+	publisher ports.CommandPublisher,
+) ports.DeviceCommandService {
+	return &DeviceCommandServiceImpl{
+		fieldDeviceService: fieldDeviceService,
+		commandRepo:        commandRepo,
+		publisher:          publisher,
+	}
+}
+
+// SendCommand publica el comando en el tema del equipo y lo registra en su historial, tanto si
+// se entregó como si no. Un cambio de intervalo entregado se guarda también como configuración
+// deseada, para que el equipo no lo revierta en su siguiente consulta de configuración.
+func (s *DeviceCommandServiceImpl) SendCommand(ctx context.Context, command *domain.DeviceCommand) error {
+	if command == nil || command.ID == "" || command.DeviceID == "" || !command.IsValid() {
+		return ErrInvalidDeviceCommand
+	}
+
+	if s.publisher == nil {
+		return ErrDownlinkUnavailable
+	}
+
+	device, err := s.fieldDeviceService.GetFieldDevice(ctx, command.DeviceID)
+	if err != nil {
+		return err
+	}
+
+	if principal := domain.PrincipalFromContext(ctx); principal != nil {
+		command.IssuedBy = principal.Subject
+	}
+	command.CreatedAt = time.Now()
+
+	publishErr := s.publisher.PublishCommand(ctx, command)
+	if publishErr != nil {
+		command.Status = domain.DeviceCommandFailed
+		command.Error = publishErr.Error()
+	} else {
+		command.Status = domain.DeviceCommandSent
+	}
+
+	if err := s.commandRepo.SaveCommand(ctx, command); err != nil {
+		return err
+	}
+
+	if publishErr != nil {
+		return fmt.Errorf("%w: %v", ErrCommandNotDelivered, publishErr)
+	}
+
+	if command.Type == domain.DeviceCommandSetReportingInterval {
+		config := device.Desired
+		config.ReportingInterval = command.ReportingInterval
+		if _, err := s.fieldDeviceService.SetDesiredConfig(ctx, device.ID, device.TankID, config); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetCommands obtiene el historial de comandos enviados a un equipo
+func (s *DeviceCommandServiceImpl) GetCommands(ctx context.Context, deviceID string) ([]*domain.DeviceCommand, error) {
+	if _, err := s.fieldDeviceService.GetFieldDevice(ctx, deviceID); err != nil {
+		return nil, err
+	}
+
+	return s.commandRepo.GetCommands(ctx, deviceID)
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/i18n"
+)
+
+// ErrDigestNotDelivered se devuelve cuando el canal no acepta el resumen
+var ErrDigestNotDelivered = errors.New("digest could not be delivered")
+
+// DigestServiceImpl implementa la interfaz DigestService: resume a diario los tanques de cada
+// canal con resumen, con su nivel, su variación en 24 h, su previsión de vaciado y las alertas
+// acumuladas, y lo entrega como una alerta más por el canal
+type DigestServiceImpl struct {
+	tankService     ports.TankService
+	measurementRepo ports.MeasurementRepository
+	channelRepo     ports.NotificationChannelRepository
+	queueRepo       ports.AlertQueueRepository
+	sender          ports.ChannelSender
+}
+
+// NewDigestService crea una nueva instancia del servicio de resúmenes diarios
+func NewDigestService(
+	tankService ports.TankService,
+	measurementRepo ports.MeasurementRepository,
+	channelRepo ports.NotificationChannelRepository,
+	queueRepo ports.AlertQueueRepository,
+	sender ports.ChannelSender,
+) ports.DigestService {
+	return &DigestServiceImpl{
+		tankService:     tankService,
+		measurementRepo: measurementRepo,
+		channelRepo:     channelRepo,
+		queueRepo:       queueRepo,
+		sender:          sender,
+	}
+}
+
+// GetDigest compone el resumen que recibiría ahora el canal, sin enviarlo
+func (s *DigestServiceImpl) GetDigest(ctx context.Context, channelID string) (*domain.Digest, error) {
+	channel, err := s.channel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, _, err := s.build(ctx, channel, time.Now())
+	return digest, err
+}
+
+// SendDigest envía ahora el resumen del canal, aunque no sea su hora
+func (s *DigestServiceImpl) SendDigest(ctx context.Context, channelID string) (*domain.Digest, error) {
+	channel, err := s.channel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return s.deliver(ctx, channel, time.Now())
+}
+
+// SendDueDigests envía el resumen de los canales habilitados a los que ya les corresponde
+func (s *DigestServiceImpl) SendDueDigests(ctx context.Context) error {
+	channels, err := s.channelRepo.GetAllChannels(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var errs []error
+	for _, channel := range channels {
+		if !channel.Enabled || !channel.DigestDue(now) {
+			continue
+		}
+		if _, err := s.deliver(ctx, channel, now); err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", channel.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// channel obtiene el canal por su ID
+func (s *DigestServiceImpl) channel(ctx context.Context, id string) (*domain.NotificationChannel, error) {
+	if id == "" {
+		return nil, ErrInvalidChannel
+	}
+	channel, err := s.channelRepo.GetChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, ErrChannelNotFound
+	}
+	return channel, nil
+}
+
+// deliver envía el resumen y, solo si se entregó, descarta las alertas que incluye y anota la
+// fecha del envío
+func (s *DigestServiceImpl) deliver(ctx context.Context, channel *domain.NotificationChannel, now time.Time) (*domain.Digest, error) {
+	digest, queued, err := s.build(ctx, channel, now)
+	if err != nil {
+		return nil, err
+	}
+
+	alert := &domain.Alert{
+		Type:      domain.AlertEventDailyDigest,
+		Severity:  digestSeverity(digest),
+		Message:   digestText(digest, channel.Language),
+		Timestamp: now,
+		Digest:    digest,
+	}
+	if err := s.sender.Send(ctx, channel, alert); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDigestNotDelivered, err)
+	}
+
+	var errs []error
+	for _, alert := range queued {
+		if err := s.queueRepo.DeleteQueuedAlert(ctx, alert.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	sent := *channel
+	sent.LastDigestAt = &now
+	if err := s.channelRepo.UpdateChannel(ctx, &sent); err != nil {
+		errs = append(errs, err)
+	}
+	return digest, errors.Join(errs...)
+}
+
+// build compone el resumen del canal con sus tanques y sus alertas en cola
+func (s *DigestServiceImpl) build(ctx context.Context, channel *domain.NotificationChannel, now time.Time) (*domain.Digest, []*domain.QueuedAlert, error) {
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lines := make([]*domain.TankDigest, 0, len(tanks))
+	for _, tank := range tanks {
+		if !channel.MatchesTank(tank) {
+			continue
+		}
+		measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tank.ID, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		lines = append(lines, domain.NewTankDigest(tank, recentMeasurements(measurements, now.Add(-consumptionWindow)), now))
+	}
+
+	queued, err := s.queueRepo.GetQueuedAlerts(ctx, channel.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	alerts := make([]*domain.Alert, 0, len(queued))
+	for _, alert := range queued {
+		alerts = append(alerts, queuedAlertContent(alert))
+	}
+
+	return domain.NewDigest(channel, lines, alerts, now), queued, nil
+}
+
+// digestSeverity es la severidad del resumen: la del tanque más urgente
+func digestSeverity(digest *domain.Digest) string {
+	severity := domain.AlertSeverityInfo
+	for _, tank := range digest.Tanks {
+		switch tank.Status {
+		case "critical":
+			return domain.AlertSeverityCritical
+		case "warning":
+			severity = domain.AlertSeverityWarning
+		}
+	}
+	return severity
+}
+
+// digestStatusLabels nombran el estado de cada tanque en el texto del resumen
+var digestStatusLabels = map[string]string{
+	"critical": "crítico",
+	"warning":  "aviso",
+}
+
+// digestText compone el texto del resumen en el idioma indicado: la primera línea sirve de
+// asunto en los correos
+func digestText(digest *domain.Digest, language string) string {
+	var b strings.Builder
+	b.WriteString(i18n.Sprintf(language, "Resumen diario de los tanques del %s", digest.Date))
+	b.WriteString("\n\n")
+	b.WriteString(i18n.Sprintf(language, "Tanques: %d. Con alertas activas: %d.", len(digest.Tanks), digest.ActiveAlerts))
+	b.WriteString("\n")
+
+	for _, tank := range digest.Tanks {
+		parts := []string{i18n.Sprintf(language, "%.0f%% (%.0f de %.0f L)", tank.Percentage, tank.Level, tank.Capacity)}
+		if tank.Change24h != nil {
+			parts = append(parts, i18n.Sprintf(language, "%+.0f L en 24 h", *tank.Change24h))
+		}
+		if tank.DaysUntilEmpty != nil {
+			parts = append(parts, i18n.Sprintf(language, "se vaciará en %.1f días", *tank.DaysUntilEmpty))
+		}
+
+		name := tank.TankName
+		if label, ok := digestStatusLabels[tank.Status]; ok {
+			name += " [" + i18n.Translate(language, label) + "]"
+		}
+		b.WriteString("- " + name + ": " + strings.Join(parts, ", ") + "\n")
+	}
+
+	if len(digest.Alerts) > 0 {
+		b.WriteString("\n")
+		b.WriteString(i18n.Sprintf(language, "Alertas desde el resumen anterior: %d", len(digest.Alerts)))
+		b.WriteString("\n")
+		for _, alert := range digest.Alerts {
+			message := alert.Message
+			if alert.MessageFormat != "" {
+				message = i18n.Sprintf(language, alert.MessageFormat, alert.MessageArgs...)
+			}
+			b.WriteString("- " + alert.Timestamp.UTC().Format("2006-01-02 15:04") + " UTC " + message + "\n")
+		}
+	}
+	return b.String()
+}
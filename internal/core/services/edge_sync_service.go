@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// EdgeQueueingTankService decora el TankService de una pasarela edge: cada medición guardada
+// localmente se encola para reenviarla a la instancia central
+type EdgeQueueingTankService struct {
+	ports.TankService
+	queue ports.SyncQueueRepository
+}
+
+// NewEdgeQueueingTankService crea un TankService que encola las mediciones guardadas
+func NewEdgeQueueingTankService(inner ports.TankService, queue ports.SyncQueueRepository) ports.TankService {
+	return &EdgeQueueingTankService{
+		TankService: inner,
+		queue:       queue,
+	}
+}
+
+// AddMeasurement guarda la medición y la encola. Si no se puede encolar devuelve el error para
+// que el equipo la reintente: el reintento es un duplicado local, pero vuelve a encolarla.
+func (s *EdgeQueueingTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	assignMeasurementIDs([]*domain.Measurement{measurement})
+	// Las mediciones atrasadas y repetidas también se encolan: la central las guarda en el historial
+	// o las descarta como repetidas
+	err := s.TankService.AddMeasurement(ctx, measurement)
+	if err != nil && !IsStaleMeasurement(err) {
+		return err
+	}
+	if queueErr := s.queue.EnqueueMeasurements(ctx, []*domain.Measurement{measurement}); queueErr != nil {
+		return queueErr
+	}
+	return err
+}
+
+// AddMeasurements guarda el lote y lo encola
+func (s *EdgeQueueingTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	assignMeasurementIDs(measurements)
+	if err := s.TankService.AddMeasurements(ctx, measurements); err != nil {
+		return err
+	}
+	return s.queue.EnqueueMeasurements(ctx, measurements)
+}
+
+// BackfillMeasurements importa las mediciones históricas y las encola; la instancia central
+// descarta las que ya tuviera
+func (s *EdgeQueueingTankService) BackfillMeasurements(ctx context.Context, tankID string, measurements []*domain.Measurement) (*domain.BackfillResult, error) {
+	assignMeasurementIDs(measurements)
+	result, err := s.TankService.BackfillMeasurements(ctx, tankID, measurements)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.queue.EnqueueMeasurements(ctx, measurements); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// assignMeasurementIDs genera el ID de las mediciones que no lo traen: la cola las identifica
+// por su ID al confirmarse el reenvío
+func assignMeasurementIDs(measurements []*domain.Measurement) {
+	for _, measurement := range measurements {
+		if measurement != nil && measurement.ID == "" {
+			measurement.ID = uuid.New().String()
+		}
+	}
+}
+
+// Cursores de la pasarela en el registro de sincronización
+const (
+	edgePushCursor = "edge-push" // Último cambio local enviado a la instancia central
+	edgePullCursor = "edge-pull" // Último cambio de la instancia central aplicado
+)
+
+// EdgeSyncServiceImpl implementa la interfaz EdgeSyncService. Las mediciones solo salen de la
+// cola, y el cursor de los cambios locales solo avanza, cuando la instancia central confirma el
+// lote, así que un corte de la conexión, o un reinicio con instantáneas, no pierde nada; a lo
+// sumo se reenvía un lote ya aplicado, que la instancia central reconoce por su ID.
+type EdgeSyncServiceImpl struct {
+	queue     ports.SyncQueueRepository
+	applier   *tankSyncApplier
+	client    ports.SyncClient
+	gatewayID string
+	upstream  string
+	batchSize int
+
+	mutex      sync.Mutex
+	lastSyncAt *time.Time
+	lastError  string
+}
+
+// NewEdgeSyncService crea el servicio de sincronización de la pasarela gatewayID con upstream.
+// tanks no debe registrar los cambios en el registro de sincronización: el servicio guarda el
+// estado combinado de los cambios que recibe.
+func NewEdgeSyncService(queue ports.SyncQueueRepository, states ports.TankSyncRepository, tanks ports.TankService, client ports.SyncClient, gatewayID, upstream string, batchSize int) ports.EdgeSyncService {
+	if batchSize <= 0 || batchSize > domain.MaxSyncBatchSize {
+		batchSize = domain.MaxSyncBatchSize
+	}
+	return &EdgeSyncServiceImpl{
+		queue:     queue,
+		applier:   &tankSyncApplier{tanks: tanks, states: states},
+		client:    client,
+		gatewayID: gatewayID,
+		upstream:  upstream,
+		batchSize: batchSize,
+	}
+}
+
+// Forward envía primero las mediciones y los cambios locales de configuración y aplica después
+// los de la instancia central. Los rechazos no detienen la sincronización, porque reenviar lo
+// rechazado no cambiaría la respuesta, y se devuelven como error para que queden registrados.
+func (s *EdgeSyncServiceImpl) Forward(ctx context.Context) error {
+	rejected, err := s.push(ctx)
+	if err == nil {
+		var pullRejected []error
+		pullRejected, err = s.pull(ctx)
+		rejected = append(rejected, pullRejected...)
+	}
+
+	s.record(err)
+	return errors.Join(append(rejected, err)...)
+}
+
+// push envía la cola y los cambios locales en lotes, de los más antiguos a los más recientes
+func (s *EdgeSyncServiceImpl) push(ctx context.Context) ([]error, error) {
+	states := s.applier.states
+	cursor, err := states.GetSyncCursor(ctx, edgePushCursor)
+	if err != nil {
+		return nil, err
+	}
+	if cursor == 0 {
+		if err := seedTankSyncStates(ctx, s.applier.tanks, states, s.gatewayID); err != nil {
+			return nil, err
+		}
+	}
+
+	var rejected []error
+	for {
+		measurements, err := s.queue.GetQueuedMeasurements(ctx, s.batchSize)
+		if err != nil {
+			return rejected, err
+		}
+		tanks, err := states.GetTankSyncStates(ctx, cursor, s.batchSize)
+		if err != nil {
+			return rejected, err
+		}
+		if len(measurements) == 0 && len(tanks) == 0 {
+			return rejected, nil
+		}
+
+		batch := &domain.SyncBatch{
+			GatewayID:    s.gatewayID,
+			BatchID:      syncBatchID(measurements, tanks),
+			Measurements: measurements,
+			Tanks:        tanks,
+		}
+		result, err := s.client.Push(ctx, batch)
+		if err != nil {
+			return rejected, err
+		}
+
+		// Solo salen de la cola las mediciones que la instancia central guardó o rechazó para
+		// siempre; una que no figure en la respuesta se reenvía en el siguiente lote
+		ids := make([]string, 0, len(result.Records))
+		for _, record := range result.Records {
+			if record.Status == domain.SyncRecordAccepted || record.Status == domain.SyncRecordRejected {
+				ids = append(ids, record.MeasurementID)
+			}
+		}
+		if len(ids) == 0 && len(tanks) == 0 {
+			return rejected, fmt.Errorf("upstream confirmed none of the %d queued measurements", len(measurements))
+		}
+		if err := s.queue.DeleteQueuedMeasurements(ctx, ids); err != nil {
+			return rejected, err
+		}
+		if len(tanks) > 0 {
+			cursor = tanks[len(tanks)-1].Sequence
+			if err := states.SaveSyncCursor(ctx, edgePushCursor, cursor); err != nil {
+				return rejected, err
+			}
+		}
+
+		for _, record := range result.Records {
+			if record.Status == domain.SyncRecordRejected {
+				rejected = append(rejected, fmt.Errorf("measurement %s of tank %s rejected upstream: %s", record.MeasurementID, record.TankID, record.Error))
+			}
+		}
+		for _, record := range result.Tanks {
+			if record.Status == domain.TankSyncRejected {
+				rejected = append(rejected, fmt.Errorf("tank %s config rejected upstream: %s", record.TankID, record.Error))
+			}
+		}
+	}
+}
+
+// pull aplica los cambios de configuración de la instancia central posteriores al cursor
+func (s *EdgeSyncServiceImpl) pull(ctx context.Context) ([]error, error) {
+	states := s.applier.states
+	cursor, err := states.GetSyncCursor(ctx, edgePullCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var rejected []error
+	for {
+		result, err := s.client.Pull(ctx, cursor, s.batchSize)
+		if err != nil {
+			return rejected, err
+		}
+		for _, state := range result.Tanks {
+			record, err := s.applier.apply(ctx, state)
+			if err != nil {
+				return rejected, err
+			}
+			if record.Status == domain.TankSyncRejected {
+				rejected = append(rejected, fmt.Errorf("tank %s config from upstream rejected: %s", record.TankID, record.Error))
+			}
+		}
+
+		if result.Cursor > cursor {
+			cursor = result.Cursor
+			if err := states.SaveSyncCursor(ctx, edgePullCursor, cursor); err != nil {
+				return rejected, err
+			}
+		}
+		if !result.HasMore || len(result.Tanks) == 0 {
+			return rejected, nil
+		}
+	}
+}
+
+// syncBatchID identifica un lote por su contenido, de modo que el reenvío de un lote cuya
+// respuesta se perdió lleva el mismo ID
+func syncBatchID(measurements []*domain.Measurement, tanks []*domain.TankSyncState) string {
+	hash := sha256.New()
+	for _, measurement := range measurements {
+		fmt.Fprintf(hash, "m:%s\n", measurement.ID)
+	}
+	for _, state := range tanks {
+		fmt.Fprintf(hash, "t:%s:%d\n", state.TankID, state.Sequence)
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// record anota el resultado del último intento de reenvío
+func (s *EdgeSyncServiceImpl) record(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err != nil {
+		s.lastError = err.Error()
+		return
+	}
+	now := time.Now()
+	s.lastSyncAt = &now
+	s.lastError = ""
+}
+
+// GetStatus devuelve el estado de la sincronización con las mediciones pendientes
+func (s *EdgeSyncServiceImpl) GetStatus(ctx context.Context) (*domain.EdgeSyncStatus, error) {
+	pending, err := s.queue.CountQueuedMeasurements(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pullCursor, err := s.applier.states.GetSyncCursor(ctx, edgePullCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return &domain.EdgeSyncStatus{
+		GatewayID:  s.gatewayID,
+		Upstream:   s.upstream,
+		Pending:    pending,
+		PullCursor: pullCursor,
+		LastSyncAt: s.lastSyncAt,
+		LastError:  s.lastError,
+	}, nil
+}
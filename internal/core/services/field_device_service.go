@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver el servicio de equipos de campo
+var (
+	ErrFieldDeviceNotFound  = errors.New("field device not found")
+	ErrInvalidDeviceConfig  = errors.New("invalid device configuration")
+	ErrUnknownConfigVersion = errors.New("device configuration version was never issued")
+)
+
+// FieldDeviceServiceImpl implementa la interfaz FieldDeviceService. El acceso a cada equipo
+// depende del acceso al tanque en el que está instalado.
+type FieldDeviceServiceImpl struct {
+	tankService ports.TankService
+	deviceRepo  ports.FieldDeviceRepository
+}
+
+// NewFieldDeviceService crea una nueva instancia del servicio de equipos de campo
+func NewFieldDeviceService(tankService ports.TankService, deviceRepo ports.FieldDeviceRepository) ports.FieldDeviceService {
+	return &FieldDeviceServiceImpl{
+		tankService: tankService,
+		deviceRepo:  deviceRepo,
+	}
+}
+
+// GetFieldDevices obtiene los equipos instalados en tanques accesibles
+func (s *FieldDeviceServiceImpl) GetFieldDevices(ctx context.Context) ([]*domain.FieldDevice, error) {
+	devices, err := s.deviceRepo.GetAllFieldDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.FieldDevice, 0, len(devices))
+	for _, device := range devices {
+		if _, err := s.tankService.GetTank(ctx, device.TankID); err != nil {
+			if errors.Is(err, ErrTankNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		result = append(result, device)
+	}
+
+	return result, nil
+}
+
+// GetFieldDevice obtiene un equipo con su configuración deseada y aplicada
+func (s *FieldDeviceServiceImpl) GetFieldDevice(ctx context.Context, id string) (*domain.FieldDevice, error) {
+	return s.accessibleDevice(ctx, id)
+}
+
+// SetDesiredConfig asigna una nueva versión de la configuración deseada. El número de versión lo
+// asigna el servicio; el equipo queda pendiente hasta que confirme haberla aplicado.
+func (s *FieldDeviceServiceImpl) SetDesiredConfig(ctx context.Context, id, tankID string, config domain.DeviceConfig) (*domain.FieldDevice, error) {
+	if id == "" || tankID == "" || !config.IsValid() {
+		return nil, ErrInvalidDeviceConfig
+	}
+
+	// Verificamos que el tanque exista y que el usuario tenga acceso
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	device, err := s.deviceRepo.GetFieldDevice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if device == nil {
+		device = &domain.FieldDevice{ID: id}
+	} else if device.TankID != tankID {
+		// Trasladar un equipo requiere también acceso al tanque en el que estaba
+		if _, err := s.accessibleDevice(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	config.Version = device.Desired.Version + 1
+	device.TankID = tankID
+	device.Desired = config
+	device.UpdatedAt = time.Now()
+	device.UpdateSync()
+
+	if err := s.deviceRepo.SaveFieldDevice(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// PollConfig devuelve la configuración deseada del equipo y registra la hora de la consulta
+func (s *FieldDeviceServiceImpl) PollConfig(ctx context.Context, id string) (*domain.DeviceConfig, error) {
+	device, err := s.accessibleDevice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	device.LastPolledAt = &now
+
+	if err := s.deviceRepo.SaveFieldDevice(ctx, device); err != nil {
+		return nil, err
+	}
+
+	desired := device.Desired
+	return &desired, nil
+}
+
+// ReportAppliedConfig registra la configuración aplicada por el equipo. Un equipo puede confirmar
+// una versión anterior a la deseada (p. ej. si la nueva no pudo aplicarse), pero no una que
+// nunca se emitió.
+func (s *FieldDeviceServiceImpl) ReportAppliedConfig(ctx context.Context, id string, config domain.DeviceConfig) (*domain.FieldDevice, error) {
+	if config.Version <= 0 || !config.IsValid() {
+		return nil, ErrInvalidDeviceConfig
+	}
+
+	device, err := s.accessibleDevice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Version > device.Desired.Version {
+		return nil, ErrUnknownConfigVersion
+	}
+
+	now := time.Now()
+	device.Applied = &config
+	device.AppliedAt = &now
+	device.UpdateSync()
+
+	if err := s.deviceRepo.SaveFieldDevice(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// accessibleDevice obtiene el equipo si el usuario tiene acceso a su tanque
+func (s *FieldDeviceServiceImpl) accessibleDevice(ctx context.Context, id string) (*domain.FieldDevice, error) {
+	device, err := s.deviceRepo.GetFieldDevice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if device == nil {
+		return nil, ErrFieldDeviceNotFound
+	}
+
+	if _, err := s.tankService.GetTank(ctx, device.TankID); err != nil {
+		if errors.Is(err, ErrTankNotFound) {
+			return nil, ErrFieldDeviceNotFound
+		}
+		return nil, err
+	}
+
+	return device, nil
+}
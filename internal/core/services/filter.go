@@ -0,0 +1,24 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+// ErrInvalidFilter se devuelve cuando la expresión del parámetro filter no es válida
+var ErrInvalidFilter = errors.New("invalid filter expression")
+
+// parseFilter analiza la expresión de filtro sobre los campos indicados; sin expresión devuelve
+// nil, que no filtra nada
+func parseFilter(expression string, fields map[string]domain.FilterFieldType) (*domain.Filter, error) {
+	if expression == "" {
+		return nil, nil
+	}
+	filter, err := domain.ParseFilter(expression, fields)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFilter, err)
+	}
+	return filter, nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrFutureMeasurement se devuelve cuando una medición está fechada en el futuro más allá del
+// margen admitido, casi siempre por un reloj del equipo mal ajustado
+var ErrFutureMeasurement = errors.New("measurement timestamp is in the future")
+
+// FutureGuardTankService decora un TankService rechazando las mediciones con una marca de
+// tiempo posterior a la hora actual más tolerance. Sin el margen, la deriva habitual de los
+// relojes de los equipos rechazaría lecturas válidas.
+type FutureGuardTankService struct {
+	ports.TankService
+	tolerance time.Duration
+}
+
+// NewFutureGuardTankService crea un TankService que admite mediciones hasta tolerance en el futuro
+func NewFutureGuardTankService(inner ports.TankService, tolerance time.Duration) ports.TankService {
+	return &FutureGuardTankService{
+		TankService: inner,
+		tolerance:   tolerance,
+	}
+}
+
+// AddMeasurement rechaza la medición si está fechada demasiado en el futuro
+func (s *FutureGuardTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if err := s.check(time.Now(), measurement); err != nil {
+		return err
+	}
+	return s.TankService.AddMeasurement(ctx, measurement)
+}
+
+// AddMeasurements rechaza el lote entero si alguna medición está fechada demasiado en el futuro
+func (s *FutureGuardTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	now := time.Now()
+	for _, measurement := range measurements {
+		if err := s.check(now, measurement); err != nil {
+			return err
+		}
+	}
+	return s.TankService.AddMeasurements(ctx, measurements)
+}
+
+// check comprueba la marca de tiempo; sin ella, el servicio asignará la hora de llegada
+func (s *FutureGuardTankService) check(now time.Time, measurement *domain.Measurement) error {
+	if measurement != nil && measurement.Timestamp.After(now.Add(s.tolerance)) {
+		return ErrFutureMeasurement
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// GeofencedPushNotifier implementa ports.AlertNotifier enviando notificaciones push solo a los
+// dispositivos de los técnicos que se encuentran dentro del radio configurado alrededor del
+// tanque que genera la alerta
+type GeofencedPushNotifier struct {
+	tankRepo   ports.TankRepository
+	deviceRepo ports.MobileDeviceRepository
+	sender     ports.PushSender
+	radiusKm   float64
+}
+
+// NewGeofencedPushNotifier crea un notificador push con geovalla de radiusKm kilómetros
+func NewGeofencedPushNotifier(
+	tankRepo ports.TankRepository,
+	deviceRepo ports.MobileDeviceRepository,
+	sender ports.PushSender,
+	radiusKm float64,
+) *GeofencedPushNotifier {
+	return &GeofencedPushNotifier{
+		tankRepo:   tankRepo,
+		deviceRepo: deviceRepo,
+		sender:     sender,
+		radiusKm:   radiusKm,
+	}
+}
+
+// Notify envía la alerta a los dispositivos cercanos al tanque. Los tanques sin ubicación no
+// generan notificaciones push. Las alertas informativas no interrumpen a los técnicos.
+func (n *GeofencedPushNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	if alert.Severity == domain.AlertSeverityInfo {
+		return nil
+	}
+
+	// La ubicación se toma del estado actual: el tanque pudo reubicarse tras generarse la alerta
+	tank, err := n.tankRepo.GetTank(ctx, alert.TankID)
+	if err != nil {
+		return err
+	}
+
+	if tank == nil || tank.Location == nil {
+		return nil
+	}
+
+	devices, err := n.deviceRepo.GetDevices(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, device := range n.devicesNear(devices, *tank.Location) {
+		err := n.sender.Push(ctx, device, alert)
+		if errors.Is(err, domain.ErrDeviceUnregistered) {
+			// El token caducó (aplicación desinstalada o sesión cerrada): damos de baja el dispositivo
+			if err := n.deviceRepo.DeleteDevice(ctx, device.ID); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("device %s: %w", device.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// devicesNear filtra los dispositivos que están dentro del radio alrededor de la ubicación
+func (n *GeofencedPushNotifier) devicesNear(devices []*domain.MobileDevice, location domain.GeoLocation) []*domain.MobileDevice {
+	near := make([]*domain.MobileDevice, 0, len(devices))
+	for _, device := range devices {
+		if device.Location.DistanceKm(location) <= n.radiusKm {
+			near = append(near, device)
+		}
+	}
+	return near
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// HazmatAlertNotifier implementa ports.AlertNotifier entregando cada alerta por los canales y,
+// si es crítica, avisando por correo a los contactos de materiales peligrosos de la política del
+// líquido del tanque
+type HazmatAlertNotifier struct {
+	next        ports.AlertNotifier
+	policyRepo  ports.LiquidPolicyRepository
+	emailSender ports.EmailSender
+}
+
+// NewHazmatAlertNotifier crea un notificador que avisa a los contactos de materiales peligrosos
+// después de delegar en next
+func NewHazmatAlertNotifier(next ports.AlertNotifier, policyRepo ports.LiquidPolicyRepository, emailSender ports.EmailSender) *HazmatAlertNotifier {
+	return &HazmatAlertNotifier{
+		next:        next,
+		policyRepo:  policyRepo,
+		emailSender: emailSender,
+	}
+}
+
+// Notify entrega la alerta por los canales y avisa a los contactos de la política. Un fallo del
+// correo no impide la entrega por los canales, pero se devuelve junto con sus errores.
+func (n *HazmatAlertNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	err := n.next.Notify(ctx, alert)
+	if alert.Severity != domain.AlertSeverityCritical || alert.Tank == nil {
+		return err
+	}
+
+	policy, policyErr := n.policyRepo.GetPolicy(ctx, alert.Tank.LiquidType)
+	if policyErr != nil {
+		return errors.Join(err, policyErr)
+	}
+	if policy == nil || len(policy.HazmatContacts) == 0 {
+		return err
+	}
+
+	subject := fmt.Sprintf("Alerta crítica en un tanque de %s: %s", alert.Tank.LiquidType, alert.Tank.Name)
+	if sendErr := n.emailSender.SendEmail(ctx, policy.HazmatContacts, subject, hazmatAlertText(alert)); sendErr != nil {
+		return errors.Join(err, fmt.Errorf("hazmat contacts: %w", sendErr))
+	}
+	return err
+}
+
+// hazmatAlertText compone el cuerpo del aviso con el mensaje de la alerta y el estado del tanque
+func hazmatAlertText(alert *domain.Alert) string {
+	var b strings.Builder
+	b.WriteString(alert.Message + "\n\n")
+	fmt.Fprintf(&b, "Tanque: %s (%s)\n", alert.Tank.Name, alert.TankID)
+	fmt.Fprintf(&b, "Líquido: %s\n", alert.Tank.LiquidType)
+	if alert.Tank.SiteID != "" {
+		fmt.Fprintf(&b, "Sitio: %s\n", alert.Tank.SiteID)
+	}
+	fmt.Fprintf(&b, "Nivel: %.2f L de %.2f L (%.2f%%)\n", alert.Tank.CurrentLevel, alert.Tank.Capacity, alert.Tank.GetLevelPercentage())
+	fmt.Fprintf(&b, "Fecha: %s\n", alert.Timestamp.In(alert.Tank.TimeLocation()).Format("2006-01-02 15:04:05 MST"))
+	return b.String()
+}
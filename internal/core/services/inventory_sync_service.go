@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores del servicio de sincronización de inventario
+var (
+	ErrConnectorNotFound     = errors.New("erp connector not found")
+	ErrInventoryNotDelivered = errors.New("inventory not delivered to erp")
+)
+
+// maxInventorySyncHistory limita las sincronizaciones que se conservan por conexión
+const maxInventorySyncHistory = 50
+
+// InventorySyncServiceImpl implementa la interfaz InventorySyncService
+type InventorySyncServiceImpl struct {
+	tankService ports.TankService
+	exporters   map[string]ports.InventoryExporter
+
+	mutex   sync.RWMutex
+	history map[string][]*domain.InventorySync // clave: conexión, de la más reciente a la más antigua
+}
+
+// NewInventorySyncService crea una nueva instancia del servicio de sincronización con las
+// conexiones a los ERP indicadas
+func NewInventorySyncService(tankService ports.TankService, exporters ...ports.InventoryExporter) ports.InventorySyncService {
+	service := &InventorySyncServiceImpl{
+		tankService: tankService,
+		exporters:   make(map[string]ports.InventoryExporter, len(exporters)),
+		history:     make(map[string][]*domain.InventorySync, len(exporters)),
+	}
+	for _, exporter := range exporters {
+		service.exporters[exporter.Connector().Name] = exporter
+	}
+	return service
+}
+
+// Sync envía el inventario actual por la conexión indicada. Los fallos transitorios se reintentan
+// con el mismo lote y una espera que se duplica en cada intento. Si la entrega no se confirma se
+// devuelve el resultado junto con ErrInventoryNotDelivered.
+func (s *InventorySyncServiceImpl) Sync(ctx context.Context, connectorName string) (*domain.InventorySync, error) {
+	exporter, ok := s.exporters[connectorName]
+	if !ok {
+		return nil, ErrConnectorNotFound
+	}
+	connector := exporter.Connector()
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	batch := domain.BuildInventoryBatch(uuid.New().String(), connector.Name, tanks, connector.TankSelector, started)
+	result := &domain.InventorySync{
+		BatchID:   batch.ID,
+		Connector: connector.Name,
+		Tanks:     len(batch.Records),
+		StartedAt: started,
+	}
+
+	confirmation, err := s.export(ctx, exporter, connector, batch, result)
+	result.FinishedAt = time.Now()
+	if err != nil {
+		result.Status = domain.InventorySyncFailed
+		result.Error = err.Error()
+	} else {
+		result.Status = domain.InventorySyncDelivered
+		result.Confirmation = confirmation
+		result.DeliveredAt = &result.FinishedAt
+	}
+	s.record(result)
+
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrInventoryNotDelivered, err)
+	}
+	return result, nil
+}
+
+// export entrega el lote con los reintentos de la conexión y anota los intentos en result
+func (s *InventorySyncServiceImpl) export(ctx context.Context, exporter ports.InventoryExporter, connector domain.InventoryConnector, batch *domain.InventoryBatch, result *domain.InventorySync) (string, error) {
+	backoff := connector.RetryBackoff
+	for {
+		result.Attempts++
+		confirmation, err := exporter.Export(ctx, batch)
+		if err == nil {
+			return confirmation, nil
+		}
+		if errors.Is(err, domain.ErrInventoryRejected) || result.Attempts > connector.Retries {
+			return "", err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", fmt.Errorf("%v (%w)", err, ctx.Err())
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// record guarda el resultado al principio del historial de la conexión
+func (s *InventorySyncServiceImpl) record(result *domain.InventorySync) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	history := append([]*domain.InventorySync{result}, s.history[result.Connector]...)
+	if len(history) > maxInventorySyncHistory {
+		history = history[:maxInventorySyncHistory]
+	}
+	s.history[result.Connector] = history
+}
+
+// GetSyncs devuelve las últimas sincronizaciones de la conexión, de la más reciente a la más antigua
+func (s *InventorySyncServiceImpl) GetSyncs(ctx context.Context, connectorName string) ([]*domain.InventorySync, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, ok := s.exporters[connectorName]; !ok {
+		return nil, ErrConnectorNotFound
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	syncs := make([]*domain.InventorySync, len(s.history[connectorName]))
+	for i, result := range s.history[connectorName] {
+		resultCopy := *result
+		syncs[i] = &resultCopy
+	}
+	return syncs, nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// KPIServiceImpl implementa la interfaz KPIService
+type KPIServiceImpl struct {
+	tankService     ports.TankService
+	measurementRepo ports.MeasurementRepository
+	statusRepo      ports.StatusHistoryRepository
+}
+
+// NewKPIService crea una nueva instancia del servicio de indicadores
+func NewKPIService(
+	tankService ports.TankService,
+	measurementRepo ports.MeasurementRepository,
+	statusRepo ports.StatusHistoryRepository,
+) ports.KPIService {
+	return &KPIServiceImpl{
+		tankService:     tankService,
+		measurementRepo: measurementRepo,
+		statusRepo:      statusRepo,
+	}
+}
+
+// GetTankKPIs calcula los indicadores de un tanque en el periodo [from, to]
+func (s *KPIServiceImpl) GetTankKPIs(ctx context.Context, tankID string, from, to time.Time) (*domain.TankKPIs, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	// Verificamos que el tanque exista y que el usuario tenga acceso
+	tank, err := s.tankService.GetTank(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tankID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.statusRepo.GetStatusChanges(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.BuildTankKPIs(tank, measurements, changes, from, to), nil
+}
+
+// GetTankStats calcula las estadísticas de las lecturas de un tanque en el periodo [from, to]
+func (s *KPIServiceImpl) GetTankStats(ctx context.Context, tankID string, from, to time.Time) (*domain.TankLevelStats, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	tank, err := s.tankService.GetTank(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tankID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.BuildTankLevelStats(tank, measurements, from, to), nil
+}
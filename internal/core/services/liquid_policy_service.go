@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores del servicio de políticas por tipo de líquido
+var (
+	ErrLiquidPolicyNotFound = errors.New("liquid policy not found")
+	ErrInvalidLiquidPolicy  = errors.New("invalid liquid policy")
+)
+
+// LiquidPolicyServiceImpl implementa la interfaz LiquidPolicyService
+type LiquidPolicyServiceImpl struct {
+	policyRepo ports.LiquidPolicyRepository
+}
+
+// NewLiquidPolicyService crea una nueva instancia del servicio de políticas por tipo de líquido
+func NewLiquidPolicyService(policyRepo ports.LiquidPolicyRepository) ports.LiquidPolicyService {
+	return &LiquidPolicyServiceImpl{
+		policyRepo: policyRepo,
+	}
+}
+
+// GetPolicies devuelve todas las políticas ordenadas por tipo de líquido
+func (s *LiquidPolicyServiceImpl) GetPolicies(ctx context.Context) ([]*domain.LiquidPolicy, error) {
+	return s.policyRepo.GetPolicies(ctx)
+}
+
+// GetPolicy devuelve la política del tipo de líquido
+func (s *LiquidPolicyServiceImpl) GetPolicy(ctx context.Context, liquidType string) (*domain.LiquidPolicy, error) {
+	policy, err := s.policyRepo.GetPolicy(ctx, liquidType)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, ErrLiquidPolicyNotFound
+	}
+	return policy, nil
+}
+
+// SavePolicy valida la política y reemplaza la que tuviera el tipo de líquido
+func (s *LiquidPolicyServiceImpl) SavePolicy(ctx context.Context, policy *domain.LiquidPolicy) (*domain.LiquidPolicy, error) {
+	if policy == nil {
+		return nil, ErrInvalidLiquidPolicy
+	}
+
+	saved := &domain.LiquidPolicy{
+		LiquidType:     strings.TrimSpace(policy.LiquidType),
+		MinSafetyStock: policy.MinSafetyStock,
+		MaxFill:        policy.MaxFill,
+		UpdatedAt:      time.Now(),
+	}
+	for _, contact := range policy.HazmatContacts {
+		saved.HazmatContacts = append(saved.HazmatContacts, strings.TrimSpace(contact))
+	}
+	if err := saved.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLiquidPolicy, err)
+	}
+
+	if err := s.policyRepo.SavePolicy(ctx, saved); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// DeletePolicy elimina la política del tipo de líquido; sus tanques conservan sus umbrales
+func (s *LiquidPolicyServiceImpl) DeletePolicy(ctx context.Context, liquidType string) error {
+	if _, err := s.GetPolicy(ctx, liquidType); err != nil {
+		return err
+	}
+	return s.policyRepo.DeletePolicy(ctx, liquidType)
+}
+
+// LiquidPolicyTankService decora un TankService aplicando la política del tipo de líquido: los
+// tanques nuevos toman de ella su umbral de alerta, y los cambios de umbrales que la incumplen se
+// rechazan con domain.ErrSafetyPolicyViolation. Los tanques anteriores a la política se admiten
+// sin cambios hasta que se modifican sus umbrales.
+type LiquidPolicyTankService struct {
+	ports.TankService
+	policyRepo ports.LiquidPolicyRepository
+}
+
+// NewLiquidPolicyTankService crea un TankService que aplica las políticas por tipo de líquido
+func NewLiquidPolicyTankService(inner ports.TankService, policyRepo ports.LiquidPolicyRepository) ports.TankService {
+	return &LiquidPolicyTankService{
+		TankService: inner,
+		policyRepo:  policyRepo,
+	}
+}
+
+// CreateTank completa el umbral de alerta con la política del líquido y comprueba que la cumpla
+func (s *LiquidPolicyTankService) CreateTank(ctx context.Context, tank *domain.Tank) error {
+	if tank == nil {
+		return ErrInvalidTank
+	}
+
+	policy, err := s.policyRepo.GetPolicy(ctx, tank.LiquidType)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		policy.ApplyDefaults(tank)
+		if err := policy.Check(tank); err != nil {
+			return err
+		}
+	}
+
+	return s.TankService.CreateTank(ctx, tank)
+}
+
+// UpdateTank comprueba la política del líquido si cambian los umbrales, la capacidad o el líquido
+func (s *LiquidPolicyTankService) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	if tank == nil || tank.ID == "" {
+		return ErrInvalidTank
+	}
+
+	existing, err := s.TankService.GetTank(ctx, tank.ID)
+	if err != nil {
+		return err
+	}
+
+	thresholdsChanged := existing.AlertThreshold != tank.AlertThreshold ||
+		existing.Reorder != tank.Reorder ||
+		existing.Capacity != tank.Capacity ||
+		domain.LiquidPolicyKey(existing.LiquidType) != domain.LiquidPolicyKey(tank.LiquidType)
+	if thresholdsChanged {
+		policy, err := s.policyRepo.GetPolicy(ctx, tank.LiquidType)
+		if err != nil {
+			return err
+		}
+		if policy != nil {
+			if err := policy.Check(tank); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.TankService.UpdateTank(ctx, tank)
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// LiveEventTankService decora un TankService para difundir en tiempo real cada medición guardada
+type LiveEventTankService struct {
+	ports.TankService
+	publisher ports.LiveEventPublisher
+}
+
+// NewLiveEventTankService crea un TankService que publica las mediciones guardadas
+func NewLiveEventTankService(inner ports.TankService, publisher ports.LiveEventPublisher) ports.TankService {
+	return &LiveEventTankService{
+		TankService: inner,
+		publisher:   publisher,
+	}
+}
+
+// AddMeasurement guarda la medición y la publica con el estado actualizado del tanque
+func (s *LiveEventTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if err := s.TankService.AddMeasurement(ctx, measurement); err != nil {
+		return err
+	}
+
+	s.publish(ctx, []*domain.Measurement{measurement})
+	return nil
+}
+
+// AddMeasurements guarda el lote y publica cada una de sus mediciones
+func (s *LiveEventTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	if err := s.TankService.AddMeasurements(ctx, measurements); err != nil {
+		return err
+	}
+
+	s.publish(ctx, measurements)
+	return nil
+}
+
+// publish difunde las mediciones ya guardadas. Un tanque que no se puede leer no impide guardar
+// la medición: su evento simplemente no se publica.
+func (s *LiveEventTankService) publish(ctx context.Context, measurements []*domain.Measurement) {
+	if !s.publisher.HasSubscribers() {
+		return
+	}
+
+	tanks := make(map[string]*domain.Tank)
+	for _, measurement := range measurements {
+		tank, ok := tanks[measurement.TankID]
+		if !ok {
+			tank, _ = s.TankService.GetTank(ctx, measurement.TankID)
+			tanks[measurement.TankID] = tank
+		}
+		if tank != nil {
+			s.publisher.Publish(domain.NewMeasurementEvent(tank, measurement))
+		}
+	}
+}
+
+// LiveAlertNotifier implementa ports.AlertNotifier difundiendo en tiempo real cada alerta antes
+// de entregarla por los canales
+type LiveAlertNotifier struct {
+	next      ports.AlertNotifier
+	publisher ports.LiveEventPublisher
+}
+
+// NewLiveAlertNotifier crea un notificador que publica las alertas antes de delegar en next
+func NewLiveAlertNotifier(next ports.AlertNotifier, publisher ports.LiveEventPublisher) *LiveAlertNotifier {
+	return &LiveAlertNotifier{
+		next:      next,
+		publisher: publisher,
+	}
+}
+
+// Notify publica la alerta y la entrega por los canales
+func (n *LiveAlertNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	n.publisher.Publish(domain.NewAlertEvent(alert))
+	return n.next.Notify(ctx, alert)
+}
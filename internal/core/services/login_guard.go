@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver la protección de la autenticación
+var (
+	ErrCaptchaRequired   = errors.New("captcha verification required")
+	ErrInvalidCaptcha    = errors.New("captcha verification failed")
+	ErrInvalidLoginGuard = errors.New("invalid login guard configuration")
+)
+
+// maxTrackedClients es el número de clientes a partir del cual se descartan los fallos vencidos,
+// para que un ataque desde muchas direcciones no agote la memoria
+const maxTrackedClients = 10000
+
+// LoginGuardImpl implementa la interfaz LoginGuard. Los fallos se cuentan en memoria por
+// dirección IP del cliente, porque la API no tiene cuentas con contraseña: los intentos son
+// tokens o inicios de sesión OIDC, y un atacante puede probar cualquier identidad.
+type LoginGuardImpl struct {
+	config   domain.LoginGuardConfig
+	events   ports.SecurityEventRepository
+	captcha  ports.CaptchaVerifier // nil si no se exige CAPTCHA
+	failures map[string]*domain.LoginFailures
+	mutex    sync.Mutex
+}
+
+// NewLoginGuard crea una nueva instancia de la protección de la autenticación. Sin captcha no se
+// exige verificación CAPTCHA aunque la configuración lo pida.
+func NewLoginGuard(config domain.LoginGuardConfig, events ports.SecurityEventRepository, captcha ports.CaptchaVerifier) (*LoginGuardImpl, error) {
+	if config.MaxFailures < 0 || config.CaptchaAfter < 0 {
+		return nil, ErrInvalidLoginGuard
+	}
+	if config.MaxFailures > 0 && (config.Window <= 0 || config.Lockout <= 0) {
+		return nil, ErrInvalidLoginGuard
+	}
+	if captcha == nil {
+		config.CaptchaAfter = 0
+	}
+	return &LoginGuardImpl{
+		config:   config,
+		events:   events,
+		captcha:  captcha,
+		failures: make(map[string]*domain.LoginFailures),
+	}, nil
+}
+
+// Check devuelve cuánto falta para que termine el bloqueo del cliente, o 0 si puede intentarlo
+func (g *LoginGuardImpl) Check(ctx context.Context, clientIP string) time.Duration {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	now := time.Now()
+	failures, ok := g.failures[clientIP]
+	if !ok || !failures.IsLocked(now) {
+		return 0
+	}
+	return failures.LockedUntil.Sub(now)
+}
+
+// RequiresCaptcha indica si el cliente acumula suficientes fallos para exigirle CAPTCHA
+func (g *LoginGuardImpl) RequiresCaptcha(ctx context.Context, clientIP string) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	failures, ok := g.failures[clientIP]
+	return ok && failures.RequiresCaptcha(time.Now(), g.config)
+}
+
+// VerifyCaptcha comprueba la respuesta CAPTCHA del cliente con el proveedor. Una respuesta
+// ausente o rechazada se audita y cuenta como intento fallido.
+func (g *LoginGuardImpl) VerifyCaptcha(ctx context.Context, clientIP, response string) error {
+	if g.captcha == nil {
+		return nil
+	}
+
+	response = strings.TrimSpace(response)
+	failure := ErrCaptchaRequired
+	if response != "" {
+		valid, err := g.captcha.VerifyCaptcha(ctx, response, clientIP)
+		if err != nil {
+			return err
+		}
+		if valid {
+			return nil
+		}
+		failure = ErrInvalidCaptcha
+	}
+
+	attempt := domain.LoginAttempt{ClientIP: clientIP, Method: domain.LoginMethodOIDC, Reason: failure.Error()}
+	if err := g.saveEvent(ctx, domain.SecurityEventCaptchaFailed, attempt, nil); err != nil {
+		return err
+	}
+	if err := g.RecordFailure(ctx, attempt); err != nil {
+		return err
+	}
+	return failure
+}
+
+// RecordFailure cuenta un intento fallido y bloquea al cliente si alcanza el máximo. Los
+// intentos durante un bloqueo no lo prolongan, pero quedan auditados.
+func (g *LoginGuardImpl) RecordFailure(ctx context.Context, attempt domain.LoginAttempt) error {
+	g.mutex.Lock()
+	now := time.Now()
+	failures, ok := g.failures[attempt.ClientIP]
+	if !ok {
+		g.pruneLocked(now)
+		failures = &domain.LoginFailures{WindowStart: now}
+		g.failures[attempt.ClientIP] = failures
+	}
+
+	eventType := domain.SecurityEventLoginFailed
+	var lockedUntil *time.Time
+	if failures.IsLocked(now) {
+		eventType = domain.SecurityEventLoginBlocked
+	} else if failures.RecordFailure(now, g.config) {
+		eventType = domain.SecurityEventLockout
+		until := failures.LockedUntil
+		lockedUntil = &until
+	}
+	g.mutex.Unlock()
+
+	return g.saveEvent(ctx, eventType, attempt, lockedUntil)
+}
+
+// RecordSuccess reinicia los fallos del cliente. Solo se auditan los inicios de sesión
+// interactivos: un token válido se presenta en cada solicitud.
+func (g *LoginGuardImpl) RecordSuccess(ctx context.Context, attempt domain.LoginAttempt) error {
+	g.mutex.Lock()
+	if failures, ok := g.failures[attempt.ClientIP]; ok && !failures.IsLocked(time.Now()) {
+		delete(g.failures, attempt.ClientIP)
+	}
+	g.mutex.Unlock()
+
+	if attempt.Method != domain.LoginMethodOIDC {
+		return nil
+	}
+	return g.saveEvent(ctx, domain.SecurityEventLoginSucceeded, attempt, nil)
+}
+
+// GetSecurityEvents devuelve los últimos eventos de seguridad, del más reciente al más antiguo
+func (g *LoginGuardImpl) GetSecurityEvents(ctx context.Context, limit int) ([]*domain.SecurityEvent, error) {
+	return g.events.GetSecurityEvents(ctx, limit)
+}
+
+// saveEvent añade un evento al registro de auditoría
+func (g *LoginGuardImpl) saveEvent(ctx context.Context, eventType string, attempt domain.LoginAttempt, lockedUntil *time.Time) error {
+	return g.events.SaveSecurityEvent(ctx, &domain.SecurityEvent{
+		ID:          uuid.New().String(),
+		Type:        eventType,
+		Method:      attempt.Method,
+		ClientIP:    attempt.ClientIP,
+		Subject:     attempt.Subject,
+		Path:        attempt.Path,
+		Reason:      attempt.Reason,
+		LockedUntil: lockedUntil,
+		CreatedAt:   time.Now(),
+	})
+}
+
+// pruneLocked descarta los fallos vencidos cuando hay demasiados clientes. Requiere el mutex.
+func (g *LoginGuardImpl) pruneLocked(now time.Time) {
+	if len(g.failures) < maxTrackedClients {
+		return
+	}
+	for clientIP, failures := range g.failures {
+		if failures.IsStale(now, g.config) {
+			delete(g.failures, clientIP)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrInvalidSeries se devuelve cuando los parámetros de la serie uniforme no son válidos
+var ErrInvalidSeries = errors.New("invalid measurement series parameters")
+
+// errFilterLimitReached detiene el recorrido filtrado al alcanzar el límite de mediciones
+var errFilterLimitReached = errors.New("filter limit reached")
+
+// MeasurementServiceImpl implementa la interfaz MeasurementService
+type MeasurementServiceImpl struct {
+	tankService     ports.TankService
+	measurementRepo ports.MeasurementRepository
+	streamer        ports.MeasurementStreamer
+}
+
+// NewMeasurementService crea una nueva instancia del servicio de historial de mediciones
+func NewMeasurementService(
+	tankService ports.TankService,
+	measurementRepo ports.MeasurementRepository,
+	streamer ports.MeasurementStreamer,
+) ports.MeasurementService {
+	return &MeasurementServiceImpl{
+		tankService:     tankService,
+		measurementRepo: measurementRepo,
+		streamer:        streamer,
+	}
+}
+
+// GetMeasurements obtiene las mediciones más recientes de un tanque accesible que cumplen el filtro
+func (s *MeasurementServiceImpl) GetMeasurements(ctx context.Context, tankID string, limit int, filter string) ([]*domain.Measurement, error) {
+	parsed, err := parseFilter(filter, domain.MeasurementFilterFields)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+	if parsed == nil {
+		return s.measurementRepo.GetMeasurementsByTankID(ctx, tankID, limit)
+	}
+
+	measurements := []*domain.Measurement{}
+	err = s.streamFiltered(ctx, tankID, parsed, limit, func(m *domain.Measurement) error {
+		measurements = append(measurements, m)
+		return nil
+	})
+	return measurements, err
+}
+
+// StreamMeasurements entrega las mediciones de un tanque accesible que cumplen el filtro a
+// medida que se leen del repositorio, para exportar historiales largos sin acumularlos en memoria
+func (s *MeasurementServiceImpl) StreamMeasurements(ctx context.Context, tankID string, limit int, filter string, fn func(*domain.Measurement) error) error {
+	parsed, err := parseFilter(filter, domain.MeasurementFilterFields)
+	if err != nil {
+		return err
+	}
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return err
+	}
+	if parsed == nil {
+		return s.streamer.StreamMeasurementsByTankID(ctx, tankID, limit, fn)
+	}
+
+	return s.streamFiltered(ctx, tankID, parsed, limit, fn)
+}
+
+// streamFiltered delega el filtro en el repositorio si sabe evaluarlo; si no, lo aplica a cada
+// medición leída y corta el recorrido al alcanzar limit
+func (s *MeasurementServiceImpl) streamFiltered(ctx context.Context, tankID string, filter *domain.Filter, limit int, fn func(*domain.Measurement) error) error {
+	if filtered, ok := s.streamer.(ports.FilteredMeasurementStreamer); ok {
+		return filtered.StreamFilteredMeasurementsByTankID(ctx, tankID, filter, limit, fn)
+	}
+
+	matched := 0
+	err := s.streamer.StreamMeasurementsByTankID(ctx, tankID, 0, func(m *domain.Measurement) error {
+		if !filter.Match(m) {
+			return nil
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+		matched++
+		if limit > 0 && matched >= limit {
+			return errFilterLimitReached
+		}
+		return nil
+	})
+	if errors.Is(err, errFilterLimitReached) {
+		return nil
+	}
+	return err
+}
+
+// GetSeries remuestrea en pasos iguales las mediciones de un tanque accesible
+func (s *MeasurementServiceImpl) GetSeries(ctx context.Context, tankID string, from, to time.Time, step time.Duration, fill string) (*domain.MeasurementSeries, error) {
+	tank, err := s.tankService.GetTank(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tankID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := domain.BuildMeasurementSeries(tank, measurements, from, to, step, fill)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSeries, err)
+	}
+	return series, nil
+}
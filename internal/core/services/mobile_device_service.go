@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/i18n"
+)
+
+// Errores que puede devolver el servicio de dispositivos móviles
+var (
+	ErrDeviceNotFound = errors.New("mobile device not found")
+	ErrInvalidDevice  = errors.New("invalid mobile device data")
+)
+
+// MobileDeviceServiceImpl implementa la interfaz MobileDeviceService. Cada técnico gestiona sus
+// propios dispositivos; los administradores y las llamadas internas pueden gestionar todos.
+type MobileDeviceServiceImpl struct {
+	deviceRepo ports.MobileDeviceRepository
+}
+
+// NewMobileDeviceService crea una nueva instancia del servicio de dispositivos
+func NewMobileDeviceService(deviceRepo ports.MobileDeviceRepository) ports.MobileDeviceService {
+	return &MobileDeviceServiceImpl{
+		deviceRepo: deviceRepo,
+	}
+}
+
+// GetDevices obtiene los dispositivos de un usuario. Los usuarios que no son administradores
+// solo ven los suyos.
+func (s *MobileDeviceServiceImpl) GetDevices(ctx context.Context, subject string) ([]*domain.MobileDevice, error) {
+	if principal := domain.PrincipalFromContext(ctx); principal != nil && !principal.HasRole(domain.RoleAdmin) {
+		subject = principal.Subject
+	}
+
+	return s.deviceRepo.GetDevices(ctx, subject)
+}
+
+// RegisterDevice registra un dispositivo con su token push y su ubicación actual
+func (s *MobileDeviceServiceImpl) RegisterDevice(ctx context.Context, device *domain.MobileDevice) error {
+	if device == nil || device.ID == "" || device.Token == "" || !device.Location.IsValid() {
+		return ErrInvalidDevice
+	}
+
+	if device.Platform != domain.PushPlatformFCM && device.Platform != domain.PushPlatformAPNs {
+		return ErrInvalidDevice
+	}
+
+	if device.Language != "" && !i18n.IsSupported(device.Language) {
+		return ErrInvalidDevice
+	}
+
+	// El dispositivo pertenece siempre a quien lo registra
+	if principal := domain.PrincipalFromContext(ctx); principal != nil {
+		device.Subject = principal.Subject
+	}
+
+	if device.Subject == "" {
+		return ErrInvalidDevice
+	}
+
+	now := time.Now()
+	device.Registered = now
+	device.LocatedAt = now
+
+	return s.deviceRepo.SaveDevice(ctx, device)
+}
+
+// UpdateLocation actualiza la última ubicación conocida del dispositivo
+func (s *MobileDeviceServiceImpl) UpdateLocation(ctx context.Context, id string, location domain.GeoLocation) (*domain.MobileDevice, error) {
+	if !location.IsValid() {
+		return nil, ErrInvalidDevice
+	}
+
+	device, err := s.ownedDevice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	device.Location = location
+	device.LocatedAt = time.Now()
+
+	if err := s.deviceRepo.SaveDevice(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// DeleteDevice da de baja un dispositivo
+func (s *MobileDeviceServiceImpl) DeleteDevice(ctx context.Context, id string) error {
+	if _, err := s.ownedDevice(ctx, id); err != nil {
+		return err
+	}
+
+	return s.deviceRepo.DeleteDevice(ctx, id)
+}
+
+// ownedDevice obtiene el dispositivo si pertenece al usuario del contexto
+func (s *MobileDeviceServiceImpl) ownedDevice(ctx context.Context, id string) (*domain.MobileDevice, error) {
+	device, err := s.deviceRepo.GetDevice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if device == nil {
+		return nil, ErrDeviceNotFound
+	}
+
+	principal := domain.PrincipalFromContext(ctx)
+	if principal != nil && !principal.HasRole(domain.RoleAdmin) && principal.Subject != device.Subject {
+		return nil, ErrDeviceNotFound
+	}
+
+	return device, nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// errReferenceFound detiene el recorrido de las mediciones al encontrar la lectura de referencia
+var errReferenceFound = errors.New("reference measurement found")
+
+// MobileOverviewServiceImpl implementa la interfaz MobileOverviewService
+type MobileOverviewServiceImpl struct {
+	tankService ports.TankService
+	streamer    ports.MeasurementStreamer
+}
+
+// NewMobileOverviewService crea una nueva instancia del servicio de la pantalla de inicio móvil
+func NewMobileOverviewService(tankService ports.TankService, streamer ports.MeasurementStreamer) ports.MobileOverviewService {
+	return &MobileOverviewServiceImpl{
+		tankService: tankService,
+		streamer:    streamer,
+	}
+}
+
+// GetOverview resume los tanques accesibles con su tendencia frente al nivel de hace
+// domain.MobileTrendWindow
+func (s *MobileOverviewServiceImpl) GetOverview(ctx context.Context) (*domain.MobileOverview, error) {
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rows := make([]*domain.MobileTank, 0, len(tanks))
+	for _, tank := range tanks {
+		reference, err := s.reference(ctx, tank.ID, now.Add(-domain.MobileTrendWindow))
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, domain.NewMobileTank(tank, reference))
+	}
+
+	return domain.NewMobileOverview(rows, now), nil
+}
+
+// reference devuelve la lectura más reciente no posterior a since, o nil si no hay. Las mediciones
+// se recorren de la más reciente a la más antigua, así que basta con leer hasta la primera.
+func (s *MobileOverviewServiceImpl) reference(ctx context.Context, tankID string, since time.Time) (*domain.Measurement, error) {
+	var reference *domain.Measurement
+	err := s.streamer.StreamMeasurementsByTankID(ctx, tankID, 0, func(m *domain.Measurement) error {
+		if m.Timestamp.After(since) {
+			return nil
+		}
+		reference = m
+		return errReferenceFound
+	})
+	if err != nil && !errors.Is(err, errReferenceFound) {
+		return nil, err
+	}
+	return reference, nil
+}
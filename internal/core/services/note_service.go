@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrInvalidNote se devuelve cuando la nota no tiene texto
+var ErrInvalidNote = errors.New("invalid note data")
+
+// maxNoteLength limita la longitud de las observaciones
+const maxNoteLength = 2000
+
+// NoteServiceImpl implementa la interfaz NoteService
+type NoteServiceImpl struct {
+	tankService     ports.TankService
+	noteRepo        ports.TankNoteRepository
+	measurementRepo ports.MeasurementRepository
+	statusRepo      ports.StatusHistoryRepository
+}
+
+// NewNoteService crea una nueva instancia del servicio de notas
+func NewNoteService(
+	tankService ports.TankService,
+	noteRepo ports.TankNoteRepository,
+	measurementRepo ports.MeasurementRepository,
+	statusRepo ports.StatusHistoryRepository,
+) ports.NoteService {
+	return &NoteServiceImpl{
+		tankService:     tankService,
+		noteRepo:        noteRepo,
+		measurementRepo: measurementRepo,
+		statusRepo:      statusRepo,
+	}
+}
+
+// AddNote registra una observación sobre un tanque. El autor es el usuario autenticado si lo hay.
+func (s *NoteServiceImpl) AddNote(ctx context.Context, note *domain.TankNote) error {
+	if note == nil || note.ID == "" {
+		return ErrInvalidNote
+	}
+
+	note.Text = strings.TrimSpace(note.Text)
+	if note.Text == "" || len(note.Text) > maxNoteLength {
+		return ErrInvalidNote
+	}
+
+	if _, err := s.tankService.GetTank(ctx, note.TankID); err != nil {
+		return err
+	}
+
+	if author := principalName(ctx); author != "" {
+		note.Author = author
+	}
+
+	note.CreatedAt = time.Now()
+
+	return s.noteRepo.SaveNote(ctx, note)
+}
+
+// GetNotes obtiene las notas de un tanque en el periodo indicado
+func (s *NoteServiceImpl) GetNotes(ctx context.Context, tankID string, from, to time.Time) ([]*domain.TankNote, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	notes, err := s.noteRepo.GetNotes(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NotesInPeriod(notes, from, to), nil
+}
+
+// GetTimeline obtiene las mediciones, notas y cambios de estado del tanque en orden cronológico
+func (s *NoteServiceImpl) GetTimeline(ctx context.Context, tankID string, from, to time.Time) ([]*domain.TimelineEntry, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tankID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := s.noteRepo.GetNotes(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.statusRepo.GetStatusChanges(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.BuildTimeline(measurements, notes, changes, from, to), nil
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/i18n"
+)
+
+// Errores que puede devolver el servicio de notificaciones
+var (
+	ErrChannelNotFound = errors.New("notification channel not found")
+	ErrInvalidChannel  = errors.New("invalid notification channel data")
+)
+
+// NotificationServiceImpl implementa la interfaz NotificationService. Distribuye cada alerta entre
+// los canales habilitados respetando sus horarios; si no hay canales configurados, delega en el
+// notificador predeterminado.
+type NotificationServiceImpl struct {
+	channelRepo     ports.NotificationChannelRepository
+	queueRepo       ports.AlertQueueRepository
+	sender          ports.ChannelSender
+	defaultNotifier ports.AlertNotifier
+	accessService   ports.AccessService
+}
+
+// NewNotificationService crea una nueva instancia del servicio de notificaciones
+func NewNotificationService(
+	channelRepo ports.NotificationChannelRepository,
+	queueRepo ports.AlertQueueRepository,
+	sender ports.ChannelSender,
+	defaultNotifier ports.AlertNotifier,
+	accessService ports.AccessService,
+) ports.NotificationService {
+	return &NotificationServiceImpl{
+		channelRepo:     channelRepo,
+		queueRepo:       queueRepo,
+		sender:          sender,
+		defaultNotifier: defaultNotifier,
+		accessService:   accessService,
+	}
+}
+
+// Notify entrega la alerta a los canales habilitados cuyo selector de tanques la incluye, según su
+// horario. Si ningún canal la recibe, se entrega al notificador predeterminado.
+func (s *NotificationServiceImpl) Notify(ctx context.Context, alert *domain.Alert) error {
+	enabled, err := s.enabledChannels(ctx)
+	if err != nil {
+		return err
+	}
+
+	channels := make([]*domain.NotificationChannel, 0, len(enabled))
+	for _, channel := range enabled {
+		if channel.MatchesAlert(alert) {
+			channels = append(channels, channel)
+		}
+	}
+
+	if len(channels) == 0 {
+		return s.defaultNotifier.Notify(ctx, alert)
+	}
+
+	now := time.Now()
+	delivered := make(map[string]bool)
+	var errs []error
+
+	for _, channel := range channels {
+		if delivered[channel.ID] {
+			continue
+		}
+
+		if err := s.deliver(ctx, channel, alert, now, delivered); err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", channel.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deliver envía la alerta si el canal está en horario; en caso contrario la desvía al canal
+// alternativo o la encola, según la configuración del canal
+func (s *NotificationServiceImpl) deliver(
+	ctx context.Context,
+	channel *domain.NotificationChannel,
+	alert *domain.Alert,
+	now time.Time,
+	delivered map[string]bool,
+) error {
+	// Los canales con resumen diario reciben la alerta en su próximo resumen
+	if channel.IsDigest() {
+		delivered[channel.ID] = true
+		return s.enqueue(ctx, channel, alert, now)
+	}
+
+	if channel.Schedule.IsActiveFor(now, alert.Tank) {
+		delivered[channel.ID] = true
+		return s.sender.Send(ctx, channel, alert)
+	}
+
+	if channel.OutOfSchedule == domain.OutOfScheduleRoute && channel.FallbackChannelID != "" {
+		fallback, err := s.channelRepo.GetChannel(ctx, channel.FallbackChannelID)
+		if err != nil {
+			return err
+		}
+
+		if fallback != nil && fallback.Enabled && fallback.Schedule.IsActiveFor(now, alert.Tank) {
+			if delivered[fallback.ID] {
+				return nil
+			}
+			delivered[fallback.ID] = true
+			return s.sender.Send(ctx, fallback, alert)
+		}
+	}
+
+	// Sin alternativa disponible, retenemos la alerta hasta que el canal vuelva a estar activo
+	return s.enqueue(ctx, channel, alert, now)
+}
+
+// enqueue retiene la alerta en la cola del canal
+func (s *NotificationServiceImpl) enqueue(ctx context.Context, channel *domain.NotificationChannel, alert *domain.Alert, now time.Time) error {
+	return s.queueRepo.EnqueueAlert(ctx, &domain.QueuedAlert{
+		ID:        uuid.New().String(),
+		ChannelID: channel.ID,
+		TankID:    alert.TankID,
+		Message:   alert.Message,
+		QueuedAt:  now,
+		Alert:     alert,
+	})
+}
+
+// FlushQueuedAlerts envía las alertas encoladas cuyos canales vuelven a estar en horario. Las de
+// los canales con resumen diario esperan al resumen.
+func (s *NotificationServiceImpl) FlushQueuedAlerts(ctx context.Context) error {
+	alerts, err := s.queueRepo.GetQueuedAlerts(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var errs []error
+
+	for _, alert := range alerts {
+		channel, err := s.channelRepo.GetChannel(ctx, alert.ChannelID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// Si el canal se eliminó o deshabilitó, descartamos la alerta
+		if channel == nil || !channel.Enabled {
+			if err := s.queueRepo.DeleteQueuedAlert(ctx, alert.ID); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		content := queuedAlertContent(alert)
+		if channel.IsDigest() || !channel.Schedule.IsActiveFor(now, content.Tank) {
+			continue
+		}
+
+		if err := s.sender.Send(ctx, channel, content); err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", channel.Name, err))
+			continue
+		}
+
+		if err := s.queueRepo.DeleteQueuedAlert(ctx, alert.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// queuedAlertContent devuelve la alerta que se encoló. Las alertas encoladas antes de que la cola
+// guardara la alerta completa solo conservan el tanque y el mensaje.
+func queuedAlertContent(queued *domain.QueuedAlert) *domain.Alert {
+	if queued.Alert != nil {
+		return queued.Alert
+	}
+	return &domain.Alert{
+		Severity:  domain.AlertSeverityWarning,
+		TankID:    queued.TankID,
+		Message:   queued.Message,
+		Timestamp: queued.QueuedAt,
+	}
+}
+
+// GetQueuedAlerts obtiene las alertas retenidas de un canal que cumplen el filtro, solo de los
+// tanques a los que tiene acceso el principal del contexto
+func (s *NotificationServiceImpl) GetQueuedAlerts(ctx context.Context, channelID, filter string) ([]*domain.QueuedAlert, error) {
+	parsed, err := parseFilter(filter, domain.QueuedAlertFilterFields)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetChannel(ctx, channelID); err != nil {
+		return nil, err
+	}
+
+	alerts, err := s.queueRepo.GetQueuedAlerts(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	matching := make([]*domain.QueuedAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		if parsed != nil && !parsed.Match(alert) {
+			continue
+		}
+
+		// El acceso se comprueba con el tanque guardado en la alerta, como en las evidencias
+		tank := &domain.Tank{ID: alert.TankID}
+		if alert.Alert != nil && alert.Alert.Tank != nil {
+			tank = alert.Alert.Tank
+		}
+		allowed, err := s.accessService.CanAccessTank(ctx, tank)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			matching = append(matching, alert)
+		}
+	}
+	return matching, nil
+}
+
+// GetChannel obtiene un canal por su ID
+func (s *NotificationServiceImpl) GetChannel(ctx context.Context, id string) (*domain.NotificationChannel, error) {
+	if id == "" {
+		return nil, ErrInvalidChannel
+	}
+
+	channel, err := s.channelRepo.GetChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if channel == nil {
+		return nil, ErrChannelNotFound
+	}
+
+	return channel, nil
+}
+
+// GetAllChannels obtiene todos los canales
+func (s *NotificationServiceImpl) GetAllChannels(ctx context.Context) ([]*domain.NotificationChannel, error) {
+	return s.channelRepo.GetAllChannels(ctx)
+}
+
+// CreateChannel crea un nuevo canal de notificación
+func (s *NotificationServiceImpl) CreateChannel(ctx context.Context, channel *domain.NotificationChannel) error {
+	if err := s.validateChannel(ctx, channel); err != nil {
+		return err
+	}
+
+	return s.channelRepo.SaveChannel(ctx, channel)
+}
+
+// UpdateChannel actualiza un canal existente
+func (s *NotificationServiceImpl) UpdateChannel(ctx context.Context, channel *domain.NotificationChannel) error {
+	if err := s.validateChannel(ctx, channel); err != nil {
+		return err
+	}
+
+	existing, err := s.GetChannel(ctx, channel.ID)
+	if err != nil {
+		return err
+	}
+	// La fecha del último resumen solo la cambia el envío del resumen
+	channel.LastDigestAt = existing.LastDigestAt
+	// Las respuestas no incluyen el secreto de firma: sin uno nuevo, se conserva el guardado
+	if channel.Secret == "" && channel.Type == domain.ChannelTypeWebhook {
+		channel.Secret = existing.Secret
+	}
+
+	return s.channelRepo.UpdateChannel(ctx, channel)
+}
+
+// DeleteChannel elimina un canal por su ID
+func (s *NotificationServiceImpl) DeleteChannel(ctx context.Context, id string) error {
+	if _, err := s.GetChannel(ctx, id); err != nil {
+		return err
+	}
+
+	return s.channelRepo.DeleteChannel(ctx, id)
+}
+
+// enabledChannels devuelve los canales habilitados
+func (s *NotificationServiceImpl) enabledChannels(ctx context.Context) ([]*domain.NotificationChannel, error) {
+	channels, err := s.channelRepo.GetAllChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make([]*domain.NotificationChannel, 0, len(channels))
+	for _, channel := range channels {
+		if channel.Enabled {
+			enabled = append(enabled, channel)
+		}
+	}
+
+	return enabled, nil
+}
+
+// validateChannel comprueba los datos del canal y aplica los valores predeterminados
+func (s *NotificationServiceImpl) validateChannel(ctx context.Context, channel *domain.NotificationChannel) error {
+	if channel == nil || channel.ID == "" || channel.Name == "" {
+		return ErrInvalidChannel
+	}
+
+	switch channel.Type {
+	case domain.ChannelTypeLog, domain.ChannelTypePush:
+	case domain.ChannelTypeWebhook, domain.ChannelTypeSlack, domain.ChannelTypeEmail:
+		if channel.Target == "" {
+			return ErrInvalidChannel
+		}
+	default:
+		return ErrInvalidChannel
+	}
+	// Solo los webhooks genéricos se firman; Slack y el resto tienen su propia autenticación
+	if channel.Secret != "" && channel.Type != domain.ChannelTypeWebhook {
+		return ErrInvalidChannel
+	}
+
+	switch channel.Delivery {
+	case "":
+		channel.Delivery = domain.DeliveryImmediate
+	case domain.DeliveryImmediate:
+	case domain.DeliveryDigest:
+		// Las notificaciones push avisan a los técnicos cercanos a un tanque, no resumen todos
+		if channel.Type == domain.ChannelTypePush {
+			return ErrInvalidChannel
+		}
+		if channel.DigestTime == "" {
+			channel.DigestTime = domain.DefaultDigestTime
+		}
+		if err := domain.ValidateDigestTime(channel.DigestTime); err != nil {
+			return err
+		}
+	default:
+		return ErrInvalidChannel
+	}
+
+	if channel.Language != "" && !i18n.IsSupported(channel.Language) {
+		return ErrInvalidChannel
+	}
+
+	if err := channel.Schedule.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := domain.ParseLabelSelector(channel.TankSelector); err != nil {
+		return ErrInvalidChannel
+	}
+
+	switch channel.OutOfSchedule {
+	case "":
+		channel.OutOfSchedule = domain.OutOfScheduleQueue
+	case domain.OutOfScheduleQueue:
+	case domain.OutOfScheduleRoute:
+		if channel.FallbackChannelID == "" || channel.FallbackChannelID == channel.ID {
+			return ErrInvalidChannel
+		}
+		if _, err := s.GetChannel(ctx, channel.FallbackChannelID); err != nil {
+			return err
+		}
+	default:
+		return ErrInvalidChannel
+	}
+
+	return nil
+}
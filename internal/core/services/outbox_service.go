@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// OutboxServiceImpl implementa la interfaz OutboxService. Entrega los eventos de la bandeja de
+// salida por el notificador en el orden en que se guardaron; un evento solo se marca como
+// entregado cuando el notificador lo confirma, así que puede repetirse tras un corte.
+type OutboxServiceImpl struct {
+	outboxRepo ports.OutboxRepository
+	notifier   ports.AlertNotifier
+	config     domain.OutboxConfig
+}
+
+// NewOutboxService crea una nueva instancia del servicio de entrega de la bandeja de salida
+func NewOutboxService(outboxRepo ports.OutboxRepository, notifier ports.AlertNotifier, config domain.OutboxConfig) ports.OutboxService {
+	return &OutboxServiceImpl{
+		outboxRepo: outboxRepo,
+		notifier:   notifier,
+		config:     config,
+	}
+}
+
+// RelayEvents entrega los eventos pendientes cuyo intento ya venció y elimina los entregados
+// hace más de domain.OutboxRetention. Devuelve los errores de entrega para que queden registrados.
+func (s *OutboxServiceImpl) RelayEvents(ctx context.Context) error {
+	events, err := s.outboxRepo.GetDueEvents(ctx, time.Now(), s.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		if err := s.notifier.Notify(ctx, event.Alert); err != nil {
+			event.MarkFailed(err, time.Now(), s.config)
+			errs = append(errs, fmt.Errorf("outbox event %s (attempt %d): %w", event.ID, event.Attempts, err))
+		} else {
+			event.MarkDelivered(time.Now())
+		}
+
+		if err := s.outboxRepo.UpdateEvent(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if _, err := s.outboxRepo.DeleteDeliveredBefore(ctx, time.Now().Add(-domain.OutboxRetention)); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrInvalidProvisioningSpec indica que el estado declarado no es coherente
+var ErrInvalidProvisioningSpec = errors.New("invalid provisioning spec")
+
+// ProvisioningServiceImpl implementa la interfaz ProvisioningService sobre los servicios de
+// tanques, equipos de campo y notificaciones, de modo que se aplican sus mismas validaciones
+type ProvisioningServiceImpl struct {
+	tankService         ports.TankService
+	deviceService       ports.FieldDeviceService
+	notificationService ports.NotificationService
+}
+
+// NewProvisioningService crea una nueva instancia del servicio de aprovisionamiento
+func NewProvisioningService(tankService ports.TankService, deviceService ports.FieldDeviceService, notificationService ports.NotificationService) ports.ProvisioningService {
+	return &ProvisioningServiceImpl{
+		tankService:         tankService,
+		deviceService:       deviceService,
+		notificationService: notificationService,
+	}
+}
+
+// Provision reconcilia el estado declarado: crea los recursos que no existen y actualiza los que
+// difieren. Los recursos que no aparecen en la declaración no se tocan. Volver a aplicar la misma
+// declaración no produce cambios.
+func (s *ProvisioningServiceImpl) Provision(ctx context.Context, spec *domain.ProvisioningSpec) (*domain.ProvisioningResult, error) {
+	return s.reconcile(ctx, spec, true)
+}
+
+// Plan calcula los cambios que aplicaría Provision sin modificar nada. Las validaciones que
+// dependen de otros recursos (como el canal alternativo de una regla nueva) solo se comprueban
+// al aplicar.
+func (s *ProvisioningServiceImpl) Plan(ctx context.Context, spec *domain.ProvisioningSpec) (*domain.ProvisioningResult, error) {
+	return s.reconcile(ctx, spec, false)
+}
+
+// reconcile compara el estado declarado con el actual y, si apply es true, aplica las diferencias
+func (s *ProvisioningServiceImpl) reconcile(ctx context.Context, spec *domain.ProvisioningSpec, apply bool) (*domain.ProvisioningResult, error) {
+	if err := validateProvisioningSpec(spec); err != nil {
+		return nil, err
+	}
+
+	result := &domain.ProvisioningResult{DryRun: !apply}
+
+	// Los tanques primero: los sensores se instalan en ellos
+	if err := s.provisionTanks(ctx, spec.Tanks, apply, result); err != nil {
+		return result, err
+	}
+	if err := s.provisionSensors(ctx, spec.Sensors, apply, result); err != nil {
+		return result, err
+	}
+	if err := s.provisionAlertRules(ctx, spec.AlertRules, apply, result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// provisionTanks crea o actualiza los tanques declarados
+func (s *ProvisioningServiceImpl) provisionTanks(ctx context.Context, tanks []*domain.Tank, apply bool, result *domain.ProvisioningResult) error {
+	existing, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*domain.Tank, len(existing))
+	for _, tank := range existing {
+		byID[tank.ID] = tank
+	}
+
+	for _, declared := range tanks {
+		desired := *declared
+		if desired.AlertThreshold <= 0 {
+			desired.AlertThreshold = domain.DefaultAlertThreshold
+		}
+
+		current, ok := byID[desired.ID]
+		if !ok {
+			if apply {
+				tank := desired
+				if err := s.tankService.CreateTank(ctx, &tank); err != nil {
+					return fmt.Errorf("creating tank %s: %w", desired.ID, err)
+				}
+			}
+			result.Add(&domain.ProvisioningChange{Kind: domain.ProvisioningKindTank, ID: desired.ID, Action: domain.ProvisioningActionCreate})
+			continue
+		}
+
+		fields := domain.TankSpecDiff(current, &desired)
+		if len(fields) == 0 {
+			result.Add(&domain.ProvisioningChange{Kind: domain.ProvisioningKindTank, ID: desired.ID, Action: domain.ProvisioningActionUnchanged})
+			continue
+		}
+
+		if apply {
+			// Trabajamos sobre una copia para conservar el nivel y la temperatura medidos
+			tank := *current
+			domain.ApplyTankSpec(&tank, &desired)
+			if err := s.tankService.UpdateTank(ctx, &tank); err != nil {
+				return fmt.Errorf("updating tank %s: %w", desired.ID, err)
+			}
+		}
+		result.Add(&domain.ProvisioningChange{Kind: domain.ProvisioningKindTank, ID: desired.ID, Action: domain.ProvisioningActionUpdate, Fields: fields})
+	}
+
+	return nil
+}
+
+// provisionSensors asigna la configuración deseada de los equipos declarados. Solo se emite una
+// nueva versión cuando la configuración o el tanque cambian, para no forzar a los equipos a
+// volver a aplicarla en cada arranque.
+func (s *ProvisioningServiceImpl) provisionSensors(ctx context.Context, sensors []*domain.FieldDevice, apply bool, result *domain.ProvisioningResult) error {
+	existing, err := s.deviceService.GetFieldDevices(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*domain.FieldDevice, len(existing))
+	for _, device := range existing {
+		byID[device.ID] = device
+	}
+
+	for _, desired := range sensors {
+		change := &domain.ProvisioningChange{Kind: domain.ProvisioningKindSensor, ID: desired.ID}
+
+		current, ok := byID[desired.ID]
+		if !ok {
+			change.Action = domain.ProvisioningActionCreate
+		} else {
+			change.Fields = domain.DeviceConfigDiff(current.Desired, desired.Desired)
+			if current.TankID != desired.TankID {
+				change.Fields = append([]string{"tank_id"}, change.Fields...)
+			}
+			change.Action = domain.ProvisioningActionUpdate
+			if len(change.Fields) == 0 {
+				change.Action = domain.ProvisioningActionUnchanged
+			}
+		}
+
+		if apply && change.Action != domain.ProvisioningActionUnchanged {
+			if _, err := s.deviceService.SetDesiredConfig(ctx, desired.ID, desired.TankID, desired.Desired); err != nil {
+				return fmt.Errorf("configuring sensor %s: %w", desired.ID, err)
+			}
+		}
+		result.Add(change)
+	}
+
+	return nil
+}
+
+// provisionAlertRules crea o actualiza los canales de notificación declarados. Se aplican en el
+// orden declarado, por lo que un canal de respaldo debe declararse antes que los que lo usan.
+func (s *ProvisioningServiceImpl) provisionAlertRules(ctx context.Context, rules []*domain.NotificationChannel, apply bool, result *domain.ProvisioningResult) error {
+	existing, err := s.notificationService.GetAllChannels(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*domain.NotificationChannel, len(existing))
+	for _, channel := range existing {
+		byID[channel.ID] = channel
+	}
+
+	for _, desired := range rules {
+		channel := *desired
+		if channel.OutOfSchedule == "" {
+			channel.OutOfSchedule = domain.OutOfScheduleQueue
+		}
+		if channel.IsDigest() && channel.DigestTime == "" {
+			channel.DigestTime = domain.DefaultDigestTime
+		}
+
+		current, ok := byID[desired.ID]
+		if !ok {
+			if apply {
+				if err := s.notificationService.CreateChannel(ctx, &channel); err != nil {
+					return fmt.Errorf("creating alert rule %s: %w", desired.ID, err)
+				}
+			}
+			result.Add(&domain.ProvisioningChange{Kind: domain.ProvisioningKindAlertRule, ID: desired.ID, Action: domain.ProvisioningActionCreate})
+			continue
+		}
+
+		fields := domain.ChannelSpecDiff(current, &channel)
+		if len(fields) == 0 {
+			result.Add(&domain.ProvisioningChange{Kind: domain.ProvisioningKindAlertRule, ID: desired.ID, Action: domain.ProvisioningActionUnchanged})
+			continue
+		}
+
+		if apply {
+			if err := s.notificationService.UpdateChannel(ctx, &channel); err != nil {
+				return fmt.Errorf("updating alert rule %s: %w", desired.ID, err)
+			}
+		}
+		result.Add(&domain.ProvisioningChange{Kind: domain.ProvisioningKindAlertRule, ID: desired.ID, Action: domain.ProvisioningActionUpdate, Fields: fields})
+	}
+
+	return nil
+}
+
+// validateProvisioningSpec comprueba que los identificadores sean únicos y que cada sensor
+// se instale en un tanque declarado
+func validateProvisioningSpec(spec *domain.ProvisioningSpec) error {
+	if spec == nil {
+		return ErrInvalidProvisioningSpec
+	}
+
+	tanks := make(map[string]bool, len(spec.Tanks))
+	for _, tank := range spec.Tanks {
+		if tank == nil || tank.ID == "" {
+			return fmt.Errorf("%w: tank without id", ErrInvalidProvisioningSpec)
+		}
+		if tanks[tank.ID] {
+			return fmt.Errorf("%w: duplicate tank %s", ErrInvalidProvisioningSpec, tank.ID)
+		}
+		if err := tank.Labels.Validate(); err != nil {
+			return fmt.Errorf("%w: tank %s has invalid labels", ErrInvalidProvisioningSpec, tank.ID)
+		}
+		if !domain.IsValidTimezone(tank.Timezone) {
+			return fmt.Errorf("%w: tank %s has an unknown timezone %q", ErrInvalidProvisioningSpec, tank.ID, tank.Timezone)
+		}
+		tanks[tank.ID] = true
+	}
+
+	sensors := make(map[string]bool, len(spec.Sensors))
+	for _, sensor := range spec.Sensors {
+		if sensor == nil || sensor.ID == "" {
+			return fmt.Errorf("%w: sensor without id", ErrInvalidProvisioningSpec)
+		}
+		if sensors[sensor.ID] {
+			return fmt.Errorf("%w: duplicate sensor %s", ErrInvalidProvisioningSpec, sensor.ID)
+		}
+		if !tanks[sensor.TankID] {
+			return fmt.Errorf("%w: sensor %s references undeclared tank %s", ErrInvalidProvisioningSpec, sensor.ID, sensor.TankID)
+		}
+		sensors[sensor.ID] = true
+	}
+
+	rules := make(map[string]bool, len(spec.AlertRules))
+	for _, rule := range spec.AlertRules {
+		if rule == nil || rule.ID == "" {
+			return fmt.Errorf("%w: alert rule without id", ErrInvalidProvisioningSpec)
+		}
+		if rules[rule.ID] {
+			return fmt.Errorf("%w: duplicate alert rule %s", ErrInvalidProvisioningSpec, rule.ID)
+		}
+		if _, err := domain.ParseLabelSelector(rule.TankSelector); err != nil {
+			return fmt.Errorf("%w: alert rule %s has an invalid tank selector", ErrInvalidProvisioningSpec, rule.ID)
+		}
+		rules[rule.ID] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores del servicio de purgas
+var (
+	ErrInvalidPurge     = errors.New("invalid purge request")
+	ErrPurgeJobNotFound = errors.New("purge job not found")
+)
+
+// maxPurgeJobHistory limita los trabajos de purga que se conservan para consultar su resultado
+const maxPurgeJobHistory = 50
+
+// PurgeServiceImpl implementa la interfaz PurgeService. Las purgas se ejecutan en segundo plano
+// con permisos propios: la ruta ya exige el rol admin, y una purga de organización debe alcanzar
+// todos sus tanques aunque quien la pide no tenga concesiones sobre ellos.
+type PurgeServiceImpl struct {
+	tankRepo          ports.TankRepository
+	measurements      ports.MeasurementPurger
+	purgers           map[string]ports.TankDataPurger // clave: tipo de dato informado en PurgeJob.Deleted
+	attachmentRepo    ports.AttachmentRepository
+	storage           ports.FileStorage
+	states            ports.TankStateStore
+	cache             ports.CacheInvalidator // nil sin caché de respuestas
+	organizationLabel string
+
+	ctx     context.Context // Se cancela si el cierre no puede esperar a las purgas en curso
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+
+	mutex sync.RWMutex
+	jobs  []*domain.PurgeJob // del más reciente al más antiguo
+}
+
+// NewPurgeService crea el servicio de purgas. purgers son los repositorios con datos de los
+// tanques que se vacían en una purga de organización, identificados por el tipo de dato; los
+// tanques de una organización se reconocen por la etiqueta organizationLabel.
+func NewPurgeService(
+	tankRepo ports.TankRepository,
+	measurements ports.MeasurementPurger,
+	purgers map[string]ports.TankDataPurger,
+	attachmentRepo ports.AttachmentRepository,
+	storage ports.FileStorage,
+	states ports.TankStateStore,
+	cache ports.CacheInvalidator,
+	organizationLabel string,
+) *PurgeServiceImpl {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PurgeServiceImpl{
+		tankRepo:          tankRepo,
+		measurements:      measurements,
+		purgers:           purgers,
+		attachmentRepo:    attachmentRepo,
+		storage:           storage,
+		states:            states,
+		cache:             cache,
+		organizationLabel: organizationLabel,
+		ctx:               ctx,
+		cancel:            cancel,
+	}
+}
+
+// StartPurge valida la solicitud y lanza la purga en segundo plano
+func (s *PurgeServiceImpl) StartPurge(ctx context.Context, request domain.PurgeRequest) (*domain.PurgeJob, error) {
+	switch request.Scope {
+	case domain.PurgeScopeMeasurements:
+		if request.TankID == "" || request.Before == nil || request.Before.IsZero() {
+			return nil, fmt.Errorf("%w: tank_id and before are required", ErrInvalidPurge)
+		}
+		exists, err := s.tankExists(ctx, request.TankID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrTankNotFound
+		}
+		request.Organization = ""
+	case domain.PurgeScopeOrganization:
+		// Los tanques sin organización no forman un grupo que alguien pueda pedir eliminar
+		if request.Organization == "" || request.Organization == domain.UnassignedOrganization {
+			return nil, fmt.Errorf("%w: organization is required", ErrInvalidPurge)
+		}
+		request.TankID = ""
+		request.Before = nil
+	default:
+		return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidPurge, request.Scope)
+	}
+
+	job := &domain.PurgeJob{
+		ID:        uuid.New().String(),
+		Request:   request,
+		Status:    domain.PurgeJobPending,
+		Deleted:   make(map[string]int),
+		CreatedAt: time.Now(),
+	}
+	if principal := domain.PrincipalFromContext(ctx); principal != nil {
+		job.RequestedBy = principal.Subject
+	}
+	snapshot := s.record(job)
+
+	s.running.Add(1)
+	go s.run(job)
+
+	return snapshot, nil
+}
+
+// GetPurgeJobs devuelve los últimos trabajos de purga, del más reciente al más antiguo
+func (s *PurgeServiceImpl) GetPurgeJobs(ctx context.Context) ([]*domain.PurgeJob, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	jobs := make([]*domain.PurgeJob, len(s.jobs))
+	for i, job := range s.jobs {
+		jobs[i] = job.Clone()
+	}
+	return jobs, nil
+}
+
+// GetPurgeJob devuelve el estado actual de un trabajo de purga
+func (s *PurgeServiceImpl) GetPurgeJob(ctx context.Context, id string) (*domain.PurgeJob, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, job := range s.jobs {
+		if job.ID == id {
+			return job.Clone(), nil
+		}
+	}
+	return nil, ErrPurgeJobNotFound
+}
+
+// Wait espera a que terminen las purgas en curso. Si ctx vence antes, las interrumpe: los
+// datos ya eliminados no se recuperan y el trabajo queda como fallido.
+func (s *PurgeServiceImpl) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// tankExists indica si el tanque está registrado, sin depender del error que devuelva cada
+// repositorio para un identificador desconocido
+func (s *PurgeServiceImpl) tankExists(ctx context.Context, tankID string) (bool, error) {
+	tanks, err := s.tankRepo.GetAllTanks(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, tank := range tanks {
+		if tank.ID == tankID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// run ejecuta la purga tanque a tanque, anotando el progreso en el trabajo
+func (s *PurgeServiceImpl) run(job *domain.PurgeJob) {
+	defer s.running.Done()
+
+	s.update(job, func() {
+		started := time.Now()
+		job.Status = domain.PurgeJobRunning
+		job.StartedAt = &started
+	})
+
+	tankIDs, err := s.targetTanks(s.ctx, job.Request)
+	if err != nil {
+		s.finish(job, err)
+		return
+	}
+	s.update(job, func() { job.TanksTotal = len(tankIDs) })
+
+	for _, tankID := range tankIDs {
+		deleted, err := s.purgeTank(s.ctx, tankID, job.Request)
+		s.update(job, func() {
+			for kind, count := range deleted {
+				job.Deleted[kind] += count
+			}
+			if err == nil {
+				job.MarkTankDone()
+			}
+		})
+		if err != nil {
+			s.finish(job, fmt.Errorf("tank %s: %w", tankID, err))
+			return
+		}
+	}
+
+	s.finish(job, nil)
+}
+
+// targetTanks devuelve los tanques afectados por la purga
+func (s *PurgeServiceImpl) targetTanks(ctx context.Context, request domain.PurgeRequest) ([]string, error) {
+	if request.Scope == domain.PurgeScopeMeasurements {
+		return []string{request.TankID}, nil
+	}
+
+	tanks, err := s.tankRepo.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tankIDs := make([]string, 0)
+	for _, tank := range tanks {
+		if domain.TankOrganization(tank, s.organizationLabel) == request.Organization {
+			tankIDs = append(tankIDs, tank.ID)
+		}
+	}
+	sort.Strings(tankIDs)
+	return tankIDs, nil
+}
+
+// purgeTank elimina los datos de un tanque y devuelve los registros eliminados por tipo, también
+// si falla a mitad
+func (s *PurgeServiceImpl) purgeTank(ctx context.Context, tankID string, request domain.PurgeRequest) (map[string]int, error) {
+	deleted := make(map[string]int)
+	defer s.forget(tankID)
+
+	if request.Scope == domain.PurgeScopeMeasurements {
+		removed, err := s.measurements.DeleteMeasurementsBefore(ctx, tankID, *request.Before)
+		deleted["measurements"] = removed
+		return deleted, err
+	}
+
+	kinds := make([]string, 0, len(s.purgers))
+	for kind := range s.purgers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		removed, err := s.purgers[kind].PurgeTankData(ctx, tankID)
+		deleted[kind] += removed
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	attachments, err := s.attachmentRepo.GetAttachmentsByTankID(ctx, tankID)
+	if err != nil {
+		return deleted, err
+	}
+	for _, attachment := range attachments {
+		if err := s.storage.Delete(ctx, attachment.StorageKey); err != nil {
+			return deleted, err
+		}
+		if err := s.attachmentRepo.DeleteAttachment(ctx, attachment.ID); err != nil {
+			return deleted, err
+		}
+		deleted["attachments"]++
+	}
+
+	// El tanque se elimina al final: si algo falla antes, la purga puede repetirse
+	if err := s.tankRepo.DeleteTank(ctx, tankID); err != nil {
+		return deleted, err
+	}
+	deleted["tanks"]++
+	return deleted, nil
+}
+
+// forget descarta la proyección y las respuestas en caché del tanque purgado
+func (s *PurgeServiceImpl) forget(tankID string) {
+	s.states.Remove(tankID)
+	if s.cache != nil {
+		s.cache.InvalidateTank(tankID)
+	}
+}
+
+// finish cierra el trabajo como completado o, si err no es nil, como fallido
+func (s *PurgeServiceImpl) finish(job *domain.PurgeJob, err error) {
+	s.update(job, func() {
+		finished := time.Now()
+		job.FinishedAt = &finished
+		if err != nil {
+			job.Status = domain.PurgeJobFailed
+			job.Error = err.Error()
+			return
+		}
+		job.Status = domain.PurgeJobCompleted
+		job.Progress = 100
+	})
+}
+
+// update modifica el trabajo con el bloqueo tomado, para que las consultas vean un estado coherente
+func (s *PurgeServiceImpl) update(job *domain.PurgeJob, change func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	change()
+}
+
+// record guarda el trabajo al principio del historial y devuelve una copia
+func (s *PurgeServiceImpl) record(job *domain.PurgeJob) *domain.PurgeJob {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	jobs := append([]*domain.PurgeJob{job}, s.jobs...)
+	if len(jobs) > maxPurgeJobHistory {
+		jobs = jobs[:maxPurgeJobHistory]
+	}
+	s.jobs = jobs
+	return job.Clone()
+}
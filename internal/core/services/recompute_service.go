@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// RecomputeServiceImpl implementa la interfaz RecomputeService. Las mediciones son la fuente de
+// verdad: el nivel actual del tanque, su proyección y sus transiciones de estado se reconstruyen
+// a partir de ellas. Los indicadores, la conciliación de entregas y los informes se calculan al
+// consultarlos, así que basta con descartar sus respuestas en caché.
+type RecomputeServiceImpl struct {
+	tankService     ports.TankService
+	tankRepo        ports.TankRepository
+	measurementRepo ports.MeasurementRepository
+	statusRepo      ports.StatusHistoryRepository
+	statusRewriter  ports.StatusHistoryRewriter
+	states          ports.TankStateStore
+	cache           ports.CacheInvalidator // nil sin caché de respuestas
+}
+
+// NewRecomputeService crea una nueva instancia del servicio de reconstrucción de datos derivados
+func NewRecomputeService(
+	tankService ports.TankService,
+	tankRepo ports.TankRepository,
+	measurementRepo ports.MeasurementRepository,
+	statusRepo ports.StatusHistoryRepository,
+	statusRewriter ports.StatusHistoryRewriter,
+	states ports.TankStateStore,
+	cache ports.CacheInvalidator,
+) ports.RecomputeService {
+	return &RecomputeServiceImpl{
+		tankService:     tankService,
+		tankRepo:        tankRepo,
+		measurementRepo: measurementRepo,
+		statusRepo:      statusRepo,
+		statusRewriter:  statusRewriter,
+		states:          states,
+		cache:           cache,
+	}
+}
+
+// Recompute reconstruye los datos derivados de los tanques accesibles indicados. No se evalúan
+// alertas ni anomalías: las transiciones reconstruidas describen el pasado.
+func (s *RecomputeServiceImpl) Recompute(ctx context.Context, tankID string, from time.Time) ([]*domain.RecomputeResult, error) {
+	if from.After(time.Now()) {
+		return nil, fmt.Errorf("%w: from must not be in the future", ErrInvalidPeriod)
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tankID != "" {
+		var selected []*domain.Tank
+		for _, tank := range tanks {
+			if tank.ID == tankID {
+				selected = append(selected, tank)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, ErrTankNotFound
+		}
+		tanks = selected
+	}
+
+	results := make([]*domain.RecomputeResult, 0, len(tanks))
+	for _, tank := range tanks {
+		result, err := s.recomputeTank(ctx, tank.ID, from)
+		if err != nil {
+			return nil, fmt.Errorf("recompute tank %s: %w", tank.ID, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// recomputeTank reconstruye las transiciones del tanque desde from y el nivel guardado con él a
+// partir de la medición más reciente
+func (s *RecomputeServiceImpl) recomputeTank(ctx context.Context, tankID string, from time.Time) (*domain.RecomputeResult, error) {
+	tank, err := s.tankRepo.GetTank(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+	if tank == nil {
+		return nil, ErrTankNotFound
+	}
+
+	measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tankID, 0)
+	if err != nil {
+		return nil, err
+	}
+	measurements = domain.SortMeasurementsAscending(measurements)
+
+	// Sin from se parte de la primera medición, para conservar el estado inicial del tanque
+	if from.IsZero() && len(measurements) > 0 {
+		from = measurements[0].Timestamp
+	}
+	result := &domain.RecomputeResult{TankID: tankID, From: from}
+
+	changes, err := s.statusRepo.GetStatusChanges(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+	initialStatus := ""
+	for _, change := range changes {
+		if !change.ChangedAt.Before(from) {
+			break
+		}
+		initialStatus = change.ToStatus
+	}
+
+	var replayed []*domain.Measurement
+	for _, measurement := range measurements {
+		if !measurement.Timestamp.Before(from) {
+			replayed = append(replayed, measurement)
+		}
+	}
+	result.Measurements = len(replayed)
+
+	if len(replayed) > 0 {
+		rebuilt := domain.ReplayStatusChanges(tank, replayed, initialStatus)
+		if err := s.statusRewriter.ReplaceStatusChanges(ctx, tankID, from, rebuilt); err != nil {
+			return nil, err
+		}
+		result.StatusChanges = len(rebuilt)
+
+		latest := measurements[len(measurements)-1]
+		tank.CurrentLevel = latest.Level
+		tank.Temperature = latest.Temperature
+		if latest.Timestamp.After(tank.LastUpdated) {
+			tank.LastUpdated = latest.Timestamp
+		}
+		tank.UpdateStatus()
+		if err := s.tankRepo.UpdateTank(ctx, tank); err != nil {
+			return nil, err
+		}
+	}
+	result.Level = tank.CurrentLevel
+	result.Status = tank.Status
+
+	// La proyección se vuelve a cargar de las mediciones en la siguiente lectura
+	s.states.Remove(tankID)
+	if s.cache != nil {
+		s.cache.InvalidateTank(tankID)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// consumptionWindow es el periodo de historial usado para estimar el consumo
+const consumptionWindow = 14 * 24 * time.Hour
+
+// maxCapacityPlanDays limita el horizonte del plan de capacidad: más allá, el consumo de las
+// últimas dos semanas deja de ser una previsión fiable
+const maxCapacityPlanDays = 90
+
+// Errores que puede devolver el servicio de reabastecimiento
+var (
+	ErrGroupNotFound       = errors.New("tank group not found")
+	ErrInvalidCapacityPlan = errors.New("invalid capacity plan horizon")
+	ErrInsufficientHistory = errors.New("not enough consumption history to recommend thresholds")
+)
+
+// ReorderServiceImpl implementa la interfaz ReorderService
+type ReorderServiceImpl struct {
+	tankService     ports.TankService
+	measurementRepo ports.MeasurementRepository
+}
+
+// NewReorderService crea una nueva instancia del servicio de reabastecimiento
+func NewReorderService(
+	tankService ports.TankService,
+	measurementRepo ports.MeasurementRepository,
+) ports.ReorderService {
+	return &ReorderServiceImpl{
+		tankService:     tankService,
+		measurementRepo: measurementRepo,
+	}
+}
+
+// GetReorderSuggestions devuelve los tanques que necesitan un pedido dentro del horizonte indicado
+func (s *ReorderServiceImpl) GetReorderSuggestions(ctx context.Context, horizonDays int) ([]*domain.ReorderSuggestion, error) {
+	if horizonDays <= 0 {
+		horizonDays = 7
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	horizon := now.Add(time.Duration(horizonDays) * 24 * time.Hour)
+	suggestions := make([]*domain.ReorderSuggestion, 0)
+
+	for _, tank := range tanks {
+		if !tank.Reorder.IsEnabled() {
+			continue
+		}
+
+		measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tank.ID, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		suggestion := buildReorderSuggestion(tank, recentMeasurements(measurements, now.Add(-consumptionWindow)), now)
+		if suggestion != nil && !suggestion.OrderBy.After(horizon) {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	// Los pedidos más urgentes primero
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].OrderBy.Before(suggestions[j].OrderBy)
+	})
+
+	return suggestions, nil
+}
+
+// GetGroupCapacityPlan prevé los litros que hay que entregar a los tanques accesibles del grupo
+// en los próximos horizonDays días
+func (s *ReorderServiceImpl) GetGroupCapacityPlan(ctx context.Context, groupID string, horizonDays int) (*domain.CapacityPlan, error) {
+	if horizonDays <= 0 || horizonDays > maxCapacityPlanDays {
+		return nil, ErrInvalidCapacityPlan
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	forecasts := make([]*domain.TankCapacityForecast, 0)
+	for _, tank := range tanks {
+		if tank.GroupID != groupID {
+			continue
+		}
+
+		measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tank.ID, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		recent := recentMeasurements(measurements, now.Add(-consumptionWindow))
+		forecasts = append(forecasts, domain.BuildTankCapacityForecast(tank, recent, horizonDays, now))
+	}
+
+	// Un grupo sin tanques accesibles no existe para el usuario
+	if len(forecasts) == 0 {
+		return nil, ErrGroupNotFound
+	}
+
+	return domain.BuildCapacityPlan(groupID, horizonDays, forecasts, now), nil
+}
+
+// GetThresholdRecommendation propone el umbral de alerta y el punto de pedido de un tanque
+// accesible a partir de su consumo en la ventana de estimación
+func (s *ReorderServiceImpl) GetThresholdRecommendation(ctx context.Context, tankID string, alertLeadTime time.Duration) (*domain.ThresholdRecommendation, error) {
+	tank, err := s.tankService.GetTank(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tankID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	recent := recentMeasurements(measurements, time.Now().Add(-consumptionWindow))
+	recommendation, ok := domain.BuildThresholdRecommendation(tank, recent, alertLeadTime)
+	if !ok {
+		return nil, ErrInsufficientHistory
+	}
+	return recommendation, nil
+}
+
+// buildReorderSuggestion calcula la sugerencia de pedido para un tanque. Devuelve nil si
+// con el consumo actual el tanque nunca alcanzará el punto de pedido.
+func buildReorderSuggestion(tank *domain.Tank, measurements []*domain.Measurement, now time.Time) *domain.ReorderSuggestion {
+	daily := domain.EstimateDailyConsumption(measurements)
+
+	daysUntilReorder := 0.0
+	if tank.CurrentLevel > tank.Reorder.ReorderLevel {
+		if daily <= 0 {
+			return nil
+		}
+		daysUntilReorder = (tank.CurrentLevel - tank.Reorder.ReorderLevel) / daily
+	}
+
+	// El pedido debe hacerse con la antelación del plazo de entrega del proveedor
+	orderInDays := daysUntilReorder - tank.Reorder.LeadTimeDays
+	orderBy := now.Add(time.Duration(orderInDays * 24 * float64(time.Hour)))
+
+	// Estimamos el nivel en el momento de la entrega para no sobrepasar la capacidad
+	deliveryInDays := max(orderInDays, 0) + tank.Reorder.LeadTimeDays
+	levelAtDelivery := tank.CurrentLevel - daily*deliveryInDays
+	if levelAtDelivery < 0 {
+		levelAtDelivery = 0
+	}
+
+	quantity := tank.Capacity - levelAtDelivery
+	if tank.Reorder.DeliverySize > 0 && tank.Reorder.DeliverySize < quantity {
+		quantity = tank.Reorder.DeliverySize
+	}
+
+	return &domain.ReorderSuggestion{
+		TankID:            tank.ID,
+		TankName:          tank.Name,
+		CurrentLevel:      tank.CurrentLevel,
+		ReorderLevel:      tank.Reorder.ReorderLevel,
+		DailyConsumption:  daily,
+		DaysUntilReorder:  daysUntilReorder,
+		OrderBy:           orderBy,
+		SuggestedQuantity: quantity,
+		Urgent:            orderInDays <= 0,
+	}
+}
+
+// recentMeasurements filtra las mediciones posteriores a la fecha indicada
+func recentMeasurements(measurements []*domain.Measurement, since time.Time) []*domain.Measurement {
+	result := make([]*domain.Measurement, 0, len(measurements))
+	for _, m := range measurements {
+		if !m.Timestamp.Before(since) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
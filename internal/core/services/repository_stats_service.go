@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// RepositoryStatsServiceImpl implementa la interfaz RepositoryStatsService. Lee directamente de
+// los repositorios: es un diagnóstico para los administradores, que ven todos los tanques.
+type RepositoryStatsServiceImpl struct {
+	tankRepo  ports.TankRepository
+	streamer  ports.MeasurementStreamer
+	inspector ports.RepositoryInspector
+	backend   domain.RepositoryBackendInfo
+}
+
+// NewRepositoryStatsService crea una nueva instancia del servicio de diagnóstico de la persistencia
+func NewRepositoryStatsService(
+	tankRepo ports.TankRepository,
+	streamer ports.MeasurementStreamer,
+	inspector ports.RepositoryInspector,
+	backend domain.RepositoryBackendInfo,
+) ports.RepositoryStatsService {
+	return &RepositoryStatsServiceImpl{
+		tankRepo:  tankRepo,
+		streamer:  streamer,
+		inspector: inspector,
+		backend:   backend,
+	}
+}
+
+// GetStats cuenta las entidades de cada colección y recorre las mediciones de cada tanque para
+// obtener su rango y la ingesta reciente
+func (s *RepositoryStatsServiceImpl) GetStats(ctx context.Context) (*domain.RepositoryStats, error) {
+	entities, err := s.inspector.CountEntities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tanks, err := s.tankRepo.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stats := &domain.RepositoryStats{
+		GeneratedAt: now,
+		Backend:     s.backend,
+		Entities:    entities,
+		Tanks:       make([]*domain.TankMeasurementRange, 0, len(tanks)),
+	}
+	for _, tank := range tanks {
+		tankRange := &domain.TankMeasurementRange{TankID: tank.ID, TankName: tank.Name}
+		err := s.streamer.StreamMeasurementsByTankID(ctx, tank.ID, 0, func(m *domain.Measurement) error {
+			tankRange.Add(m.Timestamp)
+			stats.Ingestion.Add(m.Timestamp, now)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		stats.Tanks = append(stats.Tanks, tankRange)
+	}
+
+	domain.SortTankMeasurementRanges(stats.Tanks)
+	return stats, nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver el servicio de configuración recargable
+var (
+	ErrRuntimeConfigUnavailable = errors.New("no runtime config file to reload")
+	ErrInvalidRuntimeConfig     = errors.New("invalid runtime config")
+)
+
+// RuntimeConfigServiceImpl implementa la interfaz RuntimeConfigService. Parte de la
+// configuración de las variables de entorno y, si hay fuente, la sustituye por la que lee de
+// ella en cada recarga.
+type RuntimeConfigServiceImpl struct {
+	source  ports.RuntimeSettingsSource // nil si no hay archivo de configuración recargable
+	applier ports.RuntimeSettingsApplier
+	base    domain.RuntimeSettings
+	mutex   sync.Mutex // Serializa las recargas y protege current
+	current domain.RuntimeSettings
+}
+
+// NewRuntimeConfigService crea una nueva instancia del servicio de configuración recargable. base
+// es la configuración de las variables de entorno, que se supone ya aplicada.
+func NewRuntimeConfigService(source ports.RuntimeSettingsSource, applier ports.RuntimeSettingsApplier, base domain.RuntimeSettings) *RuntimeConfigServiceImpl {
+	return &RuntimeConfigServiceImpl{
+		source:  source,
+		applier: applier,
+		base:    base,
+		current: base,
+	}
+}
+
+// GetSettings devuelve la configuración recargable en vigor
+func (s *RuntimeConfigServiceImpl) GetSettings(ctx context.Context) (*domain.RuntimeSettings, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current := s.current
+	return &current, nil
+}
+
+// Reload lee la fuente y aplica la configuración. Si no es válida se rechaza entera y siguen en
+// vigor los valores anteriores.
+func (s *RuntimeConfigServiceImpl) Reload(ctx context.Context) (*domain.RuntimeSettings, error) {
+	if s.source == nil {
+		return nil, ErrRuntimeConfigUnavailable
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Un archivo ilegible o mal formado se trata como una configuración no válida
+	settings, err := s.source.Load(ctx, s.base)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRuntimeConfig, err)
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRuntimeConfig, err)
+	}
+	if err := s.applier.ApplyRuntimeSettings(ctx, settings); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRuntimeConfig, err)
+	}
+
+	now := time.Now()
+	settings.ReloadedAt = &now
+	s.current = *settings
+
+	current := s.current
+	return &current, nil
+}
+
+// SetLogLevel cambia el nivel de logging en vigor, p. ej. para depurar la ingesta sin reiniciar.
+// La siguiente recarga vuelve al nivel del archivo o de las variables de entorno.
+func (s *RuntimeConfigServiceImpl) SetLogLevel(ctx context.Context, level string) (*domain.RuntimeSettings, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	settings := s.current
+	settings.LogLevel = strings.ToLower(strings.TrimSpace(level))
+	if err := settings.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRuntimeConfig, err)
+	}
+	if err := s.applier.ApplyRuntimeSettings(ctx, &settings); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRuntimeConfig, err)
+	}
+	s.current = settings
+
+	current := s.current
+	return &current, nil
+}
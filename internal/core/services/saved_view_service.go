@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores del servicio de vistas guardadas
+var (
+	ErrSavedViewNotFound = errors.New("saved view not found")
+	ErrInvalidSavedView  = errors.New("invalid saved view")
+	ErrSavedViewConflict = errors.New("saved view name already in use")
+)
+
+// SavedViewServiceImpl implementa la interfaz SavedViewService. Cada usuario ve y gestiona solo
+// sus vistas, también los administradores; sin autenticación las vistas son comunes a toda la
+// instalación.
+type SavedViewServiceImpl struct {
+	viewRepo ports.SavedViewRepository
+}
+
+// NewSavedViewService crea una nueva instancia del servicio de vistas guardadas
+func NewSavedViewService(viewRepo ports.SavedViewRepository) ports.SavedViewService {
+	return &SavedViewServiceImpl{
+		viewRepo: viewRepo,
+	}
+}
+
+// GetViews obtiene las vistas del usuario ordenadas por nombre
+func (s *SavedViewServiceImpl) GetViews(ctx context.Context) ([]*domain.SavedView, error) {
+	return s.viewRepo.GetViews(ctx, viewSubject(ctx))
+}
+
+// GetView obtiene una vista del usuario
+func (s *SavedViewServiceImpl) GetView(ctx context.Context, id string) (*domain.SavedView, error) {
+	return s.ownedView(ctx, id)
+}
+
+// CreateView guarda una vista nueva del usuario con un nombre que aún no use
+func (s *SavedViewServiceImpl) CreateView(ctx context.Context, view *domain.SavedView) error {
+	if err := validateSavedView(view); err != nil {
+		return err
+	}
+
+	subject := viewSubject(ctx)
+	views, err := s.viewRepo.GetViews(ctx, subject)
+	if err != nil {
+		return err
+	}
+	if len(views) >= domain.MaxSavedViewsPerSubject {
+		return fmt.Errorf("%w: at most %d views per user", ErrInvalidSavedView, domain.MaxSavedViewsPerSubject)
+	}
+	if err := checkSavedViewName(views, view); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	view.ID = uuid.New().String()
+	view.Subject = subject
+	view.CreatedAt = now
+	view.UpdatedAt = now
+	return s.viewRepo.SaveView(ctx, view)
+}
+
+// UpdateView reemplaza el nombre, la descripción, la ruta y los parámetros de una vista del usuario
+func (s *SavedViewServiceImpl) UpdateView(ctx context.Context, view *domain.SavedView) error {
+	if err := validateSavedView(view); err != nil {
+		return err
+	}
+
+	existing, err := s.ownedView(ctx, view.ID)
+	if err != nil {
+		return err
+	}
+	views, err := s.viewRepo.GetViews(ctx, existing.Subject)
+	if err != nil {
+		return err
+	}
+	if err := checkSavedViewName(views, view); err != nil {
+		return err
+	}
+
+	view.Subject = existing.Subject
+	view.CreatedAt = existing.CreatedAt
+	view.UpdatedAt = time.Now()
+	return s.viewRepo.SaveView(ctx, view)
+}
+
+// DeleteView elimina una vista del usuario
+func (s *SavedViewServiceImpl) DeleteView(ctx context.Context, id string) error {
+	if _, err := s.ownedView(ctx, id); err != nil {
+		return err
+	}
+
+	return s.viewRepo.DeleteView(ctx, id)
+}
+
+// ownedView obtiene la vista si pertenece al usuario del contexto; las de otros usuarios no existen
+// para él
+func (s *SavedViewServiceImpl) ownedView(ctx context.Context, id string) (*domain.SavedView, error) {
+	view, err := s.viewRepo.GetView(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if view == nil || view.Subject != viewSubject(ctx) {
+		return nil, ErrSavedViewNotFound
+	}
+	return view, nil
+}
+
+// viewSubject devuelve el usuario dueño de las vistas, vacío sin autenticación
+func viewSubject(ctx context.Context) string {
+	if principal := domain.PrincipalFromContext(ctx); principal != nil {
+		return principal.Subject
+	}
+	return ""
+}
+
+// validateSavedView normaliza y valida la vista. La ruta es la de un listado o informe de la API,
+// sin parámetros; un filtro en los parámetros se valida con los campos de su listado.
+func validateSavedView(view *domain.SavedView) error {
+	if view == nil {
+		return ErrInvalidSavedView
+	}
+
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" || utf8.RuneCountInString(view.Name) > domain.MaxSavedViewNameLength {
+		return fmt.Errorf("%w: name is required (at most %d characters)", ErrInvalidSavedView, domain.MaxSavedViewNameLength)
+	}
+	view.Path = strings.TrimSpace(view.Path)
+	if !strings.HasPrefix(view.Path, "/api/") || strings.ContainsAny(view.Path, "?# ") {
+		return fmt.Errorf("%w: path must be an API path without query", ErrInvalidSavedView)
+	}
+
+	for key, value := range view.Query {
+		if key == "" {
+			return fmt.Errorf("%w: empty query parameter", ErrInvalidSavedView)
+		}
+		if key != "filter" {
+			continue
+		}
+		fields, ok := savedViewFilterFields(view.Path)
+		if !ok {
+			return fmt.Errorf("%w: %s does not accept filter", ErrInvalidSavedView, view.Path)
+		}
+		if _, err := parseFilter(value, fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// savedViewFilterFields devuelve los campos del filtro del listado de la ruta, con o sin versión
+func savedViewFilterFields(path string) (map[string]domain.FilterFieldType, bool) {
+	switch {
+	case strings.HasSuffix(path, "/measurements"):
+		return domain.MeasurementFilterFields, true
+	case strings.Contains(path, "/notification-channels/") && strings.HasSuffix(path, "/queue"):
+		return domain.QueuedAlertFilterFields, true
+	}
+	return nil, false
+}
+
+// checkSavedViewName comprueba que ninguna otra vista del usuario tenga el mismo nombre (sin
+// distinguir mayúsculas)
+func checkSavedViewName(views []*domain.SavedView, view *domain.SavedView) error {
+	for _, other := range views {
+		if other.ID != view.ID && strings.EqualFold(other.Name, view.Name) {
+			return ErrSavedViewConflict
+		}
+	}
+	return nil
+}
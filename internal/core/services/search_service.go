@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrInvalidSearchQuery se devuelve cuando la consulta de búsqueda está vacía
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// Pesos de los campos en la puntuación de la búsqueda
+const (
+	searchWeightTankName   = 3.0
+	searchWeightSite       = 2.0
+	searchWeightLiquidType = 1.5
+	searchWeightGroup      = 1.5
+	searchWeightNote       = 1.0
+
+	// Un sitio puntúa por encima de los tanques que solo coinciden por estar en él
+	searchWeightSiteResult = 2.5
+)
+
+// SearchServiceImpl implementa la interfaz SearchService sobre los tanques accesibles para el
+// usuario y sus notas
+type SearchServiceImpl struct {
+	tankService ports.TankService
+	noteRepo    ports.TankNoteRepository
+}
+
+// NewSearchService crea una nueva instancia del servicio de búsqueda
+func NewSearchService(tankService ports.TankService, noteRepo ports.TankNoteRepository) ports.SearchService {
+	return &SearchServiceImpl{
+		tankService: tankService,
+		noteRepo:    noteRepo,
+	}
+}
+
+// Search busca la consulta, sin distinguir mayúsculas ni tildes, en los nombres, tipos de líquido,
+// sitios y grupos de los tanques y en el texto de sus notas. Cada término de la consulta debe
+// aparecer en el resultado, aunque sea en campos distintos: "diesel norte" encuentra un tanque de
+// diésel del sitio estacion-norte. Los resultados se ordenan por relevancia.
+func (s *SearchServiceImpl) Search(ctx context.Context, query string, limit int) ([]*domain.SearchResult, error) {
+	terms := domain.SearchTerms(query)
+	if len(terms) == 0 {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*domain.SearchResult, 0)
+	tanksBySite := make(map[string]int)
+	for _, tank := range tanks {
+		if tank.SiteID != "" {
+			tanksBySite[tank.SiteID]++
+		}
+
+		score := domain.ScoreSearch(terms,
+			domain.SearchField{Text: tank.Name, Weight: searchWeightTankName},
+			domain.SearchField{Text: tank.SiteID, Weight: searchWeightSite},
+			domain.SearchField{Text: tank.LiquidType, Weight: searchWeightLiquidType},
+			domain.SearchField{Text: tank.GroupID, Weight: searchWeightGroup},
+		)
+		if score > 0 {
+			results = append(results, &domain.SearchResult{
+				Type:   domain.SearchResultTank,
+				ID:     tank.ID,
+				Title:  tank.Name,
+				Score:  roundScore(score),
+				TankID: tank.ID,
+				Tank:   tank,
+			})
+		}
+
+		notes, err := s.noteRepo.GetNotes(ctx, tank.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, note := range notes {
+			score := domain.ScoreSearch(terms, domain.SearchField{Text: note.Text, Weight: searchWeightNote})
+			if score > 0 {
+				results = append(results, &domain.SearchResult{
+					Type:   domain.SearchResultNote,
+					ID:     note.ID,
+					Title:  tank.Name,
+					Score:  roundScore(score),
+					TankID: tank.ID,
+					Note:   note,
+				})
+			}
+		}
+	}
+
+	// Los sitios se deducen de los tanques visibles, así que no se revelan sitios sin acceso
+	for site, count := range tanksBySite {
+		score := domain.ScoreSearch(terms, domain.SearchField{Text: site, Weight: searchWeightSiteResult})
+		if score > 0 {
+			results = append(results, &domain.SearchResult{
+				Type:      domain.SearchResultSite,
+				ID:        site,
+				Title:     site,
+				Score:     roundScore(score),
+				TankCount: count,
+			})
+		}
+	}
+
+	return domain.RankSearchResults(results, limit), nil
+}
+
+// roundScore redondea la puntuación a dos decimales para la respuesta
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrInvalidHealthFilter se devuelve cuando se filtra por una categoría de salud inexistente
+var ErrInvalidHealthFilter = errors.New("invalid sensor health filter")
+
+// SensorHealthServiceImpl implementa la interfaz SensorHealthService
+type SensorHealthServiceImpl struct {
+	tankService     ports.TankService
+	measurementRepo ports.MeasurementRepository
+	config          domain.SensorHealthConfig
+}
+
+// NewSensorHealthService crea una nueva instancia del servicio de salud de sensores
+func NewSensorHealthService(
+	tankService ports.TankService,
+	measurementRepo ports.MeasurementRepository,
+	config domain.SensorHealthConfig,
+) ports.SensorHealthService {
+	return &SensorHealthServiceImpl{
+		tankService:     tankService,
+		measurementRepo: measurementRepo,
+		config:          config,
+	}
+}
+
+// GetSensorHealth evalúa los sensores de los tanques accesibles, del peor al mejor. Si health
+// no está vacío, solo se devuelven los sensores de esa categoría.
+func (s *SensorHealthServiceImpl) GetSensorHealth(ctx context.Context, health string) ([]*domain.SensorHealth, error) {
+	if health != "" && !domain.IsValidSensorHealth(health) {
+		return nil, ErrInvalidHealthFilter
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]*domain.SensorHealth, 0)
+	for _, tank := range tanks {
+		measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tank.ID, s.config.Samples)
+		if err != nil {
+			return nil, err
+		}
+
+		// Un tanque puede tener varios sensores; cada uno se evalúa con sus propias lecturas
+		bySensor := make(map[string][]*domain.Measurement)
+		for _, measurement := range measurements {
+			sensorID := domain.SensorIDForMeasurement(measurement)
+			bySensor[sensorID] = append(bySensor[sensorID], measurement)
+		}
+
+		for sensorID, sensorMeasurements := range bySensor {
+			sensorHealth := domain.BuildSensorHealth(sensorID, tank, sensorMeasurements, s.config, now)
+			if health == "" || sensorHealth.Health == health {
+				result = append(result, sensorHealth)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score < result[j].Score
+		}
+		return result[i].SensorID < result[j].SensorID
+	})
+
+	return result, nil
+}
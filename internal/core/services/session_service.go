@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver el servicio de sesiones
+var (
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidSession      = errors.New("invalid session data")
+	ErrInvalidSessionToken = errors.New("invalid or expired session token")
+)
+
+// sessionTokenBytes es la entropía de los tokens de sesión
+const sessionTokenBytes = 32
+
+// SessionServiceImpl implementa la interfaz SessionService
+type SessionServiceImpl struct {
+	config      domain.SessionConfig
+	sessionRepo ports.SessionRepository
+	// mutex serializa los refrescos: dos refrescos simultáneos con el mismo token no deben rotarlo
+	// dos veces, porque el segundo parecería una reutilización y revocaría la sesión
+	mutex sync.Mutex
+}
+
+// NewSessionService crea una nueva instancia del servicio de sesiones
+func NewSessionService(config domain.SessionConfig, sessionRepo ports.SessionRepository) (ports.SessionService, error) {
+	if config.AccessTTL <= 0 || config.RefreshTTL < config.AccessTTL {
+		return nil, fmt.Errorf("%w: refresh ttl must be at least the access ttl", ErrInvalidSession)
+	}
+	return &SessionServiceImpl{
+		config:      config,
+		sessionRepo: sessionRepo,
+	}, nil
+}
+
+// CreateSession inicia una sesión para el principal autenticado. Las sesiones vencidas hace más
+// de un periodo de refresco se eliminan al crear otras, para que el listado siga siendo útil.
+func (s *SessionServiceImpl) CreateSession(ctx context.Context, principal *domain.Principal, clientIP, userAgent string) (*domain.SessionTokens, error) {
+	if principal == nil || principal.Subject == "" {
+		return nil, fmt.Errorf("%w: subject is required", ErrInvalidSession)
+	}
+
+	now := time.Now()
+	if _, err := s.sessionRepo.DeleteSessionsExpiredBefore(ctx, now.Add(-s.config.RefreshTTL)); err != nil {
+		return nil, err
+	}
+
+	session := &domain.Session{
+		ID:        uuid.New().String(),
+		Principal: *principal,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+		CreatedAt: now,
+	}
+	tokens, err := s.issueTokens(session, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.SaveSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Refresh rota el token de refresco y emite un nuevo token de acceso. Un token de refresco que
+// ya se usó revoca la sesión: lo presenta quien lo robó o el usuario legítimo tras el robo. Un
+// token que la sesión nunca emitió solo se rechaza.
+func (s *SessionServiceImpl) Refresh(ctx context.Context, refreshToken string) (*domain.SessionTokens, error) {
+	id, ok := domain.SessionTokenID(refreshToken, domain.SessionRefreshTokenPrefix)
+	if !ok {
+		return nil, ErrInvalidSessionToken
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, err := s.sessionRepo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if session == nil || !session.IsActive(now) {
+		return nil, ErrInvalidSessionToken
+	}
+
+	if !tokenMatches(refreshToken, session.RefreshHash) {
+		// El ID de la sesión no es secreto: solo un token que la sesión emitió y ya rotó prueba la
+		// reutilización; cualquier otro se rechaza sin revocarla
+		if !tokenMatchesAny(refreshToken, session.PreviousRefreshHashes) {
+			return nil, ErrInvalidSessionToken
+		}
+		session.Revoke(domain.SessionRevokedRefreshReuse, now)
+		if err := s.sessionRepo.SaveSession(ctx, session); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: refresh token reused, session %s revoked", ErrInvalidSessionToken, session.ID)
+	}
+
+	tokens, err := s.issueTokens(session, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.SaveSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Authenticate valida un token de acceso de una sesión activa y devuelve su principal
+func (s *SessionServiceImpl) Authenticate(ctx context.Context, accessToken string) (*domain.Principal, error) {
+	id, ok := domain.SessionTokenID(accessToken, domain.SessionAccessTokenPrefix)
+	if !ok {
+		return nil, ErrInvalidSessionToken
+	}
+
+	session, err := s.sessionRepo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if session == nil || !session.IsActive(now) || !now.Before(session.AccessExpiresAt) ||
+		!tokenMatches(accessToken, session.AccessHash) {
+		return nil, ErrInvalidSessionToken
+	}
+
+	principal := session.Principal
+	return &principal, nil
+}
+
+// GetSessions devuelve las sesiones que cumplen el filtro, de la más reciente a la más antigua
+func (s *SessionServiceImpl) GetSessions(ctx context.Context, filter domain.SessionFilter) ([]*domain.Session, error) {
+	sessions, err := s.sessionRepo.GetSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	filtered := make([]*domain.Session, 0, len(sessions))
+	for _, session := range sessions {
+		session.Active = session.IsActive(now)
+		if (filter.Subject != "" && session.Principal.Subject != filter.Subject) || (filter.ActiveOnly && !session.Active) {
+			continue
+		}
+		filtered = append(filtered, session)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].CreatedAt.After(filtered[j].CreatedAt)
+	})
+	return filtered, nil
+}
+
+// RevokeSession revoca una sesión; sus tokens dejan de aceptarse de inmediato
+func (s *SessionServiceImpl) RevokeSession(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, err := s.sessionRepo.GetSession(ctx, id)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return ErrSessionNotFound
+	}
+
+	session.Revoke(domain.SessionRevokedByAdmin, time.Now())
+	return s.sessionRepo.SaveSession(ctx, session)
+}
+
+// RevokeSubjectSessions revoca las sesiones activas del usuario y devuelve cuántas revocó
+func (s *SessionServiceImpl) RevokeSubjectSessions(ctx context.Context, subject, reason string) (int, error) {
+	if subject == "" {
+		return 0, fmt.Errorf("%w: subject is required", ErrInvalidSession)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sessions, err := s.sessionRepo.GetSessions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	revoked := 0
+	for _, session := range sessions {
+		if session.Principal.Subject != subject || !session.IsActive(now) {
+			continue
+		}
+		session.Revoke(reason, now)
+		if err := s.sessionRepo.SaveSession(ctx, session); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// issueTokens genera un nuevo par de tokens para la sesión y guarda en ella sus resúmenes; los
+// tokens anteriores dejan de ser válidos
+func (s *SessionServiceImpl) issueTokens(session *domain.Session, now time.Time) (*domain.SessionTokens, error) {
+	accessToken, err := newSessionToken(domain.SessionAccessTokenPrefix, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := newSessionToken(domain.SessionRefreshTokenPrefix, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.AccessHash = domain.HashSessionToken(accessToken)
+	session.AccessExpiresAt = now.Add(s.config.AccessTTL)
+	session.RotateRefreshHash(domain.HashSessionToken(refreshToken))
+	session.RefreshedAt = now
+	session.ExpiresAt = now.Add(s.config.RefreshTTL)
+
+	return &domain.SessionTokens{
+		SessionID:    session.ID,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    domain.SessionTokenType,
+		ExpiresIn:    int(s.config.AccessTTL.Seconds()),
+	}, nil
+}
+
+// newSessionToken genera un token aleatorio de la forma <prefijo><ID de la sesión>.<secreto>
+func newSessionToken(prefix, sessionID string) (string, error) {
+	secret := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return prefix + sessionID + "." + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// tokenMatches compara el token con el resumen guardado en tiempo constante
+func tokenMatches(token, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(domain.HashSessionToken(token)), []byte(hash)) == 1
+}
+
+// tokenMatchesAny indica si el token corresponde a alguno de los resúmenes guardados
+func tokenMatchesAny(token string, hashes []string) bool {
+	matched := false
+	for _, hash := range hashes {
+		if tokenMatches(token, hash) {
+			matched = true
+		}
+	}
+	return matched
+}
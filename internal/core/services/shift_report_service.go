@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ShiftReportServiceImpl implementa la interfaz ShiftReportService
+type ShiftReportServiceImpl struct {
+	tankService     ports.TankService
+	measurementRepo ports.MeasurementRepository
+	statusRepo      ports.StatusHistoryRepository
+	muteRepo        ports.AlertMuteRepository
+}
+
+// NewShiftReportService crea una nueva instancia del servicio de informes de relevo de turno
+func NewShiftReportService(
+	tankService ports.TankService,
+	measurementRepo ports.MeasurementRepository,
+	statusRepo ports.StatusHistoryRepository,
+	muteRepo ports.AlertMuteRepository,
+) ports.ShiftReportService {
+	return &ShiftReportServiceImpl{
+		tankService:     tankService,
+		measurementRepo: measurementRepo,
+		statusRepo:      statusRepo,
+		muteRepo:        muteRepo,
+	}
+}
+
+// GetShiftReport recorre los tanques accesibles: sus transiciones de estado dan las alertas del
+// turno, los silencios de los operadores las marcan como atendidas y las subidas de nivel de las
+// mediciones dan las entregas. El estado de atención es el actual, no el del final del periodo.
+func (s *ShiftReportServiceImpl) GetShiftReport(ctx context.Context, from, to time.Time) (*domain.ShiftReport, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ShiftReport{
+		GeneratedAt: time.Now(),
+		From:        from,
+		To:          to,
+		Alerts:      make([]*domain.ShiftAlert, 0),
+		Deliveries:  make([]*domain.DetectedDelivery, 0),
+		Attention:   make([]*domain.ShiftTankStatus, 0),
+	}
+	for _, tank := range tanks {
+		changes, err := s.statusRepo.GetStatusChanges(ctx, tank.ID)
+		if err != nil {
+			return nil, err
+		}
+		mutes, err := s.muteRepo.GetMutes(ctx, tank.ID)
+		if err != nil {
+			return nil, err
+		}
+		measurements, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tank.ID, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Alerts = append(report.Alerts, domain.BuildShiftAlerts(tank, changes, mutes, from, to)...)
+		report.Deliveries = append(report.Deliveries, domain.DetectDeliveries(tank, measurements, from, to)...)
+		if status := domain.NewShiftTankStatus(tank, changes); status != nil {
+			report.Attention = append(report.Attention, status)
+		}
+	}
+
+	report.Finalize()
+	return report, nil
+}
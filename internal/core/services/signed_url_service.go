@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrInvalidSignedURL se devuelve cuando la ruta no es una exportación firmable o la vigencia
+// solicitada no es válida
+var ErrInvalidSignedURL = errors.New("invalid signed url")
+
+// SignedURLServiceImpl implementa la interfaz SignedURLService
+type SignedURLServiceImpl struct {
+	signer     ports.URLSigner
+	paths      []string // Patrones de las rutas firmables; {nombre} admite cualquier segmento
+	baseURL    *url.URL // Origen de los enlaces generados; nil los deja relativos
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+// NewSignedURLService crea una nueva instancia del servicio de enlaces firmados. paths son los
+// patrones de las rutas que se pueden firmar, p. ej. /api/tanks/{id}/measurements.
+func NewSignedURLService(signer ports.URLSigner, paths []string, baseURL string, defaultTTL, maxTTL time.Duration) (ports.SignedURLService, error) {
+	service := &SignedURLServiceImpl{
+		signer:     signer,
+		paths:      paths,
+		defaultTTL: defaultTTL,
+		maxTTL:     maxTTL,
+	}
+	if baseURL != "" {
+		parsed, err := url.Parse(baseURL)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid base url %q", baseURL)
+		}
+		service.baseURL = parsed
+	}
+	return service, nil
+}
+
+// SignURL firma la ruta para el principal de la solicitud. El enlace conserva sus roles: una ruta
+// que requiere un rol que el principal no tiene se firma, pero el enlace responde 403.
+func (s *SignedURLServiceImpl) SignURL(ctx context.Context, target string, ttl time.Duration) (*domain.SignedURL, error) {
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+	if ttl < 0 || ttl > s.maxTTL {
+		return nil, fmt.Errorf("%w: ttl must be positive and at most %s", ErrInvalidSignedURL, s.maxTTL)
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" || parsed.Fragment != "" {
+		return nil, fmt.Errorf("%w: target must be an API path", ErrInvalidSignedURL)
+	}
+	if !s.isSignable(parsed.Path) {
+		return nil, fmt.Errorf("%w: %s is not an export", ErrInvalidSignedURL, parsed.Path)
+	}
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	signed := s.signer.Sign(parsed, domain.PrincipalFromContext(ctx), expiresAt)
+	if s.baseURL != nil {
+		absolute := s.baseURL.JoinPath(signed.Path)
+		absolute.RawQuery = signed.RawQuery
+		signed = absolute
+	}
+
+	return &domain.SignedURL{
+		URL:       signed.String(),
+		Method:    http.MethodGet,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// isSignable indica si la ruta, con o sin versión (/api/v1/...), coincide con algún patrón
+func (s *SignedURLServiceImpl) isSignable(path string) bool {
+	segments := strings.Split(path, "/")
+	if len(segments) > 2 && segments[1] == "api" && isAPIVersion(segments[2]) {
+		segments = append(segments[:2:2], segments[3:]...)
+	}
+
+	for _, pattern := range s.paths {
+		if matchPathSegments(strings.Split(pattern, "/"), segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathSegments compara los segmentos de una ruta con los de un patrón
+func matchPathSegments(pattern, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+	for i, part := range pattern {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if part != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isAPIVersion indica si el segmento es una versión de la API, p. ej. v1
+func isAPIVersion(segment string) bool {
+	if len(segment) < 2 || segment[0] != 'v' {
+		return false
+	}
+	for _, c := range segment[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrInvalidSimulation se devuelve cuando el perfil, la duración o el intervalo de la serie
+// sintética no son válidos
+var ErrInvalidSimulation = errors.New("invalid simulation")
+
+// SimulationServiceImpl implementa la interfaz SimulationService: inyecta series sintéticas en
+// los tanques de los entornos de pruebas para ensayar las reglas de alerta y los paneles
+type SimulationServiceImpl struct {
+	tankService ports.TankService
+}
+
+// NewSimulationService crea una nueva instancia del servicio de series sintéticas
+func NewSimulationService(tankService ports.TankService) ports.SimulationService {
+	return &SimulationServiceImpl{tankService: tankService}
+}
+
+// SimulateTank genera la serie del perfil con una lectura cada interval durante la duración
+// indicada, hasta ahora, y la guarda como un lote de mediciones: el tanque termina con el nivel
+// de la última y se evalúan sus alertas como con las lecturas reales
+func (s *SimulationServiceImpl) SimulateTank(ctx context.Context, tankID, profile string, duration, interval time.Duration) (*domain.SyntheticSeries, error) {
+	if !domain.IsValidSyntheticProfile(profile) {
+		return nil, fmt.Errorf("%w: unknown profile %q", ErrInvalidSimulation, profile)
+	}
+	if duration <= 0 || interval <= 0 || interval > duration {
+		return nil, fmt.Errorf("%w: interval must be positive and not longer than the duration", ErrInvalidSimulation)
+	}
+	if count := duration / interval; count > domain.MaxSyntheticMeasurements {
+		return nil, fmt.Errorf("%w: at most %d measurements per series", ErrInvalidSimulation, domain.MaxSyntheticMeasurements)
+	}
+
+	tank, err := s.tankService.GetTank(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	measurements := domain.GenerateSyntheticSeries(tank, profile, time.Now(), duration, interval, random)
+	if len(measurements) == 0 {
+		return nil, fmt.Errorf("%w: the tank has no capacity", ErrInvalidSimulation)
+	}
+	for _, measurement := range measurements {
+		measurement.ID = uuid.New().String()
+	}
+
+	if err := s.tankService.AddMeasurements(ctx, measurements); err != nil {
+		return nil, err
+	}
+
+	series := &domain.SyntheticSeries{
+		TankID:       tank.ID,
+		Profile:      profile,
+		From:         measurements[0].Timestamp,
+		To:           measurements[len(measurements)-1].Timestamp,
+		Interval:     interval.Seconds(),
+		Measurements: len(measurements),
+		StartLevel:   measurements[0].Level,
+		EndLevel:     measurements[len(measurements)-1].Level,
+		MinLevel:     math.Inf(1),
+	}
+	for _, measurement := range measurements {
+		series.MinLevel = math.Min(series.MinLevel, measurement.Level)
+	}
+	return series, nil
+}
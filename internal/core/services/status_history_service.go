@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrInvalidPeriod se devuelve cuando el periodo solicitado no es válido
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// StatusHistoryServiceImpl implementa la interfaz StatusHistoryService
+type StatusHistoryServiceImpl struct {
+	tankService ports.TankService
+	statusRepo  ports.StatusHistoryRepository
+	noteRepo    ports.TankNoteRepository
+}
+
+// NewStatusHistoryService crea una nueva instancia del servicio de historial de estados
+func NewStatusHistoryService(
+	tankService ports.TankService,
+	statusRepo ports.StatusHistoryRepository,
+	noteRepo ports.TankNoteRepository,
+) ports.StatusHistoryService {
+	return &StatusHistoryServiceImpl{
+		tankService: tankService,
+		statusRepo:  statusRepo,
+		noteRepo:    noteRepo,
+	}
+}
+
+// GetStatusHistory obtiene las transiciones de estado de un tanque y el tiempo en cada estado
+func (s *StatusHistoryServiceImpl) GetStatusHistory(ctx context.Context, tankID string, from, to time.Time) (*domain.StatusHistory, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	// Verificamos que el tanque exista
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	changes, err := s.statusRepo.GetStatusChanges(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := domain.BuildStatusHistory(tankID, changes, from, to)
+
+	// El informe incluye las observaciones de los operadores para explicar los cambios
+	notes, err := s.noteRepo.GetNotes(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+	history.Notes = domain.NotesInPeriod(notes, from, to)
+
+	return history, nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores del servicio de páginas de estado públicas
+var (
+	ErrStatusShareNotFound = errors.New("status share not found")
+	ErrInvalidStatusShare  = errors.New("invalid status share")
+)
+
+// maxStatusShareNameLength limita el título de las páginas públicas
+const maxStatusShareNameLength = 100
+
+// StatusShareServiceImpl implementa la interfaz StatusShareService
+type StatusShareServiceImpl struct {
+	tankService ports.TankService
+	shareRepo   ports.StatusShareRepository
+}
+
+// NewStatusShareService crea una nueva instancia del servicio de páginas de estado públicas
+func NewStatusShareService(tankService ports.TankService, shareRepo ports.StatusShareRepository) ports.StatusShareService {
+	return &StatusShareServiceImpl{
+		tankService: tankService,
+		shareRepo:   shareRepo,
+	}
+}
+
+// CreateShare valida la página, comprueba que sus tanques existan y sean accesibles y genera su
+// token. Solo se guarda el hash del token.
+func (s *StatusShareServiceImpl) CreateShare(ctx context.Context, share *domain.StatusShare) (*domain.StatusShare, error) {
+	now := time.Now()
+	name := strings.TrimSpace(share.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxStatusShareNameLength {
+		return nil, fmt.Errorf("%w: name must have between 1 and %d characters", ErrInvalidStatusShare, maxStatusShareNameLength)
+	}
+	if share.ExpiresAt != nil && !share.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidStatusShare)
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accessible := make(map[string]bool, len(tanks))
+	for _, tank := range tanks {
+		accessible[tank.ID] = true
+	}
+
+	tankIDs := make([]string, 0, len(share.TankIDs))
+	seen := make(map[string]bool, len(share.TankIDs))
+	for _, tankID := range share.TankIDs {
+		if seen[tankID] {
+			continue
+		}
+		seen[tankID] = true
+		if !accessible[tankID] {
+			return nil, fmt.Errorf("%w: tank %s not found", ErrInvalidStatusShare, tankID)
+		}
+		tankIDs = append(tankIDs, tankID)
+	}
+	if len(tankIDs) == 0 || len(tankIDs) > domain.MaxStatusShareTanks {
+		return nil, fmt.Errorf("%w: between 1 and %d tanks are required", ErrInvalidStatusShare, domain.MaxStatusShareTanks)
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	created := &domain.StatusShare{
+		ID:        uuid.New().String(),
+		Name:      name,
+		TankIDs:   tankIDs,
+		TokenHash: domain.HashShareToken(token),
+		CreatedBy: principalName(ctx),
+		CreatedAt: now,
+		ExpiresAt: share.ExpiresAt,
+	}
+	if err := s.shareRepo.SaveShare(ctx, created); err != nil {
+		return nil, err
+	}
+
+	created.Token = token
+	return created, nil
+}
+
+// GetShares devuelve todas las páginas, incluidas las revocadas y las vencidas
+func (s *StatusShareServiceImpl) GetShares(ctx context.Context) ([]*domain.StatusShare, error) {
+	return s.shareRepo.GetShares(ctx)
+}
+
+// RevokeShare desactiva la página; revocar una página ya revocada no la modifica
+func (s *StatusShareServiceImpl) RevokeShare(ctx context.Context, id string) (*domain.StatusShare, error) {
+	share, err := s.shareRepo.GetShare(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if share == nil {
+		return nil, ErrStatusShareNotFound
+	}
+	if share.RevokedAt != nil {
+		return share, nil
+	}
+
+	now := time.Now()
+	share.RevokedAt = &now
+	if err := s.shareRepo.SaveShare(ctx, share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// GetPublicStatus devuelve el nivel de los tanques de la página. Un token desconocido, revocado o
+// vencido responde ErrStatusShareNotFound, sin distinguir el motivo. Los tanques eliminados
+// después de crear la página se omiten.
+func (s *StatusShareServiceImpl) GetPublicStatus(ctx context.Context, token string) (*domain.PublicStatusPage, error) {
+	now := time.Now()
+	share, err := s.shareRepo.GetShareByTokenHash(ctx, domain.HashShareToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if share == nil || !share.IsActive(now) {
+		return nil, ErrStatusShareNotFound
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tanksByID := make(map[string]*domain.Tank, len(tanks))
+	for _, tank := range tanks {
+		tanksByID[tank.ID] = tank
+	}
+
+	page := &domain.PublicStatusPage{Name: share.Name, GeneratedAt: now, Tanks: []*domain.PublicTankStatus{}}
+	for _, tankID := range share.TankIDs {
+		if tank, ok := tanksByID[tankID]; ok {
+			page.Tanks = append(page.Tanks, domain.NewPublicTankStatus(tank))
+		}
+	}
+
+	return page, nil
+}
+
+// newShareToken genera un token aleatorio de 256 bits apto para una URL
+func newShareToken() (string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
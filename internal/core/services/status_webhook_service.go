@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// StatusWebhookTankService decora un TankService avisando al webhook propio de cada tanque
+// (domain.Tank.StatusWebhookURL) cuando cambia su estado, con el estado anterior, el nuevo y la
+// última medición. Compara el estado de los tanques afectados antes y después de cada operación.
+type StatusWebhookTankService struct {
+	ports.TankService
+	measurementRepo ports.MeasurementRepository
+	sender          ports.StatusWebhookSender
+}
+
+// NewStatusWebhookTankService crea un TankService que avisa de los cambios de estado a los webhooks
+// de los tanques
+func NewStatusWebhookTankService(inner ports.TankService, measurementRepo ports.MeasurementRepository, sender ports.StatusWebhookSender) ports.TankService {
+	return &StatusWebhookTankService{
+		TankService:     inner,
+		measurementRepo: measurementRepo,
+		sender:          sender,
+	}
+}
+
+// UpdateTank actualiza el tanque; un cambio de umbral puede cambiar también su estado
+func (s *StatusWebhookTankService) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	if tank == nil {
+		return s.TankService.UpdateTank(ctx, tank)
+	}
+
+	before := s.statuses(ctx, []string{tank.ID})
+	if err := s.TankService.UpdateTank(ctx, tank); err != nil {
+		return err
+	}
+	s.notify(ctx, before)
+	return nil
+}
+
+// AddMeasurement guarda la medición y avisa si cambió el estado del tanque
+func (s *StatusWebhookTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if measurement == nil {
+		return s.TankService.AddMeasurement(ctx, measurement)
+	}
+
+	before := s.statuses(ctx, []string{measurement.TankID})
+	if err := s.TankService.AddMeasurement(ctx, measurement); err != nil {
+		return err
+	}
+	s.notify(ctx, before)
+	return nil
+}
+
+// AddMeasurements guarda el lote y avisa de los tanques cuyo estado cambió
+func (s *StatusWebhookTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	tankIDs := make([]string, 0, len(measurements))
+	for _, measurement := range measurements {
+		if measurement != nil {
+			tankIDs = append(tankIDs, measurement.TankID)
+		}
+	}
+
+	before := s.statuses(ctx, tankIDs)
+	if err := s.TankService.AddMeasurements(ctx, measurements); err != nil {
+		return err
+	}
+	s.notify(ctx, before)
+	return nil
+}
+
+// BackfillMeasurements importa el historial y avisa si la importación cambió el estado actual
+func (s *StatusWebhookTankService) BackfillMeasurements(ctx context.Context, tankID string, measurements []*domain.Measurement) (*domain.BackfillResult, error) {
+	before := s.statuses(ctx, []string{tankID})
+	result, err := s.TankService.BackfillMeasurements(ctx, tankID, measurements)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(ctx, before)
+	return result, nil
+}
+
+// statuses devuelve el estado actual de los tanques con webhook. Los tanques que no se pueden
+// leer se omiten: la operación decorada devolverá su propio error.
+func (s *StatusWebhookTankService) statuses(ctx context.Context, tankIDs []string) map[string]string {
+	statuses := make(map[string]string)
+	for _, tankID := range tankIDs {
+		if _, ok := statuses[tankID]; ok {
+			continue
+		}
+		tank, err := s.TankService.GetTank(ctx, tankID)
+		if err != nil || tank == nil || tank.StatusWebhookURL == "" {
+			continue
+		}
+		statuses[tankID] = tank.Status
+	}
+	return statuses
+}
+
+// notify avisa a los webhooks de los tanques cuyo estado ya no es el de before
+func (s *StatusWebhookTankService) notify(ctx context.Context, before map[string]string) {
+	for tankID, oldStatus := range before {
+		tank, err := s.TankService.GetTank(ctx, tankID)
+		if err != nil || tank == nil || tank.StatusWebhookURL == "" || tank.Status == oldStatus {
+			continue
+		}
+
+		// Sin la última medición el aviso se envía igualmente
+		measurement, _ := s.measurementRepo.GetLastMeasurement(ctx, tankID)
+		s.sender.SendStatusWebhook(ctx, tank.StatusWebhookURL, domain.NewStatusWebhookEvent(tank, oldStatus, measurement))
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// SwappableAlertNotifier delega en un notificador que puede reemplazarse en caliente, de modo
+// que la recarga de la configuración cambie el notificador predeterminado sin reconstruir la
+// cadena de decoradores que lo envuelve
+type SwappableAlertNotifier struct {
+	mutex sync.RWMutex
+	next  ports.AlertNotifier
+}
+
+// NewSwappableAlertNotifier crea un notificador reemplazable que empieza delegando en next
+func NewSwappableAlertNotifier(next ports.AlertNotifier) *SwappableAlertNotifier {
+	return &SwappableAlertNotifier{next: next}
+}
+
+// Swap reemplaza el notificador; las alertas en curso terminan con el anterior
+func (n *SwappableAlertNotifier) Swap(next ports.AlertNotifier) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.next = next
+}
+
+// Notify entrega la alerta al notificador en vigor
+func (n *SwappableAlertNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	n.mutex.RLock()
+	next := n.next
+	n.mutex.RUnlock()
+
+	return next.Notify(ctx, alert)
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver el servicio de cambios pendientes de aprobación
+var (
+	ErrTankChangeNotFound   = errors.New("tank change request not found")
+	ErrTankChangeNotPending = errors.New("tank change request is not pending")
+	ErrTankChangeConflict   = errors.New("tank changed since the request was made")
+	ErrInvalidTankChange    = errors.New("invalid tank change review")
+)
+
+// maxTankChangeCommentLength limita la longitud del comentario de la revisión
+const maxTankChangeCommentLength = 500
+
+// TankChangeServiceImpl implementa la interfaz TankChangeService
+type TankChangeServiceImpl struct {
+	tankService ports.TankService
+	changeRepo  ports.TankChangeRepository
+}
+
+// NewTankChangeService crea una nueva instancia del servicio de cambios pendientes de aprobación
+func NewTankChangeService(tankService ports.TankService, changeRepo ports.TankChangeRepository) ports.TankChangeService {
+	return &TankChangeServiceImpl{
+		tankService: tankService,
+		changeRepo:  changeRepo,
+	}
+}
+
+// GetTankChanges devuelve las solicitudes que cumplen el filtro, solo de los tanques accesibles
+func (s *TankChangeServiceImpl) GetTankChanges(ctx context.Context, filter domain.TankChangeFilter) ([]*domain.TankChangeRequest, error) {
+	if filter.Status != "" && !domain.IsValidTankChangeStatus(filter.Status) {
+		return nil, ErrInvalidTankChange
+	}
+
+	changes, err := s.changeRepo.GetTankChanges(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un tanque eliminado o sin acceso oculta sus solicitudes
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accessible := make(map[string]bool, len(tanks))
+	for _, tank := range tanks {
+		accessible[tank.ID] = true
+	}
+
+	visible := make([]*domain.TankChangeRequest, 0, len(changes))
+	for _, change := range changes {
+		if accessible[change.TankID] {
+			visible = append(visible, change)
+		}
+	}
+	return visible, nil
+}
+
+// GetTankChange devuelve una solicitud de un tanque accesible
+func (s *TankChangeServiceImpl) GetTankChange(ctx context.Context, id string) (*domain.TankChangeRequest, error) {
+	change, err := s.changeRepo.GetTankChange(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return nil, ErrTankChangeNotFound
+	}
+
+	if _, err := s.tankService.GetTank(ctx, change.TankID); err != nil {
+		if errors.Is(err, ErrTankNotFound) {
+			return nil, ErrTankChangeNotFound
+		}
+		return nil, err
+	}
+	return change, nil
+}
+
+// ApproveTankChange aplica al tanque los valores de una solicitud pendiente. Si el umbral o la
+// capacidad cambiaron desde la solicitud, no se aplica: el revisor aprobaría sin saberlo sobre
+// otros valores.
+func (s *TankChangeServiceImpl) ApproveTankChange(ctx context.Context, id, comment string) (*domain.TankChangeRequest, error) {
+	change, comment, err := s.pending(ctx, id, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	tank, err := s.tankService.GetTank(ctx, change.TankID)
+	if err != nil {
+		return nil, err
+	}
+	if change.IsStale(tank) {
+		return nil, ErrTankChangeConflict
+	}
+
+	// Trabajamos sobre una copia para no modificar el tanque que devolvió el servicio
+	updated := *tank
+	change.Apply(&updated)
+	if err := s.tankService.UpdateTank(ctx, &updated); err != nil {
+		return nil, err
+	}
+
+	change.Review(domain.TankChangeApproved, principalName(ctx), comment, time.Now())
+	if err := s.changeRepo.SaveTankChange(ctx, change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// RejectTankChange descarta una solicitud pendiente con el motivo indicado
+func (s *TankChangeServiceImpl) RejectTankChange(ctx context.Context, id, reason string) (*domain.TankChangeRequest, error) {
+	change, reason, err := s.pending(ctx, id, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	change.Review(domain.TankChangeRejected, principalName(ctx), reason, time.Now())
+	if err := s.changeRepo.SaveTankChange(ctx, change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// pending obtiene una solicitud pendiente de revisión y valida el comentario del revisor
+func (s *TankChangeServiceImpl) pending(ctx context.Context, id, comment string) (*domain.TankChangeRequest, string, error) {
+	comment = strings.TrimSpace(comment)
+	if len(comment) > maxTankChangeCommentLength {
+		return nil, "", ErrInvalidTankChange
+	}
+
+	change, err := s.GetTankChange(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if change.Status != domain.TankChangePending {
+		return nil, "", ErrTankChangeNotPending
+	}
+	return change, comment, nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// TankDefaults guarda los valores por defecto de los tanques nuevos, que pueden cambiar al
+// recargar la configuración
+type TankDefaults struct {
+	mutex          sync.RWMutex
+	alertThreshold float64
+}
+
+// NewTankDefaults crea los valores por defecto de los tanques nuevos
+func NewTankDefaults(alertThreshold float64) *TankDefaults {
+	return &TankDefaults{alertThreshold: alertThreshold}
+}
+
+// AlertThreshold devuelve el umbral de alerta de los tanques que no indican uno
+func (d *TankDefaults) AlertThreshold() float64 {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.alertThreshold
+}
+
+// SetAlertThreshold cambia el umbral de alerta por defecto; no afecta a los tanques existentes
+func (d *TankDefaults) SetAlertThreshold(threshold float64) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.alertThreshold = threshold
+}
+
+// DefaultsTankService decora un TankService completando los tanques nuevos con los valores por
+// defecto en vigor
+type DefaultsTankService struct {
+	ports.TankService
+	defaults *TankDefaults
+}
+
+// NewDefaultsTankService crea un TankService que aplica los valores por defecto indicados
+func NewDefaultsTankService(inner ports.TankService, defaults *TankDefaults) ports.TankService {
+	return &DefaultsTankService{
+		TankService: inner,
+		defaults:    defaults,
+	}
+}
+
+// CreateTank completa el umbral de alerta del tanque que no indica uno
+func (s *DefaultsTankService) CreateTank(ctx context.Context, tank *domain.Tank) error {
+	if tank != nil && tank.AlertThreshold <= 0 {
+		tank.AlertThreshold = s.defaults.AlertThreshold()
+	}
+
+	return s.TankService.CreateTank(ctx, tank)
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// TankImportServiceImpl implementa la interfaz TankImportService. Los tanques se crean con el
+// servicio de tanques (con control de acceso), así que se aplican sus mismas validaciones.
+type TankImportServiceImpl struct {
+	tankService ports.TankService
+	access      ports.AccessService
+}
+
+// NewTankImportService crea una nueva instancia del servicio de importación de tanques
+func NewTankImportService(tankService ports.TankService, access ports.AccessService) ports.TankImportService {
+	return &TankImportServiceImpl{
+		tankService: tankService,
+		access:      access,
+	}
+}
+
+// ImportTanks valida cada fila y crea los tanques de las válidas. Las filas con errores se omiten
+// sin impedir el resto; el informe indica el resultado de cada una. Con dryRun no se crea nada,
+// de modo que puede revisarse el archivo antes de importarlo.
+func (s *TankImportServiceImpl) ImportTanks(ctx context.Context, rows []*domain.TankImportRow, dryRun bool) (*domain.TankImportResult, error) {
+	existing, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existingIDs := make(map[string]bool, len(existing))
+	for _, tank := range existing {
+		existingIDs[tank.ID] = true
+	}
+
+	result := &domain.TankImportResult{DryRun: dryRun, Rows: make([]*domain.TankImportRowResult, 0, len(rows))}
+	seenIDs := make(map[string]int)
+
+	for _, row := range rows {
+		tank := row.Tank
+		problems := append([]domain.TankImportError(nil), row.Errors...)
+		problems = append(problems, domain.ValidateImportedTank(tank)...)
+
+		if tank.ID != "" {
+			if first, repeated := seenIDs[tank.ID]; repeated && first != row.Row {
+				problems = append(problems, domain.TankImportError{Column: "id", Message: "El ID está repetido en el archivo"})
+			} else {
+				seenIDs[tank.ID] = row.Row
+			}
+			if existingIDs[tank.ID] {
+				problems = append(problems, domain.TankImportError{Column: "id", Message: "Ya existe un tanque con ese ID"})
+			}
+		}
+
+		allowed, err := s.access.CanAccessTank(ctx, tank)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			problems = append(problems, domain.TankImportError{Message: "Sin acceso al sitio o grupo del tanque"})
+		}
+
+		rowResult := &domain.TankImportRowResult{Row: row.Row, TankID: tank.ID, Name: tank.Name}
+		switch {
+		case len(problems) > 0:
+			rowResult.Status = domain.TankImportInvalid
+			rowResult.Errors = problems
+		case dryRun:
+			rowResult.Status = domain.TankImportValid
+		default:
+			if tank.ID == "" {
+				tank.ID = uuid.New().String()
+			}
+			if err := s.tankService.CreateTank(ctx, tank); err != nil {
+				if !errors.Is(err, ErrInvalidTank) && !errors.Is(err, ErrForbidden) {
+					return result, err
+				}
+				rowResult.Status = domain.TankImportInvalid
+				rowResult.Errors = []domain.TankImportError{{Message: "El tanque no es válido"}}
+				break
+			}
+			rowResult.TankID = tank.ID
+			rowResult.Status = domain.TankImportCreated
+		}
+
+		result.Add(rowResult)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// Errores que puede devolver el servicio de lectura bajo demanda
+var (
+	ErrNoPollableDevice = errors.New("tank has no field device to poll")
+	ErrPollTimeout      = errors.New("timed out waiting for a fresh measurement")
+)
+
+// pollCheckInterval es la frecuencia con la que se busca la nueva medición
+const pollCheckInterval = 200 * time.Millisecond
+
+// TankPollServiceImpl implementa la interfaz TankPollService
+type TankPollServiceImpl struct {
+	tankService        ports.TankService
+	fieldDeviceService ports.FieldDeviceService
+	commandService     ports.DeviceCommandService
+	measurementRepo    ports.MeasurementRepository
+	timeout            time.Duration
+}
+
+// NewTankPollService crea una nueva instancia del servicio de lectura bajo demanda. timeout es
+// el tiempo máximo de espera de la nueva medición.
+func NewTankPollService(
+	tankService ports.TankService,
+	fieldDeviceService ports.FieldDeviceService,
+	commandService ports.DeviceCommandService,
+	measurementRepo ports.MeasurementRepository,
+	timeout time.Duration,
+) ports.TankPollService {
+	return &TankPollServiceImpl{
+		tankService:        tankService,
+		fieldDeviceService: fieldDeviceService,
+		commandService:     commandService,
+		measurementRepo:    measurementRepo,
+		timeout:            timeout,
+	}
+}
+
+// PollTank solicita una lectura inmediata a los equipos del tanque y espera a que llegue la
+// medición. Se considera nueva cualquier medición distinta de la última conocida al solicitarla,
+// de modo que un reloj desajustado en el equipo no impida reconocerla.
+func (s *TankPollServiceImpl) PollTank(ctx context.Context, tankID string) (*domain.Measurement, error) {
+	if _, err := s.tankService.GetTank(ctx, tankID); err != nil {
+		return nil, err
+	}
+
+	devices, err := s.fieldDeviceService.GetFieldDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.measurementRepo.GetLastMeasurement(ctx, tankID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Basta con que un equipo del tanque reciba la solicitud
+	var sendErrs []error
+	requested := 0
+	for _, device := range devices {
+		if device.TankID != tankID {
+			continue
+		}
+
+		command := &domain.DeviceCommand{
+			ID:       uuid.New().String(),
+			DeviceID: device.ID,
+			Type:     domain.DeviceCommandReadNow,
+		}
+		if err := s.commandService.SendCommand(ctx, command); err != nil {
+			sendErrs = append(sendErrs, err)
+			continue
+		}
+		requested++
+	}
+
+	if requested == 0 {
+		if len(sendErrs) > 0 {
+			return nil, errors.Join(sendErrs...)
+		}
+		return nil, ErrNoPollableDevice
+	}
+
+	return s.waitForMeasurement(ctx, tankID, previous)
+}
+
+// waitForMeasurement espera una medición del tanque distinta de previous
+func (s *TankPollServiceImpl) waitForMeasurement(ctx context.Context, tankID string, previous *domain.Measurement) (*domain.Measurement, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(pollCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, ErrPollTimeout
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		latest, err := s.measurementRepo.GetLastMeasurement(ctx, tankID)
+		if err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			return nil, err
+		}
+
+		if latest != nil && (previous == nil || latest.ID != previous.ID) {
+			return latest, nil
+		}
+	}
+}
@@ -6,21 +6,60 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+
 	"monitor-tanques/internal/core/domain"
 	"monitor-tanques/internal/core/ports"
 )
 
 // Errores comunes que puede devolver el servicio
 var (
-	ErrTankNotFound = errors.New("tank not found")
+	ErrTankNotFound = domain.ErrTankNotFound
 	ErrInvalidTank  = errors.New("invalid tank data")
+
+	// ErrInvalidMeasurement se devuelve con una medición sin tanque o con nivel negativo
+	ErrInvalidMeasurement = errors.New("invalid measurement data")
+
+	// ErrTankHasCompartments se devuelve al eliminar un tanque que aún tiene compartimentos, o al
+	// convertirlo en compartimento de otro: la jerarquía tiene un solo nivel
+	ErrTankHasCompartments = errors.New("tank has compartments")
+
+	ErrInvalidBackfill = errors.New("invalid backfill")
 )
 
-// TankServiceImpl implementa la interfaz TankService
+// IsPermanentRejection indica si el error al guardar una medición o aplicar un cambio de
+// configuración recibidos de otro sistema es definitivo: repetirlo daría el mismo error, así que
+// se descartan en lugar de reintentarse. Cualquier otro error puede ser pasajero.
+func IsPermanentRejection(err error) bool {
+	var pending *PendingApprovalError
+	return errors.Is(err, ErrInvalidMeasurement) ||
+		errors.Is(err, ErrInvalidTank) ||
+		errors.Is(err, ErrTankNotFound) ||
+		errors.Is(err, ErrTankHasCompartments) ||
+		errors.Is(err, ErrForbidden) ||
+		errors.Is(err, ErrFutureMeasurement) ||
+		errors.Is(err, ErrChangeRequiresApproval) ||
+		errors.Is(err, domain.ErrSafetyPolicyViolation) ||
+		errors.As(err, &pending)
+}
+
+// IsStaleMeasurement indica si AddMeasurement no aplicó la lectura como la actual del tanque porque
+// ya estaba guardada o es anterior a la última. Para quien la envía no es un error, pero no debe
+// tratarse como una lectura nueva: no se analiza ni se difunde.
+func IsStaleMeasurement(err error) bool {
+	return errors.Is(err, domain.ErrDuplicateMeasurement) || errors.Is(err, domain.ErrLateMeasurement)
+}
+
+// TankServiceImpl implementa la interfaz TankService. El nivel, la temperatura y el estado
+// actuales de cada tanque se leen de un modelo de lectura (states) que se actualiza al guardar
+// mediciones; los tanques devueltos son copias que pueden modificarse sin afectarlo.
 type TankServiceImpl struct {
 	tankRepo        ports.TankRepository
 	measurementRepo ports.MeasurementRepository
 	alertNotifier   ports.AlertNotifier
+	unitOfWork      ports.UnitOfWork
+	states          ports.TankStateStore
+	outbox          bool // Las alertas se guardan en la bandeja de salida en lugar de enviarse
 }
 
 // NewTankService crea una nueva instancia del servicio de tanques
@@ -28,11 +67,35 @@ func NewTankService(
 	tankRepo ports.TankRepository,
 	measurementRepo ports.MeasurementRepository,
 	alertNotifier ports.AlertNotifier,
+	unitOfWork ports.UnitOfWork,
+	states ports.TankStateStore,
+) ports.TankService {
+	return &TankServiceImpl{
+		tankRepo:        tankRepo,
+		measurementRepo: measurementRepo,
+		alertNotifier:   alertNotifier,
+		unitOfWork:      unitOfWork,
+		states:          states,
+	}
+}
+
+// NewTankServiceWithOutbox crea un servicio de tanques que guarda las alertas en la bandeja de
+// salida de la unidad de trabajo, junto con las mediciones que las provocan. Las entrega después
+// OutboxService a través de alertNotifier.
+func NewTankServiceWithOutbox(
+	tankRepo ports.TankRepository,
+	measurementRepo ports.MeasurementRepository,
+	alertNotifier ports.AlertNotifier,
+	unitOfWork ports.UnitOfWork,
+	states ports.TankStateStore,
 ) ports.TankService {
 	return &TankServiceImpl{
 		tankRepo:        tankRepo,
 		measurementRepo: measurementRepo,
 		alertNotifier:   alertNotifier,
+		unitOfWork:      unitOfWork,
+		states:          states,
+		outbox:          true,
 	}
 }
 
@@ -51,16 +114,7 @@ func (s *TankServiceImpl) GetTank(ctx context.Context, id string) (*domain.Tank,
 		return nil, ErrTankNotFound
 	}
 
-	// Obtenemos la última medición para actualizar el estado actual
-	lastMeasurement, err := s.measurementRepo.GetLastMeasurement(ctx, id)
-	if err == nil && lastMeasurement != nil {
-		tank.CurrentLevel = lastMeasurement.Level
-		tank.Temperature = lastMeasurement.Temperature
-		tank.LastUpdated = lastMeasurement.Timestamp
-		tank.UpdateStatus()
-	}
-
-	return tank, nil
+	return s.project(ctx, tank), nil
 }
 
 // GetAllTanks obtiene todos los tanques
@@ -70,18 +124,44 @@ func (s *TankServiceImpl) GetAllTanks(ctx context.Context) ([]*domain.Tank, erro
 		return nil, err
 	}
 
-	// Actualizamos los estados de todos los tanques con las últimas mediciones
-	for _, tank := range tanks {
+	// Completamos cada tanque con el estado de su última medición
+	projected := make([]*domain.Tank, len(tanks))
+	for i, tank := range tanks {
+		projected[i] = s.project(ctx, tank)
+	}
+
+	return projected, nil
+}
+
+// project devuelve una copia del tanque con el estado de su última medición. La proyección se
+// carga del repositorio de mediciones solo la primera vez (o al vencer); después se mantiene
+// al guardar mediciones.
+func (s *TankServiceImpl) project(ctx context.Context, tank *domain.Tank) *domain.Tank {
+	state, ok := s.states.Get(tank.ID)
+	if !ok {
 		lastMeasurement, err := s.measurementRepo.GetLastMeasurement(ctx, tank.ID)
-		if err == nil && lastMeasurement != nil {
-			tank.CurrentLevel = lastMeasurement.Level
-			tank.Temperature = lastMeasurement.Temperature
-			tank.LastUpdated = lastMeasurement.Timestamp
-			tank.UpdateStatus()
+		if err != nil {
+			// Sin proyección, el tanque conserva los valores guardados con él
+			return domain.NewTankState(tank.ID, nil).Project(tank)
 		}
+		state = domain.NewTankState(tank.ID, lastMeasurement)
+		s.states.Put(state)
 	}
 
-	return tanks, nil
+	return state.Project(tank)
+}
+
+// refreshState vuelve a cargar la proyección de los tanques tras guardar sus mediciones. Se lee
+// del repositorio para que una medición atrasada no reemplace a otra más reciente.
+func (s *TankServiceImpl) refreshState(ctx context.Context, tankIDs ...string) {
+	for _, tankID := range tankIDs {
+		lastMeasurement, err := s.measurementRepo.GetLastMeasurement(ctx, tankID)
+		if err != nil {
+			s.states.Remove(tankID)
+			continue
+		}
+		s.states.Put(domain.NewTankState(tankID, lastMeasurement))
+	}
 }
 
 // CreateTank crea un nuevo tanque
@@ -90,14 +170,32 @@ func (s *TankServiceImpl) CreateTank(ctx context.Context, tank *domain.Tank) err
 		return ErrInvalidTank
 	}
 
+	if (tank.Location != nil && !tank.Location.IsValid()) || tank.Labels.Validate() != nil {
+		return ErrInvalidTank
+	}
+	if !domain.IsValidWebhookURL(tank.StatusWebhookURL) || !domain.IsValidTimezone(tank.Timezone) {
+		return ErrInvalidTank
+	}
+
+	if err := s.checkParent(ctx, tank); err != nil {
+		return err
+	}
+
 	// Aseguramos que tenga los valores predeterminados adecuados
 	tank.Status = "normal"
 	if tank.AlertThreshold <= 0 {
-		tank.AlertThreshold = 10.0 // Valor predeterminado: 10%
+		tank.AlertThreshold = domain.DefaultAlertThreshold
 	}
 	tank.LastUpdated = time.Now()
 
-	return s.tankRepo.SaveTank(ctx, tank)
+	return s.unitOfWork.Do(ctx, func(ctx context.Context, repos ports.TxRepositories) error {
+		if err := repos.Tanks.SaveTank(ctx, tank); err != nil {
+			return err
+		}
+
+		// Registramos el estado inicial para poder contabilizar el tiempo en cada estado
+		return recordStatusChange(ctx, repos, tank, "")
+	})
 }
 
 // UpdateTank actualiza un tanque existente
@@ -106,6 +204,13 @@ func (s *TankServiceImpl) UpdateTank(ctx context.Context, tank *domain.Tank) err
 		return ErrInvalidTank
 	}
 
+	if (tank.Location != nil && !tank.Location.IsValid()) || tank.Labels.Validate() != nil {
+		return ErrInvalidTank
+	}
+	if !domain.IsValidWebhookURL(tank.StatusWebhookURL) || !domain.IsValidTimezone(tank.Timezone) {
+		return ErrInvalidTank
+	}
+
 	// Verificamos que el tanque exista
 	existingTank, err := s.tankRepo.GetTank(ctx, tank.ID)
 	if err != nil {
@@ -116,11 +221,21 @@ func (s *TankServiceImpl) UpdateTank(ctx context.Context, tank *domain.Tank) err
 		return ErrTankNotFound
 	}
 
+	if err := s.checkParent(ctx, tank); err != nil {
+		return err
+	}
+
 	// Actualizamos el estado basado en los valores actuales
 	tank.UpdateStatus()
 	tank.LastUpdated = time.Now()
 
-	return s.tankRepo.UpdateTank(ctx, tank)
+	return s.unitOfWork.Do(ctx, func(ctx context.Context, repos ports.TxRepositories) error {
+		if err := repos.Tanks.UpdateTank(ctx, tank); err != nil {
+			return err
+		}
+
+		return recordStatusChange(ctx, repos, tank, existingTank.Status)
+	})
 }
 
 // DeleteTank elimina un tanque por su ID
@@ -139,7 +254,61 @@ func (s *TankServiceImpl) DeleteTank(ctx context.Context, id string) error {
 		return ErrTankNotFound
 	}
 
-	return s.tankRepo.DeleteTank(ctx, id)
+	// Los compartimentos no pueden quedar huérfanos
+	compartments, err := s.compartments(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(compartments) > 0 {
+		return ErrTankHasCompartments
+	}
+
+	if err := s.tankRepo.DeleteTank(ctx, id); err != nil {
+		return err
+	}
+
+	s.states.Remove(id)
+	return nil
+}
+
+// checkParent comprueba el tanque padre de un compartimento: debe existir y no ser a su vez un
+// compartimento, y un tanque con compartimentos no puede pasar a ser uno
+func (s *TankServiceImpl) checkParent(ctx context.Context, tank *domain.Tank) error {
+	if tank.ParentID == "" {
+		return nil
+	}
+	if tank.ParentID == tank.ID {
+		return ErrInvalidTank
+	}
+
+	parent, err := s.tankRepo.GetTank(ctx, tank.ParentID)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err != nil || parent == nil || parent.ParentID != "" {
+		return ErrInvalidTank
+	}
+
+	if tank.ID == "" {
+		return nil
+	}
+	compartments, err := s.compartments(ctx, tank.ID)
+	if err != nil {
+		return err
+	}
+	if len(compartments) > 0 {
+		return ErrTankHasCompartments
+	}
+	return nil
+}
+
+// compartments devuelve los compartimentos guardados del tanque
+func (s *TankServiceImpl) compartments(ctx context.Context, tankID string) ([]*domain.Tank, error) {
+	tanks, err := s.tankRepo.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domain.CompartmentsOf(tanks, tankID), nil
 }
 
 // MonitorTank monitorea un tanque específico y genera alertas si es necesario
@@ -150,21 +319,76 @@ func (s *TankServiceImpl) MonitorTank(ctx context.Context, tankID string) error
 	}
 
 	// Verificamos si el nivel es crítico y enviamos una alerta
-	if tank.IsLevelCritical() {
-		message := "¡Alerta! El tanque " + tank.Name + " está en nivel crítico (" +
-			"nivel: " + fmt.Sprintf("%.2f%%", tank.GetLevelPercentage()) + "). " +
-			"Se requiere atención inmediata."
+	alert := criticalLevelAlert(tank)
+	if alert == nil {
+		return nil
+	}
+
+	if s.outbox {
+		return s.unitOfWork.Do(ctx, func(ctx context.Context, repos ports.TxRepositories) error {
+			return repos.Outbox.AddEvent(ctx, domain.NewOutboxEvent(uuid.New().String(), alert, time.Now()))
+		})
+	}
+	return s.alertNotifier.Notify(ctx, alert)
+}
 
-		return s.alertNotifier.SendAlert(ctx, tankID, message)
+// criticalLevelAlert devuelve la alerta de nivel crítico del tanque, o nil si no está en nivel crítico
+func criticalLevelAlert(tank *domain.Tank) *domain.Alert {
+	if !tank.IsLevelCritical() {
+		return nil
 	}
 
-	return nil
+	return domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, tank,
+		"¡Alerta! El tanque %s está en nivel crítico (nivel: %.2f%%). Se requiere atención inmediata.",
+		tank.Name, tank.GetLevelPercentage())
 }
 
-// AddMeasurement añade una nueva medición para un tanque
+// stageAlert guarda en la bandeja de salida, dentro de la unidad de trabajo, la alerta que
+// MonitorTank enviaría con el estado resultante del tanque. Sin bandeja de salida no hace nada.
+func (s *TankServiceImpl) stageAlert(ctx context.Context, repos ports.TxRepositories, tankID string) error {
+	if !s.outbox {
+		return nil
+	}
+
+	tank, err := repos.Tanks.GetTank(ctx, tankID)
+	if err != nil {
+		return err
+	}
+	lastMeasurement, err := repos.Measurements.GetLastMeasurement(ctx, tankID)
+	if err != nil {
+		return err
+	}
+
+	alert := criticalLevelAlert(domain.NewTankState(tankID, lastMeasurement).Project(tank))
+	if alert == nil {
+		return nil
+	}
+	return repos.Outbox.AddEvent(ctx, domain.NewOutboxEvent(uuid.New().String(), alert, time.Now()))
+}
+
+// MonitorAllTanks monitorea todos los tanques y genera las alertas necesarias
+func (s *TankServiceImpl) MonitorAllTanks(ctx context.Context) error {
+	tanks, err := s.tankRepo.GetAllTanks(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, tank := range tanks {
+		if err := s.MonitorTank(ctx, tank.ID); err != nil {
+			errs = append(errs, fmt.Errorf("tank %s: %w", tank.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// AddMeasurement añade una nueva medición para un tanque. Una lectura ya guardada devuelve
+// domain.ErrDuplicateMeasurement y una anterior a la última, que solo se guarda en el historial,
+// domain.ErrLateMeasurement (ver IsStaleMeasurement).
 func (s *TankServiceImpl) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
 	if measurement == nil || measurement.TankID == "" || measurement.Level < 0 {
-		return errors.New("invalid measurement data")
+		return ErrInvalidMeasurement
 	}
 
 	// Verificamos que el tanque exista
@@ -182,23 +406,257 @@ func (s *TankServiceImpl) AddMeasurement(ctx context.Context, measurement *domai
 		measurement.Timestamp = time.Now()
 	}
 
-	// Guardamos la medición
-	if err := s.measurementRepo.SaveMeasurement(ctx, measurement); err != nil {
+	// Guardamos la medición y actualizamos el tanque de forma atómica
+	late := false
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context, repos ports.TxRepositories) error {
+		latest, err := repos.Measurements.GetLastMeasurement(ctx, measurement.TankID)
+		if err != nil {
+			return err
+		}
+		if err := repos.Measurements.SaveMeasurement(ctx, measurement); err != nil {
+			return err
+		}
+
+		// Una medición que llega después de otra más reciente solo completa el historial: el
+		// nivel actual no retrocede
+		if latest != nil && measurement.Timestamp.Before(latest.Timestamp) {
+			late = true
+			return nil
+		}
+
+		// Actualizamos el tanque con los nuevos valores
+		previousStatus := tank.Status
+		tank.CurrentLevel = measurement.Level
+		tank.Temperature = measurement.Temperature
+		tank.LastUpdated = measurement.Timestamp
+		tank.UpdateStatus()
+
+		if err := repos.Tanks.UpdateTank(ctx, tank); err != nil {
+			return err
+		}
+
+		if err := recordStatusChange(ctx, repos, tank, previousStatus); err != nil {
+			return err
+		}
+		return s.stageAlert(ctx, repos, tank.ID)
+	})
+	// Un reintento del equipo con una lectura ya guardada devuelve domain.ErrDuplicateMeasurement
+	if err != nil {
 		return err
 	}
+	if late {
+		return domain.ErrLateMeasurement
+	}
+	s.refreshState(ctx, measurement.TankID)
 
-	// Actualizamos el tanque con los nuevos valores
-	tank.CurrentLevel = measurement.Level
-	tank.Temperature = measurement.Temperature
-	tank.LastUpdated = measurement.Timestamp
-	tank.UpdateStatus()
+	// Verificamos si necesitamos enviar alertas; con bandeja de salida ya se guardaron
+	if s.outbox {
+		return nil
+	}
+	return s.MonitorTank(ctx, measurement.TankID)
+}
+
+// AddMeasurements guarda un lote de mediciones y actualiza sus tanques en una sola unidad de
+// trabajo. Si alguna medición no es válida o su tanque no existe, no se guarda ninguna. Las
+// lecturas ya guardadas se omiten y las anteriores a la última guardada de su tanque solo
+// completan el historial, como en AddMeasurement.
+func (s *TankServiceImpl) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	if len(measurements) == 0 {
+		return nil
+	}
 
-	if err := s.tankRepo.UpdateTank(ctx, tank); err != nil {
+	now := time.Now()
+	tankIDs := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, measurement := range measurements {
+		if measurement == nil || measurement.TankID == "" || measurement.Level < 0 {
+			return ErrInvalidMeasurement
+		}
+
+		if measurement.Timestamp.IsZero() {
+			measurement.Timestamp = now
+		}
+
+		if !seen[measurement.TankID] {
+			seen[measurement.TankID] = true
+			tankIDs = append(tankIDs, measurement.TankID)
+		}
+	}
+
+	// Aplicamos las mediciones en orden cronológico para que cada tanque termine con la más reciente
+	ordered := domain.SortMeasurementsAscending(measurements)
+
+	err := s.unitOfWork.Do(ctx, func(ctx context.Context, repos ports.TxRepositories) error {
+		latest := make(map[string]time.Time, len(tankIDs))
+		for _, measurement := range ordered {
+			tank, err := repos.Tanks.GetTank(ctx, measurement.TankID)
+			if err != nil {
+				return err
+			}
+
+			if tank == nil {
+				return ErrTankNotFound
+			}
+
+			if _, known := latest[tank.ID]; !known {
+				lastMeasurement, err := repos.Measurements.GetLastMeasurement(ctx, tank.ID)
+				if err != nil {
+					return err
+				}
+				if lastMeasurement != nil {
+					latest[tank.ID] = lastMeasurement.Timestamp
+				} else {
+					latest[tank.ID] = time.Time{}
+				}
+			}
+
+			err = repos.Measurements.SaveMeasurement(ctx, measurement)
+			if errors.Is(err, domain.ErrDuplicateMeasurement) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if measurement.Timestamp.Before(latest[tank.ID]) {
+				continue
+			}
+			latest[tank.ID] = measurement.Timestamp
+
+			previousStatus := tank.Status
+			tank.CurrentLevel = measurement.Level
+			tank.Temperature = measurement.Temperature
+			tank.LastUpdated = measurement.Timestamp
+			tank.UpdateStatus()
+
+			if err := repos.Tanks.UpdateTank(ctx, tank); err != nil {
+				return err
+			}
+
+			if err := recordStatusChange(ctx, repos, tank, previousStatus); err != nil {
+				return err
+			}
+		}
+
+		for _, tankID := range tankIDs {
+			if err := s.stageAlert(ctx, repos, tankID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
+	s.refreshState(ctx, tankIDs...)
+	if s.outbox {
+		return nil
+	}
 
-	// Verificamos si necesitamos enviar alertas
-	return s.MonitorTank(ctx, measurement.TankID)
+	// Verificamos las alertas una sola vez por tanque
+	var errs []error
+	for _, tankID := range tankIDs {
+		if err := s.MonitorTank(ctx, tankID); err != nil {
+			errs = append(errs, fmt.Errorf("tank %s: %w", tankID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// BackfillMeasurements importa mediciones históricas de un tanque, p. ej. de un sistema anterior.
+// Las mediciones con la misma marca de tiempo que una ya guardada se omiten, de modo que repetir
+// la importación no las duplica. El nivel actual, que es el de la medición más reciente, solo
+// cambia si ninguna medición guardada es posterior a las importadas, y no se evalúan alertas ni
+// anomalías: son lecturas del pasado.
+func (s *TankServiceImpl) BackfillMeasurements(ctx context.Context, tankID string, measurements []*domain.Measurement) (*domain.BackfillResult, error) {
+	if len(measurements) == 0 || len(measurements) > domain.MaxBackfillMeasurements {
+		return nil, fmt.Errorf("%w: between 1 and %d measurements are required", ErrInvalidBackfill, domain.MaxBackfillMeasurements)
+	}
+
+	now := time.Now()
+	for i, measurement := range measurements {
+		if measurement == nil || measurement.Level < 0 {
+			return nil, fmt.Errorf("%w: measurement %d has an invalid level", ErrInvalidBackfill, i)
+		}
+		if measurement.Timestamp.IsZero() || measurement.Timestamp.After(now) {
+			return nil, fmt.Errorf("%w: measurement %d needs a past timestamp", ErrInvalidBackfill, i)
+		}
+		measurement.TankID = tankID
+	}
+
+	existing, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tankID, 0)
+	if err != nil {
+		return nil, err
+	}
+	var latestSaved time.Time
+	saved := make(map[int64]bool, len(existing)+len(measurements))
+	for _, measurement := range existing {
+		saved[measurement.Timestamp.UnixNano()] = true
+		if measurement.Timestamp.After(latestSaved) {
+			latestSaved = measurement.Timestamp
+		}
+	}
+
+	result := &domain.BackfillResult{TankID: tankID, Received: len(measurements)}
+	pending := make([]*domain.Measurement, 0, len(measurements))
+	for _, measurement := range domain.SortMeasurementsAscending(measurements) {
+		key := measurement.Timestamp.UnixNano()
+		if saved[key] {
+			result.Duplicates++
+			continue
+		}
+		saved[key] = true
+		pending = append(pending, measurement)
+	}
+
+	err = s.unitOfWork.Do(ctx, func(ctx context.Context, repos ports.TxRepositories) error {
+		tank, err := repos.Tanks.GetTank(ctx, tankID)
+		if err != nil {
+			return err
+		}
+		if tank == nil {
+			return ErrTankNotFound
+		}
+
+		for _, measurement := range pending {
+			if err := repos.Measurements.SaveMeasurement(ctx, measurement); err != nil {
+				return err
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		latest := pending[len(pending)-1]
+		if !latest.Timestamp.After(latestSaved) {
+			return nil
+		}
+
+		previousStatus := tank.Status
+		tank.CurrentLevel = latest.Level
+		tank.Temperature = latest.Temperature
+		tank.LastUpdated = latest.Timestamp
+		tank.UpdateStatus()
+		result.CurrentLevelUpdated = true
+
+		if err := repos.Tanks.UpdateTank(ctx, tank); err != nil {
+			return err
+		}
+		return recordStatusChange(ctx, repos, tank, previousStatus)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pending) > 0 {
+		result.Imported = len(pending)
+		result.From = pending[0].Timestamp
+		result.To = pending[len(pending)-1].Timestamp
+	}
+	if result.CurrentLevelUpdated {
+		s.refreshState(ctx, tankID)
+	}
+	return result, nil
 }
 
 // GetTankStatus obtiene el estado actual de un tanque
@@ -210,3 +668,17 @@ func (s *TankServiceImpl) GetTankStatus(ctx context.Context, tankID string) (str
 
 	return tank.Status, nil
 }
+
+// recordStatusChange registra la transición si el estado del tanque cambió
+func recordStatusChange(ctx context.Context, repos ports.TxRepositories, tank *domain.Tank, previousStatus string) error {
+	if tank.Status == previousStatus {
+		return nil
+	}
+
+	return repos.StatusChanges.SaveStatusChange(ctx, &domain.StatusChange{
+		TankID:     tank.ID,
+		FromStatus: previousStatus,
+		ToStatus:   tank.Status,
+		ChangedAt:  tank.LastUpdated,
+	})
+}
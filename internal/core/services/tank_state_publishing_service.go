@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// TankStatePublishingTankService decora un TankService publicando el estado de los tanques que
+// cambian (alta, edición, mediciones) y la retirada de los que se eliminan
+type TankStatePublishingTankService struct {
+	ports.TankService
+	publisher ports.TankStatePublisher
+}
+
+// NewTankStatePublishingTankService crea un TankService que publica el estado de los tanques
+func NewTankStatePublishingTankService(inner ports.TankService, publisher ports.TankStatePublisher) *TankStatePublishingTankService {
+	return &TankStatePublishingTankService{
+		TankService: inner,
+		publisher:   publisher,
+	}
+}
+
+// PublishAllTanks publica el estado de todos los tanques, p. ej. al arrancar
+func (s *TankStatePublishingTankService) PublishAllTanks(ctx context.Context) error {
+	tanks, err := s.TankService.GetAllTanks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, tank := range tanks {
+		s.publisher.PublishTank(ctx, tank)
+	}
+	return nil
+}
+
+// CreateTank crea el tanque y lo publica
+func (s *TankStatePublishingTankService) CreateTank(ctx context.Context, tank *domain.Tank) error {
+	if err := s.TankService.CreateTank(ctx, tank); err != nil {
+		return err
+	}
+	if tank != nil {
+		s.publish(ctx, []string{tank.ID})
+	}
+	return nil
+}
+
+// UpdateTank actualiza el tanque y publica su nuevo estado
+func (s *TankStatePublishingTankService) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	if err := s.TankService.UpdateTank(ctx, tank); err != nil {
+		return err
+	}
+	if tank != nil {
+		s.publish(ctx, []string{tank.ID})
+	}
+	return nil
+}
+
+// DeleteTank elimina el tanque y lo retira
+func (s *TankStatePublishingTankService) DeleteTank(ctx context.Context, id string) error {
+	if err := s.TankService.DeleteTank(ctx, id); err != nil {
+		return err
+	}
+	s.publisher.RemoveTank(ctx, id)
+	return nil
+}
+
+// AddMeasurement guarda la medición y publica el estado del tanque
+func (s *TankStatePublishingTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if err := s.TankService.AddMeasurement(ctx, measurement); err != nil {
+		return err
+	}
+	if measurement != nil {
+		s.publish(ctx, []string{measurement.TankID})
+	}
+	return nil
+}
+
+// AddMeasurements guarda el lote y publica el estado de sus tanques
+func (s *TankStatePublishingTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	if err := s.TankService.AddMeasurements(ctx, measurements); err != nil {
+		return err
+	}
+	tankIDs := make([]string, 0, len(measurements))
+	for _, measurement := range measurements {
+		if measurement != nil {
+			tankIDs = append(tankIDs, measurement.TankID)
+		}
+	}
+	s.publish(ctx, tankIDs)
+	return nil
+}
+
+// BackfillMeasurements importa el historial y publica el estado actual del tanque
+func (s *TankStatePublishingTankService) BackfillMeasurements(ctx context.Context, tankID string, measurements []*domain.Measurement) (*domain.BackfillResult, error) {
+	result, err := s.TankService.BackfillMeasurements(ctx, tankID, measurements)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, []string{tankID})
+	return result, nil
+}
+
+// publish publica una vez el estado actual de cada tanque. Los tanques que no se pueden leer se
+// omiten: la operación ya terminó y el siguiente cambio volverá a publicarlos.
+func (s *TankStatePublishingTankService) publish(ctx context.Context, tankIDs []string) {
+	seen := make(map[string]bool, len(tankIDs))
+	for _, tankID := range tankIDs {
+		if seen[tankID] {
+			continue
+		}
+		seen[tankID] = true
+
+		tank, err := s.TankService.GetTank(ctx, tankID)
+		if err != nil || tank == nil {
+			continue
+		}
+		s.publisher.PublishTank(ctx, tank)
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrInvalidSyncBatch se devuelve con un lote de sincronización sin pasarela, sin ID o demasiado grande
+var ErrInvalidSyncBatch = errors.New("invalid sync batch")
+
+// SyncTrackingTankService decora un TankService registrando cada cambio de la configuración de un
+// tanque, con la fecha de cada campo cambiado, en el registro de sincronización del que la otra
+// instancia obtiene los cambios
+type SyncTrackingTankService struct {
+	ports.TankService
+	states ports.TankSyncRepository
+	origin string
+}
+
+// NewSyncTrackingTankService crea un TankService que registra los cambios de configuración como
+// hechos por origin (central o el ID de la pasarela)
+func NewSyncTrackingTankService(inner ports.TankService, states ports.TankSyncRepository, origin string) ports.TankService {
+	return &SyncTrackingTankService{
+		TankService: inner,
+		states:      states,
+		origin:      origin,
+	}
+}
+
+// CreateTank crea el tanque y registra todos sus campos como cambiados
+func (s *SyncTrackingTankService) CreateTank(ctx context.Context, tank *domain.Tank) error {
+	if err := s.TankService.CreateTank(ctx, tank); err != nil {
+		return err
+	}
+	return s.states.SaveTankSyncState(ctx, domain.NewTankSyncState(nil, tank, domain.TankSyncFields, s.origin, time.Now()))
+}
+
+// UpdateTank actualiza el tanque y registra los campos de configuración que cambiaron
+func (s *SyncTrackingTankService) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	if tank == nil {
+		return s.TankService.UpdateTank(ctx, tank)
+	}
+
+	before, err := s.TankService.GetTank(ctx, tank.ID)
+	if err != nil {
+		return s.TankService.UpdateTank(ctx, tank)
+	}
+	if err := s.TankService.UpdateTank(ctx, tank); err != nil {
+		return err
+	}
+
+	changes := domain.TankConfigChanges(before, tank)
+	if len(changes) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(changes))
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+
+	previous, err := s.states.GetTankSyncState(ctx, tank.ID)
+	if err != nil {
+		return err
+	}
+	return s.states.SaveTankSyncState(ctx, domain.NewTankSyncState(previous, tank, fields, s.origin, time.Now()))
+}
+
+// DeleteTank elimina el tanque y registra el borrado
+func (s *SyncTrackingTankService) DeleteTank(ctx context.Context, id string) error {
+	if err := s.TankService.DeleteTank(ctx, id); err != nil {
+		return err
+	}
+	return s.states.SaveTankSyncState(ctx, domain.NewTankSyncTombstone(id, s.origin, time.Now()))
+}
+
+// seedTankSyncStates registra los tanques que aún no están en el registro, p. ej. los creados
+// antes de activar la sincronización. Sus campos llevan fecha cero: cualquier cambio conocido de
+// la otra instancia gana, pero ninguno se pierde por ser anterior a este registro.
+func seedTankSyncStates(ctx context.Context, tanks ports.TankService, states ports.TankSyncRepository, origin string) error {
+	all, err := tanks.GetAllTanks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, tank := range all {
+		state, err := states.GetTankSyncState(ctx, tank.ID)
+		if err != nil {
+			return err
+		}
+		if state != nil {
+			continue
+		}
+		if err := states.SaveTankSyncState(ctx, domain.NewTankSyncState(nil, tank, domain.TankSyncFields, origin, time.Time{})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tankSyncApplier aplica los cambios de configuración recibidos de la otra instancia. Los aplica
+// sobre un TankService que no los registra: el estado combinado se guarda tal cual, con las
+// fechas de cada campo, para que no vuelva como un cambio nuevo.
+type tankSyncApplier struct {
+	tanks  ports.TankService
+	states ports.TankSyncRepository
+}
+
+// apply combina el estado recibido con el local y aplica al tanque los campos más recientes. Los
+// cambios que no pueden aplicarse nunca (ver IsPermanentRejection) quedan en el resultado como
+// rechazo; cualquier otro fallo se devuelve como error para que el cambio se reintente.
+func (a *tankSyncApplier) apply(ctx context.Context, remote *domain.TankSyncState) (*domain.TankSyncRecord, error) {
+	record := &domain.TankSyncRecord{TankID: remote.TankID}
+	if remote.TankID == "" || (remote.DeletedAt == nil && remote.Tank == nil) {
+		record.Status = domain.TankSyncRejected
+		record.Error = ErrInvalidTank.Error()
+		return record, nil
+	}
+	if remote.Tank != nil {
+		remote.Tank.ID = remote.TankID
+	}
+
+	local, err := a.states.GetTankSyncState(ctx, remote.TankID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := a.tanks.GetTank(ctx, remote.TankID)
+	if err != nil {
+		if !errors.Is(err, ErrTankNotFound) {
+			return nil, err
+		}
+		current = nil
+	}
+	if local == nil && current != nil {
+		// Un tanque anterior al registro conserva los valores que el otro lado no cambió
+		local = domain.NewTankSyncState(nil, current, domain.TankSyncFields, "", time.Time{})
+	}
+
+	merged, fields := domain.MergeTankSyncState(local, remote)
+	if merged == nil {
+		record.Status = domain.TankSyncIgnored
+		return record, nil
+	}
+
+	switch {
+	case merged.DeletedAt != nil:
+		if current != nil {
+			err = a.tanks.DeleteTank(ctx, remote.TankID)
+		}
+	case current == nil:
+		err = a.tanks.CreateTank(ctx, domain.TankSyncConfig(merged.Tank))
+	default:
+		for _, field := range domain.TankSyncFields {
+			domain.CopyTankConfigField(current, merged.Tank, field)
+		}
+		err = a.tanks.UpdateTank(ctx, current)
+	}
+	if err != nil {
+		if !IsPermanentRejection(err) {
+			return nil, err
+		}
+		record.Status = domain.TankSyncRejected
+		record.Error = err.Error()
+		return record, nil
+	}
+
+	if err := a.states.SaveTankSyncState(ctx, merged); err != nil {
+		return nil, err
+	}
+	record.Fields = fields
+	record.Status = domain.TankSyncApplied
+	if merged.DeletedAt == nil && len(fields) < len(remote.FieldTimes) {
+		record.Status = domain.TankSyncMerged
+	}
+	return record, nil
+}
+
+// TankSyncServiceImpl implementa la interfaz TankSyncService
+type TankSyncServiceImpl struct {
+	applier *tankSyncApplier
+}
+
+// NewTankSyncService crea el servicio de la API de sincronización. tanks no debe registrar los
+// cambios en el registro de sincronización: el servicio guarda el estado combinado.
+func NewTankSyncService(tanks ports.TankService, states ports.TankSyncRepository) ports.TankSyncService {
+	return &TankSyncServiceImpl{
+		applier: &tankSyncApplier{tanks: tanks, states: states},
+	}
+}
+
+// Push aplica primero los cambios de configuración, para que existan los tanques nuevos, y
+// después las mediciones. Las mediciones ya guardadas se aceptan sin duplicarse. Solo se rechazan
+// las mediciones y los cambios que no podrían aplicarse nunca; ante cualquier otro fallo (p. ej.
+// del repositorio) el lote entero falla sin guardar su resultado, de modo que la pasarela lo
+// conserva y lo reenvía, y lo ya aplicado no se duplica.
+func (s *TankSyncServiceImpl) Push(ctx context.Context, batch *domain.SyncBatch) (*domain.SyncResult, error) {
+	if batch == nil || batch.GatewayID == "" || batch.BatchID == "" ||
+		len(batch.Measurements) > domain.MaxSyncBatchSize || len(batch.Tanks) > domain.MaxSyncBatchSize {
+		return nil, ErrInvalidSyncBatch
+	}
+
+	states := s.applier.states
+	if result, err := states.GetSyncResult(ctx, batch.GatewayID, batch.BatchID); err != nil || result != nil {
+		return result, err
+	}
+
+	result := &domain.SyncResult{
+		GatewayID:  batch.GatewayID,
+		BatchID:    batch.BatchID,
+		ReceivedAt: time.Now(),
+		Records:    make([]*domain.SyncRecord, 0, len(batch.Measurements)),
+	}
+	for _, state := range batch.Tanks {
+		if state == nil {
+			continue
+		}
+		record, err := s.applier.apply(ctx, state)
+		if err != nil {
+			return nil, err
+		}
+		result.Tanks = append(result.Tanks, record)
+	}
+
+	for _, measurement := range batch.Measurements {
+		if measurement == nil {
+			result.Add(&domain.SyncRecord{Status: domain.SyncRecordRejected, Error: "invalid measurement"})
+			continue
+		}
+
+		record := &domain.SyncRecord{MeasurementID: measurement.ID, TankID: measurement.TankID, Status: domain.SyncRecordAccepted}
+		if err := s.applier.tanks.AddMeasurement(ctx, measurement); err != nil && !IsStaleMeasurement(err) {
+			if !IsPermanentRejection(err) {
+				return nil, err
+			}
+			record.Status = domain.SyncRecordRejected
+			record.Error = err.Error()
+		}
+		result.Add(record)
+	}
+
+	if err := states.SaveSyncResult(ctx, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Pull devuelve los cambios posteriores al cursor. La primera consulta (cursor 0) registra antes
+// los tanques que aún no estaban en el registro, para que la pasarela los reciba todos.
+func (s *TankSyncServiceImpl) Pull(ctx context.Context, cursor int64, limit int) (*domain.SyncPullResult, error) {
+	if cursor < 0 {
+		return nil, ErrInvalidSyncBatch
+	}
+	if limit <= 0 || limit > domain.MaxSyncPullLimit {
+		limit = domain.MaxSyncPullLimit
+	}
+	if cursor == 0 {
+		if err := seedTankSyncStates(ctx, s.applier.tanks, s.applier.states, domain.SyncOriginCentral); err != nil {
+			return nil, err
+		}
+	}
+
+	changes, err := s.applier.states.GetTankSyncStates(ctx, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.SyncPullResult{Tanks: changes, Cursor: cursor}
+	if len(changes) > limit {
+		result.Tanks, result.HasMore = changes[:limit], true
+	}
+	if len(result.Tanks) > 0 {
+		result.Cursor = result.Tanks[len(result.Tanks)-1].Sequence
+	}
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// telemetryHistorySize es el número de lecturas previas del tanque en las que se busca la
+// lectura anterior de cada sensor
+const telemetryHistorySize = 50
+
+// TelemetryAlertingTankService decora un TankService revisando la batería y la señal que
+// reportan los sensores en cada medición guardada. Los avisos se envían por el mismo
+// notificador que las alertas de nivel.
+type TelemetryAlertingTankService struct {
+	ports.TankService
+	measurementRepo ports.MeasurementRepository
+	notifier        ports.AlertNotifier
+	config          domain.TelemetryConfig
+}
+
+// NewTelemetryAlertingTankService crea un TankService que avisa de batería baja y señal débil
+func NewTelemetryAlertingTankService(
+	inner ports.TankService,
+	measurementRepo ports.MeasurementRepository,
+	notifier ports.AlertNotifier,
+	config domain.TelemetryConfig,
+) ports.TankService {
+	return &TelemetryAlertingTankService{
+		TankService:     inner,
+		measurementRepo: measurementRepo,
+		notifier:        notifier,
+		config:          config,
+	}
+}
+
+// AddMeasurement guarda la medición y revisa la telemetría del sensor
+func (s *TelemetryAlertingTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if err := s.TankService.AddMeasurement(ctx, measurement); err != nil {
+		return err
+	}
+
+	return s.check(ctx, []*domain.Measurement{measurement})
+}
+
+// AddMeasurements guarda el lote y revisa la telemetría de cada medición
+func (s *TelemetryAlertingTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	if err := s.TankService.AddMeasurements(ctx, measurements); err != nil {
+		return err
+	}
+
+	return s.check(ctx, measurements)
+}
+
+// check compara cada medición nueva con la lectura anterior del mismo sensor
+func (s *TelemetryAlertingTankService) check(ctx context.Context, measurements []*domain.Measurement) error {
+	if s.config.LowBatteryVoltage <= 0 && s.config.WeakSignalRSSI == 0 {
+		return nil
+	}
+
+	added := make(map[string]bool)
+	countByTank := make(map[string]int)
+	tankIDs := make([]string, 0)
+	for _, measurement := range measurements {
+		if measurement.BatteryVoltage == nil && measurement.SignalStrength == nil {
+			continue
+		}
+		added[measurement.ID] = true
+		if countByTank[measurement.TankID] == 0 {
+			tankIDs = append(tankIDs, measurement.TankID)
+		}
+		countByTank[measurement.TankID]++
+	}
+
+	var errs []error
+	for _, tankID := range tankIDs {
+		// El repositorio devuelve las mediciones de la más reciente a la más antigua
+		recent, err := s.measurementRepo.GetMeasurementsByTankID(ctx, tankID, countByTank[tankID]+telemetryHistorySize)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tank %s: %w", tankID, err))
+			continue
+		}
+
+		var detected []*domain.TelemetryAlert
+		previousBySensor := make(map[string]*domain.Measurement)
+		for _, measurement := range domain.SortMeasurementsAscending(recent) {
+			sensorID := domain.SensorIDForMeasurement(measurement)
+			if added[measurement.ID] {
+				detected = append(detected, domain.DetectTelemetryAlerts(previousBySensor[sensorID], measurement, s.config)...)
+			}
+			previousBySensor[sensorID] = measurement
+		}
+		if len(detected) == 0 {
+			continue
+		}
+
+		tank, err := s.TankService.GetTank(ctx, tankID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tank %s: %w", tankID, err))
+			continue
+		}
+
+		for _, telemetryAlert := range detected {
+			format, args := telemetryMessage(tankID, telemetryAlert)
+			alert := domain.NewAlert(telemetryAlert.Kind, telemetrySeverity(telemetryAlert), tank, format, args...)
+			alert.SensorID = telemetryAlert.SensorID
+			if err := s.notifier.Notify(ctx, alert); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// telemetrySeverity clasifica el aviso: la batería baja deja pronto al sensor sin servicio,
+// mientras que la señal débil solo anticipa posibles lecturas perdidas
+func telemetrySeverity(alert *domain.TelemetryAlert) string {
+	if alert.Kind == domain.TelemetryAlertLowBattery {
+		return domain.AlertSeverityWarning
+	}
+	return domain.AlertSeverityInfo
+}
+
+// telemetryMessage devuelve el formato y los argumentos del mensaje que describe el aviso
+func telemetryMessage(tankID string, alert *domain.TelemetryAlert) (string, []interface{}) {
+	if alert.Kind == domain.TelemetryAlertLowBattery {
+		return "Batería baja en el sensor %s del tanque %s (%.2f V). Programe su reemplazo.",
+			[]interface{}{alert.SensorID, tankID, alert.Value}
+	}
+	return "Señal débil en el sensor %s del tanque %s (%.0f dBm). Revise la antena o la cobertura.",
+		[]interface{}{alert.SensorID, tankID, alert.Value}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// errTrendWindowEnd detiene el recorrido de las mediciones al salir del periodo de la tendencia
+var errTrendWindowEnd = errors.New("trend window end")
+
+// TrendTankService decora un TankService añadiendo a los tanques consultados su tendencia a
+// corto plazo, calculada con las mediciones de domain.TankTrendWindow
+type TrendTankService struct {
+	ports.TankService
+	streamer ports.MeasurementStreamer
+}
+
+// NewTrendTankService crea un TankService que devuelve los tanques con su tendencia
+func NewTrendTankService(inner ports.TankService, streamer ports.MeasurementStreamer) ports.TankService {
+	return &TrendTankService{
+		TankService: inner,
+		streamer:    streamer,
+	}
+}
+
+// GetTank devuelve el tanque con su tendencia
+func (s *TrendTankService) GetTank(ctx context.Context, id string) (*domain.Tank, error) {
+	tank, err := s.TankService.GetTank(ctx, id)
+	if err != nil || tank == nil {
+		return tank, err
+	}
+
+	if tank.Trend, err = s.trend(ctx, tank); err != nil {
+		return nil, err
+	}
+	return tank, nil
+}
+
+// GetAllTanks devuelve los tanques con su tendencia
+func (s *TrendTankService) GetAllTanks(ctx context.Context) ([]*domain.Tank, error) {
+	tanks, err := s.TankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tank := range tanks {
+		if tank.Trend, err = s.trend(ctx, tank); err != nil {
+			return nil, err
+		}
+	}
+	return tanks, nil
+}
+
+// CreateTank descarta la tendencia recibida: se calcula, no se guarda
+func (s *TrendTankService) CreateTank(ctx context.Context, tank *domain.Tank) error {
+	tank.Trend = nil
+	return s.TankService.CreateTank(ctx, tank)
+}
+
+// UpdateTank descarta la tendencia recibida: se calcula, no se guarda
+func (s *TrendTankService) UpdateTank(ctx context.Context, tank *domain.Tank) error {
+	tank.Trend = nil
+	return s.TankService.UpdateTank(ctx, tank)
+}
+
+// trend lee las mediciones del tanque de la más reciente hacia atrás hasta salir del periodo de
+// la tendencia, así que no recorre el historial completo
+func (s *TrendTankService) trend(ctx context.Context, tank *domain.Tank) (*domain.TankTrend, error) {
+	var recent []*domain.Measurement
+	err := s.streamer.StreamMeasurementsByTankID(ctx, tank.ID, 0, func(m *domain.Measurement) error {
+		if len(recent) > 0 && m.Timestamp.Before(recent[0].Timestamp.Add(-domain.TankTrendWindow)) {
+			return errTrendWindowEnd
+		}
+		recent = append(recent, m)
+		return nil
+	})
+	if err != nil && !errors.Is(err, errTrendWindowEnd) {
+		return nil, err
+	}
+	return domain.ComputeTankTrend(tank, recent), nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// UsageServiceImpl implementa la interfaz UsageService
+type UsageServiceImpl struct {
+	usageRepo ports.UsageRepository
+}
+
+// NewUsageService crea una nueva instancia del servicio de medición de uso
+func NewUsageService(usageRepo ports.UsageRepository) ports.UsageService {
+	return &UsageServiceImpl{usageRepo: usageRepo}
+}
+
+// RecordAPICall atribuye una solicitud a la organización del usuario autenticado; las solicitudes
+// anónimas cuentan como UnassignedOrganization
+func (s *UsageServiceImpl) RecordAPICall(ctx context.Context) error {
+	organization := domain.OrganizationOf(domain.PrincipalFromContext(ctx))
+	return s.usageRepo.AddAPICalls(ctx, domain.UsageMonth(time.Now()), organization, 1)
+}
+
+// GetUsage devuelve el uso de cada organización en el mes indicado o en el mes en curso
+func (s *UsageServiceImpl) GetUsage(ctx context.Context, month string) ([]*domain.OrganizationUsage, error) {
+	if month == "" {
+		month = domain.UsageMonth(time.Now())
+	}
+	month, err := domain.ParseUsageMonth(month)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.usageRepo.GetUsage(ctx, month)
+}
+
+// MeteringTankService decora un TankService contando las mediciones guardadas de cada tanque en
+// la organización indicada por su etiqueta de organización
+type MeteringTankService struct {
+	ports.TankService
+	usageRepo         ports.UsageRepository
+	organizationLabel string
+}
+
+// NewMeteringTankService crea un TankService que mide las mediciones guardadas por organización
+func NewMeteringTankService(inner ports.TankService, usageRepo ports.UsageRepository, organizationLabel string) ports.TankService {
+	return &MeteringTankService{
+		TankService:       inner,
+		usageRepo:         usageRepo,
+		organizationLabel: organizationLabel,
+	}
+}
+
+// AddMeasurement guarda la medición y la suma al uso de la organización del tanque
+func (s *MeteringTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	// Una medición atrasada se guarda en el historial y cuenta; una repetida no
+	err := s.TankService.AddMeasurement(ctx, measurement)
+	if err != nil && !errors.Is(err, domain.ErrLateMeasurement) {
+		return err
+	}
+
+	if meterErr := s.meter(ctx, []*domain.Measurement{measurement}); meterErr != nil {
+		return meterErr
+	}
+	return err
+}
+
+// AddMeasurements guarda el lote y suma sus mediciones al uso de la organización de cada tanque
+func (s *MeteringTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	if err := s.TankService.AddMeasurements(ctx, measurements); err != nil {
+		return err
+	}
+
+	return s.meter(ctx, measurements)
+}
+
+// meter suma las mediciones guardadas al mes en curso, agrupadas por tanque
+func (s *MeteringTankService) meter(ctx context.Context, measurements []*domain.Measurement) error {
+	countByTank := make(map[string]int64)
+	tankIDs := make([]string, 0)
+	for _, measurement := range measurements {
+		if countByTank[measurement.TankID] == 0 {
+			tankIDs = append(tankIDs, measurement.TankID)
+		}
+		countByTank[measurement.TankID]++
+	}
+
+	month := domain.UsageMonth(time.Now())
+	var errs []error
+	for _, tankID := range tankIDs {
+		tank, err := s.TankService.GetTank(ctx, tankID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tank %s: %w", tankID, err))
+			continue
+		}
+		organization := domain.TankOrganization(tank, s.organizationLabel)
+		if err := s.usageRepo.AddMeasurements(ctx, month, organization, tankID, countByTank[tankID]); err != nil {
+			errs = append(errs, fmt.Errorf("tank %s: %w", tankID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
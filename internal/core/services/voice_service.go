@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// ErrInvalidVoiceQuery se devuelve cuando la pregunta no trae intención, tanque ni frase, o
+// trae una intención desconocida
+var ErrInvalidVoiceQuery = errors.New("invalid voice query")
+
+// VoiceServiceImpl implementa la interfaz VoiceService respondiendo sobre los tanques accesibles
+// para el usuario
+type VoiceServiceImpl struct {
+	tankService ports.TankService
+}
+
+// NewVoiceService crea una nueva instancia del servicio de asistentes de voz
+func NewVoiceService(tankService ports.TankService) ports.VoiceService {
+	return &VoiceServiceImpl{
+		tankService: tankService,
+	}
+}
+
+// Answer responde la pregunta con una frase corta para leer en voz alta. Si la pregunta es sobre
+// un tanque que no se encuentra o que coincide con varios, la respuesta pide aclararlo y deja la
+// conversación abierta.
+func (s *VoiceServiceImpl) Answer(ctx context.Context, query *domain.VoiceQuery) (*domain.VoiceAnswer, error) {
+	if query.Intent != "" && !domain.IsValidVoiceIntent(query.Intent) {
+		return nil, fmt.Errorf("%w: unknown intent %q", ErrInvalidVoiceQuery, query.Intent)
+	}
+	if query.Intent == "" && strings.TrimSpace(query.Tank) == "" && strings.TrimSpace(query.Text) == "" {
+		return nil, fmt.Errorf("%w: intent, tank or text is required", ErrInvalidVoiceQuery)
+	}
+
+	tanks, err := s.tankService.GetAllTanks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	phrase := query.Tank
+	if strings.TrimSpace(phrase) == "" {
+		phrase = query.Text
+	}
+	matches := matchVoiceTanks(phrase, tanks)
+
+	intent := query.Intent
+	if intent == "" {
+		intent = domain.DetectVoiceIntent(query.Text, len(matches) > 0 || strings.TrimSpace(query.Tank) != "")
+	}
+
+	switch intent {
+	case domain.VoiceIntentLowTanks:
+		return lowTanksAnswer(tanks), nil
+	case domain.VoiceIntentSummary:
+		return summaryAnswer(tanks), nil
+	}
+
+	switch {
+	case strings.TrimSpace(phrase) == "":
+		return voiceClarification(intent, "¿De qué tanque quieres saber?"), nil
+	case len(matches) == 0:
+		return voiceClarification(intent, "No encontré ningún tanque que coincida con %s.", strings.TrimSpace(phrase)), nil
+	case len(matches) > 1:
+		answer := voiceClarification(intent, "Hay varios tanques que coinciden: %s. ¿Cuál quieres consultar?", voiceTankNames(matches))
+		answer.TankIDs = voiceTankIDs(matches)
+		return answer, nil
+	}
+
+	tank := matches[0]
+	var answer *domain.VoiceAnswer
+	if intent == domain.VoiceIntentTankLevel {
+		answer = domain.NewVoiceAnswer(intent, "%s está al %.0f por ciento: %.0f de %.0f litros.",
+			tank.Name, tank.GetLevelPercentage(), tank.CurrentLevel, tank.Capacity)
+	} else {
+		answer = domain.NewVoiceAnswer(intent, statusSpeech(tank.Status), tank.Name, tank.GetLevelPercentage())
+	}
+	answer.TankIDs = []string{tank.ID}
+	return answer, nil
+}
+
+// matchVoiceTanks devuelve los tanques que mejor coinciden con lo dicho; más de uno si empatan
+func matchVoiceTanks(phrase string, tanks []*domain.Tank) []*domain.Tank {
+	var matches []*domain.Tank
+	best := 0.0
+	for _, tank := range tanks {
+		score := domain.VoiceTankScore(phrase, tank)
+		switch {
+		case score <= 0 || score < best:
+		case score > best:
+			best, matches = score, []*domain.Tank{tank}
+		default:
+			matches = append(matches, tank)
+		}
+	}
+	return matches
+}
+
+// statusSpeech devuelve la frase del estado del tanque, con su nombre y su porcentaje
+func statusSpeech(status string) string {
+	switch status {
+	case "critical":
+		return "%s está en estado crítico: queda al %.0f por ciento."
+	case "warning":
+		return "%s está en advertencia: queda al %.0f por ciento."
+	default:
+		return "%s está en estado normal, al %.0f por ciento."
+	}
+}
+
+// lowTanksAnswer enumera los tanques en advertencia o en estado crítico, del más vacío al más lleno
+func lowTanksAnswer(tanks []*domain.Tank) *domain.VoiceAnswer {
+	var low []*domain.Tank
+	for _, tank := range tanks {
+		if tank.Status == "warning" || tank.Status == "critical" {
+			low = append(low, tank)
+		}
+	}
+	sort.SliceStable(low, func(i, j int) bool {
+		return low[i].GetLevelPercentage() < low[j].GetLevelPercentage()
+	})
+
+	var answer *domain.VoiceAnswer
+	switch len(low) {
+	case 0:
+		answer = domain.NewVoiceAnswer(domain.VoiceIntentLowTanks, "Ningún tanque tiene el nivel bajo.")
+	case 1:
+		answer = domain.NewVoiceAnswer(domain.VoiceIntentLowTanks, "Un tanque tiene el nivel bajo: %s, al %.0f por ciento.",
+			low[0].Name, low[0].GetLevelPercentage())
+	default:
+		answer = domain.NewVoiceAnswer(domain.VoiceIntentLowTanks, "%d tanques tienen el nivel bajo: %s.", len(low), voiceTankNames(low))
+	}
+	answer.TankIDs = voiceTankIDs(low)
+	return answer
+}
+
+// summaryAnswer resume cuántos tanques hay en cada estado
+func summaryAnswer(tanks []*domain.Tank) *domain.VoiceAnswer {
+	if len(tanks) == 0 {
+		return domain.NewVoiceAnswer(domain.VoiceIntentSummary, "No tienes tanques registrados.")
+	}
+
+	normal, warning, critical := 0, 0, 0
+	for _, tank := range tanks {
+		switch tank.Status {
+		case "critical":
+			critical++
+		case "warning":
+			warning++
+		default:
+			normal++
+		}
+	}
+	return domain.NewVoiceAnswer(domain.VoiceIntentSummary,
+		"Tienes %d tanques: %d en estado normal, %d en advertencia y %d en estado crítico.",
+		len(tanks), normal, warning, critical)
+}
+
+// voiceClarification crea una respuesta que pide al usuario que repita o precise la pregunta
+func voiceClarification(intent, format string, args ...interface{}) *domain.VoiceAnswer {
+	answer := domain.NewVoiceAnswer(intent, format, args...)
+	answer.EndSession = false
+	return answer
+}
+
+// voiceTankNames une los nombres de los tanques para leerlos en voz alta
+func voiceTankNames(tanks []*domain.Tank) string {
+	names := make([]string, len(tanks))
+	for i, tank := range tanks {
+		names[i] = tank.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// voiceTankIDs devuelve los IDs de los tanques
+func voiceTankIDs(tanks []*domain.Tank) []string {
+	ids := make([]string, len(tanks))
+	for i, tank := range tanks {
+		ids[i] = tank.ID
+	}
+	return ids
+}
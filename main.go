@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"os"
+
 	"monitor-tanques/cmd/api"
+	"monitor-tanques/pkg/cli"
 	"monitor-tanques/pkg/logger"
 )
 
@@ -9,17 +14,21 @@ func main() {
 	// Inicializamos el logger
 	log := logger.NewSimpleLogger()
 
-	// Configuramos la API
-	config := api.DefaultConfig()
-
-	// Creamos la instancia de la API
-	app := api.NewAPI(config, log)
+	// Configuramos la API a partir de las variables de entorno
+	config := api.ConfigFromEnv()
 
-	// Configuramos las rutas
-	app.SetupRoutes()
+	// Sin comando se arranca el servidor; los comandos de administración trabajan sobre el
+	// almacenamiento configurado
+	app := &cli.App{
+		Name:     "monitor-tanques",
+		Default:  api.ServeCommand(config, log),
+		Commands: api.AdminCommands(config, log),
+	}
 
-	// Iniciamos el servidor
-	if err := app.Start(); err != nil {
-		log.Fatal("Error al iniciar la aplicación", "error", err)
+	if err := app.Run(context.Background(), os.Args[1:]); err != nil {
+		if errors.Is(err, cli.ErrUsage) {
+			os.Exit(2)
+		}
+		log.Fatal("Error al ejecutar el comando", "error", err)
 	}
 }
@@ -0,0 +1,262 @@
+package i18n
+
+// english traduce al inglés los textos de la API y de las notificaciones
+var english = map[string]string{
+	// Errores de la API
+	"Actualizado":                                                 "Updated",
+	"Archivo de aprovisionamiento inválido":                       "Invalid provisioning file",
+	"Archivo de tanques inválido":                                 "Invalid tanks file",
+	"Contenido de la carga inválido":                              "Invalid payload content",
+	"Contenido del webhook inválido":                              "Invalid webhook payload",
+	"Código de autorización ausente":                              "Missing authorization code",
+	"El archivo supera el tamaño máximo permitido":                "The file exceeds the maximum allowed size",
+	"El cuerpo de la solicitud supera el tamaño máximo permitido": "The request body exceeds the maximum allowed size",
+	"El parámetro step debe ser una duración, p. ej. 15m":         "The step parameter must be a duration, e.g. 15m",
+	"El parámetro limit debe ser un entero positivo":              "The limit parameter must be a positive integer",
+	"Equipo Sigfox desconocido":                                   "Unknown Sigfox device",
+	"Error al actualizar el canal de notificación":                "Error updating the notification channel",
+	"Error al actualizar el proveedor":                            "Error updating the supplier",
+	"Error al actualizar el tanque":                               "Error updating the tank",
+	"Error al actualizar la configuración del equipo":             "Error updating the device configuration",
+	"Error al actualizar la ubicación del dispositivo":            "Error updating the device location",
+	"Error al aplicar el aprovisionamiento":                       "Error applying the provisioning",
+	"Error al añadir la medición":                                 "Error adding the measurement",
+	"Error al codificar la respuesta":                             "Error encoding the response",
+	"Error al conciliar el pedido":                                "Error reconciling the order",
+	"Error al crear el canal de notificación":                     "Error creating the notification channel",
+	"Error al crear la página de estado":                          "Error creating the status page",
+	"Error al crear el proveedor":                                 "Error creating the supplier",
+	"Error al crear el tanque":                                    "Error creating the tank",
+	"Error al crear la concesión de acceso":                       "Error creating the access grant",
+	"Error al decodificar la solicitud":                           "Error decoding the request",
+	"Error al eliminar el adjunto":                                "Error deleting the attachment",
+	"Error al eliminar el canal de notificación":                  "Error deleting the notification channel",
+	"Error al eliminar el dispositivo":                            "Error deleting the device",
+	"Error al eliminar el proveedor":                              "Error deleting the supplier",
+	"Error al eliminar el tanque":                                 "Error deleting the tank",
+	"Error al eliminar la concesión de acceso":                    "Error deleting the access grant",
+	"Error al eliminar la política del líquido":                   "Error deleting the liquid policy",
+	"Error al enviar el comando al equipo":                        "Error sending the command to the device",
+	"Error al firmar el enlace":                                   "Error signing the link",
+	"Error al guardar la lectura de Sigfox":                       "Error saving the Sigfox reading",
+	"Error al cambiar el nivel de logging":                        "Error changing the log level",
+	"Error al recargar la configuración":                          "Error reloading the configuration",
+	"Error al guardar la política del líquido":                    "Error saving the liquid policy",
+	"Error al guardar las lecturas del equipo":                    "Error saving the device readings",
+	"Error al guardar las lecturas del webhook":                   "Error saving the webhook readings",
+	"Error al iniciar sesión":                                     "Error signing in",
+	"Error al importar el historial de mediciones":                "Error importing the measurement history",
+	"Error al importar los tanques":                               "Error importing the tanks",
+	"Error al obtener el adjunto":                                 "Error getting the attachment",
+	"Error al obtener el canal de notificación":                   "Error getting the notification channel",
+	"Error al obtener el equipo de campo":                         "Error getting the field device",
+	"Error al obtener el informe de calidad de datos":             "Error getting the data quality report",
+	"Error al obtener el informe de relevo de turno":              "Error getting the shift handover report",
+	"Error al obtener el historial de estados":                    "Error getting the status history",
+	"Error al obtener el pedido":                                  "Error getting the order",
+	"Error al obtener el plan de capacidad del grupo":             "Error getting the group capacity plan",
+	"Error al obtener el proveedor":                               "Error getting the supplier",
+	"Error al obtener el tanque":                                  "Error getting the tank",
+	"Error al obtener el uso por organización":                    "Error getting the usage per organization",
+	"Error al obtener la configuración del equipo":                "Error getting the device configuration",
+	"Error al obtener la línea de tiempo":                         "Error getting the timeline",
+	"Error al obtener la serie de mediciones":                     "Error getting the measurement series",
+	"Error al obtener la evidencia de la alerta":                  "Error getting the alert evidence",
+	"Error al obtener la configuración":                           "Error getting the configuration",
+	"Error al obtener la política del líquido":                    "Error getting the liquid policy",
+	"Error al obtener la salud de los sensores":                   "Error getting the sensor health",
+	"Error al iniciar la purga":                                   "Error starting the purge",
+	"Error al obtener las purgas":                                 "Error getting the purges",
+	"Error al obtener la purga":                                   "Error getting the purge",
+	"Error al emitir el token":                                    "Error issuing the token",
+	"Error al obtener los tokens":                                 "Error getting the tokens",
+	"Error al revocar el token":                                   "Error revoking the token",
+	"Error al obtener las sesiones":                               "Error getting the sessions",
+	"Error al revocar la sesión":                                  "Error revoking the session",
+	"Error al revocar las sesiones":                               "Error revoking the sessions",
+	"Error al cerrar las sesiones":                                "Error logging out the sessions",
+	"El parámetro active debe ser true o false":                   "The active parameter must be true or false",
+	"Error al obtener los eventos de seguridad":                   "Error getting the security events",
+	"Error al obtener el reloj de los sensores":                   "Error getting the sensor clocks",
+	"Parámetro drifting inválido":                                 "Invalid drifting parameter",
+	"Error al obtener las alertas en cola":                        "Error getting the queued alerts",
+	"Error al componer el resumen diario":                         "Error building the daily digest",
+	"Error al enviar el resumen diario":                           "Error sending the daily digest",
+	"Error al probar la regla de alerta":                          "Error testing the alert rule",
+	"Error al generar la serie sintética":                         "Error generating the synthetic series",
+	"Error al obtener las estadísticas de la persistencia":        "Error getting the repository statistics",
+	"Error al obtener las anomalías":                              "Error getting the anomalies",
+	"Error al obtener las concesiones de acceso":                  "Error getting the access grants",
+	"Error al obtener las mediciones":                             "Error getting the measurements",
+	"Error al obtener las notas":                                  "Error getting the notes",
+	"Error al obtener las políticas de líquidos":                  "Error getting the liquid policies",
+	"Error al obtener las páginas de estado":                      "Error getting the status pages",
+	"Error al obtener las sincronizaciones con el ERP":            "Error getting the ERP syncs",
+	"Error al obtener las sugerencias de pedido":                  "Error getting the order suggestions",
+	"Error al obtener los adjuntos":                               "Error getting the attachments",
+	"Error al obtener los canales de notificación":                "Error getting the notification channels",
+	"Error al obtener los comandos del equipo":                    "Error getting the device commands",
+	"Error al obtener los dispositivos":                           "Error getting the devices",
+	"Error al obtener los equipos de campo":                       "Error getting the field devices",
+	"Error al obtener los indicadores del tanque":                 "Error getting the tank KPIs",
+	"Error al obtener los pedidos":                                "Error getting the orders",
+	"Error al obtener los proveedores":                            "Error getting the suppliers",
+	"Error al obtener los silencios de alertas":                   "Error getting the alert mutes",
+	"Error al obtener los tanques":                                "Error getting the tanks",
+	"Error al obtener una lectura inmediata del tanque":           "Error getting an immediate tank reading",
+	"Error al programar el pedido":                                "Error scheduling the order",
+	"Error al recalcular los datos derivados":                     "Error recomputing the derived data",
+	"Error al reactivar las alertas del tanque":                   "Error unmuting the tank alerts",
+	"Error al obtener los compartimentos del tanque":              "Error getting the tank compartments",
+	"Error al obtener el estado de la sincronización":             "Error getting the sync status",
+	"Error al guardar el lote de sincronización":                  "Error saving the sync batch",
+	"Cursor de sincronización inválido":                           "Invalid sync cursor",
+	"La expresión del parámetro filter no es válida":              "The filter parameter expression is invalid",
+	"Error al obtener las vistas guardadas":                       "Error getting saved views",
+	"Error al obtener la vista guardada":                          "Error getting the saved view",
+	"Error al guardar la vista":                                   "Error saving the view",
+	"Error al actualizar la vista guardada":                       "Error updating the saved view",
+	"Error al eliminar la vista guardada":                         "Error deleting the saved view",
+	"Error al obtener los cambios de sincronización":              "Error getting sync changes",
+	"Error al exportar las mediciones al data lake":               "Error exporting measurements to the data lake",
+	"Error al obtener el estado de la exportación al data lake":   "Error getting the data lake export status",
+	"Error al obtener el resumen de los tanques":                  "Error getting the tanks overview",
+	"Error al obtener los cambios pendientes":                     "Error getting pending changes",
+	"Error al obtener la solicitud de cambio":                     "Error getting change request",
+	"Error al aprobar la solicitud de cambio":                     "Error approving change request",
+	"Error al rechazar la solicitud de cambio":                    "Error rejecting change request",
+	"Error al registrar el dispositivo":                           "Error registering the device",
+	"Error al registrar el pedido":                                "Error registering the order",
+	"Error al realizar la búsqueda":                               "Error performing the search",
+	"Error al responder la pregunta":                              "Error answering the question",
+	"Error al registrar la configuración aplicada":                "Error recording the applied configuration",
+	"Error al registrar la nota":                                  "Error recording the note",
+	"Error al registrar la recepción del pedido":                  "Error recording the order receipt",
+	"Error al revocar la página de estado":                        "Error revoking the status page",
+	"Error al silenciar las alertas del tanque":                   "Error muting the tank alerts",
+	"Error al sincronizar el inventario con el ERP":               "Error syncing the inventory with the ERP",
+	"Error al subir el adjunto":                                   "Error uploading the attachment",
+	"Error al validar el inicio de sesión":                        "Error validating the sign-in",
+	"Falta el archivo en el campo file":                           "The file field is missing",
+	"Formato de archivo no soportado, use CSV o XLSX":             "Unsupported file format, use CSV or XLSX",
+	"Página de estado no encontrada":                              "Status page not found",
+	"Parámetro bbox inválido, use minLon,minLat,maxLon,maxLat":    "Invalid bbox parameter, use minLon,minLat,maxLon,maxLat",
+	"Parámetro format inválido, use json o text":                  "Invalid format parameter, use json or text",
+	"Parámetro days inválido":                                     "Invalid days parameter",
+	"Parámetro duration inválido":                                 "Invalid duration parameter",
+	"Parámetro interval inválido":                                 "Invalid interval parameter",
+	"Parámetro dry_run inválido":                                  "Invalid dry_run parameter",
+	"Parámetro from inválido":                                     "Invalid from parameter",
+	"Parámetro in_sync inválido":                                  "Invalid in_sync parameter",
+	"Parámetro labels inválido":                                   "Invalid labels parameter",
+	"Parámetro state inválido":                                    "Invalid state parameter",
+	"Parámetro ttl inválido":                                      "Invalid ttl parameter",
+	"Parámetro topics inválido":                                   "Invalid topics parameter",
+	"El parámetro period debe ser una duración, p. ej. 30d":       "The period parameter must be a duration, e.g. 30d",
+	"Error al obtener las estadísticas del tanque":                "Error getting tank statistics",
+	"El parámetro lead_time debe ser una duración, p. ej. 48h":    "The lead_time parameter must be a duration, e.g. 48h",
+	"Error al recomendar los umbrales del tanque":                 "Error recommending tank thresholds",
+	"Parámetros from/to inválidos":                                "Invalid from/to parameters",
+	"Parámetros limit u offset inválidos":                         "Invalid limit or offset parameters",
+	"Perfil de equipo desconocido":                                "Unknown device profile",
+	"Plataforma de webhook desconocida":                           "Unknown webhook platform",
+	"Proveedor de identidad no disponible":                        "Identity provider unavailable",
+	"Se esperaba una conexión WebSocket":                          "A WebSocket connection was expected",
+	"Sin lecturas":                                                "No readings",
+	"Tanque no encontrado":                                        "Tank not found",
+	"Trama de Sigfox inválida":                                    "Invalid Sigfox frame",
+	"Token de webhook inválido":                                   "Invalid webhook token",
+	"Autenticación requerida":                                     "Authentication required",
+	"Token inválido":                                              "Invalid token",
+	"Permisos insuficientes":                                      "Insufficient permissions",
+	"Enlace firmado inválido o vencido":                           "Invalid or expired signed link",
+	"Ruta no permitida con certificado de cliente":                "Route not allowed with a client certificate",
+	"Equipo no registrado":                                        "Unregistered device",
+	"Demasiados intentos fallidos; inténtelo más tarde":           "Too many failed attempts; try again later",
+	"Token de refresco inválido o vencido":                        "Invalid or expired refresh token",
+	"El token no tiene el alcance necesario":                      "The token does not have the required scope",
+	"Verificación CAPTCHA requerida":                              "CAPTCHA verification required",
+	"Verificación CAPTCHA inválida":                               "Invalid CAPTCHA verification",
+	"Error al verificar el CAPTCHA":                               "Error verifying the CAPTCHA",
+	"Versión de API no soportada":                                 "Unsupported API version",
+	"Versión de carga no admitida por el perfil":                  "Payload version not supported by the profile",
+
+	// Nombre predeterminado de un tanque clonado: "<nombre> (copia)"
+	"copia": "copy",
+
+	// Informe de importación de tanques
+	"El nombre es obligatorio":                             "The name is required",
+	"La capacidad debe ser mayor que cero":                 "The capacity must be greater than zero",
+	"El nivel actual debe estar entre cero y la capacidad": "The current level must be between zero and the capacity",
+	"El umbral de alerta debe estar entre 0 y 100":         "The alert threshold must be between 0 and 100",
+	"La ubicación no es válida":                            "The location is not valid",
+	"La zona horaria no existe":                            "The time zone does not exist",
+	"Indique la latitud y la longitud":                     "Provide both latitude and longitude",
+	"El valor no es un número":                             "The value is not a number",
+	"El ID está repetido en el archivo":                    "The ID is repeated in the file",
+	"Ya existe un tanque con ese ID":                       "A tank with that ID already exists",
+	"Sin acceso al sitio o grupo del tanque":               "No access to the tank's site or group",
+	"El tanque no es válido":                               "The tank is not valid",
+
+	// Lecturas recibidas por webhook
+	"Falta el ID del tanque":           "The tank ID is missing",
+	"Falta el nivel o no es un número": "The level is missing or is not a number",
+	"La marca de tiempo no es válida":  "The timestamp is not valid",
+	"El tanque no existe":              "The tank does not exist",
+	"Sin acceso al tanque":             "No access to the tank",
+	"No se pudo guardar la medición":   "The measurement could not be saved",
+
+	// Notificaciones
+	"Alerta de tanque": "Tank alert",
+	"CRÍTICO":          "CRITICAL",
+	"¡Alerta! El tanque %s está en nivel crítico (nivel: %.2f%%). Se requiere atención inmediata.":                            "Alert! Tank %s is at a critical level (level: %.2f%%). Immediate attention required.",
+	"Anomalía: el sensor del tanque %s repite la misma lectura desde hace %.0f mediciones. Es posible que esté congelado.":    "Anomaly: the sensor of tank %s has repeated the same reading for %.0f measurements. It may be frozen.",
+	"Anomalía: caída de nivel inusual en el tanque %s (%.2f L, z=%.1f). Revise posibles fugas o extracciones no registradas.": "Anomaly: unusual level drop in tank %s (%.2f L, z=%.1f). Check for leaks or unrecorded withdrawals.",
+	"Anomalía: temperatura inusual en el tanque %s (%.2f °C, media reciente %.2f °C).":                                        "Anomaly: unusual temperature in tank %s (%.2f °C, recent average %.2f °C).",
+	"Batería baja en el sensor %s del tanque %s (%.2f V). Programe su reemplazo.":                                             "Low battery on sensor %s of tank %s (%.2f V). Schedule its replacement.",
+	"Señal débil en el sensor %s del tanque %s (%.0f dBm). Revise la antena o la cobertura.":                                  "Weak signal on sensor %s of tank %s (%.0f dBm). Check the antenna or coverage.",
+
+	// Alarmas de las consolas ATG; los argumentos son la consola y el nombre del tanque
+	"La consola %s reporta datos de configuración incorrectos en el tanque %s.":   "Console %s reports invalid setup data for tank %s.",
+	"La consola %s reporta una fuga en el tanque %s.":                             "Console %s reports a leak in tank %s.",
+	"La consola %s reporta agua alta en el tanque %s.":                            "Console %s reports high water in tank %s.",
+	"La consola %s reporta sobrellenado en el tanque %s.":                         "Console %s reports an overfill in tank %s.",
+	"La consola %s reporta producto bajo en el tanque %s.":                        "Console %s reports low product in tank %s.",
+	"La consola %s reporta una pérdida repentina en el tanque %s.":                "Console %s reports a sudden loss in tank %s.",
+	"La consola %s reporta producto alto en el tanque %s.":                        "Console %s reports high product in tank %s.",
+	"La consola %s reporta un nivel de combustible inválido en el tanque %s.":     "Console %s reports an invalid fuel level in tank %s.",
+	"La consola %s no recibe datos de la sonda del tanque %s.":                    "Console %s receives no data from the probe of tank %s.",
+	"La consola %s advierte de agua alta en el tanque %s.":                        "Console %s warns of high water in tank %s.",
+	"La consola %s indica que el tanque %s necesita una entrega.":                 "Console %s indicates that tank %s needs a delivery.",
+	"La consola %s reporta el producto máximo en el tanque %s.":                   "Console %s reports maximum product in tank %s.",
+	"El tanque %[2]s no superó la prueba de fuga gruesa de la consola %[1]s.":     "Tank %[2]s failed the gross leak test of console %[1]s.",
+	"El tanque %[2]s no superó la prueba de fugas periódica de la consola %[1]s.": "Tank %[2]s failed the periodic leak test of console %[1]s.",
+	"El tanque %[2]s no superó la prueba de fugas anual de la consola %[1]s.":     "Tank %[2]s failed the annual leak test of console %[1]s.",
+	"La consola %s reporta la alarma %d en el tanque %s.":                         "Console %s reports alarm %d in tank %s.",
+	"Configuración del tanque %s cambiada por %s: %s.":                            "Configuration of tank %s changed by %s: %s.",
+	"Configuración del tanque %s cambiada: %s.":                                   "Configuration of tank %s changed: %s.",
+
+	// Respuestas de los asistentes de voz
+	"¿De qué tanque quieres saber?":                                                     "Which tank do you want to know about?",
+	"No encontré ningún tanque que coincida con %s.":                                    "I couldn't find a tank matching %s.",
+	"Hay varios tanques que coinciden: %s. ¿Cuál quieres consultar?":                    "Several tanks match: %s. Which one do you mean?",
+	"%s está al %.0f por ciento: %.0f de %.0f litros.":                                  "%s is at %.0f percent: %.0f of %.0f liters.",
+	"%s está en estado crítico: queda al %.0f por ciento.":                              "%s is critical: it is down to %.0f percent.",
+	"%s está en advertencia: queda al %.0f por ciento.":                                 "%s is in warning: it is down to %.0f percent.",
+	"%s está en estado normal, al %.0f por ciento.":                                     "%s is normal, at %.0f percent.",
+	"Ningún tanque tiene el nivel bajo.":                                                "No tank is running low.",
+	"Un tanque tiene el nivel bajo: %s, al %.0f por ciento.":                            "One tank is running low: %s, at %.0f percent.",
+	"%d tanques tienen el nivel bajo: %s.":                                              "%d tanks are running low: %s.",
+	"No tienes tanques registrados.":                                                    "You have no tanks registered.",
+	"Tienes %d tanques: %d en estado normal, %d en advertencia y %d en estado crítico.": "You have %d tanks: %d normal, %d in warning and %d critical.",
+
+	// Resumen diario de los canales
+	"Resumen diario de los tanques del %s":  "Daily tank digest for %s",
+	"Tanques: %d. Con alertas activas: %d.": "Tanks: %d. With active alerts: %d.",
+	"%.0f%% (%.0f de %.0f L)":               "%.0f%% (%.0f of %.0f L)",
+	"%+.0f L en 24 h":                       "%+.0f L in 24 h",
+	"se vaciará en %.1f días":               "empty in %.1f days",
+	"crítico":                               "critical",
+	"aviso":                                 "warning",
+	"Alertas desde el resumen anterior: %d": "Alerts since the previous digest: %d",
+}
@@ -0,0 +1,94 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage es el idioma de los textos originales y el que se usa cuando el cliente no
+// acepta ninguno de los disponibles
+const DefaultLanguage = "es"
+
+// catalogs contiene las traducciones de cada idioma. Como en gettext, la clave es el texto
+// original en español, de modo que un texto sin traducir se muestra en el idioma original.
+var catalogs = map[string]map[string]string{
+	"en": english,
+}
+
+// Languages devuelve los idiomas disponibles, empezando por el predeterminado
+func Languages() []string {
+	languages := []string{DefaultLanguage}
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages[1:])
+	return languages
+}
+
+// IsSupported indica si hay mensajes en el idioma indicado
+func IsSupported(language string) bool {
+	_, ok := catalogs[language]
+	return ok || language == DefaultLanguage
+}
+
+// Translate devuelve el mensaje en el idioma indicado, o el original si no hay traducción
+func Translate(language, message string) string {
+	if translated, ok := catalogs[language][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Sprintf traduce el formato y lo aplica a los argumentos
+func Sprintf(language, format string, args ...interface{}) string {
+	return fmt.Sprintf(Translate(language, format), args...)
+}
+
+// Negotiate elige el idioma disponible preferido según la cabecera Accept-Language. Las
+// variantes regionales (es-CO, en-US) se atienden con su idioma base.
+func Negotiate(acceptLanguage string) string {
+	best := DefaultLanguage
+	bestQuality := 0.0
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if base == "*" {
+			base = DefaultLanguage
+		}
+		if IsSupported(base) && quality > bestQuality {
+			best = base
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// languageKey es la clave del idioma de la solicitud en el contexto
+type languageKey struct{}
+
+// WithLanguage devuelve un contexto con el idioma negociado para la solicitud
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// FromContext devuelve el idioma anotado en el contexto, o el predeterminado si no hay ninguno
+func FromContext(ctx context.Context) string {
+	if language, ok := ctx.Value(languageKey{}).(string); ok && language != "" {
+		return language
+	}
+	return DefaultLanguage
+}
@@ -1,10 +1,45 @@
 package logger
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
+	"sync/atomic"
 )
 
+// Level es el nivel mínimo de los mensajes que se registran
+type Level int32
+
+// Niveles de logging, de menor a mayor gravedad
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// ParseLevel interpreta un nivel por su nombre: debug, info, warn o error
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelDebug, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// LevelSetter es un logger cuyo nivel mínimo puede cambiarse en caliente
+type LevelSetter interface {
+	SetLevel(level Level)
+}
+
 // Logger define la interfaz para el logging en la aplicación
 type Logger interface {
 	Debug(msg string, keysAndValues ...interface{})
@@ -21,9 +56,10 @@ type SimpleLogger struct {
 	warnLog  *log.Logger
 	errorLog *log.Logger
 	fatalLog *log.Logger
+	level    atomic.Int32
 }
 
-// NewSimpleLogger crea una nueva instancia del logger simple
+// NewSimpleLogger crea una nueva instancia del logger simple, que registra todos los niveles
 func NewSimpleLogger() *SimpleLogger {
 	return &SimpleLogger{
 		debugLog: log.New(os.Stdout, "DEBUG: ", log.Ldate|log.Ltime|log.Lshortfile),
@@ -34,6 +70,16 @@ func NewSimpleLogger() *SimpleLogger {
 	}
 }
 
+// SetLevel cambia el nivel mínimo de los mensajes registrados; los fatales se registran siempre
+func (l *SimpleLogger) SetLevel(level Level) {
+	l.level.Store(int32(level))
+}
+
+// enabled indica si los mensajes del nivel indicado se registran
+func (l *SimpleLogger) enabled(level Level) bool {
+	return Level(l.level.Load()) <= level
+}
+
 // formatKeyValues formatea los pares clave-valor para el logging
 func formatKeyValues(keysAndValues ...interface{}) string {
 	if len(keysAndValues) == 0 {
@@ -63,24 +109,36 @@ func formatKeyValues(keysAndValues ...interface{}) string {
 
 // Debug registra un mensaje de nivel debug
 func (l *SimpleLogger) Debug(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(LevelDebug) {
+		return
+	}
 	format := msg + formatKeyValues(keysAndValues...)
 	l.debugLog.Printf(format, keysAndValues...)
 }
 
 // Info registra un mensaje de nivel info
 func (l *SimpleLogger) Info(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(LevelInfo) {
+		return
+	}
 	format := msg + formatKeyValues(keysAndValues...)
 	l.infoLog.Printf(format, keysAndValues...)
 }
 
 // Warn registra un mensaje de nivel warn
 func (l *SimpleLogger) Warn(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(LevelWarn) {
+		return
+	}
 	format := msg + formatKeyValues(keysAndValues...)
 	l.warnLog.Printf(format, keysAndValues...)
 }
 
 // Error registra un mensaje de nivel error
 func (l *SimpleLogger) Error(msg string, keysAndValues ...interface{}) {
+	if !l.enabled(LevelError) {
+		return
+	}
 	format := msg + formatKeyValues(keysAndValues...)
 	l.errorLog.Printf(format, keysAndValues...)
 }
@@ -0,0 +1,327 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets son los límites en segundos de los histogramas de latencia
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// collector es una métrica que sabe escribirse en el formato de texto de Prometheus
+type collector interface {
+	write(w io.Writer)
+}
+
+// Registry agrupa las métricas de la aplicación y las expone en el formato de texto de
+// Prometheus (versión 0.0.4), sin dependencias externas
+type Registry struct {
+	collectors []collector
+	mutex      sync.Mutex
+}
+
+// NewRegistry crea un registro vacío
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// NewCounterVec registra un contador con las etiquetas indicadas
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	counter := &CounterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]*counterValue),
+	}
+	r.register(counter)
+	return counter
+}
+
+// NewGaugeVec registra un indicador con las etiquetas indicadas
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	gauge := &GaugeVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]*counterValue),
+	}
+	r.register(gauge)
+	return gauge
+}
+
+// NewHistogramVec registra un histograma con los límites y las etiquetas indicados
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	histogram := &HistogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: sorted,
+		values:  make(map[string]*histogramValue),
+	}
+	r.register(histogram)
+	return histogram
+}
+
+func (r *Registry) register(c collector) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.collectors = append(r.collectors, c)
+}
+
+// WriteText escribe todas las métricas en el formato de texto de Prometheus
+func (r *Registry) WriteText(w io.Writer) {
+	r.mutex.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mutex.Unlock()
+
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// Handler devuelve el manejador HTTP de /metrics
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// CounterVec es un contador con etiquetas, p. ej. alertas entregadas por canal
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+	values map[string]*counterValue
+	mutex  sync.Mutex
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+// Inc suma uno al contador de las etiquetas indicadas
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add suma delta al contador de las etiquetas indicadas
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := labelKey(labelValues)
+	value, ok := c.values[key]
+	if !ok {
+		value = &counterValue{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = value
+	}
+	value.value += delta
+}
+
+// Value devuelve el valor actual del contador de las etiquetas indicadas
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if value, ok := c.values[labelKey(labelValues)]; ok {
+		return value.value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, escapeHelp(c.help), c.name)
+	for _, key := range sortedKeys(c.values) {
+		value := c.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, value.labelValues, "", ""), formatFloat(value.value))
+	}
+}
+
+// GaugeVec es un indicador con etiquetas que puede subir y bajar, p. ej. clientes conectados
+type GaugeVec struct {
+	name   string
+	help   string
+	labels []string
+	values map[string]*counterValue
+	mutex  sync.Mutex
+}
+
+// Add suma delta, que puede ser negativo, al indicador de las etiquetas indicadas
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	key := labelKey(labelValues)
+	value, ok := g.values[key]
+	if !ok {
+		value = &counterValue{labelValues: append([]string(nil), labelValues...)}
+		g.values[key] = value
+	}
+	value.value += delta
+}
+
+// Value devuelve el valor actual del indicador de las etiquetas indicadas
+func (g *GaugeVec) Value(labelValues ...string) float64 {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if value, ok := g.values[labelKey(labelValues)]; ok {
+		return value.value
+	}
+	return 0
+}
+
+func (g *GaugeVec) write(w io.Writer) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", g.name, escapeHelp(g.help), g.name)
+	for _, key := range sortedKeys(g.values) {
+		value := g.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", g.name, formatLabels(g.labels, value.labelValues, "", ""), formatFloat(value.value))
+	}
+}
+
+// HistogramVec es un histograma con etiquetas, p. ej. la latencia de entrega por canal
+type HistogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	values  map[string]*histogramValue
+	mutex   sync.Mutex
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64 // Observaciones menores o iguales que cada límite (no acumuladas)
+	count       uint64
+	sum         float64
+}
+
+// Observe registra una observación con las etiquetas indicadas
+func (h *HistogramVec) Observe(observation float64, labelValues ...string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	key := labelKey(labelValues)
+	value, ok := h.values[key]
+	if !ok {
+		value = &histogramValue{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.values[key] = value
+	}
+
+	for i, bound := range h.buckets {
+		if observation <= bound {
+			value.counts[i]++
+			break
+		}
+	}
+	value.count++
+	value.sum += observation
+}
+
+// Count devuelve el número de observaciones con las etiquetas indicadas
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if value, ok := h.values[labelKey(labelValues)]; ok {
+		return value.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, escapeHelp(h.help), h.name)
+	for _, key := range sortedKeys(h.values) {
+		value := h.values[key]
+
+		cumulative := uint64(0)
+		for i, bound := range h.buckets {
+			cumulative += value.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, value.labelValues, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, value.labelValues, "le", "+Inf"), value.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, value.labelValues, "", ""), formatFloat(value.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, value.labelValues, "", ""), value.count)
+	}
+}
+
+// labelKey identifica una combinación de valores de etiquetas
+func labelKey(labelValues []string) string {
+	return strings.Join(labelValues, "\x00")
+}
+
+// sortedKeys ordena las series para que la salida sea estable
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatLabels escribe {name="value",...}, con una etiqueta adicional (le) si extraName no está vacío
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	pairs := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs = append(pairs, name+`="`+escapeLabel(value)+`"`)
+	}
+	if extraName != "" {
+		pairs = append(pairs, extraName+`="`+extraValue+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+// formatFloat escribe el número como lo espera Prometheus
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,330 @@
+// Package msgpack codifica y decodifica MessagePack (https://msgpack.org) para los valores
+// genéricos que produce encoding/json: nil, bool, números, cadenas, listas y objetos. Es
+// suficiente para las respuestas de la API sin depender de bibliotecas externas.
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ErrTruncated indica que los datos terminan antes de completar un valor
+var ErrTruncated = errors.New("msgpack: truncated data")
+
+// Marshal codifica un valor genérico. Los objetos se escriben con las claves ordenadas para que
+// la salida sea estable.
+func Marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encode(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			encodeInt(buf, i)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("msgpack: invalid number %q", v)
+		}
+		encodeFloat(buf, f)
+	case float64:
+		encodeFloat(buf, v)
+	case int:
+		encodeInt(buf, int64(v))
+	case int64:
+		encodeInt(buf, v)
+	case string:
+		encodeString(buf, v)
+	case []interface{}:
+		writeHeader(buf, len(v), 0x90, 16, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		writeHeader(buf, len(keys), 0x80, 16, 0xde, 0xdf)
+		for _, key := range keys {
+			encodeString(buf, key)
+			if err := encode(buf, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", value)
+	}
+	return nil
+}
+
+// writeHeader escribe la longitud de una lista o un objeto en su forma más corta
+func writeHeader(buf *bytes.Buffer, n int, fixPrefix byte, fixLimit int, prefix16, prefix32 byte) {
+	switch {
+	case n < fixLimit:
+		buf.WriteByte(fixPrefix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(prefix16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(prefix32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func encodeString(buf *bytes.Buffer, s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+	buf.WriteString(s)
+}
+
+// encodeInt escribe el entero en el formato más corto que lo representa
+func encodeInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= 127:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= 0 && i <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(i))
+	case i >= 0 && i <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(i))
+	case i >= 0:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, uint64(i))
+	case i >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+func encodeFloat(buf *bytes.Buffer, f float64) {
+	buf.WriteByte(0xcb)
+	binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+}
+
+// Unmarshal decodifica un valor en su forma genérica: nil, bool, int64, float64, string,
+// []interface{} o map[string]interface{}. Los binarios se devuelven como cadenas.
+func Unmarshal(data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+	value, err := d.decode()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, errors.New("msgpack: trailing data")
+	}
+	return value, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, ErrTruncated
+	}
+	chunk := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return chunk, nil
+}
+
+// length lee una longitud de n bytes en big endian
+func (d *decoder) length(n int) (int, error) {
+	chunk, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	length := 0
+	for _, b := range chunk {
+		length = length<<8 | int(b)
+	}
+	return length, nil
+}
+
+func (d *decoder) decode() (interface{}, error) {
+	prefix, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	b := prefix[0]
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return d.decodeMap(int(b & 0x0f))
+	case b&0xf0 == 0x90:
+		return d.decodeArray(int(b & 0x0f))
+	case b&0xe0 == 0xa0:
+		return d.decodeString(int(b & 0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		return d.decodeSized(1, d.decodeString)
+	case 0xc5, 0xda:
+		return d.decodeSized(2, d.decodeString)
+	case 0xc6, 0xdb:
+		return d.decodeSized(4, d.decodeString)
+	case 0xca:
+		chunk, err := d.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(chunk))), nil
+	case 0xcb:
+		chunk, err := d.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(chunk)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		chunk, err := d.next(1 << (b - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		value := uint64(0)
+		for _, c := range chunk {
+			value = value<<8 | uint64(c)
+		}
+		return int64(value), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		chunk, err := d.next(size)
+		if err != nil {
+			return nil, err
+		}
+		value := uint64(0)
+		for _, c := range chunk {
+			value = value<<8 | uint64(c)
+		}
+		// Extiende el signo desde el tamaño original
+		shift := 64 - 8*size
+		return int64(value<<shift) >> shift, nil
+	case 0xdc:
+		return d.decodeSized(2, d.decodeArray)
+	case 0xdd:
+		return d.decodeSized(4, d.decodeArray)
+	case 0xde:
+		return d.decodeSized(2, d.decodeMap)
+	case 0xdf:
+		return d.decodeSized(4, d.decodeMap)
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported prefix 0x%02x", b)
+	}
+}
+
+// decodeSized lee una longitud de n bytes y decodifica el valor con ella
+func (d *decoder) decodeSized(n int, decode func(int) (interface{}, error)) (interface{}, error) {
+	length, err := d.length(n)
+	if err != nil {
+		return nil, err
+	}
+	return decode(length)
+}
+
+func (d *decoder) decodeString(n int) (interface{}, error) {
+	chunk, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(chunk), nil
+}
+
+func (d *decoder) decodeArray(n int) (interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, ErrTruncated
+	}
+	items := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		item, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (d *decoder) decodeMap(n int) (interface{}, error) {
+	if n > len(d.data)-d.pos {
+		return nil, ErrTruncated
+	}
+	object := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: unsupported map key %T", key)
+		}
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		object[name] = value
+	}
+	return object, nil
+}
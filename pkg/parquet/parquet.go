@@ -0,0 +1,344 @@
+// Package parquet escribe archivos Apache Parquet (https://parquet.apache.org) con un solo grupo
+// de filas, una página por columna, codificación PLAIN y compresión GZIP. Cubre las columnas
+// planas de las exportaciones (texto, marcas de tiempo y números, opcionales o no) sin depender
+// de bibliotecas externas; Athena, BigQuery, Spark o DuckDB los leen sin configuración.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// magic abre y cierra todo archivo Parquet
+const magic = "PAR1"
+
+// createdBy identifica al escritor en los metadatos del archivo
+const createdBy = "monitor-tanques parquet writer"
+
+// ErrColumnLength indica que las columnas no tienen el mismo número de filas
+var ErrColumnLength = errors.New("parquet: columns have different lengths")
+
+// Tipos físicos, tipos convertidos, repeticiones, codificaciones y compresiones de Parquet
+const (
+	typeInt64     = 2
+	typeDouble    = 5
+	typeByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecGzip = 2
+
+	pageTypeData = 0
+)
+
+// Column es una columna del archivo con los valores de todas las filas
+type Column struct {
+	name      string
+	physical  int32
+	converted int32 // -1 si no tiene tipo convertido
+	optional  bool
+	rows      int
+	present   []bool // Solo en las opcionales: si la fila tiene valor
+	plain     []byte // Valores presentes con codificación PLAIN
+}
+
+// String crea una columna de texto obligatoria
+func String(name string, values []string) Column {
+	column := Column{name: name, physical: typeByteArray, converted: convertedUTF8, rows: len(values)}
+	for _, value := range values {
+		column.plain = binary.LittleEndian.AppendUint32(column.plain, uint32(len(value)))
+		column.plain = append(column.plain, value...)
+	}
+	return column
+}
+
+// Timestamp crea una columna obligatoria de marcas de tiempo con precisión de milisegundos (UTC)
+func Timestamp(name string, values []time.Time) Column {
+	column := Column{name: name, physical: typeInt64, converted: convertedTimestampMillis, rows: len(values)}
+	for _, value := range values {
+		column.plain = binary.LittleEndian.AppendUint64(column.plain, uint64(value.UnixMilli()))
+	}
+	return column
+}
+
+// Double crea una columna numérica obligatoria
+func Double(name string, values []float64) Column {
+	column := Column{name: name, physical: typeDouble, converted: -1, rows: len(values)}
+	for _, value := range values {
+		column.plain = binary.LittleEndian.AppendUint64(column.plain, math.Float64bits(value))
+	}
+	return column
+}
+
+// OptionalDouble crea una columna numérica opcional; las filas con nil quedan vacías (NULL)
+func OptionalDouble(name string, values []*float64) Column {
+	column := Column{name: name, physical: typeDouble, converted: -1, optional: true, rows: len(values), present: make([]bool, len(values))}
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		column.present[i] = true
+		column.plain = binary.LittleEndian.AppendUint64(column.plain, math.Float64bits(*value))
+	}
+	return column
+}
+
+// Write escribe un archivo Parquet con las columnas indicadas, que deben tener todas el mismo
+// número de filas
+func Write(w io.Writer, columns ...Column) error {
+	rows := 0
+	if len(columns) > 0 {
+		rows = columns[0].rows
+	}
+	for _, column := range columns {
+		if column.rows != rows {
+			return ErrColumnLength
+		}
+	}
+
+	var file bytes.Buffer
+	file.WriteString(magic)
+
+	chunks := make([]chunkInfo, 0, len(columns))
+	var totalSize int64
+	for _, column := range columns {
+		chunk, err := writeChunk(&file, column)
+		if err != nil {
+			return fmt.Errorf("parquet: column %s: %w", column.name, err)
+		}
+		chunks = append(chunks, chunk)
+		totalSize += chunk.uncompressedSize
+	}
+
+	footer := fileMetaData(columns, chunks, int64(rows), totalSize)
+	file.Write(footer)
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer))))
+	file.WriteString(magic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// chunkInfo describe la posición y el tamaño de una columna ya escrita
+type chunkInfo struct {
+	offset           int64
+	uncompressedSize int64 // Cabecera de página incluida
+	compressedSize   int64 // Cabecera de página incluida
+}
+
+// writeChunk escribe la única página de datos de la columna: niveles de definición (solo en las
+// opcionales) y valores, comprimidos con GZIP
+func writeChunk(file *bytes.Buffer, column Column) (chunkInfo, error) {
+	var page []byte
+	if column.optional {
+		levels := definitionLevels(column.present)
+		page = binary.LittleEndian.AppendUint32(page, uint32(len(levels)))
+		page = append(page, levels...)
+	}
+	page = append(page, column.plain...)
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(page); err != nil {
+		return chunkInfo{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return chunkInfo{}, err
+	}
+
+	header := &compactWriter{}
+	header.beginStruct()
+	header.i32(1, pageTypeData)
+	header.i32(2, int32(len(page)))
+	header.i32(3, int32(compressed.Len()))
+	header.structField(5)
+	header.i32(1, int32(column.rows))
+	header.i32(2, encodingPlain)
+	header.i32(3, encodingRLE)
+	header.i32(4, encodingRLE)
+	header.endStruct()
+	header.endStruct()
+
+	chunk := chunkInfo{
+		offset:           int64(file.Len()),
+		uncompressedSize: int64(header.buf.Len() + len(page)),
+		compressedSize:   int64(header.buf.Len() + compressed.Len()),
+	}
+	file.Write(header.buf.Bytes())
+	file.Write(compressed.Bytes())
+	return chunk, nil
+}
+
+// definitionLevels codifica los niveles de definición (1 con valor, 0 vacío) con el híbrido
+// RLE/bit-packing de Parquet, usando solo secuencias RLE de ancho 1
+func definitionLevels(present []bool) []byte {
+	var out []byte
+	for i := 0; i < len(present); {
+		j := i
+		for j < len(present) && present[j] == present[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		if present[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	return out
+}
+
+// fileMetaData codifica el pie del archivo: esquema, grupo de filas y metadatos de cada columna
+func fileMetaData(columns []Column, chunks []chunkInfo, rows, totalSize int64) []byte {
+	w := &compactWriter{}
+	w.beginStruct()
+	w.i32(1, 1)
+
+	w.listHeader(2, compactStruct, len(columns)+1)
+	w.beginStruct()
+	w.binary(4, "schema")
+	w.i32(5, int32(len(columns)))
+	w.endStruct()
+	for _, column := range columns {
+		w.beginStruct()
+		w.i32(1, column.physical)
+		repetition := int32(repetitionRequired)
+		if column.optional {
+			repetition = repetitionOptional
+		}
+		w.i32(3, repetition)
+		w.binary(4, column.name)
+		if column.converted >= 0 {
+			w.i32(6, column.converted)
+		}
+		w.endStruct()
+	}
+
+	w.i64(3, rows)
+
+	w.listHeader(4, compactStruct, 1)
+	w.beginStruct()
+	w.listHeader(1, compactStruct, len(columns))
+	for i, column := range columns {
+		chunk := chunks[i]
+		w.beginStruct()
+		w.i64(2, chunk.offset)
+		w.structField(3)
+		w.i32(1, column.physical)
+		w.listHeader(2, compactI32, 2)
+		w.listI32(encodingPlain)
+		w.listI32(encodingRLE)
+		w.listHeader(3, compactBinary, 1)
+		w.listBinary(column.name)
+		w.i32(4, codecGzip)
+		w.i64(5, int64(column.rows))
+		w.i64(6, chunk.uncompressedSize)
+		w.i64(7, chunk.compressedSize)
+		w.i64(9, chunk.offset)
+		w.endStruct()
+		w.endStruct()
+	}
+	w.i64(2, totalSize)
+	w.i64(3, rows)
+	w.endStruct()
+
+	w.binary(6, createdBy)
+	w.endStruct()
+	return w.buf.Bytes()
+}
+
+// Tipos del protocolo compacto de Thrift, con el que Parquet codifica sus metadatos
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// compactWriter codifica estructuras Thrift con el protocolo compacto
+type compactWriter struct {
+	buf  bytes.Buffer
+	last []int16 // Último campo escrito de cada estructura abierta
+}
+
+func (w *compactWriter) beginStruct() {
+	w.last = append(w.last, 0)
+}
+
+func (w *compactWriter) endStruct() {
+	w.buf.WriteByte(0)
+	w.last = w.last[:len(w.last)-1]
+}
+
+// fieldHeader escribe la cabecera de un campo: la diferencia con el anterior si cabe en 4 bits,
+// o el identificador completo
+func (w *compactWriter) fieldHeader(id int16, fieldType byte) {
+	last := &w.last[len(w.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.varint(int64(id))
+	}
+	*last = id
+}
+
+func (w *compactWriter) i32(id int16, value int32) {
+	w.fieldHeader(id, compactI32)
+	w.varint(int64(value))
+}
+
+func (w *compactWriter) i64(id int16, value int64) {
+	w.fieldHeader(id, compactI64)
+	w.varint(value)
+}
+
+func (w *compactWriter) binary(id int16, value string) {
+	w.fieldHeader(id, compactBinary)
+	w.listBinary(value)
+}
+
+// structField abre una estructura como campo; se cierra con endStruct
+func (w *compactWriter) structField(id int16) {
+	w.fieldHeader(id, compactStruct)
+	w.beginStruct()
+}
+
+func (w *compactWriter) listHeader(id int16, elementType byte, size int) {
+	w.fieldHeader(id, compactList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	w.buf.WriteByte(0xf0 | elementType)
+	w.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+}
+
+func (w *compactWriter) listI32(value int32) {
+	w.varint(int64(value))
+}
+
+func (w *compactWriter) listBinary(value string) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64(len(value))))
+	w.buf.WriteString(value)
+}
+
+// varint escribe un entero con codificación zigzag
+func (w *compactWriter) varint(value int64) {
+	w.buf.Write(binary.AppendUvarint(nil, uint64((value<<1)^(value>>63))))
+}
@@ -0,0 +1,224 @@
+// Package webhooksig firma los webhooks salientes del monitor de tanques y verifica esas firmas
+// en los receptores. Cada entrega lleva un identificador único, la marca de tiempo del envío y
+// un HMAC-SHA256 de ambos y del cuerpo con el secreto compartido:
+//
+//	X-Webhook-Id: 4f9c0b7e-...
+//	X-Webhook-Timestamp: 1718000000
+//	X-Webhook-Signature: v1=5d41402abc4b2a76b9719d911017c592...
+//
+// El receptor rechaza las firmas que no coinciden, las entregas fuera de la tolerancia de la
+// marca de tiempo y, con una ReplayCache, las que repiten un identificador ya recibido. Solo
+// depende de la biblioteca estándar, así que los consumidores pueden importarlo sin arrastrar
+// el resto del módulo.
+package webhooksig
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cabeceras de las entregas firmadas
+const (
+	IDHeader        = "X-Webhook-Id"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// signatureVersion precede a cada firma para poder cambiar el esquema sin romper a los receptores
+const signatureVersion = "v1"
+
+// DefaultTolerance es la antigüedad máxima, en cualquier sentido, de la marca de tiempo de una
+// entrega; cubre los reintentos de red y una desviación razonable de los relojes
+const DefaultTolerance = 5 * time.Minute
+
+// Errores de verificación
+var (
+	ErrMissingSignature = errors.New("webhooksig: missing signature headers")
+	ErrInvalidSignature = errors.New("webhooksig: invalid signature")
+	ErrTimestampExpired = errors.New("webhooksig: timestamp outside tolerance")
+	ErrReplayed         = errors.New("webhooksig: delivery already received")
+)
+
+// Sign devuelve la cabecera de firma de la entrega: el HMAC-SHA256 en hexadecimal de
+// "id.timestamp.body" con el secreto
+func Sign(secret []byte, id string, timestamp time.Time, body []byte) string {
+	return signatureVersion + "=" + hex.EncodeToString(mac(secret, id, timestamp.Unix(), body))
+}
+
+// SignRequest añade a la solicitud las cabeceras de la entrega id firmada en el instante now.
+// body debe ser exactamente el cuerpo que se envía.
+func SignRequest(req *http.Request, secret []byte, id string, now time.Time, body []byte) {
+	req.Header.Set(IDHeader, id)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(secret, id, now, body))
+}
+
+// mac calcula el HMAC del contenido firmado
+func mac(secret []byte, id string, timestamp int64, body []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(id))
+	h.Write([]byte("."))
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// ReplayCache recuerda los identificadores de las entregas recibidas mientras su marca de tiempo
+// esté dentro de la tolerancia; pasado ese plazo la propia tolerancia las rechaza
+type ReplayCache interface {
+	// Seen anota el identificador hasta expiresAt e indica si ya estaba anotado
+	Seen(id string, expiresAt time.Time) bool
+}
+
+// Verifier comprueba las entregas firmadas con uno o varios secretos. Varios secretos permiten
+// rotarlos sin perder entregas: se acepta la firma de cualquiera de ellos.
+type Verifier struct {
+	secrets   [][]byte
+	tolerance time.Duration
+	replay    ReplayCache
+	now       func() time.Time
+}
+
+// NewVerifier crea un verificador con la tolerancia DefaultTolerance y sin protección frente a
+// repeticiones más allá de la marca de tiempo
+func NewVerifier(secrets ...[]byte) *Verifier {
+	return &Verifier{
+		secrets:   secrets,
+		tolerance: DefaultTolerance,
+		now:       time.Now,
+	}
+}
+
+// WithTolerance cambia la antigüedad máxima de las marcas de tiempo
+func (v *Verifier) WithTolerance(tolerance time.Duration) *Verifier {
+	v.tolerance = tolerance
+	return v
+}
+
+// WithReplayCache rechaza las entregas cuyo identificador ya se recibió
+func (v *Verifier) WithReplayCache(cache ReplayCache) *Verifier {
+	v.replay = cache
+	return v
+}
+
+// WithClock sustituye el reloj del verificador, p. ej. en las pruebas
+func (v *Verifier) WithClock(now func() time.Time) *Verifier {
+	v.now = now
+	return v
+}
+
+// Verify comprueba las cabeceras de una entrega con su cuerpo
+func (v *Verifier) Verify(header http.Header, body []byte) error {
+	id := header.Get(IDHeader)
+	rawTimestamp := header.Get(TimestampHeader)
+	signatures := header.Get(SignatureHeader)
+	if id == "" || rawTimestamp == "" || signatures == "" {
+		return ErrMissingSignature
+	}
+
+	timestamp, err := strconv.ParseInt(rawTimestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !v.validSignature(signatures, id, timestamp, body) {
+		return ErrInvalidSignature
+	}
+
+	// La marca de tiempo solo se comprueba con la firma válida: forma parte del contenido firmado
+	sentAt := time.Unix(timestamp, 0)
+	now := v.now()
+	if sentAt.Before(now.Add(-v.tolerance)) || sentAt.After(now.Add(v.tolerance)) {
+		return ErrTimestampExpired
+	}
+	if v.replay != nil && v.replay.Seen(id, sentAt.Add(v.tolerance)) {
+		return ErrReplayed
+	}
+	return nil
+}
+
+// validSignature indica si alguna de las firmas de la cabecera, separadas por espacios,
+// corresponde a alguno de los secretos
+func (v *Verifier) validSignature(signatures, id string, timestamp int64, body []byte) bool {
+	for _, signature := range strings.Fields(signatures) {
+		version, encoded, ok := strings.Cut(signature, "=")
+		if !ok || version != signatureVersion {
+			continue
+		}
+		received, err := hex.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		for _, secret := range v.secrets {
+			if hmac.Equal(received, mac(secret, id, timestamp, body)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VerifyRequest lee el cuerpo de la solicitud, lo comprueba y lo devuelve. El cuerpo de la
+// solicitud se restaura para que los manejadores posteriores puedan leerlo.
+func (v *Verifier) VerifyRequest(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, v.Verify(r.Header, body)
+}
+
+// Middleware responde 401 a las solicitudes sin una firma válida y pasa el resto a next
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := v.VerifyRequest(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MemoryReplayCache es una ReplayCache en memoria, válida para un único proceso receptor
+type MemoryReplayCache struct {
+	mutex sync.Mutex
+	seen  map[string]time.Time
+	now   func() time.Time
+}
+
+// NewMemoryReplayCache crea una caché de identificadores vacía
+func NewMemoryReplayCache() *MemoryReplayCache {
+	return &MemoryReplayCache{
+		seen: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// Seen anota el identificador y descarta los que ya expiraron
+func (c *MemoryReplayCache) Seen(id string, expiresAt time.Time) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	for seenID, expiry := range c.seen {
+		if now.After(expiry) {
+			delete(c.seen, seenID)
+		}
+	}
+
+	if _, exists := c.seen[id]; exists {
+		return true
+	}
+	c.seen[id] = expiresAt
+	return false
+}
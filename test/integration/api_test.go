@@ -0,0 +1,3599 @@
+package integration_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/cmd/api"
+	"monitor-tanques/internal/adapters/handlers"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/pkg/msgpack"
+)
+
+func TestAPI_TankLifecycle(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			// Crear
+			var created domain.Tank
+			status := server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Integración",
+				"capacity":        1000.0,
+				"current_level":   600.0,
+				"liquid_type":     "Agua",
+				"alert_threshold": 10.0,
+			}, &created)
+			if status != http.StatusCreated {
+				t.Fatalf("Código de estado inesperado al crear. Esperado: %d, Obtenido: %d", http.StatusCreated, status)
+			}
+			if created.ID == "" {
+				t.Fatal("El tanque creado no tiene ID")
+			}
+
+			// Listar
+			var tanks []domain.Tank
+			if status := server.do(t, http.MethodGet, "/api/tanks", nil, &tanks); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado al listar: %d", status)
+			}
+			if len(tanks) != 1 {
+				t.Fatalf("Se esperaba 1 tanque, pero se obtuvieron %d", len(tanks))
+			}
+
+			// Actualizar
+			created.Name = "Tanque Renombrado"
+			var updated domain.Tank
+			if status := server.do(t, http.MethodPut, "/api/tanks/"+created.ID, created, &updated); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado al actualizar: %d", status)
+			}
+
+			var fetched domain.Tank
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+created.ID, nil, &fetched); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado al obtener: %d", status)
+			}
+			if fetched.Name != "Tanque Renombrado" {
+				t.Errorf("Nombre incorrecto. Esperado: %s, Obtenido: %s", "Tanque Renombrado", fetched.Name)
+			}
+
+			// Eliminar
+			if status := server.do(t, http.MethodDelete, "/api/tanks/"+created.ID, nil, nil); status != http.StatusNoContent {
+				t.Fatalf("Código de estado inesperado al eliminar: %d", status)
+			}
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+created.ID, nil, nil); status == http.StatusOK {
+				t.Errorf("El tanque eliminado todavía puede obtenerse")
+			}
+		})
+	}
+}
+
+func TestAPI_MeasurementTriggersAlert(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Alerta",
+				"capacity":        1000.0,
+				"current_level":   800.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+
+			// Una medición normal no genera alertas
+			status := server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements",
+				map[string]interface{}{"level": 700.0, "temperature": 20.0}, nil)
+			if status != http.StatusCreated {
+				t.Fatalf("Código de estado inesperado al añadir la medición: %d", status)
+			}
+			if server.notifier.count() != 0 {
+				t.Fatalf("No se esperaban alertas, pero se enviaron %d", server.notifier.count())
+			}
+
+			// Una medición por debajo del umbral genera una alerta
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements",
+				map[string]interface{}{"level": 50.0, "temperature": 20.0}, nil)
+			if server.notifier.count() != 1 {
+				t.Fatalf("Se esperaba 1 alerta, pero se enviaron %d", server.notifier.count())
+			}
+
+			var fetched domain.Tank
+			server.do(t, http.MethodGet, "/api/tanks/"+tank.ID, nil, &fetched)
+			if fetched.Status != "critical" {
+				t.Errorf("Estado incorrecto. Esperado: critical, Obtenido: %s", fetched.Status)
+			}
+		})
+	}
+}
+
+func TestAPI_Health(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			if status := server.do(t, http.MethodGet, "/health", nil, nil); status != http.StatusOK {
+				t.Errorf("Código de estado inesperado: %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_ProfilingEndpoints(t *testing.T) {
+	disabled := newTestServer(t, backends()[0])
+	if status := disabled.do(t, http.MethodGet, "/api/admin/debug/pprof/heap", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Los perfiles deberían estar desactivados por defecto, código: %d", status)
+	}
+
+	config := api.DefaultConfig()
+	config.ProfilingEnabled = true
+	enabled := newTestServer(t, backend{
+		name: "profiling",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	for _, path := range []string{"/api/admin/debug/pprof/", "/api/admin/debug/pprof/heap"} {
+		if status := enabled.do(t, http.MethodGet, path, nil, nil); status != http.StatusOK {
+			t.Errorf("Código de estado inesperado para %s: %d", path, status)
+		}
+	}
+}
+
+func TestAPI_TanksGeoJSON(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			located := map[string]interface{}{
+				"name":            "Tanque Bogotá",
+				"capacity":        1000.0,
+				"current_level":   50.0,
+				"alert_threshold": 10.0,
+				"location":        map[string]float64{"latitude": 4.711, "longitude": -74.0721},
+			}
+			if status := server.do(t, http.MethodPost, "/api/tanks", located, nil); status != http.StatusCreated {
+				t.Fatalf("Código de estado inesperado al crear: %d", status)
+			}
+			unlocated := map[string]interface{}{"name": "Sin ubicación", "capacity": 500.0}
+			server.do(t, http.MethodPost, "/api/tanks", unlocated, nil)
+
+			var collection domain.FeatureCollection
+			if status := server.do(t, http.MethodGet, "/api/tanks/geojson", nil, &collection); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado: %d", status)
+			}
+			if collection.Type != "FeatureCollection" || len(collection.Features) != 1 {
+				t.Fatalf("Se esperaba 1 elemento, se obtuvieron %d", len(collection.Features))
+			}
+			if coords := collection.Features[0].Geometry.Coordinates; coords[0] != -74.0721 || coords[1] != 4.711 {
+				t.Errorf("Coordenadas incorrectas (se esperaba [lon, lat]): %v", coords)
+			}
+
+			// Un rectángulo que no contiene el tanque devuelve una colección vacía
+			server.do(t, http.MethodGet, "/api/tanks/geojson?bbox=0,0,10,10", nil, &collection)
+			if len(collection.Features) != 0 {
+				t.Errorf("Se esperaba una colección vacía, se obtuvieron %d elementos", len(collection.Features))
+			}
+
+			if status := server.do(t, http.MethodGet, "/api/tanks/geojson?bbox=1,2,3", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con bbox inválido, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_ConditionalGet(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":     "Tanque Tablero",
+				"capacity": 1000.0,
+			}, &tank)
+
+			get := func(path string, header, value string) *http.Response {
+				t.Helper()
+				req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+				if err != nil {
+					t.Fatalf("Error al crear la petición: %v", err)
+				}
+				if header != "" {
+					req.Header.Set(header, value)
+				}
+				resp, err := server.Client().Do(req)
+				if err != nil {
+					t.Fatalf("Error al ejecutar GET %s: %v", path, err)
+				}
+				resp.Body.Close()
+				return resp
+			}
+
+			for _, path := range []string{"/api/tanks", "/api/tanks/" + tank.ID} {
+				first := get(path, "", "")
+				etag := first.Header.Get("ETag")
+				if first.StatusCode != http.StatusOK || etag == "" || first.Header.Get("Last-Modified") == "" {
+					t.Fatalf("%s: se esperaban ETag y Last-Modified, se obtuvo %d %v", path, first.StatusCode, first.Header)
+				}
+
+				if resp := get(path, "If-None-Match", etag); resp.StatusCode != http.StatusNotModified {
+					t.Errorf("%s: se esperaba 304 con el mismo ETag, se obtuvo %d", path, resp.StatusCode)
+				}
+				if resp := get(path, "If-Modified-Since", first.Header.Get("Last-Modified")); resp.StatusCode != http.StatusNotModified {
+					t.Errorf("%s: se esperaba 304 con If-Modified-Since, se obtuvo %d", path, resp.StatusCode)
+				}
+			}
+
+			// Una nueva medición cambia la representación del tanque
+			before := get("/api/tanks/"+tank.ID, "", "").Header.Get("ETag")
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": 300.0}, nil)
+			if resp := get("/api/tanks/"+tank.ID, "If-None-Match", before); resp.StatusCode != http.StatusOK {
+				t.Errorf("Se esperaba 200 tras la nueva medición, se obtuvo %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestAPI_ResponseCompression(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			// Suficientes tanques para superar el tamaño mínimo de compresión
+			for i := 0; i < 20; i++ {
+				server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+					"name":     fmt.Sprintf("Tanque %d", i),
+					"capacity": 1000.0,
+				}, nil)
+			}
+
+			get := func(path, acceptEncoding string) *http.Response {
+				t.Helper()
+				req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+				if err != nil {
+					t.Fatalf("Error al crear la petición: %v", err)
+				}
+				// Al fijar Accept-Encoding, el cliente no descomprime la respuesta por su cuenta
+				req.Header.Set("Accept-Encoding", acceptEncoding)
+				resp, err := server.Client().Do(req)
+				if err != nil {
+					t.Fatalf("Error al ejecutar GET %s: %v", path, err)
+				}
+				t.Cleanup(func() { resp.Body.Close() })
+				return resp
+			}
+
+			resp := get("/api/tanks", "br;q=1.0, gzip;q=0.8")
+			if resp.Header.Get("Content-Encoding") != "gzip" {
+				t.Fatalf("Se esperaba una respuesta gzip, se obtuvo %q", resp.Header.Get("Content-Encoding"))
+			}
+			reader, err := gzip.NewReader(resp.Body)
+			if err != nil {
+				t.Fatalf("La respuesta no es gzip válido: %v", err)
+			}
+			var tanks []domain.Tank
+			if err := json.NewDecoder(reader).Decode(&tanks); err != nil || len(tanks) != 20 {
+				t.Fatalf("Contenido descomprimido incorrecto: %d tanques, error %v", len(tanks), err)
+			}
+
+			if resp := get("/api/tanks", "identity"); resp.Header.Get("Content-Encoding") != "" {
+				t.Errorf("No se esperaba compresión sin gzip en Accept-Encoding")
+			}
+			if resp := get("/api/tanks", "gzip;q=0"); resp.Header.Get("Content-Encoding") != "" {
+				t.Errorf("No se esperaba compresión con gzip;q=0")
+			}
+			if resp := get("/health", "gzip"); resp.Header.Get("Content-Encoding") != "" {
+				t.Errorf("Las respuestas pequeñas no deben comprimirse")
+			}
+		})
+	}
+}
+
+func TestAPI_MeasurementsExport(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Historial",
+				"capacity":        1000.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+
+			start := time.Now().Add(-time.Hour)
+			for i := 0; i < 250; i++ {
+				server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{
+					"level":     900.0 - float64(i),
+					"timestamp": start.Add(time.Duration(i) * time.Second),
+				}, nil)
+			}
+
+			// JSON con límite
+			var latest []domain.Measurement
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements?limit=10", nil, &latest); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado al listar las mediciones: %d", status)
+			}
+			if len(latest) != 10 || latest[0].Level != 651 {
+				t.Fatalf("Se esperaban las 10 mediciones más recientes, se obtuvieron %d", len(latest))
+			}
+
+			// NDJSON completo
+			resp, err := server.Client().Get(server.URL + "/api/tanks/" + tank.ID + "/measurements?format=ndjson")
+			if err != nil {
+				t.Fatalf("Error al exportar las mediciones: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+				t.Fatalf("Content-Type inesperado: %q", ct)
+			}
+			rows := 0
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				var measurement domain.Measurement
+				if err := json.Unmarshal(scanner.Bytes(), &measurement); err != nil {
+					t.Fatalf("Línea %d no es JSON válido: %v", rows+1, err)
+				}
+				rows++
+			}
+			if rows != 250 {
+				t.Errorf("Se esperaban 250 líneas, se obtuvieron %d", rows)
+			}
+
+			// Un tanque inexistente conserva su código de error
+			if status := server.do(t, http.MethodGet, "/api/tanks/no-existe/measurements?format=ndjson", nil, nil); status == http.StatusOK {
+				t.Errorf("No se esperaba 200 para un tanque inexistente")
+			}
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements?limit=0", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 para limit=0, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_MeasurementFilter(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Filtro",
+				"capacity":        1000.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+
+			start := time.Now().Add(-time.Hour)
+			for i, reading := range []struct{ level, temperature float64 }{{80, 35}, {90, 20}, {150, 40}, {60, 31}} {
+				server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{
+					"level":       reading.level,
+					"temperature": reading.temperature,
+					"timestamp":   start.Add(time.Duration(i) * time.Minute),
+				}, nil)
+			}
+
+			filter := url.QueryEscape("level<100 AND temperature>30")
+			var measurements []domain.Measurement
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements?filter="+filter, nil, &measurements); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado al filtrar las mediciones: %d", status)
+			}
+			if len(measurements) != 2 || measurements[0].Level != 60 || measurements[1].Level != 80 {
+				t.Errorf("Filtrado incorrecto: %+v", measurements)
+			}
+
+			// limit cuenta solo las mediciones que cumplen el filtro
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements?limit=1&filter="+filter, nil, &measurements); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado: %d", status)
+			}
+			if len(measurements) != 1 || measurements[0].Level != 60 {
+				t.Errorf("Se esperaba la medición filtrada más reciente: %+v", measurements)
+			}
+
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements?filter="+url.QueryEscape("level<"), nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un filtro inválido, se obtuvo %d", status)
+			}
+
+			// El error indica el motivo y la posición del fallo
+			resp, err := http.Get(server.URL + "/api/tanks/" + tank.ID + "/measurements?filter=" + url.QueryEscape("level<100 AND nivel>3"))
+			if err != nil {
+				t.Fatalf("Error al consultar las mediciones: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(body), `unknown field "nivel" at position 15`) {
+				t.Errorf("Se esperaba el detalle del error del filtro: %d %s", resp.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestAPI_Versioning(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var created domain.Tank
+			if status := server.do(t, http.MethodPost, "/api/v1/tanks", map[string]interface{}{
+				"name":     "Tanque Versionado",
+				"capacity": 1000.0,
+			}, &created); status != http.StatusCreated {
+				t.Fatalf("Código de estado inesperado al crear con /api/v1: %d", status)
+			}
+
+			// El alias sin versión y la ruta versionada sirven los mismos datos
+			var unversioned, versioned domain.Tank
+			server.do(t, http.MethodGet, "/api/tanks/"+created.ID, nil, &unversioned)
+			server.do(t, http.MethodGet, "/api/v1/tanks/"+created.ID, nil, &versioned)
+			if unversioned.ID != created.ID || versioned.ID != created.ID {
+				t.Fatalf("Se esperaba el mismo tanque en ambas rutas")
+			}
+
+			resp, err := server.Client().Get(server.URL + "/api/v1/tanks")
+			if err != nil {
+				t.Fatalf("Error al ejecutar GET: %v", err)
+			}
+			resp.Body.Close()
+			if version := resp.Header.Get("API-Version"); version != "v1" {
+				t.Errorf("Cabecera API-Version inesperada: %q", version)
+			}
+
+			if status := server.do(t, http.MethodGet, "/api/v9/tanks", nil, nil); status != http.StatusNotFound {
+				t.Errorf("Se esperaba 404 para una versión no publicada, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_V2Envelope(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			for i := 0; i < 3; i++ {
+				server.do(t, http.MethodPost, "/api/v2/tanks", map[string]interface{}{
+					"name":     fmt.Sprintf("Tanque %d", i),
+					"capacity": 1000.0,
+				}, nil)
+			}
+
+			var page struct {
+				Data  []domain.Tank     `json:"data"`
+				Meta  handlers.Meta     `json:"meta"`
+				Links map[string]string `json:"links"`
+			}
+			if status := server.do(t, http.MethodGet, "/api/v2/tanks?limit=2", nil, &page); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado al listar: %d", status)
+			}
+			if len(page.Data) != 2 || page.Meta.Pagination == nil || page.Meta.Pagination.Total != 3 {
+				t.Fatalf("Página incorrecta: %d tanques, meta %+v", len(page.Data), page.Meta)
+			}
+			if page.Links["self"] != "/api/v2/tanks?limit=2" {
+				t.Errorf("Enlace self inesperado: %q", page.Links["self"])
+			}
+			if page.Links["next"] != "/api/v2/tanks?limit=2&offset=2" {
+				t.Errorf("Enlace next inesperado: %q", page.Links["next"])
+			}
+
+			var last struct {
+				Data []domain.Tank `json:"data"`
+			}
+			server.do(t, http.MethodGet, page.Links["next"], nil, &last)
+			if len(last.Data) != 1 {
+				t.Errorf("Se esperaba 1 tanque en la última página, se obtuvieron %d", len(last.Data))
+			}
+
+			var single struct {
+				Data domain.Tank `json:"data"`
+				Meta *handlers.Meta
+			}
+			server.do(t, http.MethodGet, "/api/v2/tanks/"+page.Data[0].ID, nil, &single)
+			if single.Data.ID != page.Data[0].ID || single.Meta != nil {
+				t.Errorf("Sobre incorrecto para un tanque individual: %+v", single)
+			}
+
+			resp, err := server.Client().Get(server.URL + "/api/v2/tanks?limit=abc")
+			if err != nil {
+				t.Fatalf("Error al ejecutar GET: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Fatalf("Se esperaba 400 para limit inválido, se obtuvo %d", resp.StatusCode)
+			}
+			var failure handlers.ErrorEnvelope
+			if err := json.NewDecoder(resp.Body).Decode(&failure); err != nil {
+				t.Fatalf("El error no es JSON: %v", err)
+			}
+			if failure.Error.Status != http.StatusBadRequest || failure.Error.Message == "" {
+				t.Errorf("Error con formato inesperado: %+v", failure)
+			}
+
+			// La v1 conserva el formato original
+			var tanks []domain.Tank
+			if status := server.do(t, http.MethodGet, "/api/v1/tanks", nil, &tanks); status != http.StatusOK || len(tanks) != 3 {
+				t.Errorf("La v1 debería devolver la lista sin sobre")
+			}
+		})
+	}
+}
+
+func TestAPI_ProvisionDryRun(t *testing.T) {
+	const file = `
+sites:
+  - id: estacion-norte
+    tanks:
+      - id: tanque-1
+        name: Diésel principal
+        capacity: 20000
+`
+
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			provision := func(query string) domain.ProvisioningResult {
+				t.Helper()
+				resp, err := server.Client().Post(server.URL+"/api/admin/provision"+query, "application/yaml", strings.NewReader(file))
+				if err != nil {
+					t.Fatalf("Error al ejecutar POST: %v", err)
+				}
+				defer resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("Código de estado inesperado al aprovisionar: %d", resp.StatusCode)
+				}
+				var result domain.ProvisioningResult
+				if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+					t.Fatalf("Error al decodificar el resultado: %v", err)
+				}
+				return result
+			}
+
+			// El plan informa del tanque a crear sin crearlo
+			plan := provision("?dry_run=true")
+			var tanks []domain.Tank
+			server.do(t, http.MethodGet, "/api/tanks", nil, &tanks)
+			if !plan.DryRun || plan.Created != 1 || len(tanks) != 0 {
+				t.Fatalf("El plan no debe aplicar cambios: %+v, tanques: %d", plan, len(tanks))
+			}
+
+			applied := provision("")
+			server.do(t, http.MethodGet, "/api/tanks", nil, &tanks)
+			if applied.DryRun || applied.Created != 1 || len(tanks) != 1 {
+				t.Fatalf("El aprovisionamiento debe crear el tanque: %+v, tanques: %d", applied, len(tanks))
+			}
+
+			if again := provision("?dry_run=true"); again.Created != 0 || again.Unchanged != 1 {
+				t.Errorf("Tras aplicar, el plan no debe tener cambios: %+v", again)
+			}
+
+			resp, err := server.Client().Post(server.URL+"/api/admin/provision?dry_run=true", "application/yaml", strings.NewReader("tanques: []"))
+			if err != nil {
+				t.Fatalf("Error al ejecutar POST: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 para un archivo inválido, se obtuvo %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestAPI_AcceptLanguage(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			get := func(path, acceptLanguage string) (*http.Response, string) {
+				t.Helper()
+				req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+				if acceptLanguage != "" {
+					req.Header.Set("Accept-Language", acceptLanguage)
+				}
+				resp, err := server.Client().Do(req)
+				if err != nil {
+					t.Fatalf("Error al ejecutar GET %s: %v", path, err)
+				}
+				defer resp.Body.Close()
+				var body bytes.Buffer
+				body.ReadFrom(resp.Body)
+				return resp, strings.TrimSpace(body.String())
+			}
+
+			resp, body := get("/api/field-devices?in_sync=quizas", "en-US,en;q=0.9")
+			if resp.Header.Get("Content-Language") != "en" || body != "Invalid in_sync parameter" {
+				t.Errorf("Se esperaba el error en inglés, se obtuvo %q (%s)", body, resp.Header.Get("Content-Language"))
+			}
+
+			resp, body = get("/api/v9/tanks", "en")
+			if resp.StatusCode != http.StatusNotFound || body != "Unsupported API version" {
+				t.Errorf("Se esperaba el error de versión en inglés, se obtuvo %d %q", resp.StatusCode, body)
+			}
+
+			// Sin Accept-Language o con un idioma no disponible se responde en español
+			resp, body = get("/api/v9/tanks", "fr")
+			if resp.Header.Get("Content-Language") != "es" || body != "Versión de API no soportada" {
+				t.Errorf("Se esperaba el error en español, se obtuvo %q", body)
+			}
+		})
+	}
+}
+
+func TestAPI_CloneTank(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var source domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Diésel patio",
+				"site_id":         "estacion-norte",
+				"location":        map[string]float64{"latitude": 4.711, "longitude": -74.0721},
+				"capacity":        20000.0,
+				"alert_threshold": 15.0,
+				"reorder":         map[string]float64{"reorder_level": 5000},
+			}, &source)
+			server.do(t, http.MethodPost, "/api/tanks/"+source.ID+"/measurements",
+				map[string]interface{}{"level": 12000.0, "temperature": 22.0}, nil)
+
+			// Sin cuerpo: ID generado y nombre derivado del original
+			var clone domain.Tank
+			if status := server.do(t, http.MethodPost, "/api/tanks/"+source.ID+"/clone", nil, &clone); status != http.StatusCreated {
+				t.Fatalf("Código de estado inesperado al clonar: %d", status)
+			}
+			if clone.ID == "" || clone.ID == source.ID || clone.Name != "Diésel patio (copia)" {
+				t.Errorf("Identidad del clon incorrecta: %s %q", clone.ID, clone.Name)
+			}
+			if clone.SiteID != source.SiteID || clone.AlertThreshold != 15 || clone.Reorder.ReorderLevel != 5000 || clone.Location == nil {
+				t.Errorf("La configuración no se copió: %+v", clone)
+			}
+
+			var measurements []domain.Measurement
+			server.do(t, http.MethodGet, "/api/tanks/"+clone.ID+"/measurements", nil, &measurements)
+			if len(measurements) != 0 || clone.CurrentLevel != 0 {
+				t.Errorf("El clon no debería tener mediciones ni nivel: %d mediciones, nivel %.2f", len(measurements), clone.CurrentLevel)
+			}
+
+			// Con ID y nombre propios; repetir el ID es un conflicto
+			body := map[string]string{"id": "tq-clon", "name": "Diésel patio 2"}
+			if status := server.do(t, http.MethodPost, "/api/tanks/"+source.ID+"/clone", body, &clone); status != http.StatusCreated || clone.ID != "tq-clon" {
+				t.Fatalf("Clonación con ID propio inesperada: %d %s", status, clone.ID)
+			}
+			if status := server.do(t, http.MethodPost, "/api/tanks/"+source.ID+"/clone", body, nil); status != http.StatusConflict {
+				t.Errorf("Se esperaba 409 al repetir el ID, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodPost, "/api/tanks/no-existe/clone", nil, nil); status < 400 {
+				t.Errorf("Clonar un tanque inexistente debería fallar, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_TankImport(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			post := func(path, contentType, body string) (int, domain.TankImportResult) {
+				t.Helper()
+				req, _ := http.NewRequest(http.MethodPost, server.URL+path, strings.NewReader(body))
+				req.Header.Set("Content-Type", contentType)
+				req.Header.Set("Accept-Language", "en")
+				resp, err := server.Client().Do(req)
+				if err != nil {
+					t.Fatalf("Error al ejecutar POST %s: %v", path, err)
+				}
+				defer resp.Body.Close()
+				var result domain.TankImportResult
+				if resp.StatusCode == http.StatusOK {
+					json.NewDecoder(resp.Body).Decode(&result)
+				}
+				return resp.StatusCode, result
+			}
+
+			csv := "name,capacity,current_level\nTanque A,1000,400\nTanque B,0,\n"
+
+			status, plan := post("/api/tanks/import?dry_run=true", "text/csv", csv)
+			if status != http.StatusOK || plan.Valid != 1 || plan.Invalid != 1 {
+				t.Fatalf("Simulación inesperada: %d %+v", status, plan)
+			}
+			if message := plan.Rows[1].Errors[0].Message; message != "The capacity must be greater than zero" {
+				t.Errorf("Se esperaba el error de la fila en inglés, se obtuvo %q", message)
+			}
+
+			status, result := post("/api/tanks/import", "text/csv; charset=utf-8", csv)
+			if status != http.StatusOK || result.Created != 1 || result.Rows[0].TankID == "" {
+				t.Fatalf("Importación inesperada: %d %+v", status, result)
+			}
+
+			var tanks []domain.Tank
+			server.do(t, http.MethodGet, "/api/tanks", nil, &tanks)
+			if len(tanks) != 1 || tanks[0].Name != "Tanque A" {
+				t.Errorf("Se esperaba solo el tanque válido, se obtuvieron %+v", tanks)
+			}
+
+			if status, _ := post("/api/tanks/import", "application/json", "{}"); status != http.StatusUnsupportedMediaType {
+				t.Errorf("Se esperaba 415 para un formato no soportado, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_ResponseCache(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Tablero",
+				"capacity":        1000.0,
+				"current_level":   800.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+
+			cacheStatus := func(path string) string {
+				t.Helper()
+				resp, err := server.Client().Get(server.URL + path)
+				if err != nil {
+					t.Fatalf("Error al ejecutar GET %s: %v", path, err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("Código de estado inesperado en %s: %d", path, resp.StatusCode)
+				}
+				return resp.Header.Get("X-Cache")
+			}
+
+			kpis := "/api/tanks/" + tank.ID + "/kpis"
+			if got := cacheStatus(kpis); got != "MISS" {
+				t.Errorf("La primera consulta debería calcularse, X-Cache: %q", got)
+			}
+			if got := cacheStatus(kpis); got != "HIT" {
+				t.Errorf("La segunda consulta debería salir de la caché, X-Cache: %q", got)
+			}
+			if got := cacheStatus("/api/sensors"); got != "MISS" {
+				t.Errorf("La salud de los sensores debería calcularse, X-Cache: %q", got)
+			}
+
+			// Una medición nueva invalida los KPI del tanque y los agregados de la flota
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements",
+				map[string]interface{}{"level": 700.0, "temperature": 20.0}, nil)
+			if got := cacheStatus(kpis); got != "MISS" {
+				t.Errorf("Los KPI deberían recalcularse tras la medición, X-Cache: %q", got)
+			}
+			if got := cacheStatus("/api/sensors"); got != "MISS" {
+				t.Errorf("La salud de los sensores debería recalcularse tras la medición, X-Cache: %q", got)
+			}
+
+			// Las consultas fuera de la caché no llevan X-Cache
+			if got := cacheStatus("/api/tanks/" + tank.ID); got != "" {
+				t.Errorf("La consulta del tanque no debería pasar por la caché, X-Cache: %q", got)
+			}
+		})
+	}
+}
+
+func TestAPI_GracefulShutdownFlushesAndSnapshots(t *testing.T) {
+	config := api.DefaultConfig()
+	config.Port = "0"
+	config.MonitorInterval = 0
+	config.MeasurementBatchSize = 100
+	config.MeasurementFlushInterval = time.Hour // Solo el cierre vacía el búfer
+	config.MemorySnapshotPath = filepath.Join(t.TempDir(), "snapshot.gob")
+
+	serve := func(t *testing.T, method, path string, body interface{}, app *api.API) *httptest.ResponseRecorder {
+		t.Helper()
+		payload, _ := json.Marshal(body)
+		recorder := httptest.NewRecorder()
+		app.Handler().ServeHTTP(recorder, httptest.NewRequest(method, path, bytes.NewReader(payload)))
+		return recorder
+	}
+
+	// Primera ejecución: la medición queda en el búfer hasta el cierre
+	app := api.NewAPI(config, nopLogger{})
+	app.SetupRoutes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.Run(ctx) }()
+
+	var tank domain.Tank
+	recorder := serve(t, http.MethodPost, "/api/tanks", map[string]interface{}{"name": "Tanque Persistente", "capacity": 1000.0}, app)
+	if err := json.NewDecoder(recorder.Body).Decode(&tank); err != nil {
+		t.Fatalf("Error al crear el tanque: %v", err)
+	}
+	serve(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": 321.0}, app)
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run terminó con error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run no terminó tras cancelar el contexto")
+	}
+
+	// Segunda ejecución: la instantánea incluye la medición vaciada al apagar
+	restarted := api.NewAPI(config, nopLogger{})
+	restarted.SetupRoutes()
+
+	var measurements []domain.Measurement
+	recorder = serve(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements", nil, restarted)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Código de estado inesperado tras reiniciar: %d", recorder.Code)
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&measurements); err != nil {
+		t.Fatalf("Error al decodificar las mediciones: %v", err)
+	}
+	if len(measurements) != 1 || measurements[0].Level != 321.0 {
+		t.Errorf("Se esperaba recuperar la medición del búfer tras reiniciar, se obtuvieron %+v", measurements)
+	}
+}
+
+func TestAPI_AlertMetrics(t *testing.T) {
+	server := newTestServer(t, backends()[0])
+
+	var tank domain.Tank
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name":            "Tanque Métricas",
+		"capacity":        1000.0,
+		"current_level":   800.0,
+		"alert_threshold": 10.0,
+	}, &tank)
+	server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements",
+		map[string]interface{}{"level": 50.0, "temperature": 20.0}, nil)
+
+	resp, err := server.Client().Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Error al consultar las métricas: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Código de estado inesperado: %d", resp.StatusCode)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Errorf("Content-Type inesperado: %s", resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(body), `tank_alerts_delivered_total{channel="default",type="log"} 1`) {
+		t.Errorf("Las métricas no incluyen la alerta entregada:\n%s", body)
+	}
+}
+
+func TestAPI_FaultInjection(t *testing.T) {
+	newFaultyServer := func(targets string) *testServer {
+		config := api.DefaultConfig()
+		config.Environment = "test"
+		config.FaultErrorRate = 1
+		config.FaultTargets = targets
+		return newTestServer(t, backend{
+			name: "faults",
+			setup: func(t *testing.T) *api.API {
+				return api.NewAPI(config, nopLogger{})
+			},
+		})
+	}
+
+	t.Run("repositories", func(t *testing.T) {
+		server := newFaultyServer("repositories")
+
+		if status := server.do(t, http.MethodGet, "/api/tanks", nil, nil); status != http.StatusInternalServerError {
+			t.Errorf("Se esperaba un error al fallar la persistencia, código: %d", status)
+		}
+		if status := server.do(t, http.MethodGet, "/health", nil, nil); status != http.StatusOK {
+			t.Errorf("La comprobación de estado no depende de la persistencia, código: %d", status)
+		}
+	})
+
+	t.Run("notifiers", func(t *testing.T) {
+		server := newFaultyServer("notifiers")
+
+		var tank domain.Tank
+		server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+			"name":            "Tanque Caos",
+			"capacity":        1000.0,
+			"current_level":   800.0,
+			"alert_threshold": 10.0,
+		}, &tank)
+		server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements",
+			map[string]interface{}{"level": 50.0, "temperature": 20.0}, nil)
+
+		// La medición se guarda aunque la alerta no pueda entregarse
+		var stored domain.Tank
+		server.do(t, http.MethodGet, "/api/tanks/"+tank.ID, nil, &stored)
+		if stored.CurrentLevel != 50 {
+			t.Errorf("Nivel incorrecto. Esperado: 50, Obtenido: %.2f", stored.CurrentLevel)
+		}
+		if server.notifier.count() != 0 {
+			t.Errorf("No se esperaban alertas entregadas, se entregaron %d", server.notifier.count())
+		}
+
+		resp, err := server.Client().Get(server.URL + "/metrics")
+		if err != nil {
+			t.Fatalf("Error al consultar las métricas: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(body), `tank_alerts_failed_total{channel="default",type="log"} 1`) {
+			t.Errorf("Las métricas no incluyen la entrega fallida:\n%s", body)
+		}
+	})
+}
+
+func TestAPI_RequestLimits(t *testing.T) {
+	config := api.DefaultConfig()
+	config.MaxRequestBodySize = 4 << 10
+	config.IngestMaxBodySize = 512
+	server := newTestServer(t, backend{
+		name: "limits",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	var tank domain.Tank
+	status := server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name":            "Tanque Límites",
+		"capacity":        1000.0,
+		"current_level":   500.0,
+		"alert_threshold": 10.0,
+	}, &tank)
+	if status != http.StatusCreated {
+		t.Fatalf("Código de estado inesperado al crear el tanque: %d", status)
+	}
+
+	padding := strings.Repeat("x", 8<<10)
+	status = server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name":     "Tanque Gigante",
+		"capacity": 1000.0,
+		"padding":  padding,
+	}, nil)
+	if status != http.StatusRequestEntityTooLarge {
+		t.Errorf("Un cuerpo mayor que el límite general debería rechazarse, código: %d", status)
+	}
+
+	// La ingesta tiene un límite propio, menor que el general
+	status = server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements",
+		map[string]interface{}{"level": 400.0, "padding": padding[:1024]}, nil)
+	if status != http.StatusRequestEntityTooLarge {
+		t.Errorf("Una medición mayor que el límite de ingesta debería rechazarse, código: %d", status)
+	}
+	status = server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements",
+		map[string]interface{}{"level": 400.0, "temperature": 20.0}, nil)
+	if status != http.StatusCreated {
+		t.Errorf("Código de estado inesperado al añadir la medición: %d", status)
+	}
+}
+
+func TestAPI_IngestTimeout(t *testing.T) {
+	// La persistencia responde con lentitud y la ingesta tiene un plazo más corto
+	config := api.DefaultConfig()
+	config.Environment = "test"
+	config.FaultLatency = 100 * time.Millisecond
+	config.FaultTargets = "repositories"
+	config.IngestRequestTimeout = 50 * time.Millisecond
+	server := newTestServer(t, backend{
+		name: "timeouts",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	var tank domain.Tank
+	if status := server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name":            "Tanque Lento",
+		"capacity":        1000.0,
+		"current_level":   500.0,
+		"alert_threshold": 10.0,
+	}, &tank); status != http.StatusCreated {
+		t.Fatalf("Código de estado inesperado al crear el tanque: %d", status)
+	}
+
+	status := server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements",
+		map[string]interface{}{"level": 400.0, "temperature": 20.0}, nil)
+	if status != http.StatusGatewayTimeout {
+		t.Errorf("Se esperaba 504 al vencer el plazo de la ingesta, código: %d", status)
+	}
+}
+
+func TestAPI_ContentNegotiation(t *testing.T) {
+	server := newTestServer(t, backends()[0])
+
+	var tank domain.Tank
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name":            "Tanque <SCADA>",
+		"capacity":        1000.0,
+		"current_level":   250.0,
+		"alert_threshold": 10.0,
+	}, &tank)
+
+	get := func(path, accept string) (*http.Response, []byte) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		req.Header.Set("Accept", accept)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Error al consultar %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp, body
+	}
+
+	// XML para las integraciones SCADA
+	resp, body := get("/api/tanks/"+tank.ID, "application/xml")
+	if resp.Header.Get("Content-Type") != "application/xml" {
+		t.Errorf("Content-Type inesperado: %s", resp.Header.Get("Content-Type"))
+	}
+	for _, fragment := range []string{"<response>", "<name>Tanque &lt;SCADA&gt;</name>", "<current_level>250</current_level>"} {
+		if !strings.Contains(string(body), fragment) {
+			t.Errorf("La respuesta XML no contiene %q:\n%s", fragment, body)
+		}
+	}
+
+	// MessagePack para los gateways con poco ancho de banda
+	resp, body = get("/api/tanks/"+tank.ID, "application/msgpack")
+	if resp.Header.Get("Content-Type") != "application/msgpack" {
+		t.Errorf("Content-Type inesperado: %s", resp.Header.Get("Content-Type"))
+	}
+	decoded, err := msgpack.Unmarshal(body)
+	if err != nil {
+		t.Fatalf("Error al decodificar la respuesta MessagePack: %v", err)
+	}
+	fields, _ := decoded.(map[string]interface{})
+	if fields["name"] != "Tanque <SCADA>" || fields["current_level"] != int64(250) {
+		t.Errorf("Respuesta MessagePack inesperada: %v", decoded)
+	}
+
+	// Las preferencias se respetan por calidad y JSON es el formato predeterminado
+	resp, _ = get("/api/tanks", "application/xml;q=0.5, application/msgpack")
+	if resp.Header.Get("Content-Type") != "application/msgpack" {
+		t.Errorf("Se esperaba MessagePack por su mayor calidad, se obtuvo: %s", resp.Header.Get("Content-Type"))
+	}
+	resp, _ = get("/api/tanks", "text/html, */*")
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Se esperaba JSON por defecto, se obtuvo: %s", resp.Header.Get("Content-Type"))
+	}
+
+	// Los errores de la v2 también usan el formato negociado
+	resp, body = get("/api/v2/tanks?limit=x", "application/xml")
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(body), "<status>400</status>") {
+		t.Errorf("Error v2 inesperado (%d):\n%s", resp.StatusCode, body)
+	}
+}
+
+func TestAPI_InboundWebhook(t *testing.T) {
+	mappingFile := filepath.Join(t.TempDir(), "webhooks.yaml")
+	mapping := `
+sources:
+  - name: acme-cloud
+    token: secreto
+    records: $.data
+    fields:
+      tank_id: $.device.tank
+      level: $.values.level_m
+      temperature: $.values.temp
+      timestamp: $.ts
+    timestamp_format: unix
+    level_scale: 100
+`
+	if err := os.WriteFile(mappingFile, []byte(mapping), 0o600); err != nil {
+		t.Fatalf("Error al escribir el mapeo: %v", err)
+	}
+	config := api.DefaultConfig()
+	config.InboundWebhooksFile = mappingFile
+	server := newTestServer(t, backend{
+		name: "webhooks",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	var tank domain.Tank
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name":            "Tanque Externo",
+		"capacity":        1000.0,
+		"current_level":   900.0,
+		"alert_threshold": 10.0,
+	}, &tank)
+
+	post := func(source, token, payload string) (int, handlers.InboundWebhookResult) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/ingest/webhooks/"+source, strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Webhook-Token", token)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Error al enviar el webhook: %v", err)
+		}
+		defer resp.Body.Close()
+		var result handlers.InboundWebhookResult
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("Error al decodificar el informe: %v", err)
+			}
+		}
+		return resp.StatusCode, result
+	}
+
+	payload := fmt.Sprintf(`{"data": [
+		{"device": {"tank": %q}, "values": {"level_m": 4.5, "temp": 19.5}, "ts": 1714557600},
+		{"device": {"tank": "no-existe"}, "values": {"level_m": 1}},
+		{"device": {}, "values": {"level_m": 1}}
+	]}`, tank.ID)
+
+	status, result := post("acme-cloud", "secreto", payload)
+	if status != http.StatusOK {
+		t.Fatalf("Código inesperado al recibir el webhook: %d", status)
+	}
+	if result.Accepted != 1 || result.Rejected != 2 {
+		t.Errorf("Resultado incorrecto: %d aceptadas, %d rechazadas", result.Accepted, result.Rejected)
+	}
+	if len(result.Records) == 3 && (result.Records[1].Status != "rejected" || result.Records[2].Error != "Falta el ID del tanque") {
+		t.Errorf("Lecturas rechazadas incorrectas: %+v, %+v", result.Records[1], result.Records[2])
+	}
+
+	var stored domain.Tank
+	server.do(t, http.MethodGet, "/api/tanks/"+tank.ID, nil, &stored)
+	if stored.CurrentLevel != 450 {
+		t.Errorf("Nivel incorrecto. Esperado: 450, Obtenido: %.2f", stored.CurrentLevel)
+	}
+
+	if status, _ := post("acme-cloud", "otro", payload); status != http.StatusUnauthorized {
+		t.Errorf("Se esperaba 401 con un token inválido, se obtuvo: %d", status)
+	}
+	if status, _ := post("desconocida", "secreto", payload); status != http.StatusNotFound {
+		t.Errorf("Se esperaba 404 para una plataforma desconocida, se obtuvo: %d", status)
+	}
+	if status, _ := post("acme-cloud", "secreto", `{"data": `); status != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 con un contenido inválido, se obtuvo: %d", status)
+	}
+}
+
+func TestAPI_DeviceIngest(t *testing.T) {
+	profilesFile := filepath.Join(t.TempDir(), "devices.yaml")
+	profiles := `
+profiles:
+  - name: ultrasonico
+    token: secreto
+    versions:
+      - version: 1
+      - version: 2
+        schema:
+          properties:
+            sensors:
+              items:
+                properties:
+                  level: {maximum: 1000}
+`
+	if err := os.WriteFile(profilesFile, []byte(profiles), 0o600); err != nil {
+		t.Fatalf("Error al escribir los perfiles: %v", err)
+	}
+	config := api.DefaultConfig()
+	config.DeviceProfilesFile = profilesFile
+	server := newTestServer(t, backend{
+		name: "devices",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	var first, second domain.Tank
+	for _, tank := range []*domain.Tank{&first, &second} {
+		server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+			"name": "Tanque de Equipo", "capacity": 1000.0, "current_level": 900.0, "alert_threshold": 10.0,
+		}, tank)
+	}
+
+	post := func(profile, token, payload string) (int, handlers.DeviceIngestResult) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/ingest/devices/"+profile, strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Webhook-Token", token)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Error al enviar la carga: %v", err)
+		}
+		defer resp.Body.Close()
+		var result handlers.DeviceIngestResult
+		json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result
+	}
+
+	status, result := post("ultrasonico", "secreto", fmt.Sprintf(`{"tank_id": %q, "level": 700}`, first.ID))
+	if status != http.StatusOK || result.Version != 1 || result.Accepted != 1 {
+		t.Fatalf("La carga v1 debería aceptarse: %d %+v", status, result)
+	}
+
+	status, result = post("ultrasonico", "secreto", fmt.Sprintf(`{"version": 2, "device_id": "gw-7", "sensors": [
+		{"tank_id": %q, "level": 650},
+		{"tank_id": %q, "level": 300}
+	]}`, first.ID, second.ID))
+	if status != http.StatusOK || result.Version != 2 || result.Accepted != 2 {
+		t.Fatalf("La carga v2 debería aceptarse: %d %+v", status, result)
+	}
+
+	var stored domain.Tank
+	server.do(t, http.MethodGet, "/api/tanks/"+second.ID, nil, &stored)
+	if stored.CurrentLevel != 300 {
+		t.Errorf("Nivel incorrecto. Esperado: 300, Obtenido: %.2f", stored.CurrentLevel)
+	}
+
+	status, result = post("ultrasonico", "secreto", fmt.Sprintf(`{"version": 2, "device_id": "gw-7", "sensors": [{"tank_id": %q, "level": 5000}]}`, first.ID))
+	if status != http.StatusBadRequest || len(result.Violations) != 1 || result.Violations[0] != "/sensors/0/level: must be <= 1000" {
+		t.Errorf("Se esperaba 400 con la infracción del esquema del perfil: %d %+v", status, result)
+	}
+	if status, _ := post("ultrasonico", "secreto", `{"version": 3}`); status != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 con una versión no admitida, se obtuvo: %d", status)
+	}
+	if status, _ := post("ultrasonico", "otro", `{}`); status != http.StatusUnauthorized {
+		t.Errorf("Se esperaba 401 con un token inválido, se obtuvo: %d", status)
+	}
+	if status, _ := post("desconocido", "secreto", `{}`); status != http.StatusNotFound {
+		t.Errorf("Se esperaba 404 para un perfil desconocido, se obtuvo: %d", status)
+	}
+}
+
+func TestAPI_SigfoxCallbacks(t *testing.T) {
+	devicesFile := filepath.Join(t.TempDir(), "sigfox.yaml")
+	devices := "token: secreto\ndevices:\n  - {id: 1A2B3C, tank_id: tanque-sigfox, type: ultrasonic, empty_distance: 2000, liters_per_mm: 5}\n"
+	if err := os.WriteFile(devicesFile, []byte(devices), 0o600); err != nil {
+		t.Fatalf("Error al escribir los equipos: %v", err)
+	}
+	config := api.DefaultConfig()
+	config.SigfoxDevicesFile = devicesFile
+	server := newTestServer(t, backend{
+		name: "sigfox",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"id":              "tanque-sigfox",
+		"name":            "Tanque Sigfox",
+		"capacity":        10000.0,
+		"current_level":   9000.0,
+		"alert_threshold": 10.0,
+	}, nil)
+
+	post := func(token, payload string) (int, handlers.SigfoxCallbackResult) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/ingest/sigfox", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Error al enviar el callback: %v", err)
+		}
+		defer resp.Body.Close()
+		var result handlers.SigfoxCallbackResult
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("Error al decodificar la respuesta: %v", err)
+			}
+		}
+		return resp.StatusCode, result
+	}
+
+	// 0x01F4 = 500 mm hasta el líquido: (2000 - 500) * 5 = 7500 L
+	frame := `{"device": "1a2b3c", "time": 1714557600, "data": "01f414a0", "seqNumber": 7, "rssi": "-118.00"}`
+	status, result := post("secreto", frame)
+	if status != http.StatusOK || result.Status != "accepted" || result.TankID != "tanque-sigfox" {
+		t.Fatalf("Callback no aceptado (%d): %+v", status, result)
+	}
+
+	// La misma trama recibida por otra estación base no se guarda de nuevo
+	if _, again := post("secreto", frame); again.Status != "duplicate" {
+		t.Errorf("Se esperaba un duplicado, se obtuvo: %+v", again)
+	}
+	if _, flagged := post("secreto", `{"device": "1A2B3C", "data": "000014a0", "seqNumber": 8, "duplicate": true}`); flagged.Status != "duplicate" {
+		t.Errorf("Se esperaba un duplicado por la marca de Sigfox, se obtuvo: %+v", flagged)
+	}
+
+	var measurements []domain.Measurement
+	server.do(t, http.MethodGet, "/api/tanks/tanque-sigfox/measurements", nil, &measurements)
+	if len(measurements) != 1 || measurements[0].Level != 7500 || measurements[0].SensorID != "1A2B3C" {
+		t.Errorf("Mediciones incorrectas: %+v", measurements)
+	}
+
+	if status, _ := post("otro", frame); status != http.StatusUnauthorized {
+		t.Errorf("Se esperaba 401 con un token inválido, se obtuvo: %d", status)
+	}
+	if status, _ := post("secreto", `{"device": "FFFF", "data": "01f414a0", "seqNumber": 1}`); status != http.StatusNotFound {
+		t.Errorf("Se esperaba 404 para un equipo desconocido, se obtuvo: %d", status)
+	}
+	if status, _ := post("secreto", `{"device": "1A2B3C", "data": "01", "seqNumber": 9}`); status != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 con una trama inválida, se obtuvo: %d", status)
+	}
+}
+
+func TestAPI_DataQualityReport(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"id":       "tq-calidad",
+				"name":     "Tanque sin lecturas",
+				"capacity": 1000.0,
+			}, nil)
+
+			// Sin informe programado todavía, se calcula al consultarlo
+			var report domain.DataQualityReport
+			if status := server.do(t, http.MethodGet, "/api/reports/data-quality", nil, &report); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado: %d", status)
+			}
+			if report.Summary.Tanks != 1 || report.Summary.TanksWithIssues != 1 || len(report.Tanks) != 1 {
+				t.Fatalf("Resumen inesperado: %+v", report.Summary)
+			}
+			if report.Tanks[0].TankID != "tq-calidad" || report.Tanks[0].Status != domain.DataQualityNoData {
+				t.Errorf("Se esperaba el tanque sin datos, se obtuvo %+v", report.Tanks[0])
+			}
+
+			// Un periodo invertido es una petición inválida
+			path := "/api/reports/data-quality?from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z"
+			if status := server.do(t, http.MethodGet, path, nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un periodo invertido, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_AlertMute(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"id":              "tq-silencio",
+				"name":            "Tanque en mantenimiento",
+				"capacity":        1000.0,
+				"current_level":   800.0,
+				"alert_threshold": 10.0,
+			}, nil)
+
+			// Silenciado, una medición por debajo del umbral no se notifica
+			var mute domain.AlertMute
+			status := server.do(t, http.MethodPost, "/api/tanks/tq-silencio/alerts/mute?duration=2h",
+				map[string]string{"reason": "Limpieza del tanque"}, &mute)
+			if status != http.StatusCreated || mute.Reason != "Limpieza del tanque" {
+				t.Fatalf("Silencio inesperado: %d %+v", status, mute)
+			}
+			server.do(t, http.MethodPost, "/api/tanks/tq-silencio/measurements",
+				map[string]interface{}{"level": 50.0, "temperature": 20.0}, nil)
+			if server.notifier.count() != 0 {
+				t.Fatalf("No se esperaban alertas durante el silencio, se enviaron %d", server.notifier.count())
+			}
+
+			// Al levantar el silencio las alertas vuelven a notificarse
+			if status := server.do(t, http.MethodDelete, "/api/tanks/tq-silencio/alerts/mute", nil, nil); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado al levantar el silencio: %d", status)
+			}
+			server.do(t, http.MethodPost, "/api/tanks/tq-silencio/measurements",
+				map[string]interface{}{"level": 40.0, "temperature": 20.0}, nil)
+			if server.notifier.count() != 1 {
+				t.Errorf("Se esperaba 1 alerta tras levantar el silencio, se enviaron %d", server.notifier.count())
+			}
+
+			var mutes []domain.AlertMute
+			server.do(t, http.MethodGet, "/api/tanks/tq-silencio/alerts/mutes", nil, &mutes)
+			if len(mutes) != 1 || mutes[0].UnmutedAt == nil {
+				t.Errorf("El historial debe registrar el silencio levantado: %+v", mutes)
+			}
+
+			// Duraciones inválidas y reactivar sin silencio vigente
+			if status := server.do(t, http.MethodPost, "/api/tanks/tq-silencio/alerts/mute?duration=30d", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con una duración inválida, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodPost, "/api/tanks/tq-silencio/alerts/mute?duration=720h", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con una duración excesiva, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodDelete, "/api/tanks/tq-silencio/alerts/mute", nil, nil); status != http.StatusConflict {
+				t.Errorf("Se esperaba 409 sin silencio vigente, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_TankLabels(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			for id, labels := range map[string]map[string]string{
+				"tq-caribe-acme": {"region": "caribe", "customer": "acme"},
+				"tq-caribe":      {"region": "caribe"},
+				"tq-andina":      {"region": "andina", "customer": "acme"},
+			} {
+				status := server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+					"id": id, "name": id, "capacity": 1000.0, "labels": labels,
+				}, nil)
+				if status != http.StatusCreated {
+					t.Fatalf("Código de estado inesperado al crear %s: %d", id, status)
+				}
+			}
+
+			var tanks []domain.Tank
+			server.do(t, http.MethodGet, "/api/tanks?labels="+url.QueryEscape("region=caribe,customer=acme"), nil, &tanks)
+			if len(tanks) != 1 || tanks[0].ID != "tq-caribe-acme" {
+				t.Errorf("Se esperaba solo tq-caribe-acme, se obtuvo %+v", tanks)
+			}
+
+			server.do(t, http.MethodGet, "/api/tanks?labels="+url.QueryEscape("region in (caribe,andina),!customer"), nil, &tanks)
+			if len(tanks) != 1 || tanks[0].ID != "tq-caribe" {
+				t.Errorf("Se esperaba solo tq-caribe, se obtuvo %+v", tanks)
+			}
+
+			var report domain.DataQualityReport
+			server.do(t, http.MethodGet, "/api/reports/data-quality?labels=customer%3Dacme", nil, &report)
+			if report.Summary.Tanks != 2 {
+				t.Errorf("El informe debía limitarse a los 2 tanques de acme, se obtuvo %d", report.Summary.Tanks)
+			}
+
+			if status := server.do(t, http.MethodGet, "/api/tanks?labels="+url.QueryEscape("region in (caribe"), nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un selector inválido, se obtuvo %d", status)
+			}
+			status := server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name": "Etiqueta inválida", "capacity": 1000.0, "labels": map[string]string{"región": "caribe"},
+			}, nil)
+			if status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con una etiqueta inválida, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_GroupCapacityPlan(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"id": "tq-plan", "name": "Diésel costa", "group_id": "caribe", "capacity": 1000.0,
+				"liquid_type": "diesel", "reorder": map[string]float64{"reorder_level": 300},
+			}, nil)
+			server.do(t, http.MethodPost, "/api/tanks/tq-plan/measurements",
+				map[string]interface{}{"level": 250.0, "temperature": 20.0}, nil)
+
+			var plan domain.CapacityPlan
+			if status := server.do(t, http.MethodGet, "/api/groups/caribe/capacity-plan?days=3", nil, &plan); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado: %d", status)
+			}
+			// Sin historial de consumo, basta con volver al punto de pedido
+			if plan.Tanks != 1 || plan.HorizonDays != 3 || plan.RequiredDelivery != 50 {
+				t.Errorf("Plan inesperado: %+v", plan)
+			}
+
+			if status := server.do(t, http.MethodGet, "/api/groups/patagonia/capacity-plan", nil, nil); status != http.StatusNotFound {
+				t.Errorf("Se esperaba 404 para un grupo sin tanques, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodGet, "/api/groups/caribe/capacity-plan?days=0", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con days=0, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_ERPInventorySync(t *testing.T) {
+	dropDir := t.TempDir()
+	erpFile := filepath.Join(t.TempDir(), "erp.yaml")
+	connectors := "connectors:\n  - {name: contabilidad, type: file, dir: '" + dropDir + "', interval: 24h}\n"
+	if err := os.WriteFile(erpFile, []byte(connectors), 0o600); err != nil {
+		t.Fatalf("Error al escribir las conexiones: %v", err)
+	}
+	config := api.DefaultConfig()
+	config.ERPFile = erpFile
+	server := newTestServer(t, backend{
+		name: "erp",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name":            "Tanque Contable",
+		"capacity":        1000.0,
+		"current_level":   400.0,
+		"alert_threshold": 10.0,
+	}, nil)
+
+	var result domain.InventorySync
+	if status := server.do(t, http.MethodPost, "/api/admin/erp/contabilidad/sync", nil, &result); status != http.StatusOK {
+		t.Fatalf("Código inesperado al sincronizar: %d", status)
+	}
+	if result.Status != domain.InventorySyncDelivered || result.Tanks != 1 || result.Confirmation == "" {
+		t.Errorf("Resultado incorrecto: %+v", result)
+	}
+	if entries, _ := os.ReadDir(dropDir); len(entries) != 1 {
+		t.Errorf("Se esperaba 1 archivo en el directorio de intercambio, hay %d", len(entries))
+	}
+
+	var syncs []domain.InventorySync
+	server.do(t, http.MethodGet, "/api/admin/erp/contabilidad/syncs", nil, &syncs)
+	if len(syncs) != 1 || syncs[0].BatchID != result.BatchID {
+		t.Errorf("Historial incorrecto: %+v", syncs)
+	}
+	if status := server.do(t, http.MethodPost, "/api/admin/erp/desconocida/sync", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Se esperaba 404 para una conexión desconocida, se obtuvo: %d", status)
+	}
+}
+
+func TestAPI_UsageMetering(t *testing.T) {
+	config := api.DefaultConfig()
+	config.UsageMeteringEnabled = true
+	server := newTestServer(t, backend{
+		name: "usage",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	var tank domain.Tank
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name":            "Tanque Acme",
+		"capacity":        1000.0,
+		"current_level":   900.0,
+		"alert_threshold": 10.0,
+		"labels":          map[string]string{"organization": "acme"},
+	}, &tank)
+	for _, level := range []float64{880, 860} {
+		server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": level, "temperature": 20.0}, nil)
+	}
+
+	var usage []domain.OrganizationUsage
+	if status := server.do(t, http.MethodGet, "/api/admin/usage", nil, &usage); status != http.StatusOK {
+		t.Fatalf("Código inesperado al obtener el uso: %d", status)
+	}
+	byOrganization := make(map[string]domain.OrganizationUsage)
+	for _, entry := range usage {
+		byOrganization[entry.Organization] = entry
+	}
+	if acme := byOrganization["acme"]; acme.Measurements != 2 || acme.ActiveTanks != 1 {
+		t.Errorf("Uso de acme incorrecto: %+v", acme)
+	}
+	// Sin autenticación las solicitudes no tienen organización
+	if unassigned := byOrganization[domain.UnassignedOrganization]; unassigned.APICalls < 4 {
+		t.Errorf("Solicitudes sin atribuir incorrectas: %+v", unassigned)
+	}
+
+	resp, err := server.Client().Get(server.URL + "/api/admin/usage/export?month=" + usage[0].Month)
+	if err != nil {
+		t.Fatalf("Error al exportar el uso: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/csv") {
+		t.Fatalf("Exportación incorrecta (%d, %s)", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if !strings.HasPrefix(string(body), "month,organization,api_calls,active_tanks,measurements\n") || !strings.Contains(string(body), ",acme,") {
+		t.Errorf("CSV incorrecto:\n%s", body)
+	}
+	if status := server.do(t, http.MethodGet, "/api/admin/usage?month=octubre", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 con un mes inválido, se obtuvo: %d", status)
+	}
+}
+
+func TestAPI_PublicStatusPage(t *testing.T) {
+	config := api.DefaultConfig()
+	config.PublicStatusEnabled = true
+	server := newTestServer(t, backend{
+		name: "public-status",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	var tank domain.Tank
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name":            "Tanque <Norte>",
+		"capacity":        1000.0,
+		"current_level":   250.0,
+		"alert_threshold": 10.0,
+		"site_id":         "estacion-secreta",
+	}, &tank)
+
+	var share domain.StatusShare
+	if status := server.do(t, http.MethodPost, "/api/admin/status-shares", map[string]interface{}{
+		"name":     "Cliente Acme",
+		"tank_ids": []string{tank.ID},
+	}, &share); status != http.StatusCreated {
+		t.Fatalf("Código inesperado al crear la página: %d", status)
+	}
+	if share.Token == "" {
+		t.Fatal("La respuesta de creación debería incluir el token")
+	}
+
+	var page domain.PublicStatusPage
+	if status := server.do(t, http.MethodGet, "/public/"+share.Token, nil, &page); status != http.StatusOK {
+		t.Fatalf("Código inesperado al consultar la página: %d", status)
+	}
+	if len(page.Tanks) != 1 || page.Tanks[0].Percentage != 25 {
+		t.Errorf("Página incorrecta: %+v", page)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/public/"+share.Token, nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Error al consultar la página HTML: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	html := string(body)
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") || !strings.Contains(html, "Tanque &lt;Norte&gt;") || !strings.Contains(html, "25.0%") {
+		t.Errorf("Página HTML incorrecta (%s):\n%s", resp.Header.Get("Content-Type"), html)
+	}
+	if strings.Contains(html, "estacion-secreta") || strings.Contains(html, tank.ID) {
+		t.Error("La página pública no debería mostrar el sitio ni el ID del tanque")
+	}
+
+	var shares []domain.StatusShare
+	server.do(t, http.MethodGet, "/api/admin/status-shares", nil, &shares)
+	if len(shares) != 1 || shares[0].Token != "" {
+		t.Errorf("El listado no debería incluir los tokens: %+v", shares)
+	}
+	server.do(t, http.MethodDelete, "/api/admin/status-shares/"+share.ID, nil, nil)
+	if status := server.do(t, http.MethodGet, "/public/"+share.Token, nil, nil); status != http.StatusNotFound {
+		t.Errorf("Se esperaba 404 tras revocar la página, se obtuvo: %d", status)
+	}
+}
+
+func TestAPI_SignedExportURLs(t *testing.T) {
+	config := api.DefaultConfig()
+	config.SignedURLSecret = "clave-de-prueba"
+	server := newTestServer(t, backend{
+		name: "signed-urls",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	var tank domain.Tank
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name":            "Tanque Exportado",
+		"capacity":        1000.0,
+		"current_level":   500.0,
+		"alert_threshold": 10.0,
+	}, &tank)
+	server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": 400.0}, nil)
+
+	for _, path := range []string{"/api/tanks/" + tank.ID + "/measurements?format=ndjson", "/api/v1/tanks/" + tank.ID + "/measurements?format=ndjson"} {
+		var signed domain.SignedURL
+		if status := server.do(t, http.MethodPost, "/api/signed-urls", map[string]interface{}{"path": path, "ttl": "10m"}, &signed); status != http.StatusCreated {
+			t.Fatalf("Código inesperado al firmar %s: %d", path, status)
+		}
+
+		resp, err := server.Client().Get(server.URL + signed.URL)
+		if err != nil {
+			t.Fatalf("Error al descargar el enlace firmado: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"level":400`) {
+			t.Errorf("Descarga incorrecta de %s (%d): %s", signed.URL, resp.StatusCode, body)
+		}
+
+		tampered := strings.Replace(signed.URL, "format=ndjson", "format=json", 1)
+		if status := server.do(t, http.MethodGet, tampered, nil, nil); status != http.StatusForbidden {
+			t.Errorf("Se esperaba 403 con un enlace manipulado, se obtuvo: %d", status)
+		}
+	}
+
+	if status := server.do(t, http.MethodPost, "/api/signed-urls", map[string]interface{}{"path": "/api/tanks/" + tank.ID}, nil); status != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 al firmar una ruta que no es una exportación, se obtuvo: %d", status)
+	}
+}
+
+func TestAPI_LiveEvents(t *testing.T) {
+	config := api.DefaultConfig()
+	server := newTestServer(t, backend{
+		name: "live",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	var watched, other domain.Tank
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name": "Tanque Vigilado", "capacity": 1000.0, "current_level": 500.0, "alert_threshold": 10.0, "site_id": "estacion-norte",
+	}, &watched)
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name": "Tanque Ajeno", "capacity": 1000.0, "current_level": 500.0, "alert_threshold": 10.0, "site_id": "estacion-sur",
+	}, &other)
+
+	if status := server.do(t, http.MethodGet, "/api/live", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 sin handshake de WebSocket, se obtuvo: %d", status)
+	}
+
+	conn, reader := dialWebSocket(t, server.URL, "/api/v1/live")
+
+	writeWebSocketText(t, conn, `{"action":"subscribe","topics":["site:estacion-norte","group:norte"]}`)
+	var reply map[string]interface{}
+	json.Unmarshal(readWebSocketText(t, conn, reader), &reply)
+	if reply["type"] != "error" {
+		t.Errorf("Se esperaba un error con un tema desconocido: %v", reply)
+	}
+
+	writeWebSocketText(t, conn, `{"action":"subscribe","topics":["site:estacion-norte","severity:critical"]}`)
+	json.Unmarshal(readWebSocketText(t, conn, reader), &reply)
+	if reply["type"] != "subscribed" || len(reply["topics"].([]interface{})) != 2 {
+		t.Fatalf("Respuesta de suscripción incorrecta: %v", reply)
+	}
+
+	server.do(t, http.MethodPost, "/api/tanks/"+other.ID+"/measurements", map[string]interface{}{"level": 450.0}, nil)
+	server.do(t, http.MethodPost, "/api/tanks/"+watched.ID+"/measurements", map[string]interface{}{"level": 400.0}, nil)
+
+	var event domain.LiveEvent
+	json.Unmarshal(readWebSocketText(t, conn, reader), &event)
+	if event.Type != domain.LiveEventMeasurement || event.TankID != watched.ID || event.SiteID != "estacion-norte" {
+		t.Errorf("Se esperaba solo la medición del tanque del sitio suscrito: %+v", event)
+	}
+
+	server.do(t, http.MethodPost, "/api/tanks/"+other.ID+"/measurements", map[string]interface{}{"level": 50.0}, nil)
+	json.Unmarshal(readWebSocketText(t, conn, reader), &event)
+	if event.Type != domain.LiveEventAlert || event.TankID != other.ID || event.Severity != domain.AlertSeverityCritical {
+		t.Errorf("Se esperaba la alerta crítica de cualquier tanque: %+v", event)
+	}
+}
+
+func TestAPI_LiveEventsSSE(t *testing.T) {
+	server := newTestServer(t, backend{
+		name: "live-sse",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(api.DefaultConfig(), nopLogger{})
+		},
+	})
+
+	var watched, other domain.Tank
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name": "Tanque Vigilado", "capacity": 1000.0, "current_level": 500.0, "alert_threshold": 10.0,
+	}, &watched)
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name": "Tanque Ajeno", "capacity": 1000.0, "current_level": 500.0, "alert_threshold": 10.0,
+	}, &other)
+
+	if status := server.do(t, http.MethodGet, "/api/live/events", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 sin temas, se obtuvo: %d", status)
+	}
+	if status := server.do(t, http.MethodGet, "/api/live/events?topics=group:norte", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 con un tema desconocido, se obtuvo: %d", status)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/live/events?topics=tank:"+watched.ID, nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Error al conectar: %v", err)
+	}
+	defer response.Body.Close()
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Content-Type inesperado: %q", contentType)
+	}
+	reader := bufio.NewReader(response.Body)
+
+	if eventType, _ := readServerSentEvent(t, reader); eventType != "subscribed" {
+		t.Fatalf("Se esperaba la confirmación de la suscripción, se obtuvo: %q", eventType)
+	}
+
+	server.do(t, http.MethodPost, "/api/tanks/"+other.ID+"/measurements", map[string]interface{}{"level": 450.0}, nil)
+	server.do(t, http.MethodPost, "/api/tanks/"+watched.ID+"/measurements", map[string]interface{}{"level": 400.0}, nil)
+
+	eventType, data := readServerSentEvent(t, reader)
+	var event domain.LiveEvent
+	json.Unmarshal([]byte(data), &event)
+	if eventType != domain.LiveEventMeasurement || event.TankID != watched.ID {
+		t.Errorf("Se esperaba solo la medición del tanque suscrito: %s %+v", eventType, event)
+	}
+}
+
+// readServerSentEvent lee el siguiente evento SSE, omitiendo los comentarios de keepalive
+func readServerSentEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+
+	var eventType, data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Error al leer el evento: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && eventType != "":
+			return eventType, data
+		case strings.HasPrefix(line, "event: "):
+			eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// dialWebSocket abre una conexión WebSocket con el servidor de pruebas
+func dialWebSocket(t *testing.T, serverURL, path string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(serverURL, "http://"))
+	if err != nil {
+		t.Fatalf("Error al conectar: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\nAccept-Encoding: gzip\r\n\r\n", path)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("Error al leer el handshake: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Handshake incorrecto: %d %v", resp.StatusCode, resp.Header)
+	}
+	return conn, reader
+}
+
+// writeWebSocketText envía un mensaje de texto enmascarado, como los clientes
+func writeWebSocketText(t *testing.T, conn net.Conn, message string) {
+	t.Helper()
+
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x81, 0x80 | byte(len(message))}
+	if len(message) > 125 {
+		t.Fatal("El mensaje de prueba es demasiado largo")
+	}
+	frame = append(frame, mask[:]...)
+	for i := 0; i < len(message); i++ {
+		frame = append(frame, message[i]^mask[i%4])
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatalf("Error al enviar el mensaje: %v", err)
+	}
+}
+
+// readWebSocketText lee el siguiente mensaje de texto sin fragmentar, descartando los ping
+func readWebSocketText(t *testing.T, conn net.Conn, reader *bufio.Reader) []byte {
+	t.Helper()
+
+	for {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			t.Fatalf("Error al leer la trama: %v", err)
+		}
+		length := int(header[1] & 0x7F)
+		switch length {
+		case 126:
+			extended := make([]byte, 2)
+			io.ReadFull(reader, extended)
+			length = int(binary.BigEndian.Uint16(extended))
+		case 127:
+			extended := make([]byte, 8)
+			io.ReadFull(reader, extended)
+			length = int(binary.BigEndian.Uint64(extended))
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			t.Fatalf("Error al leer el mensaje: %v", err)
+		}
+		if header[0]&0x0F == 0x1 {
+			return payload
+		}
+	}
+}
+
+func TestAPI_BackfillMeasurements(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Migrado",
+				"capacity":        1000.0,
+				"current_level":   500.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": 500.0}, nil)
+
+			start := time.Now().Add(-48 * time.Hour).UTC()
+			body := map[string]interface{}{
+				"measurements": []map[string]interface{}{
+					{"level": 900.0, "timestamp": start},
+					{"level": 20.0, "timestamp": start.Add(time.Hour)},
+				},
+			}
+
+			var result domain.BackfillResult
+			if status := server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements/backfill", body, &result); status != http.StatusOK {
+				t.Fatalf("Código inesperado al importar: %d", status)
+			}
+			if result.Imported != 2 || result.CurrentLevelUpdated || !result.From.Equal(start) {
+				t.Errorf("Resultado incorrecto: %+v", result)
+			}
+
+			var current domain.Tank
+			server.do(t, http.MethodGet, "/api/tanks/"+tank.ID, nil, &current)
+			if current.CurrentLevel != 500 || current.Status != "normal" {
+				t.Errorf("La importación no debería cambiar el nivel actual: %.0f (%s)", current.CurrentLevel, current.Status)
+			}
+
+			var history []domain.Measurement
+			server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements", nil, &history)
+			if len(history) != 3 {
+				t.Errorf("Se esperaban 3 mediciones en el historial, se obtuvieron %d", len(history))
+			}
+
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements/backfill", body, &result)
+			if result.Imported != 0 || result.Duplicates != 2 {
+				t.Errorf("Repetir la importación no debería duplicar mediciones: %+v", result)
+			}
+
+			future := map[string]interface{}{"measurements": []map[string]interface{}{{"level": 100.0, "timestamp": time.Now().Add(time.Hour)}}}
+			if status := server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements/backfill", future, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con una medición futura, se obtuvo: %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_RecomputeDerivedData(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Recalculado",
+				"capacity":        1000.0,
+				"current_level":   500.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": 500.0}, nil)
+
+			start := time.Now().Add(-48 * time.Hour).UTC()
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements/backfill", map[string]interface{}{
+				"measurements": []map[string]interface{}{
+					{"level": 50.0, "timestamp": start},
+					{"level": 700.0, "timestamp": start.Add(time.Hour)},
+				},
+			}, nil)
+
+			var results []domain.RecomputeResult
+			path := "/api/admin/recompute?tank=" + tank.ID
+			if status := server.do(t, http.MethodPost, path, nil, &results); status != http.StatusOK {
+				t.Fatalf("Código inesperado al recalcular: %d", status)
+			}
+			if len(results) != 1 || results[0].Measurements != 3 || results[0].StatusChanges != 2 || results[0].Level != 500 {
+				t.Fatalf("Resultado incorrecto: %+v", results)
+			}
+
+			var history domain.StatusHistory
+			server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/status-history", nil, &history)
+			if len(history.Changes) != 2 || history.Changes[0].ToStatus != "critical" || history.TimeInStatus["critical"] != 3600 {
+				t.Errorf("El historial de estados debería incluir el periodo importado: %+v", history.Changes)
+			}
+
+			if status := server.do(t, http.MethodPost, "/api/admin/recompute?from=ayer", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un from inválido, se obtuvo: %d", status)
+			}
+			if status := server.do(t, http.MethodPost, "/api/admin/recompute?tank=no-existe", nil, nil); status != http.StatusNotFound {
+				t.Errorf("Se esperaba 404 con un tanque desconocido, se obtuvo: %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_LiquidPolicies(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var policy domain.LiquidPolicy
+			status := server.do(t, http.MethodPut, "/api/admin/liquid-policies/Ácido", map[string]interface{}{
+				"min_safety_stock": 20.0,
+				"max_fill":         85.0,
+				"hazmat_contacts":  []string{"seguridad@example.com"},
+			}, &policy)
+			if status != http.StatusOK || policy.LiquidType != "Ácido" || policy.MinSafetyStock != 20 {
+				t.Fatalf("Política inesperada: %d %+v", status, policy)
+			}
+
+			// El tanque nuevo sin umbral toma el stock de seguridad de la política
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":          "Tanque de ácido",
+				"capacity":      1000.0,
+				"current_level": 600.0,
+				"liquid_type":   "ácido",
+			}, &tank)
+			if tank.AlertThreshold != 20 {
+				t.Errorf("Se esperaba el umbral de la política, se obtuvo %.2f", tank.AlertThreshold)
+			}
+
+			// Los umbrales que incumplen la política se rechazan
+			tank.AlertThreshold = 5
+			if status := server.do(t, http.MethodPut, "/api/tanks/"+tank.ID, tank, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un umbral bajo el stock de seguridad, se obtuvo %d", status)
+			}
+			tank.AlertThreshold = 25
+			tank.Reorder = domain.ReorderConfig{ReorderLevel: 400, DeliverySize: 500}
+			if status := server.do(t, http.MethodPut, "/api/tanks/"+tank.ID, tank, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un pedido que supera el llenado máximo, se obtuvo %d", status)
+			}
+			tank.Reorder.DeliverySize = 400
+			if status := server.do(t, http.MethodPut, "/api/tanks/"+tank.ID, tank, nil); status != http.StatusOK {
+				t.Errorf("Se esperaba 200 con umbrales dentro de la política, se obtuvo %d", status)
+			}
+
+			var policies []domain.LiquidPolicy
+			server.do(t, http.MethodGet, "/api/admin/liquid-policies", nil, &policies)
+			if len(policies) != 1 || len(policies[0].HazmatContacts) != 1 {
+				t.Errorf("Listado de políticas incorrecto: %+v", policies)
+			}
+
+			if status := server.do(t, http.MethodPut, "/api/admin/liquid-policies/Diésel", map[string]interface{}{
+				"min_safety_stock": 90.0, "max_fill": 80.0,
+			}, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con una política incoherente, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodDelete, "/api/admin/liquid-policies/ácido", nil, nil); status != http.StatusNoContent {
+				t.Errorf("Código inesperado al eliminar la política: %d", status)
+			}
+			if status := server.do(t, http.MethodGet, "/api/admin/liquid-policies/Ácido", nil, nil); status != http.StatusNotFound {
+				t.Errorf("Se esperaba 404 tras eliminar la política, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_ShiftReport(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"id":              "tq-turno",
+				"name":            "Tanque del turno",
+				"capacity":        1000.0,
+				"current_level":   800.0,
+				"alert_threshold": 10.0,
+			}, nil)
+			server.do(t, http.MethodPost, "/api/tanks/tq-turno/measurements", map[string]interface{}{"level": 800.0}, nil)
+			server.do(t, http.MethodPost, "/api/tanks/tq-turno/measurements", map[string]interface{}{"level": 50.0}, nil)
+
+			var report domain.ShiftReport
+			if status := server.do(t, http.MethodGet, "/api/reports/shift", nil, &report); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado: %d", status)
+			}
+			if report.Summary.AlertsRaised != 1 || report.Summary.TanksCritical != 1 || len(report.Attention) != 1 {
+				t.Errorf("Informe de relevo incorrecto: %+v", report.Summary)
+			}
+
+			resp, err := server.Client().Get(server.URL + "/api/reports/shift?format=text")
+			if err != nil {
+				t.Fatalf("Error al pedir el informe en texto: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") || !strings.Contains(string(body), "[critical] Tanque del turno") {
+				t.Errorf("Texto de relevo inesperado (%s):\n%s", resp.Header.Get("Content-Type"), body)
+			}
+
+			if status := server.do(t, http.MethodGet, "/api/reports/shift?format=pdf", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un formato desconocido, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodGet, "/api/reports/shift?from=2026-01-02T00:00:00Z&to=2026-01-01T00:00:00Z", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un periodo invertido, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_AdminCommands(t *testing.T) {
+	ctx := context.Background()
+	config := api.DefaultConfig()
+	config.MemorySnapshotPath = filepath.Join(t.TempDir(), "snapshot.gob")
+
+	// Sin instantánea los comandos no guardarían nada
+	if err := api.NewAPI(api.DefaultConfig(), nopLogger{}).CreateAdmin(ctx, "ops", io.Discard); err == nil {
+		t.Error("Se esperaba un error al crear un administrador en repositorios volátiles")
+	}
+
+	var output bytes.Buffer
+	if err := api.NewAPI(config, nopLogger{}).Migrate(ctx, &output); err != nil {
+		t.Fatalf("Error inesperado al migrar: %v", err)
+	}
+	if err := api.NewAPI(config, nopLogger{}).CreateAdmin(ctx, "ops", &output); err != nil {
+		t.Fatalf("Error inesperado al crear el administrador: %v", err)
+	}
+	if err := api.NewAPI(config, nopLogger{}).Seed(ctx, "", &output); err != nil {
+		t.Fatalf("Error inesperado al cargar la semilla: %v", err)
+	}
+
+	var token string
+	for _, line := range strings.Split(output.String(), "\n") {
+		if strings.HasPrefix(line, domain.APITokenPrefix) {
+			token = line
+		}
+	}
+	if token == "" {
+		t.Fatalf("El comando no mostró el token:\n%s", output.String())
+	}
+
+	// El servidor restaura la instantánea y acepta el token emitido
+	config.AuthMode = "token"
+	app := api.NewAPI(config, nopLogger{})
+	app.SetupRoutes()
+	server := httptest.NewServer(app.Handler())
+	t.Cleanup(server.Close)
+
+	get := func(bearer string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/admin/liquid-policies", nil)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Error al ejecutar la petición: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := get(""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Se esperaba 401 sin token, se obtuvo %d", resp.StatusCode)
+	}
+	if resp := get(domain.APITokenPrefix + "desconocido"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Se esperaba 401 con un token desconocido, se obtuvo %d", resp.StatusCode)
+	}
+	if resp := get(token); resp.StatusCode != http.StatusOK {
+		t.Errorf("Se esperaba 200 con el token de administración, se obtuvo %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/tanks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Error al listar los tanques: %v", err)
+	}
+	defer resp.Body.Close()
+	var tanks []domain.Tank
+	json.NewDecoder(resp.Body).Decode(&tanks)
+	if len(tanks) != 3 {
+		t.Errorf("Se esperaban los 3 tanques de la semilla, se obtuvieron %d", len(tanks))
+	}
+}
+
+func TestAPI_RuntimeConfigReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runtime.yaml")
+	if err := os.WriteFile(path, []byte("default_alert_threshold: 15\n"), 0o600); err != nil {
+		t.Fatalf("Error al escribir el archivo de configuración: %v", err)
+	}
+
+	config := api.DefaultConfig()
+	config.RuntimeConfigFile = path
+	server := newTestServer(t, backend{
+		name: "runtime-config",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+	createTank := func() domain.Tank {
+		var tank domain.Tank
+		server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+			"name":          "Tanque sin umbral",
+			"capacity":      1000.0,
+			"current_level": 600.0,
+		}, &tank)
+		return tank
+	}
+
+	// El archivo se aplica al arrancar sobre las variables de entorno
+	if tank := createTank(); tank.AlertThreshold != 15 {
+		t.Errorf("Se esperaba el umbral del archivo, se obtuvo %.2f", tank.AlertThreshold)
+	}
+
+	// Recargar aplica los cambios del archivo sin reiniciar el servidor
+	os.WriteFile(path, []byte("log_level: warn\ndefault_alert_threshold: 25\nalert_notifier: webhook\nalert_notifier_target: http://127.0.0.1:1/hook\n"), 0o600)
+	var settings map[string]interface{}
+	if status := server.do(t, http.MethodPost, "/api/admin/config/reload", nil, &settings); status != http.StatusOK {
+		t.Fatalf("Código inesperado al recargar: %d", status)
+	}
+	if settings["log_level"] != "warn" || settings["alert_notifier"] != "webhook" || settings["reloaded_at"] == nil {
+		t.Errorf("Configuración recargada incorrecta: %v", settings)
+	}
+	if _, ok := settings["alert_notifier_target"]; ok {
+		t.Error("La URL del notificador no debe exponerse")
+	}
+	if tank := createTank(); tank.AlertThreshold != 25 {
+		t.Errorf("Se esperaba el umbral recargado, se obtuvo %.2f", tank.AlertThreshold)
+	}
+
+	// Un archivo no válido se rechaza y sigue en vigor la configuración anterior
+	os.WriteFile(path, []byte("default_alert_threshold: -5\n"), 0o600)
+	if status := server.do(t, http.MethodPost, "/api/admin/config/reload", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 con un archivo no válido, se obtuvo %d", status)
+	}
+	server.do(t, http.MethodGet, "/api/admin/config", nil, &settings)
+	if settings["default_alert_threshold"] != 25.0 {
+		t.Errorf("Debe seguir en vigor la configuración anterior: %v", settings)
+	}
+
+	// Sin archivo no hay nada que recargar
+	withoutFile := newTestServer(t, backends()[0])
+	if status := withoutFile.do(t, http.MethodPost, "/api/admin/config/reload", nil, nil); status != http.StatusConflict {
+		t.Errorf("Se esperaba 409 sin archivo de configuración, se obtuvo %d", status)
+	}
+}
+
+func TestAPI_LogLevel(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var level map[string]string
+			server.do(t, http.MethodGet, "/api/admin/loglevel", nil, &level)
+			if level["level"] != "debug" {
+				t.Errorf("Se esperaba el nivel predeterminado debug, se obtuvo %v", level)
+			}
+
+			if status := server.do(t, http.MethodPut, "/api/admin/loglevel", map[string]string{"level": "warn"}, &level); status != http.StatusOK || level["level"] != "warn" {
+				t.Errorf("Respuesta inesperada al cambiar el nivel: %d %v", status, level)
+			}
+			if status := server.do(t, http.MethodPut, "/api/admin/loglevel", map[string]string{"level": "verbose"}, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un nivel desconocido, se obtuvo %d", status)
+			}
+
+			var settings map[string]interface{}
+			server.do(t, http.MethodGet, "/api/admin/config", nil, &settings)
+			if settings["log_level"] != "warn" {
+				t.Errorf("La configuración en vigor debe reflejar el nuevo nivel: %v", settings)
+			}
+		})
+	}
+}
+
+func TestAPI_TankStatusWebhook(t *testing.T) {
+	events := make(chan domain.StatusWebhookEvent, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event domain.StatusWebhookEvent
+		json.NewDecoder(r.Body).Decode(&event)
+		events <- event
+	}))
+	t.Cleanup(receiver.Close)
+
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			if status := server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name": "Tanque", "capacity": 1000.0, "status_webhook_url": "not-a-url",
+			}, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con una URL de webhook no válida, se obtuvo %d", status)
+			}
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":               "Tanque con webhook",
+				"capacity":           1000.0,
+				"current_level":      600.0,
+				"alert_threshold":    10.0,
+				"status_webhook_url": receiver.URL,
+			}, &tank)
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": 50.0}, nil)
+
+			select {
+			case event := <-events:
+				if event.TankID != tank.ID || event.OldStatus != "normal" || event.NewStatus != "critical" {
+					t.Errorf("Evento inesperado: %+v", event)
+				}
+				if event.Measurement == nil || event.Measurement.Level != 50 {
+					t.Errorf("El evento debe incluir la última medición: %+v", event.Measurement)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("El webhook del tanque no recibió el cambio de estado")
+			}
+		})
+	}
+}
+
+func TestAPI_AlertEvidence(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque con incidente",
+				"capacity":        1000.0,
+				"current_level":   600.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": 400.0}, nil)
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/notes", map[string]interface{}{"text": "Se revisó la válvula de salida"}, nil)
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": 50.0}, nil)
+
+			alertID := server.notifier.lastAlertID()
+			if alertID == "" {
+				t.Fatal("La alerta crítica debe llegar a los canales con su ID")
+			}
+
+			resp, err := http.Get(server.URL + "/api/alerts/" + alertID + "/evidence")
+			if err != nil {
+				t.Fatalf("Error al descargar la evidencia: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Disposition"), "attachment") {
+				t.Fatalf("Respuesta inesperada: %d %q", resp.StatusCode, resp.Header.Get("Content-Disposition"))
+			}
+
+			var evidence domain.AlertEvidence
+			json.NewDecoder(resp.Body).Decode(&evidence)
+			if evidence.AlertID != alertID || evidence.Tank == nil || evidence.Tank.AlertThreshold != 10 {
+				t.Errorf("Evidencia incorrecta: %+v", evidence)
+			}
+			if len(evidence.Measurements) != 2 || evidence.Measurements[1].Level != 50 {
+				t.Errorf("Se esperaban las 2 mediciones de las últimas 24 horas: %+v", evidence.Measurements)
+			}
+			if len(evidence.Notes) != 1 || evidence.Alert == nil || evidence.Alert.Severity != domain.AlertSeverityCritical {
+				t.Errorf("La evidencia debe incluir la nota y la alerta: %+v", evidence)
+			}
+
+			if status := server.do(t, http.MethodGet, "/api/alerts/desconocida/evidence", nil, nil); status != http.StatusNotFound {
+				t.Errorf("Se esperaba 404 para una alerta sin evidencia, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_MeasurementSeries(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque con huecos",
+				"capacity":        1000.0,
+				"current_level":   500.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+
+			start := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Hour)
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements/backfill", map[string]interface{}{
+				"measurements": []map[string]interface{}{
+					{"level": 900.0, "timestamp": start},
+					{"level": 300.0, "timestamp": start.Add(3 * time.Hour)},
+				},
+			}, nil)
+
+			period := "&from=" + start.Format(time.RFC3339) + "&to=" + start.Add(3*time.Hour).Format(time.RFC3339)
+			var series domain.MeasurementSeries
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements?step=1h&fill=linear"+period, nil, &series); status != http.StatusOK {
+				t.Fatalf("Código inesperado al pedir la serie: %d", status)
+			}
+			if series.Fill != domain.SeriesFillLinear || len(series.Points) != 4 {
+				t.Fatalf("Serie incorrecta: %+v", series)
+			}
+			for i, expected := range []float64{900, 700, 500, 300} {
+				point := series.Points[i]
+				if point.Level == nil || *point.Level != expected {
+					t.Errorf("Paso %d: se esperaba %.0f, se obtuvo %v", i, expected, point.Level)
+				}
+				if filled := i == 1 || i == 2; point.Filled != filled {
+					t.Errorf("Paso %d: filled debería ser %v", i, filled)
+				}
+			}
+
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements?step=1h&fill=spline", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un método de relleno desconocido, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements?step=una-hora", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un step inválido, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodGet, "/api/tanks/no-existe/measurements?step=1h", nil, nil); status == http.StatusOK {
+				t.Error("No se esperaba una serie para un tanque desconocido")
+			}
+		})
+	}
+}
+
+func TestAPI_TankStats(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Estadísticas",
+				"capacity":        1000.0,
+				"current_level":   500.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+
+			start := time.Now().Add(-10 * 24 * time.Hour).UTC()
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements/backfill", map[string]interface{}{
+				"measurements": []map[string]interface{}{
+					{"level": 200.0, "temperature": 12.0, "timestamp": start},
+					{"level": 800.0, "temperature": 25.0, "timestamp": start.Add(24 * time.Hour)},
+					{"level": 600.0, "temperature": 19.0, "timestamp": start.Add(9 * 24 * time.Hour)},
+				},
+			}, nil)
+
+			var stats domain.TankLevelStats
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/stats?period=30d", nil, &stats); status != http.StatusOK {
+				t.Fatalf("Código inesperado al pedir las estadísticas: %d", status)
+			}
+			if stats.Samples != 3 || stats.MedianFillPercentage != 60 || stats.MaxTemperature == nil || *stats.MaxTemperature != 25 {
+				t.Errorf("Estadísticas incorrectas: %+v", stats)
+			}
+
+			// Con un periodo de 2 días solo queda la última lectura
+			server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/stats?period=2d", nil, &stats)
+			if stats.Samples != 1 || stats.MeanFillPercentage != 60 || stats.FillVariance != 0 {
+				t.Errorf("El periodo debería limitar las lecturas: %+v", stats)
+			}
+
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/stats?period=mes", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un period inválido, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_ThresholdRecommendation(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank, idle domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque con consumo",
+				"capacity":        1000.0,
+				"current_level":   500.0,
+				"alert_threshold": 5.0,
+			}, &tank)
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":     "Tanque sin consumo",
+				"capacity": 1000.0,
+			}, &idle)
+
+			start := time.Now().Add(-3 * 24 * time.Hour).UTC()
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements/backfill", map[string]interface{}{
+				"measurements": []map[string]interface{}{
+					{"level": 900.0, "timestamp": start},
+					{"level": 700.0, "timestamp": start.Add(24 * time.Hour)},
+					{"level": 500.0, "timestamp": start.Add(48 * time.Hour)},
+				},
+			}, nil)
+
+			var recommendation domain.ThresholdRecommendation
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/threshold-recommendation?lead_time=2d", nil, &recommendation); status != http.StatusOK {
+				t.Fatalf("Código inesperado al pedir la recomendación: %d", status)
+			}
+			// 200 L/día durante 48 horas son al menos el 40% de la capacidad
+			if recommendation.DailyConsumption != 200 || recommendation.RecommendedAlertThreshold < 40 || recommendation.AlertLeadTimeHours != 48 {
+				t.Errorf("Recomendación incorrecta: %+v", recommendation)
+			}
+			if recommendation.RecommendedReorderLevel <= recommendation.RecommendedAlertThreshold*10 {
+				t.Errorf("El punto de pedido debe quedar por encima del umbral de alerta: %+v", recommendation)
+			}
+
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+idle.ID+"/threshold-recommendation", nil, nil); status != http.StatusConflict {
+				t.Errorf("Se esperaba 409 sin historial de consumo, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/threshold-recommendation?lead_time=pronto", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un lead_time inválido, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_TankTimezone(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			tank := map[string]interface{}{
+				"name":     "Tanque en Madrid",
+				"capacity": 1000.0,
+				"timezone": "Europe/Madrid",
+			}
+			var created domain.Tank
+			if status := server.do(t, http.MethodPost, "/api/tanks", tank, &created); status != http.StatusCreated {
+				t.Fatalf("Código inesperado al crear el tanque: %d", status)
+			}
+			if created.Timezone != "Europe/Madrid" {
+				t.Errorf("Zona horaria no guardada: %q", created.Timezone)
+			}
+
+			tank["timezone"] = "Europa/Atlántida"
+			if status := server.do(t, http.MethodPost, "/api/tanks", tank, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con una zona horaria desconocida, se obtuvo %d", status)
+			}
+
+			// Los pasos diarios de la serie empiezan a la medianoche de Madrid
+			var series domain.MeasurementSeries
+			server.do(t, http.MethodGet, "/api/tanks/"+created.ID+"/measurements?step=1d&from=2024-05-01T12:00:00Z&to=2024-05-03T12:00:00Z", nil, &series)
+			madrid, _ := time.LoadLocation("Europe/Madrid")
+			if len(series.Points) != 3 || !series.Points[0].Timestamp.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, madrid)) {
+				t.Errorf("Los días de la serie deben cortarse a la medianoche local: %+v", series.Points)
+			}
+		})
+	}
+}
+
+func TestAPI_MeasurementTimestampFormats(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Marcas de Tiempo",
+				"capacity":        1000.0,
+				"current_level":   800.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+
+			// Milisegundos desde 1970 y hora local del equipo con su zona horaria
+			reading := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+			local := reading.Add(-30 * time.Minute).In(time.FixedZone("UTC-5", -5*3600))
+			for _, timestamp := range []interface{}{reading.UnixMilli(), local.Format("2006-01-02T15:04:05.000-0700")} {
+				if status := server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements",
+					map[string]interface{}{"level": 700.0, "timestamp": timestamp}, nil); status != http.StatusCreated {
+					t.Fatalf("Código inesperado con la marca %v: %d", timestamp, status)
+				}
+			}
+
+			var measurements []domain.Measurement
+			server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements", nil, &measurements)
+			found := map[time.Time]bool{}
+			for _, measurement := range measurements {
+				found[measurement.Timestamp.UTC()] = true
+			}
+			if !found[reading.UTC()] || !found[local.UTC()] {
+				t.Errorf("Las marcas de tiempo deberían guardarse normalizadas: %+v", measurements)
+			}
+
+			// Las lecturas fechadas en el futuro más allá del margen se rechazan
+			future := time.Now().Add(time.Hour).Format(time.RFC3339)
+			if status := server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements",
+				map[string]interface{}{"level": 650.0, "timestamp": future}, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con una medición futura, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements",
+				map[string]interface{}{"level": 650.0, "timestamp": "2024-05-01T10:00:00"}, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con una marca sin zona horaria, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_SensorClocks(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Reloj",
+				"capacity":        1000.0,
+				"current_level":   800.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+
+			// El sensor atrasa una hora en todas sus lecturas
+			for i := 0; i < 15; i++ {
+				server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{
+					"sensor_id": "radar-atrasado",
+					"level":     700.0 - float64(i),
+					"timestamp": time.Now().Add(-time.Hour).Format(time.RFC3339),
+				}, nil)
+			}
+
+			var clocks []domain.SensorClock
+			if status := server.do(t, http.MethodGet, "/api/sensors/clocks?drifting=true", nil, &clocks); status != http.StatusOK {
+				t.Fatalf("Código inesperado al pedir el reloj de los sensores: %d", status)
+			}
+			if len(clocks) != 1 || clocks[0].SensorID != "radar-atrasado" || !clocks[0].Drifting || clocks[0].Samples != 15 {
+				t.Fatalf("Se esperaba el sensor atrasado marcado como desajustado: %+v", clocks)
+			}
+			if clocks[0].Corrected != 0 {
+				t.Errorf("Sin umbral de corrección no deberían corregirse marcas de tiempo: %+v", clocks[0])
+			}
+
+			if status := server.do(t, http.MethodGet, "/api/sensors/clocks?drifting=quizas", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un filtro inválido, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_Purges(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Purgado",
+				"capacity":        1000.0,
+				"current_level":   500.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+			start := time.Now().Add(-72 * time.Hour).UTC()
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements/backfill", map[string]interface{}{
+				"measurements": []map[string]interface{}{
+					{"level": 400.0, "timestamp": start},
+					{"level": 450.0, "timestamp": start.Add(time.Hour)},
+				},
+			}, nil)
+			server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": 500.0}, nil)
+
+			var job domain.PurgeJob
+			status := server.do(t, http.MethodPost, "/api/admin/purges", map[string]interface{}{
+				"scope":   "measurements",
+				"tank_id": tank.ID,
+				"before":  time.Now().Add(-24 * time.Hour).UTC(),
+			}, &job)
+			if status != http.StatusAccepted || job.ID == "" {
+				t.Fatalf("Se esperaba 202 con el trabajo, se obtuvo: %d %+v", status, job)
+			}
+
+			// La purga avanza en segundo plano: consultamos el trabajo hasta que termine
+			deadline := time.Now().Add(5 * time.Second)
+			for job.Status != domain.PurgeJobCompleted && job.Status != domain.PurgeJobFailed && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+				server.do(t, http.MethodGet, "/api/admin/purges/"+job.ID, nil, &job)
+			}
+			if job.Status != domain.PurgeJobCompleted || job.Progress != 100 || job.Deleted["measurements"] != 2 {
+				t.Fatalf("Trabajo inesperado: %+v", job)
+			}
+
+			var measurements []domain.Measurement
+			server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements", nil, &measurements)
+			if len(measurements) != 1 {
+				t.Errorf("Solo debería quedar la medición reciente, quedan %d", len(measurements))
+			}
+
+			var jobs []domain.PurgeJob
+			server.do(t, http.MethodGet, "/api/admin/purges", nil, &jobs)
+			if len(jobs) != 1 || jobs[0].ID != job.ID {
+				t.Errorf("El historial debería incluir la purga: %+v", jobs)
+			}
+
+			if status := server.do(t, http.MethodPost, "/api/admin/purges", map[string]interface{}{"scope": "organization"}, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 sin organización, se obtuvo: %d", status)
+			}
+			if status := server.do(t, http.MethodPost, "/api/admin/purges", map[string]interface{}{
+				"scope":   "measurements",
+				"tank_id": "no-existe",
+				"before":  time.Now().UTC(),
+			}, nil); status != http.StatusNotFound {
+				t.Errorf("Se esperaba 404 con un tanque desconocido, se obtuvo: %d", status)
+			}
+			if status := server.do(t, http.MethodGet, "/api/admin/purges/no-existe", nil, nil); status != http.StatusNotFound {
+				t.Errorf("Se esperaba 404 con un trabajo desconocido, se obtuvo: %d", status)
+			}
+		})
+	}
+}
+
+// testCA emite certificados de cliente para las pruebas de mTLS
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error al generar la clave de la CA: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CA de equipos"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Error al crear el certificado de la CA: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// clientCertificate emite un certificado de cliente con el CN indicado
+func (ca *testCA) clientCertificate(t *testing.T, commonName string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error al generar la clave del cliente: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Error al crear el certificado del cliente: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestAPI_DeviceMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	caFile := filepath.Join(t.TempDir(), "devices-ca.pem")
+	if err := os.WriteFile(caFile, ca.pem, 0o600); err != nil {
+		t.Fatalf("Error al escribir la CA: %v", err)
+	}
+
+	config := api.DefaultConfig()
+	config.DeviceTLSAddr = "127.0.0.1:0"
+	config.DeviceTLSClientCAFile = caFile
+	var app *api.API
+	server := newTestServer(t, backend{
+		name: "device-mtls",
+		setup: func(t *testing.T) *api.API {
+			app = api.NewAPI(config, nopLogger{})
+			return app
+		},
+	})
+
+	// La escucha de los equipos sirve la misma API con la configuración TLS de la aplicación
+	deviceServer := httptest.NewUnstartedServer(app.Handler())
+	deviceServer.TLS = app.DeviceTLSConfig()
+	deviceServer.StartTLS()
+	t.Cleanup(deviceServer.Close)
+	deviceClient := func(certs ...tls.Certificate) *http.Client {
+		transport := deviceServer.Client().Transport.(*http.Transport).Clone()
+		transport.TLSClientConfig.Certificates = certs
+		return &http.Client{Transport: transport}
+	}
+	post := func(client *http.Client, path string, body string) int {
+		resp, err := client.Post(deviceServer.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Error al ejecutar POST %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	var own, other domain.Tank
+	for _, tank := range []*domain.Tank{&own, &other} {
+		server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+			"name":            "Tanque con pasarela",
+			"capacity":        1000.0,
+			"current_level":   500.0,
+			"alert_threshold": 10.0,
+		}, tank)
+	}
+	server.do(t, http.MethodPut, "/api/devices/gw-1/config", map[string]interface{}{
+		"tank_id":            own.ID,
+		"reporting_interval": 60,
+	}, nil)
+
+	gateway := deviceClient(ca.clientCertificate(t, "gw-1"))
+	if status := post(gateway, "/api/tanks/"+own.ID+"/measurements", `{"level": 420}`); status != http.StatusCreated {
+		t.Fatalf("El equipo debería poder enviar mediciones de su tanque, se obtuvo: %d", status)
+	}
+	var tank domain.Tank
+	server.do(t, http.MethodGet, "/api/tanks/"+own.ID, nil, &tank)
+	if tank.CurrentLevel != 420 {
+		t.Errorf("Se esperaba el nivel enviado por el equipo, se obtuvo %.2f", tank.CurrentLevel)
+	}
+
+	if status := post(gateway, "/api/tanks/"+other.ID+"/measurements", `{"level": 420}`); status != http.StatusNotFound {
+		t.Errorf("El equipo no debería acceder a otros tanques, se obtuvo: %d", status)
+	}
+	if status := post(gateway, "/api/tanks", `{"name": "Intruso", "capacity": 10}`); status != http.StatusForbidden {
+		t.Errorf("Con certificado solo deberían admitirse las rutas de ingesta, se obtuvo: %d", status)
+	}
+	unknown := deviceClient(ca.clientCertificate(t, "gw-desconocido"))
+	if status := post(unknown, "/api/tanks/"+own.ID+"/measurements", `{"level": 420}`); status != http.StatusForbidden {
+		t.Errorf("Un equipo no registrado debería rechazarse, se obtuvo: %d", status)
+	}
+
+	// Sin certificado de cliente la conexión no llega a establecerse
+	if _, err := deviceClient().Post(deviceServer.URL+"/api/tanks/"+own.ID+"/measurements", "application/json", strings.NewReader(`{"level": 1}`)); err == nil {
+		t.Error("La escucha mTLS debería rechazar las conexiones sin certificado")
+	}
+}
+
+func TestAPI_LoginLockout(t *testing.T) {
+	ctx := context.Background()
+	config := api.DefaultConfig()
+	config.MemorySnapshotPath = filepath.Join(t.TempDir(), "snapshot.gob")
+	config.AuthMode = "token"
+	config.LoginMaxFailures = 2
+	config.LoginLockoutDuration = 300 * time.Millisecond
+
+	var output bytes.Buffer
+	if err := api.NewAPI(config, nopLogger{}).CreateAdmin(ctx, "ops", &output); err != nil {
+		t.Fatalf("Error inesperado al crear el administrador: %v", err)
+	}
+	var token string
+	for _, line := range strings.Split(output.String(), "\n") {
+		if strings.HasPrefix(line, domain.APITokenPrefix) {
+			token = line
+		}
+	}
+
+	app := api.NewAPI(config, nopLogger{})
+	app.SetupRoutes()
+	server := httptest.NewServer(app.Handler())
+	t.Cleanup(server.Close)
+
+	get := func(path, bearer string) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Error al ejecutar la petición: %v", err)
+		}
+		return resp
+	}
+
+	// Dos tokens inválidos bloquean al cliente, incluso para un token válido
+	for i := 0; i < 2; i++ {
+		if resp := get("/api/tanks", domain.APITokenPrefix+"desconocido"); resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Se esperaba 401 con un token desconocido, se obtuvo %d", resp.StatusCode)
+		}
+	}
+	resp := get("/api/tanks", token)
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("Se esperaba 429 con Retry-After durante el bloqueo, se obtuvo %d", resp.StatusCode)
+	}
+
+	// Al terminar el bloqueo el token válido vuelve a funcionar y puede consultar la auditoría
+	time.Sleep(config.LoginLockoutDuration + 50*time.Millisecond)
+	resp = get("/api/admin/security-events?limit=10", token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Se esperaba 200 tras el bloqueo, se obtuvo %d", resp.StatusCode)
+	}
+	var events []domain.SecurityEvent
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		t.Fatalf("Error al decodificar los eventos: %v", err)
+	}
+	resp.Body.Close()
+
+	types := make([]string, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	want := []string{domain.SecurityEventLoginBlocked, domain.SecurityEventLockout, domain.SecurityEventLoginFailed}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("Eventos inesperados: %v, se esperaba %v", types, want)
+	}
+
+	if resp := get("/api/admin/security-events?limit=0", token); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 con un límite no positivo, se obtuvo %d", resp.StatusCode)
+	}
+}
+
+func TestAPI_TokenScopes(t *testing.T) {
+	ctx := context.Background()
+	config := api.DefaultConfig()
+	config.MemorySnapshotPath = filepath.Join(t.TempDir(), "snapshot.gob")
+	config.AuthMode = "token"
+
+	var output bytes.Buffer
+	if err := api.NewAPI(config, nopLogger{}).CreateAdmin(ctx, "ops", &output); err != nil {
+		t.Fatalf("Error inesperado al crear el administrador: %v", err)
+	}
+	var adminToken string
+	for _, line := range strings.Split(output.String(), "\n") {
+		if strings.HasPrefix(line, domain.APITokenPrefix) {
+			adminToken = line
+		}
+	}
+
+	app := api.NewAPI(config, nopLogger{})
+	app.SetupRoutes()
+	server := httptest.NewServer(app.Handler())
+	t.Cleanup(server.Close)
+
+	do := func(method, path, bearer string, body interface{}, out interface{}) int {
+		var reader io.Reader
+		if body != nil {
+			encoded, _ := json.Marshal(body)
+			reader = bytes.NewReader(encoded)
+		}
+		req, _ := http.NewRequest(method, server.URL+path, reader)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Error al ejecutar la petición: %v", err)
+		}
+		defer resp.Body.Close()
+		if out != nil {
+			json.NewDecoder(resp.Body).Decode(out)
+		}
+		return resp.StatusCode
+	}
+	issue := func(body map[string]interface{}) (string, string) {
+		var issued struct {
+			ID    string `json:"id"`
+			Token string `json:"token"`
+		}
+		if status := do(http.MethodPost, "/api/admin/api-tokens", adminToken, body, &issued); status != http.StatusCreated {
+			t.Fatalf("Se esperaba 201 al emitir el token, se obtuvo %d", status)
+		}
+		return issued.ID, issued.Token
+	}
+
+	var tank domain.Tank
+	do(http.MethodPost, "/api/tanks", adminToken, map[string]interface{}{
+		"name": "Tanque Alcances", "capacity": 1000.0, "current_level": 500.0, "liquid_type": "Agua",
+	}, &tank)
+
+	// Un token de solo lectura, como el de un origen de datos de Grafana
+	grafanaID, grafana := issue(map[string]interface{}{"name": "grafana", "scopes": []string{"read:tanks"}})
+	if status := do(http.MethodGet, "/api/tanks", grafana, nil, nil); status != http.StatusOK {
+		t.Errorf("Se esperaba 200 al consultar con read:tanks, se obtuvo %d", status)
+	}
+	if status := do(http.MethodDelete, "/api/tanks/"+tank.ID, grafana, nil, nil); status != http.StatusForbidden {
+		t.Errorf("Se esperaba 403 al eliminar con read:tanks, se obtuvo %d", status)
+	}
+
+	// Un token de una pasarela solo envía mediciones, aunque su rol permita más
+	_, gateway := issue(map[string]interface{}{"name": "pasarela", "role": "operator", "scopes": []string{"write:measurements"}})
+	if status := do(http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", gateway, map[string]interface{}{"level": 400.0}, nil); status != http.StatusCreated {
+		t.Errorf("Se esperaba 201 al enviar una medición con write:measurements, se obtuvo %d", status)
+	}
+	if status := do(http.MethodGet, "/api/tanks", gateway, nil, nil); status != http.StatusForbidden {
+		t.Errorf("Se esperaba 403 al consultar con write:measurements, se obtuvo %d", status)
+	}
+
+	// Un alcance que el rol no permite se rechaza al emitirlo
+	if status := do(http.MethodPost, "/api/admin/api-tokens", adminToken, map[string]interface{}{
+		"name": "invalido", "role": "viewer", "scopes": []string{"admin:config"},
+	}, nil); status != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 con un alcance superior al rol, se obtuvo %d", status)
+	}
+
+	// Revocado, el token deja de aceptarse
+	if status := do(http.MethodDelete, "/api/admin/api-tokens/"+grafanaID, adminToken, nil, nil); status != http.StatusNoContent {
+		t.Fatalf("Se esperaba 204 al revocar el token, se obtuvo %d", status)
+	}
+	if status := do(http.MethodGet, "/api/tanks", grafana, nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("Se esperaba 401 con un token revocado, se obtuvo %d", status)
+	}
+}
+
+func TestAPI_PendingChanges(t *testing.T) {
+	ctx := context.Background()
+	config := api.DefaultConfig()
+	config.MemorySnapshotPath = filepath.Join(t.TempDir(), "snapshot.gob")
+	config.AuthMode = "token"
+	config.ChangeApprovalSites = []string{"regulado"}
+
+	var output bytes.Buffer
+	if err := api.NewAPI(config, nopLogger{}).CreateAdmin(ctx, "ops", &output); err != nil {
+		t.Fatalf("Error inesperado al crear el administrador: %v", err)
+	}
+	var adminToken string
+	for _, line := range strings.Split(output.String(), "\n") {
+		if strings.HasPrefix(line, domain.APITokenPrefix) {
+			adminToken = line
+		}
+	}
+
+	app := api.NewAPI(config, nopLogger{})
+	app.SetupRoutes()
+	server := httptest.NewServer(app.Handler())
+	t.Cleanup(server.Close)
+
+	do := func(method, path, bearer string, body interface{}, out interface{}) *http.Response {
+		var reader io.Reader
+		if body != nil {
+			encoded, _ := json.Marshal(body)
+			reader = bytes.NewReader(encoded)
+		}
+		req, _ := http.NewRequest(method, server.URL+path, reader)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Error al ejecutar la petición: %v", err)
+		}
+		defer resp.Body.Close()
+		if out != nil {
+			json.NewDecoder(resp.Body).Decode(out)
+		}
+		return resp
+	}
+
+	var issued struct {
+		Token string `json:"token"`
+	}
+	if resp := do(http.MethodPost, "/api/admin/api-tokens", adminToken, map[string]interface{}{"name": "operador", "role": "operator"}, &issued); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Se esperaba 201 al emitir el token del operador, se obtuvo %d", resp.StatusCode)
+	}
+	operator := issued.Token
+
+	var tank domain.Tank
+	do(http.MethodPost, "/api/tanks", adminToken, map[string]interface{}{
+		"name": "Tanque Regulado", "site_id": "regulado", "capacity": 1000.0, "current_level": 500.0,
+		"liquid_type": "Agua", "alert_threshold": 10.0,
+	}, &tank)
+
+	// El operador cambia el umbral: la actualización queda pendiente
+	update := tank
+	update.AlertThreshold = 5
+	var change domain.TankChangeRequest
+	resp := do(http.MethodPut, "/api/tanks/"+tank.ID, operator, update, &change)
+	if resp.StatusCode != http.StatusAccepted || resp.Header.Get("Location") != "/api/pending-changes/"+change.ID {
+		t.Fatalf("Se esperaba 202 con la ruta de la solicitud, se obtuvo %d (%q)", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	var current domain.Tank
+	do(http.MethodGet, "/api/tanks/"+tank.ID, operator, nil, &current)
+	if current.AlertThreshold != 10 {
+		t.Errorf("El umbral no debería cambiar antes de la aprobación, se obtuvo %v", current.AlertThreshold)
+	}
+
+	var pending []domain.TankChangeRequest
+	do(http.MethodGet, "/api/pending-changes", operator, nil, &pending)
+	if len(pending) != 1 || pending[0].ID != change.ID {
+		t.Fatalf("Se esperaba la solicitud en la lista de pendientes: %+v", pending)
+	}
+
+	// Solo un administrador puede aprobarla
+	if resp := do(http.MethodPost, "/api/admin/pending-changes/"+change.ID+"/approve", operator, nil, nil); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Se esperaba 403 al aprobar como operador, se obtuvo %d", resp.StatusCode)
+	}
+	var approved domain.TankChangeRequest
+	if resp := do(http.MethodPost, "/api/admin/pending-changes/"+change.ID+"/approve", adminToken, map[string]string{"comment": "Revisado"}, &approved); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Se esperaba 200 al aprobar, se obtuvo %d", resp.StatusCode)
+	}
+	if approved.Status != domain.TankChangeApproved || approved.ReviewedBy == "" {
+		t.Errorf("Aprobación incorrecta: %+v", approved)
+	}
+	do(http.MethodGet, "/api/tanks/"+tank.ID, operator, nil, &current)
+	if current.AlertThreshold != 5 {
+		t.Errorf("La aprobación debería aplicar el umbral, se obtuvo %v", current.AlertThreshold)
+	}
+	if resp := do(http.MethodPost, "/api/admin/pending-changes/"+change.ID+"/reject", adminToken, nil, nil); resp.StatusCode != http.StatusConflict {
+		t.Errorf("Se esperaba 409 al rechazar una solicitud ya revisada, se obtuvo %d", resp.StatusCode)
+	}
+	if resp := do(http.MethodGet, "/api/pending-changes/no-existe", operator, nil, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Se esperaba 404 con una solicitud desconocida, se obtuvo %d", resp.StatusCode)
+	}
+}
+
+func TestAPI_ConfigChangeNotification(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name": "Tanque Configurado", "capacity": 1000.0, "current_level": 500.0,
+				"liquid_type": "Agua", "alert_threshold": 10.0,
+			}, &tank)
+
+			// Cambiar el umbral avisa; repetir la misma actualización no
+			update := tank
+			update.AlertThreshold = 5
+			for i := 0; i < 2; i++ {
+				if status := server.do(t, http.MethodPut, "/api/tanks/"+tank.ID, update, nil); status != http.StatusOK {
+					t.Fatalf("Se esperaba 200 al actualizar el tanque, se obtuvo %d", status)
+				}
+			}
+
+			if server.notifier.count() != 1 {
+				t.Errorf("Se esperaba un aviso del cambio de configuración, se enviaron %d", server.notifier.count())
+			}
+		})
+	}
+}
+
+func TestAPI_TankCompartments(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var parent domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name": "Cisterna 12", "capacity": 14000.0, "liquid_type": "Mixto",
+			}, &parent)
+			for _, compartment := range []map[string]interface{}{
+				{"name": "Compartimento 1", "parent_id": parent.ID, "capacity": 8000.0, "current_level": 6000.0, "liquid_type": "Diésel"},
+				{"name": "Compartimento 2", "parent_id": parent.ID, "capacity": 6000.0, "current_level": 1000.0, "liquid_type": "Gasolina"},
+			} {
+				if status := server.do(t, http.MethodPost, "/api/tanks", compartment, nil); status != http.StatusCreated {
+					t.Fatalf("Se esperaba 201 al crear el compartimento, se obtuvo %d", status)
+				}
+			}
+
+			var rollup domain.CompartmentRollup
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+parent.ID+"/compartments", nil, &rollup); status != http.StatusOK {
+				t.Fatalf("Se esperaba 200 al obtener los compartimentos, se obtuvo %d", status)
+			}
+			if len(rollup.Compartments) != 2 || rollup.Capacity != 14000 || rollup.CurrentLevel != 7000 || len(rollup.LiquidTypes) != 2 {
+				t.Errorf("Resumen incorrecto: %+v", rollup)
+			}
+
+			if status := server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name": "Huérfano", "parent_id": "no-existe", "capacity": 1000.0,
+			}, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un padre inexistente, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodDelete, "/api/tanks/"+parent.ID, nil, nil); status != http.StatusConflict {
+				t.Errorf("Se esperaba 409 al eliminar un tanque con compartimentos, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_MobileOverview(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			for _, tank := range []map[string]interface{}{
+				{"name": "Diésel Norte", "capacity": 1000.0, "current_level": 800.0, "liquid_type": "Diésel"},
+				{"name": "Agua Sur", "capacity": 1000.0, "current_level": 50.0, "liquid_type": "Agua"},
+			} {
+				server.do(t, http.MethodPost, "/api/tanks", tank, nil)
+			}
+
+			var overview domain.MobileOverview
+			if status := server.do(t, http.MethodGet, "/api/mobile/overview", nil, &overview); status != http.StatusOK {
+				t.Fatalf("Se esperaba 200 al obtener el resumen móvil, se obtuvo %d", status)
+			}
+			if len(overview.Tanks) != 2 || overview.Tanks[0].Name != "Agua Sur" || overview.Tanks[0].Trend != domain.TrendFlat {
+				t.Errorf("Resumen móvil incorrecto: %+v", overview.Tanks)
+			}
+		})
+	}
+}
+
+func TestAPI_TankTrend(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":          "Tanque Principal",
+				"capacity":      1000.0,
+				"current_level": 900.0,
+				"trend":         map[string]interface{}{"direction": "filling"},
+			}, &tank)
+
+			now := time.Now()
+			for i, level := range []float64{900, 850, 800} {
+				server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{
+					"level":     level,
+					"timestamp": now.Add(time.Duration(i-2) * time.Hour),
+				}, nil)
+			}
+
+			var fetched domain.Tank
+			if status := server.do(t, http.MethodGet, "/api/tanks/"+tank.ID, nil, &fetched); status != http.StatusOK {
+				t.Fatalf("Se esperaba 200 al obtener el tanque, se obtuvo %d", status)
+			}
+			if fetched.Trend == nil || fetched.Trend.Direction != domain.TrendDraining || fetched.Trend.RatePerHour != -50 {
+				t.Errorf("Tendencia incorrecta: %+v", fetched.Trend)
+			}
+
+			var tanks []domain.Tank
+			server.do(t, http.MethodGet, "/api/tanks", nil, &tanks)
+			if len(tanks) != 1 || tanks[0].Trend == nil || tanks[0].Trend.Direction != domain.TrendDraining {
+				t.Errorf("La lista debería incluir la tendencia: %+v", tanks)
+			}
+		})
+	}
+}
+
+func TestAPI_VoiceQuery(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Diésel Norte",
+				"liquid_type":     "Diésel",
+				"capacity":        5000.0,
+				"current_level":   3200.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+
+			var answer domain.VoiceAnswer
+			status := server.do(t, http.MethodPost, "/api/voice/query", map[string]interface{}{
+				"text":   "What's the level of the diesel tank?",
+				"locale": "en-US",
+			}, &answer)
+			if status != http.StatusOK {
+				t.Fatalf("Código inesperado al preguntar: %d", status)
+			}
+			if answer.Speech != "Diésel Norte is at 64 percent: 3200 of 5000 liters." || len(answer.TankIDs) != 1 || answer.TankIDs[0] != tank.ID {
+				t.Errorf("Respuesta inesperada: %+v", answer)
+			}
+
+			if status := server.do(t, http.MethodPost, "/api/voice/query", map[string]interface{}{"intent": "order_pizza"}, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con una intención desconocida, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_TestAlertRule(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+				"name":            "Tanque Principal",
+				"capacity":        1000.0,
+				"current_level":   800.0,
+				"alert_threshold": 10.0,
+			}, &tank)
+			for _, level := range []float64{700, 50, 600} {
+				server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": level}, nil)
+			}
+
+			var channel domain.NotificationChannel
+			server.do(t, http.MethodPost, "/api/notification-channels", map[string]interface{}{
+				"name": "Guardia",
+				"type": "log",
+			}, &channel)
+
+			var simulation domain.AlertRuleSimulation
+			status := server.do(t, http.MethodPost, "/api/alert-rules/"+channel.ID+"/test", nil, &simulation)
+			if status != http.StatusOK {
+				t.Fatalf("Código inesperado al probar la regla: %d", status)
+			}
+			if simulation.Tanks != 1 || len(simulation.Firings) != 1 || simulation.Firings[0].ResolvedAt == nil {
+				t.Errorf("Se esperaba una alerta resuelta: %+v", simulation)
+			}
+
+			if status := server.do(t, http.MethodPost, "/api/alert-rules/no-existe/test", nil, nil); status != http.StatusNotFound {
+				t.Errorf("Se esperaba 404 con una regla inexistente, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodPost, "/api/alert-rules/"+channel.ID+"/test?from=ayer", nil, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un periodo inválido, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_SimulateTank(t *testing.T) {
+	config := api.DefaultConfig()
+	config.Environment = "test"
+	server := newTestServer(t, backend{
+		name: "staging",
+		setup: func(t *testing.T) *api.API {
+			return api.NewAPI(config, nopLogger{})
+		},
+	})
+
+	var tank domain.Tank
+	server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{
+		"name":            "Tanque de Ensayo",
+		"capacity":        1000.0,
+		"current_level":   600.0,
+		"alert_threshold": 10.0,
+	}, &tank)
+
+	var series domain.SyntheticSeries
+	status := server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/simulate?profile=refill&duration=2h&interval=5m", nil, &series)
+	if status != http.StatusCreated {
+		t.Fatalf("Código inesperado al simular: %d", status)
+	}
+	if series.Measurements != 24 || series.Profile != domain.SyntheticProfileRefill || series.MinLevel > 100 {
+		t.Errorf("Serie inesperada: %+v", series)
+	}
+
+	var measurements []domain.Measurement
+	server.do(t, http.MethodGet, "/api/tanks/"+tank.ID+"/measurements?limit=100", nil, &measurements)
+	if len(measurements) != 24 {
+		t.Errorf("Se esperaban 24 mediciones sintéticas, hay %d", len(measurements))
+	}
+
+	if status := server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/simulate?profile=flood", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("Se esperaba 400 con un perfil desconocido, se obtuvo %d", status)
+	}
+
+	// En producción la ruta no existe
+	production := newTestServer(t, backends()[0])
+	production.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{"name": "Tanque", "capacity": 1000.0}, &tank)
+	if status := production.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/simulate", nil, nil); status == http.StatusCreated {
+		t.Error("La simulación no debe estar disponible en producción")
+	}
+}
+
+func TestAPI_RepositoryStats(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var tank domain.Tank
+			server.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{"name": "Tanque Principal", "capacity": 1000.0}, &tank)
+			for _, level := range []float64{700, 650} {
+				server.do(t, http.MethodPost, "/api/tanks/"+tank.ID+"/measurements", map[string]interface{}{"level": level}, nil)
+			}
+
+			var stats domain.RepositoryStats
+			if status := server.do(t, http.MethodGet, "/api/admin/stats", nil, &stats); status != http.StatusOK {
+				t.Fatalf("Código inesperado al consultar las estadísticas: %d", status)
+			}
+			if stats.Backend.Name != "memory" || stats.Entities["tanks"] != 1 || stats.Entities["measurements"] != 2 {
+				t.Errorf("Estadísticas inesperadas: %+v", stats)
+			}
+			if stats.Ingestion.LastMinute != 2 || len(stats.Tanks) != 1 || stats.Tanks[0].Measurements != 2 || stats.Tanks[0].Newest == nil {
+				t.Errorf("Ingesta o rango de mediciones inesperados: %+v, %+v", stats.Ingestion, stats.Tanks)
+			}
+		})
+	}
+}
+
+func TestAPI_EdgeSync(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			central := newTestServer(t, b)
+
+			config := api.DefaultConfig()
+			config.RunMode = domain.RunModeEdge
+			config.EdgeUpstreamURL = central.URL
+			config.EdgeGatewayID = "sitio-norte"
+			edge := newTestServer(t, backend{
+				name: "edge",
+				setup: func(t *testing.T) *api.API {
+					return api.NewAPI(config, nopLogger{})
+				},
+			})
+
+			// El tanque de la instancia central llega a la pasarela con la primera sincronización
+			central.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{"id": "tq-norte", "name": "Diésel norte", "capacity": 1000.0, "current_level": 900.0}, nil)
+			var status domain.EdgeSyncStatus
+			if code := edge.do(t, http.MethodPost, "/api/edge/sync", nil, &status); code != http.StatusOK {
+				t.Fatalf("Se esperaba 200 al sincronizar, se obtuvo %d", code)
+			}
+			var local domain.Tank
+			if code := edge.do(t, http.MethodGet, "/api/tanks/tq-norte", nil, &local); code != http.StatusOK || local.Name != "Diésel norte" {
+				t.Fatalf("El tanque no llegó a la pasarela: %d %+v", code, local)
+			}
+
+			now := time.Now()
+			for i, level := range []float64{850, 800} {
+				edge.do(t, http.MethodPost, "/api/tanks/tq-norte/measurements", map[string]interface{}{
+					"level":     level,
+					"timestamp": now.Add(time.Duration(i-2) * time.Minute),
+				}, nil)
+			}
+			local.AlertThreshold = 25
+			edge.do(t, http.MethodPut, "/api/tanks/tq-norte", local, nil)
+			edge.do(t, http.MethodPost, "/api/tanks", map[string]interface{}{"id": "tq-local", "name": "Solo local", "capacity": 500.0}, nil)
+
+			edge.do(t, http.MethodGet, "/api/edge/status", nil, &status)
+			if status.Pending != 2 || status.GatewayID != "sitio-norte" || status.PullCursor == 0 {
+				t.Fatalf("Estado antes de sincronizar incorrecto: %+v", status)
+			}
+
+			// Act
+			if code := edge.do(t, http.MethodPost, "/api/edge/sync", nil, &status); code != http.StatusOK {
+				t.Fatalf("Se esperaba 200 al sincronizar, se obtuvo %d", code)
+			}
+
+			// Assert: las mediciones y los cambios de configuración llegan a la instancia central
+			if status.Pending != 0 || status.LastSyncAt == nil || status.LastError != "" {
+				t.Errorf("Estado tras sincronizar incorrecto: %+v", status)
+			}
+			var measurements []domain.Measurement
+			central.do(t, http.MethodGet, "/api/tanks/tq-norte/measurements", nil, &measurements)
+			if len(measurements) != 2 {
+				t.Fatalf("Se esperaban 2 mediciones en la instancia central, se obtuvieron %d", len(measurements))
+			}
+			var synced domain.Tank
+			central.do(t, http.MethodGet, "/api/tanks/tq-norte", nil, &synced)
+			if synced.CurrentLevel != 800 || synced.AlertThreshold != 25 {
+				t.Errorf("Tanque incorrecto en la instancia central: %+v", synced)
+			}
+			if code := central.do(t, http.MethodGet, "/api/tanks/tq-local", nil, nil); code != http.StatusOK {
+				t.Errorf("El tanque creado en la pasarela debería llegar a la instancia central: %d", code)
+			}
+
+			// Un lote repetido devuelve la respuesta ya dada
+			batch := map[string]interface{}{
+				"gateway_id":   "sitio-norte",
+				"batch_id":     "lote-manual",
+				"measurements": []domain.Measurement{measurements[0], {ID: "m-huerfana", TankID: "tq-inexistente", Level: 100}},
+			}
+			var first, second domain.SyncResult
+			central.do(t, http.MethodPost, "/api/sync/push", batch, &first)
+			central.do(t, http.MethodPost, "/api/sync/push", batch, &second)
+			if first.Accepted != 1 || first.Rejected != 1 || first.Records[1].Error == "" || !second.ReceivedAt.Equal(first.ReceivedAt) {
+				t.Errorf("Resultado del lote incorrecto: %+v %+v", first, second)
+			}
+			central.do(t, http.MethodGet, "/api/tanks/tq-norte/measurements", nil, &measurements)
+			if len(measurements) != 2 {
+				t.Errorf("El reenvío no debería duplicar mediciones: %d", len(measurements))
+			}
+
+			var pulled domain.SyncPullResult
+			if code := central.do(t, http.MethodGet, "/api/sync/pull?cursor=0&limit=1", nil, &pulled); code != http.StatusOK || len(pulled.Tanks) != 1 || !pulled.HasMore {
+				t.Errorf("Consulta de cambios incorrecta: %d %+v", code, pulled)
+			}
+			if code := central.do(t, http.MethodPost, "/api/sync/push", map[string]interface{}{"gateway_id": "sitio-norte"}, nil); code != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 sin ID de lote, se obtuvo %d", code)
+			}
+			if code := central.do(t, http.MethodGet, "/api/sync/pull?cursor=-1", nil, nil); code != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un cursor negativo, se obtuvo %d", code)
+			}
+		})
+	}
+}
+
+func TestAPI_SavedViews(t *testing.T) {
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			server := newTestServer(t, b)
+
+			var view domain.SavedView
+			status := server.do(t, http.MethodPost, "/api/views", map[string]interface{}{
+				"name":  "Diésel crítico – Norte",
+				"path":  "/api/tanks",
+				"query": map[string]string{"labels": "region=norte,liquido=diesel"},
+			}, &view)
+			if status != http.StatusCreated || view.ID == "" {
+				t.Fatalf("Vista inesperada: %d %+v", status, view)
+			}
+
+			// El mismo nombre no se repite y un filtro inválido se rechaza
+			if status := server.do(t, http.MethodPost, "/api/views", map[string]interface{}{"name": "diésel crítico – norte", "path": "/api/tanks"}, nil); status != http.StatusConflict {
+				t.Errorf("Se esperaba 409 con un nombre repetido, se obtuvo %d", status)
+			}
+			if status := server.do(t, http.MethodPost, "/api/views", map[string]interface{}{
+				"name": "Filtro roto", "path": "/api/tanks/t1/measurements", "query": map[string]string{"filter": "level<"},
+			}, nil); status != http.StatusBadRequest {
+				t.Errorf("Se esperaba 400 con un filtro inválido, se obtuvo %d", status)
+			}
+
+			// Actualizar
+			status = server.do(t, http.MethodPut, "/api/views/"+view.ID, map[string]interface{}{
+				"name":  "Niveles bajos",
+				"path":  "/api/tanks/t1/measurements",
+				"query": map[string]string{"filter": "level<100", "limit": "50"},
+			}, &view)
+			if status != http.StatusOK || view.Name != "Niveles bajos" || view.CreatedAt.IsZero() {
+				t.Fatalf("Actualización inesperada: %d %+v", status, view)
+			}
+
+			var views []domain.SavedView
+			if status := server.do(t, http.MethodGet, "/api/views", nil, &views); status != http.StatusOK {
+				t.Fatalf("Código de estado inesperado al listar las vistas: %d", status)
+			}
+			if len(views) != 1 || views[0].Query["filter"] != "level<100" {
+				t.Errorf("Listado de vistas incorrecto: %+v", views)
+			}
+
+			// Eliminar
+			if status := server.do(t, http.MethodDelete, "/api/views/"+view.ID, nil, nil); status != http.StatusNoContent {
+				t.Fatalf("Código de estado inesperado al eliminar la vista: %d", status)
+			}
+			if status := server.do(t, http.MethodGet, "/api/views/"+view.ID, nil, nil); status != http.StatusNotFound {
+				t.Errorf("Se esperaba 404 tras eliminar la vista, se obtuvo %d", status)
+			}
+		})
+	}
+}
+
+func TestAPI_NotificationChannelSecretIsWriteOnly(t *testing.T) {
+	ctx := context.Background()
+	config := api.DefaultConfig()
+	config.MemorySnapshotPath = filepath.Join(t.TempDir(), "snapshot.gob")
+	config.AuthMode = "token"
+
+	var output bytes.Buffer
+	if err := api.NewAPI(config, nopLogger{}).CreateAdmin(ctx, "ops", &output); err != nil {
+		t.Fatalf("Error inesperado al crear el administrador: %v", err)
+	}
+	var adminToken string
+	for _, line := range strings.Split(output.String(), "\n") {
+		if strings.HasPrefix(line, domain.APITokenPrefix) {
+			adminToken = line
+		}
+	}
+
+	app := api.NewAPI(config, nopLogger{})
+	app.SetupRoutes()
+	server := httptest.NewServer(app.Handler())
+	t.Cleanup(server.Close)
+
+	do := func(method, path, bearer string, body interface{}) (int, string) {
+		var reader io.Reader
+		if body != nil {
+			encoded, _ := json.Marshal(body)
+			reader = bytes.NewReader(encoded)
+		}
+		req, _ := http.NewRequest(method, server.URL+path, reader)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Error al ejecutar la petición: %v", err)
+		}
+		defer resp.Body.Close()
+		raw, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(raw)
+	}
+
+	var issued struct {
+		Token string `json:"token"`
+	}
+	status, body := do(http.MethodPost, "/api/admin/api-tokens", adminToken, map[string]interface{}{"name": "lector", "role": "viewer"})
+	if status != http.StatusCreated || json.Unmarshal([]byte(body), &issued) != nil {
+		t.Fatalf("Se esperaba 201 al emitir el token de lectura, se obtuvo %d: %s", status, body)
+	}
+
+	const secret = "s3cr3t-hmac-key"
+	channel := map[string]interface{}{
+		"id": "webhook-firmado", "name": "Webhook firmado", "type": "webhook",
+		"target": "https://hooks.example.com/tanques", "enabled": true, "secret": secret,
+	}
+	status, body = do(http.MethodPost, "/api/notification-channels", adminToken, channel)
+	if status != http.StatusCreated {
+		t.Fatalf("Se esperaba 201 al crear el canal, se obtuvo %d: %s", status, body)
+	}
+	if strings.Contains(body, secret) {
+		t.Errorf("La respuesta de la creación no debería incluir el secreto: %s", body)
+	}
+
+	// Un lector ve que el canal tiene secreto, pero no el secreto
+	for _, path := range []string{"/api/notification-channels", "/api/notification-channels/webhook-firmado"} {
+		status, body := do(http.MethodGet, path, issued.Token, nil)
+		if status != http.StatusOK {
+			t.Fatalf("Se esperaba 200 al consultar %s como lector, se obtuvo %d", path, status)
+		}
+		if strings.Contains(body, secret) || !strings.Contains(body, `"has_secret":true`) {
+			t.Errorf("La respuesta de %s no debería incluir el secreto: %s", path, body)
+		}
+	}
+
+	// Una referencia al proveedor de secretos sí se muestra
+	channel["secret"] = "secret:webhook-tanques"
+	status, body = do(http.MethodPut, "/api/notification-channels/webhook-firmado", adminToken, channel)
+	if status != http.StatusOK || !strings.Contains(body, `"secret":"secret:webhook-tanques"`) {
+		t.Errorf("Se esperaba ver la referencia del secreto, se obtuvo %d: %s", status, body)
+	}
+}
@@ -0,0 +1,131 @@
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"monitor-tanques/cmd/api"
+	"monitor-tanques/internal/core/domain"
+)
+
+// backend describe un conjunto de adaptadores de persistencia contra el que se ejecuta la suite.
+// Los adaptadores de base de datos reales se añaden aquí (p. ej. con dockertest) a medida que
+// existan implementaciones de los puertos de repositorio.
+type backend struct {
+	name  string
+	setup func(t *testing.T) *api.API
+}
+
+// backends devuelve los backends disponibles para la suite de integración
+func backends() []backend {
+	return []backend{
+		{
+			name: "memory",
+			setup: func(t *testing.T) *api.API {
+				return api.NewAPI(api.DefaultConfig(), nopLogger{})
+			},
+		},
+	}
+}
+
+// recordingNotifier registra las alertas enviadas por la API
+type recordingNotifier struct {
+	mutex    sync.Mutex
+	alerts   []string
+	alertIDs []string // ID de cada alerta; vacío en las que no son críticas
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.alerts = append(n.alerts, alert.TankID)
+	n.alertIDs = append(n.alertIDs, alert.ID)
+	return nil
+}
+
+// lastAlertID devuelve el ID de la última alerta recibida
+func (n *recordingNotifier) lastAlertID() string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if len(n.alertIDs) == 0 {
+		return ""
+	}
+	return n.alertIDs[len(n.alertIDs)-1]
+}
+
+func (n *recordingNotifier) count() int {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return len(n.alerts)
+}
+
+// nopLogger descarta todos los mensajes para no ensuciar la salida de las pruebas
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Fatal(msg string, keysAndValues ...interface{}) {}
+
+// testServer agrupa el servidor de pruebas y el notificador de alertas
+type testServer struct {
+	*httptest.Server
+	notifier *recordingNotifier
+}
+
+// newTestServer levanta la API completa sobre httptest para el backend indicado
+func newTestServer(t *testing.T, b backend) *testServer {
+	t.Helper()
+
+	app := b.setup(t)
+	notifier := &recordingNotifier{}
+	app.SetAlertNotifier(notifier)
+	app.SetupRoutes()
+
+	server := httptest.NewServer(app.Handler())
+	t.Cleanup(server.Close)
+
+	return &testServer{Server: server, notifier: notifier}
+}
+
+// do ejecuta una petición con cuerpo JSON opcional y decodifica la respuesta en out si no es nil
+func (s *testServer) do(t *testing.T, method, path string, body interface{}, out interface{}) int {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Error al codificar la petición: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, s.URL+path, reader)
+	if err != nil {
+		t.Fatalf("Error al crear la petición: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Error al ejecutar %s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Error al decodificar la respuesta de %s %s: %v", method, path, err)
+		}
+	}
+
+	return resp.StatusCode
+}
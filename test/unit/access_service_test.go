@@ -0,0 +1,84 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestAuthorizedTankService_RestrictsToGrantedSites(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	alertNotifier := &MockAlertNotifier{}
+
+	accessService := services.NewAccessService(repositories.NewMemoryAccessGrantRepository())
+	service := services.NewAuthorizedTankService(
+		newTestTankService(tankRepo, measurementRepo, alertNotifier),
+		accessService,
+	)
+	adminCtx := context.Background()
+
+	ownTank := createTestTank()
+	ownTank.SiteID = "estacion-norte"
+	otherTank := createTestTank()
+	otherTank.SiteID = "estacion-sur"
+	for _, tank := range []*domain.Tank{ownTank, otherTank} {
+		if err := service.CreateTank(adminCtx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+	}
+
+	grant := &domain.AccessGrant{
+		ID:        uuid.New().String(),
+		Subject:   "contratista",
+		ScopeType: domain.AccessScopeSite,
+		ScopeID:   "estacion-norte",
+	}
+	if err := accessService.CreateGrant(adminCtx, grant); err != nil {
+		t.Fatalf("Error al crear la concesión: %v", err)
+	}
+
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{
+		Subject: "contratista",
+		Roles:   []string{domain.RoleOperator},
+	})
+
+	// Act & Assert: solo se listan los tanques del sitio concedido
+	tanks, err := service.GetAllTanks(ctx)
+	if err != nil {
+		t.Fatalf("Error al obtener los tanques: %v", err)
+	}
+	if len(tanks) != 1 || tanks[0].ID != ownTank.ID {
+		t.Fatalf("Se esperaba ver solo el tanque %s, se obtuvieron %d tanques", ownTank.ID, len(tanks))
+	}
+
+	// Los tanques de otros sitios se tratan como inexistentes
+	if _, err := service.GetTank(ctx, otherTank.ID); !errors.Is(err, services.ErrTankNotFound) {
+		t.Errorf("Se esperaba ErrTankNotFound al leer otro sitio, se obtuvo: %v", err)
+	}
+	if err := service.AddMeasurement(ctx, createTestMeasurement(otherTank.ID, 100.0)); !errors.Is(err, services.ErrTankNotFound) {
+		t.Errorf("Se esperaba ErrTankNotFound al medir otro sitio, se obtuvo: %v", err)
+	}
+	if err := service.DeleteTank(ctx, otherTank.ID); !errors.Is(err, services.ErrTankNotFound) {
+		t.Errorf("Se esperaba ErrTankNotFound al eliminar otro sitio, se obtuvo: %v", err)
+	}
+
+	// No se puede mover un tanque propio a un sitio no concedido
+	moved := *ownTank
+	moved.SiteID = "estacion-sur"
+	if err := service.UpdateTank(ctx, &moved); !errors.Is(err, services.ErrForbidden) {
+		t.Errorf("Se esperaba ErrForbidden al mover el tanque, se obtuvo: %v", err)
+	}
+
+	// El tanque propio sigue siendo accesible
+	if err := service.AddMeasurement(ctx, createTestMeasurement(ownTank.ID, 400.0)); err != nil {
+		t.Errorf("No se esperaba error al medir el tanque propio: %v", err)
+	}
+}
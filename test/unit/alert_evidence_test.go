@@ -0,0 +1,82 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestEvidenceAlertNotifier_CapturesCriticalAlerts(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	noteRepo := repositories.NewMemoryTankNoteRepository()
+	evidenceRepo := repositories.NewMemoryAlertEvidenceRepository()
+	next := &MockAlertNotifier{}
+	notifier := services.NewEvidenceAlertNotifier(next, tankRepo, measurementRepo, noteRepo, evidenceRepo)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	tank.SiteID = "estacion-norte"
+	tankRepo.SaveTank(ctx, tank)
+	old := createTestMeasurement(tank.ID, 700)
+	old.Timestamp = time.Now().Add(-30 * time.Hour)
+	recent := createTestMeasurement(tank.ID, 80)
+	recent.Timestamp = time.Now().Add(-time.Hour)
+	measurementRepo.SaveMeasurement(ctx, old)
+	measurementRepo.SaveMeasurement(ctx, recent)
+	noteRepo.SaveNote(ctx, &domain.TankNote{ID: uuid.New().String(), TankID: tank.ID, Text: "Fuga en la brida", CreatedAt: time.Now()})
+
+	warning := domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityWarning, tank, "Aviso")
+	critical := domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, tank, "Nivel crítico")
+
+	// Act
+	notifier.Notify(ctx, warning)
+	notifier.Notify(ctx, critical)
+
+	// Assert
+	if next.AlertsSent != 2 {
+		t.Errorf("Todas las alertas deben entregarse, se entregaron %d", next.AlertsSent)
+	}
+	if warning.ID != "" {
+		t.Errorf("Las alertas que no son críticas no guardan evidencia: %q", warning.ID)
+	}
+	if critical.ID == "" || next.LastAlert.ID != critical.ID {
+		t.Fatalf("La alerta crítica debe entregarse con su ID: %q", next.LastAlert.ID)
+	}
+
+	evidence, _ := evidenceRepo.GetEvidence(ctx, critical.ID)
+	if evidence == nil || evidence.Tank.ID != tank.ID {
+		t.Fatalf("No se guardó la evidencia de la alerta: %+v", evidence)
+	}
+	if len(evidence.Measurements) != 1 || evidence.Measurements[0].Level != 80 {
+		t.Errorf("Solo deben guardarse las mediciones de las últimas 24 horas: %+v", evidence.Measurements)
+	}
+	if len(evidence.Notes) != 1 || evidence.Alert.MessageArgs != nil {
+		t.Errorf("Evidencia incorrecta: %d notas, alerta %+v", len(evidence.Notes), evidence.Alert)
+	}
+
+	// Solo quien tiene acceso al tanque puede consultar la evidencia
+	accessService := services.NewAccessService(repositories.NewMemoryAccessGrantRepository())
+	accessService.CreateGrant(ctx, &domain.AccessGrant{
+		ID:        uuid.New().String(),
+		Subject:   "contratista",
+		ScopeType: domain.AccessScopeSite,
+		ScopeID:   "estacion-sur",
+	})
+	evidenceService := services.NewAlertEvidenceService(evidenceRepo, accessService)
+	if _, err := evidenceService.GetEvidence(ctx, critical.ID); err != nil {
+		t.Errorf("No se esperaba error sin autenticación: %v", err)
+	}
+	contractorCtx := domain.ContextWithPrincipal(ctx, &domain.Principal{Subject: "contratista", Roles: []string{domain.RoleOperator}})
+	if _, err := evidenceService.GetEvidence(contractorCtx, critical.ID); !errors.Is(err, services.ErrAlertEvidenceNotFound) {
+		t.Errorf("Se esperaba ErrAlertEvidenceNotFound sin acceso al tanque, se obtuvo: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"monitor-tanques/internal/adapters/notifiers"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/metrics"
+)
+
+// failingChannelSender falla en los canales indicados, como un proveedor caído
+type failingChannelSender struct {
+	failing map[string]bool
+}
+
+func (s *failingChannelSender) Send(ctx context.Context, channel *domain.NotificationChannel, alert *domain.Alert) error {
+	if s.failing[channel.ID] {
+		return errors.New("proveedor no disponible")
+	}
+	return nil
+}
+
+func TestAlertMetrics_CountsDeliveriesFailuresAndQueue(t *testing.T) {
+	// Arrange: SMS falla, Slack funciona y el email está fuera de horario
+	registry := metrics.NewRegistry()
+	alertMetrics := notifiers.NewAlertMetrics(registry)
+	sender := notifiers.NewInstrumentedChannelSender(&failingChannelSender{failing: map[string]bool{"sms": true}}, alertMetrics)
+	queue := notifiers.NewInstrumentedAlertQueue(repositories.NewMemoryAlertQueueRepository(), alertMetrics)
+	service := services.NewNotificationService(repositories.NewMemoryNotificationChannelRepository(), queue, sender, &MockAlertNotifier{}, services.NewAccessService(repositories.NewMemoryAccessGrantRepository()))
+	ctx := context.Background()
+
+	channels := []*domain.NotificationChannel{
+		{ID: "sms", Name: "SMS", Type: domain.ChannelTypeWebhook, Target: "https://sms.example.com", Enabled: true},
+		{ID: "slack", Name: "Slack", Type: domain.ChannelTypeSlack, Target: "https://hooks.slack.com/x", Enabled: true},
+		{
+			ID:       "email",
+			Name:     "Email",
+			Type:     domain.ChannelTypeWebhook,
+			Target:   "https://email.example.com",
+			Enabled:  true,
+			Schedule: domain.NotificationSchedule{Windows: []domain.ScheduleWindow{outOfHoursWindow()}},
+		},
+	}
+	for _, channel := range channels {
+		if err := service.CreateChannel(ctx, channel); err != nil {
+			t.Fatalf("Error al crear el canal %s: %v", channel.ID, err)
+		}
+	}
+
+	// Act
+	for i := 0; i < 2; i++ {
+		service.Notify(ctx, &domain.Alert{TankID: "tank-1", Severity: domain.AlertSeverityCritical, Message: "nivel crítico"})
+	}
+
+	// Assert
+	if value := alertMetrics.Failed.Value("sms", domain.ChannelTypeWebhook); value != 2 {
+		t.Errorf("Entregas fallidas por SMS incorrectas. Esperado: 2, Obtenido: %v", value)
+	}
+	if value := alertMetrics.Delivered.Value("slack", domain.ChannelTypeSlack); value != 2 {
+		t.Errorf("Entregas por Slack incorrectas. Esperado: 2, Obtenido: %v", value)
+	}
+	if value := alertMetrics.Queued.Value("email"); value != 2 {
+		t.Errorf("Alertas encoladas incorrectas. Esperado: 2, Obtenido: %v", value)
+	}
+	if count := alertMetrics.Latency.Count("sms", domain.ChannelTypeWebhook); count != 2 {
+		t.Errorf("Se esperaban 2 latencias registradas para SMS, se obtuvieron %d", count)
+	}
+}
+
+func TestMetricsRegistry_WritesPrometheusText(t *testing.T) {
+	// Arrange
+	registry := metrics.NewRegistry()
+	counter := registry.NewCounterVec("alerts_total", "Alertas enviadas.", "channel")
+	histogram := registry.NewHistogramVec("delivery_seconds", "Latencia de entrega.", []float64{0.1, 1}, "channel")
+
+	counter.Inc(`sms "guardia"`)
+	counter.Add(2, "slack")
+	histogram.Observe(0.1, "sms")
+	histogram.Observe(0.5, "sms")
+	histogram.Observe(3, "sms")
+
+	// Act
+	var output bytes.Buffer
+	registry.WriteText(&output)
+
+	// Assert
+	expected := []string{
+		"# TYPE alerts_total counter",
+		`alerts_total{channel="slack"} 2`,
+		`alerts_total{channel="sms \"guardia\""} 1`,
+		"# TYPE delivery_seconds histogram",
+		`delivery_seconds_bucket{channel="sms",le="0.1"} 1`,
+		`delivery_seconds_bucket{channel="sms",le="1"} 2`,
+		`delivery_seconds_bucket{channel="sms",le="+Inf"} 3`,
+		`delivery_seconds_sum{channel="sms"} 3.6`,
+		`delivery_seconds_count{channel="sms"} 3`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(output.String(), line+"\n") {
+			t.Errorf("Falta la línea %q en la salida:\n%s", line, output.String())
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestAlertMuteService_MuteSuppressesAlerts(t *testing.T) {
+	// Arrange: las alertas del tanque pasan por el notificador que respeta los silencios
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	muteRepo := repositories.NewMemoryAlertMuteRepository()
+	notifier := &MockAlertNotifier{}
+	tankService := newTestTankService(tankRepo, measurementRepo, services.NewMutingAlertNotifier(notifier, muteRepo))
+	muteService := services.NewAlertMuteService(tankService, muteRepo)
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{Subject: "u1", Name: "Operador"})
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act
+	mute, err := muteService.MuteAlerts(ctx, tank.ID, 2*time.Hour, "Cambio de sensor programado")
+	if err != nil {
+		t.Fatalf("No se esperaba error al silenciar, se obtuvo %v", err)
+	}
+	if err := tankService.AddMeasurement(ctx, createTestMeasurement(tank.ID, 50)); err != nil {
+		t.Fatalf("Error al añadir la medición: %v", err)
+	}
+	mutedAlerts := notifier.AlertsSent
+
+	unmuted, unmuteErr := muteService.UnmuteAlerts(ctx, tank.ID)
+	if err := tankService.AddMeasurement(ctx, createTestMeasurement(tank.ID, 40)); err != nil {
+		t.Fatalf("Error al añadir la medición: %v", err)
+	}
+
+	// Assert
+	if mute.MutedBy != "Operador" || mute.ExpiresAt.Sub(mute.MutedAt) != 2*time.Hour {
+		t.Errorf("Registro de silencio incorrecto: %+v", mute)
+	}
+	if mutedAlerts != 0 {
+		t.Errorf("No se esperaban alertas durante el silencio, se enviaron %d", mutedAlerts)
+	}
+	if unmuteErr != nil || unmuted.UnmutedAt == nil || unmuted.UnmutedBy != "Operador" {
+		t.Errorf("El silencio no se levantó correctamente: %+v, %v", unmuted, unmuteErr)
+	}
+	if notifier.AlertsSent != 1 {
+		t.Errorf("Se esperaba 1 alerta tras levantar el silencio, se enviaron %d", notifier.AlertsSent)
+	}
+
+	mutes, _ := muteService.GetMutes(ctx, tank.ID)
+	if len(mutes) != 1 || mutes[0].Reason != "Cambio de sensor programado" {
+		t.Errorf("El historial debe conservar el silencio levantado, se obtuvo %+v", mutes)
+	}
+}
+
+func TestMutingAlertNotifier_ExpiredMute(t *testing.T) {
+	// Arrange: un silencio que ya expiró no bloquea las alertas
+	muteRepo := repositories.NewMemoryAlertMuteRepository()
+	notifier := &MockAlertNotifier{}
+	mutingNotifier := services.NewMutingAlertNotifier(notifier, muteRepo)
+	ctx := context.Background()
+
+	now := time.Now()
+	muteRepo.SaveMute(ctx, &domain.AlertMute{ID: "m1", TankID: "t1", MutedAt: now.Add(-3 * time.Hour), ExpiresAt: now.Add(-time.Hour)})
+	muteRepo.SaveMute(ctx, &domain.AlertMute{ID: "m2", TankID: "t2", MutedAt: now, ExpiresAt: now.Add(time.Hour)})
+
+	// Act
+	mutingNotifier.Notify(ctx, &domain.Alert{TankID: "t1", Message: "expirado"})
+	mutingNotifier.Notify(ctx, &domain.Alert{TankID: "t2", Message: "silenciado"})
+
+	// Assert
+	if notifier.AlertsSent != 1 || notifier.LastTankID != "t1" {
+		t.Errorf("Solo debía notificarse la alerta del silencio expirado, se enviaron %d (%s)", notifier.AlertsSent, notifier.LastTankID)
+	}
+}
+
+func TestAlertMuteService_InvalidRequests(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	muteRepo := repositories.NewMemoryAlertMuteRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	muteService := services.NewAlertMuteService(tankService, muteRepo)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act & Assert
+	for _, duration := range []time.Duration{0, -time.Hour, domain.MaxAlertMuteDuration + time.Hour} {
+		if _, err := muteService.MuteAlerts(ctx, tank.ID, duration, ""); !errors.Is(err, services.ErrInvalidAlertMute) {
+			t.Errorf("Duración %s: se esperaba ErrInvalidAlertMute, se obtuvo %v", duration, err)
+		}
+	}
+	if _, err := muteService.UnmuteAlerts(ctx, tank.ID); !errors.Is(err, services.ErrAlertsNotMuted) {
+		t.Errorf("Se esperaba ErrAlertsNotMuted sin silencio vigente, se obtuvo %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestSimulateLevelAlerts(t *testing.T) {
+	// Arrange: umbral del 10 %; la primera lectura queda fuera del periodo
+	tank := createTestTank()
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	levels := []float64{50, 500, 80, 90, 300, 60}
+	measurements := make([]*domain.Measurement, 0, len(levels))
+	for i, level := range levels {
+		m := createTestMeasurement(tank.ID, level)
+		m.Timestamp = start.Add(time.Duration(i) * time.Hour)
+		measurements = append(measurements, m)
+	}
+	// Las lecturas pueden llegar en cualquier orden
+	measurements[2], measurements[5] = measurements[5], measurements[2]
+
+	// Act
+	firings, evaluated := domain.SimulateLevelAlerts(tank, measurements, start.Add(30*time.Minute), start.Add(6*time.Hour))
+
+	// Assert
+	if evaluated != 5 {
+		t.Errorf("Se esperaban 5 lecturas en el periodo, se evaluaron %d", evaluated)
+	}
+	if len(firings) != 2 {
+		t.Fatalf("Se esperaban 2 alertas, se obtuvieron %d", len(firings))
+	}
+	if !firings[0].FiredAt.Equal(start.Add(2*time.Hour)) || firings[0].Percentage != 8 {
+		t.Errorf("Primera alerta incorrecta: %+v", firings[0])
+	}
+	if firings[0].ResolvedAt == nil || !firings[0].ResolvedAt.Equal(start.Add(4*time.Hour)) {
+		t.Errorf("La primera alerta debería resolverse con la lectura de 300 L: %v", firings[0].ResolvedAt)
+	}
+	if !firings[1].FiredAt.Equal(start.Add(5*time.Hour)) || firings[1].ResolvedAt != nil {
+		t.Errorf("La segunda alerta debería seguir activa al final del periodo: %+v", firings[1])
+	}
+}
+
+func TestAlertRuleService_TestAlertRule(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	channelRepo := repositories.NewMemoryNotificationChannelRepository()
+	muteRepo := repositories.NewMemoryAlertMuteRepository()
+	service := services.NewAlertRuleService(tankService, measurementRepo, channelRepo, muteRepo)
+	ctx := context.Background()
+
+	north := createTestTank()
+	north.Labels = domain.Labels{"zona": "norte"}
+	south := createTestTank()
+	south.Labels = domain.Labels{"zona": "sur"}
+	for _, tank := range []*domain.Tank{north, south} {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+	}
+
+	now := time.Now()
+	readings := []struct {
+		tank  *domain.Tank
+		level float64
+		ago   time.Duration
+	}{
+		{north, 80, 5 * time.Hour},
+		{north, 400, 4 * time.Hour},
+		{north, 70, time.Hour},
+		{south, 20, 3 * time.Hour},
+	}
+	for _, reading := range readings {
+		m := createTestMeasurement(reading.tank.ID, reading.level)
+		m.Timestamp = now.Add(-reading.ago)
+		if err := measurementRepo.SaveMeasurement(ctx, m); err != nil {
+			t.Fatalf("Error al guardar la medición: %v", err)
+		}
+	}
+
+	// Un silencio ya levantado cubría la segunda alerta del tanque norte
+	unmutedAt := now.Add(-30 * time.Minute)
+	mute := &domain.AlertMute{ID: "mute-1", TankID: north.ID, MutedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(time.Hour), UnmutedAt: &unmutedAt}
+	if err := muteRepo.SaveMute(ctx, mute); err != nil {
+		t.Fatalf("Error al guardar el silencio: %v", err)
+	}
+
+	rule := &domain.NotificationChannel{ID: "guardia", Name: "Guardia", Type: domain.ChannelTypeLog, TankSelector: "zona=norte"}
+	if err := channelRepo.SaveChannel(ctx, rule); err != nil {
+		t.Fatalf("Error al guardar la regla: %v", err)
+	}
+
+	// Act
+	simulation, err := service.TestAlertRule(ctx, "guardia", now.Add(-6*time.Hour), now)
+	_, missingErr := service.TestAlertRule(ctx, "no-existe", now.Add(-time.Hour), now)
+	_, periodErr := service.TestAlertRule(ctx, "guardia", now, now.Add(-time.Hour))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al probar la regla: %v", err)
+	}
+	if simulation.Tanks != 1 || simulation.Measurements != 3 {
+		t.Errorf("Se esperaba solo el tanque norte con 3 lecturas: %d tanques, %d lecturas", simulation.Tanks, simulation.Measurements)
+	}
+	if len(simulation.Firings) != 2 {
+		t.Fatalf("Se esperaban 2 alertas, se obtuvieron %d", len(simulation.Firings))
+	}
+	if first := simulation.Firings[0]; first.TankID != north.ID || first.Disposition != domain.RuleDispositionSend || first.ResolvedAt == nil {
+		t.Errorf("La primera alerta debería enviarse y resolverse: %+v", first)
+	}
+	if second := simulation.Firings[1]; second.Disposition != domain.RuleDispositionMuted || second.ResolvedAt != nil {
+		t.Errorf("La segunda alerta debería estar silenciada y sin resolver: %+v", second)
+	}
+	if !errors.Is(missingErr, services.ErrChannelNotFound) {
+		t.Errorf("Se esperaba ErrChannelNotFound, se obtuvo %v", missingErr)
+	}
+	if !errors.Is(periodErr, services.ErrInvalidPeriod) {
+		t.Errorf("Se esperaba ErrInvalidPeriod, se obtuvo %v", periodErr)
+	}
+}
+
+func TestNotificationChannel_Disposition(t *testing.T) {
+	// Arrange
+	tank := createTestTank()
+	at := time.Now()
+	offHours := domain.NotificationSchedule{Windows: []domain.ScheduleWindow{outOfHoursWindow()}}
+	fallback := &domain.NotificationChannel{ID: "slack", Enabled: true}
+
+	cases := []struct {
+		name     string
+		channel  *domain.NotificationChannel
+		fallback *domain.NotificationChannel
+		expected string
+	}{
+		{"en horario", &domain.NotificationChannel{}, nil, domain.RuleDispositionSend},
+		{"resumen diario", &domain.NotificationChannel{Delivery: domain.DeliveryDigest}, nil, domain.RuleDispositionDigest},
+		{"fuera de horario con desvío", &domain.NotificationChannel{Schedule: offHours, OutOfSchedule: domain.OutOfScheduleRoute, FallbackChannelID: "slack"}, fallback, domain.RuleDispositionRoute},
+		{"fuera de horario sin alternativa", &domain.NotificationChannel{Schedule: offHours, OutOfSchedule: domain.OutOfScheduleRoute, FallbackChannelID: "slack"}, nil, domain.RuleDispositionQueue},
+		{"fuera de horario con cola", &domain.NotificationChannel{Schedule: offHours}, nil, domain.RuleDispositionQueue},
+	}
+
+	// Act & Assert
+	for _, c := range cases {
+		if disposition := c.channel.Disposition(at, tank, c.fallback); disposition != c.expected {
+			t.Errorf("%s: esperado %s, obtenido %s", c.name, c.expected, disposition)
+		}
+	}
+}
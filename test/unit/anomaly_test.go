@@ -0,0 +1,158 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+// consumptionSeries genera mediciones con un consumo ligeramente variable y temperatura estable
+func consumptionSeries(tankID string, start time.Time, count int) []*domain.Measurement {
+	measurements := make([]*domain.Measurement, count)
+	level := 900.0
+	for i := range measurements {
+		level -= 5 + float64(i%3)
+		measurements[i] = &domain.Measurement{
+			ID:          "m" + time.Duration(i).String(),
+			TankID:      tankID,
+			Level:       level,
+			Temperature: 20 + float64(i%2)*0.5,
+			Timestamp:   start.Add(time.Duration(i) * time.Hour),
+		}
+	}
+	return measurements
+}
+
+func TestDetectAnomalies_LevelDrop(t *testing.T) {
+	// Arrange
+	config := domain.AnomalyConfig{Window: 10, ZThreshold: 3}
+	series := consumptionSeries("t1", time.Now().Add(-24*time.Hour), 12)
+	last := series[len(series)-1]
+
+	normal := append(series, &domain.Measurement{TankID: "t1", Level: last.Level - 6, Temperature: 20, Timestamp: last.Timestamp.Add(time.Hour)})
+	drop := append(series, &domain.Measurement{TankID: "t1", Level: last.Level - 200, Temperature: 20, Timestamp: last.Timestamp.Add(time.Hour)})
+	refill := append(series, &domain.Measurement{TankID: "t1", Level: 1000, Temperature: 20, Timestamp: last.Timestamp.Add(time.Hour)})
+
+	// Act & Assert
+	if anomalies := domain.DetectAnomalies(normal, config); len(anomalies) != 0 {
+		t.Errorf("No se esperaban anomalías con un consumo normal, se obtuvieron %d", len(anomalies))
+	}
+
+	anomalies := domain.DetectAnomalies(drop, config)
+	if len(anomalies) != 1 || anomalies[0].Metric != domain.AnomalyMetricLevel || anomalies[0].Kind != domain.AnomalyKindOutlier {
+		t.Fatalf("Se esperaba una anomalía de nivel, se obtuvo %+v", anomalies)
+	}
+
+	if anomalies := domain.DetectAnomalies(refill, config); len(anomalies) != 0 {
+		t.Errorf("Una recarga no debe considerarse anómala, se obtuvieron %d anomalías", len(anomalies))
+	}
+}
+
+func TestDetectAnomalies_Temperature(t *testing.T) {
+	// Arrange
+	config := domain.AnomalyConfig{Window: 10, ZThreshold: 3}
+	series := consumptionSeries("t1", time.Now().Add(-24*time.Hour), 12)
+	last := series[len(series)-1]
+	series = append(series, &domain.Measurement{TankID: "t1", Level: last.Level - 5, Temperature: 45, Timestamp: last.Timestamp.Add(time.Hour)})
+
+	// Act
+	anomalies := domain.DetectAnomalies(series, config)
+
+	// Assert
+	if len(anomalies) != 1 || anomalies[0].Metric != domain.AnomalyMetricTemperature {
+		t.Fatalf("Se esperaba una anomalía de temperatura, se obtuvo %+v", anomalies)
+	}
+}
+
+func TestAnomalyDetectingTankService_Flatline(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	anomalyRepo := repositories.NewMemoryAnomalyRepository()
+	notifier := &MockAlertNotifier{}
+
+	tankService := services.NewAnomalyDetectingTankService(
+		newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{}),
+		measurementRepo,
+		anomalyRepo,
+		notifier,
+		domain.AnomalyConfig{Window: 10, ZThreshold: 3, FlatlineCount: 5},
+	)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act: el sensor envía 8 veces exactamente la misma lectura
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 8; i++ {
+		measurement := createTestMeasurement(tank.ID, 600)
+		measurement.Timestamp = start.Add(time.Duration(i) * time.Minute)
+		if err := tankService.AddMeasurement(ctx, measurement); err != nil {
+			t.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+
+	// Assert: la anomalía se registra y se notifica una sola vez
+	anomalies, _ := anomalyRepo.GetAnomalies(ctx, tank.ID)
+	if len(anomalies) != 1 || anomalies[0].Kind != domain.AnomalyKindFlatline {
+		t.Fatalf("Se esperaba una anomalía de sensor congelado, se obtuvo %+v", anomalies)
+	}
+	if notifier.AlertsSent != 1 || notifier.LastTankID != tank.ID {
+		t.Errorf("Se esperaba una notificación de anomalía, se enviaron %d", notifier.AlertsSent)
+	}
+}
+
+func TestAnomalyDetectingTankService_IgnoresRetriesAndLateReadings(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	anomalyRepo := repositories.NewMemoryAnomalyRepository()
+	notifier := &MockAlertNotifier{}
+
+	tankService := services.NewAnomalyDetectingTankService(
+		newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{}),
+		measurementRepo,
+		anomalyRepo,
+		notifier,
+		domain.AnomalyConfig{Window: 10, ZThreshold: 3},
+	)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	series := consumptionSeries(tank.ID, time.Now().Add(-24*time.Hour), 12)
+	last := series[len(series)-1]
+	drop := &domain.Measurement{ID: "drop", TankID: tank.ID, Level: last.Level - 200, Temperature: 20, Timestamp: last.Timestamp.Add(time.Hour)}
+	for _, measurement := range append(series, drop) {
+		if err := tankService.AddMeasurement(ctx, measurement); err != nil {
+			t.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+	if anomalies, _ := anomalyRepo.GetAnomalies(ctx, tank.ID); len(anomalies) != 1 || notifier.AlertsSent != 1 {
+		t.Fatalf("Se esperaba una anomalía por la caída, hay %d y %d notificaciones", len(anomalies), notifier.AlertsSent)
+	}
+
+	// Act: el equipo reintenta la lectura de la caída y reenvía una atrasada
+	retryErr := tankService.AddMeasurement(ctx, &domain.Measurement{ID: "retry", TankID: tank.ID, Level: drop.Level, Temperature: 20, Timestamp: drop.Timestamp})
+	late := &domain.Measurement{ID: "late", TankID: tank.ID, Level: last.Level, Temperature: 20, Timestamp: last.Timestamp.Add(30 * time.Minute)}
+	lateErr := tankService.AddMeasurement(ctx, late)
+
+	// Assert: se informan como no aplicadas y no se vuelve a analizar ni a notificar la caída
+	if !errors.Is(retryErr, domain.ErrDuplicateMeasurement) || !errors.Is(lateErr, domain.ErrLateMeasurement) {
+		t.Errorf("Se esperaba ErrDuplicateMeasurement y ErrLateMeasurement, se obtuvo: %v, %v", retryErr, lateErr)
+	}
+	anomalies, _ := anomalyRepo.GetAnomalies(ctx, tank.ID)
+	if len(anomalies) != 1 || notifier.AlertsSent != 1 {
+		t.Errorf("Los reintentos no deberían generar anomalías: hay %d y %d notificaciones", len(anomalies), notifier.AlertsSent)
+	}
+}
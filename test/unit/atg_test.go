@@ -0,0 +1,168 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/atg"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/pkg/logger"
+)
+
+// atgFloat codifica un campo de inventario como lo envía la consola
+func atgFloat(value float32) string {
+	return fmt.Sprintf("%08X", math.Float32bits(value))
+}
+
+// atgResponse compone una respuesta completa de la consola con su suma de control
+func atgResponse(body string) []byte {
+	frame := append([]byte{0x01}, body+"&&"...)
+	frame = append(frame, fmt.Sprintf("%04X", atg.Checksum(frame))...)
+	return append(frame, 0x03)
+}
+
+// testInventoryResponse es el inventario de dos tanques, en galones y °F
+func testInventoryResponse() []byte {
+	tank1 := "01" + "1" + "0000" + "07" + atgFloat(1000) + atgFloat(990) + atgFloat(4000) + atgFloat(40) + atgFloat(0.5) + atgFloat(68) + atgFloat(0)
+	tank2 := "02" + "2" + "0001" + "07" + atgFloat(2500) + atgFloat(2480) + atgFloat(500) + atgFloat(80) + atgFloat(0) + atgFloat(50) + atgFloat(0)
+	return atgResponse(atg.CommandInventory + "2405011000" + tank1 + tank2)
+}
+
+func TestATG_ParseInventory(t *testing.T) {
+	// Act
+	data, err := atg.ParseResponse(testInventoryResponse(), atg.CommandInventory)
+	if err != nil {
+		t.Fatalf("Error inesperado al validar la respuesta: %v", err)
+	}
+	timestamp, inventories, err := atg.ParseInventory(data, time.UTC)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error inesperado al leer el inventario: %v", err)
+	}
+	if !timestamp.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Fecha incorrecta: %v", timestamp)
+	}
+	if len(inventories) != 2 {
+		t.Fatalf("Se esperaban 2 tanques, se obtuvieron %d", len(inventories))
+	}
+	first := inventories[0]
+	if first.Tank != 1 || first.Product != '1' || first.Volume != 1000 || first.Ullage != 4000 || first.Water != 0.5 || first.Temperature != 68 {
+		t.Errorf("Inventario incorrecto: %+v", first)
+	}
+	if inventories[1].Tank != 2 || inventories[1].Status != 1 {
+		t.Errorf("Segundo tanque incorrecto: %+v", inventories[1])
+	}
+
+	// Una respuesta alterada no supera la suma de control
+	corrupted := testInventoryResponse()
+	corrupted[10] = '9'
+	if _, err := atg.ParseResponse(corrupted, atg.CommandInventory); !errors.Is(err, atg.ErrChecksum) {
+		t.Errorf("Se esperaba ErrChecksum, se obtuvo: %v", err)
+	}
+	if _, err := atg.ParseResponse([]byte("\x019999FF1B\x03"), atg.CommandInventory); !errors.Is(err, atg.ErrUnsupportedCommand) {
+		t.Errorf("Se esperaba ErrUnsupportedCommand, se obtuvo: %v", err)
+	}
+}
+
+func TestATG_PollSavesInventoryAndNotifiesNewAlarms(t *testing.T) {
+	// Arrange: una consola que responde al inventario y al estado (fuga en el tanque 1)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error al crear la consola: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			command := make([]byte, 1+len(atg.CommandInventory))
+			if _, err := io.ReadFull(conn, command); err == nil {
+				switch string(command[1:]) {
+				case atg.CommandInventory:
+					conn.Write(testInventoryResponse())
+				case atg.CommandStatus:
+					conn.Write(atgResponse(atg.CommandStatus + "2405011000" + "010102" + "0200"))
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	config, err := atg.Parse(strings.NewReader(`
+consoles:
+  - name: estacion-norte
+    address: ` + listener.Addr().String() + `
+    interval: 5m
+    volume_unit: gallons
+    temperature_unit: F
+    tanks:
+      - {number: 1, tank_id: tanque-atg}
+`))
+	if err != nil {
+		t.Fatalf("Error inesperado al leer las consolas: %v", err)
+	}
+
+	notifier := &MockAlertNotifier{}
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	tankService.CreateTank(context.Background(), &domain.Tank{ID: "tanque-atg", Name: "Diésel", Capacity: 20000, CurrentLevel: 10000, AlertThreshold: 5})
+	collector := atg.NewCollector(tankService, notifier, atg.NewClient(2*time.Second), logger.NewSimpleLogger())
+
+	// Act
+	firstErr := collector.Poll(context.Background(), config.Consoles[0])
+	secondErr := collector.Poll(context.Background(), config.Consoles[0])
+
+	// Assert
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("Error inesperado al consultar la consola: %v, %v", firstErr, secondErr)
+	}
+	tank, _ := tankService.GetTank(context.Background(), "tanque-atg")
+	if math.Abs(tank.CurrentLevel-3785.41) > 0.01 || math.Abs(tank.Temperature-20) > 0.001 {
+		t.Errorf("Lectura incorrecta. Esperado: 3785.41 L y 20 °C, Obtenido: %.2f L y %.2f °C", tank.CurrentLevel, tank.Temperature)
+	}
+	if notifier.AlertsSent != 1 {
+		t.Fatalf("La alarma debería notificarse una sola vez, se notificó %d veces", notifier.AlertsSent)
+	}
+	alert := notifier.LastAlert
+	if alert.Type != domain.AlertEventATGAlarm || alert.Severity != domain.AlertSeverityCritical || alert.TankID != "tanque-atg" {
+		t.Errorf("Alerta incorrecta: %+v", alert)
+	}
+	if alert.Message != "La consola estacion-norte reporta una fuga en el tanque Diésel." {
+		t.Errorf("Mensaje incorrecto: %s", alert.Message)
+	}
+}
+
+func TestATG_ParseRejectsInvalidConfig(t *testing.T) {
+	cases := map[string]string{
+		"sin dirección":    "consoles:\n  - {name: a, interval: 5m, tanks: [{number: 1, tank_id: t}]}\n",
+		"sin intervalo":    "consoles:\n  - {name: a, address: 'h:1', tanks: [{number: 1, tank_id: t}]}\n",
+		"sin tanques":      "consoles:\n  - {name: a, address: 'h:1', interval: 5m}\n",
+		"número inválido":  "consoles:\n  - {name: a, address: 'h:1', interval: 5m, tanks: [{number: 0, tank_id: t}]}\n",
+		"tanque repetido":  "consoles:\n  - {name: a, address: 'h:1', interval: 5m, tanks: [{number: 1, tank_id: t}, {number: 1, tank_id: u}]}\n",
+		"unidad":           "consoles:\n  - {name: a, address: 'h:1', interval: 5m, volume_unit: barrels, tanks: [{number: 1, tank_id: t}]}\n",
+		"código seguridad": "consoles:\n  - {name: a, address: 'h:1', interval: 5m, security_code: '123', tanks: [{number: 1, tank_id: t}]}\n",
+		"duplicada":        "consoles:\n  - {name: a, address: 'h:1', interval: 5m, tanks: [{number: 1, tank_id: t}]}\n  - {name: a, address: 'h:2', interval: 5m, tanks: [{number: 1, tank_id: u}]}\n",
+	}
+
+	for name, document := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := atg.Parse(strings.NewReader(document))
+
+			// Assert
+			if !errors.Is(err, atg.ErrInvalidConfig) {
+				t.Errorf("Se esperaba ErrInvalidConfig, se obtuvo: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/adapters/storage"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestAttachmentService_UploadAndDownload(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	attachmentService := services.NewAttachmentService(
+		tankService,
+		repositories.NewMemoryAttachmentRepository(),
+		storage.NewLocalStorage(t.TempDir()),
+	)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	attachment := &domain.Attachment{
+		ID:       uuid.New().String(),
+		TankID:   tank.ID,
+		Kind:     domain.AttachmentKindCalibration,
+		FileName: `C:\certificados\calibracion.pdf`,
+	}
+
+	// Act
+	if err := attachmentService.UploadAttachment(ctx, attachment, strings.NewReader("contenido del certificado")); err != nil {
+		t.Fatalf("Error al subir el adjunto: %v", err)
+	}
+
+	// Assert
+	if attachment.FileName != "calibracion.pdf" {
+		t.Errorf("Nombre de archivo incorrecto: %s", attachment.FileName)
+	}
+	if attachment.Size != int64(len("contenido del certificado")) {
+		t.Errorf("Tamaño incorrecto: %d", attachment.Size)
+	}
+
+	stored, content, err := attachmentService.OpenAttachment(ctx, tank.ID, attachment.ID)
+	if err != nil {
+		t.Fatalf("Error al abrir el adjunto: %v", err)
+	}
+	data, _ := io.ReadAll(content)
+	content.Close()
+	if string(data) != "contenido del certificado" || stored.Kind != domain.AttachmentKindCalibration {
+		t.Errorf("Adjunto inesperado: %q (%s)", data, stored.Kind)
+	}
+
+	// El adjunto no es accesible a través de otro tanque
+	otherTank := createTestTank()
+	if err := tankService.CreateTank(ctx, otherTank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	if _, err := attachmentService.GetAttachment(ctx, otherTank.ID, attachment.ID); !errors.Is(err, services.ErrAttachmentNotFound) {
+		t.Errorf("Se esperaba ErrAttachmentNotFound, se obtuvo: %v", err)
+	}
+
+	// Al eliminarlo desaparecen los metadatos y el contenido
+	if err := attachmentService.DeleteAttachment(ctx, tank.ID, attachment.ID); err != nil {
+		t.Fatalf("Error al eliminar el adjunto: %v", err)
+	}
+	if _, _, err := attachmentService.OpenAttachment(ctx, tank.ID, attachment.ID); !errors.Is(err, services.ErrAttachmentNotFound) {
+		t.Errorf("Se esperaba ErrAttachmentNotFound tras eliminar, se obtuvo: %v", err)
+	}
+}
+
+func TestAttachmentService_RejectsInvalidKind(t *testing.T) {
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	attachmentService := services.NewAttachmentService(
+		tankService,
+		repositories.NewMemoryAttachmentRepository(),
+		storage.NewLocalStorage(t.TempDir()),
+	)
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	attachment := &domain.Attachment{ID: uuid.New().String(), TankID: tank.ID, Kind: "video", FileName: "x.mp4"}
+	err := attachmentService.UploadAttachment(context.Background(), attachment, strings.NewReader("x"))
+	if !errors.Is(err, services.ErrInvalidAttachment) {
+		t.Errorf("Se esperaba ErrInvalidAttachment, se obtuvo: %v", err)
+	}
+}
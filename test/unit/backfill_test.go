@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestTankService_BackfillMeasurements(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	notifier := &MockAlertNotifier{}
+	service := newTestTankService(tankRepo, measurementRepo, notifier)
+
+	tank := createTestTank()
+	service.CreateTank(context.Background(), tank)
+	service.AddMeasurement(context.Background(), createTestMeasurement(tank.ID, 500))
+
+	now := time.Now()
+	backfill := func() []*domain.Measurement {
+		measurements := make([]*domain.Measurement, 0, 3)
+		for i, level := range []float64{800, 50, 600} {
+			measurement := createTestMeasurement(tank.ID, level)
+			measurement.Timestamp = now.Add(-time.Duration(72-i*24) * time.Hour)
+			measurements = append(measurements, measurement)
+		}
+		return measurements
+	}
+
+	// Act
+	result, err := service.BackfillMeasurements(context.Background(), tank.ID, backfill())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	if result.Imported != 3 || result.Duplicates != 0 || result.CurrentLevelUpdated {
+		t.Errorf("Resultado incorrecto: %+v", result)
+	}
+	if notifier.AlertsSent != 0 {
+		t.Errorf("El historial no debería generar alertas, se enviaron %d", notifier.AlertsSent)
+	}
+	current, _ := service.GetTank(context.Background(), tank.ID)
+	if current.CurrentLevel != 500 {
+		t.Errorf("El historial no debería reemplazar el nivel actual, más reciente: %.0f", current.CurrentLevel)
+	}
+	stored, _ := measurementRepo.GetMeasurementsByTankID(context.Background(), tank.ID, 0)
+	if len(stored) != 4 || stored[0].Level != 500 || stored[1].Level != 600 {
+		t.Errorf("Se esperaban 4 mediciones ordenadas con la más reciente primero: %d", len(stored))
+	}
+
+	repeated, err := service.BackfillMeasurements(context.Background(), tank.ID, backfill())
+	if err != nil || repeated.Imported != 0 || repeated.Duplicates != 3 {
+		t.Errorf("Repetir la importación no debería duplicar mediciones: %+v, %v", repeated, err)
+	}
+}
+
+func TestTankService_BackfillMeasurements_UpdatesCurrentLevelWithoutNewerMeasurements(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	notifier := &MockAlertNotifier{}
+	service := newTestTankService(tankRepo, measurementRepo, notifier)
+
+	tank := createTestTank()
+	service.CreateTank(context.Background(), tank)
+
+	measurement := createTestMeasurement(tank.ID, 50)
+	measurement.Timestamp = time.Now().Add(-time.Hour)
+
+	// Act
+	result, err := service.BackfillMeasurements(context.Background(), tank.ID, []*domain.Measurement{measurement})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	if !result.CurrentLevelUpdated {
+		t.Error("Sin mediciones posteriores, la importada más reciente debería fijar el nivel actual")
+	}
+	current, _ := service.GetTank(context.Background(), tank.ID)
+	if current.CurrentLevel != 50 || current.Status != "critical" {
+		t.Errorf("Nivel o estado incorrectos: %.0f, %s", current.CurrentLevel, current.Status)
+	}
+	if notifier.AlertsSent != 0 {
+		t.Errorf("El historial no debería generar alertas, se enviaron %d", notifier.AlertsSent)
+	}
+}
+
+func TestTankService_BackfillMeasurements_RejectsInvalidMeasurements(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	service := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+
+	tank := createTestTank()
+	service.CreateTank(context.Background(), tank)
+
+	future := createTestMeasurement(tank.ID, 400)
+	future.Timestamp = time.Now().Add(time.Hour)
+	negative := createTestMeasurement(tank.ID, -1)
+	negative.Timestamp = time.Now().Add(-time.Hour)
+
+	// Act & Assert
+	for name, measurements := range map[string][]*domain.Measurement{
+		"vacío":    nil,
+		"futuro":   {future},
+		"negativo": {negative},
+	} {
+		if _, err := service.BackfillMeasurements(context.Background(), tank.ID, measurements); !errors.Is(err, services.ErrInvalidBackfill) {
+			t.Errorf("%s: se esperaba ErrInvalidBackfill, se obtuvo %v", name, err)
+		}
+	}
+}
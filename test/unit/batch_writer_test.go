@@ -0,0 +1,196 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/ingest"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+func TestBatchWriter_FlushesPendingMeasurementsOnClose(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	alertNotifier := &MockAlertNotifier{}
+
+	tankService := newTestTankService(tankRepo, measurementRepo, alertNotifier)
+	writer := ingest.NewBatchWriter(tankService, ingest.BatchConfig{
+		Size:          100,
+		FlushInterval: time.Hour,
+	}, logger.NewSimpleLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	writer.Start(ctx)
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	base := time.Now().Add(-time.Minute)
+	for i, level := range []float64{450.0, 400.0, 50.0} {
+		measurement := createTestMeasurement(tank.ID, level)
+		measurement.Timestamp = base.Add(time.Duration(i) * time.Second)
+		if err := writer.AddMeasurement(context.Background(), measurement); err != nil {
+			t.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+
+	// Las mediciones siguen en el búfer hasta la siguiente escritura
+	if stored, _ := measurementRepo.GetMeasurementsByTankID(context.Background(), tank.ID, 0); len(stored) != 0 {
+		t.Fatalf("No se esperaban mediciones guardadas antes de la escritura, se obtuvieron %d", len(stored))
+	}
+
+	// Act: el apagado guarda lo pendiente
+	cancel()
+	if err := writer.Close(context.Background()); err != nil {
+		t.Fatalf("Error al cerrar el escritor: %v", err)
+	}
+
+	// Assert
+	stored, err := measurementRepo.GetMeasurementsByTankID(context.Background(), tank.ID, 0)
+	if err != nil {
+		t.Fatalf("Error al obtener las mediciones: %v", err)
+	}
+	if len(stored) != 3 {
+		t.Errorf("Se esperaban 3 mediciones guardadas, se obtuvieron %d", len(stored))
+	}
+
+	updatedTank, err := tankService.GetTank(context.Background(), tank.ID)
+	if err != nil {
+		t.Fatalf("Error al obtener el tanque: %v", err)
+	}
+	if updatedTank.CurrentLevel != 50.0 {
+		t.Errorf("Se esperaba el nivel de la última medición (50), se obtuvo %.2f", updatedTank.CurrentLevel)
+	}
+
+	// Una sola alerta por tanque y lote
+	if alertNotifier.AlertsSent != 1 {
+		t.Errorf("Se esperaba 1 alerta, se enviaron %d", alertNotifier.AlertsSent)
+	}
+}
+
+func TestBatchWriter_RejectsUnknownTank(t *testing.T) {
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	writer := ingest.NewBatchWriter(tankService, ingest.BatchConfig{Size: 10}, logger.NewSimpleLogger())
+
+	if err := writer.AddMeasurement(context.Background(), createTestMeasurement("inexistente", 100.0)); err == nil {
+		t.Error("Se esperaba un error al añadir una medición a un tanque inexistente")
+	}
+}
+
+// disconnectingTankService simula un cliente que se desconecta justo después de comprobarse el
+// tanque de su medición
+type disconnectingTankService struct {
+	ports.TankService
+	disconnect context.CancelFunc
+}
+
+func (s *disconnectingTankService) GetTank(ctx context.Context, id string) (*domain.Tank, error) {
+	tank, err := s.TankService.GetTank(ctx, id)
+	if s.disconnect != nil {
+		s.disconnect()
+	}
+	return tank, err
+}
+
+func TestBatchWriter_OverflowFlushIgnoresCallerCancellation(t *testing.T) {
+	// Arrange: el cuarto envío llena el búfer y escribe el lote quien lo añade
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+
+	tankService := &disconnectingTankService{TankService: newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})}
+	writer := ingest.NewBatchWriter(tankService, ingest.BatchConfig{Size: 1, FlushInterval: time.Hour}, logger.NewSimpleLogger())
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	base := time.Now().Add(-time.Minute)
+	for i := 0; i < 3; i++ {
+		measurement := createTestMeasurement(tank.ID, 400)
+		measurement.Timestamp = base.Add(time.Duration(i) * time.Second)
+		if err := writer.AddMeasurement(context.Background(), measurement); err != nil {
+			t.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+
+	// Act: el cliente que provoca la escritura se desconecta tras validarse su medición
+	ctx, cancel := context.WithCancel(context.Background())
+	tankService.disconnect = cancel
+	measurement := createTestMeasurement(tank.ID, 300)
+	measurement.Timestamp = base.Add(3 * time.Second)
+	err := writer.AddMeasurement(ctx, measurement)
+
+	// Assert: no se pierde ninguna de las mediciones ya aceptadas
+	if err != nil {
+		t.Fatalf("Error inesperado al escribir el lote: %v", err)
+	}
+	if stored, _ := measurementRepo.GetMeasurementsByTankID(context.Background(), tank.ID, 0); len(stored) != 4 {
+		t.Errorf("Se esperaban 4 mediciones guardadas, se obtuvieron %d", len(stored))
+	}
+}
+
+func TestBatchWriter_KeepsMeasurementsWhenStorageFailsTemporarily(t *testing.T) {
+	// Arrange: dos tanques, uno de los cuales se elimina con una medición ya aceptada
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	flaky := &flakyTankService{TankService: newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})}
+	writer := ingest.NewBatchWriter(flaky, ingest.BatchConfig{Size: 100, FlushInterval: time.Hour}, logger.NewSimpleLogger())
+	ctx := context.Background()
+
+	kept := createTestTank()
+	removed := createTestTank()
+	removed.ID = "tank-eliminado"
+	for _, tank := range []*domain.Tank{kept, removed} {
+		if err := flaky.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+	}
+
+	base := time.Now().Add(-time.Minute)
+	for i, tankID := range []string{kept.ID, removed.ID, kept.ID} {
+		measurement := createTestMeasurement(tankID, 400-float64(i))
+		measurement.Timestamp = base.Add(time.Duration(i) * time.Second)
+		if err := writer.AddMeasurement(ctx, measurement); err != nil {
+			t.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+	if err := flaky.DeleteTank(ctx, removed.ID); err != nil {
+		t.Fatalf("Error al eliminar el tanque: %v", err)
+	}
+
+	// Act: la escritura falla mientras el almacenamiento no está disponible
+	flaky.down = true
+	downErr := writer.Flush(ctx)
+
+	// Assert: no se pierde ninguna medición
+	if downErr == nil {
+		t.Error("Se esperaba un error con el almacenamiento caído")
+	}
+	if stored, _ := measurementRepo.GetMeasurementsByTankID(ctx, kept.ID, 0); len(stored) != 0 {
+		t.Fatalf("No se esperaban mediciones guardadas, se obtuvieron %d", len(stored))
+	}
+
+	// Act: la siguiente escritura, con el almacenamiento disponible
+	flaky.down = false
+	writer.Flush(ctx)
+
+	// Assert: se guardan las del tanque existente en orden y solo se descarta la del eliminado
+	stored, _ := measurementRepo.GetMeasurementsByTankID(ctx, kept.ID, 0)
+	if len(stored) != 2 || stored[0].Level != 398 {
+		t.Fatalf("Se esperaban las 2 mediciones del tanque, la última con nivel 398: %+v", stored)
+	}
+	if err := writer.Flush(ctx); err != nil {
+		t.Errorf("No deberían quedar mediciones en el búfer: %v", err)
+	}
+}
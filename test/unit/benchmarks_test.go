@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+// benchmarkTanks es el número de tanques sobre los que se reparten las mediciones
+const benchmarkTanks = 100
+
+// newBenchmarkTankService crea el servicio con benchmarkTanks tanques ya registrados
+func newBenchmarkTankService(b *testing.B) (ports.TankService, []*domain.Tank) {
+	b.Helper()
+
+	service := newTestTankService(
+		repositories.NewMemoryTankRepository(),
+		repositories.NewMemoryMeasurementRepository(),
+		&MockAlertNotifier{},
+	)
+
+	tanks := make([]*domain.Tank, benchmarkTanks)
+	for i := range tanks {
+		tank := createTestTank()
+		tank.Name = fmt.Sprintf("Tanque %d", i)
+		if err := service.CreateTank(context.Background(), tank); err != nil {
+			b.Fatalf("Error al crear el tanque: %v", err)
+		}
+		tanks[i] = tank
+	}
+
+	return service, tanks
+}
+
+// benchmarkMeasurement crea la medición i-ésima, repartida entre los tanques en orden
+func benchmarkMeasurement(tanks []*domain.Tank, i int, base time.Time) *domain.Measurement {
+	measurement := createTestMeasurement(tanks[i%len(tanks)].ID, float64(100+i%800))
+	measurement.Timestamp = base.Add(time.Duration(i) * time.Millisecond)
+	return measurement
+}
+
+func BenchmarkTankService_AddMeasurement(b *testing.B) {
+	service, tanks := newBenchmarkTankService(b)
+	ctx := context.Background()
+	base := time.Now()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := service.AddMeasurement(ctx, benchmarkMeasurement(tanks, i, base)); err != nil {
+			b.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+}
+
+func BenchmarkTankService_AddMeasurementsBatch(b *testing.B) {
+	service, tanks := newBenchmarkTankService(b)
+	ctx := context.Background()
+	base := time.Now()
+	const batchSize = 500
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	batch := make([]*domain.Measurement, 0, batchSize)
+	for i := 0; i < b.N; i++ {
+		batch = append(batch, benchmarkMeasurement(tanks, i, base))
+		if len(batch) == batchSize || i == b.N-1 {
+			if err := service.AddMeasurements(ctx, batch); err != nil {
+				b.Fatalf("Error al añadir el lote: %v", err)
+			}
+			batch = batch[:0]
+		}
+	}
+}
+
+func BenchmarkTankService_GetAllTanks(b *testing.B) {
+	service, tanks := newBenchmarkTankService(b)
+	ctx := context.Background()
+	base := time.Now()
+
+	// Cada tanque tiene historial para que GetAllTanks consulte la última medición real
+	for i := 0; i < benchmarkTanks*10; i++ {
+		if err := service.AddMeasurement(ctx, benchmarkMeasurement(tanks, i, base)); err != nil {
+			b.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := service.GetAllTanks(ctx); err != nil {
+			b.Fatalf("Error al obtener los tanques: %v", err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"monitor-tanques/internal/adapters/auth"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/cli"
+)
+
+func TestCLIApp_DispatchesCommands(t *testing.T) {
+	// Arrange
+	var ran, name string
+	var plan bool
+	var output bytes.Buffer
+	app := &cli.App{
+		Name:   "monitor-tanques",
+		Output: &output,
+		Default: &cli.Command{
+			Name:  "serve",
+			Flags: func(flags *flag.FlagSet) { flags.BoolVar(&plan, "plan", false, "") },
+			Run:   func(ctx context.Context, args []string) error { ran = "serve"; return nil },
+		},
+		Commands: []*cli.Command{{
+			Name:  "create-admin",
+			Short: "Emite un token",
+			Flags: func(flags *flag.FlagSet) { flags.StringVar(&name, "name", "admin", "") },
+			Run:   func(ctx context.Context, args []string) error { ran = "create-admin"; return nil },
+		}},
+	}
+	ctx := context.Background()
+
+	// Act & Assert
+	if err := app.Run(ctx, []string{"-plan"}); err != nil || ran != "serve" || !plan {
+		t.Errorf("Las opciones sin comando deberían ir al comando predeterminado: %q %v %v", ran, plan, err)
+	}
+	if err := app.Run(ctx, []string{"create-admin", "-name", "ops"}); err != nil || ran != "create-admin" || name != "ops" {
+		t.Errorf("No se ejecutó create-admin con sus opciones: %q %q %v", ran, name, err)
+	}
+	if err := app.Run(ctx, []string{"create-admin", "-rol", "x"}); !errors.Is(err, cli.ErrUsage) {
+		t.Errorf("Se esperaba ErrUsage con una opción desconocida, se obtuvo: %v", err)
+	}
+	output.Reset()
+	if err := app.Run(ctx, []string{"borrar-todo"}); !errors.Is(err, cli.ErrUsage) || !strings.Contains(output.String(), "create-admin  Emite un token") {
+		t.Errorf("Un comando desconocido debería mostrar la ayuda: %v\n%s", err, output.String())
+	}
+}
+
+func TestAPITokenAuthenticator(t *testing.T) {
+	// Arrange
+	tokenRepo := repositories.NewMemoryAPITokenRepository()
+	tokenService := services.NewAPITokenService(tokenRepo)
+	authenticator := auth.NewAPITokenAuthenticator(tokenRepo, nil)
+	ctx := context.Background()
+
+	// Act
+	token, plain, err := tokenService.CreateToken(ctx, " ops ", domain.RoleAdmin, nil)
+	principal, authErr := authenticator.Authenticate(ctx, plain)
+	_, unknownErr := authenticator.Authenticate(ctx, domain.APITokenPrefix+"otro")
+	_, foreignErr := authenticator.Authenticate(ctx, "eyJhbGciOiJSUzI1NiJ9.e30.firma")
+	_, _, invalidErr := tokenService.CreateToken(ctx, "ops", "superusuario", nil)
+
+	// Assert
+	if err != nil || token.Name != "ops" || !strings.HasPrefix(plain, domain.APITokenPrefix) || token.Hash == plain {
+		t.Fatalf("Token creado incorrectamente: %+v, %v", token, err)
+	}
+	if authErr != nil || principal.Subject != "token:"+token.ID || !principal.HasRole(domain.RoleAdmin) {
+		t.Errorf("El token debería autenticar como administrador: %+v, %v", principal, authErr)
+	}
+	if !errors.Is(unknownErr, auth.ErrInvalidToken) || !errors.Is(foreignErr, auth.ErrInvalidToken) {
+		t.Errorf("Se esperaba ErrInvalidToken: %v, %v", unknownErr, foreignErr)
+	}
+	if !errors.Is(invalidErr, services.ErrInvalidAPIToken) {
+		t.Errorf("Se esperaba ErrInvalidAPIToken con un rol desconocido, se obtuvo: %v", invalidErr)
+	}
+}
+
+func TestAPITokenService_Scopes(t *testing.T) {
+	// Arrange
+	tokenRepo := repositories.NewMemoryAPITokenRepository()
+	tokenService := services.NewAPITokenService(tokenRepo)
+	authenticator := auth.NewAPITokenAuthenticator(tokenRepo, nil)
+	ctx := context.Background()
+
+	// Act
+	token, plain, err := tokenService.CreateToken(ctx, "grafana", "", []string{domain.ScopeReadTanks})
+	principal, _ := authenticator.Authenticate(ctx, plain)
+	_, _, unknownErr := tokenService.CreateToken(ctx, "otro", "", []string{"delete:everything"})
+	_, _, roleErr := tokenService.CreateToken(ctx, "otro", domain.RoleViewer, []string{domain.ScopeWriteMeasurements})
+	revokeErr := tokenService.RevokeToken(ctx, token.ID)
+	_, revokedErr := authenticator.Authenticate(ctx, plain)
+
+	// Assert
+	if err != nil || token.Role != domain.RoleViewer {
+		t.Fatalf("Sin rol debería usarse el mínimo de los alcances: %+v, %v", token, err)
+	}
+	if !principal.HasScope(domain.ScopeReadTanks) || principal.HasScope(domain.ScopeWriteTanks) {
+		t.Errorf("El principal debería limitarse a sus alcances: %v", principal.Scopes)
+	}
+	if !errors.Is(unknownErr, services.ErrInvalidAPIToken) || !errors.Is(roleErr, services.ErrInvalidAPIToken) {
+		t.Errorf("Se esperaba ErrInvalidAPIToken: %v, %v", unknownErr, roleErr)
+	}
+	if revokeErr != nil || !errors.Is(revokedErr, auth.ErrInvalidToken) {
+		t.Errorf("El token revocado no debería autenticar: %v, %v", revokeErr, revokedErr)
+	}
+	if err := tokenService.RevokeToken(ctx, token.ID); !errors.Is(err, services.ErrAPITokenNotFound) {
+		t.Errorf("Se esperaba ErrAPITokenNotFound, se obtuvo: %v", err)
+	}
+}
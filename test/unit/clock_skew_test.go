@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestSensorClock_SingleLateReadingDoesNotFlagDrift(t *testing.T) {
+	// Arrange
+	config := domain.ClockSkewConfig{DriftThreshold: 2 * time.Minute}
+	clock := &domain.SensorClock{}
+	now := time.Now()
+
+	// Act: reloj en hora y una lectura reenviada con diez minutos de retraso
+	clock.Observe(time.Second, now, config)
+	clock.Observe(-10*time.Minute, now, config)
+
+	// Assert
+	if clock.Drifting {
+		t.Errorf("Una lectura aislada no debería marcar el reloj como desajustado: %+v", clock)
+	}
+	if clock.Offset != -600 || clock.MaxOffset != 600 || clock.Samples != 2 {
+		t.Errorf("Desfase registrado incorrecto: %+v", clock)
+	}
+
+	// Act: el retraso se mantiene
+	for i := 0; i < 10; i++ {
+		clock.Observe(-10*time.Minute, now, config)
+	}
+
+	// Assert
+	if !clock.Drifting {
+		t.Errorf("Un desfase sostenido debería marcar el reloj como desajustado: %+v", clock)
+	}
+}
+
+func TestClockSkewTankService_TracksAndCorrectsSkewedSensors(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	tracker := services.NewClockSkewTracker(domain.ClockSkewConfig{
+		DriftThreshold:      2 * time.Minute,
+		CorrectionThreshold: 30 * time.Minute,
+	})
+	skewService := services.NewClockSkewTankService(tankService, tracker)
+	clockService := services.NewClockSkewService(tankService, tracker)
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act: el sensor "radar" adelanta dos horas y envía dos lecturas separadas 5 minutos; el
+	// sensor principal del tanque está en hora
+	ahead := time.Now().Add(2 * time.Hour)
+	first := createTestMeasurement(tank.ID, 450.0)
+	first.SensorID = "radar"
+	first.Timestamp = ahead.Add(-5 * time.Minute)
+	second := createTestMeasurement(tank.ID, 440.0)
+	second.SensorID = "radar"
+	second.Timestamp = ahead
+	if err := skewService.AddMeasurements(context.Background(), []*domain.Measurement{first, second}); err != nil {
+		t.Fatalf("Error al añadir las mediciones: %v", err)
+	}
+
+	onTime := createTestMeasurement(tank.ID, 430.0)
+	onTime.Timestamp = time.Now()
+	if err := skewService.AddMeasurement(context.Background(), onTime); err != nil {
+		t.Fatalf("Error al añadir la medición: %v", err)
+	}
+
+	clocks, err := clockService.GetSensorClocks(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Error al obtener el reloj de los sensores: %v", err)
+	}
+	drifting, _ := clockService.GetSensorClocks(context.Background(), true)
+
+	// Assert
+	if second.Timestamp.After(time.Now()) || second.Timestamp.Sub(first.Timestamp) != 5*time.Minute {
+		t.Errorf("Las lecturas desfasadas deberían corregirse conservando su intervalo: %v, %v", first.Timestamp, second.Timestamp)
+	}
+	if len(clocks) != 2 || clocks[0].SensorID != "radar" || clocks[0].Corrected != 2 || clocks[0].Offset < 7100 {
+		t.Fatalf("Relojes incorrectos: %+v", clocks)
+	}
+	if clocks[1].SensorID != tank.ID || clocks[1].Drifting || clocks[1].Corrected != 0 {
+		t.Errorf("El sensor en hora no debería marcarse ni corregirse: %+v", clocks[1])
+	}
+	if len(drifting) != 1 || drifting[0].SensorID != "radar" {
+		t.Errorf("Solo el sensor adelantado debería estar desajustado: %+v", drifting)
+	}
+}
@@ -0,0 +1,113 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestRollupCompartments(t *testing.T) {
+	// Arrange
+	parent := &domain.Tank{ID: "cisterna", Name: "Cisterna 12"}
+	compartments := []*domain.Tank{
+		{ID: "c2", Name: "Compartimento 2", ParentID: "cisterna", Capacity: 6000, CurrentLevel: 300, LiquidType: "Gasolina", Status: "critical"},
+		{ID: "c1", Name: "Compartimento 1", ParentID: "cisterna", Capacity: 8000, CurrentLevel: 6000, LiquidType: "Diésel", Status: "normal"},
+		{ID: "c3", Name: "Compartimento 3", ParentID: "cisterna", Capacity: 6000, CurrentLevel: 1700, LiquidType: "Diésel", Status: "warning"},
+	}
+
+	// Act
+	rollup := domain.RollupCompartments(parent, compartments)
+
+	// Assert
+	if rollup.Capacity != 20000 || rollup.CurrentLevel != 8000 || rollup.Percentage != 40 {
+		t.Errorf("Totales incorrectos: capacidad %v, nivel %v, porcentaje %v", rollup.Capacity, rollup.CurrentLevel, rollup.Percentage)
+	}
+	if rollup.Status != "critical" || rollup.Critical != 1 {
+		t.Errorf("Se esperaba el estado crítico de un compartimento: %s, %d", rollup.Status, rollup.Critical)
+	}
+	if len(rollup.LiquidTypes) != 2 || rollup.LiquidTypes[0] != "Diésel" || rollup.LiquidTypes[1] != "Gasolina" {
+		t.Errorf("Líquidos incorrectos: %v", rollup.LiquidTypes)
+	}
+	if rollup.Compartments[0].ID != "c1" || rollup.Compartments[2].ID != "c3" {
+		t.Errorf("Los compartimentos deberían ordenarse por nombre")
+	}
+
+	empty := domain.RollupCompartments(parent, nil)
+	if empty.Status != "normal" || empty.Percentage != 0 || len(empty.Compartments) != 0 {
+		t.Errorf("Un tanque sin compartimentos debería tener un resumen vacío: %+v", empty)
+	}
+}
+
+func TestTankService_Compartments(t *testing.T) {
+	// Arrange
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	compartmentService := services.NewCompartmentService(tankService)
+	ctx := context.Background()
+
+	parent := createTestTank()
+	if err := tankService.CreateTank(ctx, parent); err != nil {
+		t.Fatalf("Error al crear el tanque padre: %v", err)
+	}
+	compartment := createTestTank()
+	compartment.ParentID = parent.ID
+	if err := tankService.CreateTank(ctx, compartment); err != nil {
+		t.Fatalf("Error al crear el compartimento: %v", err)
+	}
+	if err := tankService.AddMeasurement(ctx, createTestMeasurement(compartment.ID, 250)); err != nil {
+		t.Fatalf("Error al registrar la medición: %v", err)
+	}
+
+	other := createTestTank()
+	if err := tankService.CreateTank(ctx, other); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act
+	rollup, rollupErr := compartmentService.GetCompartments(ctx, parent.ID)
+	_, nestedRollupErr := compartmentService.GetCompartments(ctx, compartment.ID)
+
+	nested := createTestTank()
+	nested.ParentID = compartment.ID
+	nestedErr := tankService.CreateTank(ctx, nested)
+
+	orphan := createTestTank()
+	orphan.ParentID = "no-existe"
+	orphanErr := tankService.CreateTank(ctx, orphan)
+
+	demoted := *parent
+	demoted.ParentID = other.ID
+	demoteErr := tankService.UpdateTank(ctx, &demoted)
+
+	deleteErr := tankService.DeleteTank(ctx, parent.ID)
+
+	// Assert
+	if rollupErr != nil {
+		t.Fatalf("Error al obtener los compartimentos: %v", rollupErr)
+	}
+	if len(rollup.Compartments) != 1 || rollup.CurrentLevel != 250 || rollup.Capacity != 1000 {
+		t.Errorf("Resumen incorrecto: %+v", rollup)
+	}
+	if !errors.Is(nestedRollupErr, services.ErrInvalidTank) {
+		t.Errorf("Un compartimento no tiene compartimentos, se obtuvo %v", nestedRollupErr)
+	}
+	if !errors.Is(nestedErr, services.ErrInvalidTank) || !errors.Is(orphanErr, services.ErrInvalidTank) {
+		t.Errorf("Se esperaba ErrInvalidTank con un padre inválido: %v, %v", nestedErr, orphanErr)
+	}
+	if !errors.Is(demoteErr, services.ErrTankHasCompartments) {
+		t.Errorf("Un tanque con compartimentos no puede pasar a ser uno, se obtuvo %v", demoteErr)
+	}
+	if !errors.Is(deleteErr, services.ErrTankHasCompartments) {
+		t.Errorf("Se esperaba ErrTankHasCompartments al eliminar el padre, se obtuvo %v", deleteErr)
+	}
+
+	if err := tankService.DeleteTank(ctx, compartment.ID); err != nil {
+		t.Fatalf("Error al eliminar el compartimento: %v", err)
+	}
+	if err := tankService.DeleteTank(ctx, parent.ID); err != nil {
+		t.Errorf("Sin compartimentos, el padre debería poder eliminarse: %v", err)
+	}
+}
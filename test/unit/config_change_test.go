@@ -0,0 +1,84 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestTankConfigChanges(t *testing.T) {
+	// Arrange
+	before := createTestTank()
+	after := *before
+	after.AlertThreshold = 5
+	after.Labels = domain.Labels{"zona": "norte"}
+	after.CurrentLevel = 300
+
+	// Act
+	change := &domain.TankConfigChange{Changes: domain.TankConfigChanges(before, &after)}
+
+	// Assert
+	if len(change.Changes) != 2 {
+		t.Fatalf("Se esperaban 2 campos cambiados (el nivel no es configuración), se obtuvieron %d", len(change.Changes))
+	}
+	if change.Severity() != domain.AlertSeverityWarning {
+		t.Errorf("Un cambio de umbral debería notificarse como aviso, se obtuvo %s", change.Severity())
+	}
+	if summary := change.Summary(); summary != "labels: - → zona=norte; alert_threshold: 10 → 5" {
+		t.Errorf("Resumen incorrecto: %q", summary)
+	}
+
+	renamed := *before
+	renamed.Name = "Otro nombre"
+	if severity := (&domain.TankConfigChange{Changes: domain.TankConfigChanges(before, &renamed)}).Severity(); severity != domain.AlertSeverityInfo {
+		t.Errorf("Un cambio de nombre debería ser informativo, se obtuvo %s", severity)
+	}
+}
+
+func TestConfigNotifyingTankService_UpdateTank(t *testing.T) {
+	// Arrange
+	notifier := &MockAlertNotifier{}
+	inner := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	service := services.NewConfigNotifyingTankService(inner, notifier)
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{Subject: "u1", Name: "Operador"})
+
+	tank := createTestTank()
+	if err := service.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act
+	unchanged := *tank
+	unchangedErr := service.UpdateTank(ctx, &unchanged)
+	alertsAfterUnchanged := notifier.AlertsSent
+
+	raised := *tank
+	raised.AlertThreshold = 25
+	raisedErr := service.UpdateTank(ctx, &raised)
+
+	// Assert
+	if unchangedErr != nil || raisedErr != nil {
+		t.Fatalf("Error al actualizar el tanque: %v, %v", unchangedErr, raisedErr)
+	}
+	if alertsAfterUnchanged != 0 {
+		t.Errorf("Una actualización sin cambios de configuración no debería notificarse")
+	}
+	if notifier.AlertsSent != 1 {
+		t.Fatalf("Se esperaba un aviso, se enviaron %d", notifier.AlertsSent)
+	}
+
+	alert := notifier.LastAlert
+	if alert.Type != domain.AlertEventConfigChanged || alert.Severity != domain.AlertSeverityWarning || alert.TankID != tank.ID {
+		t.Errorf("Aviso incorrecto: %s, %s, %s", alert.Type, alert.Severity, alert.TankID)
+	}
+	if alert.Message != "Configuración del tanque Tanque de Prueba cambiada por Operador: alert_threshold: 10 → 25." {
+		t.Errorf("Mensaje incorrecto: %q", alert.Message)
+	}
+	change := alert.ConfigChange
+	if change == nil || change.ChangedBy != "Operador" || len(change.Changes) != 1 || change.Changes[0].Before != 10.0 || change.Changes[0].After != 25.0 {
+		t.Errorf("Detalle del cambio incorrecto: %+v", change)
+	}
+}
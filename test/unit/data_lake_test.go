@@ -0,0 +1,188 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/datalake"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/adapters/storage"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/parquet"
+)
+
+// failingDataLakeWriter falla la escritura de archivos mientras fail es true, o solo la de los días
+// desde failFrom si no es cero
+type failingDataLakeWriter struct {
+	*datalake.Writer
+	fail     bool
+	failFrom time.Time
+}
+
+func (w *failingDataLakeWriter) WriteMeasurements(ctx context.Context, day time.Time, name string, measurements []*domain.Measurement) (string, error) {
+	if w.fail || (!w.failFrom.IsZero() && !day.Before(w.failFrom)) {
+		return "", errors.New("bucket unavailable")
+	}
+	return w.Writer.WriteMeasurements(ctx, day, name, measurements)
+}
+
+// newDataLakeFixture crea un tanque con mediciones a ambos lados de la medianoche UTC
+func newDataLakeFixture(t *testing.T) (*repositories.MemoryTankRepository, *repositories.MemoryMeasurementRepository) {
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tank := createTestTank()
+	if err := tankRepo.SaveTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	voltage := 3.4
+	for i, ts := range []time.Time{
+		time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC),
+		time.Date(2026, 3, 2, 0, 30, 0, 0, time.UTC),
+	} {
+		measurement := createTestMeasurement(tank.ID, float64(500-i*10))
+		measurement.Timestamp = ts
+		measurement.BatteryVoltage = &voltage
+		if err := measurementRepo.SaveMeasurement(context.Background(), measurement); err != nil {
+			t.Fatalf("Error al guardar la medición: %v", err)
+		}
+	}
+	return tankRepo, measurementRepo
+}
+
+func TestDataLakeExport_WritesDailyPartitionsAndAdvancesCheckpoint(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	tankRepo, measurementRepo := newDataLakeFixture(t)
+	writer := datalake.NewWriter(storage.NewLocalStorage(dir), "lake")
+	exportService := services.NewDataLakeExportService(tankRepo, measurementRepo, writer, 0)
+
+	// Act
+	export, err := exportService.Export(context.Background())
+
+	// Assert: un archivo por día, con la partición en la ruta
+	if err != nil {
+		t.Fatalf("Error inesperado al exportar: %v", err)
+	}
+	if export.Measurements != 3 || len(export.Files) != 2 || !export.From.IsZero() {
+		t.Fatalf("Exportación incorrecta: %+v", export)
+	}
+	for i, day := range []string{"dt=2026-03-01", "dt=2026-03-02"} {
+		if filepath.Base(filepath.Dir(export.Files[i])) != day {
+			t.Errorf("Partición incorrecta: %s", export.Files[i])
+		}
+		content, err := os.ReadFile(filepath.Join(dir, export.Files[i]))
+		if err != nil {
+			t.Fatalf("No se escribió el archivo %s: %v", export.Files[i], err)
+		}
+		if !bytes.HasPrefix(content, []byte("PAR1")) || !bytes.HasSuffix(content, []byte("PAR1")) ||
+			!bytes.Contains(content, []byte("battery_voltage")) {
+			t.Errorf("El archivo %s no es un Parquet válido", export.Files[i])
+		}
+	}
+	checkpoint, err := exportService.GetCheckpoint(context.Background())
+	if err != nil || !checkpoint.ExportedUntil.Equal(export.To) || checkpoint.PendingUntil != nil || checkpoint.LastExport == nil {
+		t.Fatalf("Punto de control incorrecto: %+v %v", checkpoint, err)
+	}
+
+	// Act: otra exportación sin mediciones nuevas
+	time.Sleep(time.Second)
+	next, err := exportService.Export(context.Background())
+
+	// Assert: no vuelve a exportar lo ya exportado
+	if err != nil {
+		t.Fatalf("Error inesperado al exportar: %v", err)
+	}
+	if next.Measurements != 0 || len(next.Files) != 0 || !next.From.Equal(export.To) {
+		t.Errorf("Solo deberían exportarse las mediciones nuevas: %+v", next)
+	}
+}
+
+func TestDataLakeExport_RetryReusesPendingCutoff(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	tankRepo, measurementRepo := newDataLakeFixture(t)
+	writer := &failingDataLakeWriter{Writer: datalake.NewWriter(storage.NewLocalStorage(dir), ""), fail: true}
+	exportService := services.NewDataLakeExportService(tankRepo, measurementRepo, writer, 0)
+
+	// Act
+	_, err := exportService.Export(context.Background())
+
+	// Assert: el corte del primer día queda pendiente y el punto de control no avanza
+	if err == nil {
+		t.Fatal("Se esperaba un error al fallar la escritura")
+	}
+	checkpoint, _ := exportService.GetCheckpoint(context.Background())
+	if checkpoint.PendingUntil == nil || !checkpoint.ExportedUntil.IsZero() {
+		t.Fatalf("El corte debería quedar pendiente: %+v", checkpoint)
+	}
+	pending := *checkpoint.PendingUntil
+
+	// Act: el reintento, más tarde
+	time.Sleep(time.Second)
+	writer.fail = false
+	export, err := exportService.Export(context.Background())
+
+	// Assert: repite el mismo corte, con el mismo nombre de archivo, antes de seguir
+	if err != nil {
+		t.Fatalf("Error inesperado al reintentar: %v", err)
+	}
+	if export.Measurements != 3 || len(export.Files) != 2 {
+		t.Fatalf("Exportación incorrecta: %+v", export)
+	}
+	if want := "part-" + pending.Format("20060102T150405Z") + "-0001.parquet"; filepath.Base(export.Files[0]) != want {
+		t.Errorf("El reintento debería repetir el corte pendiente %s: %s", want, export.Files[0])
+	}
+}
+
+func TestDataLakeExport_AdvancesOneDayAtATime(t *testing.T) {
+	// Arrange: el segundo día no puede escribirse
+	dir := t.TempDir()
+	tankRepo, measurementRepo := newDataLakeFixture(t)
+	writer := &failingDataLakeWriter{
+		Writer:   datalake.NewWriter(storage.NewLocalStorage(dir), ""),
+		failFrom: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+	}
+	exportService := services.NewDataLakeExportService(tankRepo, measurementRepo, writer, 0)
+
+	// Act
+	_, err := exportService.Export(context.Background())
+
+	// Assert: el primer día queda exportado y solo el segundo pendiente
+	if err == nil {
+		t.Fatal("Se esperaba un error al fallar la escritura del segundo día")
+	}
+	checkpoint, _ := exportService.GetCheckpoint(context.Background())
+	endOfFirstDay := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC).Add(-time.Nanosecond)
+	endOfSecondDay := endOfFirstDay.AddDate(0, 0, 1)
+	if !checkpoint.ExportedUntil.Equal(endOfFirstDay) || checkpoint.PendingUntil == nil || !checkpoint.PendingUntil.Equal(endOfSecondDay) {
+		t.Fatalf("El punto de control debería avanzar día a día: %+v", checkpoint)
+	}
+
+	// Act: se recupera el almacenamiento
+	writer.failFrom = time.Time{}
+	export, err := exportService.Export(context.Background())
+
+	// Assert: solo se exporta lo que faltaba
+	if err != nil {
+		t.Fatalf("Error inesperado al reintentar: %v", err)
+	}
+	if export.Measurements != 1 || len(export.Files) != 1 || !export.From.Equal(endOfFirstDay) {
+		t.Errorf("Solo debería exportarse el segundo día: %+v", export)
+	}
+}
+
+func TestParquetWrite_RejectsColumnsOfDifferentLength(t *testing.T) {
+	var buf bytes.Buffer
+	err := parquet.Write(&buf, parquet.String("id", []string{"a", "b"}), parquet.Double("level", []float64{1}))
+	if !errors.Is(err, parquet.ErrColumnLength) {
+		t.Errorf("Se esperaba ErrColumnLength, se obtuvo %v", err)
+	}
+}
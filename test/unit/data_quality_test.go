@@ -0,0 +1,143 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+// MockEmailSender registra los correos enviados
+type MockEmailSender struct {
+	Sent        int
+	LastTo      []string
+	LastSubject string
+	LastBody    string
+}
+
+func (m *MockEmailSender) SendEmail(ctx context.Context, to []string, subject, body string) error {
+	m.Sent++
+	m.LastTo = to
+	m.LastSubject = subject
+	m.LastBody = body
+	return nil
+}
+
+var testDataQualityConfig = domain.DataQualityConfig{FlatlineCount: 4}
+
+func TestBuildTankDataQuality(t *testing.T) {
+	// Arrange: un sensor reporta cada hora durante un día, con un hueco de 4 horas
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	tank := &domain.Tank{ID: "t1", Name: "Diésel norte", Capacity: 1000}
+
+	measurements := make([]*domain.Measurement, 0)
+	for i := 0; i <= 24; i++ {
+		if i > 10 && i < 15 {
+			continue
+		}
+		measurements = append(measurements, &domain.Measurement{
+			TankID:      "t1",
+			Level:       900 - float64(i)*10,
+			Temperature: 20,
+			Timestamp:   from.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	// Una lectura imposible y una racha congelada al final del día
+	measurements[2].Level = 1500
+	for _, measurement := range measurements[len(measurements)-5:] {
+		measurement.Level = 700
+	}
+
+	// Act
+	degraded := domain.BuildTankDataQuality(tank, measurements, from, to, testDataQualityConfig)
+	healthy := domain.BuildTankDataQuality(tank, measurements[:8], from, from.Add(7*time.Hour), testDataQualityConfig)
+	empty := domain.BuildTankDataQuality(tank, nil, from, to, testDataQualityConfig)
+
+	// Assert
+	if degraded.ExpectedInterval != 3600 || degraded.MissingIntervals != 4 || degraded.LongestGap != 5*3600 {
+		t.Errorf("Se esperaban 4 lecturas faltantes con un hueco de 5 horas, se obtuvo %+v", degraded)
+	}
+	if degraded.OutOfRange != 1 || degraded.Flatlines != 1 || degraded.Status != domain.DataQualityDegraded {
+		t.Errorf("Se esperaba una lectura fuera de rango y una racha congelada, se obtuvo %+v", degraded)
+	}
+	if healthy.Status != domain.DataQualityDegraded || healthy.MissingIntervals != 0 {
+		t.Errorf("El tramo inicial solo debía tener la lectura fuera de rango, se obtuvo %+v", healthy)
+	}
+	if empty.Status != domain.DataQualityNoData || empty.LongestGap != 24*3600 {
+		t.Errorf("Sin lecturas el estado debe ser no_data, se obtuvo %+v", empty)
+	}
+}
+
+func TestDataQualityService_ScheduledReport(t *testing.T) {
+	// Arrange: un tanque reporta con normalidad y otro no ha reportado nada
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	sender := &MockEmailSender{}
+	recipients := []string{"operaciones@example.com"}
+	dataQualityService := services.NewDataQualityService(tankService, measurementRepo, sender, testDataQualityConfig, 6*time.Hour, recipients)
+	ctx := context.Background()
+
+	working := createTestTank()
+	working.Name = "Tanque activo"
+	silent := createTestTank()
+	silent.Name = "Tanque silencioso"
+	for _, tank := range []*domain.Tank{working, silent} {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+	}
+
+	now := time.Now()
+	for i := 0; i <= 12; i++ {
+		measurement := createTestMeasurement(working.ID, 800-float64(i)*10)
+		measurement.Timestamp = now.Add(-6*time.Hour + time.Duration(i)*30*time.Minute)
+		if err := measurementRepo.SaveMeasurement(ctx, measurement); err != nil {
+			t.Fatalf("Error al guardar la medición para la prueba: %v", err)
+		}
+	}
+
+	// Act
+	err := dataQualityService.GenerateScheduledReport(ctx)
+	report, latestErr := dataQualityService.GetLatestReport(ctx, nil)
+
+	// Assert
+	if err != nil || latestErr != nil {
+		t.Fatalf("No se esperaba error, se obtuvo %v / %v", err, latestErr)
+	}
+	if report.Summary.Tanks != 2 || report.Summary.TanksWithIssues != 1 {
+		t.Errorf("Se esperaba un tanque con problemas de dos, se obtuvo %+v", report.Summary)
+	}
+	if report.Tanks[0].TankID != silent.ID || report.Tanks[0].Status != domain.DataQualityNoData {
+		t.Errorf("El tanque sin datos debía aparecer primero, se obtuvo %+v", report.Tanks[0])
+	}
+	if sender.Sent != 1 || sender.LastTo[0] != recipients[0] {
+		t.Fatalf("Se esperaba un correo a %v, se enviaron %d", recipients, sender.Sent)
+	}
+	if !strings.Contains(sender.LastSubject, "1 de 2") || !strings.Contains(sender.LastBody, "Tanque silencioso") {
+		t.Errorf("El correo no resume los problemas: %q\n%s", sender.LastSubject, sender.LastBody)
+	}
+}
+
+func TestDataQualityService_InvalidPeriod(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	dataQualityService := services.NewDataQualityService(tankService, measurementRepo, nil, testDataQualityConfig, time.Hour, nil)
+	now := time.Now()
+
+	// Act
+	_, err := dataQualityService.GetReport(context.Background(), now, now.Add(-time.Hour), nil)
+
+	// Assert
+	if !errors.Is(err, services.ErrInvalidPeriod) {
+		t.Errorf("Se esperaba ErrInvalidPeriod, se obtuvo %v", err)
+	}
+}
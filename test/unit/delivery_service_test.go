@@ -0,0 +1,86 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestDeliveryService_ReconcileDelivery(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	alertNotifier := &MockAlertNotifier{}
+
+	tankService := newTestTankService(tankRepo, measurementRepo, alertNotifier)
+	deliveryService := services.NewDeliveryService(
+		repositories.NewMemorySupplierRepository(),
+		repositories.NewMemoryDeliveryOrderRepository(),
+		tankService,
+		measurementRepo,
+	)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	supplier := &domain.Supplier{ID: uuid.New().String(), Name: "Proveedor de Prueba"}
+	if err := deliveryService.CreateSupplier(ctx, supplier); err != nil {
+		t.Fatalf("Error al crear el proveedor: %v", err)
+	}
+
+	order := &domain.DeliveryOrder{
+		ID:              uuid.New().String(),
+		TankID:          tank.ID,
+		SupplierID:      supplier.ID,
+		RequestedVolume: 400.0,
+	}
+	if err := deliveryService.RequestDelivery(ctx, order); err != nil {
+		t.Fatalf("Error al registrar el pedido: %v", err)
+	}
+
+	// La entrega sube el nivel de 200 a 590 litros
+	receivedAt := time.Now().Add(-time.Hour)
+	before := createTestMeasurement(tank.ID, 200.0)
+	before.Timestamp = receivedAt.Add(-30 * time.Minute)
+	after := createTestMeasurement(tank.ID, 590.0)
+	after.Timestamp = receivedAt.Add(30 * time.Minute)
+	for _, m := range []*domain.Measurement{before, after} {
+		if err := tankService.AddMeasurement(ctx, m); err != nil {
+			t.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+
+	if _, err := deliveryService.ReceiveDelivery(ctx, order.ID, 400.0, receivedAt); err != nil {
+		t.Fatalf("Error al registrar la recepción: %v", err)
+	}
+
+	// Act
+	reconciliation, err := deliveryService.ReconcileDelivery(ctx, order.ID)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al conciliar el pedido: %v", err)
+	}
+
+	if reconciliation.DetectedVolume != 390.0 {
+		t.Errorf("Volumen detectado incorrecto. Esperado: %.2f, Obtenido: %.2f", 390.0, reconciliation.DetectedVolume)
+	}
+
+	if reconciliation.WithinTolerance {
+		t.Errorf("Una diferencia del 2.5%% no debería estar dentro de la tolerancia")
+	}
+
+	// Un pedido ya recibido no puede volver a programarse
+	if _, err := deliveryService.ScheduleDelivery(ctx, order.ID, time.Now()); err != services.ErrInvalidDeliveryTransition {
+		t.Errorf("Se esperaba ErrInvalidDeliveryTransition, pero se obtuvo: %v", err)
+	}
+}
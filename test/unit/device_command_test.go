@@ -0,0 +1,152 @@
+package services_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"monitor-tanques/internal/adapters/mqtt"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+// MockCommandPublisher registra los comandos publicados
+type MockCommandPublisher struct {
+	Published []*domain.DeviceCommand
+	Err       error
+}
+
+func (m *MockCommandPublisher) PublishCommand(ctx context.Context, command *domain.DeviceCommand) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Published = append(m.Published, command)
+	return nil
+}
+
+func TestDeviceCommandService_SendCommand(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	fieldDeviceService := services.NewFieldDeviceService(tankService, repositories.NewMemoryFieldDeviceRepository())
+	publisher := &MockCommandPublisher{}
+	commandService := services.NewDeviceCommandService(fieldDeviceService, repositories.NewMemoryDeviceCommandRepository(), publisher)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	if _, err := fieldDeviceService.SetDesiredConfig(ctx, "gw-1", tank.ID, domain.DeviceConfig{ReportingInterval: 900}); err != nil {
+		t.Fatalf("Error al registrar el equipo: %v", err)
+	}
+
+	// Act
+	err := commandService.SendCommand(ctx, &domain.DeviceCommand{ID: "c1", DeviceID: "gw-1", Type: domain.DeviceCommandSetReportingInterval, ReportingInterval: 60})
+	invalidErr := commandService.SendCommand(ctx, &domain.DeviceCommand{ID: "c2", DeviceID: "gw-1", Type: "reboot"})
+
+	publisher.Err = errors.New("broker unreachable")
+	failedErr := commandService.SendCommand(ctx, &domain.DeviceCommand{ID: "c3", DeviceID: "gw-1", Type: domain.DeviceCommandReadNow})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al enviar el comando: %v", err)
+	}
+	if len(publisher.Published) != 1 || publisher.Published[0].Status != domain.DeviceCommandSent {
+		t.Errorf("Se esperaba un comando publicado, se obtuvo %+v", publisher.Published)
+	}
+	if !errors.Is(invalidErr, services.ErrInvalidDeviceCommand) {
+		t.Errorf("Se esperaba ErrInvalidDeviceCommand, se obtuvo %v", invalidErr)
+	}
+	if !errors.Is(failedErr, services.ErrCommandNotDelivered) {
+		t.Errorf("Se esperaba ErrCommandNotDelivered, se obtuvo %v", failedErr)
+	}
+
+	device, _ := fieldDeviceService.GetFieldDevice(ctx, "gw-1")
+	if device.Desired.ReportingInterval != 60 || device.Desired.Version != 2 {
+		t.Errorf("El nuevo intervalo debe quedar como configuración deseada, se obtuvo %+v", device.Desired)
+	}
+
+	commands, _ := commandService.GetCommands(ctx, "gw-1")
+	if len(commands) != 2 || commands[1].Status != domain.DeviceCommandFailed {
+		t.Errorf("El historial debe incluir el comando enviado y el fallido, se obtuvo %+v", commands)
+	}
+}
+
+func TestMQTTCommandPublisher_PublishesWithQoS1(t *testing.T) {
+	// Arrange: un broker mínimo que acepta la conexión y confirma la publicación
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error al abrir el broker de prueba: %v", err)
+	}
+	defer listener.Close()
+
+	type received struct {
+		topic   string
+		payload []byte
+	}
+	messages := make(chan received, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+
+		readPacket := func() (byte, []byte) {
+			header, _ := reader.ReadByte()
+			length, _ := reader.ReadByte() // Los paquetes de la prueba miden menos de 128 bytes
+			body := make([]byte, length)
+			io.ReadFull(reader, body)
+			return header, body
+		}
+
+		if header, _ := readPacket(); header != 0x10 {
+			return
+		}
+		conn.Write([]byte{0x20, 0x02, 0x00, 0x00})
+
+		header, body := readPacket()
+		if header != 0x32 {
+			return
+		}
+		topicLength := int(body[0])<<8 | int(body[1])
+		topic := string(body[2 : 2+topicLength])
+		packetID := body[2+topicLength : 4+topicLength]
+		conn.Write([]byte{0x40, 0x02, packetID[0], packetID[1]})
+
+		messages <- received{topic: topic, payload: body[4+topicLength:]}
+	}()
+
+	publisher := mqtt.NewCommandPublisher(mqtt.Config{
+		BrokerAddr:   listener.Addr().String(),
+		ClientID:     "test",
+		CommandTopic: "tanques/{id}/cmd",
+	})
+
+	// Act
+	err = publisher.PublishCommand(context.Background(), &domain.DeviceCommand{ID: "c1", DeviceID: "gw-1", Type: domain.DeviceCommandReadNow})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al publicar el comando: %v", err)
+	}
+
+	message := <-messages
+	if message.topic != "tanques/gw-1/cmd" {
+		t.Errorf("Tema incorrecto: %s", message.topic)
+	}
+
+	var command domain.DeviceCommand
+	if err := json.Unmarshal(message.payload, &command); err != nil || command.Type != domain.DeviceCommandReadNow {
+		t.Errorf("Contenido del mensaje incorrecto: %s", message.payload)
+	}
+}
@@ -0,0 +1,138 @@
+package services_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/deviceprofiles"
+)
+
+const testDeviceProfilesYAML = `
+profiles:
+  - name: ultrasonico
+    token: secreto
+    versions:
+      - version: 1
+      - version: 2
+        schema:
+          properties:
+            sensors:
+              maxItems: 2
+              items:
+                properties:
+                  level: {minimum: 0, maximum: 5000}
+  - name: solo-v2
+    token: otro
+    versions:
+      - version: 2
+        schema: {"required": ["timestamp"]}
+`
+
+func TestDeviceProfile_ExtractVersions(t *testing.T) {
+	// Arrange
+	profiles, err := deviceprofiles.Parse(strings.NewReader(testDeviceProfilesYAML))
+	if err != nil {
+		t.Fatalf("Error inesperado al leer los perfiles: %v", err)
+	}
+	profile := profiles["ultrasonico"]
+
+	// Act
+	legacyVersion, legacy, legacyErr := profile.Extract([]byte(`{"tank_id": "tanque-1", "level": 400, "temperature": 21.5, "timestamp": "2026-05-01T10:00:00Z"}`))
+	version, records, err := profile.Extract([]byte(`{
+		"version": 2, "device_id": "gw-7", "timestamp": "2026-05-01T10:00:00Z", "rssi": -70,
+		"sensors": [
+			{"id": "s-1", "tank_id": "tanque-1", "level": 400},
+			{"tank_id": "tanque-2", "level": 250, "timestamp": "ayer"}
+		]
+	}`))
+
+	// Assert
+	if legacyErr != nil || legacyVersion != deviceprofiles.Version1 || len(legacy) != 1 {
+		t.Fatalf("La carga sin versión debería interpretarse como v1: %d %v %v", legacyVersion, legacy, legacyErr)
+	}
+	if m := legacy[0].Measurement; m == nil || m.TankID != "tanque-1" || m.Level != 400 || m.Temperature != 21.5 ||
+		!m.Timestamp.Equal(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Medición v1 incorrecta: %+v", legacy[0].Measurement)
+	}
+
+	if err != nil || version != deviceprofiles.Version2 || len(records) != 2 {
+		t.Fatalf("Se esperaban 2 lecturas v2: %d %v %v", version, records, err)
+	}
+	first := records[0].Measurement
+	if first == nil || first.SensorID != "s-1" || first.TankID != "tanque-1" || first.SignalStrength == nil || *first.SignalStrength != -70 {
+		t.Errorf("Medición del primer sensor incorrecta: %+v", first)
+	}
+	if records[1].Measurement != nil || records[1].Error != "La marca de tiempo no es válida" {
+		t.Errorf("Error incorrecto para la marca de tiempo inválida: %q", records[1].Error)
+	}
+}
+
+func TestDeviceProfile_ValidatesSchemas(t *testing.T) {
+	// Arrange
+	profiles, err := deviceprofiles.Parse(strings.NewReader(testDeviceProfilesYAML))
+	if err != nil {
+		t.Fatalf("Error inesperado al leer los perfiles: %v", err)
+	}
+
+	cases := []struct {
+		name      string
+		profile   string
+		payload   string
+		violation string
+	}{
+		{"base v1", "ultrasonico", `{"tank_id": "tanque-1", "level": "alto"}`, "/level: expected number, got string"},
+		{"base v2", "ultrasonico", `{"version": 2, "device_id": "gw-7", "sensors": []}`, "/sensors: must have at least 1 items"},
+		{"perfil", "ultrasonico", `{"version": 2, "device_id": "gw-7", "sensors": [{"tank_id": "t", "level": 9000}]}`, "/sensors/0/level: must be <= 5000"},
+		{"requerido", "solo-v2", `{"version": 2, "device_id": "gw-7", "sensors": [{"tank_id": "t", "level": 1}]}`, `/: missing required property "timestamp"`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			_, records, err := profiles[tc.profile].Extract([]byte(tc.payload))
+
+			// Assert
+			var invalid *deviceprofiles.ValidationError
+			if !errors.As(err, &invalid) || records != nil {
+				t.Fatalf("Se esperaba un ValidationError, se obtuvo: %v", err)
+			}
+			if len(invalid.Violations) != 1 || invalid.Violations[0] != tc.violation {
+				t.Errorf("Infracciones incorrectas: %v", invalid.Violations)
+			}
+		})
+	}
+
+	if _, _, err := profiles["solo-v2"].Extract([]byte(`{"version": 1, "tank_id": "t", "level": 1}`)); !errors.Is(err, deviceprofiles.ErrUnsupportedVersion) {
+		t.Errorf("La v1 no debería admitirse en un perfil solo v2: %v", err)
+	}
+	if _, _, err := profiles["ultrasonico"].Extract([]byte(`[1, 2]`)); !errors.Is(err, deviceprofiles.ErrInvalidPayload) {
+		t.Errorf("Se esperaba ErrInvalidPayload, se obtuvo: %v", err)
+	}
+}
+
+func TestDeviceProfile_ParseRejectsInvalidConfig(t *testing.T) {
+	cases := map[string]string{
+		"sin token":           "profiles:\n  - name: a\n    versions: [{version: 1}]\n",
+		"sin versiones":       "profiles:\n  - name: a\n    token: x\n",
+		"versión desconocida": "profiles:\n  - name: a\n    token: x\n    versions: [{version: 3}]\n",
+		"versión repetida":    "profiles:\n  - name: a\n    token: x\n    versions: [{version: 1}, {version: 1}]\n",
+		"predeterminada":      "profiles:\n  - name: a\n    token: x\n    default_version: 1\n    versions: [{version: 2}]\n",
+		"palabra desconocida": "profiles:\n  - name: a\n    token: x\n    versions: [{version: 1, schema: {properties: {level: {maximo: 10}}}}]\n",
+		"tipo desconocido":    "profiles:\n  - name: a\n    token: x\n    versions: [{version: 1, schema: {type: decimal}}]\n",
+		"nombre inválido":     "profiles:\n  - name: A B\n    token: x\n    versions: [{version: 1}]\n",
+	}
+
+	for name, document := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := deviceprofiles.Parse(strings.NewReader(document))
+
+			// Assert
+			if !errors.Is(err, deviceprofiles.ErrInvalidConfig) {
+				t.Errorf("Se esperaba ErrInvalidConfig, se obtuvo: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+// MockDigestSender registra las alertas enviadas a los canales
+type MockDigestSender struct {
+	Alerts []*domain.Alert
+	Err    error
+}
+
+func (m *MockDigestSender) Send(ctx context.Context, channel *domain.NotificationChannel, alert *domain.Alert) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Alerts = append(m.Alerts, alert)
+	return nil
+}
+
+func TestNotificationChannel_DigestDue(t *testing.T) {
+	// Arrange: resumen a las 07:00 en Bogotá, que son las 12:00 UTC
+	channel := &domain.NotificationChannel{
+		Delivery:   domain.DeliveryDigest,
+		DigestTime: "07:00",
+		Schedule:   domain.NotificationSchedule{Timezone: "America/Bogota"},
+	}
+	before := time.Date(2024, 1, 2, 11, 0, 0, 0, time.UTC)
+	after := time.Date(2024, 1, 2, 12, 30, 0, 0, time.UTC)
+
+	// Act & Assert
+	if !channel.DigestDue(before) {
+		t.Error("Sin envíos previos debería corresponder el resumen del día anterior")
+	}
+
+	sentYesterday := time.Date(2024, 1, 1, 12, 5, 0, 0, time.UTC)
+	channel.LastDigestAt = &sentYesterday
+	if channel.DigestDue(before) {
+		t.Error("Antes de la hora local no debería corresponder otro resumen")
+	}
+	if !channel.DigestDue(after) {
+		t.Error("Pasada la hora local debería corresponder el resumen de hoy")
+	}
+
+	sentToday := after.Add(-10 * time.Minute)
+	channel.LastDigestAt = &sentToday
+	if channel.DigestDue(after) {
+		t.Error("El resumen de hoy ya se envió")
+	}
+
+	channel.Delivery = domain.DeliveryImmediate
+	channel.LastDigestAt = nil
+	if channel.DigestDue(after) {
+		t.Error("Un canal inmediato nunca recibe resumen")
+	}
+}
+
+func TestNotificationService_DigestChannelQueuesAlerts(t *testing.T) {
+	// Arrange
+	channelRepo := repositories.NewMemoryNotificationChannelRepository()
+	queueRepo := repositories.NewMemoryAlertQueueRepository()
+	sender := &MockChannelSender{}
+	service := services.NewNotificationService(channelRepo, queueRepo, sender, &MockAlertNotifier{}, services.NewAccessService(repositories.NewMemoryAccessGrantRepository()))
+	ctx := context.Background()
+
+	digest := &domain.NotificationChannel{ID: "digest", Name: "Resumen", Type: domain.ChannelTypeLog, Enabled: true, Delivery: domain.DeliveryDigest}
+	if err := service.CreateChannel(ctx, digest); err != nil {
+		t.Fatalf("Error al crear el canal: %v", err)
+	}
+
+	// Act
+	if err := service.Notify(ctx, &domain.Alert{TankID: "tank-1", Severity: domain.AlertSeverityWarning, Message: "nivel bajo"}); err != nil {
+		t.Fatalf("Error al enviar la alerta: %v", err)
+	}
+	flushErr := service.FlushQueuedAlerts(ctx)
+
+	// Assert
+	if flushErr != nil {
+		t.Fatalf("Error al vaciar las colas: %v", flushErr)
+	}
+	if len(sender.Sent) != 0 {
+		t.Errorf("Un canal con resumen no debería recibir alertas sueltas: %v", sender.Sent)
+	}
+	queued, err := service.GetQueuedAlerts(ctx, "digest", "")
+	if err != nil {
+		t.Fatalf("Error al obtener la cola: %v", err)
+	}
+	if len(queued) != 1 {
+		t.Errorf("La alerta debería esperar al resumen, hay %d en cola", len(queued))
+	}
+	stored, _ := channelRepo.GetChannel(ctx, "digest")
+	if stored.DigestTime != domain.DefaultDigestTime {
+		t.Errorf("Se esperaba la hora de resumen por defecto, se obtuvo %q", stored.DigestTime)
+	}
+}
+
+func TestNotificationService_ValidatesDigestChannels(t *testing.T) {
+	// Arrange
+	service := services.NewNotificationService(repositories.NewMemoryNotificationChannelRepository(), repositories.NewMemoryAlertQueueRepository(), &MockChannelSender{}, &MockAlertNotifier{}, services.NewAccessService(repositories.NewMemoryAccessGrantRepository()))
+	ctx := context.Background()
+
+	cases := []struct {
+		name     string
+		channel  *domain.NotificationChannel
+		expected error
+	}{
+		{"email sin destinatario", &domain.NotificationChannel{ID: "email", Name: "Email", Type: domain.ChannelTypeEmail}, services.ErrInvalidChannel},
+		{"entrega desconocida", &domain.NotificationChannel{ID: "log", Name: "Log", Type: domain.ChannelTypeLog, Delivery: "weekly"}, services.ErrInvalidChannel},
+		{"resumen por push", &domain.NotificationChannel{ID: "push", Name: "Push", Type: domain.ChannelTypePush, Delivery: domain.DeliveryDigest}, services.ErrInvalidChannel},
+		{"hora de resumen inválida", &domain.NotificationChannel{ID: "log", Name: "Log", Type: domain.ChannelTypeLog, Delivery: domain.DeliveryDigest, DigestTime: "25:00"}, domain.ErrInvalidSchedule},
+	}
+
+	// Act & Assert
+	for _, c := range cases {
+		if err := service.CreateChannel(ctx, c.channel); !errors.Is(err, c.expected) {
+			t.Errorf("%s: se esperaba %v, se obtuvo %v", c.name, c.expected, err)
+		}
+	}
+	email := &domain.NotificationChannel{ID: "email", Name: "Email", Type: domain.ChannelTypeEmail, Target: "ops@example.com", Delivery: domain.DeliveryDigest}
+	if err := service.CreateChannel(ctx, email); err != nil {
+		t.Errorf("Un canal de email con resumen debería ser válido: %v", err)
+	}
+}
+
+func TestDigestService_SendDueDigests(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	channelRepo := repositories.NewMemoryNotificationChannelRepository()
+	queueRepo := repositories.NewMemoryAlertQueueRepository()
+	sender := &MockDigestSender{}
+	notificationService := services.NewNotificationService(channelRepo, queueRepo, &MockChannelSender{}, &MockAlertNotifier{}, services.NewAccessService(repositories.NewMemoryAccessGrantRepository()))
+	digestService := services.NewDigestService(tankService, measurementRepo, channelRepo, queueRepo, sender)
+	ctx := context.Background()
+
+	full := createTestTank()
+	full.Name = "Tanque Lleno"
+	low := createTestTank()
+	low.Name = "Tanque Bajo"
+	for _, tank := range []*domain.Tank{full, low} {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+	}
+	dayAgo := createTestMeasurement(low.ID, 800)
+	dayAgo.Timestamp = time.Now().Add(-30 * time.Hour)
+	if err := measurementRepo.SaveMeasurement(ctx, dayAgo); err != nil {
+		t.Fatalf("Error al guardar la medición antigua: %v", err)
+	}
+	if err := tankService.AddMeasurement(ctx, createTestMeasurement(low.ID, 150)); err != nil {
+		t.Fatalf("Error al registrar la medición: %v", err)
+	}
+
+	channel := &domain.NotificationChannel{ID: "digest", Name: "Resumen", Type: domain.ChannelTypeLog, Enabled: true, Delivery: domain.DeliveryDigest, DigestTime: "00:00"}
+	if err := notificationService.CreateChannel(ctx, channel); err != nil {
+		t.Fatalf("Error al crear el canal: %v", err)
+	}
+	if err := notificationService.Notify(ctx, &domain.Alert{TankID: low.ID, Severity: domain.AlertSeverityWarning, Message: "nivel bajo", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Error al enviar la alerta: %v", err)
+	}
+
+	// Act
+	firstErr := digestService.SendDueDigests(ctx)
+	secondErr := digestService.SendDueDigests(ctx)
+
+	// Assert
+	if firstErr != nil || secondErr != nil {
+		t.Fatalf("Error al enviar los resúmenes: %v, %v", firstErr, secondErr)
+	}
+	if len(sender.Alerts) != 1 {
+		t.Fatalf("Se esperaba un único resumen, se enviaron %d", len(sender.Alerts))
+	}
+
+	alert := sender.Alerts[0]
+	if alert.Type != domain.AlertEventDailyDigest || alert.Severity != domain.AlertSeverityWarning {
+		t.Errorf("Tipo o severidad del resumen incorrectos: %s, %s", alert.Type, alert.Severity)
+	}
+	if !strings.HasPrefix(alert.Message, "Resumen diario de los tanques del ") || !strings.Contains(alert.Message, "Tanque Bajo [aviso]") {
+		t.Errorf("Texto del resumen incorrecto:\n%s", alert.Message)
+	}
+
+	digest := alert.Digest
+	if len(digest.Tanks) != 2 || digest.Tanks[0].TankID != low.ID || digest.ActiveAlerts != 1 || len(digest.Alerts) != 1 {
+		t.Fatalf("Contenido del resumen incorrecto: %+v", digest)
+	}
+	if change := digest.Tanks[0].Change24h; change == nil || *change != -650 {
+		t.Errorf("Se esperaba una variación de -650 L en 24 h, se obtuvo %v", change)
+	}
+	if digest.Tanks[0].DaysUntilEmpty == nil {
+		t.Error("Se esperaba la previsión de vaciado del tanque que se consume")
+	}
+
+	queued, _ := queueRepo.GetQueuedAlerts(ctx, "digest")
+	if len(queued) != 0 {
+		t.Errorf("Las alertas incluidas en el resumen deberían salir de la cola, quedan %d", len(queued))
+	}
+	stored, _ := channelRepo.GetChannel(ctx, "digest")
+	if stored.LastDigestAt == nil {
+		t.Error("Se esperaba anotada la fecha del último resumen")
+	}
+}
+
+func TestDigestService_SendDigestKeepsQueueOnFailure(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	channelRepo := repositories.NewMemoryNotificationChannelRepository()
+	queueRepo := repositories.NewMemoryAlertQueueRepository()
+	notificationService := services.NewNotificationService(channelRepo, queueRepo, &MockChannelSender{}, &MockAlertNotifier{}, services.NewAccessService(repositories.NewMemoryAccessGrantRepository()))
+	digestService := services.NewDigestService(tankService, measurementRepo, channelRepo, queueRepo, &MockDigestSender{Err: errors.New("smtp caído")})
+	ctx := context.Background()
+
+	channel := &domain.NotificationChannel{ID: "digest", Name: "Resumen", Type: domain.ChannelTypeLog, Enabled: true, Delivery: domain.DeliveryDigest}
+	if err := notificationService.CreateChannel(ctx, channel); err != nil {
+		t.Fatalf("Error al crear el canal: %v", err)
+	}
+	if err := notificationService.Notify(ctx, &domain.Alert{TankID: "tank-1", Severity: domain.AlertSeverityWarning, Message: "nivel bajo"}); err != nil {
+		t.Fatalf("Error al enviar la alerta: %v", err)
+	}
+
+	// Act
+	_, sendErr := digestService.SendDigest(ctx, "digest")
+	_, missingErr := digestService.GetDigest(ctx, "no-existe")
+
+	// Assert
+	if !errors.Is(sendErr, services.ErrDigestNotDelivered) {
+		t.Errorf("Se esperaba ErrDigestNotDelivered, se obtuvo %v", sendErr)
+	}
+	if !errors.Is(missingErr, services.ErrChannelNotFound) {
+		t.Errorf("Se esperaba ErrChannelNotFound, se obtuvo %v", missingErr)
+	}
+	queued, _ := queueRepo.GetQueuedAlerts(ctx, "digest")
+	stored, _ := channelRepo.GetChannel(ctx, "digest")
+	if len(queued) != 1 || stored.LastDigestAt != nil {
+		t.Errorf("Un resumen no entregado no debe vaciar la cola ni anotarse: %d en cola, %v", len(queued), stored.LastDigestAt)
+	}
+}
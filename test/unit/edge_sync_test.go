@@ -0,0 +1,392 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+)
+
+// syncNode es una instancia (central o pasarela) con sus repositorios en memoria
+type syncNode struct {
+	base         ports.TankService // Sin registrar los cambios de configuración
+	tanks        ports.TankService // Como la API: registra los cambios de configuración
+	measurements *repositories.MemoryMeasurementRepository
+	states       *repositories.MemoryTankSyncRepository
+	queue        *repositories.MemorySyncQueueRepository
+}
+
+func newSyncNode(origin string) *syncNode {
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	node := &syncNode{
+		base:         newTestTankService(repositories.NewMemoryTankRepository(), measurementRepo, &MockAlertNotifier{}),
+		measurements: measurementRepo,
+		states:       repositories.NewMemoryTankSyncRepository(),
+		queue:        repositories.NewMemorySyncQueueRepository(),
+	}
+	if origin != domain.SyncOriginCentral {
+		node.base = services.NewEdgeQueueingTankService(node.base, node.queue)
+	}
+	node.tanks = services.NewSyncTrackingTankService(node.base, node.states, origin)
+	return node
+}
+
+// MockSyncClient lleva los lotes de la pasarela a la instancia central en el mismo proceso; falla
+// mientras offline es true
+type MockSyncClient struct {
+	offline bool
+	central ports.TankSyncService
+	batches []*domain.SyncBatch
+}
+
+func (c *MockSyncClient) Push(ctx context.Context, batch *domain.SyncBatch) (*domain.SyncResult, error) {
+	if c.offline {
+		return nil, errors.New("connection refused")
+	}
+	c.batches = append(c.batches, batch)
+	return c.central.Push(ctx, batch)
+}
+
+func (c *MockSyncClient) Pull(ctx context.Context, cursor int64, limit int) (*domain.SyncPullResult, error) {
+	if c.offline {
+		return nil, errors.New("connection refused")
+	}
+	return c.central.Pull(ctx, cursor, limit)
+}
+
+// newEdgeSync conecta la pasarela edge con central
+func newEdgeSync(central, edge *syncNode, batchSize int) (ports.EdgeSyncService, *MockSyncClient) {
+	client := &MockSyncClient{central: services.NewTankSyncService(central.base, central.states)}
+	syncService := services.NewEdgeSyncService(edge.queue, edge.states, edge.base, client, "sitio-norte", "https://central.example.com", batchSize)
+	return syncService, client
+}
+
+func TestEdgeSync_ForwardsQueuedMeasurementsAfterOutage(t *testing.T) {
+	// Arrange
+	central := newSyncNode(domain.SyncOriginCentral)
+	edge := newSyncNode("sitio-norte")
+	syncService, client := newEdgeSync(central, edge, 2)
+	client.offline = true
+
+	tank := createTestTank()
+	if err := edge.tanks.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	for _, level := range []float64{450, 440, 430} {
+		if err := edge.tanks.AddMeasurement(context.Background(), createTestMeasurement(tank.ID, level)); err != nil {
+			t.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+	// Una medición inválida no se guarda ni se encola
+	if err := edge.tanks.AddMeasurement(context.Background(), createTestMeasurement("inexistente", 10)); err == nil {
+		t.Fatal("Se esperaba un error con un tanque inexistente")
+	}
+
+	// Act: sin conexión
+	err := syncService.Forward(context.Background())
+
+	// Assert: nada sale de la cola
+	if err == nil {
+		t.Error("Se esperaba un error sin conexión con la instancia central")
+	}
+	status, _ := syncService.GetStatus(context.Background())
+	if status.Pending != 3 || status.LastError == "" || status.LastSyncAt != nil {
+		t.Errorf("Estado sin conexión incorrecto: %+v", status)
+	}
+
+	// Act: vuelve la conexión
+	client.offline = false
+	err = syncService.Forward(context.Background())
+
+	// Assert: el tanque llega con el primer lote y las mediciones en lotes de dos, de la más
+	// antigua a la más reciente
+	if err != nil {
+		t.Fatalf("Error inesperado al sincronizar: %v", err)
+	}
+	if len(client.batches) != 2 || len(client.batches[0].Measurements) != 2 || len(client.batches[0].Tanks) != 1 || len(client.batches[1].Measurements) != 1 {
+		t.Fatalf("Lotes incorrectos: %+v", client.batches)
+	}
+	if client.batches[0].Measurements[0].Level != 450 || client.batches[1].Measurements[0].Level != 430 {
+		t.Errorf("Orden de los lotes incorrecto: %+v", client.batches[0])
+	}
+	synced, err := central.base.GetTank(context.Background(), tank.ID)
+	if err != nil || synced.Name != tank.Name || synced.CurrentLevel != 430 {
+		t.Fatalf("El tanque no llegó a la instancia central: %+v %v", synced, err)
+	}
+	measurements, _ := central.measurements.GetMeasurementsByTankID(context.Background(), tank.ID, 0)
+	if len(measurements) != 3 {
+		t.Errorf("Se esperaban 3 mediciones en la instancia central, hay %d", len(measurements))
+	}
+	status, _ = syncService.GetStatus(context.Background())
+	if status.Pending != 0 || status.LastError != "" || status.LastSyncAt == nil {
+		t.Errorf("Estado tras sincronizar incorrecto: %+v", status)
+	}
+}
+
+// flakyTankService simula una instancia central cuyo almacenamiento falla mientras down es true
+type flakyTankService struct {
+	ports.TankService
+	down bool
+}
+
+func (s *flakyTankService) AddMeasurement(ctx context.Context, measurement *domain.Measurement) error {
+	if s.down {
+		return errors.New("database unavailable")
+	}
+	return s.TankService.AddMeasurement(ctx, measurement)
+}
+
+func (s *flakyTankService) AddMeasurements(ctx context.Context, measurements []*domain.Measurement) error {
+	if s.down {
+		return errors.New("database unavailable")
+	}
+	return s.TankService.AddMeasurements(ctx, measurements)
+}
+
+func TestEdgeSync_KeepsMeasurementsWhenCentralFailsTemporarily(t *testing.T) {
+	// Arrange
+	central := newSyncNode(domain.SyncOriginCentral)
+	edge := newSyncNode("sitio-norte")
+	flaky := &flakyTankService{TankService: central.base, down: true}
+	client := &MockSyncClient{central: services.NewTankSyncService(flaky, central.states)}
+	syncService := services.NewEdgeSyncService(edge.queue, edge.states, edge.base, client, "sitio-norte", "https://central.example.com", 0)
+
+	tank := createTestTank()
+	if err := edge.tanks.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	for _, level := range []float64{450, 440} {
+		if err := edge.tanks.AddMeasurement(context.Background(), createTestMeasurement(tank.ID, level)); err != nil {
+			t.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+	// Una medición de un tanque que la instancia central no tiene se rechaza para siempre
+	if err := edge.queue.EnqueueMeasurements(context.Background(), []*domain.Measurement{createTestMeasurement("desconocido", 10)}); err != nil {
+		t.Fatalf("Error al encolar la medición: %v", err)
+	}
+
+	// Act: falla el almacenamiento de la instancia central
+	err := syncService.Forward(context.Background())
+
+	// Assert: el lote falla entero y nada sale de la cola
+	if err == nil {
+		t.Fatal("Se esperaba un error con la instancia central caída")
+	}
+	status, _ := syncService.GetStatus(context.Background())
+	if status.Pending != 3 {
+		t.Fatalf("Las mediciones deberían seguir en la cola: %+v", status)
+	}
+
+	// Act: se recupera y la pasarela reenvía el mismo lote
+	flaky.down = false
+	err = syncService.Forward(context.Background())
+
+	// Assert: el reenvío se aplica en lugar de devolver un rechazo guardado
+	if err == nil || !strings.Contains(err.Error(), "desconocido") {
+		t.Errorf("Se esperaba solo el rechazo de la medición del tanque desconocido: %v", err)
+	}
+	measurements, _ := central.measurements.GetMeasurementsByTankID(context.Background(), tank.ID, 0)
+	if len(measurements) != 2 {
+		t.Errorf("Se esperaban 2 mediciones en la instancia central, hay %d", len(measurements))
+	}
+	status, _ = syncService.GetStatus(context.Background())
+	if status.Pending != 0 {
+		t.Errorf("La cola debería vaciarse, con el rechazo definitivo incluido: %+v", status)
+	}
+	last := client.batches[len(client.batches)-1]
+	if last.BatchID != client.batches[0].BatchID {
+		t.Errorf("El reenvío debería llevar el mismo ID de lote: %s %s", last.BatchID, client.batches[0].BatchID)
+	}
+}
+
+func TestEdgeSync_MergesConcurrentConfigChangesPerField(t *testing.T) {
+	// Arrange: el tanque de la instancia central, anterior al registro, llega a la pasarela
+	central := newSyncNode(domain.SyncOriginCentral)
+	edge := newSyncNode("sitio-norte")
+	syncService, _ := newEdgeSync(central, edge, 0)
+
+	tank := createTestTank()
+	if err := central.base.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	if err := syncService.Forward(context.Background()); err != nil {
+		t.Fatalf("Error inesperado al sincronizar: %v", err)
+	}
+	local, err := edge.base.GetTank(context.Background(), tank.ID)
+	if err != nil || local.Name != tank.Name {
+		t.Fatalf("El tanque no llegó a la pasarela: %+v %v", local, err)
+	}
+
+	// Act: entre dos sincronizaciones, la pasarela cambia el umbral y el líquido y después la
+	// instancia central cambia el nombre y el umbral
+	local.AlertThreshold = 25
+	local.LiquidType = "Diésel"
+	if err := edge.tanks.UpdateTank(context.Background(), local); err != nil {
+		t.Fatalf("Error al actualizar el tanque en la pasarela: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	remote, _ := central.base.GetTank(context.Background(), tank.ID)
+	remote.Name = "Diésel norte"
+	remote.AlertThreshold = 30
+	if err := central.tanks.UpdateTank(context.Background(), remote); err != nil {
+		t.Fatalf("Error al actualizar el tanque en la instancia central: %v", err)
+	}
+	err = syncService.Forward(context.Background())
+
+	// Assert: cada campo conserva su último cambio, en las dos instancias
+	if err != nil {
+		t.Fatalf("Error inesperado al sincronizar: %v", err)
+	}
+	for name, node := range map[string]*syncNode{"central": central, "edge": edge} {
+		merged, _ := node.base.GetTank(context.Background(), tank.ID)
+		if merged.Name != "Diésel norte" || merged.AlertThreshold != 30 || merged.LiquidType != "Diésel" {
+			t.Errorf("Configuración combinada incorrecta en %s: %+v", name, merged)
+		}
+	}
+
+	// Act: la instancia central borra el tanque
+	if err := central.tanks.DeleteTank(context.Background(), tank.ID); err != nil {
+		t.Fatalf("Error al borrar el tanque: %v", err)
+	}
+	err = syncService.Forward(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error inesperado al sincronizar: %v", err)
+	}
+	if _, err := edge.base.GetTank(context.Background(), tank.ID); err == nil {
+		t.Error("El borrado debería llegar a la pasarela")
+	}
+}
+
+func TestTankSyncService_PushIsIdempotent(t *testing.T) {
+	// Arrange
+	central := newSyncNode(domain.SyncOriginCentral)
+	syncService := services.NewTankSyncService(central.base, central.states)
+	batch := &domain.SyncBatch{
+		GatewayID:    "sitio-norte",
+		BatchID:      "lote-1",
+		Measurements: []*domain.Measurement{createTestMeasurement("tq-local", 50)},
+	}
+
+	// Act
+	first, err := syncService.Push(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("Error inesperado al aplicar el lote: %v", err)
+	}
+	tank := createTestTank()
+	tank.ID = "tq-local"
+	if err := central.base.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	second, err := syncService.Push(context.Background(), batch)
+
+	// Assert: el reenvío devuelve la respuesta ya dada sin aplicarse de nuevo
+	if err != nil {
+		t.Fatalf("Error inesperado al reenviar el lote: %v", err)
+	}
+	if first.Rejected != 1 || second.Rejected != 1 || !second.ReceivedAt.Equal(first.ReceivedAt) {
+		t.Errorf("El reenvío debería devolver la misma respuesta: %+v %+v", first, second)
+	}
+	if _, err := syncService.Push(context.Background(), &domain.SyncBatch{GatewayID: "sitio-norte"}); !errors.Is(err, services.ErrInvalidSyncBatch) {
+		t.Errorf("Se esperaba ErrInvalidSyncBatch sin ID de lote, se obtuvo %v", err)
+	}
+}
+
+func TestTankSyncService_RejectsChangesRequiringApproval(t *testing.T) {
+	// Arrange: un tanque de un sitio regulado, conocido por la pasarela
+	central := newSyncNode(domain.SyncOriginCentral)
+	syncService := services.NewTankSyncService(services.NewSyncApprovalTankService(central.base, []string{"planta-norte"}), central.states)
+	tank := createTestTank()
+	tank.SiteID = "planta-norte"
+	if err := central.base.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	changed := *tank
+	changed.AlertThreshold = 40
+	renamed := *tank
+	renamed.Name = "Norte renombrado"
+	now := time.Now()
+
+	// Act: la pasarela envía un cambio de umbral y, en otro lote, uno de nombre
+	threshold, err := syncService.Push(context.Background(), &domain.SyncBatch{
+		GatewayID: "sitio-norte",
+		BatchID:   "lote-1",
+		Tanks:     []*domain.TankSyncState{domain.NewTankSyncState(nil, &changed, []string{domain.TankFieldAlertThreshold}, "sitio-norte", now)},
+	})
+	if err != nil {
+		t.Fatalf("Error inesperado al aplicar el lote: %v", err)
+	}
+	name, err := syncService.Push(context.Background(), &domain.SyncBatch{
+		GatewayID: "sitio-norte",
+		BatchID:   "lote-2",
+		Tanks:     []*domain.TankSyncState{domain.NewTankSyncState(nil, &renamed, []string{"name"}, "sitio-norte", now)},
+	})
+
+	// Assert: el umbral requiere aprobación y se rechaza; el nombre se aplica
+	if err != nil {
+		t.Fatalf("Error inesperado al aplicar el lote: %v", err)
+	}
+	if record := threshold.Tanks[0]; record.Status != domain.TankSyncRejected || record.Error != services.ErrChangeRequiresApproval.Error() {
+		t.Errorf("Se esperaba el rechazo del cambio de umbral: %+v", record)
+	}
+	if record := name.Tanks[0]; record.Status == domain.TankSyncRejected {
+		t.Errorf("El cambio de nombre no requiere aprobación: %+v", record)
+	}
+	stored, _ := central.base.GetTank(context.Background(), tank.ID)
+	if stored.AlertThreshold != tank.AlertThreshold || stored.Name != renamed.Name {
+		t.Errorf("Configuración inesperada en la instancia central: %+v", stored)
+	}
+}
+
+func TestMergeTankSyncState(t *testing.T) {
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	tank := &domain.Tank{ID: "tq-1", Name: "Norte", Capacity: 1000, AlertThreshold: 10}
+	local := domain.NewTankSyncState(nil, tank, domain.TankSyncFields, "central", base)
+
+	t.Run("gana el cambio más reciente de cada campo", func(t *testing.T) {
+		changed := *tank
+		changed.Name, changed.Capacity = "Sur", 2000
+		remote := domain.NewTankSyncState(local, &changed, []string{"name"}, "sitio-norte", base.Add(time.Minute))
+		remote.FieldTimes[domain.TankFieldCapacity] = base.Add(-time.Minute)
+
+		merged, fields := domain.MergeTankSyncState(local, remote)
+
+		if merged == nil || merged.Tank.Name != "Sur" || merged.Tank.Capacity != 1000 {
+			t.Fatalf("Combinación incorrecta: %+v", merged)
+		}
+		if len(fields) != 1 || fields[0] != "name" {
+			t.Errorf("Campos tomados incorrectos: %v", fields)
+		}
+	})
+
+	t.Run("un estado igual no cambia nada", func(t *testing.T) {
+		if merged, _ := domain.MergeTankSyncState(local, local.Clone()); merged != nil {
+			t.Errorf("No debería haber nada que aplicar: %+v", merged)
+		}
+	})
+
+	t.Run("el borrado gana solo a los cambios anteriores", func(t *testing.T) {
+		older := domain.NewTankSyncTombstone("tq-1", "sitio-norte", base.Add(-time.Hour))
+		if merged, _ := domain.MergeTankSyncState(local, older); merged != nil {
+			t.Errorf("Un borrado anterior al último cambio no debería aplicarse: %+v", merged)
+		}
+
+		newer := domain.NewTankSyncTombstone("tq-1", "sitio-norte", base.Add(time.Hour))
+		merged, _ := domain.MergeTankSyncState(local, newer)
+		if merged == nil || merged.DeletedAt == nil {
+			t.Fatalf("El borrado posterior debería aplicarse: %+v", merged)
+		}
+
+		revived := domain.NewTankSyncState(nil, tank, []string{"name"}, "central", base.Add(2*time.Hour))
+		if merged, _ := domain.MergeTankSyncState(newer, revived); merged == nil || merged.Tank == nil {
+			t.Errorf("Un cambio posterior al borrado debería recuperar el tanque: %+v", merged)
+		}
+	})
+}
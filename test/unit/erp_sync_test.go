@@ -0,0 +1,198 @@
+package services_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/erp"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/services"
+)
+
+// parseERPConnector interpreta un archivo con una sola conexión
+func parseERPConnector(t *testing.T, document string) *erp.Connector {
+	t.Helper()
+	config, err := erp.Parse(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("Error inesperado al leer las conexiones: %v", err)
+	}
+	if len(config.Connectors) != 1 {
+		t.Fatalf("Se esperaba 1 conexión, se obtuvieron %d", len(config.Connectors))
+	}
+	return config.Connectors[0]
+}
+
+func TestERP_ParseConfig(t *testing.T) {
+	// Act
+	connector := parseERPConnector(t, `
+connectors:
+  - name: sap
+    type: http
+    url: https://erp.example.com/inventory
+    headers: {authorization: Bearer secreto}
+    interval: 1h
+    retries: 0
+`)
+
+	// Assert
+	if connector.Format != erp.FormatCSV || connector.Retries != 0 || connector.Timeout != 30*time.Second {
+		t.Errorf("Valores predeterminados incorrectos: %+v", connector)
+	}
+	if connector.Headers["Authorization"] != "Bearer secreto" {
+		t.Errorf("Cabeceras incorrectas: %v", connector.Headers)
+	}
+
+	invalid := []string{
+		"connectors:\n  - {name: sap, type: http, url: ftp://erp, interval: 1h}\n",
+		"connectors:\n  - {name: sap, type: file, interval: 1h}\n",
+		"connectors:\n  - {name: sap, type: sftp, dir: /inbox, interval: 1h}\n",
+		"connectors:\n  - {name: sap, type: file, dir: /inbox, interval: 1h, format: xml}\n",
+		"connectors:\n  - {name: sap, type: file, dir: /inbox, interval: 1h, tank_selector: 'a in'}\n",
+		"connectors:\n  - {name: sap, type: file, dir: /inbox}\n",
+		"connectors:\n  - {name: sap, type: file, dir: /a, interval: 1h}\n  - {name: sap, type: file, dir: /b, interval: 1h}\n",
+	}
+	for _, document := range invalid {
+		if _, err := erp.Parse(strings.NewReader(document)); !errors.Is(err, erp.ErrInvalidConfig) {
+			t.Errorf("Se esperaba ErrInvalidConfig para %q, se obtuvo: %v", document, err)
+		}
+	}
+}
+
+func TestERP_HTTPSyncRetriesUntilConfirmed(t *testing.T) {
+	// Arrange: un ERP que falla una vez y después confirma con una referencia
+	var attempts atomic.Int32
+	var batchIDs []string
+	var body string
+	erpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		batchIDs = append(batchIDs, r.Header.Get("Idempotency-Key"))
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secreto" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		content, _ := io.ReadAll(r.Body)
+		body = string(content)
+		w.Header().Set("X-Document-Id", "DOC-42")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer erpServer.Close()
+
+	connector := parseERPConnector(t, `
+connectors:
+  - name: sap
+    type: http
+    url: `+erpServer.URL+`
+    headers: {Authorization: Bearer secreto}
+    confirmation_header: X-Document-Id
+    interval: 1h
+    retries: 2
+    retry_backoff: 1ms
+`)
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	tank := createTestTank()
+	if err := tankService.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque: %v", err)
+	}
+	syncService := services.NewInventorySyncService(tankService, erp.NewExporter(connector))
+
+	// Act
+	result, err := syncService.Sync(context.Background(), "sap")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error inesperado al sincronizar: %v", err)
+	}
+	if result.Status != "delivered" || result.Attempts != 2 || result.Confirmation != "DOC-42" || result.Tanks != 1 {
+		t.Errorf("Resultado incorrecto: %+v", result)
+	}
+	if len(batchIDs) != 2 || batchIDs[0] != result.BatchID || batchIDs[1] != result.BatchID {
+		t.Errorf("Los reintentos deberían conservar el lote %s: %v", result.BatchID, batchIDs)
+	}
+	rows, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	if err != nil || len(rows) != 2 {
+		t.Fatalf("CSV incorrecto (%v):\n%s", err, body)
+	}
+	if rows[1][2] != tank.ID || rows[1][8] != "500.00" || rows[1][9] != "50.00" {
+		t.Errorf("Fila incorrecta: %v", rows[1])
+	}
+
+	syncs, err := syncService.GetSyncs(context.Background(), "sap")
+	if err != nil || len(syncs) != 1 {
+		t.Fatalf("Historial incorrecto (%v): %d", err, len(syncs))
+	}
+	if _, err := syncService.Sync(context.Background(), "otro"); !errors.Is(err, services.ErrConnectorNotFound) {
+		t.Errorf("Se esperaba ErrConnectorNotFound, se obtuvo: %v", err)
+	}
+}
+
+func TestERP_HTTPRejectionIsNotRetried(t *testing.T) {
+	// Arrange
+	var attempts atomic.Int32
+	erpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "formato desconocido", http.StatusUnprocessableEntity)
+	}))
+	defer erpServer.Close()
+
+	connector := parseERPConnector(t, "connectors:\n  - {name: sap, type: http, url: '"+erpServer.URL+"', interval: 1h, retries: 3, retry_backoff: 1ms}\n")
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	syncService := services.NewInventorySyncService(tankService, erp.NewExporter(connector))
+
+	// Act
+	result, err := syncService.Sync(context.Background(), "sap")
+
+	// Assert
+	if !errors.Is(err, services.ErrInventoryNotDelivered) {
+		t.Fatalf("Se esperaba ErrInventoryNotDelivered, se obtuvo: %v", err)
+	}
+	if attempts.Load() != 1 || result.Status != "failed" || !strings.Contains(result.Error, "formato desconocido") {
+		t.Errorf("Un rechazo no debería reintentarse: %d intentos, %+v", attempts.Load(), result)
+	}
+}
+
+func TestERP_FileDropIsAtomic(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	connector := parseERPConnector(t, "connectors:\n  - {name: contabilidad, type: file, dir: '"+dir+"', delimiter: ';', done_marker: true, interval: 1h}\n")
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	if err := tankService.CreateTank(context.Background(), createTestTank()); err != nil {
+		t.Fatalf("Error al crear el tanque: %v", err)
+	}
+	syncService := services.NewInventorySyncService(tankService, erp.NewExporter(connector))
+
+	// Act
+	result, err := syncService.Sync(context.Background(), "contabilidad")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error inesperado al sincronizar: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("Se esperaban el archivo y su marca, se obtuvieron %d entradas", len(entries))
+	}
+	name, _, _ := strings.Cut(result.Confirmation, " ")
+	if !strings.HasSuffix(name, ".csv") || !strings.Contains(result.Confirmation, "sha256:") {
+		t.Errorf("Acuse incorrecto: %q", result.Confirmation)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil || !strings.HasPrefix(string(content), "batch_id;generated_at;tank_id") {
+		t.Errorf("Archivo incorrecto (%v):\n%s", err, content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, name+".done")); err != nil {
+		t.Errorf("Falta la marca de fin: %v", err)
+	}
+}
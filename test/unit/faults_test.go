@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/faults"
+	"monitor-tanques/internal/adapters/projections"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestFaultInjector_ErrorRate(t *testing.T) {
+	ctx := context.Background()
+
+	never := faults.NewInjector(faults.Config{ErrorRate: 0})
+	always := faults.NewInjector(faults.Config{ErrorRate: 1})
+
+	for i := 0; i < 10; i++ {
+		if err := never.Inject(ctx, "GetTank"); err != nil {
+			t.Fatalf("Sin tasa de error no debería fallar: %v", err)
+		}
+		if err := always.Inject(ctx, "GetTank"); !errors.Is(err, faults.ErrInjectedFault) {
+			t.Fatalf("Se esperaba ErrInjectedFault, se obtuvo: %v", err)
+		}
+	}
+
+	// Con la misma semilla, los fallos se repiten en el mismo orden
+	first := faults.NewInjectorWithSeed(faults.Config{ErrorRate: 0.5}, 42)
+	second := faults.NewInjectorWithSeed(faults.Config{ErrorRate: 0.5}, 42)
+	for i := 0; i < 20; i++ {
+		if (first.Inject(ctx, "op") == nil) != (second.Inject(ctx, "op") == nil) {
+			t.Fatalf("Los inyectores con la misma semilla difieren en la operación %d", i)
+		}
+	}
+}
+
+func TestFaultInjector_LatencyRespectsContext(t *testing.T) {
+	// Arrange
+	injector := faults.NewInjector(faults.Config{Latency: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	started := time.Now()
+	err := injector.Inject(ctx, "SaveMeasurement")
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Se esperaba el error del contexto, se obtuvo: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("La espera no se interrumpió al vencer el contexto (%v)", elapsed)
+	}
+}
+
+func TestFaultInjection_FailedUnitOfWorkLeavesNoWrites(t *testing.T) {
+	// Arrange: la persistencia de la transacción falla siempre
+	ctx := context.Background()
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	statusRepo := repositories.NewMemoryStatusHistoryRepository()
+	injector := faults.NewInjector(faults.Config{ErrorRate: 1})
+	unitOfWork := faults.NewUnitOfWork(repositories.NewMemoryUnitOfWork(tankRepo, measurementRepo, statusRepo, repositories.NewMemoryOutboxRepository()), injector)
+	service := services.NewTankService(tankRepo, measurementRepo, &MockAlertNotifier{}, unitOfWork, projections.NewMemoryTankStateStore(0))
+
+	tank := createTestTank()
+	if err := tankRepo.SaveTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque: %v", err)
+	}
+
+	// Act
+	err := service.AddMeasurement(ctx, createTestMeasurement(tank.ID, 300))
+
+	// Assert
+	if !errors.Is(err, faults.ErrInjectedFault) {
+		t.Fatalf("Se esperaba ErrInjectedFault, se obtuvo: %v", err)
+	}
+	measurements, _ := measurementRepo.GetMeasurementsByTankID(ctx, tank.ID, 0)
+	if len(measurements) != 0 {
+		t.Errorf("No deberían guardarse mediciones, se guardaron %d", len(measurements))
+	}
+	stored, _ := tankRepo.GetTank(ctx, tank.ID)
+	if stored.CurrentLevel != 500 {
+		t.Errorf("El nivel del tanque no debería cambiar. Esperado: 500, Obtenido: %.2f", stored.CurrentLevel)
+	}
+}
@@ -0,0 +1,99 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestFieldDeviceService_DesiredAndAppliedConfig(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	fieldDeviceService := services.NewFieldDeviceService(tankService, repositories.NewMemoryFieldDeviceRepository())
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	config := domain.DeviceConfig{ReportingInterval: 900, LowLevelThreshold: 15, HighLevelThreshold: 95}
+
+	// Act: se asigna una configuración, el equipo la consulta y la confirma
+	device, err := fieldDeviceService.SetDesiredConfig(ctx, "gw-1", tank.ID, config)
+	if err != nil {
+		t.Fatalf("Error al asignar la configuración: %v", err)
+	}
+
+	polled, err := fieldDeviceService.PollConfig(ctx, "gw-1")
+	if err != nil {
+		t.Fatalf("Error al consultar la configuración: %v", err)
+	}
+
+	applied, err := fieldDeviceService.ReportAppliedConfig(ctx, "gw-1", *polled)
+	if err != nil {
+		t.Fatalf("Error al confirmar la configuración: %v", err)
+	}
+
+	// Un nuevo cambio deja el equipo pendiente de nuevo
+	config.ReportingInterval = 300
+	changed, err := fieldDeviceService.SetDesiredConfig(ctx, "gw-1", tank.ID, config)
+	if err != nil {
+		t.Fatalf("Error al cambiar la configuración: %v", err)
+	}
+
+	_, unknownErr := fieldDeviceService.ReportAppliedConfig(ctx, "gw-1", domain.DeviceConfig{Version: 7, ReportingInterval: 300})
+
+	// Assert
+	if device.Desired.Version != 1 || device.InSync {
+		t.Errorf("La primera configuración debe ser la versión 1 y quedar pendiente, se obtuvo %+v", device)
+	}
+	if polled.ReportingInterval != 900 {
+		t.Errorf("Configuración consultada incorrecta: %+v", polled)
+	}
+	if !applied.InSync || applied.LastPolledAt == nil {
+		t.Errorf("El equipo debe quedar sincronizado tras confirmar la configuración, se obtuvo %+v", applied)
+	}
+	if changed.Desired.Version != 2 || changed.InSync || changed.Applied.Version != 1 {
+		t.Errorf("El cambio debe crear la versión 2 pendiente de aplicar, se obtuvo %+v", changed)
+	}
+	if !errors.Is(unknownErr, services.ErrUnknownConfigVersion) {
+		t.Errorf("Se esperaba ErrUnknownConfigVersion, se obtuvo %v", unknownErr)
+	}
+}
+
+func TestFieldDeviceService_InvalidConfig(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	fieldDeviceService := services.NewFieldDeviceService(tankService, repositories.NewMemoryFieldDeviceRepository())
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act
+	_, invalidErr := fieldDeviceService.SetDesiredConfig(ctx, "gw-1", tank.ID, domain.DeviceConfig{ReportingInterval: 60, LowLevelThreshold: 90, HighLevelThreshold: 20})
+	_, missingTankErr := fieldDeviceService.SetDesiredConfig(ctx, "gw-1", "no-existe", domain.DeviceConfig{ReportingInterval: 60})
+	_, notFoundErr := fieldDeviceService.PollConfig(ctx, "gw-1")
+
+	// Assert
+	if !errors.Is(invalidErr, services.ErrInvalidDeviceConfig) {
+		t.Errorf("Se esperaba ErrInvalidDeviceConfig, se obtuvo %v", invalidErr)
+	}
+	if missingTankErr == nil {
+		t.Error("Se esperaba un error al asignar el equipo a un tanque inexistente")
+	}
+	if !errors.Is(notFoundErr, services.ErrFieldDeviceNotFound) {
+		t.Errorf("Se esperaba ErrFieldDeviceNotFound, se obtuvo %v", notFoundErr)
+	}
+}
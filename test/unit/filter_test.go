@@ -0,0 +1,107 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestParseFilter(t *testing.T) {
+	rssi := -95.0
+	measurement := &domain.Measurement{
+		Level:          80,
+		Temperature:    35,
+		SensorID:       "Radar-1",
+		SignalStrength: &rssi,
+		Timestamp:      time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{"level<100 AND temperature>30", true},
+		{"level < 100 and temperature > 40", false},
+		{"level>100 OR temperature>=35", true},
+		{"NOT (level>100 OR temperature<30)", true},
+		{"level>100 OR level<90 AND temperature=35", true}, // AND antes que OR
+		{"(level>100 OR level<90) AND temperature!=35", false},
+		{"sensor_id='radar-1'", true},
+		{"rssi<=-90", true},
+		{"battery_voltage<3.2", false}, // Sin valor no se cumple la comparación
+		{"battery_voltage!=3.2", false},
+		// Lógica de tres valores de SQL: NOT de una comparación sin valor tampoco se cumple
+		{"NOT battery_voltage<3.2", false},
+		{"NOT (battery_voltage<3.2 AND level>100)", true}, // desconocido AND falso es falso
+		{"NOT (battery_voltage<3.2 OR level<100)", false}, // desconocido OR cierto es cierto
+		{"battery_voltage<3.2 OR level<100", true},
+		{"NOT (battery_voltage<3.2 OR level>100)", false}, // desconocido OR falso es desconocido
+		{"timestamp>=2026-03-01T00:00:00Z AND timestamp<\"2026-03-02 00:00:00Z\"", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			filter, err := domain.ParseFilter(tt.expression, domain.MeasurementFilterFields)
+			if err != nil {
+				t.Fatalf("Error inesperado al analizar el filtro: %v", err)
+			}
+			if got := filter.Match(measurement); got != tt.want {
+				t.Errorf("Match() = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, expression := range []string{
+		"", "level", "level<", "level<abc", "nivel<100", "level<100 AND", "(level<100", "level<100)",
+		"sensor_id>'a'", "timestamp>ayer", "level<100 temperature>30", "sensor_id='sin cerrar", "level!100",
+	} {
+		if _, err := domain.ParseFilter(expression, domain.MeasurementFilterFields); err == nil {
+			t.Errorf("Se esperaba un error con el filtro %q", expression)
+		}
+	}
+
+	// El error indica el motivo y la posición
+	_, err := domain.ParseFilter("level<100 AND nivel>3", domain.MeasurementFilterFields)
+	var invalid *domain.FilterError
+	if !errors.As(err, &invalid) || invalid.Position != 15 || invalid.Error() != `unknown field "nivel" at position 15` {
+		t.Errorf("Error inesperado: %v", err)
+	}
+}
+
+func TestMeasurementService_GetMeasurementsWithFilter(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	tank := createTestTank()
+	if err := tankService.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	start := time.Now().Add(-time.Hour)
+	for i, level := range []float64{50, 150, 70, 160, 90} {
+		measurement := createTestMeasurement(tank.ID, level)
+		measurement.Timestamp = start.Add(time.Duration(i) * time.Minute)
+		if err := measurementRepo.SaveMeasurement(context.Background(), measurement); err != nil {
+			t.Fatalf("Error al guardar la medición: %v", err)
+		}
+	}
+	measurementService := services.NewMeasurementService(tankService, measurementRepo, measurementRepo)
+
+	// Act
+	measurements, err := measurementService.GetMeasurements(context.Background(), tank.ID, 2, "level<100")
+
+	// Assert: las dos más recientes de las que cumplen el filtro
+	if err != nil {
+		t.Fatalf("Error inesperado: %v", err)
+	}
+	if len(measurements) != 2 || measurements[0].Level != 90 || measurements[1].Level != 70 {
+		t.Errorf("Mediciones filtradas incorrectas: %+v", measurements)
+	}
+	if _, err := measurementService.GetMeasurements(context.Background(), tank.ID, 0, "nivel<100"); !errors.Is(err, services.ErrInvalidFilter) {
+		t.Errorf("Se esperaba ErrInvalidFilter, se obtuvo %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+// MockPushSender registra los dispositivos notificados y simula tokens caducados
+type MockPushSender struct {
+	Pushed       []string
+	Unregistered map[string]bool
+}
+
+func (m *MockPushSender) Push(ctx context.Context, device *domain.MobileDevice, alert *domain.Alert) error {
+	if m.Unregistered[device.ID] {
+		return domain.ErrDeviceUnregistered
+	}
+	m.Pushed = append(m.Pushed, device.ID)
+	return nil
+}
+
+func TestGeofencedPushNotifier_OnlyNotifiesNearbyDevices(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	deviceRepo := repositories.NewMemoryMobileDeviceRepository()
+	sender := &MockPushSender{Unregistered: map[string]bool{"caducado": true}}
+	notifier := services.NewGeofencedPushNotifier(tankRepo, deviceRepo, sender, 25)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	tank.Location = &domain.GeoLocation{Latitude: 4.7110, Longitude: -74.0721} // Bogotá
+	if err := tankRepo.SaveTank(ctx, tank); err != nil {
+		t.Fatalf("Error al guardar el tanque: %v", err)
+	}
+
+	devices := []*domain.MobileDevice{
+		{ID: "cercano", Platform: domain.PushPlatformFCM, Token: "a", Location: domain.GeoLocation{Latitude: 4.75, Longitude: -74.05}},
+		{ID: "lejano", Platform: domain.PushPlatformAPNs, Token: "b", Location: domain.GeoLocation{Latitude: 6.2442, Longitude: -75.5812}}, // Medellín
+		{ID: "caducado", Platform: domain.PushPlatformFCM, Token: "c", Location: domain.GeoLocation{Latitude: 4.70, Longitude: -74.08}},
+	}
+	for _, device := range devices {
+		if err := deviceRepo.SaveDevice(ctx, device); err != nil {
+			t.Fatalf("Error al guardar el dispositivo: %v", err)
+		}
+	}
+
+	// Act
+	alert := domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, tank, "Nivel crítico")
+	if err := notifier.Notify(ctx, alert); err != nil {
+		t.Fatalf("Error al enviar la alerta: %v", err)
+	}
+
+	info := domain.NewAlert(domain.AlertEventWeakSignal, domain.AlertSeverityInfo, tank, "Señal débil")
+	if err := notifier.Notify(ctx, info); err != nil {
+		t.Fatalf("Error al enviar el aviso informativo: %v", err)
+	}
+
+	// Assert
+	if len(sender.Pushed) != 1 || sender.Pushed[0] != "cercano" {
+		t.Errorf("Solo se esperaba notificar la alerta crítica al dispositivo cercano, se notificó a %v", sender.Pushed)
+	}
+
+	// Los dispositivos con el token caducado se dan de baja
+	if device, _ := deviceRepo.GetDevice(ctx, "caducado"); device != nil {
+		t.Error("Se esperaba dar de baja el dispositivo con el token caducado")
+	}
+}
@@ -0,0 +1,286 @@
+package services_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/mqtt"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// MockTankStatePublisher registra los tanques publicados y retirados
+type MockTankStatePublisher struct {
+	Published []*domain.Tank
+	Removed   []string
+}
+
+func (m *MockTankStatePublisher) PublishTank(ctx context.Context, tank *domain.Tank) {
+	m.Published = append(m.Published, tank)
+}
+
+func (m *MockTankStatePublisher) RemoveTank(ctx context.Context, tankID string) {
+	m.Removed = append(m.Removed, tankID)
+}
+
+// retainedMessage es un mensaje recibido por el broker de prueba
+type retainedMessage struct {
+	topic    string
+	payload  []byte
+	retained bool
+}
+
+// startTestBroker abre un broker MQTT mínimo que acepta las conexiones y confirma cada
+// publicación, enviando los mensajes recibidos por el canal devuelto
+func startTestBroker(t *testing.T) (string, <-chan retainedMessage) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error al abrir el broker de prueba: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan retainedMessage, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestBroker(conn, messages)
+		}
+	}()
+	return listener.Addr().String(), messages
+}
+
+// serveTestBroker atiende una conexión del broker de prueba
+func serveTestBroker(conn net.Conn, messages chan<- retainedMessage) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		header, err := reader.ReadByte()
+		if err != nil {
+			return
+		}
+		length, multiplier := 0, 1
+		for {
+			encoded, _ := reader.ReadByte()
+			length += int(encoded&0x7F) * multiplier
+			if encoded&0x80 == 0 {
+				break
+			}
+			multiplier *= 128
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return
+		}
+
+		switch header & 0xF0 {
+		case 0x10:
+			conn.Write([]byte{0x20, 0x02, 0x00, 0x00})
+		case 0x30:
+			topicLength := int(body[0])<<8 | int(body[1])
+			packetID := body[2+topicLength : 4+topicLength]
+			conn.Write([]byte{0x40, 0x02, packetID[0], packetID[1]})
+			messages <- retainedMessage{
+				topic:    string(body[2 : 2+topicLength]),
+				payload:  body[4+topicLength:],
+				retained: header&0x01 != 0,
+			}
+		case 0xE0:
+			return
+		}
+	}
+}
+
+// receiveMessages espera count mensajes del broker de prueba
+func receiveMessages(t *testing.T, messages <-chan retainedMessage, count int) []retainedMessage {
+	t.Helper()
+	received := make([]retainedMessage, 0, count)
+	for len(received) < count {
+		select {
+		case message := <-messages:
+			received = append(received, message)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Se esperaban %d mensajes, se recibieron %d", count, len(received))
+		}
+	}
+	return received
+}
+
+func TestHomeAssistantPublisher_PublishesDiscoveryAndState(t *testing.T) {
+	// Arrange
+	addr, messages := startTestBroker(t)
+	publisher := mqtt.NewHomeAssistantPublisher(mqtt.Config{BrokerAddr: addr, ClientID: "test"}, mqtt.HomeAssistantConfig{}, logger.NewSimpleLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		publisher.Run(ctx)
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	tank := createTestTank()
+	tank.ID = "t1"
+	tank.CurrentLevel = 250
+
+	// Act: la primera publicación anuncia el tanque; la segunda solo envía el estado
+	publisher.PublishTank(ctx, tank)
+	first := receiveMessages(t, messages, 5)
+
+	tank.CurrentLevel = 200
+	publisher.PublishTank(ctx, tank)
+	second := receiveMessages(t, messages, 1)
+
+	publisher.RemoveTank(ctx, tank.ID)
+	removal := receiveMessages(t, messages, 5)
+
+	// Assert
+	var discovery struct {
+		UniqueID   string `json:"unique_id"`
+		StateTopic string `json:"state_topic"`
+		Device     struct {
+			Name string `json:"name"`
+		} `json:"device"`
+	}
+	if first[0].topic != "homeassistant/sensor/monitor_tanques_t1/level/config" {
+		t.Errorf("Tema de descubrimiento incorrecto: %s", first[0].topic)
+	}
+	if err := json.Unmarshal(first[0].payload, &discovery); err != nil {
+		t.Fatalf("Mensaje de descubrimiento inválido: %s", first[0].payload)
+	}
+	if discovery.UniqueID != "monitor_tanques_t1_level" || discovery.StateTopic != "monitor-tanques/tanks/t1/state" || discovery.Device.Name != tank.Name {
+		t.Errorf("Descubrimiento incorrecto: %+v", discovery)
+	}
+	for _, message := range append(append(first, second...), removal...) {
+		if !message.retained {
+			t.Errorf("El mensaje de %s debe publicarse retenido", message.topic)
+		}
+	}
+
+	var state struct {
+		Level      float64 `json:"level"`
+		Percentage float64 `json:"percentage"`
+		Status     string  `json:"status"`
+	}
+	if second[0].topic != "monitor-tanques/tanks/t1/state" {
+		t.Fatalf("Sin cambios en el tanque solo debe publicarse el estado, se obtuvo %s", second[0].topic)
+	}
+	json.Unmarshal(second[0].payload, &state)
+	if state.Level != 200 || state.Percentage != 20 || state.Status != "normal" {
+		t.Errorf("Estado incorrecto: %s", second[0].payload)
+	}
+
+	for _, message := range removal {
+		if len(message.payload) != 0 {
+			t.Errorf("Al retirar el tanque, %s debe quedar vacío, se obtuvo %s", message.topic, message.payload)
+		}
+	}
+	if !strings.HasSuffix(removal[0].topic, "/config") {
+		t.Errorf("La retirada debe borrar el descubrimiento, se obtuvo %s", removal[0].topic)
+	}
+}
+
+func TestHomeAssistantPublisher_RetriesWhenBrokerReturns(t *testing.T) {
+	// Arrange: reservamos una dirección sin broker escuchando
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error al reservar la dirección del broker: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	publisher := mqtt.NewHomeAssistantPublisher(mqtt.Config{BrokerAddr: addr, ClientID: "test"}, mqtt.HomeAssistantConfig{}, logger.NewSimpleLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		publisher.Run(ctx)
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	tank := createTestTank()
+	tank.ID = "t1"
+	tank.CurrentLevel = 250
+
+	// Act: el primer envío falla; el broker vuelve antes del reintento
+	publisher.PublishTank(ctx, tank)
+	time.Sleep(200 * time.Millisecond)
+
+	listener, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("No se pudo volver a abrir la dirección del broker: %v", err)
+	}
+	defer listener.Close()
+	messages := make(chan retainedMessage, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestBroker(conn, messages)
+		}
+	}()
+
+	// Assert: el reintento publica el descubrimiento y el estado pendientes
+	received := receiveMessages(t, messages, 5)
+	if received[4].topic != "monitor-tanques/tanks/t1/state" {
+		t.Errorf("El reintento debería terminar con el estado del tanque, se obtuvo %s", received[4].topic)
+	}
+}
+
+func TestTankStatePublishingTankService_PublishesChanges(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	publisher := &MockTankStatePublisher{}
+	tankService := services.NewTankStatePublishingTankService(newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{}), publisher)
+	ctx := context.Background()
+
+	tank := createTestTank()
+
+	// Act
+	createErr := tankService.CreateTank(ctx, tank)
+	measurementErr := tankService.AddMeasurements(ctx, []*domain.Measurement{
+		createTestMeasurement(tank.ID, 400),
+		createTestMeasurement(tank.ID, 300),
+	})
+	allErr := tankService.PublishAllTanks(ctx)
+	deleteErr := tankService.DeleteTank(ctx, tank.ID)
+	failedErr := tankService.AddMeasurement(ctx, createTestMeasurement("inexistente", 100))
+
+	// Assert
+	if createErr != nil || measurementErr != nil || allErr != nil || deleteErr != nil {
+		t.Fatalf("Errores inesperados: %v, %v, %v, %v", createErr, measurementErr, allErr, deleteErr)
+	}
+	if failedErr == nil {
+		t.Error("Se esperaba un error al medir un tanque inexistente")
+	}
+	if len(publisher.Published) != 3 {
+		t.Fatalf("Se esperaban 3 publicaciones (alta, lote y arranque), se obtuvieron %d", len(publisher.Published))
+	}
+	if publisher.Published[1].CurrentLevel != 300 {
+		t.Errorf("El lote debe publicar el estado final del tanque, se obtuvo %v", publisher.Published[1].CurrentLevel)
+	}
+	if len(publisher.Removed) != 1 || publisher.Removed[0] != tank.ID {
+		t.Errorf("Se esperaba la retirada del tanque, se obtuvo %v", publisher.Removed)
+	}
+}
@@ -0,0 +1,47 @@
+package services_test
+
+import (
+	"testing"
+
+	"monitor-tanques/pkg/i18n"
+)
+
+func TestI18n_Negotiate(t *testing.T) {
+	cases := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{"", "es"},
+		{"en", "en"},
+		{"en-US,en;q=0.9", "en"},
+		{"fr-FR, en;q=0.5, es;q=0.8", "es"},
+		{"fr", "es"},
+		{"de, *;q=0.1", "es"},
+		{"es-CO;q=0.4, EN;q=0.6", "en"},
+	}
+
+	for _, c := range cases {
+		if language := i18n.Negotiate(c.acceptLanguage); language != c.expected {
+			t.Errorf("%q: esperado %s, obtenido %s", c.acceptLanguage, c.expected, language)
+		}
+	}
+}
+
+func TestI18n_TranslateFallsBackToSource(t *testing.T) {
+	// Act
+	translated := i18n.Translate("en", "Tanque no encontrado")
+	missing := i18n.Translate("en", "Texto sin traducción")
+	spanish := i18n.Translate("es", "Tanque no encontrado")
+	formatted := i18n.Sprintf("en", "Batería baja en el sensor %s del tanque %s (%.2f V). Programe su reemplazo.", "radar-1", "tanque-1", 3.1)
+
+	// Assert
+	if translated != "Tank not found" {
+		t.Errorf("Traducción inesperada: %q", translated)
+	}
+	if missing != "Texto sin traducción" || spanish != "Tanque no encontrado" {
+		t.Errorf("Sin traducción debe devolverse el texto original: %q, %q", missing, spanish)
+	}
+	if formatted != "Low battery on sensor radar-1 of tank tanque-1 (3.10 V). Schedule its replacement." {
+		t.Errorf("Mensaje formateado inesperado: %q", formatted)
+	}
+}
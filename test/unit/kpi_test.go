@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+func TestBuildTankKPIs(t *testing.T) {
+	// Arrange: 1000 L de capacidad; 2 días a 500 L, 1 día a 0 L (agotado) y 1 día a 1000 L tras una recarga
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(4 * 24 * time.Hour)
+	tank := &domain.Tank{ID: "t1", Capacity: 1000, AlertThreshold: 10}
+
+	measurements := []*domain.Measurement{
+		{TankID: "t1", Level: 0, Timestamp: from.Add(2 * 24 * time.Hour)},
+		{TankID: "t1", Level: 500, Timestamp: from.Add(-time.Hour)},
+		{TankID: "t1", Level: 1000, Timestamp: from.Add(3 * 24 * time.Hour)},
+	}
+	changes := []*domain.StatusChange{
+		{TankID: "t1", FromStatus: "", ToStatus: "normal", ChangedAt: from.Add(-time.Hour)},
+		{TankID: "t1", FromStatus: "normal", ToStatus: "critical", ChangedAt: from.Add(2 * 24 * time.Hour)},
+		{TankID: "t1", FromStatus: "critical", ToStatus: "normal", ChangedAt: from.Add(3 * 24 * time.Hour)},
+	}
+
+	// Act
+	kpis := domain.BuildTankKPIs(tank, measurements, changes, from, to)
+
+	// Assert
+	if kpis.AverageLevel != 500 {
+		t.Errorf("Inventario medio incorrecto. Esperado: 500, Obtenido: %.2f", kpis.AverageLevel)
+	}
+	if kpis.AverageFillPercentage != 50 {
+		t.Errorf("Llenado medio incorrecto. Esperado: 50, Obtenido: %.2f", kpis.AverageFillPercentage)
+	}
+	if kpis.Consumption != 500 || kpis.Replenishment != 1000 {
+		t.Errorf("Consumo/recarga incorrectos. Obtenido: %.2f / %.2f", kpis.Consumption, kpis.Replenishment)
+	}
+	if kpis.Turnover != 1 {
+		t.Errorf("Rotación incorrecta. Esperado: 1, Obtenido: %.2f", kpis.Turnover)
+	}
+	if math.Abs(kpis.DaysAtCritical-1) > 1e-9 {
+		t.Errorf("Días en estado crítico incorrectos. Esperado: 1, Obtenido: %.2f", kpis.DaysAtCritical)
+	}
+	if kpis.Stockouts != 1 {
+		t.Errorf("Roturas de stock incorrectas. Esperado: 1, Obtenido: %d", kpis.Stockouts)
+	}
+}
+
+func TestBuildTankLevelStats(t *testing.T) {
+	// Arrange: cinco lecturas del 10% al 50% en el periodo y una anterior que no cuenta
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(30 * 24 * time.Hour)
+	tank := &domain.Tank{ID: "t1", Capacity: 1000, AlertThreshold: 15}
+
+	measurements := []*domain.Measurement{
+		{TankID: "t1", Level: 900, Temperature: 40, Timestamp: from.Add(-time.Hour)},
+		{TankID: "t1", Level: 300, Temperature: 18, Timestamp: from.Add(3 * 24 * time.Hour)},
+		{TankID: "t1", Level: 100, Temperature: -3, Timestamp: from.Add(24 * time.Hour)},
+		{TankID: "t1", Level: 500, Temperature: 22, Timestamp: from.Add(5 * 24 * time.Hour)},
+		{TankID: "t1", Level: 200, Temperature: 15, Timestamp: from.Add(2 * 24 * time.Hour)},
+		{TankID: "t1", Level: 400, Temperature: 20, Timestamp: from.Add(4 * 24 * time.Hour)},
+	}
+
+	// Act
+	stats := domain.BuildTankLevelStats(tank, measurements, from, to)
+
+	// Assert
+	if stats.Samples != 5 {
+		t.Fatalf("Lecturas incorrectas. Esperado: 5, Obtenido: %d", stats.Samples)
+	}
+	if stats.MeanFillPercentage != 30 || stats.MedianFillPercentage != 30 {
+		t.Errorf("Media/mediana incorrectas. Obtenido: %.2f / %.2f", stats.MeanFillPercentage, stats.MedianFillPercentage)
+	}
+	if math.Abs(stats.P5FillPercentage-12) > 1e-9 || math.Abs(stats.P95FillPercentage-48) > 1e-9 {
+		t.Errorf("Percentiles incorrectos. Esperado: 12 / 48, Obtenido: %.2f / %.2f", stats.P5FillPercentage, stats.P95FillPercentage)
+	}
+	if stats.MinFillPercentage != 10 || stats.MaxFillPercentage != 50 {
+		t.Errorf("Extremos incorrectos. Obtenido: %.2f / %.2f", stats.MinFillPercentage, stats.MaxFillPercentage)
+	}
+	if math.Abs(stats.FillVariance-200) > 1e-9 {
+		t.Errorf("Varianza incorrecta. Esperado: 200, Obtenido: %.2f", stats.FillVariance)
+	}
+	if stats.MinTemperature == nil || *stats.MinTemperature != -3 || stats.MaxTemperature == nil || *stats.MaxTemperature != 22 {
+		t.Errorf("Temperaturas extremas incorrectas: %v / %v", stats.MinTemperature, stats.MaxTemperature)
+	}
+	if stats.SamplesBelowThreshold != 1 {
+		t.Errorf("Lecturas bajo el umbral incorrectas. Esperado: 1, Obtenido: %d", stats.SamplesBelowThreshold)
+	}
+
+	empty := domain.BuildTankLevelStats(tank, nil, from, to)
+	if empty.Samples != 0 || empty.MinTemperature != nil {
+		t.Errorf("Sin lecturas no debería haber estadísticas: %+v", empty)
+	}
+}
@@ -0,0 +1,121 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestLabelSelector_Matches(t *testing.T) {
+	labels := domain.Labels{"region": "caribe", "customer": "acme", "tier": "1"}
+
+	cases := []struct {
+		selector string
+		expected bool
+	}{
+		{"", true},
+		{"region=caribe", true},
+		{"region==caribe,customer=acme", true},
+		{"region=andina", false},
+		{"customer!=acme", false},
+		{"segment!=retail", true},
+		{"tier in (1, 2)", true},
+		{"region notin (caribe,andina)", false},
+		{"segment notin (retail)", true},
+		{"customer", true},
+		{"!retired", true},
+		{"!customer", false},
+		{"region=caribe, tier in (2,3)", false},
+	}
+
+	for _, c := range cases {
+		selector, err := domain.ParseLabelSelector(c.selector)
+		if err != nil {
+			t.Errorf("%q: no se esperaba error, se obtuvo %v", c.selector, err)
+			continue
+		}
+		if matched := selector.Matches(labels); matched != c.expected {
+			t.Errorf("%q: esperado %v, obtenido %v", c.selector, c.expected, matched)
+		}
+	}
+}
+
+func TestLabelSelector_Invalid(t *testing.T) {
+	for _, selector := range []string{"=caribe", "region=caribe,", "tier in (1,2", "tier between (1,2)", "región=caribe", "a/b/c=1"} {
+		if _, err := domain.ParseLabelSelector(selector); !errors.Is(err, domain.ErrInvalidLabelSelector) {
+			t.Errorf("%q: se esperaba ErrInvalidLabelSelector, se obtuvo %v", selector, err)
+		}
+	}
+}
+
+func TestTankService_RejectsInvalidLabels(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	ctx := context.Background()
+
+	valid := createTestTank()
+	valid.Labels = domain.Labels{"region": "caribe", "acme.com/cuenta": "A-17"}
+	invalid := createTestTank()
+	invalid.Labels = domain.Labels{"región": "caribe"}
+
+	// Act
+	validErr := tankService.CreateTank(ctx, valid)
+	invalidErr := tankService.CreateTank(ctx, invalid)
+
+	// Assert
+	if validErr != nil {
+		t.Errorf("No se esperaba error con etiquetas válidas, se obtuvo %v", validErr)
+	}
+	if !errors.Is(invalidErr, services.ErrInvalidTank) {
+		t.Errorf("Se esperaba ErrInvalidTank con una clave inválida, se obtuvo %v", invalidErr)
+	}
+
+	// Modificar las etiquetas devueltas no altera el tanque guardado
+	fetched, _ := tankService.GetTank(ctx, valid.ID)
+	fetched.Labels["region"] = "andina"
+	again, _ := tankService.GetTank(ctx, valid.ID)
+	if again.Labels["region"] != "caribe" {
+		t.Errorf("Las etiquetas del repositorio no deben compartirse, se obtuvo %v", again.Labels)
+	}
+}
+
+func TestNotificationService_TankSelector(t *testing.T) {
+	// Arrange: un canal recibe solo las alertas de los tanques del Caribe
+	channelRepo := repositories.NewMemoryNotificationChannelRepository()
+	queueRepo := repositories.NewMemoryAlertQueueRepository()
+	sender := &MockChannelSender{}
+	defaultNotifier := &MockAlertNotifier{}
+	service := services.NewNotificationService(channelRepo, queueRepo, sender, defaultNotifier, services.NewAccessService(repositories.NewMemoryAccessGrantRepository()))
+	ctx := context.Background()
+
+	caribe := &domain.NotificationChannel{ID: "caribe", Name: "Caribe", Type: domain.ChannelTypeLog, Enabled: true, TankSelector: "region=caribe"}
+	if err := service.CreateChannel(ctx, caribe); err != nil {
+		t.Fatalf("Error al crear el canal: %v", err)
+	}
+	invalid := &domain.NotificationChannel{ID: "x", Name: "X", Type: domain.ChannelTypeLog, TankSelector: "region in (caribe"}
+
+	inCaribe := &domain.Tank{ID: "t1", Labels: domain.Labels{"region": "caribe"}}
+	inAndes := &domain.Tank{ID: "t2", Labels: domain.Labels{"region": "andina"}}
+
+	// Act
+	service.Notify(ctx, domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, inCaribe, "Nivel crítico"))
+	service.Notify(ctx, domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, inAndes, "Nivel crítico"))
+	invalidErr := service.CreateChannel(ctx, invalid)
+
+	// Assert
+	if len(sender.Sent) != 1 || sender.Sent[0] != "caribe" {
+		t.Errorf("Solo la alerta del Caribe debía ir al canal, se enviaron %v", sender.Sent)
+	}
+	if defaultNotifier.AlertsSent != 1 || defaultNotifier.LastTankID != "t2" {
+		t.Errorf("La alerta sin canal debía ir al notificador predeterminado, se obtuvo %d (%s)", defaultNotifier.AlertsSent, defaultNotifier.LastTankID)
+	}
+	if !errors.Is(invalidErr, services.ErrInvalidChannel) {
+		t.Errorf("Se esperaba ErrInvalidChannel con un selector inválido, se obtuvo %v", invalidErr)
+	}
+}
@@ -0,0 +1,144 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestLiquidPolicyService_SaveValidates(t *testing.T) {
+	// Arrange
+	policyService := services.NewLiquidPolicyService(repositories.NewMemoryLiquidPolicyRepository())
+	ctx := context.Background()
+
+	cases := map[string]*domain.LiquidPolicy{
+		"sin líquido":            {MinSafetyStock: 10, MaxFill: 90},
+		"mínimo negativo":        {LiquidType: "Diésel", MinSafetyStock: -1, MaxFill: 90},
+		"llenado excesivo":       {LiquidType: "Diésel", MinSafetyStock: 10, MaxFill: 120},
+		"mínimo sobre el máximo": {LiquidType: "Diésel", MinSafetyStock: 95, MaxFill: 90},
+		"contacto inválido":      {LiquidType: "Diésel", MinSafetyStock: 10, MaxFill: 90, HazmatContacts: []string{"seguridad"}},
+	}
+
+	for name, policy := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := policyService.SavePolicy(ctx, policy)
+
+			// Assert
+			if !errors.Is(err, services.ErrInvalidLiquidPolicy) {
+				t.Errorf("Se esperaba ErrInvalidLiquidPolicy, se obtuvo: %v", err)
+			}
+		})
+	}
+
+	saved, err := policyService.SavePolicy(ctx, &domain.LiquidPolicy{LiquidType: " Diésel ", MinSafetyStock: 15, MaxFill: 90})
+	if err != nil || saved.LiquidType != "Diésel" || saved.UpdatedAt.IsZero() {
+		t.Fatalf("No se guardó la política válida: %+v, %v", saved, err)
+	}
+	if found, err := policyService.GetPolicy(ctx, "diésel"); err != nil || found.MinSafetyStock != 15 {
+		t.Errorf("La política debe encontrarse sin distinguir mayúsculas: %+v, %v", found, err)
+	}
+	if err := policyService.DeletePolicy(ctx, "Gasolina"); !errors.Is(err, services.ErrLiquidPolicyNotFound) {
+		t.Errorf("Se esperaba ErrLiquidPolicyNotFound, se obtuvo: %v", err)
+	}
+}
+
+func TestLiquidPolicyTankService_AppliesAndEnforcesPolicy(t *testing.T) {
+	// Arrange
+	policyRepo := repositories.NewMemoryLiquidPolicyRepository()
+	tankService := services.NewLiquidPolicyTankService(
+		newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{}),
+		policyRepo,
+	)
+	ctx := context.Background()
+	policyRepo.SavePolicy(ctx, &domain.LiquidPolicy{LiquidType: "Ácido", MinSafetyStock: 20, MaxFill: 85})
+
+	defaulted := createTestTank()
+	defaulted.LiquidType = "ácido"
+	defaulted.AlertThreshold = 0
+	belowMinimum := createTestTank()
+	belowMinimum.LiquidType = "Ácido"
+	belowMinimum.AlertThreshold = 5
+	unrelated := createTestTank()
+	unrelated.AlertThreshold = 5
+
+	// Act
+	defaultedErr := tankService.CreateTank(ctx, defaulted)
+	belowMinimumErr := tankService.CreateTank(ctx, belowMinimum)
+	unrelatedErr := tankService.CreateTank(ctx, unrelated)
+
+	lowReorder := *defaulted
+	lowReorder.Reorder = domain.ReorderConfig{ReorderLevel: 100, DeliverySize: 500}
+	lowReorderErr := tankService.UpdateTank(ctx, &lowReorder)
+
+	overfill := *defaulted
+	overfill.Reorder = domain.ReorderConfig{ReorderLevel: 300, DeliverySize: 600}
+	overfillErr := tankService.UpdateTank(ctx, &overfill)
+
+	valid := *defaulted
+	valid.Reorder = domain.ReorderConfig{ReorderLevel: 300, DeliverySize: 500}
+	validErr := tankService.UpdateTank(ctx, &valid)
+
+	// Assert
+	if defaultedErr != nil || defaulted.AlertThreshold != 20 {
+		t.Errorf("El tanque nuevo debe tomar el umbral de la política: %.2f, %v", defaulted.AlertThreshold, defaultedErr)
+	}
+	if !errors.Is(belowMinimumErr, domain.ErrSafetyPolicyViolation) {
+		t.Errorf("Se esperaba ErrSafetyPolicyViolation para el umbral bajo, se obtuvo: %v", belowMinimumErr)
+	}
+	if unrelatedErr != nil {
+		t.Errorf("Los líquidos sin política no deben restringirse: %v", unrelatedErr)
+	}
+	if !errors.Is(lowReorderErr, domain.ErrSafetyPolicyViolation) {
+		t.Errorf("Se esperaba ErrSafetyPolicyViolation para el punto de pedido bajo, se obtuvo: %v", lowReorderErr)
+	}
+	if !errors.Is(overfillErr, domain.ErrSafetyPolicyViolation) {
+		t.Errorf("Se esperaba ErrSafetyPolicyViolation para el sobrellenado, se obtuvo: %v", overfillErr)
+	}
+	if validErr != nil {
+		t.Errorf("No se esperaba error con umbrales dentro de la política: %v", validErr)
+	}
+}
+
+func TestHazmatAlertNotifier_EmailsContactsOnCriticalAlerts(t *testing.T) {
+	// Arrange
+	policyRepo := repositories.NewMemoryLiquidPolicyRepository()
+	notifier := &MockAlertNotifier{}
+	sender := &MockEmailSender{}
+	hazmatNotifier := services.NewHazmatAlertNotifier(notifier, policyRepo, sender)
+	ctx := context.Background()
+	policyRepo.SavePolicy(ctx, &domain.LiquidPolicy{
+		LiquidType:     "Ácido",
+		MinSafetyStock: 20,
+		MaxFill:        85,
+		HazmatContacts: []string{"seguridad@example.com"},
+	})
+
+	acid := createTestTank()
+	acid.LiquidType = "Ácido"
+	acid.CurrentLevel = 50
+
+	// Act
+	hazmatNotifier.Notify(ctx, domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, acid, "Nivel crítico"))
+	hazmatNotifier.Notify(ctx, domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityWarning, acid, "Aviso"))
+	hazmatNotifier.Notify(ctx, domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, createTestTank(), "Agua"))
+
+	// Assert
+	if notifier.AlertsSent != 3 {
+		t.Errorf("Todas las alertas deben llegar a los canales, se enviaron %d", notifier.AlertsSent)
+	}
+	if sender.Sent != 1 {
+		t.Fatalf("Se esperaba 1 correo a los contactos, se enviaron %d", sender.Sent)
+	}
+	if len(sender.LastTo) != 1 || sender.LastTo[0] != "seguridad@example.com" {
+		t.Errorf("Destinatarios incorrectos: %v", sender.LastTo)
+	}
+	if !strings.Contains(sender.LastSubject, "Ácido") || !strings.Contains(sender.LastBody, "Nivel crítico") {
+		t.Errorf("Correo incorrecto: %q\n%s", sender.LastSubject, sender.LastBody)
+	}
+}
@@ -0,0 +1,165 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"monitor-tanques/internal/adapters/broadcast"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+	"monitor-tanques/pkg/metrics"
+)
+
+// recordingPublisher guarda los eventos publicados
+type recordingPublisher struct {
+	subscribers bool
+	events      []*domain.LiveEvent
+}
+
+func (p *recordingPublisher) Publish(event *domain.LiveEvent) {
+	p.events = append(p.events, event)
+}
+
+func (p *recordingPublisher) HasSubscribers() bool {
+	return p.subscribers
+}
+
+func TestValidateLiveTopic(t *testing.T) {
+	tests := []struct {
+		topic string
+		valid bool
+	}{
+		{"tank:tanque-1", true},
+		{"site:estacion-norte", true},
+		{"severity:critical", true},
+		{"severity:urgente", false},
+		{"tank:", false},
+		{"group:norte", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		err := domain.ValidateLiveTopic(tt.topic)
+		if tt.valid && err != nil {
+			t.Errorf("%q debería ser válido: %v", tt.topic, err)
+		}
+		if !tt.valid && !errors.Is(err, domain.ErrInvalidLiveTopic) {
+			t.Errorf("%q debería ser inválido, se obtuvo %v", tt.topic, err)
+		}
+	}
+}
+
+func TestLiveEventTankService_PublishesSavedMeasurements(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	publisher := &recordingPublisher{subscribers: true}
+	service := services.NewLiveEventTankService(newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{}), publisher)
+
+	tank := createTestTank()
+	tank.SiteID = "estacion-norte"
+	service.CreateTank(context.Background(), tank)
+
+	// Act
+	err := service.AddMeasurement(context.Background(), createTestMeasurement(tank.ID, 400))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	if len(publisher.events) != 1 {
+		t.Fatalf("Se esperaba un evento, se obtuvieron %d", len(publisher.events))
+	}
+	event := publisher.events[0]
+	if event.Type != domain.LiveEventMeasurement || event.Tank.CurrentLevel != 400 {
+		t.Errorf("Evento incorrecto: %+v", event)
+	}
+	topics := event.Topics()
+	if len(topics) != 2 || topics[0] != "tank:"+tank.ID || topics[1] != "site:estacion-norte" {
+		t.Errorf("Temas incorrectos: %v", topics)
+	}
+
+	publisher.subscribers = false
+	service.AddMeasurement(context.Background(), createTestMeasurement(tank.ID, 300))
+	if len(publisher.events) != 1 {
+		t.Error("Sin clientes conectados no deberían publicarse eventos")
+	}
+}
+
+func TestLiveAlertNotifier_PublishesAlerts(t *testing.T) {
+	// Arrange
+	publisher := &recordingPublisher{subscribers: true}
+	next := &MockAlertNotifier{}
+	notifier := services.NewLiveAlertNotifier(next, publisher)
+	tank := createTestTank()
+	tank.SiteID = "estacion-norte"
+	alert := domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, tank, "Nivel crítico en %s", tank.Name)
+
+	// Act
+	err := notifier.Notify(context.Background(), alert)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	if next.AlertsSent != 1 {
+		t.Error("La alerta también debería entregarse por los canales")
+	}
+	if len(publisher.events) != 1 {
+		t.Fatalf("Se esperaba un evento, se obtuvieron %d", len(publisher.events))
+	}
+	topics := publisher.events[0].Topics()
+	if len(topics) != 3 || topics[2] != "severity:critical" {
+		t.Errorf("Temas incorrectos: %v", topics)
+	}
+}
+
+func TestBroadcastHub_EvictsSlowSubscribers(t *testing.T) {
+	// Arrange
+	liveMetrics := broadcast.NewMetrics(metrics.NewRegistry())
+	accessService := services.NewAccessService(repositories.NewMemoryAccessGrantRepository())
+	hub := broadcast.NewHub(accessService, broadcast.Config{SendBuffer: 1}, liveMetrics, logger.NewSimpleLogger())
+
+	tank := createTestTank()
+	slow := hub.Subscribe("websocket", nil)
+	fast := hub.Subscribe("sse", nil)
+	idle := hub.Subscribe("sse", nil)
+	slow.Subscribe([]string{"tank:" + tank.ID})
+	fast.Subscribe([]string{"tank:" + tank.ID})
+	idle.Subscribe([]string{"tank:otro"})
+
+	// Act
+	hub.Publish(domain.NewMeasurementEvent(tank, createTestMeasurement(tank.ID, 400)))
+	<-fast.Events()
+	hub.Publish(domain.NewMeasurementEvent(tank, createTestMeasurement(tank.ID, 300)))
+
+	// Assert
+	if err := slow.Err(); !errors.Is(err, broadcast.ErrSlowSubscriber) {
+		t.Errorf("El suscriptor con el búfer lleno debería desconectarse, se obtuvo: %v", err)
+	}
+	select {
+	case <-fast.Done():
+		t.Error("El suscriptor que consume sus eventos no debería desconectarse")
+	default:
+	}
+	if len(idle.Events()) != 0 {
+		t.Error("El suscriptor de otro tema no debería recibir eventos")
+	}
+	if evicted := liveMetrics.Evicted.Value("websocket"); evicted != 1 {
+		t.Errorf("Se esperaba un suscriptor desconectado, se obtuvieron %v", evicted)
+	}
+	if queued := liveMetrics.Queued.Value("sse"); queued != 2 {
+		t.Errorf("Se esperaban 2 eventos encolados por SSE, se obtuvieron %v", queued)
+	}
+
+	hub.Remove(slow)
+	if subscribers := liveMetrics.Subscribers.Value("websocket"); subscribers != 0 {
+		t.Errorf("El suscriptor dado de baja no debería contarse, se obtuvieron %v", subscribers)
+	}
+	if subscribers := liveMetrics.Subscribers.Value("sse"); subscribers != 2 {
+		t.Errorf("Se esperaban 2 suscriptores SSE, se obtuvieron %v", subscribers)
+	}
+}
@@ -0,0 +1,148 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/auth"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+// stubCaptchaVerifier acepta solo la respuesta válida
+type stubCaptchaVerifier struct {
+	valid string
+}
+
+func (v *stubCaptchaVerifier) VerifyCaptcha(ctx context.Context, response, remoteIP string) (bool, error) {
+	return response == v.valid, nil
+}
+
+func TestLoginFailures_WindowAndLockout(t *testing.T) {
+	// Arrange
+	config := domain.LoginGuardConfig{MaxFailures: 3, Window: time.Minute, Lockout: 10 * time.Minute}
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	failures := &domain.LoginFailures{WindowStart: start}
+
+	// Act
+	failures.RecordFailure(start, config)
+	failures.RecordFailure(start.Add(30*time.Second), config)
+	// Fuera de la ventana los fallos anteriores dejan de contar
+	windowReset := !failures.RecordFailure(start.Add(2*time.Minute), config) && failures.Count == 1
+	failures.RecordFailure(start.Add(2*time.Minute+time.Second), config)
+	locked := failures.RecordFailure(start.Add(2*time.Minute+2*time.Second), config)
+
+	// Assert
+	if !windowReset {
+		t.Errorf("Los fallos fuera de la ventana no deberían acumularse: %d", failures.Count)
+	}
+	if !locked || !failures.IsLocked(start.Add(5*time.Minute)) {
+		t.Error("El tercer fallo dentro de la ventana debería bloquear al cliente")
+	}
+	if failures.IsLocked(start.Add(13 * time.Minute)) {
+		t.Error("El bloqueo debería terminar tras la duración configurada")
+	}
+}
+
+func TestLoginGuard_LocksOutAndAudits(t *testing.T) {
+	// Arrange
+	events := repositories.NewMemorySecurityEventRepository()
+	guard, err := services.NewLoginGuard(domain.LoginGuardConfig{MaxFailures: 2, Window: time.Minute, Lockout: time.Minute}, events, nil)
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	ctx := context.Background()
+	attempt := domain.LoginAttempt{ClientIP: "203.0.113.7", Method: domain.LoginMethodToken, Path: "/api/tanks", Reason: "invalid token"}
+
+	// Act
+	guard.RecordFailure(ctx, attempt)
+	beforeLockout := guard.Check(ctx, attempt.ClientIP)
+	guard.RecordFailure(ctx, attempt)
+	wait := guard.Check(ctx, attempt.ClientIP)
+	guard.RecordSuccess(ctx, attempt)
+	other := guard.Check(ctx, "198.51.100.1")
+	recorded, _ := guard.GetSecurityEvents(ctx, 0)
+
+	// Assert
+	if beforeLockout != 0 {
+		t.Errorf("Un solo fallo no debería bloquear: %v", beforeLockout)
+	}
+	if wait <= 0 || wait > time.Minute {
+		t.Errorf("Se esperaba un bloqueo de hasta un minuto, se obtuvo %v", wait)
+	}
+	if guard.Check(ctx, attempt.ClientIP) <= 0 {
+		t.Error("Un acceso correcto no debería levantar un bloqueo vigente")
+	}
+	if other != 0 {
+		t.Errorf("El bloqueo no debería afectar a otros clientes: %v", other)
+	}
+	if len(recorded) != 2 || recorded[0].Type != domain.SecurityEventLockout || recorded[1].Type != domain.SecurityEventLoginFailed {
+		t.Fatalf("Eventos inesperados: %+v", recorded)
+	}
+	if recorded[0].LockedUntil == nil || recorded[0].ClientIP != attempt.ClientIP {
+		t.Errorf("El bloqueo debería registrar el cliente y su fin: %+v", recorded[0])
+	}
+}
+
+func TestLoginGuard_RequiresCaptchaAfterFailures(t *testing.T) {
+	// Arrange
+	events := repositories.NewMemorySecurityEventRepository()
+	config := domain.LoginGuardConfig{MaxFailures: 4, Window: time.Minute, Lockout: time.Minute, CaptchaAfter: 2}
+	guard, _ := services.NewLoginGuard(config, events, &stubCaptchaVerifier{valid: "ok"})
+	withoutVerifier, _ := services.NewLoginGuard(config, events, nil)
+	ctx := context.Background()
+	attempt := domain.LoginAttempt{ClientIP: "203.0.113.7", Method: domain.LoginMethodOIDC}
+
+	// Act
+	guard.RecordFailure(ctx, attempt)
+	afterOne := guard.RequiresCaptcha(ctx, attempt.ClientIP)
+	guard.RecordFailure(ctx, attempt)
+	afterTwo := guard.RequiresCaptcha(ctx, attempt.ClientIP)
+	withoutVerifier.RecordFailure(ctx, attempt)
+	withoutVerifier.RecordFailure(ctx, attempt)
+	missingErr := guard.VerifyCaptcha(ctx, attempt.ClientIP, "")
+	invalidErr := guard.VerifyCaptcha(ctx, attempt.ClientIP, "robot")
+	validErr := guard.VerifyCaptcha(ctx, attempt.ClientIP, "ok")
+
+	// Assert
+	if afterOne {
+		t.Error("No debería exigirse CAPTCHA antes de alcanzar el umbral")
+	}
+	if !afterTwo {
+		t.Error("Se esperaba exigir CAPTCHA tras dos fallos")
+	}
+	if withoutVerifier.RequiresCaptcha(ctx, attempt.ClientIP) {
+		t.Error("Sin verificador no debería exigirse CAPTCHA")
+	}
+	if !errors.Is(missingErr, services.ErrCaptchaRequired) || !errors.Is(invalidErr, services.ErrInvalidCaptcha) || validErr != nil {
+		t.Errorf("Resultados inesperados: %v, %v, %v", missingErr, invalidErr, validErr)
+	}
+	// Las dos respuestas rechazadas cuentan como fallos y completan los cuatro permitidos
+	if guard.Check(ctx, attempt.ClientIP) <= 0 {
+		t.Error("Los CAPTCHA rechazados deberían contar como intentos fallidos")
+	}
+}
+
+func TestLoginProtection_ClientIP(t *testing.T) {
+	// Arrange
+	req := httptest.NewRequest(http.MethodGet, "/api/tanks", nil)
+	req.RemoteAddr = "10.0.0.2:41234"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 203.0.113.7")
+
+	// Act
+	direct := (&auth.LoginProtection{}).ClientIP(req)
+	proxied := (&auth.LoginProtection{TrustForwardedFor: true}).ClientIP(req)
+
+	// Assert
+	if direct != "10.0.0.2" {
+		t.Errorf("Sin proxy de confianza debería usarse la dirección de la conexión: %q", direct)
+	}
+	if proxied != "203.0.113.7" {
+		t.Errorf("Detrás del proxy debería usarse la entrada que añade el proxy: %q", proxied)
+	}
+}
@@ -0,0 +1,104 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+func TestBuildMeasurementSeries_FillsGaps(t *testing.T) {
+	// Arrange: mediciones a las 00:00, 00:10 y 00:40, con un hueco entre medias
+	base := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	measurement := func(minutes int, level float64) *domain.Measurement {
+		return &domain.Measurement{TankID: "tanque-1", Level: level, Temperature: 20, Timestamp: base.Add(time.Duration(minutes) * time.Minute)}
+	}
+	measurements := []*domain.Measurement{measurement(40, 200), measurement(10, 500), measurement(0, 540), measurement(12, 480)}
+	from, to := base, base.Add(50*time.Minute)
+	tank := &domain.Tank{ID: "tanque-1"}
+
+	cases := map[string][]float64{
+		// 00:00, 00:10 (promedio de 500 y 480), 00:20, 00:30, 00:40, 00:50
+		domain.SeriesFillLinear: {540, 490, 400, 300, 200, -1},
+		domain.SeriesFillLOCF:   {540, 490, 480, 480, 200, 200},
+		domain.SeriesFillNone:   {540, 490, -1, -1, 200, -1},
+	}
+
+	for fill, expected := range cases {
+		t.Run(fill, func(t *testing.T) {
+			// Act
+			series, err := domain.BuildMeasurementSeries(tank, measurements, from, to, 10*time.Minute, fill)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("No se esperaba error: %v", err)
+			}
+			if len(series.Points) != len(expected) {
+				t.Fatalf("Se esperaban %d pasos, se obtuvieron %d", len(expected), len(series.Points))
+			}
+			for i, point := range series.Points {
+				if !point.Timestamp.Equal(base.Add(time.Duration(i) * 10 * time.Minute)) {
+					t.Errorf("Paso %d fuera de la escala uniforme: %s", i, point.Timestamp)
+				}
+				if expected[i] < 0 {
+					if point.Level != nil {
+						t.Errorf("Paso %d: no se esperaba valor, se obtuvo %.2f", i, *point.Level)
+					}
+					continue
+				}
+				if point.Level == nil || *point.Level != expected[i] {
+					t.Errorf("Paso %d: se esperaba %.2f, se obtuvo %v", i, expected[i], point.Level)
+				}
+				if point.Filled != (point.Samples == 0) {
+					t.Errorf("Paso %d: los valores estimados deben marcarse como tales: %+v", i, point)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildMeasurementSeries_DailyStepsBreakAtLocalMidnight(t *testing.T) {
+	// Arrange: en Bogotá (UTC-5) las 03:00 UTC del día 2 aún son el día 1
+	tank := &domain.Tank{ID: "tanque-1", Timezone: "America/Bogota"}
+	location := tank.TimeLocation()
+	measurements := []*domain.Measurement{
+		{TankID: "tanque-1", Level: 100, Timestamp: time.Date(2024, 5, 2, 3, 0, 0, 0, time.UTC)},
+		{TankID: "tanque-1", Level: 300, Timestamp: time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)},
+		{TankID: "tanque-1", Level: 50, Timestamp: time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)},
+	}
+	from := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	series, err := domain.BuildMeasurementSeries(tank, measurements, from, to, 24*time.Hour, domain.SeriesFillNone)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	if len(series.Points) != 2 {
+		t.Fatalf("Se esperaban 2 días, se obtuvieron %d", len(series.Points))
+	}
+	if first := series.Points[0]; !first.Timestamp.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, location)) || first.Samples != 2 || *first.Level != 200 {
+		t.Errorf("El primer día debe empezar a la medianoche local e incluir la lectura de las 03:00 UTC: %+v", first)
+	}
+	if second := series.Points[1]; second.Samples != 1 || *second.Level != 50 {
+		t.Errorf("Segundo día incorrecto: %+v", second)
+	}
+}
+
+func TestBuildMeasurementSeries_RejectsInvalidParameters(t *testing.T) {
+	tank := &domain.Tank{ID: "tanque-1"}
+	from := time.Now().Add(-time.Hour)
+	to := time.Now()
+
+	if _, err := domain.BuildMeasurementSeries(tank, nil, from, to, time.Minute, "spline"); err == nil {
+		t.Error("Se esperaba error con un método de relleno desconocido")
+	}
+	if _, err := domain.BuildMeasurementSeries(tank, nil, from, to, 0, domain.SeriesFillNone); err == nil {
+		t.Error("Se esperaba error con un paso nulo")
+	}
+	if _, err := domain.BuildMeasurementSeries(tank, nil, from, to, time.Millisecond, domain.SeriesFillNone); err == nil {
+		t.Error("Se esperaba error al superar el máximo de pasos")
+	}
+}
@@ -0,0 +1,83 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestNewMobileTank_Trend(t *testing.T) {
+	// Arrange: tanque de 1000 L con 500 L
+	tank := createTestTank()
+	cases := []struct {
+		name      string
+		reference *domain.Measurement
+		expected  string
+	}{
+		{"sin referencia", nil, domain.TrendFlat},
+		{"subida", &domain.Measurement{Level: 400}, domain.TrendUp},
+		{"bajada", &domain.Measurement{Level: 600}, domain.TrendDown},
+		{"ruido del sensor", &domain.Measurement{Level: 505}, domain.TrendFlat},
+	}
+
+	// Act & Assert
+	for _, c := range cases {
+		if row := domain.NewMobileTank(tank, c.reference); row.Trend != c.expected {
+			t.Errorf("%s: esperado %s, obtenido %s", c.name, c.expected, row.Trend)
+		}
+	}
+}
+
+func TestMobileOverviewService_GetOverview(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	service := services.NewMobileOverviewService(tankService, measurementRepo)
+	ctx := context.Background()
+
+	full := createTestTank()
+	full.Name = "Tanque Lleno"
+	low := createTestTank()
+	low.Name = "Tanque Bajo"
+	for _, tank := range []*domain.Tank{full, low} {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+	}
+
+	// El tanque bajo tenía 600 L hace 8 horas y 400 L hace 7; ahora tiene 80 L
+	now := time.Now()
+	for _, reading := range []struct {
+		level float64
+		ago   time.Duration
+	}{{600, 8 * time.Hour}, {400, 7 * time.Hour}, {80, 0}} {
+		m := createTestMeasurement(low.ID, reading.level)
+		m.Timestamp = now.Add(-reading.ago)
+		if err := tankService.AddMeasurement(ctx, m); err != nil {
+			t.Fatalf("Error al registrar la medición: %v", err)
+		}
+	}
+
+	// Act
+	overview, err := service.GetOverview(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al obtener el resumen: %v", err)
+	}
+	if overview.Critical != 1 || overview.Warning != 0 || len(overview.Tanks) != 2 {
+		t.Fatalf("Resumen incorrecto: %+v", overview)
+	}
+	first := overview.Tanks[0]
+	if first.ID != low.ID || first.Percent != 8 || first.Status != "critical" || first.Trend != domain.TrendDown {
+		t.Errorf("El tanque crítico debería ir primero y bajando: %+v", first)
+	}
+	if second := overview.Tanks[1]; second.Trend != domain.TrendFlat {
+		t.Errorf("Un tanque sin lecturas antiguas debería estar estable: %+v", second)
+	}
+}
@@ -0,0 +1,178 @@
+package services_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/mqtt"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/pkg/logger"
+)
+
+// readTestPacket lee un paquete MQTT y devuelve su cabecera y su cuerpo
+func readTestPacket(t *testing.T, reader *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	header, err := reader.ReadByte()
+	if err != nil {
+		t.Errorf("Error al leer del suscriptor: %v", err)
+		return 0, nil
+	}
+	length, multiplier := 0, 1
+	for {
+		encoded, _ := reader.ReadByte()
+		length += int(encoded&0x7F) * multiplier
+		if encoded&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		t.Errorf("Error al leer del suscriptor: %v", err)
+	}
+	return header, body
+}
+
+// publishTestMeasurement envía una medición con QoS 1 y comprueba que el suscriptor la confirme
+func publishTestMeasurement(t *testing.T, conn net.Conn, reader *bufio.Reader, topic string, packetID uint16, payload string) {
+	t.Helper()
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(topic)))
+	body = append(body, topic...)
+	body = binary.BigEndian.AppendUint16(body, packetID)
+	body = append(body, payload...)
+	conn.Write(append([]byte{0x32, byte(len(body))}, body...))
+
+	header, ack := readTestPacket(t, reader)
+	if header != 0x40 || len(ack) != 2 || binary.BigEndian.Uint16(ack) != packetID {
+		t.Errorf("Se esperaba el PUBACK del paquete %d, se recibió %#x %v", packetID, header, ack)
+	}
+}
+
+func TestMQTTSubscriber_BuffersMeasurementsAcrossOutages(t *testing.T) {
+	// Arrange: el almacenamiento no está disponible y el broker se cae tras el primer mensaje
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), measurementRepo, &MockAlertNotifier{})
+	tank := createTestTank()
+	if err := tankService.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "mqtt-buffer.jsonl")
+	buffer, err := repositories.OpenFileSyncQueueRepository(path)
+	if err != nil {
+		t.Fatalf("Error al abrir el búfer: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error al abrir el broker de prueba: %v", err)
+	}
+	defer listener.Close()
+	topic := "tanks/" + tank.ID + "/measurements"
+	brokerDone := make(chan struct{})
+	go func() {
+		defer close(brokerDone)
+
+		// Primera conexión: sesión nueva, suscripción y un mensaje antes de la caída
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		reader := bufio.NewReader(conn)
+		if header, connect := readTestPacket(t, reader); header != 0x10 || len(connect) < 8 || connect[7]&0x02 != 0 {
+			t.Errorf("Se esperaba un CONNECT con sesión persistente: %#x %v", header, connect)
+		}
+		conn.Write([]byte{0x20, 0x02, 0x00, 0x00})
+		header, subscribe := readTestPacket(t, reader)
+		if header != 0x82 || len(subscribe) < 5 || string(subscribe[4:len(subscribe)-1]) != "tanks/+/measurements" {
+			t.Errorf("Suscripción inesperada: %#x %q", header, subscribe)
+			return
+		}
+		conn.Write([]byte{0x90, 0x03, subscribe[0], subscribe[1], 0x01})
+		publishTestMeasurement(t, conn, reader, topic, 7, `{"level": 420}`)
+		conn.Close()
+
+		// Segunda conexión: el broker reanuda la sesión y entrega lo retenido sin nueva suscripción
+		conn, err = listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader = bufio.NewReader(conn)
+		readTestPacket(t, reader)
+		conn.Write([]byte{0x20, 0x02, 0x01, 0x00})
+		publishTestMeasurement(t, conn, reader, topic, 8, `{"level": 410, "timestamp": "2026-01-01T10:00:00Z"}`)
+	}()
+
+	down := &flakyTankService{TankService: tankService, down: true}
+	subscriber, err := mqtt.NewSubscriber(mqtt.Config{BrokerAddr: listener.Addr().String(), ClientID: "test-ingest"},
+		"tanks/{id}/measurements", down, buffer, logger.NewSimpleLogger())
+	if err != nil {
+		t.Fatalf("Error al crear el suscriptor: %v", err)
+	}
+
+	// Act
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		subscriber.Run(ctx)
+		close(stopped)
+	}()
+	select {
+	case <-brokerDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("El suscriptor no reconectó con el broker")
+	}
+	cancel()
+	<-stopped
+	buffer.Close()
+
+	// Assert: las dos mediciones confirmadas siguen en el búfer, sin guardar
+	reopened, err := repositories.OpenFileSyncQueueRepository(path)
+	if err != nil {
+		t.Fatalf("Error al reabrir el búfer: %v", err)
+	}
+	if count, _ := reopened.CountQueuedMeasurements(context.Background()); count != 2 {
+		t.Fatalf("Se esperaban 2 mediciones en el búfer, hay %d", count)
+	}
+	if last, _ := measurementRepo.GetLastMeasurement(context.Background(), tank.ID); last != nil {
+		t.Fatalf("No debería haberse guardado ninguna medición: %+v", last)
+	}
+
+	// Act: tras el reinicio, con el almacenamiento disponible y sin broker
+	listener.Close()
+	restarted, err := mqtt.NewSubscriber(mqtt.Config{BrokerAddr: listener.Addr().String(), ClientID: "test-ingest"},
+		"tanks/{id}/measurements", tankService, reopened, logger.NewSimpleLogger())
+	if err != nil {
+		t.Fatalf("Error al crear el suscriptor: %v", err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	stopped = make(chan struct{})
+	go func() {
+		restarted.Run(ctx)
+		close(stopped)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if count, _ := reopened.CountQueuedMeasurements(context.Background()); count == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-stopped
+
+	// Assert: el búfer se vació en el tanque
+	if count, _ := reopened.CountQueuedMeasurements(context.Background()); count != 0 {
+		t.Errorf("El búfer debería haberse vaciado, quedan %d", count)
+	}
+	stored, err := measurementRepo.GetMeasurementsByTankID(context.Background(), tank.ID, 0)
+	if err != nil || len(stored) != 2 {
+		t.Errorf("Se esperaban 2 mediciones guardadas: %+v %v", stored, err)
+	}
+}
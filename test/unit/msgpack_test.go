@@ -0,0 +1,77 @@
+package services_test
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"monitor-tanques/pkg/msgpack"
+)
+
+func TestMsgpack_RoundTrip(t *testing.T) {
+	// Arrange: enteros y cadenas en los límites de cada formato
+	value := map[string]interface{}{
+		"nil":      nil,
+		"true":     true,
+		"false":    false,
+		"fixint":   json.Number("127"),
+		"negfix":   json.Number("-32"),
+		"uint8":    json.Number("255"),
+		"uint16":   json.Number("65535"),
+		"uint32":   json.Number("4294967295"),
+		"int8":     json.Number("-128"),
+		"int16":    json.Number("-32768"),
+		"int64":    json.Number("-9223372036854775808"),
+		"float":    json.Number("12.5"),
+		"fixstr":   "nivel",
+		"str8":     string(make([]byte, 200)),
+		"str16":    string(make([]byte, 70000)),
+		"array":    []interface{}{json.Number("1"), "dos", nil},
+		"nested":   map[string]interface{}{"lat": json.Number("4.6097")},
+		"emptyArr": []interface{}{},
+	}
+
+	// Act
+	encoded, err := msgpack.Marshal(value)
+	if err != nil {
+		t.Fatalf("Error al codificar: %v", err)
+	}
+	decoded, err := msgpack.Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("Error al decodificar: %v", err)
+	}
+
+	// Assert
+	expected := map[string]interface{}{
+		"nil":      nil,
+		"true":     true,
+		"false":    false,
+		"fixint":   int64(127),
+		"negfix":   int64(-32),
+		"uint8":    int64(255),
+		"uint16":   int64(65535),
+		"uint32":   int64(4294967295),
+		"int8":     int64(-128),
+		"int16":    int64(-32768),
+		"int64":    int64(math.MinInt64),
+		"float":    12.5,
+		"fixstr":   "nivel",
+		"str8":     string(make([]byte, 200)),
+		"str16":    string(make([]byte, 70000)),
+		"array":    []interface{}{int64(1), "dos", nil},
+		"nested":   map[string]interface{}{"lat": 4.6097},
+		"emptyArr": []interface{}{},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("El valor decodificado no coincide con el original: %v", decoded)
+	}
+}
+
+func TestMsgpack_TruncatedData(t *testing.T) {
+	encoded, _ := msgpack.Marshal(map[string]interface{}{"name": "Tanque Norte"})
+
+	if _, err := msgpack.Unmarshal(encoded[:len(encoded)-3]); err == nil {
+		t.Error("Se esperaba un error al decodificar datos incompletos")
+	}
+}
@@ -0,0 +1,99 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestNoteService_AddNoteAndTimeline(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	noteService := services.NewNoteService(
+		tankService,
+		repositories.NewMemoryTankNoteRepository(),
+		measurementRepo,
+		repositories.NewMemoryStatusHistoryRepository(),
+	)
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{Subject: "u1", Name: "Ana"})
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	measurement := createTestMeasurement(tank.ID, 400)
+	measurement.Timestamp = time.Now().Add(-time.Hour)
+	if err := tankService.AddMeasurement(ctx, measurement); err != nil {
+		t.Fatalf("Error al añadir la medición: %v", err)
+	}
+
+	note := &domain.TankNote{ID: uuid.New().String(), TankID: tank.ID, Author: "otro", Text: "  se drenó el agua  "}
+
+	// Act
+	if err := noteService.AddNote(ctx, note); err != nil {
+		t.Fatalf("Error al registrar la nota: %v", err)
+	}
+
+	// Assert
+	if note.Author != "Ana" {
+		t.Errorf("El autor debe ser el usuario autenticado, se obtuvo %q", note.Author)
+	}
+	if note.Text != "se drenó el agua" {
+		t.Errorf("Texto incorrecto: %q", note.Text)
+	}
+
+	from, to := time.Now().Add(-24*time.Hour), time.Now().Add(time.Minute)
+	timeline, err := noteService.GetTimeline(ctx, tank.ID, from, to)
+	if err != nil {
+		t.Fatalf("Error al obtener la línea de tiempo: %v", err)
+	}
+
+	if len(timeline) != 2 {
+		t.Fatalf("Se esperaban 2 entradas en la línea de tiempo, se obtuvieron %d", len(timeline))
+	}
+	if timeline[0].Type != domain.TimelineEntryMeasurement || timeline[1].Type != domain.TimelineEntryNote {
+		t.Errorf("Orden de la línea de tiempo incorrecto: %s, %s", timeline[0].Type, timeline[1].Type)
+	}
+}
+
+func TestNoteService_AddNote_Invalid(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	noteService := services.NewNoteService(
+		tankService,
+		repositories.NewMemoryTankNoteRepository(),
+		measurementRepo,
+		repositories.NewMemoryStatusHistoryRepository(),
+	)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act & Assert
+	err := noteService.AddNote(ctx, &domain.TankNote{ID: uuid.New().String(), TankID: tank.ID, Text: "   "})
+	if !errors.Is(err, services.ErrInvalidNote) {
+		t.Errorf("Se esperaba ErrInvalidNote para una nota vacía, se obtuvo %v", err)
+	}
+
+	err = noteService.AddNote(ctx, &domain.TankNote{ID: uuid.New().String(), TankID: "no-existe", Text: "medidor reemplazado"})
+	if err == nil {
+		t.Error("Se esperaba un error al registrar una nota en un tanque inexistente")
+	}
+}
@@ -0,0 +1,250 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/adapters/notifiers"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// MockChannelSender registra los canales por los que se envían las alertas
+type MockChannelSender struct {
+	Sent []string
+}
+
+func (m *MockChannelSender) Send(ctx context.Context, channel *domain.NotificationChannel, alert *domain.Alert) error {
+	m.Sent = append(m.Sent, channel.ID)
+	return nil
+}
+
+// outOfHoursWindow devuelve una ventana horaria que no incluye el instante actual
+func outOfHoursWindow() domain.ScheduleWindow {
+	start := time.Now().UTC().Add(2 * time.Hour)
+	return domain.ScheduleWindow{
+		Start: start.Format("15:04"),
+		End:   start.Add(time.Hour).Format("15:04"),
+	}
+}
+
+func TestNotificationSchedule_IsActive(t *testing.T) {
+	schedule := domain.NotificationSchedule{
+		Timezone: "UTC",
+		Windows: []domain.ScheduleWindow{
+			{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "06:00", End: "22:00"},
+			{Days: []string{"sat"}, Start: "22:00", End: "06:00"},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		at       time.Time
+		expected bool
+	}{
+		{"lunes en horario", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), true},
+		{"lunes de madrugada", time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC), false},
+		{"sábado por la noche", time.Date(2024, 1, 6, 23, 0, 0, 0, time.UTC), true},
+		{"domingo de madrugada tras la franja del sábado", time.Date(2024, 1, 7, 2, 0, 0, 0, time.UTC), true},
+		{"domingo a mediodía", time.Date(2024, 1, 7, 12, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, c := range cases {
+		if active := schedule.IsActive(c.at); active != c.expected {
+			t.Errorf("%s: esperado %v, obtenido %v", c.name, c.expected, active)
+		}
+	}
+}
+
+func TestNotificationSchedule_IsActiveForUsesTankTimezone(t *testing.T) {
+	// Arrange: horario de oficina sin zona horaria propia; las 12:00 UTC son las 07:00 en Bogotá
+	schedule := domain.NotificationSchedule{
+		Windows: []domain.ScheduleWindow{{Start: "08:00", End: "18:00"}},
+	}
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Act & Assert
+	if !schedule.IsActiveFor(at, &domain.Tank{}) {
+		t.Error("Sin zona horaria en el tanque, el horario debería usar UTC")
+	}
+	if schedule.IsActiveFor(at, &domain.Tank{Timezone: "America/Bogota"}) {
+		t.Error("El horario debería seguir la hora local del tanque")
+	}
+
+	schedule.Timezone = "UTC"
+	if !schedule.IsActiveFor(at, &domain.Tank{Timezone: "America/Bogota"}) {
+		t.Error("La zona horaria del horario debe prevalecer sobre la del tanque")
+	}
+}
+
+func TestNotificationService_OutOfScheduleRoutingAndQueue(t *testing.T) {
+	// Arrange
+	channelRepo := repositories.NewMemoryNotificationChannelRepository()
+	queueRepo := repositories.NewMemoryAlertQueueRepository()
+	sender := &MockChannelSender{}
+	service := services.NewNotificationService(channelRepo, queueRepo, sender, &MockAlertNotifier{}, services.NewAccessService(repositories.NewMemoryAccessGrantRepository()))
+	ctx := context.Background()
+
+	slack := &domain.NotificationChannel{ID: "slack", Name: "Slack", Type: domain.ChannelTypeLog, Enabled: true}
+	sms := &domain.NotificationChannel{
+		ID:                "sms",
+		Name:              "SMS",
+		Type:              domain.ChannelTypeLog,
+		Enabled:           true,
+		Schedule:          domain.NotificationSchedule{Windows: []domain.ScheduleWindow{outOfHoursWindow()}},
+		OutOfSchedule:     domain.OutOfScheduleRoute,
+		FallbackChannelID: "slack",
+	}
+	email := &domain.NotificationChannel{
+		ID:       "email",
+		Name:     "Email",
+		Type:     domain.ChannelTypeLog,
+		Enabled:  true,
+		Schedule: domain.NotificationSchedule{Windows: []domain.ScheduleWindow{outOfHoursWindow()}},
+	}
+	for _, channel := range []*domain.NotificationChannel{slack, sms, email} {
+		if err := service.CreateChannel(ctx, channel); err != nil {
+			t.Fatalf("Error al crear el canal %s: %v", channel.ID, err)
+		}
+	}
+
+	// Act
+	if err := service.Notify(ctx, &domain.Alert{TankID: "tank-1", Severity: domain.AlertSeverityCritical, Message: "nivel crítico"}); err != nil {
+		t.Fatalf("Error al enviar la alerta: %v", err)
+	}
+
+	// Assert: Slack recibe la alerta una sola vez aunque SMS se desvíe hacia él
+	if len(sender.Sent) != 1 || sender.Sent[0] != "slack" {
+		t.Errorf("Se esperaba un único envío por Slack, se obtuvo: %v", sender.Sent)
+	}
+
+	queued, err := service.GetQueuedAlerts(ctx, "email", "")
+	if err != nil {
+		t.Fatalf("Error al obtener la cola: %v", err)
+	}
+	if len(queued) != 1 {
+		t.Errorf("Se esperaba 1 alerta en cola para el canal de email, se obtuvieron %d", len(queued))
+	}
+}
+
+func TestChannelSender_FormatsBySeverity(t *testing.T) {
+	// Arrange: un receptor que guarda el cuerpo de cada petición
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+	}))
+	defer server.Close()
+
+	sender := notifiers.NewChannelSender(nil, nil, "", logger.NewSimpleLogger())
+	ctx := context.Background()
+
+	tank := createTestTank()
+	alert := domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, tank, "Nivel crítico")
+	webhook := &domain.NotificationChannel{ID: "webhook", Name: "Webhook", Type: domain.ChannelTypeWebhook, Target: server.URL}
+	slack := &domain.NotificationChannel{ID: "slack", Name: "Slack", Type: domain.ChannelTypeSlack, Target: server.URL}
+
+	// Act
+	if err := sender.Send(ctx, webhook, alert); err != nil {
+		t.Fatalf("Error al enviar por webhook: %v", err)
+	}
+	if err := sender.Send(ctx, slack, alert); err != nil {
+		t.Fatalf("Error al enviar por Slack: %v", err)
+	}
+
+	// Assert: el webhook recibe la alerta completa y Slack un texto que destaca la severidad
+	if len(bodies) != 2 {
+		t.Fatalf("Se esperaban 2 envíos, se recibieron %d", len(bodies))
+	}
+	if bodies[0]["severity"] != domain.AlertSeverityCritical || bodies[0]["tank_id"] != tank.ID || bodies[0]["tank"] == nil {
+		t.Errorf("Cuerpo del webhook inesperado: %v", bodies[0])
+	}
+	if text, _ := bodies[1]["text"].(string); !strings.Contains(text, "CRÍTICO") || !strings.HasSuffix(text, "Nivel crítico") {
+		t.Errorf("Texto de Slack inesperado: %q", text)
+	}
+}
+
+func TestChannelSender_UsesChannelLanguage(t *testing.T) {
+	// Arrange
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer server.Close()
+
+	sender := notifiers.NewChannelSender(nil, nil, "", logger.NewSimpleLogger())
+	tank := createTestTank()
+	alert := domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, tank,
+		"¡Alerta! El tanque %s está en nivel crítico (nivel: %.2f%%). Se requiere atención inmediata.", tank.Name, 5.0)
+	slack := &domain.NotificationChannel{ID: "slack", Name: "Slack", Type: domain.ChannelTypeSlack, Target: server.URL, Language: "en"}
+
+	// Act
+	if err := sender.Send(context.Background(), slack, alert); err != nil {
+		t.Fatalf("Error al enviar por Slack: %v", err)
+	}
+
+	// Assert: el canal recibe el mensaje en inglés y la alerta original no cambia
+	expected := ":rotating_light: *CRITICAL* Alert! Tank " + tank.Name + " is at a critical level (level: 5.00%). Immediate attention required."
+	if body["text"] != expected {
+		t.Errorf("Texto inesperado: %q", body["text"])
+	}
+	if !strings.HasPrefix(alert.Message, "¡Alerta!") {
+		t.Errorf("La alerta original no debe traducirse: %q", alert.Message)
+	}
+}
+
+func TestNotificationService_QueuedAlertsRespectAccessGrants(t *testing.T) {
+	// Arrange: un contratista solo tiene acceso a los tanques de la estación sur
+	ctx := context.Background()
+	channelRepo := repositories.NewMemoryNotificationChannelRepository()
+	queueRepo := repositories.NewMemoryAlertQueueRepository()
+	accessService := services.NewAccessService(repositories.NewMemoryAccessGrantRepository())
+	accessService.CreateGrant(ctx, &domain.AccessGrant{
+		ID:        uuid.New().String(),
+		Subject:   "contratista",
+		ScopeType: domain.AccessScopeSite,
+		ScopeID:   "estacion-sur",
+	})
+	service := services.NewNotificationService(channelRepo, queueRepo, &MockChannelSender{}, &MockAlertNotifier{}, accessService)
+	channelRepo.SaveChannel(ctx, &domain.NotificationChannel{ID: "sms", Name: "SMS", Type: domain.ChannelTypeLog, Enabled: true})
+
+	south := createTestTank()
+	south.ID, south.SiteID = "tank-sur", "estacion-sur"
+	north := createTestTank()
+	north.ID, north.SiteID = "tank-norte", "estacion-norte"
+	for _, tank := range []*domain.Tank{south, north} {
+		alert := domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, tank, "Nivel crítico")
+		queueRepo.EnqueueAlert(ctx, &domain.QueuedAlert{
+			ID: uuid.New().String(), ChannelID: "sms", TankID: tank.ID, Message: alert.Message, QueuedAt: time.Now(), Alert: alert,
+		})
+	}
+
+	// Act
+	contractorCtx := domain.ContextWithPrincipal(ctx, &domain.Principal{Subject: "contratista", Roles: []string{domain.RoleOperator}})
+	visible, err := service.GetQueuedAlerts(contractorCtx, "sms", "")
+	if err != nil {
+		t.Fatalf("Error al consultar la cola: %v", err)
+	}
+	all, err := service.GetQueuedAlerts(ctx, "sms", "")
+	if err != nil {
+		t.Fatalf("Error al consultar la cola: %v", err)
+	}
+
+	// Assert: el contratista solo ve las alertas de su sitio; sin principal se ven todas
+	if len(visible) != 1 || visible[0].TankID != south.ID {
+		t.Errorf("El contratista solo debería ver la alerta de %s: %+v", south.ID, visible)
+	}
+	if len(all) != 2 {
+		t.Errorf("Se esperaban 2 alertas sin autenticación, hay %d", len(all))
+	}
+}
@@ -0,0 +1,138 @@
+package services_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/auth"
+	"monitor-tanques/internal/core/domain"
+)
+
+// fakeIdentityProvider simula un proveedor OIDC con descubrimiento y JWKS
+type fakeIdentityProvider struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	idToken string // id_token que devuelve el endpoint de tokens
+}
+
+func newFakeIdentityProvider(t *testing.T) *fakeIdentityProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error al generar la clave: %v", err)
+	}
+
+	idp := &fakeIdentityProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":         idp.server.URL,
+			"jwks_uri":       idp.server.URL + "/jwks",
+			"token_endpoint": idp.server.URL + "/token",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "codigo-valido" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "token-del-proveedor",
+			"id_token":     idp.idToken,
+			"token_type":   "Bearer",
+			"expires_in":   300,
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+// sign emite un token RS256 con los claims indicados
+func (idp *fakeIdentityProvider) sign(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test-key", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Error al firmar el token: %v", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestOIDCProvider_Authenticate(t *testing.T) {
+	// Arrange
+	idp := newFakeIdentityProvider(t)
+	provider := auth.NewOIDCProvider(auth.OIDCConfig{
+		IssuerURL:   idp.server.URL,
+		ClientID:    "monitor-tanques",
+		RoleMapping: map[string]string{"tank-operators": domain.RoleOperator},
+	})
+	ctx := context.Background()
+
+	claims := map[string]interface{}{
+		"iss":    idp.server.URL,
+		"aud":    "monitor-tanques",
+		"sub":    "user-1",
+		"email":  "operador@example.com",
+		"groups": []string{"/tank-operators", "otros"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+
+	// Act
+	principal, err := provider.Authenticate(ctx, idp.sign(t, claims))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al autenticar un token válido: %v", err)
+	}
+
+	if principal.Subject != "user-1" {
+		t.Errorf("Subject incorrecto. Esperado: %s, Obtenido: %s", "user-1", principal.Subject)
+	}
+
+	if !principal.HasRole(domain.RoleViewer) || principal.HasRole(domain.RoleAdmin) {
+		t.Errorf("Roles mapeados incorrectos: %v", principal.Roles)
+	}
+
+	// Un token caducado se rechaza
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	if _, err := provider.Authenticate(ctx, idp.sign(t, claims)); err != auth.ErrTokenExpired {
+		t.Errorf("Se esperaba ErrTokenExpired, se obtuvo: %v", err)
+	}
+
+	// Un token para otro cliente se rechaza
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	claims["aud"] = "otra-aplicacion"
+	if _, err := provider.Authenticate(ctx, idp.sign(t, claims)); err == nil {
+		t.Errorf("Se esperaba un error para una audiencia distinta")
+	}
+}
@@ -0,0 +1,135 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/projections"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+)
+
+// failingAlertNotifier rechaza las primeras failures entregas
+type failingAlertNotifier struct {
+	MockAlertNotifier
+	failures int
+}
+
+func (n *failingAlertNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
+	if n.failures > 0 {
+		n.failures--
+		return errors.New("webhook unavailable")
+	}
+	return n.MockAlertNotifier.Notify(ctx, alert)
+}
+
+// newTestOutboxTankService crea un servicio de tanques con bandeja de salida en memoria
+func newTestOutboxTankService(notifier ports.AlertNotifier) (ports.TankService, *repositories.MemoryOutboxRepository) {
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	outboxRepo := repositories.NewMemoryOutboxRepository()
+	unitOfWork := repositories.NewMemoryUnitOfWork(tankRepo, measurementRepo, repositories.NewMemoryStatusHistoryRepository(), outboxRepo)
+	service := services.NewTankServiceWithOutbox(tankRepo, measurementRepo, notifier, unitOfWork, projections.NewMemoryTankStateStore(0))
+	return service, outboxRepo
+}
+
+func TestOutbox_StoresAlertWithMeasurementAndRelaysIt(t *testing.T) {
+	// Arrange
+	notifier := &MockAlertNotifier{}
+	tankService, outboxRepo := newTestOutboxTankService(notifier)
+	outboxService := services.NewOutboxService(outboxRepo, notifier, domain.OutboxConfig{BatchSize: 10, MaxAttempts: 3, RetryBackoff: time.Minute})
+
+	tank := createTestTank()
+	tankService.CreateTank(context.Background(), tank)
+
+	// Act
+	err := tankService.AddMeasurement(context.Background(), createTestMeasurement(tank.ID, 50))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	if notifier.AlertsSent != 0 {
+		t.Errorf("Con bandeja de salida la alerta no debería enviarse al guardar la medición")
+	}
+	pending, _ := outboxRepo.GetDueEvents(context.Background(), time.Now(), 0)
+	if len(pending) != 1 || pending[0].TankID != tank.ID || pending[0].Alert.Type != domain.AlertEventLevelCritical {
+		t.Fatalf("Se esperaba un evento de nivel crítico en la bandeja de salida: %d", len(pending))
+	}
+
+	if err := outboxService.RelayEvents(context.Background()); err != nil {
+		t.Fatalf("No se esperaba error al entregar: %v", err)
+	}
+	if notifier.AlertsSent != 1 || notifier.LastTankID != tank.ID {
+		t.Errorf("Se esperaba una alerta entregada, se enviaron %d", notifier.AlertsSent)
+	}
+
+	outboxService.RelayEvents(context.Background())
+	if notifier.AlertsSent != 1 {
+		t.Errorf("Un evento entregado no debería repetirse, se enviaron %d", notifier.AlertsSent)
+	}
+}
+
+func TestOutbox_DoesNotStoreAlertWhenTransactionFails(t *testing.T) {
+	// Arrange
+	notifier := &MockAlertNotifier{}
+	tankService, outboxRepo := newTestOutboxTankService(notifier)
+
+	tank := createTestTank()
+	tankService.CreateTank(context.Background(), tank)
+	measurements := []*domain.Measurement{
+		createTestMeasurement(tank.ID, 50),
+		createTestMeasurement("no-existe", 50),
+	}
+
+	// Act
+	err := tankService.AddMeasurements(context.Background(), measurements)
+
+	// Assert
+	if err == nil {
+		t.Fatal("Se esperaba un error por el tanque inexistente")
+	}
+	pending, _ := outboxRepo.GetDueEvents(context.Background(), time.Now(), 0)
+	if len(pending) != 0 {
+		t.Errorf("Una transacción fallida no debería dejar eventos, hay %d", len(pending))
+	}
+}
+
+func TestOutbox_RetriesWithBackoffUntilMaxAttempts(t *testing.T) {
+	// Arrange
+	notifier := &failingAlertNotifier{failures: 5}
+	tankService, outboxRepo := newTestOutboxTankService(notifier)
+	outboxService := services.NewOutboxService(outboxRepo, notifier, domain.OutboxConfig{BatchSize: 10, MaxAttempts: 2, RetryBackoff: time.Minute})
+
+	tank := createTestTank()
+	tankService.CreateTank(context.Background(), tank)
+	tankService.AddMeasurement(context.Background(), createTestMeasurement(tank.ID, 50))
+	events, _ := outboxRepo.GetDueEvents(context.Background(), time.Now(), 0)
+
+	// Act
+	firstErr := outboxService.RelayEvents(context.Background())
+	retried, _ := outboxRepo.GetDueEvents(context.Background(), time.Now(), 0)
+	later, _ := outboxRepo.GetDueEvents(context.Background(), time.Now().Add(2*time.Minute), 0)
+
+	// Assert
+	if firstErr == nil {
+		t.Error("Se esperaba el error de entrega")
+	}
+	if len(retried) != 0 || len(later) != 1 || later[0].Attempts != 1 || later[0].LastError != "webhook unavailable" {
+		t.Fatalf("El evento debería reintentarse tras la espera: %d, %d", len(retried), len(later))
+	}
+
+	// El segundo fallo agota los intentos y el evento queda como fallido
+	event := later[0]
+	event.MarkFailed(errors.New("webhook unavailable"), time.Now(), domain.OutboxConfig{MaxAttempts: 2, RetryBackoff: time.Minute})
+	if event.Status != domain.OutboxEventFailed || event.Due(time.Now().Add(24*time.Hour)) {
+		t.Errorf("Se esperaba un evento fallido sin más intentos: %s", event.Status)
+	}
+	if events[0].ID != event.ID {
+		t.Errorf("El evento reintentado debería ser el mismo")
+	}
+}
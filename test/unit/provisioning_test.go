@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"monitor-tanques/internal/adapters/provisioning"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+)
+
+const testProvisioningYAML = `
+sites:
+  - id: estacion-norte
+    timezone: America/Bogota
+    tanks:
+      - id: tanque-1
+        name: Diésel principal
+        capacity: 20000
+        liquid_type: diesel
+        sensors:
+          - id: radar-1
+            reporting_interval: 300
+            low_level_threshold: 15
+alert_rules:
+  - id: guardia
+    name: Guardia
+    type: log
+  - id: operaciones
+    name: Operaciones
+    type: log
+    schedule:
+      windows: [{ days: [mon, tue, wed, thu, fri], start: "08:00", end: "18:00" }]
+    out_of_schedule: route
+    fallback_channel_id: guardia
+`
+
+// newTestProvisioningService crea el servicio de aprovisionamiento sobre repositorios en memoria
+func newTestProvisioningService() (ports.ProvisioningService, ports.TankService, ports.FieldDeviceService) {
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	fieldDeviceService := services.NewFieldDeviceService(tankService, repositories.NewMemoryFieldDeviceRepository())
+	notificationService := services.NewNotificationService(
+		repositories.NewMemoryNotificationChannelRepository(),
+		repositories.NewMemoryAlertQueueRepository(),
+		&MockChannelSender{},
+		&MockAlertNotifier{},
+		services.NewAccessService(repositories.NewMemoryAccessGrantRepository()),
+	)
+	return services.NewProvisioningService(tankService, fieldDeviceService, notificationService), tankService, fieldDeviceService
+}
+
+func TestProvisioning_ParseYAML(t *testing.T) {
+	// Act
+	spec, err := provisioning.Parse(strings.NewReader(testProvisioningYAML))
+	_, unknownErr := provisioning.Parse(strings.NewReader("sites:\n  - id: a\n    tankz: []\n"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al interpretar el archivo: %v", err)
+	}
+	if len(spec.Tanks) != 1 || spec.Tanks[0].SiteID != "estacion-norte" || spec.Tanks[0].AlertThreshold != domain.DefaultAlertThreshold || spec.Tanks[0].Timezone != "America/Bogota" {
+		t.Errorf("Tanque mal interpretado: %+v", spec.Tanks)
+	}
+	if len(spec.Sensors) != 1 || spec.Sensors[0].TankID != "tanque-1" || spec.Sensors[0].Desired.ReportingInterval != 300 {
+		t.Errorf("Sensor mal interpretado: %+v", spec.Sensors)
+	}
+	if len(spec.AlertRules) != 2 || !spec.AlertRules[0].Enabled || spec.AlertRules[0].OutOfSchedule != domain.OutOfScheduleQueue {
+		t.Errorf("Las reglas deben quedar habilitadas y encolar fuera de horario por defecto: %+v", spec.AlertRules)
+	}
+	if !errors.Is(unknownErr, provisioning.ErrInvalidSpec) {
+		t.Errorf("Un campo desconocido debe rechazarse, se obtuvo %v", unknownErr)
+	}
+}
+
+func TestProvisioningService_ReconcileIsIdempotent(t *testing.T) {
+	// Arrange
+	service, tankService, fieldDeviceService := newTestProvisioningService()
+	ctx := context.Background()
+
+	spec, err := provisioning.Parse(strings.NewReader(testProvisioningYAML))
+	if err != nil {
+		t.Fatalf("Error al interpretar el archivo: %v", err)
+	}
+
+	// Act: se aplica dos veces el mismo archivo y luego una versión modificada
+	first, err := service.Provision(ctx, spec)
+	if err != nil {
+		t.Fatalf("Error en el primer aprovisionamiento: %v", err)
+	}
+
+	second, err := service.Provision(ctx, spec)
+	if err != nil {
+		t.Fatalf("Error en el segundo aprovisionamiento: %v", err)
+	}
+
+	spec.Tanks[0].Capacity = 25000
+	third, err := service.Provision(ctx, spec)
+	if err != nil {
+		t.Fatalf("Error en el tercer aprovisionamiento: %v", err)
+	}
+
+	tank, _ := tankService.GetTank(ctx, "tanque-1")
+	device, _ := fieldDeviceService.GetFieldDevice(ctx, "radar-1")
+
+	// Assert
+	if first.Created != 4 || first.Updated != 0 || first.Unchanged != 0 {
+		t.Errorf("El primer aprovisionamiento debe crear los 4 recursos, se obtuvo %+v", first)
+	}
+	if second.Created != 0 || second.Updated != 0 || second.Unchanged != 4 {
+		t.Errorf("Aplicar de nuevo el mismo archivo no debe producir cambios, se obtuvo %+v", second)
+	}
+	if third.Updated != 1 || third.Changes[0].Action != domain.ProvisioningActionUpdate || strings.Join(third.Changes[0].Fields, ",") != "capacity" {
+		t.Errorf("Solo debe actualizarse la capacidad del tanque, se obtuvo %+v", third.Changes[0])
+	}
+	if tank == nil || tank.Capacity != 25000 {
+		t.Errorf("El tanque debe tener la nueva capacidad, se obtuvo %+v", tank)
+	}
+	if device == nil || device.Desired.Version != 1 {
+		t.Errorf("La configuración del sensor no debe cambiar de versión si no cambia, se obtuvo %+v", device)
+	}
+}
+
+func TestProvisioningService_SensorWithUndeclaredTank(t *testing.T) {
+	// Arrange
+	service, _, _ := newTestProvisioningService()
+	spec := &domain.ProvisioningSpec{
+		Sensors: []*domain.FieldDevice{{ID: "radar-1", TankID: "inexistente"}},
+	}
+
+	// Act
+	_, err := service.Provision(context.Background(), spec)
+
+	// Assert
+	if !errors.Is(err, services.ErrInvalidProvisioningSpec) {
+		t.Errorf("Se esperaba ErrInvalidProvisioningSpec, se obtuvo %v", err)
+	}
+}
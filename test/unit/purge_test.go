@@ -0,0 +1,176 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/projections"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/adapters/storage"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+)
+
+// purgeFixture agrupa los repositorios sobre los que trabaja el servicio de purgas
+type purgeFixture struct {
+	tanks        *repositories.MemoryTankRepository
+	measurements *repositories.MemoryMeasurementRepository
+	notes        *repositories.MemoryTankNoteRepository
+	attachments  *repositories.MemoryAttachmentRepository
+	storage      *storage.LocalStorage
+	service      *services.PurgeServiceImpl
+}
+
+func newPurgeFixture(t *testing.T) *purgeFixture {
+	f := &purgeFixture{
+		tanks:        repositories.NewMemoryTankRepository(),
+		measurements: repositories.NewMemoryMeasurementRepository(),
+		notes:        repositories.NewMemoryTankNoteRepository(),
+		attachments:  repositories.NewMemoryAttachmentRepository(),
+		storage:      storage.NewLocalStorage(t.TempDir()),
+	}
+	f.service = services.NewPurgeService(
+		f.tanks,
+		f.measurements,
+		map[string]ports.TankDataPurger{"measurements": f.measurements, "notes": f.notes},
+		f.attachments,
+		f.storage,
+		projections.NewMemoryTankStateStore(0),
+		nil,
+		"organization",
+	)
+	return f
+}
+
+// waitPurge espera a que termine el trabajo y devuelve su estado final
+func waitPurge(t *testing.T, service *services.PurgeServiceImpl, id string) *domain.PurgeJob {
+	t.Helper()
+	if err := service.Wait(context.Background()); err != nil {
+		t.Fatalf("No se esperaba error al esperar la purga: %v", err)
+	}
+	job, err := service.GetPurgeJob(context.Background(), id)
+	if err != nil {
+		t.Fatalf("No se esperaba error al consultar la purga: %v", err)
+	}
+	return job
+}
+
+func TestPurgeService_DeletesMeasurementsBeforeDate(t *testing.T) {
+	// Arrange
+	f := newPurgeFixture(t)
+	tank := createTestTank()
+	f.tanks.SaveTank(context.Background(), tank)
+
+	now := time.Now()
+	for _, age := range []time.Duration{72 * time.Hour, 48 * time.Hour, time.Hour} {
+		measurement := createTestMeasurement(tank.ID, 500)
+		measurement.Timestamp = now.Add(-age)
+		f.measurements.SaveMeasurement(context.Background(), measurement)
+	}
+	before := now.Add(-24 * time.Hour)
+
+	// Act
+	job, err := f.service.StartPurge(context.Background(), domain.PurgeRequest{
+		Scope:  domain.PurgeScopeMeasurements,
+		TankID: tank.ID,
+		Before: &before,
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	job = waitPurge(t, f.service, job.ID)
+	if job.Status != domain.PurgeJobCompleted || job.Progress != 100 || job.TanksDone != 1 || job.Deleted["measurements"] != 2 {
+		t.Fatalf("Trabajo inesperado: %+v", job)
+	}
+
+	remaining, _ := f.measurements.GetMeasurementsByTankID(context.Background(), tank.ID, 0)
+	if len(remaining) != 1 || remaining[0].Timestamp.Before(before) {
+		t.Errorf("Solo debería quedar la medición posterior a la fecha: %d", len(remaining))
+	}
+	if stored, _ := f.tanks.GetTank(context.Background(), tank.ID); stored == nil {
+		t.Error("Una purga de mediciones no debería eliminar el tanque")
+	}
+}
+
+func TestPurgeService_DeletesOrganizationData(t *testing.T) {
+	// Arrange
+	f := newPurgeFixture(t)
+	ctx := context.Background()
+
+	purged := createTestTank()
+	purged.Labels = domain.Labels{"organization": "acme"}
+	kept := createTestTank()
+	kept.Labels = domain.Labels{"organization": "otra"}
+	for _, tank := range []*domain.Tank{purged, kept} {
+		f.tanks.SaveTank(ctx, tank)
+		f.measurements.SaveMeasurement(ctx, createTestMeasurement(tank.ID, 500))
+		f.notes.SaveNote(ctx, &domain.TankNote{ID: tank.ID + "-nota", TankID: tank.ID, Text: "Revisión", CreatedAt: time.Now()})
+	}
+
+	attachment := &domain.Attachment{ID: "foto", TankID: purged.ID, StorageKey: purged.ID + "/foto.jpg"}
+	f.storage.Put(ctx, attachment.StorageKey, strings.NewReader("jpg"), 3, "image/jpeg")
+	f.attachments.SaveAttachment(ctx, attachment)
+
+	// Act
+	job, err := f.service.StartPurge(ctx, domain.PurgeRequest{Scope: domain.PurgeScopeOrganization, Organization: "acme"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	job = waitPurge(t, f.service, job.ID)
+	if job.Status != domain.PurgeJobCompleted || job.TanksTotal != 1 {
+		t.Fatalf("Trabajo inesperado: %+v", job)
+	}
+	for kind, expected := range map[string]int{"tanks": 1, "measurements": 1, "notes": 1, "attachments": 1} {
+		if job.Deleted[kind] != expected {
+			t.Errorf("Se esperaban %d registros de %s eliminados, se obtuvieron %d", expected, kind, job.Deleted[kind])
+		}
+	}
+
+	if tank, _ := f.tanks.GetTank(ctx, purged.ID); tank != nil {
+		t.Error("El tanque de la organización debería eliminarse")
+	}
+	if _, err := f.storage.Get(ctx, attachment.StorageKey); err == nil {
+		t.Error("El archivo adjunto debería eliminarse del almacenamiento")
+	}
+	if tank, _ := f.tanks.GetTank(ctx, kept.ID); tank == nil {
+		t.Error("Los tanques de otras organizaciones no deberían tocarse")
+	}
+	if notes, _ := f.notes.GetNotes(ctx, kept.ID); len(notes) != 1 {
+		t.Errorf("Las notas de otras organizaciones no deberían tocarse: %d", len(notes))
+	}
+}
+
+func TestPurgeService_RejectsInvalidRequests(t *testing.T) {
+	f := newPurgeFixture(t)
+	before := time.Now()
+
+	cases := map[string]struct {
+		request domain.PurgeRequest
+		err     error
+	}{
+		"alcance desconocido":    {domain.PurgeRequest{Scope: "todo"}, services.ErrInvalidPurge},
+		"mediciones sin fecha":   {domain.PurgeRequest{Scope: domain.PurgeScopeMeasurements, TankID: "t1"}, services.ErrInvalidPurge},
+		"tanque desconocido":     {domain.PurgeRequest{Scope: domain.PurgeScopeMeasurements, TankID: "no-existe", Before: &before}, services.ErrTankNotFound},
+		"organización vacía":     {domain.PurgeRequest{Scope: domain.PurgeScopeOrganization}, services.ErrInvalidPurge},
+		"tanques sin asignación": {domain.PurgeRequest{Scope: domain.PurgeScopeOrganization, Organization: domain.UnassignedOrganization}, services.ErrInvalidPurge},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := f.service.StartPurge(context.Background(), tc.request); !errors.Is(err, tc.err) {
+				t.Errorf("Se esperaba %v, se obtuvo: %v", tc.err, err)
+			}
+		})
+	}
+
+	if _, err := f.service.GetPurgeJob(context.Background(), "no-existe"); !errors.Is(err, services.ErrPurgeJobNotFound) {
+		t.Errorf("Se esperaba ErrPurgeJobNotFound, se obtuvo: %v", err)
+	}
+}
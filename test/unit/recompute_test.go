@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/projections"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+)
+
+// newTestRecomputeService crea un servicio de tanques y el de reconstrucción sobre los mismos repositorios
+func newTestRecomputeService(statusRepo *repositories.MemoryStatusHistoryRepository) (ports.TankService, ports.RecomputeService) {
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	unitOfWork := repositories.NewMemoryUnitOfWork(tankRepo, measurementRepo, statusRepo, repositories.NewMemoryOutboxRepository())
+	states := projections.NewMemoryTankStateStore(0)
+
+	tankService := services.NewTankService(tankRepo, measurementRepo, &MockAlertNotifier{}, unitOfWork, states)
+	recomputeService := services.NewRecomputeService(tankService, tankRepo, measurementRepo, statusRepo, statusRepo, states, nil)
+	return tankService, recomputeService
+}
+
+func TestRecomputeService_RebuildsStatusHistoryAfterBackfill(t *testing.T) {
+	// Arrange
+	statusRepo := repositories.NewMemoryStatusHistoryRepository()
+	tankService, recomputeService := newTestRecomputeService(statusRepo)
+
+	tank := createTestTank()
+	tankService.CreateTank(context.Background(), tank)
+	tankService.AddMeasurement(context.Background(), createTestMeasurement(tank.ID, 500))
+
+	now := time.Now()
+	measurements := make([]*domain.Measurement, 0, 3)
+	for i, level := range []float64{50, 150, 800} {
+		measurement := createTestMeasurement(tank.ID, level)
+		measurement.Timestamp = now.Add(-time.Duration(72-i*24) * time.Hour)
+		measurements = append(measurements, measurement)
+	}
+	tankService.BackfillMeasurements(context.Background(), tank.ID, measurements)
+
+	// Act
+	results, err := recomputeService.Recompute(context.Background(), tank.ID, time.Time{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	if len(results) != 1 || results[0].Measurements != 4 || results[0].StatusChanges != 3 {
+		t.Fatalf("Resultado incorrecto: %+v", results[0])
+	}
+	if !results[0].From.Equal(measurements[0].Timestamp) || results[0].Level != 500 || results[0].Status != "normal" {
+		t.Errorf("Sin from debería reconstruirse desde la primera medición: %+v", results[0])
+	}
+
+	changes, _ := statusRepo.GetStatusChanges(context.Background(), tank.ID)
+	expected := []string{"critical", "warning", "normal"}
+	if len(changes) != len(expected) {
+		t.Fatalf("Se esperaban %d transiciones, se obtuvieron %d", len(expected), len(changes))
+	}
+	for i, change := range changes {
+		if change.ToStatus != expected[i] || !change.ChangedAt.Equal(measurements[i].Timestamp) {
+			t.Errorf("Transición %d incorrecta: %s en %s", i, change.ToStatus, change.ChangedAt)
+		}
+	}
+
+	// Reconstruir desde un punto intermedio conserva las transiciones anteriores
+	results, err = recomputeService.Recompute(context.Background(), tank.ID, measurements[1].Timestamp)
+	if err != nil || results[0].Measurements != 3 || results[0].StatusChanges != 2 {
+		t.Errorf("Resultado incorrecto desde un punto intermedio: %+v, %v", results[0], err)
+	}
+	changes, _ = statusRepo.GetStatusChanges(context.Background(), tank.ID)
+	if len(changes) != 3 || changes[1].FromStatus != "critical" {
+		t.Errorf("La reconstrucción parcial debería partir del estado vigente: %d transiciones", len(changes))
+	}
+}
+
+func TestRecomputeService_RejectsUnknownTankAndFutureFrom(t *testing.T) {
+	// Arrange
+	_, recomputeService := newTestRecomputeService(repositories.NewMemoryStatusHistoryRepository())
+
+	// Act
+	_, unknownErr := recomputeService.Recompute(context.Background(), "no-existe", time.Time{})
+	_, futureErr := recomputeService.Recompute(context.Background(), "", time.Now().Add(time.Hour))
+
+	// Assert
+	if !errors.Is(unknownErr, services.ErrTankNotFound) {
+		t.Errorf("Se esperaba ErrTankNotFound, se obtuvo: %v", unknownErr)
+	}
+	if !errors.Is(futureErr, services.ErrInvalidPeriod) {
+		t.Errorf("Se esperaba ErrInvalidPeriod, se obtuvo: %v", futureErr)
+	}
+}
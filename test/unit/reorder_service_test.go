@@ -0,0 +1,210 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestReorderService_GetReorderSuggestions(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	alertNotifier := &MockAlertNotifier{}
+
+	tankService := newTestTankService(tankRepo, measurementRepo, alertNotifier)
+	reorderService := services.NewReorderService(tankService, measurementRepo)
+	ctx := context.Background()
+
+	// Tanque con punto de pedido que consume 100 litros por día
+	tank := createTestTank()
+	tank.Reorder.ReorderLevel = 200.0
+	tank.Reorder.LeadTimeDays = 2.0
+	tank.Reorder.DeliverySize = 500.0
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	first := createTestMeasurement(tank.ID, 600.0)
+	first.Timestamp = time.Now().Add(-72 * time.Hour)
+	last := createTestMeasurement(tank.ID, 300.0)
+	for _, m := range []*domain.Measurement{first, last} {
+		if err := tankService.AddMeasurement(ctx, m); err != nil {
+			t.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+
+	// Tanque sin configuración de reabastecimiento, que debe ignorarse
+	other := createTestTank()
+	if err := tankService.CreateTank(ctx, other); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act
+	suggestions, err := reorderService.GetReorderSuggestions(ctx, 7)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al obtener las sugerencias: %v", err)
+	}
+
+	if len(suggestions) != 1 {
+		t.Fatalf("Se esperaba 1 sugerencia, pero se obtuvieron %d", len(suggestions))
+	}
+
+	suggestion := suggestions[0]
+	if suggestion.TankID != tank.ID {
+		t.Errorf("ID incorrecto en la sugerencia. Esperado: %s, Obtenido: %s", tank.ID, suggestion.TankID)
+	}
+
+	if suggestion.DailyConsumption < 99.0 || suggestion.DailyConsumption > 101.0 {
+		t.Errorf("Consumo diario incorrecto. Esperado: ~100, Obtenido: %.2f", suggestion.DailyConsumption)
+	}
+
+	if !suggestion.Urgent {
+		t.Errorf("La sugerencia debería ser urgente: el plazo de entrega supera los días restantes")
+	}
+
+	if suggestion.SuggestedQuantity != 500.0 {
+		t.Errorf("Cantidad sugerida incorrecta. Esperado: %.2f, Obtenido: %.2f", 500.0, suggestion.SuggestedQuantity)
+	}
+}
+
+func TestReorderService_GetGroupCapacityPlan(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	reorderService := services.NewReorderService(tankService, measurementRepo)
+	ctx := context.Background()
+
+	// Tanque de diésel del grupo que consume 100 litros por día y queda en 300 litros
+	diesel := createTestTank()
+	diesel.GroupID = "caribe"
+	diesel.LiquidType = "diesel"
+	diesel.Reorder.ReorderLevel = 200.0
+	// Tanque de gasolina del grupo sin historial: no necesita entregas
+	gasoline := createTestTank()
+	gasoline.GroupID = "caribe"
+	gasoline.LiquidType = "gasolina"
+	// Tanque de otro grupo, que no forma parte del plan
+	other := createTestTank()
+	other.GroupID = "andina"
+	for _, tank := range []*domain.Tank{diesel, gasoline, other} {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+	}
+
+	first := createTestMeasurement(diesel.ID, 600.0)
+	first.Timestamp = time.Now().Add(-72 * time.Hour)
+	last := createTestMeasurement(diesel.ID, 300.0)
+	for _, m := range []*domain.Measurement{first, last} {
+		if err := tankService.AddMeasurement(ctx, m); err != nil {
+			t.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+
+	// Act
+	plan, err := reorderService.GetGroupCapacityPlan(ctx, "caribe", 7)
+	_, notFoundErr := reorderService.GetGroupCapacityPlan(ctx, "patagonia", 7)
+	_, invalidErr := reorderService.GetGroupCapacityPlan(ctx, "caribe", 365)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al obtener el plan de capacidad: %v", err)
+	}
+	if plan.Tanks != 2 || plan.TanksNeedingDelivery != 1 || len(plan.Products) != 2 {
+		t.Fatalf("Plan inesperado: %+v", plan)
+	}
+
+	// 200 litros de punto de pedido + 700 de consumo - 300 disponibles
+	forecast := plan.Forecasts[0]
+	if forecast.TankID != diesel.ID || forecast.RequiredDelivery < 599 || forecast.RequiredDelivery > 601 {
+		t.Errorf("Se esperaban ~600 litros para el diésel, se obtuvo %+v", forecast)
+	}
+	if forecast.RunsOutAt == nil || forecast.ProjectedLevel != 0 {
+		t.Errorf("El diésel debería vaciarse dentro del horizonte: %+v", forecast)
+	}
+	if plan.RequiredDelivery != forecast.RequiredDelivery || plan.Products[0].LiquidType != "diesel" {
+		t.Errorf("Totales incorrectos: %.2f litros, productos %+v", plan.RequiredDelivery, plan.Products[0])
+	}
+	if plan.Forecasts[1].ForecastAvailable || plan.Forecasts[1].RequiredDelivery != 0 {
+		t.Errorf("La gasolina no tiene historial ni necesita entregas: %+v", plan.Forecasts[1])
+	}
+
+	if !errors.Is(notFoundErr, services.ErrGroupNotFound) {
+		t.Errorf("Se esperaba ErrGroupNotFound, se obtuvo %v", notFoundErr)
+	}
+	if !errors.Is(invalidErr, services.ErrInvalidCapacityPlan) {
+		t.Errorf("Se esperaba ErrInvalidCapacityPlan, se obtuvo %v", invalidErr)
+	}
+}
+
+func TestBuildThresholdRecommendation(t *testing.T) {
+	// Arrange: 1000 L consumidos en 3 días, con un día de 400 L
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tank := &domain.Tank{ID: "t1", Capacity: 10000, AlertThreshold: 20}
+	tank.Reorder.LeadTimeDays = 3
+	measurements := []*domain.Measurement{
+		{TankID: "t1", Level: 8400, Timestamp: start.Add(36 * time.Hour)},
+		{TankID: "t1", Level: 9000, Timestamp: start},
+		{TankID: "t1", Level: 8800, Timestamp: start.Add(12 * time.Hour)},
+		{TankID: "t1", Level: 8200, Timestamp: start.Add(60 * time.Hour)},
+		{TankID: "t1", Level: 8000, Timestamp: start.Add(72 * time.Hour)},
+	}
+
+	// Act
+	recommendation, ok := domain.BuildThresholdRecommendation(tank, measurements, 48*time.Hour)
+
+	// Assert: el día punta (percentil 95) consume 370 L, así que 48 horas son 740 L (7,4%)
+	if !ok {
+		t.Fatal("Se esperaba una recomendación")
+	}
+	if recommendation.PeakDailyConsumption < 369.99 || recommendation.PeakDailyConsumption > 370.01 {
+		t.Errorf("Consumo punta incorrecto. Esperado: 370, Obtenido: %.2f", recommendation.PeakDailyConsumption)
+	}
+	if recommendation.RecommendedAlertThreshold != 8 {
+		t.Errorf("Umbral recomendado incorrecto. Esperado: 8, Obtenido: %.2f", recommendation.RecommendedAlertThreshold)
+	}
+	if recommendation.RecommendedReorderLevel != 1800 {
+		t.Errorf("Punto de pedido incorrecto. Esperado: 1800, Obtenido: %.2f", recommendation.RecommendedReorderLevel)
+	}
+	if recommendation.HistoryDays != 3 || recommendation.CurrentAlertThreshold != 20 {
+		t.Errorf("Datos de la recomendación incorrectos: %+v", recommendation)
+	}
+
+	// Sin plazo del proveedor se usa la antelación de la alerta
+	tank.Reorder.LeadTimeDays = 0
+	recommendation, _ = domain.BuildThresholdRecommendation(tank, measurements, 48*time.Hour)
+	if recommendation.SupplierLeadTimeDays != 2 || recommendation.RecommendedReorderLevel != 1467 {
+		t.Errorf("Punto de pedido sin plazo del proveedor incorrecto: %+v", recommendation)
+	}
+}
+
+func TestReorderService_GetThresholdRecommendation_WithoutHistory(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	reorderService := services.NewReorderService(tankService, measurementRepo)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act
+	_, err := reorderService.GetThresholdRecommendation(ctx, tank.ID, domain.DefaultAlertLeadTime)
+
+	// Assert
+	if !errors.Is(err, services.ErrInsufficientHistory) {
+		t.Errorf("Se esperaba ErrInsufficientHistory sin consumo registrado, se obtuvo: %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+)
+
+func TestMemoryRepositories_CancelledContext(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+
+	tank := createTestTank()
+	if err := tankRepo.SaveTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al guardar el tanque para la prueba: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act & Assert
+	if _, err := tankRepo.GetTank(ctx, tank.ID); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetTank debería respetar la cancelación del contexto, se obtuvo: %v", err)
+	}
+
+	if err := measurementRepo.SaveMeasurement(ctx, createTestMeasurement(tank.ID, 100.0)); !errors.Is(err, context.Canceled) {
+		t.Errorf("SaveMeasurement debería respetar la cancelación del contexto, se obtuvo: %v", err)
+	}
+
+	if _, err := measurementRepo.GetLastMeasurement(ctx, tank.ID); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetLastMeasurement debería respetar la cancelación del contexto, se obtuvo: %v", err)
+	}
+}
+
+func TestMemoryMeasurementRepository_ChronologicalOrderAndDuplicates(t *testing.T) {
+	// Arrange
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	unitOfWork := repositories.NewMemoryUnitOfWork(repositories.NewMemoryTankRepository(), measurementRepo, repositories.NewMemoryStatusHistoryRepository(), repositories.NewMemoryOutboxRepository())
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+
+	newer := createTestMeasurement("tanque", 300.0)
+	newer.Timestamp = base.Add(10 * time.Minute)
+	older := createTestMeasurement("tanque", 500.0)
+	older.Timestamp = base
+	otherSensor := createTestMeasurement("tanque", 310.0)
+	otherSensor.SensorID = "radar-2"
+	otherSensor.Timestamp = newer.Timestamp
+
+	// Act
+	for _, measurement := range []*domain.Measurement{newer, older, otherSensor} {
+		if err := measurementRepo.SaveMeasurement(ctx, measurement); err != nil {
+			t.Fatalf("Error al guardar la medición: %v", err)
+		}
+	}
+	duplicateErr := measurementRepo.SaveMeasurement(ctx, older)
+	txErr := unitOfWork.Do(ctx, func(ctx context.Context, repos ports.TxRepositories) error {
+		middle := createTestMeasurement("tanque", 400.0)
+		middle.Timestamp = base.Add(5 * time.Minute)
+		if err := repos.Measurements.SaveMeasurement(ctx, middle); err != nil {
+			return err
+		}
+		if err := repos.Measurements.SaveMeasurement(ctx, middle); !errors.Is(err, domain.ErrDuplicateMeasurement) {
+			t.Errorf("Se esperaba ErrDuplicateMeasurement dentro de la unidad de trabajo, se obtuvo %v", err)
+		}
+		return nil
+	})
+
+	// Assert
+	if !errors.Is(duplicateErr, domain.ErrDuplicateMeasurement) {
+		t.Errorf("Se esperaba ErrDuplicateMeasurement, se obtuvo %v", duplicateErr)
+	}
+	if txErr != nil {
+		t.Fatalf("Error en la unidad de trabajo: %v", txErr)
+	}
+
+	measurements, _ := measurementRepo.GetMeasurementsByTankID(ctx, "tanque", 0)
+	levels := make([]float64, len(measurements))
+	for i, measurement := range measurements {
+		levels[i] = measurement.Level
+	}
+	// A igual marca de tiempo, la medición guardada después va delante
+	if len(levels) != 4 || levels[0] != 310.0 || levels[1] != 300.0 || levels[2] != 400.0 || levels[3] != 500.0 {
+		t.Errorf("Orden incorrecto de las mediciones: %v", levels)
+	}
+}
+
+func TestMemoryUnitOfWork_RollbackOnError(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	unitOfWork := repositories.NewMemoryUnitOfWork(tankRepo, measurementRepo, repositories.NewMemoryStatusHistoryRepository(), repositories.NewMemoryOutboxRepository())
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankRepo.SaveTank(ctx, tank); err != nil {
+		t.Fatalf("Error al guardar el tanque para la prueba: %v", err)
+	}
+
+	errAbort := errors.New("abort")
+
+	// Act
+	err := unitOfWork.Do(ctx, func(ctx context.Context, repos ports.TxRepositories) error {
+		if err := repos.Measurements.SaveMeasurement(ctx, createTestMeasurement(tank.ID, 100.0)); err != nil {
+			return err
+		}
+
+		tank.CurrentLevel = 100.0
+		if err := repos.Tanks.UpdateTank(ctx, tank); err != nil {
+			return err
+		}
+
+		return errAbort
+	})
+
+	// Assert
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Se esperaba el error de la unidad de trabajo, se obtuvo: %v", err)
+	}
+
+	last, err := measurementRepo.GetLastMeasurement(ctx, tank.ID)
+	if err != nil {
+		t.Fatalf("Error al obtener la última medición: %v", err)
+	}
+	if last != nil {
+		t.Errorf("La medición no debería haberse guardado tras el error")
+	}
+
+	stored, err := tankRepo.GetTank(ctx, tank.ID)
+	if err != nil {
+		t.Fatalf("Error al obtener el tanque: %v", err)
+	}
+	if stored.CurrentLevel != 500.0 {
+		t.Errorf("El tanque no debería haberse actualizado. Esperado: %.2f, Obtenido: %.2f", 500.0, stored.CurrentLevel)
+	}
+}
+
+func TestMemoryStore_SnapshotRoundTrip(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot.gob")
+
+	store := repositories.NewMemoryStore()
+	tank := createTestTank()
+	if err := store.Tanks.SaveTank(ctx, tank); err != nil {
+		t.Fatalf("Error al guardar el tanque para la prueba: %v", err)
+	}
+	if err := store.Measurements.SaveMeasurement(ctx, createTestMeasurement(tank.ID, 420.0)); err != nil {
+		t.Fatalf("Error al guardar la medición para la prueba: %v", err)
+	}
+	attachment := &domain.Attachment{ID: "adjunto-1", TankID: tank.ID, StorageKey: "tanques/adjunto-1"}
+	if err := store.Attachments.SaveAttachment(ctx, attachment); err != nil {
+		t.Fatalf("Error al guardar el adjunto para la prueba: %v", err)
+	}
+
+	// Act
+	if err := store.SaveSnapshot(ctx, path); err != nil {
+		t.Fatalf("Error al guardar la instantánea: %v", err)
+	}
+	restored := repositories.NewMemoryStore()
+	loaded, err := restored.LoadSnapshot(ctx, path)
+
+	// Assert
+	if err != nil || !loaded {
+		t.Fatalf("Se esperaba restaurar la instantánea, loaded=%v error=%v", loaded, err)
+	}
+	if got, err := restored.Tanks.GetTank(ctx, tank.ID); err != nil || got == nil || got.Name != tank.Name {
+		t.Errorf("El tanque no se restauró correctamente: %+v, %v", got, err)
+	}
+	if last, _ := restored.Measurements.GetLastMeasurement(ctx, tank.ID); last == nil || last.Level != 420.0 {
+		t.Errorf("La medición no se restauró correctamente: %+v", last)
+	}
+	if got, _ := restored.Attachments.GetAttachment(ctx, attachment.ID); got == nil || got.StorageKey != attachment.StorageKey {
+		t.Errorf("Se esperaba conservar la clave de almacenamiento del adjunto: %+v", got)
+	}
+}
+
+func TestMemoryStore_LoadMissingSnapshot(t *testing.T) {
+	// Arrange
+	store := repositories.NewMemoryStore()
+
+	// Act
+	loaded, err := store.LoadSnapshot(context.Background(), filepath.Join(t.TempDir(), "no-existe.gob"))
+
+	// Assert
+	if err != nil || loaded {
+		t.Errorf("Sin instantánea no debería restaurarse nada, loaded=%v error=%v", loaded, err)
+	}
+}
+
+func TestFileSyncQueueRepository_SurvivesRestart(t *testing.T) {
+	// Arrange: una pasarela encola tres mediciones, confirma la primera y se apaga de golpe en
+	// mitad de la escritura de la siguiente
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "edge", "queue.jsonl")
+	queue, err := repositories.OpenFileSyncQueueRepository(path)
+	if err != nil {
+		t.Fatalf("Error al abrir la cola: %v", err)
+	}
+	measurements := []*domain.Measurement{
+		createTestMeasurement("tq-1", 100),
+		createTestMeasurement("tq-1", 110),
+		createTestMeasurement("tq-2", 120),
+	}
+	for i, measurement := range measurements {
+		measurement.ID = []string{"m-1", "m-2", "m-3"}[i]
+	}
+	if err := queue.EnqueueMeasurements(ctx, measurements); err != nil {
+		t.Fatalf("Error al encolar: %v", err)
+	}
+	if err := queue.DeleteQueuedMeasurements(ctx, []string{"m-1"}); err != nil {
+		t.Fatalf("Error al confirmar: %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Error al abrir el diario: %v", err)
+	}
+	if _, err := file.WriteString(`{"enqueue":[{"id":"m-4","tank_`); err != nil {
+		t.Fatalf("Error al escribir en el diario: %v", err)
+	}
+	file.Close()
+
+	// Act
+	restored, err := repositories.OpenFileSyncQueueRepository(path)
+	if err != nil {
+		t.Fatalf("Error al reabrir la cola: %v", err)
+	}
+	pending, err := restored.GetQueuedMeasurements(ctx, 0)
+
+	// Assert: siguen las no confirmadas, en orden, y la escritura interrumpida se descarta
+	if err != nil {
+		t.Fatalf("Error al leer la cola: %v", err)
+	}
+	if len(pending) != 2 || pending[0].ID != "m-2" || pending[1].ID != "m-3" || pending[1].Level != 120 {
+		t.Fatalf("Cola restaurada incorrecta: %+v", pending)
+	}
+
+	// Act: al confirmarse el resto, el diario se reescribe vacío y sigue admitiendo altas
+	if err := restored.DeleteQueuedMeasurements(ctx, []string{"m-2", "m-3"}); err != nil {
+		t.Fatalf("Error al confirmar: %v", err)
+	}
+	if err := restored.EnqueueMeasurements(ctx, []*domain.Measurement{{ID: "m-5", TankID: "tq-1", Level: 130}}); err != nil {
+		t.Fatalf("Error al encolar tras la reescritura: %v", err)
+	}
+	if err := restored.Close(); err != nil {
+		t.Fatalf("Error al cerrar la cola: %v", err)
+	}
+	reopened, err := repositories.OpenFileSyncQueueRepository(path)
+	if err != nil {
+		t.Fatalf("Error al reabrir la cola: %v", err)
+	}
+	count, _ := reopened.CountQueuedMeasurements(ctx)
+
+	// Assert
+	if count != 1 {
+		t.Errorf("Se esperaba una medición pendiente tras la reescritura, hay %d", count)
+	}
+	if leftovers, _ := filepath.Glob(path + ".*.tmp"); len(leftovers) > 0 {
+		t.Errorf("La reescritura dejó archivos temporales: %v", leftovers)
+	}
+}
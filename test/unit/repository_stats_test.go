@@ -0,0 +1,80 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestRepositoryStatsService_GetStats(t *testing.T) {
+	// Arrange
+	store := repositories.NewMemoryStore()
+	backend := domain.RepositoryBackendInfo{Name: "memory", Persistent: true, SnapshotPath: "/data/snapshot.gob", SnapshotInterval: 300}
+	service := services.NewRepositoryStatsService(store.Tanks, store.Measurements, store, backend)
+	ctx := context.Background()
+
+	north := createTestTank()
+	north.Name = "Norte"
+	south := createTestTank()
+	south.Name = "Sur"
+	empty := createTestTank()
+	empty.Name = "Vacío"
+	for _, tank := range []*domain.Tank{south, north, empty} {
+		if err := store.Tanks.SaveTank(ctx, tank); err != nil {
+			t.Fatalf("Error al guardar el tanque: %v", err)
+		}
+	}
+
+	now := time.Now()
+	readings := []struct {
+		tank *domain.Tank
+		ago  time.Duration
+	}{
+		{north, 30 * time.Second},
+		{north, 30 * time.Minute},
+		{north, 3 * time.Hour},
+		{south, 2 * 24 * time.Hour},
+	}
+	for _, reading := range readings {
+		m := createTestMeasurement(reading.tank.ID, 400)
+		m.Timestamp = now.Add(-reading.ago)
+		if err := store.Measurements.SaveMeasurement(ctx, m); err != nil {
+			t.Fatalf("Error al guardar la medición: %v", err)
+		}
+	}
+	if err := store.Channels.SaveChannel(ctx, &domain.NotificationChannel{ID: "log", Name: "Log", Type: domain.ChannelTypeLog}); err != nil {
+		t.Fatalf("Error al guardar el canal: %v", err)
+	}
+
+	// Act
+	stats, err := service.GetStats(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al obtener las estadísticas: %v", err)
+	}
+	if stats.Backend != backend {
+		t.Errorf("Backend incorrecto: %+v", stats.Backend)
+	}
+	if stats.Entities["tanks"] != 3 || stats.Entities["measurements"] != 4 || stats.Entities["channels"] != 1 || stats.Entities["sessions"] != 0 {
+		t.Errorf("Recuento de entidades incorrecto: %v", stats.Entities)
+	}
+	if stats.Ingestion.LastMinute != 1 || stats.Ingestion.LastHour != 2 || stats.Ingestion.LastDay != 3 {
+		t.Errorf("Ingesta reciente incorrecta: %+v", stats.Ingestion)
+	}
+
+	if len(stats.Tanks) != 3 || stats.Tanks[0].TankID != north.ID || stats.Tanks[1].TankID != south.ID {
+		t.Fatalf("Se esperaban los tres tanques ordenados por nombre: %+v", stats.Tanks)
+	}
+	northRange := stats.Tanks[0]
+	if northRange.Measurements != 3 || !northRange.Oldest.Equal(now.Add(-3*time.Hour)) || !northRange.Newest.Equal(now.Add(-30*time.Second)) {
+		t.Errorf("Rango de mediciones del tanque norte incorrecto: %+v", northRange)
+	}
+	if emptyRange := stats.Tanks[2]; emptyRange.Measurements != 0 || emptyRange.Oldest != nil || emptyRange.Newest != nil {
+		t.Errorf("Un tanque sin mediciones no debería tener rango: %+v", emptyRange)
+	}
+}
@@ -0,0 +1,70 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/cache"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestCacheInvalidatingTankService_InvalidatesOnMeasurement(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	responseCache := cache.NewResponseCache(time.Minute)
+
+	tankService := services.NewCacheInvalidatingTankService(
+		newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{}),
+		responseCache,
+	)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	other := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	if err := tankService.CreateTank(ctx, other); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	response := &cache.Response{Status: http.StatusOK, Body: []byte("{}")}
+	responseCache.Set("kpis-tank", tank.ID, response)
+	responseCache.Set("kpis-other", other.ID, response)
+	responseCache.Set("sensors", cache.AllTanks, response)
+
+	// Act
+	if err := tankService.AddMeasurement(ctx, createTestMeasurement(tank.ID, 600)); err != nil {
+		t.Fatalf("Error al añadir la medición: %v", err)
+	}
+
+	// Assert: se descartan las respuestas del tanque y las de toda la flota, no las de otros tanques
+	if _, ok := responseCache.Get("kpis-tank"); ok {
+		t.Error("Los KPI del tanque medido siguen en la caché")
+	}
+	if _, ok := responseCache.Get("sensors"); ok {
+		t.Error("Los agregados de la flota siguen en la caché")
+	}
+	if _, ok := responseCache.Get("kpis-other"); !ok {
+		t.Error("Los KPI de otro tanque no deberían invalidarse")
+	}
+}
+
+func TestResponseCache_Expires(t *testing.T) {
+	// Arrange
+	responseCache := cache.NewResponseCache(10 * time.Millisecond)
+	responseCache.Set("kpis", "tank-1", &cache.Response{Status: http.StatusOK})
+
+	// Act & Assert
+	if _, ok := responseCache.Get("kpis"); !ok {
+		t.Fatal("La respuesta recién guardada debería estar en la caché")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := responseCache.Get("kpis"); ok {
+		t.Error("La respuesta vencida no debería devolverse")
+	}
+}
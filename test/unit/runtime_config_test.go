@@ -0,0 +1,189 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/adapters/runtimeconfig"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// stubSettingsSource devuelve la configuración leída de un documento YAML en memoria
+type stubSettingsSource struct {
+	document string
+}
+
+func (s *stubSettingsSource) Load(ctx context.Context, base domain.RuntimeSettings) (*domain.RuntimeSettings, error) {
+	return runtimeconfig.Parse(strings.NewReader(s.document), base)
+}
+
+// recordingSettingsApplier registra la última configuración aplicada
+type recordingSettingsApplier struct {
+	applied *domain.RuntimeSettings
+	calls   int
+}
+
+func (a *recordingSettingsApplier) ApplyRuntimeSettings(ctx context.Context, settings *domain.RuntimeSettings) error {
+	a.applied = settings
+	a.calls++
+	return nil
+}
+
+func TestRuntimeConfigService_ReloadAppliesFileOverEnv(t *testing.T) {
+	// Arrange
+	base := domain.RuntimeSettings{LogLevel: "debug", AlertNotifier: "log", DefaultAlertThreshold: 10, Source: "env"}
+	source := &stubSettingsSource{document: "log_level: warn\ndefault_alert_threshold: 15\n"}
+	applier := &recordingSettingsApplier{}
+	configService := services.NewRuntimeConfigService(source, applier, base)
+	ctx := context.Background()
+
+	// Act
+	settings, err := configService.Reload(ctx)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error al recargar: %v", err)
+	}
+	if settings.LogLevel != "warn" || settings.DefaultAlertThreshold != 15 || settings.AlertNotifier != "log" {
+		t.Errorf("El archivo debe sustituir solo los valores que indica: %+v", settings)
+	}
+	if settings.ReloadedAt == nil || applier.applied.LogLevel != "warn" {
+		t.Errorf("La configuración recargada debe aplicarse: %+v", applier.applied)
+	}
+	if current, _ := configService.GetSettings(ctx); current.DefaultAlertThreshold != 15 {
+		t.Errorf("Se esperaba la configuración recargada en vigor, se obtuvo %+v", current)
+	}
+}
+
+func TestRuntimeConfigService_InvalidReloadKeepsPreviousSettings(t *testing.T) {
+	// Arrange
+	base := domain.RuntimeSettings{LogLevel: "info", AlertNotifier: "log", DefaultAlertThreshold: 10, Source: "env"}
+	applier := &recordingSettingsApplier{}
+	ctx := context.Background()
+
+	cases := map[string]string{
+		"nivel desconocido":     "log_level: verbose\n",
+		"umbral fuera de rango": "default_alert_threshold: 150\n",
+		"webhook sin destino":   "alert_notifier: webhook\n",
+		"campo desconocido":     "log_levle: warn\n",
+	}
+
+	for name, document := range cases {
+		t.Run(name, func(t *testing.T) {
+			configService := services.NewRuntimeConfigService(&stubSettingsSource{document: document}, applier, base)
+
+			// Act
+			_, err := configService.Reload(ctx)
+
+			// Assert
+			if !errors.Is(err, services.ErrInvalidRuntimeConfig) {
+				t.Errorf("Se esperaba ErrInvalidRuntimeConfig, se obtuvo: %v", err)
+			}
+			if current, _ := configService.GetSettings(ctx); current.LogLevel != "info" || current.ReloadedAt != nil {
+				t.Errorf("Debe seguir en vigor la configuración anterior: %+v", current)
+			}
+		})
+	}
+	if applier.calls != 0 {
+		t.Errorf("Una configuración no válida no debe aplicarse, se aplicó %d veces", applier.calls)
+	}
+
+	withoutFile := services.NewRuntimeConfigService(nil, applier, base)
+	if _, err := withoutFile.Reload(ctx); !errors.Is(err, services.ErrRuntimeConfigUnavailable) {
+		t.Errorf("Se esperaba ErrRuntimeConfigUnavailable sin archivo, se obtuvo: %v", err)
+	}
+}
+
+func TestDefaultsTankService_UsesCurrentDefaultThreshold(t *testing.T) {
+	// Arrange
+	defaults := services.NewTankDefaults(10)
+	tankService := services.NewDefaultsTankService(
+		newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{}),
+		defaults,
+	)
+	ctx := context.Background()
+
+	before := createTestTank()
+	before.AlertThreshold = 0
+	after := createTestTank()
+	after.AlertThreshold = 0
+	explicit := createTestTank()
+	explicit.AlertThreshold = 30
+
+	// Act
+	beforeErr := tankService.CreateTank(ctx, before)
+	defaults.SetAlertThreshold(25)
+	afterErr := tankService.CreateTank(ctx, after)
+	explicitErr := tankService.CreateTank(ctx, explicit)
+
+	// Assert
+	if beforeErr != nil || before.AlertThreshold != 10 {
+		t.Errorf("Se esperaba el umbral por defecto inicial: %.2f, %v", before.AlertThreshold, beforeErr)
+	}
+	if afterErr != nil || after.AlertThreshold != 25 {
+		t.Errorf("Se esperaba el umbral por defecto recargado: %.2f, %v", after.AlertThreshold, afterErr)
+	}
+	if explicitErr != nil || explicit.AlertThreshold != 30 {
+		t.Errorf("El umbral indicado no debe cambiarse: %.2f, %v", explicit.AlertThreshold, explicitErr)
+	}
+}
+
+func TestSwappableAlertNotifier_DeliversToCurrentNotifier(t *testing.T) {
+	// Arrange
+	first, second := &MockAlertNotifier{}, &MockAlertNotifier{}
+	notifier := services.NewSwappableAlertNotifier(first)
+	alert := domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, createTestTank(), "Nivel crítico")
+	ctx := context.Background()
+
+	// Act
+	notifier.Notify(ctx, alert)
+	notifier.Swap(second)
+	notifier.Notify(ctx, alert)
+
+	// Assert
+	if first.AlertsSent != 1 || second.AlertsSent != 1 {
+		t.Errorf("Cada alerta debe llegar al notificador en vigor: %d y %d", first.AlertsSent, second.AlertsSent)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]logger.Level{"debug": logger.LevelDebug, "INFO": logger.LevelInfo, " warn ": logger.LevelWarn, "error": logger.LevelError}
+	for name, expected := range cases {
+		if level, err := logger.ParseLevel(name); err != nil || level != expected {
+			t.Errorf("ParseLevel(%q) = %v, %v; se esperaba %v", name, level, err, expected)
+		}
+	}
+	if _, err := logger.ParseLevel("verbose"); err == nil {
+		t.Error("Se esperaba error con un nivel desconocido")
+	}
+}
+
+func TestRuntimeConfigService_SetLogLevelUntilNextReload(t *testing.T) {
+	// Arrange
+	base := domain.RuntimeSettings{LogLevel: "info", AlertNotifier: "log", DefaultAlertThreshold: 10, Source: "env"}
+	applier := &recordingSettingsApplier{}
+	configService := services.NewRuntimeConfigService(&stubSettingsSource{document: "default_alert_threshold: 20\n"}, applier, base)
+	ctx := context.Background()
+
+	// Act
+	settings, err := configService.SetLogLevel(ctx, " DEBUG ")
+	appliedLevel := applier.applied.LogLevel
+	_, invalidErr := configService.SetLogLevel(ctx, "verbose")
+	reloaded, reloadErr := configService.Reload(ctx)
+
+	// Assert
+	if err != nil || settings.LogLevel != "debug" || appliedLevel != "debug" {
+		t.Fatalf("Se esperaba el nivel debug aplicado: %+v, %v", settings, err)
+	}
+	if !errors.Is(invalidErr, services.ErrInvalidRuntimeConfig) {
+		t.Errorf("Se esperaba ErrInvalidRuntimeConfig con un nivel desconocido, se obtuvo: %v", invalidErr)
+	}
+	if reloadErr != nil || reloaded.LogLevel != "info" || reloaded.DefaultAlertThreshold != 20 {
+		t.Errorf("La recarga debe volver al nivel del archivo o del entorno: %+v, %v", reloaded, reloadErr)
+	}
+}
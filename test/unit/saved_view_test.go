@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func userContext(subject, role string) context.Context {
+	return domain.ContextWithPrincipal(context.Background(), &domain.Principal{Subject: subject, Roles: []string{role}})
+}
+
+func TestSavedViewService_ViewsBelongToTheirUser(t *testing.T) {
+	// Arrange
+	viewService := services.NewSavedViewService(repositories.NewMemorySavedViewRepository())
+	ana := userContext("ana", domain.RoleViewer)
+	admin := userContext("admin", domain.RoleAdmin)
+	view := &domain.SavedView{
+		Name:  "  Diésel crítico – Norte ",
+		Path:  "/api/tanks",
+		Query: map[string]string{"labels": "region=norte"},
+	}
+
+	// Act
+	err := viewService.CreateView(ana, view)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error inesperado al guardar la vista: %v", err)
+	}
+	if view.ID == "" || view.Subject != "ana" || view.Name != "Diésel crítico – Norte" {
+		t.Errorf("Vista guardada incorrecta: %+v", view)
+	}
+	views, _ := viewService.GetViews(ana)
+	if len(views) != 1 || views[0].Query["labels"] != "region=norte" {
+		t.Errorf("Se esperaba la vista del usuario: %+v", views)
+	}
+
+	// Las vistas de otro usuario no existen para los demás, tampoco para un administrador
+	if views, _ := viewService.GetViews(admin); len(views) != 0 {
+		t.Errorf("El administrador no debería ver las vistas de otro usuario: %+v", views)
+	}
+	if _, err := viewService.GetView(admin, view.ID); !errors.Is(err, services.ErrSavedViewNotFound) {
+		t.Errorf("Se esperaba ErrSavedViewNotFound, se obtuvo %v", err)
+	}
+	if err := viewService.DeleteView(admin, view.ID); !errors.Is(err, services.ErrSavedViewNotFound) {
+		t.Errorf("Se esperaba ErrSavedViewNotFound al borrar, se obtuvo %v", err)
+	}
+
+	// Otro usuario puede usar el mismo nombre; el mismo usuario no
+	if err := viewService.CreateView(admin, &domain.SavedView{Name: view.Name, Path: "/api/tanks"}); err != nil {
+		t.Errorf("Error inesperado al guardar la vista de otro usuario: %v", err)
+	}
+	duplicate := &domain.SavedView{Name: "diésel crítico – norte", Path: "/api/tanks"}
+	if err := viewService.CreateView(ana, duplicate); !errors.Is(err, services.ErrSavedViewConflict) {
+		t.Errorf("Se esperaba ErrSavedViewConflict, se obtuvo %v", err)
+	}
+}
+
+func TestSavedViewService_Validation(t *testing.T) {
+	viewService := services.NewSavedViewService(repositories.NewMemorySavedViewRepository())
+	ctx := userContext("ana", domain.RoleViewer)
+
+	tests := []struct {
+		name string
+		view *domain.SavedView
+		want error
+	}{
+		{"sin nombre", &domain.SavedView{Path: "/api/tanks"}, services.ErrInvalidSavedView},
+		{"ruta fuera de la API", &domain.SavedView{Name: "a", Path: "https://example.com"}, services.ErrInvalidSavedView},
+		{"ruta con parámetros", &domain.SavedView{Name: "a", Path: "/api/tanks?labels=x"}, services.ErrInvalidSavedView},
+		{"filtro en una ruta sin filtros", &domain.SavedView{Name: "a", Path: "/api/tanks", Query: map[string]string{"filter": "level<10"}}, services.ErrInvalidSavedView},
+		{"filtro inválido", &domain.SavedView{Name: "a", Path: "/api/tanks/t1/measurements", Query: map[string]string{"filter": "nivel<10"}}, services.ErrInvalidFilter},
+		{"filtro de mediciones", &domain.SavedView{Name: "b", Path: "/api/tanks/t1/measurements", Query: map[string]string{"filter": "level<100 AND temperature>30", "limit": "50"}}, nil},
+		{"filtro de alertas en cola", &domain.SavedView{Name: "c", Path: "/api/v2/notification-channels/email/queue", Query: map[string]string{"filter": "severity=critical"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := viewService.CreateView(ctx, tt.view)
+			if (tt.want == nil && err != nil) || (tt.want != nil && !errors.Is(err, tt.want)) {
+				t.Errorf("Se esperaba %v, se obtuvo %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/locks"
+	"monitor-tanques/internal/adapters/scheduler"
+	"monitor-tanques/pkg/logger"
+)
+
+func TestScheduler_RunsOnSingleInstance(t *testing.T) {
+	// Arrange: dos réplicas que comparten el mismo bloqueo
+	locker := locks.NewMemoryLocker()
+	log := logger.NewSimpleLogger()
+	replicaA := scheduler.NewScheduler(locker, log)
+	replicaB := scheduler.NewScheduler(locker, log)
+	ctx := context.Background()
+
+	runs := 0
+	job := scheduler.Job{
+		Name:     "monitor-tanks",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			runs++
+			return nil
+		},
+	}
+
+	// Act
+	ranA := replicaA.RunOnce(ctx, job)
+	ranB := replicaB.RunOnce(ctx, job)
+
+	// Assert
+	if !ranA || ranB {
+		t.Errorf("Solo la primera réplica debería ejecutar la tarea. A: %v, B: %v", ranA, ranB)
+	}
+
+	if runs != 1 {
+		t.Errorf("La tarea debería ejecutarse una sola vez por intervalo, se ejecutó %d veces", runs)
+	}
+}
+
+func TestScheduler_RunsEveryPeriod(t *testing.T) {
+	// Arrange: dos réplicas que comparten el bloqueo y disparan en los mismos periodos
+	locker := locks.NewMemoryLocker()
+	log := logger.NewSimpleLogger()
+	replicaA := scheduler.NewScheduler(locker, log)
+	replicaB := scheduler.NewScheduler(locker, log)
+	ctx := context.Background()
+
+	runs := 0
+	job := scheduler.Job{
+		Name:     "monitor-tanks",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			runs++
+			return nil
+		},
+	}
+
+	// Act: cinco disparos consecutivos, poco después del comienzo de cada periodo
+	const ticks = 5
+	start := time.Now().Truncate(job.Interval).Add(time.Millisecond)
+	for i := 0; i < ticks; i++ {
+		at := start.Add(time.Duration(i) * job.Interval)
+		ranA := replicaA.RunAt(ctx, job, at)
+		ranB := replicaB.RunAt(ctx, job, at)
+		if !ranA || ranB {
+			t.Errorf("Disparo %d: solo la primera réplica debería ejecutar la tarea. A: %v, B: %v", i, ranA, ranB)
+		}
+	}
+
+	// Assert: el bloqueo de un periodo no impide la ejecución del siguiente
+	if runs != ticks {
+		t.Errorf("Se esperaban %d ejecuciones, hubo %d", ticks, runs)
+	}
+}
@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestSearchService_RanksTanksSitesAndNotes(t *testing.T) {
+	// Arrange
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	noteRepo := repositories.NewMemoryTankNoteRepository()
+	searchService := services.NewSearchService(tankService, noteRepo)
+	ctx := context.Background()
+
+	tanks := []*domain.Tank{
+		{ID: "t1", Name: "Diésel principal", SiteID: "estacion-norte", LiquidType: "diesel", Capacity: 1000},
+		{ID: "t2", Name: "Tanque 2", SiteID: "estacion-norte", LiquidType: "Diésel", Capacity: 1000},
+		{ID: "t3", Name: "Diésel sur", SiteID: "estacion-sur", LiquidType: "diesel", Capacity: 1000},
+		{ID: "t4", Name: "Agua", SiteID: "estacion-norte", LiquidType: "agua", Capacity: 1000},
+	}
+	for _, tank := range tanks {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+	}
+	noteRepo.SaveNote(ctx, &domain.TankNote{ID: "n1", TankID: "t4", Text: "Se cambió la bomba de DIESEL en el sector norte", CreatedAt: time.Now()})
+
+	// Act
+	results, err := searchService.Search(ctx, "diesel NORTE", 10)
+	_, emptyErr := searchService.Search(ctx, "   ", 10)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al buscar: %v", err)
+	}
+
+	found := make(map[string]*domain.SearchResult)
+	for _, result := range results {
+		found[result.Type+":"+result.ID] = result
+	}
+	if len(results) != 3 || found["tank:t1"] == nil || found["tank:t2"] == nil || found["note:n1"] == nil {
+		t.Fatalf("Resultados inesperados: %+v", results)
+	}
+	if found["tank:t3"] != nil || found["tank:t4"] != nil {
+		t.Error("Solo deberían aparecer los resultados con todos los términos")
+	}
+
+	// El nombre pesa más que el tipo de líquido
+	if results[0].ID != "t1" || found["tank:t1"].Score <= found["tank:t2"].Score {
+		t.Errorf("Orden inesperado: %s primero (%.2f frente a %.2f)", results[0].ID, found["tank:t1"].Score, found["tank:t2"].Score)
+	}
+
+	sites, _ := searchService.Search(ctx, "norte", 10)
+	if sites[0].Type != domain.SearchResultSite || sites[0].TankCount != 3 {
+		t.Errorf("Se esperaba el sitio estacion-norte con 3 tanques primero: %+v", sites[0])
+	}
+
+	if !errors.Is(emptyErr, services.ErrInvalidSearchQuery) {
+		t.Errorf("Se esperaba ErrInvalidSearchQuery para una consulta vacía, se obtuvo %v", emptyErr)
+	}
+}
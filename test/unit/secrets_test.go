@@ -0,0 +1,270 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/secrets"
+	"monitor-tanques/internal/core/domain"
+)
+
+// countingSecretProvider devuelve siempre el mismo secreto y cuenta las lecturas
+type countingSecretProvider struct {
+	value string
+	reads int
+}
+
+func (p *countingSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	p.reads++
+	return p.value, nil
+}
+
+// recordingChannelSender guarda el último canal por el que se envió una alerta
+type recordingChannelSender struct {
+	channel *domain.NotificationChannel
+}
+
+func (s *recordingChannelSender) Send(ctx context.Context, channel *domain.NotificationChannel, alert *domain.Alert) error {
+	s.channel = channel
+	return nil
+}
+
+func TestSecrets_ResolveOnlyReferences(t *testing.T) {
+	// Arrange
+	t.Setenv("MONITOR_TEST_SMTP_PASSWORD", "s3cr3t")
+	provider := secrets.NewEnvProvider()
+
+	// Act
+	plain, plainErr := secrets.Resolve(context.Background(), provider, "texto-plano")
+	resolved, resolvedErr := secrets.Resolve(context.Background(), provider, "secret:MONITOR_TEST_SMTP_PASSWORD")
+	_, missingErr := secrets.Resolve(context.Background(), provider, "secret:MONITOR_TEST_NO_EXISTE")
+
+	// Assert
+	if plainErr != nil || plain != "texto-plano" {
+		t.Errorf("Los valores que no son referencias no deberían cambiar: %q, %v", plain, plainErr)
+	}
+	if resolvedErr != nil || resolved != "s3cr3t" {
+		t.Errorf("Secreto inesperado: %q, %v", resolved, resolvedErr)
+	}
+	if !errors.Is(missingErr, secrets.ErrSecretNotFound) {
+		t.Errorf("Se esperaba ErrSecretNotFound, se obtuvo: %v", missingErr)
+	}
+}
+
+func TestFileProvider_ReadsMountedSecrets(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "smtp-password"), []byte("s3cr3t\n"), 0o600)
+	provider := secrets.NewFileProvider(dir)
+
+	// Act
+	value, err := provider.GetSecret(context.Background(), "smtp-password")
+
+	// Assert
+	if err != nil || value != "s3cr3t" {
+		t.Errorf("Se esperaba el contenido sin el salto de línea final: %q, %v", value, err)
+	}
+	if _, err := provider.GetSecret(context.Background(), "no-existe"); !errors.Is(err, secrets.ErrSecretNotFound) {
+		t.Errorf("Se esperaba ErrSecretNotFound, se obtuvo: %v", err)
+	}
+	if _, err := provider.GetSecret(context.Background(), "../etc/passwd"); !errors.Is(err, secrets.ErrInvalidName) {
+		t.Errorf("Un nombre fuera del directorio debería rechazarse, se obtuvo: %v", err)
+	}
+}
+
+func TestVaultProvider_ReadsKVField(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token-de-prueba" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/monitor/smtp" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data": map[string]string{"password": "s3cr3t", "value": "predeterminado"},
+			},
+		})
+	}))
+	defer server.Close()
+	provider := secrets.NewVaultProvider(secrets.VaultConfig{Addr: server.URL, Token: "token-de-prueba", Mount: "kv"})
+
+	// Act
+	field, fieldErr := provider.GetSecret(context.Background(), "monitor/smtp#password")
+	value, valueErr := provider.GetSecret(context.Background(), "monitor/smtp")
+
+	// Assert
+	if fieldErr != nil || field != "s3cr3t" {
+		t.Errorf("Campo inesperado: %q, %v", field, fieldErr)
+	}
+	if valueErr != nil || value != "predeterminado" {
+		t.Errorf("Sin campo debería leerse value: %q, %v", value, valueErr)
+	}
+	if _, err := provider.GetSecret(context.Background(), "monitor/otro"); !errors.Is(err, secrets.ErrSecretNotFound) {
+		t.Errorf("Se esperaba ErrSecretNotFound, se obtuvo: %v", err)
+	}
+}
+
+func TestAWSProvider_GetsSignedSecretValue(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDTEST/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/secretsmanager/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var request map[string]string
+		json.NewDecoder(r.Body).Decode(&request)
+		if request["SecretId"] != "monitor/twilio" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"not found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"auth_token":"tw-token"}`})
+	}))
+	defer server.Close()
+	provider := secrets.NewAWSProvider(secrets.AWSConfig{
+		Endpoint:        server.URL,
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKIDTEST",
+		SecretAccessKey: "clave",
+	})
+
+	// Act
+	whole, wholeErr := provider.GetSecret(context.Background(), "monitor/twilio")
+	field, fieldErr := provider.GetSecret(context.Background(), "monitor/twilio#auth_token")
+
+	// Assert
+	if wholeErr != nil || whole != `{"auth_token":"tw-token"}` {
+		t.Errorf("Sin campo debería devolverse el SecretString completo: %q, %v", whole, wholeErr)
+	}
+	if fieldErr != nil || field != "tw-token" {
+		t.Errorf("Campo inesperado: %q, %v", field, fieldErr)
+	}
+	if _, err := provider.GetSecret(context.Background(), "monitor/otro"); !errors.Is(err, secrets.ErrSecretNotFound) {
+		t.Errorf("Se esperaba ErrSecretNotFound, se obtuvo: %v", err)
+	}
+}
+
+// TestSignV4_MatchesAWSTestSuite comprueba la firma con los casos post-vanilla y
+// post-sts-header-before de la batería de pruebas de SigV4 publicada por AWS
+func TestSignV4_MatchesAWSTestSuite(t *testing.T) {
+	config := secrets.AWSConfig{
+		Region:          "us-east-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	sessionToken := "AQoDYXdzEPT//////////wEXAMPLEtc764bNrC9SAPBSM22wDOk4x4HIZ8j4FZTwdQWLWsKWHGBuFqwAeMicRXmxfpSPfIeoIYRqTflfKD8YUuwthAx7mSEI/qkPpKPi/kMcGdQrmGdeehM4IC1NtBmUpp2wUE8phUZampKsburEDy0KPkyQDYwT7WZ0wq5VSXDvp75YU9HFvlRd8Tx6q6fE8YQcHNVXAkiY9q6d+xo0rKwT38xVqr7ZD0u0iPPkUL64lIZbqBAz+scqKmlzm8FDrypNC9Yjc8fPOLn9FX9KSYvKTr4rvx3iSIlTJabIQwj2ICCR/oLxBA=="
+
+	tests := []struct {
+		name          string
+		sessionToken  string
+		authorization string
+	}{
+		{
+			name:          "post-vanilla",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:          "post-sts-header-before",
+			sessionToken:  sessionToken,
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date;x-amz-security-token, Signature=85d96828115b5dc0cfc3bd16ad9e210dd772bbebba041836c64533a82be05ead",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "https://example.amazonaws.com/", nil)
+			config.SessionToken = tt.sessionToken
+
+			secrets.SignV4(req, nil, config, "service", now)
+
+			if got := req.Header.Get("Authorization"); got != tt.authorization {
+				t.Errorf("Firma inesperada:\n%s\nse esperaba:\n%s", got, tt.authorization)
+			}
+		})
+	}
+}
+
+func TestAWSProvider_SignsSessionTokenInHeaderOrder(t *testing.T) {
+	// Arrange
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": "valor"})
+	}))
+	defer server.Close()
+	provider := secrets.NewAWSProvider(secrets.AWSConfig{
+		Endpoint:        server.URL,
+		AccessKeyID:     "AKIDTEST",
+		SecretAccessKey: "clave",
+		SessionToken:    "token-temporal",
+	})
+
+	// Act
+	_, err := provider.GetSecret(context.Background(), "monitor/twilio")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	if !strings.Contains(authorization, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target,") {
+		t.Errorf("Las cabeceras firmadas deben ir ordenadas por nombre: %s", authorization)
+	}
+}
+
+func TestCachedProvider_ReusesSecretsWithinTTL(t *testing.T) {
+	// Arrange
+	next := &countingSecretProvider{value: "s3cr3t"}
+	cached := secrets.NewCachedProvider(next, time.Minute)
+	uncached := secrets.NewCachedProvider(next, 0)
+
+	// Act
+	cached.GetSecret(context.Background(), "smtp")
+	cached.GetSecret(context.Background(), "smtp")
+	readsWithCache := next.reads
+	uncached.GetSecret(context.Background(), "smtp")
+	uncached.GetSecret(context.Background(), "smtp")
+
+	// Assert
+	if readsWithCache != 1 {
+		t.Errorf("Con caché se esperaba una lectura, hubo %d", readsWithCache)
+	}
+	if next.reads != 3 {
+		t.Errorf("Sin caché cada uso debería leer el secreto: %d lecturas", next.reads)
+	}
+}
+
+func TestSecretsChannelSender_ResolvesTargetReference(t *testing.T) {
+	// Arrange
+	next := &recordingChannelSender{}
+	sender := secrets.NewChannelSender(next, &countingSecretProvider{value: "https://hooks.slack.com/services/T/B/X"})
+	channel := &domain.NotificationChannel{ID: "c1", Name: "slack", Type: domain.ChannelTypeSlack, Target: "secret:slack-webhook"}
+
+	// Act
+	err := sender.Send(context.Background(), channel, &domain.Alert{TankID: "t1"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	if next.channel.Target != "https://hooks.slack.com/services/T/B/X" {
+		t.Errorf("El destino debería resolverse antes de enviar: %q", next.channel.Target)
+	}
+	if channel.Target != "secret:slack-webhook" {
+		t.Errorf("El canal guardado debería conservar la referencia: %q", channel.Target)
+	}
+}
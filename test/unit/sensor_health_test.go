@@ -0,0 +1,115 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+var testSensorHealthConfig = domain.SensorHealthConfig{Samples: 100, BatteryFullVoltage: 3.6, BatteryEmptyVoltage: 3.0}
+
+func TestBuildSensorHealth(t *testing.T) {
+	// Arrange: un sensor sano reporta cada hora con consumo regular
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	tank := &domain.Tank{ID: "t1", Capacity: 1000}
+	voltage := 3.5
+
+	healthy := make([]*domain.Measurement, 0)
+	for i := 0; i < 24; i++ {
+		healthy = append(healthy, &domain.Measurement{
+			TankID:      "t1",
+			Level:       900 - float64(i)*5,
+			Temperature: 20 + float64(i%2)*0.5,
+			Timestamp:   now.Add(time.Duration(i-24) * time.Hour),
+		})
+	}
+	healthy[len(healthy)-1].BatteryVoltage = &voltage
+
+	// Un sensor congelado repite la lectura y lleva un día sin reportar
+	frozen := make([]*domain.Measurement, 0)
+	for i := 0; i < 24; i++ {
+		frozen = append(frozen, &domain.Measurement{
+			TankID:      "t1",
+			Level:       600,
+			Temperature: 20,
+			Timestamp:   now.Add(time.Duration(i-48) * time.Hour),
+		})
+	}
+
+	// Act
+	good := domain.BuildSensorHealth("t1", tank, healthy, testSensorHealthConfig, now)
+	poor := domain.BuildSensorHealth("t1", tank, frozen, testSensorHealthConfig, now)
+	unknown := domain.BuildSensorHealth("t1", tank, healthy[:2], testSensorHealthConfig, now)
+
+	// Assert
+	if good.Health != domain.SensorHealthGood || good.ReportingInterval != 3600 {
+		t.Errorf("Se esperaba un sensor sano con intervalo de 3600 s, se obtuvo %+v", good)
+	}
+	if good.BatteryPercentage == nil || *good.BatteryPercentage < 83 || *good.BatteryPercentage > 84 {
+		t.Errorf("Porcentaje de batería incorrecto: %v", good.BatteryPercentage)
+	}
+	if poor.Health != domain.SensorHealthPoor || poor.FlatlineRatio != 1 {
+		t.Errorf("Se esperaba un sensor en mal estado, se obtuvo %+v", poor)
+	}
+	if unknown.Health != domain.SensorHealthUnknown {
+		t.Errorf("Con menos de 3 lecturas la salud debe ser desconocida, se obtuvo %s", unknown.Health)
+	}
+}
+
+func TestSensorHealthService_FilterByHealth(t *testing.T) {
+	// Arrange: el tanque tiene dos sensores, uno de ellos congelado
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	sensorHealthService := services.NewSensorHealthService(tankService, measurementRepo, testSensorHealthConfig)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	start := time.Now().Add(-10 * time.Hour)
+	for i := 0; i < 10; i++ {
+		working := createTestMeasurement(tank.ID, 800-float64(i)*10)
+		working.SensorID = "radar-1"
+		working.Timestamp = start.Add(time.Duration(i) * time.Hour)
+
+		frozen := createTestMeasurement(tank.ID, 600)
+		frozen.SensorID = "flotador-1"
+		frozen.Timestamp = start.Add(time.Duration(i)*time.Hour + time.Minute)
+
+		for _, measurement := range []*domain.Measurement{working, frozen} {
+			if err := measurementRepo.SaveMeasurement(ctx, measurement); err != nil {
+				t.Fatalf("Error al guardar la medición: %v", err)
+			}
+		}
+	}
+
+	// Act
+	all, err := sensorHealthService.GetSensorHealth(ctx, "")
+	if err != nil {
+		t.Fatalf("Error al evaluar los sensores: %v", err)
+	}
+	poor, err := sensorHealthService.GetSensorHealth(ctx, domain.SensorHealthPoor)
+	if err != nil {
+		t.Fatalf("Error al evaluar los sensores: %v", err)
+	}
+	_, invalidErr := sensorHealthService.GetSensorHealth(ctx, "regular")
+
+	// Assert
+	if len(all) != 2 || all[0].SensorID != "flotador-1" {
+		t.Fatalf("Se esperaban dos sensores con el peor primero, se obtuvo %+v", all)
+	}
+	if len(poor) != 1 || poor[0].SensorID != "flotador-1" {
+		t.Errorf("Se esperaba solo el sensor congelado, se obtuvo %+v", poor)
+	}
+	if !errors.Is(invalidErr, services.ErrInvalidHealthFilter) {
+		t.Errorf("Se esperaba ErrInvalidHealthFilter, se obtuvo %v", invalidErr)
+	}
+}
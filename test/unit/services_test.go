@@ -2,13 +2,17 @@ package services_test
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 
+	"monitor-tanques/internal/adapters/projections"
 	"monitor-tanques/internal/adapters/repositories"
 	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
 	"monitor-tanques/internal/core/services"
 )
 
@@ -17,15 +21,28 @@ type MockAlertNotifier struct {
 	AlertsSent  int
 	LastTankID  string
 	LastMessage string
+	LastAlert   *domain.Alert
 }
 
-func (m *MockAlertNotifier) SendAlert(ctx context.Context, tankID string, message string) error {
+func (m *MockAlertNotifier) Notify(ctx context.Context, alert *domain.Alert) error {
 	m.AlertsSent++
-	m.LastTankID = tankID
-	m.LastMessage = message
+	m.LastTankID = alert.TankID
+	m.LastMessage = alert.Message
+	m.LastAlert = alert
 	return nil
 }
 
+// newTestTankService crea el servicio de tanques sobre los repositorios en memoria indicados
+func newTestTankService(
+	tankRepo *repositories.MemoryTankRepository,
+	measurementRepo *repositories.MemoryMeasurementRepository,
+	alertNotifier ports.AlertNotifier,
+) ports.TankService {
+	statusRepo := repositories.NewMemoryStatusHistoryRepository()
+	unitOfWork := repositories.NewMemoryUnitOfWork(tankRepo, measurementRepo, statusRepo, repositories.NewMemoryOutboxRepository())
+	return services.NewTankService(tankRepo, measurementRepo, alertNotifier, unitOfWork, projections.NewMemoryTankStateStore(0))
+}
+
 func createTestTank() *domain.Tank {
 	return &domain.Tank{
 		ID:             uuid.New().String(),
@@ -56,7 +73,7 @@ func TestTankService_CreateTank(t *testing.T) {
 	measurementRepo := repositories.NewMemoryMeasurementRepository()
 	alertNotifier := &MockAlertNotifier{}
 
-	service := services.NewTankService(tankRepo, measurementRepo, alertNotifier)
+	service := newTestTankService(tankRepo, measurementRepo, alertNotifier)
 	ctx := context.Background()
 
 	tank := createTestTank()
@@ -94,7 +111,7 @@ func TestTankService_UpdateTank(t *testing.T) {
 	measurementRepo := repositories.NewMemoryMeasurementRepository()
 	alertNotifier := &MockAlertNotifier{}
 
-	service := services.NewTankService(tankRepo, measurementRepo, alertNotifier)
+	service := newTestTankService(tankRepo, measurementRepo, alertNotifier)
 	ctx := context.Background()
 
 	// Creamos un tanque para actualizar
@@ -143,7 +160,7 @@ func TestTankService_DeleteTank(t *testing.T) {
 	measurementRepo := repositories.NewMemoryMeasurementRepository()
 	alertNotifier := &MockAlertNotifier{}
 
-	service := services.NewTankService(tankRepo, measurementRepo, alertNotifier)
+	service := newTestTankService(tankRepo, measurementRepo, alertNotifier)
 	ctx := context.Background()
 
 	// Creamos un tanque para eliminar
@@ -174,7 +191,7 @@ func TestTankService_AddMeasurement(t *testing.T) {
 	measurementRepo := repositories.NewMemoryMeasurementRepository()
 	alertNotifier := &MockAlertNotifier{}
 
-	service := services.NewTankService(tankRepo, measurementRepo, alertNotifier)
+	service := newTestTankService(tankRepo, measurementRepo, alertNotifier)
 	ctx := context.Background()
 
 	// Creamos un tanque para añadir mediciones
@@ -207,13 +224,168 @@ func TestTankService_AddMeasurement(t *testing.T) {
 	}
 }
 
+func TestTankService_StateProjection(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+
+	service := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := service.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	latest := createTestMeasurement(tank.ID, 400.0)
+	late := createTestMeasurement(tank.ID, 900.0)
+	late.Timestamp = latest.Timestamp.Add(-time.Hour)
+
+	// Act: la medición atrasada llega después de la más reciente
+	if err := service.AddMeasurement(ctx, latest); err != nil {
+		t.Fatalf("Error al añadir la medición: %v", err)
+	}
+	if err := service.AddMeasurement(ctx, late); !errors.Is(err, domain.ErrLateMeasurement) {
+		t.Fatalf("Se esperaba ErrLateMeasurement, se obtuvo: %v", err)
+	}
+
+	// Assert
+	fetched, err := service.GetTank(ctx, tank.ID)
+	if err != nil {
+		t.Fatalf("Error al obtener el tanque: %v", err)
+	}
+	if fetched.CurrentLevel != 400.0 {
+		t.Errorf("La medición atrasada reemplazó a la más reciente. Esperado: %.2f, Obtenido: %.2f", 400.0, fetched.CurrentLevel)
+	}
+
+	// Modificar el tanque devuelto no altera la proyección
+	fetched.CurrentLevel = 0
+	fetched.Status = "critical"
+	tanks, err := service.GetAllTanks(ctx)
+	if err != nil {
+		t.Fatalf("Error al listar los tanques: %v", err)
+	}
+	if len(tanks) != 1 || tanks[0].CurrentLevel != 400.0 || tanks[0].Status != "normal" {
+		t.Errorf("La proyección cambió al modificar una copia: %+v", tanks[0])
+	}
+}
+
+func TestTankService_OutOfOrderAndDuplicateMeasurements(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	alertNotifier := &MockAlertNotifier{}
+
+	service := newTestTankService(tankRepo, measurementRepo, alertNotifier)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := service.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	latest := createTestMeasurement(tank.ID, 400.0)
+	if err := service.AddMeasurement(ctx, latest); err != nil {
+		t.Fatalf("Error al añadir la medición: %v", err)
+	}
+
+	// Act: una lectura atrasada en nivel crítico, un reintento de la última y un lote que mezcla
+	// una lectura repetida con otra atrasada
+	late := createTestMeasurement(tank.ID, 50.0)
+	late.Timestamp = latest.Timestamp.Add(-time.Hour)
+	lateErr := service.AddMeasurement(ctx, late)
+
+	retry := *latest
+	retry.ID = uuid.New().String()
+	retryErr := service.AddMeasurement(ctx, &retry)
+
+	older := createTestMeasurement(tank.ID, 450.0)
+	older.Timestamp = latest.Timestamp.Add(-30 * time.Minute)
+	batchRetry := *latest
+	batchErr := service.AddMeasurements(ctx, []*domain.Measurement{&batchRetry, older})
+
+	// Assert
+	if !errors.Is(lateErr, domain.ErrLateMeasurement) || !errors.Is(retryErr, domain.ErrDuplicateMeasurement) {
+		t.Errorf("Se esperaba que se informara la lectura atrasada y la repetida: %v, %v", lateErr, retryErr)
+	}
+	if !services.IsStaleMeasurement(lateErr) || !services.IsStaleMeasurement(retryErr) || batchErr != nil {
+		t.Fatalf("Las lecturas atrasadas o repetidas no deberían fallar: %v, %v, %v", lateErr, retryErr, batchErr)
+	}
+
+	stored, err := tankRepo.GetTank(ctx, tank.ID)
+	if err != nil {
+		t.Fatalf("Error al obtener el tanque: %v", err)
+	}
+	if stored.CurrentLevel != 400.0 || !stored.LastUpdated.Equal(latest.Timestamp) {
+		t.Errorf("Las lecturas atrasadas no deberían retroceder el nivel guardado: %.2f en %v", stored.CurrentLevel, stored.LastUpdated)
+	}
+	if alertNotifier.AlertsSent != 0 {
+		t.Errorf("Una lectura atrasada no debería generar alertas, se enviaron %d", alertNotifier.AlertsSent)
+	}
+
+	measurements, _ := measurementRepo.GetMeasurementsByTankID(ctx, tank.ID, 0)
+	if len(measurements) != 3 {
+		t.Fatalf("Se esperaban 3 mediciones sin repetir, se obtuvieron %d", len(measurements))
+	}
+	for i, expected := range []float64{400.0, 450.0, 50.0} {
+		if measurements[i].Level != expected {
+			t.Errorf("Posición %d: se esperaba el nivel %.0f, se obtuvo %.0f", i, expected, measurements[i].Level)
+		}
+	}
+}
+
+func TestTankService_ConcurrentReadsAndIngestion(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+
+	service := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := service.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act: lecturas y mediciones simultáneas (ejecutar con -race para detectar accesos sin sincronizar)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			measurement := createTestMeasurement(tank.ID, float64(200+i))
+			measurement.Timestamp = start.Add(time.Duration(i) * time.Second)
+			if err := service.AddMeasurement(ctx, measurement); err != nil {
+				t.Errorf("Error al añadir la medición: %v", err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := service.GetAllTanks(ctx); err != nil {
+				t.Errorf("Error al listar los tanques: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert: el tanque termina con la medición más reciente
+	fetched, err := service.GetTank(ctx, tank.ID)
+	if err != nil {
+		t.Fatalf("Error al obtener el tanque: %v", err)
+	}
+	if fetched.CurrentLevel != 219.0 {
+		t.Errorf("Nivel incorrecto tras la ingesta concurrente. Esperado: %.2f, Obtenido: %.2f", 219.0, fetched.CurrentLevel)
+	}
+}
+
 func TestTankService_MonitorTank_Critical(t *testing.T) {
 	// Arrange
 	tankRepo := repositories.NewMemoryTankRepository()
 	measurementRepo := repositories.NewMemoryMeasurementRepository()
 	alertNotifier := &MockAlertNotifier{}
 
-	service := services.NewTankService(tankRepo, measurementRepo, alertNotifier)
+	service := newTestTankService(tankRepo, measurementRepo, alertNotifier)
 	ctx := context.Background()
 
 	// Creamos un tanque con nivel crítico (por debajo del umbral de alerta)
@@ -242,4 +414,12 @@ func TestTankService_MonitorTank_Critical(t *testing.T) {
 	if alertNotifier.LastTankID != tank.ID {
 		t.Errorf("ID incorrecto en la alerta. Esperado: %s, Obtenido: %s", tank.ID, alertNotifier.LastTankID)
 	}
+
+	alert := alertNotifier.LastAlert
+	if alert.Type != domain.AlertEventLevelCritical || alert.Severity != domain.AlertSeverityCritical {
+		t.Errorf("Tipo o severidad incorrectos: %s/%s", alert.Type, alert.Severity)
+	}
+	if alert.Tank == nil || alert.Tank.CurrentLevel != 50.0 {
+		t.Errorf("La alerta debe incluir el estado del tanque, se obtuvo %+v", alert.Tank)
+	}
 }
@@ -0,0 +1,197 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"monitor-tanques/internal/adapters/auth"
+	"monitor-tanques/internal/adapters/handlers"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+func newTestSessionService(t *testing.T) ports.SessionService {
+	t.Helper()
+	service, err := services.NewSessionService(domain.SessionConfig{AccessTTL: time.Minute, RefreshTTL: time.Hour}, repositories.NewMemorySessionRepository())
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+	return service
+}
+
+func TestSessionService_RefreshRotatesTokens(t *testing.T) {
+	// Arrange
+	service := newTestSessionService(t)
+	ctx := context.Background()
+	principal := &domain.Principal{Subject: "user-1", Roles: []string{domain.RoleOperator}, Source: "oidc"}
+	first, err := service.CreateSession(ctx, principal, "203.0.113.7", "test")
+	if err != nil {
+		t.Fatalf("No se esperaba error: %v", err)
+	}
+
+	// Act
+	second, refreshErr := service.Refresh(ctx, first.RefreshToken)
+	_, oldAccessErr := service.Authenticate(ctx, first.AccessToken)
+	authenticated, newAccessErr := service.Authenticate(ctx, second.AccessToken)
+
+	// Assert
+	if refreshErr != nil || second.SessionID != first.SessionID || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("El refresco debería rotar los tokens de la misma sesión: %+v, %v", second, refreshErr)
+	}
+	if !errors.Is(oldAccessErr, services.ErrInvalidSessionToken) {
+		t.Errorf("El token de acceso anterior no debería aceptarse: %v", oldAccessErr)
+	}
+	if newAccessErr != nil || authenticated.Subject != "user-1" || !authenticated.HasRole(domain.RoleOperator) {
+		t.Errorf("Principal inesperado: %+v, %v", authenticated, newAccessErr)
+	}
+}
+
+func TestSessionService_RefreshReuseRevokesSession(t *testing.T) {
+	// Arrange
+	service := newTestSessionService(t)
+	ctx := context.Background()
+	first, _ := service.CreateSession(ctx, &domain.Principal{Subject: "user-1"}, "", "")
+	second, _ := service.Refresh(ctx, first.RefreshToken)
+
+	// Act
+	_, reuseErr := service.Refresh(ctx, first.RefreshToken)
+	_, accessErr := service.Authenticate(ctx, second.AccessToken)
+	_, refreshErr := service.Refresh(ctx, second.RefreshToken)
+	sessions, _ := service.GetSessions(ctx, domain.SessionFilter{})
+
+	// Assert
+	if !errors.Is(reuseErr, services.ErrInvalidSessionToken) {
+		t.Errorf("Se esperaba rechazar el token reutilizado: %v", reuseErr)
+	}
+	if accessErr == nil || refreshErr == nil {
+		t.Error("La reutilización debería revocar toda la sesión")
+	}
+	if len(sessions) != 1 || sessions[0].Active || sessions[0].RevokedReason != domain.SessionRevokedRefreshReuse {
+		t.Errorf("Sesión inesperada: %+v", sessions[0])
+	}
+}
+
+func TestSessionService_RefreshForgedSecretKeepsSession(t *testing.T) {
+	// Arrange: el ID de la sesión aparece en sus tokens y en el listado de administración
+	service := newTestSessionService(t)
+	ctx := context.Background()
+	first, _ := service.CreateSession(ctx, &domain.Principal{Subject: "user-1"}, "", "")
+	second, _ := service.Refresh(ctx, first.RefreshToken)
+
+	// Act
+	_, forgedErr := service.Refresh(ctx, domain.SessionRefreshTokenPrefix+first.SessionID+".inventado")
+	_, accessErr := service.Authenticate(ctx, second.AccessToken)
+	third, refreshErr := service.Refresh(ctx, second.RefreshToken)
+
+	// Assert
+	if !errors.Is(forgedErr, services.ErrInvalidSessionToken) {
+		t.Errorf("Se esperaba rechazar el token falsificado: %v", forgedErr)
+	}
+	if accessErr != nil || refreshErr != nil || third == nil {
+		t.Errorf("Un token falsificado no debería revocar la sesión: %v, %v", accessErr, refreshErr)
+	}
+	sessions, _ := service.GetSessions(ctx, domain.SessionFilter{})
+	if len(sessions) != 1 || !sessions[0].Active || sessions[0].RevokedReason != "" {
+		t.Errorf("Sesión inesperada: %+v", sessions[0])
+	}
+}
+
+func TestSessionService_RevokeSubjectSessions(t *testing.T) {
+	// Arrange
+	service := newTestSessionService(t)
+	ctx := context.Background()
+	laptop, _ := service.CreateSession(ctx, &domain.Principal{Subject: "user-1"}, "", "")
+	service.CreateSession(ctx, &domain.Principal{Subject: "user-1"}, "", "")
+	other, _ := service.CreateSession(ctx, &domain.Principal{Subject: "user-2"}, "", "")
+
+	// Act
+	revoked, err := service.RevokeSubjectSessions(ctx, "user-1", domain.SessionRevokedLogout)
+	active, _ := service.GetSessions(ctx, domain.SessionFilter{ActiveOnly: true})
+	_, laptopErr := service.Authenticate(ctx, laptop.AccessToken)
+	_, otherErr := service.Authenticate(ctx, other.AccessToken)
+
+	// Assert
+	if err != nil || revoked != 2 {
+		t.Fatalf("Se esperaban 2 sesiones revocadas, se obtuvo %d, %v", revoked, err)
+	}
+	if len(active) != 1 || active[0].Principal.Subject != "user-2" {
+		t.Errorf("Solo debería quedar activa la sesión de user-2: %+v", active)
+	}
+	if laptopErr == nil || otherErr != nil {
+		t.Errorf("Resultados inesperados: %v, %v", laptopErr, otherErr)
+	}
+	if err := service.RevokeSession(ctx, "no-existe"); !errors.Is(err, services.ErrSessionNotFound) {
+		t.Errorf("Se esperaba ErrSessionNotFound, se obtuvo: %v", err)
+	}
+}
+
+func TestSessions_OIDCLoginOpensSession(t *testing.T) {
+	// Arrange
+	idp := newFakeIdentityProvider(t)
+	idp.idToken = idp.sign(t, map[string]interface{}{
+		"iss":    idp.server.URL,
+		"aud":    "monitor-tanques",
+		"sub":    "user-1",
+		"groups": []string{"tank-admins"},
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	})
+	provider := auth.NewOIDCProvider(auth.OIDCConfig{
+		IssuerURL:   idp.server.URL,
+		ClientID:    "monitor-tanques",
+		RoleMapping: map[string]string{"tank-admins": domain.RoleAdmin},
+	})
+	sessionService := newTestSessionService(t)
+	log := logger.NewSimpleLogger()
+
+	router := mux.NewRouter()
+	handlers.NewOIDCHandler(provider, sessionService, nil, log).RegisterRoutes(router)
+	handlers.NewSessionHandler(sessionService, nil, log).RegisterRoutes(router)
+	router.Use(auth.Middleware(auth.NewSessionAuthenticator(sessionService, provider), []string{"/api/auth/oidc/", handlers.RefreshPath}, nil, log))
+
+	do := func(method, path, bearer, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		req.AddCookie(&http.Cookie{Name: "oidc_state", Value: "estado"})
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Act
+	login := do(http.MethodGet, "/api/auth/oidc/callback?state=estado&code=codigo-valido", "", "")
+	var tokens domain.SessionTokens
+	json.NewDecoder(login.Body).Decode(&tokens)
+	sessions := do(http.MethodGet, "/api/admin/sessions?active=true", tokens.AccessToken, "")
+	logout := do(http.MethodPost, "/api/auth/logout-all", tokens.AccessToken, "")
+	afterLogout := do(http.MethodGet, "/api/admin/sessions", tokens.AccessToken, "")
+	refresh := do(http.MethodPost, handlers.RefreshPath, "", `{"refresh_token":"`+tokens.RefreshToken+`"}`)
+
+	// Assert
+	if login.Code != http.StatusOK || !domain.IsSessionToken(tokens.AccessToken) || tokens.IDToken != idp.idToken {
+		t.Fatalf("El inicio de sesión debería devolver los tokens de la sesión: %d %s", login.Code, login.Body.String())
+	}
+	var listed []domain.Session
+	json.NewDecoder(sessions.Body).Decode(&listed)
+	if sessions.Code != http.StatusOK || len(listed) != 1 || listed[0].Principal.Subject != "user-1" {
+		t.Errorf("Listado inesperado: %d %+v", sessions.Code, listed)
+	}
+	if logout.Code != http.StatusOK || !strings.Contains(logout.Body.String(), `"revoked":1`) {
+		t.Errorf("Cierre de sesiones inesperado: %d %s", logout.Code, logout.Body.String())
+	}
+	if afterLogout.Code != http.StatusUnauthorized || refresh.Code != http.StatusUnauthorized {
+		t.Errorf("Tras cerrar las sesiones sus tokens no deberían aceptarse: %d, %d", afterLogout.Code, refresh.Code)
+	}
+}
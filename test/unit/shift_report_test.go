@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/projections"
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestBuildShiftAlerts_AcknowledgedByMutes(t *testing.T) {
+	// Arrange: un turno de 8 horas con un empeoramiento previo, dos alertas y una mejora
+	from := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	to := from.Add(8 * time.Hour)
+	tank := &domain.Tank{ID: "t1", Name: "Diésel norte", Capacity: 1000}
+	changes := []*domain.StatusChange{
+		{TankID: "t1", FromStatus: "normal", ToStatus: "warning", ChangedAt: from.Add(-time.Hour)},
+		{TankID: "t1", FromStatus: "warning", ToStatus: "critical", ChangedAt: from.Add(time.Hour)},
+		{TankID: "t1", FromStatus: "critical", ToStatus: "warning", ChangedAt: from.Add(2 * time.Hour)},
+		{TankID: "t1", FromStatus: "warning", ToStatus: "critical", ChangedAt: from.Add(3 * time.Hour)},
+	}
+	mutes := []*domain.AlertMute{
+		{ID: "m1", TankID: "t1", MutedBy: "Operador", Reason: "Pedido en camino", MutedAt: from.Add(90 * time.Minute)},
+	}
+
+	// Act
+	alerts := domain.BuildShiftAlerts(tank, changes, mutes, from, to)
+
+	// Assert
+	if len(alerts) != 2 {
+		t.Fatalf("Se esperaban 2 alertas en el turno, se obtuvieron %d", len(alerts))
+	}
+	if alerts[0].Severity != "critical" || alerts[0].AcknowledgedAt == nil || alerts[0].AcknowledgedBy != "Operador" || alerts[0].Reason != "Pedido en camino" {
+		t.Errorf("La primera alerta debería estar atendida por el silencio: %+v", alerts[0])
+	}
+	if alerts[1].AcknowledgedAt != nil {
+		t.Errorf("La segunda alerta, posterior al silencio, no debería estar atendida: %+v", alerts[1])
+	}
+}
+
+func TestDetectDeliveries(t *testing.T) {
+	// Arrange: una recarga de 600 L en dos lecturas y una oscilación de 20 L
+	from := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	tank := &domain.Tank{ID: "t1", Name: "Diésel norte", Capacity: 1000}
+	levels := []float64{300, 250, 550, 850, 840, 860, 800}
+	measurements := make([]*domain.Measurement, 0, len(levels))
+	for i, level := range levels {
+		measurements = append(measurements, &domain.Measurement{TankID: "t1", Level: level, Timestamp: from.Add(time.Duration(i) * time.Hour)})
+	}
+
+	// Act
+	deliveries := domain.DetectDeliveries(tank, measurements, from, from.Add(8*time.Hour))
+	outside := domain.DetectDeliveries(tank, measurements, from.Add(4*time.Hour), from.Add(8*time.Hour))
+
+	// Assert
+	if len(deliveries) != 1 || deliveries[0].Volume != 600 {
+		t.Fatalf("Se esperaba una entrega de 600 L, se obtuvo %+v", deliveries)
+	}
+	if !deliveries[0].StartedAt.Equal(from.Add(time.Hour)) || !deliveries[0].EndedAt.Equal(from.Add(3*time.Hour)) {
+		t.Errorf("Intervalo de la entrega incorrecto: %+v", deliveries[0])
+	}
+	if len(outside) != 0 {
+		t.Errorf("Una entrega terminada antes del turno no debería contarse: %+v", outside)
+	}
+}
+
+func TestShiftReportService_GetShiftReport(t *testing.T) {
+	// Arrange: un tanque baja a nivel crítico, se silencia y recibe una entrega
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	statusRepo := repositories.NewMemoryStatusHistoryRepository()
+	muteRepo := repositories.NewMemoryAlertMuteRepository()
+	unitOfWork := repositories.NewMemoryUnitOfWork(tankRepo, measurementRepo, statusRepo, repositories.NewMemoryOutboxRepository())
+	tankService := services.NewTankService(tankRepo, measurementRepo, &MockAlertNotifier{}, unitOfWork, projections.NewMemoryTankStateStore(0))
+	muteService := services.NewAlertMuteService(tankService, muteRepo)
+	shiftService := services.NewShiftReportService(tankService, measurementRepo, statusRepo, muteRepo)
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{Subject: "u1", Name: "Operador"})
+
+	refilled := createTestTank()
+	refilled.Name = "Tanque recargado"
+	pending := createTestTank()
+	pending.Name = "Tanque pendiente"
+	from := time.Now().Add(-time.Minute)
+	for _, tank := range []*domain.Tank{refilled, pending} {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+		tankService.AddMeasurement(ctx, createTestMeasurement(tank.ID, 50))
+	}
+	muteService.MuteAlerts(ctx, refilled.ID, time.Hour, "Recarga solicitada")
+	tankService.AddMeasurement(ctx, createTestMeasurement(refilled.ID, 900))
+
+	// Act
+	report, err := shiftService.GetShiftReport(ctx, from, time.Now().Add(time.Minute))
+	_, invalidErr := shiftService.GetShiftReport(ctx, from, from)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error, se obtuvo %v", err)
+	}
+	summary := report.Summary
+	if summary.AlertsRaised != 2 || summary.AlertsAcknowledged != 1 || summary.DeliveriesDetected != 1 || summary.TanksCritical != 1 {
+		t.Errorf("Resumen incorrecto: %+v", summary)
+	}
+	if len(report.Attention) != 1 || report.Attention[0].TankID != pending.ID || report.Attention[0].Since == nil {
+		t.Errorf("Solo el tanque pendiente debería requerir atención: %+v", report.Attention)
+	}
+	text := report.HandoverText()
+	if !strings.Contains(text, "Alertas levantadas: 2 (1 atendidas)") || !strings.Contains(text, "atendida por Operador (Recarga solicitada)") {
+		t.Errorf("Texto de relevo incorrecto:\n%s", text)
+	}
+	if !errors.Is(invalidErr, services.ErrInvalidPeriod) {
+		t.Errorf("Se esperaba ErrInvalidPeriod, se obtuvo: %v", invalidErr)
+	}
+}
@@ -0,0 +1,121 @@
+package services_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/sigfox"
+)
+
+const testSigfoxYAML = `
+token: secreto
+devices:
+  - id: 1a2b3c
+    tank_id: tanque-1
+    type: ultrasonic
+    empty_distance: 2000
+    liters_per_mm: 5
+  - id: 4D5E6F
+    tank_id: tanque-2
+    type: level
+`
+
+func TestSigfox_DecodeFrames(t *testing.T) {
+	// Arrange
+	config, err := sigfox.Parse(strings.NewReader(testSigfoxYAML))
+	if err != nil {
+		t.Fatalf("Error inesperado al leer los equipos: %v", err)
+	}
+
+	// Act
+	// 0x01F4 = 500 mm hasta el líquido, 0xFB = -5 °C, 0xA0 = 160 * 20 mV
+	ultrasonic, err := config.Devices["1A2B3C"].Measurement(&sigfox.Callback{
+		Device: "1a2b3c", Data: "01f4fba0", Time: 1714557600,
+		RSSI: sigfox.Number{Value: -121, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("Error inesperado al decodificar la trama ultrasónica: %v", err)
+	}
+	// 0x00002710 = 1000,0 L, 0x00D7 = 21,5 °C, sin batería
+	level, err := config.Devices["4D5E6F"].Measurement(&sigfox.Callback{Device: "4D5E6F", Data: "0000271000d7"})
+	if err != nil {
+		t.Fatalf("Error inesperado al decodificar la trama de nivel: %v", err)
+	}
+	_, shortErr := config.Devices["1A2B3C"].Measurement(&sigfox.Callback{Data: "01f4"})
+	_, hexErr := config.Devices["1A2B3C"].Measurement(&sigfox.Callback{Data: "zz"})
+
+	// Assert
+	if ultrasonic.TankID != "tanque-1" || ultrasonic.SensorID != "1A2B3C" {
+		t.Errorf("Tanque o sensor incorrectos: %+v", ultrasonic)
+	}
+	if ultrasonic.Level != 7500 || ultrasonic.Temperature != -5 {
+		t.Errorf("Nivel o temperatura incorrectos. Esperado: 7500 L y -5 °C, Obtenido: %.2f L y %.2f °C", ultrasonic.Level, ultrasonic.Temperature)
+	}
+	if ultrasonic.BatteryVoltage == nil || *ultrasonic.BatteryVoltage < 3.199 || *ultrasonic.BatteryVoltage > 3.201 {
+		t.Errorf("Voltaje incorrecto: %v", ultrasonic.BatteryVoltage)
+	}
+	if ultrasonic.SignalStrength == nil || *ultrasonic.SignalStrength != -121 {
+		t.Errorf("RSSI incorrecto: %v", ultrasonic.SignalStrength)
+	}
+	if !ultrasonic.Timestamp.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Marca de tiempo incorrecta: %v", ultrasonic.Timestamp)
+	}
+	if level.Level != 1000 || level.Temperature != 21.5 || level.BatteryVoltage != nil {
+		t.Errorf("Medición de nivel incorrecta: %+v", level)
+	}
+	if !errors.Is(shortErr, sigfox.ErrInvalidFrame) || !errors.Is(hexErr, sigfox.ErrInvalidFrame) {
+		t.Errorf("Se esperaba ErrInvalidFrame, se obtuvo: %v y %v", shortErr, hexErr)
+	}
+}
+
+func TestSigfox_Deduplicator(t *testing.T) {
+	// Arrange
+	deduplicator := sigfox.NewDeduplicator(10 * time.Minute)
+	now := time.Now()
+
+	// Act & Assert
+	if !deduplicator.Claim("1A2B3C", 42, now) {
+		t.Fatal("La primera trama debería aceptarse")
+	}
+	if deduplicator.Claim("1A2B3C", 42, now.Add(2*time.Second)) {
+		t.Error("La trama repetida debería descartarse")
+	}
+	if !deduplicator.Claim("4D5E6F", 42, now) {
+		t.Error("La misma secuencia de otro equipo no es un duplicado")
+	}
+
+	deduplicator.Release("1A2B3C", 42)
+	if !deduplicator.Claim("1A2B3C", 42, now.Add(3*time.Second)) {
+		t.Error("Una trama liberada debería poder reintentarse")
+	}
+
+	// Los números de secuencia se reutilizan pasada la ventana
+	if !deduplicator.Claim("1A2B3C", 42, now.Add(11*time.Minute)) {
+		t.Error("La trama debería aceptarse fuera de la ventana")
+	}
+}
+
+func TestSigfox_ParseRejectsInvalidConfig(t *testing.T) {
+	cases := map[string]string{
+		"sin token":        "devices:\n  - {id: 1A, tank_id: t, type: level}\n",
+		"id inválido":      "token: x\ndevices:\n  - {id: equipo-1, tank_id: t, type: level}\n",
+		"sin tanque":       "token: x\ndevices:\n  - {id: 1A, type: level}\n",
+		"tipo desconocido": "token: x\ndevices:\n  - {id: 1A, tank_id: t, type: radar}\n",
+		"sin conversión":   "token: x\ndevices:\n  - {id: 1A, tank_id: t, type: ultrasonic}\n",
+		"duplicado":        "token: x\ndevices:\n  - {id: 1A, tank_id: t, type: level}\n  - {id: 1a, tank_id: u, type: level}\n",
+	}
+
+	for name, document := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := sigfox.Parse(strings.NewReader(document))
+
+			// Assert
+			if !errors.Is(err, sigfox.ErrInvalidConfig) {
+				t.Errorf("Se esperaba ErrInvalidConfig, se obtuvo: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/auth"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+	"monitor-tanques/pkg/logger"
+)
+
+// rejectingAuthenticator rechaza cualquier token: las pruebas solo usan enlaces firmados
+type rejectingAuthenticator struct{}
+
+func (rejectingAuthenticator) Authenticate(ctx context.Context, token string) (*domain.Principal, error) {
+	return nil, auth.ErrInvalidToken
+}
+
+var _ ports.Authenticator = rejectingAuthenticator{}
+
+func TestHMACURLSigner_SignAndVerify(t *testing.T) {
+	// Arrange
+	signer := auth.NewHMACURLSigner([]byte("clave-de-prueba"))
+	principal := &domain.Principal{Subject: "u-1", Name: "Ana", Roles: []string{domain.RoleViewer}, Organization: "acme"}
+	target, _ := url.Parse("/api/tanks/tanque-1/measurements?format=ndjson&from=2026-01-01T00:00:00Z")
+	now := time.Now()
+
+	// Act
+	signed := signer.Sign(target, principal, now.Add(time.Hour))
+	verified, err := signer.Verify(signed, now)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error al verificar: %v", err)
+	}
+	if verified.Subject != "u-1" || verified.Organization != "acme" || verified.Source != auth.SignedURLSource {
+		t.Errorf("Principal incorrecto: %+v", verified)
+	}
+	if signed.Query().Get("format") != "ndjson" {
+		t.Errorf("El enlace debería conservar los parámetros: %s", signed)
+	}
+
+	if _, err := signer.Verify(signed, now.Add(2*time.Hour)); !errors.Is(err, auth.ErrSignatureExpired) {
+		t.Errorf("Se esperaba ErrSignatureExpired, se obtuvo %v", err)
+	}
+
+	tampered := *signed
+	tampered.RawQuery = strings.Replace(signed.RawQuery, "format=ndjson", "format=json", 1)
+	if _, err := signer.Verify(&tampered, now); !errors.Is(err, auth.ErrInvalidSignature) {
+		t.Errorf("Se esperaba ErrInvalidSignature al cambiar un parámetro, se obtuvo %v", err)
+	}
+
+	otherPath := *signed
+	otherPath.Path = "/api/tanks/tanque-2/measurements"
+	if _, err := signer.Verify(&otherPath, now); !errors.Is(err, auth.ErrInvalidSignature) {
+		t.Errorf("Se esperaba ErrInvalidSignature al cambiar la ruta, se obtuvo %v", err)
+	}
+
+	if _, err := auth.NewHMACURLSigner([]byte("otra-clave")).Verify(signed, now); !errors.Is(err, auth.ErrInvalidSignature) {
+		t.Errorf("Se esperaba ErrInvalidSignature con otra clave, se obtuvo %v", err)
+	}
+}
+
+func TestSignedURLService_SignURL(t *testing.T) {
+	// Arrange
+	signer := auth.NewHMACURLSigner([]byte("clave-de-prueba"))
+	paths := []string{"/api/tanks/{id}/measurements", "/api/admin/usage/export"}
+	service, err := services.NewSignedURLService(signer, paths, "https://tanques.example.com", time.Hour, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("No se esperaba error al crear el servicio: %v", err)
+	}
+	ctx := domain.ContextWithPrincipal(context.Background(), &domain.Principal{Subject: "u-1", Roles: []string{domain.RoleViewer}})
+
+	// Act
+	signed, err := service.SignURL(ctx, "/api/v1/tanks/tanque-1/measurements?format=ndjson", 0)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("No se esperaba error al firmar: %v", err)
+	}
+	if !strings.HasPrefix(signed.URL, "https://tanques.example.com/api/v1/tanks/tanque-1/measurements?") {
+		t.Errorf("URL incorrecta: %s", signed.URL)
+	}
+	if remaining := time.Until(signed.ExpiresAt); remaining <= 59*time.Minute || remaining > time.Hour {
+		t.Errorf("Se esperaba la vigencia por defecto de una hora, quedan %s", remaining)
+	}
+
+	parsed, _ := url.Parse(signed.URL)
+	if principal, err := signer.Verify(parsed, time.Now()); err != nil || principal.Subject != "u-1" {
+		t.Errorf("El enlace debería verificarse con el principal que lo firmó: %+v, %v", principal, err)
+	}
+
+	for _, target := range []string{"/api/tanks/tanque-1", "https://otro.example.com/api/admin/usage/export", "/api/tanks//measurements"} {
+		if _, err := service.SignURL(ctx, target, 0); !errors.Is(err, services.ErrInvalidSignedURL) {
+			t.Errorf("Se esperaba ErrInvalidSignedURL para %s, se obtuvo %v", target, err)
+		}
+	}
+	if _, err := service.SignURL(ctx, "/api/admin/usage/export", 48*time.Hour); !errors.Is(err, services.ErrInvalidSignedURL) {
+		t.Errorf("Se esperaba ErrInvalidSignedURL con una vigencia mayor que la máxima, se obtuvo %v", err)
+	}
+}
+
+func TestSignedURLMiddleware_KeepsSignerRoles(t *testing.T) {
+	// Arrange
+	signer := auth.NewHMACURLSigner([]byte("clave-de-prueba"))
+	viewer := &domain.Principal{Subject: "u-1", Roles: []string{domain.RoleViewer}}
+	var seen *domain.Principal
+	handler := auth.SignedURLMiddleware(signer, logger.NewSimpleLogger())(
+		auth.Middleware(rejectingAuthenticator{}, nil, nil, logger.NewSimpleLogger())(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = domain.PrincipalFromContext(r.Context())
+			}),
+		),
+	)
+	request := func(method, path string) int {
+		target, _ := url.Parse(path)
+		signed := signer.Sign(target, viewer, time.Now().Add(time.Hour))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, signed.String(), nil))
+		return recorder.Code
+	}
+
+	// Act & Assert
+	if code := request(http.MethodGet, "/api/tanks/tanque-1/measurements"); code != http.StatusOK || seen == nil || seen.Subject != "u-1" {
+		t.Errorf("El enlace del viewer debería autorizar la consulta: %d, %+v", code, seen)
+	}
+	if code := request(http.MethodGet, "/api/admin/usage/export"); code != http.StatusForbidden {
+		t.Errorf("El enlace de un viewer no debería dar acceso a administración: %d", code)
+	}
+	if code := request(http.MethodDelete, "/api/tanks/tanque-1/measurements"); code != http.StatusForbidden {
+		t.Errorf("Los enlaces firmados solo deberían valer para consultas: %d", code)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/tanks/tanque-1/measurements?signature=falsa", nil))
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("Una firma falsa debería responder 403, se obtuvo %d", recorder.Code)
+	}
+}
@@ -0,0 +1,110 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestGenerateSyntheticSeries(t *testing.T) {
+	// Arrange: tanque de 1000 L al 50 % con umbral crítico del 10 % (100 L)
+	tank := createTestTank()
+	to := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+	random := rand.New(rand.NewSource(1))
+
+	// Act
+	consumption := domain.GenerateSyntheticSeries(tank, domain.SyntheticProfileConsumption, to, 24*time.Hour, 10*time.Minute, random)
+	refill := domain.GenerateSyntheticSeries(tank, domain.SyntheticProfileRefill, to, 24*time.Hour, 10*time.Minute, random)
+	leak := domain.GenerateSyntheticSeries(tank, domain.SyntheticProfileLeak, to, 6*time.Hour, 5*time.Minute, random)
+
+	// Assert
+	if len(consumption) != 144 || !consumption[len(consumption)-1].Timestamp.Equal(to) || !consumption[0].Timestamp.Equal(to.Add(-23*time.Hour-50*time.Minute)) {
+		t.Fatalf("Serie de consumo mal espaciada: %d lecturas", len(consumption))
+	}
+	for _, m := range consumption {
+		if m.TankID != tank.ID || m.SensorID != domain.SyntheticSensorID {
+			t.Fatalf("Lectura sintética sin tanque o sensor: %+v", m)
+		}
+	}
+	// Un día completo consume en torno al 15 % de la capacidad, casi todo de día
+	if used := tank.CurrentLevel - consumption[len(consumption)-1].Level; used < 130 || used > 170 {
+		t.Errorf("Consumo diario fuera de lo esperado: %.1f L", used)
+	}
+	night := consumption[5].Level - consumption[35].Level // 00:00 a 05:00
+	day := consumption[65].Level - consumption[95].Level  // 11:00 a 16:00
+	if night >= day/2 {
+		t.Errorf("El consumo nocturno (%.1f L) debería ser muy inferior al diurno (%.1f L)", night, day)
+	}
+
+	minLevel := tank.Capacity
+	for _, m := range refill {
+		if m.Level < minLevel {
+			minLevel = m.Level
+		}
+	}
+	if minLevel > 100 || refill[len(refill)-1].Level < 800 {
+		t.Errorf("El reabastecimiento debería bajar del nivel crítico y volver a llenar el tanque: mínimo %.1f L, final %.1f L",
+			minLevel, refill[len(refill)-1].Level)
+	}
+
+	if end := leak[len(leak)-1].Level; end > 100 {
+		t.Errorf("La fuga debería terminar en nivel crítico, terminó en %.1f L", end)
+	}
+}
+
+func TestSimulationService_SimulateTank(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	notifier := &MockAlertNotifier{}
+	tankService := newTestTankService(tankRepo, measurementRepo, notifier)
+	service := services.NewSimulationService(tankService)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act
+	series, err := service.SimulateTank(ctx, tank.ID, domain.SyntheticProfileLeak, 2*time.Hour, time.Minute)
+	_, profileErr := service.SimulateTank(ctx, tank.ID, "flood", time.Hour, time.Minute)
+	_, intervalErr := service.SimulateTank(ctx, tank.ID, domain.SyntheticProfileConsumption, time.Minute, time.Hour)
+	_, tooManyErr := service.SimulateTank(ctx, tank.ID, domain.SyntheticProfileConsumption, 30*24*time.Hour, time.Minute)
+	_, missingErr := service.SimulateTank(ctx, "no-existe", domain.SyntheticProfileConsumption, time.Hour, time.Minute)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al generar la serie: %v", err)
+	}
+	if series.Measurements != 120 || series.Interval != 60 || series.MinLevel > series.StartLevel {
+		t.Errorf("Resumen de la serie incorrecto: %+v", series)
+	}
+
+	measurements, _ := measurementRepo.GetMeasurementsByTankID(ctx, tank.ID, 0)
+	if len(measurements) != 120 {
+		t.Errorf("Se esperaban 120 mediciones guardadas, hay %d", len(measurements))
+	}
+	updated, _ := tankService.GetTank(ctx, tank.ID)
+	if updated.CurrentLevel != series.EndLevel || updated.Status != "critical" {
+		t.Errorf("El tanque debería quedar con la última lectura de la fuga: nivel %.1f, estado %s", updated.CurrentLevel, updated.Status)
+	}
+	if notifier.AlertsSent == 0 {
+		t.Error("La fuga debería disparar la alerta de nivel crítico")
+	}
+
+	for name, err := range map[string]error{"perfil": profileErr, "intervalo": intervalErr, "demasiadas lecturas": tooManyErr} {
+		if !errors.Is(err, services.ErrInvalidSimulation) {
+			t.Errorf("%s: se esperaba ErrInvalidSimulation, se obtuvo %v", name, err)
+		}
+	}
+	if missingErr == nil {
+		t.Error("Se esperaba un error con un tanque inexistente")
+	}
+}
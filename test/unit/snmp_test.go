@@ -0,0 +1,241 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/adapters/snmp"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/pkg/logger"
+)
+
+const (
+	testLevelOID       = "1.3.6.1.4.1.99999.1.1"
+	testTemperatureOID = "1.3.6.1.4.1.99999.1.2"
+)
+
+// newTestSNMPCollector crea un colector para un agente en address con un tanque asociado
+func newTestSNMPCollector(t *testing.T, address string) (*snmp.Collector, *snmp.Config, ports.TankService) {
+	t.Helper()
+
+	config, err := snmp.Parse(strings.NewReader(`
+targets:
+  - name: consola-norte
+    address: ` + address + `
+    community: secreta
+    tanks:
+      - tank_id: tanque-snmp
+        level_oid: .` + testLevelOID + `
+        level_scale: 0.1
+        temperature_oid: ` + testTemperatureOID + `
+`))
+	if err != nil {
+		t.Fatalf("Error inesperado al leer la configuración SNMP: %v", err)
+	}
+
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	if err := tankService.CreateTank(context.Background(), &domain.Tank{ID: "tanque-snmp", Name: "Tanque SNMP", Capacity: 5000, CurrentLevel: 4000, AlertThreshold: 10}); err != nil {
+		t.Fatalf("Error inesperado al crear el tanque: %v", err)
+	}
+
+	collector := snmp.NewCollector(tankService, config, snmp.NewClient(time.Second), logger.NewSimpleLogger())
+	return collector, config, tankService
+}
+
+func TestSNMP_PacketRoundTrip(t *testing.T) {
+	// Arrange
+	packet := &snmp.Packet{
+		Version:   snmp.Version2c,
+		Community: "public",
+		PDUType:   snmp.PDUGetResponse,
+		RequestID: 1234567,
+		Varbinds: []snmp.Varbind{
+			{OID: testLevelOID, Type: snmp.TagGauge32, Value: uint64(4294967295)},
+			{OID: "1.3.6.1.2.1.1.3.0", Type: snmp.TagInteger, Value: int64(-129)},
+			{OID: testTemperatureOID, Type: snmp.TagOctetString, Value: []byte(" 21.5 ")},
+			{OID: "1.3.6.1.6.3.1.1.4.1.0", Type: snmp.TagOID, Value: "1.3.6.1.4.1.99999.0.1"},
+		},
+	}
+	trap := &snmp.Packet{
+		Version:      snmp.Version1,
+		Community:    "public",
+		PDUType:      snmp.PDUTrapV1,
+		Enterprise:   "1.3.6.1.4.1.99999",
+		AgentAddr:    net.IPv4(10, 0, 0, 5),
+		GenericTrap:  6,
+		SpecificTrap: 1,
+		Timestamp:    300,
+	}
+
+	// Act
+	encoded, err := packet.Marshal()
+	if err != nil {
+		t.Fatalf("Error inesperado al codificar: %v", err)
+	}
+	decoded, err := snmp.ParsePacket(encoded)
+	if err != nil {
+		t.Fatalf("Error inesperado al decodificar: %v", err)
+	}
+	encodedTrap, _ := trap.Marshal()
+	decodedTrap, err := snmp.ParsePacket(encodedTrap)
+	if err != nil {
+		t.Fatalf("Error inesperado al decodificar el trap: %v", err)
+	}
+	_, truncatedErr := snmp.ParsePacket(encoded[:len(encoded)-3])
+
+	// Assert
+	if decoded.RequestID != 1234567 || decoded.Community != "public" || len(decoded.Varbinds) != 4 {
+		t.Fatalf("Paquete decodificado incorrecto: %+v", decoded)
+	}
+	if value, ok := decoded.Varbinds[0].Float(); !ok || value != 4294967295 {
+		t.Errorf("Gauge32 incorrecto: %v", decoded.Varbinds[0].Value)
+	}
+	if value, ok := decoded.Varbinds[1].Float(); !ok || value != -129 {
+		t.Errorf("Entero negativo incorrecto: %v", decoded.Varbinds[1].Value)
+	}
+	if value, ok := decoded.Varbinds[2].Float(); !ok || value != 21.5 {
+		t.Errorf("Texto numérico incorrecto: %v", decoded.Varbinds[2].Value)
+	}
+	if decoded.Varbinds[3].Value != "1.3.6.1.4.1.99999.0.1" {
+		t.Errorf("OID incorrecto: %v", decoded.Varbinds[3].Value)
+	}
+	if decodedTrap.Enterprise != "1.3.6.1.4.1.99999" || !decodedTrap.AgentAddr.Equal(net.IPv4(10, 0, 0, 5)) || decodedTrap.SpecificTrap != 1 || decodedTrap.Timestamp != 300 {
+		t.Errorf("Trap decodificado incorrecto: %+v", decodedTrap)
+	}
+	if !errors.Is(truncatedErr, snmp.ErrMalformed) {
+		t.Errorf("Se esperaba ErrMalformed con un paquete truncado, se obtuvo: %v", truncatedErr)
+	}
+}
+
+func TestSNMP_PollSavesMeasurements(t *testing.T) {
+	// Arrange: un agente que responde a las consultas con la comunidad correcta
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error al crear el agente: %v", err)
+	}
+	defer agent.Close()
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, addr, err := agent.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			request, err := snmp.ParsePacket(buf[:n])
+			if err != nil || request.Community != "secreta" {
+				continue
+			}
+			response := *request
+			response.PDUType = snmp.PDUGetResponse
+			response.Varbinds = []snmp.Varbind{
+				{OID: testLevelOID, Type: snmp.TagGauge32, Value: uint64(32505)},
+				{OID: testTemperatureOID, Type: snmp.TagOctetString, Value: []byte("18.5")},
+			}
+			encoded, _ := response.Marshal()
+			agent.WriteTo(encoded, addr)
+		}
+	}()
+
+	collector, config, tankService := newTestSNMPCollector(t, agent.LocalAddr().String())
+
+	// Act
+	err = collector.Poll(context.Background(), config.Targets[0])
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error inesperado al sondear el agente: %v", err)
+	}
+	tank, _ := tankService.GetTank(context.Background(), "tanque-snmp")
+	if tank.CurrentLevel != 3250.5 || tank.Temperature != 18.5 {
+		t.Errorf("Lectura incorrecta. Esperado: 3250.5 L y 18.5 °C, Obtenido: %.2f L y %.2f °C", tank.CurrentLevel, tank.Temperature)
+	}
+}
+
+func TestSNMP_TrapsSaveMeasurements(t *testing.T) {
+	// Arrange
+	collector, _, tankService := newTestSNMPCollector(t, "127.0.0.1")
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error al escuchar los traps: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- collector.ServeTraps(ctx, conn) }()
+
+	agent, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("Error al conectar con el receptor: %v", err)
+	}
+	defer agent.Close()
+
+	send := func(community string, level uint64, pduType byte) {
+		t.Helper()
+		trap := &snmp.Packet{
+			Version:   snmp.Version2c,
+			Community: community,
+			PDUType:   pduType,
+			RequestID: 7,
+			Varbinds:  []snmp.Varbind{{OID: testLevelOID, Type: snmp.TagGauge32, Value: level}},
+		}
+		encoded, _ := trap.Marshal()
+		if _, err := agent.Write(encoded); err != nil {
+			t.Fatalf("Error al enviar el trap: %v", err)
+		}
+	}
+
+	// Act
+	send("otra", 1000, snmp.PDUTrapV2) // Comunidad incorrecta: se descarta
+	send("secreta", 15000, snmp.PDUInform)
+
+	// Assert: el inform se confirma después de guardar la medición
+	agent.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 2048)
+	n, err := agent.Read(buf)
+	if err != nil {
+		t.Fatalf("No se recibió la confirmación del inform: %v", err)
+	}
+	if response, err := snmp.ParsePacket(buf[:n]); err != nil || response.PDUType != snmp.PDUGetResponse || response.RequestID != 7 {
+		t.Errorf("Confirmación incorrecta: %+v, %v", response, err)
+	}
+
+	tank, _ := tankService.GetTank(context.Background(), "tanque-snmp")
+	if tank.CurrentLevel != 1500 {
+		t.Errorf("Nivel incorrecto. Esperado: 1500, Obtenido: %.2f", tank.CurrentLevel)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("El receptor debería terminar sin error al cancelar: %v", err)
+	}
+}
+
+func TestSNMP_ParseRejectsInvalidConfig(t *testing.T) {
+	cases := map[string]string{
+		"sin dirección":   "targets:\n  - name: a\n    tanks: [{tank_id: t, level_oid: 1.3.6.1}]\n",
+		"sin tanques":     "targets:\n  - name: a\n    address: 10.0.0.1\n",
+		"sin nivel":       "targets:\n  - name: a\n    address: 10.0.0.1\n    tanks: [{tank_id: t}]\n",
+		"oid inválido":    "targets:\n  - name: a\n    address: 10.0.0.1\n    tanks: [{tank_id: t, level_oid: nivel}]\n",
+		"versión":         "targets:\n  - name: a\n    address: 10.0.0.1\n    version: 3\n    tanks: [{tank_id: t, level_oid: 1.3.6.1}]\n",
+		"duplicado":       "targets:\n  - {name: a, address: 10.0.0.1, tanks: [{tank_id: t, level_oid: 1.3.6.1}]}\n  - {name: a, address: 10.0.0.2, tanks: [{tank_id: u, level_oid: 1.3.6.1}]}\n",
+		"campo erróneo":   "targets:\n  - name: a\n    address: 10.0.0.1\n    intervalo: 5m\n    tanks: [{tank_id: t, level_oid: 1.3.6.1}]\n",
+		"nombre inválido": "targets:\n  - name: Consola Norte\n    address: 10.0.0.1\n    tanks: [{tank_id: t, level_oid: 1.3.6.1}]\n",
+	}
+
+	for name, document := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := snmp.Parse(strings.NewReader(document))
+
+			// Assert
+			if !errors.Is(err, snmp.ErrInvalidConfig) {
+				t.Errorf("Se esperaba ErrInvalidConfig, se obtuvo: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/core/domain"
+)
+
+func TestBuildStatusHistory_TimeInStatus(t *testing.T) {
+	// Arrange: 2h en normal, 1h en critical y 1h en warning dentro del periodo
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(4 * time.Hour)
+
+	changes := []*domain.StatusChange{
+		{TankID: "t1", FromStatus: "", ToStatus: "normal", ChangedAt: from.Add(-time.Hour)},
+		{TankID: "t1", FromStatus: "normal", ToStatus: "critical", ChangedAt: from.Add(2 * time.Hour)},
+		{TankID: "t1", FromStatus: "critical", ToStatus: "warning", ChangedAt: from.Add(3 * time.Hour)},
+	}
+
+	// Act
+	history := domain.BuildStatusHistory("t1", changes, from, to)
+
+	// Assert
+	if len(history.Changes) != 2 {
+		t.Fatalf("Se esperaban 2 transiciones en el periodo, se obtuvieron %d", len(history.Changes))
+	}
+
+	expected := map[string]float64{"normal": 7200, "critical": 3600, "warning": 3600}
+	for status, seconds := range expected {
+		if history.TimeInStatus[status] != seconds {
+			t.Errorf("Tiempo en %s incorrecto. Esperado: %.0f, Obtenido: %.0f", status, seconds, history.TimeInStatus[status])
+		}
+	}
+
+	if history.Availability != 75.0 {
+		t.Errorf("Disponibilidad incorrecta. Esperado: %.2f, Obtenido: %.2f", 75.0, history.Availability)
+	}
+}
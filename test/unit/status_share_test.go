@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestStatusShare_PublishesOnlySelectedTanks(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	tankRepo := repositories.NewMemoryTankRepository()
+	tankService := newTestTankService(tankRepo, repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	shared := createTestTank()
+	shared.Name = "Tanque Compartido"
+	private := createTestTank()
+	private.ID = "tanque-privado"
+	for _, tank := range []*domain.Tank{shared, private} {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque: %v", err)
+		}
+	}
+	shareRepo := repositories.NewMemoryStatusShareRepository()
+	shareService := services.NewStatusShareService(tankService, shareRepo)
+
+	// Act
+	share, err := shareService.CreateShare(ctx, &domain.StatusShare{Name: " Cliente Acme ", TankIDs: []string{shared.ID, shared.ID}})
+	if err != nil {
+		t.Fatalf("Error inesperado al crear la página: %v", err)
+	}
+	page, err := shareService.GetPublicStatus(ctx, share.Token)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error inesperado al consultar la página: %v", err)
+	}
+	if page.Name != "Cliente Acme" || len(page.Tanks) != 1 {
+		t.Fatalf("Página incorrecta: %+v", page)
+	}
+	if page.Tanks[0].Name != "Tanque Compartido" || page.Tanks[0].Percentage != 50 {
+		t.Errorf("Estado del tanque incorrecto: %+v", page.Tanks[0])
+	}
+
+	// Solo se guarda el hash del token
+	stored, _ := shareRepo.GetShare(ctx, share.ID)
+	if stored.Token != "" || stored.TokenHash != domain.HashShareToken(share.Token) {
+		t.Errorf("El token no debería guardarse en claro: %+v", stored)
+	}
+
+	if _, err := shareService.GetPublicStatus(ctx, "token-inventado"); !errors.Is(err, services.ErrStatusShareNotFound) {
+		t.Errorf("Se esperaba ErrStatusShareNotFound con un token desconocido, se obtuvo: %v", err)
+	}
+	if _, err := shareService.RevokeShare(ctx, share.ID); err != nil {
+		t.Fatalf("Error inesperado al revocar la página: %v", err)
+	}
+	if _, err := shareService.GetPublicStatus(ctx, share.Token); !errors.Is(err, services.ErrStatusShareNotFound) {
+		t.Errorf("Una página revocada no debería consultarse, se obtuvo: %v", err)
+	}
+}
+
+func TestStatusShare_Validation(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque: %v", err)
+	}
+	shareService := services.NewStatusShareService(tankService, repositories.NewMemoryStatusShareRepository())
+	past := time.Now().Add(-time.Hour)
+
+	invalid := []*domain.StatusShare{
+		{Name: "", TankIDs: []string{tank.ID}},
+		{Name: "Sin tanques"},
+		{Name: "Tanque inexistente", TankIDs: []string{"no-existe"}},
+		{Name: "Vencida", TankIDs: []string{tank.ID}, ExpiresAt: &past},
+	}
+
+	for _, share := range invalid {
+		// Act
+		_, err := shareService.CreateShare(ctx, share)
+
+		// Assert
+		if !errors.Is(err, services.ErrInvalidStatusShare) {
+			t.Errorf("Se esperaba ErrInvalidStatusShare para %q, se obtuvo: %v", share.Name, err)
+		}
+	}
+	if _, err := shareService.RevokeShare(ctx, "no-existe"); !errors.Is(err, services.ErrStatusShareNotFound) {
+		t.Errorf("Se esperaba ErrStatusShareNotFound, se obtuvo: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+// recordingWebhookSender registra los eventos enviados a los webhooks de los tanques
+type recordingWebhookSender struct {
+	urls   []string
+	events []*domain.StatusWebhookEvent
+}
+
+func (s *recordingWebhookSender) SendStatusWebhook(ctx context.Context, url string, event *domain.StatusWebhookEvent) {
+	s.urls = append(s.urls, url)
+	s.events = append(s.events, event)
+}
+
+func TestStatusWebhookTankService_NotifiesStatusTransitions(t *testing.T) {
+	// Arrange
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	sender := &recordingWebhookSender{}
+	tankService := services.NewStatusWebhookTankService(
+		newTestTankService(repositories.NewMemoryTankRepository(), measurementRepo, &MockAlertNotifier{}),
+		measurementRepo,
+		sender,
+	)
+	ctx := context.Background()
+
+	withWebhook := createTestTank()
+	withWebhook.StatusWebhookURL = "https://example.com/hooks/tanque"
+	withoutWebhook := createTestTank()
+	tankService.CreateTank(ctx, withWebhook)
+	tankService.CreateTank(ctx, withoutWebhook)
+
+	// Act
+	tankService.AddMeasurement(ctx, createTestMeasurement(withWebhook.ID, 450))
+	tankService.AddMeasurement(ctx, createTestMeasurement(withWebhook.ID, 50))
+	tankService.AddMeasurements(ctx, []*domain.Measurement{
+		createTestMeasurement(withWebhook.ID, 60),
+		createTestMeasurement(withoutWebhook.ID, 50),
+	})
+
+	// Assert
+	if len(sender.events) != 1 {
+		t.Fatalf("Se esperaba 1 aviso por el único cambio de estado, se enviaron %d", len(sender.events))
+	}
+	event := sender.events[0]
+	if sender.urls[0] != withWebhook.StatusWebhookURL || event.Event != domain.StatusWebhookEventType {
+		t.Errorf("Aviso enviado a %q con el evento %q", sender.urls[0], event.Event)
+	}
+	if event.TankID != withWebhook.ID || event.OldStatus != "normal" || event.NewStatus != "critical" {
+		t.Errorf("Transición incorrecta: %+v", event)
+	}
+	if event.Measurement == nil || event.Measurement.Level != 50 {
+		t.Errorf("El aviso debe incluir la última medición: %+v", event.Measurement)
+	}
+}
+
+func TestTankService_RejectsInvalidStatusWebhookURL(t *testing.T) {
+	// Arrange
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	ctx := context.Background()
+
+	for _, url := range []string{"ftp://example.com/hook", "/hooks/tanque", "https://"} {
+		tank := createTestTank()
+		tank.StatusWebhookURL = url
+
+		// Act
+		err := tankService.CreateTank(ctx, tank)
+
+		// Assert
+		if !errors.Is(err, services.ErrInvalidTank) {
+			t.Errorf("Se esperaba ErrInvalidTank con la URL %q, se obtuvo: %v", url, err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+)
+
+// newApprovalTestServices crea un TankService que exige aprobación en el sitio "regulado" y el
+// servicio que revisa sus solicitudes
+func newApprovalTestServices() (ports.TankService, ports.TankChangeService, *repositories.MemoryTankChangeRepository) {
+	changeRepo := repositories.NewMemoryTankChangeRepository()
+	inner := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	tankService := services.NewApprovalTankService(inner, changeRepo, []string{"regulado"})
+	return tankService, services.NewTankChangeService(tankService, changeRepo), changeRepo
+}
+
+func TestApprovalTankService_StagesOperatorChanges(t *testing.T) {
+	// Arrange
+	tankService, _, changeRepo := newApprovalTestServices()
+	ctx := context.Background()
+	operatorCtx := domain.ContextWithPrincipal(ctx, &domain.Principal{Subject: "u1", Name: "Operador", Roles: []string{domain.RoleOperator}})
+
+	tank := createTestTank()
+	tank.SiteID = "regulado"
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	first := *tank
+	first.AlertThreshold = 5
+	second := *tank
+	second.Name = "Tanque Renombrado"
+	second.AlertThreshold = 8
+	second.Capacity = 1200
+
+	// Act
+	firstErr := tankService.UpdateTank(operatorCtx, &first)
+	secondErr := tankService.UpdateTank(operatorCtx, &second)
+
+	// Assert
+	var pending *services.PendingApprovalError
+	if !errors.As(firstErr, &pending) || !errors.As(secondErr, &pending) {
+		t.Fatalf("Se esperaba PendingApprovalError, se obtuvo %v y %v", firstErr, secondErr)
+	}
+	change := pending.Change
+	if change.Status != domain.TankChangePending || change.RequestedBy != "Operador" || change.SiteID != "regulado" || len(change.Changes) != 2 {
+		t.Errorf("Solicitud incorrecta: %+v", change)
+	}
+
+	stored, _ := tankService.GetTank(ctx, tank.ID)
+	if stored.Name != "Tanque Renombrado" {
+		t.Errorf("El resto de la actualización debería aplicarse, nombre: %q", stored.Name)
+	}
+	if stored.AlertThreshold != 10 || stored.Capacity != 1000 {
+		t.Errorf("El umbral y la capacidad deberían esperar la aprobación: %v, %v", stored.AlertThreshold, stored.Capacity)
+	}
+
+	pendingChanges, _ := changeRepo.GetTankChanges(ctx, domain.TankChangeFilter{Status: domain.TankChangePending})
+	superseded, _ := changeRepo.GetTankChanges(ctx, domain.TankChangeFilter{Status: domain.TankChangeSuperseded})
+	if len(pendingChanges) != 1 || len(superseded) != 1 {
+		t.Errorf("Se esperaba una solicitud pendiente y otra reemplazada: %d, %d", len(pendingChanges), len(superseded))
+	}
+}
+
+func TestApprovalTankService_PassesThrough(t *testing.T) {
+	// Arrange
+	tankService, _, changeRepo := newApprovalTestServices()
+	ctx := context.Background()
+	adminCtx := domain.ContextWithPrincipal(ctx, &domain.Principal{Subject: "admin", Roles: []string{domain.RoleAdmin}})
+	operatorCtx := domain.ContextWithPrincipal(ctx, &domain.Principal{Subject: "u1", Roles: []string{domain.RoleOperator}})
+
+	regulated := createTestTank()
+	regulated.SiteID = "regulado"
+	free := createTestTank()
+	free.SiteID = "libre"
+	for _, tank := range []*domain.Tank{regulated, free} {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name string
+		ctx  context.Context
+		tank *domain.Tank
+	}{
+		{"administrador", adminCtx, regulated},
+		{"sin usuario", ctx, regulated},
+		{"sitio no regulado", operatorCtx, free},
+	}
+
+	// Act & Assert
+	for _, c := range cases {
+		update := *c.tank
+		update.AlertThreshold += 5
+		if err := tankService.UpdateTank(c.ctx, &update); err != nil {
+			t.Errorf("%s: el cambio debería aplicarse directamente: %v", c.name, err)
+		}
+	}
+	changes, _ := changeRepo.GetTankChanges(ctx, domain.TankChangeFilter{})
+	if len(changes) != 0 {
+		t.Errorf("No se esperaban solicitudes de cambio, hay %d", len(changes))
+	}
+}
+
+func TestTankChangeService_ReviewChanges(t *testing.T) {
+	// Arrange
+	tankService, changeService, _ := newApprovalTestServices()
+	ctx := context.Background()
+	operatorCtx := domain.ContextWithPrincipal(ctx, &domain.Principal{Subject: "u1", Roles: []string{domain.RoleOperator}})
+	adminCtx := domain.ContextWithPrincipal(ctx, &domain.Principal{Subject: "a1", Name: "Supervisora", Roles: []string{domain.RoleAdmin}})
+
+	approved := createTestTank()
+	approved.SiteID = "regulado"
+	rejected := createTestTank()
+	rejected.SiteID = "regulado"
+	stale := createTestTank()
+	stale.SiteID = "regulado"
+	requests := make(map[string]*domain.TankChangeRequest)
+	for _, tank := range []*domain.Tank{approved, rejected, stale} {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+		update := *tank
+		update.AlertThreshold = 20
+		var pending *services.PendingApprovalError
+		if err := tankService.UpdateTank(operatorCtx, &update); !errors.As(err, &pending) {
+			t.Fatalf("Se esperaba una solicitud pendiente, se obtuvo %v", err)
+		}
+		requests[tank.ID] = pending.Change
+	}
+
+	// Un administrador cambia el umbral antes de que se revise la solicitud
+	direct := *stale
+	direct.AlertThreshold = 15
+	if err := tankService.UpdateTank(adminCtx, &direct); err != nil {
+		t.Fatalf("Error al actualizar el tanque: %v", err)
+	}
+
+	// Act
+	approvedChange, approveErr := changeService.ApproveTankChange(adminCtx, requests[approved.ID].ID, "Revisado")
+	rejectedChange, rejectErr := changeService.RejectTankChange(adminCtx, requests[rejected.ID].ID, "Incumple el plan")
+	_, staleErr := changeService.ApproveTankChange(adminCtx, requests[stale.ID].ID, "")
+	_, againErr := changeService.ApproveTankChange(adminCtx, requests[approved.ID].ID, "")
+	_, missingErr := changeService.GetTankChange(ctx, "no-existe")
+	pendingList, listErr := changeService.GetTankChanges(ctx, domain.TankChangeFilter{Status: domain.TankChangePending})
+
+	// Assert
+	if approveErr != nil || rejectErr != nil {
+		t.Fatalf("Error al revisar las solicitudes: %v, %v", approveErr, rejectErr)
+	}
+	if approvedChange.Status != domain.TankChangeApproved || approvedChange.ReviewedBy != "Supervisora" || approvedChange.ReviewedAt == nil {
+		t.Errorf("Aprobación incorrecta: %+v", approvedChange)
+	}
+	if rejectedChange.Status != domain.TankChangeRejected || rejectedChange.Comment != "Incumple el plan" {
+		t.Errorf("Rechazo incorrecto: %+v", rejectedChange)
+	}
+	if tank, _ := tankService.GetTank(ctx, approved.ID); tank.AlertThreshold != 20 {
+		t.Errorf("La aprobación debería aplicar el umbral, se obtuvo %v", tank.AlertThreshold)
+	}
+	if tank, _ := tankService.GetTank(ctx, rejected.ID); tank.AlertThreshold != 10 {
+		t.Errorf("El rechazo no debería cambiar el umbral, se obtuvo %v", tank.AlertThreshold)
+	}
+	if !errors.Is(staleErr, services.ErrTankChangeConflict) {
+		t.Errorf("Se esperaba ErrTankChangeConflict, se obtuvo %v", staleErr)
+	}
+	if !errors.Is(againErr, services.ErrTankChangeNotPending) {
+		t.Errorf("Se esperaba ErrTankChangeNotPending, se obtuvo %v", againErr)
+	}
+	if !errors.Is(missingErr, services.ErrTankChangeNotFound) {
+		t.Errorf("Se esperaba ErrTankChangeNotFound, se obtuvo %v", missingErr)
+	}
+	if listErr != nil || len(pendingList) != 1 || pendingList[0].TankID != stale.ID {
+		t.Errorf("Solo debería quedar pendiente la solicitud desactualizada: %v, %d", listErr, len(pendingList))
+	}
+}
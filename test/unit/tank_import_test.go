@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/adapters/tankimport"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+const testTankImportCSV = "ID;Name;Site ID;Capacity;Current Level;Latitude;Longitude\n" +
+	"tq-101;Diésel patio;estacion-norte;20000;12500,5;4.711;-74.0721\n" +
+	";;;;;;\n" +
+	"tq-102;Agua lavado;estacion-norte;mucha;;4.7;\n" +
+	"tq-101;Duplicado;estacion-norte;1000;;;\n"
+
+// buildTestXLSX crea un libro XLSX mínimo con una hoja que usa textos compartidos y en línea
+func buildTestXLSX(t *testing.T) []byte {
+	t.Helper()
+
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Tanques" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/tanques.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>name</t></si><si><t>capacity</t></si><si><r><t>Tanque </t></r><r><t>XLSX</t></r></si></sst>`,
+		"xl/worksheets/tanques.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>1500</v></c></row>` +
+			`<row r="3"><c r="A3" t="inlineStr"><is><t>Otro</t></is></c><c r="C3"><v>800.5</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for name, content := range files {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Error al crear el libro de prueba: %v", err)
+		}
+		writer.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Error al crear el libro de prueba: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestTankImport_ParseCSV(t *testing.T) {
+	// Act
+	rows, err := tankimport.Parse(strings.NewReader(testTankImportCSV), tankimport.FormatCSV)
+	_, unknownErr := tankimport.Parse(strings.NewReader("name,capacity,color\nA,10,rojo\n"), tankimport.FormatCSV)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al leer el CSV: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Se esperaban 3 filas (sin la vacía), se obtuvieron %d", len(rows))
+	}
+
+	first := rows[0]
+	if first.Row != 2 || first.Tank.ID != "tq-101" || first.Tank.SiteID != "estacion-norte" || first.Tank.CurrentLevel != 12500.5 {
+		t.Errorf("Primera fila mal leída: %d %+v", first.Row, first.Tank)
+	}
+	if first.Tank.Location == nil || first.Tank.Location.Longitude != -74.0721 {
+		t.Errorf("Ubicación mal leída: %+v", first.Tank.Location)
+	}
+
+	// La fila 4 tiene una capacidad no numérica y solo la latitud
+	if rows[1].Row != 4 || len(rows[1].Errors) != 2 || rows[1].Errors[0].Column != "capacity" {
+		t.Errorf("Errores inesperados en la fila 4: %+v", rows[1].Errors)
+	}
+
+	if unknownErr == nil {
+		t.Error("Se esperaba un error por la columna desconocida")
+	}
+}
+
+func TestTankImport_ParseXLSX(t *testing.T) {
+	// Act
+	rows, err := tankimport.Parse(bytes.NewReader(buildTestXLSX(t)), tankimport.FormatXLSX)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error al leer el XLSX: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Se esperaban 2 filas, se obtuvieron %d", len(rows))
+	}
+	if rows[0].Tank.Name != "Tanque XLSX" || rows[0].Tank.Capacity != 1500 {
+		t.Errorf("Primera fila mal leída: %+v", rows[0].Tank)
+	}
+	if rows[1].Tank.Name != "Otro" || rows[1].Tank.Capacity != 800.5 {
+		t.Errorf("Segunda fila mal leída: %+v", rows[1].Tank)
+	}
+}
+
+func TestTankImportService_DryRunAndImport(t *testing.T) {
+	// Arrange
+	tankService := newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{})
+	importService := services.NewTankImportService(tankService, services.NewAccessService(repositories.NewMemoryAccessGrantRepository()))
+	ctx := context.Background()
+
+	parse := func() []*domain.TankImportRow {
+		rows, err := tankimport.Parse(strings.NewReader(testTankImportCSV), tankimport.FormatCSV)
+		if err != nil {
+			t.Fatalf("Error al leer el CSV: %v", err)
+		}
+		return rows
+	}
+
+	// Act
+	plan, planErr := importService.ImportTanks(ctx, parse(), true)
+	tanksAfterPlan, _ := tankService.GetAllTanks(ctx)
+	result, importErr := importService.ImportTanks(ctx, parse(), false)
+	repeated, _ := importService.ImportTanks(ctx, parse(), true)
+
+	// Assert
+	if planErr != nil || importErr != nil {
+		t.Fatalf("Errores inesperados: %v, %v", planErr, importErr)
+	}
+	if !plan.DryRun || plan.Valid != 1 || plan.Invalid != 2 || len(tanksAfterPlan) != 0 {
+		t.Errorf("La simulación no debería crear tanques: %+v (%d tanques)", plan, len(tanksAfterPlan))
+	}
+	if result.Created != 1 || result.Invalid != 2 || result.Rows[0].Status != domain.TankImportCreated {
+		t.Errorf("Resultado inesperado de la importación: %+v", result)
+	}
+	if duplicated := result.Rows[2]; duplicated.Status != domain.TankImportInvalid || duplicated.Errors[0].Column != "id" {
+		t.Errorf("El ID repetido en el archivo debería rechazarse: %+v", duplicated)
+	}
+	if existing := repeated.Rows[0]; existing.Status != domain.TankImportInvalid || existing.Errors[0].Message != "Ya existe un tanque con ese ID" {
+		t.Errorf("Un tanque ya importado debería rechazarse: %+v", existing)
+	}
+}
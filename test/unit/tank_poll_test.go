@@ -0,0 +1,95 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/ports"
+	"monitor-tanques/internal/core/services"
+)
+
+// readingPublisher simula un equipo que responde a read_now enviando una medición
+type readingPublisher struct {
+	tankService ports.TankService
+	tankID      string
+	level       float64
+}
+
+func (p *readingPublisher) PublishCommand(ctx context.Context, command *domain.DeviceCommand) error {
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		p.tankService.AddMeasurement(context.Background(), createTestMeasurement(p.tankID, p.level))
+	}()
+	return nil
+}
+
+func TestTankPollService_PollTank(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	fieldDeviceService := services.NewFieldDeviceService(tankService, repositories.NewMemoryFieldDeviceRepository())
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	if err := tankService.AddMeasurement(ctx, createTestMeasurement(tank.ID, 400)); err != nil {
+		t.Fatalf("Error al añadir la medición: %v", err)
+	}
+
+	publisher := &readingPublisher{tankService: tankService, tankID: tank.ID, level: 900}
+	commandService := services.NewDeviceCommandService(fieldDeviceService, repositories.NewMemoryDeviceCommandRepository(), publisher)
+	pollService := services.NewTankPollService(tankService, fieldDeviceService, commandService, measurementRepo, 2*time.Second)
+
+	// Act: sin equipos registrados no hay a quién solicitar la lectura
+	_, noDeviceErr := pollService.PollTank(ctx, tank.ID)
+
+	if _, err := fieldDeviceService.SetDesiredConfig(ctx, "gw-1", tank.ID, domain.DeviceConfig{ReportingInterval: 900}); err != nil {
+		t.Fatalf("Error al registrar el equipo: %v", err)
+	}
+	measurement, err := pollService.PollTank(ctx, tank.ID)
+
+	// Assert
+	if !errors.Is(noDeviceErr, services.ErrNoPollableDevice) {
+		t.Errorf("Se esperaba ErrNoPollableDevice, se obtuvo %v", noDeviceErr)
+	}
+	if err != nil {
+		t.Fatalf("Error al solicitar la lectura: %v", err)
+	}
+	if measurement.Level != 900 {
+		t.Errorf("Se esperaba la nueva medición de 900 L, se obtuvo %.2f", measurement.Level)
+	}
+}
+
+func TestTankPollService_Timeout(t *testing.T) {
+	// Arrange: el equipo recibe el comando pero nunca responde
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	fieldDeviceService := services.NewFieldDeviceService(tankService, repositories.NewMemoryFieldDeviceRepository())
+	commandService := services.NewDeviceCommandService(fieldDeviceService, repositories.NewMemoryDeviceCommandRepository(), &MockCommandPublisher{})
+	pollService := services.NewTankPollService(tankService, fieldDeviceService, commandService, measurementRepo, 300*time.Millisecond)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+	if _, err := fieldDeviceService.SetDesiredConfig(ctx, "gw-1", tank.ID, domain.DeviceConfig{ReportingInterval: 900}); err != nil {
+		t.Fatalf("Error al registrar el equipo: %v", err)
+	}
+
+	// Act
+	_, err := pollService.PollTank(ctx, tank.ID)
+
+	// Assert
+	if !errors.Is(err, services.ErrPollTimeout) {
+		t.Errorf("Se esperaba ErrPollTimeout, se obtuvo %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+// trendMeasurements crea lecturas del tanque cada hora hasta now, la última con el último nivel
+func trendMeasurements(tankID string, now time.Time, levels ...float64) []*domain.Measurement {
+	measurements := make([]*domain.Measurement, 0, len(levels))
+	for i, level := range levels {
+		m := createTestMeasurement(tankID, level)
+		m.Timestamp = now.Add(-time.Duration(len(levels)-1-i) * time.Hour)
+		measurements = append(measurements, m)
+	}
+	return measurements
+}
+
+func TestComputeTankTrend(t *testing.T) {
+	// Arrange: tanque de 1000 L; el umbral de estable es de 5 L/h
+	tank := createTestTank()
+	now := time.Now()
+	cases := []struct {
+		name      string
+		levels    []float64
+		direction string
+		rate      float64
+	}{
+		{"vaciado", []float64{600, 560, 520, 480}, domain.TrendDraining, -40},
+		{"llenado", []float64{200, 400, 600}, domain.TrendFilling, 200},
+		{"ruido del sensor", []float64{500, 503, 499, 502}, domain.TrendStable, 0.2},
+		// La lectura de hace 5 horas queda fuera del periodo
+		{"lectura antigua", []float64{900, 500, 500, 500, 500, 500}, domain.TrendStable, 0},
+	}
+
+	// Act & Assert
+	for _, c := range cases {
+		trend := domain.ComputeTankTrend(tank, trendMeasurements(tank.ID, now, c.levels...))
+		if trend == nil || trend.Direction != c.direction || trend.RatePerHour != c.rate {
+			t.Errorf("%s: esperado %s a %.1f L/h, obtenido %+v", c.name, c.direction, c.rate, trend)
+		}
+	}
+	if trend := domain.ComputeTankTrend(tank, trendMeasurements(tank.ID, now, 500)); trend != nil {
+		t.Errorf("Con una sola lectura no debería haber tendencia: %+v", trend)
+	}
+}
+
+func TestTrendTankService(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	service := services.NewTrendTankService(tankService, measurementRepo)
+	ctx := context.Background()
+
+	draining := createTestTank()
+	idle := createTestTank()
+	for _, tank := range []*domain.Tank{draining, idle} {
+		if err := service.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+	}
+	for _, m := range trendMeasurements(draining.ID, time.Now(), 800, 700, 600) {
+		if err := tankService.AddMeasurement(ctx, m); err != nil {
+			t.Fatalf("Error al registrar la medición: %v", err)
+		}
+	}
+
+	// Act
+	tank, err := service.GetTank(ctx, draining.ID)
+	tanks, allErr := service.GetAllTanks(ctx)
+
+	// Assert
+	if err != nil || allErr != nil {
+		t.Fatalf("Error al obtener los tanques: %v, %v", err, allErr)
+	}
+	if tank.Trend == nil || tank.Trend.Direction != domain.TrendDraining || tank.Trend.RatePerHour != -100 || tank.Trend.Samples != 3 {
+		t.Errorf("Tendencia incorrecta: %+v", tank.Trend)
+	}
+	for _, listed := range tanks {
+		if listed.ID == idle.ID && listed.Trend != nil {
+			t.Errorf("Un tanque sin mediciones no debería tener tendencia: %+v", listed.Trend)
+		}
+		if listed.ID == draining.ID && listed.Trend == nil {
+			t.Error("La lista debería incluir la tendencia de cada tanque")
+		}
+	}
+
+	// La tendencia recibida al actualizar no se guarda
+	tank.Name = "Renombrado"
+	if err := service.UpdateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al actualizar el tanque: %v", err)
+	}
+	stored, _ := tankRepo.GetTank(ctx, draining.ID)
+	if stored.Trend != nil {
+		t.Errorf("La tendencia no debería guardarse: %+v", stored.Trend)
+	}
+}
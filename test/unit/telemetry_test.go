@@ -0,0 +1,106 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestTelemetryAlertingTankService_LowBattery(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	notifier := &MockAlertNotifier{}
+
+	tankService := services.NewTelemetryAlertingTankService(
+		newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{}),
+		measurementRepo,
+		notifier,
+		domain.TelemetryConfig{LowBatteryVoltage: 3.2, WeakSignalRSSI: -110},
+	)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act: la batería cae por debajo del umbral y sigue baja en las lecturas siguientes
+	start := time.Now().Add(-time.Hour)
+	for i, voltage := range []float64{3.4, 3.3, 3.15, 3.1, 3.05} {
+		rssi := -90.0
+		measurement := createTestMeasurement(tank.ID, 600)
+		measurement.Timestamp = start.Add(time.Duration(i) * time.Minute)
+		measurement.BatteryVoltage = &voltage
+		measurement.SignalStrength = &rssi
+		if err := tankService.AddMeasurement(ctx, measurement); err != nil {
+			t.Fatalf("Error al añadir la medición: %v", err)
+		}
+	}
+
+	// Assert: un solo aviso al cruzar el umbral
+	if notifier.AlertsSent != 1 {
+		t.Fatalf("Se esperaba un aviso de batería baja, se enviaron %d", notifier.AlertsSent)
+	}
+	if !strings.Contains(notifier.LastMessage, "Batería baja") {
+		t.Errorf("Mensaje inesperado: %s", notifier.LastMessage)
+	}
+	if alert := notifier.LastAlert; alert.Type != domain.AlertEventLowBattery || alert.Severity != domain.AlertSeverityWarning || alert.SensorID == "" {
+		t.Errorf("Aviso de batería baja mal clasificado: %+v", alert)
+	}
+}
+
+func TestTelemetryAlertingTankService_WeakSignalInBatch(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	notifier := &MockAlertNotifier{}
+
+	tankService := services.NewTelemetryAlertingTankService(
+		newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{}),
+		measurementRepo,
+		notifier,
+		domain.TelemetryConfig{WeakSignalRSSI: -110},
+	)
+	ctx := context.Background()
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(ctx, tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Dos sensores del mismo tanque; solo uno pierde cobertura
+	start := time.Now().Add(-time.Hour)
+	batch := make([]*domain.Measurement, 0)
+	for i, readings := range [][2]float64{{-100, -95}, {-115, -96}, {-118, -97}} {
+		for j, sensorID := range []string{"radar-1", "radar-2"} {
+			rssi := readings[j]
+			batch = append(batch, &domain.Measurement{
+				ID:             uuid.New().String(),
+				TankID:         tank.ID,
+				SensorID:       sensorID,
+				Level:          600,
+				Temperature:    20,
+				SignalStrength: &rssi,
+				Timestamp:      start.Add(time.Duration(i) * time.Minute),
+			})
+		}
+	}
+
+	// Act
+	if err := tankService.AddMeasurements(ctx, batch); err != nil {
+		t.Fatalf("Error al añadir el lote: %v", err)
+	}
+
+	// Assert
+	if notifier.AlertsSent != 1 || !strings.Contains(notifier.LastMessage, "radar-1") {
+		t.Errorf("Se esperaba un aviso de señal débil para radar-1, se enviaron %d: %s", notifier.AlertsSent, notifier.LastMessage)
+	}
+}
@@ -0,0 +1,104 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestParseTimestamp_AcceptsISO8601AndEpoch(t *testing.T) {
+	expected := time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC)
+	cases := map[string]string{
+		"RFC 3339 en UTC":            "2024-05-01T15:00:00Z",
+		"hora local con zona":        "2024-05-01T10:00:00-05:00",
+		"zona sin dos puntos":        "2024-05-01T10:00:00-0500",
+		"separador con espacio":      "2024-05-01 10:00:00-05:00",
+		"formato básico":             "20240501T150000Z",
+		"segundos desde 1970":        "1714575600",
+		"milisegundos desde 1970":    "1714575600000",
+		"segundos con espacios":      " 1714575600 ",
+		"RFC 3339 con fracción cero": "2024-05-01T15:00:00.000Z",
+	}
+
+	for name, value := range cases {
+		// Act
+		parsed, err := domain.ParseTimestamp(value)
+
+		// Assert
+		if err != nil {
+			t.Errorf("%s: error inesperado: %v", name, err)
+			continue
+		}
+		if !parsed.Equal(expected) || parsed.Location() != time.UTC {
+			t.Errorf("%s: se esperaba %v en UTC, se obtuvo %v", name, expected, parsed)
+		}
+	}
+}
+
+func TestParseTimestamp_RejectsAmbiguousValues(t *testing.T) {
+	for _, value := range []string{"", "2024-05-01T10:00:00", "2024-05-01", "ayer", "-5", "NaN"} {
+		if _, err := domain.ParseTimestamp(value); !errors.Is(err, domain.ErrInvalidTimestamp) {
+			t.Errorf("Se esperaba ErrInvalidTimestamp para %q, se obtuvo %v", value, err)
+		}
+	}
+}
+
+func TestMeasurement_UnmarshalJSONAcceptsNumericTimestamp(t *testing.T) {
+	// Act
+	var measurement domain.Measurement
+	err := json.Unmarshal([]byte(`{"tank_id":"t1","level":250,"timestamp":1714575600500}`), &measurement)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error inesperado: %v", err)
+	}
+	expected := time.Date(2024, 5, 1, 15, 0, 0, 500*int(time.Millisecond), time.UTC)
+	if !measurement.Timestamp.Equal(expected) || measurement.Level != 250 || measurement.TankID != "t1" {
+		t.Errorf("Medición incorrecta: %+v", measurement)
+	}
+
+	var invalid domain.Measurement
+	if err := json.Unmarshal([]byte(`{"level":250,"timestamp":"01/05/2024"}`), &invalid); !errors.Is(err, domain.ErrInvalidTimestamp) {
+		t.Errorf("Se esperaba ErrInvalidTimestamp, se obtuvo %v", err)
+	}
+}
+
+func TestFutureGuardTankService_RejectsReadingsBeyondTolerance(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	guarded := services.NewFutureGuardTankService(tankService, 5*time.Minute)
+
+	tank := createTestTank()
+	if err := tankService.CreateTank(context.Background(), tank); err != nil {
+		t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+	}
+
+	// Act
+	drifted := createTestMeasurement(tank.ID, 450.0)
+	drifted.Timestamp = time.Now().Add(2 * time.Minute)
+	driftErr := guarded.AddMeasurement(context.Background(), drifted)
+
+	future := createTestMeasurement(tank.ID, 400.0)
+	future.Timestamp = time.Now().Add(time.Hour)
+	futureErr := guarded.AddMeasurements(context.Background(), []*domain.Measurement{createTestMeasurement(tank.ID, 420.0), future})
+
+	// Assert
+	if driftErr != nil {
+		t.Errorf("Una deriva dentro del margen no debería rechazarse: %v", driftErr)
+	}
+	if !errors.Is(futureErr, services.ErrFutureMeasurement) {
+		t.Errorf("Se esperaba ErrFutureMeasurement, se obtuvo %v", futureErr)
+	}
+	stored, _ := measurementRepo.GetMeasurementsByTankID(context.Background(), tank.ID, 0)
+	if len(stored) != 1 {
+		t.Errorf("El lote con una medición futura no debería guardarse: se esperaba 1 medición, se obtuvieron %d", len(stored))
+	}
+}
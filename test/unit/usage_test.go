@@ -0,0 +1,110 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestUsage_MetersAPICallsAndMeasurementsPerOrganization(t *testing.T) {
+	// Arrange: un tanque de acme y otro sin organización
+	ctx := context.Background()
+	usageRepo := repositories.NewMemoryUsageRepository()
+	tankService := services.NewMeteringTankService(
+		newTestTankService(repositories.NewMemoryTankRepository(), repositories.NewMemoryMeasurementRepository(), &MockAlertNotifier{}),
+		usageRepo,
+		"organization",
+	)
+	acmeTank := createTestTank()
+	acmeTank.Labels = domain.Labels{"organization": "acme"}
+	otherTank := createTestTank()
+	otherTank.ID = "tanque-sin-organizacion"
+	for _, tank := range []*domain.Tank{acmeTank, otherTank} {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque: %v", err)
+		}
+	}
+	usageService := services.NewUsageService(usageRepo)
+
+	// Act
+	if err := tankService.AddMeasurement(ctx, createTestMeasurement(acmeTank.ID, 400)); err != nil {
+		t.Fatalf("Error al guardar la medición: %v", err)
+	}
+	batch := []*domain.Measurement{
+		createTestMeasurement(acmeTank.ID, 390),
+		createTestMeasurement(acmeTank.ID, 380),
+		createTestMeasurement(otherTank.ID, 300),
+	}
+	if err := tankService.AddMeasurements(ctx, batch); err != nil {
+		t.Fatalf("Error al guardar el lote: %v", err)
+	}
+	acmeCtx := domain.ContextWithPrincipal(ctx, &domain.Principal{Subject: "ana", Organization: "acme"})
+	for i := 0; i < 3; i++ {
+		usageService.RecordAPICall(acmeCtx)
+	}
+	usageService.RecordAPICall(ctx)
+	usage, err := usageService.GetUsage(ctx, "")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error inesperado al obtener el uso: %v", err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("Se esperaban 2 organizaciones, se obtuvieron %d", len(usage))
+	}
+	acme, unassigned := usage[0], usage[1]
+	if acme.Organization != "acme" || acme.APICalls != 3 || acme.Measurements != 3 || acme.ActiveTanks != 1 {
+		t.Errorf("Uso de acme incorrecto: %+v", acme)
+	}
+	if unassigned.Organization != domain.UnassignedOrganization || unassigned.APICalls != 1 || unassigned.Measurements != 1 {
+		t.Errorf("Uso sin organización incorrecto: %+v", unassigned)
+	}
+	if acme.Month != domain.UsageMonth(time.Now()) {
+		t.Errorf("Mes incorrecto: %s", acme.Month)
+	}
+
+	if past, err := usageService.GetUsage(ctx, "2020-01"); err != nil || len(past) != 0 {
+		t.Errorf("Un mes sin uso debería estar vacío (%v): %+v", err, past)
+	}
+	if _, err := usageService.GetUsage(ctx, "2020-13"); !errors.Is(err, domain.ErrInvalidUsageMonth) {
+		t.Errorf("Se esperaba ErrInvalidUsageMonth, se obtuvo: %v", err)
+	}
+}
+
+func TestUsage_SurvivesMemorySnapshot(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot.gob")
+	store := repositories.NewMemoryStore()
+	store.Usage.AddAPICalls(ctx, "2026-10", "acme", 5)
+	store.Usage.AddMeasurements(ctx, "2026-10", "acme", "tanque-1", 7)
+	store.Usage.AddAPICalls(ctx, "2026-10", "beta", 1)
+	if err := store.SaveSnapshot(ctx, path); err != nil {
+		t.Fatalf("Error al guardar la instantánea: %v", err)
+	}
+
+	// Act
+	restored := repositories.NewMemoryStore()
+	if _, err := restored.LoadSnapshot(ctx, path); err != nil {
+		t.Fatalf("Error al cargar la instantánea: %v", err)
+	}
+	// beta no tenía tanques: el contador restaurado debe admitir mediciones nuevas
+	if err := restored.Usage.AddMeasurements(ctx, "2026-10", "beta", "tanque-2", 1); err != nil {
+		t.Fatalf("Error al sumar mediciones: %v", err)
+	}
+	usage, _ := restored.Usage.GetUsage(ctx, "2026-10")
+
+	// Assert
+	if len(usage) != 2 {
+		t.Fatalf("Se esperaban 2 organizaciones, se obtuvieron %d", len(usage))
+	}
+	if usage[0].APICalls != 5 || usage[0].Measurements != 7 || usage[0].ActiveTanks != 1 || usage[1].ActiveTanks != 1 {
+		t.Errorf("Uso restaurado incorrecto: %+v, %+v", usage[0], usage[1])
+	}
+}
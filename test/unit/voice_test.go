@@ -0,0 +1,74 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"monitor-tanques/internal/adapters/repositories"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/internal/core/services"
+)
+
+func TestVoiceService_Answer(t *testing.T) {
+	// Arrange
+	tankRepo := repositories.NewMemoryTankRepository()
+	measurementRepo := repositories.NewMemoryMeasurementRepository()
+	tankService := newTestTankService(tankRepo, measurementRepo, &MockAlertNotifier{})
+	voiceService := services.NewVoiceService(tankService)
+	ctx := context.Background()
+
+	diesel := createTestTank()
+	diesel.Name, diesel.LiquidType, diesel.CurrentLevel = "Tanque Norte", "Diésel", 640
+	water := createTestTank()
+	water.Name, water.LiquidType = "Tanque Sur", "Agua"
+	rain := createTestTank()
+	rain.Name, rain.LiquidType = "Aljibe", "Agua"
+	for _, tank := range []*domain.Tank{diesel, water, rain} {
+		if err := tankService.CreateTank(ctx, tank); err != nil {
+			t.Fatalf("Error al crear el tanque para la prueba: %v", err)
+		}
+	}
+	if err := tankService.AddMeasurement(ctx, createTestMeasurement(water.ID, 150)); err != nil {
+		t.Fatalf("Error al registrar la medición: %v", err)
+	}
+
+	// Act
+	level, levelErr := voiceService.Answer(ctx, &domain.VoiceQuery{Text: "What's the level of the diesel tank?"})
+	status, statusErr := voiceService.Answer(ctx, &domain.VoiceQuery{Intent: domain.VoiceIntentTankStatus, Tank: "sur"})
+	low, lowErr := voiceService.Answer(ctx, &domain.VoiceQuery{Text: "¿Qué tanques están bajos?"})
+	ambiguous, ambiguousErr := voiceService.Answer(ctx, &domain.VoiceQuery{Text: "nivel del agua"})
+	missing, missingErr := voiceService.Answer(ctx, &domain.VoiceQuery{Intent: domain.VoiceIntentTankLevel, Tank: "gasolina"})
+	summary, summaryErr := voiceService.Answer(ctx, &domain.VoiceQuery{Text: "¿Cómo están mis tanques?"})
+	_, invalidErr := voiceService.Answer(ctx, &domain.VoiceQuery{Intent: "order_pizza"})
+	_, emptyErr := voiceService.Answer(ctx, &domain.VoiceQuery{})
+
+	// Assert
+	for _, err := range []error{levelErr, statusErr, lowErr, ambiguousErr, missingErr, summaryErr} {
+		if err != nil {
+			t.Fatalf("Error inesperado al responder: %v", err)
+		}
+	}
+
+	if level.Intent != domain.VoiceIntentTankLevel || level.Speech != "Tanque Norte está al 64 por ciento: 640 de 1000 litros." || !level.EndSession {
+		t.Errorf("Respuesta de nivel incorrecta: %+v", level)
+	}
+	if status.Speech != "Tanque Sur está en advertencia: queda al 15 por ciento." || status.TankIDs[0] != water.ID {
+		t.Errorf("Respuesta de estado incorrecta: %+v", status)
+	}
+	if low.Intent != domain.VoiceIntentLowTanks || len(low.TankIDs) != 1 || low.TankIDs[0] != water.ID {
+		t.Errorf("Se esperaba solo el tanque sur con el nivel bajo: %+v", low)
+	}
+	if ambiguous.EndSession || len(ambiguous.TankIDs) != 2 {
+		t.Errorf("Dos tanques de agua deben pedir aclaración: %+v", ambiguous)
+	}
+	if missing.EndSession || len(missing.TankIDs) != 0 {
+		t.Errorf("Un tanque inexistente debe pedir aclaración: %+v", missing)
+	}
+	if summary.Intent != domain.VoiceIntentSummary || summary.Speech != "Tienes 3 tanques: 2 en estado normal, 1 en advertencia y 0 en estado crítico." {
+		t.Errorf("Resumen incorrecto: %+v", summary)
+	}
+	if !errors.Is(invalidErr, services.ErrInvalidVoiceQuery) || !errors.Is(emptyErr, services.ErrInvalidVoiceQuery) {
+		t.Errorf("Se esperaba ErrInvalidVoiceQuery, se obtuvo %v y %v", invalidErr, emptyErr)
+	}
+}
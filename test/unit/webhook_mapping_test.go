@@ -0,0 +1,145 @@
+package services_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/webhooks"
+)
+
+const testWebhooksYAML = `
+sources:
+  - name: acme-cloud
+    token: secreto
+    records: $.data[*]
+    fields:
+      tank_id: $.device.tags['tank id']
+      level: $.values.level_m
+      temperature: $.values.temp
+      timestamp: $.ts
+      rssi: $.radio.rssi
+    timestamp_format: unix_ms
+    level_scale: 100
+  - name: simple
+    token: otro
+    fields:
+      tank_id: $.tank
+      level: $.level
+`
+
+func TestWebhookPath_Select(t *testing.T) {
+	// Arrange
+	document := map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"name": "a", "tags": map[string]interface{}{"tank id": "t-1"}},
+			map[string]interface{}{"name": "b"},
+		},
+	}
+
+	// Act
+	all, err := webhooks.ParsePath("$.data[*].name")
+	if err != nil {
+		t.Fatalf("Error inesperado al compilar la ruta: %v", err)
+	}
+	last, _ := webhooks.ParsePath("$.data[-1].name")
+	quoted, _ := webhooks.ParsePath("$.data[0].tags['tank id']")
+	missing, _ := webhooks.ParsePath("$.data[5].name")
+
+	// Assert
+	if got := all.Select(document); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Valores incorrectos para [*]: %v", got)
+	}
+	if got := last.Select(document); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Valor incorrecto para el índice negativo: %v", got)
+	}
+	if got := quoted.Select(document); len(got) != 1 || got[0] != "t-1" {
+		t.Errorf("Valor incorrecto para el campo entre comillas: %v", got)
+	}
+	if got := missing.Select(document); len(got) != 0 {
+		t.Errorf("Una posición inexistente no debería devolver valores: %v", got)
+	}
+	for _, expression := range []string{"data.name", "$.data[", "$.data[x]", "$..name"} {
+		if _, err := webhooks.ParsePath(expression); err == nil {
+			t.Errorf("Se esperaba un error para la ruta %q", expression)
+		}
+	}
+}
+
+func TestWebhookMapping_Extract(t *testing.T) {
+	// Arrange
+	mappings, err := webhooks.Parse(strings.NewReader(testWebhooksYAML))
+	if err != nil {
+		t.Fatalf("Error inesperado al leer el mapeo: %v", err)
+	}
+	payload := `{"data": [
+		{"device": {"tags": {"tank id": "tanque-1"}}, "values": {"level_m": 4.5, "temp": "21.5"}, "ts": 1714557600000, "radio": {"rssi": -97}},
+		{"device": {"tags": {}}, "values": {"level_m": 1}},
+		{"device": {"tags": {"tank id": "tanque-2"}}, "values": {"level_m": "n/a"}}
+	]}`
+
+	// Act
+	records, err := mappings["acme-cloud"].Extract([]byte(payload))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Error inesperado al extraer las lecturas: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Se esperaban 3 lecturas, se obtuvieron %d", len(records))
+	}
+
+	measurement := records[0].Measurement
+	if measurement == nil || records[0].Error != "" {
+		t.Fatalf("La primera lectura debería ser válida: %q", records[0].Error)
+	}
+	if measurement.TankID != "tanque-1" || measurement.Level != 450 || measurement.Temperature != 21.5 {
+		t.Errorf("Medición incorrecta: %+v", measurement)
+	}
+	if !measurement.Timestamp.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Marca de tiempo incorrecta: %v", measurement.Timestamp)
+	}
+	if measurement.SignalStrength == nil || *measurement.SignalStrength != -97 {
+		t.Errorf("RSSI incorrecto: %v", measurement.SignalStrength)
+	}
+	if records[1].Measurement != nil || records[1].Error != "Falta el ID del tanque" {
+		t.Errorf("Error incorrecto para la lectura sin tanque: %q", records[1].Error)
+	}
+	if records[2].Measurement != nil || records[2].Error != "Falta el nivel o no es un número" {
+		t.Errorf("Error incorrecto para el nivel no numérico: %q", records[2].Error)
+	}
+
+	// Sin records el documento completo es una lectura
+	single, err := mappings["simple"].Extract([]byte(`{"tank": 17, "level": 300}`))
+	if err != nil || len(single) != 1 || single[0].Measurement == nil || single[0].Measurement.TankID != "17" {
+		t.Errorf("Lectura única incorrecta: %v, %v", single, err)
+	}
+	if _, err := mappings["simple"].Extract([]byte(`no es json`)); !errors.Is(err, webhooks.ErrInvalidPayload) {
+		t.Errorf("Se esperaba ErrInvalidPayload, se obtuvo: %v", err)
+	}
+}
+
+func TestWebhookMapping_ParseRejectsInvalidConfig(t *testing.T) {
+	cases := map[string]string{
+		"sin token":       "sources:\n  - name: a\n    fields: {tank_id: $.t, level: $.l}\n",
+		"sin nivel":       "sources:\n  - name: a\n    token: x\n    fields: {tank_id: $.t}\n",
+		"nombre inválido": "sources:\n  - name: A B\n    token: x\n    fields: {tank_id: $.t, level: $.l}\n",
+		"duplicada":       "sources:\n  - {name: a, token: x, fields: {tank_id: $.t, level: $.l}}\n  - {name: a, token: y, fields: {tank_id: $.t, level: $.l}}\n",
+		"campo erróneo":   "sources:\n  - name: a\n    token: x\n    fields: {tank_id: $.t, level: $.l, nivel: $.n}\n",
+		"formato":         "sources:\n  - name: a\n    token: x\n    timestamp_format: iso\n    fields: {tank_id: $.t, level: $.l}\n",
+		"ruta inválida":   "sources:\n  - name: a\n    token: x\n    fields: {tank_id: t, level: $.l}\n",
+	}
+
+	for name, document := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			_, err := webhooks.Parse(strings.NewReader(document))
+
+			// Assert
+			if !errors.Is(err, webhooks.ErrInvalidConfig) {
+				t.Errorf("Se esperaba ErrInvalidConfig, se obtuvo: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"monitor-tanques/internal/adapters/notifiers"
+	"monitor-tanques/internal/core/domain"
+	"monitor-tanques/pkg/logger"
+	"monitor-tanques/pkg/webhooksig"
+)
+
+// signedHeader devuelve las cabeceras de una entrega firmada con el secreto en el instante at
+func signedHeader(secret, id string, at time.Time, body []byte) http.Header {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	webhooksig.SignRequest(req, []byte(secret), id, at, body)
+	return req.Header
+}
+
+func TestWebhookSignature_Verify(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"type":"level_critical"}`)
+	verifier := webhooksig.NewVerifier([]byte("secreto")).WithClock(func() time.Time { return now })
+
+	cases := []struct {
+		name     string
+		header   http.Header
+		body     []byte
+		expected error
+	}{
+		{"válida", signedHeader("secreto", "entrega-1", now, body), body, nil},
+		{"cuerpo alterado", signedHeader("secreto", "entrega-1", now, body), []byte(`{"type":"normal"}`), webhooksig.ErrInvalidSignature},
+		{"otro secreto", signedHeader("otro", "entrega-1", now, body), body, webhooksig.ErrInvalidSignature},
+		{"marca de tiempo antigua", signedHeader("secreto", "entrega-1", now.Add(-10*time.Minute), body), body, webhooksig.ErrTimestampExpired},
+		{"sin cabeceras", http.Header{}, body, webhooksig.ErrMissingSignature},
+	}
+
+	// Act & Assert
+	for _, c := range cases {
+		if err := verifier.Verify(c.header, c.body); !errors.Is(err, c.expected) {
+			t.Errorf("%s: se esperaba %v, se obtuvo %v", c.name, c.expected, err)
+		}
+	}
+
+	// Cambiar la marca de tiempo invalida la firma: forma parte del contenido firmado
+	tampered := signedHeader("secreto", "entrega-1", now.Add(-10*time.Minute), body)
+	tampered.Set(webhooksig.TimestampHeader, "1717243200")
+	if err := verifier.Verify(tampered, body); !errors.Is(err, webhooksig.ErrInvalidSignature) {
+		t.Errorf("Una marca de tiempo cambiada debería invalidar la firma, se obtuvo %v", err)
+	}
+}
+
+func TestWebhookSignature_ReplayAndRotation(t *testing.T) {
+	// Arrange: durante la rotación se aceptan el secreto nuevo y el anterior
+	body := []byte(`{}`)
+	verifier := webhooksig.NewVerifier([]byte("nuevo"), []byte("anterior")).
+		WithReplayCache(webhooksig.NewMemoryReplayCache())
+	now := time.Now()
+
+	// Act
+	first := verifier.Verify(signedHeader("anterior", "entrega-1", now, body), body)
+	replayed := verifier.Verify(signedHeader("anterior", "entrega-1", now, body), body)
+	second := verifier.Verify(signedHeader("nuevo", "entrega-2", now, body), body)
+
+	// Assert
+	if first != nil || second != nil {
+		t.Errorf("Ambos secretos deberían ser válidos: %v, %v", first, second)
+	}
+	if !errors.Is(replayed, webhooksig.ErrReplayed) {
+		t.Errorf("Se esperaba ErrReplayed al repetir la entrega, se obtuvo %v", replayed)
+	}
+}
+
+func TestChannelSender_SignsWebhooks(t *testing.T) {
+	// Arrange: un receptor que verifica cada entrega con el secreto del canal
+	verifier := webhooksig.NewVerifier([]byte("secreto-del-canal"))
+	var results []error
+	var signedBodies int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(webhooksig.SignatureHeader) != "" {
+			signedBodies++
+		}
+		_, err := verifier.VerifyRequest(r)
+		if body, _ := io.ReadAll(r.Body); len(body) == 0 {
+			t.Error("El cuerpo debería seguir disponible tras la verificación")
+		}
+		results = append(results, err)
+	}))
+	defer server.Close()
+
+	sender := notifiers.NewChannelSender(nil, nil, "secreto-global", logger.NewSimpleLogger())
+	alert := domain.NewAlert(domain.AlertEventLevelCritical, domain.AlertSeverityCritical, createTestTank(), "Nivel crítico")
+	channels := []*domain.NotificationChannel{
+		{ID: "propio", Name: "Propio", Type: domain.ChannelTypeWebhook, Target: server.URL, Secret: "secreto-del-canal"},
+		{ID: "global", Name: "Global", Type: domain.ChannelTypeWebhook, Target: server.URL},
+		{ID: "slack", Name: "Slack", Type: domain.ChannelTypeSlack, Target: server.URL},
+	}
+
+	// Act
+	for _, channel := range channels {
+		if err := sender.Send(context.Background(), channel, alert); err != nil {
+			t.Fatalf("Error al enviar por %s: %v", channel.ID, err)
+		}
+	}
+
+	// Assert: el canal con secreto propio lo usa, el resto de webhooks usan el global y Slack no se firma
+	if len(results) != 3 || signedBodies != 2 {
+		t.Fatalf("Se esperaban 3 entregas, 2 firmadas: %d entregas, %d firmadas", len(results), signedBodies)
+	}
+	if results[0] != nil {
+		t.Errorf("La firma con el secreto del canal debería ser válida: %v", results[0])
+	}
+	if !errors.Is(results[1], webhooksig.ErrInvalidSignature) {
+		t.Errorf("El canal sin secreto propio debería firmar con el global: %v", results[1])
+	}
+	if !errors.Is(results[2], webhooksig.ErrMissingSignature) {
+		t.Errorf("Slack no debería recibir firma: %v", results[2])
+	}
+}